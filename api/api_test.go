@@ -0,0 +1,97 @@
+package api
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/internal/apicheck"
+)
+
+var update = flag.Bool("update", false, "update the API baselines")
+
+const module = "github.com/willibrandon/gonuget"
+
+// stablePackages are the packages of the stable tier, relative to the module
+var stablePackages = []string{
+	"core",
+	"core/resolver",
+	"frameworks",
+	"packaging",
+	"packaging/assets",
+	"packaging/signatures",
+	"version",
+}
+
+// baselineFile returns the baseline file of a stable package, such as core.resolver.txt
+func baselineFile(pkg string) string {
+	return strings.ReplaceAll(pkg, "/", ".") + ".txt"
+}
+
+func TestAPI(t *testing.T) {
+	paths := make([]string, len(stablePackages))
+	for i, pkg := range stablePackages {
+		paths[i] = module + "/" + pkg
+	}
+	apis, err := apicheck.Load(".", paths...)
+	if err != nil {
+		t.Fatalf("failed to load the stable packages: %v", err)
+	}
+	changelog, err := os.ReadFile(filepath.Join("..", "CHANGELOG.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, pkg := range stablePackages {
+		api := apis[paths[i]]
+		file := baselineFile(pkg)
+		data, err := os.ReadFile(file)
+		if err != nil && !(*update && os.IsNotExist(err)) {
+			t.Errorf("%s: %v; run go test ./api -update", pkg, err)
+			continue
+		}
+		baseline := apicheck.ParseBaseline(data)
+
+		if *update {
+			baseline = baseline.Update(api.Features)
+			header := "API of " + paths[i] + ", checked by TestAPI.\nRegenerate with: go test ./api -update"
+			if err := os.WriteFile(file, baseline.Format(header), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if added, removed := apicheck.Diff(baseline.Features, api.Features); len(added) > 0 || len(removed) > 0 {
+			var diff strings.Builder
+			for _, feature := range removed {
+				diff.WriteString("\n  - " + feature)
+			}
+			for _, feature := range added {
+				diff.WriteString("\n  + " + feature)
+			}
+			t.Errorf("%s: exported API differs from %s; run go test ./api -update and commit the baseline with the change:%s",
+				pkg, file, diff.String())
+		}
+
+		if missing := apicheck.Unannounced(api.Name, baseline.Removed, changelog); len(missing) > 0 {
+			var symbols strings.Builder
+			for _, feature := range missing {
+				symbols.WriteString("\n  " + apicheck.Symbol(api.Name, feature) + " (" + feature + ")")
+			}
+			t.Errorf("%s: removed or changed API is not announced in CHANGELOG.md:%s", pkg, symbols.String())
+		}
+	}
+
+	// A baseline without a stable package would never be checked
+	files, err := filepath.Glob("*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if !slices.ContainsFunc(stablePackages, func(pkg string) bool { return baselineFile(pkg) == file }) {
+			t.Errorf("%s is not the baseline of a stable package", file)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package api holds the API baselines of gonuget's stable packages and the test that
+// keeps them current. It has no code of its own.
+//
+// # Stability tiers
+//
+// Stable: version, frameworks, packaging, packaging/assets, packaging/signatures, core
+// and core/resolver. Their exported API is recorded in a baseline file per package in
+// this directory, such as core.resolver.txt for core/resolver, and follows the
+// deprecation policy below.
+//
+// Experimental: the other importable packages, such as http, protocol/v3, restore and
+// the packages under cmd/gonuget. They are public so the CLI and tools can use them, but
+// their API may change in any release.
+//
+// Internal: the packages under internal/, which only gonuget can import. The interop
+// test bridges under cmd are commands and can't be imported either.
+//
+// # Deprecation policy
+//
+// An exported declaration of a stable package is deprecated before it is removed: its
+// doc comment gets a paragraph starting with "Deprecated:" that names the replacement,
+// and it is removed in a later minor release at the earliest. Changing a signature is a
+// removal of the old one.
+//
+// Every removed or changed declaration is announced in CHANGELOG.md by its qualified
+// name, such as `version.Parse` or `version.NuGetVersion.String`.
+//
+// # Enforcement
+//
+// TestAPI type-checks the stable packages and fails when their exported API differs
+// from the baselines. After an intentional change, regenerate the baselines in the same
+// commit:
+//
+//	go test ./api -update
+//
+// The update records removed or changed features on "removed:" lines, and the test
+// fails until CHANGELOG.md mentions each of them. The removed lines are deleted when a
+// release is cut. Additions and deprecations need no changelog entry to pass.
+package api
@@ -0,0 +1,87 @@
+package credentialprovider
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// PluginPathsEnv lists plugin files or directories, separated like PATH. When set, the
+	// conventional plugin folder is not searched.
+	PluginPathsEnv = "NUGET_PLUGIN_PATHS"
+	// NetCorePluginPathsEnv takes precedence over PluginPathsEnv, as for dotnet.
+	NetCorePluginPathsEnv = "NUGET_NETCORE_PLUGIN_PATHS"
+)
+
+// Discover finds the credential provider plugins dotnet would use: the entries of
+// NUGET_NETCORE_PLUGIN_PATHS or NUGET_PLUGIN_PATHS when set, otherwise the plugins
+// installed under ~/.nuget/plugins/netcore, such as the Azure Artifacts Credential Provider.
+// Reference: NuGet.Protocol.Plugins.PluginDiscoveryUtility
+func Discover() []Plugin {
+	raw := os.Getenv(NetCorePluginPathsEnv)
+	if raw == "" {
+		raw = os.Getenv(PluginPathsEnv)
+	}
+	if raw != "" {
+		return discoverPaths(filepath.SplitList(raw))
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return discoverDirectory(filepath.Join(home, ".nuget", "plugins", "netcore"))
+}
+
+// discoverPaths returns the plugins named by a list of files and plugin directories.
+func discoverPaths(paths []string) []Plugin {
+	var plugins []Plugin
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			plugins = append(plugins, discoverDirectory(path)...)
+			continue
+		}
+		plugins = append(plugins, Plugin{Path: path})
+	}
+	return plugins
+}
+
+// discoverDirectory returns the plugins of a plugin folder, each in its own
+// subdirectory named after the plugin: <dir>/<name>/<name>.dll, or an executable
+// <dir>/<name>/<name>[.exe].
+func discoverDirectory(dir string) []Plugin {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var plugins []Plugin
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		candidates := []string{name + ".dll", name}
+		if runtime.GOOS == "windows" {
+			candidates = []string{name + ".dll", name + ".exe"}
+		}
+		for _, candidate := range candidates {
+			path := filepath.Join(dir, name, candidate)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				plugins = append(plugins, Plugin{Path: path})
+				break
+			}
+		}
+	}
+	return plugins
+}
@@ -0,0 +1,72 @@
+package credentialprovider
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func writePluginFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("plugin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func pluginPaths(plugins []Plugin) []string {
+	paths := make([]string, len(plugins))
+	for i, plugin := range plugins {
+		paths[i] = plugin.Path
+	}
+	return paths
+}
+
+func TestDiscover_Convention(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(PluginPathsEnv, "")
+	t.Setenv(NetCorePluginPathsEnv, "")
+
+	netcore := filepath.Join(home, ".nuget", "plugins", "netcore")
+	azure := filepath.Join(netcore, "CredentialProvider.Microsoft", "CredentialProvider.Microsoft.dll")
+	writePluginFile(t, azure)
+	// Folders without a plugin named after them are skipped
+	writePluginFile(t, filepath.Join(netcore, "Other", "Helper.dll"))
+
+	if got := pluginPaths(Discover()); !slices.Equal(got, []string{azure}) {
+		t.Errorf("Discover() = %v, want %v", got, []string{azure})
+	}
+}
+
+func TestDiscover_Environment(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "custom", "provider.dll")
+	writePluginFile(t, file)
+	exeName := "Contoso.Provider"
+	if runtime.GOOS == "windows" {
+		exeName += ".exe"
+	}
+	folder := filepath.Join(dir, "plugins")
+	exe := filepath.Join(folder, "Contoso.Provider", exeName)
+	writePluginFile(t, exe)
+
+	// Missing entries are ignored; the conventional folder is not searched
+	raw := file + string(os.PathListSeparator) + filepath.Join(dir, "missing.dll") + string(os.PathListSeparator) + folder
+	t.Setenv(PluginPathsEnv, raw)
+	t.Setenv(NetCorePluginPathsEnv, "")
+	if got, want := pluginPaths(Discover()), []string{file, exe}; !slices.Equal(got, want) {
+		t.Errorf("Discover() = %v, want %v", got, want)
+	}
+
+	// The .NET Core variable wins
+	t.Setenv(NetCorePluginPathsEnv, file)
+	if got, want := pluginPaths(Discover()), []string{file}; !slices.Equal(got, want) {
+		t.Errorf("Discover() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,102 @@
+package credentialprovider
+
+import "encoding/json"
+
+// Protocol versions offered in the handshake.
+// Reference: NuGet.Protocol.Plugins.ProtocolConstants
+const (
+	protocolVersion        = "2.0.0"
+	minimumProtocolVersion = "1.0.0"
+)
+
+// messageType is the kind of a plugin protocol message.
+type messageType string
+
+const (
+	messageRequest  messageType = "Request"
+	messageResponse messageType = "Response"
+	messageFault    messageType = "Fault"
+	messageCancel   messageType = "Cancel"
+)
+
+// Message methods used by gonuget. A plugin may send others, which are answered with a fault.
+const (
+	methodHandshake                    = "Handshake"
+	methodInitialize                   = "Initialize"
+	methodGetOperationClaims           = "GetOperationClaims"
+	methodGetAuthenticationCredentials = "GetAuthenticationCredentials"
+	methodSetLogLevel                  = "SetLogLevel"
+	methodLog                          = "Log"
+	methodMonitorNuGetProcessExit      = "MonitorNuGetProcessExit"
+	methodClose                        = "Close"
+)
+
+// Response codes of plugin responses.
+const (
+	responseSuccess  = "Success"
+	responseNotFound = "NotFound"
+)
+
+// operationClaimAuthentication is the claim of a plugin that provides credentials.
+const operationClaimAuthentication = "Authentication"
+
+// message is one line of JSON exchanged with a plugin over stdin and stdout.
+// Reference: NuGet.Protocol.Plugins.Message
+type message struct {
+	RequestID string          `json:"RequestId"`
+	Type      messageType     `json:"Type"`
+	Method    string          `json:"Method"`
+	Payload   json.RawMessage `json:"Payload,omitempty"`
+}
+
+// handshakeRequest is sent by both sides when a connection starts.
+type handshakeRequest struct {
+	ProtocolVersion        string `json:"ProtocolVersion"`
+	MinimumProtocolVersion string `json:"MinimumProtocolVersion"`
+}
+
+// handshakeResponse answers a handshakeRequest.
+type handshakeResponse struct {
+	ResponseCode    string `json:"ResponseCode"`
+	ProtocolVersion string `json:"ProtocolVersion,omitempty"`
+}
+
+// initializeRequest tells the plugin about the client after the handshake.
+type initializeRequest struct {
+	ClientVersion  string `json:"ClientVersion"`
+	Culture        string `json:"Culture"`
+	RequestTimeout string `json:"RequestTimeout"`
+}
+
+// codeResponse is a response that only carries a response code.
+type codeResponse struct {
+	ResponseCode string `json:"ResponseCode"`
+}
+
+// operationClaimsResponse lists the operations a plugin supports.
+type operationClaimsResponse struct {
+	ResponseCode string   `json:"ResponseCode"`
+	Claims       []string `json:"Claims"`
+}
+
+// authenticationCredentialsRequest asks a plugin for the credentials of a source.
+type authenticationCredentialsRequest struct {
+	URI              string `json:"Uri"`
+	IsRetry          bool   `json:"IsRetry"`
+	IsNonInteractive bool   `json:"IsNonInteractive"`
+	CanShowDialog    bool   `json:"CanShowDialog"`
+}
+
+// authenticationCredentialsResponse carries the credentials a plugin found.
+type authenticationCredentialsResponse struct {
+	ResponseCode        string   `json:"ResponseCode"`
+	Username            string   `json:"Username"`
+	Password            string   `json:"Password"`
+	Message             string   `json:"Message"`
+	AuthenticationTypes []string `json:"AuthenticationTypes"`
+}
+
+// faultPayload describes why a request failed.
+type faultPayload struct {
+	Message string `json:"Message"`
+}
@@ -0,0 +1,234 @@
+package credentialprovider
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// requestTimeout bounds the handshake and the other setup requests.
+// Matches NuGet.Client's default NUGET_PLUGIN_REQUEST_TIMEOUT_IN_SECONDS.
+const requestTimeout = 5 * time.Second
+
+// closeTimeout is how long a plugin may take to exit after it is asked to close.
+const closeTimeout = 2 * time.Second
+
+// Plugin is a credential provider plugin found on disk.
+type Plugin struct {
+	// Path is the plugin's .dll, run with dotnet, or its executable
+	Path string
+}
+
+// command returns the command that starts the plugin in plugin mode.
+func (p Plugin) command(ctx context.Context) *exec.Cmd {
+	if strings.EqualFold(filepath.Ext(p.Path), ".dll") {
+		return exec.CommandContext(ctx, "dotnet", p.Path, "-Plugin")
+	}
+	return exec.CommandContext(ctx, p.Path, "-Plugin")
+}
+
+// connection is a running plugin speaking the NuGet cross-platform plugin protocol:
+// one JSON message per line, requests and responses matched by RequestId.
+// Reference: NuGet.Protocol.Plugins.Connection
+type connection struct {
+	plugin Plugin
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	stdin  io.WriteCloser
+
+	writeMu sync.Mutex
+	nextID  atomic.Int64
+
+	mu      sync.Mutex
+	pending map[string]chan *message
+
+	// done is closed when the plugin's output ends; readErr then holds the reason
+	done    chan struct{}
+	readErr error
+}
+
+// start launches a plugin and performs the handshake.
+func start(plugin Plugin) (*connection, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := plugin.command(ctx)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("start credential provider %s: %w", plugin.Path, err)
+	}
+
+	c := &connection{
+		plugin:  plugin,
+		cmd:     cmd,
+		cancel:  cancel,
+		stdin:   stdin,
+		pending: make(map[string]chan *message),
+		done:    make(chan struct{}),
+	}
+	go c.read(stdout)
+
+	var handshake handshakeResponse
+	err = c.request(context.Background(), requestTimeout, methodHandshake, handshakeRequest{
+		ProtocolVersion:        protocolVersion,
+		MinimumProtocolVersion: minimumProtocolVersion,
+	}, &handshake)
+	if err == nil && handshake.ResponseCode != responseSuccess {
+		err = fmt.Errorf("handshake failed with %s", handshake.ResponseCode)
+	}
+	if err != nil {
+		c.close()
+		return nil, fmt.Errorf("credential provider %s: %w", plugin.Path, err)
+	}
+	return c, nil
+}
+
+// read dispatches the plugin's messages until its output ends.
+func (c *connection) read(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var msg message
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			// Plugins may write diagnostics to stdout before the protocol starts
+			continue
+		}
+
+		switch msg.Type {
+		case messageResponse, messageFault:
+			c.mu.Lock()
+			ch, ok := c.pending[msg.RequestID]
+			delete(c.pending, msg.RequestID)
+			c.mu.Unlock()
+			if ok {
+				ch <- &msg
+			}
+		case messageRequest:
+			c.answer(&msg)
+		}
+	}
+
+	c.readErr = scanner.Err()
+	if c.readErr == nil {
+		c.readErr = errors.New("plugin exited")
+	}
+	close(c.done)
+}
+
+// answer responds to a request the plugin sends to gonuget.
+func (c *connection) answer(msg *message) {
+	switch msg.Method {
+	case methodHandshake:
+		_ = c.send(msg.RequestID, messageResponse, msg.Method, handshakeResponse{
+			ResponseCode:    responseSuccess,
+			ProtocolVersion: protocolVersion,
+		})
+	case methodLog, methodSetLogLevel, methodMonitorNuGetProcessExit:
+		_ = c.send(msg.RequestID, messageResponse, msg.Method, codeResponse{ResponseCode: responseSuccess})
+	case methodClose:
+		// The plugin is shutting down; close will reap it
+	default:
+		_ = c.send(msg.RequestID, messageFault, msg.Method, faultPayload{
+			Message: fmt.Sprintf("gonuget does not support the %s request", msg.Method),
+		})
+	}
+}
+
+// request sends a request and decodes the payload of its response into response.
+// A positive timeout bounds the wait in addition to ctx.
+func (c *connection) request(ctx context.Context, timeout time.Duration, method string, payload, response any) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	id := strconv.FormatInt(c.nextID.Add(1), 10)
+	ch := make(chan *message, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(id, messageRequest, method, payload); err != nil {
+		return fmt.Errorf("send %s: %w", method, err)
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Type == messageFault {
+			var fault faultPayload
+			_ = json.Unmarshal(msg.Payload, &fault)
+			return fmt.Errorf("%s failed: %s", method, fault.Message)
+		}
+		if err := json.Unmarshal(msg.Payload, response); err != nil {
+			return fmt.Errorf("parse %s response: %w", method, err)
+		}
+		return nil
+	case <-c.done:
+		return fmt.Errorf("%s: %w", method, c.readErr)
+	case <-ctx.Done():
+		_ = c.send(id, messageCancel, method, nil)
+		return fmt.Errorf("%s: %w", method, ctx.Err())
+	}
+}
+
+// send writes one message to the plugin.
+func (c *connection) send(id string, kind messageType, method string, payload any) error {
+	msg := message{RequestID: id, Type: kind, Method: method}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		msg.Payload = data
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// close asks the plugin to exit and kills it if it does not.
+func (c *connection) close() {
+	_ = c.send(strconv.FormatInt(c.nextID.Add(1), 10), messageRequest, methodClose, nil)
+	_ = c.stdin.Close()
+
+	select {
+	case <-c.done:
+	case <-time.After(closeTimeout):
+	}
+	c.cancel()
+	_ = c.cmd.Wait()
+}
@@ -0,0 +1,122 @@
+// Package credentialprovider obtains package source credentials from NuGet credential
+// provider plugins, such as the Azure Artifacts Credential Provider, over the NuGet
+// cross-platform plugin protocol (JSON messages over the plugin's stdin and stdout).
+//
+// Reference: https://learn.microsoft.com/nuget/reference/extensibility/nuget-cross-platform-plugins
+package credentialprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/willibrandon/gonuget/auth"
+	nugethttp "github.com/willibrandon/gonuget/http"
+)
+
+// clientVersion is reported to plugins in the Initialize request.
+const clientVersion = "6.0.0"
+
+// Provider asks credential provider plugins for the credentials of a source. Each
+// plugin is started for a request and closed afterwards; the credentials a plugin
+// returns are kept for the lifetime of the Provider, so a source asks once per session.
+type Provider struct {
+	plugins []Plugin
+
+	mu     sync.Mutex
+	tokens map[string]auth.Authenticator
+}
+
+// NewProvider creates a provider that asks plugins in order.
+func NewProvider(plugins []Plugin) *Provider {
+	return &Provider{
+		plugins: plugins,
+		tokens:  make(map[string]auth.Authenticator),
+	}
+}
+
+// GetCredentials implements auth.CredentialProvider. It returns the credentials of the
+// first plugin that has some for sourceURL, or nil when none has. Plugin failures are
+// only reported when no plugin supplied credentials.
+func (p *Provider) GetCredentials(ctx context.Context, sourceURL string) (auth.Authenticator, error) {
+	key := nugethttp.CanonicalSourceURL(sourceURL)
+	p.mu.Lock()
+	cached, ok := p.tokens[key]
+	p.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	var errs []error
+	for _, plugin := range p.plugins {
+		authenticator, err := getCredentials(ctx, plugin, sourceURL)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if authenticator != nil {
+			p.mu.Lock()
+			p.tokens[key] = authenticator
+			p.mu.Unlock()
+			return authenticator, nil
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+// getCredentials runs one plugin and asks it for the credentials of sourceURL.
+// Returns nil without an error when the plugin has none or does not provide credentials.
+func getCredentials(ctx context.Context, plugin Plugin, sourceURL string) (auth.Authenticator, error) {
+	conn, err := start(plugin)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	var initialized codeResponse
+	if err := conn.request(ctx, requestTimeout, methodInitialize, initializeRequest{
+		ClientVersion:  clientVersion,
+		Culture:        "en-US",
+		RequestTimeout: "00:00:05",
+	}, &initialized); err != nil {
+		return nil, fmt.Errorf("credential provider %s: %w", plugin.Path, err)
+	}
+	if initialized.ResponseCode != responseSuccess {
+		return nil, fmt.Errorf("credential provider %s: initialize failed with %s", plugin.Path, initialized.ResponseCode)
+	}
+
+	var claims operationClaimsResponse
+	if err := conn.request(ctx, requestTimeout, methodGetOperationClaims, struct{}{}, &claims); err != nil {
+		return nil, fmt.Errorf("credential provider %s: %w", plugin.Path, err)
+	}
+	if !slices.Contains(claims.Claims, operationClaimAuthentication) {
+		return nil, nil
+	}
+
+	// Restore runs unattended, so the plugin may not prompt
+	var credentials authenticationCredentialsResponse
+	if err := conn.request(ctx, 0, methodGetAuthenticationCredentials, authenticationCredentialsRequest{
+		URI:              sourceURL,
+		IsNonInteractive: true,
+	}, &credentials); err != nil {
+		return nil, fmt.Errorf("credential provider %s: %w", plugin.Path, err)
+	}
+
+	switch credentials.ResponseCode {
+	case responseSuccess:
+		if credentials.Username == "" && credentials.Password == "" {
+			return nil, nil
+		}
+		return auth.NewBasicAuthenticator(credentials.Username, credentials.Password), nil
+	case responseNotFound:
+		return nil, nil
+	default:
+		message := credentials.Message
+		if message == "" {
+			message = credentials.ResponseCode
+		}
+		return nil, fmt.Errorf("credential provider %s: %s", plugin.Path, message)
+	}
+}
@@ -0,0 +1,173 @@
+package credentialprovider
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakePluginEnv makes the test binary act as a credential provider plugin; its value
+// selects the answer to GetAuthenticationCredentials.
+const fakePluginEnv = "GONUGET_TEST_CREDENTIAL_PLUGIN"
+
+// fakePluginLogEnv names a file the fake plugin appends a line to on every launch.
+const fakePluginLogEnv = "GONUGET_TEST_CREDENTIAL_PLUGIN_LOG"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(fakePluginEnv); mode != "" {
+		os.Exit(runFakePlugin(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// runFakePlugin speaks the plugin protocol like the Azure Artifacts Credential Provider:
+// it sends its own handshake and a log message, and hands out a token per source.
+func runFakePlugin(mode string) int {
+	if path := os.Getenv(fakePluginLogEnv); path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err == nil {
+			_, _ = f.WriteString("launched\n")
+			_ = f.Close()
+		}
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	send := func(id string, kind messageType, method string, payload any) {
+		data, _ := json.Marshal(payload)
+		_ = out.Encode(message{RequestID: id, Type: kind, Method: method, Payload: data})
+	}
+
+	// Noise before the protocol starts is ignored by the host
+	fmt.Println("Starting credential provider")
+	send("plugin-1", messageRequest, methodHandshake, handshakeRequest{ProtocolVersion: protocolVersion, MinimumProtocolVersion: minimumProtocolVersion})
+
+	hostHandshake := false
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return 2
+		}
+		if msg.Type == messageResponse {
+			hostHandshake = hostHandshake || msg.RequestID == "plugin-1"
+			continue
+		}
+		if msg.Type != messageRequest {
+			continue
+		}
+
+		switch msg.Method {
+		case methodHandshake:
+			send(msg.RequestID, messageResponse, msg.Method, handshakeResponse{ResponseCode: responseSuccess, ProtocolVersion: protocolVersion})
+		case methodInitialize:
+			send("plugin-2", messageRequest, methodLog, map[string]string{"LogLevel": "Verbose", "Message": "initialized"})
+			send(msg.RequestID, messageResponse, msg.Method, codeResponse{ResponseCode: responseSuccess})
+		case methodGetOperationClaims:
+			claims := []string{operationClaimAuthentication}
+			if mode == "noclaims" {
+				claims = []string{}
+			}
+			send(msg.RequestID, messageResponse, msg.Method, operationClaimsResponse{ResponseCode: responseSuccess, Claims: claims})
+		case methodGetAuthenticationCredentials:
+			var req authenticationCredentialsRequest
+			_ = json.Unmarshal(msg.Payload, &req)
+			switch {
+			case mode == "notfound":
+				send(msg.RequestID, messageResponse, msg.Method, authenticationCredentialsResponse{ResponseCode: responseNotFound})
+			case mode == "error" || !hostHandshake || !req.IsNonInteractive:
+				send(msg.RequestID, messageResponse, msg.Method, authenticationCredentialsResponse{ResponseCode: "Error", Message: "no session token"})
+			default:
+				send(msg.RequestID, messageResponse, msg.Method, authenticationCredentialsResponse{
+					ResponseCode:        responseSuccess,
+					Username:            "VssSessionToken",
+					Password:            "token-for-" + req.URI,
+					AuthenticationTypes: []string{"Basic"},
+				})
+			}
+		case methodClose:
+			return 0
+		}
+	}
+	return 0
+}
+
+// fakePlugin returns the test binary as a plugin answering in the given mode.
+func fakePlugin(t *testing.T, mode string) Plugin {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(fakePluginEnv, mode)
+	return Plugin{Path: exe}
+}
+
+func TestProvider_GetCredentials(t *testing.T) {
+	plugin := fakePlugin(t, "token")
+	launches := filepath.Join(t.TempDir(), "launches")
+	t.Setenv(fakePluginLogEnv, launches)
+
+	const source = "https://pkgs.dev.azure.com/contoso/_packaging/feed/nuget/v3/index.json"
+	provider := NewProvider([]Plugin{plugin})
+	authenticator, err := provider.GetCredentials(context.Background(), source)
+	if err != nil {
+		t.Fatalf("GetCredentials() error = %v", err)
+	}
+	if authenticator == nil {
+		t.Fatal("GetCredentials() returned no credentials")
+	}
+
+	req := httptest.NewRequest("GET", source, nil)
+	if err := authenticator.Authenticate(req); err != nil {
+		t.Fatal(err)
+	}
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("VssSessionToken:token-for-"+source))
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+
+	// The token is kept for the session: the plugin is not started again
+	if _, err := provider.GetCredentials(context.Background(), source+"/"); err != nil {
+		t.Fatalf("second GetCredentials() error = %v", err)
+	}
+	data, err := os.ReadFile(launches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "launched"); n != 1 {
+		t.Errorf("plugin launched %d times, want 1", n)
+	}
+}
+
+func TestProvider_NoCredentials(t *testing.T) {
+	for _, mode := range []string{"notfound", "noclaims"} {
+		t.Run(mode, func(t *testing.T) {
+			provider := NewProvider([]Plugin{fakePlugin(t, mode)})
+			authenticator, err := provider.GetCredentials(context.Background(), "https://example.com/index.json")
+			if err != nil || authenticator != nil {
+				t.Errorf("GetCredentials() = %v, %v, want no credentials and no error", authenticator, err)
+			}
+		})
+	}
+}
+
+func TestProvider_PluginErrors(t *testing.T) {
+	provider := NewProvider([]Plugin{fakePlugin(t, "error")})
+	_, err := provider.GetCredentials(context.Background(), "https://example.com/index.json")
+	if err == nil || !strings.Contains(err.Error(), "no session token") {
+		t.Errorf("GetCredentials() error = %v, want the plugin's message", err)
+	}
+
+	// A plugin that cannot be started is reported too
+	provider = NewProvider([]Plugin{{Path: filepath.Join(t.TempDir(), "missing-plugin")}})
+	if _, err := provider.GetCredentials(context.Background(), "https://example.com/index.json"); err == nil {
+		t.Error("GetCredentials() succeeded with a missing plugin")
+	}
+}
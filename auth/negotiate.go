@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrNegotiateUnsupported is returned when a source requires Windows integrated
+// authentication on a platform without SSPI.
+var ErrNegotiateUnsupported = errors.New("Windows integrated authentication (Negotiate/NTLM) is only supported on Windows; " + //nolint:staticcheck // names the feature
+	"configure a personal access token as the source password instead")
+
+// maxNegotiateLegs bounds the requests of one handshake. Kerberos needs one, NTLM two.
+const maxNegotiateLegs = 3
+
+// securityContext produces the tokens of one Negotiate or NTLM handshake.
+type securityContext interface {
+	// Step returns the next token for the server given the server's last token (nil at first).
+	Step(input []byte) ([]byte, error)
+	// Close releases the context.
+	Close()
+}
+
+// NegotiateAuthenticator implements Windows integrated authentication with the identity
+// of the current process, so no password is configured or sent. Negotiate and NTLM are
+// handshakes rather than headers computed up front: Authenticate adds nothing, and the
+// round tripper returned by Transport answers a source's challenge.
+type NegotiateAuthenticator struct {
+	// newContext starts a security context for a scheme and host; nil without SSPI
+	newContext func(scheme, host string) (securityContext, error)
+}
+
+// NewNegotiateAuthenticator creates an authenticator for the current process identity.
+func NewNegotiateAuthenticator() *NegotiateAuthenticator {
+	return &NegotiateAuthenticator{newContext: sspiSecurityContext}
+}
+
+// Authenticate adds nothing; credentials are exchanged by the Transport handshake.
+func (a *NegotiateAuthenticator) Authenticate(*http.Request) error {
+	return nil
+}
+
+// Type returns the authentication type.
+func (a *NegotiateAuthenticator) Type() Type {
+	return AuthTypeNegotiate
+}
+
+// Transport returns a round tripper that sends every request through base with a scheme
+// ("Negotiate" or "NTLM") handshake. Because it sits below http.Client, a request that is
+// redirected handshakes again with the host it lands on.
+// Returns ErrNegotiateUnsupported on platforms other than Windows.
+func (a *NegotiateAuthenticator) Transport(scheme string, base http.RoundTripper) (http.RoundTripper, error) {
+	if a.newContext == nil {
+		return nil, ErrNegotiateUnsupported
+	}
+	return &negotiateTransport{scheme: scheme, base: base, newContext: a.newContext}, nil
+}
+
+// NegotiateScheme returns the Windows integrated authentication scheme a 401 response
+// offers in WWW-Authenticate: "Negotiate", preferred, "NTLM", or "" when it offers neither.
+func NegotiateScheme(resp *http.Response) string {
+	for _, scheme := range []string{"Negotiate", "NTLM"} {
+		if _, ok := challengeToken(resp, scheme); ok {
+			return scheme
+		}
+	}
+	return ""
+}
+
+// challengeToken returns the token of the scheme's challenge in WWW-Authenticate, nil
+// when the challenge has none, and whether the scheme is offered at all.
+func challengeToken(resp *http.Response, scheme string) ([]byte, bool) {
+	for _, header := range resp.Header.Values("WWW-Authenticate") {
+		for challenge := range strings.SplitSeq(header, ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+			if !strings.EqualFold(name, scheme) {
+				continue
+			}
+			token, err := base64.StdEncoding.DecodeString(strings.TrimSpace(param))
+			if err != nil || len(token) == 0 {
+				return nil, true
+			}
+			return token, true
+		}
+	}
+	return nil, false
+}
+
+// negotiateTransport runs a handshake for every request it sends.
+type negotiateTransport struct {
+	scheme     string
+	base       http.RoundTripper
+	newContext func(scheme, host string) (securityContext, error)
+}
+
+// RoundTrip implements http.RoundTripper. Each leg of the handshake resends the request
+// with the next token until the server stops answering 401 with a token of its own.
+// NTLM authenticates the connection rather than the request, so the response of every
+// leg is drained to let the next leg reuse the connection.
+func (t *negotiateTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+	securityContext, err := t.newContext(t.scheme, host)
+	if err != nil {
+		return nil, fmt.Errorf("%s authentication with %s: %w", t.scheme, host, err)
+	}
+	defer securityContext.Close()
+
+	var input []byte
+	for leg := 1; ; leg++ {
+		token, err := securityContext.Step(input)
+		if err != nil {
+			return nil, fmt.Errorf("%s authentication with %s: %w", t.scheme, host, err)
+		}
+
+		legReq := req.Clone(req.Context())
+		if req.GetBody != nil && leg > 1 {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("rewind request body: %w", err)
+			}
+			legReq.Body = body
+		}
+		legReq.Header.Set("Authorization", t.scheme+" "+base64.StdEncoding.EncodeToString(token))
+
+		resp, err := t.base.RoundTrip(legReq)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized || leg == maxNegotiateLegs {
+			return resp, err
+		}
+		// A challenge without a token rejects the identity
+		if input, _ = challengeToken(resp, t.scheme); input == nil {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !windows
+
+package auth
+
+// sspiSecurityContext is nil outside Windows, which has no SSPI to run the handshake with
+// the identity of the process.
+var sspiSecurityContext func(scheme, host string) (securityContext, error)
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSecurityContext plays the client side of a two-leg NTLM handshake: a negotiate
+// message, then an authenticate message answering the server's challenge.
+type fakeSecurityContext struct {
+	closed *bool
+}
+
+func (c *fakeSecurityContext) Step(input []byte) ([]byte, error) {
+	switch string(input) {
+	case "":
+		return []byte("negotiate"), nil
+	case "challenge":
+		return []byte("authenticate"), nil
+	default:
+		return nil, errors.New("unexpected server token " + string(input))
+	}
+}
+
+func (c *fakeSecurityContext) Close() {
+	*c.closed = true
+}
+
+// ntlmServer answers like IIS with Windows authentication: the connection that completes
+// the handshake is authenticated, so the authenticate message must arrive on the
+// connection that received the challenge. It records the Authorization headers it gets.
+type ntlmServer struct {
+	mu         sync.Mutex
+	challenged map[string]bool // Remote addresses sent a challenge
+	headers    []string
+	bodies     []string
+}
+
+func (s *ntlmServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.headers = append(s.headers, r.URL.Path+" "+r.Header.Get("Authorization"))
+	s.bodies = append(s.bodies, string(body))
+
+	switch r.Header.Get("Authorization") {
+	case "NTLM " + base64.StdEncoding.EncodeToString([]byte("negotiate")):
+		s.challenged[r.RemoteAddr] = true
+		w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString([]byte("challenge")))
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("challenge body"))
+	case "NTLM " + base64.StdEncoding.EncodeToString([]byte("authenticate")):
+		if !s.challenged[r.RemoteAddr] {
+			w.Header().Set("WWW-Authenticate", "NTLM")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusTemporaryRedirect)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	default:
+		w.Header().Add("WWW-Authenticate", "NTLM")
+		w.Header().Add("WWW-Authenticate", `Basic realm="feed"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+}
+
+func newNTLMServer(t *testing.T) (*httptest.Server, *ntlmServer) {
+	t.Helper()
+	feed := &ntlmServer{challenged: make(map[string]bool)}
+	server := httptest.NewServer(feed)
+	t.Cleanup(server.Close)
+	return server, feed
+}
+
+func fakeNegotiator(closed *bool) *NegotiateAuthenticator {
+	return &NegotiateAuthenticator{newContext: func(scheme, host string) (securityContext, error) {
+		return &fakeSecurityContext{closed: closed}, nil
+	}}
+}
+
+func TestNegotiateTransport_Handshake(t *testing.T) {
+	server, feed := newNTLMServer(t)
+	closed := false
+	transport, err := fakeNegotiator(&closed).Transport("NTLM", http.DefaultTransport)
+	if err != nil {
+		t.Fatalf("Transport() error = %v", err)
+	}
+	client := &http.Client{Transport: transport}
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/old", bytes.NewReader([]byte("package")))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("response = %d %q, want 200 ok", resp.StatusCode, body)
+	}
+
+	// The redirect target handshakes again, and every leg resends the body
+	negotiate := "NTLM " + base64.StdEncoding.EncodeToString([]byte("negotiate"))
+	authenticate := "NTLM " + base64.StdEncoding.EncodeToString([]byte("authenticate"))
+	want := []string{
+		"/old " + negotiate, "/old " + authenticate,
+		"/new " + negotiate, "/new " + authenticate,
+	}
+	if strings.Join(feed.headers, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", strings.Join(feed.headers, "\n"), strings.Join(want, "\n"))
+	}
+	for i, body := range feed.bodies {
+		if body != "package" {
+			t.Errorf("request %d body = %q, want the package", i, body)
+		}
+	}
+	if !closed {
+		t.Error("security context not closed")
+	}
+}
+
+func TestNegotiateTransport_Rejected(t *testing.T) {
+	var rejected bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rejected = r.Header.Get("Authorization") != ""
+		w.Header().Set("WWW-Authenticate", "Negotiate")
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+
+	closed := false
+	transport, err := fakeNegotiator(&closed).Transport("Negotiate", http.DefaultTransport)
+	if err != nil {
+		t.Fatalf("Transport() error = %v", err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || !rejected {
+		t.Errorf("status = %d after sending a token, want the server's 401", resp.StatusCode)
+	}
+}
+
+func TestNegotiateScheme(t *testing.T) {
+	tests := []struct {
+		headers []string
+		want    string
+	}{
+		{[]string{"Negotiate, NTLM"}, "Negotiate"},
+		{[]string{"NTLM", "Negotiate"}, "Negotiate"},
+		{[]string{`Basic realm="TFS"`, "NTLM"}, "NTLM"},
+		{[]string{"ntlm TlRMTVNTUAAC"}, "NTLM"},
+		{[]string{`Bearer authorization_uri="https://login.example"`, `Basic realm="feed"`}, ""},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{"Www-Authenticate": tt.headers}}
+		if got := NegotiateScheme(resp); got != tt.want {
+			t.Errorf("NegotiateScheme(%q) = %q, want %q", tt.headers, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiateAuthenticator_AddsNoHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://tfs.example/nuget/index.json", nil)
+	if err := NewNegotiateAuthenticator().Authenticate(req); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if got := req.Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q, want none before the handshake", got)
+	}
+	if got := NewNegotiateAuthenticator().Type(); got != AuthTypeNegotiate {
+		t.Errorf("Type() = %q, want %q", got, AuthTypeNegotiate)
+	}
+}
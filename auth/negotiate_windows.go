@@ -0,0 +1,153 @@
+//go:build windows
+
+package auth
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	secur32                        = windows.NewLazySystemDLL("secur32.dll")
+	procAcquireCredentialsHandleW  = secur32.NewProc("AcquireCredentialsHandleW")
+	procInitializeSecurityContextW = secur32.NewProc("InitializeSecurityContextW")
+	procDeleteSecurityContext      = secur32.NewProc("DeleteSecurityContext")
+	procFreeCredentialsHandle      = secur32.NewProc("FreeCredentialsHandle")
+	procFreeContextBuffer          = secur32.NewProc("FreeContextBuffer")
+)
+
+const (
+	// AcquireCredentialsHandle credential use
+	secpkgCredOutbound = 2
+
+	// InitializeSecurityContext requirements and data representation
+	iscReqAllocateMemory = 0x00000100
+	iscReqConnection     = 0x00000800
+	securityNativeDrep   = 0x00000010
+
+	// SecBuffer type and SecBufferDesc version
+	secbufferToken   = 2
+	secbufferVersion = 0
+
+	// SECURITY_STATUS values
+	secEOK             = 0
+	secIContinueNeeded = 0x00090312
+)
+
+// sspiSecurityContext runs the handshake with the SSPI security package of the scheme,
+// which is named like the scheme ("Negotiate" or "NTLM").
+var sspiSecurityContext = newSSPIContext
+
+// secHandle is CredHandle and CtxtHandle.
+type secHandle struct {
+	lower uintptr
+	upper uintptr
+}
+
+// secBuffer is SecBuffer.
+type secBuffer struct {
+	size       uint32
+	bufferType uint32
+	buffer     *byte
+}
+
+// secBufferDesc is SecBufferDesc.
+type secBufferDesc struct {
+	version uint32
+	count   uint32
+	buffers *secBuffer
+}
+
+// sspiContext is an SSPI client security context for the current process identity.
+type sspiContext struct {
+	credentials secHandle
+	context     secHandle
+	hasContext  bool
+	target      *uint16
+}
+
+// newSSPIContext acquires the outbound credentials of the current user for the scheme's
+// package, targeting the HTTP service principal of host.
+func newSSPIContext(scheme, host string) (securityContext, error) {
+	if err := secur32.Load(); err != nil {
+		return nil, err
+	}
+	pkg, err := windows.UTF16PtrFromString(scheme)
+	if err != nil {
+		return nil, err
+	}
+	target, err := windows.UTF16PtrFromString("HTTP/" + host)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &sspiContext{target: target}
+	var expiry int64
+	status, _, _ := procAcquireCredentialsHandleW.Call(
+		0, // the current user
+		uintptr(unsafe.Pointer(pkg)),
+		secpkgCredOutbound,
+		0, 0, 0, 0,
+		uintptr(unsafe.Pointer(&c.credentials)),
+		uintptr(unsafe.Pointer(&expiry)))
+	if status != secEOK {
+		return nil, fmt.Errorf("acquire %s credentials: %w", scheme, windows.Errno(status))
+	}
+	return c, nil
+}
+
+// Step calls InitializeSecurityContext with the server's last token.
+func (c *sspiContext) Step(input []byte) ([]byte, error) {
+	var in *secBufferDesc
+	if len(input) > 0 {
+		in = &secBufferDesc{
+			version: secbufferVersion,
+			count:   1,
+			buffers: &secBuffer{size: uint32(len(input)), bufferType: secbufferToken, buffer: &input[0]},
+		}
+	}
+	outBuffer := &secBuffer{bufferType: secbufferToken}
+	out := &secBufferDesc{version: secbufferVersion, count: 1, buffers: outBuffer}
+
+	var context *secHandle
+	if c.hasContext {
+		context = &c.context
+	}
+	var attributes uint32
+	var expiry int64
+	status, _, _ := procInitializeSecurityContextW.Call(
+		uintptr(unsafe.Pointer(&c.credentials)),
+		uintptr(unsafe.Pointer(context)),
+		uintptr(unsafe.Pointer(c.target)),
+		iscReqAllocateMemory|iscReqConnection,
+		0,
+		securityNativeDrep,
+		uintptr(unsafe.Pointer(in)),
+		0,
+		uintptr(unsafe.Pointer(&c.context)),
+		uintptr(unsafe.Pointer(out)),
+		uintptr(unsafe.Pointer(&attributes)),
+		uintptr(unsafe.Pointer(&expiry)))
+	runtime.KeepAlive(input)
+	if status != secEOK && status != secIContinueNeeded {
+		return nil, fmt.Errorf("initialize security context: %w", windows.Errno(status))
+	}
+	c.hasContext = true
+
+	if outBuffer.buffer == nil {
+		return nil, nil
+	}
+	defer func() { _, _, _ = procFreeContextBuffer.Call(uintptr(unsafe.Pointer(outBuffer.buffer))) }()
+	return append([]byte(nil), unsafe.Slice(outBuffer.buffer, outBuffer.size)...), nil
+}
+
+// Close deletes the security context and frees the credentials.
+func (c *sspiContext) Close() {
+	if c.hasContext {
+		_, _, _ = procDeleteSecurityContext.Call(uintptr(unsafe.Pointer(&c.context)))
+	}
+	_, _, _ = procFreeCredentialsHandle.Call(uintptr(unsafe.Pointer(&c.credentials)))
+}
@@ -2,6 +2,7 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 )
 
@@ -23,4 +24,29 @@ const (
 	AuthTypeBearer Type = "bearer"
 	// AuthTypeBasic indicates HTTP basic authentication.
 	AuthTypeBasic Type = "basic"
+	// AuthTypeNegotiate indicates Windows integrated authentication (Negotiate or NTLM).
+	AuthTypeNegotiate Type = "negotiate"
 )
+
+// CredentialProvider supplies credentials for a package source, for example from
+// NuGet.Config, an interactive prompt or a device flow.
+// Returning a nil Authenticator and nil error means no credentials are available.
+type CredentialProvider interface {
+	// GetCredentials returns an authenticator for sourceURL
+	GetCredentials(ctx context.Context, sourceURL string) (Authenticator, error)
+}
+
+// CredentialProviders asks each provider in turn and returns the first credentials found.
+type CredentialProviders []CredentialProvider
+
+// GetCredentials returns the credentials of the first provider that has some for sourceURL.
+// An error from a provider stops the search.
+func (p CredentialProviders) GetCredentials(ctx context.Context, sourceURL string) (Authenticator, error) {
+	for _, provider := range p {
+		authenticator, err := provider.GetCredentials(ctx, sourceURL)
+		if err != nil || authenticator != nil {
+			return authenticator, err
+		}
+	}
+	return nil, nil
+}
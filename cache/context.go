@@ -70,3 +70,12 @@ func FromContext(ctx context.Context) *SourceCacheContext {
 	}
 	return nil
 }
+
+// FromContextOrNew retrieves the source cache context from the Go context,
+// or creates one with defaults if none was set.
+func FromContextOrNew(ctx context.Context) *SourceCacheContext {
+	if cacheCtx := FromContext(ctx); cacheCtx != nil {
+		return cacheCtx
+	}
+	return NewSourceCacheContext()
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -67,3 +68,14 @@ func TestSourceCacheContext_SessionIDUniqueness(t *testing.T) {
 		t.Error("SessionID should not be empty")
 	}
 }
+
+func TestFromContextOrNew(t *testing.T) {
+	if got := FromContextOrNew(context.Background()); got == nil || got.NoCache {
+		t.Errorf("FromContextOrNew() without context value = %+v, want defaults", got)
+	}
+
+	noCache := &SourceCacheContext{NoCache: true}
+	if got := FromContextOrNew(WithCacheContext(context.Background(), noCache)); got != noCache {
+		t.Errorf("FromContextOrNew() = %+v, want the context value", got)
+	}
+}
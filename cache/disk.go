@@ -3,6 +3,7 @@ package cache
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +11,9 @@ import (
 	"slices"
 	"strings"
 	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/willibrandon/gonuget/observability"
 )
 
 const (
@@ -31,6 +35,7 @@ const (
 type DiskCache struct {
 	rootDir string
 	maxSize int64
+	logger  observability.Logger
 }
 
 // NewDiskCache creates a new disk cache.
@@ -41,6 +46,7 @@ func NewDiskCache(rootDir string, maxSize int64) (*DiskCache, error) {
 		return &DiskCache{
 			rootDir: "",
 			maxSize: maxSize,
+			logger:  observability.DefaultLogger(),
 		}, nil
 	}
 
@@ -52,9 +58,19 @@ func NewDiskCache(rootDir string, maxSize int64) (*DiskCache, error) {
 	return &DiskCache{
 		rootDir: rootDir,
 		maxSize: maxSize,
+		logger:  observability.DefaultLogger(),
 	}, nil
 }
 
+// SetLogger sets the logger used to report discarded cache entries.
+// A nil logger disables logging.
+func (dc *DiskCache) SetLogger(logger observability.Logger) {
+	if logger == nil {
+		logger = observability.NewNullLogger()
+	}
+	dc.logger = logger
+}
+
 // ComputeHash computes a hash for the given value.
 // Matches NuGet.Client's CachingUtility.ComputeHash exactly.
 func ComputeHash(value string, addIdentifiableCharacters bool) string {
@@ -146,6 +162,7 @@ func (dc *DiskCache) GetCachePath(sourceURL string, cacheKey string) (cacheFile
 
 // Get retrieves a cached file if it exists and is not expired.
 // Returns (reader, true) if found and valid, (nil, false) otherwise.
+// Entries that don't match their checksum are deleted and reported as a miss.
 // In NoCache mode (empty rootDir), always returns cache miss.
 func (dc *DiskCache) Get(sourceURL string, cacheKey string, maxAge time.Duration) (io.ReadCloser, bool, error) {
 	// NoCache mode: always return miss
@@ -156,7 +173,11 @@ func (dc *DiskCache) Get(sourceURL string, cacheKey string, maxAge time.Duration
 	cacheFile, _ := dc.GetCachePath(sourceURL, cacheKey)
 
 	// Check if file exists and is not expired
-	reader, valid := readCacheFile(maxAge, cacheFile)
+	reader, valid, err := readCacheFile(maxAge, cacheFile)
+	if errors.Is(err, ErrCorruptCacheEntry) {
+		dc.invalidateFile(cacheFile, err)
+		return nil, false, nil
+	}
 	if reader == nil {
 		return nil, false, nil
 	}
@@ -165,27 +186,50 @@ func (dc *DiskCache) Get(sourceURL string, cacheKey string, maxAge time.Duration
 }
 
 // readCacheFile reads a cache file if it's not expired.
-// Matches NuGet.Client's CachingUtility.ReadCacheFile.
-func readCacheFile(maxAge time.Duration, cacheFile string) (io.ReadCloser, bool) {
+// Matches NuGet.Client's CachingUtility.ReadCacheFile, with the addition of
+// checksum verification for entries written by gonuget.
+func readCacheFile(maxAge time.Duration, cacheFile string) (io.ReadCloser, bool, error) {
 	fileInfo, err := os.Stat(cacheFile)
 	if err != nil {
-		return nil, false
+		return nil, false, nil
 	}
 
 	// Check age
 	age := time.Since(fileInfo.ModTime())
 	if age >= maxAge {
-		return nil, false
+		return nil, false, nil
 	}
 
-	// Open file with appropriate sharing flags
-	// FileShare.Read | FileShare.Delete matches NuGet.Client
-	file, err := os.OpenFile(cacheFile, os.O_RDONLY, 0644)
+	// Open file and verify it against its checksum
+	reader, err := openVerifiedCacheFile(cacheFile)
 	if err != nil {
-		return nil, false
+		if errors.Is(err, ErrCorruptCacheEntry) {
+			return nil, false, err
+		}
+		return nil, false, nil
+	}
+
+	return reader, true, nil
+}
+
+// Invalidate removes a cache entry whose payload could not be used (for example,
+// because it failed to deserialize) so the next lookup refetches from the network.
+func (dc *DiskCache) Invalidate(sourceURL string, cacheKey string, reason error) {
+	if dc == nil || dc.rootDir == "" {
+		return
 	}
 
-	return file, true
+	cacheFile, _ := dc.GetCachePath(sourceURL, cacheKey)
+	dc.invalidateFile(cacheFile, reason)
+}
+
+// invalidateFile deletes a bad cache file and logs it at debug (detailed) level.
+func (dc *DiskCache) invalidateFile(cacheFile string, reason error) {
+	dc.logger.Debug("Discarding invalid HTTP cache entry {Path}: {Reason}", cacheFile, reason)
+	if err := os.Remove(cacheFile); err != nil && !os.IsNotExist(err) {
+		dc.logger.Debug("Failed to delete invalid HTTP cache entry {Path}: {Error}", cacheFile, err)
+	}
+	_ = os.Remove(integrityFilePath(cacheFile))
 }
 
 // Set writes data to the cache using atomic two-phase update.
@@ -217,8 +261,10 @@ func (dc *DiskCache) Set(sourceURL string, cacheKey string, data io.Reader, vali
 	// Temp file is already open from CreateTemp
 	defer func() { _ = tempFile.Close() }()
 
-	// Copy data to temp file
-	if _, err := io.CopyBuffer(tempFile, data, make([]byte, BufferSize)); err != nil {
+	// Copy data to temp file, hashing the payload for the integrity file
+	digest := xxhash.New()
+	length, err := io.CopyBuffer(io.MultiWriter(tempFile, digest), data, make([]byte, BufferSize))
+	if err != nil {
 		return fmt.Errorf("write temp file: %w", err)
 	}
 
@@ -237,15 +283,35 @@ func (dc *DiskCache) Set(sourceURL string, cacheKey string, data io.Reader, vali
 		return fmt.Errorf("close temp file: %w", err)
 	}
 
+	// The checksum of the entry being replaced doesn't describe the new one
+	integrityFile := integrityFilePath(cacheFile)
+	_ = os.Remove(integrityFile)
+
+	moved, err := moveCacheFile(newFile, cacheFile)
+	if err != nil || !moved {
+		return err
+	}
+
+	// Written after the entry: a reader in between sees an entry without a checksum,
+	// which is accepted like one written by dotnet
+	if err := writeIntegrityFile(integrityFile, encodeIntegrityRecord(length, digest.Sum64())); err != nil {
+		dc.logger.Debug("Failed to write HTTP cache checksum {Path}: {Error}", integrityFile, err)
+	}
+	return nil
+}
+
+// moveCacheFile moves a completed temp file to the cache location. Returns false if
+// another writer placed the entry instead.
+func moveCacheFile(newFile, cacheFile string) (bool, error) {
 	// Phase 2: Atomic move to final location
 	// This matches NuGet.Client's two-phase update pattern:
 	// 1. Delete old file (if not already open)
 	// 2. Move new file to cache location
 
 	// Try atomic rename first (works on Unix, may fail on Windows if destination exists)
-	err = os.Rename(newFile, cacheFile)
+	err := os.Rename(newFile, cacheFile)
 	if err == nil {
-		return nil // Success
+		return true, nil // Success
 	}
 
 	// On Windows, rename fails if destination exists. Try remove then rename.
@@ -261,21 +327,41 @@ func (dc *DiskCache) Set(sourceURL string, cacheKey string, data io.Reader, vali
 				if fileExists(cacheFile) {
 					// Another goroutine completed successfully, clean up our temp file
 					_ = os.Remove(newFile)
-					return nil
+					return false, nil
 				}
 				// Neither us nor another goroutine succeeded - this is an error
-				return fmt.Errorf("move cache file: %w", err)
+				return false, fmt.Errorf("move cache file: %w", err)
 			}
-			return nil // Our retry succeeded
+			return true, nil // Our retry succeeded
 		}
 		// File exists but is open - clean up temp and let the other writer finish
 		_ = os.Remove(newFile)
-		return nil
+		return false, nil
 	}
 
 	// File doesn't exist and rename failed - this is an error
 	_ = os.Remove(newFile)
-	return fmt.Errorf("rename failed and destination does not exist: %w", err)
+	return false, fmt.Errorf("rename failed and destination does not exist: %w", err)
+}
+
+// writeIntegrityFile writes the checksum of a cache entry through a temp file, so a
+// reader never sees a partial one.
+func writeIntegrityFile(path string, record []byte) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+"-new.*")
+	if err != nil {
+		return err
+	}
+	_, err = tempFile.Write(record)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tempFile.Name())
+	}
+	return err
 }
 
 // fileExists checks if a file exists.
@@ -305,8 +391,9 @@ func isFileAlreadyOpen(filePath string) bool {
 func (dc *DiskCache) Delete(sourceURL string, cacheKey string) error {
 	cacheFile, newFile := dc.GetCachePath(sourceURL, cacheKey)
 
-	// Remove both cache file and temp file if they exist
+	// Remove the cache file, its checksum and the temp file if they exist
 	_ = os.Remove(cacheFile)
+	_ = os.Remove(integrityFilePath(cacheFile))
 	_ = os.Remove(newFile)
 
 	return nil
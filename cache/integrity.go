@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// IntegrityFileExtension is appended to the path of a cache file to name the file that
+// holds its checksum. The checksum lives next to the entry rather than inside it because
+// the HTTP cache is shared with dotnet, which reads the .dat files as-is.
+const IntegrityFileExtension = ".xxh64"
+
+// integrityMagic starts an integrity file written by gonuget.
+var integrityMagic = [8]byte{'G', 'N', 'U', 'G', 'C', 'F', 'v', '1'}
+
+// integrityRecordSize is the size of an integrity file:
+// 8-byte magic, 8-byte little-endian payload length, 8-byte little-endian xxhash64.
+const integrityRecordSize = 24
+
+// ErrCorruptCacheEntry indicates a cache entry failed its integrity check.
+var ErrCorruptCacheEntry = errors.New("corrupt cache entry")
+
+// integrityFilePath returns the path of the integrity file for a cache file.
+func integrityFilePath(cacheFile string) string {
+	return cacheFile + IntegrityFileExtension
+}
+
+// encodeIntegrityRecord builds the integrity file content for a payload of the given length and hash.
+func encodeIntegrityRecord(length int64, sum uint64) []byte {
+	record := make([]byte, integrityRecordSize)
+	copy(record[:8], integrityMagic[:])
+	binary.LittleEndian.PutUint64(record[8:16], uint64(length))
+	binary.LittleEndian.PutUint64(record[16:24], sum)
+	return record
+}
+
+// openVerifiedCacheFile opens a cache file and checks it against its integrity file if
+// there is one. Files without an integrity file (written by dotnet) are returned
+// unchanged. ErrCorruptCacheEntry is returned when the recorded length or hash does not
+// match the file, which includes an entry dotnet rewrote after gonuget cached it.
+func openVerifiedCacheFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyIntegrity(file, integrityFilePath(path)); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// verifyIntegrity checks an open cache file against the integrity file at integrityPath.
+func verifyIntegrity(file *os.File, integrityPath string) error {
+	record, err := os.ReadFile(integrityPath)
+	if err != nil {
+		// No integrity file - entry written by dotnet (shared cache) or an older gonuget
+		return nil
+	}
+	if len(record) != integrityRecordSize || !bytes.Equal(record[:8], integrityMagic[:]) {
+		return fmt.Errorf("%w: invalid integrity file %s", ErrCorruptCacheEntry, integrityPath)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	recordedLength := int64(binary.LittleEndian.Uint64(record[8:16]))
+	if recordedLength != info.Size() {
+		return fmt.Errorf("%w: length %d, integrity file records %d", ErrCorruptCacheEntry, info.Size(), recordedLength)
+	}
+
+	digest := xxhash.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return fmt.Errorf("hash cache entry: %w", err)
+	}
+	if digest.Sum64() != binary.LittleEndian.Uint64(record[16:24]) {
+		return fmt.Errorf("%w: hash mismatch", ErrCorruptCacheEntry)
+	}
+
+	return nil
+}
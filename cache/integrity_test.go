@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskCache_SetWritesIntegrityFile(t *testing.T) {
+	dc, err := NewDiskCache(t.TempDir(), 1024*1024)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	sourceURL := "https://api.nuget.org/v3/index.json"
+	data := []byte(`{"version":"3.0.0"}`)
+	if err := dc.Set(sourceURL, "checksum", bytes.NewReader(data), nil); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// The entry itself must stay readable by dotnet, which shares the HTTP cache
+	cacheFile, _ := dc.GetCachePath(sourceURL, "checksum")
+	raw, err := os.ReadFile(cacheFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !bytes.Equal(raw, data) {
+		t.Errorf("cache file = %s, want %s", raw, data)
+	}
+
+	record, err := os.ReadFile(cacheFile + IntegrityFileExtension)
+	if err != nil {
+		t.Fatalf("ReadFile() integrity file error = %v", err)
+	}
+	if len(record) != integrityRecordSize {
+		t.Errorf("integrity file size = %d, want %d", len(record), integrityRecordSize)
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(cacheFile))
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("cache folder has %d files, want the entry and its integrity file", len(entries))
+	}
+}
+
+func TestDiskCache_GetDetectsCorruption(t *testing.T) {
+	data := []byte(`{"count":1,"items":[{"lower":"1.0.0","upper":"2.0.0"}]}`)
+
+	tests := []struct {
+		name    string
+		corrupt func(raw []byte) []byte
+	}{
+		{
+			name: "truncated payload",
+			corrupt: func(raw []byte) []byte {
+				return raw[:10]
+			},
+		},
+		{
+			name: "flipped bit",
+			corrupt: func(raw []byte) []byte {
+				raw[5] ^= 0x01
+				return raw
+			},
+		},
+		{
+			name: "appended garbage",
+			corrupt: func(raw []byte) []byte {
+				return append(raw, "xyz"...)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc, err := NewDiskCache(t.TempDir(), 1024*1024)
+			if err != nil {
+				t.Fatalf("NewDiskCache() error = %v", err)
+			}
+
+			sourceURL := "https://api.nuget.org/v3/registration5-gz-semver2/pkg/index.json"
+			if err := dc.Set(sourceURL, "list_pkg", bytes.NewReader(data), nil); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			cacheFile, _ := dc.GetCachePath(sourceURL, "list_pkg")
+			raw, err := os.ReadFile(cacheFile)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if err := os.WriteFile(cacheFile, tt.corrupt(raw), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			reader, ok, err := dc.Get(sourceURL, "list_pkg", time.Hour)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if ok || reader != nil {
+				t.Fatal("Get() expected cache miss for corrupt entry")
+			}
+			if _, err := os.Stat(cacheFile); !os.IsNotExist(err) {
+				t.Errorf("corrupt cache file should be deleted, stat error = %v", err)
+			}
+			if _, err := os.Stat(cacheFile + IntegrityFileExtension); !os.IsNotExist(err) {
+				t.Errorf("integrity file should be deleted, stat error = %v", err)
+			}
+		})
+	}
+}
+
+func TestDiskCache_GetAcceptsEntryWithoutIntegrityFile(t *testing.T) {
+	dc, err := NewDiskCache(t.TempDir(), 1024*1024)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	// Simulate an entry written by dotnet into the shared HTTP cache
+	sourceURL := "https://api.nuget.org/v3/index.json"
+	data := []byte(`{"version":"3.0.0","resources":[]}`)
+	cacheFile, _ := dc.GetCachePath(sourceURL, "service_index")
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(cacheFile, data, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	reader, ok, err := dc.Get(sourceURL, "service_index", time.Hour)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !ok {
+		t.Fatal("Get() expected cache hit for entry without integrity file")
+	}
+	defer func() { _ = reader.Close() }()
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Get() = %s, want %s", got, data)
+	}
+}
+
+func TestDiskCache_Invalidate(t *testing.T) {
+	dc, err := NewDiskCache(t.TempDir(), 1024*1024)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	sourceURL := "https://api.nuget.org/v3/index.json"
+	if err := dc.Set(sourceURL, "key", bytes.NewReader([]byte("not json")), nil); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	dc.Invalidate(sourceURL, "key", errors.New("invalid character 'o' in literal null"))
+
+	_, ok, err := dc.Get(sourceURL, "key", time.Hour)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if ok {
+		t.Error("Get() expected cache miss after Invalidate")
+	}
+
+	// Invalidating a missing entry or a nil cache is a no-op
+	dc.Invalidate(sourceURL, "missing", nil)
+	var nilCache *DiskCache
+	nilCache.Invalidate(sourceURL, "key", nil)
+}
+
+func TestDiskCache_SetReplacesIntegrityFile(t *testing.T) {
+	dc, err := NewDiskCache(t.TempDir(), 1024*1024)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	sourceURL := "https://api.nuget.org/v3/index.json"
+	for _, data := range []string{`{"version":"1"}`, `{"version":"2.0.0"}`} {
+		if err := dc.Set(sourceURL, "key", bytes.NewReader([]byte(data)), nil); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+
+	reader, ok, err := dc.Get(sourceURL, "key", time.Hour)
+	if err != nil || !ok {
+		t.Fatalf("Get() = %v, %v, want cache hit", ok, err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	got, _ := io.ReadAll(reader)
+	if string(got) != `{"version":"2.0.0"}` {
+		t.Errorf("Get() = %s, want the second entry", got)
+	}
+}
+
+func TestOpenVerifiedCacheFile_CorruptReturnsSentinel(t *testing.T) {
+	payload := []byte("payload")
+	path := filepath.Join(t.TempDir(), "bad.dat")
+	if err := os.WriteFile(path, payload, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		record []byte
+	}{
+		{"hash mismatch", encodeIntegrityRecord(int64(len(payload)), 42)},
+		{"unrecognized integrity file", []byte("not a checksum")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path+IntegrityFileExtension, tt.record, 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+
+			_, err := openVerifiedCacheFile(path)
+			if !errors.Is(err, ErrCorruptCacheEntry) {
+				t.Errorf("openVerifiedCacheFile() error = %v, want ErrCorruptCacheEntry", err)
+			}
+		})
+	}
+}
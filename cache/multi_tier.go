@@ -24,10 +24,17 @@ func NewMultiTierCache(l1 *MemoryCache, l2 *DiskCache) *MultiTierCache {
 	}
 }
 
+// memoryCacheKey scopes an L1 key to its source. The memory tier is shared by all
+// sources, so keys like "service_index" must not collide between feeds.
+// The disk tier is already scoped by source folder.
+func memoryCacheKey(sourceURL, cacheKey string) string {
+	return sourceURL + " " + cacheKey
+}
+
 // Get retrieves from L1 first, then L2, promoting to L1 on L2 hit.
 func (mtc *MultiTierCache) Get(ctx context.Context, sourceURL string, cacheKey string, maxAge time.Duration) ([]byte, bool, error) {
 	// Check L1 (memory cache)
-	if data, ok := mtc.l1.Get(cacheKey); ok {
+	if data, ok := mtc.l1.Get(memoryCacheKey(sourceURL, cacheKey)); ok {
 		observability.CacheHitsTotal.WithLabelValues("memory").Inc()
 		return data, true, nil
 	}
@@ -56,7 +63,7 @@ func (mtc *MultiTierCache) Get(ctx context.Context, sourceURL string, cacheKey s
 	observability.CacheMissesTotal.WithLabelValues("memory").Inc()
 
 	// Promote to L1
-	mtc.l1.Set(cacheKey, data, maxAge)
+	mtc.l1.Set(memoryCacheKey(sourceURL, cacheKey), data, maxAge)
 
 	return data, true, nil
 }
@@ -70,12 +77,21 @@ func (mtc *MultiTierCache) Set(ctx context.Context, sourceURL string, cacheKey s
 	}
 
 	// Write to L1 (memory)
-	mtc.l1.Set(cacheKey, dataBytes, maxAge)
+	mtc.l1.Set(memoryCacheKey(sourceURL, cacheKey), dataBytes, maxAge)
 
 	// Write to L2 (disk) - use bytes.NewReader for validation
 	return mtc.l2.Set(sourceURL, cacheKey, bytes.NewReader(dataBytes), validate)
 }
 
+// Invalidate removes an entry from both tiers after its payload proved unusable
+// (for example, a deserialization failure), forcing the next lookup to refetch.
+func (mtc *MultiTierCache) Invalidate(ctx context.Context, sourceURL string, cacheKey string, reason error) {
+	mtc.l1.Delete(memoryCacheKey(sourceURL, cacheKey))
+	if mtc.l2 != nil {
+		mtc.l2.Invalidate(sourceURL, cacheKey, reason)
+	}
+}
+
 // Clear clears both caches.
 func (mtc *MultiTierCache) Clear() error {
 	mtc.l1.Clear()
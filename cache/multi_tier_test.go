@@ -22,7 +22,7 @@ func TestMultiTierCache_L1Hit(t *testing.T) {
 	mtc := NewMultiTierCache(l1, l2)
 
 	// Populate L1 only
-	l1.Set("test-key", []byte("L1 data"), 30*time.Minute)
+	l1.Set(memoryCacheKey("https://example.com", "test-key"), []byte("L1 data"), 30*time.Minute)
 
 	// Get should return L1 data without touching L2
 	ctx := context.Background()
@@ -69,7 +69,7 @@ func TestMultiTierCache_L2HitWithPromotion(t *testing.T) {
 	}
 
 	// Verify L1 is empty
-	if _, ok := l1.Get(cacheKey); ok {
+	if _, ok := l1.Get(memoryCacheKey(sourceURL, cacheKey)); ok {
 		t.Fatal("L1 should be empty initially")
 	}
 
@@ -86,7 +86,7 @@ func TestMultiTierCache_L2HitWithPromotion(t *testing.T) {
 	}
 
 	// Verify promotion to L1
-	l1Data, ok := l1.Get(cacheKey)
+	l1Data, ok := l1.Get(memoryCacheKey(sourceURL, cacheKey))
 	if !ok {
 		t.Fatal("Data should be promoted to L1")
 	}
@@ -159,7 +159,7 @@ func TestMultiTierCache_SetWritesBothTiers(t *testing.T) {
 	}
 
 	// Verify L1 has data
-	l1Data, ok := l1.Get(cacheKey)
+	l1Data, ok := l1.Get(memoryCacheKey(sourceURL, cacheKey))
 	if !ok {
 		t.Fatal("L1 should have data after Set()")
 	}
@@ -212,7 +212,7 @@ func TestMultiTierCache_ClearBothTiers(t *testing.T) {
 	}
 
 	// Verify both have data
-	if _, ok := l1.Get(cacheKey); !ok {
+	if _, ok := l1.Get(memoryCacheKey(sourceURL, cacheKey)); !ok {
 		t.Fatal("L1 should have data before clear")
 	}
 	reader, ok, err := l2.Get(sourceURL, cacheKey, 30*time.Minute)
@@ -231,7 +231,7 @@ func TestMultiTierCache_ClearBothTiers(t *testing.T) {
 	}
 
 	// Verify both are cleared
-	if _, ok := l1.Get(cacheKey); ok {
+	if _, ok := l1.Get(memoryCacheKey(sourceURL, cacheKey)); ok {
 		t.Error("L1 should be cleared")
 	}
 
@@ -280,7 +280,7 @@ func TestMultiTierCache_SetWithValidation(t *testing.T) {
 	}
 
 	// Verify data in both caches
-	if _, ok := l1.Get(cacheKey); !ok {
+	if _, ok := l1.Get(memoryCacheKey(sourceURL, cacheKey)); !ok {
 		t.Error("L1 should have data after successful validation")
 	}
 	reader, ok, _ := l2.Get(sourceURL, cacheKey, 30*time.Minute)
@@ -328,7 +328,7 @@ func TestMultiTierCache_L2ExpiredNotPromoted(t *testing.T) {
 	}
 
 	// Verify not promoted to L1
-	if _, ok := l1.Get(cacheKey); ok {
+	if _, ok := l1.Get(memoryCacheKey(sourceURL, cacheKey)); ok {
 		t.Error("Expired L2 entry should not be promoted to L1")
 	}
 }
@@ -406,7 +406,33 @@ func TestMultiTierCache_SetReadError(t *testing.T) {
 	}
 
 	// L1 should still have data even though L2 validation failed
-	if _, ok := l1.Get(cacheKey); !ok {
+	if _, ok := l1.Get(memoryCacheKey(sourceURL, cacheKey)); !ok {
 		t.Error("L1 should have data even when L2 validation fails")
 	}
 }
+
+func TestMultiTierCache_KeysScopedBySource(t *testing.T) {
+	l2, err := NewDiskCache(t.TempDir(), 1024*1024)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	mtc := NewMultiTierCache(NewMemoryCache(100, 1024*1024), l2)
+
+	ctx := context.Background()
+	if err := mtc.Set(ctx, "https://a.example/index.json", "service_index", bytes.NewReader([]byte("a")), time.Hour, nil); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := mtc.Set(ctx, "https://b.example/index.json", "service_index", bytes.NewReader([]byte("b")), time.Hour, nil); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	for source, want := range map[string]string{"https://a.example/index.json": "a", "https://b.example/index.json": "b"} {
+		data, ok, err := mtc.Get(ctx, source, "service_index", time.Hour)
+		if err != nil || !ok {
+			t.Fatalf("Get(%s) = ok %v, err %v", source, ok, err)
+		}
+		if string(data) != want {
+			t.Errorf("Get(%s) = %q, want %q", source, data, want)
+		}
+	}
+}
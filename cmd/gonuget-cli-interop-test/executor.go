@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -18,13 +19,17 @@ type CommandResult struct {
 	Success  bool
 }
 
-// ExecuteCommand executes a command and captures output
-func ExecuteCommand(executable string, args []string, workingDir string, timeout int) (*CommandResult, error) {
+// ExecuteCommand executes a command and captures output.
+// The command is killed when ctx is done or after timeout seconds, whichever comes first.
+func ExecuteCommand(ctx context.Context, executable string, args []string, workingDir string, timeout int) (*CommandResult, error) {
 	if timeout == 0 {
 		timeout = 30 // default 30 seconds
 	}
 
-	cmd := exec.Command(executable, args...)
+	cmdCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, executable, args...)
 	cmd.Dir = workingDir
 
 	var stdout, stderr bytes.Buffer
@@ -36,40 +41,36 @@ func ExecuteCommand(executable string, args []string, workingDir string, timeout
 		return nil, fmt.Errorf("failed to start command: %w", err)
 	}
 
-	// Wait with timeout
-	done := make(chan error, 1)
-	go func() {
-		done <- cmd.Wait()
-	}()
-
-	select {
-	case err := <-done:
-		exitCode := 0
-		if err != nil {
-			if exitErr, ok := err.(*exec.ExitError); ok {
-				exitCode = exitErr.ExitCode()
-			} else {
-				return nil, fmt.Errorf("command failed: %w", err)
-			}
+	err := cmd.Wait()
+	if cmdCtx.Err() != nil {
+		// The request's own deadline takes precedence over the command timeout
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("command %s: %w", filepath.Base(executable), ctx.Err())
 		}
+		return nil, fmt.Errorf("command timed out after %d seconds", timeout)
+	}
 
-		result := &CommandResult{
-			ExitCode: exitCode,
-			StdOut:   stdout.String(),
-			StdErr:   stderr.String(),
-			Success:  exitCode == 0,
+	exitCode := 0
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			exitCode = exitErr.ExitCode()
+		} else {
+			return nil, fmt.Errorf("command failed: %w", err)
 		}
+	}
 
-		return result, nil
-
-	case <-time.After(time.Duration(timeout) * time.Second):
-		_ = cmd.Process.Kill()
-		return nil, fmt.Errorf("command timed out after %d seconds", timeout)
+	result := &CommandResult{
+		ExitCode: exitCode,
+		StdOut:   stdout.String(),
+		StdErr:   stderr.String(),
+		Success:  exitCode == 0,
 	}
+
+	return result, nil
 }
 
 // ExecuteDotnetNuget executes a dotnet nuget command
-func ExecuteDotnetNuget(command string, workingDir string, configFile string, timeout int) (*CommandResult, error) {
+func ExecuteDotnetNuget(ctx context.Context, command string, workingDir string, configFile string, timeout int) (*CommandResult, error) {
 	dotnetExe := "dotnet"
 	if runtime.GOOS == "windows" {
 		dotnetExe = "dotnet.exe"
@@ -82,11 +83,11 @@ func ExecuteDotnetNuget(command string, workingDir string, configFile string, ti
 		args = append(args, "--configfile", configFile)
 	}
 
-	return ExecuteCommand(dotnetExe, args, workingDir, timeout)
+	return ExecuteCommand(ctx, dotnetExe, args, workingDir, timeout)
 }
 
 // ExecuteGonuget executes a gonuget command
-func ExecuteGonuget(command string, workingDir string, configFile string, timeout int) (*CommandResult, error) {
+func ExecuteGonuget(ctx context.Context, command string, workingDir string, configFile string, timeout int) (*CommandResult, error) {
 	gonugetExe := findGonugetExecutable()
 
 	args := strings.Fields(command)
@@ -95,7 +96,7 @@ func ExecuteGonuget(command string, workingDir string, configFile string, timeou
 		args = append(args, "--configfile", configFile)
 	}
 
-	return ExecuteCommand(gonugetExe, args, workingDir, timeout)
+	return ExecuteCommand(ctx, gonugetExe, args, workingDir, timeout)
 }
 
 // findGonugetExecutable locates the gonuget binary
@@ -1,3 +1,7 @@
 module github.com/willibrandon/gonuget/cmd/gonuget-cli-interop-test
 
 go 1.25.2
+
+require github.com/willibrandon/gonuget v0.0.0
+
+replace github.com/willibrandon/gonuget => ../..
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -12,20 +13,20 @@ type ExecuteCommandPairHandler struct{}
 func (h *ExecuteCommandPairHandler) ErrorCode() string { return "CLI_EXEC_001" }
 
 // Handle processes the request.
-func (h *ExecuteCommandPairHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteCommandPairHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteCommandPairRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
 	}
 
 	// Execute dotnet nuget command
-	dotnetResult, err := ExecuteDotnetNuget(req.DotnetCommand, req.WorkingDir, "", req.Timeout)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, req.DotnetCommand, req.WorkingDir, "", req.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
 	// Execute gonuget command
-	gonugetResult, err := ExecuteGonuget(req.GonugetCommand, req.WorkingDir, "", req.Timeout)
+	gonugetResult, err := ExecuteGonuget(ctx, req.GonugetCommand, req.WorkingDir, "", req.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -56,7 +57,7 @@ type ExecuteConfigGetHandler struct{}
 func (h *ExecuteConfigGetHandler) ErrorCode() string { return "CLI_CFG_GET_001" }
 
 // Handle processes the request.
-func (h *ExecuteConfigGetHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteConfigGetHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteConfigGetRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -82,12 +83,12 @@ func (h *ExecuteConfigGetHandler) Handle(data json.RawMessage) (any, error) {
 
 	// Execute both commands
 	// Note: config commands find NuGet.config in working directory hierarchy
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -114,7 +115,7 @@ type ExecuteConfigSetHandler struct{}
 func (h *ExecuteConfigSetHandler) ErrorCode() string { return "CLI_CFG_SET_001" }
 
 // Handle processes the request.
-func (h *ExecuteConfigSetHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteConfigSetHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteConfigSetRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -126,12 +127,12 @@ func (h *ExecuteConfigSetHandler) Handle(data json.RawMessage) (any, error) {
 
 	// Execute both commands
 	// Note: config commands find NuGet.config in working directory hierarchy
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -158,7 +159,7 @@ type ExecuteConfigUnsetHandler struct{}
 func (h *ExecuteConfigUnsetHandler) ErrorCode() string { return "CLI_CFG_UNSET_001" }
 
 // Handle processes the request.
-func (h *ExecuteConfigUnsetHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteConfigUnsetHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteConfigUnsetRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -170,12 +171,12 @@ func (h *ExecuteConfigUnsetHandler) Handle(data json.RawMessage) (any, error) {
 
 	// Execute both commands
 	// Note: config commands find NuGet.config in working directory hierarchy
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -202,7 +203,7 @@ type ExecuteConfigPathsHandler struct{}
 func (h *ExecuteConfigPathsHandler) ErrorCode() string { return "CLI_CFG_PATHS_001" }
 
 // Handle processes the request.
-func (h *ExecuteConfigPathsHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteConfigPathsHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteConfigPathsRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -218,12 +219,12 @@ func (h *ExecuteConfigPathsHandler) Handle(data json.RawMessage) (any, error) {
 	}
 
 	// Execute both commands
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
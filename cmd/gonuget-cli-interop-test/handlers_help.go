@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -13,7 +14,7 @@ type ExecuteHelpHandler struct{}
 func (h *ExecuteHelpHandler) ErrorCode() string { return "CLI_HELP_001" }
 
 // Handle processes the request.
-func (h *ExecuteHelpHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteHelpHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteHelpRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -27,7 +28,7 @@ func (h *ExecuteHelpHandler) Handle(data json.RawMessage) (any, error) {
 		dotnetCommand = req.Command + " --help"
 	}
 
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCommand, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCommand, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
@@ -40,7 +41,7 @@ func (h *ExecuteHelpHandler) Handle(data json.RawMessage) (any, error) {
 		gonugetCommand = req.Command + " --help"
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCommand, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCommand, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
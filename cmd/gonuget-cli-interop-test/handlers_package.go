@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -9,8 +10,8 @@ import (
 type ExecuteAddPackageHandler struct{}
 
 // Handle processes the request.
-func (h *ExecuteAddPackageHandler) Handle(data json.RawMessage) (interface{}, error) {
-	return HandleExecuteAddPackage(data)
+func (h *ExecuteAddPackageHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
+	return HandleExecuteAddPackage(ctx, data)
 }
 
 // ErrorCode returns the error code prefix for this handler.
@@ -19,7 +20,7 @@ func (h *ExecuteAddPackageHandler) ErrorCode() string {
 }
 
 // HandleExecuteAddPackage executes both dotnet add package and gonuget add package.
-func HandleExecuteAddPackage(data json.RawMessage) (interface{}, error) {
+func HandleExecuteAddPackage(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req struct {
 		ProjectPath string `json:"projectPath"`
 		PackageID   string `json:"packageId"`
@@ -50,7 +51,7 @@ func HandleExecuteAddPackage(data json.RawMessage) (interface{}, error) {
 	}
 
 	// Execute dotnet add package
-	dotnetResult, err := ExecuteCommand("dotnet", dotnetArgs, req.WorkingDir, 60)
+	dotnetResult, err := ExecuteCommand(ctx, "dotnet", dotnetArgs, req.WorkingDir, 60)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute dotnet: %w", err)
 	}
@@ -74,7 +75,7 @@ func HandleExecuteAddPackage(data json.RawMessage) (interface{}, error) {
 	gonugetExe := findGonugetExecutable()
 
 	// Execute gonuget add package
-	gonugetResult, err := ExecuteCommand(gonugetExe, gonugetArgs, req.WorkingDir, 60)
+	gonugetResult, err := ExecuteCommand(ctx, gonugetExe, gonugetArgs, req.WorkingDir, 60)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute gonuget: %w", err)
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 )
@@ -9,8 +10,8 @@ import (
 type ExecuteRestoreHandler struct{}
 
 // Handle processes the request.
-func (h *ExecuteRestoreHandler) Handle(data json.RawMessage) (interface{}, error) {
-	return HandleExecuteRestore(data)
+func (h *ExecuteRestoreHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
+	return HandleExecuteRestore(ctx, data)
 }
 
 // ErrorCode returns the error code prefix for this handler.
@@ -19,7 +20,7 @@ func (h *ExecuteRestoreHandler) ErrorCode() string {
 }
 
 // HandleExecuteRestore executes both dotnet restore and gonuget restore.
-func HandleExecuteRestore(data json.RawMessage) (interface{}, error) {
+func HandleExecuteRestore(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req struct {
 		ProjectPath string `json:"projectPath"`
 		WorkingDir  string `json:"workingDir"`
@@ -49,7 +50,7 @@ func HandleExecuteRestore(data json.RawMessage) (interface{}, error) {
 	}
 
 	// Execute dotnet restore
-	dotnetResult, err := ExecuteCommand("dotnet", dotnetArgs, req.WorkingDir, 60)
+	dotnetResult, err := ExecuteCommand(ctx, "dotnet", dotnetArgs, req.WorkingDir, 60)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute dotnet: %w", err)
 	}
@@ -57,7 +58,8 @@ func HandleExecuteRestore(data json.RawMessage) (interface{}, error) {
 	// Build gonuget restore command
 	gonugetArgs := []string{"restore", req.ProjectPath}
 	if req.Source != "" {
-		gonugetArgs = append(gonugetArgs, "--source", req.Source)
+		// dotnet --source replaces configured sources, which is --source-override in gonuget
+		gonugetArgs = append(gonugetArgs, "--source-override", req.Source)
 	}
 	if req.Packages != "" {
 		gonugetArgs = append(gonugetArgs, "--packages", req.Packages)
@@ -73,7 +75,7 @@ func HandleExecuteRestore(data json.RawMessage) (interface{}, error) {
 	gonugetExe := findGonugetExecutable()
 
 	// Execute gonuget restore
-	gonugetResult, err := ExecuteCommand(gonugetExe, gonugetArgs, req.WorkingDir, 60)
+	gonugetResult, err := ExecuteCommand(ctx, gonugetExe, gonugetArgs, req.WorkingDir, 60)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute gonuget: %w", err)
 	}
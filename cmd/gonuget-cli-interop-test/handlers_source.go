@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -16,7 +17,7 @@ type ExecuteSourceListHandler struct{}
 func (h *ExecuteSourceListHandler) ErrorCode() string { return "CLI_SRC_LIST_001" }
 
 // Handle processes the request.
-func (h *ExecuteSourceListHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteSourceListHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteSourceListRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -41,12 +42,12 @@ func (h *ExecuteSourceListHandler) Handle(data json.RawMessage) (any, error) {
 	}
 
 	// Execute both commands
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -73,7 +74,7 @@ type ExecuteSourceAddHandler struct{}
 func (h *ExecuteSourceAddHandler) ErrorCode() string { return "CLI_SRC_ADD_001" }
 
 // Handle processes the request.
-func (h *ExecuteSourceAddHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteSourceAddHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteSourceAddRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -135,12 +136,12 @@ func (h *ExecuteSourceAddHandler) Handle(data json.RawMessage) (any, error) {
 	}
 
 	// Execute both commands
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -163,7 +164,7 @@ type ExecuteSourceRemoveHandler struct{}
 func (h *ExecuteSourceRemoveHandler) ErrorCode() string { return "CLI_SRC_REM_001" }
 
 // Handle processes the request.
-func (h *ExecuteSourceRemoveHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteSourceRemoveHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteSourceRemoveRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -189,12 +190,12 @@ func (h *ExecuteSourceRemoveHandler) Handle(data json.RawMessage) (any, error) {
 	}
 
 	// Execute both commands
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -217,7 +218,7 @@ type ExecuteSourceEnableHandler struct{}
 func (h *ExecuteSourceEnableHandler) ErrorCode() string { return "CLI_SRC_EN_001" }
 
 // Handle processes the request.
-func (h *ExecuteSourceEnableHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteSourceEnableHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteSourceEnableRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -236,12 +237,12 @@ func (h *ExecuteSourceEnableHandler) Handle(data json.RawMessage) (any, error) {
 	}
 
 	// Execute both commands
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -264,7 +265,7 @@ type ExecuteSourceDisableHandler struct{}
 func (h *ExecuteSourceDisableHandler) ErrorCode() string { return "CLI_SRC_DIS_001" }
 
 // Handle processes the request.
-func (h *ExecuteSourceDisableHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteSourceDisableHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteSourceDisableRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -283,12 +284,12 @@ func (h *ExecuteSourceDisableHandler) Handle(data json.RawMessage) (any, error)
 	}
 
 	// Execute both commands
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -311,7 +312,7 @@ type ExecuteSourceUpdateHandler struct{}
 func (h *ExecuteSourceUpdateHandler) ErrorCode() string { return "CLI_SRC_UPD_001" }
 
 // Handle processes the request.
-func (h *ExecuteSourceUpdateHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteSourceUpdateHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteSourceUpdateRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -372,12 +373,12 @@ func (h *ExecuteSourceUpdateHandler) Handle(data json.RawMessage) (any, error) {
 	}
 
 	// Execute both commands
-	dotnetResult, err := ExecuteDotnetNuget(dotnetCmd, req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, dotnetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
-	gonugetResult, err := ExecuteGonuget(gonugetCmd, req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, gonugetCmd, req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -13,20 +14,20 @@ type ExecuteVersionHandler struct{}
 func (h *ExecuteVersionHandler) ErrorCode() string { return "CLI_VER_001" }
 
 // Handle processes the request.
-func (h *ExecuteVersionHandler) Handle(data json.RawMessage) (any, error) {
+func (h *ExecuteVersionHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
 	var req ExecuteVersionRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
 	}
 
 	// Execute dotnet nuget --version
-	dotnetResult, err := ExecuteDotnetNuget("--version", req.WorkingDir, "", 30)
+	dotnetResult, err := ExecuteDotnetNuget(ctx, "--version", req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute dotnet nuget: %w", err)
 	}
 
 	// Execute gonuget version
-	gonugetResult, err := ExecuteGonuget("version", req.WorkingDir, "", 30)
+	gonugetResult, err := ExecuteGonuget(ctx, "version", req.WorkingDir, "", 30)
 	if err != nil {
 		return nil, fmt.Errorf("execute gonuget: %w", err)
 	}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cache"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
 	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/observability"
 )
 
 var rootCmd = &cobra.Command{
@@ -14,8 +18,9 @@ var rootCmd = &cobra.Command{
 	Long: `gonuget is a cross-platform NuGet package manager CLI for Go.
 
 Complete documentation is available at https://github.com/willibrandon/gonuget`,
-	SilenceUsage:  true,
-	SilenceErrors: true,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	PersistentPreRunE: persistentPreRun,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Show help when no command is provided
 		_ = cmd.Help()
@@ -33,11 +38,13 @@ func Execute() error {
 func init() {
 	// Initialize console
 	Console = output.DefaultConsole()
+	config.FirstRunOutput = Console.ErrorOutput()
 
 	// Add common flags that will be used by subcommands
 	rootCmd.PersistentFlags().StringP("configfile", "", "", "NuGet configuration file to use")
-	rootCmd.PersistentFlags().StringP("verbosity", "", "normal", "Display verbosity (quiet, normal, detailed)")
+	rootCmd.PersistentFlags().StringP("verbosity", "", "normal", "Verbosity level: q[uiet], m[inimal], n[ormal], d[etailed], or diag[nostic]")
 	rootCmd.PersistentFlags().BoolP("non-interactive", "", false, "Do not prompt for user input or confirmations")
+	rootCmd.PersistentFlags().String("capture-http", "", "Record HTTP traffic (secrets redacted) to a directory for 'gonuget debug http-replay'")
 
 	// Configure Cobra's SuggestionsMinimumDistance for typo suggestions
 	rootCmd.SuggestionsMinimumDistance = 2
@@ -49,6 +56,52 @@ func init() {
 	rootCmd.SetHelpFunc(customHelpFunc)
 }
 
+// persistentPreRun applies the global flags before any command runs.
+func persistentPreRun(cmd *cobra.Command, args []string) error {
+	if err := applyVerbosity(cmd); err != nil {
+		return err
+	}
+	return startHTTPCapture(cmd, args)
+}
+
+// applyVerbosity validates --verbosity, or the -v|--verbosity flag of commands that
+// define their own, and applies it to the console and to the log output of every
+// component (providers, HTTP clients, caches) the command creates.
+func applyVerbosity(cmd *cobra.Command) error {
+	flag := cmd.Flags().Lookup("verbosity")
+	if flag == nil {
+		return nil
+	}
+	verbosity, err := observability.ParseVerbosity(flag.Value.String())
+	if err != nil {
+		return err
+	}
+
+	Console.SetVerbosity(verbosity)
+	observability.SetDefaultLogger(observability.NewVerbosityLogger(Console.Output(), verbosity))
+	return nil
+}
+
+// startHTTPCapture records every HTTP exchange of the command when --capture-http is set.
+// Caches are bypassed so the capture holds every response the command depends on.
+func startHTTPCapture(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("capture-http")
+	if dir == "" {
+		return nil
+	}
+
+	capture, err := nugethttp.NewCapture(dir)
+	if err != nil {
+		return err
+	}
+	nugethttp.SetTransportWrapper(capture.Wrap)
+
+	cacheCtx := cache.NewSourceCacheContext()
+	cacheCtx.NoCache = true
+	cmd.SetContext(cache.WithCacheContext(cmd.Context(), cacheCtx))
+	return nil
+}
+
 // customHelpFunc provides custom help output matching dotnet nuget --help format
 // Only applies custom formatting to the root command; subcommands use Cobra's default help
 func customHelpFunc(cmd *cobra.Command, args []string) {
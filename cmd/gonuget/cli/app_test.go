@@ -1,9 +1,13 @@
 package cli
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
 	"github.com/willibrandon/gonuget/cmd/gonuget/version"
+	"github.com/willibrandon/gonuget/observability"
 )
 
 func TestGetVersion(t *testing.T) {
@@ -24,3 +28,26 @@ func TestGetFullVersion(t *testing.T) {
 		t.Error("GetFullVersion() returned empty string")
 	}
 }
+
+func TestApplyVerbosity(t *testing.T) {
+	t.Cleanup(func() {
+		Console.SetVerbosity(output.VerbosityNormal)
+		observability.SetDefaultLogger(nil)
+	})
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("verbosity", "normal", "")
+
+	_ = cmd.Flags().Set("verbosity", "Diag")
+	if err := applyVerbosity(cmd); err != nil {
+		t.Fatalf("applyVerbosity() error = %v", err)
+	}
+	if Console.GetVerbosity() != output.VerbosityDiagnostic {
+		t.Errorf("console verbosity = %v, want diagnostic", Console.GetVerbosity())
+	}
+
+	_ = cmd.Flags().Set("verbosity", "chatty")
+	if err := applyVerbosity(cmd); !errors.Is(err, observability.ErrInvalidVerbosity) {
+		t.Errorf("applyVerbosity(chatty) error = %v, want ErrInvalidVerbosity", err)
+	}
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -10,6 +11,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+	"github.com/willibrandon/gonuget/restore"
 )
 
 func TestNewAddPackageCmd(t *testing.T) {
@@ -102,10 +105,13 @@ func TestRunAddPackage_CPMEnabled(t *testing.T) {
 	err = runAddPackage(context.Background(), "TestPackage", opts)
 	assert.NoError(t, err, "CPM projects should allow adding packages")
 
-	// Verify version was updated in Directory.Packages.props
+	// The other version overrides the one of Directory.Packages.props for this project
 	dppData, err := os.ReadFile(dppPath)
 	require.NoError(t, err)
-	assert.Contains(t, string(dppData), "2.0.0")
+	assert.Contains(t, string(dppData), `Version="1.0.0"`)
+	projData, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(projData), `VersionOverride="2.0.0"`)
 }
 
 func TestRunAddPackage_WithExplicitVersion(t *testing.T) {
@@ -516,11 +522,110 @@ func TestRunAddPackage_CPM_UpdateExisting(t *testing.T) {
 	err = runAddPackage(context.Background(), "Newtonsoft.Json", opts)
 	assert.NoError(t, err)
 
-	// Verify Directory.Packages.props was updated to new version
+	// The central version is left for the other projects...
 	dppData, err := os.ReadFile(dppPath)
 	require.NoError(t, err)
-	assert.Contains(t, string(dppData), "13.0.3")
-	assert.NotContains(t, string(dppData), "12.0.0")
+	assert.Contains(t, string(dppData), "12.0.0")
+	assert.NotContains(t, string(dppData), "13.0.3")
+
+	// ...and this project overrides it
+	proj, err := project.LoadProject(projectPath)
+	require.NoError(t, err)
+	refs := proj.GetPackageReferences()
+	require.Len(t, refs, 1)
+	assert.Empty(t, refs[0].Version)
+	assert.Equal(t, "13.0.3", refs[0].VersionOverride)
+
+	// Asking for the central version again removes the override
+	opts.Version = "12.0.0"
+	require.NoError(t, runAddPackage(context.Background(), "Newtonsoft.Json", opts))
+	proj, err = project.LoadProject(projectPath)
+	require.NoError(t, err)
+	assert.Empty(t, proj.GetPackageReferences()[0].VersionOverride)
+}
+
+func TestRunAddPackage_CPM_VersionOverrideDisabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectPath := filepath.Join(tmpDir, "test.csproj")
+	dppPath := filepath.Join(tmpDir, "Directory.Packages.props")
+
+	dppContent := `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+    <CentralPackageVersionOverrideEnabled>false</CentralPackageVersionOverrideEnabled>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Newtonsoft.Json" Version="12.0.0" />
+  </ItemGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(dppPath, []byte(dppContent), 0644))
+
+	projectContent := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(projectPath, []byte(projectContent), 0644))
+
+	opts := &AddPackageOptions{
+		ProjectPath: projectPath,
+		Version:     "13.0.3",
+		NoRestore:   true,
+	}
+
+	err := runAddPackage(context.Background(), "Newtonsoft.Json", opts)
+	require.Error(t, err)
+	assert.Equal(t, "NU1013: The package reference Newtonsoft.Json specifies a VersionOverride but the ability to override a centrally defined version is currently disabled.", err.Error())
+
+	// Neither file was changed
+	projData, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, projectContent, string(projData))
+	dppData, err := os.ReadFile(dppPath)
+	require.NoError(t, err)
+	assert.Equal(t, dppContent, string(dppData))
+}
+
+func TestRunAddPackage_CPM_ProjectOptOut(t *testing.T) {
+	tmpDir := t.TempDir()
+	dppPath := filepath.Join(tmpDir, "Directory.Packages.props")
+	dppContent := `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Newtonsoft.Json" Version="12.0.0" />
+  </ItemGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(dppPath, []byte(dppContent), 0644))
+
+	// A sibling of CPM projects that opts out gets an inline version
+	projectPath := filepath.Join(tmpDir, "Legacy", "Legacy.csproj")
+	require.NoError(t, os.MkdirAll(filepath.Dir(projectPath), 0755))
+	projectContent := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <ManagePackageVersionsCentrally>false</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(projectPath, []byte(projectContent), 0644))
+
+	opts := &AddPackageOptions{
+		ProjectPath: projectPath,
+		Version:     "13.0.3",
+		NoRestore:   true,
+	}
+	require.NoError(t, runAddPackage(context.Background(), "Newtonsoft.Json", opts))
+
+	proj, err := project.LoadProject(projectPath)
+	require.NoError(t, err)
+	refs := proj.GetPackageReferences()
+	require.Len(t, refs, 1)
+	assert.Equal(t, "13.0.3", refs[0].Version)
+
+	dppData, err := os.ReadFile(dppPath)
+	require.NoError(t, err)
+	assert.Equal(t, dppContent, string(dppData))
 }
 
 func TestRunAddPackage_CPM_MissingPropsFile(t *testing.T) {
@@ -754,3 +859,122 @@ func TestRunAddPackage_OutputParity(t *testing.T) {
 	assert.NotContains(t, output, "Downloading")
 	assert.NotContains(t, output, "already cached")
 }
+
+// writeAddPackageRepo writes a project without ItemGroup whose NuGet.config lists sources,
+// and isolates the user-wide NuGet folders of the restore.
+func writeAddPackageRepo(t *testing.T, sources ...string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("APPDATA", home)
+
+	dir := t.TempDir()
+	projectPath := filepath.Join(dir, "App.csproj")
+	require.NoError(t, os.WriteFile(projectPath, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+
+</Project>
+`), 0644))
+
+	nugetConfig := "<configuration>\n  <packageSources>\n    <clear />\n"
+	for i, source := range sources {
+		nugetConfig += fmt.Sprintf("    <add key=\"feed%d\" value=\"%s\" />\n", i, source)
+	}
+	nugetConfig += "  </packageSources>\n</configuration>\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "NuGet.config"), []byte(nugetConfig), 0644))
+	return projectPath
+}
+
+func TestRunAddPackage_ConfiguredSourcesAndRestore(t *testing.T) {
+	first := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: []nugethttptest.Package{
+		{ID: "Contoso.Core", Version: "1.0.0"},
+		{ID: "Contoso.Core", Version: "2.0.0-beta"},
+	}})
+	second := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: []nugethttptest.Package{
+		{ID: "Contoso.Core", Version: "1.5.0"},
+	}})
+	projectPath := writeAddPackageRepo(t, first.SourceURL(), second.SourceURL())
+	opts := &AddPackageOptions{
+		ProjectPath:      projectPath,
+		PackageDirectory: filepath.Join(t.TempDir(), "packages"),
+	}
+
+	// The latest stable version of all the configured sources is added to a new ItemGroup
+	out, err := runAddPackageCapturingOutput(t, "Contoso.Core", opts)
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "PackageReference for package 'Contoso.Core' version '1.5.0' added to file")
+	data, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeff"+`<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+
+  <ItemGroup>
+    <PackageReference Include="Contoso.Core" Version="1.5.0" />
+  </ItemGroup>
+
+</Project>
+`, string(data))
+
+	// The restore ran with the new reference
+	assets, err := os.ReadFile(restore.GetAssetsFilePath(projectPath))
+	require.NoError(t, err)
+	assert.Contains(t, string(assets), `"Contoso.Core/1.5.0"`)
+
+	// --prerelease updates the existing reference in place
+	opts.Prerelease = true
+	out, err = runAddPackageCapturingOutput(t, "contoso.core", opts)
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "PackageReference for package 'contoso.core' version '2.0.0-beta' updated in file")
+	data, err = os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n  <ItemGroup>\n    <PackageReference Include=\"Contoso.Core\" Version=\"2.0.0-beta\" />\n  </ItemGroup>\n\n</Project>\n")
+	assets, err = os.ReadFile(restore.GetAssetsFilePath(projectPath))
+	require.NoError(t, err)
+	assert.Contains(t, string(assets), `"Contoso.Core/2.0.0-beta"`)
+}
+
+func TestRunAddPackage_CPM_Restore(t *testing.T) {
+	server := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: []nugethttptest.Package{
+		{ID: "Contoso.Core", Version: "1.0.0"},
+		{ID: "Contoso.Logging", Version: "3.0.0"},
+	}})
+	projectPath := writeAddPackageRepo(t, server.SourceURL())
+	propsPath := filepath.Join(filepath.Dir(projectPath), "Directory.Packages.props")
+	require.NoError(t, os.WriteFile(propsPath, []byte(`<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <!-- Shared versions -->
+    <PackageVersion Include="Contoso.Core" Version="1.0.0" />
+  </ItemGroup>
+</Project>
+`), 0644))
+
+	out, err := runAddPackageCapturingOutput(t, "Contoso.Logging", &AddPackageOptions{
+		ProjectPath:      projectPath,
+		PackageDirectory: filepath.Join(t.TempDir(), "packages"),
+	})
+	require.NoError(t, err, out)
+
+	props, err := os.ReadFile(propsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(props), `    <!-- Shared versions -->
+    <PackageVersion Include="Contoso.Core" Version="1.0.0" />
+    <PackageVersion Include="Contoso.Logging" Version="3.0.0" />
+  </ItemGroup>`)
+	data, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "  <ItemGroup>\n    <PackageReference Include=\"Contoso.Logging\" />\n  </ItemGroup>\n")
+
+	assets, err := os.ReadFile(restore.GetAssetsFilePath(projectPath))
+	require.NoError(t, err)
+	assert.Contains(t, string(assets), `"Contoso.Logging/3.0.0"`)
+}
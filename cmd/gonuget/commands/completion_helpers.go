@@ -1,9 +1,18 @@
 package commands
 
 import (
+	"context"
+	"os"
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+	"github.com/willibrandon/gonuget/core"
 )
 
+// packageCompletionTimeout bounds autocomplete requests so the shell stays responsive
+const packageCompletionTimeout = 5 * time.Second
+
 // completeSourceNames provides dynamic completion for source names from NuGet.config
 func completeSourceNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// Load config to get source names
@@ -20,6 +29,41 @@ func completeSourceNames(cmd *cobra.Command, args []string, toComplete string) (
 	return sourceNames, cobra.ShellCompDirectiveNoFileComp
 }
 
+// completePackageIDs provides dynamic completion for package IDs from the enabled sources
+// autocomplete resources. Authenticated sources use packageSourceCredentials.
+func completePackageIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 || toComplete == "" {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = "."
+	}
+
+	repoManager := core.NewRepositoryManager()
+	for _, source := range config.GetEnabledSourcesOrDefault(workingDir) {
+		_ = repoManager.AddRepository(core.NewSourceRepository(core.RepositoryConfig{
+			Name:      source.Key,
+			SourceURL: source.Value,
+		}))
+	}
+	client := core.NewClient(core.ClientConfig{
+		RepositoryManager:  repoManager,
+		CredentialProvider: config.NewCredentialProvider(config.LoadConfigLayers(workingDir)),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), packageCompletionTimeout)
+	defer cancel()
+
+	ids, err := client.AutocompletePackageIDs(ctx, toComplete, 20, false)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
 // Future completion helpers can be added here:
 // - completeProjectFiles: for --project flag (.csproj, .fsproj, .vbproj)
 // - completeConfigFiles: for --configfile flag (NuGet.config)
@@ -2,7 +2,6 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -157,18 +156,12 @@ func runConfigSet(console *output.Console, configKey string, configValue string,
 		}
 	}
 
-	// Load or create config
-	cfg, err := loadOrCreateConfig(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
-	// Set value
-	cfg.SetConfigValue(configKey, configValue)
-
-	// Save config
-	if err := config.SaveNuGetConfig(configPath, cfg); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	// Set the value in the current content of the config, creating it if needed
+	if err := updateConfigFile(configPath, config.NewDefaultConfig, func(cfg *config.NuGetConfig) error {
+		cfg.SetConfigValue(configKey, configValue)
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	console.Println(fmt.Sprintf("Successfully updated config file at '%s'.", configPath))
@@ -208,18 +201,12 @@ func runConfigUnset(console *output.Console, configKey string) error {
 		return fmt.Errorf("unable to find a NuGet.config file. Create one in the current or parent directory")
 	}
 
-	// Load config
-	cfg, err := config.LoadNuGetConfig(configPath)
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-
-	// Remove value
-	cfg.DeleteConfigValue(configKey)
-
-	// Save config
-	if err := config.SaveNuGetConfig(configPath, cfg); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	// Remove the value from the current content of the config
+	if err := updateConfigFile(configPath, nil, func(cfg *config.NuGetConfig) error {
+		cfg.DeleteConfigValue(configKey)
+		return nil
+	}); err != nil {
+		return err
 	}
 
 	console.Println(fmt.Sprintf("Successfully updated config file at '%s'.", configPath))
@@ -304,19 +291,6 @@ func determineConfigPath(workingDirectory string) string {
 	return configPath
 }
 
-func loadOrCreateConfig(path string) (*config.NuGetConfig, error) {
-	cfg, err := config.LoadNuGetConfig(path)
-	if err != nil {
-		// If file doesn't exist, create a new config
-		// Use errors.Is to check through wrapped errors
-		if errors.Is(err, os.ErrNotExist) {
-			return config.NewDefaultConfig(), nil
-		}
-		return nil, err
-	}
-	return cfg, nil
-}
-
 func listAllConfigFromHierarchy(console *output.Console, workingDirectory string) error {
 	// Get all config files in hierarchy
 	paths := config.GetConfigHierarchy(workingDirectory)
@@ -429,10 +403,18 @@ func listAllConfig(console *output.Console, cfg *config.NuGetConfig) error {
 	}
 
 	// Trusted Signers
-	if cfg.TrustedSigners != nil && len(cfg.TrustedSigners.Add) > 0 {
+	if cfg.TrustedSigners != nil && len(cfg.TrustedSigners.Authors)+len(cfg.TrustedSigners.Repositories) > 0 {
 		console.Println("trustedSigners:")
-		for _, signer := range cfg.TrustedSigners.Add {
-			console.Printf("\tadd name=\"%s\"\n", signer.Name)
+		for _, author := range cfg.TrustedSigners.Authors {
+			console.Printf("\tauthor name=\"%s\"\n", author.Name)
+			printTrustedCertificates(console, author.Certificates)
+		}
+		for _, repository := range cfg.TrustedSigners.Repositories {
+			console.Printf("\trepository name=\"%s\" serviceIndex=\"%s\"\n", repository.Name, repository.ServiceIndex)
+			printTrustedCertificates(console, repository.Certificates)
+			if repository.Owners != "" {
+				console.Printf("\t\towners: %s\n", repository.Owners)
+			}
 		}
 		console.Println("")
 		hasContent = true
@@ -457,3 +439,11 @@ func listAllConfig(console *output.Console, cfg *config.NuGetConfig) error {
 
 	return nil
 }
+
+// printTrustedCertificates lists the certificates of a trusted signer
+func printTrustedCertificates(console *output.Console, certificates []config.TrustedSignerCertificate) {
+	for _, cert := range certificates {
+		console.Printf("\t\tcertificate fingerprint=\"%s\" hashAlgorithm=\"%s\" allowUntrustedRoot=\"%s\"\n",
+			cert.Fingerprint, cert.HashAlgorithm, cert.AllowUntrustedRoot)
+	}
+}
@@ -567,39 +567,26 @@ func TestListAllConfig_WithItems(t *testing.T) {
 	}
 }
 
-func TestLoadOrCreateConfig_Load(t *testing.T) {
-	tmpDir := t.TempDir()
-	configPath := filepath.Join(tmpDir, "NuGet.config")
-
-	// Create config
-	cfg := config.NewDefaultConfig()
-	cfg.SetConfigValue("test", "value")
-	if err := config.SaveNuGetConfig(configPath, cfg); err != nil {
-		t.Fatalf("failed to save config: %v", err)
-	}
+func TestConfigSet_CreatesMissingConfigFile(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "nonexistent.config")
 
-	// Load it
-	loaded, err := loadOrCreateConfig(configPath)
-	if err != nil {
-		t.Fatalf("loadOrCreateConfig() error = %v", err)
-	}
+	var out bytes.Buffer
+	console := output.NewConsole(&out, &out, output.VerbosityNormal)
 
-	if loaded.GetConfigValue("test") != "value" {
-		t.Error("loadOrCreateConfig() should load existing config")
+	// --configfile may name a file that doesn't exist yet
+	if err := runConfigSet(console, "repositoryPath", "~/packages", &configSetOptions{configFile: configPath}); err != nil {
+		t.Fatalf("runConfigSet() error = %v", err)
 	}
-}
-
-func TestLoadOrCreateConfig_Create(t *testing.T) {
-	tmpDir := t.TempDir()
-	nonexistent := filepath.Join(tmpDir, "nonexistent.config")
 
-	// Should create new config for nonexistent file
-	cfg, err := loadOrCreateConfig(nonexistent)
+	cfg, err := config.LoadNuGetConfig(configPath)
 	if err != nil {
-		t.Fatalf("loadOrCreateConfig() error = %v, want nil", err)
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if value := cfg.GetConfigValue("repositoryPath"); value != "~/packages" {
+		t.Errorf("config value = %q, want %q", value, "~/packages")
 	}
-	if cfg == nil {
-		t.Error("loadOrCreateConfig() should return new config for nonexistent file")
+	if cfg.GetPackageSource("nuget.org") == nil {
+		t.Error("new config should have the default package source")
 	}
 }
 
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cache"
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+	nugethttp "github.com/willibrandon/gonuget/http"
+)
+
+// debugCmd is the parent debug command instance
+var debugCmd *cobra.Command
+
+// NewDebugCommand creates the parent "debug" command with subcommands
+func NewDebugCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Diagnose feed, HTTP and restore issues",
+		Long: `Diagnose feed, HTTP and restore issues.
+
+Feed-specific bugs can be reproduced without access to the feed: run the
+failing command with --capture-http to record its HTTP traffic, then replay
+the capture with "gonuget debug http-replay".
+
+"gonuget debug restore-cache" shows why a restore was or wasn't skipped.`,
+		Example: `  # Record the traffic of a failing restore
+  gonuget --capture-http ./capture restore
+
+  # Run the same restore offline from the capture
+  gonuget debug http-replay ./capture restore
+
+  # Show why the next restore won't be a no-op
+  gonuget debug restore-cache`,
+		// Parent commands have no Run function - they are containers only
+	}
+
+	// Store reference for subcommand registration
+	debugCmd = cmd
+
+	return cmd
+}
+
+// GetDebugCommand returns the debug command for registration with root
+func GetDebugCommand() *cobra.Command {
+	if debugCmd == nil {
+		debugCmd = NewDebugCommand()
+	}
+	return debugCmd
+}
+
+// RegisterDebugSubcommands registers all debug subcommands with the debug parent
+func RegisterDebugSubcommands(console *output.Console) {
+	debugCmd := GetDebugCommand()
+	debugCmd.AddCommand(NewDebugHTTPReplayCommand(console))
+	debugCmd.AddCommand(NewDebugRestoreCacheCommand(console))
+}
+
+// NewDebugHTTPReplayCommand creates the "debug http-replay" subcommand
+func NewDebugHTTPReplayCommand(console *output.Console) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "http-replay <DIR> [--] <COMMAND> [ARGS...]",
+		Short: "Run a command against captured HTTP traffic",
+		Long: `Run a gonuget command with every HTTP request served from a directory
+recorded by --capture-http. No network access is made.
+
+Requests are matched on method and URL. A request that was not captured fails
+with the nearest captured request, marking where the two differ. Packages that
+were already in the global packages folder during the capture were not
+downloaded, so replay into an empty --packages folder only if the capture did.
+
+Examples:
+  gonuget debug http-replay ./capture restore
+  gonuget debug http-replay ./capture -- package list Newtonsoft.Json --source nuget.org`,
+		// The replayed command's flags are passed through untouched
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+				return cmd.Help()
+			}
+			if len(args) < 2 {
+				return fmt.Errorf("requires a capture directory and a command to replay")
+			}
+			return runDebugHTTPReplay(cmd, console, args[0], args[1:])
+		},
+	}
+
+	return cmd
+}
+
+func runDebugHTTPReplay(cmd *cobra.Command, console *output.Console, dir string, command []string) error {
+	if command[0] == "--" {
+		command = command[1:]
+	}
+	if len(command) == 0 {
+		return fmt.Errorf("requires a command to replay")
+	}
+	if command[0] == "debug" || slices.Contains(command, "--capture-http") {
+		return fmt.Errorf("cannot replay '%s'", command[0])
+	}
+
+	replay, err := nugethttp.NewReplayTransport(dir)
+	if err != nil {
+		return err
+	}
+	nugethttp.SetTransportWrapper(replay.Wrap)
+	defer nugethttp.SetTransportWrapper(nil)
+
+	console.Detail("Replaying HTTP traffic from %s", dir)
+
+	// Bypass caches so every request is answered by the capture
+	cacheCtx := cache.NewSourceCacheContext()
+	cacheCtx.NoCache = true
+
+	root := cmd.Root()
+	root.SetArgs(command)
+	return root.ExecuteContext(cache.WithCacheContext(cmd.Context(), cacheCtx))
+}
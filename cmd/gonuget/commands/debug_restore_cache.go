@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/restore"
+)
+
+type debugRestoreCacheOptions struct {
+	clear    bool
+	packages string
+}
+
+// NewDebugRestoreCacheCommand creates the "debug restore-cache" subcommand
+func NewDebugRestoreCacheCommand(console *output.Console) *cobra.Command {
+	opts := &debugRestoreCacheOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "restore-cache [<PROJECT>]",
+		Short: "Inspect or clear the no-op restore cache of a project",
+		Long: `Show the no-op restore cache (obj/project.nuget.cache) of a project and
+whether the next restore will be skipped.
+
+The stored dgspec hash is compared with the hash of the project's current
+restore inputs. When the next restore is a full restore, the reasons are
+listed, along with the inputs that changed if the last restore was made by
+gonuget.
+
+--clear deletes only the cache file, so the next restore is a full restore
+without downloading packages again as --force with an empty packages folder
+would.
+
+Examples:
+  gonuget debug restore-cache
+  gonuget debug restore-cache ./src/App/App.csproj
+  gonuget debug restore-cache --clear`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectPath, err := findDebugProject(args)
+			if err != nil {
+				return err
+			}
+			if opts.clear {
+				return runDebugRestoreCacheClear(console, projectPath)
+			}
+			return runDebugRestoreCache(console, projectPath, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.clear, "clear", false, "Delete the cache file")
+	cmd.Flags().StringVar(&opts.packages, "packages", "", "Packages folder passed to restore, which is part of the restore inputs")
+
+	return cmd
+}
+
+// findDebugProject returns the project file given on the command line, or the one in
+// the current directory.
+func findDebugProject(args []string) (string, error) {
+	if len(args) > 0 {
+		path := args[0]
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return project.FindProjectFile(path)
+		}
+		return filepath.Abs(path)
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return project.FindProjectFile(currentDir)
+}
+
+func runDebugRestoreCacheClear(console *output.Console, projectPath string) error {
+	deleted, err := restore.ClearNoOpCache(projectPath)
+	if err != nil {
+		return err
+	}
+
+	cachePath := restore.GetCacheFilePath(projectPath)
+	if deleted {
+		console.WriteLine("Deleted %s", cachePath)
+	} else {
+		console.WriteLine("No cache file at %s", cachePath)
+	}
+	return nil
+}
+
+func runDebugRestoreCache(console *output.Console, projectPath string, opts *debugRestoreCacheOptions) error {
+	info, err := restore.InspectNoOpCacheWithOptions(projectPath, &restore.Options{PackagesFolder: opts.packages})
+	if err != nil {
+		return err
+	}
+
+	block := console.BeginBlock()
+	defer console.EndBlock(block)
+
+	block.Printf("Project:       %s\n", info.ProjectPath)
+	block.Printf("Cache file:    %s\n", info.CachePath)
+	if info.Exists {
+		block.Printf("Stored hash:   %s\n", info.StoredHash)
+	}
+	block.Printf("Current hash:  %s\n", info.CurrentHash)
+	if info.Exists {
+		block.Printf("Success:       %t\n", info.Success)
+		block.Printf("Package files: %d", info.PackageFiles)
+		if len(info.MissingPackageFiles) > 0 {
+			block.Printf(" (%d missing)", len(info.MissingPackageFiles))
+		}
+		block.Println()
+	}
+
+	if info.UpToDate() {
+		block.Println("Next restore:  no-op")
+		return nil
+	}
+	block.Println("Next restore:  full restore")
+
+	block.Println()
+	block.Println("Reasons:")
+	for _, reason := range info.Reasons {
+		block.Printf("  - %s\n", reason)
+	}
+
+	if info.StoredHash != "" && info.StoredHash != info.CurrentHash {
+		block.Println()
+		if !info.InputChangesKnown {
+			block.Println("The inputs of the last restore are unknown (it was made by dotnet or an older gonuget).")
+		} else {
+			block.Println("Changed inputs:")
+			for _, change := range info.InputChanges {
+				switch {
+				case change.Stored == "":
+					block.Printf("  + %s: %s\n", change.Path, change.Current)
+				case change.Current == "":
+					block.Printf("  - %s: %s\n", change.Path, change.Stored)
+				default:
+					block.Printf("  ~ %s: %s -> %s\n", change.Path, change.Stored, change.Current)
+				}
+			}
+		}
+	}
+
+	if len(info.MissingPackageFiles) > 0 {
+		block.Println()
+		block.Println("Missing package files:")
+		for _, pkgPath := range info.MissingPackageFiles {
+			block.Printf("  %s\n", pkgPath)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+	"github.com/willibrandon/gonuget/restore"
+)
+
+func writeDebugTestProject(t *testing.T) string {
+	t.Helper()
+
+	projPath := filepath.Join(t.TempDir(), "app.csproj")
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return projPath
+}
+
+func runDebugRestoreCacheCommand(t *testing.T, args ...string) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	console := output.NewConsole(&out, &out, output.VerbosityNormal)
+	cmd := NewDebugRestoreCacheCommand(console)
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	return out.String()
+}
+
+func TestDebugRestoreCache_Inspect(t *testing.T) {
+	projPath := writeDebugTestProject(t)
+
+	out := runDebugRestoreCacheCommand(t, projPath)
+	for _, want := range []string{
+		"Cache file:    " + restore.GetCacheFilePath(projPath),
+		"Next restore:  full restore",
+		"  - there is no cache file",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// A failed restore made by another tool
+	cache := restore.NewCacheFile("stale-hash")
+	if err := cache.Save(restore.GetCacheFilePath(projPath)); err != nil {
+		t.Fatal(err)
+	}
+
+	out = runDebugRestoreCacheCommand(t, projPath)
+	for _, want := range []string{
+		"Stored hash:   stale-hash",
+		"Success:       false",
+		"  - the last restore failed",
+		"  - the restore inputs changed since the last restore",
+		"The inputs of the last restore are unknown",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDebugRestoreCache_Clear(t *testing.T) {
+	projPath := writeDebugTestProject(t)
+	cachePath := restore.GetCacheFilePath(projPath)
+	if err := restore.NewCacheFile("hash").Save(cachePath); err != nil {
+		t.Fatal(err)
+	}
+
+	out := runDebugRestoreCacheCommand(t, projPath, "--clear")
+	if !strings.Contains(out, "Deleted "+cachePath) {
+		t.Errorf("output = %q, want the deleted cache file", out)
+	}
+	if _, err := os.Stat(cachePath); !os.IsNotExist(err) {
+		t.Errorf("cache file still exists: %v", err)
+	}
+
+	out = runDebugRestoreCacheCommand(t, projPath, "--clear")
+	if !strings.Contains(out, "No cache file at "+cachePath) {
+		t.Errorf("output = %q, want no cache file", out)
+	}
+}
@@ -15,7 +15,9 @@ func NewPackageCommand() *cobra.Command {
 		Long: `Manage NuGet package references in .NET project files.
 
 This command provides operations for adding, listing, removing, and searching
-packages. All operations modify or query .NET project files (.csproj, .fsproj, .vbproj).`,
+packages. All operations modify or query .NET project files (.csproj, .fsproj, .vbproj),
+except sign and verify, which sign .nupkg files and check their signatures, and
+delete, which deletes or unlists a package on a server.`,
 		Example: `  # Add a package
   gonuget package add Newtonsoft.Json
 
@@ -26,7 +28,16 @@ packages. All operations modify or query .NET project files (.csproj, .fsproj, .
   gonuget package remove Newtonsoft.Json
 
   # Search for packages
-  gonuget package search Serilog`,
+  gonuget package search Serilog
+
+  # Sign a package with a certificate
+  gonuget package sign MyPackage.1.0.0.nupkg --certificate-path cert.pfx
+
+  # Verify the signatures of downloaded packages
+  gonuget package verify ./packages --recursive
+
+  # Delete (unlist) a package version from a server
+  gonuget package delete MyPackage 1.0.0 --source https://api.nuget.org/v3/index.json --api-key <key>`,
 		// Parent commands have no Run function - they are containers only
 	}
 
@@ -6,10 +6,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
 	"github.com/willibrandon/gonuget/restore"
 	"github.com/willibrandon/gonuget/solution"
@@ -26,6 +28,8 @@ type AddPackageOptions struct {
 	PackageDirectory string
 	Prerelease       bool
 	Interactive      bool
+	MatchExisting    bool
+	Root             string
 }
 
 // NewPackageAddCommand creates the 'package add' subcommand.
@@ -37,15 +41,25 @@ func NewPackageAddCommand() *cobra.Command {
 		Short: "Add a NuGet package reference to a project file",
 		Long: `Add a NuGet package reference to a project file.
 
-This command adds or updates a package reference in a .NET project file (.csproj, .fsproj, .vbproj).
-If no version is specified, the latest stable version is resolved from the package source.
+This command adds or updates a package reference in a .NET project file (.csproj, .fsproj, .vbproj),
+keeping the formatting of the file, and restores the project unless --no-restore is given.
+If no version is specified, the latest stable version (or the latest version with
+--prerelease) is resolved from --source, or else from all the configured package sources.
+With --match-existing, the version the other projects of the repository already use is
+preferred: their packages.lock.json, obj/project.assets.json and Directory.Packages.props
+files under the repository root (the directory holding .git, or --root) are scanned.
+In a project using Central Package Management the version goes to the PackageVersion
+item of Directory.Packages.props; a --version other than an existing PackageVersion is
+written as a VersionOverride on the project's reference instead.
 
 Examples:
   gonuget package add Newtonsoft.Json
   gonuget package add Newtonsoft.Json --version 13.0.3
   gonuget package add Newtonsoft.Json --framework net8.0
-  gonuget package add Newtonsoft.Json --prerelease`,
-		Args: cobra.ExactArgs(1),
+  gonuget package add Newtonsoft.Json --prerelease
+  gonuget package add Newtonsoft.Json --match-existing`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completePackageIDs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			packageID := args[0]
 			return runAddPackage(cmd.Context(), packageID, opts)
@@ -60,12 +74,17 @@ Examples:
 	cmd.Flags().BoolVar(&opts.Prerelease, "prerelease", false, "Allow prerelease packages to be installed")
 	cmd.Flags().BoolVar(&opts.Interactive, "interactive", false, "Allow the command to stop and wait for user input or action")
 	cmd.Flags().StringVar(&opts.ProjectPath, "project", "", "The project file to operate on (defaults to current directory)")
+	cmd.Flags().BoolVar(&opts.MatchExisting, "match-existing", false, "Use the version other projects in the repository already use")
+	cmd.Flags().StringVar(&opts.Root, "root", "", "The repository root scanned by --match-existing (defaults to the directory holding .git)")
+	cmd.MarkFlagsMutuallyExclusive("version", "match-existing")
 
 	return cmd
 }
 
 // runAddPackage implements the add package command logic.
 func runAddPackage(ctx context.Context, packageID string, opts *AddPackageOptions) error {
+	console := newCLIConsole()
+
 	// 1. Find the project file
 	projectPath := opts.ProjectPath
 	currentDir, err := os.Getwd()
@@ -106,20 +125,30 @@ func runAddPackage(ctx context.Context, packageID string, opts *AddPackageOption
 		return fmt.Errorf("failed to load project %s: %w", projectPath, err)
 	}
 
-	// 3. Check for Central Package Management (CPM)
-	if proj.IsCentralPackageManagementEnabled() {
-		return addPackageWithCPM(ctx, proj, packageID, opts)
+	// 3. Check for Central Package Management (CPM), enabled by the project or by its
+	// Directory.Packages.props unless the project opts out
+	centralVersions, err := proj.CentralPackageVersions()
+	if err != nil {
+		return fmt.Errorf("failed to load Directory.Packages.props: %w", err)
+	}
+	if centralVersions != nil || proj.IsCentralPackageManagementEnabled() {
+		return addPackageWithCPM(ctx, console, proj, packageID, opts)
 	}
 
-	// 4. Resolve version if not specified
+	// 4. Resolve version if not specified, preferring the version the repository uses
 	packageVersion := opts.Version
+	if packageVersion == "" && opts.MatchExisting {
+		if packageVersion, err = matchExistingVersion(console, projectPath, packageID, opts); err != nil {
+			return err
+		}
+	}
 	if packageVersion == "" {
 		resolvedVersion, err := resolveLatestVersion(ctx, packageID, opts)
 		if err != nil {
 			return fmt.Errorf("failed to resolve latest version for %s: %w", packageID, err)
 		}
 		packageVersion = resolvedVersion
-		fmt.Printf("Resolved version: %s\n", packageVersion)
+		console.Printf("Resolved version: %s\n", packageVersion)
 	}
 
 	// 5. Validate version format
@@ -155,13 +184,13 @@ func runAddPackage(ctx context.Context, packageID string, opts *AddPackageOption
 
 	// 9. Show warning if --framework is used with --no-restore (after save, before success message)
 	if opts.NoRestore && opts.Framework != "" {
-		fmt.Fprintf(os.Stderr, "warn  : --no-restore|-n flag was used. No compatibility check will be done and the added package reference will be unconditional.\n")
+		console.Warning("--no-restore|-n flag was used. No compatibility check will be done and the added package reference will be unconditional.\n")
 	}
 
 	// 10. Perform restore if needed
 	if !opts.NoRestore {
 		// Match dotnet: "Adding PackageReference for package 'X' into project 'PATH'"
-		fmt.Printf("info : Adding PackageReference for package '%s' into project '%s'.\n", packageID, projectPath)
+		console.Printf("info : Adding PackageReference for package '%s' into project '%s'.\n", packageID, projectPath)
 
 		restoreOpts := &restore.Options{
 			PackagesFolder: opts.PackageDirectory,
@@ -182,13 +211,15 @@ func runAddPackage(ctx context.Context, packageID string, opts *AddPackageOption
 		}
 
 		// Match dotnet: "Restoring packages for PATH..."
-		fmt.Printf("info : Restoring packages for %s...\n", projectPath)
+		console.Printf("info : Restoring packages for %s...\n", projectPath)
 
-		console := &cliConsole{}
 		restorer := restore.NewRestorer(restoreOpts, console)
 
 		restoreStart := time.Now()
-		packageRefs := proj.GetPackageReferences()
+		packageRefs, err := restore.PackageReferences(proj)
+		if err != nil {
+			return fmt.Errorf("failed to load package references: %w", err)
+		}
 		result, err := restorer.Restore(ctx, proj, packageRefs)
 		restoreElapsed := time.Since(restoreStart)
 		if err != nil {
@@ -196,71 +227,70 @@ func runAddPackage(ctx context.Context, packageID string, opts *AddPackageOption
 		}
 
 		// Match dotnet: "Package 'X' is compatible with all the specified frameworks in project 'PATH'."
-		fmt.Printf("info : Package '%s' is compatible with all the specified frameworks in project '%s'.\n", packageID, projectPath)
+		console.Printf("info : Package '%s' is compatible with all the specified frameworks in project '%s'.\n", packageID, projectPath)
 
 		// Match dotnet: "PackageReference for package 'X' version 'Y' added to file 'PATH'."
 		if updated {
-			fmt.Printf("info : PackageReference for package '%s' version '%s' updated in file '%s'.\n", packageID, packageVersion, projectPath)
+			console.Printf("info : PackageReference for package '%s' version '%s' updated in file '%s'.\n", packageID, packageVersion, projectPath)
 		} else {
-			fmt.Printf("info : PackageReference for package '%s' version '%s' added to file '%s'.\n", packageID, packageVersion, projectPath)
+			console.Printf("info : PackageReference for package '%s' version '%s' added to file '%s'.\n", packageID, packageVersion, projectPath)
 		}
 
-		// Generate project.assets.json (matches dotnet add package behavior)
-		objDir := filepath.Join(filepath.Dir(projectPath), "obj")
-		assetsPath := filepath.Join(objDir, "project.assets.json")
+		// The restore wrote project.assets.json (matches dotnet add package behavior)
+		assetsPath := restore.GetAssetsFilePath(projectPath)
 
 		// If cache hit, dotnet says "Assets file has not changed. Skipping assets file writing."
-		// Otherwise, it writes the assets file
 		if result.CacheHit {
 			// Match dotnet cache hit message
-			fmt.Printf("info : Assets file has not changed. Skipping assets file writing. Path: %s\n", assetsPath)
+			console.Printf("info : Assets file has not changed. Skipping assets file writing. Path: %s\n", assetsPath)
 		} else {
-			// Full restore - generate and write assets file
-			lockFile := restore.NewLockFileBuilder().Build(proj, result)
-
 			// Match dotnet: "Writing assets file to disk. Path: PATH"
-			fmt.Printf("info : Writing assets file to disk. Path: %s\n", assetsPath)
-
-			if err := lockFile.Save(assetsPath); err != nil {
-				return fmt.Errorf("failed to save project.assets.json: %w", err)
-			}
+			console.Printf("info : Writing assets file to disk. Path: %s\n", assetsPath)
 		}
 
 		// Match dotnet: "log  : Restored PATH (in X ms)."
-		fmt.Printf("log  : Restored %s (in %d ms).\n", projectPath, restoreElapsed.Milliseconds())
+		console.Printf("log  : Restored %s (in %d ms).\n", projectPath, restoreElapsed.Milliseconds())
 	} else {
 		// With --no-restore, just report the add/update
 		if updated {
-			fmt.Printf("info : Updated package '%s' version '%s' in project '%s'\n", packageID, packageVersion, projectPath)
+			console.Printf("info : Updated package '%s' version '%s' in project '%s'\n", packageID, packageVersion, projectPath)
 		} else {
-			fmt.Printf("info : Added package '%s' version '%s' to project '%s'\n", packageID, packageVersion, projectPath)
+			console.Printf("info : Added package '%s' version '%s' to project '%s'\n", packageID, packageVersion, projectPath)
 		}
 	}
 
 	return nil
 }
 
-// cliConsole implements the restore.Console interface for CLI output.
-type cliConsole struct{}
+// cliConsole implements the restore.Console interface for CLI output,
+// with dotnet's "error : " and "warn  : " prefixes.
+type cliConsole struct {
+	console *output.Console
+}
+
+// newCLIConsole creates a cliConsole writing to the current stdout and stderr.
+func newCLIConsole() *cliConsole {
+	return &cliConsole{console: output.DefaultConsole()}
+}
 
 func (c *cliConsole) Printf(format string, args ...any) {
-	fmt.Printf(format, args...)
+	c.console.Printf(format, args...)
 }
 
 func (c *cliConsole) Error(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "error : "+format, args...)
+	_, _ = fmt.Fprintf(c.console.ErrorOutput(), "error : "+format, args...)
 }
 
 func (c *cliConsole) Warning(format string, args ...any) {
-	fmt.Fprintf(os.Stderr, "warn  : "+format, args...)
+	_, _ = fmt.Fprintf(c.console.ErrorOutput(), "warn  : "+format, args...)
 }
 
 func (c *cliConsole) Output() io.Writer {
-	return os.Stdout
+	return c.console.Output()
 }
 
 // addPackageWithCPM handles adding a package to a CPM-enabled project.
-func addPackageWithCPM(ctx context.Context, proj *project.Project, packageID string, opts *AddPackageOptions) error {
+func addPackageWithCPM(ctx context.Context, console *cliConsole, proj *project.Project, packageID string, opts *AddPackageOptions) error {
 	projectPath := proj.Path
 
 	// 1. Validate Directory.Packages.props exists
@@ -277,21 +307,26 @@ func addPackageWithCPM(ctx context.Context, proj *project.Project, packageID str
 
 	// 3. Resolve version if not specified
 	packageVersion := opts.Version
+	existingVersion := props.GetPackageVersion(packageID)
 	if packageVersion == "" {
 		// Check if version already exists in Directory.Packages.props
-		existingVersion := props.GetPackageVersion(packageID)
 		if existingVersion != "" {
 			// Package already has a version in Directory.Packages.props, use it
 			packageVersion = existingVersion
-			fmt.Printf("info : Package '%s' version '%s' already defined in Directory.Packages.props\n", packageID, packageVersion)
-		} else {
+			console.Printf("info : Package '%s' version '%s' already defined in Directory.Packages.props\n", packageID, packageVersion)
+		} else if opts.MatchExisting {
+			if packageVersion, err = matchExistingVersion(console, projectPath, packageID, opts); err != nil {
+				return err
+			}
+		}
+		if packageVersion == "" {
 			// Resolve latest version
 			resolvedVersion, err := resolveLatestVersion(ctx, packageID, opts)
 			if err != nil {
 				return fmt.Errorf("failed to resolve latest version for %s: %w", packageID, err)
 			}
 			packageVersion = resolvedVersion
-			fmt.Printf("info : Resolved version: %s\n", packageVersion)
+			console.Printf("info : Resolved version: %s\n", packageVersion)
 		}
 	}
 
@@ -300,14 +335,28 @@ func addPackageWithCPM(ctx context.Context, proj *project.Project, packageID str
 		return fmt.Errorf("invalid package version '%s': %w", packageVersion, err)
 	}
 
-	// 5. Add/update PackageVersion in Directory.Packages.props
-	updated, err := props.AddOrUpdatePackageVersion(packageID, packageVersion)
-	if err != nil {
-		return fmt.Errorf("failed to add package version: %w", err)
-	}
+	// 5. Add/update PackageVersion in Directory.Packages.props; with --match-existing an
+	// existing PackageVersion is left as it is, and a --version other than the existing
+	// PackageVersion becomes a VersionOverride of this project's reference
+	keepExisting := opts.MatchExisting && existingVersion != ""
+	override := opts.Version != "" && existingVersion != "" && !strings.EqualFold(opts.Version, existingVersion)
+	var updated bool
+	switch {
+	case keepExisting:
+		console.Printf("info : PackageVersion for '%s' already exists in '%s'; adding the PackageReference without a version.\n", packageID, propsPath)
+	case override:
+		if !proj.IsCentralPackageVersionOverrideEnabled(props) {
+			nugetErr := restore.NewVersionOverrideDisabledError(projectPath, packageID)
+			return fmt.Errorf("%s: %s", nugetErr.Code, nugetErr.Message)
+		}
+	default:
+		if updated, err = props.AddOrUpdatePackageVersion(packageID, packageVersion); err != nil {
+			return fmt.Errorf("failed to add package version: %w", err)
+		}
 
-	if err := props.Save(); err != nil {
-		return fmt.Errorf("failed to save Directory.Packages.props: %w", err)
+		if err := props.Save(); err != nil {
+			return fmt.Errorf("failed to save Directory.Packages.props: %w", err)
+		}
 	}
 
 	// 6. Determine target frameworks
@@ -323,17 +372,31 @@ func addPackageWithCPM(ctx context.Context, proj *project.Project, packageID str
 		return fmt.Errorf("failed to add package reference: %w", err)
 	}
 
+	// An explicit version that matches the PackageVersion removes an earlier override
+	if opts.Version != "" {
+		overrideVersion := ""
+		if override {
+			overrideVersion = packageVersion
+		}
+		proj.SetPackageVersionOverride(packageID, overrideVersion)
+	}
+
 	if err := proj.Save(); err != nil {
 		return fmt.Errorf("failed to save project file: %w", err)
 	}
 
 	// 8. Report success
-	if updated {
-		fmt.Printf("info : Updated package '%s' to version '%s' in Directory.Packages.props\n", packageID, packageVersion)
-	} else {
-		fmt.Printf("info : Added package '%s' version '%s' to Directory.Packages.props\n", packageID, packageVersion)
+	switch {
+	case keepExisting:
+		// Directory.Packages.props was not changed
+	case override:
+		console.Printf("info : Package '%s' version '%s' overrides version '%s' of Directory.Packages.props\n", packageID, packageVersion, existingVersion)
+	case updated:
+		console.Printf("info : Updated package '%s' to version '%s' in Directory.Packages.props\n", packageID, packageVersion)
+	default:
+		console.Printf("info : Added package '%s' version '%s' to Directory.Packages.props\n", packageID, packageVersion)
 	}
-	fmt.Printf("info : Added PackageReference for '%s' to project '%s'\n", packageID, projectPath)
+	console.Printf("info : Added PackageReference for '%s' to project '%s'\n", packageID, projectPath)
 
 	// 9. Perform restore if needed
 	if !opts.NoRestore {
@@ -354,39 +417,35 @@ func addPackageWithCPM(ctx context.Context, proj *project.Project, packageID str
 			}
 		}
 
-		console := &cliConsole{}
 		restorer := restore.NewRestorer(restoreOpts, console)
 
-		packageRefs := proj.GetPackageReferences()
-		result, err := restorer.Restore(ctx, proj, packageRefs)
+		packageRefs, err := restore.PackageReferences(proj)
 		if err != nil {
-			return fmt.Errorf("restore failed: %w", err)
+			return fmt.Errorf("failed to load package references: %w", err)
 		}
-
-		// Generate project.assets.json
-		lockFile := restore.NewLockFileBuilder().Build(proj, result)
-		objDir := filepath.Join(filepath.Dir(projectPath), "obj")
-		assetsPath := filepath.Join(objDir, "project.assets.json")
-		if err := lockFile.Save(assetsPath); err != nil {
-			return fmt.Errorf("failed to save project.assets.json: %w", err)
+		// The restore writes project.assets.json
+		if _, err := restorer.Restore(ctx, proj, packageRefs); err != nil {
+			return fmt.Errorf("restore failed: %w", err)
 		}
 
-		fmt.Println("info : Package added successfully")
+		console.Printf("info : Package added successfully\n")
 	}
 
 	return nil
 }
 
-// resolveLatestVersion resolves the latest version of a package from the package source.
+// resolveLatestVersion resolves the latest version of a package from --source, or else
+// from all the enabled sources of the NuGet.Config hierarchy (falling back to nuget.org),
+// like dotnet add package.
 func resolveLatestVersion(ctx context.Context, packageID string, opts *AddPackageOptions) (string, error) {
 	// Create a client with timeout
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	source := opts.Source
-	// If no explicit source, use first enabled source from config with fallback to defaults
-	// This matches dotnet behavior
-	if source == "" {
+	var sources []string
+	if opts.Source != "" {
+		sources = []string{opts.Source}
+	} else {
 		var projectDir string
 		if opts.ProjectPath != "" {
 			projectDir = filepath.Dir(opts.ProjectPath)
@@ -398,15 +457,14 @@ func resolveLatestVersion(ctx context.Context, packageID string, opts *AddPackag
 			}
 		}
 
-		sources := config.GetEnabledSourcesOrDefault(projectDir)
-		if len(sources) > 0 {
-			source = sources[0].Value
+		for _, source := range config.GetEnabledSourcesOrDefault(projectDir) {
+			sources = append(sources, source.Value)
 		}
 	}
 
 	// Call library function
 	return restore.ResolveLatestVersion(ctx, packageID, &restore.ResolveLatestVersionOptions{
-		Source:     source,
+		Sources:    sources,
 		Prerelease: opts.Prerelease,
 	})
 }
@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/restore"
+	"github.com/willibrandon/gonuget/solution"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// existingPackageVersion is a version of a package already used in the repository, and
+// the file it was found in.
+type existingPackageVersion struct {
+	Version string
+	Path    string
+}
+
+// skippedScanDirs are the directories the repository scan doesn't descend into.
+// Assets files are read through their projects, so obj is skipped too.
+var skippedScanDirs = map[string]bool{
+	"node_modules": true,
+	"bin":          true,
+	"obj":          true,
+}
+
+// matchExistingVersion returns the version of packageID the other projects of the
+// repository already use, for --match-existing. It returns an empty string when none
+// uses the package, and an error listing the versions when they disagree.
+func matchExistingVersion(console *cliConsole, projectPath, packageID string, opts *AddPackageOptions) (string, error) {
+	root := opts.Root
+	if root == "" {
+		var err error
+		if root, err = findRepositoryRoot(filepath.Dir(projectPath)); err != nil {
+			return "", err
+		}
+	}
+
+	found, err := findExistingPackageVersions(root, packageID, projectPath)
+	if err != nil {
+		return "", err
+	}
+	if len(found) == 0 {
+		console.Printf("info : No project under '%s' uses package '%s'; resolving the latest version.\n", root, packageID)
+		return "", nil
+	}
+
+	selected, err := selectExistingVersion(packageID, found)
+	if err != nil {
+		return "", err
+	}
+
+	console.Printf("info : Using version '%s' of package '%s', already used in:\n", selected, packageID)
+	for _, existing := range found {
+		if existing.Version == selected {
+			console.Printf("info :   %s\n", existing.Path)
+		}
+	}
+	return selected, nil
+}
+
+// findRepositoryRoot returns the closest directory, from start upwards, that holds a
+// .git directory (or a .git file, in worktrees and submodules).
+func findRepositoryRoot(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("could not find the repository root above '%s' (use --root to set it)", start)
+		}
+		dir = parent
+	}
+}
+
+// findExistingPackageVersions scans the projects under root, other than excludeProject,
+// for the version of packageID they use: the locked version from packages.lock.json when
+// there is one, otherwise the restored version from obj/project.assets.json. The
+// PackageVersion items of Directory.Packages.props files count as well.
+func findExistingPackageVersions(root, packageID, excludeProject string) ([]existingPackageVersion, error) {
+	excludeProject, _ = filepath.Abs(excludeProject)
+
+	var found []existingPackageVersion
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than failing the scan
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (skippedScanDirs[strings.ToLower(d.Name())] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch {
+		case strings.EqualFold(d.Name(), "Directory.Packages.props"):
+			props, err := project.LoadDirectoryPackagesProps(path)
+			if err != nil {
+				return nil
+			}
+			if v := props.GetPackageVersion(packageID); v != "" {
+				found = append(found, existingPackageVersion{Version: v, Path: path})
+			}
+		case solution.IsProjectFile(path):
+			if abs, _ := filepath.Abs(path); abs == excludeProject {
+				return nil
+			}
+			if existing, ok := projectPackageVersion(path, packageID); ok {
+				found = append(found, existing)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan '%s': %w", root, err)
+	}
+	return found, nil
+}
+
+// projectPackageVersion returns the version of packageID a restored project uses.
+func projectPackageVersion(projectPath, packageID string) (existingPackageVersion, bool) {
+	lockPath := filepath.Join(filepath.Dir(projectPath), restore.PackagesLockFileName)
+	if lockFile, err := restore.LoadPackagesLockFile(lockPath); err == nil && lockFile != nil {
+		if v := lockFile.ResolvedVersion(packageID); v != "" {
+			return existingPackageVersion{Version: v, Path: lockPath}, true
+		}
+	}
+
+	assetsPath := restore.GetAssetsFilePath(projectPath)
+	if assets, err := restore.LoadLockFile(assetsPath); err == nil {
+		if v := assets.PackageVersion(packageID); v != "" {
+			return existingPackageVersion{Version: v, Path: assetsPath}, true
+		}
+	}
+	return existingPackageVersion{}, false
+}
+
+// selectExistingVersion returns the version most of the existing versions agree on.
+// When no version is the most common, it returns an error listing them.
+func selectExistingVersion(packageID string, found []existingPackageVersion) (string, error) {
+	// Versions are compared normalized, so 1.0 and 1.0.0 agree
+	counts := make(map[string]int)
+	for i, existing := range found {
+		if v, err := version.Parse(existing.Version); err == nil {
+			found[i].Version = v.ToNormalizedString()
+		}
+		counts[found[i].Version]++
+	}
+
+	versions := make([]string, 0, len(counts))
+	for v := range counts {
+		versions = append(versions, v)
+	}
+	slices.SortFunc(versions, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	if len(versions) == 1 || counts[versions[0]] > counts[versions[1]] {
+		return versions[0], nil
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "package '%s' is used with different versions in the repository; use --version to choose one:", packageID)
+	for _, v := range versions {
+		for _, existing := range found {
+			if existing.Version == v {
+				fmt.Fprintf(&msg, "\n  %s: %s", v, existing.Path)
+			}
+		}
+	}
+	return "", errors.New(msg.String())
+}
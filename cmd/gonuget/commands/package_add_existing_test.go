@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+)
+
+const matchExistingProject = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+</Project>`
+
+// writeMatchExistingFile writes a file under root, creating its directories
+func writeMatchExistingFile(t *testing.T, root, path, content string) {
+	t.Helper()
+	path = filepath.Join(root, path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// writeRestoredProject writes a project whose assets file uses Newtonsoft.Json at version
+func writeRestoredProject(t *testing.T, root, dir, version string) {
+	t.Helper()
+	writeMatchExistingFile(t, root, filepath.Join(dir, filepath.Base(dir)+".csproj"), matchExistingProject)
+	writeMatchExistingFile(t, root, filepath.Join(dir, "obj", "project.assets.json"),
+		fmt.Sprintf(`{"version": 3, "libraries": {"Newtonsoft.Json/%s": {"type": "package"}}}`, version))
+}
+
+// writeMatchExistingRepo creates a repository where two projects use Newtonsoft.Json
+// 13.0.3 (one through its packages.lock.json), one uses 12.0.1, and a project under
+// node_modules, which is not scanned, uses 9.0.1. src/New is the project to add to.
+func writeMatchExistingRepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+
+	writeRestoredProject(t, root, filepath.Join("src", "App"), "13.0.3")
+	writeMatchExistingFile(t, root, filepath.Join("src", "Lib", "Lib.csproj"), matchExistingProject)
+	writeMatchExistingFile(t, root, filepath.Join("src", "Lib", "packages.lock.json"),
+		`{"version": 1, "dependencies": {"net8.0": {"Newtonsoft.Json": {"type": "Direct", "requested": "[13.0.3, )", "resolved": "13.0.3"}}}}`)
+	writeRestoredProject(t, root, filepath.Join("tools", "Tool"), "12.0.1")
+	writeRestoredProject(t, root, filepath.Join("node_modules", "pkg", "Vendored"), "9.0.1")
+	writeMatchExistingFile(t, root, filepath.Join("src", "New", "New.csproj"), matchExistingProject)
+	return root
+}
+
+// runAddPackageCapturingOutput runs the add command and returns what it printed
+func runAddPackageCapturingOutput(t *testing.T, packageID string, opts *AddPackageOptions) (string, error) {
+	t.Helper()
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	os.Stdout = w
+
+	runErr := runAddPackage(context.Background(), packageID, opts)
+
+	_ = w.Close()
+	os.Stdout = oldStdout
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+	return buf.String(), runErr
+}
+
+func TestRunAddPackage_MatchExisting_MostCommonVersion(t *testing.T) {
+	root := writeMatchExistingRepo(t)
+	projectPath := filepath.Join(root, "src", "New", "New.csproj")
+
+	out, err := runAddPackageCapturingOutput(t, "newtonsoft.json", &AddPackageOptions{
+		ProjectPath:   projectPath,
+		MatchExisting: true,
+		NoRestore:     true,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "Using version '13.0.3' of package 'newtonsoft.json'")
+	assert.Contains(t, out, filepath.Join(root, "src", "App", "obj", "project.assets.json"))
+	assert.Contains(t, out, filepath.Join(root, "src", "Lib", "packages.lock.json"))
+	assert.NotContains(t, out, filepath.Join("tools", "Tool"))
+
+	data, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `Version="13.0.3"`)
+}
+
+func TestRunAddPackage_MatchExisting_Conflict(t *testing.T) {
+	root := writeMatchExistingRepo(t)
+	writeRestoredProject(t, root, filepath.Join("tools", "Other"), "12.0.1")
+	projectPath := filepath.Join(root, "src", "New", "New.csproj")
+
+	_, err := runAddPackageCapturingOutput(t, "Newtonsoft.Json", &AddPackageOptions{
+		ProjectPath:   projectPath,
+		MatchExisting: true,
+		NoRestore:     true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "use --version to choose one")
+	assert.Contains(t, err.Error(), "13.0.3: "+filepath.Join(root, "src", "App", "obj", "project.assets.json"))
+	assert.Contains(t, err.Error(), "12.0.1: "+filepath.Join(root, "tools", "Other", "obj", "project.assets.json"))
+	assert.NotContains(t, err.Error(), "9.0.1", "node_modules must not be scanned")
+
+	data, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Newtonsoft.Json")
+}
+
+func TestRunAddPackage_MatchExisting_NotFound(t *testing.T) {
+	root := writeMatchExistingRepo(t)
+	projectPath := filepath.Join(root, "src", "New", "New.csproj")
+	server := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: []nugethttptest.Package{
+		{ID: "Serilog", Version: "3.1.1"},
+		{ID: "Serilog", Version: "4.0.0"},
+	}})
+
+	out, err := runAddPackageCapturingOutput(t, "Serilog", &AddPackageOptions{
+		ProjectPath:   projectPath,
+		MatchExisting: true,
+		NoRestore:     true,
+		Source:        server.SourceURL(),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "No project under '"+root+"' uses package 'Serilog'")
+
+	data, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `Version="4.0.0"`)
+}
+
+func TestRunAddPackage_MatchExisting_Root(t *testing.T) {
+	// Without .git, the root must be given
+	root := t.TempDir()
+	writeRestoredProject(t, root, "App", "13.0.3")
+	writeMatchExistingFile(t, root, filepath.Join("New", "New.csproj"), matchExistingProject)
+	projectPath := filepath.Join(root, "New", "New.csproj")
+
+	_, err := runAddPackageCapturingOutput(t, "Newtonsoft.Json", &AddPackageOptions{
+		ProjectPath:   projectPath,
+		MatchExisting: true,
+		NoRestore:     true,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--root")
+
+	_, err = runAddPackageCapturingOutput(t, "Newtonsoft.Json", &AddPackageOptions{
+		ProjectPath:   projectPath,
+		MatchExisting: true,
+		NoRestore:     true,
+		Root:          root,
+	})
+	require.NoError(t, err)
+	data, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `Version="13.0.3"`)
+}
+
+func TestRunAddPackage_MatchExisting_CPM(t *testing.T) {
+	root := writeMatchExistingRepo(t)
+	propsContent := `<Project>
+  <ItemGroup>
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.1" />
+  </ItemGroup>
+</Project>`
+	writeMatchExistingFile(t, root, filepath.Join("src", "New", "Directory.Packages.props"), propsContent)
+	projectPath := filepath.Join(root, "src", "New", "New.csproj")
+	writeMatchExistingFile(t, root, filepath.Join("src", "New", "New.csproj"), `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+</Project>`)
+
+	out, err := runAddPackageCapturingOutput(t, "Newtonsoft.Json", &AddPackageOptions{
+		ProjectPath:   projectPath,
+		MatchExisting: true,
+		NoRestore:     true,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "PackageVersion for 'Newtonsoft.Json' already exists")
+
+	// The central version wins over the versions the other projects use, and is left as it is
+	props, err := os.ReadFile(filepath.Join(root, "src", "New", "Directory.Packages.props"))
+	require.NoError(t, err)
+	assert.Equal(t, propsContent, string(props))
+
+	data, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<PackageReference Include="Newtonsoft.Json" />`)
+	assert.NotContains(t, string(data), "Version=")
+}
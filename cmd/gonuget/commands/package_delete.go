@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+	"github.com/willibrandon/gonuget/core"
+)
+
+// PackageDeleteOptions holds the configuration for the package delete command.
+type PackageDeleteOptions struct {
+	Source         string
+	APIKey         string
+	NonInteractive bool
+}
+
+// NewPackageDeleteCommand creates the 'package delete' subcommand.
+func NewPackageDeleteCommand() *cobra.Command {
+	return newDeleteCommand("gonuget package delete")
+}
+
+// NewDeleteCommand creates the top-level delete command. It matches dotnet nuget delete.
+func NewDeleteCommand() *cobra.Command {
+	return newDeleteCommand("gonuget delete")
+}
+
+// newDeleteCommand creates a delete command; commandPath is how its examples invoke it.
+func newDeleteCommand(commandPath string) *cobra.Command {
+	opts := &PackageDeleteOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "delete <PACKAGE_ID> <PACKAGE_VERSION>",
+		Short: "Delete or unlist a package from a server",
+		Long: `Delete or unlist a package version from a package source.
+
+The request is sent to the PackagePublish resource of a V3 source, or to the feed
+of a V2 source. nuget.org unlists the package instead of deleting it; most private
+feeds delete it. The source is given with --source (a name from NuGet.config, or a
+URL) and defaults to the defaultPushSource config value.
+
+The command asks for confirmation unless --non-interactive is given.
+
+Examples:
+  ` + commandPath + ` MyPackage 1.0.0 --source https://api.nuget.org/v3/index.json --api-key <key>
+  ` + commandPath + ` MyPackage 1.0.0-beta --source MyInternalFeed --non-interactive`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// --non-interactive is a global flag
+			if flag := cmd.Flags().Lookup("non-interactive"); flag != nil && flag.Value.String() == "true" {
+				opts.NonInteractive = true
+			}
+			return runPackageDelete(cmd.Context(), args[0], args[1], opts, cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Source, "source", "s", "", "Package source (URL or name from NuGet.config) to delete the package from")
+	cmd.Flags().StringVarP(&opts.APIKey, "api-key", "k", "", "The API key for the server")
+
+	return cmd
+}
+
+// runPackageDelete implements the package delete command logic.
+// Reference: DeleteRunner.Run and PackageUpdateResource.Delete
+func runPackageDelete(ctx context.Context, packageID, packageVersion string, opts *PackageDeleteOptions, in io.Reader, w io.Writer) error {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = "."
+	}
+	layers := config.LoadConfigLayers(workingDir)
+
+	source, err := resolvePackageUpdateSource(opts.Source, layers, workingDir)
+	if err != nil {
+		return err
+	}
+
+	repoManager := core.NewRepositoryManager()
+	if err := repoManager.AddRepository(core.NewSourceRepository(core.RepositoryConfig{
+		Name:            source.Key,
+		SourceURL:       source.Value,
+		ProtocolVersion: source.ProtocolVersion,
+	})); err != nil {
+		return fmt.Errorf("failed to add repository: %w", err)
+	}
+	client := core.NewClient(core.ClientConfig{
+		RepositoryManager:  repoManager,
+		CredentialProvider: config.NewCredentialProvider(layers),
+	})
+	repo, err := client.GetRepositoryManager().GetRepository(source.Key)
+	if err != nil {
+		return err
+	}
+
+	updateURL, err := repo.PackageUpdateURL(ctx)
+	if err != nil {
+		return err
+	}
+	if opts.APIKey == "" {
+		_, _ = fmt.Fprintf(w, "warn : No API Key was provided and no API Key could be found for '%s'. To save an API Key for a source use the 'setApiKey' command.\n", updateURL)
+	}
+
+	if !opts.NonInteractive {
+		_, _ = fmt.Fprintf(w, "%s %s will be deleted from the '%s'. Would you like to continue? (y/N) ", packageID, packageVersion, updateURL)
+		if !confirmed(in) {
+			_, _ = fmt.Fprintln(w, "Delete canceled")
+			return nil
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "Deleting %s %s from the '%s'.\n", packageID, packageVersion, updateURL)
+	deleteURL, err := repo.PackageDeleteURL(ctx, packageID, packageVersion)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "  DELETE %s\n", deleteURL)
+	start := time.Now()
+	err = repo.DeletePackage(ctx, packageID, packageVersion, opts.APIKey)
+	elapsed := time.Since(start).Milliseconds()
+
+	var updateErr *core.PackageUpdateError
+	switch {
+	case errors.As(err, &updateErr):
+		_, _ = fmt.Fprintf(w, "  %s %s %dms\n", statusName(updateErr.StatusCode), deleteURL, elapsed)
+		if updateErr.StatusCode == http.StatusNotFound {
+			return fmt.Errorf("package %s %s was not found at the source: %w", packageID, packageVersion, err)
+		}
+		return err
+	case err != nil:
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "  OK %s %dms\n", deleteURL, elapsed)
+	_, _ = fmt.Fprintf(w, "%s %s was deleted successfully.\n", packageID, packageVersion)
+	return nil
+}
+
+// resolvePackageUpdateSource returns the source to push to or delete from: the --source
+// name or URL, otherwise the defaultPushSource config value.
+func resolvePackageUpdateSource(nameOrURL string, layers []config.ConfigLayer, workingDir string) (config.PackageSource, error) {
+	if nameOrURL == "" {
+		for _, layer := range layers {
+			if value := layer.Config.GetConfigValue("defaultPushSource"); value != "" {
+				nameOrURL = value
+				break
+			}
+		}
+	}
+	if nameOrURL == "" {
+		return config.PackageSource{}, errors.New("Source parameter was not specified.") //nolint:staticcheck // dotnet's message
+	}
+
+	if source, ok := findListableSource(listableSources(workingDir), nameOrURL); ok {
+		return source, nil
+	}
+	if !strings.Contains(nameOrURL, "://") && !filepath.IsAbs(nameOrURL) {
+		return config.PackageSource{}, fmt.Errorf("package source with name '%s' not found", nameOrURL)
+	}
+	// A source URL is used as given
+	return config.PackageSource{Key: nameOrURL, Value: nameOrURL}, nil
+}
+
+// confirmed reads an answer to a (y/N) prompt; only yes confirms.
+func confirmed(in io.Reader) bool {
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// statusName returns the name .NET gives an HTTP status code, such as NotFound.
+func statusName(code int) string {
+	if text := http.StatusText(code); text != "" {
+		return strings.ReplaceAll(strings.ReplaceAll(text, " ", ""), "-", "")
+	}
+	return fmt.Sprint(code)
+}
+
+// init registers the package delete subcommand with the package parent command
+func init() {
+	packageCmd := GetPackageCommand()
+	packageCmd.AddCommand(NewPackageDeleteCommand())
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// deleteRecorder records the DELETE requests a test feed receives.
+type deleteRecorder struct {
+	mu      sync.Mutex
+	paths   []string
+	apiKeys []string
+}
+
+func (d *deleteRecorder) record(r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paths = append(d.paths, r.URL.Path)
+	d.apiKeys = append(d.apiKeys, r.Header.Get("X-NuGet-ApiKey"))
+}
+
+// newPublishFeed serves a V3 feed with a PackagePublish resource that answers deletes with status.
+func newPublishFeed(t *testing.T, status int, deletes *deleteRecorder) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/index.json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": "http://" + r.Host + "/api/v2/package", "@type": "PackagePublish/2.0.0"},
+				},
+			})
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v2/package/"):
+			deletes.record(r)
+			w.WriteHeader(status)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPackageDelete_StatusCodes(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	tests := []struct {
+		status  int
+		wantErr string
+		wantOut string
+	}{
+		{http.StatusOK, "", "  OK "},
+		{http.StatusAccepted, "", "  OK "},
+		{http.StatusForbidden, "Response status code does not indicate success: 403 (Forbidden).", "  Forbidden "},
+		{http.StatusNotFound, "package My.Package 1.0.0-beta was not found at the source: Response status code does not indicate success: 404 (Not Found).", "  NotFound "},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			deletes := &deleteRecorder{}
+			server := newPublishFeed(t, tt.status, deletes)
+
+			var out bytes.Buffer
+			opts := &PackageDeleteOptions{Source: server.URL + "/index.json", APIKey: "secret", NonInteractive: true}
+			err := runPackageDelete(t.Context(), "My.Package", "1.0.0-beta", opts, strings.NewReader(""), &out)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("runPackageDelete() error = %v", err)
+				}
+				if !strings.Contains(out.String(), "My.Package 1.0.0-beta was deleted successfully.") {
+					t.Errorf("output missing success message:\n%s", out.String())
+				}
+			} else if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("runPackageDelete() error = %v, want %q", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantOut+server.URL+"/api/v2/package/My.Package/1.0.0-beta") {
+				t.Errorf("output missing %q line:\n%s", tt.wantOut, out.String())
+			}
+
+			if len(deletes.paths) != 1 || deletes.paths[0] != "/api/v2/package/My.Package/1.0.0-beta" {
+				t.Errorf("DELETE requests = %v", deletes.paths)
+			}
+			if len(deletes.apiKeys) == 1 && deletes.apiKeys[0] != "secret" {
+				t.Errorf("X-NuGet-ApiKey = %q, want secret", deletes.apiKeys[0])
+			}
+		})
+	}
+}
+
+func TestPackageDelete_Prompt(t *testing.T) {
+	t.Chdir(t.TempDir())
+	deletes := &deleteRecorder{}
+	server := newPublishFeed(t, http.StatusOK, deletes)
+
+	var out bytes.Buffer
+	opts := &PackageDeleteOptions{Source: server.URL + "/index.json"}
+	if err := runPackageDelete(t.Context(), "My.Package", "1.0.0", opts, strings.NewReader("n\n"), &out); err != nil {
+		t.Fatalf("runPackageDelete() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Would you like to continue? (y/N)") || !strings.Contains(out.String(), "Delete canceled") {
+		t.Errorf("output = %q", out.String())
+	}
+	if !strings.Contains(out.String(), "No API Key was provided") {
+		t.Errorf("output missing API key warning: %q", out.String())
+	}
+	if len(deletes.paths) != 0 {
+		t.Errorf("declined delete sent %v", deletes.paths)
+	}
+
+	out.Reset()
+	if err := runPackageDelete(t.Context(), "My.Package", "1.0.0", opts, strings.NewReader("y\n"), &out); err != nil {
+		t.Fatalf("runPackageDelete() error = %v", err)
+	}
+	if len(deletes.paths) != 1 {
+		t.Errorf("confirmed delete sent %v", deletes.paths)
+	}
+}
+
+func TestPackageDelete_V2Fallback(t *testing.T) {
+	t.Chdir(t.TempDir())
+	deletes := &deleteRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><service xmlns="http://www.w3.org/2007/app"/>`))
+		case r.Method == http.MethodDelete:
+			deletes.record(r)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	var out bytes.Buffer
+	opts := &PackageDeleteOptions{Source: server.URL, APIKey: "secret", NonInteractive: true}
+	if err := runPackageDelete(t.Context(), "My.Package", "2.0.0", opts, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("runPackageDelete() error = %v\n%s", err, out.String())
+	}
+	if len(deletes.paths) != 1 || deletes.paths[0] != "/api/v2/package/My.Package/2.0.0" {
+		t.Errorf("DELETE requests = %v", deletes.paths)
+	}
+}
+
+func TestPackageDelete_NoSource(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var out bytes.Buffer
+	err := runPackageDelete(t.Context(), "My.Package", "1.0.0", &PackageDeleteOptions{NonInteractive: true}, strings.NewReader(""), &out)
+	if err == nil || err.Error() != "Source parameter was not specified." {
+		t.Errorf("runPackageDelete() error = %v", err)
+	}
+}
+
+func TestDelete_TopLevelCommand(t *testing.T) {
+	t.Chdir(t.TempDir())
+	deletes := &deleteRecorder{}
+	server := newPublishFeed(t, http.StatusNoContent, deletes)
+
+	var out bytes.Buffer
+	cmd := NewDeleteCommand()
+	cmd.SetArgs([]string{"My.Package", "3.1.0", "--source", server.URL + "/index.json", "--api-key", "secret"})
+	cmd.SetIn(strings.NewReader("y\n"))
+	cmd.SetOut(&out)
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatalf("delete error = %v\n%s", err, out.String())
+	}
+
+	if len(deletes.paths) != 1 || deletes.paths[0] != "/api/v2/package/My.Package/3.1.0" {
+		t.Errorf("DELETE requests = %v", deletes.paths)
+	}
+	if len(deletes.apiKeys) != 1 || deletes.apiKeys[0] != "secret" {
+		t.Errorf("X-NuGet-ApiKey = %v, want secret", deletes.apiKeys)
+	}
+	if !strings.Contains(out.String(), "My.Package 3.1.0 was deleted successfully.") {
+		t.Errorf("output missing success message:\n%s", out.String())
+	}
+}
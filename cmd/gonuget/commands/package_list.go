@@ -1,22 +1,46 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
 	"github.com/willibrandon/gonuget/cmd/gonuget/output"
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/core"
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/restore"
 	"github.com/willibrandon/gonuget/solution"
+	"github.com/willibrandon/gonuget/version"
 )
 
 // PackageListOptions holds the configuration for the package list command.
 type PackageListOptions struct {
 	ProjectPath string
 	Format      string
+	Source      string // When set, the argument is a package ID whose versions are listed from this source only
+
+	IncludeTransitive bool // Also list the packages the project's packages depend on
+	Outdated          bool // List only packages with a newer version on the sources
+	Vulnerable        bool // List only packages with known vulnerabilities
+	Prerelease        bool // With Outdated, consider prerelease versions
+	HighestMinor      bool // With Outdated, consider only versions with the same major version
+	HighestPatch      bool // With Outdated, consider only versions with the same major and minor version
+	Offline           bool // With Vulnerable, show the vulnerabilities the last restore recorded instead of querying the sources
+}
+
+// requiresAssets reports whether the options need the resolved versions of project.assets.json.
+func (o *PackageListOptions) requiresAssets() bool {
+	return o.IncludeTransitive || o.Outdated || o.Vulnerable
 }
 
 // NewPackageListCommand creates the 'package list' subcommand.
@@ -28,31 +52,80 @@ func NewPackageListCommand() *cobra.Command {
 		Short: "List package references in a project file",
 		Long: `List all NuGet package references in a .NET project file.
 
-This command displays all package references from a .NET project file (.csproj, .fsproj, .vbproj).
+This command displays the package references of a .NET project file (.csproj, .fsproj,
+.vbproj), or of every project of a solution, with the version each project requests and,
+once the project is restored, the version its project.assets.json resolved. Like dotnet
+list package, --include-transitive also lists the packages those packages depend on.
 Output can be formatted as console (human-readable) or JSON.
 
+--outdated lists the packages that have a newer version on the configured sources. Stable
+packages are only compared with stable versions unless --prerelease is given;
+--highest-minor and --highest-patch keep the major, or major and minor, version.
+--vulnerable lists the packages with known vulnerabilities, from the sources that publish
+vulnerability data, and how each vulnerable transitive package is brought in. Both need a
+restored project. With --offline, or when the sources can't be reached, --vulnerable
+shows the vulnerabilities the last restore recorded instead, and when it recorded them.
+
+With --source, the argument is a package ID instead, and the versions of that
+package are listed from the one named source only (a name from NuGet.config, or
+a source URL). No other source is queried, so this shows exactly what that feed
+has. Disabled sources can be queried by name.
+
 Examples:
   gonuget package list
   gonuget package list --project MyProject.csproj
-  gonuget package list --format json`,
+  gonuget package list --include-transitive
+  gonuget package list --outdated --highest-minor
+  gonuget package list --vulnerable --include-transitive --format json
+  gonuget package list --vulnerable --offline
+  gonuget package list --format json
+  gonuget package list Newtonsoft.Json --source nuget.org
+  gonuget package list MyCompany.Core --source MyInternalFeed --format json`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			// With --source the positional argument is a package ID
+			if opts.Source != "" {
+				if len(args) != 1 {
+					return fmt.Errorf("a package ID is required when --source is specified")
+				}
+				return runPackageVersionList(cmd.Context(), args[0], opts, cmd.OutOrStdout())
+			}
+
+			for name, set := range map[string]bool{"prerelease": opts.Prerelease, "highest-minor": opts.HighestMinor, "highest-patch": opts.HighestPatch} {
+				if set && !opts.Outdated {
+					return fmt.Errorf("--%s can only be used with --outdated", name)
+				}
+			}
+
+			if opts.Offline && !opts.Vulnerable {
+				return fmt.Errorf("--offline can only be used with --vulnerable")
+			}
+
 			// If project is provided as positional arg, use it
 			if len(args) == 1 {
 				opts.ProjectPath = args[0]
 			}
-			return runPackageList(opts, cmd.OutOrStdout())
+			return runPackageList(cmd.Context(), opts, cmd.OutOrStdout())
 		},
 	}
 
 	cmd.Flags().StringVar(&opts.ProjectPath, "project", "", "The project file to operate on (defaults to current directory)")
 	cmd.Flags().StringVar(&opts.Format, "format", "console", "Output format: console or json")
+	cmd.Flags().StringVarP(&opts.Source, "source", "s", "", "List the versions of a package ID from this source only")
+	cmd.Flags().BoolVar(&opts.IncludeTransitive, "include-transitive", false, "Also list transitive packages")
+	cmd.Flags().BoolVar(&opts.Outdated, "outdated", false, "List packages that have newer versions on the sources")
+	cmd.Flags().BoolVar(&opts.Vulnerable, "vulnerable", false, "List packages that have known vulnerabilities")
+	cmd.Flags().BoolVar(&opts.Prerelease, "prerelease", false, "Consider prerelease versions when looking for newer packages")
+	cmd.Flags().BoolVar(&opts.HighestMinor, "highest-minor", false, "Consider only versions with a matching major version when looking for newer packages")
+	cmd.Flags().BoolVar(&opts.HighestPatch, "highest-patch", false, "Consider only versions with matching major and minor versions when looking for newer packages")
+	cmd.Flags().BoolVar(&opts.Offline, "offline", false, "Show the vulnerabilities recorded by the last restore instead of querying the sources")
+	cmd.MarkFlagsMutuallyExclusive("outdated", "vulnerable")
 
 	return cmd
 }
 
 // runPackageList implements the package list command logic.
-func runPackageList(opts *PackageListOptions, w io.Writer) error {
+func runPackageList(ctx context.Context, opts *PackageListOptions, w io.Writer) error {
 	start := time.Now()
 
 	// Find the project or solution file
@@ -87,121 +160,181 @@ func runPackageList(opts *PackageListOptions, w io.Writer) error {
 
 	// Check if it's a solution file
 	if solution.IsSolutionFile(targetPath) {
-		return runPackageListForSolution(targetPath, opts.Format, start, w)
+		return runPackageListForSolution(ctx, targetPath, opts, start, w)
 	}
 
 	// Handle as a single project file
-	return runPackageListForProject(targetPath, opts.Format, start, w)
+	return runPackageListForProject(ctx, targetPath, opts, start, w)
 }
 
-// runPackageListForSolution handles listing packages for all projects in a solution
-func runPackageListForSolution(solutionPath string, format string, start time.Time, w io.Writer) error {
-	// Parse the solution file
-	sol, err := solution.ParseSolution(solutionPath)
-	if err != nil {
-		return fmt.Errorf("failed to parse solution %s: %w", solutionPath, err)
-	}
+// listedPackage is a package of one framework of a project, as package list reports it.
+type listedPackage struct {
+	ID              string
+	Requested       string // Version the project file requests; empty for a transitive package
+	Resolved        string // Version project.assets.json resolved; empty before a restore
+	Latest          string // Newest version on the sources within the update constraints (--outdated)
+	Vulnerabilities []core.PackageVulnerability
+	Paths           [][]string // Chains of "ID/Version" from top-level packages to a vulnerable transitive package
+}
 
-	// Get all .NET project paths (excluding solution folders)
-	projectPaths := sol.GetProjects()
-	if len(projectPaths) == 0 {
-		_, _ = fmt.Fprintf(w, "Solution '%s' contains no projects.\n", filepath.Base(solutionPath))
-		return nil
-	}
+// listedFramework holds the packages of one target framework of a project.
+type listedFramework struct {
+	Framework  string
+	TopLevel   []listedPackage
+	Transitive []listedPackage
+}
 
-	// Process projects
-	if format == "json" {
-		return outputSolutionPackageListJSON(solutionPath, sol, projectPaths, start, w)
-	}
+// projectPackages holds the packages of a project, per target framework.
+type projectPackages struct {
+	Path       string // Absolute path of the project file
+	Restored   bool   // Whether resolved versions were read from project.assets.json
+	Frameworks []listedFramework
 
-	return outputSolutionPackageListConsole(solutionPath, sol, projectPaths, w)
+	assets *restore.LockFile // The project.assets.json resolved versions were read from
+
+	// Vulnerability data recorded by restore, shown instead of the sources' (--vulnerable)
+	AuditedAt time.Time // When restore recorded it; zero for data from the sources
+	Unaudited bool      // The last restore recorded no vulnerability data
 }
 
-// runPackageListForProject handles listing packages for a single project
-func runPackageListForProject(projectPath string, format string, start time.Time, w io.Writer) error {
-	// Make path absolute for consistent output
+// errNoAssetsFile is returned for a project that hasn't been restored.
+func errNoAssetsFile(projectPath string) error {
+	return fmt.Errorf("No assets file was found for `%s`. Please run restore before running this command.", projectPath) //nolint:staticcheck // matches dotnet list package
+}
+
+// loadProjectPackages reads the package references of a project and, when the project has
+// been restored, the versions its project.assets.json resolved them and their
+// dependencies to. Transitive packages are listed with includeTransitive.
+func loadProjectPackages(projectPath string, includeTransitive bool) (*projectPackages, error) {
 	absPath, err := filepath.Abs(projectPath)
 	if err != nil {
 		absPath = projectPath
 	}
 
-	// Load the project
-	proj, err := project.LoadProject(projectPath)
+	proj, err := project.LoadProject(absPath)
 	if err != nil {
-		return fmt.Errorf("failed to load project %s: %w", projectPath, err)
+		return nil, fmt.Errorf("failed to load project %s: %w", projectPath, err)
 	}
 
-	// Get package references
-	packageRefs := proj.GetPackageReferences()
-
-	// Get target framework
-	framework := proj.TargetFramework
-	if framework == "" {
-		return fmt.Errorf("project does not specify a TargetFramework")
+	// Requested versions come from the project, with Central Package Management applied
+	refs, err := restore.PackageReferences(proj)
+	if err != nil {
+		refs = proj.GetPackageReferences()
 	}
-
-	// Output based on format
-	if format == "json" {
-		return outputPackageListJSON(absPath, framework, packageRefs, start, w)
+	requested := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		requested[strings.ToLower(ref.Include)] = ref.Version
 	}
 
-	return outputPackageListConsole(projectPath, packageRefs, w)
-}
-
-// outputPackageListConsole outputs package references in human-readable format
-func outputPackageListConsole(projectPath string, packageRefs []project.PackageReference, w io.Writer) error {
-	_, _ = fmt.Fprintf(w, "Project '%s' has the following package references:\n", filepath.Base(projectPath))
-	_, _ = fmt.Fprintln(w)
-
-	if len(packageRefs) == 0 {
-		_, _ = fmt.Fprintln(w, "   [No package references found]")
-		return nil
+	result := &projectPackages{Path: absPath}
+	assets, err := restore.LoadLockFile(restore.GetAssetsFilePath(absPath))
+	if errors.Is(err, fs.ErrNotExist) {
+		frameworks := proj.GetTargetFrameworks()
+		if len(frameworks) == 0 {
+			return nil, fmt.Errorf("project does not specify a TargetFramework")
+		}
+		for _, framework := range frameworks {
+			listed := listedFramework{Framework: framework}
+			for _, ref := range refs {
+				listed.TopLevel = append(listed.TopLevel, listedPackage{ID: ref.Include, Requested: ref.Version})
+			}
+			result.Frameworks = append(result.Frameworks, listed)
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the assets file of %s: %w", projectPath, err)
 	}
+	result.Restored = true
+	result.assets = assets
 
-	for _, ref := range packageRefs {
-		if ref.Version != "" {
-			_, _ = fmt.Fprintf(w, "   %s %s\n", ref.Include, ref.Version)
-		} else {
-			_, _ = fmt.Fprintf(w, "   %s (version managed centrally)\n", ref.Include)
+	frameworks := assets.Project.Restore.OriginalTargetFrameworks
+	if len(frameworks) == 0 {
+		for framework := range assets.Project.Frameworks {
+			frameworks = append(frameworks, framework)
 		}
+		slices.Sort(frameworks)
 	}
 
-	return nil
-}
+	for _, framework := range frameworks {
+		// Resolved packages of the framework, by lowercase ID
+		resolved := make(map[string]listedPackage)
+		for key, lib := range assets.Targets[framework] {
+			id, ver, ok := strings.Cut(key, "/")
+			if ok && lib.Type == "package" {
+				resolved[strings.ToLower(id)] = listedPackage{ID: id, Resolved: ver}
+			}
+		}
 
-// outputPackageListJSON outputs package references in JSON format matching schema
-func outputPackageListJSON(projectPath, framework string, packageRefs []project.PackageReference, start time.Time, w io.Writer) error {
-	jsonOutput := output.NewPackageListOutput(projectPath, framework, start)
-
-	// Convert project.PackageReference to output.PackageReference
-	for _, ref := range packageRefs {
-		jsonOutput.Packages = append(jsonOutput.Packages, output.PackageReference{
-			ID:              ref.Include,
-			Version:         ref.Version,
-			Type:            "direct", // All references from .csproj are direct
-			ResolvedVersion: ref.Version,
-		})
+		listed := listedFramework{Framework: framework}
+		direct := make(map[string]bool)
+		for id, dependency := range assets.Project.Frameworks[framework].Dependencies {
+			if dependency.Target != "" && dependency.Target != "Package" {
+				continue
+			}
+			key := strings.ToLower(id)
+			direct[key] = true
+			requestedVersion, ok := requested[key]
+			if !ok {
+				requestedVersion = requestedVersionText(dependency.Version)
+			}
+			listed.TopLevel = append(listed.TopLevel, listedPackage{
+				ID:        id,
+				Requested: requestedVersion,
+				Resolved:  resolved[key].Resolved,
+			})
+		}
+		if includeTransitive {
+			for key, pkg := range resolved {
+				if !direct[key] {
+					listed.Transitive = append(listed.Transitive, pkg)
+				}
+			}
+		}
+
+		sortListedPackages(listed.TopLevel)
+		sortListedPackages(listed.Transitive)
+		result.Frameworks = append(result.Frameworks, listed)
 	}
+	return result, nil
+}
 
-	// Update elapsed time
-	jsonOutput.ElapsedMs = output.MeasureElapsed(start)
+// requestedVersionText shows a dependency range from project.assets.json as the project
+// file would write it: "[1.0.0, )", a minimum version, is shown as "1.0.0".
+func requestedVersionText(versionRange string) string {
+	parsed, err := version.ParseVersionRange(versionRange)
+	if err != nil || parsed.MinVersion == nil || !parsed.MinInclusive || parsed.MaxVersion != nil {
+		return versionRange
+	}
+	return parsed.MinVersion.String()
+}
 
-	// Write JSON to writer
-	return output.WriteJSON(w, jsonOutput)
+// sortListedPackages sorts packages by ID, ignoring case.
+func sortListedPackages(packages []listedPackage) {
+	slices.SortFunc(packages, func(a, b listedPackage) int {
+		return strings.Compare(strings.ToLower(a.ID), strings.ToLower(b.ID))
+	})
 }
 
-// outputSolutionPackageListConsole outputs packages from all projects in console format
-func outputSolutionPackageListConsole(solutionPath string, sol *solution.Solution, projectPaths []string, w io.Writer) error {
-	_, _ = fmt.Fprintf(w, "Solution '%s' has the following package references:\n", filepath.Base(solutionPath))
-	_, _ = fmt.Fprintln(w)
+// runPackageListForSolution handles listing packages for all projects in a solution
+func runPackageListForSolution(ctx context.Context, solutionPath string, opts *PackageListOptions, start time.Time, w io.Writer) error {
+	// Parse the solution file
+	sol, err := solution.ParseSolution(solutionPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse solution %s: %w", solutionPath, err)
+	}
 
-	totalPackages := 0
-	successCount := 0
+	// Get all .NET project paths (excluding solution folders)
+	projectPaths := sol.GetProjects()
+	if len(projectPaths) == 0 {
+		_, _ = fmt.Fprintf(w, "Solution '%s' contains no projects.\n", filepath.Base(solutionPath))
+		return nil
+	}
 
 	// Create warning writer for stderr output
 	warningWriter := output.NewWarningWriter()
 
-	// Process each project
+	var projects []*projectPackages
 	for _, projectPath := range projectPaths {
 		// Make absolute path based on solution directory
 		absProjectPath := projectPath
@@ -211,111 +344,472 @@ func outputSolutionPackageListConsole(solutionPath string, sol *solution.Solutio
 
 		// Check if project file exists
 		if _, err := os.Stat(absProjectPath); os.IsNotExist(err) {
-			// Show warning for missing project file (T041: warning output formatting)
 			warningWriter.WriteMissingProjectWarning(absProjectPath)
 			continue
 		}
 
-		// Load the project
-		proj, err := project.LoadProject(absProjectPath)
+		packages, err := loadProjectPackages(absProjectPath, opts.IncludeTransitive)
 		if err != nil {
 			// Skip projects that can't be loaded
-			_, _ = fmt.Fprintf(w, "   [Warning: Could not load project %s]\n", filepath.Base(projectPath))
+			warningWriter.WriteProjectWarning(absProjectPath, err.Error())
+			continue
+		}
+		if !packages.Restored && opts.requiresAssets() {
+			warningWriter.Warning("%s", errNoAssetsFile(absProjectPath))
 			continue
 		}
+		projects = append(projects, packages)
+	}
 
-		successCount++
-		packageRefs := proj.GetPackageReferences()
+	sources, err := findPackageUpdates(ctx, sol.SolutionDir, projects, opts)
+	if err != nil {
+		return err
+	}
 
-		if len(packageRefs) > 0 {
-			_, _ = fmt.Fprintf(w, "   Project '%s' has the following package references:\n", filepath.Base(projectPath))
-			for _, ref := range packageRefs {
-				if ref.Version != "" {
-					_, _ = fmt.Fprintf(w, "      > %s %s\n", ref.Include, ref.Version)
-				} else {
-					_, _ = fmt.Fprintf(w, "      > %s (version managed centrally)\n", ref.Include)
-				}
-				totalPackages++
-			}
+	if opts.Format == "json" {
+		type solutionOutput struct {
+			Solution  string                     `json:"solution"`
+			Sources   []string                   `json:"sources,omitempty"`
+			Projects  []output.PackageListOutput `json:"projects"`
+			ElapsedMs int64                      `json:"elapsedMs"`
+		}
+		result := solutionOutput{
+			Solution: solutionPath,
+			Sources:  sources,
+			Projects: []output.PackageListOutput{},
+		}
+		for _, packages := range projects {
+			result.Projects = append(result.Projects, *packageListJSON(packages, opts, start))
+		}
+		result.ElapsedMs = output.MeasureElapsed(start)
+		return output.WriteJSON(w, result)
+	}
+
+	writeSourcesUsed(w, sources)
+	if len(projects) == 0 {
+		_, _ = fmt.Fprintln(w, "No projects of the solution could be listed.")
+	}
+	for i, packages := range projects {
+		if i > 0 {
 			_, _ = fmt.Fprintln(w)
 		}
+		writeProjectPackagesConsole(w, packages, opts)
+	}
+	return nil
+}
+
+// runPackageListForProject handles listing packages for a single project
+func runPackageListForProject(ctx context.Context, projectPath string, opts *PackageListOptions, start time.Time, w io.Writer) error {
+	packages, err := loadProjectPackages(projectPath, opts.IncludeTransitive)
+	if err != nil {
+		return err
+	}
+	if !packages.Restored && opts.requiresAssets() {
+		return errNoAssetsFile(packages.Path)
 	}
 
-	if totalPackages == 0 {
-		_, _ = fmt.Fprintln(w, "   [No package references found in any projects]")
-	} else {
-		_, _ = fmt.Fprintf(w, "Total packages: %d across %d projects\n", totalPackages, successCount)
+	sources, err := findPackageUpdates(ctx, filepath.Dir(packages.Path), []*projectPackages{packages}, opts)
+	if err != nil {
+		return err
 	}
 
+	if opts.Format == "json" {
+		jsonOutput := packageListJSON(packages, opts, start)
+		jsonOutput.Sources = sources
+		return output.WriteJSON(w, jsonOutput)
+	}
+
+	writeSourcesUsed(w, sources)
+	writeProjectPackagesConsole(w, packages, opts)
 	return nil
 }
 
-// outputSolutionPackageListJSON outputs packages from all projects in JSON format
-func outputSolutionPackageListJSON(solutionPath string, sol *solution.Solution, projectPaths []string, start time.Time, w io.Writer) error {
-	// Create a combined output structure
-	type solutionOutput struct {
-		Solution  string                     `json:"solution"`
-		Projects  []output.PackageListOutput `json:"projects"`
-		ElapsedMs int64                      `json:"elapsedMs"`
+// reportedPackages returns the packages --outdated or --vulnerable report: those with a
+// newer version or with known vulnerabilities. Without either option, all packages.
+func reportedPackages(packages []listedPackage, opts *PackageListOptions) []listedPackage {
+	if !opts.Outdated && !opts.Vulnerable {
+		return packages
+	}
+	var reported []listedPackage
+	for _, pkg := range packages {
+		if (opts.Outdated && pkg.Latest != "") || (opts.Vulnerable && len(pkg.Vulnerabilities) > 0) {
+			reported = append(reported, pkg)
+		}
 	}
+	return reported
+}
 
-	result := solutionOutput{
-		Solution: solutionPath,
-		Projects: []output.PackageListOutput{},
+// writeSourcesUsed lists the sources --outdated or --vulnerable queried.
+func writeSourcesUsed(w io.Writer, sources []string) {
+	if len(sources) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "The following sources were used:")
+	for _, source := range sources {
+		_, _ = fmt.Fprintf(w, "   %s\n", source)
 	}
+	_, _ = fmt.Fprintln(w)
+}
 
-	// Create warning writer for stderr output
-	warningWriter := output.NewWarningWriter()
+// writeProjectPackagesConsole outputs the packages of a project in human-readable format,
+// in tables per target framework like dotnet list package.
+func writeProjectPackagesConsole(w io.Writer, packages *projectPackages, opts *PackageListOptions) {
+	name := filepath.Base(packages.Path)
 
-	// Process each project
-	for _, projectPath := range projectPaths {
-		// Make absolute path based on solution directory
-		absProjectPath := projectPath
-		if !filepath.IsAbs(projectPath) {
-			absProjectPath = filepath.Join(sol.SolutionDir, projectPath)
+	var blocks []listedFramework
+	for _, framework := range packages.Frameworks {
+		framework.TopLevel = reportedPackages(framework.TopLevel, opts)
+		framework.Transitive = reportedPackages(framework.Transitive, opts)
+		if (opts.Outdated || opts.Vulnerable) && len(framework.TopLevel) == 0 && len(framework.Transitive) == 0 {
+			continue
+		}
+		blocks = append(blocks, framework)
+	}
+
+	if opts.Vulnerable {
+		if packages.Unaudited {
+			_, _ = fmt.Fprintf(w, "The last restore of '%s' recorded no vulnerability data. Run restore with access to the sources to record it.\n", name)
+			return
 		}
+		if !packages.AuditedAt.IsZero() {
+			_, _ = fmt.Fprintf(w, "Vulnerabilities recorded by the last restore at %s (%s ago).\n",
+				packages.AuditedAt.Local().Format("2006-01-02 15:04:05"), formatAge(time.Since(packages.AuditedAt)))
+		}
+	}
 
-		// Check if project file exists
-		if _, err := os.Stat(absProjectPath); os.IsNotExist(err) {
-			// Show warning for missing project file (JSON mode still shows warnings to stderr)
-			warningWriter.WriteMissingProjectWarning(absProjectPath)
+	switch {
+	case opts.Outdated && len(blocks) == 0:
+		_, _ = fmt.Fprintf(w, "The given project '%s' has no updates given the current sources.\n", name)
+		return
+	case opts.Vulnerable && len(blocks) == 0:
+		_, _ = fmt.Fprintf(w, "The given project '%s' has no vulnerable packages given the current sources.\n", name)
+		return
+	case opts.Outdated:
+		_, _ = fmt.Fprintf(w, "Project '%s' has the following updates to its packages\n", name)
+	case opts.Vulnerable:
+		_, _ = fmt.Fprintf(w, "Project '%s' has the following vulnerable packages\n", name)
+	default:
+		_, _ = fmt.Fprintf(w, "Project '%s' has the following package references\n", name)
+	}
+
+	for _, framework := range blocks {
+		if len(framework.TopLevel) == 0 && len(framework.Transitive) == 0 {
+			_, _ = fmt.Fprintf(w, "   [%s]: No packages were found for this framework.\n", framework.Framework)
 			continue
 		}
+		_, _ = fmt.Fprintf(w, "   [%s]:\n", framework.Framework)
+		if len(framework.TopLevel) > 0 {
+			writePackageTable(w, "Top-level Package", framework.TopLevel, true, packages.Restored, opts)
+		}
+		if len(framework.Transitive) > 0 {
+			_, _ = fmt.Fprintln(w)
+			writePackageTable(w, "Transitive Package", framework.Transitive, false, packages.Restored, opts)
+			writeDependencyPaths(w, framework.Transitive)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
 
-		// Load the project
-		proj, err := project.LoadProject(absProjectPath)
-		if err != nil {
-			// Skip projects that can't be loaded
+	if !packages.Restored {
+		_, _ = fmt.Fprintf(w, "Run restore to see the resolved versions of '%s'.\n", name)
+	}
+}
+
+// writeDependencyPaths writes how each of the packages that has dependency paths is
+// brought in by the top-level packages.
+func writeDependencyPaths(w io.Writer, packages []listedPackage) {
+	for _, pkg := range packages {
+		if len(pkg.Paths) == 0 {
 			continue
 		}
+		_, _ = fmt.Fprintf(w, "\n   %s (%s) is brought in by:\n", pkg.ID, pkg.Resolved)
+		for _, path := range pkg.Paths {
+			_, _ = fmt.Fprintf(w, "      %s\n", formatDependencyPath(path))
+		}
+	}
+}
+
+// formatDependencyPath writes a chain of "ID/Version" keys as
+// "Contoso.App (1.0.0) > Contoso.Core (2.0.0)".
+func formatDependencyPath(path []string) string {
+	steps := make([]string, len(path))
+	for i, key := range path {
+		id, ver, _ := strings.Cut(key, "/")
+		steps[i] = fmt.Sprintf("%s (%s)", id, ver)
+	}
+	return strings.Join(steps, " > ")
+}
 
-		// Get framework
-		framework := proj.TargetFramework
-		if framework == "" {
-			continue // Skip projects without target framework
+// formatAge describes how long ago something happened, such as "3 hours".
+func formatAge(age time.Duration) string {
+	unit := func(n int, name string) string {
+		if n == 1 {
+			return "1 " + name
 		}
+		return fmt.Sprintf("%d %ss", n, name)
+	}
+	switch {
+	case age < time.Minute:
+		return "less than a minute"
+	case age < time.Hour:
+		return unit(int(age/time.Minute), "minute")
+	case age < 24*time.Hour:
+		return unit(int(age/time.Hour), "hour")
+	default:
+		return unit(int(age/(24*time.Hour)), "day")
+	}
+}
 
-		// Create project output
-		projectOutput := output.NewPackageListOutput(absProjectPath, framework, start)
+// writePackageTable writes a table of packages with aligned columns. Top-level packages
+// show the requested version; the resolved, latest and vulnerability columns depend on
+// the restore and the options.
+func writePackageTable(w io.Writer, title string, packages []listedPackage, topLevel, restored bool, opts *PackageListOptions) {
+	header := []string{title}
+	if topLevel {
+		header = append(header, "Requested")
+	}
+	if restored {
+		header = append(header, "Resolved")
+	}
+	switch {
+	case opts.Outdated:
+		header = append(header, "Latest")
+	case opts.Vulnerable:
+		header = append(header, "Severity", "Advisory URL")
+	}
 
-		// Convert package references
-		for _, ref := range proj.GetPackageReferences() {
-			projectOutput.Packages = append(projectOutput.Packages, output.PackageReference{
-				ID:              ref.Include,
-				Version:         ref.Version,
-				Type:            "direct",
-				ResolvedVersion: ref.Version,
-			})
+	rows := [][]string{header}
+	for _, pkg := range packages {
+		row := []string{"> " + pkg.ID}
+		if topLevel {
+			requested := pkg.Requested
+			if requested == "" {
+				requested = "(version managed centrally)"
+			}
+			row = append(row, requested)
+		}
+		if restored {
+			row = append(row, pkg.Resolved)
 		}
+		switch {
+		case opts.Outdated:
+			row = append(row, pkg.Latest)
+		case opts.Vulnerable:
+			// Further vulnerabilities of the package go on lines of their own
+			for i, vulnerability := range pkg.Vulnerabilities {
+				if i > 0 {
+					rows = append(rows, row)
+					row = make([]string, len(row)-2)
+				}
+				row = append(row, vulnerability.Severity.String(), vulnerability.AdvisoryURL)
+			}
+		}
+		rows = append(rows, row)
+	}
 
-		result.Projects = append(result.Projects, *projectOutput)
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
 	}
+	for _, row := range rows {
+		var line strings.Builder
+		line.WriteString("   ")
+		for i, cell := range row {
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-len(cell)+3))
+			}
+		}
+		_, _ = fmt.Fprintln(w, strings.TrimRight(line.String(), " "))
+	}
+}
 
-	// Update elapsed time
-	result.ElapsedMs = output.MeasureElapsed(start)
+// packageListJSON converts the packages of a project to the JSON output. The framework
+// of each package is given when the project has several.
+func packageListJSON(packages *projectPackages, opts *PackageListOptions, start time.Time) *output.PackageListOutput {
+	framework := ""
+	if len(packages.Frameworks) > 0 {
+		framework = packages.Frameworks[0].Framework
+	}
+	jsonOutput := output.NewPackageListOutput(packages.Path, framework, start)
+	if !packages.Restored {
+		jsonOutput.Warnings = append(jsonOutput.Warnings, fmt.Sprintf("No assets file was found for `%s`. Versions are those the project file requests.", packages.Path))
+	}
+	if opts.Vulnerable && packages.Unaudited {
+		jsonOutput.Warnings = append(jsonOutput.Warnings, fmt.Sprintf("The last restore of `%s` recorded no vulnerability data.", packages.Path))
+	}
+	if !packages.AuditedAt.IsZero() {
+		jsonOutput.AuditedAt = packages.AuditedAt.UTC().Format(time.RFC3339)
+	}
 
-	// Write JSON to writer
-	return output.WriteJSON(w, result)
+	for _, listed := range packages.Frameworks {
+		packageFramework := ""
+		if len(packages.Frameworks) > 1 {
+			packageFramework = listed.Framework
+		}
+		for _, pkg := range reportedPackages(listed.TopLevel, opts) {
+			resolved := pkg.Resolved
+			if !packages.Restored {
+				resolved = pkg.Requested
+			}
+			jsonOutput.Packages = append(jsonOutput.Packages, toJSONPackageReference(pkg, pkg.Requested, "direct", resolved, packageFramework))
+		}
+		// A transitive package has no requested version; the resolved one stands in for it
+		for _, pkg := range reportedPackages(listed.Transitive, opts) {
+			jsonOutput.Packages = append(jsonOutput.Packages, toJSONPackageReference(pkg, pkg.Resolved, "transitive", pkg.Resolved, packageFramework))
+		}
+	}
+
+	jsonOutput.ElapsedMs = output.MeasureElapsed(start)
+	return jsonOutput
+}
+
+// toJSONPackageReference converts a listed package to its JSON output.
+func toJSONPackageReference(pkg listedPackage, requested, referenceType, resolved, framework string) output.PackageReference {
+	ref := output.PackageReference{
+		ID:              pkg.ID,
+		Version:         requested,
+		Type:            referenceType,
+		ResolvedVersion: resolved,
+		Framework:       framework,
+		LatestVersion:   pkg.Latest,
+		Paths:           pkg.Paths,
+	}
+	for _, vulnerability := range pkg.Vulnerabilities {
+		ref.Vulnerabilities = append(ref.Vulnerabilities, output.PackageVulnerability{
+			Severity:    vulnerability.Severity.String(),
+			AdvisoryURL: vulnerability.AdvisoryURL,
+		})
+	}
+	return ref
+}
+
+// runPackageVersionList lists the versions of a package available on a single source.
+func runPackageVersionList(ctx context.Context, packageID string, opts *PackageListOptions, w io.Writer) error {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = "."
+	}
+
+	// Register every configured source under its name, then query only the requested one
+	repoManager := core.NewRepositoryManager()
+	sources := listableSources(workingDir)
+	for _, source := range sources {
+		_ = repoManager.AddRepository(core.NewSourceRepository(core.RepositoryConfig{
+			Name:      source.Key,
+			SourceURL: source.Value,
+		}))
+	}
+
+	source, ok := findListableSource(sources, opts.Source)
+	if !ok {
+		if !strings.Contains(opts.Source, "://") && !filepath.IsAbs(opts.Source) {
+			return fmt.Errorf("package source with name '%s' not found", opts.Source)
+		}
+		// A source URL or local path is used as given
+		source = config.PackageSource{Key: opts.Source, Value: opts.Source}
+		if err := repoManager.AddRepository(core.NewSourceRepository(core.RepositoryConfig{
+			Name:      source.Key,
+			SourceURL: source.Value,
+		})); err != nil {
+			return fmt.Errorf("failed to add repository: %w", err)
+		}
+	}
+
+	client := core.NewClient(core.ClientConfig{
+		RepositoryManager:  repoManager,
+		CredentialProvider: config.NewCredentialProvider(config.LoadConfigLayers(workingDir)),
+	})
+
+	versionStrings, err := client.ListVersionsFromSource(ctx, source.Key, packageID)
+	if err != nil {
+		return fmt.Errorf("failed to list versions of %s from source '%s': %w", packageID, source.Key, err)
+	}
+	versions := sortVersionStrings(versionStrings)
+
+	if opts.Format == "json" {
+		jsonOutput := struct {
+			PackageID string   `json:"packageId"`
+			Source    string   `json:"source"`
+			SourceURL string   `json:"sourceUrl"`
+			Versions  []string `json:"versions"`
+			ElapsedMs int64    `json:"elapsedMs"`
+		}{
+			PackageID: packageID,
+			Source:    source.Key,
+			SourceURL: source.Value,
+			Versions:  versions,
+			ElapsedMs: output.MeasureElapsed(start),
+		}
+		return output.WriteJSON(w, jsonOutput)
+	}
+
+	_, _ = fmt.Fprintf(w, "Package '%s' has the following versions on source '%s':\n", packageID, source.Key)
+	_, _ = fmt.Fprintln(w)
+	for _, v := range versions {
+		_, _ = fmt.Fprintf(w, "   %s\n", v)
+	}
+
+	return nil
+}
+
+// listableSources returns all sources in the NuGet.config hierarchy, including disabled ones,
+// falling back to the default sources when none are configured.
+func listableSources(workingDir string) []config.PackageSource {
+	if sources := config.MergePackageSources(config.LoadConfigLayers(workingDir)); len(sources) > 0 {
+		return sources
+	}
+	return config.GetEnabledSourcesOrDefault(workingDir)
+}
+
+// findListableSource finds a source by name (case-insensitive, as in NuGet) or by URL.
+func findListableSource(sources []config.PackageSource, nameOrURL string) (config.PackageSource, bool) {
+	for _, source := range sources {
+		if strings.EqualFold(source.Key, nameOrURL) ||
+			nugethttp.SameSourceURL(source.Value, nameOrURL) {
+			return source, true
+		}
+	}
+	return config.PackageSource{}, false
+}
+
+// sortVersionStrings sorts versions in NuGet order, lowest first.
+// Strings that are not valid versions are kept, after the valid ones.
+func sortVersionStrings(versionStrings []string) []string {
+	type parsedVersion struct {
+		text string
+		v    *version.NuGetVersion
+	}
+
+	parsed := make([]parsedVersion, 0, len(versionStrings))
+	for _, s := range versionStrings {
+		v, _ := version.Parse(s)
+		parsed = append(parsed, parsedVersion{text: s, v: v})
+	}
+
+	slices.SortStableFunc(parsed, func(a, b parsedVersion) int {
+		switch {
+		case a.v == nil && b.v == nil:
+			return strings.Compare(a.text, b.text)
+		case a.v == nil:
+			return 1
+		case b.v == nil:
+			return -1
+		}
+		return a.v.Compare(b.v)
+	})
+
+	sorted := make([]string, len(parsed))
+	for i, p := range parsed {
+		sorted[i] = p.text
+	}
+	return sorted
 }
 
 // init registers the package list subcommand with the package parent command
@@ -0,0 +1,476 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+	"github.com/willibrandon/gonuget/restore"
+)
+
+// newVersionsFeed serves a V3 feed whose registration lists versions for a single package.
+func newVersionsFeed(t *testing.T, packageID string, versions ...string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := "http://" + r.Host
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/index.json":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": base + "/registration/", "@type": "RegistrationsBaseUrl/3.6.0"},
+				},
+			})
+		case "/registration/" + strings.ToLower(packageID) + "/index.json":
+			items := make([]map[string]any, 0, len(versions))
+			for _, v := range versions {
+				items = append(items, map[string]any{
+					"@id":          base + "/registration/" + strings.ToLower(packageID) + "/" + v + ".json",
+					"catalogEntry": map[string]any{"id": packageID, "version": v},
+				})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"count": 1,
+				"items": []map[string]any{{
+					"@id":   base + "/registration/" + strings.ToLower(packageID) + "/index.json#page",
+					"lower": versions[0],
+					"upper": versions[len(versions)-1],
+					"count": len(items),
+					"items": items,
+				}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPackageList_SourceScopedVersions(t *testing.T) {
+	internal := newVersionsFeed(t, "MyCompany.Core", "1.0.0", "1.2.0")
+	public := newVersionsFeed(t, "MyCompany.Core", "2.0.0", "1.10.0", "1.9.0-beta")
+
+	tmpDir := t.TempDir()
+	nugetConfig := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+    <add key="MyInternalFeed" value="` + internal.URL + `/index.json" />
+    <add key="public" value="` + public.URL + `/index.json" />
+  </packageSources>
+</configuration>`
+	if err := os.WriteFile(filepath.Join(tmpDir, "NuGet.config"), []byte(nugetConfig), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Chdir(tmpDir)
+
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := NewPackageListCommand()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	// Only the named source is queried (source names are case-insensitive)
+	out, err := run("MyCompany.Core", "--source", "myinternalfeed")
+	if err != nil {
+		t.Fatalf("package list --source error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Package 'MyCompany.Core' has the following versions on source 'MyInternalFeed':") {
+		t.Errorf("unexpected header:\n%s", out)
+	}
+	if strings.Contains(out, "2.0.0") {
+		t.Errorf("versions from another source listed:\n%s", out)
+	}
+
+	// JSON output, sorted in NuGet version order
+	out, err = run("MyCompany.Core", "--source", "public", "--format", "json")
+	if err != nil {
+		t.Fatalf("package list --source --format json error = %v\n%s", err, out)
+	}
+	var result struct {
+		Source    string   `json:"source"`
+		SourceURL string   `json:"sourceUrl"`
+		Versions  []string `json:"versions"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if want := []string{"1.9.0-beta", "1.10.0", "2.0.0"}; !slices.Equal(result.Versions, want) {
+		t.Errorf("versions = %v, want %v", result.Versions, want)
+	}
+	if result.Source != "public" || result.SourceURL != public.URL+"/index.json" {
+		t.Errorf("source = %q (%q), want public", result.Source, result.SourceURL)
+	}
+
+	// A package the source doesn't have fails, naming the source
+	if _, err := run("Other.Package", "--source", "MyInternalFeed"); err == nil || !strings.Contains(err.Error(), "from source 'MyInternalFeed'") {
+		t.Errorf("missing package error = %v", err)
+	}
+
+	if _, err := run("MyCompany.Core", "--source", "missing"); err == nil || !strings.Contains(err.Error(), "'missing' not found") {
+		t.Errorf("unknown source error = %v", err)
+	}
+	if _, err := run("--source", "public"); err == nil {
+		t.Error("expected error without a package ID")
+	}
+}
+
+// newUpdatesFeed serves a V3 feed listing versions for several packages, with a
+// VulnerabilityInfo resource whose single page is vulnerabilities.
+func newUpdatesFeed(t *testing.T, versions map[string][]string, vulnerabilities string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := "http://" + r.Host
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/index.json":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": base + "/registration/", "@type": "RegistrationsBaseUrl/3.6.0"},
+					{"@id": base + "/vulnerabilities/index.json", "@type": "VulnerabilityInfo/6.7.0"},
+				},
+			})
+		case "/vulnerabilities/index.json":
+			_ = json.NewEncoder(w).Encode([]map[string]string{{"@name": "base", "@id": base + "/vulnerabilities/base.json", "@updated": "2025-01-01T00:00:00Z"}})
+		case "/vulnerabilities/base.json":
+			_, _ = w.Write([]byte(vulnerabilities))
+		default:
+			for packageID, packageVersions := range versions {
+				if r.URL.Path != "/registration/"+strings.ToLower(packageID)+"/index.json" {
+					continue
+				}
+				items := make([]map[string]any, 0, len(packageVersions))
+				for _, v := range packageVersions {
+					items = append(items, map[string]any{
+						"@id":          base + "/registration/" + strings.ToLower(packageID) + "/" + v + ".json",
+						"catalogEntry": map[string]any{"id": packageID, "version": v},
+					})
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"count": 1,
+					"items": []map[string]any{{
+						"@id":   base + "/registration/" + strings.ToLower(packageID) + "/index.json#page",
+						"lower": packageVersions[0],
+						"upper": packageVersions[len(packageVersions)-1],
+						"count": len(items),
+						"items": items,
+					}},
+				})
+				return
+			}
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeListableProject writes a net8.0 project referencing Newtonsoft.Json and Serilog,
+// with the project.assets.json of its restore: Serilog depends on Serilog.Sinks.Console.
+func writeListableProject(t *testing.T, dir, feedURL string) string {
+	t.Helper()
+
+	projectPath := filepath.Join(dir, "App.csproj")
+	projectXML := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="12.0.1" />
+    <PackageReference Include="Serilog" Version="2.10.0" />
+  </ItemGroup>
+</Project>`
+	assets := `{
+  "version": 3,
+  "targets": {
+    "net8.0": {
+      "Newtonsoft.Json/12.0.1": {"type": "package"},
+      "Serilog/2.10.0": {"type": "package", "dependencies": {"Serilog.Sinks.Console": "3.1.1"}},
+      "Serilog.Sinks.Console/3.1.1": {"type": "package"}
+    }
+  },
+  "project": {
+    "restore": {"originalTargetFrameworks": ["net8.0"]},
+    "frameworks": {
+      "net8.0": {
+        "targetAlias": "net8.0",
+        "dependencies": {
+          "Newtonsoft.Json": {"target": "Package", "version": "[12.0.1, )"},
+          "Serilog": {"target": "Package", "version": "[2.10.0, )"}
+        }
+      }
+    }
+  }
+}`
+	nugetConfig := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+    <add key="feed" value="` + feedURL + `/index.json" />
+  </packageSources>
+</configuration>`
+
+	if err := os.MkdirAll(filepath.Join(dir, "obj"), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	for path, content := range map[string]string{
+		projectPath: projectXML,
+		filepath.Join(dir, "obj", "project.assets.json"): assets,
+		filepath.Join(dir, "NuGet.config"):               nugetConfig,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	return projectPath
+}
+
+func TestPackageList_RestoredProject(t *testing.T) {
+	feed := newUpdatesFeed(t, map[string][]string{
+		"Newtonsoft.Json":       {"12.0.1", "12.0.3", "13.0.1", "14.0.0-beta"},
+		"Serilog":               {"2.10.0"},
+		"Serilog.Sinks.Console": {"3.1.1", "4.0.0"},
+	}, `{"newtonsoft.json": [{"url": "https://github.com/advisories/GHSA-5crp-9r3c-p9vr", "severity": 2, "versions": "(, 13.0.1)"}]}`)
+	projectPath := writeListableProject(t, t.TempDir(), feed.URL)
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := NewPackageListCommand()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(append([]string{projectPath}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("package list %v error = %v\n%s", args, err, out.String())
+		}
+		return out.String()
+	}
+
+	// Requested and resolved versions, transitive packages only on request
+	out := run()
+	if !strings.Contains(out, "Project 'App.csproj' has the following package references") ||
+		!strings.Contains(out, "   > Newtonsoft.Json   12.0.1      12.0.1") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if strings.Contains(out, "Serilog.Sinks.Console") {
+		t.Errorf("transitive package listed without --include-transitive:\n%s", out)
+	}
+	if out := run("--include-transitive"); !strings.Contains(out, "Transitive Package") || !strings.Contains(out, "> Serilog.Sinks.Console   3.1.1") {
+		t.Errorf("transitive package not listed:\n%s", out)
+	}
+
+	// Updates: stable only by default, within the major version with --highest-minor
+	latest := map[string]string{}
+	for _, args := range [][]string{
+		{"--outdated", "--include-transitive"},
+		{"--outdated", "--prerelease"},
+		{"--outdated", "--highest-minor"},
+		{"--outdated", "--highest-patch"},
+	} {
+		var result output.PackageListOutput
+		if err := json.Unmarshal([]byte(run(append(args, "--format", "json")...)), &result); err != nil {
+			t.Fatalf("invalid JSON output for %v: %v", args, err)
+		}
+		var updates []string
+		for _, pkg := range result.Packages {
+			updates = append(updates, pkg.ID+" "+pkg.ResolvedVersion+" -> "+pkg.LatestVersion)
+		}
+		latest[strings.Join(args, " ")] = strings.Join(updates, ", ")
+	}
+	want := map[string]string{
+		"--outdated --include-transitive": "Newtonsoft.Json 12.0.1 -> 13.0.1, Serilog.Sinks.Console 3.1.1 -> 4.0.0",
+		"--outdated --prerelease":         "Newtonsoft.Json 12.0.1 -> 14.0.0-beta",
+		"--outdated --highest-minor":      "Newtonsoft.Json 12.0.1 -> 12.0.3",
+		"--outdated --highest-patch":      "Newtonsoft.Json 12.0.1 -> 12.0.3",
+	}
+	for args, updates := range want {
+		if latest[args] != updates {
+			t.Errorf("%s = %q, want %q", args, latest[args], updates)
+		}
+	}
+
+	out = run("--outdated")
+	if !strings.Contains(out, "has the following updates to its packages") || !strings.Contains(out, "Latest") || !strings.Contains(out, "13.0.1") {
+		t.Errorf("unexpected --outdated output:\n%s", out)
+	}
+
+	out = run("--vulnerable")
+	if !strings.Contains(out, "has the following vulnerable packages") ||
+		!strings.Contains(out, "High       https://github.com/advisories/GHSA-5crp-9r3c-p9vr") ||
+		strings.Contains(out, "> Serilog ") {
+		t.Errorf("unexpected --vulnerable output:\n%s", out)
+	}
+}
+
+func TestPackageList_OptionsRequireRestore(t *testing.T) {
+	dir := t.TempDir()
+	projectPath := writeListableProject(t, dir, "http://127.0.0.1:1")
+	if err := os.Remove(filepath.Join(dir, "obj", "project.assets.json")); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	for _, args := range [][]string{{"--include-transitive"}, {"--outdated"}, {"--vulnerable"}} {
+		cmd := NewPackageListCommand()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{projectPath}, args...))
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "No assets file was found") {
+			t.Errorf("package list %v error = %v, want a restore error", args, err)
+		}
+	}
+
+	cmd := NewPackageListCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{projectPath, "--highest-minor"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "only be used with --outdated") {
+		t.Errorf("--highest-minor without --outdated error = %v", err)
+	}
+
+	cmd = NewPackageListCommand()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{projectPath, "--offline"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "only be used with --vulnerable") {
+		t.Errorf("--offline without --vulnerable error = %v", err)
+	}
+}
+
+// restoreVulnerableProject writes a net8.0 project referencing Contoso.App, whose
+// NuGet.config uses feed, and restores it.
+func restoreVulnerableProject(t *testing.T, feed *nugethttptest.FakeV3Server) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	projectPath := filepath.Join(dir, "App.csproj")
+	projectXML := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Contoso.App" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	nugetConfig := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+    <add key="feed" value="` + feed.SourceURL() + `" />
+  </packageSources>
+</configuration>`
+	for path, content := range map[string]string{projectPath: projectXML, filepath.Join(dir, "NuGet.config"): nugetConfig} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	var restoreOutput bytes.Buffer
+	opts := &restore.Options{
+		Sources:        []string{feed.SourceURL()},
+		PackagesFolder: filepath.Join(dir, "packages"),
+		NoCache:        true,
+	}
+	if err := restore.Run(context.Background(), []string{projectPath}, opts, output.NewConsole(&restoreOutput, &restoreOutput, output.VerbosityNormal)); err != nil {
+		t.Fatalf("restore error = %v\n%s", err, restoreOutput.String())
+	}
+	return projectPath
+}
+
+func TestPackageList_Vulnerable(t *testing.T) {
+	packages := []nugethttptest.Package{
+		{ID: "Contoso.App", Version: "1.0.0", Dependencies: []nugethttptest.Dependency{{ID: "Contoso.Core", Range: "2.0.0"}}},
+		{ID: "Contoso.Core", Version: "2.0.0"},
+	}
+	feed := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{
+		Packages: packages,
+		Vulnerabilities: []nugethttptest.Vulnerability{
+			{ID: "Contoso.Core", Versions: "(, 2.1.0)", Severity: 3, AdvisoryURL: "https://example.com/advisories/contoso-core"},
+		},
+	})
+	projectPath := restoreVulnerableProject(t, feed)
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd := NewPackageListCommand()
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(append([]string{projectPath, "--vulnerable", "--include-transitive"}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("package list %v error = %v\n%s", args, err, out.String())
+		}
+		return out.String()
+	}
+	chain := "      Contoso.App (1.0.0) > Contoso.Core (2.0.0)"
+
+	// Live: the sources are queried and the chain to the transitive package is shown
+	out := run()
+	if !strings.Contains(out, "Critical   https://example.com/advisories/contoso-core") ||
+		!strings.Contains(out, "Contoso.Core (2.0.0) is brought in by:\n"+chain) ||
+		strings.Contains(out, "recorded by the last restore") {
+		t.Errorf("unexpected live output:\n%s", out)
+	}
+	var result output.PackageListOutput
+	if err := json.Unmarshal([]byte(run("--format", "json")), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	if len(result.Packages) != 1 || !slices.Equal(result.Packages[0].Paths[0], []string{"Contoso.App/1.0.0", "Contoso.Core/2.0.0"}) || result.AuditedAt != "" {
+		t.Errorf("JSON output = %+v, want Contoso.Core with its path and live data", result)
+	}
+
+	// Offline: the audit results of the restore are shown without querying the sources
+	requests := len(feed.Requests())
+	out = run("--offline")
+	if !strings.Contains(out, "Vulnerabilities recorded by the last restore at") ||
+		!strings.Contains(out, "Critical   https://example.com/advisories/contoso-core") ||
+		!strings.Contains(out, chain) {
+		t.Errorf("unexpected --offline output:\n%s", out)
+	}
+	if len(feed.Requests()) != requests {
+		t.Errorf("--offline queried the sources: %v", feed.Requests()[requests:])
+	}
+
+	// Unreachable sources fall back to the audit results
+	feed.Handle(nugethttptest.ServiceIndexPath, http.NotFoundHandler())
+	if out := run(); !strings.Contains(out, "Vulnerabilities recorded by the last restore at") || !strings.Contains(out, "Critical") {
+		t.Errorf("unexpected output with the sources unreachable:\n%s", out)
+	}
+
+	// Without audit results there is nothing to show offline
+	if err := os.Remove(restore.GetAuditFilePath(projectPath)); err != nil {
+		t.Fatal(err)
+	}
+	if out := run("--offline"); !strings.Contains(out, "The last restore of 'App.csproj' recorded no vulnerability data.") {
+		t.Errorf("unexpected --offline output without audit results:\n%s", out)
+	}
+
+	// A project without vulnerable packages lists none and succeeds
+	clean := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{
+		Packages: packages,
+		Vulnerabilities: []nugethttptest.Vulnerability{
+			{ID: "Contoso.Core", Versions: "[1.0.0]", Severity: 2, AdvisoryURL: "https://example.com/advisories/contoso-core-1"},
+		},
+	})
+	projectPath = restoreVulnerableProject(t, clean)
+	if out := run(); !strings.Contains(out, "The given project 'App.csproj' has no vulnerable packages given the current sources.") {
+		t.Errorf("unexpected output without vulnerable packages:\n%s", out)
+	}
+}
@@ -0,0 +1,256 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+	"github.com/willibrandon/gonuget/core"
+	"github.com/willibrandon/gonuget/restore"
+	"github.com/willibrandon/gonuget/version"
+)
+
+const (
+	// packageUpdatesTimeout bounds the source queries of package list --outdated and --vulnerable
+	packageUpdatesTimeout = 2 * time.Minute
+
+	// maxConcurrentVersionLookups bounds the packages whose versions are listed at once
+	maxConcurrentVersionLookups = 8
+)
+
+// findPackageUpdates queries the enabled sources of dir for the packages of the projects:
+// with --outdated it sets the newest version each package can be updated to, with
+// --vulnerable the known vulnerabilities of each resolved version and the dependency
+// paths to the vulnerable transitive ones. A source that can't be queried is reported as
+// a warning. With --offline, or when no source could be read, --vulnerable uses the
+// vulnerabilities recorded by the last restore. Returns the sources used, nil when
+// neither option is given or no source was used.
+func findPackageUpdates(ctx context.Context, dir string, projects []*projectPackages, opts *PackageListOptions) ([]string, error) {
+	if !opts.Outdated && !opts.Vulnerable {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, packageUpdatesTimeout)
+	defer cancel()
+	warningWriter := output.NewWarningWriter()
+
+	if opts.Offline {
+		readAuditFiles(projects, warningWriter)
+		findDependencyPaths(ctx, projects, warningWriter)
+		return nil, nil
+	}
+
+	sources := config.GetEnabledSourcesOrDefault(dir)
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no enabled package sources found")
+	}
+
+	repoManager := core.NewRepositoryManager()
+	sourceURLs := make([]string, 0, len(sources))
+	for _, source := range sources {
+		repo := core.NewSourceRepository(core.RepositoryConfig{
+			Name:            source.Key,
+			SourceURL:       source.Value,
+			ProtocolVersion: source.ProtocolVersion,
+		})
+		if err := repoManager.AddRepository(repo); err != nil {
+			return nil, fmt.Errorf("failed to add repository: %w", err)
+		}
+		sourceURLs = append(sourceURLs, source.Value)
+	}
+
+	// Credentials from packageSourceCredentials are requested once when a source answers 401
+	client := core.NewClient(core.ClientConfig{
+		RepositoryManager:  repoManager,
+		CredentialProvider: config.NewCredentialProvider(config.LoadConfigLayers(dir)),
+	})
+	repos := client.GetRepositoryManager().ListRepositories()
+
+	if opts.Outdated {
+		available := listAvailableVersions(ctx, repos, projects, warningWriter)
+		forEachListedPackage(projects, func(pkg *listedPackage) {
+			versions, found := available[strings.ToLower(pkg.ID)]
+			if !found {
+				return
+			}
+			if latest := latestUpdate(pkg.Resolved, versions, opts); latest != nil {
+				pkg.Latest = latest.String()
+			}
+		})
+	}
+
+	if opts.Vulnerable {
+		vulnerabilities := core.PackageVulnerabilities{}
+		published, failed := false, false
+		for _, repo := range repos {
+			found, ok, err := repo.GetVulnerabilities(ctx)
+			if err != nil {
+				warningWriter.Warning("%s", err)
+				failed = true
+				continue
+			}
+			if ok {
+				published = true
+				for id, known := range found {
+					vulnerabilities[id] = append(vulnerabilities[id], known...)
+				}
+			}
+		}
+
+		switch {
+		case !published && failed:
+			warningWriter.Warning("Vulnerability data could not be read from the sources, so the vulnerabilities recorded by the last restore are shown.")
+			readAuditFiles(projects, warningWriter)
+			sourceURLs = nil
+		case !published:
+			warningWriter.Warning("None of the sources publish vulnerability data, so no vulnerable packages can be found.")
+		default:
+			forEachListedPackage(projects, func(pkg *listedPackage) {
+				if resolved, err := version.Parse(pkg.Resolved); err == nil {
+					pkg.Vulnerabilities = vulnerabilities.Find(pkg.ID, resolved)
+				}
+			})
+		}
+		findDependencyPaths(ctx, projects, warningWriter)
+	}
+
+	return sourceURLs, nil
+}
+
+// readAuditFiles sets the vulnerabilities of the packages of the projects from the audit
+// file their last restore wrote. A project without one is marked Unaudited.
+func readAuditFiles(projects []*projectPackages, warningWriter *output.WarningWriter) {
+	for _, packages := range projects {
+		audit, err := restore.LoadAuditFile(restore.GetAuditFilePath(packages.Path))
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				warningWriter.Warning("%s", err)
+			}
+			packages.Unaudited = true
+			continue
+		}
+
+		packages.AuditedAt = audit.AuditedAt
+		forEachListedPackage([]*projectPackages{packages}, func(pkg *listedPackage) {
+			pkg.Vulnerabilities = audit.Find(pkg.ID, pkg.Resolved)
+		})
+	}
+}
+
+// findDependencyPaths sets the paths from top-level packages to each vulnerable
+// transitive package. Paths that can't be read are reported as a warning.
+func findDependencyPaths(ctx context.Context, projects []*projectPackages, warningWriter *output.WarningWriter) {
+	for _, packages := range projects {
+		if packages.assets == nil {
+			continue
+		}
+		for i := range packages.Frameworks {
+			framework := &packages.Frameworks[i]
+			for j := range framework.Transitive {
+				pkg := &framework.Transitive[j]
+				if len(pkg.Vulnerabilities) == 0 {
+					continue
+				}
+				paths, err := packages.assets.DependencyPaths(ctx, framework.Framework, pkg.ID)
+				if err != nil {
+					warningWriter.Warning("%s", err)
+					continue
+				}
+				pkg.Paths = paths
+			}
+		}
+	}
+}
+
+// forEachListedPackage calls fn for every top-level and transitive package of the projects.
+func forEachListedPackage(projects []*projectPackages, fn func(pkg *listedPackage)) {
+	for _, packages := range projects {
+		for i := range packages.Frameworks {
+			framework := &packages.Frameworks[i]
+			for j := range framework.TopLevel {
+				fn(&framework.TopLevel[j])
+			}
+			for j := range framework.Transitive {
+				fn(&framework.Transitive[j])
+			}
+		}
+	}
+}
+
+// listAvailableVersions lists the listed versions of every package of the projects from
+// all repositories, keyed by lowercase package ID and sorted lowest first. Each package is
+// looked up once. A package no repository has is warned about and left out.
+func listAvailableVersions(ctx context.Context, repos []*core.SourceRepository, projects []*projectPackages, warningWriter *output.WarningWriter) map[string][]*version.NuGetVersion {
+	ids := make(map[string]string)
+	forEachListedPackage(projects, func(pkg *listedPackage) {
+		ids[strings.ToLower(pkg.ID)] = pkg.ID
+	})
+
+	var mu sync.Mutex
+	available := make(map[string][]*version.NuGetVersion, len(ids))
+	semaphore := make(chan struct{}, maxConcurrentVersionLookups)
+	var wg sync.WaitGroup
+	for key, id := range ids {
+		wg.Go(func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			var versions []*version.NuGetVersion
+			for _, repo := range repos {
+				listed, err := repo.ListListedVersions(ctx, nil, id)
+				if err != nil {
+					continue // The repository doesn't have the package
+				}
+				for _, versionString := range listed {
+					if parsed, err := version.Parse(versionString); err == nil {
+						versions = append(versions, parsed)
+					}
+				}
+			}
+			version.SortVersions(versions)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(versions) == 0 {
+				warningWriter.Warning("Package '%s' was not found at the sources.", id)
+				return
+			}
+			available[key] = versions
+		})
+	}
+	wg.Wait()
+	return available
+}
+
+// latestUpdate returns the newest of versions, sorted with version.SortVersions, that
+// package list --outdated offers for a package resolved to resolved, or nil when there is
+// none newer. Prerelease versions are considered with --prerelease or when the resolved
+// version is itself a prerelease; --highest-patch keeps the major and minor version,
+// --highest-minor the major version.
+func latestUpdate(resolved string, versions []*version.NuGetVersion, opts *PackageListOptions) *version.NuGetVersion {
+	current, err := version.Parse(resolved)
+	if err != nil {
+		return nil
+	}
+
+	buckets := version.BucketVersions(versions, current)
+	bucket := buckets.Highest
+	switch {
+	case opts.HighestPatch:
+		bucket = buckets.HighestPatch
+	case opts.HighestMinor:
+		bucket = buckets.HighestMinor
+	}
+
+	latest := bucket.Version(opts.Prerelease || current.IsPrerelease())
+	if latest == nil || !latest.GreaterThan(current) {
+		return nil
+	}
+	return latest
+}
@@ -1,18 +1,28 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/restore"
 	"github.com/willibrandon/gonuget/solution"
 )
 
 // PackageRemoveOptions holds the configuration for the package remove command.
 type PackageRemoveOptions struct {
-	ProjectPath string
+	ProjectPath  string
+	CleanCentral bool
+	Restore      bool
 }
 
 // NewPackageRemoveCommand creates the 'package remove' subcommand.
@@ -24,27 +34,40 @@ func NewPackageRemoveCommand() *cobra.Command {
 		Short: "Remove a package reference from a project file",
 		Long: `Remove a NuGet package reference from a .NET project file.
 
-This command removes a package reference from a .NET project file (.csproj, .fsproj, .vbproj).
-If the project uses Central Package Management, the package version in Directory.Packages.props
-is NOT removed (only the PackageReference in the project file).
+This command removes the PackageReference items of a package from a .NET project file
+(.csproj, .fsproj, .vbproj), conditional ones included. The rest of the file is left
+as it was; an ItemGroup the removal leaves empty is removed too.
+
+A package the project only uses transitively has no reference to remove; the command
+then lists the top-level packages that bring it in.
+
+If the project uses Central Package Management, the package version in
+Directory.Packages.props is kept unless --clean-central is given and no other project
+managed by that file references the package. With --restore, the project is restored
+afterwards so that project.assets.json no longer has the package.
 
 Examples:
   gonuget package remove Newtonsoft.Json
-  gonuget package remove Newtonsoft.Json --project MyProject.csproj`,
+  gonuget package remove Newtonsoft.Json --project MyProject.csproj
+  gonuget package remove Serilog --clean-central --restore`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			packageID := args[0]
-			return runPackageRemove(packageID, opts)
+			return runPackageRemove(cmd.Context(), packageID, opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&opts.ProjectPath, "project", "", "The project file to operate on (defaults to current directory)")
+	cmd.Flags().BoolVar(&opts.CleanCentral, "clean-central", false, "Remove the PackageVersion from Directory.Packages.props when no other project references the package")
+	cmd.Flags().BoolVar(&opts.Restore, "restore", false, "Restore the project after removing the package")
 
 	return cmd
 }
 
 // runPackageRemove implements the package remove command logic.
-func runPackageRemove(packageID string, opts *PackageRemoveOptions) error {
+func runPackageRemove(ctx context.Context, packageID string, opts *PackageRemoveOptions) error {
+	console := newCLIConsole()
+
 	// Find the project file
 	projectPath := opts.ProjectPath
 	if projectPath == "" {
@@ -85,17 +108,19 @@ func runPackageRemove(packageID string, opts *PackageRemoveOptions) error {
 		return fmt.Errorf("failed to load project %s: %w", projectPath, err)
 	}
 
-	// Check if package exists
-	packageRefs := proj.GetPackageReferences()
-	found := false
-	for _, ref := range packageRefs {
-		if ref.Include == packageID {
-			found = true
+	// Check if package exists, with the casing the project uses
+	referencedID := ""
+	for _, ref := range proj.GetPackageReferences() {
+		if strings.EqualFold(ref.Include, packageID) {
+			referencedID = ref.Include
 			break
 		}
 	}
 
-	if !found {
+	if referencedID == "" {
+		if err := transitiveReferenceError(ctx, projectPath, packageID); err != nil {
+			return err
+		}
 		return fmt.Errorf("package '%s' not found in project '%s'", packageID, projectPath)
 	}
 
@@ -109,11 +134,145 @@ func runPackageRemove(packageID string, opts *PackageRemoveOptions) error {
 		return fmt.Errorf("failed to save project file: %w", err)
 	}
 
-	fmt.Printf("info : Package '%s' removed from project '%s'\n", packageID, projectPath)
+	console.Printf("info : Package '%s' removed from project '%s'\n", packageID, projectPath)
+
+	if opts.CleanCentral {
+		if err := removeCentralPackageVersion(console, proj, referencedID); err != nil {
+			return err
+		}
+	}
+
+	if opts.Restore {
+		restoreOpts := &restore.Options{}
+		for _, source := range config.GetEnabledSourcesOrDefault(filepath.Dir(projectPath)) {
+			restoreOpts.Sources = append(restoreOpts.Sources, source.Value)
+		}
+
+		console.Printf("info : Restoring packages for %s...\n", projectPath)
+		packageRefs, err := restore.PackageReferences(proj)
+		if err != nil {
+			return fmt.Errorf("failed to load package references: %w", err)
+		}
+		// The restore writes project.assets.json without the package
+		if _, err := restore.NewRestorer(restoreOpts, console).Restore(ctx, proj, packageRefs); err != nil {
+			return fmt.Errorf("restore failed: %w", err)
+		}
+	}
 
 	return nil
 }
 
+// transitiveReferenceError returns the error for removing a package the restored project
+// uses only as a dependency of its packages, naming the chains that bring it in; nil when
+// the project isn't restored or doesn't use the package.
+func transitiveReferenceError(ctx context.Context, projectPath, packageID string) error {
+	assets, err := restore.LoadLockFile(restore.GetAssetsFilePath(projectPath))
+	if err != nil || assets.PackageVersion(packageID) == "" {
+		return nil
+	}
+
+	frameworks := slices.Sorted(maps.Keys(assets.Project.Frameworks))
+	var chains []string
+	for _, framework := range frameworks {
+		paths, err := assets.DependencyPaths(ctx, framework, packageID)
+		if err != nil {
+			return fmt.Errorf("failed to read the dependencies of %s: %w", projectPath, err)
+		}
+		for _, path := range paths {
+			if chain := formatDependencyPath(path); !slices.Contains(chains, chain) {
+				chains = append(chains, chain)
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "package '%s' is a transitive dependency of project '%s' and has no PackageReference to remove", packageID, projectPath)
+	if len(chains) > 0 {
+		b.WriteString("; it is brought in by:")
+		for _, chain := range chains {
+			b.WriteString("\n  " + chain)
+		}
+		b.WriteString("\nRemove or update the top-level package that brings it in instead")
+	}
+	return errors.New(b.String())
+}
+
+// removeCentralPackageVersion removes the PackageVersion of packageID from the
+// Directory.Packages.props of the project, unless another project managed by that file
+// still references the package.
+func removeCentralPackageVersion(console *cliConsole, proj *project.Project, packageID string) error {
+	props, err := proj.CentralPackageVersions()
+	if err != nil {
+		return fmt.Errorf("failed to load Directory.Packages.props: %w", err)
+	}
+	if props == nil || props.GetPackageVersion(packageID) == "" {
+		return nil
+	}
+
+	consumers, err := centralPackageConsumers(props.Path, packageID)
+	if err != nil {
+		return err
+	}
+	if len(consumers) > 0 {
+		console.Printf("info : PackageVersion for '%s' kept in '%s'; it is still referenced by %s\n", packageID, props.Path, strings.Join(consumers, ", "))
+		return nil
+	}
+
+	props.RemovePackageVersion(packageID)
+	if err := props.Save(); err != nil {
+		return fmt.Errorf("failed to save Directory.Packages.props: %w", err)
+	}
+	console.Printf("info : PackageVersion for '%s' removed from '%s'\n", packageID, props.Path)
+	return nil
+}
+
+// centralPackageConsumers returns the projects under the directory of the
+// Directory.Packages.props file at propsPath that take their versions from it and
+// reference packageID.
+func centralPackageConsumers(propsPath, packageID string) ([]string, error) {
+	propsPath, _ = filepath.Abs(propsPath)
+	root := filepath.Dir(propsPath)
+
+	var consumers []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are skipped rather than failing the scan
+			if d != nil && d.IsDir() && path != root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (skippedScanDirs[strings.ToLower(d.Name())] || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !solution.IsProjectFile(path) {
+			return nil
+		}
+
+		proj, err := project.LoadProject(path)
+		if err != nil {
+			return nil
+		}
+		if managedBy, _ := filepath.Abs(proj.GetDirectoryPackagesPropsPath()); managedBy != propsPath {
+			return nil
+		}
+		for _, ref := range proj.GetPackageReferences() {
+			if strings.EqualFold(ref.Include, packageID) {
+				consumers = append(consumers, path)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan '%s': %w", root, err)
+	}
+	return consumers, nil
+}
+
 // init registers the package remove subcommand with the package parent command
 func init() {
 	packageCmd := GetPackageCommand()
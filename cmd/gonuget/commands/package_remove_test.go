@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+	"github.com/willibrandon/gonuget/restore"
+)
+
+func TestRunPackageRemove_PreservesFormatting(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "App.csproj")
+	require.NoError(t, os.WriteFile(projectPath, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+
+	<!-- Build settings -->
+	<PropertyGroup>
+		<TargetFrameworks>net8.0;net48</TargetFrameworks>
+	</PropertyGroup>
+
+	<ItemGroup>
+		<PackageReference Include="Serilog" Version="3.1.1" PrivateAssets="all" />
+		<PackageReference Include="Newtonsoft.Json" Version="13.0.3" />
+		<Compile Remove="Generated/**" />
+	</ItemGroup>
+
+	<ItemGroup Condition="'$(TargetFramework)' == 'net48'">
+		<PackageReference Include="Newtonsoft.Json" Version="12.0.3" />
+	</ItemGroup>
+
+</Project>
+`), 0644))
+
+	require.NoError(t, runPackageRemove(t.Context(), "newtonsoft.json", &PackageRemoveOptions{ProjectPath: projectPath}))
+
+	data, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeff"+`<Project Sdk="Microsoft.NET.Sdk">
+
+	<!-- Build settings -->
+	<PropertyGroup>
+		<TargetFrameworks>net8.0;net48</TargetFrameworks>
+	</PropertyGroup>
+
+	<ItemGroup>
+		<PackageReference Include="Serilog" Version="3.1.1" PrivateAssets="all" />
+		<Compile Remove="Generated/**" />
+	</ItemGroup>
+
+</Project>
+`, string(data))
+
+	err = runPackageRemove(t.Context(), "Polly", &PackageRemoveOptions{ProjectPath: projectPath})
+	assert.EqualError(t, err, "package 'Polly' not found in project '"+projectPath+"'")
+}
+
+func TestRunPackageRemove_TransitivePackage(t *testing.T) {
+	feed := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: []nugethttptest.Package{
+		{ID: "Contoso.App", Version: "1.0.0", Dependencies: []nugethttptest.Dependency{{ID: "Contoso.Core", Range: "2.0.0"}}},
+		{ID: "Contoso.Core", Version: "2.0.0"},
+	}})
+	projectPath := restoreVulnerableProject(t, feed)
+
+	err := runPackageRemove(t.Context(), "contoso.core", &PackageRemoveOptions{ProjectPath: projectPath})
+	require.Error(t, err)
+	assert.Equal(t, "package 'contoso.core' is a transitive dependency of project '"+projectPath+"' and has no PackageReference to remove; it is brought in by:\n"+
+		"  Contoso.App (1.0.0) > Contoso.Core (2.0.0)\n"+
+		"Remove or update the top-level package that brings it in instead", err.Error())
+}
+
+func TestRunPackageRemove_Restore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("APPDATA", home)
+	t.Setenv("NUGET_PACKAGES", filepath.Join(home, "packages"))
+
+	feed := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: []nugethttptest.Package{
+		{ID: "Contoso.App", Version: "1.0.0", Dependencies: []nugethttptest.Dependency{{ID: "Contoso.Core", Range: "2.0.0"}}},
+		{ID: "Contoso.Core", Version: "2.0.0"},
+	}})
+	projectPath := restoreVulnerableProject(t, feed)
+
+	require.NoError(t, runPackageRemove(t.Context(), "Contoso.App", &PackageRemoveOptions{ProjectPath: projectPath, Restore: true}))
+
+	assets, err := restore.LoadLockFile(restore.GetAssetsFilePath(projectPath))
+	require.NoError(t, err)
+	assert.Empty(t, assets.PackageVersion("Contoso.App"))
+	assert.Empty(t, assets.PackageVersion("Contoso.Core"))
+}
+
+func TestRunPackageRemove_CleanCentral(t *testing.T) {
+	root := t.TempDir()
+	propsPath := filepath.Join(root, "Directory.Packages.props")
+	appPath := filepath.Join(root, "src", "App", "App.csproj")
+	files := map[string]string{
+		propsPath: `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Serilog" Version="3.1.1" />
+    <PackageVersion Include="Polly" Version="8.4.0" />
+  </ItemGroup>
+</Project>
+`,
+		appPath: `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" />
+    <PackageReference Include="Polly" />
+  </ItemGroup>
+</Project>
+`,
+		filepath.Join(root, "src", "Lib", "Lib.csproj"): `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" />
+  </ItemGroup>
+</Project>
+`,
+		// Managed by its own Directory.Packages.props, so its reference doesn't count
+		filepath.Join(root, "tools", "Directory.Packages.props"): `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+</Project>
+`,
+		filepath.Join(root, "tools", "Tool", "Tool.csproj"): `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Polly" />
+  </ItemGroup>
+</Project>
+`,
+	}
+	for path, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	// Lib still references Serilog, so its version stays
+	require.NoError(t, runPackageRemove(t.Context(), "Serilog", &PackageRemoveOptions{ProjectPath: appPath, CleanCentral: true}))
+	// App was the last consumer of Polly
+	require.NoError(t, runPackageRemove(t.Context(), "Polly", &PackageRemoveOptions{ProjectPath: appPath, CleanCentral: true}))
+
+	data, err := os.ReadFile(propsPath)
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeff"+`<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+</Project>
+`, string(data))
+
+	data, err = os.ReadFile(appPath)
+	require.NoError(t, err)
+	assert.Equal(t, "\ufeff<Project Sdk=\"Microsoft.NET.Sdk\">\n</Project>\n", string(data))
+}
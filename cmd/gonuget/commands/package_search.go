@@ -3,8 +3,13 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -15,11 +20,25 @@ import (
 
 // PackageSearchOptions holds the configuration for the package search command.
 type PackageSearchOptions struct {
-	Source     string
+	Sources    []string
+	ConfigFile string
 	Format     string
 	Take       int
 	Skip       int
 	Prerelease bool
+	ExactMatch bool
+	Strict     bool
+}
+
+// searchSourceTimeout bounds the search of each source, so a source that doesn't
+// answer can't hold up the results of the others for longer.
+var searchSourceTimeout = 30 * time.Second
+
+// sourceSearchResult is the outcome of searching one package source.
+type sourceSearchResult struct {
+	source  config.PackageSource
+	results []core.SearchResult
+	err     error
 }
 
 // NewPackageSearchCommand creates the 'package search' subcommand.
@@ -32,161 +51,326 @@ func NewPackageSearchCommand() *cobra.Command {
 		Long: `Search for NuGet packages in configured package sources.
 
 This command searches for packages matching the search term using the NuGet V3 search API.
-Results can be paginated using --skip and --take flags.
-Output can be formatted as console (human-readable) or JSON.
+Every enabled source is searched at once, and the results are listed per source in the
+order the sources are configured. Use --source (repeatable, a source name or URL) to
+search only some sources, and --configfile to take the sources from one NuGet.config only.
+
+A source that fails (unreachable, unauthorized, or slower than the per-source timeout)
+is reported under its own heading without hiding the results of the other sources.
+The command fails only when every source fails, or when any source fails with --strict.
+
+Results can be paginated using --skip and --take flags. With --exact-match only the
+package whose ID is the search term is listed.
+Output can be formatted as console (a table per source) or JSON.
 
 Examples:
   gonuget package search Newtonsoft
   gonuget package search Serilog --take 10
   gonuget package search EntityFramework --format json
-  gonuget package search AspNetCore --prerelease`,
+  gonuget package search AspNetCore --prerelease
+  gonuget package search Newtonsoft.Json --exact-match
+  gonuget package search MyCompany --source MyInternalFeed --source nuget.org
+  gonuget package search MyCompany --configfile ./ci/NuGet.config --strict`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			searchTerm := args[0]
-			return runPackageSearch(cmd.Context(), searchTerm, opts)
+			console := output.NewConsole(cmd.OutOrStdout(), cmd.ErrOrStderr(), output.VerbosityNormal)
+			return runPackageSearch(cmd.Context(), searchTerm, opts, console)
 		},
 	}
 
-	cmd.Flags().StringVarP(&opts.Source, "source", "s", "", "Package source to search")
+	cmd.Flags().StringSliceVarP(&opts.Sources, "source", "s", nil, "Package source(s) to search, by name or URL (can be repeated)")
+	cmd.Flags().StringVar(&opts.ConfigFile, "configfile", "", "The NuGet configuration file. If specified, only the settings from this file will be used.")
 	cmd.Flags().StringVar(&opts.Format, "format", "console", "Output format: console or json")
 	cmd.Flags().IntVar(&opts.Take, "take", 20, "Number of results to return")
 	cmd.Flags().IntVar(&opts.Skip, "skip", 0, "Number of results to skip (for pagination)")
 	cmd.Flags().BoolVar(&opts.Prerelease, "prerelease", false, "Include prerelease packages")
+	cmd.Flags().BoolVar(&opts.ExactMatch, "exact-match", false, "Only list the package whose ID matches the search term exactly")
+	cmd.Flags().BoolVar(&opts.Strict, "strict", false, "Fail if any source fails, not only when all sources fail")
 
 	return cmd
 }
 
 // runPackageSearch implements the package search command logic.
-func runPackageSearch(ctx context.Context, searchTerm string, opts *PackageSearchOptions) error {
+func runPackageSearch(ctx context.Context, searchTerm string, opts *PackageSearchOptions, console *output.Console) error {
 	start := time.Now()
 
-	// Create a client with timeout
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
-	// Determine source
-	source := opts.Source
-	if source == "" {
-		// Load first enabled source from config with fallback to defaults
-		projectDir, err := os.Getwd()
-		if err != nil {
-			projectDir = "."
-		}
-
-		sources := config.GetEnabledSourcesOrDefault(projectDir)
-		if len(sources) > 0 {
-			source = sources[0].Value
-		} else {
-			return fmt.Errorf("no package sources configured")
-		}
+	layers, sources, err := resolveSearchSources(opts)
+	if err != nil {
+		return err
 	}
 
-	// Track sources for JSON output
-	searchedSources := []string{source}
-
-	// Create NuGet client with repository manager
+	// Create NuGet client with a repository per source
 	repoManager := core.NewRepositoryManager()
-
-	// Add the source repository
-	repo := core.NewSourceRepository(core.RepositoryConfig{
-		SourceURL: source,
-		Name:      "default",
-	})
-	if err := repoManager.AddRepository(repo); err != nil {
-		return fmt.Errorf("failed to add repository: %w", err)
+	for _, source := range sources {
+		repo := core.NewSourceRepository(core.RepositoryConfig{
+			Name:            source.Key,
+			SourceURL:       source.Value,
+			ProtocolVersion: source.ProtocolVersion,
+		})
+		if err := repoManager.AddRepository(repo); err != nil {
+			return fmt.Errorf("failed to add repository: %w", err)
+		}
 	}
 
+	// Credentials from packageSourceCredentials are requested once when a source answers 401
 	client := core.NewClient(core.ClientConfig{
-		RepositoryManager: repoManager,
+		RepositoryManager:  repoManager,
+		CredentialProvider: config.NewCredentialProvider(layers),
 	})
 
-	// Perform search across all repositories
 	searchOpts := core.SearchOptions{
 		Skip:              opts.Skip,
 		Take:              opts.Take,
 		IncludePrerelease: opts.Prerelease,
 	}
 
-	resultsMap, err := client.SearchPackages(ctx, searchTerm, searchOpts)
-	if err != nil {
-		return fmt.Errorf("search failed: %w", err)
-	}
-
-	// Flatten results from all sources
-	var allResults []core.SearchResult
-	for _, results := range resultsMap {
-		allResults = append(allResults, results...)
+	// Search every source at once, each with its own timeout
+	results := make([]sourceSearchResult, len(sources))
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Go(func() {
+			sourceCtx, cancel := context.WithTimeout(ctx, searchSourceTimeout)
+			defer cancel()
+
+			found, err := client.SearchPackagesFromSource(sourceCtx, source.Key, searchTerm, searchOpts)
+			if err != nil && sourceCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+				err = fmt.Errorf("the source did not answer within %s", searchSourceTimeout)
+			}
+			if err == nil && opts.ExactMatch {
+				found = exactMatches(found, searchTerm)
+			}
+			results[i] = sourceSearchResult{source: source, results: found, err: err}
+		})
 	}
+	wg.Wait()
 
 	// Output based on format
 	if opts.Format == "json" {
-		return outputSearchResultsJSON(searchTerm, searchedSources, allResults, start)
+		if err := outputSearchResultsJSON(console, searchTerm, results, start); err != nil {
+			return err
+		}
+	} else {
+		outputSearchResultsConsole(console, searchTerm, results)
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+		}
+	}
+	switch {
+	case failed == len(results):
+		return fmt.Errorf("search failed: no package source could be searched")
+	case failed > 0 && opts.Strict:
+		return fmt.Errorf("search failed: %d of %d package source(s) could not be searched", failed, len(results))
+	}
+	return nil
+}
+
+// resolveSearchSources returns the config files in effect and the sources to search:
+// the --source values when given, otherwise every enabled source. With --configfile
+// only that file is read.
+func resolveSearchSources(opts *PackageSearchOptions) ([]config.ConfigLayer, []config.PackageSource, error) {
+	var layers []config.ConfigLayer
+	var enabled, known []config.PackageSource
+
+	if opts.ConfigFile != "" {
+		cfg, err := config.LoadNuGetConfig(opts.ConfigFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load config file '%s': %w", opts.ConfigFile, err)
+		}
+		layers = []config.ConfigLayer{{Path: opts.ConfigFile, Config: cfg}}
+		enabled = cfg.EnabledPackageSources(config.MergeDisabledSources(layers))
+		if cfg.PackageSources != nil {
+			known = cfg.PackageSources.Add
+		}
+	} else {
+		workingDir, err := os.Getwd()
+		if err != nil {
+			workingDir = "."
+		}
+		layers = config.LoadConfigLayers(workingDir)
+		enabled = config.GetEnabledSourcesOrDefault(workingDir)
+		known = listableSources(workingDir)
+	}
+
+	if len(opts.Sources) == 0 {
+		if len(enabled) == 0 {
+			return nil, nil, fmt.Errorf("no package sources configured")
+		}
+		return layers, enabled, nil
+	}
+
+	var sources []config.PackageSource
+	for _, nameOrURL := range opts.Sources {
+		source, ok := findListableSource(known, nameOrURL)
+		if !ok {
+			if !strings.Contains(nameOrURL, "://") && !filepath.IsAbs(nameOrURL) {
+				return nil, nil, fmt.Errorf("package source with name '%s' not found", nameOrURL)
+			}
+			// A source URL or local path is used as given
+			source = config.PackageSource{Key: nameOrURL, Value: nameOrURL}
+		}
+
+		// The same source given twice is searched once
+		if !slices.ContainsFunc(sources, func(s config.PackageSource) bool { return strings.EqualFold(s.Key, source.Key) }) {
+			sources = append(sources, source)
+		}
 	}
+	return layers, sources, nil
+}
+
+// outputSearchResultsConsole outputs search results in human-readable format, grouped by source
+func outputSearchResultsConsole(console *output.Console, searchTerm string, results []sourceSearchResult) {
+	// Write the listing as one block so it is never split by other output
+	block := console.BeginBlock()
+	defer console.EndBlock(block)
+
+	block.Printf("Searching for '%s'\n", searchTerm)
 
-	return outputSearchResultsConsole(searchTerm, source, allResults)
+	for _, result := range results {
+		block.Println()
+		if result.source.Key != result.source.Value {
+			block.Printf("Source: %s (%s)\n", result.source.Key, result.source.Value)
+		} else {
+			block.Printf("Source: %s\n", result.source.Value)
+		}
+
+		if result.err != nil {
+			block.Printf("error: Failed to retrieve results from source '%s': %v\n", result.source.Key, result.err)
+			continue
+		}
+
+		if len(result.results) == 0 {
+			block.Println("No packages found matching the search criteria.")
+			continue
+		}
+
+		block.Println()
+		writeSearchResultsTable(block, result.results, console.Width())
+		block.Println()
+		block.Printf("Showing %d results\n", len(result.results))
+	}
 }
 
-// outputSearchResultsConsole outputs search results in human-readable format
-func outputSearchResultsConsole(searchTerm, source string, results []core.SearchResult) error {
-	fmt.Printf("Searching for '%s' in source: %s\n", searchTerm, filepath.Base(source))
-	fmt.Println()
+// exactMatches returns the results whose package ID is the search term, ignoring case.
+// The search service matches IDs and descriptions loosely, so the filter is applied here.
+func exactMatches(results []core.SearchResult, searchTerm string) []core.SearchResult {
+	var matches []core.SearchResult
+	for _, pkg := range results {
+		if strings.EqualFold(pkg.ID, strings.TrimSpace(searchTerm)) {
+			matches = append(matches, pkg)
+		}
+	}
+	return matches
+}
 
-	if len(results) == 0 {
-		fmt.Println("No packages found matching the search criteria.")
-		return nil
+// writeSearchResultsTable writes results as a table whose description column is cut to fit width.
+func writeSearchResultsTable(w io.Writer, results []core.SearchResult, width int) {
+	idWidth, versionWidth, downloadsWidth := len("Package ID"), len("Latest Version"), len("Downloads")
+	downloads := make([]string, len(results))
+	for i := range results {
+		downloads[i] = formatDownloads(results[i].TotalDownloads)
+		idWidth = max(idWidth, len(results[i].ID))
+		versionWidth = max(versionWidth, len(results[i].Version))
+		downloadsWidth = max(downloadsWidth, len(downloads[i]))
 	}
+	descriptionWidth := width - (idWidth + versionWidth + downloadsWidth + 6)
 
+	_, _ = fmt.Fprintf(w, "%-*s  %-*s  %*s  %s\n", idWidth, "Package ID", versionWidth, "Latest Version", downloadsWidth, "Downloads", "Description")
 	for i := range results {
 		pkg := &results[i]
-		fmt.Printf("> %s\n", pkg.ID)
-		if pkg.Description != "" {
-			fmt.Printf("  %s\n", pkg.Description)
-		}
-		fmt.Printf("  Latest: %s | Downloads: %d\n", pkg.Version, pkg.TotalDownloads)
-		fmt.Println()
+		description := truncateDescription(pkg.Description, descriptionWidth)
+		line := fmt.Sprintf("%-*s  %-*s  %*s  %s", idWidth, pkg.ID, versionWidth, pkg.Version, downloadsWidth, downloads[i], description)
+		_, _ = fmt.Fprintln(w, strings.TrimRight(line, " "))
 	}
+}
 
-	fmt.Printf("Showing %d results\n", len(results))
+// truncateDescription puts description on one line and cuts it to at most width
+// characters, ending a cut description with "...".
+func truncateDescription(description string, width int) string {
+	description = strings.Join(strings.Fields(description), " ")
+	runes := []rune(description)
+	switch {
+	case len(runes) <= width:
+		return description
+	case width <= len("..."):
+		return ""
+	default:
+		return strings.TrimRight(string(runes[:width-len("...")]), " ") + "..."
+	}
+}
 
-	return nil
+// formatDownloads formats a download count with thousands separators, as nuget.org shows it.
+func formatDownloads(count int64) string {
+	digits := strconv.FormatInt(count, 10)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 && digits[i-1] != '-' {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
 }
 
 // outputSearchResultsJSON outputs search results in JSON format matching schema
-func outputSearchResultsJSON(searchTerm string, sources []string, results []core.SearchResult, start time.Time) error {
+func outputSearchResultsJSON(console *output.Console, searchTerm string, results []sourceSearchResult, start time.Time) error {
+	sources := make([]string, 0, len(results))
+	for _, result := range results {
+		sources = append(sources, result.source.Value)
+	}
 	jsonOutput := output.NewPackageSearchOutput(searchTerm, sources, start)
 
-	// Convert core.SearchResult to output.SearchResult
-	for i := range results {
-		pkg := &results[i]
-		// Convert authors array to comma-separated string
-		authorsStr := ""
-		if len(pkg.Authors) > 0 {
-			authorsStr = pkg.Authors[0]
-			for j := 1; j < len(pkg.Authors); j++ {
-				authorsStr += ", " + pkg.Authors[j]
-			}
+	for _, result := range results {
+		if result.err != nil {
+			jsonOutput.Problems = append(jsonOutput.Problems, output.SearchProblem{
+				Source:    result.source.Key,
+				SourceURL: result.source.Value,
+				Message:   result.err.Error(),
+			})
+			continue
 		}
 
-		jsonOutput.Items = append(jsonOutput.Items, output.SearchResult{
-			ID:             pkg.ID,
-			Version:        pkg.Version,
-			Description:    pkg.Description,
-			Authors:        authorsStr,
-			TotalDownloads: pkg.TotalDownloads,
-			Verified:       pkg.Verified,
-			IconURL:        pkg.IconURL,
-			Tags:           pkg.Tags,
-		})
+		sourceResults := output.SourceSearchResults{
+			Source:    result.source.Key,
+			SourceURL: result.source.Value,
+			Items:     make([]output.SearchResult, 0, len(result.results)),
+			Total:     len(result.results),
+		}
+		for i := range result.results {
+			sourceResults.Items = append(sourceResults.Items, toJSONSearchResult(&result.results[i]))
+		}
+		jsonOutput.SearchResults = append(jsonOutput.SearchResults, sourceResults)
+
+		// Items lists the results of all sources together
+		jsonOutput.Items = append(jsonOutput.Items, sourceResults.Items...)
 	}
 
 	// Set total count (Note: For now, same as items length. In future, this could be total available results)
-	jsonOutput.Total = len(results)
+	jsonOutput.Total = len(jsonOutput.Items)
 
 	// Update elapsed time
 	jsonOutput.ElapsedMs = output.MeasureElapsed(start)
 
 	// Write JSON to stdout (VR-019: empty results return exit code 0 with valid JSON)
-	return output.WriteJSON(os.Stdout, jsonOutput)
+	return output.WriteJSON(console.Output(), jsonOutput)
+}
+
+// toJSONSearchResult converts a core.SearchResult to output.SearchResult
+func toJSONSearchResult(pkg *core.SearchResult) output.SearchResult {
+	return output.SearchResult{
+		ID:             pkg.ID,
+		Version:        pkg.Version,
+		Description:    pkg.Description,
+		Authors:        strings.Join(pkg.Authors, ", "),
+		TotalDownloads: pkg.TotalDownloads,
+		Verified:       pkg.Verified,
+		IconURL:        pkg.IconURL,
+		Tags:           pkg.Tags,
+	}
 }
 
 // init registers the package search subcommand with the package parent command
@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/gonuget/core"
+)
+
+// newSearchFeed serves a V3 feed whose search returns the given package IDs for any query.
+func newSearchFeed(t *testing.T, packageIDs ...string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/index.json":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": "http://" + r.Host + "/query", "@type": "SearchQueryService"},
+				},
+			})
+		case "/query":
+			data := make([]map[string]any, 0, len(packageIDs))
+			for _, id := range packageIDs {
+				data = append(data, map[string]any{"id": id, "version": "1.0.0", "description": id + " library", "totalDownloads": 42})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"totalHits": len(data), "data": data})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newHangingFeed serves a feed that never answers before the client gives up.
+func newHangingFeed(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeSearchConfig writes a NuGet.config with the given name/URL pairs and returns its path.
+func writeSearchConfig(t *testing.T, dir string, sources ...string) string {
+	t.Helper()
+
+	var adds strings.Builder
+	for i := 0; i < len(sources); i += 2 {
+		adds.WriteString(`    <add key="` + sources[i] + `" value="` + sources[i+1] + `" protocolVersion="3" />` + "\n")
+	}
+	content := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+` + adds.String() + `  </packageSources>
+</configuration>`
+
+	path := filepath.Join(dir, "NuGet.config")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func runPackageSearchCommand(args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := NewPackageSearchCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPackageSearch_FailingSourceIsIsolated(t *testing.T) {
+	oldTimeout := searchSourceTimeout
+	searchSourceTimeout = 500 * time.Millisecond
+	t.Cleanup(func() { searchSourceTimeout = oldTimeout })
+
+	healthy := newSearchFeed(t, "Contoso.Logging")
+	hanging := newHangingFeed(t)
+
+	tmpDir := t.TempDir()
+	writeSearchConfig(t, tmpDir,
+		"healthy", healthy.URL+"/index.json",
+		"hanging", hanging.URL+"/index.json")
+	t.Chdir(tmpDir)
+
+	// The hanging source costs no more than its own timeout
+	start := time.Now()
+	out, err := runPackageSearchCommand("Contoso")
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want success with one healthy source\n%s", err, out)
+	}
+	if elapsed > 3*time.Second {
+		t.Errorf("search took %s, want it bounded by the per-source timeout", elapsed)
+	}
+
+	// Sections appear in configured order, the failure under its own source
+	healthyAt := strings.Index(out, "Source: healthy ("+healthy.URL+"/index.json)")
+	hangingAt := strings.Index(out, "Source: hanging ("+hanging.URL+"/index.json)")
+	if healthyAt < 0 || hangingAt < healthyAt {
+		t.Fatalf("source sections missing or out of order:\n%s", out)
+	}
+	if !strings.Contains(out[healthyAt:hangingAt], "Contoso.Logging  1.0.0") {
+		t.Errorf("healthy source results missing:\n%s", out)
+	}
+	if !strings.Contains(out[hangingAt:], "error: Failed to retrieve results from source 'hanging'") {
+		t.Errorf("failing source error missing:\n%s", out)
+	}
+
+	// --strict fails when any source fails
+	out, err = runPackageSearchCommand("Contoso", "--strict")
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 package source(s)") {
+		t.Errorf("--strict error = %v, want a failed source\n%s", err, out)
+	}
+	if !strings.Contains(out, "Contoso.Logging") {
+		t.Errorf("--strict output missing the healthy results:\n%s", out)
+	}
+}
+
+func TestPackageSearch_ExactMatch(t *testing.T) {
+	feed := newSearchFeed(t, "Contoso.Logging.Extensions", "contoso.logging", "Contoso.Data")
+	tmpDir := t.TempDir()
+	writeSearchConfig(t, tmpDir, "feed", feed.URL+"/index.json")
+	t.Chdir(tmpDir)
+
+	out, err := runPackageSearchCommand("Contoso.Logging", "--exact-match")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "contoso.logging") || strings.Contains(out, "Contoso.Logging.Extensions") || strings.Contains(out, "Contoso.Data") {
+		t.Errorf("output lists other packages than the exact match:\n%s", out)
+	}
+	if !strings.Contains(out, "Showing 1 results") {
+		t.Errorf("output missing result count:\n%s", out)
+	}
+}
+
+func TestWriteSearchResultsTable(t *testing.T) {
+	results := []core.SearchResult{
+		{ID: "Contoso.Logging", Version: "2.1.0", TotalDownloads: 1234567, Description: "Structured logging\nfor Contoso applications and services"},
+		{ID: "Contoso.Data", Version: "10.0.0-preview.1", TotalDownloads: 42, Description: "Data access"},
+	}
+
+	var out bytes.Buffer
+	writeSearchResultsTable(&out, results, 60)
+
+	want := "Package ID       Latest Version    Downloads  Description\n" +
+		"Contoso.Logging  2.1.0             1,234,567  Structured...\n" +
+		"Contoso.Data     10.0.0-preview.1         42  Data access\n"
+	if out.String() != want {
+		t.Errorf("table =\n%s\nwant\n%s", out.String(), want)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		if len(line) > 60 {
+			t.Errorf("line %q is wider than 60 columns", line)
+		}
+	}
+}
+
+func TestPackageSearch_AllSourcesFail(t *testing.T) {
+	oldTimeout := searchSourceTimeout
+	searchSourceTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { searchSourceTimeout = oldTimeout })
+
+	hanging := newHangingFeed(t)
+	tmpDir := t.TempDir()
+	writeSearchConfig(t, tmpDir, "hanging", hanging.URL+"/index.json")
+	t.Chdir(tmpDir)
+
+	out, err := runPackageSearchCommand("Contoso")
+	if err == nil || !strings.Contains(err.Error(), "no package source could be searched") {
+		t.Errorf("Execute() error = %v, want all sources failed\n%s", err, out)
+	}
+}
+
+func TestPackageSearch_JSONProblems(t *testing.T) {
+	oldTimeout := searchSourceTimeout
+	searchSourceTimeout = 200 * time.Millisecond
+	t.Cleanup(func() { searchSourceTimeout = oldTimeout })
+
+	healthy := newSearchFeed(t, "Contoso.Logging", "Contoso.Data")
+	hanging := newHangingFeed(t)
+
+	// Sources come from --configfile only, not from a NuGet.config in the working directory
+	workDir := t.TempDir()
+	writeSearchConfig(t, workDir, "ignored", "https://example.invalid/v3/index.json")
+	t.Chdir(workDir)
+	configFile := writeSearchConfig(t, t.TempDir(),
+		"hanging", hanging.URL+"/index.json",
+		"healthy", healthy.URL+"/index.json")
+
+	out, err := runPackageSearchCommand("Contoso", "--configfile", configFile, "--format", "json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out)
+	}
+
+	var result struct {
+		Sources       []string `json:"sources"`
+		Total         int      `json:"total"`
+		SearchResults []struct {
+			Source string `json:"source"`
+			Items  []struct {
+				ID string `json:"id"`
+			} `json:"items"`
+			Total int `json:"total"`
+		} `json:"searchResults"`
+		Problems []struct {
+			Source    string `json:"source"`
+			SourceURL string `json:"sourceUrl"`
+			Message   string `json:"message"`
+		} `json:"problems"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+
+	if len(result.Sources) != 2 || result.Sources[0] != hanging.URL+"/index.json" {
+		t.Errorf("sources = %v, want the two sources of the config file in order", result.Sources)
+	}
+	if len(result.SearchResults) != 1 || result.SearchResults[0].Source != "healthy" || result.SearchResults[0].Total != 2 {
+		t.Errorf("searchResults = %+v, want the healthy source's 2 results", result.SearchResults)
+	}
+	if result.Total != 2 {
+		t.Errorf("total = %d, want 2", result.Total)
+	}
+	if len(result.Problems) != 1 || result.Problems[0].Source != "hanging" || result.Problems[0].Message == "" {
+		t.Errorf("problems = %+v, want the hanging source", result.Problems)
+	}
+
+	// --source narrows the search to one source of the config file
+	out, err = runPackageSearchCommand("Contoso", "--configfile", configFile, "--source", "healthy", "--format", "json")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out)
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out)
+	}
+	if len(result.Sources) != 1 || len(result.Problems) != 0 {
+		t.Errorf("sources = %v, problems = %+v, want only the healthy source", result.Sources, result.Problems)
+	}
+
+	// A source name from outside the config file is unknown
+	if _, err := runPackageSearchCommand("Contoso", "--configfile", configFile, "--source", "ignored"); err == nil ||
+		!strings.Contains(err.Error(), "package source with name 'ignored' not found") {
+		t.Errorf("Execute() error = %v, want unknown source", err)
+	}
+}
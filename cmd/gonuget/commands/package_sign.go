@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/packaging/signatures"
+)
+
+// PackageSignOptions holds the configuration for the package sign command.
+type PackageSignOptions struct {
+	CertificatePath     string
+	CertificatePassword string
+	Timestamper         string
+	HashAlgorithm       string
+	OutputDirectory     string
+	Overwrite           bool
+	Verbosity           observability.Verbosity // Quiet writes nothing but errors
+}
+
+// NewPackageSignCommand creates the 'package sign' subcommand.
+func NewPackageSignCommand() *cobra.Command {
+	opts := &PackageSignOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sign <PATH>",
+		Short: "Sign packages with a certificate",
+		Long:  packageSignLong("gonuget package sign"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if opts.Verbosity, err = globalVerbosity(cmd); err != nil {
+				return err
+			}
+			return runPackageSign(args[0], opts, cmd.OutOrStdout())
+		},
+	}
+	addPackageSignFlags(cmd, opts)
+
+	return cmd
+}
+
+// packageSignLong returns the long description of a sign command, with examples
+// for the command invoked as commandPath.
+func packageSignLong(commandPath string) string {
+	return `Sign a .nupkg file, or the .nupkg files in a directory, with an author signature.
+
+The signing certificate and its private key are read from a PFX file given with
+--certificate-path. The package is signed in place unless --output names a
+directory for the signed packages. With --timestamper, the signature is
+timestamped by that RFC 3161 timestamp server, so it stays valid after the
+certificate expires.
+
+Packages that are already signed are refused unless --overwrite is given, in
+which case their signature is replaced.
+
+Examples:
+  ` + commandPath + ` MyPackage.1.0.0.nupkg --certificate-path cert.pfx --certificate-password secret
+  ` + commandPath + ` ./artifacts --certificate-path cert.pfx --timestamper http://timestamp.digicert.com
+  ` + commandPath + ` MyPackage.1.0.0.nupkg --certificate-path cert.pfx --output ./signed --overwrite`
+}
+
+// addPackageSignFlags adds the flags shared by the sign commands.
+func addPackageSignFlags(cmd *cobra.Command, opts *PackageSignOptions) {
+	cmd.Flags().StringVar(&opts.CertificatePath, "certificate-path", "", "Path to the PFX file of the signing certificate")
+	cmd.Flags().StringVar(&opts.CertificatePassword, "certificate-password", "", "Password of the PFX file")
+	cmd.Flags().StringVar(&opts.Timestamper, "timestamper", "", "URL of an RFC 3161 timestamp server")
+	cmd.Flags().StringVar(&opts.HashAlgorithm, "hash-algorithm", "SHA256", "Hash algorithm of the signature (SHA256, SHA384 or SHA512)")
+	cmd.Flags().StringVarP(&opts.OutputDirectory, "output", "o", "", "Directory for the signed packages (defaults to signing in place)")
+	cmd.Flags().BoolVar(&opts.Overwrite, "overwrite", false, "Replace the signature of packages that are already signed")
+	_ = cmd.MarkFlagRequired("certificate-path")
+}
+
+// globalVerbosity returns the level of the global --verbosity flag, normal when the
+// command isn't attached to the root command.
+func globalVerbosity(cmd *cobra.Command) (observability.Verbosity, error) {
+	flag := cmd.Flags().Lookup("verbosity")
+	if flag == nil {
+		return observability.VerbosityNormal, nil
+	}
+	return observability.ParseVerbosity(flag.Value.String())
+}
+
+// runPackageSign implements the package sign command logic.
+func runPackageSign(path string, opts *PackageSignOptions, w io.Writer) error {
+	if opts.Verbosity == observability.VerbosityQuiet {
+		w = io.Discard
+	}
+
+	hashAlg, err := parseSigningHashAlgorithm(opts.HashAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	paths, err := findPackageFiles(path, false)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no packages found in '%s'", path)
+	}
+
+	cert, key, chain, err := signatures.LoadSigningCertificateFromPFX(opts.CertificatePath, opts.CertificatePassword)
+	if err != nil {
+		return fmt.Errorf("failed to load signing certificate: %w", err)
+	}
+
+	signOpts := signatures.DefaultSigningOptions(cert, key)
+	signOpts.CertificateChain = chain
+	signOpts.HashAlgorithm = hashAlg
+	signOpts.TimestampURL = opts.Timestamper
+
+	writeSigningCertificate(w, cert)
+	if opts.Timestamper != "" {
+		_, _ = fmt.Fprintf(w, "Timestamping package(s) with:\n  %s\n\n", opts.Timestamper)
+	}
+
+	for _, pkgPath := range paths {
+		outputPath := pkgPath
+		if opts.OutputDirectory != "" {
+			outputPath = filepath.Join(opts.OutputDirectory, filepath.Base(pkgPath))
+		}
+
+		if err := packaging.SignPackageFile(pkgPath, outputPath, signOpts, opts.Overwrite); err != nil {
+			if errors.Is(err, signatures.ErrPackageAlreadySigned) {
+				return fmt.Errorf("'%s' is already signed (use --overwrite to replace its signature)", pkgPath)
+			}
+			return fmt.Errorf("failed to sign '%s': %w", pkgPath, err)
+		}
+		_, _ = fmt.Fprintf(w, "Signed '%s'.\n", outputPath)
+	}
+
+	_, _ = fmt.Fprintln(w, "Package(s) signed successfully.")
+	return nil
+}
+
+// parseSigningHashAlgorithm parses the --hash-algorithm flag.
+func parseSigningHashAlgorithm(name string) (signatures.HashAlgorithmName, error) {
+	for _, hashAlg := range []signatures.HashAlgorithmName{
+		signatures.HashAlgorithmSHA256,
+		signatures.HashAlgorithmSHA384,
+		signatures.HashAlgorithmSHA512,
+	} {
+		if strings.EqualFold(name, string(hashAlg)) {
+			return hashAlg, nil
+		}
+	}
+	return "", fmt.Errorf("invalid hash algorithm '%s' (expected SHA256, SHA384 or SHA512)", name)
+}
+
+// writeSigningCertificate describes the signing certificate, as dotnet nuget sign does.
+func writeSigningCertificate(w io.Writer, cert *x509.Certificate) {
+	fingerprint := sha256.Sum256(cert.Raw)
+	_, _ = fmt.Fprintln(w, "Signing package(s) with certificate:")
+	_, _ = fmt.Fprintf(w, "  Subject Name: %s\n", cert.Subject)
+	_, _ = fmt.Fprintf(w, "  SHA256 hash: %X\n", fingerprint)
+	_, _ = fmt.Fprintf(w, "  Issued by: %s\n", cert.Issuer)
+	_, _ = fmt.Fprintf(w, "  Valid from: %s to %s\n\n", cert.NotBefore.UTC().Format("2006-01-02 15:04:05Z"), cert.NotAfter.UTC().Format("2006-01-02 15:04:05Z"))
+}
+
+func init() {
+	packageCmd := GetPackageCommand()
+	packageCmd.AddCommand(NewPackageSignCommand())
+}
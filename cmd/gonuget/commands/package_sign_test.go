@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const signTestPFX = "../../../packaging/signatures/testdata/signing-chain.pfx"
+
+func runPackageSignCommand(args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := NewPackageSignCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPackageSign(t *testing.T) {
+	dir := writeVerifyTestPackages(t)
+	pkg := filepath.Join(dir, "TestUpdatePackage.1.0.1.nupkg")
+
+	out, err := runPackageSignCommand(pkg, "--certificate-path", signTestPFX, "--certificate-password", "gonuget")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out)
+	}
+	for _, want := range []string{"Subject Name: CN=gonuget Test Signer", "Signed '" + pkg + "'", "Package(s) signed successfully."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = runPackageVerifyCommand(pkg, "--require-signed", "--allow-untrusted-root")
+	if err != nil {
+		t.Fatalf("verify error = %v\n%s", err, out)
+	}
+
+	_, err = runPackageSignCommand(pkg, "--certificate-path", signTestPFX, "--certificate-password", "gonuget")
+	if err == nil || !strings.Contains(err.Error(), "already signed") {
+		t.Errorf("Execute() error = %v, want a package already signed error", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "signed")
+	out, err = runPackageSignCommand(pkg, "--certificate-path", signTestPFX, "--certificate-password", "gonuget",
+		"--overwrite", "--hash-algorithm", "sha384", "--output", output)
+	if err != nil {
+		t.Fatalf("Execute(--overwrite) error = %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(output, "TestUpdatePackage.1.0.1.nupkg")); err != nil {
+		t.Errorf("signed package not written to --output: %v", err)
+	}
+}
+
+func TestPackageSign_Errors(t *testing.T) {
+	dir := writeVerifyTestPackages(t)
+	pkg := filepath.Join(dir, "TestUpdatePackage.1.0.1.nupkg")
+
+	if _, err := runPackageSignCommand(pkg); err == nil {
+		t.Error("Execute() without --certificate-path succeeded")
+	}
+
+	_, err := runPackageSignCommand(pkg, "--certificate-path", signTestPFX, "--certificate-password", "wrong")
+	if err == nil || !strings.Contains(err.Error(), "password is incorrect") {
+		t.Errorf("Execute() error = %v, want an incorrect password error", err)
+	}
+
+	_, err = runPackageSignCommand(pkg, "--certificate-path", signTestPFX, "--hash-algorithm", "MD5")
+	if err == nil || !strings.Contains(err.Error(), "invalid hash algorithm") {
+		t.Errorf("Execute() error = %v, want an invalid hash algorithm error", err)
+	}
+}
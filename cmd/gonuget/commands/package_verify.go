@@ -0,0 +1,320 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/packaging/signatures"
+)
+
+// PackageVerifyOptions holds the configuration for the package verify command.
+type PackageVerifyOptions struct {
+	Recursive          bool
+	RequireSigned      bool
+	RequireTimestamp   bool
+	AllowUntrustedRoot bool
+	TrustedCerts       []string
+	Fingerprints       []string
+	All                bool
+	MaxParallel        int
+	ConfigFile         string
+}
+
+// NewPackageVerifyCommand creates the 'package verify' subcommand.
+func NewPackageVerifyCommand() *cobra.Command {
+	opts := &PackageVerifyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify <PATH>",
+		Short: "Verify the signatures of packages",
+		Long:  packageVerifyLong("gonuget package verify"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPackageVerify(args[0], opts, cmd.OutOrStdout())
+		},
+	}
+	addPackageVerifyFlags(cmd, opts)
+
+	return cmd
+}
+
+// packageVerifyLong returns the long description of a verify command, with examples
+// for the command invoked as commandPath.
+func packageVerifyLong(commandPath string) string {
+	return `Verify the signatures of the .nupkg files in a directory, or of a single .nupkg file.
+
+For each signed package, the package content hash, the signer certificate chain
+and, when the signature is timestamped, the timestamp token signature and the
+timestamp authority are verified. Certificates are trusted when they chain to a
+system root or to a certificate given with --trusted-cert. Packages are verified
+in parallel, and a summary table lists the result of each package, followed by the
+signer, timestamp authority and certificate chain status of each signed package.
+
+With --certificate-fingerprint, the primary signature must be signed by one of
+the given certificates (SHA-256, SHA-384 or SHA-512 fingerprints, in hex). With
+--all, every signature is verified: the repository countersignature of author
+signed packages too.
+
+When the NuGet.config files in effect list trusted signers, a signature must also
+be signed by a trusted author, or signed or countersigned by a trusted repository
+listing one of the trusted owners. Use --configfile to read only one config file.
+
+Unsigned packages are reported but only fail the command with --require-signed.
+The command exits with a non-zero code if any package fails verification.
+
+Examples:
+  ` + commandPath + ` ./packages
+  ` + commandPath + ` ~/.nuget/packages --recursive --require-signed
+  ` + commandPath + ` ./artifacts --trusted-cert ./certs/root.pem
+  ` + commandPath + ` ./artifacts/MyPackage.1.0.0.nupkg --require-timestamp
+  ` + commandPath + ` MyPackage.1.0.0.nupkg --all --certificate-fingerprint 3F9001EA83C560D712C24CF213C3D312CB3BFF51EE89435D3430BD06B5D0EECE`
+}
+
+// addPackageVerifyFlags adds the flags shared by the verify commands.
+func addPackageVerifyFlags(cmd *cobra.Command, opts *PackageVerifyOptions) {
+	cmd.Flags().BoolVarP(&opts.Recursive, "recursive", "r", false, "Also verify packages in subdirectories")
+	cmd.Flags().BoolVar(&opts.RequireSigned, "require-signed", false, "Fail if a package is not signed")
+	cmd.Flags().BoolVar(&opts.RequireTimestamp, "require-timestamp", false, "Fail if a signature is not timestamped")
+	cmd.Flags().BoolVar(&opts.AllowUntrustedRoot, "allow-untrusted-root", false, "Accept signatures whose certificate chain ends in an untrusted root")
+	cmd.Flags().StringSliceVar(&opts.TrustedCerts, "trusted-cert", nil, "PEM file with trusted root certificates (can be repeated)")
+	cmd.Flags().StringSliceVar(&opts.Fingerprints, "certificate-fingerprint", nil, "SHA-256, SHA-384 or SHA-512 fingerprint of a certificate allowed to sign the packages (can be repeated)")
+	cmd.Flags().BoolVar(&opts.All, "all", false, "Verify all signatures, including the repository countersignatures of author signed packages")
+	cmd.Flags().IntVar(&opts.MaxParallel, "max-parallel", 0, "Maximum number of packages verified at once (defaults to the number of CPUs)")
+	cmd.Flags().StringVar(&opts.ConfigFile, "configfile", "", "The NuGet configuration file to read trusted signers from. If not specified, the hierarchy of configuration files from the current directory is used.")
+}
+
+// runPackageVerify implements the package verify command logic.
+func runPackageVerify(path string, opts *PackageVerifyOptions, w io.Writer) error {
+	paths, err := findPackageFiles(path, opts.Recursive)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		_, _ = fmt.Fprintf(w, "No packages found in '%s'.\n", path)
+		return nil
+	}
+
+	verifyOpts, err := packageVerificationOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	results := packaging.VerifyPackageFiles(paths, verifyOpts, opts.MaxParallel)
+
+	failed := writePackageVerifyResults(w, path, results, opts.RequireSigned)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d package(s) failed verification", failed, len(results))
+	}
+	return nil
+}
+
+// packageVerificationOptions builds the signature verification options, trusting
+// the system roots and the certificates of --trusted-cert, and restricting the signers
+// to the trusted signers of the NuGet.config files.
+func packageVerificationOptions(opts *PackageVerifyOptions) (signatures.VerificationOptions, error) {
+	verifyOpts := signatures.DefaultVerificationOptions()
+	verifyOpts.AllowUntrustedRoot = opts.AllowUntrustedRoot
+	verifyOpts.RequireTimestamp = opts.RequireTimestamp
+	verifyOpts.VerifyRepositoryCountersignature = opts.All
+
+	for _, fingerprint := range opts.Fingerprints {
+		normalized := strings.ReplaceAll(fingerprint, ":", "")
+		if _, err := hex.DecodeString(normalized); err != nil || !slices.Contains([]int{64, 96, 128}, len(normalized)) {
+			return verifyOpts, fmt.Errorf("invalid certificate fingerprint '%s' (expected a SHA-256, SHA-384 or SHA-512 fingerprint in hex)", fingerprint)
+		}
+		verifyOpts.AllowedSignerFingerprints = append(verifyOpts.AllowedSignerFingerprints, normalized)
+	}
+
+	policy, err := verifyTrustPolicy(opts.ConfigFile)
+	if err != nil {
+		return verifyOpts, err
+	}
+	verifyOpts.TrustPolicy = policy
+
+	// Fall back to an empty store where the system roots are unavailable
+	if trustStore, err := signatures.NewTrustStoreFromSystem(); err == nil {
+		verifyOpts.TrustStore = trustStore
+	}
+
+	for _, certPath := range opts.TrustedCerts {
+		data, err := os.ReadFile(certPath)
+		if err != nil {
+			return verifyOpts, fmt.Errorf("failed to read trusted certificate: %w", err)
+		}
+		if err := verifyOpts.TrustStore.AddCertificatePEM(data); err != nil {
+			return verifyOpts, fmt.Errorf("invalid trusted certificate '%s': %w", certPath, err)
+		}
+	}
+
+	return verifyOpts, nil
+}
+
+// verifyTrustPolicy loads the trusted signers of configFile, or of the config files in
+// effect in the current directory when it is empty.
+func verifyTrustPolicy(configFile string) (*signatures.TrustPolicy, error) {
+	if configFile != "" {
+		cfg, err := config.LoadNuGetConfig(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config file '%s': %w", configFile, err)
+		}
+		return config.MergeTrustPolicy([]config.ConfigLayer{{Path: configFile, Config: cfg}})
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = "."
+	}
+	return config.MergeTrustPolicy(config.LoadConfigLayers(workingDir))
+}
+
+// findPackageFiles returns the .nupkg files in dir (and its subdirectories when
+// recursive), sorted by path. A path to a .nupkg file returns that file.
+func findPackageFiles(path string, recursive bool) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access '%s': %w", path, err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var paths []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(p), ".nupkg") {
+			paths = append(paths, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search '%s': %w", path, err)
+	}
+
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// writePackageVerifyResults writes the summary table and the failures, and returns
+// the number of packages that failed verification.
+func writePackageVerifyResults(w io.Writer, root string, results []*packaging.PackageVerificationResult, requireSigned bool) int {
+	type row struct {
+		name, result, signature string
+	}
+
+	rows := make([]row, len(results))
+	nameWidth, resultWidth := len("Package"), len("Result")
+	var valid, unsigned, failed int
+	for i, result := range results {
+		name := filepath.Base(result.Path)
+		if result.Identity != nil {
+			name = result.Identity.String()
+		}
+
+		status := string(result.Status)
+		switch {
+		case result.Status == packaging.PackageVerificationValid:
+			valid++
+			status = "Pass"
+		case result.Status == packaging.PackageVerificationUnsigned && !requireSigned:
+			unsigned++
+		default:
+			failed++
+			status = "Fail"
+			if result.Status == packaging.PackageVerificationUnsigned {
+				status = "Fail (unsigned)"
+			}
+		}
+
+		signature := "-"
+		if result.SignatureType != "" {
+			signature = string(result.SignatureType)
+			if result.Timestamped {
+				signature += ", timestamped"
+			}
+		}
+
+		rows[i] = row{name: name, result: status, signature: signature}
+		nameWidth = max(nameWidth, len(name))
+		resultWidth = max(resultWidth, len(status))
+	}
+
+	_, _ = fmt.Fprintf(w, "Verified %d package(s) in '%s':\n\n", len(results), root)
+	_, _ = fmt.Fprintf(w, "   %-*s   %-*s   %s\n", nameWidth, "Package", resultWidth, "Result", "Signature")
+	for _, r := range rows {
+		_, _ = fmt.Fprintf(w, "   %-*s   %-*s   %s\n", nameWidth, r.name, resultWidth, r.result, r.signature)
+	}
+
+	// List the signers of the packages, why packages failed, and the warnings of the others
+	var details bool
+	for _, result := range results {
+		if result.SignerCertificate == nil && len(result.Errors) == 0 && len(result.Warnings) == 0 {
+			continue
+		}
+		if !details {
+			_, _ = fmt.Fprintln(w)
+			details = true
+		}
+		_, _ = fmt.Fprintf(w, "%s:\n", result.Path)
+		if result.SignerCertificate != nil {
+			writeVerifiedSigner(w, string(result.SignatureType), result.SignerCertificate, result.SigningTime, result.TimestampCertificate, result.TrustedRoot)
+		}
+		if cs := result.RepositoryCountersignature; cs != nil && cs.SignerCertificate != nil {
+			writeVerifiedSigner(w, "Repository countersignature", cs.SignerCertificate, cs.SigningTime, nil, cs.TrustedRoot)
+		}
+		for _, err := range result.Errors {
+			_, _ = fmt.Fprintf(w, "   error: %v\n", err)
+		}
+		for _, warning := range result.Warnings {
+			_, _ = fmt.Fprintf(w, "   warning: %s\n", warning)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\nPassed: %d, Failed: %d, Unsigned: %d\n", valid, failed, unsigned)
+	return failed
+}
+
+// writeVerifiedSigner describes the signer of a signature, its timestamp authority and
+// the status of its certificate chain, as dotnet nuget verify does.
+func writeVerifiedSigner(w io.Writer, signatureType string, cert *x509.Certificate, signingTime *time.Time, tsaCert, trustedRoot *x509.Certificate) {
+	fingerprint := sha256.Sum256(cert.Raw)
+	_, _ = fmt.Fprintf(w, "   Signature type: %s\n", signatureType)
+	_, _ = fmt.Fprintf(w, "     Subject Name: %s\n", cert.Subject)
+	_, _ = fmt.Fprintf(w, "     SHA256 hash: %X\n", fingerprint)
+	_, _ = fmt.Fprintf(w, "     Issued by: %s\n", cert.Issuer)
+	if trustedRoot != nil {
+		_, _ = fmt.Fprintf(w, "     Certificate chain: trusted (root: %s)\n", trustedRoot.Subject)
+	} else {
+		_, _ = fmt.Fprintln(w, "     Certificate chain: not trusted")
+	}
+	if signingTime != nil {
+		_, _ = fmt.Fprintf(w, "     Timestamp: %s\n", signingTime.UTC().Format("2006-01-02 15:04:05Z"))
+	}
+	if tsaCert != nil {
+		_, _ = fmt.Fprintf(w, "     Timestamp authority: %s\n", tsaCert.Subject)
+	}
+}
+
+func init() {
+	packageCmd := GetPackageCommand()
+	packageCmd.AddCommand(NewPackageVerifyCommand())
+}
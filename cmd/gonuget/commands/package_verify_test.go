@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/packaging/signatures"
+)
+
+// writeVerifyTestPackages copies an unsigned package into dir and a signed package into dir/nested
+func writeVerifyTestPackages(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	copies := map[string]string{
+		"../../../packaging/testdata/TestUpdatePackage.1.0.1.nupkg":        filepath.Join(dir, "TestUpdatePackage.1.0.1.nupkg"),
+		"../../../packaging/testdata/TestPackage.AuthorSigned.1.0.0.nupkg": filepath.Join(dir, "nested", "TestPackage.AuthorSigned.1.0.0.nupkg"),
+	}
+	for src, dst := range copies {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Skipf("Test package not found: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dst, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func runPackageVerifyCommand(args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := NewPackageVerifyCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestPackageVerify_Directory(t *testing.T) {
+	dir := writeVerifyTestPackages(t)
+
+	out, err := runPackageVerifyCommand(dir)
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "TestUpdatePackage 1.0.1") || !strings.Contains(out, "Unsigned") {
+		t.Errorf("output missing the unsigned package:\n%s", out)
+	}
+	if strings.Contains(out, "TestPackage.AuthorSigned") {
+		t.Errorf("subdirectory verified without --recursive:\n%s", out)
+	}
+	if !strings.Contains(out, "Passed: 0, Failed: 0, Unsigned: 1") {
+		t.Errorf("output missing the summary:\n%s", out)
+	}
+
+	out, err = runPackageVerifyCommand(dir, "--require-signed")
+	if err == nil || !strings.Contains(err.Error(), "1 of 1 package(s) failed verification") {
+		t.Errorf("Execute() error = %v, want a failed verification", err)
+	}
+	if !strings.Contains(out, "Fail (unsigned)") {
+		t.Errorf("output missing the failed unsigned package:\n%s", out)
+	}
+}
+
+func TestPackageVerify_Recursive(t *testing.T) {
+	dir := writeVerifyTestPackages(t)
+
+	out, err := runPackageVerifyCommand(dir, "--recursive", "--allow-untrusted-root")
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"Verified 2 package(s)",
+		"TestPackage.AuthorSigned 1.0.0",
+		"Pass",
+		"Author",
+		"Passed: 1, Failed: 0, Unsigned: 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// A --trusted-cert file without a PEM certificate is an error
+	certPath := filepath.Join(t.TempDir(), "root.pem")
+	if err := os.WriteFile(certPath, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runPackageVerifyCommand(dir, "--trusted-cert", certPath); err == nil || !strings.Contains(err.Error(), "invalid trusted certificate") {
+		t.Errorf("Execute() error = %v, want an invalid certificate error", err)
+	}
+}
+
+func TestPackageVerify_CertificateFingerprint(t *testing.T) {
+	dir := writeVerifyTestPackages(t)
+	pkg := filepath.Join(dir, "TestUpdatePackage.1.0.1.nupkg")
+	if out, err := runPackageSignCommand(pkg, "--certificate-path", signTestPFX, "--certificate-password", "gonuget"); err != nil {
+		t.Fatalf("sign error = %v\n%s", err, out)
+	}
+
+	cert, _, chain, err := signatures.LoadSigningCertificateFromPFX(signTestPFX, "gonuget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := sha256.Sum256(cert.Raw)
+	rootFingerprint := sha256.Sum256(chain[len(chain)-1].Raw)
+
+	out, err := runPackageVerifyCommand(pkg, "--allow-untrusted-root", "--all",
+		"--certificate-fingerprint", hex.EncodeToString(rootFingerprint[:]),
+		"--certificate-fingerprint", hex.EncodeToString(fingerprint[:]))
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"Signature type: Author",
+		"Subject Name: CN=gonuget Test Signer",
+		fmt.Sprintf("SHA256 hash: %X", fingerprint),
+		"Passed: 1, Failed: 0, Unsigned: 0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// Signed by another certificate
+	out, err = runPackageVerifyCommand(pkg, "--allow-untrusted-root", "--certificate-fingerprint", hex.EncodeToString(rootFingerprint[:]))
+	if err == nil || !strings.Contains(out, "does not match any allowed certificate fingerprint") {
+		t.Errorf("Execute() error = %v, want a fingerprint mismatch:\n%s", err, out)
+	}
+
+	if _, err := runPackageVerifyCommand(pkg, "--certificate-fingerprint", "ABCD"); err == nil || !strings.Contains(err.Error(), "invalid certificate fingerprint") {
+		t.Errorf("Execute() error = %v, want an invalid fingerprint error", err)
+	}
+}
+
+func TestPackageVerify_TrustedSigners(t *testing.T) {
+	dir := writeVerifyTestPackages(t)
+	pkg := filepath.Join(dir, "TestUpdatePackage.1.0.1.nupkg")
+	if out, err := runPackageSignCommand(pkg, "--certificate-path", signTestPFX, "--certificate-password", "gonuget"); err != nil {
+		t.Fatalf("sign error = %v\n%s", err, out)
+	}
+
+	cert, _, chain, err := signatures.LoadSigningCertificateFromPFX(signTestPFX, "gonuget")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := sha256.Sum256(cert.Raw)
+	rootFingerprint := sha256.Sum256(chain[len(chain)-1].Raw)
+
+	writeConfig := func(fingerprint []byte) string {
+		path := filepath.Join(t.TempDir(), "NuGet.Config")
+		content := fmt.Sprintf(`<configuration>
+  <trustedSigners>
+    <author name="gonuget">
+      <certificate fingerprint="%X" hashAlgorithm="SHA256" allowUntrustedRoot="true" />
+    </author>
+  </trustedSigners>
+</configuration>`, fingerprint)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// The trusted author accepts the untrusted test root
+	out, err := runPackageVerifyCommand(pkg, "--configfile", writeConfig(fingerprint[:]))
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Passed: 1, Failed: 0, Unsigned: 0") {
+		t.Errorf("output missing the passed package:\n%s", out)
+	}
+
+	// Signed by a certificate that isn't trusted
+	out, err = runPackageVerifyCommand(pkg, "--allow-untrusted-root", "--configfile", writeConfig(rootFingerprint[:]))
+	if err == nil || !strings.Contains(out, "is not a trusted signer") {
+		t.Errorf("Execute() error = %v, want an untrusted signer:\n%s", err, out)
+	}
+
+	if _, err := runPackageVerifyCommand(pkg, "--configfile", writeConfig([]byte{0xAB})); err == nil || !strings.Contains(err.Error(), "invalid certificate fingerprint") {
+		t.Errorf("Execute() error = %v, want an invalid trusted signer", err)
+	}
+}
@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+	"github.com/willibrandon/gonuget/core"
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/packaging"
+)
+
+// defaultPushTimeout is how long a push may take when --timeout isn't given, as in NuGet.
+const defaultPushTimeout = 300 * time.Second
+
+// PushOptions holds the configuration for the push command.
+type PushOptions struct {
+	Source        string
+	APIKey        string
+	SkipDuplicate bool
+	Timeout       int // Seconds; 0 uses defaultPushTimeout
+}
+
+// NewPushCommand creates the top-level push command. It matches dotnet nuget push.
+func NewPushCommand() *cobra.Command {
+	opts := &PushOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "push <PACKAGE_PATH>",
+		Short: "Push a package to a server",
+		Long: `Push a .nupkg file to a package source.
+
+The package is uploaded to the PackagePublish resource of a V3 source, or to the
+feed of a V2 source (/api/v2/package for a source given as a bare host). The source
+is given with --source (a name from NuGet.config, or a URL) and defaults to the
+defaultPushSource config value.
+
+A source that already has the package version answers with a conflict; with
+--skip-duplicate that is reported and the push succeeds.
+
+Examples:
+  gonuget push MyPackage.1.0.0.nupkg --source https://api.nuget.org/v3/index.json --api-key <key>
+  gonuget push bin/Release/MyPackage.1.0.0.nupkg --source MyInternalFeed --skip-duplicate
+  gonuget push MyPackage.1.0.0.nupkg --source https://myget.example/F/feed/api/v2/package --timeout 600`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPush(cmd.Context(), args[0], opts, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Source, "source", "s", "", "Package source (URL or name from NuGet.config) to push the package to")
+	cmd.Flags().StringVarP(&opts.APIKey, "api-key", "k", "", "The API key for the server")
+	cmd.Flags().BoolVar(&opts.SkipDuplicate, "skip-duplicate", false, "Treat a package version the server already has as pushed")
+	cmd.Flags().IntVarP(&opts.Timeout, "timeout", "t", 0, "Timeout for pushing to the server in seconds (default 300)")
+
+	return cmd
+}
+
+// runPush implements the push command logic.
+// Reference: PushRunner.Run and PackageUpdateResource.Push
+func runPush(ctx context.Context, packagePath string, opts *PushOptions, w io.Writer) error {
+	if opts.Timeout < 0 {
+		return fmt.Errorf("invalid timeout %d: must be a number of seconds", opts.Timeout)
+	}
+	timeout := defaultPushTimeout
+	if opts.Timeout > 0 {
+		timeout = time.Duration(opts.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	data, err := os.ReadFile(packagePath)
+	if err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+	pkg, err := packaging.OpenPackageFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("%s is not a valid package: %w", packagePath, err)
+	}
+	identity, err := pkg.GetIdentity()
+	_ = pkg.Close()
+	if err != nil {
+		return fmt.Errorf("%s is not a valid package: %w", packagePath, err)
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		workingDir = "."
+	}
+	layers := config.LoadConfigLayers(workingDir)
+
+	source, err := resolvePackageUpdateSource(opts.Source, layers, workingDir)
+	if err != nil {
+		return err
+	}
+
+	// The client timeout would cut a large upload short of --timeout
+	httpConfig := nugethttp.DefaultConfig()
+	httpConfig.Timeout = timeout
+	repoManager := core.NewRepositoryManager()
+	if err := repoManager.AddRepository(core.NewSourceRepository(core.RepositoryConfig{
+		Name:            source.Key,
+		SourceURL:       source.Value,
+		ProtocolVersion: source.ProtocolVersion,
+		HTTPClient:      nugethttp.NewClient(httpConfig),
+	})); err != nil {
+		return fmt.Errorf("failed to add repository: %w", err)
+	}
+	client := core.NewClient(core.ClientConfig{
+		RepositoryManager:  repoManager,
+		CredentialProvider: config.NewCredentialProvider(layers),
+	})
+	repo, err := client.GetRepositoryManager().GetRepository(source.Key)
+	if err != nil {
+		return err
+	}
+
+	pushURL, err := repo.PackageUpdateURL(ctx)
+	if err != nil {
+		return err
+	}
+	if opts.APIKey == "" {
+		_, _ = fmt.Fprintf(w, "warn : No API Key was provided and no API Key could be found for '%s'. To save an API Key for a source use the 'setApiKey' command.\n", pushURL)
+	}
+
+	_, _ = fmt.Fprintf(w, "Pushing %s to '%s'...\n", filepath.Base(packagePath), pushURL)
+	_, _ = fmt.Fprintf(w, "  PUT %s\n", pushURL)
+	start := time.Now()
+	err = repo.PushPackage(ctx, bytes.NewReader(data), opts.APIKey)
+	elapsed := time.Since(start).Milliseconds()
+
+	var updateErr *core.PackageUpdateError
+	switch {
+	case errors.As(err, &updateErr):
+		_, _ = fmt.Fprintf(w, "  %s %s %dms\n", statusName(updateErr.StatusCode), pushURL, elapsed)
+		if updateErr.StatusCode == http.StatusConflict && opts.SkipDuplicate {
+			_, _ = fmt.Fprintf(w, "Package '%s' already exists at feed '%s'.\n", packagePath, pushURL)
+			return nil
+		}
+		return pushError(updateErr, identity)
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil:
+		return fmt.Errorf("pushing %s timed out after %s; use --timeout to allow more time", identity, timeout)
+	case err != nil:
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "  OK %s %dms\n", pushURL, elapsed)
+	_, _ = fmt.Fprintln(w, "Your package was pushed.")
+	return nil
+}
+
+// pushError explains the responses a source commonly rejects a push with; the status
+// line of the response stays in the error.
+func pushError(err *core.PackageUpdateError, identity *packaging.PackageIdentity) error {
+	switch err.StatusCode {
+	case http.StatusBadRequest:
+		return fmt.Errorf("the source rejected package %s as invalid: %w", identity, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("the API key is missing, invalid or not allowed to push package %s: %w", identity, err)
+	case http.StatusConflict:
+		return fmt.Errorf("package %s already exists at the source; use --skip-duplicate to ignore it: %w", identity, err)
+	}
+	return err
+}
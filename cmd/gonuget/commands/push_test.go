@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// pushRecorder records the uploads a test feed receives.
+type pushRecorder struct {
+	mu       sync.Mutex
+	paths    []string
+	apiKeys  []string
+	packages [][]byte
+	errs     []string
+}
+
+// record reads the package from the multipart body the way NuGet servers do.
+func (p *pushRecorder) record(r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paths = append(p.paths, r.URL.Path)
+	p.apiKeys = append(p.apiKeys, r.Header.Get("X-NuGet-ApiKey"))
+
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		p.errs = append(p.errs, "Content-Type = "+r.Header.Get("Content-Type"))
+		return
+	}
+	part, err := multipart.NewReader(r.Body, params["boundary"]).NextPart()
+	if err != nil {
+		p.errs = append(p.errs, err.Error())
+		return
+	}
+	if part.FormName() != "package" || part.FileName() != "package.nupkg" {
+		p.errs = append(p.errs, "part "+part.FormName()+" "+part.FileName())
+	}
+	data, _ := io.ReadAll(part)
+	p.packages = append(p.packages, data)
+}
+
+// newPushFeed serves a V3 feed with a PackagePublish resource that answers uploads with status.
+func newPushFeed(t *testing.T, status int, pushes *pushRecorder) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/index.json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": "http://" + r.Host + "/api/v2/package", "@type": "PackagePublish/2.0.0"},
+				},
+			})
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v2/package":
+			pushes.record(r)
+			w.WriteHeader(status)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPush_StatusCodes(t *testing.T) {
+	t.Chdir(t.TempDir())
+	packagePath := buildSignTestPackage(t, t.TempDir())
+	packageData, err := os.ReadFile(packagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		status        int
+		skipDuplicate bool
+		wantErr       string
+		wantOut       string
+	}{
+		{name: "created", status: http.StatusCreated, wantOut: "Your package was pushed."},
+		{name: "accepted", status: http.StatusAccepted, wantOut: "Your package was pushed."},
+		{
+			name:    "invalid package",
+			status:  http.StatusBadRequest,
+			wantErr: "the source rejected package Gonuget.SignTest 1.2.3 as invalid: Response status code does not indicate success: 400 (Bad Request).",
+			wantOut: "  BadRequest ",
+		},
+		{
+			name:    "invalid API key",
+			status:  http.StatusForbidden,
+			wantErr: "the API key is missing, invalid or not allowed to push package Gonuget.SignTest 1.2.3: Response status code does not indicate success: 403 (Forbidden).",
+			wantOut: "  Forbidden ",
+		},
+		{
+			name:    "duplicate",
+			status:  http.StatusConflict,
+			wantErr: "package Gonuget.SignTest 1.2.3 already exists at the source; use --skip-duplicate to ignore it: Response status code does not indicate success: 409 (Conflict).",
+			wantOut: "  Conflict ",
+		},
+		{
+			name:          "skipped duplicate",
+			status:        http.StatusConflict,
+			skipDuplicate: true,
+			wantOut:       "Package '" + packagePath + "' already exists at feed '",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pushes := &pushRecorder{}
+			server := newPushFeed(t, tt.status, pushes)
+
+			var out bytes.Buffer
+			opts := &PushOptions{Source: server.URL + "/index.json", APIKey: "secret", SkipDuplicate: tt.skipDuplicate}
+			err := runPush(t.Context(), packagePath, opts, &out)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("runPush() error = %v\n%s", err, out.String())
+				}
+			} else if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("runPush() error = %v, want %q", err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("output missing %q:\n%s", tt.wantOut, out.String())
+			}
+			if !strings.Contains(out.String(), "  PUT "+server.URL+"/api/v2/package\n") {
+				t.Errorf("output missing PUT line:\n%s", out.String())
+			}
+
+			if len(pushes.paths) != 1 {
+				t.Fatalf("PUT requests = %v", pushes.paths)
+			}
+			if pushes.apiKeys[0] != "secret" {
+				t.Errorf("X-NuGet-ApiKey = %q, want secret", pushes.apiKeys[0])
+			}
+			if len(pushes.errs) > 0 {
+				t.Errorf("upload body: %v", pushes.errs)
+			}
+			if len(pushes.packages) != 1 || !bytes.Equal(pushes.packages[0], packageData) {
+				t.Error("uploaded package differs from the .nupkg file")
+			}
+		})
+	}
+}
+
+func TestPush_V2Fallback(t *testing.T) {
+	t.Chdir(t.TempDir())
+	packagePath := buildSignTestPackage(t, t.TempDir())
+
+	pushes := &pushRecorder{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><service xmlns="http://www.w3.org/2007/app"/>`))
+		case r.Method == http.MethodPut:
+			pushes.record(r)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	var out bytes.Buffer
+	if err := runPush(t.Context(), packagePath, &PushOptions{Source: server.URL}, &out); err != nil {
+		t.Fatalf("runPush() error = %v\n%s", err, out.String())
+	}
+	if len(pushes.paths) != 1 || pushes.paths[0] != "/api/v2/package" {
+		t.Errorf("PUT requests = %v", pushes.paths)
+	}
+	if !strings.Contains(out.String(), "No API Key was provided") {
+		t.Errorf("output missing API key warning:\n%s", out.String())
+	}
+}
+
+func TestPush_Timeout(t *testing.T) {
+	t.Chdir(t.TempDir())
+	packagePath := buildSignTestPackage(t, t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/" {
+			w.Header().Set("Content-Type", "application/atom+xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><service xmlns="http://www.w3.org/2007/app"/>`))
+			return
+		}
+		// Read the upload, so the server notices the client going away, and never answer
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	var out bytes.Buffer
+	err := runPush(t.Context(), packagePath, &PushOptions{Source: server.URL, Timeout: 1}, &out)
+	if err == nil || err.Error() != "pushing Gonuget.SignTest 1.2.3 timed out after 1s; use --timeout to allow more time" {
+		t.Errorf("runPush() error = %v", err)
+	}
+}
+
+func TestPush_InvalidPackage(t *testing.T) {
+	t.Chdir(t.TempDir())
+	path := "not-a-package.nupkg"
+	if err := os.WriteFile(path, []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := runPush(t.Context(), path, &PushOptions{Source: "https://example.invalid/v3/index.json"}, io.Discard)
+	if err == nil || !strings.HasPrefix(err.Error(), "not-a-package.nupkg is not a valid package") {
+		t.Errorf("runPush() error = %v", err)
+	}
+}
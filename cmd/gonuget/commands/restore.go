@@ -1,18 +1,30 @@
 package commands
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/willibrandon/gonuget/cmd/gonuget/config"
 	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/observability"
 	"github.com/willibrandon/gonuget/restore"
 )
 
+// restoreSourceOptions holds the source selection flags for restore.
+type restoreSourceOptions struct {
+	additional []string // --source: appended to the configured sources for this run
+	override   []string // --source-override: replaces the configured sources for this run
+}
+
 // NewRestoreCommand creates the restore command.
 func NewRestoreCommand(console *output.Console) *cobra.Command {
 	opts := &restore.Options{}
+	sourceOpts := &restoreSourceOptions{}
+	var verbosity string
 
 	cmd := &cobra.Command{
 		Use:   "restore [<PROJECT|SOLUTION>]",
@@ -21,48 +33,190 @@ func NewRestoreCommand(console *output.Console) *cobra.Command {
 
 Downloads packages to the global package cache and generates project.assets.json.
 
+Package sources:
+  By default the enabled sources from the NuGet.config hierarchy are used.
+  --source adds a source to that set for this run only; it may be repeated.
+  --source-override replaces the configured sources for this run only; any
+  --source values are added after the override list.
+  Sources passed on the command line are always used, even if a source with
+  the same URL is listed in disabledPackageSources. NuGet.config is never
+  modified by either flag.
+
+Source integrity:
+  --verify-source-hashes downloads each newly installed package from every
+  source that has it and emits warning GN1001 if the content differs (for
+  example a republished package or a tampered mirror).
+  --strict-source-hashes reports a mismatch as an error and fails the restore.
+
+Project properties:
+  RestorePackagesPath, RestoreNoCache and RestoreIgnoreFailedSources are read
+  from the project, the nearest Directory.Build.props or the environment.
+  --packages, --no-cache and --ignore-failed-sources take precedence.
+  With RestoreIgnoreFailedSources an unreachable source is reported as warning
+  NU1801 and the remaining sources are used; otherwise it fails the restore
+  with NU1301.
+
+Forcing and lock files:
+  A project opts into packages.lock.json with RestorePackagesWithLockFile
+  (or --use-lock-file); an existing lock file is always honored.
+
+                     no-op cache    packages.lock.json
+    (default)        used           honored
+    --force          ignored        honored
+    --force-evaluate ignored        re-resolved and rewritten
+    --locked-mode    used           must be up to date (NU1004)
+
+  --force restores again but keeps the locked versions, downloading only
+  packages missing from the global packages folder. --force-evaluate also
+  re-resolves floating versions such as 1.* and rewrites the lock file.
+  RestoreLockedMode and NuGetLockFilePath are read like the properties below.
+
+Solutions:
+  A .sln or .slnx solution, or a directory with a single solution and no
+  project file, restores each of its projects, projects referenced through
+  ProjectReference first. Projects share package metadata lookups and HTTP connections. A
+  project that fails doesn't stop the others; the errors of all projects are
+  reported at the end. --fail-fast stops at the first project that fails.
+
+Legacy log format:
+  --legacy-log-format also prints the nuget.exe restore milestones
+  ("Restoring packages for X...", "Committing restore...", "Writing assets
+  file to disk...", "Restore completed in 1.2 sec for X.") for build wrappers
+  that parse them. It is enabled automatically when GONUGET_LEGACY_LOG_FORMAT
+  is set to a true value.
+
 Examples:
   gonuget restore
   gonuget restore MyApp.csproj
+  gonuget restore MyApp.sln --fail-fast
+  gonuget restore --source https://ci.example.com/v3/index.json
+  gonuget restore --source-override ./local-feed
+  gonuget restore --source ./mirror --strict-source-hashes
   gonuget restore --packages /custom/packages
   gonuget restore --force
+  gonuget restore --force-evaluate
   gonuget restore -v:quiet`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Load sources from NuGet.config if not provided via --source flag
-			if len(opts.Sources) == 0 {
+			var err error
+			if opts.Verbosity, err = observability.ParseVerbosity(verbosity); err != nil {
+				return err
+			}
+
+			var configured []string
+			protocolVersions := make(map[string]string)
+
+			// Load sources from NuGet.config unless they are replaced via --source-override
+			if len(sourceOpts.override) == 0 {
 				// Determine directory to search for config
 				var searchDir string
 				if len(args) > 0 {
 					searchDir = filepath.Dir(args[0])
+					if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+						searchDir = args[0]
+					}
 				} else {
-					var err error
 					searchDir, err = os.Getwd()
 					if err != nil {
 						searchDir = "."
 					}
 				}
 
-				// Load sources from config with fallback to defaults
-				sources := config.GetEnabledSourcesOrDefault(searchDir)
+				sources, err := restoreConfigSources(opts.ConfigFile, searchDir)
+				if err != nil {
+					return err
+				}
 				for _, source := range sources {
-					opts.Sources = append(opts.Sources, source.Value)
+					configured = append(configured, source.Value)
+					if source.ProtocolVersion != "" {
+						protocolVersions[source.Value] = source.ProtocolVersion
+					}
 				}
 			}
 
+			opts.Sources = mergeRestoreSources(configured, sourceOpts)
+			opts.SourceProtocolVersions = protocolVersions
+
+			// Build wrappers opt in through the environment; an explicit flag wins
+			if !cmd.Flags().Changed("legacy-log-format") {
+				opts.LegacyLogFormat = restore.LegacyLogFormatFromEnv()
+			}
+
 			// CLI just calls library function
 			return restore.Run(cmd.Context(), args, opts, console)
 		},
 	}
 
 	// Flag binding
-	cmd.Flags().StringSliceVarP(&opts.Sources, "source", "s", nil, "Package source(s) to use")
+	cmd.Flags().StringSliceVarP(&sourceOpts.additional, "source", "s", nil, "Additional package source(s) to use for this run")
+	cmd.Flags().StringSliceVar(&sourceOpts.override, "source-override", nil, "Package source(s) that replace the configured sources for this run")
 	cmd.Flags().StringVar(&opts.PackagesFolder, "packages", "", "Custom global packages folder")
 	cmd.Flags().StringVar(&opts.ConfigFile, "configfile", "", "NuGet configuration file")
-	cmd.Flags().BoolVar(&opts.Force, "force", false, "Force re-download even if packages exist")
+	cmd.Flags().BoolVar(&opts.Force, "force", false, "Force all dependencies to be resolved even if the last restore was successful")
+	cmd.Flags().BoolVar(&opts.ForceEvaluate, "force-evaluate", false, "Re-resolve all dependencies even if a lock file exists, and rewrite the lock file")
+	cmd.Flags().BoolVar(&opts.UseLockFile, "use-lock-file", false, "Generate and use packages.lock.json")
+	cmd.Flags().BoolVar(&opts.LockedMode, "locked-mode", false, "Fail if the lock file would change")
+	cmd.Flags().StringVar(&opts.LockFilePath, "lock-file-path", "", "Path of the lock file (default: packages.lock.json next to the project)")
 	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", false, "Don't use HTTP cache")
+	cmd.Flags().BoolVar(&opts.IgnoreFailedSources, "ignore-failed-sources", false, "Treat package source failures as warnings")
 	cmd.Flags().BoolVar(&opts.NoDependencies, "no-dependencies", false, "Only restore direct references")
-	cmd.Flags().StringVarP(&opts.Verbosity, "verbosity", "v", "minimal", "Verbosity level: q[uiet], m[inimal], n[ormal], d[etailed], or diag[nostic]")
+	cmd.Flags().BoolVar(&opts.AllowPrereleaseEverywhere, "prerelease", false, "Allow prerelease versions for all dependencies, not only prerelease ranges")
+	cmd.Flags().BoolVar(&opts.VerifySourceHashes, "verify-source-hashes", false, "Warn when a package has different content on different sources")
+	cmd.Flags().BoolVar(&opts.StrictSourceHashes, "strict-source-hashes", false, "Fail restore when a package has different content on different sources")
+	cmd.Flags().BoolVar(&opts.LegacyLogFormat, "legacy-log-format", false, "Also print nuget.exe-style restore milestones for legacy build wrappers")
+	cmd.Flags().BoolVar(&opts.FailFast, "fail-fast", false, "Stop restoring a solution at the first project that fails")
+	cmd.Flags().DurationVar(&opts.LockTimeout, "lock-timeout", 0, "How long to wait for another process installing the same package (default 2m)")
+	cmd.Flags().StringVarP(&verbosity, "verbosity", "v", "minimal", "Verbosity level: q[uiet], m[inimal], n[ormal], d[etailed], or diag[nostic]")
 
 	return cmd
 }
+
+// mergeRestoreSources combines configured sources with the command-line source flags.
+// The override list replaces configured sources; additional sources are appended after
+// the base set. Duplicates (case-insensitive, ignoring a trailing slash) are dropped
+// while preserving first-seen order.
+func mergeRestoreSources(configured []string, opts *restoreSourceOptions) []string {
+	base := configured
+	if len(opts.override) > 0 {
+		base = opts.override
+	}
+
+	seen := make(map[string]bool)
+	merged := make([]string, 0, len(base)+len(opts.additional))
+	for _, list := range [][]string{base, opts.additional} {
+		for _, source := range list {
+			source = strings.TrimSpace(source)
+			if source == "" {
+				continue
+			}
+			key := nugethttp.CanonicalSourceURL(source)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, source)
+		}
+	}
+
+	return merged
+}
+
+// restoreConfigSources returns the enabled sources of the --configfile file when given,
+// otherwise those of the config hierarchy from searchDir, merged with clear/add semantics,
+// with a fallback to the defaults. Only the hierarchy fallback creates the user config on
+// first run.
+func restoreConfigSources(configFile, searchDir string) ([]config.PackageSource, error) {
+	if configFile == "" {
+		if sources := config.MergeEnabledPackageSources(config.LoadConfigLayers(searchDir)); len(sources) > 0 {
+			return sources, nil
+		}
+		return config.GetEnabledSourcesOrDefault(searchDir), nil
+	}
+
+	cfg, err := config.LoadNuGetConfig(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file '%s': %w", configFile, err)
+	}
+	layers := []config.ConfigLayer{{Path: configFile, Config: cfg}}
+	return cfg.EnabledPackageSources(config.MergeDisabledSources(layers)), nil
+}
@@ -2,8 +2,11 @@ package commands
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
 	"github.com/willibrandon/gonuget/cmd/gonuget/output"
 )
 
@@ -131,3 +134,120 @@ func TestRestoreCommand_SourcesSliceFlag(t *testing.T) {
 		t.Errorf("source flag type = %q, want %q", sourceFlag.Value.Type(), "stringSlice")
 	}
 }
+
+func TestRestoreCommand_SourceOverrideFlag(t *testing.T) {
+	var out bytes.Buffer
+	console := output.NewConsole(&out, &out, output.VerbosityNormal)
+
+	cmd := NewRestoreCommand(console)
+
+	flag := cmd.Flags().Lookup("source-override")
+	if flag == nil {
+		t.Fatal("source-override flag not found")
+	}
+
+	if flag.Value.Type() != "stringSlice" {
+		t.Errorf("source-override flag type = %q, want %q", flag.Value.Type(), "stringSlice")
+	}
+}
+
+func TestMergeRestoreSources(t *testing.T) {
+	configured := []string{"https://api.nuget.org/v3/index.json", "https://company.example.com/nuget"}
+
+	tests := []struct {
+		name     string
+		opts     *restoreSourceOptions
+		expected []string
+	}{
+		{
+			name:     "no flags uses configured sources",
+			opts:     &restoreSourceOptions{},
+			expected: configured,
+		},
+		{
+			name:     "source augments configured sources",
+			opts:     &restoreSourceOptions{additional: []string{"./local-feed", "https://ci.example.com/v3/index.json"}},
+			expected: append(append([]string{}, configured...), "./local-feed", "https://ci.example.com/v3/index.json"),
+		},
+		{
+			name:     "source duplicate of configured source is dropped",
+			opts:     &restoreSourceOptions{additional: []string{"https://API.nuget.org/v3/index.json/"}},
+			expected: configured,
+		},
+		{
+			name:     "override replaces configured sources",
+			opts:     &restoreSourceOptions{override: []string{"./local-feed"}},
+			expected: []string{"./local-feed"},
+		},
+		{
+			name: "override combined with source",
+			opts: &restoreSourceOptions{
+				override:   []string{"./local-feed"},
+				additional: []string{"https://ci.example.com/v3/index.json", "./local-feed"},
+			},
+			expected: []string{"./local-feed", "https://ci.example.com/v3/index.json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeRestoreSources(configured, tt.opts)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("mergeRestoreSources() = %v, want %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("mergeRestoreSources()[%d] = %q, want %q", i, got[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRestoreConfigSources_ConfigFileDoesNotCreateUserConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("APPDATA", home)
+
+	configFile := filepath.Join(t.TempDir(), "ci.config")
+	ciConfig := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+  </packageSources>
+</configuration>`
+	if err := os.WriteFile(configFile, []byte(ciConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sources, err := restoreConfigSources(configFile, t.TempDir())
+	if err != nil {
+		t.Fatalf("restoreConfigSources() error = %v", err)
+	}
+	if len(sources) != 0 {
+		t.Errorf("restoreConfigSources() = %+v, want no sources", sources)
+	}
+	if _, err := os.Stat(config.GetUserConfigPath()); !os.IsNotExist(err) {
+		t.Errorf("--configfile created the user config (stat error = %v)", err)
+	}
+
+	if _, err := restoreConfigSources(filepath.Join(home, "missing.config"), t.TempDir()); err == nil {
+		t.Error("restoreConfigSources() expected error for a missing config file")
+	}
+}
+
+func TestRestoreCommand_InvalidVerbosity(t *testing.T) {
+	var out bytes.Buffer
+	console := output.NewConsole(&out, &out, output.VerbosityNormal)
+
+	cmd := NewRestoreCommand(console)
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"-v", "chatty", t.TempDir()})
+
+	err := cmd.Execute()
+	if want := "Verbosity level is not valid.\nSwitch: chatty"; err == nil || err.Error() != want {
+		t.Errorf("Execute() error = %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+	"github.com/willibrandon/gonuget/serve"
+)
+
+type serveOptions struct {
+	addr               string
+	readOnly           bool
+	apiKey             string
+	allowAnonymousPush bool
+}
+
+// NewServeCommand creates the serve command
+func NewServeCommand(console *output.Console) *cobra.Command {
+	opts := &serveOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve <FOLDER>",
+		Short: "Serve a local folder feed over HTTP",
+		Long: `Serve a local folder feed as a minimal NuGet V3 feed, for machines that
+can't mount the share.
+
+The folder may contain .nupkg files directly or in the {id}/{version}/ layout
+written by "nuget add". Packages copied into the folder are served right away.
+
+The feed supports restore (dotnet and gonuget), version listing and
+autocomplete. Packages can be pushed unless --read-only is set; pushed
+packages are written in the {id}/{version}/ layout. Set --api-key to require
+that key from clients that push.
+
+The server listens on localhost unless --addr says otherwise. On any other
+address it refuses to accept pushes without an API key: set --api-key, pass
+--read-only, or opt in with --allow-anonymous-push.
+
+Examples:
+  gonuget serve ./packages
+  gonuget serve /mnt/share/feed --addr :8080 --read-only
+  gonuget serve ./packages --addr :8080 --api-key secret
+
+Then use http://localhost:5555/v3/index.json as the package source.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(console, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.addr, "addr", "localhost:5555", "Address to listen on")
+	cmd.Flags().BoolVar(&opts.readOnly, "read-only", false, "Disable package push")
+	cmd.Flags().StringVar(&opts.apiKey, "api-key", "", "API key required to push packages")
+	cmd.Flags().BoolVar(&opts.allowAnonymousPush, "allow-anonymous-push", false, "Accept pushes without an API key on a non-loopback address")
+
+	return cmd
+}
+
+func runServe(console *output.Console, folder string, opts *serveOptions) error {
+	absFolder, err := filepath.Abs(folder)
+	if err != nil {
+		return err
+	}
+
+	server, err := serve.NewServer(serve.Options{
+		Folder:   absFolder,
+		ReadOnly: opts.readOnly,
+		APIKey:   opts.apiKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", opts.addr)
+	if err != nil {
+		return err
+	}
+
+	if !opts.readOnly && opts.apiKey == "" {
+		if !isLoopbackAddr(listener.Addr()) {
+			if !opts.allowAnonymousPush {
+				_ = listener.Close()
+				return fmt.Errorf("refusing to accept pushes without an API key on %s, which other machines can reach: set --api-key, --read-only or --allow-anonymous-push", opts.addr)
+			}
+			console.Warning("Push is enabled without an API key; anyone who can reach the server can push packages")
+		}
+	}
+	console.Info("Serving %s", absFolder)
+	console.Printf("Package source: http://%s%s\n", displayAddr(listener.Addr()), serve.ServiceIndexPath)
+
+	httpServer := &http.Server{
+		Handler:           server,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// isLoopbackAddr reports whether a listen address is only reachable from this machine.
+func isLoopbackAddr(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && tcpAddr.IP.IsLoopback()
+}
+
+// displayAddr turns a wildcard listen address into one clients on this machine can use.
+func displayAddr(addr net.Addr) string {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok || !tcpAddr.IP.IsUnspecified() {
+		return addr.String()
+	}
+	return net.JoinHostPort("localhost", fmt.Sprint(tcpAddr.Port))
+}
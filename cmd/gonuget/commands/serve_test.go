@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+)
+
+func TestNewServeCommand_ListensOnLocalhostByDefault(t *testing.T) {
+	var out bytes.Buffer
+	cmd := NewServeCommand(output.NewConsole(&out, &out, output.VerbosityNormal))
+
+	if got := cmd.Flags().Lookup("addr").DefValue; got != "localhost:5555" {
+		t.Errorf("--addr default = %q, want localhost:5555", got)
+	}
+}
+
+func TestRunServe_RefusesAnonymousPushOnNetworkAddress(t *testing.T) {
+	var out bytes.Buffer
+	console := output.NewConsole(&out, &out, output.VerbosityNormal)
+
+	err := runServe(console, t.TempDir(), &serveOptions{addr: "0.0.0.0:0"})
+	if err == nil || !strings.Contains(err.Error(), "--allow-anonymous-push") {
+		t.Errorf("runServe() error = %v, want a refusal naming --allow-anonymous-push", err)
+	}
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr net.Addr
+		want bool
+	}{
+		{&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5555}, true},
+		{&net.TCPAddr{IP: net.IPv6loopback, Port: 5555}, true},
+		{&net.TCPAddr{IP: net.IPv4zero, Port: 5555}, false},
+		{&net.TCPAddr{IP: net.IPv6unspecified, Port: 5555}, false},
+		{&net.TCPAddr{IP: net.IPv4(192, 168, 1, 10), Port: 5555}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr.String(), func(t *testing.T) {
+			if got := isLoopbackAddr(tt.addr); got != tt.want {
+				t.Errorf("isLoopbackAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
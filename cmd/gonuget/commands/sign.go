@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewSignCommand creates the top-level sign command. It matches dotnet nuget sign
+// and shares its flags and behavior with 'package sign'.
+func NewSignCommand() *cobra.Command {
+	opts := &PackageSignOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sign <PATH>",
+		Short: "Sign packages with a certificate",
+		Long:  packageSignLong("gonuget sign"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if opts.Verbosity, err = globalVerbosity(cmd); err != nil {
+				return err
+			}
+			return runPackageSign(args[0], opts, cmd.OutOrStdout())
+		},
+	}
+	addPackageSignFlags(cmd, opts)
+
+	return cmd
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/packaging/signatures"
+	"github.com/willibrandon/gonuget/version"
+)
+
+func runSignCommand(args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := NewSignCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// buildSignTestPackage writes a package built by PackageBuilder to dir
+func buildSignTestPackage(t *testing.T, dir string) string {
+	t.Helper()
+
+	builder := packaging.NewPackageBuilder()
+	builder.SetID("Gonuget.SignTest").
+		SetVersion(version.MustParse("1.2.3")).
+		SetDescription("Package signed by the sign command tests").
+		SetAuthors("gonuget")
+	if err := builder.AddFileFromBytes("lib/net8.0/Gonuget.SignTest.dll", []byte("not really an assembly")); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "Gonuget.SignTest.1.2.3.nupkg")
+	if err := builder.SaveToFile(path); err != nil {
+		t.Fatalf("SaveToFile() error = %v", err)
+	}
+	return path
+}
+
+func TestSign_BuiltPackage(t *testing.T) {
+	verifyOpts := signatures.DefaultVerificationOptions()
+	verifyOpts.AllowUntrustedRoot = true
+
+	for _, hashAlg := range []string{"sha256", "sha384", "sha512"} {
+		t.Run(hashAlg, func(t *testing.T) {
+			pkg := buildSignTestPackage(t, t.TempDir())
+
+			out, err := runSignCommand(pkg, "--certificate-path", signTestPFX, "--certificate-password", "gonuget", "--hash-algorithm", hashAlg)
+			if err != nil {
+				t.Fatalf("Execute() error = %v\n%s", err, out)
+			}
+			if !strings.Contains(out, "Signed '"+pkg+"'") {
+				t.Errorf("output missing the signed package:\n%s", out)
+			}
+
+			r := packaging.VerifyPackageFile(pkg, verifyOpts)
+			if r.Status != packaging.PackageVerificationValid {
+				t.Fatalf("VerifyPackageFile() Status = %s, errors = %v", r.Status, r.Errors)
+			}
+			if r.Identity == nil || r.Identity.ID != "Gonuget.SignTest" {
+				t.Errorf("Identity = %v, want Gonuget.SignTest", r.Identity)
+			}
+
+			// The package content is left as built
+			reader, err := packaging.OpenPackage(pkg)
+			if err != nil {
+				t.Fatalf("OpenPackage() error = %v", err)
+			}
+			defer func() { _ = reader.Close() }()
+			if !reader.IsSigned() {
+				t.Error("IsSigned() = false")
+			}
+			if files := reader.GetFilesUnder("lib/"); len(files) != 1 {
+				t.Errorf("lib files = %v, want the built assembly", files)
+			}
+
+			_, err = runSignCommand(pkg, "--certificate-path", signTestPFX, "--certificate-password", "gonuget")
+			if err == nil || !strings.Contains(err.Error(), "already signed") {
+				t.Errorf("Execute() error = %v, want a package already signed error", err)
+			}
+
+			out, err = runSignCommand(pkg, "--certificate-path", signTestPFX, "--certificate-password", "gonuget", "--overwrite")
+			if err != nil {
+				t.Fatalf("Execute(--overwrite) error = %v\n%s", err, out)
+			}
+			if r := packaging.VerifyPackageFile(pkg, verifyOpts); r.Status != packaging.PackageVerificationValid {
+				t.Errorf("re-signed package: Status = %s, errors = %v", r.Status, r.Errors)
+			}
+		})
+	}
+}
@@ -58,20 +58,11 @@ func runAddSource(console *output.Console, opts *sourceOptions) error {
 		return fmt.Errorf("HTTP source '%s' is insecure. Use --allow-insecure-connections to proceed anyway. For secure options, see https://aka.ms/nuget-https-everywhere for more information", opts.source)
 	}
 
-	cfg, configPath, err := loadSourceConfig(opts.configFile)
+	configPath, err := sourceConfigPath(opts.configFile)
 	if err != nil {
 		return err
 	}
 
-	// Check if source already exists
-	if cfg.PackageSources != nil {
-		for _, source := range cfg.PackageSources.Add {
-			if strings.EqualFold(source.Key, opts.name) {
-				return fmt.Errorf("package source with name '%s' already exists", opts.name)
-			}
-		}
-	}
-
 	// Add the source
 	newSource := config.PackageSource{
 		Key:     opts.name,
@@ -85,25 +76,40 @@ func runAddSource(console *output.Console, opts *sourceOptions) error {
 		newSource.ProtocolVersion = opts.protocolVersion
 	}
 
-	cfg.AddPackageSource(newSource)
+	hasCredentials := opts.username != "" || opts.password != ""
+	if hasCredentials && opts.storePasswordInClearText {
+		console.Warning("WARNING: Storing password in clear text is not secure!")
+	}
 
-	// Handle credentials if provided
-	if opts.username != "" || opts.password != "" {
-		if opts.storePasswordInClearText {
-			console.Warning("WARNING: Storing password in clear text is not secure!")
-		}
-		warning, err := addOrUpdateCredential(cfg, opts.name, opts.username, opts.password, opts.storePasswordInClearText, opts.validAuthenticationTypes)
-		if err != nil {
-			return fmt.Errorf("failed to add credentials: %w", err)
+	// The config is re-read under its lock, so sources added concurrently all survive
+	var warning string
+	err = updateConfigFile(configPath, newSourceConfig, func(cfg *config.NuGetConfig) error {
+		// Check if source already exists
+		if cfg.PackageSources != nil {
+			for _, source := range cfg.PackageSources.Add {
+				if strings.EqualFold(source.Key, opts.name) {
+					return fmt.Errorf("package source with name '%s' already exists", opts.name)
+				}
+			}
 		}
-		if warning != "" {
-			console.Warning("WARNING: %s", warning)
+
+		cfg.AddPackageSource(newSource)
+
+		// Handle credentials if provided
+		if hasCredentials {
+			var err error
+			warning, err = addOrUpdateCredential(cfg, opts.name, opts.username, opts.password, opts.storePasswordInClearText, opts.validAuthenticationTypes)
+			if err != nil {
+				return fmt.Errorf("failed to add credentials: %w", err)
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-
-	// Save config
-	if err := config.SaveNuGetConfig(configPath, cfg); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	if warning != "" {
+		console.Warning("WARNING: %s", warning)
 	}
 
 	console.Info("Package source with name '%s' added successfully.", opts.name)
@@ -1,14 +1,12 @@
 package commands
 
 import (
-	"encoding/base64"
 	"encoding/xml"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/willibrandon/gonuget/cmd/gonuget/config"
-	"github.com/zalando/go-keyring"
 )
 
 // sourceOptions holds common options for all source commands
@@ -23,6 +21,8 @@ type sourceOptions struct {
 	protocolVersion          string
 	allowInsecureConnections bool
 	format                   string // detailed or short
+	verbose                  bool
+	removeCredentials        bool
 }
 
 // statusString returns the status as a string matching dotnet nuget output
@@ -33,90 +33,85 @@ func statusString(enabled string) string {
 	return "Disabled"
 }
 
-const (
-	keychainService = "gonuget"
-	keychainPrefix  = "keychain:"
-)
-
-// encodePassword stores password in OS keychain (macOS Keychain, Windows Credential Manager, Linux Secret Service)
-// Returns a marker string that references the keychain entry
-func encodePassword(sourceName, password string) (string, error) {
-	// Try to store in OS keychain
-	err := keyring.Set(keychainService, sourceName, password)
+// loadSourceConfig loads a config file or creates a new one, returns config and path
+func loadSourceConfig(configPath string) (*config.NuGetConfig, string, error) {
+	configPath, err := sourceConfigPath(configPath)
 	if err != nil {
-		// Keychain not available - fall back to base64 encoding with warning
-		// This can happen in headless environments, CI/CD, or if user denies access
-		encoded := base64.StdEncoding.EncodeToString([]byte(password))
-		return encoded, fmt.Errorf("keychain unavailable, using base64 encoding (less secure): %w", err)
+		return nil, "", err
 	}
 
-	// Return marker indicating password is in keychain
-	return keychainPrefix + sourceName, nil
-}
-
-// decodePassword retrieves password from OS keychain or decodes base64-encoded password
-func decodePassword(sourceName, encodedValue string) (string, error) {
-	// Check if this is a keychain reference
-	if keychainKey, found := strings.CutPrefix(encodedValue, keychainPrefix); found {
-		// Validate that the keychain key matches the expected source name
-		if keychainKey != sourceName {
-			return "", fmt.Errorf("keychain key mismatch: expected %s, got %s", sourceName, keychainKey)
-		}
-		password, err := keyring.Get(keychainService, keychainKey)
+	// Try to load existing config
+	if _, err := os.Stat(configPath); err == nil {
+		cfg, err := config.LoadNuGetConfig(configPath)
 		if err != nil {
-			return "", fmt.Errorf("failed to retrieve password from keychain: %w", err)
+			return nil, "", fmt.Errorf("failed to load config: %w", err)
 		}
-		return password, nil
+		return cfg, configPath, nil
 	}
 
-	// Fall back to base64 decoding for legacy/fallback passwords
-	decoded, err := base64.StdEncoding.DecodeString(encodedValue)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode password: %w", err)
-	}
-	return string(decoded), nil
+	return newSourceConfig(), configPath, nil
 }
 
-// deletePasswordFromKeychain removes password from OS keychain
-func deletePasswordFromKeychain(sourceName string) error {
-	err := keyring.Delete(keychainService, sourceName)
-	if err != nil && err != keyring.ErrNotFound {
-		return fmt.Errorf("failed to delete password from keychain: %w", err)
+// sourceConfigPath returns the config file the source commands modify: the --configfile
+// file, which must exist, otherwise the closest config file or the user config.
+func sourceConfigPath(configPath string) (string, error) {
+	if configPath != "" {
+		// If user explicitly specified a config file path and it doesn't exist, return error
+		if _, err := os.Stat(configPath); err != nil {
+			return "", fmt.Errorf("specified config file does not exist: %s", configPath)
+		}
+		return configPath, nil
 	}
-	return nil
+
+	configPath = config.FindConfigFile()
+	if configPath == "" {
+		// Create default config in user location
+		configPath = config.GetUserConfigPath()
+	}
+	return configPath, nil
 }
 
-// loadSourceConfig loads a config file or creates a new one, returns config and path
-func loadSourceConfig(configPath string) (*config.NuGetConfig, string, error) {
-	// Track if config path was explicitly provided
-	explicitPath := configPath != ""
+// newSourceConfig returns the config the source commands create when there is none.
+func newSourceConfig() *config.NuGetConfig {
+	cfg := config.NewDefaultConfig()
+	// Clear default sources for fresh config
+	cfg.PackageSources.Add = nil
+	return cfg
+}
 
-	// Determine config path
-	if configPath == "" {
-		configPath = config.FindConfigFile()
-		if configPath == "" {
-			// Create default config in user location
-			configPath = config.GetUserConfigPath()
-		}
+// updateConfigFile applies mutate to the config file at path, serialized with other
+// gonuget processes (see config.UpdateNuGetConfig). Errors from mutate are returned as
+// is; failures to read or write the file are wrapped.
+func updateConfigFile(path string, initial func() *config.NuGetConfig, mutate func(cfg *config.NuGetConfig) error) error {
+	var mutateErr error
+	err := config.UpdateNuGetConfig(path, initial, func(cfg *config.NuGetConfig) error {
+		mutateErr = mutate(cfg)
+		return mutateErr
+	})
+	if err != nil && err != mutateErr {
+		return fmt.Errorf("failed to save config: %w", err)
 	}
+	return err
+}
 
-	// Try to load existing config
-	if _, err := os.Stat(configPath); err == nil {
-		cfg, err := config.LoadNuGetConfig(configPath)
+// loadSourceLayers returns the config files that enable, disable and list operate on:
+// only the --configfile file when given, otherwise the hierarchy from the current directory.
+func loadSourceLayers(configFile string) ([]config.ConfigLayer, error) {
+	if configFile == "" {
+		workingDir, err := os.Getwd()
 		if err != nil {
-			return nil, "", fmt.Errorf("failed to load config: %w", err)
+			workingDir = "."
+		}
+		if layers := config.LoadConfigLayers(workingDir); len(layers) > 0 {
+			return layers, nil
 		}
-		return cfg, configPath, nil
-	} else if explicitPath {
-		// If user explicitly specified a config file path and it doesn't exist, return error
-		return nil, "", fmt.Errorf("specified config file does not exist: %s", configPath)
 	}
 
-	// Create new config (only when no explicit path was provided)
-	cfg := config.NewDefaultConfig()
-	// Clear default sources for fresh config
-	cfg.PackageSources.Add = nil
-	return cfg, configPath, nil
+	cfg, configPath, err := loadSourceConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	return []config.ConfigLayer{{Path: configPath, Config: cfg}}, nil
 }
 
 // isSourceEnabled checks if a source is enabled
@@ -156,7 +151,7 @@ func addOrUpdateCredential(cfg *config.NuGetConfig, sourceName string, username,
 		if clearText {
 			items = append(items, config.Item{Key: "ClearTextPassword", Value: password})
 		} else {
-			encodedPassword, err := encodePassword(sourceName, password)
+			encodedPassword, err := config.EncodePassword(sourceName, password)
 			if err != nil {
 				// err contains warning about keychain fallback
 				warning = err.Error()
@@ -169,16 +164,9 @@ func addOrUpdateCredential(cfg *config.NuGetConfig, sourceName string, username,
 	}
 
 	// Find or create credential entry
-	found := false
-	for i := range cfg.PackageSourceCredentials.Items {
-		if cfg.PackageSourceCredentials.Items[i].XMLName.Local == sourceName {
-			cfg.PackageSourceCredentials.Items[i].Add = items
-			found = true
-			break
-		}
-	}
-
-	if !found {
+	if credential := cfg.GetSourceCredential(sourceName); credential != nil {
+		credential.Add = items
+	} else {
 		cfg.PackageSourceCredentials.Items = append(cfg.PackageSourceCredentials.Items, config.SourceCredential{
 			XMLName: xml.Name{Local: sourceName},
 			Add:     items,
@@ -187,3 +175,38 @@ func addOrUpdateCredential(cfg *config.NuGetConfig, sourceName string, username,
 
 	return warning, nil
 }
+
+// maskedPassword is shown in place of every stored password
+const maskedPassword = "********"
+
+// describeCredential summarizes a credential entry for display. The password is
+// always masked, whether it is stored in clear text, encoded or in the keychain.
+func describeCredential(credential *config.SourceCredential) string {
+	var username, authTypes string
+	hasPassword := false
+	for _, item := range credential.Add {
+		switch item.Key {
+		case "Username":
+			username = item.Value
+		case "Password", "ClearTextPassword":
+			hasPassword = item.Value != ""
+		case "ValidAuthenticationTypes":
+			authTypes = item.Value
+		}
+	}
+
+	var parts []string
+	if username != "" {
+		parts = append(parts, "username "+username)
+	}
+	if hasPassword {
+		parts = append(parts, "password "+maskedPassword)
+	}
+	if authTypes != "" {
+		parts = append(parts, "authentication types "+authTypes)
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
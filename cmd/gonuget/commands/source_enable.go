@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -35,28 +36,27 @@ Examples:
 }
 
 func runEnableSource(console *output.Console, opts *sourceOptions) error {
-	cfg, configPath, err := loadSourceConfig(opts.configFile)
+	layers, err := loadSourceLayers(opts.configFile)
 	if err != nil {
 		return err
 	}
 
 	// Check if source exists
-	_, err = findSourceByName(cfg, opts.name)
-	if err != nil {
-		return err
+	if source, _ := config.FindPackageSourceInLayers(layers, opts.name); source == nil {
+		return fmt.Errorf("package source with name '%s' not found", opts.name)
 	}
 
-	// Check if already enabled
-	if !cfg.IsSourceDisabled(opts.name) {
+	// Check if already enabled (no disabledPackageSources entry in effect)
+	if _, disabled := config.MergeDisabledSources(layers)[opts.name]; !disabled {
 		console.Info("Package source '%s' is already enabled.", opts.name)
 		return nil
 	}
 
-	// Enable the source by removing from disabledPackageSources
-	cfg.EnableSource(opts.name)
-
-	// Save config
-	if err := config.SaveNuGetConfig(configPath, cfg); err != nil {
+	// Enable the source by removing its disabledPackageSources entries from every file
+	if _, err := config.EnableSourceInLayers(layers, opts.name); err != nil {
+		if errors.Is(err, config.ErrMachineWideSetting) {
+			return err
+		}
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
@@ -1,10 +1,10 @@
 package commands
 
 import (
-	"os"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
 	"github.com/willibrandon/gonuget/cmd/gonuget/output"
 )
 
@@ -25,6 +25,7 @@ Examples:
   gonuget source list
   gonuget source list --format console
   gonuget source list --format json
+  gonuget source list --verbose
   gonuget source list --configfile /path/to/NuGet.config`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -34,6 +35,7 @@ Examples:
 
 	cmd.Flags().StringVar(&opts.configFile, "configfile", "", "The NuGet configuration file. If specified, only the settings from this file will be used. If not specified, the hierarchy of configuration files from the current directory will be used.")
 	cmd.Flags().StringVar(&opts.format, "format", "console", "The format of the list command output: console or json")
+	cmd.Flags().BoolVar(&opts.verbose, "verbose", false, "Show which config file disables each disabled source, and the credentials of each source with the password masked")
 
 	return cmd
 }
@@ -41,51 +43,63 @@ Examples:
 func runListSource(console *output.Console, opts *sourceOptions) error {
 	start := time.Now()
 
-	cfg, configPath, err := loadSourceConfig(opts.configFile)
+	layers, err := loadSourceLayers(opts.configFile)
 	if err != nil {
 		return err
 	}
+	configPath := layers[0].Path
+	sources := config.MergePackageSources(layers)
+	disabled := config.MergeDisabledSources(layers)
 
 	// Handle JSON output format (VR-018: JSON to stdout, errors/warnings to stderr)
 	if opts.format == "json" {
 		jsonOutput := output.NewSourceListOutput(configPath, start)
 
 		// Build sources list
-		if cfg.PackageSources != nil && len(cfg.PackageSources.Add) > 0 {
-			for _, source := range cfg.PackageSources.Add {
-				enabled := !cfg.IsSourceDisabled(source.Key)
-				jsonOutput.Sources = append(jsonOutput.Sources, output.PackageSource{
-					Name:    source.Key,
-					Source:  source.Value,
-					Enabled: enabled,
-				})
-			}
+		for _, source := range sources {
+			_, isDisabled := disabled[source.Key]
+			jsonOutput.Sources = append(jsonOutput.Sources, output.PackageSource{
+				Name:    source.Key,
+				Source:  source.Value,
+				Enabled: !isDisabled,
+			})
 		}
 
 		// Update elapsed time
 		jsonOutput.ElapsedMs = output.MeasureElapsed(start)
 
 		// Write JSON to stdout
-		return output.WriteJSON(os.Stdout, jsonOutput)
+		return output.WriteJSON(console.Output(), jsonOutput)
 	}
 
 	// Console output format
-	if cfg.PackageSources == nil || len(cfg.PackageSources.Add) == 0 {
+	if len(sources) == 0 {
 		console.Info("No package sources configured.")
 		return nil
 	}
 
-	// Match dotnet nuget output format exactly
-	console.Info("Registered Sources:")
+	// Match dotnet nuget output format exactly, written as one block
+	block := console.BeginBlock()
+	defer console.EndBlock(block)
+	block.Info("Registered Sources:")
 
-	for i, source := range cfg.PackageSources.Add {
-		// Check if source is in disabledPackageSources list (matches dotnet behavior)
+	for i, source := range sources {
+		// Check if source is in the effective disabledPackageSources set (matches dotnet behavior)
 		status := "Enabled"
-		if cfg.IsSourceDisabled(source.Key) {
+		entry, isDisabled := disabled[source.Key]
+		if isDisabled {
 			status = "Disabled"
 		}
-		console.Info("  %d.  %s [%s]", i+1, source.Key, status)
-		console.Info("      %s", source.Value)
+		block.Info("  %d.  %s [%s]", i+1, source.Key, status)
+		block.Info("      %s", source.Value)
+		if opts.verbose && isDisabled {
+			block.Info("      Disabled by: %s", entry.ConfigPath)
+		}
+		if opts.verbose {
+			if credential := config.FindSourceCredential(layers, source.Key); credential != nil {
+				block.Info("      Credentials: %s", describeCredential(credential))
+			}
+		}
 	}
 
 	return nil
@@ -36,29 +36,33 @@ Examples:
 }
 
 func runRemoveSource(console *output.Console, opts *sourceOptions) error {
-	cfg, configPath, err := loadSourceConfig(opts.configFile)
+	configPath, err := sourceConfigPath(opts.configFile)
 	if err != nil {
 		return err
 	}
 
-	// Check if source exists
-	if !validateSourceExists(cfg, opts.name) {
-		return fmt.Errorf("package source with name '%s' not found", opts.name)
-	}
+	err = updateConfigFile(configPath, newSourceConfig, func(cfg *config.NuGetConfig) error {
+		// Check if source exists
+		if !validateSourceExists(cfg, opts.name) {
+			return fmt.Errorf("package source with name '%s' not found", opts.name)
+		}
+
+		// Remove the source
+		if !cfg.RemovePackageSource(opts.name) {
+			return fmt.Errorf("failed to remove source: %s", opts.name)
+		}
 
-	// Remove the source
-	if !cfg.RemovePackageSource(opts.name) {
-		return fmt.Errorf("failed to remove source: %s", opts.name)
+		// Remove its credentials too, as dotnet does
+		cfg.RemoveSourceCredential(opts.name)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Remove password from keychain if it exists
+	// Remove the password from the keychain if it exists
 	// Ignore errors - password might not be in keychain (could be cleartext or base64)
-	_ = deletePasswordFromKeychain(opts.name)
-
-	// Save config
-	if err := config.SaveNuGetConfig(configPath, cfg); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
-	}
+	_ = config.DeletePasswordFromKeychain(opts.name)
 
 	console.Info("Package source with name '%s' removed successfully.", opts.name)
 	return nil
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
@@ -552,7 +553,7 @@ func TestEncodeDecodePassword(t *testing.T) {
 	password := "testpassword"
 
 	// Test encoding (will use keychain or fallback to base64)
-	encoded, _ := encodePassword(sourceName, password)
+	encoded, _ := config.EncodePassword(sourceName, password)
 	// Ignore warning if keychain unavailable - tests should work with or without keychain
 	if encoded == "" {
 		t.Error("Expected non-empty encoded password")
@@ -562,7 +563,7 @@ func TestEncodeDecodePassword(t *testing.T) {
 	}
 
 	// Test decoding
-	decoded, err := decodePassword(sourceName, encoded)
+	decoded, err := config.DecodePassword(sourceName, encoded)
 	if err != nil {
 		t.Errorf("Failed to decode password: %v", err)
 	}
@@ -571,8 +572,8 @@ func TestEncodeDecodePassword(t *testing.T) {
 	}
 
 	// Clean up keychain if password was stored there
-	if strings.HasPrefix(encoded, keychainPrefix) {
-		_ = deletePasswordFromKeychain(sourceName)
+	if strings.HasPrefix(encoded, config.KeychainPrefix) {
+		_ = config.DeletePasswordFromKeychain(sourceName)
 	}
 }
 
@@ -1108,6 +1109,78 @@ func TestRemoveSourceEdgeCases(t *testing.T) {
 	})
 }
 
+func TestSourceCredentials_ListAndRemove(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "NuGet.config")
+	if err := createEmptyConfig(configPath); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	var out bytes.Buffer
+	console := output.NewConsole(&out, &out, output.VerbosityNormal)
+
+	const password = "s3cret-Pa55word"
+	for _, name := range []string{"Private", "Other"} {
+		opts := &sourceOptions{
+			configFile:               configPath,
+			name:                     name,
+			source:                   "https://" + strings.ToLower(name) + ".example/v3/index.json",
+			username:                 "alice",
+			password:                 password,
+			storePasswordInClearText: true,
+			validAuthenticationTypes: "basic",
+		}
+		if err := runAddSource(console, opts); err != nil {
+			t.Fatalf("runAddSource(%s) error = %v", name, err)
+		}
+	}
+
+	// The credentials are listed with the password masked
+	out.Reset()
+	if err := runListSource(console, &sourceOptions{configFile: configPath, format: "console", verbose: true}); err != nil {
+		t.Fatalf("runListSource() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Credentials: username alice, password ********, authentication types basic") {
+		t.Errorf("output missing the Private credentials:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), password) || strings.Contains(out.String(), "s3cret") {
+		t.Errorf("output shows the password:\n%s", out.String())
+	}
+
+	// --remove-credentials keeps the source
+	if err := runUpdateSource(console, &sourceOptions{configFile: configPath, name: "Private", removeCredentials: true}); err != nil {
+		t.Fatalf("runUpdateSource() error = %v", err)
+	}
+	cfg, err := config.LoadNuGetConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GetPackageSource("Private") == nil {
+		t.Error("source Private removed, want only its credentials removed")
+	}
+	if cfg.GetSourceCredential("Private") != nil {
+		t.Error("credentials of Private still stored")
+	}
+	if cfg.GetSourceCredential("Other") == nil {
+		t.Error("credentials of Other removed")
+	}
+
+	if err := runUpdateSource(console, &sourceOptions{configFile: configPath, name: "Private", removeCredentials: true, username: "bob"}); err == nil {
+		t.Error("runUpdateSource() error = nil, want --remove-credentials with --username rejected")
+	}
+
+	// Removing the source removes its credentials, and the emptied section
+	if err := runRemoveSource(console, &sourceOptions{configFile: configPath, name: "Other"}); err != nil {
+		t.Fatalf("runRemoveSource() error = %v", err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "packageSourceCredentials") || strings.Contains(string(data), password) {
+		t.Errorf("credential section left behind:\n%s", data)
+	}
+}
+
 // Helper function to create an empty NuGet.config
 func createEmptyConfig(path string) error {
 	content := `<?xml version="1.0" encoding="utf-8"?>
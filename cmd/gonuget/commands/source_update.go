@@ -20,10 +20,15 @@ func NewSourceUpdateCommand(console *output.Console) *cobra.Command {
 
 This command matches: dotnet nuget update source
 
+--remove-credentials deletes the stored credentials of the source (its
+packageSourceCredentials entry and any keychain password) and keeps the
+source itself.
+
 Examples:
   gonuget source update MyFeed --source https://new.url/v3/index.json
   gonuget source update Azure --username newuser --password newpass
-  gonuget source update Private --store-password-in-clear-text`,
+  gonuget source update Private --store-password-in-clear-text
+  gonuget source update Azure --remove-credentials`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			opts.name = args[0]
@@ -38,24 +43,22 @@ Examples:
 	cmd.Flags().StringVar(&opts.validAuthenticationTypes, "valid-authentication-types", "", "Comma-separated list of valid authentication types for this source. Set this to basic if the server advertises NTLM or Negotiate and your credentials must be sent using the Basic mechanism, for instance when using a PAT with on-premises Azure DevOps Server. Other valid values include negotiate, kerberos, ntlm, and digest, but these values are unlikely to be useful.")
 	cmd.Flags().StringVar(&opts.protocolVersion, "protocol-version", "", "The NuGet server protocol version to be used. Currently supported versions are 2 and 3. See https://learn.microsoft.com/nuget/api/overview for information about the version 3 protocol. Defaults to 2 if not specified.")
 	cmd.Flags().BoolVar(&opts.allowInsecureConnections, "allow-insecure-connections", false, "Allows HTTP connections for adding or updating packages. Note: This method is not secure. For secure options, see https://aka.ms/nuget-https-everywhere for more information.")
+	cmd.Flags().BoolVar(&opts.removeCredentials, "remove-credentials", false, "Remove the stored credentials of the source, keeping the source.")
 	cmd.Flags().StringVar(&opts.configFile, "configfile", "", "The NuGet configuration file. If specified, only the settings from this file will be used. If not specified, the hierarchy of configuration files from the current directory will be used.")
 
 	return cmd
 }
 
 func runUpdateSource(console *output.Console, opts *sourceOptions) error {
-	cfg, configPath, err := loadSourceConfig(opts.configFile)
-	if err != nil {
-		return err
+	if opts.removeCredentials && (opts.username != "" || opts.password != "") {
+		return fmt.Errorf("--remove-credentials cannot be combined with --username or --password")
 	}
 
-	// Check if source exists
-	source, err := findSourceByName(cfg, opts.name)
+	configPath, err := sourceConfigPath(opts.configFile)
 	if err != nil {
 		return err
 	}
 
-	// Update source URL if provided
 	if opts.source != "" {
 		// Validate source URL
 		parsedURL, err := url.Parse(opts.source)
@@ -67,36 +70,63 @@ func runUpdateSource(console *output.Console, opts *sourceOptions) error {
 		if parsedURL.Scheme == "http" && !opts.allowInsecureConnections {
 			return fmt.Errorf("HTTP source '%s' is insecure. Use --allow-insecure-connections to proceed anyway. For secure options, see https://aka.ms/nuget-https-everywhere for more information", opts.source)
 		}
+	}
 
-		source.Value = opts.source
-		// Only set protocol version if it's not the default (2)
-		// This matches dotnet nuget behavior which doesn't write protocolVersion="2"
-		if opts.protocolVersion != "" && opts.protocolVersion != "2" {
-			source.ProtocolVersion = opts.protocolVersion
-		} else if opts.protocolVersion == "2" {
-			// Clear protocol version if explicitly set to 2 (default)
-			source.ProtocolVersion = ""
-		}
-		cfg.AddPackageSource(*source)
+	hasCredentials := opts.username != "" || opts.password != ""
+	if hasCredentials && opts.storePasswordInClearText {
+		console.Warning("WARNING: Storing password in clear text is not secure!")
 	}
 
-	// Update credentials if provided
-	if opts.username != "" || opts.password != "" {
-		if opts.storePasswordInClearText {
-			console.Warning("WARNING: Storing password in clear text is not secure!")
-		}
-		warning, err := addOrUpdateCredential(cfg, opts.name, opts.username, opts.password, opts.storePasswordInClearText, opts.validAuthenticationTypes)
+	var warning string
+	var credentialsRemoved bool
+	err = updateConfigFile(configPath, newSourceConfig, func(cfg *config.NuGetConfig) error {
+		// Check if source exists
+		source, err := findSourceByName(cfg, opts.name)
 		if err != nil {
-			return fmt.Errorf("failed to update credentials: %w", err)
+			return err
 		}
-		if warning != "" {
-			console.Warning("WARNING: %s", warning)
+
+		// Update source URL if provided
+		if opts.source != "" {
+			source.Value = opts.source
+			// Only set protocol version if it's not the default (2)
+			// This matches dotnet nuget behavior which doesn't write protocolVersion="2"
+			if opts.protocolVersion != "" && opts.protocolVersion != "2" {
+				source.ProtocolVersion = opts.protocolVersion
+			} else if opts.protocolVersion == "2" {
+				// Clear protocol version if explicitly set to 2 (default)
+				source.ProtocolVersion = ""
+			}
+			cfg.AddPackageSource(*source)
 		}
-	}
 
-	// Save config
-	if err := config.SaveNuGetConfig(configPath, cfg); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+		// Update credentials if provided
+		if hasCredentials {
+			warning, err = addOrUpdateCredential(cfg, opts.name, opts.username, opts.password, opts.storePasswordInClearText, opts.validAuthenticationTypes)
+			if err != nil {
+				return fmt.Errorf("failed to update credentials: %w", err)
+			}
+		}
+
+		// Remove credentials, leaving the source in place
+		if opts.removeCredentials {
+			credentialsRemoved = cfg.RemoveSourceCredential(opts.name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if warning != "" {
+		console.Warning("WARNING: %s", warning)
+	}
+	if opts.removeCredentials {
+		if credentialsRemoved {
+			// Ignore errors - password might not be in keychain (could be cleartext or base64)
+			_ = config.DeletePasswordFromKeychain(opts.name)
+		} else {
+			console.Warning("Package source with name '%s' has no stored credentials.", opts.name)
+		}
 	}
 
 	console.Info("Package source with name '%s' updated successfully.", opts.name)
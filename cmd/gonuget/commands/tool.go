@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+)
+
+// toolCmd is the parent tool command instance
+var toolCmd *cobra.Command
+
+// NewToolCommand creates the parent "tool" command with subcommands
+func NewToolCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tool",
+		Short: "Manage .NET tool packages",
+		Long: `Manage .NET tool packages.
+
+Tool packages are NuGet packages with the DotnetTool package type. They are
+extracted into a tool store (<tool-path>/.store) using the same layout as the
+dotnet CLI, and the commands they provide are read from DotnetToolSettings.xml.`,
+		Example: `  # Install a tool into a custom location
+  gonuget tool install dotnetsay --tool-path ./tools
+
+  # Install a specific version into the user-wide tool location
+  gonuget tool install dotnet-ef --version 8.0.0 --global`,
+		// Parent commands have no Run function - they are containers only
+	}
+
+	// Store reference for subcommand registration
+	toolCmd = cmd
+
+	return cmd
+}
+
+// GetToolCommand returns the tool command for registration with root
+func GetToolCommand() *cobra.Command {
+	if toolCmd == nil {
+		toolCmd = NewToolCommand()
+	}
+	return toolCmd
+}
+
+// RegisterToolSubcommands registers all tool subcommands with the tool parent
+func RegisterToolSubcommands(console *output.Console) {
+	toolCmd := GetToolCommand()
+	toolCmd.AddCommand(NewToolInstallCommand(console))
+}
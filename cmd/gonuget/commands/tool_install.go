@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+	"github.com/willibrandon/gonuget/cmd/gonuget/output"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/restore"
+)
+
+// NewToolInstallCommand creates the "tool install" subcommand
+func NewToolInstallCommand(console *output.Console) *cobra.Command {
+	opts := &restore.ToolInstallOptions{}
+	var verbosity string
+
+	cmd := &cobra.Command{
+		Use:   "install <PACKAGE_ID>",
+		Short: "Install a .NET tool package",
+		Long: `Install a .NET tool package into a tool store.
+
+The package must have the DotnetTool package type. It is extracted into
+<tool-path>/.store/<id>/<version>/ and the commands declared in its
+tools/<tfm>/<rid>/DotnetToolSettings.xml are reported. Executable shims
+are not created.
+
+Examples:
+  gonuget tool install dotnetsay --tool-path ./tools
+  gonuget tool install dotnet-ef --version 8.0.0 --global
+  gonuget tool install dotnet-ef --version "[8.0.0,9.0.0)" --tool-path ./tools`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var err error
+			if opts.Verbosity, err = observability.ParseVerbosity(verbosity); err != nil {
+				return err
+			}
+			opts.PackageID = args[0]
+			return runToolInstall(cmd, console, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Version, "version", "", "The version or version range of the tool package to install")
+	cmd.Flags().StringVar(&opts.ToolPath, "tool-path", "", "The directory where the tool will be installed")
+	cmd.Flags().BoolVarP(&opts.Global, "global", "g", false, "Install the tool for the current user (~/.dotnet/tools)")
+	cmd.Flags().StringSliceVarP(&opts.Sources, "source", "s", nil, "Package source(s) to use")
+	cmd.Flags().BoolVar(&opts.Prerelease, "prerelease", false, "Allow prerelease versions to be selected")
+	cmd.Flags().StringVar(&verbosity, "verbosity", "minimal", "Verbosity level: q[uiet], m[inimal], n[ormal], d[etailed], or diag[nostic]")
+
+	cmd.MarkFlagsMutuallyExclusive("tool-path", "global")
+	cmd.MarkFlagsOneRequired("tool-path", "global")
+
+	return cmd
+}
+
+func runToolInstall(cmd *cobra.Command, console *output.Console, opts *restore.ToolInstallOptions) error {
+	// Load sources from NuGet.config if not provided via --source flag
+	if len(opts.Sources) == 0 {
+		searchDir, err := os.Getwd()
+		if err != nil {
+			searchDir = "."
+		}
+		for _, source := range config.GetEnabledSourcesOrDefault(searchDir) {
+			opts.Sources = append(opts.Sources, source.Value)
+		}
+	}
+
+	result, err := restore.InstallTool(cmd.Context(), opts, console)
+	if err != nil {
+		return err
+	}
+
+	if result.AlreadyInstalled {
+		console.Printf("Tool '%s' (version '%s') is already installed in '%s'.\n", result.PackageID, result.Version, result.ToolPath)
+	} else {
+		console.Printf("Tool '%s' (version '%s') was successfully installed to '%s'.\n", result.PackageID, result.Version, result.ToolPath)
+	}
+
+	if opts.Verbosity >= observability.VerbosityDetailed {
+		console.Printf("  Package directory: %s\n", result.InstallPath)
+		console.Printf("  Settings: %s (%s/%s)\n", result.SettingsPath, result.TargetFramework, result.RuntimeIdentifier)
+	}
+
+	console.Printf("Available command(s):\n")
+	for _, command := range result.Commands {
+		console.Printf("  %s\n", formatToolCommand(command.Name, command.EntryPoint, command.Runner))
+	}
+
+	return nil
+}
+
+// formatToolCommand renders a tool command for display.
+func formatToolCommand(name, entryPoint, runner string) string {
+	if runner == "" {
+		return fmt.Sprintf("%s (%s)", name, entryPoint)
+	}
+	return fmt.Sprintf("%s (%s, runner: %s)", name, entryPoint, runner)
+}
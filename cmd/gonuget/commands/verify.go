@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// NewVerifyCommand creates the top-level verify command. It matches dotnet nuget verify
+// and shares its flags and behavior with 'package verify'.
+func NewVerifyCommand() *cobra.Command {
+	opts := &PackageVerifyOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "verify <PATH>",
+		Short: "Verify the signatures of packages",
+		Long:  packageVerifyLong("gonuget verify"),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPackageVerify(args[0], opts, cmd.OutOrStdout())
+		},
+	}
+	addPackageVerifyFlags(cmd, opts)
+
+	return cmd
+}
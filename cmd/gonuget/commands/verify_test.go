@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/packaging"
+)
+
+func runVerifyCommand(args ...string) (string, error) {
+	var out bytes.Buffer
+	cmd := NewVerifyCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestVerify_SignedPackage(t *testing.T) {
+	dir := writeVerifyTestPackages(t)
+	pkg := filepath.Join(dir, "nested", "TestPackage.AuthorSigned.1.0.0.nupkg")
+
+	reader, err := packaging.OpenPackage(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := reader.GetPrimarySignature()
+	_ = reader.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := sha256.Sum256(sig.SignerCertificate.Raw)
+
+	out, err := runVerifyCommand(pkg, "--all", "--allow-untrusted-root", "--certificate-fingerprint", hex.EncodeToString(fingerprint[:]))
+	if err != nil {
+		t.Fatalf("Execute() error = %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"Signature type: Author",
+		"Subject Name: " + sig.SignerCertificate.Subject.String(),
+		"Issued by: " + sig.SignerCertificate.Issuer.String(),
+		"Certificate chain:",
+		"Timestamp authority: " + sig.Timestamps[0].SignerCertificate.Subject.String(),
+		"Passed: 1, Failed: 0, Unsigned: 0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// Any error fails the command
+	other := sha256.Sum256([]byte("another certificate"))
+	out, err = runVerifyCommand(pkg, "--all", "--allow-untrusted-root", "--certificate-fingerprint", hex.EncodeToString(other[:]))
+	if err == nil || !strings.Contains(out, "does not match any allowed certificate fingerprint") {
+		t.Errorf("Execute() error = %v, want a fingerprint mismatch:\n%s", err, out)
+	}
+}
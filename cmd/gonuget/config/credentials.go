@@ -0,0 +1,214 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/willibrandon/gonuget/auth"
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	keychainService = "gonuget"
+	// KeychainPrefix marks a Password value that references an OS keychain entry
+	KeychainPrefix = "keychain:"
+	// CredentialsEnvPrefix starts the name of the environment variable that supplies the
+	// credentials of a source, e.g. NuGetPackageSourceCredentials_private
+	CredentialsEnvPrefix = "NuGetPackageSourceCredentials_"
+)
+
+// environmentCredentialPattern matches "Username=...;Password=...[;ValidAuthenticationTypes=...]"
+// Reference: PackageSourceProvider.cs ReadCredentialFromEnvironment
+var environmentCredentialPattern = regexp.MustCompile(`(?i)^Username=(.*?);\s*Password=(.*?)(?:;ValidAuthenticationTypes=(.*?))?$`)
+
+// CredentialProvider supplies credentials from the NuGetPackageSourceCredentials_<name>
+// environment variables and packageSourceCredentials in a NuGet.config hierarchy, like
+// dotnet. Personal access tokens, such as those of Azure DevOps and GitHub Packages, are
+// given as the password. It is consulted by the core client when a source answers 401, and the client
+// caches its answer per source for the rest of the command.
+type CredentialProvider struct {
+	layers []ConfigLayer
+}
+
+// NewCredentialProvider creates a credential provider for layers ordered closest first.
+func NewCredentialProvider(layers []ConfigLayer) *CredentialProvider {
+	return &CredentialProvider{layers: layers}
+}
+
+// GetCredentials returns the credentials of the source configured with sourceURL.
+// The source's environment variable wins over the config files, and the closest config
+// file that has credentials for the source wins over farther ones.
+func (p *CredentialProvider) GetCredentials(_ context.Context, sourceURL string) (auth.Authenticator, error) {
+	sourceName := p.sourceName(sourceURL)
+	if sourceName == "" {
+		return nil, nil
+	}
+
+	if items, ok := environmentCredential(sourceName); ok {
+		return credentialAuthenticator(sourceName, items)
+	}
+
+	if credential := FindSourceCredential(p.layers, sourceName); credential != nil {
+		return credentialAuthenticator(sourceName, credential.Add)
+	}
+
+	return nil, nil
+}
+
+// sourceName finds the configured name of a source URL.
+func (p *CredentialProvider) sourceName(sourceURL string) string {
+	for _, source := range MergePackageSources(p.layers) {
+		if nugethttp.SameSourceURL(source.Value, sourceURL) {
+			return source.Key
+		}
+	}
+	return ""
+}
+
+// FindSourceCredential returns the credentials of a source from the closest config file
+// that has them.
+func FindSourceCredential(layers []ConfigLayer, sourceName string) *SourceCredential {
+	for _, layer := range layers {
+		if credential := layer.Config.GetSourceCredential(sourceName); credential != nil {
+			return credential
+		}
+	}
+	return nil
+}
+
+// HasSourceCredentials reports whether any layer has a packageSourceCredentials entry or
+// a NuGetPackageSourceCredentials_<name> environment variable is set.
+func HasSourceCredentials(layers []ConfigLayer) bool {
+	for _, layer := range layers {
+		if section := layer.Config.PackageSourceCredentials; section != nil && len(section.Items) > 0 {
+			return true
+		}
+	}
+	for _, env := range os.Environ() {
+		if len(env) > len(CredentialsEnvPrefix) && strings.EqualFold(env[:len(CredentialsEnvPrefix)], CredentialsEnvPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// environmentCredential reads the credentials of a source from its environment variable,
+// in the format "Username=user;Password=token[;ValidAuthenticationTypes=types]". The
+// password is in clear text.
+func environmentCredential(sourceName string) ([]Item, bool) {
+	value, ok := os.LookupEnv(CredentialsEnvPrefix + sourceName)
+	if !ok {
+		return nil, false
+	}
+	match := environmentCredentialPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return nil, false
+	}
+	items := []Item{
+		{Key: "Username", Value: match[1]},
+		{Key: "ClearTextPassword", Value: match[2]},
+	}
+	if match[3] != "" {
+		items = append(items, Item{Key: "ValidAuthenticationTypes", Value: match[3]})
+	}
+	return items, true
+}
+
+// credentialAuthenticator builds a basic authenticator from a credential entry. An entry
+// whose ValidAuthenticationTypes allow Windows integrated authentication (negotiate,
+// kerberos or ntlm) but not basic gets a negotiate authenticator instead; it uses the
+// identity of the process, so the entry's username and password are not sent.
+func credentialAuthenticator(sourceName string, items []Item) (auth.Authenticator, error) {
+	var username, password string
+	for _, item := range items {
+		switch item.Key {
+		case "ValidAuthenticationTypes":
+			if allowsOnlyIntegratedAuthentication(item.Value) {
+				return auth.NewNegotiateAuthenticator(), nil
+			}
+		case "Username":
+			username = item.Value
+		case "ClearTextPassword":
+			password = item.Value
+		case "Password":
+			decoded, err := DecodePassword(sourceName, item.Value)
+			if err != nil {
+				return nil, err
+			}
+			password = decoded
+		}
+	}
+
+	if username == "" && password == "" {
+		return nil, nil
+	}
+	return auth.NewBasicAuthenticator(username, password), nil
+}
+
+// allowsOnlyIntegratedAuthentication reports whether a comma-separated
+// ValidAuthenticationTypes value allows Windows integrated authentication but not basic.
+func allowsOnlyIntegratedAuthentication(authTypes string) bool {
+	integrated := false
+	for authType := range strings.SplitSeq(authTypes, ",") {
+		switch strings.ToLower(strings.TrimSpace(authType)) {
+		case "basic":
+			return false
+		case "negotiate", "kerberos", "ntlm":
+			integrated = true
+		}
+	}
+	return integrated
+}
+
+// EncodePassword stores password in OS keychain (macOS Keychain, Windows Credential Manager, Linux Secret Service)
+// Returns a marker string that references the keychain entry
+func EncodePassword(sourceName, password string) (string, error) {
+	// Try to store in OS keychain
+	err := keyring.Set(keychainService, sourceName, password)
+	if err != nil {
+		// Keychain not available - fall back to base64 encoding with warning
+		// This can happen in headless environments, CI/CD, or if user denies access
+		encoded := base64.StdEncoding.EncodeToString([]byte(password))
+		return encoded, fmt.Errorf("keychain unavailable, using base64 encoding (less secure): %w", err)
+	}
+
+	// Return marker indicating password is in keychain
+	return KeychainPrefix + sourceName, nil
+}
+
+// DecodePassword retrieves password from OS keychain or decodes base64-encoded password
+func DecodePassword(sourceName, encodedValue string) (string, error) {
+	// Check if this is a keychain reference
+	if keychainKey, found := strings.CutPrefix(encodedValue, KeychainPrefix); found {
+		// Validate that the keychain key matches the expected source name
+		if keychainKey != sourceName {
+			return "", fmt.Errorf("keychain key mismatch: expected %s, got %s", sourceName, keychainKey)
+		}
+		password, err := keyring.Get(keychainService, keychainKey)
+		if err != nil {
+			return "", fmt.Errorf("failed to retrieve password from keychain: %w", err)
+		}
+		return password, nil
+	}
+
+	// Fall back to base64 decoding for legacy/fallback passwords
+	decoded, err := base64.StdEncoding.DecodeString(encodedValue)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode password: %w", err)
+	}
+	return string(decoded), nil
+}
+
+// DeletePasswordFromKeychain removes password from OS keychain
+func DeletePasswordFromKeychain(sourceName string) error {
+	err := keyring.Delete(keychainService, sourceName)
+	if err != nil && err != keyring.ErrNotFound {
+		return fmt.Errorf("failed to delete password from keychain: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/auth"
+)
+
+func TestCredentialProvider_MatchesSourceURL(t *testing.T) {
+	cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration>
+  <packageSources>
+    <add key="nexus" value="http://[::1]:8081/repository/nuget/index.json" />
+    <add key="proget" value="https://feed.example/nuget/" />
+  </packageSources>
+  <packageSourceCredentials>
+    <nexus>
+      <add key="Username" value="ci" />
+      <add key="ClearTextPassword" value="secret" />
+    </nexus>
+    <proget>
+      <add key="Username" value="build" />
+      <add key="ClearTextPassword" value="secret" />
+    </proget>
+  </packageSourceCredentials>
+</configuration>`))
+	if err != nil {
+		t.Fatalf("ParseNuGetConfig() error = %v", err)
+	}
+	provider := NewCredentialProvider([]ConfigLayer{{Config: cfg}})
+
+	tests := []struct {
+		sourceURL string
+		username  string
+	}{
+		{"http://[::1]:8081/repository/nuget/index.json", "ci"},
+		{"http://[0:0:0:0:0:0:0:1]:8081/repository/nuget/index.json", "ci"},
+		{"http://[::1]:8082/repository/nuget/index.json", ""},
+		{"https://feed.example/nuget", "build"},
+		{"https://feed.example:443/nuget/", "build"},
+		{"https://feed.example.evil.example/nuget", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sourceURL, func(t *testing.T) {
+			authenticator, err := provider.GetCredentials(context.Background(), tt.sourceURL)
+			if err != nil {
+				t.Fatalf("GetCredentials() error = %v", err)
+			}
+
+			var username string
+			if authenticator != nil {
+				req, _ := http.NewRequest(http.MethodGet, tt.sourceURL, nil)
+				if err := authenticator.Authenticate(req); err != nil {
+					t.Fatalf("Authenticate() error = %v", err)
+				}
+				username, _, _ = req.BasicAuth()
+			}
+			if username != tt.username {
+				t.Errorf("username = %q, want %q", username, tt.username)
+			}
+		})
+	}
+}
+
+func TestCredentialProvider_Environment(t *testing.T) {
+	cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration>
+  <packageSources>
+    <add key="azure" value="https://pkgs.dev.azure.com/org/_packaging/feed/nuget/v3/index.json" />
+    <add key="github" value="https://nuget.pkg.github.com/owner/index.json" />
+  </packageSources>
+  <packageSourceCredentials>
+    <github>
+      <add key="Username" value="config-user" />
+      <add key="ClearTextPassword" value="config-token" />
+    </github>
+  </packageSourceCredentials>
+</configuration>`))
+	if err != nil {
+		t.Fatalf("ParseNuGetConfig() error = %v", err)
+	}
+	layers := []ConfigLayer{{Config: cfg}}
+	provider := NewCredentialProvider(layers)
+
+	basicAuth := func(sourceURL string) (string, string) {
+		t.Helper()
+		authenticator, err := provider.GetCredentials(context.Background(), sourceURL)
+		if err != nil {
+			t.Fatalf("GetCredentials() error = %v", err)
+		}
+		if authenticator == nil {
+			return "", ""
+		}
+		req, _ := http.NewRequest(http.MethodGet, sourceURL, nil)
+		if err := authenticator.Authenticate(req); err != nil {
+			t.Fatalf("Authenticate() error = %v", err)
+		}
+		username, password, _ := req.BasicAuth()
+		return username, password
+	}
+
+	azureURL := "https://pkgs.dev.azure.com/org/_packaging/feed/nuget/v3/index.json"
+	githubURL := "https://nuget.pkg.github.com/owner/index.json"
+
+	if username, _ := basicAuth(azureURL); username != "" {
+		t.Errorf("azure username = %q before the environment variable is set", username)
+	}
+
+	t.Setenv("NuGetPackageSourceCredentials_azure", "Username=az;Password=pat=with=equals;ValidAuthenticationTypes=basic")
+	t.Setenv("NuGetPackageSourceCredentials_github", "username=owner; password=ghp_token")
+
+	if username, password := basicAuth(azureURL); username != "az" || password != "pat=with=equals" {
+		t.Errorf("azure credentials = %q:%q, want az:pat=with=equals", username, password)
+	}
+	// The environment wins over the config
+	if username, password := basicAuth(githubURL); username != "owner" || password != "ghp_token" {
+		t.Errorf("github credentials = %q:%q, want owner:ghp_token", username, password)
+	}
+
+	// A malformed value is ignored
+	t.Setenv("NuGetPackageSourceCredentials_github", "ghp_token")
+	if username, _ := basicAuth(githubURL); username != "config-user" {
+		t.Errorf("github username = %q, want the config's config-user", username)
+	}
+}
+
+func TestCredentialProvider_ValidAuthenticationTypes(t *testing.T) {
+	cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration>
+  <packageSources>
+    <add key="tfs" value="https://tfs.example/tfs/DefaultCollection/_packaging/feed/nuget/v3/index.json" />
+    <add key="tfs-pat" value="https://tfs.example/tfs/DefaultCollection/_packaging/pat/nuget/v3/index.json" />
+  </packageSources>
+  <packageSourceCredentials>
+    <tfs>
+      <add key="Username" value="DOMAIN\\build" />
+      <add key="ClearTextPassword" value="unused" />
+      <add key="ValidAuthenticationTypes" value="Negotiate, NTLM" />
+    </tfs>
+    <tfs-pat>
+      <add key="Username" value="build" />
+      <add key="ClearTextPassword" value="pat" />
+      <add key="ValidAuthenticationTypes" value="basic,negotiate" />
+    </tfs-pat>
+  </packageSourceCredentials>
+</configuration>`))
+	if err != nil {
+		t.Fatalf("ParseNuGetConfig() error = %v", err)
+	}
+	provider := NewCredentialProvider([]ConfigLayer{{Config: cfg}})
+
+	authenticator, err := provider.GetCredentials(context.Background(), "https://tfs.example/tfs/DefaultCollection/_packaging/feed/nuget/v3/index.json")
+	if _, ok := authenticator.(*auth.NegotiateAuthenticator); err != nil || !ok {
+		t.Errorf("GetCredentials(tfs) = %T, %v, want a negotiate authenticator", authenticator, err)
+	}
+	authenticator, err = provider.GetCredentials(context.Background(), "https://tfs.example/tfs/DefaultCollection/_packaging/pat/nuget/v3/index.json")
+	if _, ok := authenticator.(*auth.BasicAuthenticator); err != nil || !ok {
+		t.Errorf("GetCredentials(tfs-pat) = %T, %v, want basic credentials when basic is allowed", authenticator, err)
+	}
+
+	t.Setenv("NuGetPackageSourceCredentials_tfs-pat", "Username=build;Password=pat;ValidAuthenticationTypes=ntlm")
+	authenticator, err = provider.GetCredentials(context.Background(), "https://tfs.example/tfs/DefaultCollection/_packaging/pat/nuget/v3/index.json")
+	if _, ok := authenticator.(*auth.NegotiateAuthenticator); err != nil || !ok {
+		t.Errorf("GetCredentials(tfs-pat) = %T, %v, want a negotiate authenticator from the environment", authenticator, err)
+	}
+}
+
+func TestHasSourceCredentials_Environment(t *testing.T) {
+	cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration />`))
+	if err != nil {
+		t.Fatalf("ParseNuGetConfig() error = %v", err)
+	}
+	layers := []ConfigLayer{{Config: cfg}}
+
+	if HasSourceCredentials(layers) {
+		t.Skip("a NuGetPackageSourceCredentials_ variable is set in the test environment")
+	}
+	t.Setenv("NuGetPackageSourceCredentials_private", "Username=u;Password=p")
+	if !HasSourceCredentials(layers) {
+		t.Error("HasSourceCredentials() = false with an environment variable set")
+	}
+}
@@ -67,6 +67,15 @@ func GetUserConfigPath() string {
 	return filepath.Join(home, ".nuget", "NuGet", "NuGet.Config")
 }
 
+// DefaultUserConfig is the user-level NuGet.Config created on first run.
+// Matches the file NuGet.Client's Settings writes when no user config exists.
+const DefaultUserConfig = `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <add key="nuget.org" value="https://api.nuget.org/v3/index.json" protocolVersion="3" />
+  </packageSources>
+</configuration>`
+
 // DefaultPackageSources returns the default package sources
 func DefaultPackageSources() []PackageSource {
 	return []PackageSource{
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrMachineWideSetting is returned when a change would require editing a machine-wide config.
+// Matches NuGet.Client's "Unable to update setting since it is in a machine-wide NuGet.Config."
+var ErrMachineWideSetting = errors.New("unable to update setting since it is in a machine-wide NuGet.Config")
+
+// ConfigLayer is a loaded config file from the hierarchy.
+type ConfigLayer struct {
+	Path        string
+	Config      *NuGetConfig
+	MachineWide bool // Machine-wide configs are read-only for source commands
+}
+
+// DisabledSourceEntry is an effective disabledPackageSources entry and the file it came from.
+type DisabledSourceEntry struct {
+	Key         string
+	Value       string
+	ConfigPath  string
+	MachineWide bool
+}
+
+// LoadConfigLayers loads every readable config file in the hierarchy for workingDirectory,
+// closest first (same order as GetConfigHierarchy). Missing or invalid files are skipped.
+func LoadConfigLayers(workingDirectory string) []ConfigLayer {
+	machineWide := make(map[string]bool)
+	for _, path := range getMachineWideConfigPaths() {
+		machineWide[path] = true
+	}
+
+	var layers []ConfigLayer
+	for _, path := range GetConfigHierarchy(workingDirectory) {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		cfg, err := LoadNuGetConfig(path)
+		if err != nil {
+			continue
+		}
+		layers = append(layers, ConfigLayer{Path: path, Config: cfg, MachineWide: machineWide[path]})
+	}
+
+	return layers
+}
+
+// MergeDisabledSources computes the effective disabled set for layers ordered closest first.
+// Layers are applied from the farthest to the closest: a <clear/> in disabledPackageSources
+// drops every entry from farther files, and a closer entry for the same key wins.
+// Any entry disables its source regardless of its value, and keys are case-sensitive (matches dotnet).
+func MergeDisabledSources(layers []ConfigLayer) map[string]DisabledSourceEntry {
+	disabled := make(map[string]DisabledSourceEntry)
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		section := layers[i].Config.DisabledPackageSources
+		if section == nil {
+			continue
+		}
+
+		if section.Clear != nil {
+			disabled = make(map[string]DisabledSourceEntry)
+		}
+
+		for _, entry := range section.Add {
+			disabled[entry.Key] = DisabledSourceEntry{
+				Key:         entry.Key,
+				Value:       entry.Value,
+				ConfigPath:  layers[i].Path,
+				MachineWide: layers[i].MachineWide,
+			}
+		}
+	}
+
+	return disabled
+}
+
+// MergePackageSources returns the effective package sources for layers ordered closest first.
+// The closest definition of a key wins and sources keep closest-first order; farther files are
+// ignored once a layer with <clear/> in packageSources has been applied (matches dotnet nuget list source).
+func MergePackageSources(layers []ConfigLayer) []PackageSource {
+	var merged []PackageSource
+	seen := make(map[string]bool)
+
+	for _, layer := range layers {
+		section := layer.Config.PackageSources
+		if section == nil {
+			continue
+		}
+
+		for _, source := range section.Add {
+			if seen[source.Key] {
+				continue
+			}
+			seen[source.Key] = true
+			merged = append(merged, source)
+		}
+
+		if section.Clear != nil {
+			break
+		}
+	}
+
+	return merged
+}
+
+// MergeEnabledPackageSources returns the effective package sources of layers ordered closest
+// first (see MergePackageSources), leaving out sources with enabled="false" and those
+// disabled by disabledPackageSources anywhere in the hierarchy.
+func MergeEnabledPackageSources(layers []ConfigLayer) []PackageSource {
+	disabled := MergeDisabledSources(layers)
+
+	var enabled []PackageSource
+	for _, source := range MergePackageSources(layers) {
+		if _, ok := disabled[source.Key]; ok || source.Enabled == "false" {
+			continue
+		}
+		enabled = append(enabled, source)
+	}
+
+	return enabled
+}
+
+// FindPackageSourceInLayers returns the closest definition of a package source and its layer.
+// Returns nil if no layer defines the source.
+func FindPackageSourceInLayers(layers []ConfigLayer, key string) (*PackageSource, *ConfigLayer) {
+	for i := range layers {
+		if source := layers[i].Config.GetPackageSource(key); source != nil {
+			return source, &layers[i]
+		}
+	}
+	return nil, nil
+}
+
+// DisableSourceInLayers disables a source the way dotnet nuget disable source does: an existing
+// disabledPackageSources entry for the key is updated in the closest file that has one, otherwise
+// the entry is added to the file that defines the source. Returns the paths of modified files.
+func DisableSourceInLayers(layers []ConfigLayer, key string) ([]string, error) {
+	target := -1
+	for i := range layers {
+		if layers[i].Config.hasDisabledEntry(key) {
+			target = i
+			break
+		}
+	}
+
+	if target < 0 {
+		for i := range layers {
+			if layers[i].Config.GetPackageSource(key) != nil {
+				target = i
+				break
+			}
+		}
+	}
+	if target < 0 {
+		return nil, fmt.Errorf("unable to find any package source(s) matching name: %s", key)
+	}
+
+	if layers[target].MachineWide {
+		return nil, ErrMachineWideSetting
+	}
+
+	if err := UpdateNuGetConfig(layers[target].Path, nil, func(cfg *NuGetConfig) error {
+		cfg.DisableSource(key)
+		layers[target].Config = cfg
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return []string{layers[target].Path}, nil
+}
+
+// EnableSourceInLayers enables a source the way dotnet nuget enable source does: the
+// disabledPackageSources entry for the key is removed from every file in the hierarchy.
+// Fails without modifying anything if an entry lives in a machine-wide config.
+// Returns the paths of modified files.
+func EnableSourceInLayers(layers []ConfigLayer, key string) ([]string, error) {
+	var targets []int
+	for i := range layers {
+		if !layers[i].Config.hasDisabledEntry(key) {
+			continue
+		}
+		if layers[i].MachineWide {
+			return nil, ErrMachineWideSetting
+		}
+		targets = append(targets, i)
+	}
+
+	var modified []string
+	for _, i := range targets {
+		if err := UpdateNuGetConfig(layers[i].Path, nil, func(cfg *NuGetConfig) error {
+			cfg.EnableSource(key)
+			layers[i].Config = cfg
+			return nil
+		}); err != nil {
+			return modified, err
+		}
+		modified = append(modified, layers[i].Path)
+	}
+
+	return modified, nil
+}
+
+// hasDisabledEntry reports whether this file has a disabledPackageSources entry for key.
+func (c *NuGetConfig) hasDisabledEntry(key string) bool {
+	if c.DisabledPackageSources == nil {
+		return false
+	}
+	for _, entry := range c.DisabledPackageSources.Add {
+		if entry.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,284 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// writeLayer writes a config file and returns it as a loaded layer.
+func writeLayer(t *testing.T, dir, content string, machineWide bool) ConfigLayer {
+	t.Helper()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	path := filepath.Join(dir, "NuGet.Config")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	cfg, err := LoadNuGetConfig(path)
+	if err != nil {
+		t.Fatalf("LoadNuGetConfig() error = %v", err)
+	}
+	return ConfigLayer{Path: path, Config: cfg, MachineWide: machineWide}
+}
+
+func disabledKeys(disabled map[string]DisabledSourceEntry) []string {
+	keys := make([]string, 0, len(disabled))
+	for key := range disabled {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const parentSourcesConfig = `<configuration>
+  <packageSources>
+    <clear />
+    <add key="A" value="https://a.example/v3/index.json" />
+    <add key="B" value="https://b.example/v3/index.json" />
+    <add key="C" value="https://c.example/v3/index.json" />
+  </packageSources>
+  <disabledPackageSources>
+    <add key="A" value="true" />
+    <add key="B" value="" />
+    <add key="C" value="false" />
+  </disabledPackageSources>
+</configuration>`
+
+func TestMergeDisabledSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		closer  string // config closer to the working directory than the parent
+		machine string // machine-wide config (farthest)
+		want    []string
+	}{
+		{
+			name: "any entry disables regardless of value",
+			want: []string{"A", "B", "C"},
+		},
+		{
+			name:   "clear in closer file re-enables farther entries",
+			closer: `<configuration><disabledPackageSources><clear /></disabledPackageSources></configuration>`,
+			want:   []string{},
+		},
+		{
+			name:   "re-disable after clear in closer file",
+			closer: `<configuration><disabledPackageSources><clear /><add key="C" value="true" /></disabledPackageSources></configuration>`,
+			want:   []string{"C"},
+		},
+		{
+			name:    "machine-wide disables apply",
+			machine: `<configuration><disabledPackageSources><add key="D" value="true" /></disabledPackageSources></configuration>`,
+			want:    []string{"A", "B", "C", "D"},
+		},
+		{
+			name:    "clear drops machine-wide disables",
+			closer:  `<configuration><disabledPackageSources><clear /></disabledPackageSources></configuration>`,
+			machine: `<configuration><disabledPackageSources><add key="D" value="true" /></disabledPackageSources></configuration>`,
+			want:    []string{},
+		},
+		{
+			name:   "keys are case-sensitive",
+			closer: `<configuration><disabledPackageSources><clear /><add key="a" value="true" /></disabledPackageSources></configuration>`,
+			want:   []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+
+			var layers []ConfigLayer
+			if tt.closer != "" {
+				layers = append(layers, writeLayer(t, filepath.Join(root, "repo", "src"), tt.closer, false))
+			}
+			layers = append(layers, writeLayer(t, filepath.Join(root, "repo"), parentSourcesConfig, false))
+			if tt.machine != "" {
+				layers = append(layers, writeLayer(t, filepath.Join(root, "machine"), tt.machine, true))
+			}
+
+			got := disabledKeys(MergeDisabledSources(layers))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("MergeDisabledSources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDisabledSources_RecordsOrigin(t *testing.T) {
+	root := t.TempDir()
+	closer := writeLayer(t, filepath.Join(root, "src"),
+		`<configuration><disabledPackageSources><add key="A" value="true" /></disabledPackageSources></configuration>`, false)
+	parent := writeLayer(t, root, parentSourcesConfig, false)
+
+	disabled := MergeDisabledSources([]ConfigLayer{closer, parent})
+	if disabled["A"].ConfigPath != closer.Path {
+		t.Errorf("A disabled by %s, want closer file %s", disabled["A"].ConfigPath, closer.Path)
+	}
+	if disabled["B"].ConfigPath != parent.Path {
+		t.Errorf("B disabled by %s, want parent file %s", disabled["B"].ConfigPath, parent.Path)
+	}
+}
+
+func TestMergePackageSources(t *testing.T) {
+	root := t.TempDir()
+	closer := writeLayer(t, filepath.Join(root, "src"), `<configuration><packageSources>
+    <add key="G" value="https://g.example/v3/index.json" />
+    <add key="B" value="https://b2.example/v3/index.json" />
+  </packageSources></configuration>`, false)
+	parent := writeLayer(t, root, parentSourcesConfig, false)
+	user := writeLayer(t, filepath.Join(root, "user"), `<configuration><packageSources>
+    <add key="nuget.org" value="https://api.nuget.org/v3/index.json" />
+  </packageSources></configuration>`, false)
+
+	sources := MergePackageSources([]ConfigLayer{closer, parent, user})
+
+	var got []string
+	for _, s := range sources {
+		got = append(got, s.Key+"="+s.Value)
+	}
+	want := []string{
+		"G=https://g.example/v3/index.json",
+		"B=https://b2.example/v3/index.json",
+		"A=https://a.example/v3/index.json",
+		"C=https://c.example/v3/index.json",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("MergePackageSources() = %v, want %v", got, want)
+	}
+}
+
+func TestMergeEnabledPackageSources(t *testing.T) {
+	root := t.TempDir()
+	closer := writeLayer(t, filepath.Join(root, "src"), `<configuration>
+  <packageSources>
+    <add key="Private" value="https://private.example/v3/index.json" />
+    <add key="Off" value="https://off.example/v3/index.json" enabled="false" />
+  </packageSources>
+  <disabledPackageSources><clear /></disabledPackageSources>
+</configuration>`, false)
+	parent := writeLayer(t, root, parentSourcesConfig, false)
+	user := writeLayer(t, filepath.Join(root, "user"), `<configuration><packageSources>
+    <add key="nuget.org" value="https://api.nuget.org/v3/index.json" />
+  </packageSources></configuration>`, false)
+
+	var got []string
+	for _, s := range MergeEnabledPackageSources([]ConfigLayer{closer, parent, user}) {
+		got = append(got, s.Key)
+	}
+	// The closer <clear/> re-enables the parent's disabled sources; the parent's <clear/>
+	// drops nuget.org from the user config
+	if want := []string{"Private", "A", "B", "C"}; !slices.Equal(got, want) {
+		t.Errorf("MergeEnabledPackageSources() = %v, want %v", got, want)
+	}
+
+	got = nil
+	for _, s := range MergeEnabledPackageSources([]ConfigLayer{parent, user}) {
+		got = append(got, s.Key)
+	}
+	if len(got) != 0 {
+		t.Errorf("MergeEnabledPackageSources() = %v, want every source disabled", got)
+	}
+}
+
+func TestEnableSourceInLayers_RemovesEntryFromEveryFile(t *testing.T) {
+	root := t.TempDir()
+	closer := writeLayer(t, filepath.Join(root, "src"),
+		`<configuration><disabledPackageSources><add key="B" value="true" /></disabledPackageSources></configuration>`, false)
+	parent := writeLayer(t, root, parentSourcesConfig, false)
+	layers := []ConfigLayer{closer, parent}
+
+	modified, err := EnableSourceInLayers(layers, "B")
+	if err != nil {
+		t.Fatalf("EnableSourceInLayers() error = %v", err)
+	}
+	if len(modified) != 2 {
+		t.Errorf("modified = %v, want both files", modified)
+	}
+
+	reloaded := []ConfigLayer{reload(t, closer), reload(t, parent)}
+	if _, ok := MergeDisabledSources(reloaded)["B"]; ok {
+		t.Error("B should be enabled after EnableSourceInLayers")
+	}
+	// Entries are removed rather than rewritten as value="false"
+	data, _ := os.ReadFile(parent.Path)
+	if strings.Contains(string(data), `key="B" value=""`) || strings.Contains(string(data), `key="B" value="false"`) {
+		t.Errorf("parent config still has an entry for B:\n%s", data)
+	}
+	// Other entries are untouched
+	if !reloaded[1].Config.IsSourceDisabled("A") || !reloaded[1].Config.IsSourceDisabled("C") {
+		t.Error("A and C should still be disabled in the parent config")
+	}
+}
+
+func TestEnableSourceInLayers_MachineWide(t *testing.T) {
+	root := t.TempDir()
+	local := writeLayer(t, filepath.Join(root, "src"),
+		`<configuration><disabledPackageSources><add key="A" value="true" /></disabledPackageSources></configuration>`, false)
+	machine := writeLayer(t, filepath.Join(root, "machine"),
+		`<configuration><disabledPackageSources><add key="A" value="true" /></disabledPackageSources></configuration>`, true)
+
+	_, err := EnableSourceInLayers([]ConfigLayer{local, machine}, "A")
+	if !errors.Is(err, ErrMachineWideSetting) {
+		t.Fatalf("EnableSourceInLayers() error = %v, want ErrMachineWideSetting", err)
+	}
+
+	// Nothing is modified when the change can't be completed
+	if !reload(t, local).Config.IsSourceDisabled("A") {
+		t.Error("local entry should be left in place")
+	}
+}
+
+func TestDisableSourceInLayers(t *testing.T) {
+	root := t.TempDir()
+	closer := writeLayer(t, filepath.Join(root, "src"),
+		`<configuration><packageSources><add key="G" value="https://g.example/v3/index.json" /></packageSources></configuration>`, false)
+	parent := writeLayer(t, root, `<configuration><packageSources>
+    <add key="F" value="https://f.example/v3/index.json" />
+  </packageSources></configuration>`, false)
+
+	// New entries go to the file that defines the source
+	modified, err := DisableSourceInLayers([]ConfigLayer{closer, parent}, "F")
+	if err != nil {
+		t.Fatalf("DisableSourceInLayers() error = %v", err)
+	}
+	if len(modified) != 1 || modified[0] != parent.Path {
+		t.Errorf("modified = %v, want %s", modified, parent.Path)
+	}
+	if !reload(t, parent).Config.IsSourceDisabled("F") {
+		t.Error("F should be disabled in the parent config")
+	}
+
+	if _, err := DisableSourceInLayers([]ConfigLayer{closer, parent}, "Missing"); err == nil {
+		t.Error("DisableSourceInLayers() expected error for unknown source")
+	}
+}
+
+func TestEnableSource_KeepsClear(t *testing.T) {
+	cfg, err := ParseNuGetConfig(strings.NewReader(
+		`<configuration><disabledPackageSources><clear /><add key="A" value="true" /></disabledPackageSources></configuration>`))
+	if err != nil {
+		t.Fatalf("ParseNuGetConfig() error = %v", err)
+	}
+
+	cfg.EnableSource("A")
+	if cfg.DisabledPackageSources == nil || cfg.DisabledPackageSources.Clear == nil {
+		t.Error("EnableSource() must keep a <clear/> that re-enables farther files")
+	}
+}
+
+func reload(t *testing.T, layer ConfigLayer) ConfigLayer {
+	t.Helper()
+	cfg, err := LoadNuGetConfig(layer.Path)
+	if err != nil {
+		t.Fatalf("LoadNuGetConfig() error = %v", err)
+	}
+	layer.Config = cfg
+	return layer
+}
@@ -3,10 +3,15 @@ package config
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 )
 
 // NuGetConfig represents a NuGet.config file
@@ -19,6 +24,7 @@ type NuGetConfig struct {
 	Config                   *Section                  `xml:"config"`
 	TrustedSigners           *TrustedSigners           `xml:"trustedSigners"`
 	PackageSourceCredentials *PackageSourceCredentials `xml:"packageSourceCredentials"`
+	PackageSourceMapping     *PackageSourceMapping     `xml:"packageSourceMapping"`
 }
 
 // FallbackPackageFolders contains fallback package folder definitions
@@ -32,12 +38,15 @@ type FallbackPackageFolder struct {
 	Value string `xml:"value,attr"`
 }
 
-// DisabledPackageSources contains disabled package source definitions
+// DisabledPackageSources contains disabled package source definitions.
+// A <clear/> re-enables every source disabled by farther config files.
 type DisabledPackageSources struct {
-	Add []DisabledPackageSource `xml:"add"`
+	Clear *bool                   `xml:"clear"`
+	Add   []DisabledPackageSource `xml:"add"`
 }
 
-// DisabledPackageSource represents a disabled package source
+// DisabledPackageSource represents a disabled package source.
+// The entry's presence disables the source; its value is not interpreted (matches dotnet).
 type DisabledPackageSource struct {
 	Key   string `xml:"key,attr"`
 	Value string `xml:"value,attr"`
@@ -81,16 +90,52 @@ type Item struct {
 	Value string `xml:"value,attr"`
 }
 
-// TrustedSigners contains trusted signer definitions
+// TrustedSigners contains trusted signer definitions.
+// A <clear/> drops the trusted signers of farther config files.
 type TrustedSigners struct {
-	Clear bool            `xml:"clear"`
-	Add   []TrustedSigner `xml:"add,omitempty"`
+	Clear        *bool               `xml:"clear"`
+	Authors      []TrustedAuthor     `xml:"author"`
+	Repositories []TrustedRepository `xml:"repository"`
 }
 
-// TrustedSigner represents a trusted signer
-type TrustedSigner struct {
-	Name string `xml:"name,attr"`
-	// Additional fields as needed
+// TrustedAuthor represents a trusted author and its signing certificates
+type TrustedAuthor struct {
+	Name         string                     `xml:"name,attr"`
+	Certificates []TrustedSignerCertificate `xml:"certificate"`
+}
+
+// TrustedRepository represents a trusted repository, its signing certificates and,
+// optionally, the package owners trusted on it (separated by semicolons)
+type TrustedRepository struct {
+	Name         string                     `xml:"name,attr"`
+	ServiceIndex string                     `xml:"serviceIndex,attr"`
+	Certificates []TrustedSignerCertificate `xml:"certificate"`
+	Owners       string                     `xml:"owners,omitempty"`
+}
+
+// TrustedSignerCertificate represents a trusted signing certificate
+type TrustedSignerCertificate struct {
+	Fingerprint        string `xml:"fingerprint,attr"`
+	HashAlgorithm      string `xml:"hashAlgorithm,attr"`
+	AllowUntrustedRoot string `xml:"allowUntrustedRoot,attr"`
+}
+
+// PackageSourceMapping pins package ID patterns to package sources.
+// A <clear/> drops the mappings of farther config files.
+type PackageSourceMapping struct {
+	Clear   *bool                      `xml:"clear"`
+	Sources []PackageSourceMappingItem `xml:"packageSource"`
+}
+
+// PackageSourceMappingItem lists the package ID patterns a source may serve
+type PackageSourceMappingItem struct {
+	Key      string           `xml:"key,attr"`
+	Packages []PackagePattern `xml:"package"`
+}
+
+// PackagePattern is a package ID, or an ID prefix followed by *
+type PackagePattern struct {
+	Pattern string `xml:"pattern,attr"`
 }
 
 // PackageSourceCredentials contains credentials for sources
@@ -214,6 +259,47 @@ func (c *NuGetConfig) RemovePackageSource(key string) bool {
 	return false
 }
 
+// GetSourceCredential gets the packageSourceCredentials entry of a source by name
+func (c *NuGetConfig) GetSourceCredential(sourceName string) *SourceCredential {
+	if c.PackageSourceCredentials == nil {
+		return nil
+	}
+
+	for i := range c.PackageSourceCredentials.Items {
+		if c.PackageSourceCredentials.Items[i].XMLName.Local == sourceName {
+			return &c.PackageSourceCredentials.Items[i]
+		}
+	}
+
+	return nil
+}
+
+// RemoveSourceCredential removes the packageSourceCredentials entry of a source by name.
+// The section itself is removed once it has no entries left.
+func (c *NuGetConfig) RemoveSourceCredential(sourceName string) bool {
+	if c.PackageSourceCredentials == nil {
+		return false
+	}
+
+	removed := false
+	for i := range c.PackageSourceCredentials.Items {
+		if c.PackageSourceCredentials.Items[i].XMLName.Local == sourceName {
+			c.PackageSourceCredentials.Items = append(
+				c.PackageSourceCredentials.Items[:i],
+				c.PackageSourceCredentials.Items[i+1:]...,
+			)
+			removed = true
+			break
+		}
+	}
+
+	if len(c.PackageSourceCredentials.Items) == 0 {
+		c.PackageSourceCredentials = nil
+	}
+
+	return removed
+}
+
 // GetConfigValue gets a configuration value by key
 func (c *NuGetConfig) GetConfigValue(key string) string {
 	if c.Config == nil {
@@ -357,29 +443,29 @@ func getMachineWideConfigPaths() []string {
 			}
 		}
 	} else {
-		// Unix-like systems: Check /etc/nuget
-		configPath := "/etc/nuget/NuGet.config"
-		if _, err := os.Stat(configPath); err == nil {
-			paths = append(paths, configPath)
+		// Unix-like systems: scan the CommonApplicationData NuGet\Config directory
+		// (/etc/opt on Linux, /Library/Application Support on macOS) like dotnet
+		configDir := filepath.Join("/etc/opt", "NuGet", "Config")
+		if runtime.GOOS == "darwin" {
+			configDir = filepath.Join("/Library/Application Support", "NuGet", "Config")
+		}
+		if entries, err := os.ReadDir(configDir); err == nil {
+			for _, entry := range entries {
+				if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".config") {
+					paths = append(paths, filepath.Join(configDir, entry.Name()))
+				}
+			}
 		}
 	}
 
 	return paths
 }
 
-// IsSourceDisabled checks if a source is disabled
+// IsSourceDisabled checks if a source is disabled by this file alone.
+// Any disabledPackageSources entry for the key disables the source, whatever its value.
+// Use MergeDisabledSources for the effective state across the config hierarchy.
 func (c *NuGetConfig) IsSourceDisabled(key string) bool {
-	if c.DisabledPackageSources == nil {
-		return false
-	}
-
-	for _, disabled := range c.DisabledPackageSources.Add {
-		if disabled.Key == key && disabled.Value == "true" {
-			return true
-		}
-	}
-
-	return false
+	return c.hasDisabledEntry(key)
 }
 
 // DisableSource disables a package source
@@ -419,8 +505,8 @@ func (c *NuGetConfig) EnableSource(key string) {
 
 	c.DisabledPackageSources.Add = filtered
 
-	// Clean up empty section
-	if len(c.DisabledPackageSources.Add) == 0 {
+	// Clean up empty section (a <clear/> still matters to the hierarchy)
+	if len(c.DisabledPackageSources.Add) == 0 && c.DisabledPackageSources.Clear == nil {
 		c.DisabledPackageSources = nil
 	}
 }
@@ -450,6 +536,27 @@ func (c *NuGetConfig) GetEnabledPackageSources() []PackageSource {
 	return enabled
 }
 
+// EnabledPackageSources returns this file's sources that are not in the merged disabled set
+// and not disabled via their enabled attribute.
+func (c *NuGetConfig) EnabledPackageSources(disabled map[string]DisabledSourceEntry) []PackageSource {
+	if c.PackageSources == nil {
+		return []PackageSource{}
+	}
+
+	var enabled []PackageSource
+	for _, source := range c.PackageSources.Add {
+		if _, ok := disabled[source.Key]; ok {
+			continue
+		}
+		if source.Enabled == "false" {
+			continue
+		}
+		enabled = append(enabled, source)
+	}
+
+	return enabled
+}
+
 // GetEnabledSourcesOrDefault returns enabled package sources from the config hierarchy,
 // or default sources if none are configured. This matches NuGet.Client behavior where
 // the default nuget.org source is always available as a fallback.
@@ -458,12 +565,15 @@ func (c *NuGetConfig) GetEnabledPackageSources() []PackageSource {
 // then checks the user config location. If no sources are found in any config, it returns
 // the default sources (nuget.org).
 func GetEnabledSourcesOrDefault(startDir string) []PackageSource {
+	// Sources disabled anywhere in the hierarchy (honoring <clear/>) are skipped
+	disabled := MergeDisabledSources(LoadConfigLayers(startDir))
+
 	// Try to find and load config from the hierarchy
 	configPath := FindConfigFileFrom(startDir)
 	if configPath != "" {
 		cfg, err := LoadNuGetConfig(configPath)
 		if err == nil {
-			sources := cfg.GetEnabledPackageSources()
+			sources := cfg.EnabledPackageSources(disabled)
 			if len(sources) > 0 {
 				return sources
 			}
@@ -475,34 +585,118 @@ func GetEnabledSourcesOrDefault(startDir string) []PackageSource {
 	if userConfigPath != "" {
 		cfg, err := LoadNuGetConfig(userConfigPath)
 		if err == nil {
-			sources := cfg.GetEnabledPackageSources()
+			sources := cfg.EnabledPackageSources(disabled)
 			if len(sources) > 0 {
 				return sources
 			}
 		}
 	}
 
-	// If still no sources found, ensure user config exists and return default sources
-	// This matches NuGet.Client behavior where it auto-creates the config with defaults
-	_ = EnsureUserConfigExists()
+	// On first run there is no user config: create it with the default source and say so
+	// (matches NuGet.Client's Settings.LoadSettings)
+	created, err := EnsureUserConfigExists()
+	if created && FirstRunOutput != nil {
+		_, _ = fmt.Fprintf(FirstRunOutput, "Created the default NuGet configuration file at %s with the nuget.org package source.\n", userConfigPath)
+	} else if err == nil {
+		// Another process created it first: use what it wrote
+		if cfg, err := loadUserConfigWithRetry(userConfigPath); err == nil {
+			if sources := cfg.EnabledPackageSources(disabled); len(sources) > 0 {
+				return sources
+			}
+		}
+	}
 	return DefaultPackageSources()
 }
 
-// EnsureUserConfigExists creates the user-level NuGet.Config file with default sources
-// if it doesn't already exist. This matches NuGet.Client's behavior of auto-creating
-// the config file when any NuGet operation is performed.
-func EnsureUserConfigExists() error {
+// FirstRunOutput receives the notice printed when the user config is created on first run.
+// When nil, the default, nothing is printed. The CLI sets it to the error stream of its
+// console, so the notice is serialized with the rest of the output.
+var FirstRunOutput io.Writer
+
+// EnsureUserConfigExists creates the user-level NuGet.Config with DefaultUserConfig if it
+// doesn't already exist, and reports whether it created it. This matches NuGet.Client's
+// behavior of auto-creating the config file when any NuGet operation is performed.
+//
+// The file is written under a temporary name and linked into place, so concurrent first
+// runs never see or produce a partial file: exactly one of them creates it. Where hard
+// links aren't supported (FAT, exFAT, some network and overlay filesystems), the file is
+// created exclusively and written in place instead; a concurrent run may then read it
+// before it is complete, which loadUserConfigWithRetry absorbs.
+func EnsureUserConfigExists() (bool, error) {
 	configPath := GetUserConfigPath()
 	if configPath == "" {
-		return fmt.Errorf("unable to determine user config path")
+		return false, fmt.Errorf("unable to determine user config path")
 	}
 
 	// Check if config already exists
 	if _, err := os.Stat(configPath); err == nil {
-		return nil // Config already exists
+		return false, nil
 	}
 
-	// Create the config with default sources
-	cfg := NewDefaultConfig()
-	return SaveNuGetConfig(configPath, cfg)
+	dir := filepath.Dir(configPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".NuGet.Config-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.WriteString(DefaultUserConfig)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	// Link fails if the file exists (O_EXCL semantics) and never exposes a partial file
+	if err := os.Link(tmp.Name(), configPath); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return false, nil
+		}
+		return createUserConfigExclusive(configPath)
+	}
+	return true, nil
+}
+
+// createUserConfigExclusive creates the user config in place with O_EXCL, for filesystems
+// without hard links, and reports whether it created it.
+func createUserConfigExclusive(configPath string) (bool, error) {
+	file, err := os.OpenFile(configPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create config file: %w", err)
+	}
+
+	_, err = file.WriteString(DefaultUserConfig)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(configPath)
+		return false, fmt.Errorf("failed to write config file: %w", err)
+	}
+	return true, nil
+}
+
+// loadUserConfigWithRetry loads a user config another process may still be writing,
+// retrying briefly while it doesn't parse.
+func loadUserConfigWithRetry(path string) (*NuGetConfig, error) {
+	var err error
+	for range 5 {
+		var cfg *NuGetConfig
+		if cfg, err = LoadNuGetConfig(path); err == nil {
+			return cfg, nil
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	return nil, err
 }
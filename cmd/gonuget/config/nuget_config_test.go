@@ -4,7 +4,10 @@ package config
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -92,6 +95,34 @@ func TestNuGetConfig_RemovePackageSource(t *testing.T) {
 	}
 }
 
+func TestNuGetConfig_RemoveSourceCredential(t *testing.T) {
+	cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration>
+  <packageSourceCredentials>
+    <Private><add key="Username" value="alice" /></Private>
+    <Other><add key="Username" value="bob" /></Other>
+  </packageSourceCredentials>
+</configuration>`))
+	if err != nil {
+		t.Fatalf("ParseNuGetConfig() error = %v", err)
+	}
+
+	if !cfg.RemoveSourceCredential("Private") {
+		t.Error("RemoveSourceCredential(Private) = false, want true")
+	}
+	if cfg.GetSourceCredential("Private") != nil || cfg.GetSourceCredential("Other") == nil {
+		t.Error("want only the credentials of Private removed")
+	}
+	if cfg.RemoveSourceCredential("Private") {
+		t.Error("RemoveSourceCredential(Private) = true for removed credentials")
+	}
+
+	// The emptied section is dropped
+	cfg.RemoveSourceCredential("Other")
+	if cfg.PackageSourceCredentials != nil {
+		t.Errorf("PackageSourceCredentials = %+v, want nil", cfg.PackageSourceCredentials)
+	}
+}
+
 func TestDefaultConfigLocations(t *testing.T) {
 	locations := DefaultConfigLocations()
 	if len(locations) == 0 {
@@ -489,3 +520,138 @@ func TestDefaultConfigLocations_NotEmpty(t *testing.T) {
 		t.Error("DefaultConfigLocations() should contain paths with NuGet.config")
 	}
 }
+
+// setTempUserHome points the user config location at a temporary directory.
+func setTempUserHome(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("APPDATA", home)
+	return home
+}
+
+func TestGetEnabledSourcesOrDefault_FirstRun(t *testing.T) {
+	setTempUserHome(t)
+	workDir := t.TempDir()
+
+	var notice bytes.Buffer
+	oldOutput := FirstRunOutput
+	FirstRunOutput = &notice
+	defer func() { FirstRunOutput = oldOutput }()
+
+	sources := GetEnabledSourcesOrDefault(workDir)
+	if len(sources) != 1 || sources[0].Value != "https://api.nuget.org/v3/index.json" {
+		t.Errorf("GetEnabledSourcesOrDefault() = %+v, want nuget.org", sources)
+	}
+
+	data, err := os.ReadFile(GetUserConfigPath())
+	if err != nil {
+		t.Fatalf("user config not created: %v", err)
+	}
+	if string(data) != DefaultUserConfig {
+		t.Errorf("user config =\n%s\nwant\n%s", data, DefaultUserConfig)
+	}
+	if strings.Count(notice.String(), "\n") != 1 || !strings.Contains(notice.String(), GetUserConfigPath()) {
+		t.Errorf("notice = %q, want one line naming the user config", notice.String())
+	}
+
+	// Later runs read the file and print nothing
+	notice.Reset()
+	if sources := GetEnabledSourcesOrDefault(workDir); len(sources) != 1 || sources[0].Key != "nuget.org" {
+		t.Errorf("GetEnabledSourcesOrDefault() = %+v, want nuget.org", sources)
+	}
+	if notice.Len() != 0 {
+		t.Errorf("notice on second run = %q, want none", notice.String())
+	}
+}
+
+func TestGetEnabledSourcesOrDefault_ConfiguredSourcesDoNotCreate(t *testing.T) {
+	setTempUserHome(t)
+	workDir := t.TempDir()
+	local := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <add key="local" value="/feeds/local" />
+  </packageSources>
+</configuration>`
+	if err := os.WriteFile(filepath.Join(workDir, "NuGet.config"), []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if sources := GetEnabledSourcesOrDefault(workDir); len(sources) != 1 || sources[0].Key != "local" {
+		t.Errorf("GetEnabledSourcesOrDefault() = %+v, want the local source", sources)
+	}
+	if _, err := os.Stat(GetUserConfigPath()); !os.IsNotExist(err) {
+		t.Errorf("user config created although sources are configured (stat error = %v)", err)
+	}
+}
+
+func TestEnsureUserConfigExists_Concurrent(t *testing.T) {
+	setTempUserHome(t)
+
+	const runs = 16
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	errs := make(chan error, runs)
+	for range runs {
+		wg.Go(func() {
+			ok, err := EnsureUserConfigExists()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if ok {
+				created.Add(1)
+			}
+			if _, err := LoadNuGetConfig(GetUserConfigPath()); err != nil {
+				errs <- err
+			}
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("concurrent first run error = %v", err)
+	}
+	if created.Load() != 1 {
+		t.Errorf("user config created %d times, want once", created.Load())
+	}
+	data, err := os.ReadFile(GetUserConfigPath())
+	if err != nil || string(data) != DefaultUserConfig {
+		t.Errorf("user config = %q, %v, want the default content", data, err)
+	}
+
+	// Only the config is left in the directory: no temporary files
+	entries, err := os.ReadDir(filepath.Dir(GetUserConfigPath()))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("config directory entries = %v, %v, want only NuGet.Config", entries, err)
+	}
+}
+
+func TestCreateUserConfigExclusive(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "NuGet.Config")
+
+	created, err := createUserConfigExclusive(configPath)
+	if err != nil || !created {
+		t.Fatalf("createUserConfigExclusive() = %v, %v, want created", created, err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil || string(data) != DefaultUserConfig {
+		t.Errorf("user config = %q, %v, want the default content", data, err)
+	}
+
+	// A second run leaves the existing file alone
+	if err := os.WriteFile(configPath, []byte("<configuration />"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	created, err = createUserConfigExclusive(configPath)
+	if err != nil || created {
+		t.Errorf("createUserConfigExclusive() = %v, %v, want not created", created, err)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != "<configuration />" {
+		t.Errorf("existing config overwritten with %q", data)
+	}
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SourceMapping is the effective packageSourceMapping of a config hierarchy. A package
+// is restored only from the sources of its most specific matching pattern.
+type SourceMapping struct {
+	patterns []sourcePattern
+}
+
+// sourcePattern is a validated package pattern and the sources it is mapped to
+type sourcePattern struct {
+	prefix   string // Lowercase ID, or ID prefix when wildcard is set
+	wildcard bool
+	sources  []string
+}
+
+// SourceMapping returns the package source mapping of the config's packageSourceMapping
+// section, or nil when the config maps no source.
+func (c *NuGetConfig) SourceMapping() (*SourceMapping, error) {
+	return MergeSourceMapping([]ConfigLayer{{Config: c}})
+}
+
+// MergeSourceMapping returns the package source mapping for layers ordered closest first,
+// or nil when no layer maps a source. The closest mapping of a source name wins, and
+// farther files are ignored once a layer with <clear/> in packageSourceMapping has been
+// applied. An invalid pattern is an error naming the source and its config file.
+func MergeSourceMapping(layers []ConfigLayer) (*SourceMapping, error) {
+	var mapping SourceMapping
+	seen := make(map[string]bool)
+
+	for _, layer := range layers {
+		section := layer.Config.PackageSourceMapping
+		if section == nil {
+			continue
+		}
+
+		for _, source := range section.Sources {
+			key := strings.ToLower(source.Key)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			if source.Key == "" {
+				return nil, sourceMappingError(layer.Path, source.Key, "the key attribute is missing")
+			}
+			for _, pkg := range source.Packages {
+				if err := mapping.add(source.Key, pkg.Pattern); err != nil {
+					return nil, sourceMappingError(layer.Path, source.Key, err.Error())
+				}
+			}
+		}
+
+		if section.Clear != nil {
+			break
+		}
+	}
+
+	if len(mapping.patterns) == 0 {
+		return nil, nil
+	}
+	return &mapping, nil
+}
+
+// add maps pattern to source
+func (m *SourceMapping) add(source, pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	prefix, wildcard := strings.CutSuffix(pattern, "*")
+	if pattern == "" || strings.Contains(prefix, "*") {
+		return fmt.Errorf("invalid package pattern '%s' (expected a package ID, optionally ending with *)", pattern)
+	}
+
+	prefix = strings.ToLower(prefix)
+	for i := range m.patterns {
+		if m.patterns[i].prefix == prefix && m.patterns[i].wildcard == wildcard {
+			if !slices.Contains(m.patterns[i].sources, source) {
+				m.patterns[i].sources = append(m.patterns[i].sources, source)
+			}
+			return nil
+		}
+	}
+	m.patterns = append(m.patterns, sourcePattern{prefix: prefix, wildcard: wildcard, sources: []string{source}})
+	return nil
+}
+
+// SourcesFor returns the names of the sources packageID may be restored from, or nil
+// when no pattern matches it. An exact ID wins over prefixes, and a longer prefix wins
+// over a shorter one; case is ignored (matches NuGet.Client's PackageSourceMapping).
+func (m *SourceMapping) SourcesFor(packageID string) []string {
+	id := strings.ToLower(packageID)
+
+	var best *sourcePattern
+	for i := range m.patterns {
+		p := &m.patterns[i]
+		if p.wildcard && !strings.HasPrefix(id, p.prefix) || !p.wildcard && id != p.prefix {
+			continue
+		}
+		if best == nil || moreSpecific(p, best) {
+			best = p
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+	return best.sources
+}
+
+// moreSpecific reports whether pattern a takes precedence over b when both match
+func moreSpecific(a, b *sourcePattern) bool {
+	if a.wildcard != b.wildcard {
+		return !a.wildcard
+	}
+	return len(a.prefix) > len(b.prefix)
+}
+
+func sourceMappingError(path, source, message string) error {
+	if path == "" {
+		return fmt.Errorf("invalid package source mapping for '%s': %s", source, message)
+	}
+	return fmt.Errorf("invalid package source mapping for '%s' in '%s': %s", source, path, message)
+}
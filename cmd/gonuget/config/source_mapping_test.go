@@ -0,0 +1,147 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSourceMapping_SourcesFor(t *testing.T) {
+	cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration>
+  <packageSourceMapping>
+    <packageSource key="nuget.org">
+      <package pattern="*" />
+      <package pattern="Newtonsoft.Json" />
+    </packageSource>
+    <packageSource key="contoso">
+      <package pattern="Contoso.*" />
+      <package pattern="Contoso.Internal.Tools" />
+    </packageSource>
+    <packageSource key="internal">
+      <package pattern="Contoso.Internal.*" />
+      <package pattern="Newtonsoft.Json" />
+    </packageSource>
+  </packageSourceMapping>
+</configuration>`))
+	if err != nil {
+		t.Fatalf("ParseNuGetConfig() error = %v", err)
+	}
+
+	mapping, err := cfg.SourceMapping()
+	if err != nil {
+		t.Fatalf("SourceMapping() error = %v", err)
+	}
+
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{"Serilog", []string{"nuget.org"}},
+		{"contoso.logging", []string{"contoso"}},
+		{"Contoso.Internal.Data", []string{"internal"}},
+		{"Contoso.Internal.Tools", []string{"contoso"}},
+		{"Newtonsoft.Json", []string{"nuget.org", "internal"}},
+	}
+	for _, tt := range tests {
+		if got := mapping.SourcesFor(tt.id); !slices.Equal(got, tt.want) {
+			t.Errorf("SourcesFor(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestSourceMapping_NoMatch(t *testing.T) {
+	cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration>
+  <packageSourceMapping>
+    <packageSource key="contoso"><package pattern="Contoso.*" /></packageSource>
+  </packageSourceMapping>
+</configuration>`))
+	if err != nil {
+		t.Fatalf("ParseNuGetConfig() error = %v", err)
+	}
+
+	mapping, err := cfg.SourceMapping()
+	if err != nil {
+		t.Fatalf("SourceMapping() error = %v", err)
+	}
+	if got := mapping.SourcesFor("Contoso"); got != nil {
+		t.Errorf("SourcesFor(Contoso) = %q, want no source", got)
+	}
+}
+
+func TestSourceMapping_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping string
+		wantErr string
+	}{
+		{"empty pattern", `<packageSource key="feed"><package pattern="" /></packageSource>`, "invalid package pattern ''"},
+		{"inner wildcard", `<packageSource key="feed"><package pattern="Contoso.*.Data" /></packageSource>`, "invalid package pattern 'Contoso.*.Data'"},
+		{"missing key", `<packageSource><package pattern="*" /></packageSource>`, "the key attribute is missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration><packageSourceMapping>` + tt.mapping + `</packageSourceMapping></configuration>`))
+			if err != nil {
+				t.Fatalf("ParseNuGetConfig() error = %v", err)
+			}
+			if _, err := cfg.SourceMapping(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SourceMapping() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeSourceMapping(t *testing.T) {
+	root := t.TempDir()
+	user := writeLayer(t, filepath.Join(root, "user"), `<configuration>
+  <packageSourceMapping>
+    <packageSource key="nuget.org"><package pattern="*" /></packageSource>
+    <packageSource key="contoso"><package pattern="Contoso.*" /></packageSource>
+  </packageSourceMapping>
+</configuration>`, false)
+	project := writeLayer(t, filepath.Join(root, "project"), `<configuration>
+  <packageSourceMapping>
+    <packageSource key="Contoso"><package pattern="Fabrikam.*" /></packageSource>
+  </packageSourceMapping>
+</configuration>`, false)
+	cleared := writeLayer(t, filepath.Join(root, "cleared"), `<configuration>
+  <packageSourceMapping>
+    <clear />
+    <packageSource key="local"><package pattern="Contoso.*" /></packageSource>
+  </packageSourceMapping>
+</configuration>`, false)
+
+	// The closest mapping of a source wins
+	mapping, err := MergeSourceMapping([]ConfigLayer{project, user})
+	if err != nil {
+		t.Fatalf("MergeSourceMapping() error = %v", err)
+	}
+	if got := mapping.SourcesFor("Fabrikam.Core"); !slices.Equal(got, []string{"Contoso"}) {
+		t.Errorf("SourcesFor(Fabrikam.Core) = %q, want the project's Contoso", got)
+	}
+	if got := mapping.SourcesFor("Contoso.Core"); !slices.Equal(got, []string{"nuget.org"}) {
+		t.Errorf("SourcesFor(Contoso.Core) = %q, want nuget.org once the user's contoso patterns are overridden", got)
+	}
+
+	// A <clear/> drops the farther files
+	mapping, err = MergeSourceMapping([]ConfigLayer{cleared, user})
+	if err != nil {
+		t.Fatalf("MergeSourceMapping() error = %v", err)
+	}
+	if got := mapping.SourcesFor("Serilog"); got != nil {
+		t.Errorf("SourcesFor(Serilog) = %q, want no source", got)
+	}
+
+	// Without any mapping there is nothing to enforce
+	if mapping, err := MergeSourceMapping([]ConfigLayer{{Config: &NuGetConfig{}}}); err != nil || mapping != nil {
+		t.Errorf("MergeSourceMapping() = %v, %v, want nil", mapping, err)
+	}
+
+	// Errors name the config file
+	user.Config.PackageSourceMapping.Sources[0].Packages[0].Pattern = "**"
+	if _, err := MergeSourceMapping([]ConfigLayer{project, user}); err == nil || !strings.Contains(err.Error(), user.Path) {
+		t.Errorf("MergeSourceMapping() error = %v, want the config file named", err)
+	}
+}
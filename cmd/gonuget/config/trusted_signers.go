@@ -0,0 +1,147 @@
+package config
+
+import (
+	"crypto"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/willibrandon/gonuget/packaging/signatures"
+)
+
+// TrustPolicy returns the signature trust policy of the config's trustedSigners section.
+func (c *NuGetConfig) TrustPolicy() (*signatures.TrustPolicy, error) {
+	return MergeTrustPolicy([]ConfigLayer{{Config: c}})
+}
+
+// MergeTrustPolicy returns the signature trust policy for layers ordered closest first.
+// The closest definition of a trusted signer name wins, and farther files are ignored
+// once a layer with <clear/> in trustedSigners has been applied. An invalid certificate
+// entry is an error naming the signer and its config file.
+func MergeTrustPolicy(layers []ConfigLayer) (*signatures.TrustPolicy, error) {
+	policy := &signatures.TrustPolicy{}
+	seen := make(map[string]bool)
+
+	for _, layer := range layers {
+		section := layer.Config.TrustedSigners
+		if section == nil {
+			continue
+		}
+
+		for _, author := range section.Authors {
+			key := strings.ToLower(author.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			certificates, err := trustedCertificates(layer.Path, author.Name, author.Certificates)
+			if err != nil {
+				return nil, err
+			}
+			policy.Authors = append(policy.Authors, signatures.TrustedAuthor{
+				Name:         author.Name,
+				Certificates: certificates,
+			})
+		}
+
+		for _, repository := range section.Repositories {
+			key := strings.ToLower(repository.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			certificates, err := trustedCertificates(layer.Path, repository.Name, repository.Certificates)
+			if err != nil {
+				return nil, err
+			}
+			policy.Repositories = append(policy.Repositories, signatures.TrustedRepository{
+				Name:         repository.Name,
+				ServiceIndex: repository.ServiceIndex,
+				Certificates: certificates,
+				Owners:       splitOwners(repository.Owners),
+			})
+		}
+
+		if section.Clear != nil {
+			break
+		}
+	}
+
+	return policy, nil
+}
+
+// trustedCertificates validates the certificate entries of the trusted signer name
+func trustedCertificates(path, name string, entries []TrustedSignerCertificate) ([]signatures.TrustedCertificate, error) {
+	if name == "" {
+		return nil, trustedSignerError(path, name, "the name attribute is missing")
+	}
+	if len(entries) == 0 {
+		return nil, trustedSignerError(path, name, "no certificate is listed")
+	}
+
+	certificates := make([]signatures.TrustedCertificate, 0, len(entries))
+	for _, entry := range entries {
+		algorithm, size, ok := fingerprintAlgorithm(entry.HashAlgorithm)
+		if !ok {
+			return nil, trustedSignerError(path, name, fmt.Sprintf("unsupported hash algorithm '%s' (expected SHA256, SHA384 or SHA512)", entry.HashAlgorithm))
+		}
+
+		fingerprint := strings.ReplaceAll(strings.TrimSpace(entry.Fingerprint), ":", "")
+		if decoded, err := hex.DecodeString(fingerprint); err != nil || len(decoded) != size {
+			return nil, trustedSignerError(path, name, fmt.Sprintf("invalid certificate fingerprint '%s' (expected a %s fingerprint in hex)", entry.Fingerprint, algorithm))
+		}
+
+		var allowUntrustedRoot bool
+		if entry.AllowUntrustedRoot != "" {
+			value, err := strconv.ParseBool(entry.AllowUntrustedRoot)
+			if err != nil {
+				return nil, trustedSignerError(path, name, fmt.Sprintf("invalid allowUntrustedRoot value '%s'", entry.AllowUntrustedRoot))
+			}
+			allowUntrustedRoot = value
+		}
+
+		certificates = append(certificates, signatures.TrustedCertificate{
+			Fingerprint:        fingerprint,
+			HashAlgorithm:      algorithm,
+			AllowUntrustedRoot: allowUntrustedRoot,
+		})
+	}
+
+	return certificates, nil
+}
+
+// fingerprintAlgorithm returns the hash algorithm named by a hashAlgorithm attribute
+// and its digest size. An empty attribute is SHA256.
+func fingerprintAlgorithm(name string) (signatures.HashAlgorithmName, int, bool) {
+	switch strings.ToUpper(strings.ReplaceAll(name, "-", "")) {
+	case "", "SHA256":
+		return signatures.HashAlgorithmSHA256, crypto.SHA256.Size(), true
+	case "SHA384":
+		return signatures.HashAlgorithmSHA384, crypto.SHA384.Size(), true
+	case "SHA512":
+		return signatures.HashAlgorithmSHA512, crypto.SHA512.Size(), true
+	default:
+		return "", 0, false
+	}
+}
+
+// splitOwners splits a semicolon separated owners list
+func splitOwners(owners string) []string {
+	var result []string
+	for owner := range strings.SplitSeq(owners, ";") {
+		if owner = strings.TrimSpace(owner); owner != "" {
+			result = append(result, owner)
+		}
+	}
+	return result
+}
+
+func trustedSignerError(path, name, message string) error {
+	if path == "" {
+		return fmt.Errorf("invalid trusted signer '%s': %s", name, message)
+	}
+	return fmt.Errorf("invalid trusted signer '%s' in '%s': %s", name, path, message)
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/packaging/signatures"
+)
+
+const (
+	authorFingerprint = "3F9001EA83C560D712C24CF213C3D312CB3BFF51EE89435D3430BD06B5D0EECE"
+	nugetFingerprint  = "0E5F38F57DC1BCC806D8494F4F90FBCEDD988B46760709CBEEC6F4219AA6157D"
+)
+
+func TestNuGetConfig_TrustPolicy(t *testing.T) {
+	cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration>
+  <trustedSigners>
+    <author name="microsoft">
+      <certificate fingerprint="` + authorFingerprint + `" hashAlgorithm="SHA256" allowUntrustedRoot="false" />
+    </author>
+    <repository name="nuget.org" serviceIndex="https://api.nuget.org/v3/index.json">
+      <certificate fingerprint="` + nugetFingerprint + `" hashAlgorithm="SHA256" allowUntrustedRoot="true" />
+      <owners>microsoft; aspnet;;nuget</owners>
+    </repository>
+  </trustedSigners>
+</configuration>`))
+	if err != nil {
+		t.Fatalf("ParseNuGetConfig() error = %v", err)
+	}
+
+	policy, err := cfg.TrustPolicy()
+	if err != nil {
+		t.Fatalf("TrustPolicy() error = %v", err)
+	}
+
+	if len(policy.Authors) != 1 || policy.Authors[0].Name != "microsoft" {
+		t.Fatalf("Authors = %+v, want microsoft", policy.Authors)
+	}
+	if got := policy.Authors[0].Certificates; len(got) != 1 || got[0] != (signatures.TrustedCertificate{Fingerprint: authorFingerprint, HashAlgorithm: signatures.HashAlgorithmSHA256}) {
+		t.Errorf("author Certificates = %+v", got)
+	}
+
+	if len(policy.Repositories) != 1 {
+		t.Fatalf("Repositories = %+v, want nuget.org", policy.Repositories)
+	}
+	repository := policy.Repositories[0]
+	if repository.Name != "nuget.org" || repository.ServiceIndex != "https://api.nuget.org/v3/index.json" {
+		t.Errorf("repository = %+v", repository)
+	}
+	if len(repository.Certificates) != 1 || !repository.Certificates[0].AllowUntrustedRoot {
+		t.Errorf("repository Certificates = %+v, want one allowing an untrusted root", repository.Certificates)
+	}
+	if want := []string{"microsoft", "aspnet", "nuget"}; !slices.Equal(repository.Owners, want) {
+		t.Errorf("Owners = %q, want %q", repository.Owners, want)
+	}
+}
+
+func TestNuGetConfig_TrustPolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		signers string
+		wantErr string
+	}{
+		{
+			"no certificate",
+			`<author name="contoso" />`,
+			"invalid trusted signer 'contoso': no certificate is listed",
+		},
+		{
+			"unsupported hash algorithm",
+			`<author name="contoso"><certificate fingerprint="` + authorFingerprint + `" hashAlgorithm="SHA1" /></author>`,
+			"unsupported hash algorithm 'SHA1'",
+		},
+		{
+			"fingerprint of another algorithm",
+			`<repository name="feed"><certificate fingerprint="` + authorFingerprint + `" hashAlgorithm="SHA512" /></repository>`,
+			"expected a SHA512 fingerprint in hex",
+		},
+		{
+			"invalid allowUntrustedRoot",
+			`<author name="contoso"><certificate fingerprint="` + authorFingerprint + `" hashAlgorithm="SHA256" allowUntrustedRoot="maybe" /></author>`,
+			"invalid allowUntrustedRoot value 'maybe'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseNuGetConfig(strings.NewReader(`<configuration><trustedSigners>` + tt.signers + `</trustedSigners></configuration>`))
+			if err != nil {
+				t.Fatalf("ParseNuGetConfig() error = %v", err)
+			}
+			if _, err := cfg.TrustPolicy(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("TrustPolicy() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMergeTrustPolicy(t *testing.T) {
+	root := t.TempDir()
+	user := writeLayer(t, filepath.Join(root, "user"), `<configuration>
+  <trustedSigners>
+    <author name="contoso"><certificate fingerprint="`+nugetFingerprint+`" hashAlgorithm="SHA256" /></author>
+    <author name="fabrikam"><certificate fingerprint="`+nugetFingerprint+`" hashAlgorithm="SHA256" /></author>
+  </trustedSigners>
+</configuration>`, false)
+	project := writeLayer(t, filepath.Join(root, "project"), `<configuration>
+  <trustedSigners>
+    <author name="Contoso"><certificate fingerprint="`+authorFingerprint+`" hashAlgorithm="SHA256" /></author>
+  </trustedSigners>
+</configuration>`, false)
+	cleared := writeLayer(t, filepath.Join(root, "cleared"), `<configuration>
+  <trustedSigners>
+    <clear />
+    <author name="northwind"><certificate fingerprint="`+authorFingerprint+`" hashAlgorithm="SHA256" /></author>
+  </trustedSigners>
+</configuration>`, false)
+
+	authorNames := func(policy *signatures.TrustPolicy) []string {
+		var names []string
+		for _, author := range policy.Authors {
+			names = append(names, author.Name)
+		}
+		return names
+	}
+
+	// The closest definition of a name wins
+	policy, err := MergeTrustPolicy([]ConfigLayer{project, user})
+	if err != nil {
+		t.Fatalf("MergeTrustPolicy() error = %v", err)
+	}
+	if got := authorNames(policy); !slices.Equal(got, []string{"Contoso", "fabrikam"}) {
+		t.Errorf("authors = %q, want the project's Contoso and the user's fabrikam", got)
+	}
+	if got := policy.Authors[0].Certificates[0].Fingerprint; got != authorFingerprint {
+		t.Errorf("Contoso fingerprint = %s, want the project's", got)
+	}
+
+	// A <clear/> drops the farther files
+	policy, err = MergeTrustPolicy([]ConfigLayer{cleared, user})
+	if err != nil {
+		t.Fatalf("MergeTrustPolicy() error = %v", err)
+	}
+	if got := authorNames(policy); !slices.Equal(got, []string{"northwind"}) {
+		t.Errorf("authors = %q, want only northwind", got)
+	}
+
+	// Errors name the config file
+	user.Config.TrustedSigners.Authors[1].Certificates[0].Fingerprint = "not hex"
+	if _, err := MergeTrustPolicy([]ConfigLayer{project, user}); err == nil || !strings.Contains(err.Error(), user.Path) {
+		t.Errorf("MergeTrustPolicy() error = %v, want the config file named", err)
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"github.com/willibrandon/gonuget/packaging"
+)
+
+// maxConfigUpdateAttempts bounds how often an update is retried when another process
+// modifies the config file between reading and writing it.
+const maxConfigUpdateAttempts = 5
+
+// ErrConfigModified is returned when a config file kept changing while it was updated.
+var ErrConfigModified = errors.New("config file was modified by another process")
+
+// UpdateNuGetConfig applies mutate to the current content of the config file at path
+// and saves the result. Concurrent updates from gonuget processes are serialized by a
+// lock file in the temp directory (see configLockTarget), and the file is read again once
+// the lock is held, so distinct edits made in parallel all survive instead of the last
+// writer winning.
+//
+// Writers that don't take the lock, such as dotnet, or updates made without it because
+// the lock file can't be created (another user's lock file), are detected by comparing
+// the file with what was read just before writing it; mutate is then applied again to
+// the new content, up to maxConfigUpdateAttempts times. mutate may therefore be called
+// more than once, each time with a freshly read config. When the file doesn't exist,
+// mutate receives the config returned by initial and the file is created; a nil initial
+// requires the file to exist. An error returned by mutate is returned as is, without
+// writing anything.
+func UpdateNuGetConfig(path string, initial func() *NuGetConfig, mutate func(cfg *NuGetConfig) error) error {
+	lockTarget := configLockTarget(path)
+	if !canLockConfig(lockTarget) {
+		return updateNuGetConfig(path, initial, mutate)
+	}
+
+	var updateErr error
+	err := packaging.WithFileLock(context.Background(), lockTarget, func() error {
+		updateErr = updateNuGetConfig(path, initial, mutate)
+		return updateErr
+	})
+	if updateErr != nil {
+		return updateErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock config file: %w", err)
+	}
+	return nil
+}
+
+// configLockTarget returns the path locked while the config at path is updated. The lock
+// file lives under the temp directory rather than next to the config: lock files are
+// kept after use on Unix, and one would otherwise be left beside every NuGet.config.
+// The name is a hash of the absolute config path, so every process updating the same
+// file takes the same lock. The folder is per user, as the temp directory may be shared.
+func configLockTarget(path string) string {
+	if absPath, err := filepath.Abs(path); err == nil {
+		path = absPath
+	}
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		// Case-insensitive file systems: differently cased paths are the same file
+		path = strings.ToLower(path)
+	}
+	folder := "gonuget"
+	if u, err := user.Current(); err == nil {
+		folder += "-" + u.Uid
+	}
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(os.TempDir(), folder, "config-locks", hex.EncodeToString(sum[:16]))
+}
+
+// canLockConfig reports whether the lock file for lockTarget can be created. Updates
+// fall back to detecting concurrent modifications when it can't.
+func canLockConfig(lockTarget string) bool {
+	if err := os.MkdirAll(filepath.Dir(lockTarget), 0755); err != nil {
+		return false
+	}
+	f, err := os.OpenFile(lockTarget+packaging.LockFileExtension, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return !errors.Is(err, fs.ErrPermission) && !errors.Is(err, syscall.EROFS)
+	}
+	_ = f.Close()
+	return true
+}
+
+// updateNuGetConfig is the read-modify-write cycle of UpdateNuGetConfig. The file is
+// only written if it still holds what was read, otherwise the cycle starts over.
+func updateNuGetConfig(path string, initial func() *NuGetConfig, mutate func(cfg *NuGetConfig) error) error {
+	for range maxConfigUpdateAttempts {
+		original, err := os.ReadFile(path)
+		if err != nil && (initial == nil || !errors.Is(err, fs.ErrNotExist)) {
+			return fmt.Errorf("failed to open config file: %w", err)
+		}
+		exists := err == nil
+
+		var cfg *NuGetConfig
+		if exists {
+			if cfg, err = ParseNuGetConfig(bytes.NewReader(original)); err != nil {
+				return err
+			}
+		} else {
+			cfg = initial()
+		}
+		if err := mutate(cfg); err != nil {
+			return err
+		}
+
+		var data bytes.Buffer
+		if err := WriteNuGetConfig(&data, cfg); err != nil {
+			return err
+		}
+
+		// Another process wrote the file meanwhile: apply the change to its content
+		current, err := os.ReadFile(path)
+		if exists != (err == nil) || !bytes.Equal(current, original) {
+			continue
+		}
+
+		if err := writeConfigFile(path, data.Bytes()); err != nil {
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s changed %d times while updating it", ErrConfigModified, path, maxConfigUpdateAttempts)
+}
+
+// writeConfigFile writes the content of a config file, creating its directory.
+func writeConfigFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writeTestConfig writes a config with a single source.
+func writeTestConfig(t *testing.T, path string) {
+	t.Helper()
+
+	cfg := &NuGetConfig{PackageSources: &PackageSources{Add: []PackageSource{{Key: "nuget.org", Value: "https://api.nuget.org/v3/index.json"}}}}
+	if err := SaveNuGetConfig(path, cfg); err != nil {
+		t.Fatalf("SaveNuGetConfig() error = %v", err)
+	}
+}
+
+func TestUpdateNuGetConfig_ConcurrentUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "NuGet.Config")
+	writeTestConfig(t, path)
+
+	const n = 16
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := range n {
+		wg.Go(func() {
+			errs[i] = UpdateNuGetConfig(path, nil, func(cfg *NuGetConfig) error {
+				cfg.AddPackageSource(PackageSource{Key: fmt.Sprintf("source%d", i), Value: fmt.Sprintf("https://example.com/%d/index.json", i)})
+				return nil
+			})
+		})
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("UpdateNuGetConfig(%d) error = %v", i, err)
+		}
+	}
+	cfg, err := LoadNuGetConfig(path)
+	if err != nil {
+		t.Fatalf("LoadNuGetConfig() error = %v", err)
+	}
+	for i := range n {
+		if cfg.GetPackageSource(fmt.Sprintf("source%d", i)) == nil {
+			t.Errorf("source%d lost", i)
+		}
+	}
+	if cfg.GetPackageSource("nuget.org") == nil {
+		t.Error("existing source lost")
+	}
+}
+
+func TestUpdateNuGetConfig_ExternalEdit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "NuGet.Config")
+	writeTestConfig(t, path)
+
+	// Another tool, which doesn't take the lock, edits the file while the update runs
+	calls := 0
+	err := UpdateNuGetConfig(path, nil, func(cfg *NuGetConfig) error {
+		calls++
+		if calls == 1 {
+			external, err := LoadNuGetConfig(path)
+			if err != nil {
+				return err
+			}
+			external.AddPackageSource(PackageSource{Key: "external", Value: "https://external.example.com/index.json"})
+			if err := SaveNuGetConfig(path, external); err != nil {
+				return err
+			}
+		}
+		cfg.AddPackageSource(PackageSource{Key: "ours", Value: "https://ours.example.com/index.json"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateNuGetConfig() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("mutate called %d times, want 2", calls)
+	}
+
+	cfg, err := LoadNuGetConfig(path)
+	if err != nil {
+		t.Fatalf("LoadNuGetConfig() error = %v", err)
+	}
+	for _, key := range []string{"nuget.org", "external", "ours"} {
+		if cfg.GetPackageSource(key) == nil {
+			t.Errorf("source %s lost", key)
+		}
+	}
+}
+
+func TestUpdateNuGetConfig_KeepsChanging(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "NuGet.Config")
+	writeTestConfig(t, path)
+
+	calls := 0
+	err := updateNuGetConfig(path, nil, func(cfg *NuGetConfig) error {
+		calls++
+		return os.WriteFile(path, fmt.Appendf(nil, "<configuration><!-- %d --></configuration>", calls), 0644)
+	})
+	if !errors.Is(err, ErrConfigModified) {
+		t.Errorf("updateNuGetConfig() error = %v, want ErrConfigModified", err)
+	}
+	if calls != maxConfigUpdateAttempts {
+		t.Errorf("mutate called %d times, want %d", calls, maxConfigUpdateAttempts)
+	}
+}
+
+func TestUpdateNuGetConfig_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "NuGet.Config")
+	add := func(cfg *NuGetConfig) error {
+		cfg.SetConfigValue("repositoryPath", "packages")
+		return nil
+	}
+
+	// Without an initial config the file must exist
+	if err := UpdateNuGetConfig(path, nil, add); err == nil {
+		t.Fatal("UpdateNuGetConfig() succeeded without the file")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("config created without an initial config: %v", err)
+	}
+
+	if err := UpdateNuGetConfig(path, NewDefaultConfig, add); err != nil {
+		t.Fatalf("UpdateNuGetConfig() error = %v", err)
+	}
+	cfg, err := LoadNuGetConfig(path)
+	if err != nil {
+		t.Fatalf("LoadNuGetConfig() error = %v", err)
+	}
+	if cfg.GetConfigValue("repositoryPath") != "packages" || cfg.GetPackageSource("nuget.org") == nil {
+		t.Errorf("created config = %+v", cfg)
+	}
+
+	// An error from mutate leaves the file alone
+	want := errors.New("rejected")
+	if err := UpdateNuGetConfig(path, nil, func(cfg *NuGetConfig) error {
+		cfg.DeleteConfigValue("repositoryPath")
+		return want
+	}); err != want {
+		t.Errorf("UpdateNuGetConfig() error = %v, want %v", err, want)
+	}
+	if cfg, err := LoadNuGetConfig(path); err != nil || cfg.GetConfigValue("repositoryPath") != "packages" {
+		t.Errorf("config changed despite the error: %v", err)
+	}
+}
+
+func TestUpdateNuGetConfig_NoLockFileNextToConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "NuGet.Config")
+	writeTestConfig(t, path)
+
+	err := UpdateNuGetConfig(path, nil, func(cfg *NuGetConfig) error {
+		cfg.AddPackageSource(PackageSource{Key: "local", Value: "/packages"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateNuGetConfig() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 {
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("config directory holds %v, want only NuGet.Config", names)
+	}
+}
+
+func TestConfigLockTarget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "NuGet.Config")
+
+	target := configLockTarget(path)
+	if strings.HasPrefix(target, dir) {
+		t.Errorf("configLockTarget() = %s, want a path outside the config directory", target)
+	}
+	if got := configLockTarget(filepath.Join(dir, ".", "NuGet.Config")); got != target {
+		t.Errorf("configLockTarget() of an equivalent path = %s, want %s", got, target)
+	}
+	if got := configLockTarget(filepath.Join(dir, "other.config")); got == target {
+		t.Errorf("configLockTarget() of another config = %s, want a different lock", got)
+	}
+}
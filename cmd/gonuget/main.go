@@ -2,7 +2,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 	"os/signal"
 	"strings"
@@ -44,6 +43,11 @@ func main() {
 	cli.AddCommand(commands.NewConfigCommand(cli.Console))
 	cli.AddCommand(commands.NewRestoreCommand(cli.Console))
 	cli.AddCommand(commands.NewCompletionCommand())
+	cli.AddCommand(commands.NewServeCommand(cli.Console))
+	cli.AddCommand(commands.NewSignCommand())
+	cli.AddCommand(commands.NewVerifyCommand())
+	cli.AddCommand(commands.NewPushCommand())
+	cli.AddCommand(commands.NewDeleteCommand())
 
 	// Register noun-first parent commands with subcommands
 	// Package namespace: gonuget package add|list|remove|search
@@ -53,6 +57,14 @@ func main() {
 	cli.AddCommand(commands.GetSourceCommand())
 	commands.RegisterSourceSubcommands(cli.Console)
 
+	// Tool namespace: gonuget tool install
+	cli.AddCommand(commands.GetToolCommand())
+	commands.RegisterToolSubcommands(cli.Console)
+
+	// Debug namespace: gonuget debug http-replay
+	cli.AddCommand(commands.GetDebugCommand())
+	commands.RegisterDebugSubcommands(cli.Console)
+
 	// Handle signals for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -73,33 +85,33 @@ func main() {
 
 			// Check package namespace patterns
 			if pattern == "add package" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget package add\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget package add\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 			if pattern == "list package" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget package list\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget package list\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 			if pattern == "remove package" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget package remove\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget package remove\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 			if pattern == "search package" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget package search\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget package search\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 
 			// Check source namespace patterns
 			if pattern == "add source" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget source add\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget source add\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 			if pattern == "list source" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget source list\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget source list\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 			if pattern == "remove source" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget source remove\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget source remove\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 		}
@@ -108,24 +120,24 @@ func main() {
 		if len(args) >= 1 {
 			firstArg := strings.ToLower(args[0])
 			if firstArg == "enable" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget source enable\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget source enable\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 			if firstArg == "disable" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget source disable\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget source disable\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 			if firstArg == "update" {
-				fmt.Fprintf(os.Stderr, "Error: the verb-first form is not supported. Try: gonuget source update\n\nRun 'gonuget --help' for usage.\n")
+				cli.Console.WriteErrorLine("Error: the verb-first form is not supported. Try: gonuget source update\n\nRun 'gonuget --help' for usage.")
 				os.Exit(1)
 			}
 		}
 
 		// Print error to stderr since SilenceErrors is true in rootCmd
-		// Use os.Stderr directly to ensure error goes to stderr for interop testing
+		// Uncolored, so the error text matches dotnet for interop testing
 		// Don't print empty errors (used when NuGet errors are already formatted)
 		if err.Error() != "" {
-			_, _ = fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			cli.Console.WriteErrorLine("Error: %v", err)
 		}
 		os.Exit(1)
 	}
@@ -5,24 +5,122 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"sync"
+
+	"github.com/fatih/color"
+	"github.com/willibrandon/gonuget/observability"
 )
 
-// Verbosity levels
-type Verbosity int
+// Verbosity levels, shared with the library packages so every output gate compares the
+// same enum
+type Verbosity = observability.Verbosity
 
 const (
 	// VerbosityQuiet shows errors only
-	VerbosityQuiet Verbosity = iota
+	VerbosityQuiet = observability.VerbosityQuiet
+	// VerbosityMinimal shows errors, warnings and results
+	VerbosityMinimal = observability.VerbosityMinimal
 	// VerbosityNormal shows errors, warnings, and key operations (default)
-	VerbosityNormal
+	VerbosityNormal = observability.VerbosityNormal
 	// VerbosityDetailed shows above + progress details
-	VerbosityDetailed
+	VerbosityDetailed = observability.VerbosityDetailed
 	// VerbosityDiagnostic shows above + HTTP requests, cache hits, timing
-	VerbosityDiagnostic
+	VerbosityDiagnostic = observability.VerbosityDiagnostic
 )
 
-// Console provides output abstraction
+// clearLine erases the line under the cursor; a drawn status line leaves the cursor at column 1.
+const clearLine = "\x1B[K"
+
+// Every console write goes through writeMu, so output from parallel workers (or from two
+// consoles sharing a stream) is never interleaved mid-line. It also guards the status, a
+// single line or a live block of lines, which is cleared before other output and redrawn
+// after it. statusClear is the sequence that erases the drawn status.
+var (
+	writeMu     sync.Mutex
+	statusOut   io.Writer
+	statusLine  string
+	statusClear string
+	statusShown bool
+)
+
+// writeLocked writes s to w, moving the status out of the way. Callers hold writeMu.
+func writeLocked(w io.Writer, s string) {
+	if statusShown {
+		_, _ = io.WriteString(statusOut, statusClear)
+		statusShown = false
+	}
+	_, _ = io.WriteString(w, s)
+
+	// Redraw the status below the text, unless the text left a partial line
+	if statusLine != "" && strings.HasSuffix(s, "\n") {
+		_, _ = io.WriteString(statusOut, statusLine)
+		statusShown = true
+	}
+}
+
+// writeString writes s to w as one unit.
+func writeString(w io.Writer, s string) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	writeLocked(w, s)
+}
+
+// syncWriter is an io.Writer whose writes are serialized with all console output.
+type syncWriter struct {
+	w io.Writer
+}
+
+// Write writes p as one unit.
+func (s *syncWriter) Write(p []byte) (int, error) {
+	writeString(s.w, string(p))
+	return len(p), nil
+}
+
+// Fd returns the file descriptor of the underlying stream, so terminal detection
+// still works through the writer. It returns an invalid descriptor for other writers.
+func (s *syncWriter) Fd() uintptr {
+	if f, ok := s.w.(interface{ Fd() uintptr }); ok {
+		return f.Fd()
+	}
+	return ^uintptr(0)
+}
+
+// SetStatus draws line as the status line of the stream. line must leave the cursor
+// at the start of the line (end it with "\r").
+func (s *syncWriter) SetStatus(line string) {
+	drawStatus(s.w, line, clearLine)
+}
+
+// ClearStatus removes the status line.
+func (s *syncWriter) ClearStatus() {
+	drawStatus(nil, "", "")
+}
+
+// drawStatus replaces the drawn status with drawing on w, which clear erases again.
+// A nil w removes the status.
+func drawStatus(w io.Writer, drawing, clear string) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+	if statusShown {
+		_, _ = io.WriteString(statusOut, statusClear)
+	}
+	statusOut = w
+	statusLine = drawing
+	statusClear = clear
+	statusShown = false
+	if w != nil && drawing != "" {
+		_, _ = io.WriteString(w, drawing)
+		statusShown = true
+	}
+}
+
+// Console provides output abstraction.
+//
+// Console is safe for concurrent use. Each call writes its text in one piece, so a
+// call that writes whole lines (WriteLine, Println, Info, ...) is never split by
+// output from other goroutines. Output that spans several calls but must stay
+// together, such as a table, is written with BeginBlock and EndBlock.
 type Console struct {
 	out       io.Writer
 	err       io.Writer
@@ -83,100 +181,147 @@ func (c *Console) SetColors(enabled bool) {
 	}
 }
 
-// Print writes to output
-func (c *Console) Print(a ...any) {
+// colorsEnabled returns whether color output is enabled
+func (c *Console) colorsEnabled() bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	_, _ = fmt.Fprint(c.out, a...)
+	return c.colors
+}
+
+// Print writes to output
+func (c *Console) Print(a ...any) {
+	writeString(c.out, fmt.Sprint(a...))
 }
 
 // Println writes line to output
 func (c *Console) Println(a ...any) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	_, _ = fmt.Fprintln(c.out, a...)
+	writeString(c.out, fmt.Sprintln(a...))
 }
 
 // Printf writes formatted output
 func (c *Console) Printf(format string, a ...any) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	_, _ = fmt.Fprintf(c.out, format, a...)
+	writeString(c.out, fmt.Sprintf(format, a...))
+}
+
+// WriteLine writes one line to output; the line is never split by concurrent output
+func (c *Console) WriteLine(format string, a ...any) {
+	writeString(c.out, fmt.Sprintf(format, a...)+"\n")
+}
+
+// WriteErrorLine writes one line to the error stream, without prefix or color
+func (c *Console) WriteErrorLine(format string, a ...any) {
+	writeString(c.err, fmt.Sprintf(format, a...)+"\n")
 }
 
 // Success writes success message (green)
 func (c *Console) Success(format string, a ...any) {
-	if c.verbosity >= VerbosityNormal {
-		c.mu.Lock()
-		defer c.mu.Unlock()
-		if c.colors {
-			_, _ = ColorSuccess.Fprintf(c.out, format+"\n", a...)
-		} else {
-			_, _ = fmt.Fprintf(c.out, format+"\n", a...)
-		}
+	if c.GetVerbosity() >= VerbosityNormal {
+		writeString(c.out, c.colorize(ColorSuccess, format+"\n", a...))
 	}
 }
 
 // Error writes error message (red)
 func (c *Console) Error(format string, a ...any) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if c.colors {
-		_, _ = ColorError.Fprintf(c.err, "Error: "+format+"\n", a...)
-	} else {
-		_, _ = fmt.Fprintf(c.err, "Error: "+format+"\n", a...)
-	}
+	writeString(c.err, c.colorize(ColorError, "Error: "+format+"\n", a...))
 }
 
 // Warning writes warning message (yellow)
 func (c *Console) Warning(format string, a ...any) {
-	if c.verbosity >= VerbosityNormal {
-		c.mu.Lock()
-		defer c.mu.Unlock()
-		if c.colors {
-			_, _ = ColorWarning.Fprintf(c.out, "Warning: "+format+"\n", a...)
-		} else {
-			_, _ = fmt.Fprintf(c.out, "Warning: "+format+"\n", a...)
-		}
+	if c.GetVerbosity() >= VerbosityMinimal {
+		writeString(c.out, c.colorize(ColorWarning, "Warning: "+format+"\n", a...))
 	}
 }
 
 // Info writes info message (cyan)
 func (c *Console) Info(format string, a ...any) {
-	if c.verbosity >= VerbosityNormal {
-		c.mu.Lock()
-		defer c.mu.Unlock()
-		if c.colors {
-			_, _ = ColorInfo.Fprintf(c.out, format+"\n", a...)
-		} else {
-			_, _ = fmt.Fprintf(c.out, format+"\n", a...)
-		}
+	if c.GetVerbosity() >= VerbosityNormal {
+		writeString(c.out, c.colorize(ColorInfo, format+"\n", a...))
 	}
 }
 
 // Debug writes debug message (white)
 func (c *Console) Debug(format string, a ...any) {
-	if c.verbosity >= VerbosityDiagnostic {
-		c.mu.Lock()
-		defer c.mu.Unlock()
-		if c.colors {
-			_, _ = ColorDebug.Fprintf(c.out, "[DEBUG] "+format+"\n", a...)
-		} else {
-			_, _ = fmt.Fprintf(c.out, "[DEBUG] "+format+"\n", a...)
-		}
+	if c.GetVerbosity() >= VerbosityDiagnostic {
+		writeString(c.out, c.colorize(ColorDebug, "[DEBUG] "+format+"\n", a...))
 	}
 }
 
 // Detail writes detailed message
 func (c *Console) Detail(format string, a ...any) {
-	if c.verbosity >= VerbosityDetailed {
-		c.mu.Lock()
-		defer c.mu.Unlock()
-		_, _ = fmt.Fprintf(c.out, format+"\n", a...)
+	if c.GetVerbosity() >= VerbosityDetailed {
+		writeString(c.out, fmt.Sprintf(format+"\n", a...))
 	}
 }
 
-// Output returns the underlying output writer
+// colorize formats a message, in color when colors are enabled
+func (c *Console) colorize(col *color.Color, format string, a ...any) string {
+	if c.colorsEnabled() {
+		return col.Sprintf(format, a...)
+	}
+	return fmt.Sprintf(format, a...)
+}
+
+// SetStatus draws a status line on the output, such as a progress timer. Other
+// output clears the status line and redraws it below. line must leave the cursor
+// at the start of the line (end it with "\r").
+func (c *Console) SetStatus(line string) {
+	(&syncWriter{w: c.out}).SetStatus(line)
+}
+
+// ClearStatus removes the status line drawn by SetStatus.
+func (c *Console) ClearStatus() {
+	(&syncWriter{w: c.out}).ClearStatus()
+}
+
+// Output returns the output writer. Each Write is serialized with the console's
+// other output, and the writer supports SetStatus and ClearStatus.
 func (c *Console) Output() io.Writer {
-	return c.out
+	return &syncWriter{w: c.out}
+}
+
+// ErrorOutput returns the error stream writer, serialized like Output.
+func (c *Console) ErrorOutput() io.Writer {
+	return &syncWriter{w: c.err}
+}
+
+// Block collects output that must be written contiguously, such as a multi-line
+// error or a table. Nothing is written until EndBlock.
+type Block struct {
+	c   *Console
+	buf strings.Builder
+}
+
+// BeginBlock starts a block of output that is written in one piece by EndBlock.
+func (c *Console) BeginBlock() *Block {
+	return &Block{c: c}
+}
+
+// EndBlock writes the block's output.
+func (c *Console) EndBlock(b *Block) {
+	if b.buf.Len() > 0 {
+		writeString(c.out, b.buf.String())
+	}
+	b.buf.Reset()
+}
+
+// Write adds p to the block.
+func (b *Block) Write(p []byte) (int, error) {
+	return b.buf.Write(p)
+}
+
+// Println adds a line to the block
+func (b *Block) Println(a ...any) {
+	_, _ = fmt.Fprintln(&b.buf, a...)
+}
+
+// Printf adds formatted output to the block
+func (b *Block) Printf(format string, a ...any) {
+	_, _ = fmt.Fprintf(&b.buf, format, a...)
+}
+
+// Info adds an info message (cyan) to the block
+func (b *Block) Info(format string, a ...any) {
+	if b.c.GetVerbosity() >= VerbosityNormal {
+		b.buf.WriteString(b.c.colorize(ColorInfo, format+"\n", a...))
+	}
 }
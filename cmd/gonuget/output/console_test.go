@@ -3,7 +3,9 @@ package output
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -238,3 +240,87 @@ func TestIsColorEnabled(t *testing.T) {
 	// Actual behavior depends on terminal state
 	_ = IsColorEnabled()
 }
+
+func TestConsole_ConcurrentWritesNeverSplitLines(t *testing.T) {
+	var out bytes.Buffer
+	c := NewConsole(&out, &out, VerbosityNormal)
+	c.SetColors(false)
+
+	const workers = 32
+	const linesPerWorker = 200
+
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range linesPerWorker {
+				// Long lines make a split more likely if writes weren't atomic
+				payload := strings.Repeat(string(rune('a'+w%26)), 64+w)
+				switch i % 5 {
+				case 0:
+					c.WriteLine("<%d:%d:%s>", w, i, payload)
+				case 1:
+					c.Printf("<%d:%d:%s>\n", w, i, payload)
+				case 2:
+					c.Info("<%d:%d:%s>", w, i, payload)
+				case 3:
+					_, _ = fmt.Fprintf(c.Output(), "<%d:%d:%s>\n", w, i, payload)
+				case 4:
+					// A block's lines must stay together
+					b := c.BeginBlock()
+					b.Printf("<%d:%d:%s>\n", w, i, payload)
+					b.Printf("<%d:%d:%s:cont>\n", w, i, payload)
+					c.EndBlock(b)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	wantLines := workers * linesPerWorker * 6 / 5
+	if len(lines) != wantLines {
+		t.Fatalf("got %d lines, want %d", len(lines), wantLines)
+	}
+	for n, line := range lines {
+		// Every line must be one complete message: starts with '<', ends with '>', no markers inside
+		if !strings.HasPrefix(line, "<") || !strings.HasSuffix(line, ">") || strings.Count(line, "<") != 1 || strings.Count(line, ">") != 1 {
+			t.Fatalf("line %d was split or interleaved: %q", n, line)
+		}
+		if strings.HasSuffix(line, ":cont>") {
+			prev := strings.TrimSuffix(line, ":cont>") + ">"
+			if n == 0 || lines[n-1] != prev {
+				t.Fatalf("block line %d not preceded by its first line: %q", n, line)
+			}
+		}
+	}
+}
+
+func TestConsole_StatusLineClearedAroundOutput(t *testing.T) {
+	var out bytes.Buffer
+	c := NewConsole(&out, &out, VerbosityNormal)
+	c.SetColors(false)
+
+	c.SetStatus("status\r")
+	c.WriteLine("line one")
+	c.ClearStatus()
+	c.WriteLine("line two")
+
+	want := "status\r" + clearLine + "line one\n" + "status\r" + clearLine + "line two\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestConsole_WriteErrorLine(t *testing.T) {
+	var outBuf, errBuf bytes.Buffer
+	c := NewConsole(&outBuf, &errBuf, VerbosityNormal)
+	c.WriteErrorLine("Error: %s", "failed")
+	if got := errBuf.String(); got != "Error: failed\n" {
+		t.Errorf("WriteErrorLine() = %q, want %q", got, "Error: failed\n")
+	}
+	if outBuf.Len() != 0 {
+		t.Errorf("WriteErrorLine() wrote to output: %q", outBuf.String())
+	}
+}
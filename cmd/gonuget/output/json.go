@@ -32,6 +32,8 @@ type PackageListOutput struct {
 	Framework     string             `json:"framework"`
 	Packages      []PackageReference `json:"packages"`
 	Warnings      []string           `json:"warnings"`
+	Sources       []string           `json:"sources,omitempty"`   // Sources queried for --outdated or --vulnerable
+	AuditedAt     string             `json:"auditedAt,omitempty"` // When restore recorded the vulnerability data shown, if not live
 	ElapsedMs     int64              `json:"elapsedMs"`
 }
 
@@ -42,16 +44,43 @@ type PackageReference struct {
 	Type            string `json:"type"` // "direct" or "transitive"
 	ResolvedVersion string `json:"resolvedVersion"`
 	Framework       string `json:"framework,omitempty"`
+	LatestVersion   string `json:"latestVersion,omitempty"` // Newest version on the sources (--outdated)
+
+	Vulnerabilities []PackageVulnerability `json:"vulnerabilities,omitempty"` // Known vulnerabilities (--vulnerable)
+	Paths           [][]string             `json:"paths,omitempty"`           // Chains from top-level packages to a vulnerable transitive package
+}
+
+// PackageVulnerability represents a known vulnerability of a package in JSON output
+type PackageVulnerability struct {
+	Severity    string `json:"severity"`
+	AdvisoryURL string `json:"advisoryUrl"`
 }
 
 // PackageSearchOutput represents the JSON output for package search command
 type PackageSearchOutput struct {
-	SchemaVersion string         `json:"schemaVersion"`
-	SearchTerm    string         `json:"searchTerm"`
-	Sources       []string       `json:"sources"`
-	Items         []SearchResult `json:"items"`
-	Total         int            `json:"total"`
-	ElapsedMs     int64          `json:"elapsedMs"`
+	SchemaVersion string                `json:"schemaVersion"`
+	SearchTerm    string                `json:"searchTerm"`
+	Sources       []string              `json:"sources"`
+	Items         []SearchResult        `json:"items"`
+	Total         int                   `json:"total"`
+	SearchResults []SourceSearchResults `json:"searchResults"` // Results per source that answered, in source order
+	Problems      []SearchProblem       `json:"problems"`      // Sources that failed
+	ElapsedMs     int64                 `json:"elapsedMs"`
+}
+
+// SourceSearchResults represents the search results of one package source in JSON output
+type SourceSearchResults struct {
+	Source    string         `json:"source"`
+	SourceURL string         `json:"sourceUrl"`
+	Items     []SearchResult `json:"items"`
+	Total     int            `json:"total"`
+}
+
+// SearchProblem represents a package source that could not be searched in JSON output
+type SearchProblem struct {
+	Source    string `json:"source"`
+	SourceURL string `json:"sourceUrl"`
+	Message   string `json:"message"`
 }
 
 // SearchResult represents a package search result in JSON output
@@ -113,6 +142,8 @@ func NewPackageSearchOutput(searchTerm string, sources []string, start time.Time
 		Sources:       sources,
 		Items:         []SearchResult{},
 		Total:         0,
+		SearchResults: []SourceSearchResults{},
+		Problems:      []SearchProblem{},
 		ElapsedMs:     MeasureElapsed(start),
 	}
 }
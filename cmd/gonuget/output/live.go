@@ -0,0 +1,311 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// liveMode is how a LiveStatus shows progress
+type liveMode int
+
+const (
+	// liveOff writes nothing, for structured output and quiet verbosity
+	liveOff liveMode = iota
+	// livePlain writes a line whenever a project changes phase
+	livePlain
+	// liveTerminal updates a block of status lines in place
+	liveTerminal
+)
+
+// liveRefresh is how often the elapsed times of the live block are redrawn
+const liveRefresh = 100 * time.Millisecond
+
+// Project phases shown by LiveStatus
+const (
+	phaseResolving   = "resolving"
+	phaseDownloading = "downloading"
+	phaseWriting     = "writing assets"
+)
+
+// LiveStatus shows the progress of several projects restored at once, like the dotnet
+// terminal logger. On a terminal each in-flight project has a status line that is
+// updated in place, and finished projects collapse into a single summary line at the
+// top of the block. Console output written meanwhile appears above the block.
+//
+// Without a terminal, or with colors disabled (NO_COLOR, TERM=dumb), progress is
+// written as plain lines when a project changes phase. In structured output modes and
+// at quiet verbosity nothing is written.
+//
+// LiveStatus is safe for concurrent use.
+type LiveStatus struct {
+	c     *Console
+	mode  liveMode
+	width int
+	now   func() time.Time
+
+	mu       sync.Mutex
+	projects []*liveProject // in flight, in start order
+	restored int
+	failed   int
+	drawn    string
+	stopped  bool
+
+	done   chan struct{}
+	exited chan struct{}
+}
+
+// liveProject is a project being restored
+type liveProject struct {
+	name   string
+	phase  string
+	detail string
+	start  time.Time
+}
+
+// NewLiveStatus creates the progress display of a multi-project restore on the console's
+// output. structured disables it for JSON and other machine-readable output modes.
+func (c *Console) NewLiveStatus(structured bool) *LiveStatus {
+	mode := livePlain
+	width := 0
+	if structured || c.GetVerbosity() == VerbosityQuiet {
+		mode = liveOff
+	} else if w, ok := terminalWidth(c.out); ok && c.colorsEnabled() {
+		mode = liveTerminal
+		width = w
+	}
+	return newLiveStatus(c, mode, width, time.Now)
+}
+
+// newLiveStatus creates a LiveStatus that renders in mode, starting the refresh of the
+// elapsed times on a terminal.
+func newLiveStatus(c *Console, mode liveMode, width int, now func() time.Time) *LiveStatus {
+	l := &LiveStatus{
+		c:     c,
+		mode:  mode,
+		width: width,
+		now:   now,
+	}
+	if mode == liveTerminal {
+		l.done = make(chan struct{})
+		l.exited = make(chan struct{})
+		go l.refreshLoop()
+	}
+	return l
+}
+
+// terminalWidth returns the width of w when it is a terminal
+func terminalWidth(w io.Writer) (int, bool) {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0, false
+	}
+	width, _, err := term.GetSize(int(f.Fd()))
+	if err != nil || width <= 0 {
+		width = 120
+	}
+	return width, true
+}
+
+// Width returns the width of the console's output terminal, or 120 columns when the
+// output is redirected.
+func (c *Console) Width() int {
+	if width, ok := terminalWidth(c.out); ok {
+		return width
+	}
+	return 120
+}
+
+// IsLive reports whether progress is drawn as a live block on a terminal.
+func (l *LiveStatus) IsLive() bool {
+	return l.mode == liveTerminal
+}
+
+// Start adds project to the in-flight projects, in the resolving phase.
+func (l *LiveStatus) Start(project string) {
+	l.setPhase(project, phaseResolving, "")
+}
+
+// Downloading shows that project has downloaded done of total packages.
+func (l *LiveStatus) Downloading(project string, done, total int) {
+	l.setPhase(project, phaseDownloading, fmt.Sprintf("%d of %d", done, total))
+}
+
+// WritingAssets shows that project is writing its assets file.
+func (l *LiveStatus) WritingAssets(project string) {
+	l.setPhase(project, phaseWriting, "")
+}
+
+// Complete removes project from the in-flight projects and counts it as restored, or
+// as failed when err is not nil. The caller reports the error itself.
+func (l *LiveStatus) Complete(project string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		return
+	}
+
+	var elapsed time.Duration
+	for i, p := range l.projects {
+		if p.name == project {
+			elapsed = l.now().Sub(p.start)
+			l.projects = append(l.projects[:i], l.projects[i+1:]...)
+			break
+		}
+	}
+
+	outcome := "restored"
+	if err != nil {
+		outcome = "failed"
+		l.failed++
+	} else {
+		l.restored++
+	}
+
+	if l.mode == livePlain {
+		l.c.WriteLine("  %s %s (%.1fs)", project, outcome, elapsed.Seconds())
+	}
+	l.render()
+}
+
+// Stop removes the live block and writes the summary of the completed projects.
+// Safe to call multiple times.
+func (l *LiveStatus) Stop() {
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		return
+	}
+	l.stopped = true
+	l.mu.Unlock()
+
+	if l.done != nil {
+		close(l.done)
+		// Wait for an in-flight refresh so it can't redraw the block after it is cleared
+		<-l.exited
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.mode == liveTerminal {
+		drawStatus(nil, "", "")
+	}
+	if l.mode != liveOff && l.restored+l.failed > 0 {
+		l.c.WriteLine("%s", l.summary())
+	}
+}
+
+// setPhase moves project to phase, starting it when it isn't in flight yet
+func (l *LiveStatus) setPhase(project, phase, detail string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		return
+	}
+
+	var p *liveProject
+	for _, candidate := range l.projects {
+		if candidate.name == project {
+			p = candidate
+			break
+		}
+	}
+	if p == nil {
+		p = &liveProject{name: project, start: l.now()}
+		l.projects = append(l.projects, p)
+	}
+
+	// Plain lines are written for phase changes only, not for every downloaded package
+	if l.mode == livePlain && p.phase != phase {
+		if phase == phaseDownloading {
+			l.c.WriteLine("  %s %s %s", project, phase, detail)
+		} else {
+			l.c.WriteLine("  %s %s", project, phase)
+		}
+	}
+	p.phase = phase
+	p.detail = detail
+	l.render()
+}
+
+// refreshLoop redraws the live block so the elapsed times keep counting
+func (l *LiveStatus) refreshLoop() {
+	defer close(l.exited)
+	ticker := time.NewTicker(liveRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.mu.Lock()
+			l.render()
+			l.mu.Unlock()
+		case <-l.done:
+			return
+		}
+	}
+}
+
+// render draws the live block, unless it is unchanged. Callers hold l.mu.
+func (l *LiveStatus) render() {
+	if l.mode != liveTerminal || l.stopped {
+		return
+	}
+
+	var lines []string
+	if l.restored+l.failed > 0 {
+		lines = append(lines, l.summary())
+	}
+	now := l.now()
+	for _, p := range l.projects {
+		phase := p.phase
+		if p.detail != "" {
+			phase += " " + p.detail
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s (%.1fs)", p.name, phase, now.Sub(p.start).Seconds()))
+	}
+
+	// Hide the cursor while drawing; each line is cut to the terminal width so it
+	// never wraps, which would break moving back up over the block
+	var drawing strings.Builder
+	drawing.WriteString("\x1B[?25l")
+	for _, line := range lines {
+		drawing.WriteString(truncateLine(line, l.width-1))
+		drawing.WriteString(clearLine + "\n")
+	}
+	drawing.WriteString("\x1B[?25h")
+
+	if drawing.String() == l.drawn {
+		return
+	}
+	l.drawn = drawing.String()
+	if len(lines) == 0 {
+		drawStatus(nil, "", "")
+		return
+	}
+	drawStatus(l.c.out, l.drawn, fmt.Sprintf("\x1B[%dA\r\x1B[J", len(lines)))
+}
+
+// summary describes the completed projects
+func (l *LiveStatus) summary() string {
+	summary := fmt.Sprintf("Restored %d project(s)", l.restored)
+	if l.failed > 0 {
+		summary += fmt.Sprintf(", %d failed", l.failed)
+	}
+	return summary
+}
+
+// truncateLine cuts line to at most width characters
+func truncateLine(line string, width int) string {
+	if width <= 0 {
+		return line
+	}
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line
+	}
+	return string(runes[:width])
+}
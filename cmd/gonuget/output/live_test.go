@@ -0,0 +1,241 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// virtualTerminal applies written output to a screen, interpreting the ANSI sequences
+// the console uses: cursor up, erase line, erase display and cursor visibility.
+type virtualTerminal struct {
+	screen [][]rune
+	row    int
+	col    int
+}
+
+func (v *virtualTerminal) Write(p []byte) (int, error) {
+	s := []rune(string(p))
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\n':
+			v.row++
+			v.col = 0
+		case '\r':
+			v.col = 0
+		case '\x1B':
+			// CSI: ESC [ parameters final
+			j := i + 2
+			for j < len(s) && (s[j] < '@' || s[j] > '~') {
+				j++
+			}
+			param := strings.TrimPrefix(string(s[i+2:j]), "?")
+			v.control(s[j], param)
+			i = j
+		default:
+			v.line()
+			for len(v.screen[v.row]) <= v.col {
+				v.screen[v.row] = append(v.screen[v.row], ' ')
+			}
+			v.screen[v.row][v.col] = s[i]
+			v.col++
+		}
+	}
+	return len(p), nil
+}
+
+// control applies a CSI sequence
+func (v *virtualTerminal) control(final rune, param string) {
+	switch final {
+	case 'A':
+		n, _ := strconv.Atoi(param)
+		v.row = max(v.row-max(n, 1), 0)
+	case 'K':
+		v.line()
+		v.screen[v.row] = v.screen[v.row][:min(v.col, len(v.screen[v.row]))]
+	case 'J':
+		v.line()
+		v.screen[v.row] = v.screen[v.row][:min(v.col, len(v.screen[v.row]))]
+		v.screen = v.screen[:v.row+1]
+	}
+}
+
+// line makes sure the cursor row exists
+func (v *virtualTerminal) line() {
+	for len(v.screen) <= v.row {
+		v.screen = append(v.screen, nil)
+	}
+}
+
+// lines returns the screen without trailing blank lines
+func (v *virtualTerminal) lines() []string {
+	var lines []string
+	for _, line := range v.screen {
+		lines = append(lines, strings.TrimRight(string(line), " "))
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// fakeClock is a clock advanced by the test
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.t
+}
+
+func (f *fakeClock) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.t = f.t.Add(d)
+}
+
+func assertScreen(t *testing.T, term *virtualTerminal, want ...string) {
+	t.Helper()
+	got := term.lines()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("screen =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLiveStatus_Terminal(t *testing.T) {
+	term := &virtualTerminal{}
+	c := NewConsole(term, term, VerbosityNormal)
+	c.SetColors(false)
+
+	// The clock only moves when the test advances it, so the refresh redraws the same frame
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	live := newLiveStatus(c, liveTerminal, 48, clock.now)
+	defer live.Stop()
+
+	c.WriteLine("Restoring 3 projects")
+	live.Start("App.csproj")
+	live.Start("Lib.csproj")
+	live.Start("Tests.csproj")
+	clock.advance(1500 * time.Millisecond)
+	live.Downloading("App.csproj", 2, 5)
+	live.Downloading("Tests.csproj", 1, 40)
+
+	assertScreen(t, term,
+		"Restoring 3 projects",
+		"  App.csproj downloading 2 of 5 (1.5s)",
+		"  Lib.csproj resolving (1.5s)",
+		"  Tests.csproj downloading 1 of 40 (1.5s)",
+	)
+
+	// A failing project reports its error above the block
+	c.Error("NU1101: Unable to find package Missing.Package")
+	live.Complete("Lib.csproj", errors.New("restore failed"))
+	live.WritingAssets("App.csproj")
+	live.Complete("App.csproj", nil)
+	c.WriteLine("a very long line that is wider than the terminal is written as is")
+
+	assertScreen(t, term,
+		"Restoring 3 projects",
+		"Error: NU1101: Unable to find package Missing.Package",
+		"a very long line that is wider than the terminal is written as is",
+		"Restored 1 project(s), 1 failed",
+		"  Tests.csproj downloading 1 of 40 (1.5s)",
+	)
+
+	clock.advance(time.Second)
+	live.Complete("Tests.csproj", nil)
+	live.Stop()
+
+	assertScreen(t, term,
+		"Restoring 3 projects",
+		"Error: NU1101: Unable to find package Missing.Package",
+		"a very long line that is wider than the terminal is written as is",
+		"Restored 2 project(s), 1 failed",
+	)
+
+	// Output after Stop is not followed by a block
+	c.WriteLine("done")
+	if got := term.lines(); got[len(got)-1] != "done" {
+		t.Errorf("last line = %q, want done", got[len(got)-1])
+	}
+}
+
+func TestLiveStatus_TerminalTruncatesLines(t *testing.T) {
+	term := &virtualTerminal{}
+	c := NewConsole(term, term, VerbosityNormal)
+	live := newLiveStatus(c, liveTerminal, 20, time.Now)
+	defer live.Stop()
+
+	live.Start("A.Project.With.A.Long.Name.csproj")
+	assertScreen(t, term, "  A.Project.With.A.")
+}
+
+func TestLiveStatus_PlainLines(t *testing.T) {
+	var out bytes.Buffer
+	c := NewConsole(&out, &out, VerbosityNormal)
+	clock := &fakeClock{t: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	live := newLiveStatus(c, livePlain, 0, clock.now)
+
+	live.Start("App.csproj")
+	live.Downloading("App.csproj", 1, 3)
+	live.Downloading("App.csproj", 2, 3)
+	live.WritingAssets("App.csproj")
+	clock.advance(1200 * time.Millisecond)
+	live.Complete("App.csproj", nil)
+	live.Start("Lib.csproj")
+	live.Complete("Lib.csproj", errors.New("restore failed"))
+	live.Stop()
+
+	want := "  App.csproj resolving\n" +
+		"  App.csproj downloading 1 of 3\n" +
+		"  App.csproj writing assets\n" +
+		"  App.csproj restored (1.2s)\n" +
+		"  Lib.csproj resolving\n" +
+		"  Lib.csproj failed (0.0s)\n" +
+		"Restored 1 project(s), 1 failed\n"
+	if got := out.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLiveStatus_Disabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		verbosity  Verbosity
+		structured bool
+	}{
+		{"structured output", VerbosityNormal, true},
+		{"quiet", VerbosityQuiet, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			c := NewConsole(&out, &out, tt.verbosity)
+			live := c.NewLiveStatus(tt.structured)
+
+			live.Start("App.csproj")
+			live.Downloading("App.csproj", 1, 1)
+			live.Complete("App.csproj", nil)
+			live.Stop()
+
+			if out.Len() != 0 {
+				t.Errorf("output = %q, want nothing", out.String())
+			}
+		})
+	}
+}
+
+func TestLiveStatus_NotATerminal(t *testing.T) {
+	c := NewConsole(&bytes.Buffer{}, &bytes.Buffer{}, VerbosityNormal)
+	if live := c.NewLiveStatus(false); live.IsLive() {
+		t.Error("IsLive() = true for a buffer, want plain lines")
+	}
+}
@@ -14,14 +14,14 @@ type WarningWriter struct {
 // NewWarningWriter creates a new warning writer that outputs to stderr
 func NewWarningWriter() *WarningWriter {
 	return &WarningWriter{
-		writer: os.Stderr,
+		writer: &syncWriter{w: os.Stderr},
 	}
 }
 
 // NewWarningWriterWithOutput creates a warning writer with a custom output
 func NewWarningWriterWithOutput(w io.Writer) *WarningWriter {
 	return &WarningWriter{
-		writer: w,
+		writer: &syncWriter{w: w},
 	}
 }
 
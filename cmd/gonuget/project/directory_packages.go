@@ -12,6 +12,7 @@ import (
 type DirectoryPackagesProps struct {
 	Path     string
 	Root     *DirectoryPackagesRootElement
+	raw      []byte // XML the file was loaded from, edited by Save
 	modified bool
 }
 
@@ -24,8 +25,9 @@ type DirectoryPackagesRootElement struct {
 
 // PackageVersionGroup represents an <ItemGroup> containing PackageVersion elements.
 type PackageVersionGroup struct {
-	XMLName         xml.Name         `xml:"ItemGroup"`
-	PackageVersions []PackageVersion `xml:"PackageVersion"`
+	XMLName                 xml.Name                 `xml:"ItemGroup"`
+	PackageVersions         []PackageVersion         `xml:"PackageVersion"`
+	GlobalPackageReferences []GlobalPackageReference `xml:"GlobalPackageReference"`
 }
 
 // PackageVersion represents a <PackageVersion> element in Directory.Packages.props.
@@ -35,6 +37,37 @@ type PackageVersion struct {
 	Version string   `xml:"Version,attr"`
 }
 
+// GlobalPackageReference represents a <GlobalPackageReference> element in Directory.Packages.props.
+// It references a package from every project that manages its versions centrally.
+type GlobalPackageReference struct {
+	XMLName xml.Name `xml:"GlobalPackageReference"`
+	Include string   `xml:"Include,attr"`
+	Version string   `xml:"Version,attr,omitempty"`
+	// Asset metadata written on the item; restore always uses the implicit defaults instead
+	PrivateAssets string `xml:"PrivateAssets,attr,omitempty"`
+	IncludeAssets string `xml:"IncludeAssets,attr,omitempty"`
+	ExcludeAssets string `xml:"ExcludeAssets,attr,omitempty"`
+}
+
+// Implicit asset metadata of a GlobalPackageReference. The package contributes build
+// logic and analyzers but no compile assets, and never flows to referencing projects.
+// Matches the PackageReference items NuGet.targets creates from GlobalPackageReference.
+const (
+	GlobalPackageReferencePrivateAssets = "All"
+	GlobalPackageReferenceIncludeAssets = "Runtime;Build;Native;ContentFiles;Analyzers"
+)
+
+// PackageReference returns the PackageReference the item adds to every project.
+// Asset metadata written on the GlobalPackageReference is replaced by the implicit defaults.
+func (g GlobalPackageReference) PackageReference() PackageReference {
+	return PackageReference{
+		Include:       g.Include,
+		Version:       g.Version,
+		PrivateAssets: GlobalPackageReferencePrivateAssets,
+		IncludeAssets: GlobalPackageReferenceIncludeAssets,
+	}
+}
+
 // LoadDirectoryPackagesProps loads a Directory.Packages.props file from disk.
 func LoadDirectoryPackagesProps(path string) (*DirectoryPackagesProps, error) {
 	data, err := os.ReadFile(path)
@@ -50,6 +83,7 @@ func LoadDirectoryPackagesProps(path string) (*DirectoryPackagesProps, error) {
 	return &DirectoryPackagesProps{
 		Path:     path,
 		Root:     &root,
+		raw:      data,
 		modified: false,
 	}, nil
 }
@@ -93,6 +127,34 @@ func (dp *DirectoryPackagesProps) GetPackageVersion(packageID string) string {
 	return ""
 }
 
+// GetPackageVersions returns all PackageVersion elements in the file.
+func (dp *DirectoryPackagesProps) GetPackageVersions() []PackageVersion {
+	var versions []PackageVersion
+	for _, ig := range dp.Root.ItemGroups {
+		versions = append(versions, ig.PackageVersions...)
+	}
+	return versions
+}
+
+// GetGlobalPackageReferences returns all GlobalPackageReference elements in the file.
+func (dp *DirectoryPackagesProps) GetGlobalPackageReferences() []GlobalPackageReference {
+	var refs []GlobalPackageReference
+	for _, ig := range dp.Root.ItemGroups {
+		refs = append(refs, ig.GlobalPackageReferences...)
+	}
+	return refs
+}
+
+// IsCentralPackageManagementEnabled checks if the file sets ManagePackageVersionsCentrally to true.
+func (dp *DirectoryPackagesProps) IsCentralPackageManagementEnabled() bool {
+	for _, pg := range dp.Root.Properties {
+		if strings.EqualFold(strings.TrimSpace(pg.ManagePackageVersionsCentrally), "true") {
+			return true
+		}
+	}
+	return false
+}
+
 // RemovePackageVersion removes a PackageVersion by package ID.
 // Returns true if a PackageVersion was removed, false if not found.
 func (dp *DirectoryPackagesProps) RemovePackageVersion(packageID string) bool {
@@ -110,51 +172,47 @@ func (dp *DirectoryPackagesProps) RemovePackageVersion(packageID string) bool {
 	return false
 }
 
-// Save saves the Directory.Packages.props file to disk.
+// Save saves the Directory.Packages.props file to disk, with a UTF-8 BOM. Like
+// Project.Save, only the PackageVersion items that changed are written back.
 func (dp *DirectoryPackagesProps) Save() error {
 	if !dp.modified {
 		return nil
 	}
 
-	// Marshal XML with indentation
-	output, err := xml.MarshalIndent(dp.Root, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal Directory.Packages.props: %w", err)
+	groups := make([]itemGroupEdit[PackageVersion], len(dp.Root.ItemGroups))
+	for i, ig := range dp.Root.ItemGroups {
+		groups[i] = itemGroupEdit[PackageVersion]{items: ig.PackageVersions}
 	}
-
-	// Open file for writing
-	file, err := os.Create(dp.Path)
+	data, err := editItems(dp.raw, "PackageVersion", groups, func(pv PackageVersion) string { return pv.Include })
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer func() {
-		if cerr := file.Close(); cerr != nil && err == nil {
-			err = cerr
-		}
-	}()
-
-	// Write UTF-8 BOM (required for .NET compatibility)
-	if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
-		return err
+		return fmt.Errorf("failed to edit Directory.Packages.props: %w", err)
 	}
 
-	// Write XML declaration
-	if _, err := file.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n"); err != nil {
-		return err
+	// Reload Root so that its groups stay those of the XML, which loses groups the edit
+	// left empty
+	var root DirectoryPackagesRootElement
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("failed to parse edited Directory.Packages.props: %w", err)
 	}
 
-	// Write XML
-	if _, err := file.Write(output); err != nil {
+	if err := writeXMLFile(dp.Path, data); err != nil {
 		return err
 	}
 
+	dp.Root = &root
+	dp.raw = data
 	dp.modified = false
 	return nil
 }
 
-// findOrCreateItemGroup finds the first ItemGroup or creates a new one.
+// findOrCreateItemGroup finds the first ItemGroup holding PackageVersion items, else the
+// first ItemGroup, or creates a new one.
 func (dp *DirectoryPackagesProps) findOrCreateItemGroup() *PackageVersionGroup {
-	// Find first existing ItemGroup
+	for i := range dp.Root.ItemGroups {
+		if len(dp.Root.ItemGroups[i].PackageVersions) > 0 {
+			return &dp.Root.ItemGroups[i]
+		}
+	}
 	if len(dp.Root.ItemGroups) > 0 {
 		return &dp.Root.ItemGroups[0]
 	}
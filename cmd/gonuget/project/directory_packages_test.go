@@ -508,3 +508,43 @@ func TestFindOrCreateItemGroup_Empty(t *testing.T) {
 	assert.Len(t, dpp.Root.ItemGroups, 1, "Should create new ItemGroup")
 	assert.Len(t, ig.PackageVersions, 0)
 }
+
+func TestGetGlobalPackageReferences(t *testing.T) {
+	tmpDir := t.TempDir()
+	dppPath := filepath.Join(tmpDir, "Directory.Packages.props")
+
+	dppContent := `<?xml version="1.0" encoding="utf-8"?>
+<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.3" />
+    <GlobalPackageReference Include="StyleCop.Analyzers" Version="1.1.118" />
+    <GlobalPackageReference Include="Nerdbank.GitVersioning" Version="3.6.133" IncludeAssets="all" PrivateAssets="none" />
+  </ItemGroup>
+</Project>`
+
+	err := os.WriteFile(dppPath, []byte(dppContent), 0644)
+	require.NoError(t, err)
+
+	dpp, err := LoadDirectoryPackagesProps(dppPath)
+	require.NoError(t, err)
+	assert.True(t, dpp.IsCentralPackageManagementEnabled())
+	assert.Equal(t, "13.0.3", dpp.GetPackageVersion("Newtonsoft.Json"))
+
+	refs := dpp.GetGlobalPackageReferences()
+	require.Len(t, refs, 2)
+	assert.Equal(t, "StyleCop.Analyzers", refs[0].Include)
+	assert.Equal(t, "1.1.118", refs[0].Version)
+
+	// Explicit asset metadata is replaced by the implicit defaults
+	for _, ref := range refs {
+		pkgRef := ref.PackageReference()
+		assert.Equal(t, ref.Include, pkgRef.Include)
+		assert.Equal(t, ref.Version, pkgRef.Version)
+		assert.Equal(t, "All", pkgRef.PrivateAssets)
+		assert.Equal(t, "Runtime;Build;Native;ContentFiles;Analyzers", pkgRef.IncludeAssets)
+		assert.Empty(t, pkgRef.ExcludeAssets)
+	}
+}
@@ -0,0 +1,36 @@
+package project
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+)
+
+// PackagesConfigEntry is a <package> element of a packages.config file.
+type PackagesConfigEntry struct {
+	ID              string `xml:"id,attr"`
+	Version         string `xml:"version,attr"`
+	TargetFramework string `xml:"targetFramework,attr"`
+}
+
+// LoadPackagesConfig reads the packages listed in a packages.config file.
+func LoadPackagesConfig(path string) ([]PackagesConfigEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packages.config: %w", err)
+	}
+
+	var file struct {
+		Packages []PackagesConfigEntry `xml:"package"`
+	}
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse '%s': %w", path, err)
+	}
+
+	for _, entry := range file.Packages {
+		if entry.ID == "" || entry.Version == "" {
+			return nil, fmt.Errorf("invalid package entry in '%s': the id and version attributes are required", path)
+		}
+	}
+	return file.Packages, nil
+}
@@ -2,7 +2,9 @@
 package project
 
 import (
+	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -18,9 +20,15 @@ type Project struct {
 	modified         bool
 	TargetFramework  string   // Single target framework (e.g., "net8.0")
 	TargetFrameworks []string // Multiple target frameworks (e.g., ["net6.0", "net7.0", "net8.0"])
+	Style            Style    // How the project restores its packages
 }
 
-// LoadProject loads and parses a project file from the given path.
+// LoadProject loads and parses a project file from the given path and detects its Style.
+// SDK-style projects of any language are read the same way. A legacy (non-SDK) project
+// is restored from packages.config when it has one and no PackageReference items;
+// otherwise its TargetFrameworkVersion (v4.7.2) gives its target framework (net472).
+// Files that can't be restored, such as legacy projects for other platforms, fail with
+// an *UnsupportedProjectError.
 func LoadProject(path string) (*Project, error) {
 	// Read file
 	data, err := os.ReadFile(path)
@@ -28,9 +36,9 @@ func LoadProject(path string) (*Project, error) {
 		return nil, fmt.Errorf("failed to read project file: %w", err)
 	}
 
-	// Parse XML
+	// Parse XML; any root element is read here so that detectStyle can name it
 	var root RootElement
-	if err := xml.Unmarshal(data, &root); err != nil {
+	if err := xml.Unmarshal(data, &root); err != nil && !isRootElementError(err) {
 		return nil, fmt.Errorf("failed to parse project XML: %w", err)
 	}
 
@@ -57,23 +65,70 @@ func LoadProject(path string) (*Project, error) {
 		}
 	}
 
+	if err := detectStyle(proj, data); err != nil {
+		return nil, err
+	}
+
 	return proj, nil
 }
 
-// Save saves the project file with UTF-8 BOM and formatting preservation.
+// isRootElementError reports whether err is the decoder's complaint about a root element
+// other than <Project>
+func isRootElementError(err error) bool {
+	var unmarshalErr xml.UnmarshalError
+	return errors.As(err, &unmarshalErr) && strings.HasPrefix(string(unmarshalErr), "expected element type <Project>")
+}
+
+// Save saves the project file with a UTF-8 BOM. A loaded project keeps its formatting:
+// only the PackageReference items that changed are written back to the XML it was read
+// from (see editItems), so other changes to Root aren't saved.
 func (p *Project) Save() error {
 	if !p.modified {
 		return nil
 	}
 
-	// Marshal with indentation
-	output, err := xml.MarshalIndent(p.Root, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal project: %w", err)
+	var data []byte
+	root := p.Root
+	if p.Root.RawXML != nil {
+		groups := make([]itemGroupEdit[PackageReference], len(p.Root.ItemGroups))
+		for i, ig := range p.Root.ItemGroups {
+			groups[i] = itemGroupEdit[PackageReference]{condition: ig.Condition, items: ig.PackageReferences}
+		}
+		edited, err := editItems(p.Root.RawXML, "PackageReference", groups, func(ref PackageReference) string { return ref.Include })
+		if err != nil {
+			return fmt.Errorf("failed to edit project: %w", err)
+		}
+		data = edited
+
+		// Reload Root so that its groups stay those of the XML, which loses groups the
+		// edit left empty
+		root = &RootElement{}
+		if err := xml.Unmarshal(data, root); err != nil && !isRootElementError(err) {
+			return fmt.Errorf("failed to parse edited project: %w", err)
+		}
+	} else {
+		// A new project is marshaled with indentation
+		output, err := xml.MarshalIndent(p.Root, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal project: %w", err)
+		}
+		data = append([]byte("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n"), output...)
+	}
+
+	if err := writeXMLFile(p.Path, data); err != nil {
+		return err
 	}
 
-	// Open file for writing
-	file, err := os.Create(p.Path)
+	root.RawXML = data
+	p.Root = root
+	p.modified = false
+	return nil
+}
+
+// writeXMLFile writes data to path, starting it with a UTF-8 BOM (required for .NET
+// compatibility) unless it already has one.
+func writeXMLFile(path string, data []byte) (err error) {
+	file, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %w", err)
 	}
@@ -83,25 +138,18 @@ func (p *Project) Save() error {
 		}
 	}()
 
-	// Write UTF-8 BOM (required for .NET compatibility)
-	if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
-		return err
-	}
-
-	// Write XML declaration
-	if _, err := file.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n"); err != nil {
-		return err
-	}
-
-	// Write project XML
-	if _, err := file.Write(output); err != nil {
-		return err
+	if !bytes.HasPrefix(data, utf8BOM) {
+		if _, err := file.Write(utf8BOM); err != nil {
+			return err
+		}
 	}
-
-	p.modified = false
-	return nil
+	_, err = file.Write(data)
+	return err
 }
 
+// utf8BOM is the byte order mark .NET writes at the start of project files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
 // AddOrUpdatePackageReference adds a new PackageReference or updates an existing one.
 // Parameters:
 //   - id: Package ID
@@ -149,9 +197,7 @@ func (p *Project) addOrUpdateUnconditionalPackageReference(id, version string) (
 			pr := &ig.PackageReferences[j]
 			if strings.EqualFold(pr.Include, id) {
 				// Update existing reference
-				if version != "" {
-					pr.Version = version
-				}
+				setPackageReferenceVersion(pr, version)
 				p.modified = true
 				return true, nil
 			}
@@ -169,6 +215,40 @@ func (p *Project) addOrUpdateUnconditionalPackageReference(id, version string) (
 	return false, nil
 }
 
+// setPackageReferenceVersion updates the version of a reference where it's written: the
+// Version attribute, or the <Version> child element of a legacy project. An empty version
+// (CPM) leaves it unchanged.
+func setPackageReferenceVersion(pr *PackageReference, version string) {
+	switch {
+	case version == "":
+	case pr.Version == "" && pr.VersionElement != "":
+		pr.VersionElement = version
+	default:
+		pr.Version = version
+	}
+}
+
+// SetPackageVersionOverride sets the VersionOverride of every PackageReference for the
+// package, or removes it when version is empty. Returns true if a reference was found.
+func (p *Project) SetPackageVersionOverride(id, version string) bool {
+	found := false
+	for i := range p.Root.ItemGroups {
+		ig := &p.Root.ItemGroups[i]
+		for j := range ig.PackageReferences {
+			pr := &ig.PackageReferences[j]
+			if !strings.EqualFold(pr.Include, id) {
+				continue
+			}
+			found = true
+			if pr.VersionOverride != version {
+				pr.VersionOverride = version
+				p.modified = true
+			}
+		}
+	}
+	return found
+}
+
 // addOrUpdateConditionalPackageReference adds/updates conditional package references (one per framework).
 func (p *Project) addOrUpdateConditionalPackageReference(id, version string, frameworks []string) (bool, error) {
 	updated := false
@@ -191,9 +271,7 @@ func (p *Project) addOrUpdateConditionalPackageReference(id, version string, fra
 				pr := &ig.PackageReferences[j]
 				if strings.EqualFold(pr.Include, id) {
 					// Update existing reference
-					if version != "" {
-						pr.Version = version
-					}
+					setPackageReferenceVersion(pr, version)
 					p.modified = true
 					updated = true
 					found = true
@@ -219,28 +297,48 @@ func (p *Project) addOrUpdateConditionalPackageReference(id, version string, fra
 	return updated, nil
 }
 
-// RemovePackageReference removes a PackageReference by package ID.
-// Returns true if a reference was removed, false if not found.
+// RemovePackageReference removes the PackageReference items of a package ID from every
+// ItemGroup, conditional ones included. Returns true if a reference was removed, false if not found.
 func (p *Project) RemovePackageReference(id string) bool {
+	removed := false
 	for i := range p.Root.ItemGroups {
 		ig := &p.Root.ItemGroups[i]
-		for j := range ig.PackageReferences {
-			if strings.EqualFold(ig.PackageReferences[j].Include, id) {
-				// Remove reference
-				ig.PackageReferences = append(ig.PackageReferences[:j], ig.PackageReferences[j+1:]...)
-				p.modified = true
-				return true
+		kept := ig.PackageReferences[:0]
+		for _, ref := range ig.PackageReferences {
+			if strings.EqualFold(ref.Include, id) {
+				removed = true
+				continue
 			}
+			kept = append(kept, ref)
 		}
+		ig.PackageReferences = kept
 	}
-	return false
+	if removed {
+		p.modified = true
+	}
+	return removed
 }
 
 // GetPackageReferences returns all PackageReference elements in the project.
 func (p *Project) GetPackageReferences() []PackageReference {
 	var refs []PackageReference
 	for _, ig := range p.Root.ItemGroups {
-		refs = append(refs, ig.PackageReferences...)
+		for _, ref := range ig.PackageReferences {
+			// Legacy projects write the version as a child element
+			if ref.Version == "" {
+				ref.Version = strings.TrimSpace(ref.VersionElement)
+			}
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// GetProjectReferences returns all ProjectReference elements in the project.
+func (p *Project) GetProjectReferences() []Reference {
+	var refs []Reference
+	for _, ig := range p.Root.ItemGroups {
+		refs = append(refs, ig.ProjectReferences...)
 	}
 	return refs
 }
@@ -257,6 +355,104 @@ func (p *Project) IsCentralPackageManagementEnabled() bool {
 	return false
 }
 
+// IsNuGetAuditEnabled reports whether restore checks the project's packages for known
+// vulnerabilities. Like NuGet, auditing is enabled unless NuGetAudit is set to false.
+func (p *Project) IsNuGetAuditEnabled() bool {
+	for i := range p.Root.PropertyGroup {
+		pg := &p.Root.PropertyGroup[i]
+		if strings.EqualFold(strings.TrimSpace(pg.NuGetAudit), "false") {
+			return false
+		}
+	}
+	return true
+}
+
+// IsCentralPackageManagementDisabled reports whether the project sets
+// ManagePackageVersionsCentrally to false, opting out of the Central Package Management
+// its Directory.Packages.props enables for the rest of the repository.
+func (p *Project) IsCentralPackageManagementDisabled() bool {
+	for i := range p.Root.PropertyGroup {
+		pg := &p.Root.PropertyGroup[i]
+		if strings.EqualFold(strings.TrimSpace(pg.ManagePackageVersionsCentrally), "false") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCentralPackageVersionOverrideEnabled reports whether PackageReference items may use
+// VersionOverride. The CentralPackageVersionOverrideEnabled property of the project wins
+// over the one of props, its Directory.Packages.props (which may be nil); overrides are
+// enabled unless one of them sets it to false.
+func (p *Project) IsCentralPackageVersionOverrideEnabled(props *DirectoryPackagesProps) bool {
+	groups := [][]PropertyGroup{p.Root.PropertyGroup}
+	if props != nil {
+		groups = append(groups, props.Root.Properties)
+	}
+	for _, properties := range groups {
+		for _, pg := range properties {
+			if value := strings.TrimSpace(pg.CentralPackageVersionOverrideEnabled); value != "" {
+				return !strings.EqualFold(value, "false")
+			}
+		}
+	}
+	return true
+}
+
+// IsCentralPackageTransitivePinningEnabled reports whether the PackageVersion items of
+// props, its Directory.Packages.props, also pin the versions of transitive packages. The
+// CentralPackageTransitivePinningEnabled property of the project wins over the one of
+// props; pinning is disabled unless one of them sets it to true.
+func (p *Project) IsCentralPackageTransitivePinningEnabled(props *DirectoryPackagesProps) bool {
+	groups := [][]PropertyGroup{p.Root.PropertyGroup}
+	if props != nil {
+		groups = append(groups, props.Root.Properties)
+	}
+	for _, properties := range groups {
+		for _, pg := range properties {
+			if value := strings.TrimSpace(pg.CentralPackageTransitivePinningEnabled); value != "" {
+				return strings.EqualFold(value, "true")
+			}
+		}
+	}
+	return false
+}
+
+// GetGlobalPackageReferences returns the GlobalPackageReference items of the project's
+// Directory.Packages.props when Central Package Management is enabled by the project or
+// by that file. It returns nil when there is no Directory.Packages.props.
+func (p *Project) GetGlobalPackageReferences() ([]GlobalPackageReference, error) {
+	props, err := p.CentralPackageVersions()
+	if err != nil || props == nil {
+		return nil, err
+	}
+	return props.GetGlobalPackageReferences(), nil
+}
+
+// CentralPackageVersions returns the Directory.Packages.props that manages the versions
+// of the project's packages. It returns nil when there is no Directory.Packages.props,
+// when neither the project nor that file enables Central Package Management, or when the
+// project opts out of it.
+func (p *Project) CentralPackageVersions() (*DirectoryPackagesProps, error) {
+	if p.IsCentralPackageManagementDisabled() {
+		return nil, nil
+	}
+
+	propsPath := p.GetDirectoryPackagesPropsPath()
+	if _, err := os.Stat(propsPath); err != nil {
+		return nil, nil
+	}
+
+	props, err := LoadDirectoryPackagesProps(propsPath)
+	if err != nil {
+		return nil, err
+	}
+	if !p.IsCentralPackageManagementEnabled() && !props.IsCentralPackageManagementEnabled() {
+		return nil, nil
+	}
+	return props, nil
+}
+
 // GetDirectoryPackagesPropsPath returns the path to Directory.Packages.props.
 // It checks the DirectoryPackagesPropsPath property first, then walks up the directory tree.
 func (p *Project) GetDirectoryPackagesPropsPath() string {
@@ -293,7 +489,7 @@ func (p *Project) GetDirectoryPackagesPropsPath() string {
 
 // IsSDKStyle returns true if this is an SDK-style project.
 func (p *Project) IsSDKStyle() bool {
-	return p.Root.Sdk != ""
+	return p.Root.Sdk != "" || p.Style == StyleSDK
 }
 
 // FindProjectFile finds a single .csproj, .fsproj, or .vbproj file in the directory.
@@ -424,13 +620,14 @@ func validateFrameworkCompatibility(framework string) error {
 	return nil
 }
 
-// findOrCreateItemGroup finds an ItemGroup with the given condition or creates a new one.
+// findOrCreateItemGroup finds an ItemGroup with the given condition that already holds
+// PackageReference items or creates a new one, like dotnet add package.
 func (p *Project) findOrCreateItemGroup(condition string) *ItemGroup {
 	// Find existing ItemGroup with matching condition
 	normalizedCondition := normalizeCondition(condition)
 	for i := range p.Root.ItemGroups {
 		ig := &p.Root.ItemGroups[i]
-		if normalizeCondition(ig.Condition) == normalizedCondition {
+		if normalizeCondition(ig.Condition) == normalizedCondition && len(ig.PackageReferences) > 0 {
 			return ig
 		}
 	}
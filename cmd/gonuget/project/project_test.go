@@ -812,3 +812,238 @@ func TestIsMultiTargeting(t *testing.T) {
 		})
 	}
 }
+
+func TestGetProjectReferences_RestoreMetadataSurvivesSave(t *testing.T) {
+	tempDir := t.TempDir()
+	projectPath := filepath.Join(tempDir, "App.csproj")
+
+	projectXML := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <ProjectReference Include="../Lib/Lib.csproj" PrivateAssets="all" />
+    <ProjectReference Include="../Tool/Tool.csproj">
+      <ReferenceOutputAssembly>false</ReferenceOutputAssembly>
+    </ProjectReference>
+  </ItemGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(projectPath, []byte(projectXML), 0644))
+
+	proj, err := LoadProject(projectPath)
+	require.NoError(t, err)
+
+	refs := proj.GetProjectReferences()
+	require.Len(t, refs, 2)
+	assert.True(t, refs[0].IsPrivate())
+	assert.True(t, refs[1].IsBuildOrderOnly())
+
+	_, err = proj.AddOrUpdatePackageReference("Newtonsoft.Json", "13.0.3", nil)
+	require.NoError(t, err)
+	require.NoError(t, proj.Save())
+
+	reloaded, err := LoadProject(projectPath)
+	require.NoError(t, err)
+	refs = reloaded.GetProjectReferences()
+	require.Len(t, refs, 2)
+	assert.True(t, refs[0].IsPrivate())
+	assert.True(t, refs[1].IsBuildOrderOnly())
+}
+
+func TestGetGlobalPackageReferences_CentralPackageManagement(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectCPM  bool
+		propsCPM    bool
+		wantPackage bool
+	}{
+		{name: "enabled in Directory.Packages.props", propsCPM: true, wantPackage: true},
+		{name: "enabled in project", projectCPM: true, wantPackage: true},
+		{name: "not enabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			projDir := filepath.Join(root, "src", "App")
+			require.NoError(t, os.MkdirAll(projDir, 0755))
+
+			props := "<Project>\n"
+			if tt.propsCPM {
+				props += "  <PropertyGroup>\n    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n  </PropertyGroup>\n"
+			}
+			props += "  <ItemGroup>\n    <GlobalPackageReference Include=\"StyleCop.Analyzers\" Version=\"1.1.118\" />\n  </ItemGroup>\n</Project>"
+			require.NoError(t, os.WriteFile(filepath.Join(root, "Directory.Packages.props"), []byte(props), 0644))
+
+			projContent := "<Project Sdk=\"Microsoft.NET.Sdk\">\n  <PropertyGroup>\n    <TargetFramework>net8.0</TargetFramework>\n"
+			if tt.projectCPM {
+				projContent += "    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>\n"
+			}
+			projContent += "  </PropertyGroup>\n</Project>"
+			projPath := filepath.Join(projDir, "App.csproj")
+			require.NoError(t, os.WriteFile(projPath, []byte(projContent), 0644))
+
+			proj, err := LoadProject(projPath)
+			require.NoError(t, err)
+
+			refs, err := proj.GetGlobalPackageReferences()
+			require.NoError(t, err)
+			centralVersions, err := proj.CentralPackageVersions()
+			require.NoError(t, err)
+			if tt.wantPackage {
+				require.Len(t, refs, 1)
+				assert.Equal(t, "StyleCop.Analyzers", refs[0].Include)
+				assert.NotNil(t, centralVersions)
+			} else {
+				assert.Empty(t, refs)
+				assert.Nil(t, centralVersions)
+			}
+		})
+	}
+}
+
+func TestGetGlobalPackageReferences_NoDirectoryPackagesProps(t *testing.T) {
+	projPath := filepath.Join(t.TempDir(), "App.csproj")
+	require.NoError(t, os.WriteFile(projPath, []byte(`<Project Sdk="Microsoft.NET.Sdk" />`), 0644))
+
+	proj, err := LoadProject(projPath)
+	require.NoError(t, err)
+
+	refs, err := proj.GetGlobalPackageReferences()
+	require.NoError(t, err)
+	assert.Nil(t, refs)
+}
+
+func TestPackageReference_CentralPackageManagementAttributes(t *testing.T) {
+	projPath := filepath.Join(t.TempDir(), "App.csproj")
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" VersionOverride="4.0.0" />
+    <PackageReference Include="Microsoft.NETCore.App.Ref" Version="8.0.0" IsImplicitlyDefined="true" />
+  </ItemGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(projPath, []byte(content), 0644))
+
+	proj, err := LoadProject(projPath)
+	require.NoError(t, err)
+
+	refs := proj.GetPackageReferences()
+	require.Len(t, refs, 2)
+	assert.Equal(t, "", refs[0].Version)
+	assert.Equal(t, "4.0.0", refs[0].VersionOverride)
+	assert.Equal(t, "true", refs[1].IsImplicitlyDefined)
+}
+
+func TestCentralPackageVersions_ProjectOptOut(t *testing.T) {
+	root := t.TempDir()
+	props := `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(filepath.Join(root, "Directory.Packages.props"), []byte(props), 0644))
+
+	projPath := filepath.Join(root, "Legacy.csproj")
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>false</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(projPath, []byte(content), 0644))
+
+	proj, err := LoadProject(projPath)
+	require.NoError(t, err)
+	assert.True(t, proj.IsCentralPackageManagementDisabled())
+
+	centralVersions, err := proj.CentralPackageVersions()
+	require.NoError(t, err)
+	assert.Nil(t, centralVersions, "a project that opts out has no central versions")
+}
+
+func TestIsCentralPackageVersionOverrideEnabled(t *testing.T) {
+	property := func(value string) []PropertyGroup {
+		if value == "" {
+			return nil
+		}
+		return []PropertyGroup{{CentralPackageVersionOverrideEnabled: value}}
+	}
+
+	tests := []struct {
+		name    string
+		project string
+		props   string
+		want    bool
+	}{
+		{name: "not set", want: true},
+		{name: "disabled in Directory.Packages.props", props: "false", want: false},
+		{name: "disabled in project", project: "False", want: false},
+		{name: "project wins", project: "true", props: "false", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := &Project{Root: &RootElement{PropertyGroup: property(tt.project)}}
+			props := &DirectoryPackagesProps{Root: &DirectoryPackagesRootElement{Properties: property(tt.props)}}
+			assert.Equal(t, tt.want, proj.IsCentralPackageVersionOverrideEnabled(props))
+		})
+	}
+
+	proj := &Project{Root: &RootElement{}}
+	assert.True(t, proj.IsCentralPackageVersionOverrideEnabled(nil))
+}
+
+func TestIsCentralPackageTransitivePinningEnabled(t *testing.T) {
+	property := func(value string) []PropertyGroup {
+		if value == "" {
+			return nil
+		}
+		return []PropertyGroup{{CentralPackageTransitivePinningEnabled: value}}
+	}
+
+	tests := []struct {
+		name    string
+		project string
+		props   string
+		want    bool
+	}{
+		{name: "not set", want: false},
+		{name: "enabled in Directory.Packages.props", props: "true", want: true},
+		{name: "enabled in project", project: "True", want: true},
+		{name: "project wins", project: "false", props: "true", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := &Project{Root: &RootElement{PropertyGroup: property(tt.project)}}
+			props := &DirectoryPackagesProps{Root: &DirectoryPackagesRootElement{Properties: property(tt.props)}}
+			assert.Equal(t, tt.want, proj.IsCentralPackageTransitivePinningEnabled(props))
+		})
+	}
+
+	proj := &Project{Root: &RootElement{}}
+	assert.False(t, proj.IsCentralPackageTransitivePinningEnabled(nil))
+}
+
+func TestSetPackageVersionOverride(t *testing.T) {
+	projPath := filepath.Join(t.TempDir(), "App.csproj")
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" />
+  </ItemGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(projPath, []byte(content), 0644))
+
+	proj, err := LoadProject(projPath)
+	require.NoError(t, err)
+
+	assert.False(t, proj.SetPackageVersionOverride("Polly", "8.0.0"))
+	assert.True(t, proj.SetPackageVersionOverride("serilog", "4.0.0"))
+	require.NoError(t, proj.Save())
+
+	data, err := os.ReadFile(projPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<PackageReference Include="Serilog" VersionOverride="4.0.0" />`)
+
+	assert.True(t, proj.SetPackageVersionOverride("Serilog", ""))
+	assert.Empty(t, proj.GetPackageReferences()[0].VersionOverride)
+}
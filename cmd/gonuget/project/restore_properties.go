@@ -0,0 +1,112 @@
+package project
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RestoreProperties holds the restore-affecting MSBuild properties of a project.
+type RestoreProperties struct {
+	// PackagesPath is RestorePackagesPath as an absolute path (empty when not set)
+	PackagesPath string
+	// NoCache is RestoreNoCache: bypass the HTTP cache
+	NoCache bool
+	// IgnoreFailedSources is RestoreIgnoreFailedSources: unreachable sources are warnings
+	IgnoreFailedSources bool
+	// UseLockFile is RestorePackagesWithLockFile: write and honor packages.lock.json
+	UseLockFile bool
+	// LockFilePath is NuGetLockFilePath as an absolute path (empty when not set)
+	LockFilePath string
+	// LockedMode is RestoreLockedMode: fail instead of updating packages.lock.json
+	LockedMode bool
+}
+
+// GetRestoreProperties returns the restore properties of the project.
+// As in MSBuild evaluation, environment variables of the same name are the lowest precedence,
+// then the nearest Directory.Build.props, then the project itself. Relative paths are resolved
+// against the project directory, and $(MSBuildThisFileDirectory) and $(MSBuildProjectDirectory)
+// are expanded. Command-line overrides are applied by the caller.
+func (p *Project) GetRestoreProperties() RestoreProperties {
+	var props RestoreProperties
+	projectDir := filepath.Dir(p.Path)
+
+	applyRestoreProperties(&props, []PropertyGroup{{
+		RestorePackagesPath:         os.Getenv("RestorePackagesPath"),
+		RestoreNoCache:              os.Getenv("RestoreNoCache"),
+		RestoreIgnoreFailedSources:  os.Getenv("RestoreIgnoreFailedSources"),
+		RestorePackagesWithLockFile: os.Getenv("RestorePackagesWithLockFile"),
+		NuGetLockFilePath:           os.Getenv("NuGetLockFilePath"),
+		RestoreLockedMode:           os.Getenv("RestoreLockedMode"),
+	}}, projectDir, projectDir)
+
+	if propsPath := findDirectoryBuildProps(projectDir); propsPath != "" {
+		if data, err := os.ReadFile(propsPath); err == nil {
+			var root RootElement
+			if xml.Unmarshal(data, &root) == nil {
+				applyRestoreProperties(&props, root.PropertyGroup, projectDir, filepath.Dir(propsPath))
+			}
+		}
+	}
+
+	applyRestoreProperties(&props, p.Root.PropertyGroup, projectDir, projectDir)
+	return props
+}
+
+// applyRestoreProperties overlays the restore properties defined in groups onto props.
+// Later definitions win, as in MSBuild evaluation.
+func applyRestoreProperties(props *RestoreProperties, groups []PropertyGroup, projectDir, fileDir string) {
+	for i := range groups {
+		pg := &groups[i]
+		if pg.RestorePackagesPath != "" {
+			props.PackagesPath = resolvePropertyPath(pg.RestorePackagesPath, projectDir, fileDir)
+		}
+		if pg.RestoreNoCache != "" {
+			props.NoCache = strings.EqualFold(strings.TrimSpace(pg.RestoreNoCache), "true")
+		}
+		if pg.RestoreIgnoreFailedSources != "" {
+			props.IgnoreFailedSources = strings.EqualFold(strings.TrimSpace(pg.RestoreIgnoreFailedSources), "true")
+		}
+		if pg.RestorePackagesWithLockFile != "" {
+			props.UseLockFile = strings.EqualFold(strings.TrimSpace(pg.RestorePackagesWithLockFile), "true")
+		}
+		if pg.NuGetLockFilePath != "" {
+			props.LockFilePath = resolvePropertyPath(pg.NuGetLockFilePath, projectDir, fileDir)
+		}
+		if pg.RestoreLockedMode != "" {
+			props.LockedMode = strings.EqualFold(strings.TrimSpace(pg.RestoreLockedMode), "true")
+		}
+	}
+}
+
+// resolvePropertyPath expands the MSBuild directory properties in a path value and makes it absolute.
+func resolvePropertyPath(value, projectDir, fileDir string) string {
+	value = strings.TrimSpace(value)
+	value = strings.ReplaceAll(value, "$(MSBuildThisFileDirectory)", fileDir+string(filepath.Separator))
+	value = strings.ReplaceAll(value, "$(MSBuildProjectDirectory)", projectDir)
+	value = filepath.FromSlash(strings.ReplaceAll(value, `\`, "/"))
+
+	if !filepath.IsAbs(value) {
+		value = filepath.Join(projectDir, value)
+	}
+	return filepath.Clean(value)
+}
+
+// findDirectoryBuildProps walks up from dir to the nearest Directory.Build.props.
+// Returns an empty string if there is none.
+func findDirectoryBuildProps(dir string) string {
+	current := dir
+	for {
+		propsPath := filepath.Join(current, "Directory.Build.props")
+		if _, err := os.Stat(propsPath); err == nil {
+			return propsPath
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return ""
+		}
+		current = parent
+	}
+}
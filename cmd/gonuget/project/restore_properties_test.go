@@ -0,0 +1,108 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRestorePropsProject(t *testing.T, dir, properties string) *Project {
+	t.Helper()
+
+	projectPath := filepath.Join(dir, "Test.csproj")
+	projectXML := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    ` + properties + `
+  </PropertyGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(projectPath, []byte(projectXML), 0644))
+
+	proj, err := LoadProject(projectPath)
+	require.NoError(t, err)
+	return proj
+}
+
+func TestGetRestoreProperties_Defaults(t *testing.T) {
+	proj := writeRestorePropsProject(t, t.TempDir(), "")
+
+	assert.Equal(t, RestoreProperties{}, proj.GetRestoreProperties())
+}
+
+func TestGetRestoreProperties_PackagesPath(t *testing.T) {
+	tempDir := t.TempDir()
+	proj := writeRestorePropsProject(t, tempDir, `<RestorePackagesPath>packages</RestorePackagesPath>`)
+
+	props := proj.GetRestoreProperties()
+	assert.Equal(t, filepath.Join(tempDir, "packages"), props.PackagesPath)
+	assert.False(t, props.NoCache)
+	assert.False(t, props.IgnoreFailedSources)
+}
+
+func TestGetRestoreProperties_NoCache(t *testing.T) {
+	proj := writeRestorePropsProject(t, t.TempDir(), `<RestoreNoCache>True</RestoreNoCache>`)
+
+	props := proj.GetRestoreProperties()
+	assert.True(t, props.NoCache)
+	assert.False(t, props.IgnoreFailedSources)
+	assert.Empty(t, props.PackagesPath)
+}
+
+func TestGetRestoreProperties_IgnoreFailedSources(t *testing.T) {
+	proj := writeRestorePropsProject(t, t.TempDir(), `<RestoreIgnoreFailedSources>true</RestoreIgnoreFailedSources>`)
+
+	props := proj.GetRestoreProperties()
+	assert.True(t, props.IgnoreFailedSources)
+	assert.False(t, props.NoCache)
+}
+
+func TestGetRestoreProperties_LockFile(t *testing.T) {
+	tempDir := t.TempDir()
+	proj := writeRestorePropsProject(t, tempDir, `<RestorePackagesWithLockFile>true</RestorePackagesWithLockFile>
+    <NuGetLockFilePath>$(MSBuildProjectDirectory)/locks/app.lock.json</NuGetLockFilePath>
+    <RestoreLockedMode>true</RestoreLockedMode>`)
+
+	props := proj.GetRestoreProperties()
+	assert.True(t, props.UseLockFile)
+	assert.True(t, props.LockedMode)
+	assert.Equal(t, filepath.Join(tempDir, "locks", "app.lock.json"), props.LockFilePath)
+}
+
+func TestGetRestoreProperties_DirectoryBuildProps(t *testing.T) {
+	rootDir := t.TempDir()
+	projectDir := filepath.Join(rootDir, "src", "app")
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+
+	buildProps := `<Project>
+  <PropertyGroup>
+    <RestorePackagesPath>$(MSBuildThisFileDirectory)packages</RestorePackagesPath>
+    <RestoreNoCache>true</RestoreNoCache>
+    <RestoreIgnoreFailedSources>true</RestoreIgnoreFailedSources>
+  </PropertyGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "Directory.Build.props"), []byte(buildProps), 0644))
+
+	// The project overrides one of the inherited values
+	proj := writeRestorePropsProject(t, projectDir, `<RestoreNoCache>false</RestoreNoCache>`)
+
+	props := proj.GetRestoreProperties()
+	assert.Equal(t, filepath.Join(rootDir, "packages"), props.PackagesPath)
+	assert.False(t, props.NoCache)
+	assert.True(t, props.IgnoreFailedSources)
+}
+
+func TestGetRestoreProperties_Environment(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("RestoreNoCache", "true")
+	t.Setenv("RestorePackagesPath", "env-packages")
+
+	// Environment variables are the lowest precedence
+	proj := writeRestorePropsProject(t, tempDir, `<RestorePackagesPath>project-packages</RestorePackagesPath>`)
+
+	props := proj.GetRestoreProperties()
+	assert.True(t, props.NoCache)
+	assert.Equal(t, filepath.Join(tempDir, "project-packages"), props.PackagesPath)
+}
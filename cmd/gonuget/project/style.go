@@ -0,0 +1,184 @@
+package project
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/gonuget/frameworks"
+)
+
+// Style is how a project restores its packages, detected by LoadProject.
+type Style int
+
+const (
+	// StyleLegacy is a non-SDK project whose PackageReference items are restored
+	StyleLegacy Style = iota
+	// StyleSDK is an SDK-style project of any language (.csproj, .fsproj, .vbproj)
+	StyleSDK
+	// StylePackagesConfig is a non-SDK project whose packages are listed in packages.config
+	StylePackagesConfig
+)
+
+// String describes the style for messages
+func (s Style) String() string {
+	switch s {
+	case StyleSDK:
+		return "SDK-style project"
+	case StylePackagesConfig:
+		return "packages.config project"
+	default:
+		return "legacy project with PackageReference"
+	}
+}
+
+// UnsupportedProjectError is returned by LoadProject for a file gonuget can't restore,
+// naming what was detected and what to do about it.
+type UnsupportedProjectError struct {
+	Path     string
+	Detected string
+	Action   string
+}
+
+func (e *UnsupportedProjectError) Error() string {
+	return fmt.Sprintf("'%s' is not a supported project: detected %s. %s", e.Path, e.Detected, e.Action)
+}
+
+// projectShape is the part of a project file that tells its style
+type projectShape struct {
+	XMLName     xml.Name
+	Sdk         string `xml:"Sdk,attr"`
+	SdkElements []struct {
+		Name string `xml:"Name,attr"`
+	} `xml:"Sdk"`
+	Imports []struct {
+		Sdk string `xml:"Sdk,attr"`
+	} `xml:"Import"`
+	PropertyGroups []struct {
+		TargetFrameworkIdentifier string `xml:"TargetFrameworkIdentifier"`
+		TargetFrameworkVersion    string `xml:"TargetFrameworkVersion"`
+		RestoreProjectStyle       string `xml:"RestoreProjectStyle"`
+	} `xml:"PropertyGroup"`
+}
+
+// isSDK reports whether the project uses an SDK, through the Sdk attribute, an <Sdk>
+// element or an <Import Sdk="..."/>
+func (s *projectShape) isSDK() bool {
+	if s.Sdk != "" {
+		return true
+	}
+	for _, sdk := range s.SdkElements {
+		if sdk.Name != "" {
+			return true
+		}
+	}
+	for _, imp := range s.Imports {
+		if imp.Sdk != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// property returns the last value of a property, like MSBuild's evaluation without conditions
+func (s *projectShape) property(get func(i int) string) string {
+	var value string
+	for i := range s.PropertyGroups {
+		if v := strings.TrimSpace(get(i)); v != "" {
+			value = v
+		}
+	}
+	return value
+}
+
+// detectStyle reads the shape of the project in data and returns its style. A legacy
+// project without TargetFramework gets the framework of its TargetFrameworkVersion.
+// Reference: NuGet.targets _GetRestoreProjectStyle
+func detectStyle(proj *Project, data []byte) error {
+	var shape projectShape
+	if err := xml.Unmarshal(data, &shape); err != nil {
+		return fmt.Errorf("failed to parse project XML: %w", err)
+	}
+	if shape.XMLName.Local != "Project" {
+		return &UnsupportedProjectError{
+			Path:     proj.Path,
+			Detected: fmt.Sprintf("an XML file whose root element is <%s>", shape.XMLName.Local),
+			Action:   "MSBuild project files (.csproj, .fsproj, .vbproj) have a <Project> root element.",
+		}
+	}
+
+	if shape.isSDK() {
+		proj.Style = StyleSDK
+		return nil
+	}
+
+	restoreStyle := shape.property(func(i int) string { return shape.PropertyGroups[i].RestoreProjectStyle })
+	switch {
+	case strings.EqualFold(restoreStyle, "PackageReference") || len(proj.GetPackageReferences()) > 0:
+		proj.Style = StyleLegacy
+	case strings.EqualFold(restoreStyle, "PackagesConfig") || proj.PackagesConfigPath() != "":
+		proj.Style = StylePackagesConfig
+		return nil
+	default:
+		proj.Style = StyleLegacy
+	}
+
+	if proj.TargetFramework != "" || len(proj.TargetFrameworks) > 0 {
+		return nil
+	}
+
+	identifier := shape.property(func(i int) string { return shape.PropertyGroups[i].TargetFrameworkIdentifier })
+	frameworkVersion := shape.property(func(i int) string { return shape.PropertyGroups[i].TargetFrameworkVersion })
+	if identifier != "" && !strings.EqualFold(identifier, ".NETFramework") {
+		return &UnsupportedProjectError{
+			Path:     proj.Path,
+			Detected: fmt.Sprintf("a legacy (non-SDK) project targeting %s %s", identifier, frameworkVersion),
+			Action:   "Only legacy projects targeting .NETFramework can be restored; convert the project to an SDK-style project.",
+		}
+	}
+	if frameworkVersion == "" {
+		return nil
+	}
+
+	tfm, ok := frameworkFromVersion(frameworkVersion)
+	if !ok {
+		return &UnsupportedProjectError{
+			Path:     proj.Path,
+			Detected: fmt.Sprintf("a legacy (non-SDK) project with TargetFrameworkVersion '%s'", frameworkVersion),
+			Action:   "Set TargetFrameworkVersion to a .NET Framework version such as v4.7.2, or convert the project to an SDK-style project.",
+		}
+	}
+	proj.TargetFramework = tfm
+	return nil
+}
+
+// frameworkFromVersion maps a .NET Framework TargetFrameworkVersion such as v4.7.2 to
+// its folder name, net472
+func frameworkFromVersion(frameworkVersion string) (string, bool) {
+	digits := strings.ReplaceAll(strings.TrimPrefix(strings.ToLower(frameworkVersion), "v"), ".", "")
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return "", false
+	}
+	tfm := "net" + digits
+	fw, err := frameworks.ParseFramework(tfm)
+	if err != nil || fw.Framework != ".NETFramework" {
+		return "", false
+	}
+	return tfm, true
+}
+
+// PackagesConfigPath returns the packages.config file of the project: packages.<project
+// name>.config when it exists, otherwise packages.config. Returns "" when neither exists.
+func (p *Project) PackagesConfigPath() string {
+	dir := filepath.Dir(p.Path)
+	name := strings.TrimSuffix(filepath.Base(p.Path), filepath.Ext(p.Path))
+	for _, file := range []string{"packages." + name + ".config", "packages.config"} {
+		path := filepath.Join(dir, file)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
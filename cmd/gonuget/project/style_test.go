@@ -0,0 +1,111 @@
+package project
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProject_Styles(t *testing.T) {
+	tests := []struct {
+		path       string
+		style      Style
+		frameworks []string
+		packages   map[string]string
+	}{
+		{"sdk/App.csproj", StyleSDK, []string{"net8.0"}, map[string]string{"Newtonsoft.Json": "13.0.3"}},
+		{"sdk/Lib.fsproj", StyleSDK, []string{"net8.0", "netstandard2.0"}, map[string]string{"FSharp.Core": "8.0.400"}},
+		{"sdk/Tool.vbproj", StyleSDK, []string{"net8.0"}, map[string]string{"System.CommandLine": "2.0.0-beta4.22272.1"}},
+		{"sdk/Imported.csproj", StyleSDK, []string{"net8.0"}, map[string]string{}},
+		{"legacy-packagereference/Legacy.csproj", StyleLegacy, []string{"net472"}, map[string]string{"Newtonsoft.Json": "13.0.3", "Serilog": "3.1.1"}},
+		{"legacy-packagesconfig/Legacy.vbproj", StylePackagesConfig, []string{}, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			proj, err := LoadProject(filepath.Join("testdata", "styles", tt.path))
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.style, proj.Style)
+			assert.Equal(t, tt.style == StyleSDK, proj.IsSDKStyle())
+			assert.Equal(t, tt.frameworks, proj.GetTargetFrameworks())
+
+			packages := map[string]string{}
+			for _, ref := range proj.GetPackageReferences() {
+				packages[ref.Include] = ref.Version
+			}
+			assert.Equal(t, tt.packages, packages)
+		})
+	}
+}
+
+func TestLoadProject_Unsupported(t *testing.T) {
+	tests := []struct {
+		path     string
+		detected string
+	}{
+		{"NotAProject.csproj", "detected an XML file whose root element is <configuration>"},
+		{"Portable.csproj", "detected a legacy (non-SDK) project targeting .NETPortable v4.5"},
+		{"BadVersion.csproj", "detected a legacy (non-SDK) project with TargetFrameworkVersion '$(FrameworkVersion)'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path := filepath.Join("testdata", "styles", "unsupported", tt.path)
+			_, err := LoadProject(path)
+
+			var unsupported *UnsupportedProjectError
+			require.True(t, errors.As(err, &unsupported), "error = %v, want *UnsupportedProjectError", err)
+			assert.Contains(t, err.Error(), "'"+path+"' is not a supported project: "+tt.detected)
+		})
+	}
+}
+
+func TestLoadProject_PackagesConfigForProjectName(t *testing.T) {
+	dir := t.TempDir()
+	projectPath := filepath.Join(dir, "Legacy.csproj")
+	require.NoError(t, os.WriteFile(projectPath, []byte(`<Project ToolsVersion="4.0"><PropertyGroup><TargetFrameworkVersion>v4.5</TargetFrameworkVersion></PropertyGroup></Project>`), 0644))
+
+	// Without packages.config the legacy project restores nothing through PackageReference
+	proj, err := LoadProject(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, StyleLegacy, proj.Style)
+	assert.Equal(t, "net45", proj.TargetFramework)
+
+	configPath := filepath.Join(dir, "packages.Legacy.config")
+	require.NoError(t, os.WriteFile(configPath, []byte(`<packages><package id="A" version="1.0.0" /></packages>`), 0644))
+	proj, err = LoadProject(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, StylePackagesConfig, proj.Style)
+	assert.Equal(t, configPath, proj.PackagesConfigPath())
+
+	entries, err := LoadPackagesConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []PackagesConfigEntry{{ID: "A", Version: "1.0.0"}}, entries)
+}
+
+func TestFrameworkFromVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		ok      bool
+	}{
+		{"v4.7.2", "net472", true},
+		{"v4.0", "net40", true},
+		{"v3.5", "net35", true},
+		{"v4.8.1", "net481", true},
+		{"4.6.1", "net461", true},
+		{"v", "", false},
+		{"$(FrameworkVersion)", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := frameworkFromVersion(tt.version)
+		assert.Equal(t, tt.want, got, tt.version)
+		assert.Equal(t, tt.ok, ok, tt.version)
+	}
+}
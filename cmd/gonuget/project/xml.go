@@ -1,6 +1,9 @@
 package project
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"strings"
+)
 
 // RootElement represents the root <Project> element of a .csproj file.
 type RootElement struct {
@@ -13,14 +16,23 @@ type RootElement struct {
 
 // PropertyGroup represents a <PropertyGroup> element.
 type PropertyGroup struct {
-	Condition                      string `xml:"Condition,attr,omitempty"`
-	TargetFramework                string `xml:"TargetFramework,omitempty"`
-	TargetFrameworks               string `xml:"TargetFrameworks,omitempty"`
-	OutputType                     string `xml:"OutputType,omitempty"`
-	RootNamespace                  string `xml:"RootNamespace,omitempty"`
-	AssemblyName                   string `xml:"AssemblyName,omitempty"`
-	ManagePackageVersionsCentrally string `xml:"ManagePackageVersionsCentrally,omitempty"`
-	DirectoryPackagesPropsPath     string `xml:"DirectoryPackagesPropsPath,omitempty"`
+	Condition                              string `xml:"Condition,attr,omitempty"`
+	TargetFramework                        string `xml:"TargetFramework,omitempty"`
+	TargetFrameworks                       string `xml:"TargetFrameworks,omitempty"`
+	OutputType                             string `xml:"OutputType,omitempty"`
+	RootNamespace                          string `xml:"RootNamespace,omitempty"`
+	AssemblyName                           string `xml:"AssemblyName,omitempty"`
+	ManagePackageVersionsCentrally         string `xml:"ManagePackageVersionsCentrally,omitempty"`
+	CentralPackageVersionOverrideEnabled   string `xml:"CentralPackageVersionOverrideEnabled,omitempty"`
+	CentralPackageTransitivePinningEnabled string `xml:"CentralPackageTransitivePinningEnabled,omitempty"`
+	DirectoryPackagesPropsPath             string `xml:"DirectoryPackagesPropsPath,omitempty"`
+	RestorePackagesPath                    string `xml:"RestorePackagesPath,omitempty"`
+	RestoreNoCache                         string `xml:"RestoreNoCache,omitempty"`
+	RestoreIgnoreFailedSources             string `xml:"RestoreIgnoreFailedSources,omitempty"`
+	RestorePackagesWithLockFile            string `xml:"RestorePackagesWithLockFile,omitempty"`
+	NuGetLockFilePath                      string `xml:"NuGetLockFilePath,omitempty"`
+	RestoreLockedMode                      string `xml:"RestoreLockedMode,omitempty"`
+	NuGetAudit                             string `xml:"NuGetAudit,omitempty"`
 }
 
 // ItemGroup represents an <ItemGroup> element containing package references or other items.
@@ -40,12 +52,62 @@ type PackageReference struct {
 	IncludeAssets        string `xml:"IncludeAssets,attr,omitempty"`
 	ExcludeAssets        string `xml:"ExcludeAssets,attr,omitempty"`
 	GeneratePathProperty string `xml:"GeneratePathProperty,attr,omitempty"`
+	// VersionOverride replaces the central version of the package for this project
+	// under Central Package Management
+	VersionOverride string `xml:"VersionOverride,attr,omitempty"`
+	// IsImplicitlyDefined marks a reference added by the SDK rather than the user; it
+	// keeps its own version under Central Package Management
+	IsImplicitlyDefined string `xml:"IsImplicitlyDefined,attr,omitempty"`
+	// VersionElement is the version written as a child element, as legacy projects do;
+	// GetPackageReferences returns it as Version
+	VersionElement string `xml:"Version,omitempty"`
 }
 
 // Reference represents a <ProjectReference> element (references to other projects).
 // Named Reference rather than ProjectReference to avoid package name stuttering.
+// Restore metadata may be written as an attribute or as a child element; both are kept
+// so saving the project round-trips them.
 type Reference struct {
 	Include string `xml:"Include,attr"`
+	// Restore metadata as attributes
+	PrivateAssetsAttr           string `xml:"PrivateAssets,attr,omitempty"`
+	ReferenceOutputAssemblyAttr string `xml:"ReferenceOutputAssembly,attr,omitempty"`
+	// Restore metadata as child elements
+	PrivateAssetsElement           string `xml:"PrivateAssets,omitempty"`
+	ReferenceOutputAssemblyElement string `xml:"ReferenceOutputAssembly,omitempty"`
+}
+
+// PrivateAssets returns the PrivateAssets metadata; the child element wins over the attribute.
+func (r Reference) PrivateAssets() string {
+	if r.PrivateAssetsElement != "" {
+		return strings.TrimSpace(r.PrivateAssetsElement)
+	}
+	return strings.TrimSpace(r.PrivateAssetsAttr)
+}
+
+// ReferenceOutputAssembly returns the ReferenceOutputAssembly metadata; the child element wins over the attribute.
+func (r Reference) ReferenceOutputAssembly() string {
+	if r.ReferenceOutputAssemblyElement != "" {
+		return strings.TrimSpace(r.ReferenceOutputAssemblyElement)
+	}
+	return strings.TrimSpace(r.ReferenceOutputAssemblyAttr)
+}
+
+// IsBuildOrderOnly reports whether the reference only orders the build
+// (ReferenceOutputAssembly="false"). Restore ignores such references entirely.
+func (r Reference) IsBuildOrderOnly() bool {
+	return strings.EqualFold(r.ReferenceOutputAssembly(), "false")
+}
+
+// IsPrivate reports whether PrivateAssets="all" keeps the referenced project's
+// dependencies from flowing to projects that reference this one.
+func (r Reference) IsPrivate() bool {
+	for asset := range strings.SplitSeq(r.PrivateAssets(), ";") {
+		if strings.EqualFold(strings.TrimSpace(asset), "all") {
+			return true
+		}
+	}
+	return false
 }
 
 // AssemblyReference represents a <Reference> element (legacy .NET Framework assembly references).
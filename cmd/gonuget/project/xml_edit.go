@@ -0,0 +1,541 @@
+package project
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Saving a loaded project or Directory.Packages.props file edits the XML it was read
+// from rather than marshaling the structs again, so comments, whitespace, attribute order
+// and the elements the structs don't model are kept. Only the items of one kind
+// (PackageReference, PackageVersion) are compared with the loaded XML, and only their
+// differences are written, the way MSBuild's ProjectRootElement preserves formatting.
+
+// itemGroupEdit is the wanted content of the nth ItemGroup of a document: its items of the
+// kind being edited. Groups past the ones the document has are added to it.
+type itemGroupEdit[T any] struct {
+	condition string // Condition of a group added to the document
+	items     []T
+}
+
+// rawElement is an element of the loaded XML and its byte offsets.
+type rawElement struct {
+	name        string // Qualified name as written
+	attrs       []xml.Attr
+	start       int // The '<' of the start tag
+	startTagEnd int // After the '>' of the start tag
+	endTagStart int // The '<' of the end tag, or the "/>" of a self-closing element
+	end         int // After the element
+	selfClosing bool
+	children    []*rawElement
+}
+
+// childrenNamed returns the child elements with the given local name.
+func (e *rawElement) childrenNamed(name string) []*rawElement {
+	var named []*rawElement
+	for _, child := range e.children {
+		if localName(child.name) == name {
+			named = append(named, child)
+		}
+	}
+	return named
+}
+
+// xmlEdit replaces data[start:end] with text.
+type xmlEdit struct {
+	start, end int
+	text       string
+}
+
+// attrPattern matches an attribute of a start tag with its leading whitespace.
+var attrPattern = regexp.MustCompile(`\s+([^\s=/>]+)\s*=\s*("[^"]*"|'[^']*')`)
+
+// xmlEscaper escapes attribute values and text. Unlike xml.EscapeText it leaves the
+// quotes of MSBuild conditions ('$(TargetFramework)' == 'net8.0') readable.
+var xmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// editItems rewrites the itemName items of the ItemGroups of data to match groups, which
+// list the groups in document order. Items are matched to the ones of the same group by
+// ID: a matched item gets only the attributes that changed rewritten, items that are gone
+// are removed with their line (and their group when it's left empty), and new items are
+// added after the last element of their group with the indentation of its items. New
+// groups go after the last ItemGroup, or after the last PropertyGroup of a document
+// without any.
+func editItems[T any](data []byte, itemName string, groups []itemGroupEdit[T], id func(T) string) ([]byte, error) {
+	root, err := parseRawElements(data)
+	if err != nil {
+		return nil, err
+	}
+
+	newline := "\n"
+	if bytes.Contains(data, []byte("\r\n")) {
+		newline = "\r\n"
+	}
+	unit := "  "
+	if len(root.children) > 0 {
+		if indent := lineIndent(data, root.children[0].start); indent != "" {
+			unit = indent
+		}
+	}
+
+	var edits []xmlEdit
+	rawGroups := root.childrenNamed("ItemGroup")
+	var added strings.Builder
+	for i, group := range groups {
+		if i < len(rawGroups) {
+			groupEdits, err := editItemGroup(data, rawGroups[i], itemName, group.items, id, unit, newline)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, groupEdits...)
+			continue
+		}
+		if len(group.items) == 0 {
+			continue
+		}
+
+		added.WriteString(newline + unit + "<ItemGroup")
+		if group.condition != "" {
+			added.WriteString(` Condition="` + xmlEscaper.Replace(group.condition) + `"`)
+		}
+		added.WriteString(">")
+		for _, item := range group.items {
+			wanted, err := marshalItem(item)
+			if err != nil {
+				return nil, err
+			}
+			added.WriteString(newline + unit + unit + wanted.render(unit+unit, unit, newline))
+		}
+		added.WriteString(newline + unit + "</ItemGroup>")
+	}
+
+	if added.Len() > 0 {
+		anchor := lastElement(root.children, "ItemGroup")
+		if anchor == nil {
+			anchor = lastElement(root.children, "PropertyGroup")
+		}
+		if anchor == nil && len(root.children) > 0 {
+			anchor = root.children[len(root.children)-1]
+		}
+		if anchor == nil {
+			edits = append(edits, appendChildren(data, root, added.String(), newline))
+		} else {
+			// Separate the new groups the way the anchor is separated from what precedes it
+			text := added.String()
+			if strings.Count(leadingSpace(data, anchor.start), "\n") > 1 {
+				text = newline + text
+			}
+			edits = append(edits, xmlEdit{start: anchor.end, end: anchor.end, text: text})
+		}
+	}
+
+	return applyEdits(data, edits)
+}
+
+// editItemGroup returns the edits that make the itemName items of group match items.
+func editItemGroup[T any](data []byte, group *rawElement, itemName string, items []T, id func(T) string, unit, newline string) ([]xmlEdit, error) {
+	rawItems := group.childrenNamed(itemName)
+	matched := make([]bool, len(rawItems))
+	var edits []xmlEdit
+	var added []T
+
+	for _, item := range items {
+		index := -1
+		for i, raw := range rawItems {
+			if !matched[i] && strings.EqualFold(attrValue(raw.attrs, "Include"), id(item)) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			added = append(added, item)
+			continue
+		}
+		matched[index] = true
+
+		itemEdits, err := editItem(data, rawItems[index], item, unit, newline)
+		if err != nil {
+			return nil, err
+		}
+		edits = append(edits, itemEdits...)
+	}
+
+	var removed []*rawElement
+	for i, raw := range rawItems {
+		if !matched[i] {
+			removed = append(removed, raw)
+			edits = append(edits, removeElement(data, raw))
+		}
+	}
+
+	// A group the removals leave empty goes as well, as MSBuild removes it, unless it
+	// holds something else such as a comment
+	if len(items) == 0 && len(removed) > 0 && len(removed) == len(group.children) {
+		rest := data[group.startTagEnd:group.endTagStart]
+		for i := len(removed) - 1; i >= 0; i-- {
+			rest = slices.Concat(rest[:removed[i].start-group.startTagEnd], rest[removed[i].end-group.startTagEnd:])
+		}
+		if len(bytes.TrimSpace(rest)) == 0 {
+			edit := removeElement(data, group)
+			// Of the blank lines around the group, only the one after it is kept
+			before := leadingSpace(data, group.start)
+			after := data[group.end:]
+			after = after[:len(after)-len(bytes.TrimLeft(after, " \t\r\n"))]
+			if strings.Count(before, "\n") > 1 && bytes.Count(after, []byte("\n")) > 1 {
+				edit.start = group.start - len(before)
+			}
+			return []xmlEdit{edit}, nil
+		}
+	}
+
+	if len(added) == 0 {
+		return edits, nil
+	}
+
+	indent := lineIndent(data, group.start) + unit
+	if len(group.children) > 0 {
+		indent = lineIndent(data, group.children[len(group.children)-1].start)
+	}
+	var text strings.Builder
+	for _, item := range added {
+		wanted, err := marshalItem(item)
+		if err != nil {
+			return nil, err
+		}
+		text.WriteString(newline + indent + wanted.render(indent, unit, newline))
+	}
+	return append(edits, appendChildren(data, group, text.String(), newline)), nil
+}
+
+// editItem returns the edits that make the raw item match item. Changed attributes are
+// rewritten in place and new ones are added after the last attribute; attributes the
+// struct doesn't model are left alone. A child element such as a legacy <Version> gets its
+// text replaced; other changes to child elements rewrite the whole item.
+func editItem[T any](data []byte, raw *rawElement, item T, unit, newline string) ([]xmlEdit, error) {
+	var loaded T
+	if err := xml.Unmarshal(data[raw.start:raw.end], &loaded); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", raw.name, err)
+	}
+	original, err := marshalItem(loaded)
+	if err != nil {
+		return nil, err
+	}
+	wanted, err := marshalItem(item)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []xmlEdit
+	for _, name := range unionNames(original.children, wanted.children) {
+		before, hadChild := attrLookup(original.children, name)
+		after, hasChild := attrLookup(wanted.children, name)
+		if before == after && hadChild == hasChild {
+			continue
+		}
+		children := raw.childrenNamed(name)
+		if len(children) != 1 || children[0].selfClosing || len(children[0].children) > 0 {
+			// Rewrite the whole item
+			indent := lineIndent(data, raw.start)
+			return []xmlEdit{{start: raw.start, end: raw.end, text: wanted.render(indent, unit, newline)}}, nil
+		}
+		if hasChild {
+			edits = append(edits, xmlEdit{start: children[0].startTagEnd, end: children[0].endTagStart, text: xmlEscaper.Replace(after)})
+		} else {
+			edits = append(edits, removeElement(data, children[0]))
+		}
+	}
+
+	tag := string(data[raw.start:raw.startTagEnd])
+	spans := attrPattern.FindAllStringSubmatchIndex(tag, -1)
+	insertAt := raw.start + 1 + len(raw.name)
+	if len(spans) > 0 {
+		insertAt = raw.start + spans[len(spans)-1][1]
+	}
+	var inserted strings.Builder
+	for _, name := range unionNames(original.attrs, wanted.attrs) {
+		before, hadAttr := attrLookup(original.attrs, name)
+		after, hasAttr := attrLookup(wanted.attrs, name)
+		if before == after && hadAttr == hasAttr {
+			continue
+		}
+
+		span := slices.IndexFunc(spans, func(s []int) bool { return tag[s[2]:s[3]] == name })
+		switch {
+		case span < 0 && hasAttr:
+			inserted.WriteString(" " + name + `="` + xmlEscaper.Replace(after) + `"`)
+		case span < 0:
+			// Already absent from the XML
+		case hasAttr:
+			// Keep the quote character
+			valueStart, valueEnd := raw.start+spans[span][4]+1, raw.start+spans[span][5]-1
+			edits = append(edits, xmlEdit{start: valueStart, end: valueEnd, text: xmlEscaper.Replace(after)})
+		default:
+			edits = append(edits, xmlEdit{start: raw.start + spans[span][0], end: raw.start + spans[span][1]})
+		}
+	}
+	if inserted.Len() > 0 {
+		edits = append(edits, xmlEdit{start: insertAt, end: insertAt, text: inserted.String()})
+	}
+	return edits, nil
+}
+
+// parseRawElements returns the root element of data with all of its descendants.
+func parseRawElements(data []byte) (*rawElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var root *rawElement
+	var open []*rawElement
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := &rawElement{
+				name:        qualifiedName(t.Name),
+				attrs:       t.Attr,
+				start:       offset,
+				startTagEnd: int(decoder.InputOffset()),
+			}
+			element.selfClosing = bytes.HasSuffix(data[:element.startTagEnd], []byte("/>"))
+			if len(open) > 0 {
+				parent := open[len(open)-1]
+				parent.children = append(parent.children, element)
+			} else if root == nil {
+				root = element
+			}
+			open = append(open, element)
+		case xml.EndElement:
+			if len(open) == 0 {
+				return nil, fmt.Errorf("failed to parse XML: unexpected end element </%s>", qualifiedName(t.Name))
+			}
+			element := open[len(open)-1]
+			open = open[:len(open)-1]
+			if element.selfClosing {
+				element.endTagStart = element.startTagEnd - len("/>")
+				element.end = element.startTagEnd
+			} else {
+				element.endTagStart = offset
+				element.end = int(decoder.InputOffset())
+			}
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("failed to parse XML: no root element")
+	}
+	return root, nil
+}
+
+// appendChildren returns the edit that adds text, child elements each starting with a
+// newline and their indentation, after the last content of element.
+func appendChildren(data []byte, element *rawElement, text, newline string) xmlEdit {
+	closing := newline + lineIndent(data, element.start)
+	if element.selfClosing {
+		tag := strings.TrimRight(string(data[element.start:element.endTagStart]), " \t")
+		return xmlEdit{start: element.start, end: element.end, text: tag + ">" + text + closing + "</" + element.name + ">"}
+	}
+
+	// Keep the whitespace before the end tag when it puts the end tag on its own line
+	pos := element.endTagStart
+	for pos > element.startTagEnd && isXMLSpace(data[pos-1]) {
+		pos--
+	}
+	if trailing := string(data[pos:element.endTagStart]); strings.Contains(trailing, "\n") {
+		closing = trailing
+	}
+	return xmlEdit{start: pos, end: element.endTagStart, text: text + closing}
+}
+
+// removeElement returns the edit that removes element, with the line break and
+// indentation before it when it's on a line of its own.
+func removeElement(data []byte, element *rawElement) xmlEdit {
+	start := element.start - len(lineIndent(data, element.start))
+	if start == element.start && (start == 0 || data[start-1] != '\n') {
+		return xmlEdit{start: element.start, end: element.end}
+	}
+	if start > 0 && data[start-1] == '\n' {
+		start--
+		if start > 0 && data[start-1] == '\r' {
+			start--
+		}
+	}
+	return xmlEdit{start: start, end: element.end}
+}
+
+// applyEdits applies non-overlapping edits to data.
+func applyEdits(data []byte, edits []xmlEdit) ([]byte, error) {
+	slices.SortStableFunc(edits, func(a, b xmlEdit) int {
+		if a.start != b.start {
+			return a.start - b.start
+		}
+		return a.end - b.end
+	})
+
+	var out bytes.Buffer
+	cursor := 0
+	for _, edit := range edits {
+		if edit.start < cursor {
+			return nil, fmt.Errorf("overlapping XML edits at offset %d", edit.start)
+		}
+		out.Write(data[cursor:edit.start])
+		out.WriteString(edit.text)
+		cursor = edit.end
+	}
+	out.Write(data[cursor:])
+	return out.Bytes(), nil
+}
+
+// itemXML is an item as its struct marshals it: its attributes and its child elements
+// with their text.
+type itemXML struct {
+	name     string
+	attrs    []xml.Attr
+	children []xml.Attr
+}
+
+// marshalItem marshals item and reads back its attributes and child elements.
+func marshalItem(item any) (itemXML, error) {
+	data, err := xml.Marshal(item)
+	if err != nil {
+		return itemXML{}, fmt.Errorf("failed to marshal item: %w", err)
+	}
+
+	var marshaled itemXML
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return marshaled, nil
+		}
+		if err != nil {
+			return itemXML{}, fmt.Errorf("failed to read marshaled item: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				marshaled.name = t.Name.Local
+				marshaled.attrs = t.Attr
+			} else if depth == 2 {
+				marshaled.children = append(marshaled.children, xml.Attr{Name: t.Name})
+			}
+		case xml.CharData:
+			if depth == 2 {
+				marshaled.children[len(marshaled.children)-1].Value += string(t)
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// render writes the item as an element whose line is indented by indent.
+func (it itemXML) render(indent, unit, newline string) string {
+	var b strings.Builder
+	b.WriteString("<" + it.name)
+	for _, attr := range it.attrs {
+		b.WriteString(" " + attr.Name.Local + `="` + xmlEscaper.Replace(attr.Value) + `"`)
+	}
+	if len(it.children) == 0 {
+		b.WriteString(" />")
+		return b.String()
+	}
+	b.WriteString(">")
+	for _, child := range it.children {
+		name := child.Name.Local
+		b.WriteString(newline + indent + unit + "<" + name + ">" + xmlEscaper.Replace(child.Value) + "</" + name + ">")
+	}
+	b.WriteString(newline + indent + "</" + it.name + ">")
+	return b.String()
+}
+
+// lastElement returns the last of elements with the given local name, or nil.
+func lastElement(elements []*rawElement, name string) *rawElement {
+	for i := len(elements) - 1; i >= 0; i-- {
+		if localName(elements[i].name) == name {
+			return elements[i]
+		}
+	}
+	return nil
+}
+
+// lineIndent returns the whitespace before pos when it starts its line, and "" otherwise.
+func lineIndent(data []byte, pos int) string {
+	start := pos
+	for start > 0 && (data[start-1] == ' ' || data[start-1] == '\t') {
+		start--
+	}
+	if start > 0 && data[start-1] != '\n' {
+		return ""
+	}
+	return string(data[start:pos])
+}
+
+// leadingSpace returns all the whitespace before pos.
+func leadingSpace(data []byte, pos int) string {
+	start := pos
+	for start > 0 && isXMLSpace(data[start-1]) {
+		start--
+	}
+	return string(data[start:pos])
+}
+
+func isXMLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// unionNames returns the local names of a and then those of b that a doesn't have.
+func unionNames(a, b []xml.Attr) []string {
+	var names []string
+	for _, attrs := range [][]xml.Attr{a, b} {
+		for _, attr := range attrs {
+			if !slices.Contains(names, attr.Name.Local) {
+				names = append(names, attr.Name.Local)
+			}
+		}
+	}
+	return names
+}
+
+// attrLookup returns the value of the attribute with the given local name.
+func attrLookup(attrs []xml.Attr, name string) (string, bool) {
+	for _, attr := range attrs {
+		if attr.Name.Local == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// attrValue returns the value of the attribute with the given local name, or "".
+func attrValue(attrs []xml.Attr, name string) string {
+	value, _ := attrLookup(attrs, name)
+	return value
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func localName(name string) string {
+	if _, local, ok := strings.Cut(name, ":"); ok {
+		return local
+	}
+	return name
+}
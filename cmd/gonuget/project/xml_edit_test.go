@@ -0,0 +1,313 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSave_PreservesFormatting(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		edit    func(t *testing.T, proj *Project)
+		want    string
+	}{
+		{
+			name: "update existing version",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+
+	<!-- Build settings -->
+	<PropertyGroup>
+		<TargetFramework>net8.0</TargetFramework>
+		<Nullable>enable</Nullable>
+	</PropertyGroup>
+
+	<ItemGroup>
+		<PackageReference Include="Serilog" Version="3.1.1" PrivateAssets="all" />
+		<PackageReference Version='12.0.0' Include="Newtonsoft.Json" NoWarn="NU1605" />
+		<Compile Remove="Generated/**" />
+	</ItemGroup>
+
+</Project>
+`,
+			edit: func(t *testing.T, proj *Project) {
+				updated, err := proj.AddOrUpdatePackageReference("newtonsoft.json", "13.0.3", nil)
+				require.NoError(t, err)
+				assert.True(t, updated)
+			},
+			want: `<Project Sdk="Microsoft.NET.Sdk">
+
+	<!-- Build settings -->
+	<PropertyGroup>
+		<TargetFramework>net8.0</TargetFramework>
+		<Nullable>enable</Nullable>
+	</PropertyGroup>
+
+	<ItemGroup>
+		<PackageReference Include="Serilog" Version="3.1.1" PrivateAssets="all" />
+		<PackageReference Version='13.0.3' Include="Newtonsoft.Json" NoWarn="NU1605" />
+		<Compile Remove="Generated/**" />
+	</ItemGroup>
+
+</Project>
+`,
+		},
+		{
+			name: "add to project without ItemGroup",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+
+</Project>
+`,
+			edit: func(t *testing.T, proj *Project) {
+				updated, err := proj.AddOrUpdatePackageReference("Newtonsoft.Json", "13.0.3", nil)
+				require.NoError(t, err)
+				assert.False(t, updated)
+			},
+			want: `<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="13.0.3" />
+  </ItemGroup>
+
+</Project>
+`,
+		},
+		{
+			name: "add after the existing references",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <None Include="README.md" Pack="true" PackagePath="\" />
+  </ItemGroup>
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+    <!-- Pinned for the analyzers -->
+  </ItemGroup>
+  <Target Name="Hello" />
+</Project>`,
+			edit: func(t *testing.T, proj *Project) {
+				_, err := proj.AddOrUpdatePackageReference("Polly", "8.4.0", nil)
+				require.NoError(t, err)
+			},
+			want: `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <None Include="README.md" Pack="true" PackagePath="\" />
+  </ItemGroup>
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+    <!-- Pinned for the analyzers -->
+    <PackageReference Include="Polly" Version="8.4.0" />
+  </ItemGroup>
+  <Target Name="Hello" />
+</Project>`,
+		},
+		{
+			name:    "add conditional reference with CRLF line endings",
+			content: "<Project Sdk=\"Microsoft.NET.Sdk\">\r\n  <PropertyGroup>\r\n    <TargetFrameworks>net8.0;net48</TargetFrameworks>\r\n  </PropertyGroup>\r\n  <ItemGroup>\r\n  </ItemGroup>\r\n</Project>\r\n",
+			edit: func(t *testing.T, proj *Project) {
+				_, err := proj.AddOrUpdatePackageReference("System.Memory", "4.5.5", []string{"net48"})
+				require.NoError(t, err)
+			},
+			want: "<Project Sdk=\"Microsoft.NET.Sdk\">\r\n  <PropertyGroup>\r\n    <TargetFrameworks>net8.0;net48</TargetFrameworks>\r\n  </PropertyGroup>\r\n  <ItemGroup>\r\n  </ItemGroup>\r\n  <ItemGroup Condition=\"'$(TargetFramework)' == 'net48'\">\r\n    <PackageReference Include=\"System.Memory\" Version=\"4.5.5\" />\r\n  </ItemGroup>\r\n</Project>\r\n",
+		},
+		{
+			name: "remove reference",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+    <PackageReference Include="Polly" Version="8.4.0" />
+  </ItemGroup>
+</Project>`,
+			edit: func(t *testing.T, proj *Project) {
+				assert.True(t, proj.RemovePackageReference("serilog"))
+			},
+			want: `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Polly" Version="8.4.0" />
+  </ItemGroup>
+</Project>`,
+		},
+		{
+			name: "update legacy Version element",
+			content: `<?xml version="1.0" encoding="utf-8"?>
+<Project ToolsVersion="15.0" xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <PropertyGroup>
+    <TargetFrameworkVersion>v4.7.2</TargetFrameworkVersion>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json">
+      <Version>12.0.0</Version>
+    </PackageReference>
+  </ItemGroup>
+</Project>`,
+			edit: func(t *testing.T, proj *Project) {
+				_, err := proj.AddOrUpdatePackageReference("Newtonsoft.Json", "13.0.3", nil)
+				require.NoError(t, err)
+			},
+			want: `<?xml version="1.0" encoding="utf-8"?>
+<Project ToolsVersion="15.0" xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <PropertyGroup>
+    <TargetFrameworkVersion>v4.7.2</TargetFrameworkVersion>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json">
+      <Version>13.0.3</Version>
+    </PackageReference>
+  </ItemGroup>
+</Project>`,
+		},
+		{
+			name: "remove last reference of a group",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="13.0.3" />
+  </ItemGroup>
+
+</Project>
+`,
+			edit: func(t *testing.T, proj *Project) {
+				assert.True(t, proj.RemovePackageReference("newtonsoft.json"))
+			},
+			want: `<Project Sdk="Microsoft.NET.Sdk">
+
+  <ItemGroup>
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+
+</Project>
+`,
+		},
+		{
+			name: "remove keeps a group with a comment",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <!-- Logging -->
+    <PackageReference Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+</Project>
+`,
+			edit: func(t *testing.T, proj *Project) {
+				assert.True(t, proj.RemovePackageReference("Serilog"))
+			},
+			want: `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <!-- Logging -->
+  </ItemGroup>
+</Project>
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectPath := filepath.Join(t.TempDir(), "App.csproj")
+			require.NoError(t, os.WriteFile(projectPath, []byte(tt.content), 0644))
+
+			proj, err := LoadProject(projectPath)
+			require.NoError(t, err)
+			tt.edit(t, proj)
+			require.NoError(t, proj.Save())
+
+			data, err := os.ReadFile(projectPath)
+			require.NoError(t, err)
+			assert.Equal(t, "\ufeff"+tt.want, string(data))
+
+			// The saved project loads again with the same references
+			reloaded, err := LoadProject(projectPath)
+			require.NoError(t, err)
+			assert.Equal(t, proj.GetPackageReferences(), reloaded.GetPackageReferences())
+		})
+	}
+}
+
+func TestSave_KeepsBOMAndSavesAgain(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "App.csproj")
+	content := "\ufeff<Project Sdk=\"Microsoft.NET.Sdk\" />"
+	require.NoError(t, os.WriteFile(projectPath, []byte(content), 0644))
+
+	proj, err := LoadProject(projectPath)
+	require.NoError(t, err)
+	_, err = proj.AddOrUpdatePackageReference("Serilog", "3.1.1", nil)
+	require.NoError(t, err)
+	require.NoError(t, proj.Save())
+
+	// A second edit of the same Project applies to what the first one saved
+	_, err = proj.AddOrUpdatePackageReference("Serilog", "4.0.0", nil)
+	require.NoError(t, err)
+	require.NoError(t, proj.Save())
+
+	data, err := os.ReadFile(projectPath)
+	require.NoError(t, err)
+	want := "\ufeff<Project Sdk=\"Microsoft.NET.Sdk\">\n  <ItemGroup>\n    <PackageReference Include=\"Serilog\" Version=\"4.0.0\" />\n  </ItemGroup>\n</Project>"
+	assert.Equal(t, want, string(data))
+	assert.Equal(t, 1, strings.Count(string(data), "\ufeff"))
+}
+
+func TestSave_DirectoryPackagesProps_PreservesFormatting(t *testing.T) {
+	propsPath := filepath.Join(t.TempDir(), "Directory.Packages.props")
+	content := `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <GlobalPackageReference Include="StyleCop.Analyzers" Version="1.1.118" />
+  </ItemGroup>
+  <ItemGroup Label="Logging">
+    <!-- Keep in sync with the docs -->
+    <PackageVersion Include="Serilog" Version="3.1.1" />
+  </ItemGroup>
+</Project>
+`
+	require.NoError(t, os.WriteFile(propsPath, []byte(content), 0644))
+
+	props, err := LoadDirectoryPackagesProps(propsPath)
+	require.NoError(t, err)
+	_, err = props.AddOrUpdatePackageVersion("serilog", "4.0.0")
+	require.NoError(t, err)
+	_, err = props.AddOrUpdatePackageVersion("Polly", "8.4.0")
+	require.NoError(t, err)
+	require.NoError(t, props.Save())
+
+	data, err := os.ReadFile(propsPath)
+	require.NoError(t, err)
+	want := "\ufeff" + `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <GlobalPackageReference Include="StyleCop.Analyzers" Version="1.1.118" />
+  </ItemGroup>
+  <ItemGroup Label="Logging">
+    <!-- Keep in sync with the docs -->
+    <PackageVersion Include="Serilog" Version="4.0.0" />
+    <PackageVersion Include="Polly" Version="8.4.0" />
+  </ItemGroup>
+</Project>
+`
+	assert.Equal(t, want, string(data))
+}
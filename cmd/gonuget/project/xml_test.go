@@ -217,3 +217,36 @@ func TestProjectRootElement_Unmarshal_Legacy(t *testing.T) {
 	require.Len(t, root.ItemGroups[0].References, 1)
 	assert.Equal(t, "System", root.ItemGroups[0].References[0].Include)
 }
+
+func TestReference_Unmarshal_RestoreMetadata(t *testing.T) {
+	xmlData := `<ItemGroup>
+  <ProjectReference Include="../Analyzers/Analyzers.csproj" PrivateAssets="all" ReferenceOutputAssembly="false" />
+  <ProjectReference Include="../Lib/Lib.csproj">
+    <PrivateAssets>compile;All</PrivateAssets>
+  </ProjectReference>
+  <ProjectReference Include="../Core/Core.csproj" ReferenceOutputAssembly="true" />
+</ItemGroup>`
+
+	var ig ItemGroup
+	err := xml.Unmarshal([]byte(xmlData), &ig)
+	require.NoError(t, err)
+	require.Len(t, ig.ProjectReferences, 3)
+
+	analyzers, lib, core := ig.ProjectReferences[0], ig.ProjectReferences[1], ig.ProjectReferences[2]
+
+	assert.True(t, analyzers.IsBuildOrderOnly())
+	assert.True(t, analyzers.IsPrivate())
+
+	assert.Equal(t, "compile;All", lib.PrivateAssets())
+	assert.True(t, lib.IsPrivate())
+	assert.False(t, lib.IsBuildOrderOnly())
+
+	assert.False(t, core.IsBuildOrderOnly())
+	assert.False(t, core.IsPrivate())
+
+	// Both metadata forms are written back
+	data, err := xml.Marshal(ig)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `PrivateAssets="all" ReferenceOutputAssembly="false"`)
+	assert.Contains(t, string(data), `<PrivateAssets>compile;All</PrivateAssets>`)
+}
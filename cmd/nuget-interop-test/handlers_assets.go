@@ -1,11 +1,21 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/willibrandon/gonuget/frameworks"
+	"github.com/willibrandon/gonuget/packaging"
 	"github.com/willibrandon/gonuget/packaging/assets"
+	"github.com/willibrandon/gonuget/restore"
+	"github.com/willibrandon/gonuget/serve"
+	"github.com/willibrandon/gonuget/version"
 )
 
 // FindRuntimeAssembliesHandler finds runtime assemblies from package paths.
@@ -17,7 +27,7 @@ type FindRuntimeAssembliesHandler struct{}
 func (h *FindRuntimeAssembliesHandler) ErrorCode() string { return "ASSET_RT_001" }
 
 // Handle processes the request.
-func (h *FindRuntimeAssembliesHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *FindRuntimeAssembliesHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req FindAssembliesRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -69,7 +79,7 @@ type FindCompileAssembliesHandler struct{}
 func (h *FindCompileAssembliesHandler) ErrorCode() string { return "ASSET_CP_001" }
 
 // Handle processes the request.
-func (h *FindCompileAssembliesHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *FindCompileAssembliesHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req FindAssembliesRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -132,7 +142,7 @@ type ParseAssetPathHandler struct{}
 func (h *ParseAssetPathHandler) ErrorCode() string { return "ASSET_PARSE_001" }
 
 // Handle processes the request.
-func (h *ParseAssetPathHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ParseAssetPathHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ParseAssetPathRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -190,6 +200,133 @@ func (h *ParseAssetPathHandler) Handle(data json.RawMessage) (interface{}, error
 	return resp, nil
 }
 
+// SelectAssetsForPackageHandler builds a package from a file list, restores it from an
+// in-memory feed into a project targeting each requested framework, and returns the
+// compile and runtime groups written to project.assets.json. Unlike the find_*
+// handlers this runs the whole restore, so the results are what a real restore selects.
+type SelectAssetsForPackageHandler struct{}
+
+// ErrorCode returns the error code for this handler.
+func (h *SelectAssetsForPackageHandler) ErrorCode() string { return "ASSET_SEL_001" }
+
+// Handle processes the request.
+func (h *SelectAssetsForPackageHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
+	var req SelectAssetsForPackageRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	// Validate required fields
+	if req.PackageID == "" {
+		return nil, fmt.Errorf("packageId is required")
+	}
+	if len(req.Files) == 0 {
+		return nil, fmt.Errorf("files is required")
+	}
+	if len(req.TargetFrameworks) == 0 {
+		return nil, fmt.Errorf("targetFrameworks is required")
+	}
+	if req.Version == "" {
+		req.Version = "1.0.0"
+	}
+	ver, err := version.Parse(req.Version)
+	if err != nil {
+		return nil, fmt.Errorf("parse version: %w", err)
+	}
+
+	workDir, err := os.MkdirTemp("", "gonuget-select-assets-")
+	if err != nil {
+		return nil, fmt.Errorf("create work directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	// Build the package into a folder feed served over HTTP
+	feedDir := filepath.Join(workDir, "feed")
+	if err := os.MkdirAll(feedDir, 0755); err != nil {
+		return nil, fmt.Errorf("create feed: %w", err)
+	}
+	builder := packaging.NewPackageBuilder().
+		SetID(req.PackageID).
+		SetVersion(ver).
+		SetDescription("Asset selection matrix package").
+		SetAuthors("gonuget")
+	for _, file := range req.Files {
+		if err := builder.AddFileFromBytes(file, []byte(file)); err != nil {
+			return nil, fmt.Errorf("add %s: %w", file, err)
+		}
+	}
+	if err := builder.SaveToFile(filepath.Join(feedDir, req.PackageID+"."+ver.ToNormalizedString()+".nupkg")); err != nil {
+		return nil, fmt.Errorf("build package: %w", err)
+	}
+
+	feed, err := serve.NewServer(serve.Options{Folder: feedDir, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("serve feed: %w", err)
+	}
+	server := httptest.NewServer(feed)
+	defer server.Close()
+
+	// Restore a project targeting every requested framework
+	projectDir := filepath.Join(workDir, "project")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		return nil, fmt.Errorf("create project: %w", err)
+	}
+	projectPath := filepath.Join(projectDir, "Matrix.csproj")
+	projectXML := fmt.Sprintf(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFrameworks>%s</TargetFrameworks>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="%s" Version="%s" />
+  </ItemGroup>
+</Project>`, strings.Join(req.TargetFrameworks, ";"), req.PackageID, req.Version)
+	if err := os.WriteFile(projectPath, []byte(projectXML), 0644); err != nil {
+		return nil, fmt.Errorf("write project: %w", err)
+	}
+
+	opts := &restore.Options{
+		Sources:        []string{server.URL + serve.ServiceIndexPath},
+		PackagesFolder: filepath.Join(workDir, "packages"),
+		NoCache:        true,
+		Force:          true,
+	}
+	if err := restore.Run(ctx, []string{projectPath}, opts, &silentConsole{}); err != nil {
+		return nil, fmt.Errorf("restore failed: %w", err)
+	}
+
+	assets, err := os.ReadFile(restore.GetAssetsFilePath(projectPath))
+	if err != nil {
+		return nil, fmt.Errorf("read project.assets.json: %w", err)
+	}
+	var lockFile restore.LockFile
+	if err := json.Unmarshal(assets, &lockFile); err != nil {
+		return nil, fmt.Errorf("parse project.assets.json: %w", err)
+	}
+
+	resp := SelectAssetsForPackageResponse{Targets: []SelectedAssets{}}
+	libraryKey := req.PackageID + "/" + ver.ToNormalizedString()
+	for _, tfm := range req.TargetFrameworks {
+		library := lockFile.Targets[tfm][libraryKey]
+		resp.Targets = append(resp.Targets, SelectedAssets{
+			TargetFramework: tfm,
+			Compile:         sortedKeys(library.Compile),
+			Runtime:         sortedKeys(library.Runtime),
+		})
+	}
+
+	return resp, nil
+}
+
+// sortedKeys returns the item paths of an asset group in order
+func sortedKeys(group map[string]map[string]string) []string {
+	paths := make([]string, 0, len(group))
+	for path := range group {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 // contentItemToData converts a ContentItem to ContentItemData for JSON serialization.
 func contentItemToData(item *assets.ContentItem) ContentItemData {
 	data := ContentItemData{
@@ -221,7 +358,7 @@ type ExpandRuntimeHandler struct{}
 func (h *ExpandRuntimeHandler) ErrorCode() string { return "RID_EXP_001" }
 
 // Handle processes the request.
-func (h *ExpandRuntimeHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ExpandRuntimeHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ExpandRuntimeRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -250,7 +387,7 @@ type AreRuntimesCompatibleHandler struct{}
 func (h *AreRuntimesCompatibleHandler) ErrorCode() string { return "RID_COMPAT_001" }
 
 // Handle processes the request.
-func (h *AreRuntimesCompatibleHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *AreRuntimesCompatibleHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req AreRuntimesCompatibleRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -41,7 +42,7 @@ type ComputeCacheHashHandler struct{}
 func (h *ComputeCacheHashHandler) ErrorCode() string { return "CACHE_HASH_001" }
 
 // Handle processes the request.
-func (h *ComputeCacheHashHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ComputeCacheHashHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ComputeCacheHashRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -68,7 +69,7 @@ type SanitizeCacheFilenameHandler struct{}
 func (h *SanitizeCacheFilenameHandler) ErrorCode() string { return "CACHE_SANITIZE_001" }
 
 // Handle processes the request.
-func (h *SanitizeCacheFilenameHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *SanitizeCacheFilenameHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req SanitizeCacheFilenameRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -95,7 +96,7 @@ type GenerateCachePathsHandler struct{}
 func (h *GenerateCachePathsHandler) ErrorCode() string { return "CACHE_PATHS_001" }
 
 // Handle processes the request.
-func (h *GenerateCachePathsHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *GenerateCachePathsHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req GenerateCachePathsRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -139,7 +140,7 @@ type ValidateCacheFileHandler struct{}
 func (h *ValidateCacheFileHandler) ErrorCode() string { return "CACHE_VALIDATE_001" }
 
 // Handle processes the request.
-func (h *ValidateCacheFileHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ValidateCacheFileHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ValidateCacheFileRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -187,7 +188,7 @@ type CalculateDgSpecHashHandler struct{}
 func (h *CalculateDgSpecHashHandler) ErrorCode() string { return "CACHE_DGSPEC_001" }
 
 // Handle processes the request.
-func (h *CalculateDgSpecHashHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *CalculateDgSpecHashHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req CalculateDgSpecHashRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -223,7 +224,7 @@ type VerifyProjectCacheFileHandler struct{}
 func (h *VerifyProjectCacheFileHandler) ErrorCode() string { return "CACHE_VERIFY_001" }
 
 // Handle processes the request.
-func (h *VerifyProjectCacheFileHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *VerifyProjectCacheFileHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req VerifyProjectCacheFileRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -14,7 +15,7 @@ type CheckFrameworkCompatHandler struct{}
 func (h *CheckFrameworkCompatHandler) ErrorCode() string { return "FW_COMPAT_001" }
 
 // Handle processes the framework compatibility check request.
-func (h *CheckFrameworkCompatHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *CheckFrameworkCompatHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req CheckFrameworkCompatRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -63,7 +64,7 @@ type ParseFrameworkHandler struct{}
 func (h *ParseFrameworkHandler) ErrorCode() string { return "FW_PARSE_001" }
 
 // Handle processes the framework parsing request.
-func (h *ParseFrameworkHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ParseFrameworkHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ParseFrameworkRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -100,7 +101,7 @@ type FormatFrameworkHandler struct{}
 func (h *FormatFrameworkHandler) ErrorCode() string { return "FW_FORMAT_001" }
 
 // Handle processes the framework formatting request.
-func (h *FormatFrameworkHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *FormatFrameworkHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req FormatFrameworkRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
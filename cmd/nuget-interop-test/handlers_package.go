@@ -19,7 +19,7 @@ type ReadPackageHandler struct{}
 func (h *ReadPackageHandler) ErrorCode() string { return "PKG_READ_001" }
 
 // Handle processes the request.
-func (h *ReadPackageHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ReadPackageHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ReadPackageRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -84,7 +84,7 @@ type BuildPackageHandler struct{}
 func (h *BuildPackageHandler) ErrorCode() string { return "PKG_BUILD_001" }
 
 // Handle processes the request.
-func (h *BuildPackageHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *BuildPackageHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req BuildPackageRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -141,7 +141,7 @@ type ExtractPackageV2Handler struct{}
 func (h *ExtractPackageV2Handler) ErrorCode() string { return "PKG_EXTRACT_V2_001" }
 
 // Handle processes the request.
-func (h *ExtractPackageV2Handler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ExtractPackageV2Handler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ExtractPackageV2Request
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -161,7 +161,7 @@ func (h *ExtractPackageV2Handler) Handle(data json.RawMessage) (interface{}, err
 	resolver := packaging.NewPackagePathResolver(req.InstallPath, req.UseSideBySideLayout)
 
 	// Create extraction context
-	ctx := &packaging.PackageExtractionContext{
+	extractionCtx := &packaging.PackageExtractionContext{
 		PackageSaveMode:    packaging.PackageSaveMode(req.PackageSaveMode),
 		XMLDocFileSaveMode: packaging.XMLDocFileSaveMode(req.XMLDocFileSaveMode),
 		Logger:             nil, // No logging for tests
@@ -169,11 +169,11 @@ func (h *ExtractPackageV2Handler) Handle(data json.RawMessage) (interface{}, err
 
 	// Extract package
 	extractedFiles, err := packaging.ExtractPackageV2(
-		context.Background(),
+		ctx,
 		"source", // Source string (not used in V2)
 		packageReader,
 		resolver,
-		ctx,
+		extractionCtx,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("extract package: %w", err)
@@ -192,7 +192,7 @@ type InstallFromSourceV3Handler struct{}
 func (h *InstallFromSourceV3Handler) ErrorCode() string { return "PKG_INSTALL_V3_001" }
 
 // Handle processes the request.
-func (h *InstallFromSourceV3Handler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *InstallFromSourceV3Handler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req InstallFromSourceV3Request
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -227,7 +227,7 @@ func (h *InstallFromSourceV3Handler) Handle(data json.RawMessage) (interface{},
 	resolver := packaging.NewVersionFolderPathResolver(req.GlobalPackagesFolder, true)
 
 	// Create extraction context
-	ctx := &packaging.PackageExtractionContext{
+	extractionCtx := &packaging.PackageExtractionContext{
 		PackageSaveMode:    packaging.PackageSaveMode(req.PackageSaveMode),
 		XMLDocFileSaveMode: packaging.XMLDocFileSaveMode(req.XMLDocFileSaveMode),
 		Logger:             nil, // No logging for tests
@@ -240,12 +240,12 @@ func (h *InstallFromSourceV3Handler) Handle(data json.RawMessage) (interface{},
 
 	// Install package
 	installed, err := packaging.InstallFromSourceV3(
-		context.Background(),
+		ctx,
 		"source", // Source string
 		identity,
 		copyToAsync,
 		resolver,
-		ctx,
+		extractionCtx,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("install package: %w", err)
@@ -20,7 +20,7 @@ type WalkGraphHandler struct{}
 func (h *WalkGraphHandler) ErrorCode() string { return "WALK_001" }
 
 // Handle processes the request.
-func (h *WalkGraphHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *WalkGraphHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req WalkGraphRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -53,7 +53,7 @@ func (h *WalkGraphHandler) Handle(data json.RawMessage) (interface{}, error) {
 
 	// Walk the graph (recursive=true for full transitive resolution)
 	rootNode, err := walker.Walk(
-		context.Background(),
+		ctx,
 		req.PackageID,
 		req.VersionRange,
 		req.TargetFramework,
@@ -102,7 +102,7 @@ type ResolveConflictsHandler struct{}
 func (h *ResolveConflictsHandler) ErrorCode() string { return "RESOLVE_001" }
 
 // Handle processes the request.
-func (h *ResolveConflictsHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ResolveConflictsHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ResolveConflictsRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -141,7 +141,7 @@ func (h *ResolveConflictsHandler) Handle(data json.RawMessage) (interface{}, err
 		r := resolver.NewResolver(client, sources, req.TargetFramework)
 
 		// Resolve the package
-		result, err := r.Resolve(context.Background(), packageID, versionRange)
+		result, err := r.Resolve(ctx, packageID, versionRange)
 		if err != nil {
 			return nil, fmt.Errorf("resolve %s: %w", packageID, err)
 		}
@@ -306,7 +306,7 @@ type AnalyzeCyclesHandler struct{}
 func (h *AnalyzeCyclesHandler) ErrorCode() string { return "CYCLES_001" }
 
 // Handle processes the request.
-func (h *AnalyzeCyclesHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *AnalyzeCyclesHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req AnalyzeCyclesRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -348,7 +348,7 @@ func (h *AnalyzeCyclesHandler) Handle(data json.RawMessage) (interface{}, error)
 
 	// Walk the graph (recursive=true for full transitive resolution)
 	rootNode, err := walker.Walk(
-		context.Background(),
+		ctx,
 		req.PackageID,
 		req.VersionRange,
 		req.TargetFramework,
@@ -386,7 +386,7 @@ type ResolveTransitiveHandler struct{}
 func (h *ResolveTransitiveHandler) ErrorCode() string { return "TRANSITIVE_001" }
 
 // Handle processes the request.
-func (h *ResolveTransitiveHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ResolveTransitiveHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ResolveTransitiveRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -411,6 +411,7 @@ func (h *ResolveTransitiveHandler) Handle(data json.RawMessage) (interface{}, er
 
 	// Create resolver
 	r := resolver.NewResolver(client, req.Sources, req.TargetFramework)
+	r.SetAllowPrereleaseEverywhere(req.AllowPrereleaseEverywhere)
 
 	// Convert request to PackageDependency slice
 	deps := make([]resolver.PackageDependency, len(req.RootPackages))
@@ -422,7 +423,7 @@ func (h *ResolveTransitiveHandler) Handle(data json.RawMessage) (interface{}, er
 	}
 
 	// Resolve project
-	result, err := r.ResolveProject(context.Background(), deps)
+	result, err := r.ResolveProject(ctx, deps)
 	if err != nil {
 		return nil, fmt.Errorf("resolve transitive: %w", err)
 	}
@@ -479,7 +480,7 @@ type BenchmarkCacheHandler struct{}
 func (h *BenchmarkCacheHandler) ErrorCode() string { return "CACHE_001" }
 
 // Handle processes the request.
-func (h *BenchmarkCacheHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *BenchmarkCacheHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req BenchmarkCacheRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -521,7 +522,7 @@ func (h *BenchmarkCacheHandler) Handle(data json.RawMessage) (interface{}, error
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			_, _ = r.Resolve(context.Background(), req.PackageID, req.VersionRange)
+			_, _ = r.Resolve(ctx, req.PackageID, req.VersionRange)
 		}()
 	}
 
@@ -543,7 +544,7 @@ type ResolveWithTTLHandler struct{}
 func (h *ResolveWithTTLHandler) ErrorCode() string { return "TTL_001" }
 
 // Handle processes the request.
-func (h *ResolveWithTTLHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ResolveWithTTLHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ResolveWithTTLRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -574,7 +575,7 @@ func (h *ResolveWithTTLHandler) Handle(data json.RawMessage) (interface{}, error
 	// TODO: Set TTL on walker's operation cache
 
 	// Resolve
-	result, err := r.Resolve(context.Background(), req.PackageID, req.VersionRange)
+	result, err := r.Resolve(ctx, req.PackageID, req.VersionRange)
 	if err != nil {
 		return nil, fmt.Errorf("resolve: %w", err)
 	}
@@ -602,7 +603,7 @@ type BenchmarkParallelHandler struct{}
 func (h *BenchmarkParallelHandler) ErrorCode() string { return "PARALLEL_001" }
 
 // Handle processes the request.
-func (h *BenchmarkParallelHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *BenchmarkParallelHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req BenchmarkParallelRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -653,9 +654,9 @@ func (h *BenchmarkParallelHandler) Handle(data json.RawMessage) (interface{}, er
 		results = make([]*resolver.ResolutionResult, len(deps))
 		for i, dep := range deps {
 			if recursive {
-				results[i], err = r.Resolve(context.Background(), dep.ID, dep.VersionRange)
+				results[i], err = r.Resolve(ctx, dep.ID, dep.VersionRange)
 			} else {
-				results[i], err = r.ResolveNonRecursive(context.Background(), dep.ID, dep.VersionRange)
+				results[i], err = r.ResolveNonRecursive(ctx, dep.ID, dep.VersionRange)
 			}
 			if err != nil {
 				return nil, fmt.Errorf("resolve %s: %w", dep.ID, err)
@@ -664,14 +665,14 @@ func (h *BenchmarkParallelHandler) Handle(data json.RawMessage) (interface{}, er
 	} else {
 		// Resolve in parallel
 		if recursive {
-			results, err = r.ResolveMultiple(context.Background(), deps)
+			results, err = r.ResolveMultiple(ctx, deps)
 		} else {
 			// For non-recursive parallel resolution, resolve each package independently
 			results = make([]*resolver.ResolutionResult, len(deps))
 			errChan := make(chan error, len(deps))
 			for i, dep := range deps {
 				go func(index int, d resolver.PackageDependency) {
-					result, resolveErr := r.ResolveNonRecursive(context.Background(), d.ID, d.VersionRange)
+					result, resolveErr := r.ResolveNonRecursive(ctx, d.ID, d.VersionRange)
 					if resolveErr != nil {
 						errChan <- resolveErr
 					} else {
@@ -715,7 +716,7 @@ type ResolveWithWorkerLimitHandler struct{}
 func (h *ResolveWithWorkerLimitHandler) ErrorCode() string { return "WORKER_001" }
 
 // Handle processes the request.
-func (h *ResolveWithWorkerLimitHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ResolveWithWorkerLimitHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ResolveWithWorkerLimitRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -759,7 +760,7 @@ func (h *ResolveWithWorkerLimitHandler) Handle(data json.RawMessage) (interface{
 	}
 
 	// Resolve in parallel
-	results, err := r.ResolveMultiple(context.Background(), deps)
+	results, err := r.ResolveMultiple(ctx, deps)
 	if err != nil {
 		return nil, fmt.Errorf("resolve multiple: %w", err)
 	}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/internal/pathnorm"
 	"github.com/willibrandon/gonuget/restore"
 )
 
@@ -35,15 +36,12 @@ type ResolveLatestVersionResponse struct {
 }
 
 // Handle processes the version resolution request.
-func (h *ResolveLatestVersionHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ResolveLatestVersionHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ResolveLatestVersionRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	version, err := restore.ResolveLatestVersion(ctx, req.PackageID, &restore.ResolveLatestVersionOptions{
 		Source:     req.Source,
 		Prerelease: req.Prerelease,
@@ -134,7 +132,7 @@ type DependencyInfo struct {
 }
 
 // Handle processes the lock file parsing request.
-func (h *ParseLockFileHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ParseLockFileHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ParseLockFileRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
@@ -253,7 +251,7 @@ type RestoreDirectDependenciesResponse struct {
 }
 
 // Handle processes the restore request.
-func (h *RestoreDirectDependenciesHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *RestoreDirectDependenciesHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req RestoreDirectDependenciesRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
@@ -272,8 +270,6 @@ func (h *RestoreDirectDependenciesHandler) Handle(data json.RawMessage) (interfa
 
 	// Execute restore
 	start := time.Now()
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
 
 	err := restore.Run(ctx, []string{req.ProjectPath}, opts, console)
 	elapsed := time.Since(start)
@@ -326,7 +322,7 @@ func (h *RestoreTransitiveHandler) ErrorCode() string {
 }
 
 // Handle processes the restore transitive request.
-func (h *RestoreTransitiveHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *RestoreTransitiveHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req RestoreTransitiveRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
@@ -345,8 +341,6 @@ func (h *RestoreTransitiveHandler) Handle(data json.RawMessage) (interface{}, er
 
 	// Execute restore
 	start := time.Now()
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
 
 	result, err := restore.RunWithResult(ctx, []string{req.ProjectPath}, opts, console)
 	elapsed := time.Since(start)
@@ -432,7 +426,7 @@ func (h *CompareProjectAssetsHandler) ErrorCode() string {
 }
 
 // Handle processes the comparison request.
-func (h *CompareProjectAssetsHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *CompareProjectAssetsHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req CompareProjectAssetsRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
@@ -466,6 +460,8 @@ func (h *CompareProjectAssetsHandler) Handle(data json.RawMessage) (interface{},
 	projectFileDependencyGroupsMatch := true
 	versionsMatch := true
 	pathsMatch := true
+	// Paths that differ only in separators are reported apart from content differences
+	formattingErrors := []string{}
 
 	// Compare Libraries map keys
 	if len(gonugetLockFile.Libraries) != len(nugetLockFile.Libraries) {
@@ -490,11 +486,45 @@ func (h *CompareProjectAssetsHandler) Handle(data json.RawMessage) (interface{},
 		}
 
 		// Compare paths (should be lowercase)
-		if gonugetLib.Path != nugetLib.Path {
+		if pathnorm.SeparatorOnlyDifference(gonugetLib.Path, nugetLib.Path) {
+			formattingErrors = append(formattingErrors, fmt.Sprintf("Library path separators differ for %s: gonuget=%s, nuget=%s",
+				key, gonugetLib.Path, nugetLib.Path))
+		} else if gonugetLib.Path != nugetLib.Path {
 			pathsMatch = false
 			differences = append(differences, fmt.Sprintf("Library path mismatch for %s: gonuget=%s, nuget=%s",
 				key, gonugetLib.Path, nugetLib.Path))
 		}
+
+		if len(gonugetLib.Files) == len(nugetLib.Files) {
+			for i, file := range gonugetLib.Files {
+				if pathnorm.SeparatorOnlyDifference(file, nugetLib.Files[i]) {
+					formattingErrors = append(formattingErrors, fmt.Sprintf("Library file separators differ for %s: gonuget=%s, nuget=%s",
+						key, file, nugetLib.Files[i]))
+				}
+			}
+		}
+	}
+
+	// Machine-specific folders may differ between the two restores, but not in separators alone
+	for folder := range gonugetLockFile.PackageFolders {
+		if _, exists := nugetLockFile.PackageFolders[folder]; exists {
+			continue
+		}
+		for nugetFolder := range nugetLockFile.PackageFolders {
+			if pathnorm.SeparatorOnlyDifference(folder, nugetFolder) {
+				formattingErrors = append(formattingErrors, fmt.Sprintf("Package folder separators differ: gonuget=%s, nuget=%s",
+					folder, nugetFolder))
+			}
+		}
+	}
+	gonugetRestore, nugetRestore := gonugetLockFile.Project.Restore, nugetLockFile.Project.Restore
+	if pathnorm.SeparatorOnlyDifference(gonugetRestore.PackagesPath, nugetRestore.PackagesPath) {
+		formattingErrors = append(formattingErrors, fmt.Sprintf("packagesPath separators differ: gonuget=%s, nuget=%s",
+			gonugetRestore.PackagesPath, nugetRestore.PackagesPath))
+	}
+	if pathnorm.SeparatorOnlyDifference(gonugetRestore.OutputPath, nugetRestore.OutputPath) {
+		formattingErrors = append(formattingErrors, fmt.Sprintf("outputPath separators differ: gonuget=%s, nuget=%s",
+			gonugetRestore.OutputPath, nugetRestore.OutputPath))
 	}
 
 	// Compare ProjectFileDependencyGroups
@@ -513,7 +543,8 @@ func (h *CompareProjectAssetsHandler) Handle(data json.RawMessage) (interface{},
 		}
 	}
 
-	areEqual := librariesMatch && projectFileDependencyGroupsMatch && versionsMatch && pathsMatch
+	formattingMatch := len(formattingErrors) == 0
+	areEqual := librariesMatch && projectFileDependencyGroupsMatch && versionsMatch && pathsMatch && formattingMatch
 
 	return CompareProjectAssetsResponse{
 		AreEqual:                         areEqual,
@@ -521,7 +552,9 @@ func (h *CompareProjectAssetsHandler) Handle(data json.RawMessage) (interface{},
 		ProjectFileDependencyGroupsMatch: projectFileDependencyGroupsMatch,
 		VersionsMatch:                    versionsMatch,
 		PathsMatch:                       pathsMatch,
+		FormattingMatch:                  formattingMatch,
 		Differences:                      differences,
+		FormattingErrors:                 formattingErrors,
 	}, nil
 }
 
@@ -534,7 +567,7 @@ func (h *ValidateErrorMessagesHandler) ErrorCode() string {
 }
 
 // Handle processes the validation request.
-func (h *ValidateErrorMessagesHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ValidateErrorMessagesHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ValidateErrorMessagesRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("invalid request: %w", err)
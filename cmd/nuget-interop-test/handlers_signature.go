@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto"
 	"crypto/x509"
 	"encoding/hex"
@@ -18,7 +19,7 @@ type SignPackageHandler struct{}
 func (h *SignPackageHandler) ErrorCode() string { return "SIGN_001" }
 
 // Handle processes the request.
-func (h *SignPackageHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *SignPackageHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req SignPackageRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -62,25 +63,27 @@ func (h *SignPackageHandler) Handle(data json.RawMessage) (interface{}, error) {
 		return nil, fmt.Errorf("invalid hashAlgorithm: %s (must be 'SHA256', 'SHA384', or 'SHA512')", req.HashAlgorithm)
 	}
 
-	// Load certificate
-	cert, err := loadCertificate(req.CertPath, req.CertPassword)
-	if err != nil {
-		return nil, fmt.Errorf("load certificate: %w", err)
-	}
-
-	// Load private key
-	var privateKey interface{}
+	// Load certificate and private key
+	var cert *x509.Certificate
+	var privateKey crypto.PrivateKey
+	var chain []*x509.Certificate
 	if req.KeyPath != "" {
-		// Load from separate key file
+		// Load from separate certificate and key files
+		var err error
+		cert, err = loadCertificate(req.CertPath)
+		if err != nil {
+			return nil, fmt.Errorf("load certificate: %w", err)
+		}
 		privateKey, err = loadPrivateKey(req.KeyPath)
 		if err != nil {
 			return nil, fmt.Errorf("load private key: %w", err)
 		}
 	} else if req.CertPassword != "" {
-		// Extract from PFX
-		privateKey, err = loadPrivateKeyFromPFX(req.CertPath, req.CertPassword)
+		// Extract certificate, key and intermediates from PFX
+		var err error
+		cert, privateKey, chain, err = signatures.LoadSigningCertificateFromPFX(req.CertPath, req.CertPassword)
 		if err != nil {
-			return nil, fmt.Errorf("load private key from PFX: %w", err)
+			return nil, fmt.Errorf("load PFX: %w", err)
 		}
 	} else {
 		return nil, fmt.Errorf("either keyPath or certPassword (for PFX) must be provided")
@@ -88,11 +91,12 @@ func (h *SignPackageHandler) Handle(data json.RawMessage) (interface{}, error) {
 
 	// Create signing options
 	opts := signatures.SigningOptions{
-		Certificate:   cert,
-		PrivateKey:    privateKey,
-		SignatureType: sigType,
-		HashAlgorithm: hashAlgo,
-		TimestampURL:  req.TimestampURL,
+		Certificate:      cert,
+		PrivateKey:       privateKey,
+		CertificateChain: chain,
+		SignatureType:    sigType,
+		HashAlgorithm:    hashAlgo,
+		TimestampURL:     req.TimestampURL,
 	}
 
 	// Create signature using gonuget API
@@ -113,7 +117,7 @@ type ParseSignatureHandler struct{}
 func (h *ParseSignatureHandler) ErrorCode() string { return "PARSE_001" }
 
 // Handle processes the request.
-func (h *ParseSignatureHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ParseSignatureHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ParseSignatureRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -193,7 +197,7 @@ type VerifySignatureHandler struct{}
 func (h *VerifySignatureHandler) ErrorCode() string { return "VERIFY_001" }
 
 // Handle processes the request.
-func (h *VerifySignatureHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *VerifySignatureHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req VerifySignatureRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -214,6 +218,14 @@ func (h *VerifySignatureHandler) Handle(data json.RawMessage) (interface{}, erro
 	opts := signatures.DefaultVerificationOptions()
 	opts.AllowUntrustedRoot = req.AllowUntrustedRoot
 	opts.RequireTimestamp = req.RequireTimestamp
+	opts.RevocationTimeout = time.Duration(req.RevocationTimeoutMs) * time.Millisecond
+	switch mode := signatures.RevocationMode(req.RevocationMode); mode {
+	case "", signatures.RevocationModeDisabled:
+	case signatures.RevocationModeOnline, signatures.RevocationModeOffline:
+		opts.RevocationMode = mode
+	default:
+		return nil, fmt.Errorf("invalid revocation mode %q", req.RevocationMode)
+	}
 
 	// Add trusted roots if provided
 	if len(req.TrustedRoots) > 0 {
@@ -241,6 +253,9 @@ func (h *VerifySignatureHandler) Handle(data json.RawMessage) (interface{}, erro
 		resp.Errors = append(resp.Errors, err.Error())
 	}
 	resp.Warnings = append(resp.Warnings, result.Warnings...)
+	for _, issue := range result.Issues {
+		resp.Issues = append(resp.Issues, SignatureIssue{Code: issue.Code, Message: issue.Message, IsError: issue.IsError})
+	}
 
 	// Extract signer subject if available
 	if sig.SignerCertificate != nil {
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
+	"github.com/willibrandon/gonuget/core"
 	"github.com/willibrandon/gonuget/version"
 )
 
@@ -14,7 +16,7 @@ type CompareVersionsHandler struct{}
 func (h *CompareVersionsHandler) ErrorCode() string { return "VER_CMP_001" }
 
 // Handle processes the version comparison request.
-func (h *CompareVersionsHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *CompareVersionsHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req CompareVersionsRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -55,7 +57,7 @@ type ParseVersionHandler struct{}
 func (h *ParseVersionHandler) ErrorCode() string { return "VER_PARSE_001" }
 
 // Handle processes the version parsing request.
-func (h *ParseVersionHandler) Handle(data json.RawMessage) (interface{}, error) {
+func (h *ParseVersionHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
 	var req ParseVersionRequest
 	if err := json.Unmarshal(data, &req); err != nil {
 		return nil, fmt.Errorf("parse request: %w", err)
@@ -98,3 +100,50 @@ func (h *ParseVersionHandler) Handle(data json.RawMessage) (interface{}, error)
 
 	return resp, nil
 }
+
+// SelectVersionHandler picks a version of a list with core.SelectVersion.
+type SelectVersionHandler struct{}
+
+// ErrorCode returns the error code for version selection failures.
+func (h *SelectVersionHandler) ErrorCode() string { return "VER_SELECT_001" }
+
+// Handle processes the version selection request.
+func (h *SelectVersionHandler) Handle(ctx context.Context, data json.RawMessage) (interface{}, error) {
+	var req SelectVersionRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("parse request: %w", err)
+	}
+
+	var selection core.VersionSelection
+	switch req.Selection {
+	case "lowest":
+		selection = core.SelectLowest
+	case "highest":
+		selection = core.SelectHighest
+	default:
+		return nil, fmt.Errorf("selection must be lowest or highest, got '%s'", req.Selection)
+	}
+
+	var rng *version.Range
+	if req.Range != "" {
+		var err error
+		if rng, err = version.ParseVersionRange(req.Range); err != nil {
+			return nil, fmt.Errorf("parse range '%s': %w", req.Range, err)
+		}
+	}
+
+	versions := make([]*version.NuGetVersion, 0, len(req.Versions))
+	for _, s := range req.Versions {
+		v, err := version.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("parse version '%s': %w", s, err)
+		}
+		versions = append(versions, v)
+	}
+
+	var resp SelectVersionResponse
+	if best := core.SelectVersion(versions, rng, selection, req.IncludePrerelease); best != nil {
+		resp.Version = best.String()
+	}
+	return resp, nil
+}
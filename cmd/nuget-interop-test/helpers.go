@@ -5,13 +5,11 @@ import (
 	"encoding/pem"
 	"fmt"
 	"os"
-
-	"software.sslmate.com/src/go-pkcs12"
 )
 
-// loadCertificate loads a certificate from PEM, DER, or PFX format.
-// For PFX files, password may be required.
-func loadCertificate(path, password string) (*x509.Certificate, error) {
+// loadCertificate loads a certificate from PEM or DER format.
+// PFX files are loaded with signatures.LoadSigningCertificateFromPFX instead.
+func loadCertificate(path string) (*x509.Certificate, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("read file: %w", err)
@@ -23,19 +21,10 @@ func loadCertificate(path, password string) (*x509.Certificate, error) {
 		return x509.ParseCertificate(block.Bytes)
 	}
 
-	// Try PFX format
-	if password != "" || isPFX(data) {
-		_, cert, _, err := pkcs12.DecodeChain(data, password)
-		if err != nil {
-			return nil, fmt.Errorf("decode PFX: %w", err)
-		}
-		return cert, nil
-	}
-
 	// Try DER format
 	cert, err := x509.ParseCertificate(data)
 	if err != nil {
-		return nil, fmt.Errorf("parse certificate (tried PEM, PFX, DER): %w", err)
+		return nil, fmt.Errorf("parse certificate (tried PEM, DER): %w", err)
 	}
 	return cert, nil
 }
@@ -71,28 +60,3 @@ func loadPrivateKey(path string) (interface{}, error) {
 
 	return nil, fmt.Errorf("unsupported key type: %s", block.Type)
 }
-
-// loadPrivateKeyFromPFX loads private key from PFX file.
-func loadPrivateKeyFromPFX(path, password string) (interface{}, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("read file: %w", err)
-	}
-
-	key, _, _, err := pkcs12.DecodeChain(data, password)
-	if err != nil {
-		return nil, fmt.Errorf("decode PFX: %w", err)
-	}
-
-	return key, nil
-}
-
-// isPFX checks if data looks like a PFX file.
-// PFX files start with the PKCS#12 magic bytes.
-func isPFX(data []byte) bool {
-	// PKCS#12 magic: 0x30 (SEQUENCE)
-	if len(data) < 4 {
-		return false
-	}
-	return data[0] == 0x30
-}
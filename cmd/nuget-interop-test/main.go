@@ -4,45 +4,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-)
-
-// Request represents an incoming test request from C# tests.
-// Action specifies which gonuget operation to perform.
-// Data contains action-specific parameters in JSON format.
-type Request struct {
-	Action string          `json:"action"`
-	Data   json.RawMessage `json:"data"`
-}
-
-// Response represents the standard response format sent back to C#.
-// Success indicates whether the operation completed without errors.
-// Data contains action-specific results (only present on success).
-// Error contains detailed error information (only present on failure).
-type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   *ErrorInfo  `json:"error,omitempty"`
-}
 
-// ErrorInfo contains structured error information for debugging.
-// Code is a machine-readable error code (e.g., "SIGN_001").
-// Message is a human-readable error description.
-// Details contains additional context (e.g., file paths, stack traces).
-type ErrorInfo struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
-}
+	"github.com/willibrandon/gonuget/internal/interopbridge"
+)
 
 func main() {
 	// Disable log output to avoid contaminating JSON response
 	// (tests may need to enable logging to files for debugging)
 
 	// Read request from stdin
-	var req Request
+	var req interopbridge.Request
 	decoder := json.NewDecoder(os.Stdin)
 	if err := decoder.Decode(&req); err != nil {
 		sendError("REQ_001", "Failed to parse request JSON", err.Error())
@@ -50,7 +25,7 @@ func main() {
 	}
 
 	// Route to appropriate handler based on action
-	var handler Handler
+	var handler interopbridge.Handler
 	switch req.Action {
 	// Signature operations
 	case "sign_package":
@@ -65,6 +40,8 @@ func main() {
 		handler = &CompareVersionsHandler{}
 	case "parse_version":
 		handler = &ParseVersionHandler{}
+	case "select_version":
+		handler = &SelectVersionHandler{}
 
 	// Framework operations
 	case "check_framework_compat":
@@ -91,6 +68,8 @@ func main() {
 		handler = &FindCompileAssembliesHandler{}
 	case "parse_asset_path":
 		handler = &ParseAssetPathHandler{}
+	case "select_assets_for_package":
+		handler = &SelectAssetsForPackageHandler{}
 
 	// RID (Runtime Identifier) operations
 	case "expand_runtime":
@@ -149,47 +128,22 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Execute handler
-	result, err := handler.Handle(req.Data)
-	if err != nil {
-		sendError(handler.ErrorCode(), err.Error(), "")
+	// Execute handler under the request's timeout and size budget
+	resp := interopbridge.Dispatch(context.Background(), handler, req)
+	sendResponse(resp)
+	if !resp.Success {
 		os.Exit(1)
 	}
-
-	// Send success response
-	sendSuccess(result)
 }
 
-// Handler interface for all request handlers.
-// Handle processes the request data and returns a result or error.
-// ErrorCode returns the error code prefix for this handler.
-type Handler interface {
-	Handle(data json.RawMessage) (interface{}, error)
-	ErrorCode() string
-}
-
-// sendSuccess writes a successful response to stdout.
-func sendSuccess(data interface{}) {
-	resp := Response{
-		Success: true,
-		Data:    data,
-	}
+// sendResponse writes a response to stdout.
+func sendResponse(resp interopbridge.Response) {
 	encoder := json.NewEncoder(os.Stdout)
 	encoder.SetIndent("", "  ") // Pretty print for debugging
 	_ = encoder.Encode(resp)
 }
 
-// sendError writes an error response to stdout.
+// sendError writes an error response to stdout for a request no handler ran for.
 func sendError(code, message, details string) {
-	resp := Response{
-		Success: false,
-		Error: &ErrorInfo{
-			Code:    code,
-			Message: message,
-			Details: details,
-		},
-	}
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ") // Pretty print for debugging
-	_ = encoder.Encode(resp)
+	sendResponse(interopbridge.ErrorResponse(code, message, details, interopbridge.Timing{}))
 }
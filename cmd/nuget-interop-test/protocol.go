@@ -77,6 +77,12 @@ type VerifySignatureRequest struct {
 
 	// RequireTimestamp requires the signature to be timestamped.
 	RequireTimestamp bool `json:"requireTimestamp"`
+
+	// RevocationMode is "Online", "Offline" or "Disabled" (the default).
+	RevocationMode string `json:"revocationMode,omitempty"`
+
+	// RevocationTimeoutMs bounds each OCSP request and CRL download (0 for the defaults).
+	RevocationTimeoutMs int `json:"revocationTimeoutMs,omitempty"`
 }
 
 // VerifySignatureResponse contains verification results.
@@ -92,6 +98,16 @@ type VerifySignatureResponse struct {
 
 	// SignerSubject is the signer certificate's subject DN.
 	SignerSubject string `json:"signerSubject,omitempty"`
+
+	// Issues are the errors and warnings that have a NuGet log code (e.g. NU3018).
+	Issues []SignatureIssue `json:"issues,omitempty"`
+}
+
+// SignatureIssue is a verification error or warning with its NuGet log code.
+type SignatureIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	IsError bool   `json:"isError"`
 }
 
 // CompareVersionsRequest compares two NuGet version strings.
@@ -124,6 +140,20 @@ type ParseVersionResponse struct {
 	IsLegacy     bool   `json:"isLegacy"`     // True if uses legacy 4-part format
 }
 
+// SelectVersionRequest picks a version of a list that satisfies a range.
+type SelectVersionRequest struct {
+	Versions          []string `json:"versions"`
+	Range             string   `json:"range"`     // Empty allows any version
+	Selection         string   `json:"selection"` // "lowest" or "highest"
+	IncludePrerelease bool     `json:"includePrerelease"`
+}
+
+// SelectVersionResponse returns the selected version.
+type SelectVersionResponse struct {
+	// Version is empty when no version satisfies the range.
+	Version string `json:"version"`
+}
+
 // CheckFrameworkCompatRequest checks framework compatibility.
 type CheckFrameworkCompatRequest struct {
 	// PackageFramework is the framework the package supports (e.g., "net6.0").
@@ -244,6 +274,35 @@ type ParseAssetPathResponse struct {
 	Item *ContentItemData `json:"item"`
 }
 
+// SelectAssetsForPackageRequest restores a package built from a file list for
+// several project frameworks.
+type SelectAssetsForPackageRequest struct {
+	// PackageID is the ID of the generated package (e.g., "Matrix.LibOnly").
+	PackageID string `json:"packageId"`
+
+	// Version is the version of the generated package. Defaults to 1.0.0.
+	Version string `json:"version,omitempty"`
+
+	// Files are the package file paths (e.g., "lib/net6.0/MyLib.dll").
+	Files []string `json:"files"`
+
+	// TargetFrameworks are the frameworks of the restored project (e.g., "net8.0").
+	TargetFrameworks []string `json:"targetFrameworks"`
+}
+
+// SelectAssetsForPackageResponse contains the asset groups restore selected.
+type SelectAssetsForPackageResponse struct {
+	// Targets has one entry per requested target framework, in request order.
+	Targets []SelectedAssets `json:"targets"`
+}
+
+// SelectedAssets contains the assets of the package in one project.assets.json target.
+type SelectedAssets struct {
+	TargetFramework string   `json:"targetFramework"`
+	Compile         []string `json:"compile"`
+	Runtime         []string `json:"runtime"`
+}
+
 // ExpandRuntimeRequest expands a runtime identifier to compatible RIDs.
 type ExpandRuntimeRequest struct {
 	// RID is the runtime identifier to expand (e.g., "win10-x64").
@@ -617,6 +676,9 @@ type ResolveTransitiveRequest struct {
 
 	// Sources is the list of package sources to query.
 	Sources []string `json:"sources"`
+
+	// AllowPrereleaseEverywhere lets every dependency range resolve to a prerelease version.
+	AllowPrereleaseEverywhere bool `json:"allowPrereleaseEverywhere,omitempty"`
 }
 
 // PackageSpec specifies a package ID and version range.
@@ -852,7 +914,9 @@ type CompareProjectAssetsResponse struct {
 	ProjectFileDependencyGroupsMatch bool     `json:"projectFileDependencyGroupsMatch"`
 	VersionsMatch                    bool     `json:"versionsMatch"`
 	PathsMatch                       bool     `json:"pathsMatch"`
+	FormattingMatch                  bool     `json:"formattingMatch"`
 	Differences                      []string `json:"differences"`
+	FormattingErrors                 []string `json:"formattingErrors"`
 }
 
 // ValidateErrorMessagesRequest represents a request to validate error messages.
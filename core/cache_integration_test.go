@@ -481,6 +481,94 @@ func TestV3Provider_ListVersions_CacheHit(t *testing.T) {
 	}
 }
 
+func TestV3Provider_ListVersions_RetriesTransientFailures(t *testing.T) {
+	var registrationCalls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Header().Set("Content-Type", "application/json")
+			index := map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]any{
+					{
+						"@id":   "http://" + r.Host + "/registration/",
+						"@type": "RegistrationsBaseUrl/3.6.0",
+					},
+				},
+			}
+			_ = json.NewEncoder(w).Encode(index)
+		case "/registration/testpkg/index.json":
+			registrationCalls++
+			// The first two requests fail like an overloaded feed
+			if registrationCalls <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			response := map[string]any{
+				"count": 1,
+				"items": []map[string]any{
+					{
+						"items": []map[string]any{
+							{"catalogEntry": map[string]any{"version": "1.0.0"}},
+						},
+					},
+				},
+			}
+			_ = json.NewEncoder(w).Encode(response)
+		}
+	}))
+	defer server.Close()
+
+	memCache := cache.NewMemoryCache(100, 10*1024*1024)
+	diskCache, err := cache.NewDiskCache(t.TempDir(), 100*1024*1024)
+	if err != nil {
+		t.Fatalf("Failed to create disk cache: %v", err)
+	}
+
+	httpClient := nugethttp.NewClient(&nugethttp.Config{
+		RetryConfig: &nugethttp.RetryConfig{
+			MaxRetries:     3,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+			BackoffFactor:  2,
+		},
+	})
+	repo := NewSourceRepository(RepositoryConfig{
+		Name:       "test",
+		SourceURL:  server.URL + "/index.json",
+		HTTPClient: httpClient,
+		Cache:      cache.NewMultiTierCache(memCache, diskCache),
+	})
+
+	var retries []nugethttp.RetryEvent
+	ctx := nugethttp.WithRetryObserver(context.Background(), func(event nugethttp.RetryEvent) {
+		retries = append(retries, event)
+	})
+	cacheCtx := cache.NewSourceCacheContext()
+
+	versions, err := repo.ListVersions(ctx, cacheCtx, "TestPkg")
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if len(versions) != 1 {
+		t.Errorf("ListVersions() returned %d versions, want 1", len(versions))
+	}
+	if registrationCalls != 3 || len(retries) != 2 {
+		t.Fatalf("registration calls = %d with %d retries, want 3 calls and 2 retries", registrationCalls, len(retries))
+	}
+	if retries[1].Attempt != 2 || retries[1].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("second retry = %+v, want attempt 2 after 503", retries[1])
+	}
+
+	// The answer obtained by the retry is cached; failed attempts are not
+	if _, err := repo.ListVersions(ctx, cacheCtx, "TestPkg"); err != nil {
+		t.Fatalf("ListVersions() second call error = %v", err)
+	}
+	if registrationCalls != 3 {
+		t.Errorf("registration calls = %d after a cache hit, want 3", registrationCalls)
+	}
+}
+
 func TestV3Provider_NoCacheConfigured(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
@@ -7,9 +7,10 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
-	"slices"
+	"strings"
 	"sync"
 
+	"github.com/willibrandon/gonuget/auth"
 	"github.com/willibrandon/gonuget/cache"
 	"github.com/willibrandon/gonuget/core/resolver"
 	"github.com/willibrandon/gonuget/frameworks"
@@ -24,12 +25,16 @@ type Client struct {
 	repositoryManager *RepositoryManager
 	targetFramework   *frameworks.NuGetFramework
 	resolver          *resolver.Resolver
+	credentials       *CredentialCache
 }
 
 // ClientConfig holds client configuration
 type ClientConfig struct {
 	RepositoryManager *RepositoryManager
 	TargetFramework   *frameworks.NuGetFramework
+	// CredentialProvider supplies credentials when a source answers 401 (nil disables).
+	// Its results are cached per source for the lifetime of the client.
+	CredentialProvider auth.CredentialProvider
 }
 
 // clientMetadataAdapter adapts Client to implement resolver.PackageMetadataClient
@@ -60,6 +65,14 @@ func NewClient(cfg ClientConfig) *Client {
 		targetFramework:   cfg.TargetFramework,
 	}
 
+	// Share one credential cache across every repository so a source prompts at most once
+	if cfg.CredentialProvider != nil {
+		client.credentials = NewCredentialCache(cfg.CredentialProvider)
+		for _, repo := range repoManager.ListRepositories() {
+			repo.SetCredentials(client.credentials)
+		}
+	}
+
 	// Initialize resolver if target framework is specified
 	if cfg.TargetFramework != nil {
 		// Get source URLs from all repositories
@@ -116,6 +129,11 @@ func (c *Client) GetRepositoryManager() *RepositoryManager {
 	return c.repositoryManager
 }
 
+// Credentials returns the per-source credential cache, or nil when no credential provider is configured
+func (c *Client) Credentials() *CredentialCache {
+	return c.credentials
+}
+
 // SetTargetFramework sets the target framework for package operations
 func (c *Client) SetTargetFramework(fw *frameworks.NuGetFramework) {
 	c.targetFramework = fw
@@ -131,6 +149,62 @@ func (c *Client) SearchPackages(ctx context.Context, query string, opts SearchOp
 	return c.repositoryManager.SearchAll(ctx, nil, query, opts)
 }
 
+// SearchPackagesFromSource searches for packages on the repository with the given name only.
+func (c *Client) SearchPackagesFromSource(ctx context.Context, sourceName, query string, opts SearchOptions) ([]SearchResult, error) {
+	repo, err := c.repositoryManager.GetRepository(sourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.Search(ctx, nil, query, opts)
+}
+
+// AutocompletePackageIDs returns package IDs starting with query from every v3 repository.
+// Repositories without an autocomplete resource are skipped.
+func (c *Client) AutocompletePackageIDs(ctx context.Context, query string, take int, includePrerelease bool) ([]string, error) {
+	repos := c.repositoryManager.ListRepositories()
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repositories configured")
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	var lastErr error
+	succeeded := false
+	for _, repo := range repos {
+		provider, err := repo.GetProvider(ctx)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		v3Provider, ok := provider.(*V3ResourceProvider)
+		if !ok {
+			continue
+		}
+
+		results, err := v3Provider.AutocompletePackageIDs(ctx, query, take, includePrerelease)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		succeeded = true
+
+		for _, id := range results {
+			key := strings.ToLower(id)
+			if !seen[key] {
+				seen[key] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if !succeeded && lastErr != nil {
+		return nil, fmt.Errorf("autocomplete failed: %w", lastErr)
+	}
+
+	return ids, nil
+}
+
 // GetPackageMetadata retrieves metadata for a specific package version from the first repository that has it
 func (c *Client) GetPackageMetadata(ctx context.Context, packageID, versionStr string) (*ProtocolMetadata, error) {
 	repos := c.repositoryManager.ListRepositories()
@@ -188,35 +262,50 @@ func (c *Client) ListVersions(ctx context.Context, packageID string) ([]string,
 	return versions, nil
 }
 
-// FindBestVersion finds the best matching version for a version range
-func (c *Client) FindBestVersion(ctx context.Context, packageID string, versionRange *version.Range) (*version.NuGetVersion, error) {
-	// Get all versions
-	versionStrings, err := c.ListVersions(ctx, packageID)
+// ListVersionsFromSource lists the versions of a package on the repository with the given name.
+// Unlike ListVersions, no other repository is queried, so the result shows exactly what that
+// source has.
+func (c *Client) ListVersionsFromSource(ctx context.Context, sourceName, packageID string) ([]string, error) {
+	repo, err := c.repositoryManager.GetRepository(sourceName)
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse versions
-	versions := make([]*version.NuGetVersion, 0, len(versionStrings))
-	for _, vStr := range versionStrings {
-		v, err := version.Parse(vStr)
-		if err != nil {
-			continue // Skip invalid versions
-		}
-		versions = append(versions, v)
+	versions, err := repo.ListVersions(ctx, nil, packageID)
+	if err != nil {
+		return nil, fmt.Errorf("list versions of %s from %s: %w", packageID, sourceName, err)
 	}
 
-	if len(versions) == 0 {
-		return nil, fmt.Errorf("no valid versions found for %s", packageID)
+	return versions, nil
+}
+
+// GetPackageMetadataFromSource retrieves metadata for a package version from the repository
+// with the given name only.
+func (c *Client) GetPackageMetadataFromSource(ctx context.Context, sourceName, packageID, versionStr string) (*ProtocolMetadata, error) {
+	repo, err := c.repositoryManager.GetRepository(sourceName)
+	if err != nil {
+		return nil, err
 	}
 
-	// Find best match
-	bestVersion := versionRange.FindBestMatch(versions)
-	if bestVersion == nil {
-		return nil, fmt.Errorf("no version satisfies range %s", versionRange.String())
+	metadata, err := repo.GetMetadata(ctx, nil, packageID, versionStr)
+	if err != nil {
+		return nil, fmt.Errorf("get metadata of %s %s from %s: %w", packageID, versionStr, sourceName, err)
 	}
 
-	return bestVersion, nil
+	return metadata, nil
+}
+
+// FindBestVersion finds the lowest version satisfying a version range across all repositories
+func (c *Client) FindBestVersion(ctx context.Context, packageID string, versionRange *version.Range) (*version.NuGetVersion, error) {
+	resolved, err := ResolveVersion(ctx, nil, packageID, versionRange, ResolveVersionOptions{
+		Repositories:    c.repositoryManager.ListRepositories(),
+		Selection:       SelectLowest,
+		IncludeUnlisted: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolved.Version, nil
 }
 
 // DownloadPackage downloads a package from the first repository that has it
@@ -275,31 +364,29 @@ type InstallPackageRequest struct {
 	IncludePrerelease bool
 }
 
-// ResolvePackageVersion resolves a version string (exact or range) to a specific version
+// ResolvePackageVersion resolves a version string (exact or range) to a specific version.
+// An exact version must exist on a source; a range resolves to its lowest satisfying version.
 func (c *Client) ResolvePackageVersion(ctx context.Context, packageID, versionStr string, includePrerelease bool) (*version.NuGetVersion, error) {
-	// Try parsing as exact version first
-	exactVer, err := version.Parse(versionStr)
-	if err == nil {
-		// Verify this version exists
-		versions, err := c.ListVersions(ctx, packageID)
-		if err != nil {
-			return nil, err
-		}
-
-		if !slices.Contains(versions, versionStr) {
-			return nil, fmt.Errorf("version %s not found", versionStr)
-		}
-		return exactVer, nil
-	}
-
-	// Try parsing as version range
 	versionRange, err := version.ParseVersionRange(versionStr)
 	if err != nil {
 		return nil, fmt.Errorf("invalid version or range: %s", versionStr)
 	}
 
-	// Find best matching version
-	return c.FindBestVersion(ctx, packageID, versionRange)
+	// A plain version is a minimum in a range, but here it asks for that exact version
+	if exactVer, err := version.Parse(versionStr); err == nil {
+		versionRange = &version.Range{MinVersion: exactVer, MaxVersion: exactVer, MinInclusive: true, MaxInclusive: true}
+	}
+
+	resolved, err := ResolveVersion(ctx, nil, packageID, versionRange, ResolveVersionOptions{
+		Repositories:      c.repositoryManager.ListRepositories(),
+		Selection:         SelectLowest,
+		IncludePrerelease: includePrerelease,
+		IncludeUnlisted:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resolved.Version, nil
 }
 
 // GetPackageMetadata implements resolver.PackageMetadataClient interface for the adapter.
@@ -349,9 +436,12 @@ func (a *clientMetadataAdapter) getPackageMetadataV3(ctx context.Context, provid
 
 	// Extract service index URL from V3 provider
 	// For nuget.org fast-path: sourceURL="https://www.nuget.org/api/v2", serviceIndexURL="https://api.nuget.org/v3/index.json"
+	// The provider's metadata client carries the source's credentials
 	var serviceIndexURL string
+	metadataClient := a.v3MetadataClient
 	if v3Provider, ok := provider.(*V3ResourceProvider); ok {
 		serviceIndexURL = v3Provider.ServiceIndexURL()
+		metadataClient = v3Provider.metadataClient
 	} else {
 		serviceIndexURL = provider.SourceURL()
 	}
@@ -370,7 +460,7 @@ func (a *clientMetadataAdapter) getPackageMetadataV3(ctx context.Context, provid
 		observability.RecordCacheHit(ctx, false)
 
 		// Use V3 registration API to get all versions in a single HTTP call
-		index, err := a.v3MetadataClient.GetPackageMetadata(ctx, serviceIndexURL, packageID)
+		index, err := metadataClient.GetPackageMetadata(ctx, serviceIndexURL, packageID)
 		if err != nil {
 			return nil, err
 		}
@@ -2,143 +2,38 @@ package core
 
 import (
 	"context"
-	"encoding/json"
 	"io"
-	"net/http"
-	"net/http/httptest"
-	"strings"
 	"testing"
 
 	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
 	"github.com/willibrandon/gonuget/version"
 )
 
-// createTestServer creates a test NuGet v3 server
-func createTestServer() *httptest.Server {
-	// Map of lowercase package IDs to their canonical casing
-	// This mimics how real NuGet.org preserves the original casing
-	packageCasing := map[string]string{
-		"testpkg":     "TestPkg",
-		"testpackage": "TestPackage",
-	}
-
-	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch {
-		case strings.Contains(r.URL.Path, "/registration/"):
-			// Registration/metadata
-			// Extract package ID from path like /registration/testpkg/index.json
-			path := strings.TrimPrefix(r.URL.Path, "/registration/")
-			path = strings.TrimSuffix(path, "/index.json")
-			packageIDLower := path
-
-			// Get canonical casing for this package ID
-			// In real NuGet v3, the catalog entry preserves the original package ID casing
-			canonicalID := packageCasing[packageIDLower]
-			if canonicalID == "" {
-				// If not in our map, just use the lowercase version
-				canonicalID = packageIDLower
-			}
-
-			// Return registration index with all versions
-			response := map[string]any{
-				"count": 1,
-				"items": []map[string]any{
-					{
-						"@id":   "http://" + r.Host + "/registration/" + packageIDLower + "/page.json",
-						"lower": "1.0.0",
-						"upper": "2.0.0",
-						"count": 3,
-						"items": []map[string]any{
-							{
-								"@id": "http://" + r.Host + "/registration/" + packageIDLower + "/1.0.0.json",
-								"catalogEntry": map[string]any{
-									"@id":     "http://" + r.Host + "/catalog/" + packageIDLower + "/1.0.0.json",
-									"id":      canonicalID,
-									"version": "1.0.0",
-								},
-								"packageContent": "http://" + r.Host + "/download/" + packageIDLower + "/1.0.0/" + packageIDLower + ".1.0.0.nupkg",
-							},
-							{
-								"@id": "http://" + r.Host + "/registration/" + packageIDLower + "/1.5.0.json",
-								"catalogEntry": map[string]any{
-									"@id":     "http://" + r.Host + "/catalog/" + packageIDLower + "/1.5.0.json",
-									"id":      canonicalID,
-									"version": "1.5.0",
-								},
-								"packageContent": "http://" + r.Host + "/download/" + packageIDLower + "/1.5.0/" + packageIDLower + ".1.5.0.nupkg",
-							},
-							{
-								"@id": "http://" + r.Host + "/registration/" + packageIDLower + "/2.0.0.json",
-								"catalogEntry": map[string]any{
-									"@id":     "http://" + r.Host + "/catalog/" + packageIDLower + "/2.0.0.json",
-									"id":      canonicalID,
-									"version": "2.0.0",
-								},
-								"packageContent": "http://" + r.Host + "/download/" + packageIDLower + "/2.0.0/" + packageIDLower + ".2.0.0.nupkg",
-							},
-						},
-					},
-				},
-			}
-			_ = json.NewEncoder(w).Encode(response)
-
-		case r.URL.Path == "/index.json":
-			// Service index (must come AFTER more specific paths like /registration/)
-			w.Header().Set("Content-Type", "application/json")
-			index := map[string]any{
-				"version": "3.0.0",
-				"resources": []map[string]any{
-					{
-						"@id":   "http://" + r.Host + "/registration/",
-						"@type": "RegistrationsBaseUrl",
-					},
-					{
-						"@id":   "http://" + r.Host + "/search",
-						"@type": "SearchQueryService",
-					},
-					{
-						"@id":   "http://" + r.Host + "/download/",
-						"@type": "PackageBaseAddress",
-					},
-				},
-			}
-			_ = json.NewEncoder(w).Encode(index)
-
-		case strings.Contains(r.URL.Path, "/search"):
-			// Search
-			query := r.URL.Query().Get("q")
-			response := map[string]any{
-				"totalHits": 1,
-				"data": []map[string]any{
-					{
-						"id":      query,
-						"version": "1.0.0",
-					},
-				},
-			}
-			_ = json.NewEncoder(w).Encode(response)
-
-		case strings.Contains(r.URL.Path, "/download/"):
-			// Download
-			w.Header().Set("Content-Type", "application/zip")
-			_, _ = w.Write([]byte("fake package content"))
-
-		default:
-			http.NotFound(w, r)
+// createTestServer creates a test NuGet v3 server with TestPkg and TestPackage at
+// 1.0.0, 1.5.0 and 2.0.0
+func createTestServer(t *testing.T) *nugethttptest.FakeV3Server {
+	var packages []nugethttptest.Package
+	for _, id := range []string{"TestPkg", "TestPackage"} {
+		for _, v := range []string{"1.0.0", "1.5.0", "2.0.0"} {
+			packages = append(packages, nugethttptest.Package{ID: id, Version: v})
 		}
-	}))
+	}
+	// Downloads return fixed content rather than a generated package
+	packages[0].Nupkg = []byte("fake package content")
+
+	return nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: packages})
 }
 
 func TestClient_FindBestVersion(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
 
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	_ = repoManager.AddRepository(repo)
@@ -161,15 +56,14 @@ func TestClient_FindBestVersion(t *testing.T) {
 }
 
 func TestClient_FindBestVersion_NoMatch(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
 
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	_ = repoManager.AddRepository(repo)
@@ -188,15 +82,14 @@ func TestClient_FindBestVersion_NoMatch(t *testing.T) {
 }
 
 func TestClient_ResolvePackageVersion_Exact(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
 
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	_ = repoManager.AddRepository(repo)
@@ -217,15 +110,14 @@ func TestClient_ResolvePackageVersion_Exact(t *testing.T) {
 }
 
 func TestClient_ResolvePackageVersion_Range(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
 
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	_ = repoManager.AddRepository(repo)
@@ -246,15 +138,14 @@ func TestClient_ResolvePackageVersion_Range(t *testing.T) {
 }
 
 func TestClient_ResolvePackageVersion_NotFound(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
 
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	_ = repoManager.AddRepository(repo)
@@ -271,15 +162,14 @@ func TestClient_ResolvePackageVersion_NotFound(t *testing.T) {
 }
 
 func TestClient_SearchPackages(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
 
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	_ = repoManager.AddRepository(repo)
@@ -289,7 +179,7 @@ func TestClient_SearchPackages(t *testing.T) {
 	})
 
 	ctx := context.Background()
-	results, err := client.SearchPackages(ctx, "TestQuery", SearchOptions{})
+	results, err := client.SearchPackages(ctx, "TestPkg", SearchOptions{})
 	if err != nil {
 		t.Fatalf("SearchPackages() error = %v", err)
 	}
@@ -304,13 +194,12 @@ func TestClient_SearchPackages(t *testing.T) {
 }
 
 func TestSourceRepository_GetMetadata(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 
@@ -326,13 +215,12 @@ func TestSourceRepository_GetMetadata(t *testing.T) {
 }
 
 func TestSourceRepository_ListVersions(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 
@@ -348,18 +236,17 @@ func TestSourceRepository_ListVersions(t *testing.T) {
 }
 
 func TestSourceRepository_Search(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 
 	ctx := context.Background()
-	results, err := repo.Search(ctx, nil, "test", SearchOptions{})
+	results, err := repo.Search(ctx, nil, "TestPkg", SearchOptions{})
 	if err != nil {
 		t.Fatalf("Search() error = %v", err)
 	}
@@ -370,13 +257,12 @@ func TestSourceRepository_Search(t *testing.T) {
 }
 
 func TestSourceRepository_DownloadPackage(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 
@@ -394,20 +280,19 @@ func TestSourceRepository_DownloadPackage(t *testing.T) {
 }
 
 func TestRepositoryManager_SearchAll(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	manager := NewRepositoryManager()
 
 	repo1 := NewSourceRepository(RepositoryConfig{
 		Name:       "repo1",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	repo2 := NewSourceRepository(RepositoryConfig{
 		Name:       "repo2",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 
@@ -415,7 +300,7 @@ func TestRepositoryManager_SearchAll(t *testing.T) {
 	_ = manager.AddRepository(repo2)
 
 	ctx := context.Background()
-	results, err := manager.SearchAll(ctx, nil, "test", SearchOptions{})
+	results, err := manager.SearchAll(ctx, nil, "TestPkg", SearchOptions{})
 	if err != nil {
 		t.Fatalf("SearchAll() error = %v", err)
 	}
@@ -434,8 +319,7 @@ func TestRepositoryManager_SearchAll(t *testing.T) {
 }
 
 func TestClient_GetPackageMetadata_MultipleRepos(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
@@ -443,12 +327,12 @@ func TestClient_GetPackageMetadata_MultipleRepos(t *testing.T) {
 	// Add two repos
 	repo1 := NewSourceRepository(RepositoryConfig{
 		Name:       "repo1",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	repo2 := NewSourceRepository(RepositoryConfig{
 		Name:       "repo2",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 
@@ -471,8 +355,7 @@ func TestClient_GetPackageMetadata_MultipleRepos(t *testing.T) {
 }
 
 func TestClient_ListVersions_MultipleRepos(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
@@ -480,12 +363,12 @@ func TestClient_ListVersions_MultipleRepos(t *testing.T) {
 	// Add two repos (both will return same versions, should be deduplicated)
 	repo1 := NewSourceRepository(RepositoryConfig{
 		Name:       "repo1",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	repo2 := NewSourceRepository(RepositoryConfig{
 		Name:       "repo2",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 
@@ -508,16 +391,61 @@ func TestClient_ListVersions_MultipleRepos(t *testing.T) {
 	}
 }
 
+func TestClient_ListVersionsFromSource(t *testing.T) {
+	server := createTestServer(t)
+
+	// A second source that has no packages at all
+	empty := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{})
+
+	httpClient := nugethttp.NewClient(nil)
+	repoManager := NewRepositoryManager()
+	_ = repoManager.AddRepository(NewSourceRepository(RepositoryConfig{
+		Name:       "internal",
+		SourceURL:  empty.SourceURL(),
+		HTTPClient: httpClient,
+	}))
+	_ = repoManager.AddRepository(NewSourceRepository(RepositoryConfig{
+		Name:       "public",
+		SourceURL:  server.SourceURL(),
+		HTTPClient: httpClient,
+	}))
+
+	client := NewClient(ClientConfig{RepositoryManager: repoManager})
+	ctx := context.Background()
+
+	versions, err := client.ListVersionsFromSource(ctx, "public", "TestPkg")
+	if err != nil {
+		t.Fatalf("ListVersionsFromSource(public) error = %v", err)
+	}
+	if len(versions) != 3 {
+		t.Errorf("ListVersionsFromSource(public) returned %d versions, want 3", len(versions))
+	}
+
+	// The package is on another source, but only the named one is queried
+	if versions, err := client.ListVersionsFromSource(ctx, "internal", "TestPkg"); err == nil && len(versions) > 0 {
+		t.Errorf("ListVersionsFromSource(internal) = %v, want no versions", versions)
+	}
+	if _, err := client.GetPackageMetadataFromSource(ctx, "internal", "TestPkg", "1.0.0"); err == nil {
+		t.Error("GetPackageMetadataFromSource(internal) expected error")
+	}
+	if _, err := client.GetPackageMetadataFromSource(ctx, "public", "TestPkg", "1.0.0"); err != nil {
+		t.Errorf("GetPackageMetadataFromSource(public) error = %v", err)
+	}
+
+	if _, err := client.ListVersionsFromSource(ctx, "missing", "TestPkg"); err == nil {
+		t.Error("ListVersionsFromSource() expected error for an unknown source name")
+	}
+}
+
 func TestClient_DownloadPackage_MultipleRepos(t *testing.T) {
-	server := createTestServer()
-	defer server.Close()
+	server := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
 
 	repo := NewSourceRepository(RepositoryConfig{
 		Name:       "test",
-		SourceURL:  server.URL + "/index.json",
+		SourceURL:  server.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	_ = repoManager.AddRepository(repo)
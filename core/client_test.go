@@ -292,8 +292,7 @@ func TestClient_GetPackageMetadata_FallbackToSecondRepo(t *testing.T) {
 	defer serverFail.Close()
 
 	// Create a working test server
-	serverSuccess := createTestServer()
-	defer serverSuccess.Close()
+	serverSuccess := createTestServer(t)
 
 	httpClient := nugethttp.NewClient(nil)
 	repoManager := NewRepositoryManager()
@@ -309,7 +308,7 @@ func TestClient_GetPackageMetadata_FallbackToSecondRepo(t *testing.T) {
 	// Add working repository second
 	repoSuccess := NewSourceRepository(RepositoryConfig{
 		Name:       "repo-success",
-		SourceURL:  serverSuccess.URL + "/index.json",
+		SourceURL:  serverSuccess.SourceURL(),
 		HTTPClient: httpClient,
 	})
 	_ = repoManager.AddRepository(repoSuccess)
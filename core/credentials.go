@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/willibrandon/gonuget/auth"
+	nugethttp "github.com/willibrandon/gonuget/http"
+)
+
+// CredentialCache holds the credentials obtained for each package source during
+// one process. The provider is consulted at most once per source, so a prompt or
+// device flow is not repeated when a command fans out to several resources.
+// A nil *CredentialCache is valid and never supplies credentials.
+type CredentialCache struct {
+	provider auth.CredentialProvider
+
+	mu      sync.Mutex
+	entries map[string]*credentialEntry
+}
+
+// credentialEntry is the lookup result for one source.
+// Its mutex makes concurrent callers wait for a single provider call.
+type credentialEntry struct {
+	mu            sync.Mutex
+	resolved      bool
+	authenticator auth.Authenticator
+	err           error
+}
+
+// NewCredentialCache creates a credential cache backed by provider.
+// provider can be nil when only injected credentials should be used.
+func NewCredentialCache(provider auth.CredentialProvider) *CredentialCache {
+	return &CredentialCache{
+		provider: provider,
+		entries:  make(map[string]*credentialEntry),
+	}
+}
+
+// Set stores credentials for a source without consulting the provider.
+func (c *CredentialCache) Set(sourceURL string, authenticator auth.Authenticator) {
+	if c == nil {
+		return
+	}
+
+	entry := c.entry(sourceURL)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	entry.resolved = true
+	entry.authenticator = authenticator
+	entry.err = nil
+}
+
+// Cached returns the credentials already obtained for a source, or nil.
+// It never calls the provider.
+func (c *CredentialCache) Cached(sourceURL string) auth.Authenticator {
+	if c == nil {
+		return nil
+	}
+
+	entry := c.entry(sourceURL)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	return entry.authenticator
+}
+
+// Get returns the credentials for a source, asking the provider on first use.
+// The result, including a failure or the absence of credentials, is remembered
+// for the lifetime of the cache.
+func (c *CredentialCache) Get(ctx context.Context, sourceURL string) (auth.Authenticator, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	entry := c.entry(sourceURL)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if !entry.resolved && c.provider != nil {
+		entry.authenticator, entry.err = c.provider.GetCredentials(ctx, sourceURL)
+		entry.resolved = true
+	}
+
+	return entry.authenticator, entry.err
+}
+
+func (c *CredentialCache) entry(sourceURL string) *credentialEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Spellings of the same source share credentials
+	key := nugethttp.CanonicalSourceURL(sourceURL)
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &credentialEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// sourceAuthenticator authenticates requests made on behalf of one source.
+// Configured credentials are always sent; credentials from the cache are sent once
+// known and requested when the source answers 401. A source answering with a Negotiate
+// or NTLM challenge is handed to a handshake transport instead (see ChallengeTransport).
+// Only requests to the source's own scheme, host and port get credentials: a service
+// index or registration can point downloads at a CDN or blob storage, which must not
+// receive the feed's credentials.
+type sourceAuthenticator struct {
+	sourceURL   string
+	configured  auth.Authenticator
+	credentials *CredentialCache
+}
+
+// Authenticate applies the configured and cached credentials to the request.
+func (a *sourceAuthenticator) Authenticate(req *http.Request) error {
+	if !a.sameOrigin(req) {
+		return nil
+	}
+	if a.configured != nil {
+		if err := a.configured.Authenticate(req); err != nil {
+			return err
+		}
+	}
+	if cached := a.credentials.Cached(a.sourceURL); cached != nil {
+		return cached.Authenticate(req)
+	}
+	return nil
+}
+
+// HandleChallenge obtains credentials for the source after a 401 response.
+// Windows integrated authentication is left to ChallengeTransport.
+func (a *sourceAuthenticator) HandleChallenge(ctx context.Context, req *http.Request) (bool, error) {
+	if !a.sameOrigin(req) {
+		return false, nil
+	}
+	authenticator, err := a.credentials.Get(ctx, a.sourceURL)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := authenticator.(*auth.NegotiateAuthenticator); ok {
+		return false, nil
+	}
+	return authenticator != nil, nil
+}
+
+// ChallengeTransport answers a Negotiate or NTLM challenge with the identity of the
+// process when the source's credentials ask for Windows integrated authentication
+// (ValidAuthenticationTypes), or when the source has no other credentials to send.
+// Outside Windows such a challenge fails with auth.ErrNegotiateUnsupported.
+func (a *sourceAuthenticator) ChallengeTransport(ctx context.Context, challenge *http.Response, base http.RoundTripper) (http.RoundTripper, error) {
+	scheme := auth.NegotiateScheme(challenge)
+	if scheme == "" || (challenge.Request != nil && !a.sameOrigin(challenge.Request)) {
+		return nil, nil
+	}
+
+	negotiator, ok := a.configured.(*auth.NegotiateAuthenticator)
+	if !ok {
+		if a.configured != nil {
+			return nil, nil
+		}
+		credentials, err := a.credentials.Get(ctx, a.sourceURL)
+		if err != nil {
+			return nil, err
+		}
+		switch credentials := credentials.(type) {
+		case nil:
+			negotiator = auth.NewNegotiateAuthenticator()
+		case *auth.NegotiateAuthenticator:
+			negotiator = credentials
+		default:
+			return nil, nil
+		}
+	}
+	return negotiator.Transport(scheme, base)
+}
+
+// sameOrigin reports whether a request goes to the source's scheme, host and port.
+func (a *sourceAuthenticator) sameOrigin(req *http.Request) bool {
+	return nugethttp.SameOrigin(req.URL.String(), a.sourceURL)
+}
@@ -0,0 +1,383 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/willibrandon/gonuget/auth"
+)
+
+// countingCredentialProvider is a fake prompt that counts how often it is asked.
+type countingCredentialProvider struct {
+	calls         atomic.Int32
+	authenticator auth.Authenticator
+	err           error
+}
+
+func (p *countingCredentialProvider) GetCredentials(_ context.Context, _ string) (auth.Authenticator, error) {
+	p.calls.Add(1)
+	return p.authenticator, p.err
+}
+
+// createAuthenticatedSearchServer serves an anonymous service index whose search and
+// autocomplete endpoints require basic credentials user:secret.
+func createAuthenticatedSearchServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	requireAuth := func(w http.ResponseWriter, r *http.Request) bool {
+		if user, pass, ok := r.BasicAuth(); ok && user == "user" && pass == "secret" {
+			return true
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="feed"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return false
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v3/index.json":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": "http://" + r.Host + "/search", "@type": "SearchQueryService"},
+					{"@id": "http://" + r.Host + "/autocomplete", "@type": "SearchAutocompleteService"},
+				},
+			})
+		case "/search":
+			if !requireAuth(w, r) {
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"totalHits": 1,
+				"data":      []map[string]any{{"id": "Private.Package", "version": "1.0.0"}},
+			})
+		case "/autocomplete":
+			if !requireAuth(w, r) {
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"totalHits": 2,
+				"data":      []string{"Private.Package", "Private.Tools"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newAuthenticatedTestClient(t *testing.T, sourceURL string, provider auth.CredentialProvider) *Client {
+	t.Helper()
+
+	repoManager := NewRepositoryManager()
+	if err := repoManager.AddRepository(NewSourceRepository(RepositoryConfig{Name: "private", SourceURL: sourceURL})); err != nil {
+		t.Fatalf("AddRepository() error = %v", err)
+	}
+	return NewClient(ClientConfig{RepositoryManager: repoManager, CredentialProvider: provider})
+}
+
+func TestClient_CredentialsSharedAcrossSearchAndAutocomplete(t *testing.T) {
+	server := createAuthenticatedSearchServer(t)
+	provider := &countingCredentialProvider{authenticator: auth.NewBasicAuthenticator("user", "secret")}
+	client := newAuthenticatedTestClient(t, server.URL+"/v3/index.json", provider)
+	ctx := context.Background()
+
+	// Fan out: concurrent searches and autocomplete calls all hit protected endpoints
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			results, err := client.SearchPackages(ctx, "private", SearchOptions{Take: 10})
+			if err == nil && len(results["private"]) != 1 {
+				err = errors.New("search returned no results")
+			}
+			errs <- err
+		}()
+		go func() {
+			defer wg.Done()
+			ids, err := client.AutocompletePackageIDs(ctx, "priv", 10, false)
+			if err == nil && !slices.Equal(ids, []string{"Private.Package", "Private.Tools"}) {
+				err = errors.New("unexpected autocomplete results")
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("authenticated request failed: %v", err)
+		}
+	}
+
+	// Later calls reuse the cached credentials
+	if _, err := client.AutocompletePackageIDs(ctx, "priv", 10, false); err != nil {
+		t.Errorf("AutocompletePackageIDs() error = %v", err)
+	}
+
+	if calls := provider.calls.Load(); calls != 1 {
+		t.Errorf("credential provider called %d times, want 1", calls)
+	}
+}
+
+func TestClient_CredentialProviderFailureNotRetried(t *testing.T) {
+	server := createAuthenticatedSearchServer(t)
+	provider := &countingCredentialProvider{err: errors.New("prompt cancelled")}
+	client := newAuthenticatedTestClient(t, server.URL+"/v3/index.json", provider)
+	ctx := context.Background()
+
+	if _, err := client.SearchPackages(ctx, "private", SearchOptions{}); err == nil {
+		t.Error("SearchPackages() expected error when credentials are unavailable")
+	}
+	if _, err := client.AutocompletePackageIDs(ctx, "priv", 10, false); err == nil {
+		t.Error("AutocompletePackageIDs() expected error when credentials are unavailable")
+	}
+
+	if calls := provider.calls.Load(); calls != 1 {
+		t.Errorf("credential provider called %d times, want 1", calls)
+	}
+}
+
+func TestClient_InjectedCredentialsSkipProvider(t *testing.T) {
+	server := createAuthenticatedSearchServer(t)
+	sourceURL := server.URL + "/v3/index.json"
+	provider := &countingCredentialProvider{}
+	client := newAuthenticatedTestClient(t, sourceURL, provider)
+
+	client.Credentials().Set(sourceURL, auth.NewBasicAuthenticator("user", "secret"))
+
+	if _, err := client.SearchPackages(context.Background(), "private", SearchOptions{}); err != nil {
+		t.Fatalf("SearchPackages() error = %v", err)
+	}
+	if calls := provider.calls.Load(); calls != 0 {
+		t.Errorf("credential provider called %d times, want 0", calls)
+	}
+}
+
+func TestCredentialCache_NilIsEmpty(t *testing.T) {
+	var credentials *CredentialCache
+
+	credentials.Set("https://example.com/v3/index.json", auth.NewBearerAuthenticator("token"))
+	if got := credentials.Cached("https://example.com/v3/index.json"); got != nil {
+		t.Errorf("Cached() = %v, want nil", got)
+	}
+	if got, err := credentials.Get(context.Background(), "https://example.com/v3/index.json"); got != nil || err != nil {
+		t.Errorf("Get() = %v, %v, want nil, nil", got, err)
+	}
+}
+
+// requireBasicAuth wraps a feed so that every request, including the service index or
+// V2 service document, needs basic credentials user:secret.
+func requireBasicAuth(t *testing.T, feed http.Handler) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="feed"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		feed.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSourceRepository_AuthenticatedFeeds(t *testing.T) {
+	v3 := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v3/index.json":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": "http://" + r.Host + "/v3/registration/", "@type": "RegistrationsBaseUrl"},
+				},
+			})
+		case "/v3/registration/private.package/index.json":
+			leaf := func(v string) map[string]any {
+				return map[string]any{"catalogEntry": map[string]any{"id": "Private.Package", "version": v}}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"count": 1,
+				"items": []map[string]any{{"count": 2, "lower": "1.0.0", "upper": "2.0.0", "items": []any{leaf("1.0.0"), leaf("2.0.0")}}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	v2 := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2":
+			w.Header().Set("Content-Type", "application/xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<service xmlns="http://www.w3.org/2007/app"><workspace><collection href="Packages" /></workspace></service>`))
+		case "/api/v2/FindPackagesById()":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			_, _ = w.Write([]byte(`<?xml version="1.0"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:d="http://schemas.microsoft.com/ado/2007/08/dataservices" xmlns:m="http://schemas.microsoft.com/ado/2007/08/dataservices/metadata">
+  <entry><m:properties><d:Version>1.0.0</d:Version></m:properties></entry>
+  <entry><m:properties><d:Version>2.0.0</d:Version></m:properties></entry>
+</feed>`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	tests := []struct {
+		name            string
+		feed            http.Handler
+		path            string
+		protocolVersion string
+	}{
+		{"v3 detected", v3, "/v3/index.json", ""},
+		{"v3 configured", v3, "/v3/index.json", "3"},
+		{"v2 detected", v2, "/api/v2", ""},
+		{"v2 configured", v2, "/api/v2", "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := requireBasicAuth(t, tt.feed)
+			provider := &countingCredentialProvider{authenticator: auth.NewBasicAuthenticator("user", "secret")}
+
+			repo := NewSourceRepository(RepositoryConfig{Name: "private", SourceURL: server.URL + tt.path})
+			repo.SetProtocolVersion(tt.protocolVersion)
+			repo.SetCredentials(NewCredentialCache(provider))
+
+			versions, err := repo.ListVersions(context.Background(), nil, "Private.Package")
+			if err != nil {
+				t.Fatalf("ListVersions() error = %v", err)
+			}
+			if !slices.Equal(versions, []string{"1.0.0", "2.0.0"}) {
+				t.Errorf("ListVersions() = %v, want 1.0.0 and 2.0.0", versions)
+			}
+			if calls := provider.calls.Load(); calls != 1 {
+				t.Errorf("credential provider called %d times, want 1", calls)
+			}
+		})
+	}
+}
+
+// createIntegratedAuthServer serves a service index like an on-premises Azure DevOps
+// Server, which offers Windows integrated authentication and basic authentication with a
+// personal access token.
+func createIntegratedAuthServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pass, ok := r.BasicAuth(); !ok || pass != "pat" {
+			w.Header().Add("WWW-Authenticate", "Negotiate")
+			w.Header().Add("WWW-Authenticate", "NTLM")
+			w.Header().Add("WWW-Authenticate", `Basic realm="https://tfs.example/tfs"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"version": "3.0.0", "resources": []any{}})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSourceRepository_IntegratedAuthWithoutCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("answers the challenge with SSPI on Windows")
+	}
+	server := createIntegratedAuthServer(t)
+
+	repo := NewSourceRepository(RepositoryConfig{Name: "tfs", SourceURL: server.URL + "/index.json"})
+	_, err := repo.GetProvider(context.Background())
+	if !errors.Is(err, auth.ErrNegotiateUnsupported) {
+		t.Errorf("GetProvider() error = %v, want ErrNegotiateUnsupported", err)
+	}
+}
+
+func TestSourceRepository_IntegratedAuthPrefersCredentials(t *testing.T) {
+	server := createIntegratedAuthServer(t)
+	provider := &countingCredentialProvider{authenticator: auth.NewBasicAuthenticator("user", "pat")}
+
+	repo := NewSourceRepository(RepositoryConfig{Name: "tfs", SourceURL: server.URL + "/index.json"})
+	repo.SetCredentials(NewCredentialCache(provider))
+	if _, err := repo.GetProvider(context.Background()); err != nil {
+		t.Fatalf("GetProvider() error = %v, want basic credentials to be sent", err)
+	}
+	if calls := provider.calls.Load(); calls != 1 {
+		t.Errorf("credential provider called %d times, want 1", calls)
+	}
+}
+
+func TestSourceRepository_CredentialsStayOnSourceOrigin(t *testing.T) {
+	var cdnAuthorization atomic.Value
+	cdnAuthorization.Store("")
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			cdnAuthorization.Store(r.Header.Get("Authorization"))
+		}
+		// A CDN asking for credentials must not get the feed's either
+		w.Header().Set("WWW-Authenticate", `Basic realm="cdn"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(cdn.Close)
+
+	feed := requireBasicAuth(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v3/index.json":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": "http://" + r.Host + "/v3/registration/", "@type": "RegistrationsBaseUrl"},
+				},
+			})
+		case "/v3/registration/private.package/index.json":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"count": 1,
+				"items": []map[string]any{{"count": 1, "lower": "1.0.0", "upper": "1.0.0", "items": []any{map[string]any{
+					"catalogEntry":   map[string]any{"id": "Private.Package", "version": "1.0.0"},
+					"packageContent": cdn.URL + "/private.package.1.0.0.nupkg",
+				}}}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	for _, tt := range []struct {
+		name   string
+		config RepositoryConfig
+	}{
+		{"configured credentials", RepositoryConfig{Authenticator: auth.NewBasicAuthenticator("user", "secret")}},
+		{"provider credentials", RepositoryConfig{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.config
+			cfg.Name = "private"
+			cfg.SourceURL = feed.URL + "/v3/index.json"
+			repo := NewSourceRepository(cfg)
+			provider := &countingCredentialProvider{authenticator: auth.NewBasicAuthenticator("user", "secret")}
+			repo.SetCredentials(NewCredentialCache(provider))
+
+			if body, err := repo.DownloadPackage(context.Background(), nil, "Private.Package", "1.0.0"); err == nil {
+				_ = body.Close()
+				t.Fatal("DownloadPackage() succeeded, want the CDN's 401")
+			}
+			if got := cdnAuthorization.Load().(string); got != "" {
+				t.Errorf("packageContent host received Authorization %q, want none", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// ErrPackageFileNotFound is returned by GetReadme and GetIcon for a package that has no
+// readme or embedded icon.
+var ErrPackageFileNotFound = errors.New("package file not found")
+
+// packageFileKind names a file packages can embed and declare in their nuspec.
+type packageFileKind string
+
+const (
+	packageReadme packageFileKind = "readme"
+	packageIcon   packageFileKind = "icon"
+)
+
+// nupkgTailSize is how much of the end of a .nupkg the first ranged request reads. The
+// end of central directory record and, for most packages, the whole central directory
+// fit in it, so opening the archive rarely needs a second request.
+const nupkgTailSize = 64 * 1024
+
+// packageFileLocator is implemented by providers whose source serves readmes and icons
+// without the package, as the flat container of a V3 source does.
+type packageFileLocator interface {
+	PackageReadmeURL(ctx context.Context, packageID, version string) (string, error)
+	PackageIconURL(ctx context.Context, packageID, version string) (string, error)
+}
+
+// packageDownloadURLResolver is implemented by providers that can report the .nupkg download URL.
+type packageDownloadURLResolver interface {
+	PackageDownloadURL(ctx context.Context, packageID, version string) (string, error)
+}
+
+// packageFile is a cached readme or icon; a nil content records that the package has none.
+type packageFile struct {
+	content []byte
+}
+
+// GetReadme returns the readme of a package version. The readme endpoint of the source is
+// tried first; when the source has none, the readme is read from the package with ranged
+// requests for the parts of the .nupkg that hold it. Results are cached per repository.
+// A package without a readme returns ErrPackageFileNotFound.
+func (r *SourceRepository) GetReadme(ctx context.Context, packageID, version string) ([]byte, error) {
+	return r.getPackageFile(ctx, packageReadme, packageID, version)
+}
+
+// GetIcon returns the embedded icon of a package version, found as GetReadme finds the
+// readme. A package without an embedded icon, including one that only has an iconUrl,
+// returns ErrPackageFileNotFound.
+func (r *SourceRepository) GetIcon(ctx context.Context, packageID, version string) ([]byte, error) {
+	return r.getPackageFile(ctx, packageIcon, packageID, version)
+}
+
+// getPackageFile returns a readme or icon from the cache, the source's endpoint for it, or
+// the package.
+func (r *SourceRepository) getPackageFile(ctx context.Context, kind packageFileKind, packageID, ver string) ([]byte, error) {
+	normalized := ver
+	if parsed, err := version.Parse(ver); err == nil {
+		normalized = parsed.ToNormalizedString()
+	}
+	cacheKey := string(kind) + ":" + strings.ToLower(packageID) + ":" + strings.ToLower(normalized)
+	if cached, ok := r.packageFiles.Load(cacheKey); ok {
+		return packageFileResult(cached.(*packageFile), kind, packageID, ver)
+	}
+
+	provider, err := r.GetProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	httpClient := r.authenticatedClient()
+	r.mu.RUnlock()
+
+	content, found, err := fetchPackageFileEndpoint(ctx, httpClient, provider, kind, packageID, ver)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		content, err = readPackageFileFromNupkg(ctx, httpClient, provider, kind, packageID, ver)
+		if err != nil && !errors.Is(err, ErrPackageFileNotFound) {
+			return nil, err
+		}
+	}
+
+	file := &packageFile{content: content}
+	r.packageFiles.Store(cacheKey, file)
+	return packageFileResult(file, kind, packageID, ver)
+}
+
+// packageFileResult returns the content of a cached file, or ErrPackageFileNotFound.
+func packageFileResult(file *packageFile, kind packageFileKind, packageID, version string) ([]byte, error) {
+	if file.content == nil {
+		return nil, fmt.Errorf("%s %s has no %s: %w", packageID, version, kind, ErrPackageFileNotFound)
+	}
+	return file.content, nil
+}
+
+// fetchPackageFileEndpoint gets a readme or icon from the endpoint the source serves it
+// from. found is false when the source has no such endpoint or it doesn't know the file.
+func fetchPackageFileEndpoint(ctx context.Context, client *nugethttp.Client, provider ResourceProvider, kind packageFileKind, packageID, version string) (content []byte, found bool, err error) {
+	locator, ok := provider.(packageFileLocator)
+	if !ok {
+		return nil, false, nil
+	}
+
+	var fileURL string
+	if kind == packageReadme {
+		fileURL, err = locator.PackageReadmeURL(ctx, packageID, version)
+	} else {
+		fileURL, err = locator.PackageIconURL(ctx, packageID, version)
+	}
+	if err != nil {
+		// No flat container; the package itself is the only place left to look
+		return nil, false, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("create request: %w", err)
+	}
+	resp, err := client.DoWithRetry(ctx, req)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s request: %w", kind, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		content, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, false, fmt.Errorf("read %s: %w", kind, err)
+		}
+		return content, true, nil
+	case http.StatusNotFound:
+		return nil, false, nil
+	default:
+		return nil, false, fmt.Errorf("%s returned %d: %s", kind, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+}
+
+// readPackageFileFromNupkg reads a readme or icon declared in the nuspec from the .nupkg,
+// downloading only the central directory, the nuspec and the file itself.
+func readPackageFileFromNupkg(ctx context.Context, client *nugethttp.Client, provider ResourceProvider, kind packageFileKind, packageID, version string) ([]byte, error) {
+	resolver, ok := provider.(packageDownloadURLResolver)
+	if !ok {
+		return nil, fmt.Errorf("source %s cannot locate the package of %s %s", provider.SourceURL(), packageID, version)
+	}
+	downloadURL, err := resolver.PackageDownloadURL(ctx, packageID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	nupkg, size, err := openRemoteNupkg(ctx, client, downloadURL)
+	if err != nil {
+		return nil, fmt.Errorf("open %s %s: %w", packageID, version, err)
+	}
+	reader, err := packaging.OpenPackageFromReaderAt(nupkg, size)
+	if err != nil {
+		return nil, err
+	}
+	nuspec, err := reader.GetNuspec()
+	if err != nil {
+		return nil, fmt.Errorf("read nuspec of %s %s: %w", packageID, version, err)
+	}
+
+	path := nuspec.Metadata.Readme
+	if kind == packageIcon {
+		path = nuspec.Metadata.Icon
+	}
+	if path == "" {
+		return nil, ErrPackageFileNotFound
+	}
+	entry, err := reader.GetFile(strings.TrimPrefix(strings.ReplaceAll(path, "\\", "/"), "/"))
+	if err != nil {
+		return nil, fmt.Errorf("%s %s declares %s '%s' but does not contain it: %w", packageID, version, kind, path, ErrPackageFileNotFound)
+	}
+
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	return io.ReadAll(rc)
+}
+
+// openRemoteNupkg opens the .nupkg at downloadURL for random access with HTTP range
+// requests, starting with its last nupkgTailSize bytes. A server that ignores the Range
+// header sends the whole package, which is then read from memory.
+func openRemoteNupkg(ctx context.Context, client *nugethttp.Client, downloadURL string) (io.ReaderAt, int64, error) {
+	resp, err := getRange(ctx, client, downloadURL, fmt.Sprintf("bytes=-%d", nupkgTailSize))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return bytes.NewReader(data), int64(len(data)), nil
+	}
+
+	start, size, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		return nil, 0, err
+	}
+	return &remoteNupkg{ctx: ctx, client: client, url: downloadURL, tail: data, tailStart: start}, size, nil
+}
+
+// getRange sends a GET request for a byte range of url. The response is either 206 or,
+// from a server without range support, 200.
+func getRange(ctx context.Context, client *nugethttp.Client, url, byteRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Range", byteRange)
+	// Byte offsets refer to the package, not to a compressed transfer of it
+	nugethttp.RequestIdentityEncoding(req)
+
+	resp, err := client.DoWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("package download returned %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// parseContentRange returns the first byte and the complete length from a Content-Range
+// header such as "bytes 1000-1999/2000".
+func parseContentRange(header string) (start, size int64, err error) {
+	spec, ok := strings.CutPrefix(header, "bytes ")
+	byteRange, total, hasTotal := strings.Cut(spec, "/")
+	first, _, hasEnd := strings.Cut(byteRange, "-")
+	if !ok || !hasTotal || !hasEnd {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	if size, err = strconv.ParseInt(total, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	return start, size, nil
+}
+
+// remoteNupkg reads a .nupkg on a server with range requests. Reads within the tail that
+// was fetched when the package was opened are served from memory.
+type remoteNupkg struct {
+	ctx       context.Context
+	client    *nugethttp.Client
+	url       string
+	tail      []byte
+	tailStart int64
+}
+
+// ReadAt implements io.ReaderAt.
+func (n *remoteNupkg) ReadAt(p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if off >= n.tailStart {
+		if off-n.tailStart >= int64(len(n.tail)) {
+			return 0, io.EOF
+		}
+		read := copy(p, n.tail[off-n.tailStart:])
+		if read < len(p) {
+			return read, io.EOF
+		}
+		return read, nil
+	}
+
+	resp, err := getRange(n.ctx, n.client, n.url, fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("server stopped honoring range requests for %s", n.url)
+	}
+
+	read, err := io.ReadFull(resp.Body, p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return read, err
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// buildTestNupkg builds a .nupkg whose nuspec declares readme and icon when they are not
+// empty. A large incompressible file comes first, so the readme and icon lie outside the
+// tail read when the package is opened.
+func buildTestNupkg(t *testing.T, readme, icon string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, content []byte) {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatalf("CreateHeader(%s) error = %v", name, err)
+		}
+		if _, err := w.Write(content); err != nil {
+			t.Fatalf("Write(%s) error = %v", name, err)
+		}
+	}
+
+	padding := make([]byte, 256*1024)
+	_, _ = rand.Read(padding)
+	write("lib/net8.0/Contoso.Lib.dll", padding)
+
+	var files string
+	if readme != "" {
+		files += "<readme>docs\\README.md</readme>"
+		write("docs/README.md", []byte(readme))
+	}
+	if icon != "" {
+		files += "<icon>images/icon.png</icon>"
+		write("images/icon.png", []byte(icon))
+	}
+	write("Contoso.Lib.nuspec", []byte(`<?xml version="1.0" encoding="utf-8"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata>
+    <id>Contoso.Lib</id>
+    <version>1.0.0</version>
+    <authors>Contoso</authors>
+    <description>Contoso library</description>
+    `+files+`
+  </metadata>
+</package>`))
+
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// packageFileFeed is a V3 feed serving one package, with or without the flat container's
+// readme and icon endpoints.
+type packageFileFeed struct {
+	mu            sync.Mutex
+	fileRequests  []string // Requests to the readme and icon endpoints
+	nupkgRequests int
+	nupkgBytes    int64 // Bytes of the .nupkg sent
+}
+
+func newPackageFileFeed(t *testing.T, nupkg []byte, endpoints map[string]string) (*httptest.Server, *packageFileFeed) {
+	t.Helper()
+
+	feed := &packageFileFeed{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch path := r.URL.Path; {
+		case path == "/index.json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": "http://" + r.Host + "/flat/", "@type": "PackageBaseAddress/3.0.0"},
+				},
+			})
+		case path == "/flat/contoso.lib/1.0.0/readme", path == "/flat/contoso.lib/1.0.0/icon":
+			feed.mu.Lock()
+			feed.fileRequests = append(feed.fileRequests, path)
+			feed.mu.Unlock()
+			content, ok := endpoints[path[strings.LastIndex(path, "/")+1:]]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(content))
+		case path == "/flat/contoso.lib/1.0.0/contoso.lib.1.0.0.nupkg":
+			counter := &countingWriter{ResponseWriter: w}
+			http.ServeContent(counter, r, "contoso.lib.1.0.0.nupkg", time.Time{}, bytes.NewReader(nupkg))
+			feed.mu.Lock()
+			feed.nupkgRequests++
+			feed.nupkgBytes += counter.n
+			feed.mu.Unlock()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, feed
+}
+
+// countingWriter counts the body bytes written to a response.
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestSourceRepository_GetReadme_Endpoint(t *testing.T) {
+	server, feed := newPackageFileFeed(t, buildTestNupkg(t, "from package", "icon"), map[string]string{
+		"readme": "# Contoso.Lib",
+		"icon":   "\x89PNG",
+	})
+	repo := NewSourceRepository(RepositoryConfig{Name: "test", SourceURL: server.URL + "/index.json"})
+
+	readme, err := repo.GetReadme(t.Context(), "Contoso.Lib", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetReadme() error = %v", err)
+	}
+	if string(readme) != "# Contoso.Lib" {
+		t.Errorf("GetReadme() = %q, want the endpoint's readme", readme)
+	}
+	icon, err := repo.GetIcon(t.Context(), "Contoso.Lib", "1.0.0")
+	if err != nil || string(icon) != "\x89PNG" {
+		t.Errorf("GetIcon() = %q, %v, want the endpoint's icon", icon, err)
+	}
+
+	if feed.nupkgRequests != 0 {
+		t.Errorf("package requested %d times, want none", feed.nupkgRequests)
+	}
+}
+
+func TestSourceRepository_GetReadme_FallsBackToPackage(t *testing.T) {
+	nupkg := buildTestNupkg(t, "# From the package", "\x89PNG from the package")
+	server, feed := newPackageFileFeed(t, nupkg, nil)
+	repo := NewSourceRepository(RepositoryConfig{Name: "test", SourceURL: server.URL + "/index.json"})
+
+	readme, err := repo.GetReadme(t.Context(), "Contoso.Lib", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetReadme() error = %v", err)
+	}
+	if string(readme) != "# From the package" {
+		t.Errorf("GetReadme() = %q, want the package's readme", readme)
+	}
+	icon, err := repo.GetIcon(t.Context(), "Contoso.Lib", "1.0.0")
+	if err != nil || string(icon) != "\x89PNG from the package" {
+		t.Errorf("GetIcon() = %q, %v, want the package's icon", icon, err)
+	}
+
+	if feed.nupkgBytes >= int64(len(nupkg)) {
+		t.Errorf("read %d bytes of a %d byte package, want only the parts holding the files", feed.nupkgBytes, len(nupkg))
+	}
+
+	// Results are cached
+	requests := feed.nupkgRequests
+	if _, err := repo.GetReadme(t.Context(), "contoso.lib", "1.0.0"); err != nil {
+		t.Fatalf("GetReadme() error = %v", err)
+	}
+	if feed.nupkgRequests != requests || len(feed.fileRequests) != 2 {
+		t.Errorf("cached readme fetched again: %d package requests, endpoint requests %v", feed.nupkgRequests-requests, feed.fileRequests)
+	}
+}
+
+func TestSourceRepository_GetReadme_Absent(t *testing.T) {
+	server, feed := newPackageFileFeed(t, buildTestNupkg(t, "", ""), nil)
+	repo := NewSourceRepository(RepositoryConfig{Name: "test", SourceURL: server.URL + "/index.json"})
+
+	for range 2 {
+		if _, err := repo.GetReadme(t.Context(), "Contoso.Lib", "1.0.0"); !errors.Is(err, ErrPackageFileNotFound) {
+			t.Errorf("GetReadme() error = %v, want ErrPackageFileNotFound", err)
+		}
+	}
+	if _, err := repo.GetIcon(t.Context(), "Contoso.Lib", "1.0.0"); !errors.Is(err, ErrPackageFileNotFound) {
+		t.Errorf("GetIcon() error = %v, want ErrPackageFileNotFound", err)
+	}
+
+	// The missing readme is cached too
+	if len(feed.fileRequests) != 2 {
+		t.Errorf("endpoint requests = %v, want one readme and one icon request", feed.fileRequests)
+	}
+}
@@ -113,10 +113,10 @@ func TestPackageMetadata_GetDependenciesForFramework(t *testing.T) {
 			expectDepID:    "System.Text.Json",
 		},
 		{
-			name:           "net48 picks netstandard2.0 (cross-framework compatible)",
+			name:           "net48 picks net45 (same framework family)",
 			target:         "net48",
 			expectDepCount: 1,
-			expectDepID:    "System.Text.Json",
+			expectDepID:    "Newtonsoft.Json",
 		},
 		{
 			name:           "exact match net45",
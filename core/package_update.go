@@ -0,0 +1,147 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APIKeyHeader carries the API key of push and delete requests.
+const APIKeyHeader = "X-NuGet-ApiKey"
+
+// PackageUpdateError reports a push or delete request the source rejected.
+type PackageUpdateError struct {
+	URL        string
+	StatusCode int
+	Reason     string // Reason phrase sent by the server, such as "Forbidden"
+}
+
+// Error matches the message NuGet prints for an unsuccessful response.
+func (e *PackageUpdateError) Error() string {
+	return fmt.Sprintf("Response status code does not indicate success: %d (%s).", e.StatusCode, e.Reason)
+}
+
+// packageUpdater is implemented by providers of sources that accept package updates.
+type packageUpdater interface {
+	PackageUpdateURL(ctx context.Context) (string, error)
+}
+
+// PackageUpdateURL returns the endpoint packages are pushed to and deleted from: the
+// PackagePublish resource of a V3 source, or the feed of a V2 source.
+func (r *SourceRepository) PackageUpdateURL(ctx context.Context) (string, error) {
+	provider, err := r.GetProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+	updater, ok := provider.(packageUpdater)
+	if !ok {
+		return "", fmt.Errorf("source %s does not support pushing or deleting packages", r.sourceURL)
+	}
+	return updater.PackageUpdateURL(ctx)
+}
+
+// PackageDeleteURL returns the URL a package version is deleted with.
+func (r *SourceRepository) PackageDeleteURL(ctx context.Context, packageID, version string) (string, error) {
+	updateURL, err := r.PackageUpdateURL(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(updateURL, "/") + "/" + url.PathEscape(packageID) + "/" + url.PathEscape(version), nil
+}
+
+// DeletePackage deletes a package version from the source. nuget.org unlists the
+// package instead, as do feeds configured to; most private feeds delete it. A response
+// other than 2xx is returned as a *PackageUpdateError.
+// Reference: PackageUpdateResource.Delete
+func (r *SourceRepository) DeletePackage(ctx context.Context, packageID, version, apiKey string) error {
+	deleteURL, err := r.PackageDeleteURL(ctx, packageID, version)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, deleteURL, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+
+	r.mu.RLock()
+	httpClient := r.authenticatedClient()
+	r.mu.RUnlock()
+
+	resp, err := httpClient.DoWithRetry(ctx, req)
+	if err != nil {
+		return fmt.Errorf("delete %s %s: %w", packageID, version, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &PackageUpdateError{URL: deleteURL, StatusCode: resp.StatusCode, Reason: reasonPhrase(resp)}
+	}
+	return nil
+}
+
+// PushPackage uploads a .nupkg to the source as multipart/form-data, the way NuGet
+// pushes. A response other than 2xx, such as 409 for a version the source already has,
+// is returned as a *PackageUpdateError.
+// Reference: PackageUpdateResource.PushPackageToServer
+func (r *SourceRepository) PushPackage(ctx context.Context, packageData io.Reader, apiKey string) error {
+	pushURL, err := r.PackageUpdateURL(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The body is buffered so the request can be replayed for an authentication challenge
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("package", "package.nupkg")
+	if err != nil {
+		return fmt.Errorf("create request body: %w", err)
+	}
+	if _, err := io.Copy(part, packageData); err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("create request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, pushURL, &body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if apiKey != "" {
+		req.Header.Set(APIKeyHeader, apiKey)
+	}
+
+	r.mu.RLock()
+	httpClient := r.authenticatedClient()
+	r.mu.RUnlock()
+
+	resp, err := httpClient.DoWithRetry(ctx, req)
+	if err != nil {
+		return fmt.Errorf("push to %s: %w", pushURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &PackageUpdateError{URL: pushURL, StatusCode: resp.StatusCode, Reason: reasonPhrase(resp)}
+	}
+	return nil
+}
+
+// reasonPhrase returns the reason phrase of a response: the status line after the code,
+// which servers such as nuget.org use to explain a rejection.
+func reasonPhrase(resp *http.Response) string {
+	if reason := strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprint(resp.StatusCode))); reason != "" {
+		return reason
+	}
+	return http.StatusText(resp.StatusCode)
+}
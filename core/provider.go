@@ -2,13 +2,17 @@ package core
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/willibrandon/gonuget/auth"
 	"github.com/willibrandon/gonuget/cache"
 	nugethttp "github.com/willibrandon/gonuget/http"
 	"github.com/willibrandon/gonuget/observability"
@@ -51,6 +55,7 @@ type ProtocolMetadata struct {
 	Owners                   []string
 	IconURL                  string
 	LicenseURL               string
+	ReadmeURL                string // Where the feed serves the readme, when it says
 	LicenseExpression        string
 	ProjectURL               string
 	Tags                     []string
@@ -62,6 +67,36 @@ type ProtocolMetadata struct {
 	DownloadURL              string
 }
 
+// unlistedPublishedDate prefixes the published date NuGet feeds report for unlisted packages
+const unlistedPublishedDate = "1900-01-01"
+
+// isUnlistedPublishedDate checks if a published date marks an unlisted package
+func isUnlistedPublishedDate(published string) bool {
+	return strings.HasPrefix(published, unlistedPublishedDate)
+}
+
+// listedVersionLister is implemented by providers that can tell listed versions from
+// unlisted ones
+type listedVersionLister interface {
+	ListListedVersions(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID string) ([]string, error)
+}
+
+// getCachedJSON returns the cached entry for cacheKey decoded from JSON. An entry that
+// doesn't decode is corrupt: it is discarded and reported as a miss so the caller refetches.
+func getCachedJSON[T any](ctx context.Context, mtCache *cache.MultiTierCache, sourceURL, cacheKey string, maxAge time.Duration) (T, bool) {
+	var value T
+	cached, hit, err := mtCache.Get(ctx, sourceURL, cacheKey, maxAge)
+	if err != nil || !hit {
+		return value, false
+	}
+	if err := json.Unmarshal(cached, &value); err != nil {
+		mtCache.Invalidate(ctx, sourceURL, cacheKey, err)
+		var zero T
+		return zero, false
+	}
+	return value, true
+}
+
 // ProtocolDependencyGroup represents dependencies for a target framework (string-based)
 type ProtocolDependencyGroup struct {
 	TargetFramework string
@@ -121,9 +156,6 @@ func getConcreteClient(client HTTPClient) *nugethttp.Client {
 	if c, ok := client.(*nugethttp.Client); ok {
 		return c
 	}
-	if ac, ok := client.(*authenticatedHTTPClient); ok {
-		return ac.base
-	}
 	// This should never happen if the interface is used correctly
 	return nil
 }
@@ -138,14 +170,16 @@ func (f *ProviderFactory) CreateProvider(ctx context.Context, sourceURL string)
 	// nuget.org V3 is the fastest protocol, always use it when available
 
 	// Fast-path for nuget.org V3 URL (already V3, no detection needed)
-	if strings.Contains(sourceURL, "api.nuget.org/v3/index.json") {
+	// The host is parsed, so a lookalike host or a path that embeds the URL isn't taken for nuget.org
+	isNuGetOrg := nugethttp.IsNuGetOrgURL(sourceURL)
+	if isNuGetOrg && strings.Contains(strings.ToLower(sourceURL), "api.nuget.org/v3/index.json") {
 		span.SetAttributes(attribute.String("protocol.fastpath", "nuget.org-v3-direct"))
 		return NewV3ResourceProvider(sourceURL, f.httpClient, f.cache), nil
 	}
 
 	// Fast-path for nuget.org V2 URL -> use V3 protocol (30-40% faster)
 	// nuget.org supports both V2 and V3, but V3 is significantly faster (JSON vs XML)
-	if strings.Contains(sourceURL, "nuget.org/api/v2") {
+	if isNuGetOrg && strings.Contains(strings.ToLower(sourceURL), "nuget.org/api/v2") {
 		span.SetAttributes(attribute.String("protocol.fastpath", "nuget.org-v2-to-v3"))
 		// Keep original V2 URL as sourceURL for repository matching
 		// Use V3 service index URL for actual API calls
@@ -158,8 +192,8 @@ func (f *ProviderFactory) CreateProvider(ctx context.Context, sourceURL string)
 	}
 
 	// Extract concrete client for protocol detection
-	// Note: If httpClient is already authenticated, the concrete client will still be wrapped
-	// by the authenticatedHTTPClient, so protocol detection requests will be authenticated
+	// Note: An authenticated repository passes a client created with WithAuthenticator,
+	// so protocol detection requests are authenticated as well
 	concreteClient := getConcreteClient(f.httpClient)
 	if concreteClient == nil {
 		return nil, fmt.Errorf("invalid HTTP client")
@@ -172,13 +206,22 @@ func (f *ProviderFactory) CreateProvider(ctx context.Context, sourceURL string)
 	// Try v3 first (modern protocol) - make direct HTTP call with authentication
 	// Use source URL as-is for NuGet.Client parity (M6.1)
 	// Callers must provide full service index URL (e.g., https://api.nuget.org/v3/index.json)
+	// An HTML page (a web site, or a sign-in or proxy page) means the source is misconfigured
+	var htmlErr error
 	resp, err := f.httpClient.Get(ctx, sourceURL)
+	// A source requiring authentication this platform lacks won't answer the V2 probe either
+	if errors.Is(err, auth.ErrNegotiateUnsupported) {
+		return nil, err
+	}
 	if err == nil {
+		if resp.StatusCode == http.StatusOK {
+			htmlErr = nugethttp.DetectHTMLPage(resp, nugethttp.ContentJSON)
+		}
 		_ = resp.Body.Close()
 		if resp.StatusCode == http.StatusOK {
 			contentType := resp.Header.Get("Content-Type")
 			// V3 service index should return JSON
-			if strings.Contains(contentType, "json") {
+			if htmlErr == nil && strings.Contains(contentType, "json") {
 				// V3 feed detected
 				return NewV3ResourceProvider(sourceURL, f.httpClient, f.cache), nil
 			}
@@ -188,6 +231,9 @@ func (f *ProviderFactory) CreateProvider(ctx context.Context, sourceURL string)
 	// Try v2 - make direct HTTP call with authentication
 	// For V2 detection, strip /index.json if present (V2 doesn't use service index)
 	v2URL := strings.TrimSuffix(sourceURL, "/index.json")
+	if htmlErr != nil && v2URL == sourceURL {
+		return nil, htmlErr
+	}
 
 	resp, err = f.httpClient.Get(ctx, v2URL)
 	if err == nil {
@@ -202,9 +248,62 @@ func (f *ProviderFactory) CreateProvider(ctx context.Context, sourceURL string)
 		}
 	}
 
+	if htmlErr != nil {
+		return nil, htmlErr
+	}
 	return nil, fmt.Errorf("unable to detect protocol version for %s", sourceURL)
 }
 
+// CreateProviderForProtocol creates a resource provider for a source with the protocolVersion
+// configured in NuGet.config. An empty protocolVersion detects the protocol like CreateProvider.
+// "2" creates a v2 provider without probing for a service index. "3" requires the service
+// index: it is fetched with the client's retry policy, and a source whose index can't be
+// loaded fails instead of falling back to v2.
+func (f *ProviderFactory) CreateProviderForProtocol(ctx context.Context, sourceURL, protocolVersion string) (ResourceProvider, error) {
+	switch protocolVersion {
+	case "":
+		return f.CreateProvider(ctx, sourceURL)
+	case "2":
+		return f.CreateV2Provider(sourceURL), nil
+	case "3":
+		// nuget.org's service index is always available
+		if strings.Contains(sourceURL, "api.nuget.org/v3/index.json") {
+			return f.CreateV3Provider(sourceURL), nil
+		}
+		if err := f.probeServiceIndex(ctx, sourceURL); err != nil {
+			return nil, fmt.Errorf("unable to load the service index for source %s (protocolVersion 3): %w", sourceURL, err)
+		}
+		return f.CreateV3Provider(sourceURL), nil
+	default:
+		return nil, fmt.Errorf("unsupported protocolVersion %q for source %s (expected 2 or 3)", protocolVersion, sourceURL)
+	}
+}
+
+// probeServiceIndex checks that sourceURL serves a V3 service index, retrying transient failures
+func (f *ProviderFactory) probeServiceIndex(ctx context.Context, sourceURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := f.httpClient.DoWithRetry(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("service index returned %d", resp.StatusCode)
+	}
+	if err := nugethttp.DetectHTMLPage(resp, nugethttp.ContentJSON); err != nil {
+		return err
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.Contains(contentType, "json") {
+		return fmt.Errorf("service index returned content type %q, expected JSON", contentType)
+	}
+	return nil
+}
+
 // CreateV3Provider creates a v3 resource provider (no detection)
 func (f *ProviderFactory) CreateV3Provider(sourceURL string) ResourceProvider {
 	return NewV3ResourceProvider(sourceURL, f.httpClient, f.cache)
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	nugethttp "github.com/willibrandon/gonuget/http"
 	"github.com/willibrandon/gonuget/protocol/v3"
@@ -115,6 +117,92 @@ func TestProviderFactory_CreateProvider_Unknown(t *testing.T) {
 	}
 }
 
+func TestProviderFactory_CreateProviderForProtocol_V3RetriesWithoutV2Fallback(t *testing.T) {
+	var indexRequests, v2Requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.json" {
+			indexRequests.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// A V2 feed is also served here; protocolVersion 3 must never use it
+		v2Requests.Add(1)
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><service xmlns="http://www.w3.org/2007/app"/>`))
+	}))
+	defer server.Close()
+
+	httpClient := nugethttp.NewClient(&nugethttp.Config{
+		RetryConfig: &nugethttp.RetryConfig{
+			MaxRetries:     2,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+			BackoffFactor:  1,
+		},
+	})
+	factory := NewProviderFactory(httpClient, nil)
+
+	_, err := factory.CreateProviderForProtocol(context.Background(), server.URL+"/index.json", "3")
+	if err == nil {
+		t.Fatal("CreateProviderForProtocol() expected error for unreachable service index")
+	}
+	if !strings.Contains(err.Error(), "service index") || !strings.Contains(err.Error(), "protocolVersion 3") {
+		t.Errorf("error = %q, want a V3 service index error", err)
+	}
+	if got := indexRequests.Load(); got != 3 {
+		t.Errorf("service index requests = %d, want 3 (1 attempt + 2 retries)", got)
+	}
+	if got := v2Requests.Load(); got != 0 {
+		t.Errorf("V2 requests = %d, want 0", got)
+	}
+}
+
+func TestProviderFactory_CreateProviderForProtocol_V3(t *testing.T) {
+	server := setupV3TestServer()
+	defer server.Close()
+
+	factory := NewProviderFactory(nugethttp.NewClient(nil), nil)
+
+	provider, err := factory.CreateProviderForProtocol(context.Background(), server.URL+"/index.json", "3")
+	if err != nil {
+		t.Fatalf("CreateProviderForProtocol() error = %v", err)
+	}
+	if provider.ProtocolVersion() != "v3" {
+		t.Errorf("ProtocolVersion() = %q, want v3", provider.ProtocolVersion())
+	}
+}
+
+func TestProviderFactory_CreateProviderForProtocol_V2SkipsServiceIndex(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	factory := NewProviderFactory(nugethttp.NewClient(nil), nil)
+
+	provider, err := factory.CreateProviderForProtocol(context.Background(), server.URL+"/index.json", "2")
+	if err != nil {
+		t.Fatalf("CreateProviderForProtocol() error = %v", err)
+	}
+	if provider.ProtocolVersion() != "v2" {
+		t.Errorf("ProtocolVersion() = %q, want v2", provider.ProtocolVersion())
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("requests = %d, want 0 (no protocol probe)", got)
+	}
+}
+
+func TestProviderFactory_CreateProviderForProtocol_Invalid(t *testing.T) {
+	factory := NewProviderFactory(nugethttp.NewClient(nil), nil)
+
+	_, err := factory.CreateProviderForProtocol(context.Background(), "https://example.com/index.json", "4")
+	if err == nil {
+		t.Error("CreateProviderForProtocol() expected error for unsupported protocolVersion")
+	}
+}
+
 func TestProviderFactory_CreateV3Provider(t *testing.T) {
 	httpClient := nugethttp.NewClient(nil)
 	factory := NewProviderFactory(httpClient, nil)
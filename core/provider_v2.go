@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
 	"strings"
 
 	"github.com/willibrandon/gonuget/cache"
@@ -26,14 +27,9 @@ type V2ResourceProvider struct {
 // mtCache can be nil if caching is not desired
 func NewV2ResourceProvider(sourceURL string, httpClient HTTPClient, mtCache *cache.MultiTierCache) *V2ResourceProvider {
 	// Type assert to *nugethttp.Client for protocol clients
-	// This is safe because HTTPClient interface is implemented by *nugethttp.Client
-	// and authenticatedHTTPClient which wraps it
-	var client *nugethttp.Client
-	if c, ok := httpClient.(*nugethttp.Client); ok {
-		client = c
-	} else if ac, ok := httpClient.(*authenticatedHTTPClient); ok {
-		client = ac.base
-	}
+	// Authenticated repositories pass a client created with WithAuthenticator,
+	// so every protocol request carries the source's credentials
+	client, _ := httpClient.(*nugethttp.Client)
 
 	return &V2ResourceProvider{
 		sourceURL:      sourceURL,
@@ -49,18 +45,14 @@ func NewV2ResourceProvider(sourceURL string, httpClient HTTPClient, mtCache *cac
 func (p *V2ResourceProvider) FindPackagesByID(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID string) ([]*ProtocolMetadata, error) {
 	// Use default cache context if none provided
 	if cacheCtx == nil {
-		cacheCtx = cache.NewSourceCacheContext()
+		cacheCtx = cache.FromContextOrNew(ctx)
 	}
 
 	// Check cache if enabled
 	if p.cache != nil && !cacheCtx.NoCache {
 		cacheKey := fmt.Sprintf("findpackagesbyid:%s", packageID)
-		cached, hit, err := p.cache.Get(ctx, p.sourceURL, cacheKey, cacheCtx.MaxAge)
-		if err == nil && hit {
-			var packages []*ProtocolMetadata
-			if err := json.Unmarshal(cached, &packages); err == nil {
-				return packages, nil
-			}
+		if packages, hit := getCachedJSON[[]*ProtocolMetadata](ctx, p.cache, p.sourceURL, cacheKey, cacheCtx.MaxAge); hit {
+			return packages, nil
 		}
 	}
 
@@ -121,18 +113,14 @@ func (p *V2ResourceProvider) FindPackagesByID(ctx context.Context, cacheCtx *cac
 func (p *V2ResourceProvider) GetMetadata(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID, version string) (*ProtocolMetadata, error) {
 	// Use default cache context if none provided
 	if cacheCtx == nil {
-		cacheCtx = cache.NewSourceCacheContext()
+		cacheCtx = cache.FromContextOrNew(ctx)
 	}
 
 	// Check cache if enabled
 	if p.cache != nil && !cacheCtx.NoCache {
 		cacheKey := fmt.Sprintf("metadata:%s:%s", packageID, version)
-		cached, hit, err := p.cache.Get(ctx, p.sourceURL, cacheKey, cacheCtx.MaxAge)
-		if err == nil && hit {
-			var metadata ProtocolMetadata
-			if err := json.Unmarshal(cached, &metadata); err == nil {
-				return &metadata, nil
-			}
+		if metadata, hit := getCachedJSON[ProtocolMetadata](ctx, p.cache, p.sourceURL, cacheKey, cacheCtx.MaxAge); hit {
+			return &metadata, nil
 		}
 	}
 
@@ -279,18 +267,14 @@ func parseDependencies(deps string) []ProtocolDependencyGroup {
 func (p *V2ResourceProvider) ListVersions(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID string) ([]string, error) {
 	// Use default cache context if none provided
 	if cacheCtx == nil {
-		cacheCtx = cache.NewSourceCacheContext()
+		cacheCtx = cache.FromContextOrNew(ctx)
 	}
 
 	// Check cache if enabled
 	if p.cache != nil && !cacheCtx.NoCache {
 		cacheKey := fmt.Sprintf("versions:%s", packageID)
-		cached, hit, err := p.cache.Get(ctx, p.sourceURL, cacheKey, cacheCtx.MaxAge)
-		if err == nil && hit {
-			var versions []string
-			if err := json.Unmarshal(cached, &versions); err == nil {
-				return versions, nil
-			}
+		if versions, hit := getCachedJSON[[]string](ctx, p.cache, p.sourceURL, cacheKey, cacheCtx.MaxAge); hit {
+			return versions, nil
 		}
 	}
 
@@ -311,22 +295,35 @@ func (p *V2ResourceProvider) ListVersions(ctx context.Context, cacheCtx *cache.S
 	return versions, nil
 }
 
+// ListListedVersions lists the versions of a package that are listed. V2 feeds report
+// unlisted packages with a 1900-01-01 published date.
+func (p *V2ResourceProvider) ListListedVersions(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID string) ([]string, error) {
+	packages, err := p.FindPackagesByID(ctx, cacheCtx, packageID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		if pkg.Version != "" && !isUnlistedPublishedDate(pkg.Published) {
+			versions = append(versions, pkg.Version)
+		}
+	}
+	return versions, nil
+}
+
 // Search searches for packages matching the query
 func (p *V2ResourceProvider) Search(ctx context.Context, cacheCtx *cache.SourceCacheContext, query string, opts SearchOptions) ([]SearchResult, error) {
 	// Use default cache context if none provided
 	if cacheCtx == nil {
-		cacheCtx = cache.NewSourceCacheContext()
+		cacheCtx = cache.FromContextOrNew(ctx)
 	}
 
 	// Check cache if enabled
 	if p.cache != nil && !cacheCtx.NoCache {
 		cacheKey := fmt.Sprintf("search:%s:%d:%d:%t", query, opts.Skip, opts.Take, opts.IncludePrerelease)
-		cached, hit, err := p.cache.Get(ctx, p.sourceURL, cacheKey, cacheCtx.MaxAge)
-		if err == nil && hit {
-			var results []SearchResult
-			if err := json.Unmarshal(cached, &results); err == nil {
-				return results, nil
-			}
+		if results, hit := getCachedJSON[[]SearchResult](ctx, p.cache, p.sourceURL, cacheKey, cacheCtx.MaxAge); hit {
+			return results, nil
 		}
 	}
 
@@ -382,7 +379,7 @@ func (p *V2ResourceProvider) Search(ctx context.Context, cacheCtx *cache.SourceC
 func (p *V2ResourceProvider) DownloadPackage(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID, version string) (io.ReadCloser, error) {
 	// Use default cache context if none provided
 	if cacheCtx == nil {
-		cacheCtx = cache.NewSourceCacheContext()
+		cacheCtx = cache.FromContextOrNew(ctx)
 	}
 
 	// Check cache if enabled
@@ -428,6 +425,25 @@ func (p *V2ResourceProvider) DownloadPackage(ctx context.Context, cacheCtx *cach
 	return io.NopCloser(bytes.NewReader(packageData)), nil
 }
 
+// PackageDownloadURL returns the URL the .nupkg of a package version is downloaded from
+func (p *V2ResourceProvider) PackageDownloadURL(_ context.Context, packageID, version string) (string, error) {
+	return p.downloadClient.PackageDownloadURL(p.sourceURL, packageID, version)
+}
+
+// PackageUpdateURL returns the endpoint packages are pushed to and deleted from: the
+// source itself, or {host}/api/v2/package for a source given as a bare host.
+// Reference: PackageUpdateResource.GetServiceEndpointUrl
+func (p *V2ResourceProvider) PackageUpdateURL(_ context.Context) (string, error) {
+	u, err := url.Parse(p.sourceURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid source URL %s: %w", p.sourceURL, err)
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = "/api/v2/package"
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
 // SourceURL returns the source URL
 func (p *V2ResourceProvider) SourceURL() string {
 	return p.sourceURL
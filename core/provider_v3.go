@@ -11,17 +11,20 @@ import (
 	"github.com/willibrandon/gonuget/cache"
 	nugethttp "github.com/willibrandon/gonuget/http"
 	"github.com/willibrandon/gonuget/protocol/v3"
+	"github.com/willibrandon/gonuget/version"
 )
 
 // V3ResourceProvider implements ResourceProvider for NuGet v3 feeds
 type V3ResourceProvider struct {
-	sourceURL          string // Repository source URL (for matching)
-	serviceIndexURL    string // V3 service index URL (for API calls)
-	serviceIndexClient *v3.ServiceIndexClient
-	searchClient       *v3.SearchClient
-	metadataClient     *v3.MetadataClient
-	downloadClient     *v3.DownloadClient
-	cache              *cache.MultiTierCache
+	sourceURL           string // Repository source URL (for matching)
+	serviceIndexURL     string // V3 service index URL (for API calls)
+	serviceIndexClient  *v3.ServiceIndexClient
+	searchClient        *v3.SearchClient
+	autocompleteClient  *v3.AutocompleteClient
+	metadataClient      *v3.MetadataClient
+	downloadClient      *v3.DownloadClient
+	vulnerabilityClient *v3.VulnerabilityClient
+	cache               *cache.MultiTierCache
 }
 
 // NewV3ResourceProvider creates a new v3 resource provider
@@ -38,14 +41,9 @@ func NewV3ResourceProvider(sourceURL string, httpClient HTTPClient, mtCache *cac
 // Used for nuget.org fast-path: sourceURL="https://www.nuget.org/api/v2", serviceIndexURL="https://api.nuget.org/v3/index.json"
 func NewV3ResourceProviderWithServiceIndex(sourceURL, serviceIndexURL string, httpClient HTTPClient, mtCache *cache.MultiTierCache) *V3ResourceProvider {
 	// Type assert to *nugethttp.Client for protocol clients
-	// This is safe because HTTPClient interface is implemented by *nugethttp.Client
-	// and authenticatedHTTPClient which wraps it
-	var client *nugethttp.Client
-	if c, ok := httpClient.(*nugethttp.Client); ok {
-		client = c
-	} else if ac, ok := httpClient.(*authenticatedHTTPClient); ok {
-		client = ac.base
-	}
+	// Authenticated repositories pass a client created with WithAuthenticator,
+	// so every protocol request carries the source's credentials
+	client, _ := httpClient.(*nugethttp.Client)
 
 	// Pass cache to service index client for disk caching (critical for first-run performance)
 	serviceIndexClient := v3.NewServiceIndexClientWithCache(client, mtCache)
@@ -63,14 +61,20 @@ func NewV3ResourceProviderWithServiceIndex(sourceURL, serviceIndexURL string, ht
 		}
 	}
 
+	// Share the cached registration client for sources without a flat container
+	downloadClient := v3.NewDownloadClient(client, serviceIndexClient)
+	downloadClient.SetMetadataClient(metadataClient)
+
 	return &V3ResourceProvider{
-		sourceURL:          sourceURL,
-		serviceIndexURL:    serviceIndexURL,
-		serviceIndexClient: serviceIndexClient,
-		searchClient:       v3.NewSearchClient(client, serviceIndexClient),
-		metadataClient:     metadataClient,
-		downloadClient:     v3.NewDownloadClient(client, serviceIndexClient),
-		cache:              mtCache,
+		sourceURL:           sourceURL,
+		serviceIndexURL:     serviceIndexURL,
+		serviceIndexClient:  serviceIndexClient,
+		searchClient:        v3.NewSearchClient(client, serviceIndexClient),
+		autocompleteClient:  v3.NewAutocompleteClient(client, serviceIndexClient),
+		metadataClient:      metadataClient,
+		downloadClient:      downloadClient,
+		vulnerabilityClient: v3.NewVulnerabilityClient(client, serviceIndexClient),
+		cache:               mtCache,
 	}
 }
 
@@ -78,7 +82,7 @@ func NewV3ResourceProviderWithServiceIndex(sourceURL, serviceIndexURL string, ht
 func (p *V3ResourceProvider) GetMetadata(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID, version string) (*ProtocolMetadata, error) {
 	// Use default cache context if none provided
 	if cacheCtx == nil {
-		cacheCtx = cache.NewSourceCacheContext()
+		cacheCtx = cache.FromContextOrNew(ctx)
 	}
 
 	// Store cache context in Go context for protocol layer to access
@@ -87,12 +91,8 @@ func (p *V3ResourceProvider) GetMetadata(ctx context.Context, cacheCtx *cache.So
 	// Check cache if enabled
 	if p.cache != nil && !cacheCtx.NoCache {
 		cacheKey := fmt.Sprintf("metadata:%s:%s", packageID, version)
-		cached, hit, err := p.cache.Get(ctx, p.sourceURL, cacheKey, cacheCtx.MaxAge)
-		if err == nil && hit {
-			var metadata ProtocolMetadata
-			if err := json.Unmarshal(cached, &metadata); err == nil {
-				return &metadata, nil
-			}
+		if metadata, hit := getCachedJSON[ProtocolMetadata](ctx, p.cache, p.sourceURL, cacheKey, cacheCtx.MaxAge); hit {
+			return &metadata, nil
 		}
 	}
 
@@ -111,6 +111,7 @@ func (p *V3ResourceProvider) GetMetadata(ctx context.Context, cacheCtx *cache.So
 		Summary:                  catalog.Summary,
 		IconURL:                  catalog.IconURL,
 		LicenseURL:               catalog.LicenseURL,
+		ReadmeURL:                catalog.ReadmeURL,
 		LicenseExpression:        catalog.LicenseExpression,
 		ProjectURL:               catalog.ProjectURL,
 		RequireLicenseAcceptance: catalog.RequireLicenseAcceptance,
@@ -159,7 +160,7 @@ func (p *V3ResourceProvider) GetMetadata(ctx context.Context, cacheCtx *cache.So
 func (p *V3ResourceProvider) ListVersions(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID string) ([]string, error) {
 	// Use default cache context if none provided
 	if cacheCtx == nil {
-		cacheCtx = cache.NewSourceCacheContext()
+		cacheCtx = cache.FromContextOrNew(ctx)
 	}
 
 	// Store cache context in Go context for protocol layer to access
@@ -168,12 +169,8 @@ func (p *V3ResourceProvider) ListVersions(ctx context.Context, cacheCtx *cache.S
 	// Check cache if enabled
 	if p.cache != nil && !cacheCtx.NoCache {
 		cacheKey := fmt.Sprintf("versions:%s", packageID)
-		cached, hit, err := p.cache.Get(ctx, p.sourceURL, cacheKey, cacheCtx.MaxAge)
-		if err == nil && hit {
-			var versions []string
-			if err := json.Unmarshal(cached, &versions); err == nil {
-				return versions, nil
-			}
+		if versions, hit := getCachedJSON[[]string](ctx, p.cache, p.sourceURL, cacheKey, cacheCtx.MaxAge); hit {
+			return versions, nil
 		}
 	}
 
@@ -194,11 +191,38 @@ func (p *V3ResourceProvider) ListVersions(ctx context.Context, cacheCtx *cache.S
 	return versions, nil
 }
 
+// ListListedVersions lists the versions of a package that are listed, using the
+// listed flag of the registration entries and, for feeds that omit it, the
+// 1900-01-01 published date of unlisted packages.
+func (p *V3ResourceProvider) ListListedVersions(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID string) ([]string, error) {
+	if cacheCtx == nil {
+		cacheCtx = cache.FromContextOrNew(ctx)
+	}
+	ctx = cache.WithCacheContext(ctx, cacheCtx)
+
+	index, err := p.metadataClient.GetPackageMetadata(ctx, p.serviceIndexURL, packageID)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, page := range index.Items {
+		for _, leaf := range page.Items {
+			entry := leaf.CatalogEntry
+			if entry == nil || (entry.Listed != nil && !*entry.Listed) || isUnlistedPublishedDate(entry.Published) {
+				continue
+			}
+			versions = append(versions, entry.Version)
+		}
+	}
+	return versions, nil
+}
+
 // Search searches for packages matching the query
 func (p *V3ResourceProvider) Search(ctx context.Context, cacheCtx *cache.SourceCacheContext, query string, opts SearchOptions) ([]SearchResult, error) {
 	// Use default cache context if none provided
 	if cacheCtx == nil {
-		cacheCtx = cache.NewSourceCacheContext()
+		cacheCtx = cache.FromContextOrNew(ctx)
 	}
 
 	// Store cache context in Go context for protocol layer to access
@@ -207,12 +231,8 @@ func (p *V3ResourceProvider) Search(ctx context.Context, cacheCtx *cache.SourceC
 	// Check cache if enabled
 	if p.cache != nil && !cacheCtx.NoCache {
 		cacheKey := fmt.Sprintf("search:%s:%d:%d:%t", query, opts.Skip, opts.Take, opts.IncludePrerelease)
-		cached, hit, err := p.cache.Get(ctx, p.sourceURL, cacheKey, cacheCtx.MaxAge)
-		if err == nil && hit {
-			var results []SearchResult
-			if err := json.Unmarshal(cached, &results); err == nil {
-				return results, nil
-			}
+		if results, hit := getCachedJSON[[]SearchResult](ctx, p.cache, p.sourceURL, cacheKey, cacheCtx.MaxAge); hit {
+			return results, nil
 		}
 	}
 
@@ -257,11 +277,20 @@ func (p *V3ResourceProvider) Search(ctx context.Context, cacheCtx *cache.SourceC
 	return results, nil
 }
 
+// AutocompletePackageIDs returns package IDs starting with query from the autocomplete resource
+func (p *V3ResourceProvider) AutocompletePackageIDs(ctx context.Context, query string, take int, includePrerelease bool) ([]string, error) {
+	resp, err := p.autocompleteClient.AutocompletePackageIDs(ctx, p.serviceIndexURL, query, 0, take, includePrerelease)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, nil
+}
+
 // DownloadPackage downloads a .nupkg file
 func (p *V3ResourceProvider) DownloadPackage(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID, version string) (io.ReadCloser, error) {
 	// Use default cache context if none provided
 	if cacheCtx == nil {
-		cacheCtx = cache.NewSourceCacheContext()
+		cacheCtx = cache.FromContextOrNew(ctx)
 	}
 
 	// Store cache context in Go context for protocol layer to access
@@ -310,6 +339,56 @@ func (p *V3ResourceProvider) DownloadPackage(ctx context.Context, cacheCtx *cach
 	return io.NopCloser(bytes.NewReader(packageData)), nil
 }
 
+// PackageDownloadURL returns the URL the .nupkg of a package version is downloaded from
+func (p *V3ResourceProvider) PackageDownloadURL(ctx context.Context, packageID, version string) (string, error) {
+	return p.downloadClient.PackageDownloadURL(ctx, p.serviceIndexURL, packageID, version)
+}
+
+// PackageReadmeURL returns the URL the flat container serves the readme of a package version from
+func (p *V3ResourceProvider) PackageReadmeURL(ctx context.Context, packageID, version string) (string, error) {
+	return p.downloadClient.PackageReadmeURL(ctx, p.serviceIndexURL, packageID, version)
+}
+
+// PackageIconURL returns the URL the flat container serves the icon of a package version from
+func (p *V3ResourceProvider) PackageIconURL(ctx context.Context, packageID, version string) (string, error) {
+	return p.downloadClient.PackageIconURL(ctx, p.serviceIndexURL, packageID, version)
+}
+
+// PackageUpdateURL returns the PackagePublish endpoint packages are pushed to and
+// deleted from. A source without the resource doesn't accept updates.
+func (p *V3ResourceProvider) PackageUpdateURL(ctx context.Context) (string, error) {
+	publishURL, err := p.serviceIndexClient.GetResourceURL(ctx, p.serviceIndexURL, v3.ResourceTypePackagePublish)
+	if err != nil {
+		return "", fmt.Errorf("source %s does not support pushing or deleting packages: %w", p.sourceURL, err)
+	}
+	return publishURL, nil
+}
+
+// GetVulnerabilities returns the known vulnerabilities the source publishes with its
+// VulnerabilityInfo resource, keyed by lowercase package ID; nil without the resource
+func (p *V3ResourceProvider) GetVulnerabilities(ctx context.Context) (map[string][]PackageVulnerability, error) {
+	published, err := p.vulnerabilityClient.GetVulnerabilities(ctx, p.serviceIndexURL)
+	if err != nil || published == nil {
+		return nil, err
+	}
+
+	vulnerabilities := make(map[string][]PackageVulnerability, len(published))
+	for id, entries := range published {
+		for _, entry := range entries {
+			versions, err := version.ParseVersionRange(entry.Versions)
+			if err != nil {
+				continue
+			}
+			vulnerabilities[id] = append(vulnerabilities[id], PackageVulnerability{
+				AdvisoryURL: entry.URL,
+				Severity:    VulnerabilitySeverity(entry.Severity),
+				Versions:    versions,
+			})
+		}
+	}
+	return vulnerabilities, nil
+}
+
 // SourceURL returns the source URL
 func (p *V3ResourceProvider) SourceURL() string {
 	return p.sourceURL
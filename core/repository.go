@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"sync"
 
 	"github.com/willibrandon/gonuget/auth"
@@ -17,23 +16,29 @@ import (
 type SourceRepository struct {
 	name            string
 	sourceURL       string
+	protocolVersion string
 	authenticator   auth.Authenticator
+	credentials     *CredentialCache
 	httpClient      *nugethttp.Client
 	providerFactory *ProviderFactory
 	logger          observability.Logger
 
 	mu       sync.RWMutex
 	provider ResourceProvider
+
+	packageFiles sync.Map // "readme:id:version" or "icon:id:version" -> *packageFile
 }
 
 // RepositoryConfig holds source repository configuration
 type RepositoryConfig struct {
-	Name          string
-	SourceURL     string
-	Authenticator auth.Authenticator
-	HTTPClient    *nugethttp.Client
-	Cache         *cache.MultiTierCache // Optional cache (nil disables caching)
-	Logger        observability.Logger  // Optional logger (nil uses NullLogger)
+	Name            string
+	SourceURL       string
+	ProtocolVersion string // protocolVersion from NuGet.config: "2", "3", or empty to detect the protocol
+	Authenticator   auth.Authenticator
+	Credentials     *CredentialCache // Optional per-process credential cache consulted on 401 (nil disables)
+	HTTPClient      *nugethttp.Client
+	Cache           *cache.MultiTierCache // Optional cache (nil disables caching)
+	Logger          observability.Logger  // Optional logger (nil uses observability.DefaultLogger)
 }
 
 // NewSourceRepository creates a new source repository
@@ -45,13 +50,15 @@ func NewSourceRepository(cfg RepositoryConfig) *SourceRepository {
 
 	logger := cfg.Logger
 	if logger == nil {
-		logger = observability.NewNullLogger()
+		logger = observability.DefaultLogger()
 	}
 
 	return &SourceRepository{
 		name:            cfg.Name,
 		sourceURL:       cfg.SourceURL,
+		protocolVersion: cfg.ProtocolVersion,
 		authenticator:   cfg.Authenticator,
+		credentials:     cfg.Credentials,
 		httpClient:      httpClient,
 		providerFactory: NewProviderFactory(httpClient, cfg.Cache),
 		logger:          logger,
@@ -79,15 +86,13 @@ func (r *SourceRepository) GetProvider(ctx context.Context) (ResourceProvider, e
 		return r.provider, nil
 	}
 
-	// Create authenticated HTTP client wrapper if needed
-	var httpClient HTTPClient = r.httpClient
-	if r.authenticator != nil {
-		httpClient = r.createAuthenticatedClient()
-	}
+	// Authenticate every request made for this source, including those of the
+	// protocol clients (search, autocomplete, metadata, download)
+	httpClient := r.authenticatedClient()
 
 	// Create new provider factory with authenticated client and cache from existing factory
 	factory := NewProviderFactory(httpClient, r.providerFactory.cache)
-	provider, err := factory.CreateProvider(ctx, r.sourceURL)
+	provider, err := factory.CreateProviderForProtocol(ctx, r.sourceURL, r.protocolVersion)
 	if err != nil {
 		r.logger.ErrorContext(ctx, "Failed to create provider for {Source}: {Error}", r.name, err)
 		return nil, fmt.Errorf("create provider: %w", err)
@@ -97,6 +102,41 @@ func (r *SourceRepository) GetProvider(ctx context.Context) (ResourceProvider, e
 	return r.provider, nil
 }
 
+// authenticatedClient returns the HTTP client with the source's credentials attached.
+// A source without credentials still gets an authenticator, which answers Windows
+// integrated authentication challenges with the identity of the process.
+// The caller must hold r.mu.
+func (r *SourceRepository) authenticatedClient() *nugethttp.Client {
+	return r.httpClient.WithAuthenticator(&sourceAuthenticator{
+		sourceURL:   r.sourceURL,
+		configured:  r.authenticator,
+		credentials: r.credentials,
+	})
+}
+
+// SetCredentials attaches a credential cache to the repository.
+// A provider created earlier is discarded so that later requests consult the cache.
+func (r *SourceRepository) SetCredentials(credentials *CredentialCache) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.credentials = credentials
+	r.provider = nil
+}
+
+// SetProtocolVersion sets the protocolVersion configured for the source ("2", "3", or empty to
+// detect it). A provider created for a different protocol version is discarded.
+func (r *SourceRepository) SetProtocolVersion(protocolVersion string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.protocolVersion == protocolVersion {
+		return
+	}
+	r.protocolVersion = protocolVersion
+	r.provider = nil
+}
+
 // GetMetadata retrieves metadata for a specific package version
 // cacheCtx controls caching behavior (can be nil for default behavior)
 func (r *SourceRepository) GetMetadata(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID, version string) (*ProtocolMetadata, error) {
@@ -147,6 +187,31 @@ func (r *SourceRepository) ListVersions(ctx context.Context, cacheCtx *cache.Sou
 	return versions, nil
 }
 
+// ListListedVersions lists the versions of a package that are listed. For a source whose
+// protocol can't tell listed and unlisted versions apart, it lists all versions.
+// cacheCtx controls caching behavior (can be nil for default behavior)
+func (r *SourceRepository) ListListedVersions(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID string) ([]string, error) {
+	provider, err := r.GetProvider(ctx)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get provider for {Source}: {Error}",
+			r.sourceURL, err)
+		return nil, err
+	}
+
+	lister, ok := provider.(listedVersionLister)
+	if !ok {
+		return r.ListVersions(ctx, cacheCtx, packageID)
+	}
+
+	versions, err := lister.ListListedVersions(ctx, cacheCtx, packageID)
+	if err != nil {
+		r.logger.WarnContext(ctx, "Failed to list listed versions for {PackageID}: {Error}",
+			packageID, err)
+		return nil, err
+	}
+	return versions, nil
+}
+
 // Search searches for packages matching the query
 // cacheCtx controls caching behavior (can be nil for default behavior)
 func (r *SourceRepository) Search(ctx context.Context, cacheCtx *cache.SourceCacheContext, query string, opts SearchOptions) ([]SearchResult, error) {
@@ -217,57 +282,6 @@ func (r *SourceRepository) SourceURL() string {
 	return r.sourceURL
 }
 
-// createAuthenticatedClient creates an HTTP client wrapper with authentication
-func (r *SourceRepository) createAuthenticatedClient() HTTPClient {
-	return &authenticatedHTTPClient{
-		base:          r.httpClient,
-		authenticator: r.authenticator,
-	}
-}
-
-// authenticatedHTTPClient wraps nugethttp.Client and applies authentication to all requests
-type authenticatedHTTPClient struct {
-	base          *nugethttp.Client
-	authenticator auth.Authenticator
-}
-
-// Do executes an HTTP request with authentication applied
-func (c *authenticatedHTTPClient) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
-	// Apply authentication to the request
-	if err := c.authenticator.Authenticate(req); err != nil {
-		return nil, fmt.Errorf("authenticate request: %w", err)
-	}
-
-	// Execute the request with the base client
-	return c.base.Do(ctx, req)
-}
-
-// Get performs an authenticated GET request
-func (c *authenticatedHTTPClient) Get(ctx context.Context, url string) (*http.Response, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-
-	return c.Do(ctx, req)
-}
-
-// DoWithRetry executes an HTTP request with retry logic and authentication
-func (c *authenticatedHTTPClient) DoWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
-	// Apply authentication to the request
-	if err := c.authenticator.Authenticate(req); err != nil {
-		return nil, fmt.Errorf("authenticate request: %w", err)
-	}
-
-	// Execute with retry using the base client
-	return c.base.DoWithRetry(ctx, req)
-}
-
-// SetUserAgent delegates to the base client
-func (c *authenticatedHTTPClient) SetUserAgent(ua string) {
-	c.base.SetUserAgent(ua)
-}
-
 // RepositoryManager manages multiple package sources
 type RepositoryManager struct {
 	repositories map[string]*SourceRepository
@@ -1,13 +1,17 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/willibrandon/gonuget/auth"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/protocol/v3"
 )
 
 func TestSourceRepository_Name(t *testing.T) {
@@ -195,3 +199,67 @@ func TestRepositoryManager_ListRepositories(t *testing.T) {
 		t.Errorf("len(repos) = %d, want 2", len(repos))
 	}
 }
+
+func TestSourceRepository_LogsAtVerbosity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/index.json":
+			_ = json.NewEncoder(w).Encode(v3.ServiceIndex{
+				Version:   "3.0.0",
+				Resources: []v3.Resource{{ID: "http://" + r.Host + "/registration/", Type: "RegistrationsBaseUrl/3.6.0"}},
+			})
+		case "/registration/contoso.lib/index.json":
+			_, _ = w.Write([]byte(`{"count":1,"items":[{"@id":"page","lower":"1.0.0","upper":"1.0.0","count":1,` +
+				`"items":[{"@id":"leaf","catalogEntry":{"id":"Contoso.Lib","version":"1.0.0"}}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		verbosity observability.Verbosity
+		wantTrace bool
+	}{
+		{observability.VerbosityQuiet, false},
+		{observability.VerbosityMinimal, false},
+		{observability.VerbosityNormal, false},
+		{observability.VerbosityDetailed, true},
+		{observability.VerbosityDiagnostic, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.verbosity.String(), func(t *testing.T) {
+			var out bytes.Buffer
+			repo := NewSourceRepository(RepositoryConfig{
+				Name:      "test",
+				SourceURL: server.URL + "/index.json",
+				Logger:    observability.NewVerbosityLogger(&out, tt.verbosity),
+			})
+
+			if _, err := repo.ListVersions(context.Background(), nil, "Contoso.Lib"); err != nil {
+				t.Fatalf("ListVersions() error = %v", err)
+			}
+
+			want := "Listing package versions for Contoso.Lib from " + server.URL + "/index.json\n"
+			if got := strings.Contains(out.String(), want); got != tt.wantTrace {
+				t.Errorf("trace written = %v, want %v; output:\n%s", got, tt.wantTrace, out.String())
+			}
+		})
+	}
+}
+
+func TestSourceRepository_DefaultLogger(t *testing.T) {
+	var out bytes.Buffer
+	observability.SetDefaultLogger(observability.NewVerbosityLogger(&out, observability.VerbosityDetailed))
+	t.Cleanup(func() { observability.SetDefaultLogger(nil) })
+
+	// A repository created without a logger uses the one installed by the CLI
+	repo := NewSourceRepository(RepositoryConfig{Name: "test", SourceURL: "http://127.0.0.1:1/index.json"})
+	_, _ = repo.ListVersions(context.Background(), nil, "Contoso.Lib")
+
+	if !strings.Contains(out.String(), "Listing package versions for Contoso.Lib") {
+		t.Errorf("default logger not used; output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,227 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/willibrandon/gonuget/cache"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// VersionSelection chooses between the versions that satisfy a range.
+type VersionSelection int
+
+const (
+	// SelectLowest picks the lowest version that satisfies the range, as restore
+	// does for dependency ranges.
+	SelectLowest VersionSelection = iota
+
+	// SelectHighest picks the highest version that satisfies the range, as package
+	// add, update and tool install do when they pick the latest version.
+	SelectHighest
+)
+
+// ResolveVersionOptions controls how ResolveVersion picks a version.
+type ResolveVersionOptions struct {
+	// Repositories are the sources to search. Their versions are combined, and the
+	// resolved version is attributed to the first repository that has it.
+	Repositories []*SourceRepository
+
+	// Selection picks the lowest or the highest satisfying version.
+	Selection VersionSelection
+
+	// IncludePrerelease allows any prerelease version within the range. Without it,
+	// prerelease versions are only allowed by a range with a prerelease bound.
+	IncludePrerelease bool
+
+	// IncludeUnlisted also considers unlisted versions. Restore does; picking the
+	// latest version for add, update or tool install doesn't.
+	IncludeUnlisted bool
+}
+
+// SourceVersions holds the versions of a package one source has.
+type SourceVersions struct {
+	Repository *SourceRepository
+
+	// Versions holds the parsed versions in ascending order. Unlisted versions are
+	// left out unless IncludeUnlisted was set.
+	Versions []*version.NuGetVersion
+
+	// Err is set when the source could not be queried
+	Err error
+}
+
+// ResolvedVersion is a version picked by ResolveVersion and the source that has it.
+type ResolvedVersion struct {
+	Version    *version.NuGetVersion
+	Repository *SourceRepository
+
+	// Sources holds the versions of every queried source, in repository order
+	Sources []SourceVersions
+}
+
+// VersionNotFoundError is returned by ResolveVersion when no source has a version
+// satisfying the range. Sources tells a package no source has (NU1101) from a
+// package without a matching version (NU1102).
+type VersionNotFoundError struct {
+	PackageID         string
+	Range             *version.Range
+	IncludePrerelease bool
+	Sources           []SourceVersions
+}
+
+// PackageFound reports whether any source has a version of the package.
+func (e *VersionNotFoundError) PackageFound() bool {
+	for _, s := range e.Sources {
+		if len(s.Versions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *VersionNotFoundError) Error() string {
+	switch {
+	case !e.PackageFound():
+		if err := errors.Join(e.Unwrap()...); err != nil {
+			return fmt.Sprintf("package %s not found: %v", e.PackageID, err)
+		}
+		return fmt.Sprintf("package %s not found in any source", e.PackageID)
+	case e.Range != nil:
+		return fmt.Sprintf("no version of %s satisfies range %s", e.PackageID, e.Range)
+	case !e.IncludePrerelease:
+		return fmt.Sprintf("no stable version of %s found", e.PackageID)
+	default:
+		return fmt.Sprintf("no version of %s found", e.PackageID)
+	}
+}
+
+// Unwrap returns the errors of the sources that could not be queried.
+func (e *VersionNotFoundError) Unwrap() []error {
+	var errs []error
+	for _, s := range e.Sources {
+		if s.Err != nil {
+			errs = append(errs, s.Err)
+		}
+	}
+	return errs
+}
+
+// ResolveVersion resolves a version range of a package to the version to use, searching
+// the union of the versions of all repositories. A nil range allows any version, so
+// SelectHighest resolves the latest version. Sources that fail are skipped; when no
+// source has a satisfying version, the error is a *VersionNotFoundError.
+// Reference: NuGet.Client VersionRange.FindBestMatch and SourceRepositoryDependencyProvider
+func ResolveVersion(ctx context.Context, cacheCtx *cache.SourceCacheContext, packageID string, rng *version.Range, opts ResolveVersionOptions) (ResolvedVersion, error) {
+	if len(opts.Repositories) == 0 {
+		return ResolvedVersion{}, fmt.Errorf("no repositories configured")
+	}
+
+	// Sources are queried in parallel, network I/O being the bottleneck
+	sources := make([]SourceVersions, len(opts.Repositories))
+	var wg sync.WaitGroup
+	for i, repo := range opts.Repositories {
+		wg.Go(func() {
+			sources[i] = listSourceVersions(ctx, cacheCtx, repo, packageID, opts.IncludeUnlisted)
+		})
+	}
+	wg.Wait()
+
+	var candidates []*version.NuGetVersion
+	for _, s := range sources {
+		candidates = append(candidates, s.Versions...)
+	}
+
+	best := SelectVersion(candidates, rng, opts.Selection, opts.IncludePrerelease)
+	if best == nil {
+		return ResolvedVersion{}, &VersionNotFoundError{
+			PackageID:         packageID,
+			Range:             rng,
+			IncludePrerelease: opts.IncludePrerelease,
+			Sources:           sources,
+		}
+	}
+
+	result := ResolvedVersion{Version: best, Sources: sources}
+	for _, s := range sources {
+		if slices.ContainsFunc(s.Versions, best.Equals) {
+			result.Repository = s.Repository
+			break
+		}
+	}
+	return result, nil
+}
+
+// SelectVersion picks the lowest or highest of versions that satisfies rng, or returns
+// nil when none does. A nil range allows any version.
+func SelectVersion(versions []*version.NuGetVersion, rng *version.Range, selection VersionSelection, includePrerelease bool) *version.NuGetVersion {
+	var best *version.NuGetVersion
+	for _, v := range versions {
+		if !allowsVersion(rng, v, includePrerelease) {
+			continue
+		}
+		if best == nil ||
+			(selection == SelectLowest && v.LessThan(best)) ||
+			(selection == SelectHighest && v.GreaterThan(best)) {
+			best = v
+		}
+	}
+	return best
+}
+
+// allowsVersion checks if v satisfies rng, comparing it to the bounds with full version
+// precedence. A prerelease version is only allowed by a range with a prerelease bound,
+// unless includePrerelease is set.
+// Reference: NuGet.Client VersionRange.IsBetter
+func allowsVersion(rng *version.Range, v *version.NuGetVersion, includePrerelease bool) bool {
+	if rng == nil {
+		return includePrerelease || !v.IsPrerelease()
+	}
+
+	hasPrereleaseBound := (rng.MinVersion != nil && rng.MinVersion.IsPrerelease()) ||
+		(rng.MaxVersion != nil && rng.MaxVersion.IsPrerelease())
+	if v.IsPrerelease() && !includePrerelease && !hasPrereleaseBound {
+		return false
+	}
+
+	if rng.MinVersion != nil {
+		cmp := v.Compare(rng.MinVersion)
+		if cmp < 0 || (cmp == 0 && !rng.MinInclusive) {
+			return false
+		}
+	}
+	if rng.MaxVersion != nil {
+		cmp := v.Compare(rng.MaxVersion)
+		if cmp > 0 || (cmp == 0 && !rng.MaxInclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// listSourceVersions lists and parses the versions of a package on one source,
+// skipping versions that don't parse
+func listSourceVersions(ctx context.Context, cacheCtx *cache.SourceCacheContext, repo *SourceRepository, packageID string, includeUnlisted bool) SourceVersions {
+	result := SourceVersions{Repository: repo}
+
+	var versionStrings []string
+	if includeUnlisted {
+		versionStrings, result.Err = repo.ListVersions(ctx, cacheCtx, packageID)
+	} else {
+		versionStrings, result.Err = repo.ListListedVersions(ctx, cacheCtx, packageID)
+	}
+	if result.Err != nil {
+		return result
+	}
+
+	for _, s := range versionStrings {
+		if v, err := version.Parse(s); err == nil {
+			result.Versions = append(result.Versions, v)
+		}
+	}
+	version.SortVersions(result.Versions)
+	return result
+}
@@ -0,0 +1,194 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+	"github.com/willibrandon/gonuget/version"
+)
+
+func parseVersions(t *testing.T, versions ...string) []*version.NuGetVersion {
+	t.Helper()
+	parsed := make([]*version.NuGetVersion, len(versions))
+	for i, v := range versions {
+		parsed[i] = version.MustParse(v)
+	}
+	return parsed
+}
+
+func TestSelectVersion(t *testing.T) {
+	available := []string{"0.9.0", "1.0.0-beta", "1.0.0", "1.5.0", "2.0.0-rc.1", "2.0.0", "3.0.0-alpha"}
+
+	tests := []struct {
+		name       string
+		rng        string // empty for no range
+		selection  VersionSelection
+		prerelease bool
+		want       string // empty for no match
+	}{
+		// Restore semantics: the lowest satisfying version
+		{"lowest minimum", "1.0.0", SelectLowest, false, "1.0.0"},
+		{"lowest exclusive minimum", "(1.0.0, )", SelectLowest, false, "1.5.0"},
+		{"lowest bounded", "[1.1.0, 2.0.0]", SelectLowest, false, "1.5.0"},
+		{"lowest upper bound only", "(, 2.0.0]", SelectLowest, false, "0.9.0"},
+		{"lowest exact", "[1.5.0]", SelectLowest, false, "1.5.0"},
+		{"lowest exact missing", "[1.2.0]", SelectLowest, false, ""},
+		{"lowest above all", "4.0.0", SelectLowest, false, ""},
+		{"lowest prerelease bound", "1.0.0-alpha", SelectLowest, false, "1.0.0-beta"},
+		{"lowest exact prerelease", "[2.0.0-rc.1]", SelectLowest, false, "2.0.0-rc.1"},
+		{"lowest with prerelease", "1.0.0", SelectLowest, true, "1.0.0"},
+		{"lowest with prerelease below stable", "(1.5.0, )", SelectLowest, true, "2.0.0-rc.1"},
+
+		// Add and update semantics: the highest satisfying version
+		{"highest any stable", "", SelectHighest, false, "2.0.0"},
+		{"highest any", "", SelectHighest, true, "3.0.0-alpha"},
+		{"highest bounded", "[1.0.0, 2.0.0)", SelectHighest, false, "1.5.0"},
+		{"highest bounded with prerelease", "[1.0.0, 2.0.0)", SelectHighest, true, "2.0.0-rc.1"},
+		{"highest inclusive maximum", "[1.0.0, 2.0.0]", SelectHighest, false, "2.0.0"},
+		{"highest exclusive maximum", "(, 1.5.0)", SelectHighest, false, "1.0.0"},
+		{"highest no match", "(2.0.0, 3.0.0)", SelectHighest, false, ""},
+		{"highest prerelease only match", "(2.0.0, 3.0.0]", SelectHighest, true, "3.0.0-alpha"},
+		{"lowest any", "", SelectLowest, false, "0.9.0"},
+
+		// Prerelease versions compare to prerelease bounds by their labels
+		{"prerelease below prerelease bound", "[1.0.0-rc, 1.1.0)", SelectLowest, false, "1.0.0"},
+		{"prerelease upper bound", "[1.0.0, 2.0.0-rc.2)", SelectHighest, false, "2.0.0-rc.1"},
+	}
+
+	versions := parseVersions(t, available...)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rng *version.Range
+			if tt.rng != "" {
+				rng = version.MustParseRange(tt.rng)
+			}
+
+			got := SelectVersion(versions, rng, tt.selection, tt.prerelease)
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("SelectVersion() = %s, want no match", got)
+			case tt.want != "" && got == nil:
+				t.Errorf("SelectVersion() = nil, want %s", tt.want)
+			case tt.want != "" && got.String() != tt.want:
+				t.Errorf("SelectVersion() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectVersion_Empty(t *testing.T) {
+	if got := SelectVersion(nil, nil, SelectHighest, true); got != nil {
+		t.Errorf("SelectVersion() = %s, want nil", got)
+	}
+}
+
+// newResolveTestRepository serves packages from a fake V3 feed
+func newResolveTestRepository(t *testing.T, name string, packages ...nugethttptest.Package) *SourceRepository {
+	t.Helper()
+	server := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: packages})
+	return NewSourceRepository(RepositoryConfig{
+		Name:       name,
+		SourceURL:  server.SourceURL(),
+		HTTPClient: nugethttp.NewClient(nil),
+	})
+}
+
+func TestResolveVersion_SourceUnion(t *testing.T) {
+	first := newResolveTestRepository(t, "first",
+		nugethttptest.Package{ID: "Contoso.Lib", Version: "1.0.0"},
+		nugethttptest.Package{ID: "Contoso.Lib", Version: "2.0.0"},
+	)
+	second := newResolveTestRepository(t, "second",
+		nugethttptest.Package{ID: "Contoso.Lib", Version: "1.5.0"},
+		nugethttptest.Package{ID: "Contoso.Lib", Version: "2.0.0"},
+		nugethttptest.Package{ID: "Contoso.Lib", Version: "3.0.0", Unlisted: true},
+	)
+	repos := []*SourceRepository{first, second}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		rng      string
+		opts     ResolveVersionOptions
+		want     string
+		wantRepo *SourceRepository
+	}{
+		{"lowest across sources", "1.1.0", ResolveVersionOptions{Selection: SelectLowest, IncludeUnlisted: true}, "1.5.0", second},
+		{"first source wins a shared version", "[2.0.0]", ResolveVersionOptions{Selection: SelectLowest, IncludeUnlisted: true}, "2.0.0", first},
+		{"highest includes unlisted", "", ResolveVersionOptions{Selection: SelectHighest, IncludeUnlisted: true}, "3.0.0", second},
+		{"highest skips unlisted", "", ResolveVersionOptions{Selection: SelectHighest}, "2.0.0", first},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rng *version.Range
+			if tt.rng != "" {
+				rng = version.MustParseRange(tt.rng)
+			}
+			tt.opts.Repositories = repos
+
+			resolved, err := ResolveVersion(ctx, nil, "Contoso.Lib", rng, tt.opts)
+			if err != nil {
+				t.Fatalf("ResolveVersion() error = %v", err)
+			}
+			if resolved.Version.String() != tt.want {
+				t.Errorf("Version = %s, want %s", resolved.Version, tt.want)
+			}
+			if resolved.Repository != tt.wantRepo {
+				t.Errorf("Repository = %s, want %s", resolved.Repository.Name(), tt.wantRepo.Name())
+			}
+			if len(resolved.Sources) != 2 || resolved.Sources[0].Repository != first {
+				t.Errorf("Sources not reported in repository order")
+			}
+		})
+	}
+}
+
+func TestResolveVersion_NotFound(t *testing.T) {
+	repo := newResolveTestRepository(t, "feed",
+		nugethttptest.Package{ID: "Contoso.Lib", Version: "1.0.0"},
+		nugethttptest.Package{ID: "Contoso.Preview", Version: "1.0.0-beta"},
+	)
+	opts := ResolveVersionOptions{Repositories: []*SourceRepository{repo}, Selection: SelectHighest}
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		packageID string
+		rng       *version.Range
+		wantFound bool
+	}{
+		{"unknown package", "Contoso.Missing", nil, false},
+		{"no satisfying version", "Contoso.Lib", version.MustParseRange("[2.0.0, )"), true},
+		{"only prerelease versions", "Contoso.Preview", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ResolveVersion(ctx, nil, tt.packageID, tt.rng, opts)
+			var notFound *VersionNotFoundError
+			if !errors.As(err, &notFound) {
+				t.Fatalf("ResolveVersion() error = %v, want a *VersionNotFoundError", err)
+			}
+			if notFound.PackageFound() != tt.wantFound {
+				t.Errorf("PackageFound() = %v, want %v (%v)", notFound.PackageFound(), tt.wantFound, err)
+			}
+		})
+	}
+
+	// The prerelease-only package resolves once prerelease versions are allowed
+	opts.IncludePrerelease = true
+	resolved, err := ResolveVersion(ctx, nil, "Contoso.Preview", nil, opts)
+	if err != nil || resolved.Version.String() != "1.0.0-beta" {
+		t.Errorf("ResolveVersion() = %v, %v, want 1.0.0-beta", resolved.Version, err)
+	}
+}
+
+func TestResolveVersion_NoRepositories(t *testing.T) {
+	if _, err := ResolveVersion(context.Background(), nil, "Contoso.Lib", nil, ResolveVersionOptions{}); err == nil {
+		t.Error("ResolveVersion() expected an error without repositories")
+	}
+}
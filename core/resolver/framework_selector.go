@@ -1,6 +1,8 @@
 package resolver
 
 import (
+	"strings"
+
 	"github.com/willibrandon/gonuget/frameworks"
 )
 
@@ -36,7 +38,7 @@ func (fs *FrameworkSelector) SelectDependencies(
 	// Find all compatible groups
 	compatibleGroups := make([]DependencyGroup, 0)
 	for _, group := range groups {
-		if group.TargetFramework == "" {
+		if isUntargeted(group) {
 			// Untargeted group is always compatible
 			compatibleGroups = append(compatibleGroups, group)
 			continue
@@ -72,7 +74,7 @@ func (fs *FrameworkSelector) SelectDependencies(
 
 	// Fall back to untargeted group
 	for _, group := range compatibleGroups {
-		if group.TargetFramework == "" {
+		if isUntargeted(group) {
 			return group.Dependencies
 		}
 	}
@@ -88,7 +90,7 @@ func (fs *FrameworkSelector) findNearest(
 	// Convert groups to frameworks
 	fws := make([]*frameworks.NuGetFramework, 0, len(groups))
 	for _, group := range groups {
-		if group.TargetFramework == "" {
+		if isUntargeted(group) {
 			continue
 		}
 		fw, err := frameworks.ParseFramework(group.TargetFramework)
@@ -113,3 +115,9 @@ func (fs *FrameworkSelector) findNearest(
 
 	return nil
 }
+
+// isUntargeted reports whether a group applies to every framework: it has no target
+// framework, or the "any" framework a nuspec group without targetFramework parses to.
+func isUntargeted(group DependencyGroup) bool {
+	return group.TargetFramework == "" || strings.EqualFold(group.TargetFramework, "any")
+}
@@ -239,3 +239,24 @@ func TestFrameworkSelector_UntargetedGroupPriority(t *testing.T) {
 		t.Errorf("Expected PackageB from net8.0 group, got %s", deps[0].ID)
 	}
 }
+
+// TestFrameworkSelector_AnyFrameworkGroup tests a group read from a nuspec <group> without
+// a targetFramework, which the local packages folder reports as the "any" framework
+func TestFrameworkSelector_AnyFrameworkGroup(t *testing.T) {
+	selector := NewFrameworkSelector()
+
+	groups := []DependencyGroup{
+		{
+			TargetFramework: "any",
+			Dependencies: []PackageDependency{
+				{ID: "PackageA", VersionRange: "[1.0.0, )"},
+			},
+		},
+	}
+
+	deps := selector.SelectDependencies(groups, "net8.0")
+
+	if len(deps) != 1 || deps[0].ID != "PackageA" {
+		t.Errorf("Expected PackageA from the any framework group, got %v", deps)
+	}
+}
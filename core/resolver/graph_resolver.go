@@ -0,0 +1,311 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/willibrandon/gonuget/observability"
+)
+
+// DefaultMaxWalkIterations is the default ceiling on package expansions in one resolution.
+const DefaultMaxWalkIterations = 1_000_000
+
+// WalkLimitError is returned when a resolution expands more packages than the walk
+// iteration ceiling allows, which happens when packages keep being re-resolved
+// through cycles instead of settling.
+type WalkLimitError struct {
+	// Limit is the ceiling that was reached
+	Limit int
+
+	// Packages lists the packages re-resolved most often ("ID version (count)"), most frequent first
+	Packages []string
+}
+
+func (e *WalkLimitError) Error() string {
+	return fmt.Sprintf("dependency walk exceeded %d iterations; packages re-resolved most often: %s",
+		e.Limit, strings.Join(e.Packages, ", "))
+}
+
+// walkVisit is one occurrence of a store node in the dependency walk. Visits are
+// immutable and point at their parent, so all paths through a package share their prefix.
+type walkVisit struct {
+	node   int
+	parent *walkVisit
+	depth  int
+
+	// expand is false when the edge into the node suppresses its dependencies (PrivateAssets="All")
+	expand bool
+}
+
+// visitRecord remembers an expanded visit of a node for pruning later visits.
+type visitRecord struct {
+	depth int
+
+	// blockers are the ancestor IDs that could turn a dependency below the node into a cycle
+	blockers idSet
+}
+
+// graphWalk is the state of a walk over a node store.
+type graphWalk struct {
+	store      *nodeStore
+	pathPrefix []string
+
+	// first visit (the shortest path) of each node, nil if never reached
+	first []*walkVisit
+
+	// expanded visits per node and how many times each node was expanded
+	records    [][]visitRecord
+	expansions []int
+
+	// cycles found, one per node and dependency
+	cycles    []CycleReport
+	cycleSeen map[[2]int]bool
+}
+
+// resolveGraph resolves the dependency graph of the roots without expanding a package
+// again for paths that can't change what is below it.
+//
+// The walk goes one depth at a time (iterative deepening), so every package is first
+// reached through its shortest path, which decides nearest-wins. A later visit only
+// expands the package again when its ancestors could block a different set of cycles
+// below it than an earlier visit did; in graphs without cycles that never happens, and
+// each package is expanded once. Results match walking the full dependency tree.
+func (r *Resolver) resolveGraph(ctx context.Context, roots []PackageDependency, pathPrefix []string) (*ResolutionResult, error) {
+	spanID := "__project__"
+	if len(roots) == 1 {
+		spanID = roots[0].ID
+	}
+	ctx, span := observability.StartResolverWalkSpan(ctx, spanID, r.targetFramework)
+	defer span.End()
+
+	store, rootNodes, err := r.loadNodeStore(ctx, roots)
+	if err != nil {
+		return nil, err
+	}
+
+	walk := &graphWalk{
+		store:      store,
+		pathPrefix: pathPrefix,
+		first:      make([]*walkVisit, len(store.nodes)),
+		records:    make([][]visitRecord, len(store.nodes)),
+		expansions: make([]int, len(store.nodes)),
+		cycles:     make([]CycleReport, 0),
+		cycleSeen:  make(map[[2]int]bool),
+	}
+
+	limit := r.maxWalkIterations
+	if limit <= 0 {
+		limit = DefaultMaxWalkIterations
+	}
+
+	layer := make([]*walkVisit, 0, len(rootNodes))
+	for _, n := range rootNodes {
+		layer = append(layer, &walkVisit{node: n, expand: true})
+	}
+
+	iterations := 0
+	for len(layer) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var next []*walkVisit
+		for _, visit := range layer {
+			if walk.first[visit.node] == nil {
+				walk.first[visit.node] = visit
+			}
+			if !visit.expand || !walk.shouldExpand(visit) {
+				continue
+			}
+
+			iterations++
+			if iterations > limit {
+				return nil, walk.limitError(limit)
+			}
+			next = walk.expand(visit, next)
+		}
+		layer = next
+	}
+
+	return r.graphResult(ctx, walk), nil
+}
+
+// shouldExpand reports whether a visit can reach anything an earlier visit of the node didn't,
+// and records it if so. An earlier visit at the same or lower depth whose blocking ancestors
+// are a subset of this visit's blocks no more cycles, so everything below this visit is already known.
+func (w *graphWalk) shouldExpand(visit *walkVisit) bool {
+	node := w.store.nodes[visit.node]
+
+	var blockers idSet
+	for v := visit; v != nil; v = v.parent {
+		bit := w.store.ids[w.store.nodes[v.node].info.ID]
+		if node.reach.has(bit) {
+			blockers.add(bit)
+		}
+	}
+
+	for _, record := range w.records[visit.node] {
+		if record.depth <= visit.depth && record.blockers.subsetOf(blockers) {
+			return false
+		}
+	}
+
+	w.records[visit.node] = append(w.records[visit.node], visitRecord{depth: visit.depth, blockers: blockers})
+	w.expansions[visit.node]++
+	return true
+}
+
+// expand appends a visit for each dependency of the visited node to next.
+// A dependency on a package already on the path is a cycle and isn't walked.
+// Matches RemoteDependencyWalker.WalkParentsAndCalculateDependencyResult.
+func (w *graphWalk) expand(visit *walkVisit, next []*walkVisit) []*walkVisit {
+	node := w.store.nodes[visit.node]
+
+	for i, dep := range node.deps {
+		if w.onPath(visit, dep.ID) {
+			key := [2]int{visit.node, i}
+			if !w.cycleSeen[key] {
+				w.cycleSeen[key] = true
+				path := w.path(visit)
+				w.cycles = append(w.cycles, CycleReport{
+					PackageID:   dep.ID,
+					PathToSelf:  path,
+					Depth:       visit.depth + 1,
+					Description: NewCycleAnalyzer().formatCycleDescription(dep.ID, path),
+				})
+			}
+			continue
+		}
+
+		next = append(next, &walkVisit{
+			node:   node.children[i],
+			parent: visit,
+			depth:  visit.depth + 1,
+			expand: dep.SuppressParent != LibraryIncludeFlagsAll,
+		})
+	}
+
+	return next
+}
+
+// onPath reports whether a package with the given ID is the visited node or one of its ancestors.
+func (w *graphWalk) onPath(visit *walkVisit, id string) bool {
+	for v := visit; v != nil; v = v.parent {
+		if w.store.nodes[v.node].info.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// path returns the packages from the root to the visit, after the path prefix.
+func (w *graphWalk) path(visit *walkVisit) []string {
+	path := make([]string, len(w.pathPrefix), len(w.pathPrefix)+visit.depth+1)
+	copy(path, w.pathPrefix)
+	start := len(path)
+	for v := visit; v != nil; v = v.parent {
+		path = append(path, w.store.nodes[v.node].info.String())
+	}
+	// Reverse the walked part into root-first order
+	for i, j := start, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// limitError reports the packages expanded more than once, most often first.
+func (w *graphWalk) limitError(limit int) *WalkLimitError {
+	var repeated []int
+	for n, count := range w.expansions {
+		if count > 1 {
+			repeated = append(repeated, n)
+		}
+	}
+	sort.SliceStable(repeated, func(i, j int) bool {
+		return w.expansions[repeated[i]] > w.expansions[repeated[j]]
+	})
+	if len(repeated) > 10 {
+		repeated = repeated[:10]
+	}
+
+	packages := make([]string, len(repeated))
+	for i, n := range repeated {
+		packages[i] = fmt.Sprintf("%s (%d)", w.store.nodes[n].info, w.expansions[n])
+	}
+	return &WalkLimitError{Limit: limit, Packages: packages}
+}
+
+// graphResult builds the resolution result from a finished walk: the nearest version
+// of each package wins, and each reached version of a package with several is a conflict.
+func (r *Resolver) graphResult(ctx context.Context, walk *graphWalk) *ResolutionResult {
+	// Reached nodes in the order of their first visit (shortest path, then walk order)
+	reached := make([]int, 0, len(walk.first))
+	for n, visit := range walk.first {
+		if visit != nil {
+			reached = append(reached, n)
+		}
+	}
+	sort.SliceStable(reached, func(i, j int) bool {
+		return walk.first[reached[i]].depth < walk.first[reached[j]].depth
+	})
+
+	var ids []string
+	candidates := make(map[string][]int)
+	unresolved := make([]UnresolvedPackage, 0)
+	unresolvedSeen := make(map[string]bool)
+	for _, n := range reached {
+		info := walk.store.nodes[n].info
+
+		if info.IsUnresolved {
+			if !unresolvedSeen[info.Key()] {
+				unresolvedSeen[info.Key()] = true
+				// Transitive nodes (below a walk root) may have been limited to stable versions
+				stableOnly := walk.first[n].depth > 0 && r.walker.transitivePrerelease != TransitivePrereleaseAllowed
+				unresolved = append(unresolved, r.diagnoseUnresolvedPackage(ctx, info.ID, info.Version, stableOnly))
+			}
+			continue
+		}
+
+		if _, ok := candidates[info.ID]; !ok {
+			ids = append(ids, info.ID)
+		}
+		candidates[info.ID] = append(candidates[info.ID], n)
+	}
+
+	packages := make([]*PackageDependencyInfo, 0, len(ids))
+	conflicts := make([]VersionConflict, 0)
+	for _, id := range ids {
+		nodes := make([]*GraphNode, 0, len(candidates[id]))
+		var versions []string
+		var paths [][]string
+		seen := make(map[string]bool)
+		for _, n := range candidates[id] {
+			info := walk.store.nodes[n].info
+			nodes = append(nodes, &GraphNode{Key: info.Key(), Item: info, Depth: walk.first[n].depth})
+
+			// The same version can be reached through several version ranges
+			if !seen[info.Key()] {
+				seen[info.Key()] = true
+				versions = append(versions, info.Version)
+				paths = append(paths, walk.path(walk.first[n]))
+			}
+		}
+		if len(versions) > 1 {
+			conflicts = append(conflicts, VersionConflict{PackageID: id, Versions: versions, Paths: paths})
+		}
+
+		winner := r.conflictResolver.ResolveConflict(nodes)
+		packages = append(packages, winner.Item)
+	}
+
+	return &ResolutionResult{
+		Packages:            packages,
+		Conflicts:           conflicts,
+		Downgrades:          make([]DowngradeWarning, 0),
+		Cycles:              walk.cycles,
+		Unresolved:          unresolved,
+		PrereleaseFallbacks: prereleaseFallbacks(packages),
+	}
+}
@@ -0,0 +1,339 @@
+package resolver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+// treeWalkResult resolves roots the way the resolver did before the node store: walk the
+// full dependency tree of each root, then let the nearest version of each package win.
+// It returns the winning version per package ID and the keys of unresolved packages.
+func treeWalkResult(t *testing.T, client PackageMetadataClient, roots []PackageDependency) (map[string]string, []string) {
+	t.Helper()
+
+	walker := NewDependencyWalker(client, []string{"source1"}, "net8.0")
+	nodesByID := make(map[string][]*GraphNode)
+	unresolved := make(map[string]bool)
+	var collect func(*GraphNode)
+	collect = func(node *GraphNode) {
+		if node.Item != nil {
+			if node.Item.IsUnresolved {
+				unresolved[node.Item.Key()] = true
+			} else {
+				nodesByID[node.Item.ID] = append(nodesByID[node.Item.ID], node)
+			}
+		}
+		for _, child := range node.InnerNodes {
+			collect(child)
+		}
+	}
+	for _, root := range roots {
+		node, err := walker.Walk(context.Background(), root.ID, root.VersionRange, "net8.0", true)
+		if err != nil {
+			t.Fatalf("Walk() error = %v", err)
+		}
+		collect(node)
+	}
+
+	versions := make(map[string]string)
+	for id, nodes := range nodesByID {
+		versions[id] = NewConflictResolver().ResolveConflict(nodes).Item.Version
+	}
+	return versions, sortedKeys(unresolved)
+}
+
+// graphResult resolves roots with the resolver and returns the same shape as treeWalkResult.
+func graphResult(t *testing.T, client PackageMetadataClient, roots []PackageDependency) (map[string]string, []string) {
+	t.Helper()
+
+	result, err := NewResolver(client, []string{"source1"}, "net8.0").ResolveProject(context.Background(), roots)
+	if err != nil {
+		t.Fatalf("ResolveProject() error = %v", err)
+	}
+
+	versions := make(map[string]string)
+	for _, pkg := range result.Packages {
+		if _, ok := versions[pkg.ID]; ok {
+			t.Errorf("package %s resolved twice", pkg.ID)
+		}
+		versions[pkg.ID] = pkg.Version
+	}
+	unresolved := make(map[string]bool)
+	for _, pkg := range result.Unresolved {
+		unresolved[pkg.ID+"|"+pkg.VersionRange] = true
+	}
+	return versions, sortedKeys(unresolved)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func assertSameResolution(t *testing.T, client PackageMetadataClient, roots []PackageDependency) {
+	t.Helper()
+
+	wantVersions, wantUnresolved := treeWalkResult(t, client, roots)
+	gotVersions, gotUnresolved := graphResult(t, client, roots)
+
+	if fmt.Sprint(gotVersions) != fmt.Sprint(wantVersions) {
+		t.Errorf("packages = %v, want %v", gotVersions, wantVersions)
+	}
+	if fmt.Sprint(gotUnresolved) != fmt.Sprint(wantUnresolved) {
+		t.Errorf("unresolved = %v, want %v", gotUnresolved, wantUnresolved)
+	}
+}
+
+func pkg(id, ver string, deps ...PackageDependency) *PackageDependencyInfo {
+	return &PackageDependencyInfo{ID: id, Version: ver, Dependencies: deps}
+}
+
+func dep(id, versionRange string) PackageDependency {
+	return PackageDependency{ID: id, VersionRange: versionRange}
+}
+
+func packageMap(pkgs ...*PackageDependencyInfo) map[string]*PackageDependencyInfo {
+	m := make(map[string]*PackageDependencyInfo, len(pkgs))
+	for _, p := range pkgs {
+		m[p.Key()] = p
+	}
+	return m
+}
+
+func TestResolveGraph_MatchesTreeWalk(t *testing.T) {
+	private := dep("C", "[1.0.0]")
+	private.SuppressParent = LibraryIncludeFlagsAll
+
+	tests := []struct {
+		name     string
+		packages map[string]*PackageDependencyInfo
+		roots    []PackageDependency
+	}{
+		{
+			name: "nearest wins",
+			packages: packageMap(
+				pkg("A", "1.0.0", dep("B", "[1.0.0]"), dep("C", "[1.0.0]")),
+				pkg("B", "1.0.0", dep("D", "[1.0.0]")),
+				pkg("C", "1.0.0", dep("D", "[2.0.0]")),
+				pkg("D", "1.0.0"), pkg("D", "2.0.0"),
+			),
+			roots: []PackageDependency{dep("A", "[1.0.0]")},
+		},
+		{
+			name: "depth wins over version",
+			packages: packageMap(
+				pkg("A", "1.0.0", dep("B", "[1.0.0]"), dep("D", "[1.0.0]")),
+				pkg("B", "1.0.0", dep("C", "[1.0.0]")),
+				pkg("C", "1.0.0", dep("D", "[2.0.0]")),
+				pkg("D", "1.0.0"), pkg("D", "2.0.0"),
+			),
+			roots: []PackageDependency{dep("A", "[1.0.0]")},
+		},
+		{
+			name: "cycle through another version",
+			packages: packageMap(
+				pkg("A", "1.0.0", dep("B", "[1.0.0]")),
+				pkg("A", "2.0.0"),
+				pkg("B", "1.0.0", dep("A", "[2.0.0]"), dep("C", "[1.0.0]")),
+				pkg("C", "1.0.0", dep("B", "[1.0.0]")),
+			),
+			roots: []PackageDependency{dep("A", "[1.0.0]")},
+		},
+		{
+			name: "private assets stop the walk",
+			packages: packageMap(
+				pkg("A", "1.0.0", private, dep("B", "[1.0.0]")),
+				pkg("B", "1.0.0", dep("C", "[1.0.0]")),
+				pkg("C", "1.0.0", dep("D", "[1.0.0]")),
+				pkg("D", "1.0.0"),
+			),
+			roots: []PackageDependency{dep("A", "[1.0.0]")},
+		},
+		{
+			name: "unresolved transitive",
+			packages: packageMap(
+				pkg("A", "1.0.0", dep("B", "[1.0.0]"), dep("Missing", "[1.0.0]")),
+				pkg("B", "1.0.0", dep("Missing", "[2.0.0]")),
+			),
+			roots: []PackageDependency{dep("A", "[1.0.0]")},
+		},
+		{
+			name: "multiple roots",
+			packages: packageMap(
+				pkg("A", "1.0.0", dep("C", "[1.0.0]")),
+				pkg("B", "1.0.0", dep("D", "[1.0.0]")),
+				pkg("C", "1.0.0"), pkg("C", "2.0.0"),
+				pkg("D", "1.0.0", dep("C", "[2.0.0]")),
+			),
+			roots: []PackageDependency{dep("A", "[1.0.0]"), dep("B", "[1.0.0]"), dep("C", "[2.0.0]")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSameResolution(t, &mockPackageMetadataClient{packages: tt.packages}, tt.roots)
+		})
+	}
+}
+
+// randomUniverse generates packages with ids*versions entries, each depending on up to
+// maxDeps random packages. When acyclic is set, packages only depend on lower IDs.
+func randomUniverse(rng *rand.Rand, ids, versions, maxDeps int, acyclic bool) map[string]*PackageDependencyInfo {
+	packages := make(map[string]*PackageDependencyInfo, ids*versions)
+	for i := range ids {
+		for v := 1; v <= versions; v++ {
+			p := pkg(fmt.Sprintf("P%03d", i), fmt.Sprintf("%d.0.0", v))
+			for range rng.Intn(maxDeps + 1) {
+				target := rng.Intn(ids)
+				if acyclic {
+					if i == 0 {
+						break
+					}
+					target = rng.Intn(i)
+				}
+				targetID := fmt.Sprintf("P%03d", target)
+				if rng.Intn(4) == 0 {
+					// Open range: the lowest version wins
+					p.Dependencies = append(p.Dependencies, dep(targetID, fmt.Sprintf("%d.0.0", 1+rng.Intn(versions))))
+				} else {
+					p.Dependencies = append(p.Dependencies, dep(targetID, fmt.Sprintf("[%d.0.0]", 1+rng.Intn(versions+1))))
+				}
+			}
+			packages[p.Key()] = p
+		}
+	}
+	return packages
+}
+
+func TestResolveGraph_MatchesTreeWalk_RandomGraphs(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := range 200 {
+		packages := randomUniverse(rng, 7, 3, 3, false)
+		roots := []PackageDependency{dep("P000", "[1.0.0]"), dep(fmt.Sprintf("P%03d", 1+rng.Intn(6)), "1.0.0")}
+
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			assertSameResolution(t, &mockPackageMetadataClient{packages: packages}, roots)
+		})
+	}
+}
+
+// denseUniverse returns 500 packages in which most packages depend on several versions
+// of the same shared packages, the shape of large SDK families, and 20 roots for it.
+// Walking the full tree of a single root of this graph takes longer than ten seconds.
+func denseUniverse() (*mockPackageMetadataClient, []PackageDependency) {
+	rng := rand.New(rand.NewSource(42))
+	packages := randomUniverse(rng, 100, 5, 16, true)
+	roots := make([]PackageDependency, 0, 20)
+	for i := 80; i < 100; i++ {
+		roots = append(roots, dep(fmt.Sprintf("P%03d", i), "[5.0.0]"))
+	}
+	return &mockPackageMetadataClient{packages: packages}, roots
+}
+
+// TestResolveGraph_DenseUniverse resolves the dense universe within budgets that don't
+// depend on the machine: every package is expanded at most once, allocations stay within
+// a ceiling, and a generous wall-clock limit separates it from a tree walk.
+// BenchmarkResolveGraph_DenseUniverse measures the actual time and allocations.
+func TestResolveGraph_DenseUniverse(t *testing.T) {
+	client, roots := denseUniverse()
+	ctx := context.Background()
+
+	// The universe has no cycles, so each package and each unresolved dependency is
+	// expanded once: fewer than twice as many expansions as packages. A tree walk needs
+	// millions and fails with a WalkLimitError.
+	resolver := NewResolver(client, []string{"source1"}, "net8.0")
+	resolver.SetMaxWalkIterations(2 * len(client.packages))
+
+	start := time.Now()
+	result, err := resolver.ResolveProject(ctx, roots)
+	if err != nil {
+		t.Fatalf("ResolveProject() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Errorf("ResolveProject() took %v, want under 30s", elapsed)
+	}
+
+	if len(result.Packages) < 50 {
+		t.Errorf("resolved %d packages, want most of the universe", len(result.Packages))
+	}
+	if len(result.Conflicts) == 0 {
+		t.Error("expected version conflicts in a dense universe")
+	}
+
+	// About 160k allocations at the time of writing; a tree walk needs orders of magnitude more
+	allocs := testing.AllocsPerRun(1, func() {
+		if _, err := resolver.ResolveProject(ctx, roots); err != nil {
+			t.Fatalf("ResolveProject() error = %v", err)
+		}
+	})
+	if allocs > 2_000_000 {
+		t.Errorf("ResolveProject() made %.0f allocations, want at most 2000000", allocs)
+	}
+}
+
+// BenchmarkResolveGraph_DenseUniverse measures the time and allocations of resolving the
+// dense universe, which walked as a tree takes longer than ten seconds.
+func BenchmarkResolveGraph_DenseUniverse(b *testing.B) {
+	client, roots := denseUniverse()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := NewResolver(client, []string{"source1"}, "net8.0").ResolveProject(ctx, roots); err != nil {
+			b.Fatalf("ResolveProject() error = %v", err)
+		}
+	}
+}
+
+func TestResolveGraph_WalkLimit(t *testing.T) {
+	// Six layers of three packages; each package depends on every package of the next layer,
+	// and the last layer depends back on the first. Every path reaches a package with a
+	// different set of ancestors that can close a cycle, so each path is walked separately.
+	var pkgs []*PackageDependencyInfo
+	root := pkg("Root", "1.0.0")
+	for k := range 3 {
+		root.Dependencies = append(root.Dependencies, dep(fmt.Sprintf("L0P%d", k), "[1.0.0]"))
+	}
+	pkgs = append(pkgs, root)
+	for l := range 6 {
+		for k := range 3 {
+			p := pkg(fmt.Sprintf("L%dP%d", l, k), "1.0.0")
+			for next := range 3 {
+				p.Dependencies = append(p.Dependencies, dep(fmt.Sprintf("L%dP%d", (l+1)%6, next), "[1.0.0]"))
+			}
+			pkgs = append(pkgs, p)
+		}
+	}
+	client := &mockPackageMetadataClient{packages: packageMap(pkgs...)}
+
+	resolver := NewResolver(client, []string{"source1"}, "net8.0")
+	resolver.SetMaxWalkIterations(100)
+	_, err := resolver.Resolve(context.Background(), "Root", "[1.0.0]")
+
+	var limitErr *WalkLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Resolve() error = %v, want *WalkLimitError", err)
+	}
+	if limitErr.Limit != 100 || len(limitErr.Packages) == 0 {
+		t.Errorf("WalkLimitError = %+v, want the re-resolved packages", limitErr)
+	}
+
+	// The default ceiling is far above what the cycles need
+	resolver.SetMaxWalkIterations(0)
+	result, err := resolver.Resolve(context.Background(), "Root", "[1.0.0]")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if len(result.Packages) != 19 || len(result.Cycles) == 0 {
+		t.Errorf("got %d packages and %d cycles, want 19 packages and cycles", len(result.Packages), len(result.Cycles))
+	}
+}
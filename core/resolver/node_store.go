@@ -0,0 +1,289 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// nodeStore indexes every package reached during a resolution exactly once.
+// Nodes don't change after they are loaded, so walks share them through their
+// index instead of building a separate subtree for every path that reaches a package.
+type nodeStore struct {
+	nodes []*storeNode
+	index map[string]int // fetch key -> node index
+
+	// ids assigns each package ID a bit in an idSet
+	ids map[string]int
+}
+
+// storeNode is a loaded package and the nodes its dependencies resolved to.
+type storeNode struct {
+	info *PackageDependencyInfo
+
+	// deps are the dependencies for the target framework; children[i] is the node deps[i] resolved to
+	deps     []PackageDependency
+	children []int
+
+	// expandable is set once the node is reached through an edge that doesn't suppress
+	// its dependencies (PrivateAssets="All"); only then are its dependencies loaded
+	expandable bool
+	loaded     bool
+
+	// reach holds the IDs of every dependency that can be walked below this node
+	reach idSet
+}
+
+// idSet is a bitset of package IDs, indexed by nodeStore.ids.
+type idSet []uint64
+
+func (s idSet) has(bit int) bool {
+	return bit/64 < len(s) && s[bit/64]&(1<<(bit%64)) != 0
+}
+
+func (s *idSet) add(bit int) {
+	for len(*s) <= bit/64 {
+		*s = append(*s, 0)
+	}
+	(*s)[bit/64] |= 1 << (bit % 64)
+}
+
+func (s *idSet) union(other idSet) {
+	for len(*s) < len(other) {
+		*s = append(*s, 0)
+	}
+	for i, word := range other {
+		(*s)[i] |= word
+	}
+}
+
+// subsetOf reports whether every ID in s is also in other.
+func (s idSet) subsetOf(other idSet) bool {
+	for i, word := range s {
+		var o uint64
+		if i < len(other) {
+			o = other[i]
+		}
+		if word&^o != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// idBit returns the bit assigned to a package ID, assigning the next one if needed.
+func (s *nodeStore) idBit(id string) int {
+	bit, ok := s.ids[id]
+	if !ok {
+		bit = len(s.ids)
+		s.ids[id] = bit
+	}
+	return bit
+}
+
+// loadNodeStore fetches the roots and, layer by layer, the dependencies of every package
+// reachable from them. Each (ID, range) is fetched once no matter how many packages depend on it.
+func (r *Resolver) loadNodeStore(ctx context.Context, roots []PackageDependency) (*nodeStore, []int, error) {
+	store := &nodeStore{
+		index: make(map[string]int),
+		ids:   make(map[string]int),
+	}
+
+	// Roots use the direct-reference rules; everything below them is transitive
+	rootNodes := make([]int, len(roots))
+	for i, dep := range roots {
+		node, err := r.loadNode(ctx, store, dep, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fetch root package: %w", err)
+		}
+		store.nodes[node].expandable = true
+		rootNodes[i] = node
+	}
+
+	layer := append([]int(nil), rootNodes...)
+	for len(layer) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		// Start every fetch of the layer before waiting on any of them
+		type fetch struct {
+			node, dep int
+			info      *PackageDependencyInfo
+			err       error
+		}
+		var fetches []*fetch
+		for _, n := range layer {
+			node := store.nodes[n]
+			if node.loaded || !node.expandable {
+				continue
+			}
+			node.loaded = true
+
+			for _, dep := range r.walker.getDependenciesForFramework(node.info, r.targetFramework) {
+				// Skip empty dependency IDs (can occur in malformed V2 metadata)
+				if dep.ID == "" {
+					continue
+				}
+				// A pinned package is constrained to its pinned range wherever it appears
+				if pinned, ok := r.pinnedVersions[strings.ToLower(dep.ID)]; ok {
+					dep.VersionRange = pinned
+				}
+				node.deps = append(node.deps, dep)
+				node.children = append(node.children, -1)
+
+				// A dependency on the package's own ID is always a cycle
+				if dep.ID == node.info.ID {
+					continue
+				}
+				fetches = append(fetches, &fetch{node: n, dep: len(node.deps) - 1})
+			}
+		}
+
+		var wg sync.WaitGroup
+		for _, f := range fetches {
+			dep := store.nodes[f.node].deps[f.dep]
+			prereleaseParent := isResolvedPrerelease(store.nodes[f.node].info)
+			if _, ok := store.index[fetchKey(dep, true, prereleaseParent)]; ok {
+				continue
+			}
+			wg.Go(func() {
+				f.info, f.err = r.walker.fetchDependency(ctx, dep, r.targetFramework, true, prereleaseParent)
+			})
+		}
+		wg.Wait()
+
+		var next []int
+		for _, f := range fetches {
+			if f.err != nil {
+				return nil, nil, f.err
+			}
+			node := store.nodes[f.node]
+			dep := node.deps[f.dep]
+			key := fetchKey(dep, true, isResolvedPrerelease(node.info))
+			child, ok := store.index[key]
+			if !ok {
+				child = store.add(key, f.info, dep)
+			}
+			node.children[f.dep] = child
+
+			if dep.SuppressParent != LibraryIncludeFlagsAll && !store.nodes[child].expandable {
+				store.nodes[child].expandable = true
+				next = append(next, child)
+			}
+		}
+		layer = next
+	}
+
+	store.computeReach()
+	return store, rootNodes, nil
+}
+
+// loadNode fetches a single dependency into the store and returns its index.
+func (r *Resolver) loadNode(ctx context.Context, store *nodeStore, dep PackageDependency, transitive bool) (int, error) {
+	key := fetchKey(dep, transitive, false)
+	if node, ok := store.index[key]; ok {
+		return node, nil
+	}
+	info, err := r.walker.fetchDependency(ctx, dep, r.targetFramework, transitive, false)
+	if err != nil {
+		return 0, err
+	}
+	return store.add(key, info, dep), nil
+}
+
+// add stores a fetched package, or an unresolved placeholder when info is nil.
+// Matches NuGet.Client's ResolverUtility.CreateUnresolvedResult for missing packages.
+func (s *nodeStore) add(key string, info *PackageDependencyInfo, dep PackageDependency) int {
+	if info == nil {
+		info = &PackageDependencyInfo{
+			ID:               dep.ID,
+			Version:          dep.VersionRange,
+			Dependencies:     []PackageDependency{},
+			DependencyGroups: []DependencyGroup{},
+			IsUnresolved:     true,
+		}
+	}
+	s.nodes = append(s.nodes, &storeNode{info: info})
+	s.index[key] = len(s.nodes) - 1
+	s.idBit(info.ID)
+	return len(s.nodes) - 1
+}
+
+// fetchKey identifies a dependency fetch; transitive fetches and dependencies of a
+// prerelease package may follow different prerelease rules.
+func fetchKey(dep PackageDependency, transitive, prereleaseParent bool) string {
+	return fmt.Sprintf("%s|%s|%t|%t", dep.ID, dep.VersionRange, transitive, prereleaseParent)
+}
+
+// computeReach fills in storeNode.reach using Tarjan's strongly connected components,
+// so packages that depend on each other share one set.
+func (s *nodeStore) computeReach() {
+	for _, node := range s.nodes {
+		for _, dep := range node.deps {
+			node.reach.add(s.idBit(dep.ID))
+		}
+	}
+
+	index := make([]int, len(s.nodes))
+	low := make([]int, len(s.nodes))
+	onStack := make([]bool, len(s.nodes))
+	var stack []int
+	next := 1
+
+	var strongConnect func(n int)
+	strongConnect = func(n int) {
+		index[n], low[n] = next, next
+		next++
+		stack = append(stack, n)
+		onStack[n] = true
+
+		node := s.nodes[n]
+		for i, child := range node.children {
+			if child < 0 || node.deps[i].SuppressParent == LibraryIncludeFlagsAll {
+				continue
+			}
+			if index[child] == 0 {
+				strongConnect(child)
+				low[n] = min(low[n], low[child])
+			} else if onStack[child] {
+				low[n] = min(low[n], index[child])
+			}
+		}
+
+		if low[n] != index[n] {
+			return
+		}
+
+		// n is the root of a component; its successors outside the component are done
+		var members []int
+		for {
+			m := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[m] = false
+			members = append(members, m)
+			if m == n {
+				break
+			}
+		}
+		var reach idSet
+		for _, m := range members {
+			reach.union(s.nodes[m].reach)
+			for i, child := range s.nodes[m].children {
+				if child >= 0 && s.nodes[m].deps[i].SuppressParent != LibraryIncludeFlagsAll {
+					reach.union(s.nodes[child].reach)
+				}
+			}
+		}
+		for _, m := range members {
+			s.nodes[m].reach = reach
+		}
+	}
+
+	for n := range s.nodes {
+		if index[n] == 0 {
+			strongConnect(n)
+		}
+	}
+}
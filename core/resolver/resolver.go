@@ -14,6 +14,12 @@ type Resolver struct {
 	conflictResolver *ConflictResolver
 	parallelResolver *ParallelResolver
 	targetFramework  string
+
+	// maxWalkIterations caps package expansions per resolution (0 = DefaultMaxWalkIterations)
+	maxWalkIterations int
+
+	// pinnedVersions maps lowercase package IDs to the range every transitive dependency on them uses
+	pinnedVersions map[string]string
 }
 
 // NewResolver creates a new resolver.
@@ -31,6 +37,41 @@ func NewResolver(client PackageMetadataClient, sources []string, targetFramework
 	return r
 }
 
+// SetTransitivePrerelease sets how prerelease versions are chosen for transitive
+// dependencies; direct references always follow their own range. Set it before
+// resolving; it is not safe to change while a resolution is running.
+func (r *Resolver) SetTransitivePrerelease(mode TransitivePrerelease) {
+	r.walker.SetTransitivePrerelease(mode)
+}
+
+// SetAllowPrereleaseEverywhere admits prerelease versions for every dependency range.
+// By default, as in NuGet, a range admits a prerelease only when one of its bounds is a
+// prerelease or the package depending on it resolved to a prerelease. Set it before
+// resolving; it is not safe to change while a resolution is running.
+func (r *Resolver) SetAllowPrereleaseEverywhere(allow bool) {
+	r.walker.SetAllowPrereleaseEverywhere(allow)
+}
+
+// SetCache makes the resolver look up package metadata through cache, so resolutions
+// that fetch from the same sources can share their lookups. Set it before resolving.
+func (r *Resolver) SetCache(cache *WalkerCache) {
+	r.walker.cache = cache
+}
+
+// SetMaxWalkIterations sets the ceiling on package expansions in one resolution; a resolution
+// that needs more fails with a *WalkLimitError. Zero restores DefaultMaxWalkIterations.
+func (r *Resolver) SetMaxWalkIterations(limit int) {
+	r.maxWalkIterations = limit
+}
+
+// SetPinnedVersions pins transitive dependencies: a dependency on a package whose
+// lowercase ID is a key of pins uses its value as the version range, whatever range the
+// depending package asks for. Matches NuGet's CentralPackageTransitivePinningEnabled.
+// Set it before resolving; it is not safe to change while a resolution is running.
+func (r *Resolver) SetPinnedVersions(pins map[string]string) {
+	r.pinnedVersions = pins
+}
+
 // Resolve performs complete dependency resolution with conflict resolution.
 func (r *Resolver) Resolve(
 	ctx context.Context,
@@ -57,6 +98,15 @@ func (r *Resolver) resolveWithRecursive(
 	versionRange string,
 	recursive bool,
 ) (*ResolutionResult, error) {
+	// Transitive resolution walks the shared node store instead of the full tree
+	if recursive {
+		result, err := r.resolveGraph(ctx, []PackageDependency{{ID: packageID, VersionRange: versionRange}}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("walk dependencies: %w", err)
+		}
+		return result, nil
+	}
+
 	// Step 1: Walk dependency graph
 	rootNode, err := r.walker.Walk(ctx, packageID, versionRange, r.targetFramework, recursive)
 	if err != nil {
@@ -95,14 +145,26 @@ func (r *Resolver) resolveWithRecursive(
 	}
 
 	return &ResolutionResult{
-		Packages:   resolvedPackages,
-		Conflicts:  conflicts,
-		Downgrades: downgrades,
-		Cycles:     cycles,
-		Unresolved: unresolved,
+		Packages:            resolvedPackages,
+		Conflicts:           conflicts,
+		Downgrades:          downgrades,
+		Cycles:              cycles,
+		Unresolved:          unresolved,
+		PrereleaseFallbacks: prereleaseFallbacks(resolvedPackages),
 	}, nil
 }
 
+// prereleaseFallbacks returns the resolved packages chosen by the TransitivePrereleasePreferStable fallback.
+func prereleaseFallbacks(packages []*PackageDependencyInfo) []*PackageDependencyInfo {
+	var fallbacks []*PackageDependencyInfo
+	for _, pkg := range packages {
+		if pkg.IsPrereleaseFallback {
+			fallbacks = append(fallbacks, pkg)
+		}
+	}
+	return fallbacks
+}
+
 // collectUnresolvedPackages traverses the graph and collects all unresolved packages
 // with enhanced diagnostics (error codes, available versions, nearest version).
 // Matches NuGet.Client's RestoreTargetGraph.Create which populates the Unresolved collection.
@@ -133,7 +195,9 @@ func (r *Resolver) collectUnresolvedRecursive(
 
 	// If this package is unresolved, diagnose and add to the list
 	if node.Item.IsUnresolved {
-		unresolvedPkg := r.diagnoseUnresolvedPackage(ctx, node.Item.ID, node.Item.Version)
+		// Transitive nodes (below a walk root) may have been limited to stable versions
+		stableOnly := node.Depth > 0 && r.walker.transitivePrerelease != TransitivePrereleaseAllowed
+		unresolvedPkg := r.diagnoseUnresolvedPackage(ctx, node.Item.ID, node.Item.Version, stableOnly)
 		*unresolved = append(*unresolved, unresolvedPkg)
 	}
 
@@ -145,7 +209,8 @@ func (r *Resolver) collectUnresolvedRecursive(
 
 // diagnoseUnresolvedPackage queries sources to determine the specific error type and build diagnostics.
 // Matches NuGet.Client's UnresolvedMessages.GetMessagesAsync behavior.
-func (r *Resolver) diagnoseUnresolvedPackage(ctx context.Context, packageID string, versionRange string) UnresolvedPackage {
+// stableOnly reports a range limited to stable versions by TransitivePrerelease.
+func (r *Resolver) diagnoseUnresolvedPackage(ctx context.Context, packageID string, versionRange string, stableOnly bool) UnresolvedPackage {
 	// Get sources from walker
 	sources := r.walker.sources
 
@@ -181,7 +246,13 @@ func (r *Resolver) diagnoseUnresolvedPackage(ctx context.Context, packageID stri
 		allPrerelease := r.areAllVersionsPrerelease(availableVersions)
 		requestingStable := r.isRequestingStableVersion(versionRange)
 
-		if allPrerelease && requestingStable {
+		if stableOnly && r.hasMatch(versionRange, availableVersions, true) && !r.hasMatch(versionRange, availableVersions, false) {
+			// NU1103: The range only matches prereleases, which transitive dependencies may not use
+			errorCode = NU1103
+			message = fmt.Sprintf("Unable to find a stable package '%s' with version (%s)",
+				packageID, versionRange)
+			message += "\n  - Prerelease versions are not allowed for transitive dependencies"
+		} else if allPrerelease && requestingStable {
 			// NU1103: Only prerelease versions available but stable requested
 			errorCode = NU1103
 			message = fmt.Sprintf("Unable to find a stable package '%s' with version (%s)",
@@ -236,6 +307,21 @@ func formatSources(sources []string) string {
 	return result
 }
 
+// hasMatch reports whether a prerelease (or, when prerelease is false, a stable) version satisfies the range.
+func (r *Resolver) hasMatch(versionRange string, versions []string, prerelease bool) bool {
+	parsedRange, err := version.ParseVersionRange(versionRange)
+	if err != nil {
+		return false
+	}
+	for _, vStr := range versions {
+		v, err := version.Parse(vStr)
+		if err == nil && v.IsPrerelease() == prerelease && parsedRange.Satisfies(v) {
+			return true
+		}
+	}
+	return false
+}
+
 // areAllVersionsPrerelease checks if all available versions are prerelease versions.
 func (r *Resolver) areAllVersionsPrerelease(versions []string) bool {
 	if len(versions) == 0 {
@@ -337,3 +337,202 @@ func TestConflictResolver_DepthPriority(t *testing.T) {
 		t.Errorf("Expected version 1.0.0 at depth 0, got %s", result.Item.Version)
 	}
 }
+
+// transitivePrereleaseClient: App 1.0.0-beta (direct) -> B >= 1.0.0-beta and C [2.0.0-rc]
+// B has a stable version in range; C only has the prerelease.
+func transitivePrereleaseClient() *mockPackageMetadataClient {
+	return &mockPackageMetadataClient{
+		packages: map[string]*PackageDependencyInfo{
+			"App|1.0.0-beta": {
+				ID:      "App",
+				Version: "1.0.0-beta",
+				Dependencies: []PackageDependency{
+					{ID: "B", VersionRange: "1.0.0-beta"},
+					{ID: "C", VersionRange: "[2.0.0-rc]"},
+				},
+			},
+			"App|1.0.0":    {ID: "App", Version: "1.0.0"},
+			"B|1.0.0-beta": {ID: "B", Version: "1.0.0-beta"},
+			"B|1.0.0":      {ID: "B", Version: "1.0.0"},
+			"C|2.0.0-rc":   {ID: "C", Version: "2.0.0-rc"},
+			"Other|1.0.0":  {ID: "Other", Version: "1.0.0"},
+		},
+	}
+}
+
+func resolvedVersions(result *ResolutionResult) map[string]string {
+	versions := make(map[string]string)
+	for _, pkg := range result.Packages {
+		versions[pkg.ID] = pkg.Version
+	}
+	return versions
+}
+
+func TestResolver_TransitivePrerelease_Allowed(t *testing.T) {
+	r := NewResolver(transitivePrereleaseClient(), []string{"source1"}, "net8.0")
+
+	result, err := r.Resolve(context.Background(), "App", "1.0.0-beta")
+	if err != nil {
+		t.Fatalf("Resolve() failed: %v", err)
+	}
+
+	// Default NuGet behavior: lowest version in range, prerelease included
+	versions := resolvedVersions(result)
+	if versions["App"] != "1.0.0-beta" || versions["B"] != "1.0.0-beta" || versions["C"] != "2.0.0-rc" {
+		t.Errorf("versions = %v, want App 1.0.0-beta, B 1.0.0-beta, C 2.0.0-rc", versions)
+	}
+}
+
+func TestResolver_TransitivePrerelease_StableOnly(t *testing.T) {
+	r := NewResolver(transitivePrereleaseClient(), []string{"source1"}, "net8.0")
+	r.SetTransitivePrerelease(TransitivePrereleaseStableOnly)
+
+	result, err := r.Resolve(context.Background(), "App", "1.0.0-beta")
+	if err != nil {
+		t.Fatalf("Resolve() failed: %v", err)
+	}
+
+	// The direct reference keeps its prerelease; B moves to stable
+	versions := resolvedVersions(result)
+	if versions["App"] != "1.0.0-beta" || versions["B"] != "1.0.0" {
+		t.Errorf("versions = %v, want App 1.0.0-beta and B 1.0.0", versions)
+	}
+
+	// C has no stable version in range, so it is unresolved with NU1103
+	if len(result.Unresolved) != 1 {
+		t.Fatalf("Unresolved = %+v, want C", result.Unresolved)
+	}
+	if result.Unresolved[0].ID != "C" || result.Unresolved[0].ErrorCode != string(NU1103) {
+		t.Errorf("Unresolved[0] = %s %s, want C NU1103", result.Unresolved[0].ID, result.Unresolved[0].ErrorCode)
+	}
+	if len(result.PrereleaseFallbacks) != 0 {
+		t.Errorf("PrereleaseFallbacks = %v, want none", result.PrereleaseFallbacks)
+	}
+}
+
+func TestResolver_TransitivePrerelease_PreferStable(t *testing.T) {
+	r := NewResolver(transitivePrereleaseClient(), []string{"source1"}, "net8.0")
+	r.SetTransitivePrerelease(TransitivePrereleasePreferStable)
+
+	result, err := NewTransitiveResolver(r).ResolveMultipleRoots(context.Background(), []PackageDependency{
+		{ID: "App", VersionRange: "1.0.0-beta"},
+		{ID: "Other", VersionRange: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("ResolveMultipleRoots() failed: %v", err)
+	}
+	if len(result.Unresolved) != 0 {
+		t.Fatalf("Unresolved = %+v, want none", result.Unresolved)
+	}
+
+	// B moves to stable; C falls back to the only version in range and is reported
+	versions := resolvedVersions(result)
+	if versions["B"] != "1.0.0" || versions["C"] != "2.0.0-rc" {
+		t.Errorf("versions = %v, want B 1.0.0 and C 2.0.0-rc", versions)
+	}
+	if len(result.PrereleaseFallbacks) != 1 || result.PrereleaseFallbacks[0].ID != "C" {
+		t.Errorf("PrereleaseFallbacks = %v, want C", result.PrereleaseFallbacks)
+	}
+}
+
+func TestResolver_SharedCache_TransitivePrereleaseModes(t *testing.T) {
+	// Two projects share their lookups; only the second falls back to C's prerelease
+	cache := NewWalkerCache()
+	resolve := func(mode TransitivePrerelease) *ResolutionResult {
+		t.Helper()
+		r := NewResolver(transitivePrereleaseClient(), []string{"source1"}, "net8.0")
+		r.SetTransitivePrerelease(mode)
+		r.SetCache(cache)
+		result, err := r.Resolve(context.Background(), "App", "1.0.0-beta")
+		if err != nil {
+			t.Fatalf("Resolve() failed: %v", err)
+		}
+		return result
+	}
+
+	stableOnly := resolve(TransitivePrereleaseStableOnly)
+	if len(stableOnly.Unresolved) != 1 || stableOnly.Unresolved[0].ID != "C" {
+		t.Errorf("stable only: Unresolved = %+v, want C", stableOnly.Unresolved)
+	}
+
+	preferStable := resolve(TransitivePrereleasePreferStable)
+	if len(preferStable.Unresolved) != 0 {
+		t.Errorf("prefer stable: Unresolved = %+v, want none", preferStable.Unresolved)
+	}
+	if versions := resolvedVersions(preferStable); versions["B"] != "1.0.0" || versions["C"] != "2.0.0-rc" {
+		t.Errorf("prefer stable: versions = %v, want B 1.0.0 and C 2.0.0-rc", versions)
+	}
+}
+
+// prereleaseEdgeClient serves a stable and a prerelease parent whose dependency ranges
+// have no prerelease bound, while the dependencies only have prerelease versions in range.
+func prereleaseEdgeClient() *mockPackageMetadataClient {
+	return &mockPackageMetadataClient{
+		packages: map[string]*PackageDependencyInfo{
+			"Stable|1.0.0": {
+				ID:           "Stable",
+				Version:      "1.0.0",
+				Dependencies: []PackageDependency{{ID: "Lib", VersionRange: "1.0.0"}},
+			},
+			"Preview|9.0.0-preview.1": {
+				ID:           "Preview",
+				Version:      "9.0.0-preview.1",
+				Dependencies: []PackageDependency{{ID: "Lib", VersionRange: "[1.0.0, 3.0.0)"}},
+			},
+			"Lib|1.0.0-beta": {ID: "Lib", Version: "1.0.0-beta"},
+			"Lib|2.0.0-beta": {ID: "Lib", Version: "2.0.0-beta"},
+		},
+	}
+}
+
+func TestResolver_PrereleaseEdge_StableParent(t *testing.T) {
+	r := NewResolver(prereleaseEdgeClient(), []string{"source1"}, "net8.0")
+
+	result, err := r.Resolve(context.Background(), "Stable", "1.0.0")
+	if err != nil {
+		t.Fatalf("Resolve() failed: %v", err)
+	}
+
+	// A stable range under a stable parent only admits stable versions
+	if len(result.Unresolved) != 1 {
+		t.Fatalf("Unresolved = %+v, want Lib", result.Unresolved)
+	}
+	if result.Unresolved[0].ID != "Lib" || result.Unresolved[0].ErrorCode != string(NU1103) {
+		t.Errorf("Unresolved[0] = %s %s, want Lib NU1103", result.Unresolved[0].ID, result.Unresolved[0].ErrorCode)
+	}
+}
+
+func TestResolver_PrereleaseEdge_PrereleaseParent(t *testing.T) {
+	r := NewResolver(prereleaseEdgeClient(), []string{"source1"}, "net8.0")
+
+	result, err := r.Resolve(context.Background(), "Preview", "9.0.0-preview.1")
+	if err != nil {
+		t.Fatalf("Resolve() failed: %v", err)
+	}
+	if len(result.Unresolved) != 0 {
+		t.Fatalf("Unresolved = %+v, want none", result.Unresolved)
+	}
+
+	// The prerelease parent admits prerelease children, but 1.0.0-beta is below the range
+	if versions := resolvedVersions(result); versions["Lib"] != "2.0.0-beta" {
+		t.Errorf("versions = %v, want Lib 2.0.0-beta", versions)
+	}
+}
+
+func TestResolver_AllowPrereleaseEverywhere(t *testing.T) {
+	r := NewResolver(prereleaseEdgeClient(), []string{"source1"}, "net8.0")
+	r.SetAllowPrereleaseEverywhere(true)
+
+	result, err := NewTransitiveResolver(r).ResolveMultipleRoots(context.Background(), []PackageDependency{
+		{ID: "Stable", VersionRange: "1.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("ResolveMultipleRoots() failed: %v", err)
+	}
+	if len(result.Unresolved) != 0 {
+		t.Fatalf("Unresolved = %+v, want none", result.Unresolved)
+	}
+	if versions := resolvedVersions(result); versions["Lib"] != "2.0.0-beta" {
+		t.Errorf("versions = %v, want Lib 2.0.0-beta", versions)
+	}
+}
@@ -41,105 +41,10 @@ func (tr *TransitiveResolver) ResolveMultipleRoots(
 		Dependencies: roots,
 	}
 
-	// Create synthetic root node manually (don't fetch from client)
-	rootNode := &GraphNode{
-		Key:         syntheticRoot.Key(),
-		Item:        syntheticRoot,
-		OuterNode:   nil,
-		InnerNodes:  make([]*GraphNode, 0),
-		ParentNodes: make([]*GraphNode, 0),
-		Disposition: DispositionAcceptable,
-		Depth:       0,
-		OuterEdge:   nil,
-	}
-
-	// Walk each root dependency (recursive=true for transitive resolution)
-	for _, dep := range roots {
-		childNode, err := tr.resolver.walker.Walk(
-			ctx,
-			dep.ID,
-			dep.VersionRange,
-			tr.resolver.targetFramework,
-			true,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("walk dependency %s: %w", dep.ID, err)
-		}
-		rootNode.InnerNodes = append(rootNode.InnerNodes, childNode)
-		childNode.OuterNode = rootNode
-	}
-
-	// Detect conflicts, cycles, downgrades
-	conflicts, downgrades := tr.resolver.conflictDetector.DetectFromGraph(rootNode)
-	cycleAnalyzer := NewCycleAnalyzer()
-	cycles := cycleAnalyzer.AnalyzeCycles(rootNode)
-
-	// Resolve conflicts
-	resolvedPackages := make([]*PackageDependencyInfo, 0)
-	if len(conflicts) > 0 {
-		nodesByID := make(map[string][]*GraphNode)
-		tr.resolver.collectAllNodes(rootNode, nodesByID)
-		for packageID, nodes := range nodesByID {
-			// Skip synthetic root
-			if packageID == "__project__" {
-				continue
-			}
-			winner := tr.resolver.conflictResolver.ResolveConflict(nodes)
-			if winner != nil && winner.Item != nil {
-				resolvedPackages = append(resolvedPackages, winner.Item)
-			}
-		}
-	} else {
-		resolvedPackages = tr.flattenGraphExcludingRoot(rootNode)
-	}
-
-	return &ResolutionResult{
-		Packages:   resolvedPackages,
-		Conflicts:  conflicts,
-		Downgrades: downgrades,
-		Cycles:     cycles,
-	}, nil
-}
-
-// flattenGraphExcludingRoot flattens graph but excludes synthetic root
-func (tr *TransitiveResolver) flattenGraphExcludingRoot(root *GraphNode) []*PackageDependencyInfo {
-	visited := make(map[string]bool)
-	packages := make([]*PackageDependencyInfo, 0)
-
-	// Start from children (skip synthetic root)
-	for _, child := range root.InnerNodes {
-		tr.flattenRecursive(child, visited, &packages)
-	}
-
-	return packages
-}
-
-// flattenRecursive recursively flattens graph
-func (tr *TransitiveResolver) flattenRecursive(
-	node *GraphNode,
-	visited map[string]bool,
-	packages *[]*PackageDependencyInfo,
-) {
-	if node == nil || node.Item == nil {
-		return
-	}
-
-	// Skip cycle and downgrade nodes (they don't have Item)
-	if node.Disposition == DispositionCycle || node.Disposition == DispositionPotentiallyDowngraded {
-		if node.Item == nil {
-			return
-		}
-	}
-
-	key := node.Item.Key()
-	if visited[key] {
-		return
-	}
-
-	visited[key] = true
-	*packages = append(*packages, node.Item)
-
-	for _, child := range node.InnerNodes {
-		tr.flattenRecursive(child, visited, packages)
+	// Paths start at the synthetic root, which is not itself resolved
+	result, err := tr.resolver.resolveGraph(ctx, roots, []string{syntheticRoot.String()})
+	if err != nil {
+		return nil, fmt.Errorf("walk dependencies: %w", err)
 	}
+	return result, nil
 }
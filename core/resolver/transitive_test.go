@@ -192,3 +192,65 @@ func TestTransitiveResolver_ExcludesSyntheticRoot(t *testing.T) {
 		t.Errorf("Expected 1 package, got %d", len(result.Packages))
 	}
 }
+
+func TestTransitiveResolver_PinnedVersions(t *testing.T) {
+	client := &mockPackageMetadataClient{
+		packages: map[string]*PackageDependencyInfo{
+			"A|1.0.0": {
+				ID:      "A",
+				Version: "1.0.0",
+				Dependencies: []PackageDependency{
+					{ID: "B", VersionRange: "[1.0.0, )"},
+				},
+			},
+			"C|1.0.0": {
+				ID:      "C",
+				Version: "1.0.0",
+				Dependencies: []PackageDependency{
+					{ID: "D", VersionRange: "[1.0.0, )"},
+				},
+			},
+			"D|1.0.0": {
+				ID:      "D",
+				Version: "1.0.0",
+				Dependencies: []PackageDependency{
+					{ID: "B", VersionRange: "[2.0.0, )"},
+				},
+			},
+			"B|1.0.0": {ID: "B", Version: "1.0.0", Dependencies: []PackageDependency{}},
+			"B|2.0.0": {ID: "B", Version: "2.0.0", Dependencies: []PackageDependency{}},
+		},
+	}
+
+	roots := []PackageDependency{
+		{ID: "A", VersionRange: "[1.0.0]"},
+		{ID: "C", VersionRange: "[1.0.0]"},
+	}
+
+	resolveB := func(pins map[string]string) (string, int) {
+		t.Helper()
+		resolver := NewResolver(client, []string{"source1"}, "net8.0")
+		resolver.SetPinnedVersions(pins)
+		result, err := NewTransitiveResolver(resolver).ResolveMultipleRoots(context.Background(), roots)
+		if err != nil {
+			t.Fatalf("ResolveMultipleRoots() failed: %v", err)
+		}
+		for _, pkg := range result.Packages {
+			if pkg.ID == "B" {
+				return pkg.Version, len(result.Conflicts)
+			}
+		}
+		t.Fatalf("B not resolved: %v", result.Packages)
+		return "", 0
+	}
+
+	// Nearest wins: A's B 1.0.0 beats the B 2.0.0 that D needs
+	if version, conflicts := resolveB(nil); version != "1.0.0" || conflicts != 1 {
+		t.Errorf("unpinned B = %s with %d conflicts, want 1.0.0 with 1", version, conflicts)
+	}
+
+	// The pin constrains every dependency on B, so nothing is downgraded
+	if version, conflicts := resolveB(map[string]string{"b": "[2.0.0, )"}); version != "2.0.0" || conflicts != 0 {
+		t.Errorf("pinned B = %s with %d conflicts, want 2.0.0 with 0", version, conflicts)
+	}
+}
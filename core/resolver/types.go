@@ -41,6 +41,28 @@ const (
 	LibraryIncludeFlagsAll LibraryIncludeFlags = 0x7F
 )
 
+// TransitivePrerelease controls how prerelease versions are chosen for transitive dependencies.
+// Direct references always follow their own range: a range with a prerelease bound
+// (for example 1.0.0-beta or [1.0.0-beta, 2.0.0)) may resolve to a prerelease.
+type TransitivePrerelease int
+
+const (
+	// TransitivePrereleaseAllowed applies each range's own prerelease rules to transitive
+	// dependencies too. This is NuGet's behavior and the default.
+	TransitivePrereleaseAllowed TransitivePrerelease = iota
+
+	// TransitivePrereleaseStableOnly resolves transitive dependencies to the lowest stable
+	// version that satisfies the range. When no stable version satisfies it, the dependency
+	// is unresolved and reported as NU1103.
+	TransitivePrereleaseStableOnly
+
+	// TransitivePrereleasePreferStable resolves transitive dependencies like
+	// TransitivePrereleaseStableOnly, but when no stable version satisfies the range it
+	// falls back to the prerelease the range allows. Packages chosen this way are listed in
+	// ResolutionResult.PrereleaseFallbacks so callers can warn about them.
+	TransitivePrereleasePreferStable
+)
+
 // PackageDependency represents a dependency on another package.
 // Maps to NuGet's LibraryDependency.
 type PackageDependency struct {
@@ -69,6 +91,10 @@ type PackageDependencyInfo struct {
 	// IsUnresolved indicates this package could not be found
 	// Maps to LibraryType.Unresolved in NuGet.Client
 	IsUnresolved bool
+
+	// IsPrereleaseFallback indicates a transitive prerelease chosen because no stable
+	// version satisfied the range (TransitivePrereleasePreferStable)
+	IsPrereleaseFallback bool
 }
 
 // Key returns a unique key for this package
@@ -94,6 +120,10 @@ type ResolutionResult struct {
 	Downgrades []DowngradeWarning
 	Cycles     []CycleReport
 	Unresolved []UnresolvedPackage // Packages that could not be resolved
+
+	// PrereleaseFallbacks lists resolved transitive prereleases chosen because no stable
+	// version satisfied the range (TransitivePrereleasePreferStable)
+	PrereleaseFallbacks []*PackageDependencyInfo
 }
 
 // Success returns true if resolution completed without unresolved packages.
@@ -17,6 +17,11 @@ type DependencyWalker struct {
 	cache             *WalkerCache
 	targetFramework   string
 	frameworkSelector *FrameworkSelector
+
+	// transitivePrerelease controls prerelease selection for transitive dependencies
+	transitivePrerelease TransitivePrerelease
+	// allowPrereleaseEverywhere admits prerelease versions for every range
+	allowPrereleaseEverywhere bool
 }
 
 // PackageMetadataClient interface for fetching package metadata
@@ -35,6 +40,19 @@ func NewDependencyWalker(client PackageMetadataClient, sources []string, targetF
 	}
 }
 
+// SetTransitivePrerelease sets how prerelease versions are chosen for transitive
+// dependencies. Set it before walking; it is not safe to change during a walk.
+func (w *DependencyWalker) SetTransitivePrerelease(mode TransitivePrerelease) {
+	w.transitivePrerelease = mode
+}
+
+// SetAllowPrereleaseEverywhere admits prerelease versions for every range, not only for
+// ranges with a prerelease bound or dependencies of a prerelease package.
+// Set it before walking; it is not safe to change during a walk.
+func (w *DependencyWalker) SetAllowPrereleaseEverywhere(allow bool) {
+	w.allowPrereleaseEverywhere = allow
+}
+
 // Walk builds the complete dependency graph starting from the given package.
 // Uses manual stack-based traversal matching NuGet.Client for performance.
 // When recursive is false, only the root package is resolved (no transitive dependencies).
@@ -52,7 +70,7 @@ func (w *DependencyWalker) Walk(
 	rootInfo, err := w.fetchDependency(ctx, PackageDependency{
 		ID:           packageID,
 		VersionRange: versionRange,
-	}, targetFramework)
+	}, targetFramework, false, false)
 	if err != nil {
 		return nil, fmt.Errorf("fetch root package: %w", err)
 	}
@@ -192,8 +210,9 @@ func (w *DependencyWalker) walkStackBased(
 				}
 
 				// Start fetch in background
+				prereleaseParent := isResolvedPrerelease(node.Item)
 				go func(t *DependencyFetchTask) {
-					info, err := w.fetchDependency(ctx, t.Dependency, targetFramework)
+					info, err := w.fetchDependency(ctx, t.Dependency, targetFramework, true, prereleaseParent)
 					t.ResultChan <- &DependencyFetchResult{Info: info, Error: err}
 				}(task)
 
@@ -317,13 +336,29 @@ func (w *DependencyWalker) makeDependencyKey(dep PackageDependency) string {
 	return fmt.Sprintf("%s|%s", dep.ID, dep.VersionRange)
 }
 
-// fetchDependency fetches metadata for a dependency
+// fetchDependency fetches metadata for a dependency.
+// transitive selects the transitive prerelease rules (see TransitivePrerelease).
+// prereleaseParent reports a dependency of a package that resolved to a prerelease,
+// which may itself resolve to a prerelease even when its range has no prerelease bound.
 func (w *DependencyWalker) fetchDependency(
 	ctx context.Context,
 	dep PackageDependency,
 	targetFramework string,
+	transitive bool,
+	prereleaseParent bool,
 ) (*PackageDependencyInfo, error) {
+	stableOnly := transitive && w.transitivePrerelease != TransitivePrereleaseAllowed
+	admitPrerelease := w.allowPrereleaseEverywhere || prereleaseParent
+
+	// The key holds everything the result depends on: the cache may be shared by
+	// resolvers with different prerelease settings (see Resolver.SetCache)
 	cacheKey := fmt.Sprintf("%s|%s|%s", dep.ID, dep.VersionRange, targetFramework)
+	if stableOnly {
+		cacheKey += fmt.Sprintf("|stable:%d", w.transitivePrerelease)
+	}
+	if admitPrerelease {
+		cacheKey += "|prerelease"
+	}
 
 	// Use operation cache to deduplicate concurrent fetches
 	return w.cache.GetOrFetch(ctx, cacheKey, func(ctx context.Context) (*PackageDependencyInfo, error) {
@@ -333,9 +368,18 @@ func (w *DependencyWalker) fetchDependency(
 			return nil, fmt.Errorf("parse version range %q: %w", dep.VersionRange, err)
 		}
 
+		// Clients filter by the range they are given, so ask for the prereleases it admits
+		query := dep.VersionRange
+		if admitPrerelease {
+			query = prereleaseQuery(versionRange, query)
+		}
+
+		// Prerelease match kept for TransitivePrereleasePreferStable when no source has a stable one
+		var prereleaseMatch *PackageDependencyInfo
+
 		// Try all sources
 		for _, source := range w.sources {
-			packages, err := w.client.GetPackageMetadata(ctx, source, dep.ID, dep.VersionRange)
+			packages, err := w.client.GetPackageMetadata(ctx, source, dep.ID, query)
 			if err != nil {
 				// If context was cancelled or deadline exceeded, propagate immediately
 				if ctx.Err() != nil {
@@ -346,7 +390,8 @@ func (w *DependencyWalker) fetchDependency(
 
 			// Find best match for version range (return lowest version that satisfies range)
 			// NuGet uses "minimum dependency version" strategy to ensure compatibility
-			var bestMatch *PackageDependencyInfo
+			var bestMatch, bestStable *PackageDependencyInfo
+			var bestVersion, bestStableVersion *version.NuGetVersion
 			for _, pkg := range packages {
 				pkgVersion, err := version.Parse(pkg.Version)
 				if err != nil {
@@ -354,24 +399,93 @@ func (w *DependencyWalker) fetchDependency(
 				}
 
 				// Check if this version satisfies the range
-				if versionRange.Satisfies(pkgVersion) {
-					// Keep the lowest satisfying version (NuGet's minimum dependency version strategy)
-					if bestMatch == nil {
-						bestMatch = pkg
-					} else {
-						bestVersion, _ := version.Parse(bestMatch.Version)
-						if pkgVersion.Compare(bestVersion) < 0 {
-							bestMatch = pkg
-						}
-					}
+				if !versionRange.Satisfies(pkgVersion) &&
+					(!admitPrerelease || !pkgVersion.IsPrerelease() || !withinBounds(versionRange, pkgVersion)) {
+					continue
+				}
+
+				// Keep the lowest satisfying version (NuGet's minimum dependency version strategy)
+				if bestMatch == nil || pkgVersion.Compare(bestVersion) < 0 {
+					bestMatch, bestVersion = pkg, pkgVersion
 				}
+				if !pkgVersion.IsPrerelease() && (bestStable == nil || pkgVersion.Compare(bestStableVersion) < 0) {
+					bestStable, bestStableVersion = pkg, pkgVersion
+				}
+			}
+
+			if !stableOnly {
+				if bestMatch != nil {
+					return bestMatch, nil
+				}
+				continue
 			}
 
-			if bestMatch != nil {
-				return bestMatch, nil
+			if bestStable != nil {
+				return bestStable, nil
 			}
+			if prereleaseMatch == nil {
+				prereleaseMatch = bestMatch
+			}
+		}
+
+		if prereleaseMatch != nil && w.transitivePrerelease == TransitivePrereleasePreferStable {
+			// Copy so the flag doesn't leak to direct references of the same version
+			fallback := *prereleaseMatch
+			fallback.IsPrereleaseFallback = true
+			return &fallback, nil
 		}
 
 		return nil, nil // Not found
 	})
 }
+
+// isResolvedPrerelease reports whether a package resolved to a prerelease version.
+func isResolvedPrerelease(info *PackageDependencyInfo) bool {
+	if info == nil || info.IsUnresolved {
+		return false
+	}
+	v, err := version.Parse(info.Version)
+	return err == nil && v.IsPrerelease()
+}
+
+// prereleaseQuery returns the range to query sources with when r admits prerelease versions
+// it would not admit on its own. A range without a prerelease bound gets its lower bound
+// lowered to that version's first prerelease ("1.0.0" becomes "[1.0.0-0, )"), which makes
+// range-filtering clients return prereleases; withinBounds then applies the real bounds.
+func prereleaseQuery(r *version.Range, original string) string {
+	if (r.MinVersion != nil && r.MinVersion.IsPrerelease()) || (r.MaxVersion != nil && r.MaxVersion.IsPrerelease()) {
+		return original
+	}
+
+	lower := "0.0.0-0"
+	if r.MinVersion != nil {
+		lower = strings.SplitN(r.MinVersion.ToNormalizedString(), "+", 2)[0] + "-0"
+	}
+	upper, upperBracket := "", ")"
+	if r.MaxVersion != nil {
+		upper = r.MaxVersion.ToNormalizedString()
+		if r.MaxInclusive {
+			upperBracket = "]"
+		}
+	}
+	return fmt.Sprintf("[%s, %s%s", lower, upper, upperBracket)
+}
+
+// withinBounds reports whether v lies between the range bounds using full version
+// ordering, so 1.0.0-beta is below [1.0.0, ). Unlike Range.Satisfies it does not reject
+// prerelease versions of a range without a prerelease bound.
+func withinBounds(r *version.Range, v *version.NuGetVersion) bool {
+	if r.MinVersion != nil {
+		cmp := v.Compare(r.MinVersion)
+		if cmp < 0 || (cmp == 0 && !r.MinInclusive) {
+			return false
+		}
+	}
+	if r.MaxVersion != nil {
+		cmp := v.Compare(r.MaxVersion)
+		if cmp > 0 || (cmp == 0 && !r.MaxInclusive) {
+			return false
+		}
+	}
+	return true
+}
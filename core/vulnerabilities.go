@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/willibrandon/gonuget/version"
+)
+
+// VulnerabilitySeverity is the severity of a known vulnerability, as the VulnerabilityInfo
+// resource ranks it.
+type VulnerabilitySeverity int
+
+const (
+	// VulnerabilitySeverityLow is a low severity vulnerability.
+	VulnerabilitySeverityLow VulnerabilitySeverity = iota
+	// VulnerabilitySeverityModerate is a moderate severity vulnerability.
+	VulnerabilitySeverityModerate
+	// VulnerabilitySeverityHigh is a high severity vulnerability.
+	VulnerabilitySeverityHigh
+	// VulnerabilitySeverityCritical is a critical severity vulnerability.
+	VulnerabilitySeverityCritical
+)
+
+// String returns the severity as NuGet prints it, such as "High".
+func (s VulnerabilitySeverity) String() string {
+	switch s {
+	case VulnerabilitySeverityLow:
+		return "Low"
+	case VulnerabilitySeverityModerate:
+		return "Moderate"
+	case VulnerabilitySeverityHigh:
+		return "High"
+	case VulnerabilitySeverityCritical:
+		return "Critical"
+	default:
+		return "Unknown"
+	}
+}
+
+// PackageVulnerability is a known vulnerability of the package versions in Versions.
+type PackageVulnerability struct {
+	AdvisoryURL string
+	Severity    VulnerabilitySeverity
+	Versions    *version.Range
+}
+
+// PackageVulnerabilities holds the known vulnerabilities of a source, keyed by lowercase
+// package ID.
+type PackageVulnerabilities map[string][]PackageVulnerability
+
+// Find returns the vulnerabilities affecting a package version.
+func (v PackageVulnerabilities) Find(packageID string, ver *version.NuGetVersion) []PackageVulnerability {
+	var found []PackageVulnerability
+	for _, vulnerability := range v[strings.ToLower(packageID)] {
+		if vulnerability.Versions.Satisfies(ver) {
+			found = append(found, vulnerability)
+		}
+	}
+	return found
+}
+
+// vulnerabilityProvider is implemented by providers of sources that publish known
+// vulnerabilities.
+type vulnerabilityProvider interface {
+	GetVulnerabilities(ctx context.Context) (map[string][]PackageVulnerability, error)
+}
+
+// GetVulnerabilities returns the known vulnerabilities the source publishes with the V3
+// VulnerabilityInfo resource. ok is false for a source that publishes none, such as a V2
+// feed or a local folder.
+func (r *SourceRepository) GetVulnerabilities(ctx context.Context) (vulnerabilities PackageVulnerabilities, ok bool, err error) {
+	provider, err := r.GetProvider(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	publisher, ok := provider.(vulnerabilityProvider)
+	if !ok {
+		return nil, false, nil
+	}
+
+	published, err := publisher.GetVulnerabilities(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("get vulnerabilities from %s: %w", r.sourceURL, err)
+	}
+	if published == nil {
+		return nil, false, nil
+	}
+	return published, true, nil
+}
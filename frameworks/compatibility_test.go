@@ -58,7 +58,7 @@ func TestGetNearest(t *testing.T) {
 		expected string
 	}{
 		{"net8.0 picks net6.0", "net8.0", "net6.0"},
-		{"net48 picks netstandard2.0", "net48", "netstandard2.0"},
+		{"net48 picks net45 of its own family", "net48", "net45"},
 		{"netcoreapp3.1 exact", "netcoreapp3.1", "netcoreapp3.1"},
 	}
 
@@ -88,3 +88,52 @@ func BenchmarkIsCompatible(b *testing.B) {
 		_ = pkg.IsCompatible(target)
 	}
 }
+
+func TestIsCompatible_Platforms(t *testing.T) {
+	tests := []struct {
+		packageFw  string
+		target     string
+		compatible bool
+	}{
+		{"net6.0-android31.0", "net6.0", false},
+		{"net6.0-android31.0", "net6.0-android31.0", true},
+		{"net6.0-android31.0", "net8.0-android34.0", true},
+		{"net6.0-android31.0", "net8.0-ios17.0", false},
+		{"net8.0-windows10.0.19041.0", "net8.0-windows7.0", false},
+		{"net6.0", "net8.0-android", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.packageFw+" → "+tt.target, func(t *testing.T) {
+			got := MustParseFramework(tt.packageFw).IsCompatible(MustParseFramework(tt.target))
+			if got != tt.compatible {
+				t.Errorf("IsCompatible() = %v, want %v", got, tt.compatible)
+			}
+		})
+	}
+}
+
+func TestGetNearest_SameFamily(t *testing.T) {
+	tests := []struct {
+		target    string
+		available []string
+		expected  string
+	}{
+		{"net48", []string{"net45", "net46", "net47", "netstandard2.0"}, "net47"},
+		{"net8.0-android", []string{"net8.0", "net8.0-android", "netstandard2.1"}, "net8.0-android"},
+		{"net8.0", []string{"net6.0-android31.0", "netstandard2.0"}, "netstandard2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			var available []*NuGetFramework
+			for _, fw := range tt.available {
+				available = append(available, MustParseFramework(fw))
+			}
+			got := GetNearest(MustParseFramework(tt.target), available)
+			if got == nil || got.String() != tt.expected {
+				t.Errorf("GetNearest() = %v, want %s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -425,6 +425,7 @@ func parseFrameworkIdentifier(fw *NuGetFramework, s string) error {
 		{"monomac", "MonoMac"},
 		{"xamarin", "Xamarin"},
 		{"tizen", "Tizen"},
+		{"dotnet", ".NETPlatform"},
 		{"dnxcore", "DNXCore"},
 		{"dnx", "DNX"},
 		{"uap", "UAP"},
@@ -442,6 +443,12 @@ func parseFrameworkIdentifier(fw *NuGetFramework, s string) error {
 		if versionPart, ok := strings.CutPrefix(s, p.prefix); ok {
 			// Extract version
 			if versionPart == "" {
+				// The bare "dotnet" folder name means .NETPlatform 5.0
+				if p.prefix == "dotnet" {
+					fw.Framework = p.fullName
+					fw.Version = FrameworkVersion{Major: 5}
+					return nil
+				}
 				return fmt.Errorf("missing version for framework %s", p.prefix)
 			}
 
@@ -698,6 +705,14 @@ func (fw *NuGetFramework) IsCompatible(target *NuGetFramework) bool {
 		return false
 	}
 
+	// A platform-specific package framework needs the same platform at the same or a
+	// higher platform version: net6.0-android is not usable from net6.0
+	if fw.Platform != "" {
+		if !strings.EqualFold(fw.Platform, target.Platform) || fw.PlatformVersion.Compare(target.PlatformVersion) > 0 {
+			return false
+		}
+	}
+
 	// Same framework and version
 	if fw.Framework == target.Framework && fw.Version.Compare(target.Version) == 0 {
 		return true
@@ -14,6 +14,18 @@ func GetNearest(target *NuGetFramework, available []*NuGetFramework) *NuGetFrame
 		return nil
 	}
 
+	// Like NuGet's FrameworkReducer, frameworks of the target's own family win over
+	// any other compatible framework: net48 uses net45 rather than netstandard2.0
+	var sameFamily *NuGetFramework
+	for _, fw := range available {
+		if fw.Framework == target.Framework && fw.IsCompatible(target) && (sameFamily == nil || isNearerInFamily(fw, sameFamily)) {
+			sameFamily = fw
+		}
+	}
+	if sameFamily != nil {
+		return sameFamily
+	}
+
 	var best *NuGetFramework
 	var bestScore int
 
@@ -32,6 +44,19 @@ func GetNearest(target *NuGetFramework, available []*NuGetFramework) *NuGetFrame
 	return best
 }
 
+// isNearerInFamily reports whether fw is nearer than other, both compatible frameworks
+// of the target's family: the higher version wins, then the platform-specific framework
+// with the higher platform version.
+func isNearerInFamily(fw, other *NuGetFramework) bool {
+	if c := fw.Version.Compare(other.Version); c != 0 {
+		return c > 0
+	}
+	if (fw.Platform != "") != (other.Platform != "") {
+		return fw.Platform != ""
+	}
+	return fw.PlatformVersion.Compare(other.PlatformVersion) > 0
+}
+
 // calculateCompatibilityScore calculates how well a framework matches the target.
 // Higher score = better match.
 func calculateCompatibilityScore(fw, target *NuGetFramework) int {
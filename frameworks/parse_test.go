@@ -27,6 +27,10 @@ func TestParseFramework(t *testing.T) {
 		{"netstandard1.6", "netstandard1.6", ".NETStandard", 1, 6, 0, "", false},
 		{"netstandard1.0", "netstandard1.0", ".NETStandard", 1, 0, 0, "", false},
 
+		// .NET Platform (dotnet folder names)
+		{"dotnet", "dotnet", ".NETPlatform", 5, 0, 0, "", false},
+		{"dotnet5.4", "dotnet5.4", ".NETPlatform", 5, 4, 0, "", false},
+
 		// .NET Core
 		{"netcoreapp3.1", "netcoreapp3.1", ".NETCoreApp", 3, 1, 0, "", false},
 		{"netcoreapp3.0", "netcoreapp3.0", ".NETCoreApp", 3, 0, 0, "", false},
@@ -49,6 +49,7 @@ func newDefaultProvider() *defaultFrameworkNameProvider {
 			".NETStandard":            "netstandard",
 			".NETCoreApp":             "netcoreapp",
 			".NETPortable":            "portable",
+			".NETPlatform":            "dotnet",
 			".NETMicroFramework":      "netmf",
 			"Silverlight":             "sl",
 			"Windows":                 "win",
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCaptureBodyLimit is the largest response body stored per captured exchange.
+// Longer bodies are truncated in the capture; the caller still receives all of it.
+const DefaultCaptureBodyLimit int64 = 32 << 20
+
+// redacted replaces secrets in captured headers and URLs.
+const redacted = "REDACTED"
+
+// Headers whose values are never written to a capture.
+var (
+	redactedRequestHeaders  = []string{"Authorization", "Proxy-Authorization", "X-NuGet-ApiKey", "Cookie"}
+	redactedResponseHeaders = []string{"Set-Cookie"}
+)
+
+// redactedQueryParams are query parameters that carry credentials: Azure SAS signatures,
+// S3 and GCS pre-signed URL signatures, and bearer tokens passed in the URL.
+var redactedQueryParams = map[string]bool{
+	"sig":                  true,
+	"signature":            true,
+	"x-amz-signature":      true,
+	"x-amz-credential":     true,
+	"x-amz-security-token": true,
+	"x-goog-signature":     true,
+	"x-goog-credential":    true,
+	"access_token":         true,
+	"token":                true,
+	"code":                 true,
+}
+
+// CapturedExchange is one recorded request and response.
+// It is stored as NNNN.json, with the response body in NNNN.body.
+type CapturedExchange struct {
+	Sequence        int         `json:"sequence"`
+	Method          string      `json:"method"`
+	URL             string      `json:"url"` // Secrets redacted
+	RequestHeaders  http.Header `json:"requestHeaders,omitempty"`
+	Status          int         `json:"status,omitempty"`
+	ResponseHeaders http.Header `json:"responseHeaders,omitempty"`
+	BodyFile        string      `json:"bodyFile,omitempty"`
+	BodySize        int64       `json:"bodySize"`
+	BodyTruncated   bool        `json:"bodyTruncated,omitempty"`
+	Error           string      `json:"error,omitempty"` // Transport error instead of a response
+	StartedAt       time.Time   `json:"startedAt"`
+	DurationMs      int64       `json:"durationMs"` // Time until the response headers arrived
+}
+
+// Capture records HTTP exchanges into a directory so a feed-specific problem can be
+// reproduced without access to the feed (see ReplayTransport). Credentials are redacted,
+// so the directory is safe to share. One Capture numbers the exchanges of every
+// transport it wraps.
+type Capture struct {
+	dir       string
+	bodyLimit int64
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewCapture creates dir if needed and prepares to record into it.
+// Numbering continues after exchanges already in the directory.
+func NewCapture(dir string) (*Capture, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create capture directory: %w", err)
+	}
+
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("read capture directory: %w", err)
+	}
+
+	return &Capture{dir: dir, bodyLimit: DefaultCaptureBodyLimit, seq: len(existing)}, nil
+}
+
+// SetBodyLimit sets the largest response body stored per exchange.
+func (c *Capture) SetBodyLimit(limit int64) {
+	c.bodyLimit = limit
+}
+
+// Wrap returns a transport that records every exchange made through base.
+// It has the signature expected by SetTransportWrapper.
+func (c *Capture) Wrap(base http.RoundTripper) http.RoundTripper {
+	return &captureTransport{capture: c, base: base}
+}
+
+// captureTransport records the exchanges of one base transport.
+type captureTransport struct {
+	capture *Capture
+	base    http.RoundTripper
+}
+
+// RoundTrip performs the request and records it.
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	exchange := &CapturedExchange{
+		Method:         req.Method,
+		URL:            RedactURL(req.URL),
+		RequestHeaders: redactHeader(req.Header, redactedRequestHeaders),
+		StartedAt:      start,
+		DurationMs:     time.Since(start).Milliseconds(),
+	}
+
+	if err != nil {
+		exchange.Error = err.Error()
+		if writeErr := t.capture.write(exchange, nil); writeErr != nil {
+			return nil, writeErr
+		}
+		return nil, err
+	}
+
+	// Read up to the limit; anything beyond it is passed through but not stored
+	data, readErr := io.ReadAll(io.LimitReader(resp.Body, t.capture.bodyLimit+1))
+	if readErr != nil {
+		_ = resp.Body.Close()
+		exchange.Error = readErr.Error()
+		if writeErr := t.capture.write(exchange, nil); writeErr != nil {
+			return nil, writeErr
+		}
+		return nil, readErr
+	}
+
+	if int64(len(data)) > t.capture.bodyLimit {
+		exchange.BodyTruncated = true
+		resp.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(data), resp.Body), Closer: resp.Body}
+		data = data[:t.capture.bodyLimit]
+	} else {
+		_ = resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	exchange.Status = resp.StatusCode
+	exchange.ResponseHeaders = redactHeader(resp.Header, redactedResponseHeaders)
+	exchange.BodySize = int64(len(data))
+
+	if err := t.capture.write(exchange, data); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// write stores an exchange and its body under the next sequence number.
+func (c *Capture) write(exchange *CapturedExchange, body []byte) error {
+	c.mu.Lock()
+	c.seq++
+	exchange.Sequence = c.seq
+	c.mu.Unlock()
+
+	name := fmt.Sprintf("%04d", exchange.Sequence)
+	if body != nil {
+		exchange.BodyFile = name + ".body"
+		if err := os.WriteFile(filepath.Join(c.dir, exchange.BodyFile), body, 0644); err != nil {
+			return fmt.Errorf("capture http: %w", err)
+		}
+	}
+
+	// Unescaped so URLs stay readable when diffing captures
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(exchange); err != nil {
+		return fmt.Errorf("capture http: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(c.dir, name+".json"), data.Bytes(), 0644); err != nil {
+		return fmt.Errorf("capture http: %w", err)
+	}
+	return nil
+}
+
+// multiReadCloser reads from Reader and closes Closer.
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// RedactURL returns the URL with its password and credential query parameters replaced by REDACTED.
+// Parameter order is kept, so a redacted live URL can be compared with a captured one.
+func RedactURL(u *url.URL) string {
+	redactedURL := *u
+	if u.User != nil {
+		if _, hasPassword := u.User.Password(); hasPassword {
+			redactedURL.User = url.UserPassword(u.User.Username(), redacted)
+		}
+	}
+
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		for i, param := range params {
+			key, _, hasValue := strings.Cut(param, "=")
+			name, err := url.QueryUnescape(key)
+			if err != nil {
+				name = key
+			}
+			if hasValue && redactedQueryParams[strings.ToLower(name)] {
+				params[i] = key + "=" + redacted
+			}
+		}
+		redactedURL.RawQuery = strings.Join(params, "&")
+	}
+
+	return redactedURL.String()
+}
+
+// redactHeader copies a header with the values of the named headers replaced by REDACTED.
+func redactHeader(header http.Header, names []string) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	copied := header.Clone()
+	for _, name := range names {
+		if values := copied.Values(name); len(values) > 0 {
+			redactedValues := make([]string, len(values))
+			for i := range redactedValues {
+				redactedValues[i] = redacted
+			}
+			copied[http.CanonicalHeaderKey(name)] = redactedValues
+		}
+	}
+	return copied
+}
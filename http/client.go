@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/willibrandon/gonuget/observability"
@@ -23,6 +24,8 @@ const (
 	DefaultDialTimeout = 10 * time.Second
 	// DefaultUserAgent is the default User-Agent header value.
 	DefaultUserAgent = "gonuget/0.1.0"
+	// DefaultMinTLSVersion is the oldest TLS version negotiated with feeds.
+	DefaultMinTLSVersion = tls.VersionTLS12
 )
 
 // Client wraps http.Client with NuGet-specific configuration
@@ -34,6 +37,32 @@ type Client struct {
 	logger         observability.Logger
 	circuitBreaker *resilience.HTTPCircuitBreaker // Optional circuit breaker (nil disables)
 	rateLimiter    *resilience.PerSourceLimiter   // Optional rate limiter (nil disables)
+	authenticator  RequestAuthenticator           // Optional request authenticator (nil sends anonymously)
+	responseLimits ResponseLimits                 // Maximum feed response sizes (see ReadBody)
+}
+
+// RequestAuthenticator applies credentials to outgoing requests.
+// It is satisfied by every auth.Authenticator. The client calls it for every request,
+// whatever its host; an authenticator holding a source's credentials must leave
+// requests to other hosts alone.
+type RequestAuthenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// ChallengeHandler is implemented by authenticators that can obtain credentials
+// after a source answers 401 Unauthorized. HandleChallenge reports whether the
+// request should be replayed; the client replays it at most once.
+type ChallengeHandler interface {
+	HandleChallenge(ctx context.Context, req *http.Request) (bool, error)
+}
+
+// TransportAuthenticator is implemented by authenticators whose credentials are exchanged
+// in a handshake over the connection, such as Negotiate and NTLM. After a 401,
+// ChallengeTransport returns the round tripper that replays the request through base with
+// the handshake, or nil to leave the challenge to the ChallengeHandler. The replay keeps
+// the client's redirect policy, and DoWithRetry runs it again on every attempt.
+type TransportAuthenticator interface {
+	ChallengeTransport(ctx context.Context, challenge *http.Response, base http.RoundTripper) (http.RoundTripper, error)
 }
 
 // Config holds HTTP client configuration
@@ -42,24 +71,43 @@ type Config struct {
 	DialTimeout          time.Duration
 	UserAgent            string
 	TLSConfig            *tls.Config
+	MinTLSVersion        uint16 // Oldest TLS version accepted (0 uses DefaultMinTLSVersion)
 	MaxIdleConns         int
 	EnableHTTP2          bool
 	RetryConfig          *RetryConfig
-	Logger               observability.Logger             // Optional logger (nil uses NullLogger)
+	Logger               observability.Logger             // Optional logger (nil uses observability.DefaultLogger)
 	EnableTracing        bool                             // Enable OpenTelemetry HTTP tracing
 	CircuitBreakerConfig *resilience.CircuitBreakerConfig // Optional circuit breaker config (nil disables)
 	RateLimiterConfig    *resilience.TokenBucketConfig    // Optional rate limiter config (nil disables)
+	Transport            http.RoundTripper                // Optional base transport (nil uses a pooled http.Transport)
+	ResponseLimits       *ResponseLimits                  // Optional maximum feed response sizes (nil uses DefaultResponseLimits)
+}
+
+// transportWrapper wraps the transport of every client created by NewClient (nil when unset).
+var transportWrapper atomic.Pointer[func(http.RoundTripper) http.RoundTripper]
+
+// SetTransportWrapper installs a process-wide wrapper around the transport of every
+// client created afterwards, including the global client. It is how the CLI records
+// traffic (--capture-http) and serves it back (debug http-replay) without threading a
+// transport through every layer. Pass nil to remove the wrapper.
+func SetTransportWrapper(wrap func(http.RoundTripper) http.RoundTripper) {
+	if wrap == nil {
+		transportWrapper.Store(nil)
+		return
+	}
+	transportWrapper.Store(&wrap)
 }
 
 // DefaultConfig returns a client configuration with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
-		Timeout:      DefaultTimeout,
-		DialTimeout:  DefaultDialTimeout,
-		UserAgent:    DefaultUserAgent,
-		MaxIdleConns: 100,
-		EnableHTTP2:  true,
-		RetryConfig:  DefaultRetryConfig(),
+		Timeout:       DefaultTimeout,
+		DialTimeout:   DefaultDialTimeout,
+		UserAgent:     DefaultUserAgent,
+		MinTLSVersion: DefaultMinTLSVersion,
+		MaxIdleConns:  100,
+		EnableHTTP2:   true,
+		RetryConfig:   DefaultRetryConfig(),
 	}
 }
 
@@ -85,7 +133,7 @@ func NewClient(cfg *Config) *Client {
 		TLSHandshakeTimeout:   1500 * time.Millisecond, // Faster timeout (down from 10s)
 		ExpectContinueTimeout: 200 * time.Millisecond,  // Faster 100-continue handling
 		ResponseHeaderTimeout: 10 * time.Second,        // Detect stalled connections
-		TLSClientConfig:       cfg.TLSConfig,
+		TLSClientConfig:       clientTLSConfig(cfg),
 		ForceAttemptHTTP2:     cfg.EnableHTTP2,
 		DisableKeepAlives:     false, // NEVER disable keep-alives
 		DisableCompression:    false, // Enable gzip for metadata (packages are already compressed)
@@ -93,13 +141,19 @@ func NewClient(cfg *Config) *Client {
 
 	// Wrap transport with tracing if enabled
 	var finalTransport http.RoundTripper = transport
+	if cfg.Transport != nil {
+		finalTransport = cfg.Transport
+	}
 	if cfg.EnableTracing {
-		finalTransport = observability.NewHTTPTracingTransport(transport, "github.com/willibrandon/gonuget/http")
+		finalTransport = observability.NewHTTPTracingTransport(finalTransport, "github.com/willibrandon/gonuget/http")
+	}
+	if wrap := transportWrapper.Load(); wrap != nil {
+		finalTransport = (*wrap)(finalTransport)
 	}
 
 	logger := cfg.Logger
 	if logger == nil {
-		logger = observability.NewNullLogger()
+		logger = observability.DefaultLogger()
 	}
 
 	client := &Client{
@@ -112,6 +166,9 @@ func NewClient(cfg *Config) *Client {
 		retryConfig: cfg.RetryConfig,
 		logger:      logger,
 	}
+	if cfg.ResponseLimits != nil {
+		client.responseLimits = *cfg.ResponseLimits
+	}
 
 	// Add circuit breaker if configured
 	if cfg.CircuitBreakerConfig != nil {
@@ -151,7 +208,7 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 	// Execute request with circuit breaker protection
 	executeRequest := func(context.Context) (*http.Response, error) {
 		start := time.Now()
-		resp, err := c.httpClient.Do(req)
+		resp, err := c.send(ctx, req)
 		duration := time.Since(start)
 
 		if err != nil {
@@ -192,11 +249,113 @@ func (c *Client) SetUserAgent(ua string) {
 	c.userAgent = ua
 }
 
-// DoWithRetry executes an HTTP request with retry logic
+// WithAuthenticator returns a copy of the client that authenticates every request.
+// The copy shares the transport, circuit breaker and rate limiter with c.
+func (c *Client) WithAuthenticator(authenticator RequestAuthenticator) *Client {
+	clone := *c
+	clone.authenticator = authenticator
+	return &clone
+}
+
+// send applies the client's authenticator and executes the request. When the source
+// answers 401 and the authenticator can handle the challenge, the request is replayed once,
+// through the authenticator's handshake transport when it has one.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.authenticator == nil {
+		return c.httpClient.Do(req)
+	}
+
+	if err := c.authenticator.Authenticate(req); err != nil {
+		return nil, fmt.Errorf("authenticate request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	if negotiator, ok := c.authenticator.(TransportAuthenticator); ok {
+		base := c.httpClient.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		transport, err := negotiator.ChallengeTransport(ctx, resp, base)
+		if err != nil {
+			_ = resp.Body.Close()
+			return nil, fmt.Errorf("authenticate request: %w", err)
+		}
+		if transport != nil {
+			_ = resp.Body.Close()
+			retryReq, err := replayRequest(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+
+			client := *c.httpClient
+			client.Transport = transport
+			c.logger.DebugContext(ctx, "HTTP {Method} {URL} retrying with a connection handshake after 401", req.Method, req.URL.String())
+			return client.Do(retryReq)
+		}
+	}
+
+	handler, ok := c.authenticator.(ChallengeHandler)
+	if !ok {
+		return resp, nil
+	}
+
+	retry, err := handler.HandleChallenge(ctx, req)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("authenticate request: %w", err)
+	}
+	if !retry {
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+
+	retryReq, err := replayRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.authenticator.Authenticate(retryReq); err != nil {
+		return nil, fmt.Errorf("authenticate request: %w", err)
+	}
+
+	c.logger.DebugContext(ctx, "HTTP {Method} {URL} retrying with credentials after 401", req.Method, req.URL.String())
+	return c.httpClient.Do(retryReq)
+}
+
+// replayRequest returns a copy of req to send again, with its body rewound.
+func replayRequest(ctx context.Context, req *http.Request) (*http.Request, error) {
+	retryReq := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("rewind request body: %w", err)
+		}
+		retryReq.Body = body
+	}
+	return retryReq, nil
+}
+
+// DoWithRetry executes an HTTP request with retry logic. Idempotent requests (GET, HEAD)
+// are retried on network errors and on 408, 429, 500, 502, 503 and 504 responses,
+// waiting for the Retry-After header when the source sends one and for an exponential
+// backoff with jitter otherwise. Other requests are sent once.
 func (c *Client) DoWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
 	// Extract host for circuit breaker and rate limiter
 	host := req.URL.Host
 
+	maxRetries := c.retryConfig.MaxRetries
+	if !isIdempotent(req.Method) {
+		maxRetries = 0
+	}
+	observe := retryObserver(ctx)
+
 	// Apply rate limiting before retry attempts
 	if c.rateLimiter != nil {
 		if err := c.rateLimiter.Wait(ctx, host); err != nil {
@@ -207,21 +366,21 @@ func (c *Client) DoWithRetry(ctx context.Context, req *http.Request) (*http.Resp
 	}
 
 	c.logger.DebugContext(ctx, "HTTP {Method} {URL} with retry (max={MaxRetries})",
-		req.Method, req.URL.String(), c.retryConfig.MaxRetries)
+		req.Method, req.URL.String(), maxRetries)
 
 	// Execute retry logic with circuit breaker wrapping entire sequence
 	executeWithRetry := func(context.Context) (*http.Response, error) {
 		var lastErr error
 		var resp *http.Response
 
-		for attempt := 0; attempt <= c.retryConfig.MaxRetries; attempt++ {
+		for attempt := 0; attempt <= maxRetries; attempt++ {
 			// Clone request for retry (body may have been consumed)
 			reqClone := req.Clone(ctx)
 			if req.Header.Get("User-Agent") == "" {
 				reqClone.Header.Set("User-Agent", c.userAgent)
 			}
 
-			resp, lastErr = c.httpClient.Do(reqClone)
+			resp, lastErr = c.send(ctx, reqClone)
 
 			// Success
 			if lastErr == nil && !IsRetriableStatus(resp.StatusCode) {
@@ -245,7 +404,7 @@ func (c *Client) DoWithRetry(ctx context.Context, req *http.Request) (*http.Resp
 			}
 
 			// Don't sleep after last attempt
-			if attempt < c.retryConfig.MaxRetries {
+			if attempt < maxRetries {
 				var backoff time.Duration
 
 				// Check for Retry-After header
@@ -262,7 +421,21 @@ func (c *Client) DoWithRetry(ctx context.Context, req *http.Request) (*http.Resp
 				}
 
 				c.logger.DebugContext(ctx, "HTTP {Method} {URL} retry {Attempt}/{MaxRetries} after {Backoff}ms",
-					req.Method, req.URL.String(), attempt+1, c.retryConfig.MaxRetries, backoff.Milliseconds())
+					req.Method, req.URL.String(), attempt+1, maxRetries, backoff.Milliseconds())
+				if observe != nil {
+					event := RetryEvent{
+						Method:     req.Method,
+						URL:        req.URL.String(),
+						Attempt:    attempt + 1,
+						MaxRetries: maxRetries,
+						Delay:      backoff,
+						Err:        lastErr,
+					}
+					if resp != nil {
+						event.StatusCode = resp.StatusCode
+					}
+					observe(event)
+				}
 
 				// Close response body before retry
 				if resp != nil {
@@ -280,8 +453,8 @@ func (c *Client) DoWithRetry(ctx context.Context, req *http.Request) (*http.Resp
 
 		if lastErr != nil {
 			c.logger.ErrorContext(ctx, "HTTP {Method} {URL} failed after {MaxRetries} retries: {Error}",
-				req.Method, req.URL.String(), c.retryConfig.MaxRetries, lastErr)
-			return nil, fmt.Errorf("after %d retries: %w", c.retryConfig.MaxRetries, lastErr)
+				req.Method, req.URL.String(), maxRetries, lastErr)
+			return nil, fmt.Errorf("after %d retries: %w", maxRetries, lastErr)
 		}
 
 		return resp, nil
@@ -295,6 +468,22 @@ func (c *Client) DoWithRetry(ctx context.Context, req *http.Request) (*http.Resp
 	return executeWithRetry(ctx)
 }
 
+// clientTLSConfig returns the TLS configuration for feed connections.
+// MinTLSVersion raises the minimum of a custom TLSConfig but never lowers it.
+func clientTLSConfig(cfg *Config) *tls.Config {
+	minVersion := cfg.MinTLSVersion
+	if minVersion == 0 {
+		minVersion = DefaultMinTLSVersion
+	}
+
+	tlsConfig := &tls.Config{}
+	if cfg.TLSConfig != nil {
+		tlsConfig = cfg.TLSConfig.Clone()
+	}
+	tlsConfig.MinVersion = max(tlsConfig.MinVersion, minVersion)
+	return tlsConfig
+}
+
 // Option is a functional option for configuring the client
 type Option func(*Config)
 
@@ -319,6 +508,13 @@ func WithTLSConfig(tlsCfg *tls.Config) Option {
 	}
 }
 
+// WithMinTLSVersion sets the oldest TLS version accepted from feeds (e.g. tls.VersionTLS13)
+func WithMinTLSVersion(version uint16) Option {
+	return func(cfg *Config) {
+		cfg.MinTLSVersion = version
+	}
+}
+
 // WithMaxIdleConns sets the maximum idle connections
 func WithMaxIdleConns(n int) Option {
 	return func(cfg *Config) {
@@ -343,6 +539,13 @@ func WithMaxRetries(n int) Option {
 	}
 }
 
+// WithTransport sets the base transport, e.g. a ReplayTransport to serve captured traffic
+func WithTransport(transport http.RoundTripper) Option {
+	return func(cfg *Config) {
+		cfg.Transport = transport
+	}
+}
+
 // NewClientWithOptions creates a client with functional options
 func NewClientWithOptions(opts ...Option) *Client {
 	cfg := DefaultConfig()
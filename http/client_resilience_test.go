@@ -396,7 +396,7 @@ func TestClient_DoWithRetry_CircuitBreaker(t *testing.T) {
 
 	ctx := context.Background()
 
-	// First DoWithRetry call - will make 4 attempts (initial + 3 retries)
+	// First DoWithRetry call - makes 4 attempts (initial + 3 retries)
 	// This counts as 1 failure for circuit breaker (returns 500 response, not error)
 	req, _ := http.NewRequest("GET", server.URL, nil)
 	resp, err := client.DoWithRetry(ctx, req)
@@ -435,9 +435,9 @@ func TestClient_DoWithRetry_CircuitBreaker(t *testing.T) {
 	// - First DoWithRetry: Circuit allows, but the operation itself fails (counts as 1 circuit failure)
 	// - Second DoWithRetry: Circuit allows, operation fails again (counts as 2nd circuit failure, opens circuit)
 	// - Third DoWithRetry: Circuit is open, blocks immediately (no attempts)
-	// However, we only see 2 HTTP attempts because circuit opened after 2 failures at the circuit level
-	if attemptCount != 2 {
-		t.Errorf("Expected 2 attempts before circuit opened, got %d", attemptCount)
+	// So we see the 4 HTTP attempts of each of the first two calls and none of the third
+	if attemptCount != 8 {
+		t.Errorf("Expected 8 attempts before circuit opened, got %d", attemptCount)
 	}
 }
 
@@ -3,8 +3,13 @@ package http
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -141,6 +146,68 @@ func TestWithTLSConfig(t *testing.T) {
 	}
 }
 
+// newTLSServer starts a TLS server that only negotiates versions in [minVersion, maxVersion].
+func newTLSServer(t *testing.T, minVersion, maxVersion uint16) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{MinVersion: minVersion, MaxVersion: maxVersion}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // Rejected handshakes are expected
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+// trustServer returns a TLS config trusting the test server's certificate.
+func trustServer(server *httptest.Server) *tls.Config {
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	return &tls.Config{RootCAs: roots}
+}
+
+func TestClient_MinTLSVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		serverVersion uint16
+		opts          []Option
+		wantErr       bool
+	}{
+		{name: "TLS 1.0 rejected by default", serverVersion: tls.VersionTLS10, wantErr: true},
+		{name: "TLS 1.1 rejected by default", serverVersion: tls.VersionTLS11, wantErr: true},
+		{name: "TLS 1.2 accepted by default", serverVersion: tls.VersionTLS12},
+		{name: "TLS 1.2 rejected when TLS 1.3 required", serverVersion: tls.VersionTLS12, opts: []Option{WithMinTLSVersion(tls.VersionTLS13)}, wantErr: true},
+		{name: "TLS 1.0 accepted when explicitly allowed", serverVersion: tls.VersionTLS10, opts: []Option{WithMinTLSVersion(tls.VersionTLS10)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTLSServer(t, tls.VersionTLS10, tt.serverVersion)
+
+			opts := append([]Option{WithTLSConfig(trustServer(server)), WithMaxRetries(0)}, tt.opts...)
+			client := NewClientWithOptions(opts...)
+
+			resp, err := client.Get(context.Background(), server.URL)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewClient_MinTLSVersionDefault(t *testing.T) {
+	client := NewClient(&Config{})
+
+	transport := client.httpClient.Transport.(*http.Transport)
+	if transport.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %d, want %d (TLS 1.2)", transport.TLSClientConfig.MinVersion, tls.VersionTLS12)
+	}
+}
+
 func TestWithRetryConfig(t *testing.T) {
 	customRetry := &RetryConfig{
 		MaxRetries:     5,
@@ -201,3 +268,78 @@ func TestWithMaxRetries(t *testing.T) {
 		}
 	})
 }
+
+// handshakeAuthenticator answers Negotiate challenges with a transport that adds a token.
+type handshakeAuthenticator struct {
+	challenges int
+}
+
+func (a *handshakeAuthenticator) Authenticate(*http.Request) error { return nil }
+
+func (a *handshakeAuthenticator) ChallengeTransport(_ context.Context, challenge *http.Response, base http.RoundTripper) (http.RoundTripper, error) {
+	a.challenges++
+	if challenge.Header.Get("WWW-Authenticate") != "Negotiate" {
+		return nil, nil
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Negotiate token")
+		return base.RoundTrip(req)
+	}), nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestClient_DoWithRetry_TransportAuthenticator(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	unavailable := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.URL.Path+" "+r.Header.Get("Authorization"))
+
+		switch {
+		case r.URL.Path == "/index.json":
+			http.Redirect(w, r, "/v3/index.json", http.StatusFound)
+		case unavailable:
+			unavailable = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.Header.Get("Authorization") != "Negotiate token":
+			w.Header().Set("WWW-Authenticate", "Negotiate")
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			_, _ = w.Write([]byte("OK"))
+		}
+	}))
+	defer server.Close()
+
+	authenticator := &handshakeAuthenticator{}
+	client := NewClientWithOptions(WithRetryConfig(&RetryConfig{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BackoffFactor: 1})).
+		WithAuthenticator(authenticator)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/index.json", nil)
+	resp, err := client.DoWithRetry(context.Background(), req)
+	if err != nil {
+		t.Fatalf("DoWithRetry() error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want 200 after the handshake", resp.StatusCode)
+	}
+
+	// The 503 is retried, and the replay after the 401 follows the redirect again
+	want := []string{
+		"/index.json ", "/v3/index.json ",
+		"/index.json ", "/v3/index.json ",
+		"/index.json Negotiate token", "/v3/index.json Negotiate token",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests = %q, want %q", requests, want)
+	}
+	if authenticator.challenges != 1 {
+		t.Errorf("ChallengeTransport called %d times, want 1", authenticator.challenges)
+	}
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// RequestIdentityEncoding asks the server not to compress the response.
+// Package downloads use it: a .nupkg is already a zip, so compressing it
+// again only costs time on both ends.
+func RequestIdentityEncoding(req *http.Request) {
+	req.Header.Set("Accept-Encoding", "identity")
+}
+
+// DecodeContentEncoding removes a gzip Content-Encoding the transport left in place,
+// so the body holds the payload as stored on the server. It is a no-op when the
+// transport already decompressed the body. Some feeds gzip .nupkg responses even
+// when asked not to, and some send the header with an unencoded body; the body is
+// sniffed so neither case corrupts the download.
+func DecodeContentEncoding(resp *http.Response) error {
+	if resp.Uncompressed {
+		return nil
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding != "gzip" && encoding != "x-gzip" {
+		return nil
+	}
+
+	body := resp.Body
+	buffered := bufio.NewReader(body)
+	magic, _ := buffered.Peek(len(gzipMagic))
+
+	if bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return fmt.Errorf("decode gzip content encoding: %w", err)
+		}
+		resp.Body = &decodedBody{Reader: gz, decoder: gz, body: body}
+	} else {
+		// Header without an encoded body: pass the payload through as is
+		resp.Body = &decodedBody{Reader: buffered, body: body}
+	}
+
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// decodedBody reads a decoded response body and closes the decoder and the original body.
+type decodedBody struct {
+	io.Reader
+	decoder io.Closer
+	body    io.Closer
+}
+
+// Close closes the decoder, if any, and the original body.
+func (b *decodedBody) Close() error {
+	if b.decoder != nil {
+		_ = b.decoder.Close()
+	}
+	return b.body.Close()
+}
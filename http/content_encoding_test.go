@@ -0,0 +1,80 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatalf("gzip Write() error = %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeContentEncoding(t *testing.T) {
+	payload := []byte("PK\x03\x04 zip payload")
+
+	tests := []struct {
+		name         string
+		encoding     string
+		body         []byte
+		uncompressed bool
+	}{
+		{name: "gzip", encoding: "gzip", body: gzipBytes(t, payload)},
+		{name: "x-gzip", encoding: "x-gzip", body: gzipBytes(t, payload)},
+		{name: "header without encoded body", encoding: "gzip", body: payload},
+		{name: "no encoding", body: payload},
+		{name: "already decoded by transport", encoding: "gzip", body: payload, uncompressed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header:        http.Header{},
+				Body:          io.NopCloser(bytes.NewReader(tt.body)),
+				ContentLength: int64(len(tt.body)),
+				Uncompressed:  tt.uncompressed,
+			}
+			if tt.encoding != "" {
+				resp.Header.Set("Content-Encoding", tt.encoding)
+			}
+
+			if err := DecodeContentEncoding(resp); err != nil {
+				t.Fatalf("DecodeContentEncoding() error = %v", err)
+			}
+			got, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Errorf("body = %q, want %q", got, payload)
+			}
+			if err := resp.Body.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeContentEncoding_CorruptGzip(t *testing.T) {
+	body := []byte{0x1f, 0x8b, 0x00}
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": []string{"gzip"}},
+		Body:   io.NopCloser(bytes.NewReader(body)),
+	}
+
+	if err := DecodeContentEncoding(resp); err == nil {
+		t.Error("DecodeContentEncoding() expected error for a truncated gzip header")
+	}
+}
@@ -0,0 +1,18 @@
+// Package nugethttptest provides utilities for testing code that talks to NuGet feeds
+// through gonuget, without network access.
+//
+// NewFakeV3Server serves a declarative Feed over the NuGet V3 protocol: the service
+// index, registrations, the flat container (including generated .nupkg and .nuspec
+// files) and search. Endpoints can be replaced with Handle, for example with the canned
+// Unauthorized and ServiceUnavailable responses, or with FailTimes to fail a few
+// requests before recovering. StartFakeV3Server starts the same server where there is
+// no testing.TB, such as in examples.
+//
+// Record and Replay capture real traffic into a directory and serve it back, in the
+// format written by --capture-http and read by "gonuget debug http-replay", so a
+// capture attached to a bug report can become a regression test.
+//
+// The package is meant to be used by projects embedding gonuget. Its API follows the
+// compatibility of the rest of the module; responses only model the parts of the
+// protocol gonuget reads, and may gain fields.
+package nugethttptest
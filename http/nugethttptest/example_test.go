@@ -0,0 +1,64 @@
+package nugethttptest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+	v3 "github.com/willibrandon/gonuget/protocol/v3"
+)
+
+// Serve a declarative feed and read it with the V3 protocol client. Tests use
+// NewFakeV3Server instead, which stops the server when the test ends.
+func ExampleStartFakeV3Server() {
+	server, err := nugethttptest.StartFakeV3Server(nugethttptest.Feed{
+		Packages: []nugethttptest.Package{
+			{ID: "Contoso.Core", Version: "1.0.0"},
+			{ID: "Contoso.Core", Version: "1.1.0"},
+			{ID: "Contoso.Web", Version: "1.0.0", Dependencies: []nugethttptest.Dependency{
+				{ID: "Contoso.Core", Range: "[1.1.0, )"},
+			}},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer server.Close()
+
+	httpClient := nugethttp.NewClient(nil)
+	metadata := v3.NewMetadataClient(httpClient, v3.NewServiceIndexClient(httpClient))
+
+	versions, _ := metadata.ListVersions(context.Background(), server.SourceURL(), "Contoso.Core")
+	fmt.Println(versions)
+
+	entry, _ := metadata.GetVersionMetadata(context.Background(), server.SourceURL(), "Contoso.Web", "1.0.0")
+	dep := entry.DependencyGroups[0].Dependencies[0]
+	fmt.Println(dep.ID, dep.Range)
+
+	// Output:
+	// [1.0.0 1.1.0]
+	// Contoso.Core [1.1.0, )
+}
+
+// Replace an endpoint with a canned failure.
+func ExampleFakeV3Server_Handle() {
+	server, err := nugethttptest.StartFakeV3Server(nugethttptest.Feed{})
+	if err != nil {
+		panic(err)
+	}
+	defer server.Close()
+
+	server.Handle(nugethttptest.ServiceIndexPath, nugethttptest.Unauthorized(`Basic realm="contoso"`))
+
+	resp, err := http.Get(server.SourceURL())
+	if err != nil {
+		panic(err)
+	}
+	_ = resp.Body.Close()
+	fmt.Println(resp.StatusCode, resp.Header.Get("WWW-Authenticate"))
+
+	// Output:
+	// 401 Basic realm="contoso"
+}
@@ -0,0 +1,183 @@
+package nugethttptest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/willibrandon/gonuget/version"
+)
+
+// Feed declares the packages a fake feed serves.
+type Feed struct {
+	Packages []Package
+
+	// Vulnerabilities are published with a VulnerabilityInfo resource when set
+	Vulnerabilities []Vulnerability
+}
+
+// Vulnerability is a known vulnerability of a range of versions of a package.
+type Vulnerability struct {
+	ID          string
+	Versions    string // Affected version range, e.g. "(, 13.0.1)"
+	Severity    int    // 0 low, 1 moderate, 2 high, 3 critical
+	AdvisoryURL string
+}
+
+// Package is one version of a package on a fake feed.
+type Package struct {
+	ID      string
+	Version string
+
+	Description string
+	Authors     string
+
+	// Dependencies apply to every target framework. DependencyGroups declare
+	// per-framework dependencies instead; a group with an empty TargetFramework
+	// applies to any framework.
+	Dependencies     []Dependency
+	DependencyGroups []DependencyGroup
+
+	// Unlisted packages are restorable but hidden from search
+	Unlisted bool
+
+	// Files are added to the generated .nupkg, keyed by path (e.g. "lib/net8.0/Foo.dll")
+	Files map[string][]byte
+
+	// Nupkg replaces the generated .nupkg when set, e.g. with a real signed package
+	Nupkg []byte
+}
+
+// DependencyGroup lists the dependencies of a package for one target framework.
+type DependencyGroup struct {
+	TargetFramework string
+	Dependencies    []Dependency
+}
+
+// Dependency is a dependency on a range of versions of another package.
+type Dependency struct {
+	ID    string
+	Range string
+}
+
+// dependencyGroups returns the package's dependency groups, with Dependencies as a
+// group without a target framework.
+func (p *Package) dependencyGroups() []DependencyGroup {
+	if len(p.Dependencies) == 0 {
+		return p.DependencyGroups
+	}
+	return append([]DependencyGroup{{Dependencies: p.Dependencies}}, p.DependencyGroups...)
+}
+
+// normalizedVersion returns the version as the feed publishes it in metadata.
+func (p *Package) normalizedVersion() string {
+	if v, err := version.Parse(p.Version); err == nil {
+		return v.ToNormalizedString()
+	}
+	return p.Version
+}
+
+// lowerVersion returns the version as it appears in flat container paths:
+// lowercase and without build metadata.
+func (p *Package) lowerVersion() string {
+	ver, _, _ := strings.Cut(p.normalizedVersion(), "+")
+	return strings.ToLower(ver)
+}
+
+// nuspecXML is the .nuspec written into generated packages.
+type nuspecXML struct {
+	XMLName  xml.Name       `xml:"http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd package"`
+	Metadata nuspecMetadata `xml:"metadata"`
+}
+
+type nuspecMetadata struct {
+	ID           string              `xml:"id"`
+	Version      string              `xml:"version"`
+	Authors      string              `xml:"authors"`
+	Description  string              `xml:"description"`
+	Dependencies *nuspecDependencies `xml:"dependencies,omitempty"`
+}
+
+type nuspecDependencies struct {
+	Groups []nuspecGroup `xml:"group"`
+}
+
+type nuspecGroup struct {
+	TargetFramework string             `xml:"targetFramework,attr,omitempty"`
+	Dependencies    []nuspecDependency `xml:"dependency"`
+}
+
+type nuspecDependency struct {
+	ID      string `xml:"id,attr"`
+	Version string `xml:"version,attr,omitempty"`
+}
+
+// nuspec returns the package's .nuspec document.
+func (p *Package) nuspec() ([]byte, error) {
+	authors := p.Authors
+	if authors == "" {
+		authors = "nugethttptest"
+	}
+	description := p.Description
+	if description == "" {
+		description = p.ID
+	}
+
+	doc := nuspecXML{Metadata: nuspecMetadata{
+		ID:          p.ID,
+		Version:     p.normalizedVersion(),
+		Authors:     authors,
+		Description: description,
+	}}
+	if groups := p.dependencyGroups(); len(groups) > 0 {
+		doc.Metadata.Dependencies = &nuspecDependencies{}
+		for _, group := range groups {
+			g := nuspecGroup{TargetFramework: group.TargetFramework}
+			for _, dep := range group.Dependencies {
+				g.Dependencies = append(g.Dependencies, nuspecDependency{ID: dep.ID, Version: dep.Range})
+			}
+			doc.Metadata.Dependencies.Groups = append(doc.Metadata.Dependencies.Groups, g)
+		}
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal nuspec: %w", err)
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// nupkg returns the package's .nupkg: Nupkg when set, otherwise a package holding the
+// .nuspec and Files.
+func (p *Package) nupkg() ([]byte, error) {
+	if p.Nupkg != nil {
+		return p.Nupkg, nil
+	}
+
+	nuspec, err := p.nuspec()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string][]byte{strings.ToLower(p.ID) + ".nuspec": nuspec}
+	for name, data := range p.Files {
+		files[name] = data
+	}
+	for _, name := range sortedKeys(files) {
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("write nupkg: %w", err)
+	}
+	return buf.Bytes(), nil
+}
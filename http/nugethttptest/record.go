@@ -0,0 +1,53 @@
+package nugethttptest
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	nugethttp "github.com/willibrandon/gonuget/http"
+)
+
+// RecordEnv is the environment variable that makes Transport record instead of replay.
+const RecordEnv = "GONUGET_HTTP_RECORD"
+
+// Record returns a transport that sends requests through base and records every
+// exchange into dir, in the format written by --capture-http. Credentials are redacted.
+// A nil base uses http.DefaultTransport.
+func Record(t testing.TB, dir string, base http.RoundTripper) http.RoundTripper {
+	t.Helper()
+
+	capture, err := nugethttp.NewCapture(dir)
+	if err != nil {
+		t.Fatalf("nugethttptest: %v", err)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return capture.Wrap(base)
+}
+
+// Replay returns a transport that serves the exchanges recorded in dir, by Record,
+// --capture-http or Transport, without network access. A request that was not
+// recorded fails with nugethttp.ErrNoCapturedExchange.
+func Replay(t testing.TB, dir string) http.RoundTripper {
+	t.Helper()
+
+	replay, err := nugethttp.NewReplayTransport(dir)
+	if err != nil {
+		t.Fatalf("nugethttptest: %v", err)
+	}
+	return replay
+}
+
+// Transport replays dir, or records real traffic into it when the RecordEnv
+// environment variable is set to 1. Record into an empty directory: numbering
+// continues after exchanges already there, and older captures of a URL are replayed first.
+func Transport(t testing.TB, dir string) http.RoundTripper {
+	t.Helper()
+
+	if os.Getenv(RecordEnv) == "1" {
+		return Record(t, dir, nil)
+	}
+	return Replay(t, dir)
+}
@@ -0,0 +1,41 @@
+package nugethttptest
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Unauthorized returns a handler that answers 401 with the given WWW-Authenticate
+// challenge, e.g. `Basic realm="feed"` or `Bearer authorization_uri="https://login.example.com"`.
+func Unauthorized(challenge string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", challenge)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	})
+}
+
+// ServiceUnavailable returns a handler that answers 503 with Retry-After set to the
+// delay in whole seconds. A zero delay sends no Retry-After.
+func ServiceUnavailable(retryAfter time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second)/time.Second)))
+		}
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+	})
+}
+
+// FailTimes returns a handler that serves the first n requests with failure and every
+// later one with next, for testing retries.
+func FailTimes(n int, failure, next http.Handler) http.Handler {
+	var served atomic.Int64
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if served.Add(1) <= int64(n) {
+			failure.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,374 @@
+package nugethttptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/willibrandon/gonuget/version"
+)
+
+// ServiceIndexPath is the path of the fake feed's service index.
+const ServiceIndexPath = "/v3/index.json"
+
+// Paths of the resources the service index advertises.
+const (
+	RegistrationPath  = "/v3/registration/"
+	FlatContainerPath = "/v3/flatcontainer/"
+	SearchPath        = "/v3/search"
+	VulnerabilityPath = "/v3/vulnerabilities/"
+)
+
+// FakeV3Server is an httptest server that serves a Feed over the NuGet V3 protocol.
+type FakeV3Server struct {
+	*httptest.Server
+
+	// packages by lowercase ID, sorted by version
+	packages map[string][]*Package
+
+	vulnerabilities []Vulnerability
+
+	mu        sync.Mutex
+	overrides map[string]http.Handler
+	requests  []string
+}
+
+// NewFakeV3Server starts a server for the feed and stops it when the test ends.
+// Its source URL (SourceURL) is the service index at ServiceIndexPath, which advertises:
+//
+//	RegistrationsBaseUrl  /v3/registration/{id}/index.json
+//	PackageBaseAddress    /v3/flatcontainer/{id}/index.json
+//	                      /v3/flatcontainer/{id}/{version}/{id}.{version}.nupkg
+//	                      /v3/flatcontainer/{id}/{version}/{id}.nuspec
+//	SearchQueryService    /v3/search?q=&skip=&take=&prerelease=
+//	VulnerabilityInfo     /v3/vulnerabilities/index.json (with Feed.Vulnerabilities)
+//	                      /v3/vulnerabilities/base.json
+func NewFakeV3Server(t testing.TB, feed Feed) *FakeV3Server {
+	t.Helper()
+
+	s, err := StartFakeV3Server(feed)
+	if err != nil {
+		t.Fatalf("nugethttptest: %v", err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+// StartFakeV3Server starts a server for the feed outside of a test, for example in an
+// example or a TestMain. The caller stops it with Close. It serves the same endpoints
+// as NewFakeV3Server.
+func StartFakeV3Server(feed Feed) (*FakeV3Server, error) {
+	s := &FakeV3Server{
+		packages:        make(map[string][]*Package),
+		vulnerabilities: feed.Vulnerabilities,
+		overrides:       make(map[string]http.Handler),
+	}
+	for i := range feed.Packages {
+		pkg := &feed.Packages[i]
+		if _, err := version.Parse(pkg.Version); err != nil {
+			return nil, fmt.Errorf("package %s has an invalid version: %w", pkg.ID, err)
+		}
+		id := strings.ToLower(pkg.ID)
+		s.packages[id] = append(s.packages[id], pkg)
+	}
+	for _, versions := range s.packages {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return version.MustParse(versions[i].Version).LessThan(version.MustParse(versions[j].Version))
+		})
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s, nil
+}
+
+// SourceURL returns the package source URL of the feed (its service index).
+func (s *FakeV3Server) SourceURL() string {
+	return s.URL + ServiceIndexPath
+}
+
+// Handle serves requests for path with handler instead of the feed, for example to
+// make one endpoint fail. Handlers registered for the same path replace each other.
+func (s *FakeV3Server) Handle(path string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[path] = handler
+}
+
+// Requests returns the requests served so far as "METHOD /path?query", in order.
+func (s *FakeV3Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+func (s *FakeV3Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	override := s.overrides[r.URL.Path]
+	s.mu.Unlock()
+
+	if override != nil {
+		override.ServeHTTP(w, r)
+		return
+	}
+	s.serveFeed(w, r)
+}
+
+// FeedHandler returns a handler that serves the feed, ignoring handlers registered with
+// Handle. Pass it to FailTimes to recover after a few failed requests.
+func (s *FakeV3Server) FeedHandler() http.Handler {
+	return http.HandlerFunc(s.serveFeed)
+}
+
+func (s *FakeV3Server) serveFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := r.URL.Path
+	switch {
+	case path == ServiceIndexPath:
+		s.serveServiceIndex(w)
+	case path == SearchPath:
+		s.serveSearch(w, r)
+	case strings.HasPrefix(path, RegistrationPath):
+		id, ok := strings.CutSuffix(strings.TrimPrefix(path, RegistrationPath), "/index.json")
+		if !ok || strings.Contains(id, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		s.serveRegistration(w, r, id)
+	case strings.HasPrefix(path, FlatContainerPath):
+		s.serveFlatContainer(w, r, strings.Split(strings.TrimPrefix(path, FlatContainerPath), "/"))
+	case strings.HasPrefix(path, VulnerabilityPath) && len(s.vulnerabilities) > 0:
+		s.serveVulnerabilities(w, r, strings.TrimPrefix(path, VulnerabilityPath))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *FakeV3Server) serveServiceIndex(w http.ResponseWriter) {
+	resources := []map[string]string{
+		{"@id": s.URL + RegistrationPath, "@type": "RegistrationsBaseUrl/3.6.0"},
+		{"@id": s.URL + FlatContainerPath, "@type": "PackageBaseAddress/3.0.0"},
+		{"@id": s.URL + SearchPath, "@type": "SearchQueryService/3.5.0"},
+	}
+	if len(s.vulnerabilities) > 0 {
+		resources = append(resources, map[string]string{"@id": s.URL + VulnerabilityPath + "index.json", "@type": "VulnerabilityInfo/6.7.0"})
+	}
+	writeJSON(w, map[string]any{
+		"version":   "3.0.0",
+		"resources": resources,
+	})
+}
+
+// serveVulnerabilities serves the VulnerabilityInfo index and its single "base" page.
+func (s *FakeV3Server) serveVulnerabilities(w http.ResponseWriter, r *http.Request, file string) {
+	switch file {
+	case "index.json":
+		writeJSON(w, []map[string]string{{
+			"@name":    "base",
+			"@id":      s.URL + VulnerabilityPath + "base.json",
+			"@updated": "2025-01-01T00:00:00Z",
+		}})
+	case "base.json":
+		page := make(map[string][]map[string]any)
+		for _, vulnerability := range s.vulnerabilities {
+			id := strings.ToLower(vulnerability.ID)
+			page[id] = append(page[id], map[string]any{
+				"url":      vulnerability.AdvisoryURL,
+				"severity": vulnerability.Severity,
+				"versions": vulnerability.Versions,
+			})
+		}
+		writeJSON(w, page)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *FakeV3Server) serveRegistration(w http.ResponseWriter, r *http.Request, id string) {
+	id = strings.ToLower(id)
+	versions := s.packages[id]
+	if len(versions) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	base := s.URL + RegistrationPath + id + "/"
+	leaves := make([]map[string]any, 0, len(versions))
+	for _, pkg := range versions {
+		groups := make([]map[string]any, 0)
+		for _, group := range pkg.dependencyGroups() {
+			deps := make([]map[string]string, 0, len(group.Dependencies))
+			for _, dep := range group.Dependencies {
+				deps = append(deps, map[string]string{"id": dep.ID, "range": dep.Range})
+			}
+			g := map[string]any{"dependencies": deps}
+			if group.TargetFramework != "" {
+				g["targetFramework"] = group.TargetFramework
+			}
+			groups = append(groups, g)
+		}
+
+		leaves = append(leaves, map[string]any{
+			"@id": base + pkg.lowerVersion() + ".json",
+			"catalogEntry": map[string]any{
+				"@id":              base + pkg.lowerVersion() + "/catalog.json",
+				"id":               pkg.ID,
+				"version":          pkg.normalizedVersion(),
+				"authors":          pkg.Authors,
+				"description":      pkg.Description,
+				"listed":           !pkg.Unlisted,
+				"dependencyGroups": groups,
+			},
+			"packageContent": s.packageURL(pkg),
+		})
+	}
+
+	writeJSON(w, map[string]any{
+		"count": 1,
+		"items": []map[string]any{{
+			"@id":   base + "index.json#page",
+			"count": len(leaves),
+			"lower": versions[0].normalizedVersion(),
+			"upper": versions[len(versions)-1].normalizedVersion(),
+			"items": leaves,
+		}},
+	})
+}
+
+// serveFlatContainer serves {id}/index.json, {id}/{version}/{id}.{version}.nupkg and {id}/{version}/{id}.nuspec.
+func (s *FakeV3Server) serveFlatContainer(w http.ResponseWriter, r *http.Request, parts []string) {
+	versions := s.packages[parts[0]]
+	if len(versions) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 2 && parts[1] == "index.json" {
+		lower := make([]string, len(versions))
+		for i, pkg := range versions {
+			lower[i] = pkg.lowerVersion()
+		}
+		writeJSON(w, map[string]any{"versions": lower})
+		return
+	}
+	if len(parts) != 3 {
+		http.NotFound(w, r)
+		return
+	}
+
+	for _, pkg := range versions {
+		if pkg.lowerVersion() != parts[1] {
+			continue
+		}
+
+		var data []byte
+		var err error
+		switch parts[2] {
+		case parts[0] + "." + parts[1] + ".nupkg":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			data, err = pkg.nupkg()
+		case parts[0] + ".nuspec":
+			w.Header().Set("Content-Type", "text/xml")
+			data, err = pkg.nuspec()
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(data)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// serveSearch matches the query against package IDs and descriptions and returns the
+// latest listed version of each match, ordered by ID.
+func (s *FakeV3Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("q"))
+	prerelease, _ := strconv.ParseBool(r.URL.Query().Get("prerelease"))
+	skip, _ := strconv.Atoi(r.URL.Query().Get("skip"))
+	take, err := strconv.Atoi(r.URL.Query().Get("take"))
+	if err != nil {
+		take = 20
+	}
+
+	var data []map[string]any
+	for _, id := range sortedKeys(s.packages) {
+		var listed []*Package
+		for _, pkg := range s.packages[id] {
+			if !pkg.Unlisted && (prerelease || !version.MustParse(pkg.Version).IsPrerelease()) {
+				listed = append(listed, pkg)
+			}
+		}
+		if len(listed) == 0 {
+			continue
+		}
+		latest := listed[len(listed)-1]
+		if !strings.Contains(id, query) && !strings.Contains(strings.ToLower(latest.Description), query) {
+			continue
+		}
+
+		versions := make([]map[string]any, len(listed))
+		for i, pkg := range listed {
+			versions[i] = map[string]any{
+				"version":   pkg.normalizedVersion(),
+				"downloads": 0,
+				"@id":       s.URL + RegistrationPath + id + "/" + pkg.lowerVersion() + ".json",
+			}
+		}
+		authors := []string{}
+		if latest.Authors != "" {
+			authors = append(authors, latest.Authors)
+		}
+		data = append(data, map[string]any{
+			"@id":            s.URL + RegistrationPath + id + "/index.json",
+			"@type":          "Package",
+			"registration":   s.URL + RegistrationPath + id + "/index.json",
+			"id":             latest.ID,
+			"version":        latest.normalizedVersion(),
+			"description":    latest.Description,
+			"authors":        authors,
+			"totalDownloads": 0,
+			"versions":       versions,
+		})
+	}
+
+	total := len(data)
+	data = data[min(skip, total):min(skip+max(take, 0), total)]
+	writeJSON(w, map[string]any{"totalHits": total, "data": data})
+}
+
+// packageURL returns the flat container URL of the package's .nupkg.
+func (s *FakeV3Server) packageURL(pkg *Package) string {
+	id := strings.ToLower(pkg.ID)
+	ver := pkg.lowerVersion()
+	return fmt.Sprintf("%s%s%s/%s/%s.%s.nupkg", s.URL, FlatContainerPath, id, ver, id, ver)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
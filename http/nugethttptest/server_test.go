@@ -0,0 +1,244 @@
+package nugethttptest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+	"github.com/willibrandon/gonuget/packaging"
+	v3 "github.com/willibrandon/gonuget/protocol/v3"
+)
+
+var testFeed = nugethttptest.Feed{
+	Packages: []nugethttptest.Package{
+		{ID: "Contoso.Core", Version: "1.0.0", Description: "Core types", Authors: "Contoso"},
+		{ID: "Contoso.Core", Version: "2.0.0-beta.1", Description: "Core types"},
+		{ID: "Contoso.Core", Version: "1.10.0", Description: "Core types", Authors: "Contoso"},
+		{
+			ID:           "Contoso.Web",
+			Version:      "1.0.0+build.5",
+			Dependencies: []nugethttptest.Dependency{{ID: "Contoso.Core", Range: "[1.0.0, )"}},
+			DependencyGroups: []nugethttptest.DependencyGroup{
+				{TargetFramework: "net8.0", Dependencies: []nugethttptest.Dependency{{ID: "Contoso.Hosting", Range: "2.0.0"}}},
+			},
+			Files: map[string][]byte{"lib/net8.0/Contoso.Web.dll": []byte("MZ")},
+		},
+		{ID: "Contoso.Legacy", Version: "0.9.0", Unlisted: true},
+	},
+}
+
+func newClients(httpClient *nugethttp.Client) (*v3.MetadataClient, *v3.DownloadClient, *v3.SearchClient) {
+	serviceIndex := v3.NewServiceIndexClient(httpClient)
+	return v3.NewMetadataClient(httpClient, serviceIndex),
+		v3.NewDownloadClient(httpClient, serviceIndex),
+		v3.NewSearchClient(httpClient, serviceIndex)
+}
+
+func TestFakeV3Server_Registration(t *testing.T) {
+	server := nugethttptest.NewFakeV3Server(t, testFeed)
+	metadata, _, _ := newClients(nugethttp.NewClient(nil))
+	ctx := context.Background()
+
+	versions, err := metadata.ListVersions(ctx, server.SourceURL(), "contoso.core")
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if want := []string{"1.0.0", "1.10.0", "2.0.0-beta.1"}; !slices.Equal(versions, want) {
+		t.Errorf("ListVersions() = %v, want %v", versions, want)
+	}
+
+	entry, err := metadata.GetVersionMetadata(ctx, server.SourceURL(), "Contoso.Web", "1.0.0+build.5")
+	if err != nil {
+		t.Fatalf("GetVersionMetadata() error = %v", err)
+	}
+	if entry.PackageID != "Contoso.Web" || len(entry.DependencyGroups) != 2 {
+		t.Fatalf("GetVersionMetadata() = %+v, want Contoso.Web with two dependency groups", entry)
+	}
+	if group := entry.DependencyGroups[1]; group.TargetFramework != "net8.0" || group.Dependencies[0].ID != "Contoso.Hosting" {
+		t.Errorf("dependency group = %+v, want net8.0 -> Contoso.Hosting", group)
+	}
+
+	if _, err := metadata.GetPackageMetadata(ctx, server.SourceURL(), "Missing"); err == nil {
+		t.Error("GetPackageMetadata() expected error for a package not on the feed")
+	}
+}
+
+func TestFakeV3Server_FlatContainer(t *testing.T) {
+	server := nugethttptest.NewFakeV3Server(t, testFeed)
+	_, download, _ := newClients(nugethttp.NewClient(nil))
+	ctx := context.Background()
+
+	versions, err := download.GetPackageVersions(ctx, server.SourceURL(), "Contoso.Core")
+	if err != nil {
+		t.Fatalf("GetPackageVersions() error = %v", err)
+	}
+	if len(versions) != 3 {
+		t.Errorf("GetPackageVersions() = %v, want 3 versions", versions)
+	}
+
+	rc, err := download.DownloadPackage(ctx, server.SourceURL(), "Contoso.Web", "1.0.0")
+	if err != nil {
+		t.Fatalf("DownloadPackage() error = %v", err)
+	}
+	data, err := io.ReadAll(rc)
+	_ = rc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := packaging.OpenPackageFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("OpenPackageFromReaderAt() error = %v", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	nuspec, err := reader.GetNuspec()
+	if err != nil {
+		t.Fatalf("GetNuspec() error = %v", err)
+	}
+	if nuspec.Metadata.ID != "Contoso.Web" || nuspec.Metadata.Version != "1.0.0+build.5" {
+		t.Errorf("nuspec = %s %s, want Contoso.Web 1.0.0+build.5", nuspec.Metadata.ID, nuspec.Metadata.Version)
+	}
+	if !slices.Contains(reader.GetFiles(), "lib/net8.0/Contoso.Web.dll") {
+		t.Errorf("files = %v, want lib/net8.0/Contoso.Web.dll", reader.GetFiles())
+	}
+}
+
+func TestFakeV3Server_Search(t *testing.T) {
+	server := nugethttptest.NewFakeV3Server(t, testFeed)
+	_, _, search := newClients(nugethttp.NewClient(nil))
+	ctx := context.Background()
+
+	resp, err := search.Search(ctx, server.SourceURL(), v3.SearchOptions{Query: "contoso"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	// Unlisted packages are hidden, and prerelease versions only show when asked for
+	if resp.TotalHits != 2 || resp.Data[0].PackageID != "Contoso.Core" || resp.Data[0].Version != "1.10.0" {
+		t.Errorf("Search() = %+v, want Contoso.Core 1.10.0 and Contoso.Web", resp)
+	}
+
+	resp, err = search.Search(ctx, server.SourceURL(), v3.SearchOptions{Query: "core types", Prerelease: true, Take: 1})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if resp.TotalHits != 1 || len(resp.Data) != 1 || resp.Data[0].Version != "2.0.0-beta.1" {
+		t.Errorf("Search(prerelease) = %+v, want Contoso.Core 2.0.0-beta.1", resp)
+	}
+}
+
+func TestFakeV3Server_Vulnerabilities(t *testing.T) {
+	httpClient := nugethttp.NewClient(nil)
+	vulnerabilityClient := v3.NewVulnerabilityClient(httpClient, v3.NewServiceIndexClient(httpClient))
+	ctx := context.Background()
+
+	server := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{
+		Vulnerabilities: []nugethttptest.Vulnerability{
+			{ID: "Contoso.Core", Versions: "(, 1.10.0)", Severity: 3, AdvisoryURL: "https://example.com/advisories/1"},
+		},
+	})
+	vulnerabilities, err := vulnerabilityClient.GetVulnerabilities(ctx, server.SourceURL())
+	if err != nil {
+		t.Fatalf("GetVulnerabilities() error = %v", err)
+	}
+	got := vulnerabilities["contoso.core"]
+	if len(got) != 1 || got[0].Versions != "(, 1.10.0)" || got[0].Severity != 3 || got[0].URL != "https://example.com/advisories/1" {
+		t.Errorf("GetVulnerabilities() = %+v, want the Contoso.Core advisory", vulnerabilities)
+	}
+
+	// Without vulnerabilities the resource isn't advertised
+	vulnerabilities, err = vulnerabilityClient.GetVulnerabilities(ctx, nugethttptest.NewFakeV3Server(t, testFeed).SourceURL())
+	if err != nil || vulnerabilities != nil {
+		t.Errorf("GetVulnerabilities() = %v, %v, want nil for a feed without vulnerabilities", vulnerabilities, err)
+	}
+}
+
+func TestFakeV3Server_Handle(t *testing.T) {
+	server := nugethttptest.NewFakeV3Server(t, testFeed)
+	server.Handle(nugethttptest.ServiceIndexPath, nugethttptest.Unauthorized(`Basic realm="contoso"`))
+
+	resp, err := http.Get(server.SourceURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") != `Basic realm="contoso"` {
+		t.Errorf("got %d with challenge %q, want 401 with the Basic challenge", resp.StatusCode, resp.Header.Get("WWW-Authenticate"))
+	}
+
+	server.Handle(nugethttptest.ServiceIndexPath, nugethttptest.ServiceUnavailable(90*time.Second))
+	resp, err = http.Get(server.SourceURL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") != "90" {
+		t.Errorf("got %d with Retry-After %q, want 503 with 90", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+}
+
+func TestFakeV3Server_FailTimes(t *testing.T) {
+	server := nugethttptest.NewFakeV3Server(t, testFeed)
+	registration := nugethttptest.RegistrationPath + "contoso.core/index.json"
+	server.Handle(registration, nugethttptest.FailTimes(2, nugethttptest.ServiceUnavailable(0), server.FeedHandler()))
+
+	httpClient := nugethttp.NewClient(&nugethttp.Config{
+		RetryConfig: &nugethttp.RetryConfig{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, BackoffFactor: 1},
+	})
+	metadata, _, _ := newClients(httpClient)
+
+	versions, err := metadata.ListVersions(context.Background(), server.SourceURL(), "Contoso.Core")
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if len(versions) != 3 {
+		t.Errorf("ListVersions() = %v, want 3 versions", versions)
+	}
+
+	var attempts int
+	for _, req := range server.Requests() {
+		if req == "GET "+registration {
+			attempts++
+		}
+	}
+	if attempts != 3 {
+		t.Errorf("registration requested %d times, want 3 (two failures and a retry that succeeds)", attempts)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	server := nugethttptest.NewFakeV3Server(t, testFeed)
+	dir := t.TempDir()
+
+	recording := nugethttp.NewClientWithOptions(nugethttp.WithTransport(nugethttptest.Record(t, dir, nil)))
+	metadata, _, _ := newClients(recording)
+	want, err := metadata.ListVersions(context.Background(), server.SourceURL(), "Contoso.Core")
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+
+	// The feed is gone; the recording answers instead
+	server.Close()
+
+	replaying := nugethttp.NewClientWithOptions(nugethttp.WithTransport(nugethttptest.Replay(t, dir)))
+	metadata, _, _ = newClients(replaying)
+	got, err := metadata.ListVersions(context.Background(), server.SourceURL(), "Contoso.Core")
+	if err != nil {
+		t.Fatalf("ListVersions() from replay error = %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("replayed ListVersions() = %v, want %v", got, want)
+	}
+
+	_, err = metadata.ListVersions(context.Background(), server.SourceURL(), "Contoso.Web")
+	if !errors.Is(err, nugethttp.ErrNoCapturedExchange) {
+		t.Errorf("ListVersions() of an unrecorded package error = %v, want ErrNoCapturedExchange", err)
+	}
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrNoCapturedExchange is returned by ReplayTransport for a request that was not captured.
+var ErrNoCapturedExchange = errors.New("no captured exchange matches request")
+
+// ReplayTransport serves requests from a directory written by Capture, so a run can be
+// reproduced without network access. Requests are matched on method and redacted URL.
+// Repeated requests are served by successive captures of the same URL; once those run
+// out, the last one is served again.
+type ReplayTransport struct {
+	dir       string
+	exchanges []*CapturedExchange
+
+	mu     sync.Mutex
+	served map[int]bool
+}
+
+// NewReplayTransport loads the exchanges captured in dir.
+func NewReplayTransport(dir string) (*ReplayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("read capture directory: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no captured exchanges in %s", dir)
+	}
+
+	exchanges := make([]*CapturedExchange, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read captured exchange: %w", err)
+		}
+		var exchange CapturedExchange
+		if err := json.Unmarshal(data, &exchange); err != nil {
+			return nil, fmt.Errorf("parse captured exchange %s: %w", filepath.Base(file), err)
+		}
+		exchanges = append(exchanges, &exchange)
+	}
+
+	sort.Slice(exchanges, func(i, j int) bool {
+		return exchanges[i].Sequence < exchanges[j].Sequence
+	})
+
+	return &ReplayTransport{dir: dir, exchanges: exchanges, served: make(map[int]bool)}, nil
+}
+
+// Wrap returns the replay transport in place of base.
+// It has the signature expected by SetTransportWrapper.
+func (t *ReplayTransport) Wrap(http.RoundTripper) http.RoundTripper {
+	return t
+}
+
+// RoundTrip serves the captured response for the request.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	requested := req.Method + " " + RedactURL(req.URL)
+	exchange := t.match(requested)
+	if exchange == nil {
+		return nil, t.mismatchError(requested)
+	}
+
+	if exchange.Error != "" {
+		return nil, fmt.Errorf("captured error for %s: %s", requested, exchange.Error)
+	}
+
+	var body []byte
+	if exchange.BodyFile != "" {
+		data, err := os.ReadFile(filepath.Join(t.dir, exchange.BodyFile))
+		if err != nil {
+			return nil, fmt.Errorf("read captured body: %w", err)
+		}
+		body = data
+	}
+
+	header := exchange.ResponseHeaders.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	// The stored body is what the transport returned (Content-Encoding is kept for
+	// bodies it did not decode) and may be truncated
+	header.Del("Content-Length")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+		StatusCode:    exchange.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// match returns the first unserved exchange for the request, or the last served one.
+func (t *ReplayTransport) match(requested string) *CapturedExchange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var last *CapturedExchange
+	for i, exchange := range t.exchanges {
+		if exchange.Method+" "+exchange.URL != requested {
+			continue
+		}
+		if !t.served[i] {
+			t.served[i] = true
+			return exchange
+		}
+		last = exchange
+	}
+	return last
+}
+
+// mismatchError describes an unmatched request alongside the closest captured one.
+func (t *ReplayTransport) mismatchError(requested string) error {
+	nearest, nearestPrefix := "", -1
+	for _, exchange := range t.exchanges {
+		captured := exchange.Method + " " + exchange.URL
+		if prefix := commonPrefixLen(captured, requested); prefix > nearestPrefix {
+			nearest, nearestPrefix = captured, prefix
+		}
+	}
+
+	return fmt.Errorf("%w: %s\nnearest capture:\n- %s\n+ %s\n  %s^",
+		ErrNoCapturedExchange, requested, nearest, requested, strings.Repeat(" ", nearestPrefix))
+}
+
+// commonPrefixLen returns the number of leading bytes a and b share.
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := range n {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
@@ -0,0 +1,231 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Default maximum body sizes of feed responses, by response class.
+const (
+	DefaultMaxServiceIndexSize = 10 << 20  // 10 MB
+	DefaultMaxRegistrationSize = 100 << 20 // 100 MB
+	DefaultMaxVersionListSize  = 10 << 20  // 10 MB
+	DefaultMaxSearchSize       = 10 << 20  // 10 MB
+
+	// MaxPackageSize bounds the Content-Length a package download may announce. Package
+	// downloads are streamed rather than read whole, so they have no response class limit.
+	MaxPackageSize = 8 << 30 // 8 GB
+)
+
+// htmlSniffLength is how much of a body is inspected to recognize an HTML page
+const htmlSniffLength = 512
+
+// ResponseClass is a kind of feed response. Each class has its own maximum body size.
+type ResponseClass int
+
+const (
+	// ResponseServiceIndex is a V3 service index or a V2 service document.
+	ResponseServiceIndex ResponseClass = iota
+	// ResponseRegistration is a V3 registration index or page, or a V2 feed of package entries.
+	ResponseRegistration
+	// ResponseVersionList is a V3 flat container version list.
+	ResponseVersionList
+	// ResponseSearch is a search or autocomplete result.
+	ResponseSearch
+	// ResponsePackage is a package download. Only its announced Content-Length is
+	// checked, against MaxPackageSize.
+	ResponsePackage
+)
+
+// String returns the name of the response class used in error messages.
+func (c ResponseClass) String() string {
+	switch c {
+	case ResponseServiceIndex:
+		return "service index"
+	case ResponseRegistration:
+		return "registration"
+	case ResponseVersionList:
+		return "version list"
+	case ResponseSearch:
+		return "search"
+	case ResponsePackage:
+		return "package"
+	default:
+		return fmt.Sprintf("ResponseClass(%d)", int(c))
+	}
+}
+
+// ResponseLimits holds the maximum body size, in bytes, of each response class.
+// A zero field uses the class default.
+type ResponseLimits struct {
+	ServiceIndex int64
+	Registration int64
+	VersionList  int64
+	Search       int64
+}
+
+// DefaultResponseLimits returns the default maximum body sizes.
+func DefaultResponseLimits() ResponseLimits {
+	return ResponseLimits{
+		ServiceIndex: DefaultMaxServiceIndexSize,
+		Registration: DefaultMaxRegistrationSize,
+		VersionList:  DefaultMaxVersionListSize,
+		Search:       DefaultMaxSearchSize,
+	}
+}
+
+// limit returns the maximum body size of class
+func (l ResponseLimits) limit(class ResponseClass) int64 {
+	defaults := DefaultResponseLimits()
+	var limit, def int64
+	switch class {
+	case ResponseServiceIndex:
+		limit, def = l.ServiceIndex, defaults.ServiceIndex
+	case ResponseRegistration:
+		limit, def = l.Registration, defaults.Registration
+	case ResponseVersionList:
+		limit, def = l.VersionList, defaults.VersionList
+	case ResponsePackage:
+		return MaxPackageSize
+	default:
+		limit, def = l.Search, defaults.Search
+	}
+	if limit <= 0 {
+		return def
+	}
+	return limit
+}
+
+// ContentFormat is the format a feed response body is expected in.
+type ContentFormat int
+
+const (
+	// ContentJSON is a V3 JSON response.
+	ContentJSON ContentFormat = iota
+	// ContentXML is a V2 Atom or OData XML response.
+	ContentXML
+)
+
+func (f ContentFormat) String() string {
+	if f == ContentXML {
+		return "XML"
+	}
+	return "JSON"
+}
+
+// ResponseTooLargeError is returned when a feed response is larger than the limit of
+// its response class.
+type ResponseTooLargeError struct {
+	URL   string
+	Class ResponseClass
+	Limit int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("%s response from %s exceeds the limit of %d bytes", e.Class, e.URL, e.Limit)
+}
+
+// MisconfiguredSourceError is returned when a source answers with an HTML page where a
+// feed response was expected. It usually means the source URL points at a web site,
+// or a sign-in or proxy page answered instead of the feed.
+type MisconfiguredSourceError struct {
+	URL         string
+	ContentType string
+	Expected    ContentFormat
+}
+
+func (e *MisconfiguredSourceError) Error() string {
+	return fmt.Sprintf("%s returned an HTML page (Content-Type %q) instead of %s; check that the source URL points at a NuGet feed and that a sign-in or proxy page isn't answering instead",
+		e.URL, e.ContentType, e.Expected)
+}
+
+// ResponseLimit returns the maximum body size of the client's responses of class.
+func (c *Client) ResponseLimit(class ResponseClass) int64 {
+	return c.responseLimits.limit(class)
+}
+
+// ReadBody reads the body of a successful feed response of class, expected in format.
+// The body may not exceed the class limit: a larger response is reported as a
+// *ResponseTooLargeError without reading more than the limit. An HTML page is
+// reported as a *MisconfiguredSourceError; a Content-Type that doesn't match format
+// is only logged, as feeds commonly mislabel their responses.
+func (c *Client) ReadBody(ctx context.Context, resp *http.Response, class ResponseClass, format ContentFormat) ([]byte, error) {
+	url := responseURL(resp)
+	limit := c.ResponseLimit(class)
+	if resp.ContentLength > limit {
+		return nil, &ResponseTooLargeError{URL: url, Class: class, Limit: limit}
+	}
+
+	// Recognize an HTML page from the start of the body before reading the rest
+	if err := DetectHTMLPage(resp, format); err != nil {
+		return nil, err
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !contentTypeMatches(contentType, format) {
+		c.logger.WarnContext(ctx, "HTTP {URL} returned Content-Type {ContentType} for a {Format} response",
+			url, contentType, format.String())
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s response from %s: %w", class, url, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, &ResponseTooLargeError{URL: url, Class: class, Limit: limit}
+	}
+	return data, nil
+}
+
+// DetectHTMLPage returns a *MisconfiguredSourceError when the body of resp is an HTML
+// page where a format response was expected. Only the start of the body is inspected;
+// resp.Body still reads the whole body afterwards.
+func DetectHTMLPage(resp *http.Response, format ContentFormat) error {
+	body := bufio.NewReaderSize(resp.Body, htmlSniffLength)
+	head, _ := body.Peek(htmlSniffLength)
+	resp.Body = &decodedBody{Reader: body, body: resp.Body}
+
+	if looksLikeHTML(head) {
+		return &MisconfiguredSourceError{URL: responseURL(resp), ContentType: resp.Header.Get("Content-Type"), Expected: format}
+	}
+	return nil
+}
+
+// CheckPackageContentLength rejects a package download that announces a Content-Length
+// above MaxPackageSize.
+func CheckPackageContentLength(resp *http.Response) error {
+	if resp.ContentLength > MaxPackageSize {
+		return &ResponseTooLargeError{URL: responseURL(resp), Class: ResponsePackage, Limit: MaxPackageSize}
+	}
+	return nil
+}
+
+// responseURL returns the URL a response was received from
+func responseURL(resp *http.Response) string {
+	if resp.Request != nil && resp.Request.URL != nil {
+		return resp.Request.URL.String()
+	}
+	return "the source"
+}
+
+// contentTypeMatches reports whether contentType is a media type of format
+func contentTypeMatches(contentType string, format ContentFormat) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if format == ContentXML {
+		return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+	}
+	return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// looksLikeHTML reports whether head, the start of a body, is an HTML document
+func looksLikeHTML(head []byte) bool {
+	trimmed := bytes.ToLower(bytes.TrimLeft(head, " \t\r\n\ufeff"))
+	return bytes.HasPrefix(trimmed, []byte("<!doctype html")) || bytes.HasPrefix(trimmed, []byte("<html"))
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/observability"
+)
+
+// countingReader counts the bytes read through it, bounding how much of a body was
+// buffered in memory
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// endlessJSON is a JSON array that never ends
+type endlessJSON struct{ started bool }
+
+func (e *endlessJSON) Read(p []byte) (int, error) {
+	for i := range p {
+		switch {
+		case !e.started:
+			p[i] = '['
+			e.started = true
+		case i%2 == 0:
+			p[i] = '1'
+		default:
+			p[i] = ','
+		}
+	}
+	return len(p), nil
+}
+
+func newTestResponse(contentType string, contentLength int64, body io.Reader) *http.Response {
+	resp := &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        make(http.Header),
+		ContentLength: contentLength,
+		Body:          io.NopCloser(body),
+		Request:       &http.Request{URL: &url.URL{Scheme: "https", Host: "feed.example", Path: "/v3/index.json"}},
+	}
+	if contentType != "" {
+		resp.Header.Set("Content-Type", contentType)
+	}
+	return resp
+}
+
+func TestReadBody(t *testing.T) {
+	client := NewClient(nil)
+	resp := newTestResponse("application/json; charset=utf-8", -1, strings.NewReader(`{"version":"3.0.0"}`))
+
+	data, err := client.ReadBody(context.Background(), resp, ResponseServiceIndex, ContentJSON)
+	if err != nil {
+		t.Fatalf("ReadBody() error = %v", err)
+	}
+	if string(data) != `{"version":"3.0.0"}` {
+		t.Errorf("ReadBody() = %q", data)
+	}
+}
+
+func TestReadBody_TooLarge(t *testing.T) {
+	const limit = 64 << 10
+	client := NewClient(&Config{ResponseLimits: &ResponseLimits{Registration: limit}})
+
+	tests := []struct {
+		name          string
+		contentLength int64
+		maxRead       int64
+	}{
+		// The announced length is rejected before the body is read
+		{"announced", 1 << 40, 0},
+		// An endless body is read up to the limit
+		{"streamed", -1, limit + htmlSniffLength + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := &countingReader{r: &endlessJSON{}}
+			resp := newTestResponse("application/json", tt.contentLength, body)
+
+			_, err := client.ReadBody(context.Background(), resp, ResponseRegistration, ContentJSON)
+			var tooLarge *ResponseTooLargeError
+			if !errors.As(err, &tooLarge) {
+				t.Fatalf("ReadBody() error = %v, want a *ResponseTooLargeError", err)
+			}
+			if tooLarge.Limit != limit || tooLarge.Class != ResponseRegistration || tooLarge.URL != "https://feed.example/v3/index.json" {
+				t.Errorf("error = %+v, want the registration limit and URL", tooLarge)
+			}
+			if !strings.Contains(err.Error(), "https://feed.example/v3/index.json") || !strings.Contains(err.Error(), "65536 bytes") {
+				t.Errorf("Error() = %q, want the URL and the limit", err)
+			}
+			if body.n > tt.maxRead {
+				t.Errorf("read %d bytes of the body, want at most %d", body.n, tt.maxRead)
+			}
+		})
+	}
+}
+
+func TestReadBody_HTMLPage(t *testing.T) {
+	page := "\n  <!DOCTYPE html>\n<html><head><title>Sign in</title></head><body>" + strings.Repeat("x", 1<<20) + "</body></html>"
+
+	for _, contentType := range []string{"text/html; charset=utf-8", "application/json"} {
+		t.Run(contentType, func(t *testing.T) {
+			body := &countingReader{r: strings.NewReader(page)}
+			resp := newTestResponse(contentType, -1, body)
+
+			_, err := NewClient(nil).ReadBody(context.Background(), resp, ResponseServiceIndex, ContentJSON)
+			var misconfigured *MisconfiguredSourceError
+			if !errors.As(err, &misconfigured) {
+				t.Fatalf("ReadBody() error = %v, want a *MisconfiguredSourceError", err)
+			}
+			if misconfigured.ContentType != contentType || misconfigured.Expected != ContentJSON {
+				t.Errorf("error = %+v", misconfigured)
+			}
+			if body.n > htmlSniffLength {
+				t.Errorf("read %d bytes of the page, want only the start", body.n)
+			}
+		})
+	}
+}
+
+func TestReadBody_MislabeledContentType(t *testing.T) {
+	var logs bytes.Buffer
+	client := NewClient(&Config{Logger: observability.NewLogger(&logs, observability.WarnLevel)})
+
+	tests := []struct {
+		contentType string
+		format      ContentFormat
+		body        string
+		wantWarning bool
+	}{
+		{"text/plain", ContentJSON, `{"versions":["1.0.0"]}`, true},
+		{"application/octet-stream", ContentJSON, `{"versions":["1.0.0"]}`, true},
+		{"application/atom+xml;type=feed", ContentXML, `<feed/>`, false},
+		{"application/xml", ContentXML, `<?xml version="1.0"?><service/>`, false},
+		{"text/html", ContentXML, `<?xml version="1.0"?><feed/>`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			logs.Reset()
+			resp := newTestResponse(tt.contentType, -1, strings.NewReader(tt.body))
+
+			data, err := client.ReadBody(context.Background(), resp, ResponseVersionList, tt.format)
+			if err != nil {
+				t.Fatalf("ReadBody() error = %v, want the mislabeled body", err)
+			}
+			if string(data) != tt.body {
+				t.Errorf("ReadBody() = %q, want %q", data, tt.body)
+			}
+			if got := strings.Contains(logs.String(), tt.contentType); got != tt.wantWarning {
+				t.Errorf("warning logged = %v, want %v: %q", got, tt.wantWarning, logs.String())
+			}
+		})
+	}
+}
+
+func TestResponseLimit_Defaults(t *testing.T) {
+	client := NewClient(&Config{ResponseLimits: &ResponseLimits{Search: 1 << 20}})
+
+	tests := []struct {
+		class ResponseClass
+		want  int64
+	}{
+		{ResponseServiceIndex, 10 << 20},
+		{ResponseRegistration, 100 << 20},
+		{ResponseVersionList, 10 << 20},
+		{ResponseSearch, 1 << 20},
+		{ResponsePackage, MaxPackageSize},
+	}
+	for _, tt := range tests {
+		if got := client.ResponseLimit(tt.class); got != tt.want {
+			t.Errorf("ResponseLimit(%s) = %d, want %d", tt.class, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPackageContentLength(t *testing.T) {
+	if err := CheckPackageContentLength(newTestResponse("application/octet-stream", 50<<20, nil)); err != nil {
+		t.Errorf("CheckPackageContentLength() error = %v for a 50 MB package", err)
+	}
+
+	err := CheckPackageContentLength(newTestResponse("application/octet-stream", MaxPackageSize+1, nil))
+	var tooLarge *ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Class != ResponsePackage {
+		t.Errorf("CheckPackageContentLength() error = %v, want a package *ResponseTooLargeError", err)
+	}
+}
@@ -52,6 +52,11 @@ func IsRetriable(err error) bool {
 		return false
 	}
 
+	// A request missing from a replayed capture fails the same way every time
+	if errors.Is(err, ErrNoCapturedExchange) {
+		return false
+	}
+
 	// Network errors are retriable
 	var netErr net.Error
 	if errors.As(err, &netErr) {
@@ -71,15 +76,58 @@ func IsRetriable(err error) bool {
 // IsRetriableStatus determines if an HTTP status code should be retried
 func IsRetriableStatus(code int) bool {
 	switch code {
-	case http.StatusTooManyRequests, // 429
-		http.StatusServiceUnavailable, // 503
-		http.StatusGatewayTimeout:     // 504
+	case http.StatusRequestTimeout, // 408
+		http.StatusTooManyRequests,     // 429
+		http.StatusInternalServerError, // 500
+		http.StatusBadGateway,          // 502
+		http.StatusServiceUnavailable,  // 503
+		http.StatusGatewayTimeout:      // 504
+		return true
+	default:
+		return false
+	}
+}
+
+// isIdempotent reports whether a request with method can be sent again safely
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions:
 		return true
 	default:
 		return false
 	}
 }
 
+// RetryEvent describes a request that is about to be retried
+type RetryEvent struct {
+	Method     string
+	URL        string
+	Attempt    int           // Retry number, starting at 1
+	MaxRetries int           // Retries allowed for the request
+	Delay      time.Duration // Wait before the retry (Retry-After or backoff)
+	StatusCode int           // Status of the failed attempt (0 when it got no response)
+	Err        error         // Error of the failed attempt (nil when it got a response)
+}
+
+// retryObserverKey is the context key of the retry observer
+type retryObserverKey struct{}
+
+// WithRetryObserver returns a context whose requests sent with DoWithRetry report each
+// retry to observe, e.g. to print it or to count retries in tests. observe may be
+// called from several goroutines at once.
+func WithRetryObserver(ctx context.Context, observe func(RetryEvent)) context.Context {
+	if observe == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, retryObserverKey{}, observe)
+}
+
+// retryObserver returns the retry observer of ctx, or nil
+func retryObserver(ctx context.Context) func(RetryEvent) {
+	observe, _ := ctx.Value(retryObserverKey{}).(func(RetryEvent))
+	return observe
+}
+
 // CalculateBackoff computes exponential backoff with jitter
 func (rc *RetryConfig) CalculateBackoff(attempt int) time.Duration {
 	if attempt < 0 {
@@ -43,9 +43,13 @@ func TestIsRetriableStatus(t *testing.T) {
 		want bool
 	}{
 		{200, false},
+		{400, false},
 		{404, false},
+		{408, true},
 		{429, true},
-		{500, false},
+		{500, true},
+		{501, false},
+		{502, true},
 		{503, true},
 		{504, true},
 	}
@@ -175,6 +179,85 @@ func TestClient_DoWithRetry_MaxRetriesExceeded(t *testing.T) {
 	}
 }
 
+func TestClient_DoWithRetry_RetryObserver(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&attempts, 1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.RetryConfig = &RetryConfig{
+		MaxRetries:     3,
+		InitialBackoff: 1 * time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		BackoffFactor:  2.0,
+	}
+	client := NewClient(cfg)
+
+	var events []RetryEvent
+	ctx := WithRetryObserver(context.Background(), func(event RetryEvent) {
+		events = append(events, event)
+	})
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := client.DoWithRetry(ctx, req)
+	if err != nil {
+		t.Fatalf("DoWithRetry() error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK || attempts != 3 {
+		t.Fatalf("StatusCode = %d after %d attempts, want 200 after 3", resp.StatusCode, attempts)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %+v, want 2 retries", events)
+	}
+	for i, want := range []int{http.StatusBadGateway, http.StatusInternalServerError} {
+		event := events[i]
+		if event.Attempt != i+1 || event.MaxRetries != 3 || event.StatusCode != want || event.Method != "GET" || event.URL != server.URL {
+			t.Errorf("events[%d] = %+v, want retry %d after %d", i, event, i+1, want)
+		}
+	}
+}
+
+func TestClient_DoWithRetry_NonIdempotentNotRetried(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := DefaultConfig()
+	cfg.RetryConfig = &RetryConfig{
+		MaxRetries:     3,
+		InitialBackoff: 1 * time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		BackoffFactor:  2.0,
+	}
+	client := NewClient(cfg)
+
+	req, _ := http.NewRequest("PUT", server.URL, nil)
+	resp, err := client.DoWithRetry(context.Background(), req)
+	if err != nil {
+		t.Fatalf("DoWithRetry() error = %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want a PUT sent once", attempts)
+	}
+}
+
 func TestClient_DoWithRetry_NonRetriableError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
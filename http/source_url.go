@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// CanonicalSourceURL returns the form of a package source URL used to compare sources:
+// scheme and host lowercased, a default port dropped, IPv6 literals in their shortest
+// bracketed form, and no trailing slash. Local paths and URLs that don't parse are only
+// stripped of a trailing slash and lowercased.
+func CanonicalSourceURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return strings.ToLower(strings.TrimSuffix(rawURL, "/"))
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if ip := net.ParseIP(host); ip != nil {
+		host = ip.String()
+	}
+	port := u.Port()
+	if port == defaultPort(u.Scheme) {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+
+	canonical := u.Scheme + "://" + host + strings.TrimSuffix(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		canonical += "?" + u.RawQuery
+	}
+	return strings.ToLower(canonical)
+}
+
+// SameSourceURL reports whether a and b are the same package source. Matches
+// NuGet.Client's case-insensitive comparison that ignores a trailing slash, and also
+// treats an explicit default port and different spellings of an IPv6 address as equal.
+func SameSourceURL(a, b string) bool {
+	return CanonicalSourceURL(a) == CanonicalSourceURL(b)
+}
+
+// SameOrigin reports whether two URLs have the same scheme, host and port, compared as
+// CanonicalSourceURL does. URLs that are not http or https never match.
+func SameOrigin(a, b string) bool {
+	originA, okA := urlOrigin(a)
+	originB, okB := urlOrigin(b)
+	return okA && okB && originA == originB
+}
+
+// urlOrigin returns the scheme://host[:port] prefix of the canonical form of rawURL
+func urlOrigin(rawURL string) (string, bool) {
+	u, err := url.Parse(CanonicalSourceURL(rawURL))
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	return u.Scheme + "://" + u.Host, true
+}
+
+// IsNuGetOrgURL reports whether rawURL is hosted on nuget.org or one of its subdomains.
+// The host is parsed, so lookalike hosts such as nuget.org.example.com don't match.
+func IsNuGetOrgURL(rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	return host == "nuget.org" || strings.HasSuffix(host, ".nuget.org")
+}
+
+// JoinURLPath appends path segments to baseURL with exactly one slash between them,
+// whether or not baseURL ends with a slash. A query string of baseURL is kept at the end.
+func JoinURLPath(baseURL string, elem ...string) string {
+	base, query, hasQuery := strings.Cut(baseURL, "?")
+	joined := strings.TrimRight(base, "/")
+	for _, e := range elem {
+		joined += "/" + strings.Trim(e, "/")
+	}
+	if hasQuery {
+		joined += "?" + query
+	}
+	return joined
+}
+
+// defaultPort returns the port a URL scheme uses when none is given
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,108 @@
+package http
+
+import "testing"
+
+func TestSameSourceURL(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"identical", "https://feed.example/v3/index.json", "https://feed.example/v3/index.json", true},
+		{"trailing slash", "https://feed.example/nuget/", "https://feed.example/nuget", true},
+		{"case", "HTTPS://Feed.Example/V3/Index.json", "https://feed.example/v3/index.json", true},
+		{"default https port", "https://feed.example:443/v3/index.json", "https://feed.example/v3/index.json", true},
+		{"default http port", "http://feed.example:80/nuget", "http://feed.example/nuget/", true},
+		{"non-default port", "http://feed.example:8081/nuget", "http://feed.example/nuget", false},
+		{"different ports", "http://feed.example:8081/nuget", "http://feed.example:8082/nuget", false},
+		{"IPv6 literal", "http://[::1]:8081/repository/nuget/index.json", "http://[0:0:0:0:0:0:0:1]:8081/repository/nuget/index.json", true},
+		{"IPv6 default port", "https://[::1]:443/index.json", "https://[::1]/index.json/", true},
+		{"IPv6 other port", "http://[::1]:8081/index.json", "http://[::1]:8082/index.json", false},
+		{"different scheme", "http://feed.example/nuget", "https://feed.example/nuget", false},
+		{"different path", "https://feed.example/a/index.json", "https://feed.example/b/index.json", false},
+		{"local path", "/packages/local/", "/packages/local", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameSourceURL(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameSourceURL(%q, %q) = %v, want %v (canonical %q and %q)",
+					tt.a, tt.b, got, tt.want, CanonicalSourceURL(tt.a), CanonicalSourceURL(tt.b))
+			}
+		})
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"https://pkgs.example/v3/index.json", "https://pkgs.example/v3-flatcontainer/a/1.0.0/a.1.0.0.nupkg", true},
+		{"https://PKGS.example:443/v3/index.json", "https://pkgs.example/other", true},
+		{"http://[0:0::1]:8080/index.json", "http://[::1]:8080/flat/", true},
+		{"https://pkgs.example/v3/index.json", "http://pkgs.example/v3/index.json", false},
+		{"https://pkgs.example/v3/index.json", "https://pkgs.example:8443/v3/index.json", false},
+		{"https://pkgs.example/v3/index.json", "https://cdn.pkgs.example/a.nupkg", false},
+		{"https://pkgs.example/v3/index.json", "https://pkgs.example.evil.example/a.nupkg", false},
+		{"/packages", "/packages", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := SameOrigin(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameOrigin(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNuGetOrgURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://api.nuget.org/v3/index.json", true},
+		{"https://www.nuget.org/api/v2/", true},
+		{"https://nuget.org/api/v2", true},
+		{"https://API.NuGet.org:443/v3/index.json", true},
+		{"https://nuget.org.evil.example/v3/index.json", false},
+		{"https://evilnuget.org/api/v2", false},
+		{"https://evil.example/api.nuget.org/v3/index.json", false},
+		{"https://evil.example/?source=nuget.org", false},
+		{"https://nuget.org@evil.example/v3/index.json", false},
+		{"http://[::1]:8081/nuget.org/index.json", false},
+		{"/packages/nuget.org", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := IsNuGetOrgURL(tt.url); got != tt.want {
+				t.Errorf("IsNuGetOrgURL(%q) = %v, want %v", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJoinURLPath(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		elem []string
+		want string
+	}{
+		{"no trailing slash", "https://feed.example/flat", []string{"pkg", "index.json"}, "https://feed.example/flat/pkg/index.json"},
+		{"trailing slash", "https://feed.example/flat/", []string{"pkg", "index.json"}, "https://feed.example/flat/pkg/index.json"},
+		{"doubled slashes", "https://feed.example/flat//", []string{"/pkg/", "index.json"}, "https://feed.example/flat/pkg/index.json"},
+		{"host only", "http://[::1]:8081", []string{"pkg"}, "http://[::1]:8081/pkg"},
+		{"query kept", "https://feed.example/flat/?sig=abc", []string{"pkg", "index.json"}, "https://feed.example/flat/pkg/index.json?sig=abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JoinURLPath(tt.base, tt.elem...); got != tt.want {
+				t.Errorf("JoinURLPath(%q, %q) = %q, want %q", tt.base, tt.elem, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,414 @@
+// Package apicheck records the exported API of Go packages so that changes to it are
+// made on purpose.
+//
+// The API of a package is a sorted list of features, one line each, in the spirit of the
+// api files of the Go distribution:
+//
+//	func Parse(string) (*NuGetVersion, error)
+//	method (*NuGetVersion) String() string
+//	type NuGetVersion struct
+//	type NuGetVersion struct, Major int
+//	type Comparer interface, Compare(*NuGetVersion, *NuGetVersion) int
+//	const StyleSDK Style
+//	func Parse //deprecated
+//
+// Parameter names are left out, as renaming a parameter doesn't break callers.
+// A committed Baseline holds the features of a package and those removed or changed
+// since the last release, which must be announced in the changelog.
+package apicheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// deprecatedSuffix marks the feature line of a deprecated declaration
+const deprecatedSuffix = " //deprecated"
+
+// API is the exported API of a package.
+type API struct {
+	// Name is the package name, which qualifies its symbols in the changelog
+	Name string
+
+	// Features are the feature lines of the package, sorted
+	Features []string
+}
+
+// listedPackage is the part of `go list -json` output Load uses
+type listedPackage struct {
+	ImportPath string
+	Name       string
+	Dir        string
+	GoFiles    []string
+	Export     string
+	Error      *struct{ Err string }
+}
+
+// Load returns the exported API of the packages with the given import paths, keyed by
+// import path. The packages are type-checked from source within the module containing
+// dir; their dependencies are imported from the export data of go list -export.
+func Load(dir string, paths ...string) (map[string]*API, error) {
+	args := append([]string{"list", "-export", "-deps", "-json=ImportPath,Name,Dir,GoFiles,Export,Error"}, paths...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list: %w\n%s", err, stderr.String())
+	}
+
+	listed := make(map[string]*listedPackage)
+	decoder := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var pkg listedPackage
+		if err := decoder.Decode(&pkg); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read go list output: %w", err)
+		}
+		if pkg.Error != nil {
+			return nil, fmt.Errorf("package %s: %s", pkg.ImportPath, pkg.Error.Err)
+		}
+		listed[pkg.ImportPath] = &pkg
+	}
+
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		pkg, ok := listed[path]
+		if !ok || pkg.Export == "" {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(pkg.Export)
+	})
+
+	apis := make(map[string]*API, len(paths))
+	for _, path := range paths {
+		pkg, ok := listed[path]
+		if !ok {
+			return nil, fmt.Errorf("package %s not found", path)
+		}
+		api, err := check(fset, imp, pkg)
+		if err != nil {
+			return nil, err
+		}
+		apis[path] = api
+	}
+	return apis, nil
+}
+
+// check type-checks a listed package from source and returns its API
+func check(fset *token.FileSet, imp types.Importer, pkg *listedPackage) (*API, error) {
+	files := make([]*ast.File, 0, len(pkg.GoFiles))
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	conf := types.Config{Importer: imp}
+	typesPkg, err := conf.Check(pkg.ImportPath, fset, files, info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to type-check %s: %w", pkg.ImportPath, err)
+	}
+	return &API{Name: typesPkg.Name(), Features: Features(typesPkg, Deprecated(files, info))}, nil
+}
+
+// Deprecated returns the objects declared in files whose doc comment has a paragraph
+// starting with "Deprecated:".
+func Deprecated(files []*ast.File, info *types.Info) map[types.Object]bool {
+	deprecated := make(map[types.Object]bool)
+	mark := func(doc *ast.CommentGroup, names ...*ast.Ident) {
+		if !isDeprecated(doc) {
+			return
+		}
+		for _, name := range names {
+			if obj := info.Defs[name]; obj != nil {
+				deprecated[obj] = true
+			}
+		}
+	}
+	markFields := func(fields *ast.FieldList) {
+		for _, field := range fields.List {
+			mark(field.Doc, field.Names...)
+		}
+	}
+
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				mark(decl.Doc, decl.Name)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					// A declaration without parentheses documents its only spec
+					doc := decl.Doc
+					if decl.Lparen.IsValid() {
+						doc = nil
+					}
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if spec.Doc != nil {
+							doc = spec.Doc
+						}
+						mark(doc, spec.Name)
+						switch t := spec.Type.(type) {
+						case *ast.StructType:
+							markFields(t.Fields)
+						case *ast.InterfaceType:
+							markFields(t.Methods)
+						}
+					case *ast.ValueSpec:
+						if spec.Doc != nil {
+							doc = spec.Doc
+						}
+						mark(doc, spec.Names...)
+					}
+				}
+			}
+		}
+	}
+	return deprecated
+}
+
+// isDeprecated reports whether a doc comment has a "Deprecated:" paragraph
+func isDeprecated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for paragraph := range strings.SplitSeq(doc.Text(), "\n\n") {
+		if strings.HasPrefix(strings.TrimSpace(paragraph), "Deprecated:") {
+			return true
+		}
+	}
+	return false
+}
+
+// Features returns the feature lines of the exported API of pkg, sorted. Objects in
+// deprecated get an additional line ending in //deprecated.
+func Features(pkg *types.Package, deprecated map[types.Object]bool) []string {
+	w := &featureWriter{pkg: pkg, deprecated: deprecated}
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Const:
+			w.add(obj, "const %s %s", obj.Name(), w.typeString(obj.Type()))
+		case *types.Var:
+			w.add(obj, "var %s %s", obj.Name(), w.typeString(obj.Type()))
+		case *types.Func:
+			sig := obj.Type().(*types.Signature)
+			w.add(obj, "func %s%s%s", obj.Name(), w.typeParams(sig.TypeParams()), w.signature(sig))
+		case *types.TypeName:
+			w.typeFeatures(obj)
+		}
+	}
+	slices.Sort(w.features)
+	return slices.Compact(w.features)
+}
+
+// featureWriter collects the feature lines of a package
+type featureWriter struct {
+	pkg        *types.Package
+	deprecated map[types.Object]bool
+	features   []string
+}
+
+// add adds a feature line for obj, and its deprecation line when obj is deprecated
+func (w *featureWriter) add(obj types.Object, format string, args ...any) {
+	feature := fmt.Sprintf(format, args...)
+	w.features = append(w.features, feature)
+	if w.deprecated[obj] {
+		w.features = append(w.features, deprecatedName(feature)+deprecatedSuffix)
+	}
+}
+
+// typeFeatures adds the features of an exported type: its kind, exported fields or
+// interface methods, and exported methods
+func (w *featureWriter) typeFeatures(obj *types.TypeName) {
+	name := obj.Name()
+	if obj.IsAlias() {
+		w.add(obj, "type %s = %s", name, w.typeString(types.Unalias(obj.Type())))
+		return
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return
+	}
+	decl := "type " + name + w.typeParams(named.TypeParams())
+
+	switch underlying := named.Underlying().(type) {
+	case *types.Struct:
+		w.add(obj, "%s struct", decl)
+		for field := range underlying.Fields() {
+			switch {
+			case field.Embedded() && field.Exported():
+				w.add(field, "%s struct, embedded %s", decl, w.typeString(field.Type()))
+			case field.Exported():
+				w.add(field, "%s struct, %s %s", decl, field.Name(), w.typeString(field.Type()))
+			}
+		}
+	case *types.Interface:
+		if !underlying.IsMethodSet() {
+			w.add(obj, "%s %s", decl, w.typeString(underlying))
+			break
+		}
+		w.add(obj, "%s interface", decl)
+		sealed := false
+		for method := range underlying.Methods() {
+			if !method.Exported() {
+				sealed = true
+				continue
+			}
+			w.add(method, "%s interface, %s%s", decl, method.Name(), w.signature(method.Type().(*types.Signature)))
+		}
+		if sealed {
+			w.features = append(w.features, decl+" interface, unexported methods")
+		}
+	default:
+		w.add(obj, "%s %s", decl, w.typeString(underlying))
+	}
+
+	for method := range named.Methods() {
+		if !method.Exported() {
+			continue
+		}
+		sig := method.Type().(*types.Signature)
+		recv := name
+		if tparams := named.TypeParams(); tparams.Len() > 0 {
+			names := make([]string, tparams.Len())
+			for i := range names {
+				names[i] = tparams.At(i).Obj().Name()
+			}
+			recv += "[" + strings.Join(names, ", ") + "]"
+		}
+		if _, ok := sig.Recv().Type().(*types.Pointer); ok {
+			recv = "*" + recv
+		}
+		w.add(method, "method (%s) %s%s", recv, method.Name(), w.signature(sig))
+	}
+}
+
+// qualifier writes types of pkg unqualified and others by package name
+func (w *featureWriter) qualifier(p *types.Package) string {
+	if p == w.pkg {
+		return ""
+	}
+	return p.Name()
+}
+
+// typeString writes t with the parameter names of function types left out
+func (w *featureWriter) typeString(t types.Type) string {
+	switch t := t.(type) {
+	case *types.Pointer:
+		return "*" + w.typeString(t.Elem())
+	case *types.Slice:
+		return "[]" + w.typeString(t.Elem())
+	case *types.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), w.typeString(t.Elem()))
+	case *types.Map:
+		return "map[" + w.typeString(t.Key()) + "]" + w.typeString(t.Elem())
+	case *types.Chan:
+		prefix := "chan "
+		switch t.Dir() {
+		case types.SendOnly:
+			prefix = "chan<- "
+		case types.RecvOnly:
+			prefix = "<-chan "
+		}
+		return prefix + w.typeString(t.Elem())
+	case *types.Signature:
+		return "func" + w.signature(t)
+	}
+	return types.TypeString(t, w.qualifier)
+}
+
+// typeParams writes a type parameter list, or nothing for a non-generic declaration
+func (w *featureWriter) typeParams(tparams *types.TypeParamList) string {
+	if tparams.Len() == 0 {
+		return ""
+	}
+	params := make([]string, tparams.Len())
+	for i := range params {
+		param := tparams.At(i)
+		params[i] = param.Obj().Name() + " " + w.typeString(param.Constraint())
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// signature writes the parameters and results of sig without their names
+func (w *featureWriter) signature(sig *types.Signature) string {
+	params := make([]string, sig.Params().Len())
+	for i := range params {
+		t := sig.Params().At(i).Type()
+		if sig.Variadic() && i == len(params)-1 {
+			params[i] = "..." + w.typeString(t.(*types.Slice).Elem())
+		} else {
+			params[i] = w.typeString(t)
+		}
+	}
+	s := "(" + strings.Join(params, ", ") + ")"
+
+	results := make([]string, sig.Results().Len())
+	for i := range results {
+		results[i] = w.typeString(sig.Results().At(i).Type())
+	}
+	switch len(results) {
+	case 0:
+		return s
+	case 1:
+		return s + " " + results[0]
+	default:
+		return s + " (" + strings.Join(results, ", ") + ")"
+	}
+}
+
+// deprecatedName shortens a feature line to the declaration it describes, such as
+// "func Parse" or "type NuGetVersion struct, Major", for its deprecation line
+func deprecatedName(feature string) string {
+	kind, rest, _ := strings.Cut(feature, " ")
+	switch kind {
+	case "func":
+		return kind + " " + declName(rest)
+	case "method":
+		recv, rest, _ := strings.Cut(rest, ") ")
+		return kind + " " + recv + ") " + declName(rest)
+	case "type":
+		if decl, member, ok := strings.Cut(rest, ", "); ok {
+			if strings.HasPrefix(member, "embedded ") {
+				return feature
+			}
+			return "type " + decl + ", " + declName(member)
+		}
+		return "type " + declName(rest)
+	default: // const, var
+		return kind + " " + declName(rest)
+	}
+}
+
+// declName returns the identifier at the start of s
+func declName(s string) string {
+	if i := strings.IndexAny(s, " ([="); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
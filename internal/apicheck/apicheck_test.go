@@ -0,0 +1,119 @@
+package apicheck
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testSource = `package pkg
+
+import "io"
+
+const Answer = 42
+
+const Mode Style = 1
+
+var ErrClosed error
+
+// Style is a style.
+type Style int
+
+func (Style) String() string { return "" }
+
+type Options struct {
+	io.Reader
+	Name    string
+	Handler func(name string, n int) (bool, error)
+	hidden  int
+
+	// Deprecated: Use Name.
+	Title string
+}
+
+func (o *Options) Apply(values ...string) {}
+
+func (o *Options) reset() {}
+
+type Sealed interface {
+	Read(p []byte) (n int, err error)
+	sealed()
+}
+
+type Number interface{ ~int | ~float64 }
+
+type List[T any] struct{ Items []T }
+
+func (l *List[T]) Add(item T) {}
+
+type Alias = Options
+
+func Map[K comparable, V any](m map[K]V, f func(V) V) map[K]V { return m }
+
+// Open opens a file.
+//
+// Deprecated: Use OpenFile.
+func Open(name string) (io.ReadCloser, error) { return nil, nil }
+
+func unexported() {}
+`
+
+func TestFeatures(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "pkg.go", testSource, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check("example.com/pkg", fset, []*ast.File{file}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"const Answer untyped int",
+		"const Mode Style",
+		"func Map[K comparable, V any](map[K]V, func(V) V) map[K]V",
+		"func Open //deprecated",
+		"func Open(string) (io.ReadCloser, error)",
+		"method (*List[T]) Add(T)",
+		"method (*Options) Apply(...string)",
+		"method (Style) String() string",
+		"type Alias = Options",
+		"type List[T any] struct",
+		"type List[T any] struct, Items []T",
+		"type Number interface{~int | ~float64}",
+		"type Options struct",
+		"type Options struct, Handler func(string, int) (bool, error)",
+		"type Options struct, Name string",
+		"type Options struct, Title //deprecated",
+		"type Options struct, Title string",
+		"type Options struct, embedded io.Reader",
+		"type Sealed interface",
+		"type Sealed interface, Read([]byte) (int, error)",
+		"type Sealed interface, unexported methods",
+		"type Style int",
+		"var ErrClosed error",
+	}
+	got := Features(pkg, Deprecated([]*ast.File{file}, info))
+	if !slices.Equal(got, want) {
+		t.Errorf("Features() =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+}
+
+func TestLoad(t *testing.T) {
+	apis, err := Load(".", "github.com/willibrandon/gonuget/internal/pathnorm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := apis["github.com/willibrandon/gonuget/internal/pathnorm"]
+	want := []string{"func SeparatorOnlyDifference(string, string) bool", "func TrimSeparators(string) string"}
+	if api == nil || api.Name != "pathnorm" || !slices.Equal(api.Features, want) {
+		t.Errorf("Load() = %+v, want pathnorm with %v", api, want)
+	}
+}
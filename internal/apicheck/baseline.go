@@ -0,0 +1,136 @@
+package apicheck
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// removedPrefix starts the lines of a baseline that record a removed or changed feature
+const removedPrefix = "removed: "
+
+// Baseline is the committed API of a package.
+type Baseline struct {
+	// Features are the features of the package, sorted
+	Features []string
+
+	// Removed are the features removed or changed since the last release, sorted. They
+	// are kept until the release so that the changelog can be checked to announce them.
+	Removed []string
+}
+
+// ParseBaseline parses a baseline file. Blank lines and lines starting with # are ignored.
+func ParseBaseline(data []byte) *Baseline {
+	b := &Baseline{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, removedPrefix):
+			b.Removed = append(b.Removed, strings.TrimPrefix(line, removedPrefix))
+		default:
+			b.Features = append(b.Features, line)
+		}
+	}
+	slices.Sort(b.Features)
+	slices.Sort(b.Removed)
+	return b
+}
+
+// Format writes the baseline file, starting with header as # comment lines.
+func (b *Baseline) Format(header string) []byte {
+	var buf bytes.Buffer
+	for line := range strings.Lines(header) {
+		buf.WriteString(strings.TrimSpace("# " + line))
+		buf.WriteByte('\n')
+	}
+	for _, feature := range b.Features {
+		buf.WriteString(feature + "\n")
+	}
+	for _, feature := range b.Removed {
+		buf.WriteString(removedPrefix + feature + "\n")
+	}
+	return buf.Bytes()
+}
+
+// Update returns the baseline for the current features of a package. Features of b that
+// are gone are recorded as removed, and removed features that are back are dropped.
+func (b *Baseline) Update(features []string) *Baseline {
+	_, removed := Diff(b.Features, features)
+	updated := &Baseline{Features: slices.Clone(features)}
+	for _, feature := range append(slices.Clone(b.Removed), removed...) {
+		if !slices.Contains(features, feature) {
+			updated.Removed = append(updated.Removed, feature)
+		}
+	}
+	slices.Sort(updated.Features)
+	slices.Sort(updated.Removed)
+	updated.Removed = slices.Compact(updated.Removed)
+	return updated
+}
+
+// Diff returns the features that are in features but not in baseline, and those in
+// baseline but not in features. Both lists must be sorted.
+func Diff(baseline, features []string) (added, removed []string) {
+	i, j := 0, 0
+	for i < len(baseline) || j < len(features) {
+		switch {
+		case j == len(features) || (i < len(baseline) && baseline[i] < features[j]):
+			removed = append(removed, baseline[i])
+			i++
+		case i == len(baseline) || features[j] < baseline[i]:
+			added = append(added, features[j])
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return added, removed
+}
+
+// Symbol returns the qualified name of the declaration a feature line describes, such as
+// "version.Parse" or "version.NuGetVersion.String" for package pkgName.
+func Symbol(pkgName, feature string) string {
+	feature = strings.TrimSuffix(feature, deprecatedSuffix)
+	kind, rest, _ := strings.Cut(feature, " ")
+	var name string
+	switch kind {
+	case "method":
+		recv, method, _ := strings.Cut(rest, ") ")
+		recv = strings.TrimLeft(strings.TrimPrefix(recv, "("), "*")
+		name = declName(recv) + "." + declName(method)
+	case "type":
+		decl, member, ok := strings.Cut(rest, ", ")
+		name = declName(decl)
+		if member, embedded := strings.CutPrefix(member, "embedded "); embedded {
+			// An embedded field is named after its type, without package or pointer
+			member = strings.TrimLeft(member, "*")
+			if i := strings.LastIndex(declName(member), "."); i >= 0 {
+				member = member[i+1:]
+			}
+			name += "." + declName(member)
+		} else if ok && member != "unexported methods" {
+			name += "." + declName(member)
+		}
+	default: // const, func, var
+		name = declName(rest)
+	}
+	return pkgName + "." + name
+}
+
+// Unannounced returns the removed features of package pkgName whose symbol changelog
+// doesn't mention.
+func Unannounced(pkgName string, removed []string, changelog []byte) []string {
+	var missing []string
+	for _, feature := range removed {
+		symbol := regexp.QuoteMeta(Symbol(pkgName, feature))
+		if !regexp.MustCompile(`(^|[^\w.])` + symbol + `\b`).Match(changelog) {
+			missing = append(missing, feature)
+		}
+	}
+	return missing
+}
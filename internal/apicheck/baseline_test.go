@@ -0,0 +1,91 @@
+package apicheck
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBaseline_FormatAndParse(t *testing.T) {
+	b := &Baseline{
+		Features: []string{"func A()", "func B() int"},
+		Removed:  []string{"func C()"},
+	}
+	data := b.Format("API of example.com/pkg\nRegenerate with: go test ./api -update")
+	want := "# API of example.com/pkg\n# Regenerate with: go test ./api -update\nfunc A()\nfunc B() int\nremoved: func C()\n"
+	if string(data) != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", data, want)
+	}
+
+	parsed := ParseBaseline(data)
+	if !slices.Equal(parsed.Features, b.Features) || !slices.Equal(parsed.Removed, b.Removed) {
+		t.Errorf("ParseBaseline() = %+v, want %+v", parsed, b)
+	}
+}
+
+func TestBaseline_Update(t *testing.T) {
+	b := &Baseline{
+		Features: []string{"func A()", "func B() int", "func D()"},
+		Removed:  []string{"func C()", "func E()"},
+	}
+
+	// B changes its signature, D is removed and C is back
+	updated := b.Update([]string{"func A()", "func B() string", "func C()"})
+
+	if want := []string{"func A()", "func B() string", "func C()"}; !slices.Equal(updated.Features, want) {
+		t.Errorf("Features = %v, want %v", updated.Features, want)
+	}
+	if want := []string{"func B() int", "func D()", "func E()"}; !slices.Equal(updated.Removed, want) {
+		t.Errorf("Removed = %v, want %v", updated.Removed, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	added, removed := Diff([]string{"a", "b", "d"}, []string{"b", "c", "d", "e"})
+	if !slices.Equal(added, []string{"c", "e"}) || !slices.Equal(removed, []string{"a"}) {
+		t.Errorf("Diff() = %v, %v, want [c e], [a]", added, removed)
+	}
+}
+
+func TestSymbol(t *testing.T) {
+	tests := []struct {
+		feature string
+		want    string
+	}{
+		{"func Parse(string) (*NuGetVersion, error)", "version.Parse"},
+		{"func Map[K comparable, V any](map[K]V) map[K]V", "version.Map"},
+		{"func Parse //deprecated", "version.Parse"},
+		{"method (*NuGetVersion) String() string", "version.NuGetVersion.String"},
+		{"method (*List[T]) Add(T)", "version.List.Add"},
+		{"method (FloatBehavior) String() string", "version.FloatBehavior.String"},
+		{"type NuGetVersion struct", "version.NuGetVersion"},
+		{"type NuGetVersion struct, Major int", "version.NuGetVersion.Major"},
+		{"type Options struct, embedded *resolver.WalkerCache", "version.Options.WalkerCache"},
+		{"type Comparer interface, Compare(*NuGetVersion) int", "version.Comparer.Compare"},
+		{"type Sealed interface, unexported methods", "version.Sealed"},
+		{"type List[T any] struct", "version.List"},
+		{"type Alias = Options", "version.Alias"},
+		{"const FloatMajor FloatBehavior", "version.FloatMajor"},
+		{"var ErrClosed error", "version.ErrClosed"},
+	}
+
+	for _, tt := range tests {
+		if got := Symbol("version", tt.feature); got != tt.want {
+			t.Errorf("Symbol(%q) = %q, want %q", tt.feature, got, tt.want)
+		}
+	}
+}
+
+func TestUnannounced(t *testing.T) {
+	changelog := []byte("## [Unreleased]\n\n### Breaking\n- `version.Parse` returns a typed error\n- removed version.NuGetVersion.Equals.\n")
+	removed := []string{
+		"func Parse(string) (*NuGetVersion, error)",
+		"method (*NuGetVersion) Equals(*NuGetVersion) bool",
+		"func ParseRange(string) (*Range, error)",     // version.Parse is only a prefix
+		"method (*NuGetVersion) Equal(*NuGetVersion)", // as is version.NuGetVersion.Equals
+	}
+
+	got := Unannounced("version", removed, changelog)
+	if want := removed[2:]; !slices.Equal(got, want) {
+		t.Errorf("Unannounced() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,240 @@
+// Package interopbridge runs the requests of the interop test bridges, the programs the
+// C# interop tests start to call gonuget: each request is handled under a timeout and a
+// response size budget, and answered in the JSON format the tests read.
+package interopbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// Request represents an incoming test request from C# tests.
+// Action specifies which operation to perform.
+// Data contains action-specific parameters in JSON format.
+// TimeoutMs bounds the handler (120s by default) and MaxResponseBytes the size of the
+// response data (8 MiB by default); larger lists are truncated to fit.
+type Request struct {
+	Action           string          `json:"action"`
+	Data             json.RawMessage `json:"data"`
+	TimeoutMs        int             `json:"timeoutMs,omitempty"`
+	MaxResponseBytes int             `json:"maxResponseBytes,omitempty"`
+}
+
+// Response represents the standard response format sent back to C#.
+// Success indicates whether the operation completed without errors.
+// Data contains action-specific results (only present on success).
+// Error contains detailed error information (only present on failure).
+// Truncated is set when lists of Data were cut to fit the size budget; Truncations says which.
+// Timing reports the handler's wall time.
+type Response struct {
+	Success     bool         `json:"success"`
+	Data        any          `json:"data,omitempty"`
+	Error       *ErrorInfo   `json:"error,omitempty"`
+	Truncated   bool         `json:"truncated,omitempty"`
+	Truncations []Truncation `json:"truncations,omitempty"`
+	Timing      Timing       `json:"timing"`
+}
+
+// ErrorInfo contains structured error information for debugging.
+// Code is a machine-readable error code (e.g., "SIGN_001").
+// Message is a human-readable error description.
+// Details contains additional context (e.g., file paths, stderr output).
+type ErrorInfo struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+}
+
+// Handler interface for all request handlers.
+// Handle processes the request data and returns a result or error; ctx carries the
+// request timeout and must be passed to anything that blocks.
+// ErrorCode returns the error code prefix for this handler.
+type Handler interface {
+	Handle(ctx context.Context, data json.RawMessage) (any, error)
+	ErrorCode() string
+}
+
+const (
+	// defaultTimeout bounds a request that doesn't set timeoutMs.
+	defaultTimeout = 120 * time.Second
+
+	// defaultMaxResponseBytes bounds the data of a response when the request doesn't set
+	// maxResponseBytes.
+	defaultMaxResponseBytes = 8 << 20
+)
+
+// Timing reports how long the handler ran, so slowness can be attributed to a request.
+type Timing struct {
+	HandlerMs int64 `json:"handlerMs"`
+	TimeoutMs int64 `json:"timeoutMs"`
+}
+
+// Truncation records a list of the response data that was cut to fit the size budget.
+// Path locates the list in the response, e.g. "data.packages[2].dependencies".
+type Truncation struct {
+	Path  string `json:"path"`
+	Total int    `json:"total"` // Items the handler returned
+	Kept  int    `json:"kept"`  // Items left in the response
+}
+
+// Dispatch runs the handler under the timeout and the response size budget of the request
+// and returns the response to send. A handler still running at the deadline is abandoned
+// (the process exits once the response is written), so a hung network call fails the
+// request instead of the whole test run.
+func Dispatch(ctx context.Context, handler Handler, req Request) Response {
+	timeout := defaultTimeout
+	if req.TimeoutMs > 0 {
+		timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		data any
+		err  error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		data, err := handler.Handle(ctx, req.Data)
+		done <- outcome{data: data, err: err}
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = ctx.Err()
+	}
+	timing := Timing{HandlerMs: time.Since(start).Milliseconds(), TimeoutMs: timeout.Milliseconds()}
+
+	if result.err != nil {
+		if errors.Is(result.err, context.DeadlineExceeded) && ctx.Err() != nil {
+			message := fmt.Sprintf("%s exceeded the request timeout of %s", req.Action, timeout)
+			return ErrorResponse("TIMEOUT_001", message, result.err.Error(), timing)
+		}
+		return ErrorResponse(handler.ErrorCode(), result.err.Error(), "", timing)
+	}
+
+	budget := defaultMaxResponseBytes
+	if req.MaxResponseBytes > 0 {
+		budget = req.MaxResponseBytes
+	}
+	data, truncations, err := fitResponse(result.data, budget)
+	if err != nil {
+		return ErrorResponse("RESP_001", err.Error(), "", timing)
+	}
+	return Response{
+		Success:     true,
+		Data:        data,
+		Truncated:   len(truncations) > 0,
+		Truncations: truncations,
+		Timing:      timing,
+	}
+}
+
+// ErrorResponse builds a failed response.
+func ErrorResponse(code, message, details string, timing Timing) Response {
+	return Response{
+		Success: false,
+		Error:   &ErrorInfo{Code: code, Message: message, Details: details},
+		Timing:  timing,
+	}
+}
+
+// fitResponse returns data unchanged when its JSON fits in budget bytes. Otherwise the
+// largest list of the data is halved, keeping its first items, until it fits; the lists
+// that were cut are returned. Data without a list left to cut fails.
+func fitResponse(data any, budget int) (any, []Truncation, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal response: %w", err)
+	}
+	if len(encoded) <= budget {
+		return data, nil, nil
+	}
+
+	// Work on the generic form of the data; json.Number keeps numbers exact
+	var tree any
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	var truncations []Truncation
+	for len(encoded) > budget {
+		var largest *listNode
+		largestSize := 0
+		for _, list := range collectLists(tree, "data", func(v any) { tree = v }) {
+			if len(list.items) == 0 {
+				continue
+			}
+			size := encodedSize(list.items)
+			if largest == nil || size > largestSize {
+				largest, largestSize = &list, size
+			}
+		}
+		if largest == nil {
+			return nil, nil, fmt.Errorf("response of %d bytes exceeds the budget of %d bytes and has no list to truncate", len(encoded), budget)
+		}
+
+		kept := len(largest.items) / 2
+		largest.set(largest.items[:kept])
+		recorded := false
+		for i := range truncations {
+			if truncations[i].Path == largest.path {
+				truncations[i].Kept = kept
+				recorded = true
+			}
+		}
+		if !recorded {
+			truncations = append(truncations, Truncation{Path: largest.path, Total: len(largest.items), Kept: kept})
+		}
+
+		if encoded, err = json.Marshal(tree); err != nil {
+			return nil, nil, fmt.Errorf("marshal response: %w", err)
+		}
+	}
+	return tree, truncations, nil
+}
+
+// listNode is a list of the generic response data and how to replace it.
+type listNode struct {
+	path  string
+	items []any
+	set   func([]any)
+}
+
+// collectLists returns the lists of v and of its descendants; set replaces v in its parent.
+func collectLists(v any, path string, set func(any)) []listNode {
+	var lists []listNode
+	switch node := v.(type) {
+	case map[string]any:
+		for _, key := range slices.Sorted(maps.Keys(node)) {
+			child := node[key]
+			lists = append(lists, collectLists(child, path+"."+key, func(v any) { node[key] = v })...)
+		}
+	case []any:
+		lists = append(lists, listNode{path: path, items: node, set: func(items []any) { set(items) }})
+		for i, child := range node {
+			lists = append(lists, collectLists(child, fmt.Sprintf("%s[%d]", path, i), func(v any) { node[i] = v })...)
+		}
+	}
+	return lists
+}
+
+// encodedSize returns the length of the JSON encoding of v.
+func encodedSize(v any) int {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
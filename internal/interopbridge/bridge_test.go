@@ -0,0 +1,145 @@
+package interopbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testHandler runs handle as a bridge handler.
+type testHandler struct {
+	handle func(ctx context.Context, data json.RawMessage) (any, error)
+}
+
+func (h *testHandler) Handle(ctx context.Context, data json.RawMessage) (any, error) {
+	return h.handle(ctx, data)
+}
+
+func (h *testHandler) ErrorCode() string { return "TEST_001" }
+
+func TestDispatch_Timeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle func(ctx context.Context, data json.RawMessage) (any, error)
+	}{
+		{
+			name: "handler ignoring the context",
+			handle: func(ctx context.Context, data json.RawMessage) (any, error) {
+				<-t.Context().Done()
+				return nil, nil
+			},
+		},
+		{
+			name: "handler returning the context error",
+			handle: func(ctx context.Context, data json.RawMessage) (any, error) {
+				<-ctx.Done()
+				return nil, fmt.Errorf("fetch index: %w", ctx.Err())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Action: "slow_action", TimeoutMs: 50}
+			resp := Dispatch(context.Background(), &testHandler{handle: tt.handle}, req)
+
+			if resp.Success {
+				t.Fatal("expected the request to fail")
+			}
+			if resp.Error.Code != "TIMEOUT_001" {
+				t.Errorf("error code = %q, want TIMEOUT_001", resp.Error.Code)
+			}
+			if !strings.Contains(resp.Error.Message, "slow_action exceeded the request timeout of 50ms") {
+				t.Errorf("error message = %q", resp.Error.Message)
+			}
+			if resp.Timing.TimeoutMs != 50 || resp.Timing.HandlerMs < 50 {
+				t.Errorf("timing = %+v, want the 50ms timeout to have elapsed", resp.Timing)
+			}
+		})
+	}
+}
+
+func TestDispatch_HandlerError(t *testing.T) {
+	handler := &testHandler{handle: func(ctx context.Context, data json.RawMessage) (any, error) {
+		return nil, fmt.Errorf("parse request: bad input")
+	}}
+	resp := Dispatch(context.Background(), handler, Request{Action: "failing_action"})
+
+	if resp.Success || resp.Error.Code != "TEST_001" || resp.Error.Message != "parse request: bad input" {
+		t.Errorf("response = %+v, want the handler's error", resp)
+	}
+	if resp.Timing.TimeoutMs != defaultTimeout.Milliseconds() {
+		t.Errorf("timeout = %dms, want the default of %dms", resp.Timing.TimeoutMs, defaultTimeout.Milliseconds())
+	}
+}
+
+func TestDispatch_Truncation(t *testing.T) {
+	type largeResponse struct {
+		Source   string   `json:"source"`
+		Count    int      `json:"count"`
+		Packages []string `json:"packages"`
+	}
+	handler := &testHandler{handle: func(ctx context.Context, data json.RawMessage) (any, error) {
+		resp := largeResponse{Source: "https://example.org/v3/index.json", Count: 10000}
+		for i := range resp.Count {
+			resp.Packages = append(resp.Packages, fmt.Sprintf("Package.%05d", i))
+		}
+		return resp, nil
+	}}
+
+	resp := Dispatch(context.Background(), handler, Request{Action: "large_action", MaxResponseBytes: 1000})
+	if !resp.Success {
+		t.Fatalf("expected success, got %+v", resp.Error)
+	}
+	if !resp.Truncated || len(resp.Truncations) != 1 {
+		t.Fatalf("truncated = %v, truncations = %+v, want one truncation", resp.Truncated, resp.Truncations)
+	}
+	truncation := resp.Truncations[0]
+	if truncation.Path != "data.packages" || truncation.Total != 10000 || truncation.Kept <= 0 || truncation.Kept >= 10000 {
+		t.Errorf("truncation = %+v", truncation)
+	}
+
+	encoded, err := json.Marshal(resp.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(encoded) > 1000 {
+		t.Errorf("data is %d bytes, over the budget of 1000", len(encoded))
+	}
+
+	// The kept items are the first ones, and the other fields are untouched
+	var data largeResponse
+	if err := json.Unmarshal(encoded, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Source != "https://example.org/v3/index.json" || data.Count != 10000 {
+		t.Errorf("data = %+v, want the scalar fields unchanged", data)
+	}
+	if len(data.Packages) != truncation.Kept || data.Packages[0] != "Package.00000" {
+		t.Errorf("kept %d packages starting with %q", len(data.Packages), data.Packages[0])
+	}
+}
+
+func TestDispatch_NoTruncationWithinBudget(t *testing.T) {
+	handler := &testHandler{handle: func(ctx context.Context, data json.RawMessage) (any, error) {
+		return map[string]any{"versions": []string{"1.0.0", "2.0.0"}}, nil
+	}}
+	resp := Dispatch(context.Background(), handler, Request{Action: "small_action"})
+
+	if !resp.Success || resp.Truncated || resp.Truncations != nil {
+		t.Errorf("response = %+v, want the data unchanged", resp)
+	}
+}
+
+func TestDispatch_NothingToTruncate(t *testing.T) {
+	handler := &testHandler{handle: func(ctx context.Context, data json.RawMessage) (any, error) {
+		return map[string]string{"output": strings.Repeat("x", 2000)}, nil
+	}}
+	resp := Dispatch(context.Background(), handler, Request{Action: "large_action", MaxResponseBytes: 1000})
+
+	if resp.Success || resp.Error.Code != "RESP_001" {
+		t.Errorf("response = %+v, want RESP_001", resp)
+	}
+}
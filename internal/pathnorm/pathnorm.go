@@ -0,0 +1,17 @@
+// Package pathnorm compares paths that dotnet writes with either separator, such as
+// the folders of project.assets.json written on Windows and on Unix.
+package pathnorm
+
+import "strings"
+
+// SeparatorOnlyDifference reports whether two paths differ only in their separators or
+// a trailing separator, such as "C:\packages\" and "C:/packages".
+func SeparatorOnlyDifference(a, b string) bool {
+	return a != b && TrimSeparators(a) == TrimSeparators(b)
+}
+
+// TrimSeparators converts p to forward slashes without a trailing separator, so that
+// paths differing only in their separators compare equal.
+func TrimSeparators(p string) string {
+	return strings.TrimRight(strings.ReplaceAll(p, `\`, "/"), "/")
+}
@@ -0,0 +1,22 @@
+package pathnorm
+
+import "testing"
+
+func TestSeparatorOnlyDifference(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{`C:\packages\`, "C:/packages", true},
+		{"/home/u/.nuget/packages/", "/home/u/.nuget/packages", true},
+		{`lib\net8.0\A.dll`, "lib/net8.0/A.dll", true},
+		{"lib/net8.0/A.dll", "lib/net8.0/A.dll", false},
+		{"lib/net8.0/A.dll", "lib/net8.0/B.dll", false},
+	}
+
+	for _, tt := range tests {
+		if got := SeparatorOnlyDifference(tt.a, tt.b); got != tt.want {
+			t.Errorf("SeparatorOnlyDifference(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"os"
+	"sync/atomic"
 
 	"github.com/willibrandon/mtlog"
 	"github.com/willibrandon/mtlog/core"
@@ -177,6 +178,30 @@ const (
 	FatalLevel
 )
 
+// defaultLogger is the logger installed with SetDefaultLogger (nil when unset).
+var defaultLogger atomic.Pointer[Logger]
+
+// SetDefaultLogger installs the logger used by components created afterwards without
+// one of their own: repositories, HTTP clients and disk caches. It is how the CLI
+// applies --verbosity to every component without threading a logger through every
+// layer. Pass nil to discard their output again.
+func SetDefaultLogger(logger Logger) {
+	if logger == nil {
+		defaultLogger.Store(nil)
+		return
+	}
+	defaultLogger.Store(&logger)
+}
+
+// DefaultLogger returns the logger installed with SetDefaultLogger, or a logger that
+// discards all output.
+func DefaultLogger() Logger {
+	if logger := defaultLogger.Load(); logger != nil {
+		return *logger
+	}
+	return NewNullLogger()
+}
+
 // NullLogger is a logger that discards all output
 type nullLogger struct{}
 
@@ -0,0 +1,184 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Verbosity is the output verbosity selected with -v|--verbosity, using the MSBuild
+// levels dotnet accepts. Levels are ordered, so output gates compare with >=.
+type Verbosity int
+
+const (
+	// VerbosityQuiet shows errors only
+	VerbosityQuiet Verbosity = iota - 1
+	// VerbosityMinimal shows errors, warnings and results. It is the zero value, as it
+	// is the default verbosity of dotnet restore.
+	VerbosityMinimal
+	// VerbosityNormal also shows key operations
+	VerbosityNormal
+	// VerbosityDetailed also shows progress details and component traces
+	VerbosityDetailed
+	// VerbosityDiagnostic also shows HTTP requests, cache hits, timing and resolution traces
+	VerbosityDiagnostic
+)
+
+// ErrInvalidVerbosity is returned by ParseVerbosity for a value that names no level.
+var ErrInvalidVerbosity = errors.New("Verbosity level is not valid.") //nolint:staticcheck // MSBuild's message (MSB1018)
+
+// verbosityNames lists the levels with their short forms, in order.
+var verbosityNames = []struct {
+	name  string
+	short string
+}{
+	{"quiet", "q"},
+	{"minimal", "m"},
+	{"normal", "n"},
+	{"detailed", "d"},
+	{"diagnostic", "diag"},
+}
+
+// ParseVerbosity parses a verbosity level: q[uiet], m[inimal], n[ormal], d[etailed] or
+// diag[nostic], case-insensitively. Besides the short forms, any prefix of a level name
+// is accepted; once "d" is taken by detailed, every prefix names a single level.
+func ParseVerbosity(value string) (Verbosity, error) {
+	lower := strings.ToLower(strings.TrimSpace(value))
+	if lower != "" {
+		for i, level := range verbosityNames {
+			if lower == level.short {
+				return VerbosityQuiet + Verbosity(i), nil
+			}
+		}
+		for i, level := range verbosityNames {
+			if strings.HasPrefix(level.name, lower) {
+				return VerbosityQuiet + Verbosity(i), nil
+			}
+		}
+	}
+	return VerbosityMinimal, fmt.Errorf("%w\nSwitch: %s", ErrInvalidVerbosity, value)
+}
+
+// String returns the name of the level, such as "detailed".
+func (v Verbosity) String() string {
+	if i := int(v - VerbosityQuiet); i >= 0 && i < len(verbosityNames) {
+		return verbosityNames[i].name
+	}
+	return fmt.Sprintf("Verbosity(%d)", int(v))
+}
+
+// NewVerbosityLogger creates a logger that writes component log events (providers,
+// HTTP client, caches) to w as plain lines at the given verbosity. Components log
+// traces for troubleshooting, while commands report the warnings and errors users act
+// on, so nothing is written below detailed; detailed shows Debug events and above, and
+// diagnostic shows Verbose events as well.
+func NewVerbosityLogger(w io.Writer, verbosity Verbosity) Logger {
+	switch {
+	case verbosity >= VerbosityDiagnostic:
+		return &textLogger{w: w, level: VerboseLevel, mu: &sync.Mutex{}}
+	case verbosity >= VerbosityDetailed:
+		return &textLogger{w: w, level: DebugLevel, mu: &sync.Mutex{}}
+	default:
+		return NewNullLogger()
+	}
+}
+
+// textLogger renders message templates as plain text lines.
+type textLogger struct {
+	w     io.Writer
+	level LogLevel
+	mu    *sync.Mutex // Shared with child loggers so lines are never interleaved
+}
+
+// write renders and writes an event at or above the logger's level.
+func (l *textLogger) write(level LogLevel, messageTemplate string, args []any) {
+	if level < l.level {
+		return
+	}
+
+	line := renderTemplate(messageTemplate, args)
+	switch level {
+	case WarnLevel:
+		line = "warn : " + line
+	case ErrorLevel, FatalLevel:
+		line = "error: " + line
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, _ = io.WriteString(l.w, line+"\n")
+}
+
+// renderTemplate replaces the {Property} holes of a message template with args in order.
+// Format specifiers ({Duration:0.0}) and capturing hints ({@Package}) are ignored, and
+// "{{" and "}}" are literal braces.
+func renderTemplate(messageTemplate string, args []any) string {
+	var b strings.Builder
+	next := 0
+	for i := 0; i < len(messageTemplate); i++ {
+		c := messageTemplate[i]
+		switch {
+		case c == '{' && strings.HasPrefix(messageTemplate[i:], "{{"), c == '}' && strings.HasPrefix(messageTemplate[i:], "}}"):
+			b.WriteByte(c)
+			i++
+		case c == '{':
+			end := strings.IndexByte(messageTemplate[i:], '}')
+			if end < 0 || next >= len(args) {
+				b.WriteString(messageTemplate[i:])
+				return b.String()
+			}
+			_, _ = fmt.Fprint(&b, args[next])
+			next++
+			i += end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func (l *textLogger) Verbose(messageTemplate string, args ...any) {
+	l.write(VerboseLevel, messageTemplate, args)
+}
+func (l *textLogger) VerboseContext(_ context.Context, messageTemplate string, args ...any) {
+	l.write(VerboseLevel, messageTemplate, args)
+}
+func (l *textLogger) Debug(messageTemplate string, args ...any) {
+	l.write(DebugLevel, messageTemplate, args)
+}
+func (l *textLogger) DebugContext(_ context.Context, messageTemplate string, args ...any) {
+	l.write(DebugLevel, messageTemplate, args)
+}
+func (l *textLogger) Info(messageTemplate string, args ...any) {
+	l.write(InfoLevel, messageTemplate, args)
+}
+func (l *textLogger) InfoContext(_ context.Context, messageTemplate string, args ...any) {
+	l.write(InfoLevel, messageTemplate, args)
+}
+func (l *textLogger) Warn(messageTemplate string, args ...any) {
+	l.write(WarnLevel, messageTemplate, args)
+}
+func (l *textLogger) WarnContext(_ context.Context, messageTemplate string, args ...any) {
+	l.write(WarnLevel, messageTemplate, args)
+}
+func (l *textLogger) Error(messageTemplate string, args ...any) {
+	l.write(ErrorLevel, messageTemplate, args)
+}
+func (l *textLogger) ErrorContext(_ context.Context, messageTemplate string, args ...any) {
+	l.write(ErrorLevel, messageTemplate, args)
+}
+func (l *textLogger) Fatal(messageTemplate string, args ...any) {
+	l.write(FatalLevel, messageTemplate, args)
+}
+func (l *textLogger) FatalContext(_ context.Context, messageTemplate string, args ...any) {
+	l.write(FatalLevel, messageTemplate, args)
+}
+
+// ForContext returns the logger itself: properties are not rendered as plain text.
+func (l *textLogger) ForContext(key string, value any) Logger { return l }
+
+// WithProperty returns the logger itself: properties are not rendered as plain text.
+func (l *textLogger) WithProperty(key string, value any) Logger { return l }
@@ -0,0 +1,101 @@
+package observability
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestParseVerbosity(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Verbosity
+	}{
+		{"q", VerbosityQuiet},
+		{"quiet", VerbosityQuiet},
+		{"QUIET", VerbosityQuiet},
+		{"qui", VerbosityQuiet},
+		{"m", VerbosityMinimal},
+		{"minimal", VerbosityMinimal},
+		{"Min", VerbosityMinimal},
+		{"n", VerbosityNormal},
+		{"normal", VerbosityNormal},
+		{"norm", VerbosityNormal},
+		{"d", VerbosityDetailed},
+		{"detailed", VerbosityDetailed},
+		{"De", VerbosityDetailed},
+		{"diag", VerbosityDiagnostic},
+		{"diagnostic", VerbosityDiagnostic},
+		{"DIAGNOSTIC", VerbosityDiagnostic},
+		{"di", VerbosityDiagnostic},
+		{" n ", VerbosityNormal},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseVerbosity(tt.value)
+		if err != nil {
+			t.Errorf("ParseVerbosity(%q) error = %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseVerbosity(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseVerbosity_Invalid(t *testing.T) {
+	for _, value := range []string{"chatty", "", "quieter", "diagnostics", "x", "2"} {
+		_, err := ParseVerbosity(value)
+		if !errors.Is(err, ErrInvalidVerbosity) {
+			t.Errorf("ParseVerbosity(%q) error = %v, want ErrInvalidVerbosity", value, err)
+		}
+	}
+
+	_, err := ParseVerbosity("chatty")
+	if want := "Verbosity level is not valid.\nSwitch: chatty"; err == nil || err.Error() != want {
+		t.Errorf("ParseVerbosity(chatty) error = %q, want %q", err, want)
+	}
+}
+
+func TestVerbosity_String(t *testing.T) {
+	for _, name := range []string{"quiet", "minimal", "normal", "detailed", "diagnostic"} {
+		v, err := ParseVerbosity(name)
+		if err != nil {
+			t.Fatalf("ParseVerbosity(%q) error = %v", name, err)
+		}
+		if v.String() != name {
+			t.Errorf("String() = %q, want %q", v.String(), name)
+		}
+	}
+	if zero := Verbosity(0); zero != VerbosityMinimal {
+		t.Errorf("zero value = %v, want minimal", zero)
+	}
+}
+
+func TestNewVerbosityLogger(t *testing.T) {
+	tests := []struct {
+		verbosity Verbosity
+		want      string
+	}{
+		{VerbosityQuiet, ""},
+		{VerbosityMinimal, ""},
+		{VerbosityNormal, ""},
+		{VerbosityDetailed, "Fetching Contoso.Lib 1.0.0\nwarn : Retrying {literal}\n"},
+		{VerbosityDiagnostic, "Trace 42\nFetching Contoso.Lib 1.0.0\nwarn : Retrying {literal}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.verbosity.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			log := NewVerbosityLogger(&buf, tt.verbosity)
+
+			log.Verbose("Trace {Value:000}", 42)
+			log.Debug("Fetching {PackageID} {@Version}", "Contoso.Lib", "1.0.0")
+			log.ForContext("Source", "test").Warn("Retrying {{literal}}")
+
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -430,3 +430,69 @@ func TestFilterToDllExe(t *testing.T) {
 		})
 	}
 }
+
+// Regressions found by the asset selection compatibility matrix
+func TestContentItemCollection_FindBestItemGroup_MatrixRegressions(t *testing.T) {
+	conventions := NewManagedCodeConventions()
+
+	tests := []struct {
+		name      string
+		paths     []string
+		framework string
+		want      []string
+	}{
+		{
+			name:      "documentation and symbols are not assemblies",
+			paths:     []string{"lib/net6.0/MyLib.dll", "lib/net6.0/MyLib.xml", "lib/net6.0/MyLib.pdb"},
+			framework: "net8.0",
+			want:      []string{"lib/net6.0/MyLib.dll"},
+		},
+		{
+			name:      "placeholder is kept",
+			paths:     []string{"lib/net462/_._", "lib/netstandard2.0/MyLib.dll"},
+			framework: "net472",
+			want:      []string{"lib/net462/_._"},
+		},
+		{
+			name:      "versioned folder beats the root of lib",
+			paths:     []string{"lib/MyLib.dll", "lib/net40/MyLib.dll"},
+			framework: "net472",
+			want:      []string{"lib/net40/MyLib.dll"},
+		},
+		{
+			name:      "root of lib supports any .NET Framework version",
+			paths:     []string{"lib/MyLib.dll", "lib/net45/MyLib.dll"},
+			framework: "net40",
+			want:      []string{"lib/MyLib.dll"},
+		},
+		{
+			name:      "root of lib does not support .NET",
+			paths:     []string{"lib/MyLib.dll"},
+			framework: "net8.0",
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fw, err := frameworks.ParseFramework(tt.framework)
+			if err != nil {
+				t.Fatalf("failed to parse framework %s: %v", tt.framework, err)
+			}
+
+			collection := NewContentItemCollection(tt.paths)
+			group := collection.FindBestItemGroup(ForFramework(fw, conventions.Properties), conventions.RuntimeAssemblies)
+
+			var got []string
+			if group != nil {
+				for _, item := range group.Items {
+					got = append(got, item.Path)
+				}
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("items = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"github.com/willibrandon/gonuget/frameworks"
 )
 
+// defaultTfm is the framework of assemblies directly in lib/: any .NET Framework version.
+// Reference: ManagedCodeConventions.cs DefaultTfm
+var defaultTfm = &frameworks.NuGetFramework{Framework: ".NETFramework"}
+
 // ManagedCodeConventions defines standard .NET package conventions.
 // Reference: ContentModel/ManagedCodeConventions.cs
 type ManagedCodeConventions struct {
@@ -101,11 +105,13 @@ func (c *ManagedCodeConventions) defineProperties() {
 // Property parsers
 // Reference: ManagedCodeConventions.cs
 
+// allowEmptyFolderParser accepts the "_._" placeholder as a pseudo-assembly. Real files
+// are matched by the file extensions of the property.
 func allowEmptyFolderParser(value string, table *PatternTable, matchOnly bool) any {
-	if matchOnly {
-		return value // Return something non-nil to indicate match
+	if value == "_._" {
+		return value
 	}
-	return value
+	return nil
 }
 
 func identityParser(value string, table *PatternTable, matchOnly bool) any {
@@ -251,7 +257,7 @@ func (c *ManagedCodeConventions) definePatternSets() {
 				Pattern: "lib/{assembly?}",
 				Table:   DotnetAnyTable,
 				Defaults: map[string]any{
-					"tfm": frameworks.CommonFrameworks.Net,
+					"tfm": defaultTfm,
 				},
 			},
 		},
@@ -268,7 +274,7 @@ func (c *ManagedCodeConventions) definePatternSets() {
 				Pattern: "lib/{assembly}",
 				Table:   DotnetAnyTable,
 				Defaults: map[string]any{
-					"tfm": frameworks.CommonFrameworks.Net,
+					"tfm": defaultTfm,
 				},
 			},
 		},
@@ -303,7 +309,7 @@ func (c *ManagedCodeConventions) definePatternSets() {
 				Pattern: "lib/{assembly?}",
 				Table:   DotnetAnyTable,
 				Defaults: map[string]any{
-					"tfm": frameworks.CommonFrameworks.Net,
+					"tfm": defaultTfm,
 				},
 			},
 		},
@@ -316,7 +322,7 @@ func (c *ManagedCodeConventions) definePatternSets() {
 				Pattern: "lib/{assembly}",
 				Table:   DotnetAnyTable,
 				Defaults: map[string]any{
-					"tfm": frameworks.CommonFrameworks.Net,
+					"tfm": defaultTfm,
 				},
 			},
 		},
@@ -362,14 +362,19 @@ func TestTfmCompareTest(t *testing.T) {
 
 func TestPropertyParsers(t *testing.T) {
 	t.Run("allowEmptyFolderParser", func(t *testing.T) {
-		result := allowEmptyFolderParser("test", nil, false)
-		if result != "test" {
-			t.Errorf("Expected 'test', got %v", result)
+		result := allowEmptyFolderParser("_._", nil, false)
+		if result != "_._" {
+			t.Errorf("Expected '_._', got %v", result)
 		}
 
-		resultMatch := allowEmptyFolderParser("test", nil, true)
-		if resultMatch != "test" {
-			t.Errorf("Expected 'test' in match mode, got %v", resultMatch)
+		resultMatch := allowEmptyFolderParser("_._", nil, true)
+		if resultMatch != "_._" {
+			t.Errorf("Expected '_._' in match mode, got %v", resultMatch)
+		}
+
+		// Other files are only matched by the file extensions of the property
+		if result := allowEmptyFolderParser("MyLib.xml", nil, false); result != nil {
+			t.Errorf("Expected nil for a non-assembly file, got %v", result)
 		}
 	})
 
@@ -4,8 +4,11 @@ import (
 	"archive/zip"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
 	"os"
+	"path"
+	"runtime"
 	"strings"
 	"time"
 
@@ -18,6 +21,16 @@ type PackageBuilder struct {
 	metadata PackageMetadata
 	files    []PackageFile
 
+	// nuspecSchema pins the nuspec namespace; empty selects it from the features used
+	nuspecSchema string
+
+	// checkLineEndings enables the mixed line ending check when saving
+	checkLineEndings bool
+	warnings         []string
+
+	// nuspecFiles are the <files> entries of the nuspec, expanded by AddFilesFromNuspec
+	nuspecFiles []NuspecFile
+
 	// Internal tracking
 	filePaths   map[string]bool // For duplicate detection
 	createdTime time.Time
@@ -25,10 +38,11 @@ type PackageBuilder struct {
 
 // PackageFile represents a file to be added to the package.
 type PackageFile struct {
-	SourcePath string    // Path on disk (or empty for in-memory)
-	TargetPath string    // Path in .nupkg
-	Content    []byte    // In-memory content (if SourcePath is empty)
-	Reader     io.Reader // Stream content (if SourcePath is empty and Content is nil)
+	SourcePath string      // Path on disk (or empty for in-memory)
+	TargetPath string      // Path in .nupkg
+	Content    []byte      // In-memory content (if SourcePath is empty)
+	Reader     io.Reader   // Stream content (if SourcePath is empty and Content is nil)
+	Mode       fs.FileMode // Unix permission bits stored in the ZIP entry (0 = none)
 }
 
 // NewPackageBuilder creates a new package builder.
@@ -205,6 +219,19 @@ func (b *PackageBuilder) SetRepository(repo *PackageRepositoryMetadata) *Package
 	return b
 }
 
+// SetNuspecSchema pins the schema namespace written to the generated nuspec.
+// Accepts a schema version such as "2012/06" or the full namespace URI.
+// Elements the schema doesn't define are left out of the nuspec; Save fails if
+// the package uses a feature the schema can't express (e.g. prerelease versions before 2011/10).
+func (b *PackageBuilder) SetNuspecSchema(schema string) error {
+	namespace, err := ResolveNuspecNamespace(schema)
+	if err != nil {
+		return err
+	}
+	b.nuspecSchema = namespace
+	return nil
+}
+
 // SetLicenseMetadata sets license metadata.
 func (b *PackageBuilder) SetLicenseMetadata(license *LicenseMetadata) *PackageBuilder {
 	b.metadata.LicenseMetadata = license
@@ -276,9 +303,18 @@ func (b *PackageBuilder) AddFile(sourcePath, targetPath string) error {
 		return fmt.Errorf("duplicate file path: %s", targetPath)
 	}
 
+	// Keep the Unix mode bits so scripts stay executable after extraction
+	var mode fs.FileMode
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(sourcePath); err == nil {
+			mode = info.Mode().Perm()
+		}
+	}
+
 	b.files = append(b.files, PackageFile{
 		SourcePath: sourcePath,
 		TargetPath: normalizedTarget,
+		Mode:       mode,
 	})
 
 	b.filePaths[normalizedTarget] = true
@@ -327,6 +363,31 @@ func (b *PackageBuilder) AddFileFromReader(targetPath string, reader io.Reader)
 	return nil
 }
 
+// SetFileMode sets the Unix permission bits stored for a file already added to the package,
+// e.g. 0755 to keep a tool script executable after extraction on Unix.
+func (b *PackageBuilder) SetFileMode(targetPath string, mode fs.FileMode) error {
+	normalizedTarget := normalizePackagePath(targetPath)
+	for i := range b.files {
+		if b.files[i].TargetPath == normalizedTarget {
+			b.files[i].Mode = mode.Perm()
+			return nil
+		}
+	}
+	return fmt.Errorf("file not in package: %s", targetPath)
+}
+
+// SetCheckLineEndings enables a check for text files with mixed CRLF and LF line endings.
+// Files that fail the check are still packed; see Warnings.
+func (b *PackageBuilder) SetCheckLineEndings(enabled bool) *PackageBuilder {
+	b.checkLineEndings = enabled
+	return b
+}
+
+// Warnings returns the warnings of the last Save, such as files with mixed line endings.
+func (b *PackageBuilder) Warnings() []string {
+	return b.warnings
+}
+
 // normalizePackagePath normalizes a package path to use forward slashes.
 func normalizePackagePath(path string) string {
 	return strings.ReplaceAll(path, "\\", "/")
@@ -445,6 +506,8 @@ func (b *PackageBuilder) PopulateFromNuspec(nuspec *Nuspec) error {
 		b.metadata.FrameworkReferenceGroups = append(b.metadata.FrameworkReferenceGroups, group.ToPackageFrameworkReferenceGroup())
 	}
 
+	b.nuspecFiles = nuspec.Files
+
 	return nil
 }
 
@@ -482,10 +545,12 @@ func (b *PackageBuilder) writeOPCFiles(zipWriter *zip.Writer, nuspecFileName str
 // Save writes the package to a stream.
 func (b *PackageBuilder) Save(writer io.Writer) error {
 	// Comprehensive validation
-	if err := b.Validate(); err != nil {
+	if err := b.validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	b.warnings = nil
+
 	// Create ZIP archive
 	zipWriter := zip.NewWriter(writer)
 	defer func() { _ = zipWriter.Close() }()
@@ -529,8 +594,49 @@ func (b *PackageBuilder) SaveToFile(path string) error {
 	return file.Close()
 }
 
-// Validate performs comprehensive package validation
-func (b *PackageBuilder) Validate() error {
+// PackageTypeSymbolsPackage is the package type declared by .snupkg symbol packages.
+const PackageTypeSymbolsPackage = "SymbolsPackage"
+
+// SaveSymbols writes the .snupkg symbol package of the package to a stream: the .pdb
+// files added to the builder, at the same paths, and a nuspec with the package's ID,
+// version and metadata whose only package type is SymbolsPackage. The icon, readme and
+// license files aren't part of a symbol package, so their nuspec elements are dropped
+// (matches NuGet.Client's snupkg format). Files added from a reader can only be written
+// by one save.
+func (b *PackageBuilder) SaveSymbols(writer io.Writer) error {
+	if err := b.validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	var symbolFiles []PackageFile
+	for _, file := range b.files {
+		if strings.EqualFold(path.Ext(file.TargetPath), ".pdb") {
+			symbolFiles = append(symbolFiles, file)
+		}
+	}
+	if len(symbolFiles) == 0 {
+		return fmt.Errorf("symbol package for %s requires at least one .pdb file", b.metadata.ID)
+	}
+
+	symbols := NewPackageBuilder()
+	symbols.metadata = b.metadata
+	symbols.metadata.PackageTypes = []PackageTypeInfo{{Name: PackageTypeSymbolsPackage}}
+	symbols.metadata.Icon = ""
+	symbols.metadata.Readme = ""
+	if license := b.metadata.LicenseMetadata; license != nil && license.Type == "file" {
+		symbols.metadata.LicenseMetadata = nil
+	}
+	symbols.files = symbolFiles
+	symbols.checkLineEndings = b.checkLineEndings
+	symbols.createdTime = b.createdTime
+
+	return symbols.Save(writer)
+}
+
+// validate checks that the package can be written: a valid ID and version, valid
+// dependencies and files, and the license, icon and readme files it references.
+// See Validate for NuGet's pack rules.
+func (b *PackageBuilder) validate() error {
 	// Validate ID
 	if err := ValidatePackageID(b.metadata.ID); err != nil {
 		return fmt.Errorf("package ID validation: %w", err)
@@ -581,7 +687,15 @@ func (b *PackageBuilder) Validate() error {
 
 func (b *PackageBuilder) writeNuspec(zipWriter *zip.Writer) (string, error) {
 	// Generate nuspec XML
-	nuspecXML, err := GenerateNuspecXML(b.metadata)
+	namespace := b.nuspecSchema
+	if namespace == "" {
+		// Files can require a newer schema than the metadata alone
+		namespace = determineNuspecNamespace(b.metadata)
+		if forFiles := determineNuspecNamespaceForFiles(b.files); nuspecSchemaLevel(forFiles) > nuspecSchemaLevel(namespace) {
+			namespace = forFiles
+		}
+	}
+	nuspecXML, err := GenerateNuspecXMLForSchema(b.metadata, namespace)
 	if err != nil {
 		return "", err
 	}
@@ -614,12 +728,28 @@ func (b *PackageBuilder) writeFiles(zipWriter *zip.Writer) error {
 }
 
 func (b *PackageBuilder) writeFile(zipWriter *zip.Writer, file PackageFile) error {
-	// Create ZIP entry
-	writer, err := zipWriter.Create(file.TargetPath)
+	// Create ZIP entry (same header as zip.Writer.Create, plus the mode when set)
+	header := &zip.FileHeader{Name: file.TargetPath, Method: zip.Deflate}
+	if file.Mode != 0 {
+		header.SetMode(file.Mode)
+	}
+	entry, err := zipWriter.CreateHeader(header)
 	if err != nil {
 		return fmt.Errorf("create ZIP entry: %w", err)
 	}
 
+	writer := entry
+	var lineEndings *lineEndingDetector
+	if b.checkLineEndings {
+		lineEndings = &lineEndingDetector{}
+		writer = io.MultiWriter(entry, lineEndings)
+		defer func() {
+			if lineEndings.Mixed() {
+				b.warnings = append(b.warnings, fmt.Sprintf("%s has mixed line endings (CRLF and LF)", file.TargetPath))
+			}
+		}()
+	}
+
 	// Write from source
 	switch {
 	case file.SourcePath != "":
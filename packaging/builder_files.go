@@ -0,0 +1,139 @@
+package packaging
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// AddFilesFromNuspec adds the files listed in the <files> section of the nuspec the
+// builder was populated from. Each src is resolved relative to baseDir and may use the
+// wildcards "*", "?" and "**"; files matching the semicolon separated exclude patterns
+// are skipped. Files land in the target folder: a recursive ("**") src keeps the path
+// below the folder the wildcard starts in, any other src keeps only the file name. A src
+// without wildcards whose extension matches the target's is renamed to target instead.
+// Reference: PackageBuilder.AddFiles and PathResolver in NuGet.Client
+func (b *PackageBuilder) AddFilesFromNuspec(baseDir string) error {
+	for _, file := range b.nuspecFiles {
+		if err := b.addNuspecFile(baseDir, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addNuspecFile expands one <file> entry
+func (b *PackageBuilder) addNuspecFile(baseDir string, file NuspecFile) error {
+	if strings.TrimSpace(file.Source) == "" {
+		return fmt.Errorf("nuspec file entry has no src")
+	}
+
+	pattern := resolveSourcePattern(baseDir, file.Source)
+	searchDir := wildcardSearchDir(pattern)
+	isWildcard := strings.ContainsAny(file.Source, "*?")
+	isRecursive := strings.Contains(file.Source, "**")
+
+	var excludes []string
+	for exclude := range strings.SplitSeq(file.Exclude, ";") {
+		if exclude = strings.TrimSpace(exclude); exclude != "" {
+			excludes = append(excludes, resolveSourcePattern(baseDir, exclude))
+		}
+	}
+
+	sources, err := matchSourceFiles(pattern, searchDir, isWildcard)
+	if err != nil {
+		return err
+	}
+	if !isWildcard && len(sources) == 0 {
+		return fmt.Errorf("nuspec file '%s' not found", file.Source)
+	}
+
+	target := normalizePackagePath(strings.TrimSpace(file.Target))
+	for _, source := range sources {
+		if matchesAnyPattern(source, excludes) {
+			continue
+		}
+
+		var targetPath string
+		switch {
+		case isRecursive:
+			targetPath = path.Join(target, strings.TrimPrefix(source[len(searchDir):], "/"))
+		case !isWildcard && target != "" && !strings.HasSuffix(target, "/") &&
+			strings.EqualFold(path.Ext(source), path.Ext(target)):
+			// A single file whose extension matches the target is renamed
+			targetPath = target
+		default:
+			targetPath = path.Join(target, path.Base(source))
+		}
+
+		if err := b.AddFile(filepath.FromSlash(source), strings.TrimPrefix(targetPath, "/")); err != nil {
+			return fmt.Errorf("add '%s': %w", file.Source, err)
+		}
+	}
+	return nil
+}
+
+// resolveSourcePattern makes a src or exclude pattern absolute, using forward slashes
+func resolveSourcePattern(baseDir, pattern string) string {
+	pattern = normalizePackagePath(strings.TrimSpace(pattern))
+	if !filepath.IsAbs(filepath.FromSlash(pattern)) {
+		pattern = path.Join(filepath.ToSlash(baseDir), pattern)
+	}
+	return path.Clean(pattern)
+}
+
+// wildcardSearchDir returns the folder of pattern before its first wildcard
+func wildcardSearchDir(pattern string) string {
+	wildcard := strings.IndexAny(pattern, "*?")
+	if wildcard < 0 {
+		return path.Dir(pattern)
+	}
+	return path.Dir(pattern[:wildcard+1])
+}
+
+// matchSourceFiles returns the files under searchDir matching pattern, in lexical order
+func matchSourceFiles(pattern, searchDir string, isWildcard bool) ([]string, error) {
+	if !isWildcard {
+		info, err := os.Stat(filepath.FromSlash(pattern))
+		if err != nil || info.IsDir() {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+
+	re := packagePathPattern(pattern)
+	var matches []string
+	err := filepath.WalkDir(filepath.FromSlash(searchDir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// A wildcard whose folder doesn't exist matches nothing
+			if p == filepath.FromSlash(searchDir) && os.IsNotExist(err) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if name := filepath.ToSlash(p); re.MatchString(strings.TrimPrefix(name, "/")) {
+			matches = append(matches, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search '%s': %w", pattern, err)
+	}
+	return matches, nil
+}
+
+// matchesAnyPattern reports whether name matches one of the wildcard patterns
+func matchesAnyPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if packagePathPattern(pattern).MatchString(strings.TrimPrefix(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package packaging
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeSourceTree creates empty files at the slash separated paths under dir
+func writeSourceTree(t *testing.T, dir string, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		full := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(p), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// nuspecFilesBuilder returns a builder populated from a nuspec with the given <files> entries
+func nuspecFilesBuilder(t *testing.T, files string) *PackageBuilder {
+	t.Helper()
+	nuspec, err := ParseNuspec(strings.NewReader(`<package>
+  <metadata>
+    <id>Test.Package</id>
+    <version>1.0.0</version>
+    <authors>Test</authors>
+    <description>Test</description>
+  </metadata>
+  <files>` + files + `</files>
+</package>`))
+	if err != nil {
+		t.Fatalf("ParseNuspec() error = %v", err)
+	}
+
+	builder := NewPackageBuilder()
+	if err := builder.PopulateFromNuspec(nuspec); err != nil {
+		t.Fatalf("PopulateFromNuspec() error = %v", err)
+	}
+	return builder
+}
+
+func packageTargetPaths(builder *PackageBuilder) []string {
+	var paths []string
+	for _, file := range builder.GetFiles() {
+		paths = append(paths, file.TargetPath)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+func TestBuilderAddFilesFromNuspec(t *testing.T) {
+	baseDir := t.TempDir()
+	writeSourceTree(t, baseDir,
+		"bin/Release/App.dll",
+		"bin/Release/App.xml",
+		"bin/Release/de/App.resources.dll",
+		"bin/Release/fr/App.resources.dll",
+		"bin/Release/fr/App.Tests.dll",
+		"content/readme.txt",
+		"content/images/logo.png",
+		"tools/install.ps1",
+		"LICENSE.txt",
+	)
+
+	tests := []struct {
+		name  string
+		files string
+		want  []string
+	}{
+		{
+			"wildcard keeps file names",
+			`<file src="bin\Release\*.dll" target="lib\net6.0" />`,
+			[]string{"lib/net6.0/App.dll"},
+		},
+		{
+			"recursive wildcard keeps subpaths",
+			`<file src="bin/Release/**/*.dll" target="lib/net6.0" />`,
+			[]string{"lib/net6.0/App.dll", "lib/net6.0/de/App.resources.dll", "lib/net6.0/fr/App.Tests.dll", "lib/net6.0/fr/App.resources.dll"},
+		},
+		{
+			"recursive folder",
+			`<file src="content\**" target="contentFiles\any\any" />`,
+			[]string{"contentFiles/any/any/images/logo.png", "contentFiles/any/any/readme.txt"},
+		},
+		{
+			"exclude",
+			`<file src="bin/Release/**/*.dll" target="lib/net6.0" exclude="**\*.Tests.dll;bin/Release/de/**" />`,
+			[]string{"lib/net6.0/App.dll", "lib/net6.0/fr/App.resources.dll"},
+		},
+		{
+			"target folder",
+			`<file src="tools/install.ps1" target="tools" />`,
+			[]string{"tools/install.ps1"},
+		},
+		{
+			"target folder with a dot",
+			`<file src="bin/Release/App.dll" target="lib/net6.0" />`,
+			[]string{"lib/net6.0/App.dll"},
+		},
+		{
+			"target file renames",
+			`<file src="LICENSE.txt" target="docs/license.txt" />`,
+			[]string{"docs/license.txt"},
+		},
+		{
+			"no target",
+			`<file src="LICENSE.txt" />`,
+			[]string{"LICENSE.txt"},
+		},
+		{
+			"wildcard without matches",
+			`<file src="missing/**/*.dll" target="lib" />`,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := nuspecFilesBuilder(t, tt.files)
+			if err := builder.AddFilesFromNuspec(baseDir); err != nil {
+				t.Fatalf("AddFilesFromNuspec() error = %v", err)
+			}
+			if got := packageTargetPaths(builder); !slices.Equal(got, tt.want) {
+				t.Errorf("files = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderAddFilesFromNuspec_Errors(t *testing.T) {
+	baseDir := t.TempDir()
+	writeSourceTree(t, baseDir, "a/App.dll", "b/App.dll")
+
+	tests := []struct {
+		name    string
+		files   string
+		wantErr string
+	}{
+		{"missing file", `<file src="App.dll" target="lib" />`, "nuspec file 'App.dll' not found"},
+		{"duplicate target", `<file src="*/App.dll" target="lib" />`, "duplicate file path"},
+		{"no src", `<file target="lib" />`, "has no src"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := nuspecFilesBuilder(t, tt.files)
+			if err := builder.AddFilesFromNuspec(baseDir); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("AddFilesFromNuspec() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"testing"
 
@@ -919,6 +922,22 @@ func TestBuilderSave_ValidationErrors(t *testing.T) {
 			},
 			errorSubstr: "package version is required",
 		},
+		{
+			name: "missing license file",
+			setupFunc: func(b *PackageBuilder) {
+				b.SetID("TestPackage").SetVersion(version.MustParse("1.0.0"))
+				b.SetLicenseMetadata(&LicenseMetadata{Type: "file", Text: "LICENSE.txt"})
+			},
+			errorSubstr: "NU5030: The license file 'LICENSE.txt' does not exist in the package",
+		},
+		{
+			name: "invalid license expression",
+			setupFunc: func(b *PackageBuilder) {
+				b.SetID("TestPackage").SetVersion(version.MustParse("1.0.0"))
+				b.SetLicenseMetadata(&LicenseMetadata{Type: "expression", Text: "MIT OR Bogus"})
+			},
+			errorSubstr: "invalid license expression 'MIT OR Bogus': the license identifier 'Bogus' is not recognized",
+		},
 		// Note: Description and Authors are NOT required (matches NuGet.Client behavior)
 	}
 
@@ -1291,3 +1310,264 @@ func TestBuilderSave_CompleteMetadata(t *testing.T) {
 		}
 	}
 }
+
+func TestBuilderSave_NuspecSchema(t *testing.T) {
+	builder := NewPackageBuilder().
+		SetID("TestPackage").
+		SetVersion(version.MustParse("1.0.0")).
+		SetDescription("Schema test").
+		SetAuthors("Author").
+		SetRepository(&PackageRepositoryMetadata{Type: "git", URL: "https://github.com/test/repo"})
+
+	if err := builder.SetNuspecSchema("2010/01"); err == nil {
+		t.Error("SetNuspecSchema() expected error for unknown schema")
+	}
+	if err := builder.SetNuspecSchema("2012/06"); err != nil {
+		t.Fatalf("SetNuspecSchema() error = %v", err)
+	}
+	_ = builder.AddFileFromBytes("lib/net45/test.dll", []byte("test dll"))
+
+	var buf bytes.Buffer
+	if err := builder.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reader, err := OpenPackageFromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenPackageFromReaderAt() error = %v", err)
+	}
+	nuspec, err := reader.GetNuspec()
+	if err != nil {
+		t.Fatalf("GetNuspec() error = %v", err)
+	}
+
+	if nuspec.Xmlns != NuspecNamespaceV4 {
+		t.Errorf("Xmlns = %s, want %s", nuspec.Xmlns, NuspecNamespaceV4)
+	}
+	if nuspec.Metadata.Repository != nil {
+		t.Error("2012/06 nuspec should not contain <repository>")
+	}
+
+	// Prerelease versions can't be expressed before 2011/10
+	_ = builder.SetNuspecSchema(NuspecNamespaceV2)
+	builder.SetVersion(version.MustParse("1.0.0-beta"))
+	if err := builder.Save(io.Discard); err == nil {
+		t.Error("Save() expected error for prerelease version with 2011/08 schema")
+	}
+}
+
+func TestBuilderSave_MinimalNuspecSchema(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{name: "lib only", files: []string{"lib/net45/test.dll"}, want: NuspecNamespaceV1},
+		{name: "contentFiles", files: []string{"lib/net45/test.dll", "contentFiles/any/any/readme.txt"}, want: NuspecNamespaceV6},
+		{name: "XDT transform", files: []string{"content/web.config.install.xdt"}, want: NuspecNamespaceV6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := NewPackageBuilder().
+				SetID("TestPackage").
+				SetVersion(version.MustParse("1.0.0")).
+				SetDescription("Schema test").
+				SetAuthors("Author")
+			for _, file := range tt.files {
+				_ = builder.AddFileFromBytes(file, []byte("content"))
+			}
+
+			var buf bytes.Buffer
+			if err := builder.Save(&buf); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			reader, err := OpenPackageFromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err != nil {
+				t.Fatalf("OpenPackageFromReaderAt() error = %v", err)
+			}
+			nuspec, err := reader.GetNuspec()
+			if err != nil {
+				t.Fatalf("GetNuspec() error = %v", err)
+			}
+
+			if got := nuspec.SchemaNamespace(); got != tt.want {
+				t.Errorf("SchemaNamespace() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderSave_FileModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix mode bits are not recorded on Windows")
+	}
+
+	tmpDir := t.TempDir()
+	script := filepath.Join(tmpDir, "install.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho hi\n"), 0755); err != nil {
+		t.Fatalf("Failed to create script: %v", err)
+	}
+	if err := os.Chmod(script, 0755); err != nil {
+		t.Fatalf("Failed to chmod script: %v", err)
+	}
+
+	builder := NewPackageBuilder().
+		SetID("TestPackage").
+		SetVersion(version.MustParse("1.0.0")).
+		SetDescription("Test").
+		SetAuthors("Test Author")
+
+	if err := builder.AddFile(script, "tools/install.sh"); err != nil {
+		t.Fatalf("AddFile() error = %v", err)
+	}
+	_ = builder.AddFileFromBytes("tools/run", []byte("#!/bin/sh\n"))
+	_ = builder.AddFileFromBytes("lib/net6.0/test.dll", []byte("test dll"))
+
+	if err := builder.SetFileMode("tools/run", 0755); err != nil {
+		t.Fatalf("SetFileMode() error = %v", err)
+	}
+	if err := builder.SetFileMode("tools/missing", 0755); err == nil {
+		t.Error("SetFileMode() expected error for file not in package")
+	}
+
+	var buf bytes.Buffer
+	if err := builder.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Created ZIP is invalid: %v", err)
+	}
+
+	wantModes := map[string]os.FileMode{
+		"tools/install.sh":    0755,
+		"tools/run":           0755,
+		"lib/net6.0/test.dll": 0,
+	}
+	for _, file := range zipReader.File {
+		want, ok := wantModes[file.Name]
+		if !ok {
+			continue
+		}
+		delete(wantModes, file.Name)
+
+		if got := os.FileMode(file.ExternalAttrs>>16) & os.ModePerm; got != want {
+			t.Errorf("%s external attributes mode = %o, want %o", file.Name, got, want)
+		}
+	}
+	for name := range wantModes {
+		t.Errorf("File %s not found in package", name)
+	}
+}
+
+func TestBuilderSave_CheckLineEndings(t *testing.T) {
+	builder := NewPackageBuilder().
+		SetID("TestPackage").
+		SetVersion(version.MustParse("1.0.0")).
+		SetDescription("Test").
+		SetAuthors("Test Author")
+
+	_ = builder.AddFileFromBytes("content/mixed.txt", []byte("one\r\ntwo\nthree\r\n"))
+	_ = builder.AddFileFromBytes("content/crlf.txt", []byte("one\r\ntwo\r\n"))
+	_ = builder.AddFileFromBytes("content/lf.txt", []byte("one\ntwo\n"))
+	_ = builder.AddFileFromBytes("lib/net6.0/test.dll", []byte("MZ\x00\x00\r\n\n"))
+	_ = builder.AddFileFromReader("tools/mixed.ps1", strings.NewReader("a\nb\r\n"))
+
+	// Off by default
+	if err := builder.Save(io.Discard); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if warnings := builder.Warnings(); len(warnings) != 0 {
+		t.Errorf("Warnings() = %v, want none when the check is disabled", warnings)
+	}
+
+	builder = NewPackageBuilder().
+		SetID("TestPackage").
+		SetVersion(version.MustParse("1.0.0")).
+		SetDescription("Test").
+		SetAuthors("Test Author").
+		SetCheckLineEndings(true)
+
+	_ = builder.AddFileFromBytes("content/mixed.txt", []byte("one\r\ntwo\nthree\r\n"))
+	_ = builder.AddFileFromBytes("content/crlf.txt", []byte("one\r\ntwo\r\n"))
+	_ = builder.AddFileFromBytes("content/lf.txt", []byte("one\ntwo\n"))
+	_ = builder.AddFileFromBytes("lib/net6.0/test.dll", []byte("MZ\x00\x00\r\n\n"))
+	_ = builder.AddFileFromReader("tools/mixed.ps1", strings.NewReader("a\nb\r\n"))
+
+	if err := builder.Save(io.Discard); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	warnings := builder.Warnings()
+	if len(warnings) != 2 {
+		t.Fatalf("Warnings() = %v, want 2", warnings)
+	}
+	if !strings.Contains(warnings[0], "content/mixed.txt") || !strings.Contains(warnings[1], "tools/mixed.ps1") {
+		t.Errorf("Warnings() = %v, want content/mixed.txt and tools/mixed.ps1", warnings)
+	}
+}
+
+func TestBuilderSaveSymbols(t *testing.T) {
+	builder := NewPackageBuilder().
+		SetID("Contoso.Utils").
+		SetVersion(version.MustParse("1.2.0")).
+		SetDescription("Utilities").
+		SetAuthors("Contoso").
+		SetReadme("README.md")
+	builder.AddPackageType(PackageTypeInfo{Name: "Dependency"})
+	for _, file := range []string{"lib/net8.0/Contoso.Utils.dll", "lib/net8.0/Contoso.Utils.PDB", "lib/net8.0/Contoso.Utils.xml", "README.md"} {
+		if err := builder.AddFileFromBytes(file, []byte("content")); err != nil {
+			t.Fatalf("AddFileFromBytes(%s) error = %v", file, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := builder.SaveSymbols(&buf); err != nil {
+		t.Fatalf("SaveSymbols() error = %v", err)
+	}
+
+	reader, err := OpenPackageFromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("OpenPackageFromReaderAt() error = %v", err)
+	}
+	if got, want := reader.GetFiles(), []string{"Contoso.Utils.nuspec", "lib/net8.0/Contoso.Utils.PDB"}; !slices.Equal(got, want) {
+		t.Errorf("files = %q, want %q", got, want)
+	}
+
+	nuspec, err := reader.GetNuspec()
+	if err != nil {
+		t.Fatalf("GetNuspec() error = %v", err)
+	}
+	if nuspec.Metadata.ID != "Contoso.Utils" || nuspec.Metadata.Version != "1.2.0" {
+		t.Errorf("identity = %s %s, want Contoso.Utils 1.2.0", nuspec.Metadata.ID, nuspec.Metadata.Version)
+	}
+	if want := []PackageType{{Name: PackageTypeSymbolsPackage}}; !slices.Equal(nuspec.Metadata.PackageTypes, want) {
+		t.Errorf("package types = %+v, want %+v", nuspec.Metadata.PackageTypes, want)
+	}
+	if nuspec.Metadata.Readme != "" {
+		t.Errorf("readme = %q, want none in a symbol package", nuspec.Metadata.Readme)
+	}
+
+	// The primary package is unchanged
+	if got := builder.GetMetadata().PackageTypes; len(got) != 1 || got[0].Name != "Dependency" {
+		t.Errorf("primary package types = %+v, want Dependency", got)
+	}
+}
+
+func TestBuilderSaveSymbols_NoSymbols(t *testing.T) {
+	builder := NewPackageBuilder().
+		SetID("Contoso.Utils").
+		SetVersion(version.MustParse("1.2.0")).
+		SetDescription("Utilities").
+		SetAuthors("Contoso")
+	if err := builder.AddFileFromBytes("lib/net8.0/Contoso.Utils.dll", []byte("dll")); err != nil {
+		t.Fatalf("AddFileFromBytes() error = %v", err)
+	}
+
+	err := builder.SaveSymbols(io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "requires at least one .pdb file") {
+		t.Errorf("SaveSymbols() error = %v, want a missing .pdb error", err)
+	}
+}
@@ -1,9 +1,11 @@
 package packaging
 
 import (
+	"cmp"
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,6 +21,15 @@ const (
 
 	// LockFileExtension is the lock file extension
 	LockFileExtension = ".lock"
+
+	// DefaultLockWaitMessageInterval is how often a waiting message is logged while
+	// another process holds a lock
+	DefaultLockWaitMessageInterval = 5 * time.Second
+
+	// DefaultStaleLockAge is how long a lock held from another machine, for example on
+	// a network file system, is held before it is considered stale. The liveness of a
+	// process on another machine can't be checked.
+	DefaultStaleLockAge = 10 * time.Minute
 )
 
 // FileLock represents an exclusive file lock for package extraction.
@@ -30,57 +41,180 @@ type FileLock struct {
 	lockFile     *os.File
 }
 
-// acquireFileLock acquires an exclusive file lock for the target file.
-// Returns an unlock function that MUST be called when done (use defer).
+// LockOptions configures how lock acquisition waits for another process.
+// The zero value uses the defaults and logs nothing.
+type LockOptions struct {
+	// Timeout is the maximum time to wait for the lock (DefaultLockTimeout when 0)
+	Timeout time.Duration
+
+	// WaitMessageInterval is how often a waiting message is logged
+	// (DefaultLockWaitMessageInterval when 0)
+	WaitMessageInterval time.Duration
+
+	// StaleLockAge is how long a lock held from another machine is held before it
+	// is broken (DefaultStaleLockAge when 0)
+	StaleLockAge time.Duration
+
+	// Description names what the lock protects in messages, such as "<id> <version>"
+	// (the target file name when empty)
+	Description string
+
+	// Logger receives the waiting messages and stale lock warnings (optional)
+	Logger Logger
+}
+
+// lockOwner identifies the process holding a lock. The holder writes it to the lock
+// file so that waiting processes can report who they wait for and detect stale locks.
+type lockOwner struct {
+	PID      int       `json:"pid"`
+	Host     string    `json:"host"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// acquireFileLock acquires an exclusive file lock for the target file with the default
+// options. Returns an unlock function that MUST be called when done (use defer).
+func acquireFileLock(ctx context.Context, targetPath string) (unlock func(), err error) {
+	unlock, _, err = acquireFileLockWithOptions(ctx, targetPath, LockOptions{})
+	return unlock, err
+}
+
+// acquireFileLockWithOptions acquires an exclusive file lock for the target file and
+// reports how long it waited for it. Returns an unlock function that MUST be called
+// when done (use defer).
 //
 // Lock mechanism:
-// 1. Create lock file with unique temp name: {target}.lock.{random}
-// 2. Attempt to rename to {target}.lock (atomic operation)
-// 3. If rename succeeds, we have the lock
-// 4. If rename fails, wait and retry
+// 1. Open or create {target}.lock and try to lock it exclusively (non-blocking)
+// 2. If the lock is acquired, record the owner (PID, host, time) in the lock file
+// 3. If it is held by another process, wait and retry, logging who holds it every
+// WaitMessageInterval
+// 4. A lock whose holder is gone is broken: the holder's process is not alive on this
+// machine, or the lock was taken from another machine more than StaleLockAge ago
 //
 // Reference: NuGet.Client ConcurrencyUtilities.cs ExecuteWithFileLockedAsync
-func acquireFileLock(ctx context.Context, targetPath string) (unlock func(), err error) {
+func acquireFileLockWithOptions(ctx context.Context, targetPath string, opts LockOptions) (unlock func(), waited time.Duration, err error) {
+	timeout := cmp.Or(opts.Timeout, DefaultLockTimeout)
+	messageInterval := cmp.Or(opts.WaitMessageInterval, DefaultLockWaitMessageInterval)
+	staleAge := cmp.Or(opts.StaleLockAge, DefaultStaleLockAge)
+	description := cmp.Or(opts.Description, filepath.Base(targetPath))
+
 	// Generate lock file path
 	lockFilePath := targetPath + LockFileExtension
 
 	// Create directory for lock file
 	lockDir := filepath.Dir(lockFilePath)
 	if err := os.MkdirAll(lockDir, 0755); err != nil {
-		return nil, fmt.Errorf("create lock directory: %w", err)
+		return nil, 0, fmt.Errorf("create lock directory: %w", err)
 	}
 
 	// Retry loop for lock acquisition
 	startTime := time.Now()
+	nextMessage := startTime.Add(messageInterval)
+	var staleOwner *lockOwner
 	for {
 		// Check context cancellation
 		select {
 		case <-ctx.Done():
-			return nil, fmt.Errorf("lock acquisition cancelled: %w", ctx.Err())
+			return nil, time.Since(startTime), fmt.Errorf("lock acquisition cancelled: %w", ctx.Err())
 		default:
 		}
 
-		// Check timeout
-		if time.Since(startTime) > DefaultLockTimeout {
-			return nil, fmt.Errorf("timeout acquiring lock for %s", targetPath)
-		}
-
 		// Attempt to acquire lock
 		lock, err := tryAcquireLock(lockFilePath)
 		if err == nil {
-			// Lock acquired successfully
+			writeLockOwner(lock)
 			// Note: releaseLock is platform-specific (Unix vs Windows)
 			unlock := func() {
 				releaseLock(lock)
 			}
-			return unlock, nil
+			return unlock, time.Since(startTime), nil
 		}
 
-		// Lock held by another process, wait and retry
+		// Lock held by another process
+		owner := readLockOwner(lockFilePath)
+		now := time.Now()
+
+		// Check timeout
+		if now.Sub(startTime) > timeout {
+			if owner != nil {
+				return nil, now.Sub(startTime), fmt.Errorf("timeout acquiring lock for %s after %s (held by PID %d on %s for %s)",
+					targetPath, timeout, owner.PID, owner.Host, formatLockAge(now.Sub(owner.Acquired)))
+			}
+			return nil, now.Sub(startTime), fmt.Errorf("timeout acquiring lock for %s after %s", targetPath, timeout)
+		}
+
+		// A lock is broken once its owner was seen stale twice in a row, so that a new
+		// holder that has not written its owner yet is never mistaken for the old one
+		if owner != nil && isLockStale(owner, now, staleAge) {
+			if staleOwner != nil && *staleOwner == *owner && os.Remove(lockFilePath) == nil {
+				if opts.Logger != nil {
+					opts.Logger.Warning("Broke the stale lock on %s held by PID %d on %s since %s",
+						description, owner.PID, owner.Host, owner.Acquired.Format(time.RFC3339))
+				}
+				staleOwner = nil
+				continue
+			}
+			staleOwner = owner
+		} else {
+			staleOwner = nil
+		}
+
+		if opts.Logger != nil && !now.Before(nextMessage) {
+			if owner != nil {
+				opts.Logger.Info("Waiting for another process to finish installing %s (held by PID %d for %s)",
+					description, owner.PID, formatLockAge(now.Sub(owner.Acquired)))
+			} else {
+				opts.Logger.Info("Waiting for another process to finish installing %s", description)
+			}
+			nextMessage = now.Add(messageInterval)
+		}
+
+		// Wait and retry
 		time.Sleep(LockRetryDelay)
 	}
 }
 
+// writeLockOwner records the current process as the owner of an acquired lock. The
+// owner only serves diagnostics, so failing to record it is not an error.
+func writeLockOwner(lock *FileLock) {
+	host, _ := os.Hostname()
+	data, err := json.Marshal(lockOwner{PID: os.Getpid(), Host: host, Acquired: time.Now().UTC()})
+	if err != nil {
+		return
+	}
+	if err := lock.lockFile.Truncate(0); err != nil {
+		return
+	}
+	_, _ = lock.lockFile.WriteAt(data, 0)
+}
+
+// readLockOwner reads the owner recorded in a lock file. It returns nil when the
+// lock file records no owner, such as while its holder is still writing it.
+func readLockOwner(lockFilePath string) *lockOwner {
+	data, err := os.ReadFile(lockFilePath)
+	if err != nil || len(data) == 0 {
+		return nil
+	}
+	var owner lockOwner
+	if err := json.Unmarshal(data, &owner); err != nil || owner.PID <= 0 {
+		return nil
+	}
+	return &owner
+}
+
+// isLockStale reports whether the holder of a lock is gone: its process is not alive
+// on this machine, or it took the lock from another machine more than staleAge ago.
+func isLockStale(owner *lockOwner, now time.Time, staleAge time.Duration) bool {
+	if host, err := os.Hostname(); err == nil && owner.Host == host {
+		return !processAlive(owner.PID)
+	}
+	return now.Sub(owner.Acquired) > staleAge
+}
+
+// formatLockAge formats how long a lock has been held, in whole seconds
+func formatLockAge(d time.Duration) string {
+	return max(d, 0).Round(time.Second).String()
+}
+
 // tryAcquireLock attempts to acquire the file lock.
 // Platform-specific implementation in concurrency_unix.go and concurrency_windows.go
 // Returns non-nil error if lock is held by another process.
@@ -2,9 +2,13 @@ package packaging
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -502,6 +506,130 @@ func BenchmarkTryAcquireLock(b *testing.B) {
 	}
 }
 
+func TestAcquireFileLockWithOptions_WaitMessages(t *testing.T) {
+	targetPath := filepath.Join(t.TempDir(), "contoso.lib.1.0.0.nupkg")
+
+	// Another holder releases the lock after a while
+	unlock1, err := acquireFileLock(context.Background(), targetPath)
+	if err != nil {
+		t.Fatalf("acquireFileLock() error = %v", err)
+	}
+	time.AfterFunc(450*time.Millisecond, unlock1)
+
+	logger := &extractorTestLogger{}
+	unlock2, waited, err := acquireFileLockWithOptions(context.Background(), targetPath, LockOptions{
+		WaitMessageInterval: 150 * time.Millisecond,
+		Description:         "Contoso.Lib 1.0.0",
+		Logger:              logger,
+	})
+	if err != nil {
+		t.Fatalf("acquireFileLockWithOptions() error = %v", err)
+	}
+	unlock2()
+
+	if waited < 400*time.Millisecond {
+		t.Errorf("waited = %s, want the time the lock was held", waited)
+	}
+	if n := len(logger.messages); n < 2 || n > 3 {
+		t.Errorf("got %d waiting messages, want one every 150ms: %v", n, logger.messages)
+	}
+	want := fmt.Sprintf("INFO: Waiting for another process to finish installing Contoso.Lib 1.0.0 (held by PID %d for ", os.Getpid())
+	for _, msg := range logger.messages {
+		if !strings.HasPrefix(msg, want) {
+			t.Errorf("message = %q, want prefix %q", msg, want)
+		}
+	}
+}
+
+func TestAcquireFileLockWithOptions_Timeout(t *testing.T) {
+	targetPath := filepath.Join(t.TempDir(), "timeout.nupkg")
+
+	unlock1, err := acquireFileLock(context.Background(), targetPath)
+	if err != nil {
+		t.Fatalf("acquireFileLock() error = %v", err)
+	}
+	defer unlock1()
+
+	_, waited, err := acquireFileLockWithOptions(context.Background(), targetPath, LockOptions{Timeout: 200 * time.Millisecond})
+	if err == nil {
+		t.Fatal("acquireFileLockWithOptions() should time out when the lock is held")
+	}
+	if want := fmt.Sprintf("held by PID %d", os.Getpid()); !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %v, want it to contain %q", err, want)
+	}
+	if waited < 200*time.Millisecond {
+		t.Errorf("waited = %s, want at least the timeout", waited)
+	}
+}
+
+func TestAcquireFileLockWithOptions_StaleLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows releases the locks of exited processes and doesn't let held lock files be removed")
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		t.Fatalf("Hostname() error = %v", err)
+	}
+
+	// The PID of a process that has exited
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("failed to run a process: %v", err)
+	}
+	deadPID := cmd.Process.Pid
+
+	tests := []struct {
+		name       string
+		owner      lockOwner
+		wantBroken bool
+	}{
+		{"dead process on this machine", lockOwner{PID: deadPID, Host: host, Acquired: time.Now().Add(-time.Minute)}, true},
+		{"live process on this machine", lockOwner{PID: os.Getpid(), Host: host, Acquired: time.Now().Add(-time.Hour)}, false},
+		{"old lock from another machine", lockOwner{PID: 4242, Host: "build-agent-7", Acquired: time.Now().Add(-time.Hour)}, true},
+		{"recent lock from another machine", lockOwner{PID: 4242, Host: "build-agent-7", Acquired: time.Now().Add(-time.Minute)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetPath := filepath.Join(t.TempDir(), "stale.nupkg")
+
+			// The lock is held, recording the test owner
+			holder, err := tryAcquireLock(targetPath + LockFileExtension)
+			if err != nil {
+				t.Fatalf("tryAcquireLock() error = %v", err)
+			}
+			defer releaseLock(holder)
+			data, _ := json.Marshal(tt.owner)
+			if _, err := holder.lockFile.WriteAt(data, 0); err != nil {
+				t.Fatalf("failed to write lock owner: %v", err)
+			}
+
+			logger := &extractorTestLogger{}
+			unlock, _, err := acquireFileLockWithOptions(context.Background(), targetPath, LockOptions{
+				Timeout:      time.Second,
+				StaleLockAge: 30 * time.Minute,
+				Logger:       logger,
+			})
+			if (err == nil) != tt.wantBroken {
+				t.Fatalf("acquireFileLockWithOptions() error = %v, want the lock broken: %v", err, tt.wantBroken)
+			}
+			if err != nil {
+				return
+			}
+			defer unlock()
+
+			want := fmt.Sprintf("WARN: Broke the stale lock on stale.nupkg held by PID %d on %s", tt.owner.PID, tt.owner.Host)
+			if len(logger.messages) != 1 || !strings.HasPrefix(logger.messages[0], want) {
+				t.Errorf("messages = %v, want a warning starting with %q", logger.messages, want)
+			}
+			if owner := readLockOwner(targetPath + LockFileExtension); owner == nil || owner.PID != os.Getpid() {
+				t.Errorf("lock owner = %+v, want this process", owner)
+			}
+		})
+	}
+}
+
 // Helper functions
 
 func contains(s, substr string) bool {
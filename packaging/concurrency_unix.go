@@ -37,10 +37,19 @@ func tryAcquireLock(lockFilePath string) (*FileLock, error) {
 }
 
 // releaseLock releases the file lock (Unix).
-// On Unix, we only close the file - DO NOT remove it.
+// On Unix, we only clear the recorded owner and close the file - DO NOT remove it.
 // Reference: NuGet.Client ConcurrencyUtilities.cs - DeleteOnClose causes
 // concurrency issues on Mac OS X and Linux, so lock files are NOT deleted.
 func releaseLock(lock *FileLock) {
+	_ = lock.lockFile.Truncate(0)
 	_ = lock.lockFile.Close()
 	// DO NOT remove lock file on Unix - this causes race conditions
 }
+
+// processAlive reports whether a process with the given PID exists (Unix).
+// Signal 0 checks for the process without signaling it; EPERM means it exists but
+// belongs to another user.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
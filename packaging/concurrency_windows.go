@@ -3,6 +3,7 @@
 package packaging
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"syscall"
@@ -23,7 +24,16 @@ const (
 	LOCKFILE_FAIL_IMMEDIATELY = 0x00000001
 
 	// Error codes
-	ERROR_LOCK_VIOLATION = 33
+	ERROR_LOCK_VIOLATION    = 33
+	ERROR_INVALID_PARAMETER = 87
+
+	// lockRegionOffsetHigh places the locked region at 4 GiB, past the owner recorded at
+	// the start of the file, so that waiting processes can read it
+	lockRegionOffsetHigh = 1
+
+	// OpenProcess access right and GetExitCodeProcess status
+	PROCESS_QUERY_LIMITED_INFORMATION = 0x1000
+	STILL_ACTIVE                      = 259
 )
 
 // tryAcquireLock attempts to acquire the file lock using LockFileEx (Windows).
@@ -40,21 +50,23 @@ func tryAcquireLock(lockFilePath string) (*FileLock, error) {
 	// Get the file handle
 	handle := syscall.Handle(lockFile.Fd())
 
-	// Prepare OVERLAPPED structure (required for LockFileEx, but we use zeros for synchronous)
-	var overlapped syscall.Overlapped
+	// Prepare OVERLAPPED structure (required for LockFileEx); it holds the offset of
+	// the locked region
+	overlapped := syscall.Overlapped{OffsetHigh: lockRegionOffsetHigh}
 
 	// Try to acquire exclusive lock (non-blocking)
 	// LOCKFILE_EXCLUSIVE_LOCK = exclusive lock
 	// LOCKFILE_FAIL_IMMEDIATELY = non-blocking (fail immediately if can't acquire)
 	flags := uint32(LOCKFILE_EXCLUSIVE_LOCK | LOCKFILE_FAIL_IMMEDIATELY)
 
-	// Lock the entire file (0xFFFFFFFF bytes from offset 0)
+	// Lock 4 GiB from the region offset. The region overlaps the whole-file lock of
+	// earlier versions, so processes using either still exclude each other.
 	r1, _, err := procLockFileEx.Call(
 		uintptr(handle),
 		uintptr(flags),
 		uintptr(0),          // reserved, must be 0
 		uintptr(0xFFFFFFFF), // number of bytes to lock (low)
-		uintptr(0xFFFFFFFF), // number of bytes to lock (high)
+		uintptr(0),          // number of bytes to lock (high)
 		uintptr(unsafe.Pointer(&overlapped)),
 	)
 
@@ -80,3 +92,20 @@ func releaseLock(lock *FileLock) {
 	_ = lock.lockFile.Close()
 	_ = os.Remove(lock.lockFilePath)
 }
+
+// processAlive reports whether a process with the given PID is running (Windows).
+// A process that can't be opened for another reason than not existing, such as
+// access denied, is considered alive.
+func processAlive(pid int) bool {
+	handle, err := syscall.OpenProcess(PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return !errors.Is(err, syscall.Errno(ERROR_INVALID_PARAMETER))
+	}
+	defer func() { _ = syscall.CloseHandle(handle) }()
+
+	var exitCode uint32
+	if err := syscall.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return true
+	}
+	return exitCode == STILL_ACTIVE
+}
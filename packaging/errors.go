@@ -10,11 +10,14 @@ var (
 	ErrInvalidPackage = errors.New("invalid package structure")
 
 	// ErrNuspecNotFound indicates no .nuspec file was found
-	ErrNuspecNotFound = errors.New("nuspec file not found")
+	ErrNuspecNotFound = errors.New("nuspec file does not exist in package")
 
 	// ErrMultipleNuspecs indicates multiple .nuspec files were found
-	ErrMultipleNuspecs = errors.New("multiple nuspec files found")
+	ErrMultipleNuspecs = errors.New("package contains multiple nuspec files")
 
 	// ErrInvalidPath indicates an invalid file path (e.g., path traversal)
 	ErrInvalidPath = errors.New("invalid file path")
+
+	// ErrPackageHashMismatch indicates the package content doesn't match the expected hash
+	ErrPackageHashMismatch = errors.New("package hash mismatch")
 )
@@ -3,6 +3,7 @@ package packaging
 
 import (
 	"context"
+	"time"
 
 	"github.com/willibrandon/gonuget/version"
 )
@@ -61,9 +62,18 @@ type PackageExtractionContext struct {
 	// SignatureVerifier for signed package validation (optional)
 	SignatureVerifier SignatureVerifier
 
-	// Logger for extraction progress (optional)
+	// Logger for extraction progress (optional). It also receives the messages logged
+	// while waiting for another process installing the same package.
 	Logger Logger
 
+	// LockTimeout is the maximum time to wait for another process installing the same
+	// package (DefaultLockTimeout when 0)
+	LockTimeout time.Duration
+
+	// OnLockAcquired is called with the time spent waiting for the package lock once
+	// it is acquired (optional)
+	OnLockAcquired func(waited time.Duration)
+
 	// ParentID for telemetry correlation (optional)
 	ParentID string
 }
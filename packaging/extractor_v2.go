@@ -209,8 +209,7 @@ func shouldExcludeFile(path string) bool {
 	}
 
 	// Exclude root-level .nupkg and .nuspec (extracted with proper names)
-	parts := strings.Split(path, "/")
-	if len(parts) == 1 {
+	if isRootFile(path) {
 		if strings.HasSuffix(lowerPath, ".nupkg") || strings.HasSuffix(lowerPath, ".nuspec") {
 			return true
 		}
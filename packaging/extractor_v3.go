@@ -3,11 +3,8 @@ package packaging
 import (
 	"context"
 	"crypto/rand"
-	"crypto/sha512"
-	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -38,11 +35,18 @@ func InstallFromSourceV3(
 
 	// Acquire file lock for concurrent safety
 	// Reference: ConcurrencyUtilities.ExecuteWithFileLockedAsync
-	unlock, err := acquireFileLock(ctx, targetNupkg)
+	unlock, waited, err := acquireFileLockWithOptions(ctx, targetNupkg, LockOptions{
+		Timeout:     extractionContext.LockTimeout,
+		Description: packageIdentity.ID + " " + packageIdentity.Version.String(),
+		Logger:      extractionContext.Logger,
+	})
 	if err != nil {
 		return false, fmt.Errorf("acquire file lock: %w", err)
 	}
 	defer unlock()
+	if extractionContext.OnLockAcquired != nil {
+		extractionContext.OnLockAcquired(waited)
+	}
 
 	// Double-check after acquiring lock
 	if _, err := os.Stat(metadataPath); err == nil {
@@ -160,13 +164,12 @@ func InstallFromSourceV3(
 	}
 
 	// Calculate package hash (SHA512 of entire nupkg) before closing reader
-	hash, err := calculateFileHash(targetTempNupkg)
+	packageHash, err = calculateFileHash(targetTempNupkg)
 	if err != nil {
 		_ = reader.Close()
 		cleanupPartialInstall(targetTempNupkg)
 		return false, fmt.Errorf("calculate hash: %w", err)
 	}
-	packageHash = base64.StdEncoding.EncodeToString(hash)
 
 	// Get content hash (for signed packages, hash of content excluding signature)
 	// Pass the temp nupkg path to calculate signed content hash if needed
@@ -242,20 +245,15 @@ func generateTempFileName() string {
 	return hex.EncodeToString(b)
 }
 
-// calculateFileHash calculates SHA512 hash of file.
-func calculateFileHash(path string) ([]byte, error) {
+// calculateFileHash calculates the base64 SHA512 hash of a .nupkg file.
+func calculateFileHash(path string) (string, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer func() { _ = file.Close() }()
 
-	hash := sha512.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return nil, err
-	}
-
-	return hash.Sum(nil), nil
+	return ComputePackageHash(file)
 }
 
 // cleanDirectory removes all contents of directory but keeps directory.
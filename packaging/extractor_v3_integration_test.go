@@ -574,3 +574,64 @@ func TestInstallFromSourceV3_ErrorPaths(t *testing.T) {
 		// This matches NuGet.Client's behavior where Directory.Delete() throws IOException
 	})
 }
+
+// TestInstallFromSourceV3_NuspecNameCasing tests that a nuspec whose file name doesn't
+// match the package id is extracted under the lowercase id, and that a nuspec in a
+// subfolder is extracted as a regular file
+func TestInstallFromSourceV3_NuspecNameCasing(t *testing.T) {
+	nuspec := `<?xml version="1.0" encoding="utf-8"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata>
+    <id>Crafted.Package</id>
+    <version>1.0.0</version>
+    <authors>gonuget</authors>
+    <description>Package with an unconventional nuspec name</description>
+  </metadata>
+</package>`
+	packagePath := filepath.Join(t.TempDir(), "Crafted.Package.1.0.0.nupkg")
+	if err := os.WriteFile(packagePath, createTestPackageBytes(t, map[string]string{
+		"CRAFTED.package.NuSpec":         nuspec,
+		"content/templates/x.nuspec":     "not the manifest",
+		"lib/net8.0/Crafted.Package.dll": "assembly",
+	}, false), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	identity := &PackageIdentity{ID: "Crafted.Package", Version: version.MustParse("1.0.0")}
+	resolver := NewVersionFolderPathResolver(filepath.Join(t.TempDir(), "global-packages"), true)
+	ctx := &PackageExtractionContext{
+		PackageSaveMode:    PackageSaveModeDefaultV3,
+		XMLDocFileSaveMode: XMLDocFileSaveModeNone,
+	}
+	copyToAsync := func(targetPath string) error {
+		data, err := os.ReadFile(packagePath)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(targetPath, data, 0o644)
+	}
+
+	if _, err := InstallFromSourceV3(context.Background(), packagePath, identity, copyToAsync, resolver, ctx); err != nil {
+		t.Fatalf("InstallFromSourceV3() error = %v", err)
+	}
+
+	packageDir := resolver.GetPackageDirectory(identity.ID, identity.Version)
+	entries, err := os.ReadDir(packageDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nuspecs []string
+	for _, e := range entries {
+		if strings.HasSuffix(strings.ToLower(e.Name()), ".nuspec") {
+			nuspecs = append(nuspecs, e.Name())
+		}
+	}
+	if len(nuspecs) != 1 || nuspecs[0] != "crafted.package.nuspec" {
+		t.Errorf("nuspec files in package folder = %v, want [crafted.package.nuspec]", nuspecs)
+	}
+
+	nested := filepath.Join(packageDir, "content", "templates", "x.nuspec")
+	if data, err := os.ReadFile(nested); err != nil || string(data) != "not the manifest" {
+		t.Errorf("nested nuspec not extracted as a regular file: %v", err)
+	}
+}
@@ -68,21 +68,36 @@ func IsAnalyzerFile(filePath string) bool {
 	return strings.HasPrefix(strings.ToLower(filePath), AnalyzersFolder)
 }
 
-// IsManifestFile checks if a file is a .nuspec manifest
+// IsManifestFile checks if a file is the package manifest: a .nuspec file, matched
+// case-insensitively, at the package root. A .nuspec in a subfolder is package content.
+// Reference: PackageHelper.IsManifest
 func IsManifestFile(filePath string) bool {
-	return strings.HasSuffix(strings.ToLower(filePath), ManifestExtension)
+	return isRootFile(filePath) && strings.HasSuffix(strings.ToLower(filePath), ManifestExtension)
+}
+
+// isRootFile checks if a zip entry is at the package root. Entries written with
+// Windows separators count as nested too.
+func isRootFile(filePath string) bool {
+	return !strings.ContainsAny(filePath, `/\`)
 }
 
 // IsPackageMetadataFile checks if a file is package metadata
 func IsPackageMetadataFile(filePath string) bool {
 	lower := strings.ToLower(filePath)
 	return lower == SignatureFile ||
-		strings.HasPrefix(lower, "_rels/") ||
-		lower == strings.ToLower(ContentTypesFile) ||
-		strings.HasPrefix(lower, PSMDCPFile) ||
+		IsOPCFile(filePath) ||
 		IsManifestFile(filePath)
 }
 
+// IsOPCFile checks if a file is Open Packaging Conventions metadata
+// (relationships, content types or core properties) rather than package content.
+func IsOPCFile(filePath string) bool {
+	lower := strings.ToLower(filePath)
+	return strings.HasPrefix(lower, "_rels/") ||
+		lower == strings.ToLower(ContentTypesFile) ||
+		strings.HasPrefix(lower, PSMDCPFile)
+}
+
 // GetFileExtension returns the file extension (lowercase, with dot)
 func GetFileExtension(filePath string) string {
 	ext := path.Ext(filePath)
@@ -48,6 +48,28 @@ func TestIsAnalyzerFile(t *testing.T) {
 	}
 }
 
+func TestIsManifestFile(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"root nuspec", "MyPackage.nuspec", true},
+		{"wrong-cased name", "mypackage.NuSpec", true},
+		{"nested nuspec", "content/MyPackage.nuspec", false},
+		{"nested with backslash", "content\\MyPackage.nuspec", false},
+		{"not a nuspec", "MyPackage.nuspec.xml", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsManifestFile(tt.path); got != tt.want {
+				t.Errorf("IsManifestFile(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestGetFileExtension(t *testing.T) {
 	tests := []struct {
 		name string
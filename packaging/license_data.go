@@ -0,0 +1,206 @@
+package packaging
+
+// SPDX license and exception identifiers accepted in <license type="expression">, taken
+// from the SPDX license list. Deprecated identifiers map to true: NuGet rejects them.
+// Reference: NuGetLicenseData in NuGet.Packaging
+
+// spdxLicenses maps each SPDX license identifier to whether it is deprecated
+var spdxLicenses = map[string]bool{
+	"0BSD": false, "3D-Slicer-1.0": false, "AAL": false, "ADSL": false, "AFL-1.1": false,
+	"AFL-1.2": false, "AFL-2.0": false, "AFL-2.1": false, "AFL-3.0": false, "AGPL-1.0-only": false,
+	"AGPL-1.0-or-later": false, "AGPL-3.0-only": false, "AGPL-3.0-or-later": false, "AMD-newlib": false,
+	"AMDPLPA": false, "AML": false, "AML-glslang": false, "AMPAS": false, "ANTLR-PD": false,
+	"ANTLR-PD-fallback": false, "APAFML": false, "APL-1.0": false, "APSL-1.0": false, "APSL-1.1": false,
+	"APSL-1.2": false, "APSL-2.0": false, "ASWF-Digital-Assets-1.0": false,
+	"ASWF-Digital-Assets-1.1": false, "Abstyles": false, "AdaCore-doc": false, "Adobe-2006": false,
+	"Adobe-Display-PostScript": false, "Adobe-Glyph": false, "Adobe-Utopia": false, "Afmparse": false,
+	"Aladdin": false, "Apache-1.0": false, "Apache-1.1": false, "Apache-2.0": false, "App-s2p": false,
+	"Arphic-1999": false, "Artistic-1.0": false, "Artistic-1.0-Perl": false, "Artistic-1.0-cl8": false,
+	"Artistic-2.0": false, "BSD-1-Clause": false, "BSD-2-Clause": false, "BSD-2-Clause-Darwin": false,
+	"BSD-2-Clause-Patent": false, "BSD-2-Clause-Views": false, "BSD-2-Clause-first-lines": false,
+	"BSD-3-Clause": false, "BSD-3-Clause-Attribution": false, "BSD-3-Clause-Clear": false,
+	"BSD-3-Clause-HP": false, "BSD-3-Clause-LBNL": false, "BSD-3-Clause-Modification": false,
+	"BSD-3-Clause-No-Military-License": false, "BSD-3-Clause-No-Nuclear-License": false,
+	"BSD-3-Clause-No-Nuclear-License-2014": false, "BSD-3-Clause-No-Nuclear-Warranty": false,
+	"BSD-3-Clause-Open-MPI": false, "BSD-3-Clause-Sun": false, "BSD-3-Clause-acpica": false,
+	"BSD-3-Clause-flex": false, "BSD-4-Clause": false, "BSD-4-Clause-Shortened": false,
+	"BSD-4-Clause-UC": false, "BSD-4.3RENO": false, "BSD-4.3TAHOE": false,
+	"BSD-Advertising-Acknowledgement": false, "BSD-Attribution-HPND-disclaimer": false,
+	"BSD-Inferno-Nettverk": false, "BSD-Protection": false, "BSD-Source-Code": false,
+	"BSD-Source-beginning-file": false, "BSD-Systemics": false, "BSD-Systemics-W3Works": false,
+	"BSL-1.0": false, "BUSL-1.1": false, "Baekmuk": false, "Bahyph": false, "Barr": false,
+	"Beerware": false, "BitTorrent-1.0": false, "BitTorrent-1.1": false, "Bitstream-Charter": false,
+	"Bitstream-Vera": false, "BlueOak-1.0.0": false, "Boehm-GC": false, "Borceux": false,
+	"Brian-Gladman-2-Clause": false, "Brian-Gladman-3-Clause": false, "C-UDA-1.0": false,
+	"CAL-1.0": false, "CAL-1.0-Combined-Work-Exception": false, "CATOSL-1.1": false, "CC-BY-1.0": false,
+	"CC-BY-2.0": false, "CC-BY-2.5": false, "CC-BY-2.5-AU": false, "CC-BY-3.0": false,
+	"CC-BY-3.0-AT": false, "CC-BY-3.0-AU": false, "CC-BY-3.0-DE": false, "CC-BY-3.0-IGO": false,
+	"CC-BY-3.0-NL": false, "CC-BY-3.0-US": false, "CC-BY-4.0": false, "CC-BY-NC-1.0": false,
+	"CC-BY-NC-2.0": false, "CC-BY-NC-2.5": false, "CC-BY-NC-3.0": false, "CC-BY-NC-3.0-DE": false,
+	"CC-BY-NC-4.0": false, "CC-BY-NC-ND-1.0": false, "CC-BY-NC-ND-2.0": false, "CC-BY-NC-ND-2.5": false,
+	"CC-BY-NC-ND-3.0": false, "CC-BY-NC-ND-3.0-DE": false, "CC-BY-NC-ND-3.0-IGO": false,
+	"CC-BY-NC-ND-4.0": false, "CC-BY-NC-SA-1.0": false, "CC-BY-NC-SA-2.0": false,
+	"CC-BY-NC-SA-2.0-DE": false, "CC-BY-NC-SA-2.0-FR": false, "CC-BY-NC-SA-2.0-UK": false,
+	"CC-BY-NC-SA-2.5": false, "CC-BY-NC-SA-3.0": false, "CC-BY-NC-SA-3.0-DE": false,
+	"CC-BY-NC-SA-3.0-IGO": false, "CC-BY-NC-SA-4.0": false, "CC-BY-ND-1.0": false,
+	"CC-BY-ND-2.0": false, "CC-BY-ND-2.5": false, "CC-BY-ND-3.0": false, "CC-BY-ND-3.0-DE": false,
+	"CC-BY-ND-4.0": false, "CC-BY-SA-1.0": false, "CC-BY-SA-2.0": false, "CC-BY-SA-2.0-UK": false,
+	"CC-BY-SA-2.1-JP": false, "CC-BY-SA-2.5": false, "CC-BY-SA-3.0": false, "CC-BY-SA-3.0-AT": false,
+	"CC-BY-SA-3.0-DE": false, "CC-BY-SA-3.0-IGO": false, "CC-BY-SA-4.0": false, "CC-PDDC": false,
+	"CC0-1.0": false, "CDDL-1.0": false, "CDDL-1.1": false, "CDL-1.0": false,
+	"CDLA-Permissive-1.0": false, "CDLA-Permissive-2.0": false, "CDLA-Sharing-1.0": false,
+	"CECILL-1.0": false, "CECILL-1.1": false, "CECILL-2.0": false, "CECILL-2.1": false,
+	"CECILL-B": false, "CECILL-C": false, "CERN-OHL-1.1": false, "CERN-OHL-1.2": false,
+	"CERN-OHL-P-2.0": false, "CERN-OHL-S-2.0": false, "CERN-OHL-W-2.0": false, "CFITSIO": false,
+	"CMU-Mach": false, "CMU-Mach-nodoc": false, "CNRI-Jython": false, "CNRI-Python": false,
+	"CNRI-Python-GPL-Compatible": false, "COIL-1.0": false, "CPAL-1.0": false, "CPL-1.0": false,
+	"CPOL-1.02": false, "CUA-OPL-1.0": false, "Caldera": false, "Caldera-no-preamble": false,
+	"Catharon": false, "ClArtistic": false, "Clips": false, "Community-Spec-1.0": false,
+	"Condor-1.1": false, "Cornell-Lossless-JPEG": false, "Cronyx": false, "Crossword": false,
+	"CrystalStacker": false, "Cube": false, "D-FSL-1.0": false, "DEC-3-Clause": false,
+	"DL-DE-BY-2.0": false, "DL-DE-ZERO-2.0": false, "DOC": false, "DRL-1.0": false, "DRL-1.1": false,
+	"DSDP": false, "Dotseqn": false, "ECL-1.0": false, "ECL-2.0": false, "EFL-1.0": false,
+	"EFL-2.0": false, "EPICS": false, "EPL-1.0": false, "EPL-2.0": false, "EUDatagrid": false,
+	"EUPL-1.0": false, "EUPL-1.1": false, "EUPL-1.2": false, "Elastic-2.0": false, "Entessa": false,
+	"ErlPL-1.1": false, "Eurosym": false, "FBM": false, "FDK-AAC": false, "FSFAP": false,
+	"FSFAP-no-warranty-disclaimer": false, "FSFUL": false, "FSFULLR": false, "FSFULLRWD": false,
+	"FTL": false, "Fair": false, "Ferguson-Twofish": false, "Frameworx-1.0": false,
+	"FreeBSD-DOC": false, "FreeImage": false, "Furuseth": false, "GCR-docs": false, "GD": false,
+	"GFDL-1.1-invariants-only": false, "GFDL-1.1-invariants-or-later": false,
+	"GFDL-1.1-no-invariants-only": false, "GFDL-1.1-no-invariants-or-later": false,
+	"GFDL-1.1-only": false, "GFDL-1.1-or-later": false, "GFDL-1.2-invariants-only": false,
+	"GFDL-1.2-invariants-or-later": false, "GFDL-1.2-no-invariants-only": false,
+	"GFDL-1.2-no-invariants-or-later": false, "GFDL-1.2-only": false, "GFDL-1.2-or-later": false,
+	"GFDL-1.3-invariants-only": false, "GFDL-1.3-invariants-or-later": false,
+	"GFDL-1.3-no-invariants-only": false, "GFDL-1.3-no-invariants-or-later": false,
+	"GFDL-1.3-only": false, "GFDL-1.3-or-later": false, "GL2PS": false, "GLWTPL": false,
+	"GPL-1.0-only": false, "GPL-1.0-or-later": false, "GPL-2.0-only": false, "GPL-2.0-or-later": false,
+	"GPL-3.0-only": false, "GPL-3.0-or-later": false, "Giftware": false, "Glide": false,
+	"Glulxe": false, "Graphics-Gems": false, "Gutmann": false, "HP-1986": false, "HP-1989": false,
+	"HPND": false, "HPND-DEC": false, "HPND-Fenneberg-Livingston": false, "HPND-INRIA-IMAG": false,
+	"HPND-Intel": false, "HPND-Kevlin-Henney": false, "HPND-MIT-disclaimer": false,
+	"HPND-Markus-Kuhn": false, "HPND-Pbmplus": false, "HPND-UC": false, "HPND-UC-export-US": false,
+	"HPND-doc": false, "HPND-doc-sell": false, "HPND-export-US": false,
+	"HPND-export-US-acknowledgement": false, "HPND-export-US-modify": false, "HPND-export2-US": false,
+	"HPND-merchantability-variant": false, "HPND-sell-MIT-disclaimer-xserver": false,
+	"HPND-sell-regexpr": false, "HPND-sell-variant": false, "HPND-sell-variant-MIT-disclaimer": false,
+	"HPND-sell-variant-MIT-disclaimer-rev": false, "HTMLTIDY": false, "HaskellReport": false,
+	"Hippocratic-2.1": false, "IBM-pibs": false, "ICU": false, "IEC-Code-Components-EULA": false,
+	"IJG": false, "IJG-short": false, "IPA": false, "IPL-1.0": false, "ISC": false,
+	"ISC-Veillard": false, "ImageMagick": false, "Imlib2": false, "Info-ZIP": false,
+	"Inner-Net-2.0": false, "Intel": false, "Intel-ACPI": false, "Interbase-1.0": false,
+	"JPL-image": false, "JPNIC": false, "JSON": false, "Jam": false, "JasPer-2.0": false,
+	"Kastrup": false, "Kazlib": false, "Knuth-CTAN": false, "LAL-1.2": false, "LAL-1.3": false,
+	"LGPL-2.0-only": false, "LGPL-2.0-or-later": false, "LGPL-2.1-only": false,
+	"LGPL-2.1-or-later": false, "LGPL-3.0-only": false, "LGPL-3.0-or-later": false, "LGPLLR": false,
+	"LOOP": false, "LPD-document": false, "LPL-1.0": false, "LPL-1.02": false, "LPPL-1.0": false,
+	"LPPL-1.1": false, "LPPL-1.2": false, "LPPL-1.3a": false, "LPPL-1.3c": false,
+	"LZMA-SDK-9.11-to-9.20": false, "LZMA-SDK-9.22": false, "Latex2e": false,
+	"Latex2e-translated-notice": false, "Leptonica": false, "LiLiQ-P-1.1": false, "LiLiQ-R-1.1": false,
+	"LiLiQ-Rplus-1.1": false, "Libpng": false, "Linux-OpenIB": false, "Linux-man-pages-1-para": false,
+	"Linux-man-pages-copyleft": false, "Linux-man-pages-copyleft-2-para": false,
+	"Linux-man-pages-copyleft-var": false, "Lucida-Bitmap-Fonts": false, "MIT": false, "MIT-0": false,
+	"MIT-CMU": false, "MIT-Festival": false, "MIT-Khronos-old": false, "MIT-Modern-Variant": false,
+	"MIT-Wu": false, "MIT-advertising": false, "MIT-enna": false, "MIT-feh": false,
+	"MIT-open-group": false, "MIT-testregex": false, "MITNFA": false, "MMIXware": false,
+	"MPEG-SSG": false, "MPL-1.0": false, "MPL-1.1": false, "MPL-2.0": false,
+	"MPL-2.0-no-copyleft-exception": false, "MS-LPL": false, "MS-PL": false, "MS-RL": false,
+	"MTLL": false, "Mackerras-3-Clause": false, "Mackerras-3-Clause-acknowledgment": false,
+	"MakeIndex": false, "Martin-Birgmeier": false, "McPhee-slideshow": false, "Minpack": false,
+	"MirOS": false, "Motosoto": false, "MulanPSL-1.0": false, "MulanPSL-2.0": false, "Multics": false,
+	"Mup": false, "NAIST-2003": false, "NASA-1.3": false, "NBPL-1.0": false, "NCBI-PD": false,
+	"NCGL-UK-2.0": false, "NCL": false, "NCSA": false, "NGPL": false, "NICTA-1.0": false,
+	"NIST-PD": false, "NIST-PD-fallback": false, "NIST-Software": false, "NLOD-1.0": false,
+	"NLOD-2.0": false, "NLPL": false, "NOSL": false, "NPL-1.0": false, "NPL-1.1": false,
+	"NPOSL-3.0": false, "NRL": false, "NTP": false, "NTP-0": false, "Naumen": false, "Net-SNMP": false,
+	"NetCDF": false, "Newsletr": false, "Nokia": false, "Noweb": false, "O-UDA-1.0": false,
+	"OAR": false, "OCCT-PL": false, "OCLC-2.0": false, "ODC-By-1.0": false, "ODbL-1.0": false,
+	"OFFIS": false, "OFL-1.0": false, "OFL-1.0-RFN": false, "OFL-1.0-no-RFN": false, "OFL-1.1": false,
+	"OFL-1.1-RFN": false, "OFL-1.1-no-RFN": false, "OGC-1.0": false, "OGDL-Taiwan-1.0": false,
+	"OGL-Canada-2.0": false, "OGL-UK-1.0": false, "OGL-UK-2.0": false, "OGL-UK-3.0": false,
+	"OGTSL": false, "OLDAP-1.1": false, "OLDAP-1.2": false, "OLDAP-1.3": false, "OLDAP-1.4": false,
+	"OLDAP-2.0": false, "OLDAP-2.0.1": false, "OLDAP-2.1": false, "OLDAP-2.2": false,
+	"OLDAP-2.2.1": false, "OLDAP-2.2.2": false, "OLDAP-2.3": false, "OLDAP-2.4": false,
+	"OLDAP-2.5": false, "OLDAP-2.6": false, "OLDAP-2.7": false, "OLDAP-2.8": false, "OLFL-1.3": false,
+	"OML": false, "OPL-1.0": false, "OPL-UK-3.0": false, "OPUBL-1.0": false, "OSET-PL-2.1": false,
+	"OSL-1.0": false, "OSL-1.1": false, "OSL-2.0": false, "OSL-2.1": false, "OSL-3.0": false,
+	"OpenPBS-2.3": false, "OpenSSL": false, "OpenSSL-standalone": false, "OpenVision": false,
+	"PADL": false, "PDDL-1.0": false, "PHP-3.0": false, "PHP-3.01": false, "PPL": false,
+	"PSF-2.0": false, "Parity-6.0.0": false, "Parity-7.0.0": false, "Pixar": false, "Plexus": false,
+	"PolyForm-Noncommercial-1.0.0": false, "PolyForm-Small-Business-1.0.0": false, "PostgreSQL": false,
+	"Python-2.0": false, "Python-2.0.1": false, "QPL-1.0": false, "QPL-1.0-INRIA-2004": false,
+	"Qhull": false, "RHeCos-1.1": false, "RPL-1.1": false, "RPL-1.5": false, "RPSL-1.0": false,
+	"RSA-MD": false, "RSCPL": false, "Rdisc": false, "Ruby": false, "SAX-PD": false,
+	"SAX-PD-2.0": false, "SCEA": false, "SGI-B-1.0": false, "SGI-B-1.1": false, "SGI-B-2.0": false,
+	"SGI-OpenGL": false, "SGP4": false, "SHL-0.5": false, "SHL-0.51": false, "SISSL": false,
+	"SISSL-1.2": false, "SL": false, "SMLNJ": false, "SMPPL": false, "SNIA": false, "SPL-1.0": false,
+	"SSH-OpenSSH": false, "SSH-short": false, "SSLeay-standalone": false, "SSPL-1.0": false,
+	"SWL": false, "Saxpath": false, "SchemeReport": false, "Sendmail": false, "Sendmail-8.23": false,
+	"SimPL-2.0": false, "Sleepycat": false, "Soundex": false, "Spencer-86": false, "Spencer-94": false,
+	"Spencer-99": false, "SugarCRM-1.1.3": false, "Sun-PPP": false, "Sun-PPP-2000": false,
+	"SunPro": false, "Symlinks": false, "TAPR-OHL-1.0": false, "TCL": false, "TCP-wrappers": false,
+	"TGPPL-1.0": false, "TMate": false, "TORQUE-1.1": false, "TOSL": false, "TPDL": false,
+	"TPL-1.0": false, "TTWL": false, "TTYP0": false, "TU-Berlin-1.0": false, "TU-Berlin-2.0": false,
+	"TermReadKey": false, "UCAR": false, "UCL-1.0": false, "UMich-Merit": false, "UPL-1.0": false,
+	"URT-RLE": false, "Unicode-3.0": false, "Unicode-DFS-2015": false, "Unicode-DFS-2016": false,
+	"Unicode-TOU": false, "UnixCrypt": false, "Unlicense": false, "VOSTROM": false, "VSL-1.0": false,
+	"Vim": false, "W3C": false, "W3C-19980720": false, "W3C-20150513": false, "WTFPL": false,
+	"Watcom-1.0": false, "Widget-Workshop": false, "Wsuipa": false, "X11": false,
+	"X11-distribute-modifications-variant": false, "XFree86-1.1": false, "XSkat": false,
+	"Xdebug-1.03": false, "Xerox": false, "Xfig": false, "Xnet": false, "YPL-1.0": false,
+	"YPL-1.1": false, "ZPL-1.1": false, "ZPL-2.0": false, "ZPL-2.1": false, "Zed": false,
+	"Zeeff": false, "Zend-2.0": false, "Zimbra-1.3": false, "Zimbra-1.4": false, "Zlib": false,
+	"any-OSI": false, "bcrypt-Solar-Designer": false, "blessing": false, "bzip2-1.0.6": false,
+	"check-cvs": false, "checkmk": false, "copyleft-next-0.3.0": false, "copyleft-next-0.3.1": false,
+	"curl": false, "cve-tou": false, "diffmark": false, "dtoa": false, "dvipdfm": false,
+	"eGenix": false, "etalab-2.0": false, "fwlw": false, "gSOAP-1.3b": false, "gnuplot": false,
+	"gtkbook": false, "hdparm": false, "iMatix": false, "libpng-2.0": false, "libselinux-1.0": false,
+	"libtiff": false, "libutil-David-Nugent": false, "lsof": false, "magaz": false, "mailprio": false,
+	"metamail": false, "mpi-permissive": false, "mpich2": false, "mplus": false, "pkgconf": false,
+	"pnmstitch": false, "psfrag": false, "psutils": false, "python-ldap": false, "radvd": false,
+	"snprintf": false, "softSurfer": false, "ssh-keyscan": false, "swrule": false,
+	"threeparttable": false, "ulem": false, "w3m": false, "xinetd": false,
+	"xkeyboard-config-Zinoviev": false, "xlock": false, "xpp": false, "xzoom": false,
+	"zlib-acknowledgement": false,
+
+	// Deprecated
+	"AGPL-1.0": true, "AGPL-3.0": true, "BSD-2-Clause-FreeBSD": true, "BSD-2-Clause-NetBSD": true,
+	"GFDL-1.1": true, "GFDL-1.2": true, "GFDL-1.3": true, "GPL-1.0": true, "GPL-2.0": true,
+	"GPL-2.0-with-GCC-exception": true, "GPL-2.0-with-autoconf-exception": true,
+	"GPL-2.0-with-bison-exception": true, "GPL-2.0-with-classpath-exception": true,
+	"GPL-2.0-with-font-exception": true, "GPL-3.0": true, "GPL-3.0-with-GCC-exception": true,
+	"GPL-3.0-with-autoconf-exception": true, "LGPL-2.0": true, "LGPL-2.1": true, "LGPL-3.0": true,
+	"Nunit": true, "StandardML-NJ": true, "bzip2-1.0.5": true, "eCos-2.0": true, "wxWindows": true,
+}
+
+// spdxExceptions maps each SPDX license exception identifier to whether it is deprecated
+var spdxExceptions = map[string]bool{
+	"389-exception": false, "Asterisk-exception": false, "Autoconf-exception-2.0": false,
+	"Autoconf-exception-3.0": false, "Autoconf-exception-generic": false,
+	"Autoconf-exception-generic-3.0": false, "Autoconf-exception-macro": false,
+	"Bison-exception-1.24": false, "Bison-exception-2.2": false, "Bootloader-exception": false,
+	"CLISP-exception-2.0": false, "Classpath-exception-2.0": false, "DigiRule-FOSS-exception": false,
+	"FLTK-exception": false, "Fawkes-Runtime-exception": false, "Font-exception-2.0": false,
+	"GCC-exception-2.0": false, "GCC-exception-2.0-note": false, "GCC-exception-3.1": false,
+	"GNAT-exception": false, "GNOME-examples-exception": false, "GNU-compiler-exception": false,
+	"GPL-3.0-interface-exception": false, "GPL-3.0-linking-exception": false,
+	"GPL-3.0-linking-source-exception": false, "GPL-CC-1.0": false, "GStreamer-exception-2005": false,
+	"GStreamer-exception-2008": false, "Gmsh-exception": false, "KiCad-libraries-exception": false,
+	"LGPL-3.0-linking-exception": false, "LLGPL": false, "LLVM-exception": false,
+	"LZMA-exception": false, "Libtool-exception": false, "Linux-syscall-note": false,
+	"OCCT-exception-1.0": false, "OCaml-LGPL-linking-exception": false,
+	"OpenJDK-assembly-exception-1.0": false, "PS-or-PDF-font-exception-20170817": false,
+	"QPL-1.0-INRIA-2004-exception": false, "Qt-GPL-exception-1.0": false,
+	"Qt-LGPL-exception-1.1": false, "Qwt-exception-1.0": false, "SANE-exception": false,
+	"SHL-2.0": false, "SHL-2.1": false, "SWI-exception": false, "Swift-exception": false,
+	"Texinfo-exception": false, "UBDL-exception": false, "Universal-FOSS-exception-1.0": false,
+	"WxWindows-exception-3.1": false, "cryptsetup-OpenSSL-exception": false,
+	"eCos-exception-2.0": false, "fmt-exception": false, "freertos-exception-2.0": false,
+	"gnu-javamail-exception": false, "i2p-gpl-java-exception": false,
+	"libpri-OpenH323-exception": false, "mif-exception": false, "openvpn-openssl-exception": false,
+	"stunnel-exception": false, "u-boot-exception-2.0": false, "vsftpd-openssl-exception": false,
+	"x11vnc-openssl-exception": false,
+
+	// Deprecated
+	"Nokia-Qt-exception-1.1": true,
+}
@@ -0,0 +1,43 @@
+package packaging
+
+// binarySniffLength is how much of a file is checked for NUL bytes to decide that
+// it is binary, as git does.
+const binarySniffLength = 8000
+
+// lineEndingDetector records the line endings of the content written to it.
+// Content with a NUL byte near the start is treated as binary and never reported.
+type lineEndingDetector struct {
+	written   int
+	binary    bool
+	prevCR    bool
+	sawCRLF   bool
+	sawLoneLF bool
+}
+
+// Write scans p for line endings. It never fails.
+func (d *lineEndingDetector) Write(p []byte) (int, error) {
+	for _, c := range p {
+		if d.binary {
+			break
+		}
+		if c == 0 && d.written < binarySniffLength {
+			d.binary = true
+			break
+		}
+		if c == '\n' {
+			if d.prevCR {
+				d.sawCRLF = true
+			} else {
+				d.sawLoneLF = true
+			}
+		}
+		d.prevCR = c == '\r'
+		d.written++
+	}
+	return len(p), nil
+}
+
+// Mixed reports whether text content used both CRLF and LF line endings.
+func (d *lineEndingDetector) Mixed() bool {
+	return !d.binary && d.sawCRLF && d.sawLoneLF
+}
@@ -15,7 +15,7 @@ type Nuspec struct {
 	XMLName  xml.Name       `xml:"package"`
 	Xmlns    string         `xml:"xmlns,attr,omitempty"`
 	Metadata NuspecMetadata `xml:"metadata"`
-	Files    []NuspecFile   `xml:"files>file"`
+	Files    []NuspecFile   `xml:"files>file,omitempty"`
 }
 
 // NuspecMetadata represents the metadata section.
@@ -27,62 +27,62 @@ type NuspecMetadata struct {
 	Authors     string `xml:"authors"`
 
 	// Optional fields
-	Title                    string           `xml:"title"`
-	Owners                   string           `xml:"owners"`
-	ProjectURL               string           `xml:"projectUrl"`
-	IconURL                  string           `xml:"iconUrl"`
-	Icon                     string           `xml:"icon"`
-	LicenseURL               string           `xml:"licenseUrl"`
-	License                  *LicenseMetadata `xml:"license"`
+	Title                    string           `xml:"title,omitempty"`
+	Owners                   string           `xml:"owners,omitempty"`
+	ProjectURL               string           `xml:"projectUrl,omitempty"`
+	IconURL                  string           `xml:"iconUrl,omitempty"`
+	Icon                     string           `xml:"icon,omitempty"`
+	LicenseURL               string           `xml:"licenseUrl,omitempty"`
+	License                  *LicenseMetadata `xml:"license,omitempty"`
 	RequireLicenseAcceptance bool             `xml:"requireLicenseAcceptance"`
-	DevelopmentDependency    bool             `xml:"developmentDependency"`
-	Summary                  string           `xml:"summary"`
-	ReleaseNotes             string           `xml:"releaseNotes"`
-	Copyright                string           `xml:"copyright"`
-	Language                 string           `xml:"language"`
-	Tags                     string           `xml:"tags"`
-	Serviceable              bool             `xml:"serviceable"`
-	Readme                   string           `xml:"readme"`
+	DevelopmentDependency    bool             `xml:"developmentDependency,omitempty"`
+	Summary                  string           `xml:"summary,omitempty"`
+	ReleaseNotes             string           `xml:"releaseNotes,omitempty"`
+	Copyright                string           `xml:"copyright,omitempty"`
+	Language                 string           `xml:"language,omitempty"`
+	Tags                     string           `xml:"tags,omitempty"`
+	Serviceable              bool             `xml:"serviceable,omitempty"`
+	Readme                   string           `xml:"readme,omitempty"`
 
 	// Version constraints
-	MinClientVersion string `xml:"minClientVersion,attr"`
+	MinClientVersion string `xml:"minClientVersion,attr,omitempty"`
 
 	// Complex elements
-	Dependencies        *DependenciesElement        `xml:"dependencies"`
-	FrameworkReferences *FrameworkReferencesElement `xml:"frameworkReferences"`
-	FrameworkAssemblies []FrameworkAssembly         `xml:"frameworkAssemblies>frameworkAssembly"`
-	References          *ReferencesElement          `xml:"references"`
-	ContentFiles        []ContentFilesEntry         `xml:"contentFiles>files"`
-	PackageTypes        []PackageType               `xml:"packageTypes>packageType"`
-	Repository          *RepositoryMetadata         `xml:"repository"`
+	Dependencies        *DependenciesElement        `xml:"dependencies,omitempty"`
+	FrameworkReferences *FrameworkReferencesElement `xml:"frameworkReferences,omitempty"`
+	FrameworkAssemblies []FrameworkAssembly         `xml:"frameworkAssemblies>frameworkAssembly,omitempty"`
+	References          *ReferencesElement          `xml:"references,omitempty"`
+	ContentFiles        []ContentFilesEntry         `xml:"contentFiles>files,omitempty"`
+	PackageTypes        []PackageType               `xml:"packageTypes>packageType,omitempty"`
+	Repository          *RepositoryMetadata         `xml:"repository,omitempty"`
 }
 
 // LicenseMetadata represents license information.
 type LicenseMetadata struct {
-	Type    string `xml:"type,attr"`    // "expression" or "file"
-	Version string `xml:"version,attr"` // SPDX license version
-	Text    string `xml:",chardata"`    // License expression or file path
+	Type    string `xml:"type,attr"`              // "expression" or "file"
+	Version string `xml:"version,attr,omitempty"` // SPDX license version
+	Text    string `xml:",chardata"`              // License expression or file path
 }
 
 // DependenciesElement represents the dependencies container.
 type DependenciesElement struct {
-	Groups []DependencyGroup `xml:"group"`
+	Groups []DependencyGroup `xml:"group,omitempty"`
 	// Legacy: dependencies without groups (applies to all frameworks)
-	Dependencies []Dependency `xml:"dependency"`
+	Dependencies []Dependency `xml:"dependency,omitempty"`
 }
 
 // DependencyGroup represents dependencies for a specific framework.
 type DependencyGroup struct {
-	TargetFramework string       `xml:"targetFramework,attr"`
+	TargetFramework string       `xml:"targetFramework,attr,omitempty"`
 	Dependencies    []Dependency `xml:"dependency"`
 }
 
 // Dependency represents a package dependency.
 type Dependency struct {
 	ID      string `xml:"id,attr"`
-	Version string `xml:"version,attr"` // Version range string
-	Include string `xml:"include,attr"` // Asset include filter
-	Exclude string `xml:"exclude,attr"` // Asset exclude filter
+	Version string `xml:"version,attr,omitempty"` // Version range string
+	Include string `xml:"include,attr,omitempty"` // Asset include filter
+	Exclude string `xml:"exclude,attr,omitempty"` // Asset exclude filter
 }
 
 // FrameworkReferencesElement represents framework references container.
@@ -135,15 +135,15 @@ type ContentFilesEntry struct {
 // PackageType represents the type of package.
 type PackageType struct {
 	Name    string `xml:"name,attr"`
-	Version string `xml:"version,attr"`
+	Version string `xml:"version,attr,omitempty"`
 }
 
 // RepositoryMetadata represents repository information.
 type RepositoryMetadata struct {
 	Type   string `xml:"type,attr"`
 	URL    string `xml:"url,attr"`
-	Branch string `xml:"branch,attr"`
-	Commit string `xml:"commit,attr"`
+	Branch string `xml:"branch,attr,omitempty"`
+	Commit string `xml:"commit,attr,omitempty"`
 }
 
 // NuspecFile represents a file entry in the nuspec.
@@ -154,6 +154,8 @@ type NuspecFile struct {
 }
 
 // ParseNuspec parses a .nuspec XML document.
+// Every nuspec schema namespace, and a document without one, maps to the same model;
+// SchemaNamespace reports which one the document declared.
 func ParseNuspec(r io.Reader) (*Nuspec, error) {
 	decoder := xml.NewDecoder(r)
 
@@ -165,6 +167,18 @@ func ParseNuspec(r io.Reader) (*Nuspec, error) {
 	return &nuspec, nil
 }
 
+// SchemaNamespace returns the known nuspec schema namespace declared by the document,
+// or "" when it declares none or an unknown one.
+// Reference: ManifestSchemaUtility.IsKnownSchema in NuGet.Client
+func (n *Nuspec) SchemaNamespace() string {
+	for _, ns := range nuspecNamespaces {
+		if strings.EqualFold(n.XMLName.Space, ns) {
+			return ns
+		}
+	}
+	return ""
+}
+
 // GetParsedIdentity returns the package identity from nuspec.
 func (n *Nuspec) GetParsedIdentity() (*PackageIdentity, error) {
 	ver, err := version.Parse(n.Metadata.Version)
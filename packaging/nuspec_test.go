@@ -1,6 +1,7 @@
 package packaging
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -136,6 +137,69 @@ func TestParseNuspec_Complete(t *testing.T) {
 	}
 }
 
+func TestParseNuspec_SchemaNamespaces(t *testing.T) {
+	const body = `
+  <metadata minClientVersion="2.5">
+    <id>TestPackage</id>
+    <version>1.0.0-beta</version>
+    <description>A test package</description>
+    <authors>Author1, Author2</authors>
+    <copyright>Copyright 2025</copyright>
+    <dependencies>
+      <group targetFramework="net45">
+        <dependency id="Dep" version="1.0.0" />
+      </group>
+    </dependencies>
+  </metadata>
+</package>`
+
+	// Hand-written nuspecs sometimes leave out the namespace
+	documents := map[string]string{"": `<?xml version="1.0"?>
+<package>` + body}
+	for _, ns := range nuspecNamespaces {
+		documents[ns] = `<?xml version="1.0"?>
+<package xmlns="` + ns + `">` + body
+	}
+
+	want, err := ParseNuspec(strings.NewReader(documents[NuspecNamespaceV6]))
+	if err != nil {
+		t.Fatalf("ParseNuspec() error = %v", err)
+	}
+
+	for ns, doc := range documents {
+		t.Run(nuspecSchemaVersion(ns), func(t *testing.T) {
+			nuspec, err := ParseNuspec(strings.NewReader(doc))
+			if err != nil {
+				t.Fatalf("ParseNuspec() error = %v", err)
+			}
+
+			if got := nuspec.SchemaNamespace(); got != ns {
+				t.Errorf("SchemaNamespace() = %q, want %q", got, ns)
+			}
+			if !reflect.DeepEqual(nuspec.Metadata, want.Metadata) {
+				t.Errorf("Metadata = %+v, want %+v", nuspec.Metadata, want.Metadata)
+			}
+
+			groups, err := nuspec.GetDependencyGroups()
+			if err != nil {
+				t.Fatalf("GetDependencyGroups() error = %v", err)
+			}
+			if len(groups) != 1 || len(groups[0].Dependencies) != 1 || groups[0].Dependencies[0].ID != "Dep" {
+				t.Errorf("GetDependencyGroups() = %+v, want one group with Dep", groups)
+			}
+		})
+	}
+
+	// An unknown namespace still parses but is not reported as a nuspec schema
+	nuspec, err := ParseNuspec(strings.NewReader(`<package xmlns="http://example.com/nuspec.xsd">` + body))
+	if err != nil {
+		t.Fatalf("ParseNuspec() error = %v", err)
+	}
+	if nuspec.SchemaNamespace() != "" || nuspec.Metadata.ID != "TestPackage" {
+		t.Errorf("SchemaNamespace() = %q, ID = %q", nuspec.SchemaNamespace(), nuspec.Metadata.ID)
+	}
+}
+
 func TestParseNuspec_InvalidXML(t *testing.T) {
 	xml := `<?xml version="1.0"?><invalid>`
 
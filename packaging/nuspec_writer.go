@@ -30,13 +30,69 @@ const (
 	NuspecNamespaceV6 = "http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd"
 )
 
+// nuspecNamespaces lists the known schema namespaces from oldest to newest.
+var nuspecNamespaces = []string{
+	NuspecNamespaceV1,
+	NuspecNamespaceV2,
+	NuspecNamespaceV3,
+	NuspecNamespaceV4,
+	NuspecNamespaceV5,
+	NuspecNamespaceV6,
+}
+
+// ResolveNuspecNamespace maps a schema version ("2012/06") or a full namespace URI
+// to one of the known nuspec namespaces.
+func ResolveNuspecNamespace(schema string) (string, error) {
+	schema = strings.TrimSpace(schema)
+	for _, ns := range nuspecNamespaces {
+		if strings.EqualFold(schema, ns) || schema == nuspecSchemaVersion(ns) {
+			return ns, nil
+		}
+	}
+	return "", fmt.Errorf("unknown nuspec schema %q", schema)
+}
+
+// nuspecSchemaVersion returns the "yyyy/mm" part of a nuspec namespace.
+func nuspecSchemaVersion(namespace string) string {
+	version := strings.TrimPrefix(namespace, "http://schemas.microsoft.com/packaging/")
+	return strings.TrimSuffix(version, "/nuspec.xsd")
+}
+
+// nuspecSchemaLevel returns the position of a namespace in nuspecNamespaces (1-based), or 0 if unknown.
+func nuspecSchemaLevel(namespace string) int {
+	for i, ns := range nuspecNamespaces {
+		if ns == namespace {
+			return i + 1
+		}
+	}
+	return 0
+}
+
 // GenerateNuspecXML generates nuspec XML from package metadata.
+// The schema namespace is picked from the features used by the metadata.
 func GenerateNuspecXML(metadata PackageMetadata) ([]byte, error) {
-	// Determine schema version based on features used
-	namespace := determineNuspecNamespace(metadata)
+	return GenerateNuspecXMLForSchema(metadata, "")
+}
 
-	// Build nuspec structure
-	nuspec := buildNuspecStructure(metadata, namespace)
+// GenerateNuspecXMLForSchema generates nuspec XML using the given schema namespace.
+// Descriptive elements the schema doesn't define (license, repository, icon, ...) are left out;
+// metadata that changes how the package is consumed and can't be expressed is an error.
+// An empty namespace selects the schema from the features used, like GenerateNuspecXML.
+func GenerateNuspecXMLForSchema(metadata PackageMetadata, namespace string) ([]byte, error) {
+	var nuspec *Nuspec
+	if namespace == "" {
+		// Determine schema version based on features used
+		nuspec = buildNuspecStructure(metadata, determineNuspecNamespace(metadata))
+	} else {
+		if nuspecSchemaLevel(namespace) == 0 {
+			return nil, fmt.Errorf("unknown nuspec schema namespace %q", namespace)
+		}
+		if err := checkNuspecSchemaSupport(metadata, namespace); err != nil {
+			return nil, err
+		}
+		nuspec = buildNuspecStructure(metadata, namespace)
+		restrictToNuspecSchema(nuspec, namespace)
+	}
 
 	// Encode to XML
 	var buf strings.Builder
@@ -52,40 +108,142 @@ func GenerateNuspecXML(metadata PackageMetadata) ([]byte, error) {
 	return []byte(buf.String()), nil
 }
 
-// determineNuspecNamespace inspects metadata to determine minimum required schema version
+// determineNuspecNamespace returns the oldest schema namespace that can express the metadata,
+// so that servers validating the namespace accept packages that don't need newer features.
 // Reference: ManifestVersionUtility.GetManifestVersion in NuGet.Client
 func determineNuspecNamespace(metadata PackageMetadata) string {
 	// Check for features requiring newer schema versions (check newest first)
+	switch {
+	// V6 (2013/05): Elements added after the 2013/01 schema
+	case requiresLatestNuspecSchema(metadata):
+		return NuspecNamespaceV6
 
-	// V5 (2013/01): References with target frameworks
-	if hasReferencesWithTargetFramework(metadata) {
+	// V5 (2013/01): References with target frameworks and minClientVersion
+	case len(metadata.FrameworkReferenceGroups) > 0 || metadata.MinClientVersion != nil:
 		return NuspecNamespaceV5
-	}
 
 	// V4 (2012/06): Dependencies with target frameworks
-	if hasDependenciesWithTargetFramework(metadata) {
+	case hasDependenciesWithTargetFramework(metadata):
 		return NuspecNamespaceV4
-	}
 
 	// V3 (2011/10): Prerelease/semantic versions
-	if metadata.Version != nil && metadata.Version.IsPrerelease() {
+	case metadata.Version != nil && metadata.Version.IsPrerelease():
 		return NuspecNamespaceV3
+
+	// V2 (2011/08): Copyright and release notes
+	case metadata.Copyright != "" || metadata.ReleaseNotes != "":
+		return NuspecNamespaceV2
 	}
 
-	// V6 (2013/05): Default to most recent for best compatibility
-	return NuspecNamespaceV6
+	// V1 (2010/07): Everything else is part of the baseline schema
+	return NuspecNamespaceV1
 }
 
-func hasReferencesWithTargetFramework(metadata PackageMetadata) bool {
-	// Check if any reference groups have specific target frameworks
-	for _, group := range metadata.FrameworkReferenceGroups {
-		if group.TargetFramework != nil && !group.TargetFramework.IsAny() {
-			return true
+// requiresLatestNuspecSchema reports whether the metadata uses elements only the 2013/05 schema defines.
+func requiresLatestNuspecSchema(metadata PackageMetadata) bool {
+	if metadata.LicenseMetadata != nil || metadata.Repository != nil ||
+		metadata.Icon != "" || metadata.Readme != "" ||
+		metadata.Serviceable || metadata.DevelopmentDependency ||
+		len(metadata.PackageTypes) > 0 {
+		return true
+	}
+	for _, group := range metadata.DependencyGroups {
+		for _, dep := range group.Dependencies {
+			if len(dep.Include) > 0 || len(dep.Exclude) > 0 {
+				return true
+			}
 		}
 	}
 	return false
 }
 
+// determineNuspecNamespaceForFiles returns the oldest schema namespace for the package files,
+// or "" when any schema will do. contentFiles and XDT transforms need the 2013/05 schema.
+// Reference: PackageBuilder.DetermineMinimumSchemaVersion in NuGet.Client
+func determineNuspecNamespaceForFiles(files []PackageFile) string {
+	for _, file := range files {
+		target := strings.ToLower(strings.ReplaceAll(file.TargetPath, "\\", "/"))
+		if strings.HasPrefix(target, "contentfiles/") ||
+			strings.HasSuffix(target, ".install.xdt") || strings.HasSuffix(target, ".uninstall.xdt") {
+			return NuspecNamespaceV6
+		}
+	}
+	return ""
+}
+
+// checkNuspecSchemaSupport reports metadata that the pinned schema can't represent.
+// Reference: ManifestVersionUtility in NuGet.Client
+func checkNuspecSchemaSupport(metadata PackageMetadata, namespace string) error {
+	level := nuspecSchemaLevel(namespace)
+	unsupported := func(feature, required string) error {
+		return fmt.Errorf("nuspec schema %s does not support %s (requires %s or later)",
+			nuspecSchemaVersion(namespace), feature, nuspecSchemaVersion(required))
+	}
+
+	if level < nuspecSchemaLevel(NuspecNamespaceV3) && metadata.Version != nil && metadata.Version.IsPrerelease() {
+		return unsupported("prerelease versions", NuspecNamespaceV3)
+	}
+	if level < nuspecSchemaLevel(NuspecNamespaceV4) && hasDependenciesWithTargetFramework(metadata) {
+		return unsupported("framework-specific dependency groups", NuspecNamespaceV4)
+	}
+	if level < nuspecSchemaLevel(NuspecNamespaceV5) {
+		if metadata.MinClientVersion != nil {
+			return unsupported("minClientVersion", NuspecNamespaceV5)
+		}
+		if len(metadata.FrameworkReferenceGroups) > 0 {
+			return unsupported("frameworkReferences", NuspecNamespaceV5)
+		}
+	}
+	if level < nuspecSchemaLevel(NuspecNamespaceV6) {
+		if len(metadata.PackageTypes) > 0 {
+			return unsupported("packageTypes", NuspecNamespaceV6)
+		}
+		for _, group := range metadata.DependencyGroups {
+			for _, dep := range group.Dependencies {
+				if len(dep.Include) > 0 || len(dep.Exclude) > 0 {
+					return unsupported("dependency include/exclude", NuspecNamespaceV6)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// restrictToNuspecSchema removes elements that the schema doesn't define.
+// Reference: nuspec.xsd files shipped in NuGet.Client
+func restrictToNuspecSchema(nuspec *Nuspec, namespace string) {
+	level := nuspecSchemaLevel(namespace)
+	md := &nuspec.Metadata
+
+	// Schemas before 2013/05 predate these elements
+	if level < nuspecSchemaLevel(NuspecNamespaceV6) {
+		md.License = nil
+		md.Repository = nil
+		md.Icon = ""
+		md.Readme = ""
+		md.Serviceable = false
+		md.DevelopmentDependency = false
+	}
+
+	// Dependency groups were added in 2012/06; older schemas only take a flat list
+	if level < nuspecSchemaLevel(NuspecNamespaceV4) && md.Dependencies != nil {
+		for _, group := range md.Dependencies.Groups {
+			md.Dependencies.Dependencies = append(md.Dependencies.Dependencies, group.Dependencies...)
+		}
+		md.Dependencies.Groups = nil
+		if len(md.Dependencies.Dependencies) == 0 {
+			md.Dependencies = nil
+		}
+	}
+
+	// The baseline schema has no copyright or release notes
+	if level < nuspecSchemaLevel(NuspecNamespaceV2) {
+		md.Copyright = ""
+		md.ReleaseNotes = ""
+	}
+}
+
 func hasDependenciesWithTargetFramework(metadata PackageMetadata) bool {
 	// Check if any dependency groups have specific target frameworks
 	for _, group := range metadata.DependencyGroups {
@@ -47,10 +47,10 @@ func TestGenerateNuspecXML_Minimal(t *testing.T) {
 	}
 
 	// Verify namespace (checking for xmlns attribute in the package tag)
-	// Minimal package with stable version should use V6 (most recent/default)
-	expectedNamespace := `xmlns="` + NuspecNamespaceV6 + `"`
+	// Minimal package with stable version only needs the baseline schema
+	expectedNamespace := `xmlns="` + NuspecNamespaceV1 + `"`
 	if !strings.Contains(xmlStr, expectedNamespace) {
-		t.Errorf("XML should contain v6 namespace: %s\nGot XML:\n%s", NuspecNamespaceV6, xmlStr)
+		t.Errorf("XML should contain v1 namespace: %s\nGot XML:\n%s", NuspecNamespaceV1, xmlStr)
 	}
 }
 
@@ -451,14 +451,26 @@ func TestDetermineNuspecNamespace(t *testing.T) {
 		expected string
 	}{
 		{
-			name: "V6 default - stable version, no frameworks",
+			name: "V1 default - stable version, no frameworks",
 			metadata: PackageMetadata{
 				ID:          "TestPackage",
 				Version:     version.MustParse("1.0.0"),
 				Description: "Test",
 				Authors:     []string{"Test Author"},
 			},
-			expected: NuspecNamespaceV6,
+			expected: NuspecNamespaceV1,
+		},
+		{
+			name: "V2 - copyright and release notes",
+			metadata: PackageMetadata{
+				ID:           "TestPackage",
+				Version:      version.MustParse("1.0.0"),
+				Description:  "Test",
+				Authors:      []string{"Test Author"},
+				Copyright:    "Copyright 2025",
+				ReleaseNotes: "Notes",
+			},
+			expected: NuspecNamespaceV2,
 		},
 		{
 			name: "V3 - prerelease version",
@@ -526,6 +538,55 @@ func TestDetermineNuspecNamespace(t *testing.T) {
 			},
 			expected: NuspecNamespaceV5,
 		},
+		{
+			name: "V5 - minClientVersion",
+			metadata: PackageMetadata{
+				ID:               "TestPackage",
+				Version:          version.MustParse("1.0.0-beta"),
+				Description:      "Test",
+				Authors:          []string{"Test Author"},
+				MinClientVersion: version.MustParse("3.3.0"),
+			},
+			expected: NuspecNamespaceV5,
+		},
+		{
+			name: "V6 - license expression",
+			metadata: PackageMetadata{
+				ID:              "TestPackage",
+				Version:         version.MustParse("1.0.0"),
+				Description:     "Test",
+				Authors:         []string{"Test Author"},
+				LicenseMetadata: &LicenseMetadata{Type: "expression", Text: "MIT"},
+			},
+			expected: NuspecNamespaceV6,
+		},
+		{
+			name: "V6 - package types",
+			metadata: PackageMetadata{
+				ID:           "TestPackage",
+				Version:      version.MustParse("1.0.0"),
+				Description:  "Test",
+				Authors:      []string{"Test Author"},
+				PackageTypes: []PackageTypeInfo{{Name: PackageTypeDotnetTool}},
+			},
+			expected: NuspecNamespaceV6,
+		},
+		{
+			name: "V6 overrides V4 - dependency include/exclude",
+			metadata: PackageMetadata{
+				ID:          "TestPackage",
+				Version:     version.MustParse("1.0.0"),
+				Description: "Test",
+				Authors:     []string{"Test Author"},
+				DependencyGroups: []PackageDependencyGroup{
+					{
+						TargetFramework: frameworks.MustParseFramework("net6.0"),
+						Dependencies:    []PackageDependency{{ID: "Package1", Exclude: []string{"Build"}}},
+					},
+				},
+			},
+			expected: NuspecNamespaceV6,
+		},
 	}
 
 	for _, tt := range tests {
@@ -534,6 +595,19 @@ func TestDetermineNuspecNamespace(t *testing.T) {
 			if namespace != tt.expected {
 				t.Errorf("determineNuspecNamespace() = %s, want %s", namespace, tt.expected)
 			}
+
+			// The chosen schema can express everything, so pinning it changes nothing
+			got, err := GenerateNuspecXML(tt.metadata)
+			if err != nil {
+				t.Fatalf("GenerateNuspecXML() error = %v", err)
+			}
+			pinned, err := GenerateNuspecXMLForSchema(tt.metadata, namespace)
+			if err != nil {
+				t.Fatalf("GenerateNuspecXMLForSchema() error = %v", err)
+			}
+			if string(got) != string(pinned) {
+				t.Errorf("pinning %s changed the nuspec:\n%s\nwant:\n%s", namespace, pinned, got)
+			}
 		})
 	}
 }
@@ -572,3 +646,147 @@ func TestBuildNuspecStructure(t *testing.T) {
 		t.Errorf("Metadata.Authors = %s, want Author1, Author2", nuspec.Metadata.Authors)
 	}
 }
+
+func TestResolveNuspecNamespace(t *testing.T) {
+	tests := []struct {
+		schema  string
+		want    string
+		wantErr bool
+	}{
+		{schema: "2013/05", want: NuspecNamespaceV6},
+		{schema: "2012/06", want: NuspecNamespaceV4},
+		{schema: "2011/08", want: NuspecNamespaceV2},
+		{schema: NuspecNamespaceV1, want: NuspecNamespaceV1},
+		{schema: "2014/01", wantErr: true},
+		{schema: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			got, err := ResolveNuspecNamespace(tt.schema)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveNuspecNamespace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveNuspecNamespace() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateNuspecXMLForSchema_OmitsNewerElements(t *testing.T) {
+	metadata := PackageMetadata{
+		ID:              "TestPackage",
+		Version:         version.MustParse("1.0.0"),
+		Description:     "Test",
+		Authors:         []string{"Test Author"},
+		Copyright:       "Copyright 2025",
+		ReleaseNotes:    "Notes",
+		Icon:            "icon.png",
+		Readme:          "README.md",
+		Serviceable:     true,
+		LicenseMetadata: &LicenseMetadata{Type: "expression", Text: "MIT"},
+		Repository:      &PackageRepositoryMetadata{Type: "git", URL: "https://github.com/test/repo"},
+		DependencyGroups: []PackageDependencyGroup{
+			{
+				Dependencies: []PackageDependency{{ID: "Dep", VersionRange: version.MustParseRange("1.0.0")}},
+			},
+		},
+	}
+
+	tests := []struct {
+		namespace  string
+		contains   []string
+		notContain []string
+	}{
+		{
+			namespace:  NuspecNamespaceV6,
+			contains:   []string{"<license", "<repository", "<icon>", "<readme>", "<serviceable>", "<group>"},
+			notContain: []string{"minClientVersion"},
+		},
+		{
+			namespace:  NuspecNamespaceV4,
+			contains:   []string{"<copyright>", "<releaseNotes>", "<group>"},
+			notContain: []string{"<license", "<repository", "<icon>", "<readme>", "<serviceable>"},
+		},
+		{
+			namespace:  NuspecNamespaceV2,
+			contains:   []string{"<copyright>", `<dependency id="Dep"`},
+			notContain: []string{"<group", "<license", "<repository"},
+		},
+		{
+			namespace:  NuspecNamespaceV1,
+			contains:   []string{`<dependency id="Dep"`},
+			notContain: []string{"<copyright>", "<releaseNotes>", "<group"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(nuspecSchemaVersion(tt.namespace), func(t *testing.T) {
+			xmlBytes, err := GenerateNuspecXMLForSchema(metadata, tt.namespace)
+			if err != nil {
+				t.Fatalf("GenerateNuspecXMLForSchema() error = %v", err)
+			}
+			xmlStr := string(xmlBytes)
+
+			if !strings.Contains(xmlStr, `xmlns="`+tt.namespace+`"`) {
+				t.Errorf("XML should use namespace %s\nGot XML:\n%s", tt.namespace, xmlStr)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(xmlStr, s) {
+					t.Errorf("XML should contain %q\nGot XML:\n%s", s, xmlStr)
+				}
+			}
+			for _, s := range tt.notContain {
+				if strings.Contains(xmlStr, s) {
+					t.Errorf("XML should not contain %q\nGot XML:\n%s", s, xmlStr)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateNuspecXMLForSchema_UnsupportedFeatures(t *testing.T) {
+	base := func() PackageMetadata {
+		return PackageMetadata{
+			ID:          "TestPackage",
+			Version:     version.MustParse("1.0.0"),
+			Description: "Test",
+			Authors:     []string{"Test Author"},
+		}
+	}
+
+	prerelease := base()
+	prerelease.Version = version.MustParse("1.0.0-beta")
+
+	tfmDeps := base()
+	tfmDeps.DependencyGroups = []PackageDependencyGroup{
+		{TargetFramework: frameworks.MustParseFramework("net6.0"), Dependencies: []PackageDependency{{ID: "Dep"}}},
+	}
+
+	packageTypes := base()
+	packageTypes.PackageTypes = []PackageTypeInfo{{Name: PackageTypeDotnetTool}}
+
+	tests := []struct {
+		name      string
+		metadata  PackageMetadata
+		namespace string
+		wantErr   bool
+	}{
+		{name: "prerelease on 2011/08", metadata: prerelease, namespace: NuspecNamespaceV2, wantErr: true},
+		{name: "prerelease on 2011/10", metadata: prerelease, namespace: NuspecNamespaceV3},
+		{name: "tfm dependencies on 2011/10", metadata: tfmDeps, namespace: NuspecNamespaceV3, wantErr: true},
+		{name: "tfm dependencies on 2012/06", metadata: tfmDeps, namespace: NuspecNamespaceV4},
+		{name: "package types on 2013/01", metadata: packageTypes, namespace: NuspecNamespaceV5, wantErr: true},
+		{name: "unknown namespace", metadata: base(), namespace: "http://example.com/nuspec.xsd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateNuspecXMLForSchema(tt.metadata, tt.namespace)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GenerateNuspecXMLForSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
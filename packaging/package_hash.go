@@ -0,0 +1,35 @@
+package packaging
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ComputePackageHash returns the base64-encoded SHA512 of the raw .nupkg bytes read
+// from r. This is the value NuGet writes to {id}.{version}.nupkg.sha512 and records as
+// the contentHash of an unsigned package in packages.lock.json.
+// Reference: PackageExtractor.CopySatelliteFilesAsync / ProcessStreamAndGetHash
+func ComputePackageHash(r io.Reader) (string, error) {
+	hash := sha512.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", fmt.Errorf("calculate package hash: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyPackageHash computes the hash of the .nupkg read from r and compares it with
+// expected, a base64 SHA512 such as a feed or lock file records. A different hash
+// returns an error wrapping ErrPackageHashMismatch.
+func VerifyPackageHash(r io.Reader, expected string) error {
+	actual, err := ComputePackageHash(r)
+	if err != nil {
+		return err
+	}
+	if actual != strings.TrimSpace(expected) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrPackageHashMismatch, strings.TrimSpace(expected), actual)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package packaging
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+func TestComputePackageHash(t *testing.T) {
+	data := []byte("PK\x03\x04 not really a package")
+	sum := sha512.Sum512(data)
+	want := base64.StdEncoding.EncodeToString(sum[:])
+
+	got, err := ComputePackageHash(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ComputePackageHash() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("ComputePackageHash() = %s, want %s", got, want)
+	}
+}
+
+func TestVerifyPackageHash(t *testing.T) {
+	data := []byte("package bytes")
+	hash, err := ComputePackageHash(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A hash read from a .nupkg.sha512 file may end with a newline
+	if err := VerifyPackageHash(bytes.NewReader(data), hash+"\n"); err != nil {
+		t.Errorf("VerifyPackageHash() error = %v", err)
+	}
+
+	err = VerifyPackageHash(bytes.NewReader(append(data, '!')), hash)
+	if !errors.Is(err, ErrPackageHashMismatch) {
+		t.Errorf("VerifyPackageHash() error = %v, want ErrPackageHashMismatch", err)
+	}
+}
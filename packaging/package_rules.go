@@ -0,0 +1,161 @@
+package packaging
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/willibrandon/gonuget/frameworks"
+)
+
+// IssueSeverity is the severity of a ValidationIssue
+type IssueSeverity int
+
+const (
+	// IssueWarning is reported by pack but doesn't stop it
+	IssueWarning IssueSeverity = iota
+	// IssueError stops pack
+	IssueError
+)
+
+// String returns "Warning" or "Error"
+func (s IssueSeverity) String() string {
+	if s == IssueError {
+		return "Error"
+	}
+	return "Warning"
+}
+
+// ValidationIssue is a problem with the content of a package, reported with the NuGet
+// code NuGet.Client's pack uses for it.
+type ValidationIssue struct {
+	Code     string
+	Severity IssueSeverity
+	Message  string
+}
+
+// String formats the issue like NuGet's pack output, e.g. "NU5048: The 'PackageIconUrl'..."
+func (i ValidationIssue) String() string {
+	return i.Code + ": " + i.Message
+}
+
+// Validate checks the package content against NuGet's pack rules and returns the
+// issues found: NU5017 (no dependencies nor content), NU5048 (iconUrl without icon),
+// NU5125 (licenseUrl without license) and NU5128 (dependency groups that don't match
+// the lib/ and ref/ frameworks). Call it before Save; Save only rejects packages that
+// can't be written, such as ones with an invalid ID or a missing license file.
+// Reference: PackageBuilder.cs and the pack rules in NuGet.Packaging.Rules
+func (b *PackageBuilder) Validate() []ValidationIssue {
+	var issues []ValidationIssue
+
+	if !b.hasContentOrDependencies() {
+		issues = append(issues, ValidationIssue{
+			Code:     "NU5017",
+			Severity: IssueError,
+			Message:  "Cannot create a package that has no dependencies nor content.",
+		})
+	}
+
+	if b.metadata.IconURL != nil && b.metadata.Icon == "" {
+		issues = append(issues, ValidationIssue{
+			Code:     "NU5048",
+			Severity: IssueWarning,
+			Message:  "The 'PackageIconUrl'/'iconUrl' element is deprecated. Consider using the 'PackageIcon'/'icon' element instead. Learn more at https://aka.ms/deprecateIconUrl",
+		})
+	}
+
+	if b.metadata.LicenseURL != nil && b.metadata.LicenseMetadata == nil {
+		issues = append(issues, ValidationIssue{
+			Code:     "NU5125",
+			Severity: IssueWarning,
+			Message:  "The 'licenseUrl' element will be deprecated. Consider using the 'license' element instead.",
+		})
+	}
+
+	if actions := b.dependencyGroupActions(); len(actions) > 0 {
+		issues = append(issues, ValidationIssue{
+			Code:     "NU5128",
+			Severity: IssueWarning,
+			Message: "Some target frameworks declared in the dependencies group of the nuspec and the lib/ref folder do not have exact matches in the other location. Consult the list of actions below:\n" +
+				strings.Join(actions, "\n"),
+		})
+	}
+
+	return issues
+}
+
+// IssuesError returns an error listing the error issues, and the warnings too when
+// treatWarningsAsErrors is set, like pack's -WarningsAsErrors. It returns nil when no
+// issue fails the pack.
+func IssuesError(issues []ValidationIssue, treatWarningsAsErrors bool) error {
+	var errs []error
+	for _, issue := range issues {
+		if issue.Severity == IssueError || treatWarningsAsErrors {
+			errs = append(errs, errors.New(issue.String()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hasContentOrDependencies reports whether the package has files, package dependencies or
+// framework references
+// Reference: PackageBuilder.cs ValidateDependencies (NU5017)
+func (b *PackageBuilder) hasContentOrDependencies() bool {
+	if len(b.files) > 0 || len(b.nuspecFiles) > 0 || len(b.metadata.FrameworkReferenceGroups) > 0 {
+		return true
+	}
+	for _, group := range b.metadata.DependencyGroups {
+		if len(group.Dependencies) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyGroupActions lists what makes the dependency group frameworks and the lib/
+// and ref/ frameworks match exactly. Files directly under lib/ or ref/ and dependency
+// groups without a framework are ignored.
+// Reference: DependenciesGroupsForEachTFMRule.cs (NU5128)
+func (b *PackageBuilder) dependencyGroupActions() []string {
+	var fileFrameworks []*frameworks.NuGetFramework
+	for _, file := range b.files {
+		name := normalizePackagePath(file.TargetPath)
+		lower := strings.ToLower(name)
+		if !strings.HasPrefix(lower, LibFolder) && !strings.HasPrefix(lower, RefFolder) {
+			continue
+		}
+		if fw := frameworkFromPath(name); fw.IsSpecificFramework() && !containsFramework(fileFrameworks, fw) {
+			fileFrameworks = append(fileFrameworks, fw)
+		}
+	}
+
+	var groupFrameworks []*frameworks.NuGetFramework
+	for _, group := range b.metadata.DependencyGroups {
+		if fw := group.TargetFramework; fw != nil && fw.IsSpecificFramework() && !containsFramework(groupFrameworks, fw) {
+			groupFrameworks = append(groupFrameworks, fw)
+		}
+	}
+
+	provider := frameworks.DefaultFrameworkNameProvider()
+	var actions []string
+	for _, fw := range fileFrameworks {
+		if !containsFramework(groupFrameworks, fw) {
+			actions = append(actions, fmt.Sprintf("- Add a dependency group for %s to the nuspec", fw.GetShortFolderName(provider)))
+		}
+	}
+	for _, fw := range groupFrameworks {
+		if !containsFramework(fileFrameworks, fw) {
+			actions = append(actions, fmt.Sprintf("- Add lib or ref assemblies for the %s target framework", fw.GetShortFolderName(provider)))
+		}
+	}
+	return actions
+}
+
+func containsFramework(list []*frameworks.NuGetFramework, fw *frameworks.NuGetFramework) bool {
+	for _, existing := range list {
+		if existing.Equals(fw) {
+			return true
+		}
+	}
+	return false
+}
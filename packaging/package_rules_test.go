@@ -0,0 +1,150 @@
+package packaging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/frameworks"
+	"github.com/willibrandon/gonuget/version"
+)
+
+func TestBuilderValidate_Issues(t *testing.T) {
+	newBuilder := func() *PackageBuilder {
+		b := NewPackageBuilder()
+		b.SetID("MyPackage")
+		b.SetVersion(version.MustParse("1.0.0"))
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		setup func(b *PackageBuilder)
+		want  []string // codes
+	}{
+		{
+			name: "valid",
+			setup: func(b *PackageBuilder) {
+				_ = b.AddFileFromBytes("lib/net8.0/MyLib.dll", []byte("dll"))
+				b.AddDependency(frameworks.MustParseFramework("net8.0"), "Other", version.MustParseRange("1.0.0"))
+			},
+		},
+		{
+			name:  "empty package",
+			setup: func(b *PackageBuilder) {},
+			want:  []string{"NU5017"},
+		},
+		{
+			name: "empty dependency group only",
+			setup: func(b *PackageBuilder) {
+				b.AddDependencyGroup(PackageDependencyGroup{TargetFramework: frameworks.MustParseFramework("net8.0")})
+			},
+			want: []string{"NU5017", "NU5128"},
+		},
+		{
+			name: "dependencies only",
+			setup: func(b *PackageBuilder) {
+				b.AddDependency(nil, "Other", version.MustParseRange("1.0.0"))
+			},
+		},
+		{
+			name: "iconUrl without icon",
+			setup: func(b *PackageBuilder) {
+				_ = b.AddFileFromBytes("content/readme.txt", []byte("text"))
+				_ = b.SetIconURL("https://example.com/icon.png")
+			},
+			want: []string{"NU5048"},
+		},
+		{
+			name: "iconUrl with icon",
+			setup: func(b *PackageBuilder) {
+				_ = b.AddFileFromBytes("icon.png", []byte("png"))
+				_ = b.SetIconURL("https://example.com/icon.png")
+				b.SetIcon("icon.png")
+			},
+		},
+		{
+			name: "licenseUrl without license",
+			setup: func(b *PackageBuilder) {
+				_ = b.AddFileFromBytes("content/readme.txt", []byte("text"))
+				_ = b.SetLicenseURL("https://example.com/license")
+			},
+			want: []string{"NU5125"},
+		},
+		{
+			name: "licenseUrl with license expression",
+			setup: func(b *PackageBuilder) {
+				_ = b.AddFileFromBytes("content/readme.txt", []byte("text"))
+				_ = b.SetLicenseURL("https://licenses.nuget.org/MIT")
+				b.SetLicenseMetadata(&LicenseMetadata{Type: "expression", Text: "MIT"})
+			},
+		},
+		{
+			name: "lib framework without dependency group",
+			setup: func(b *PackageBuilder) {
+				_ = b.AddFileFromBytes("lib/net8.0/MyLib.dll", []byte("dll"))
+				_ = b.AddFileFromBytes("lib/netstandard2.0/MyLib.dll", []byte("dll"))
+				b.AddDependency(frameworks.MustParseFramework("net8.0"), "Other", version.MustParseRange("1.0.0"))
+			},
+			want: []string{"NU5128"},
+		},
+		{
+			name: "lib files without a framework folder",
+			setup: func(b *PackageBuilder) {
+				_ = b.AddFileFromBytes("lib/MyLib.dll", []byte("dll"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBuilder()
+			tt.setup(b)
+
+			var got []string
+			for _, issue := range b.Validate() {
+				got = append(got, issue.Code)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Validate() codes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderValidate_DependencyGroupActions(t *testing.T) {
+	b := NewPackageBuilder()
+	b.SetID("MyPackage")
+	b.SetVersion(version.MustParse("1.0.0"))
+	_ = b.AddFileFromBytes("lib/netstandard2.0/MyLib.dll", []byte("dll"))
+	_ = b.AddFileFromBytes("ref/netstandard2.0/MyLib.dll", []byte("dll"))
+	b.AddDependency(frameworks.MustParseFramework("net472"), "Other", version.MustParseRange("1.0.0"))
+
+	issues := b.Validate()
+	if len(issues) != 1 {
+		t.Fatalf("Validate() = %v, want one NU5128 issue", issues)
+	}
+	if issues[0].Severity != IssueWarning {
+		t.Errorf("Severity = %v, want Warning", issues[0].Severity)
+	}
+	want := "NU5128: Some target frameworks declared in the dependencies group of the nuspec and the lib/ref folder do not have exact matches in the other location. Consult the list of actions below:\n" +
+		"- Add a dependency group for netstandard2.0 to the nuspec\n" +
+		"- Add lib or ref assemblies for the net472 target framework"
+	if got := issues[0].String(); got != want {
+		t.Errorf("issue =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestIssuesError(t *testing.T) {
+	warning := ValidationIssue{Code: "NU5048", Severity: IssueWarning, Message: "iconUrl is deprecated"}
+	failure := ValidationIssue{Code: "NU5017", Severity: IssueError, Message: "no content"}
+
+	if err := IssuesError([]ValidationIssue{warning}, false); err != nil {
+		t.Errorf("IssuesError(warning) = %v, want nil", err)
+	}
+	if err := IssuesError([]ValidationIssue{warning}, true); err == nil || err.Error() != "NU5048: iconUrl is deprecated" {
+		t.Errorf("IssuesError(warning, treatWarningsAsErrors) = %v", err)
+	}
+	if err := IssuesError([]ValidationIssue{warning, failure}, false); err == nil || err.Error() != "NU5017: no content" {
+		t.Errorf("IssuesError(warning, error) = %v, want only the error", err)
+	}
+}
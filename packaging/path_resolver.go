@@ -117,6 +117,12 @@ func (r *VersionFolderPathResolver) GetManifestFilePath(packageID string, ver *v
 	return filepath.Join(dir, fmt.Sprintf("%s.nuspec", r.normalize(packageID)))
 }
 
+// GetNuspecPath returns full path to the .nuspec file.
+// Same as GetManifestFilePath.
+func (r *VersionFolderPathResolver) GetNuspecPath(packageID string, ver *version.NuGetVersion) string {
+	return r.GetManifestFilePath(packageID, ver)
+}
+
 // GetHashPath returns full path to hash file.
 // Format: {rootPath}/{id}/{version}/{id}.{version}.nupkg.sha512
 func (r *VersionFolderPathResolver) GetHashPath(packageID string, ver *version.NuGetVersion) string {
@@ -179,6 +179,10 @@ func TestVersionFolderPathResolver_Paths(t *testing.T) {
 		t.Errorf("GetManifestFilePath() = %q, want %q", nuspecPath, expectedNuspec)
 	}
 
+	if got := resolver.GetNuspecPath(packageID, ver); got != expectedNuspec {
+		t.Errorf("GetNuspecPath() = %q, want %q", got, expectedNuspec)
+	}
+
 	// Test GetHashPath
 	hashPath := resolver.GetHashPath(packageID, ver)
 	expectedHash := filepath.Join("/global-packages", "testpackage", "1.0.0", "testpackage.1.0.0.nupkg.sha512")
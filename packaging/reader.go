@@ -23,6 +23,11 @@ type PackageReader struct {
 	zipReaderAt *zip.Reader // For in-memory ZIPs
 	isClosable  bool
 
+	// The archive itself, for hashing: the file path, or the ReaderAt and its size
+	path     string
+	readerAt io.ReaderAt
+	size     int64
+
 	// Cached values
 	isSigned    *bool
 	identity    *PackageIdentity
@@ -50,6 +55,7 @@ func OpenPackage(path string) (*PackageReader, error) {
 	return &PackageReader{
 		zipReader:  zipReader,
 		isClosable: true,
+		path:       path,
 	}, nil
 }
 
@@ -63,6 +69,8 @@ func OpenPackageFromReaderAt(r io.ReaderAt, size int64) (*PackageReader, error)
 	return &PackageReader{
 		zipReaderAt: zipReader,
 		isClosable:  false,
+		readerAt:    r,
+		size:        size,
 	}, nil
 }
 
@@ -113,8 +121,9 @@ func (r *PackageReader) GetSignatureFile() (*zip.File, error) {
 	return nil, ErrPackageNotSigned
 }
 
-// GetNuspecFile finds and returns the .nuspec file entry.
-// Nuspec should be at the root level with .nuspec extension.
+// GetNuspecFile finds and returns the .nuspec file entry: the one root-level entry
+// with a .nuspec extension, whatever the casing of its name. Subfolders are never
+// searched. Reference: PackageArchiveReader.GetNuspecFile
 func (r *PackageReader) GetNuspecFile() (*zip.File, error) {
 	if r.nuspecEntry != nil {
 		return r.nuspecEntry, nil
@@ -122,8 +131,7 @@ func (r *PackageReader) GetNuspecFile() (*zip.File, error) {
 
 	var candidates []*zip.File
 	for _, file := range r.Files() {
-		// Nuspec must be at root (no directory separator)
-		if !strings.Contains(file.Name, "/") && strings.HasSuffix(strings.ToLower(file.Name), ".nuspec") {
+		if IsManifestFile(file.Name) {
 			candidates = append(candidates, file)
 		}
 	}
@@ -170,20 +178,6 @@ func (r *PackageReader) HasFile(filePath string) bool {
 	return err == nil
 }
 
-// GetFiles returns files matching a path prefix.
-func (r *PackageReader) GetFiles(prefix string) []*zip.File {
-	normalizedPrefix := strings.ReplaceAll(prefix, "\\", "/")
-
-	var matches []*zip.File
-	for _, file := range r.Files() {
-		if strings.HasPrefix(strings.ToLower(file.Name), strings.ToLower(normalizedPrefix)) {
-			matches = append(matches, file)
-		}
-	}
-
-	return matches
-}
-
 // GetNuspec reads and parses the .nuspec file.
 func (r *PackageReader) GetNuspec() (*Nuspec, error) {
 	nuspecReader, err := r.OpenNuspec()
@@ -0,0 +1,207 @@
+package packaging
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/gonuget/packaging/signatures"
+)
+
+// ExtractOptions configures PackageReader.ExtractAll.
+type ExtractOptions struct {
+	// VersionFolder extracts into destDir/{id}/{version}, the global packages folder
+	// layout, instead of directly into destDir.
+	VersionFolder bool
+	// LowercaseID lowercases the id and version folders and marker names, as the
+	// global packages folder does.
+	LowercaseID bool
+
+	// SkipOPCFiles leaves out the Open Packaging Conventions parts of the ZIP:
+	// _rels/, package/ (the .psmdcp core properties) and [Content_Types].xml.
+	SkipOPCFiles bool
+
+	// WriteMarkers writes {id}.{version}.nupkg.sha512 and .nupkg.metadata after the files,
+	// which mark a complete install in the global packages folder.
+	WriteMarkers bool
+	// Source is recorded in .nupkg.metadata.
+	Source string
+}
+
+// ExtractAll streams every file of the package to disk and returns the paths written,
+// the markers last. Every entry is checked before anything is written: an entry that
+// would land outside the target folder, such as "../../evil" or an absolute path, or a
+// symbolic link entry fails the extraction with ErrInvalidPath.
+func (r *PackageReader) ExtractAll(destDir string, opts ExtractOptions) ([]string, error) {
+	targetDir := destDir
+	var resolver *VersionFolderPathResolver
+	var identity *PackageIdentity
+	if opts.VersionFolder || opts.WriteMarkers {
+		var err error
+		identity, err = r.GetIdentity()
+		if err != nil {
+			return nil, fmt.Errorf("get identity: %w", err)
+		}
+		resolver = NewVersionFolderPathResolver(destDir, opts.LowercaseID)
+		if opts.VersionFolder {
+			targetDir = resolver.GetInstallPath(identity.ID, identity.Version)
+		}
+	}
+
+	root, err := filepath.Abs(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve destination: %w", err)
+	}
+
+	// Plan the whole extraction first so a malicious entry leaves nothing behind
+	type entry struct {
+		file   *zip.File
+		target string
+	}
+	var entries []entry
+	for _, file := range r.Files() {
+		if strings.HasSuffix(file.Name, "/") || (opts.SkipOPCFiles && isOPCFile(file.Name)) {
+			continue
+		}
+		if file.Mode()&fs.ModeSymlink != 0 {
+			return nil, fmt.Errorf("%w: %q is a symbolic link", ErrInvalidPath, file.Name)
+		}
+		target, err := extractionTarget(root, file.Name)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{file: file, target: target})
+	}
+
+	written := make([]string, 0, len(entries)+2)
+	for _, e := range entries {
+		if err := extractEntry(e.file, e.target); err != nil {
+			return written, fmt.Errorf("extract %q: %w", e.file.Name, err)
+		}
+		written = append(written, e.target)
+	}
+
+	if opts.WriteMarkers {
+		markers, err := r.writeMarkers(root, resolver, identity, opts.Source)
+		written = append(written, markers...)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// extractionTarget returns where an entry is extracted under root, or ErrInvalidPath
+// when the entry would escape it.
+func extractionTarget(root, name string) (string, error) {
+	normalized := strings.ReplaceAll(name, "\\", "/")
+	native := filepath.FromSlash(normalized)
+	if strings.HasPrefix(normalized, "/") || filepath.IsAbs(native) || filepath.VolumeName(native) != "" {
+		return "", fmt.Errorf("%w: %q is an absolute path", ErrInvalidPath, name)
+	}
+
+	target := filepath.Join(root, native)
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q is outside the destination", ErrInvalidPath, name)
+	}
+	return target, nil
+}
+
+// extractEntry streams one ZIP entry to target, replacing an existing file.
+func extractEntry(file *zip.File, target string) error {
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("open zip file: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	out, err := CreateFile(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("copy file contents: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return UpdateFileTimeFromEntry(target, file.Modified, nil)
+}
+
+// isOPCFile reports the Open Packaging Conventions parts of a package.
+func isOPCFile(name string) bool {
+	lower := strings.ToLower(strings.ReplaceAll(name, "\\", "/"))
+	return strings.HasPrefix(lower, "_rels/") ||
+		strings.HasPrefix(lower, "package/") ||
+		lower == "[content_types].xml" ||
+		strings.HasSuffix(lower, ".psmdcp")
+}
+
+// writeMarkers writes the .nupkg.sha512 and .nupkg.metadata files into dir.
+func (r *PackageReader) writeMarkers(dir string, resolver *VersionFolderPathResolver, identity *PackageIdentity, source string) ([]string, error) {
+	packageHash, contentHash, err := r.hashes()
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	hashPath := filepath.Join(dir, filepath.Base(resolver.GetHashPath(identity.ID, identity.Version)))
+	if err := os.WriteFile(hashPath, []byte(packageHash), 0644); err != nil {
+		return nil, fmt.Errorf("write hash file: %w", err)
+	}
+	written = append(written, hashPath)
+
+	// .nupkg.metadata goes last: it marks the install complete
+	metadataPath := filepath.Join(dir, ".nupkg.metadata")
+	if err := NewNupkgMetadataFile(contentHash, source).WriteToFile(metadataPath); err != nil {
+		return written, err
+	}
+	return append(written, metadataPath), nil
+}
+
+// hashes returns the SHA512 of the .nupkg and its content hash, which leaves out the
+// signature of a signed package. Both are base64-encoded.
+func (r *PackageReader) hashes() (packageHash, contentHash string, err error) {
+	var archive io.ReadSeeker
+	switch {
+	case r.path != "":
+		file, err := os.Open(r.path)
+		if err != nil {
+			return "", "", fmt.Errorf("open package: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		archive = file
+	case r.readerAt != nil:
+		archive = io.NewSectionReader(r.readerAt, 0, r.size)
+	default:
+		return "", "", fmt.Errorf("package archive is not available for hashing")
+	}
+
+	packageHash, err = ComputePackageHash(archive)
+	if err != nil {
+		return "", "", err
+	}
+	if !r.IsSigned() {
+		return packageHash, packageHash, nil
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return "", "", err
+	}
+	contentHash, err = signatures.GetPackageContentHash(archive)
+	if err != nil {
+		return "", "", fmt.Errorf("calculate signed content hash: %w", err)
+	}
+	if contentHash == "" {
+		contentHash = packageHash
+	}
+	return packageHash, contentHash, nil
+}
@@ -0,0 +1,197 @@
+package packaging
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const extractTestNuspec = `<?xml version="1.0" encoding="utf-8"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata>
+    <id>Contoso.Lib</id>
+    <version>1.0.0-Beta</version>
+    <authors>Contoso</authors>
+    <description>Test package</description>
+  </metadata>
+</package>`
+
+// zipEntry is one entry of a test archive; mode is applied when non-zero.
+type zipEntry struct {
+	name    string
+	content string
+	mode    os.FileMode
+}
+
+func createExtractTestPackage(t *testing.T, entries []zipEntry) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for _, e := range entries {
+		header := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		if e.mode != 0 {
+			header.SetMode(e.mode)
+		}
+		f, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatalf("create %s: %v", e.name, err)
+		}
+		if _, err := f.Write([]byte(e.content)); err != nil {
+			t.Fatalf("write %s: %v", e.name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestPackageReader_ExtractAll_VersionFolder(t *testing.T) {
+	data := createExtractTestPackage(t, []zipEntry{
+		{name: "Contoso.Lib.nuspec", content: extractTestNuspec},
+		{name: "lib/net8.0/Contoso.Lib.dll", content: "MZ"},
+		{name: "_rels/.rels", content: "<Relationships />"},
+		{name: "[Content_Types].xml", content: "<Types />"},
+		{name: "package/services/metadata/core-properties/abc.psmdcp", content: "<coreProperties />"},
+	})
+	path := filepath.Join(t.TempDir(), "Contoso.Lib.1.0.0-Beta.nupkg")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := OpenPackage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	dest := t.TempDir()
+	written, err := reader.ExtractAll(dest, ExtractOptions{
+		VersionFolder: true,
+		LowercaseID:   true,
+		SkipOPCFiles:  true,
+		WriteMarkers:  true,
+		Source:        "https://api.nuget.org/v3/index.json",
+	})
+	if err != nil {
+		t.Fatalf("ExtractAll() error = %v", err)
+	}
+
+	installDir := filepath.Join(dest, "contoso.lib", "1.0.0-beta")
+	want := []string{
+		filepath.Join(installDir, "Contoso.Lib.nuspec"),
+		filepath.Join(installDir, "lib", "net8.0", "Contoso.Lib.dll"),
+		filepath.Join(installDir, "contoso.lib.1.0.0-beta.nupkg.sha512"),
+		filepath.Join(installDir, ".nupkg.metadata"),
+	}
+	if len(written) != len(want) {
+		t.Fatalf("written = %v, want %v", written, want)
+	}
+	for i := range want {
+		if written[i] != want[i] {
+			t.Errorf("written[%d] = %s, want %s", i, written[i], want[i])
+		}
+	}
+
+	for _, opc := range []string{"_rels", "[Content_Types].xml", "package"} {
+		if _, err := os.Stat(filepath.Join(installDir, opc)); !os.IsNotExist(err) {
+			t.Errorf("%s extracted with SkipOPCFiles", opc)
+		}
+	}
+
+	sum := sha512.Sum512(data)
+	hash := base64.StdEncoding.EncodeToString(sum[:])
+	if got, err := os.ReadFile(want[2]); err != nil || string(got) != hash {
+		t.Errorf("hash file = %q, %v, want %q", got, err, hash)
+	}
+	metadata, err := ReadNupkgMetadataFile(want[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.ContentHash != hash || metadata.Source != "https://api.nuget.org/v3/index.json" {
+		t.Errorf("metadata = %+v, want content hash %s and the source", metadata, hash)
+	}
+}
+
+func TestPackageReader_ExtractAll_InMemory(t *testing.T) {
+	data := createExtractTestPackage(t, []zipEntry{
+		{name: "Contoso.Lib.nuspec", content: extractTestNuspec},
+		{name: "_rels/.rels", content: "<Relationships />"},
+		{name: "content/readme.txt", content: "readme"},
+	})
+	reader, err := OpenPackageFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without options every entry lands directly in the destination
+	dest := t.TempDir()
+	written, err := reader.ExtractAll(dest, ExtractOptions{WriteMarkers: true})
+	if err != nil {
+		t.Fatalf("ExtractAll() error = %v", err)
+	}
+	if len(written) != 5 {
+		t.Errorf("written = %v, want 3 files and 2 markers", written)
+	}
+	for _, name := range []string{filepath.Join("_rels", ".rels"), filepath.Join("content", "readme.txt"), "Contoso.Lib.1.0.0-Beta.nupkg.sha512"} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+}
+
+func TestPackageReader_ExtractAll_RejectsEscapingEntries(t *testing.T) {
+	for _, name := range []string{"../../evil", "lib/../../evil", `..\..\evil`, "/tmp/evil", ".."} {
+		t.Run(name, func(t *testing.T) {
+			data := createExtractTestPackage(t, []zipEntry{
+				{name: "lib/net8.0/Good.dll", content: "MZ"},
+				{name: name, content: "evil"},
+			})
+			reader, err := OpenPackageFromReaderAt(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parent := t.TempDir()
+			dest := filepath.Join(parent, "a", "b")
+			written, err := reader.ExtractAll(dest, ExtractOptions{})
+			if !errors.Is(err, ErrInvalidPath) {
+				t.Fatalf("ExtractAll() error = %v, want ErrInvalidPath", err)
+			}
+			if len(written) != 0 {
+				t.Errorf("written = %v, want nothing", written)
+			}
+			// Nothing is extracted, not even the entries before the malicious one
+			if _, err := os.Stat(filepath.Join(parent, "evil")); !os.IsNotExist(err) {
+				t.Error("entry escaped the destination")
+			}
+			if _, err := os.Stat(dest); !os.IsNotExist(err) {
+				t.Error("destination written despite the malicious entry")
+			}
+		})
+	}
+}
+
+func TestPackageReader_ExtractAll_RejectsSymlinks(t *testing.T) {
+	data := createExtractTestPackage(t, []zipEntry{
+		{name: "lib/net8.0/Good.dll", content: "MZ"},
+		{name: "lib/net8.0/link", content: "/etc/passwd", mode: os.ModeSymlink | 0777},
+	})
+	reader, err := OpenPackageFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "out")
+	if _, err := reader.ExtractAll(dest, ExtractOptions{}); !errors.Is(err, ErrInvalidPath) {
+		t.Fatalf("ExtractAll() error = %v, want ErrInvalidPath", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dest, "lib", "net8.0", "link")); !os.IsNotExist(err) {
+		t.Error("symbolic link entry extracted")
+	}
+}
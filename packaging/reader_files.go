@@ -0,0 +1,223 @@
+package packaging
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/willibrandon/gonuget/frameworks"
+)
+
+// FrameworkSpecificGroup is a set of package files that apply to one target framework.
+// Reference: NuGet.Packaging FrameworkSpecificGroup
+type FrameworkSpecificGroup struct {
+	TargetFramework *frameworks.NuGetFramework
+	Items           []string // Package-relative paths using forward slashes
+}
+
+// GetFiles returns the paths of all files in the package, excluding OPC metadata
+// (_rels/, [Content_Types].xml, package/services/metadata/) and directory entries.
+// Paths use forward slashes and keep the casing stored in the archive.
+// Only the central directory is read; no entry is decompressed.
+func (r *PackageReader) GetFiles() []string {
+	var paths []string
+	for _, file := range r.Files() {
+		name := normalizeZipPath(file.Name)
+		if strings.HasSuffix(name, "/") || IsOPCFile(name) {
+			continue
+		}
+		paths = append(paths, name)
+	}
+	return paths
+}
+
+// GetFilesUnder returns the files whose path starts with prefix (case-insensitive).
+// A prefix without a trailing slash is treated as a folder, so "lib" matches "lib/a.dll" but not "library.txt".
+func (r *PackageReader) GetFilesUnder(prefix string) []string {
+	prefix = strings.ToLower(normalizeZipPath(prefix))
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var paths []string
+	for _, name := range r.GetFiles() {
+		if strings.HasPrefix(strings.ToLower(name), prefix) {
+			paths = append(paths, name)
+		}
+	}
+	return paths
+}
+
+// GetFilesMatching returns the files matching a wildcard pattern (case-insensitive).
+// "*" matches within a path segment, "?" matches one character and "**" matches across
+// segments, so "lib/**/*.dll" finds every assembly under lib/.
+// Reference: PathResolver.WildcardToRegex in NuGet.Client
+func (r *PackageReader) GetFilesMatching(pattern string) []string {
+	re := packagePathPattern(pattern)
+
+	var paths []string
+	for _, name := range r.GetFiles() {
+		if re.MatchString(name) {
+			paths = append(paths, name)
+		}
+	}
+	return paths
+}
+
+// GetLibItems returns the lib/ files grouped by target framework.
+func (r *PackageReader) GetLibItems() []FrameworkSpecificGroup {
+	return r.getFileGroups(LibFolder)
+}
+
+// GetRefItems returns the ref/ files grouped by target framework.
+func (r *PackageReader) GetRefItems() []FrameworkSpecificGroup {
+	return r.getFileGroups(RefFolder)
+}
+
+// GetToolItems returns the tools/ files grouped by target framework.
+func (r *PackageReader) GetToolItems() []FrameworkSpecificGroup {
+	return r.getFileGroups(ToolsFolder)
+}
+
+// GetContentItems returns the content/ files grouped by target framework.
+func (r *PackageReader) GetContentItems() []FrameworkSpecificGroup {
+	return r.getFileGroups(ContentFolder)
+}
+
+// GetSupportedFrameworks returns the frameworks the package has lib/ or ref/ files or a
+// dependency group for, deduplicated and sorted with Any first. Files directly under
+// lib/ or ref/ and dependency groups without a target framework count as Any.
+// Dependency groups are skipped when the nuspec can't be read.
+func (r *PackageReader) GetSupportedFrameworks() []*frameworks.NuGetFramework {
+	var supported []*frameworks.NuGetFramework
+	add := func(framework *frameworks.NuGetFramework) {
+		for _, existing := range supported {
+			if existing.Equals(framework) {
+				return
+			}
+		}
+		supported = append(supported, framework)
+	}
+
+	for _, group := range r.GetLibItems() {
+		add(group.TargetFramework)
+	}
+	for _, group := range r.GetRefItems() {
+		add(group.TargetFramework)
+	}
+	if nuspec, err := r.GetNuspec(); err == nil {
+		if groups, err := nuspec.GetDependencyGroups(); err == nil {
+			for _, group := range groups {
+				add(group.TargetFramework)
+			}
+		}
+	}
+
+	sort.SliceStable(supported, func(i, j int) bool {
+		return compareFrameworks(supported[i], supported[j]) < 0
+	})
+	return supported
+}
+
+// getFileGroups groups the files under folder by the framework named in their second path segment.
+// Files directly in the folder, or under a segment that isn't a specific framework
+// (e.g. "any" or content/scripts/), belong to the Any framework.
+// Reference: PackageReaderBase.GetFileGroups
+func (r *PackageReader) getFileGroups(folder string) []FrameworkSpecificGroup {
+	var groups []FrameworkSpecificGroup
+
+	for _, name := range r.GetFilesUnder(folder) {
+		framework := frameworkFromPath(name)
+
+		idx := -1
+		for i := range groups {
+			if groups[i].TargetFramework.Equals(framework) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			groups = append(groups, FrameworkSpecificGroup{TargetFramework: framework})
+			idx = len(groups) - 1
+		}
+		groups[idx].Items = append(groups[idx].Items, name)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return compareFrameworks(groups[i].TargetFramework, groups[j].TargetFramework) < 0
+	})
+	for _, group := range groups {
+		sort.SliceStable(group.Items, func(i, j int) bool {
+			return strings.ToLower(group.Items[i]) < strings.ToLower(group.Items[j])
+		})
+	}
+
+	return groups
+}
+
+// frameworkFromPath parses the framework folder of a path like lib/net8.0/a.dll.
+func frameworkFromPath(name string) *frameworks.NuGetFramework {
+	parts := strings.Split(name, "/")
+	if len(parts) >= 3 {
+		// Folder names are case-insensitive, the framework parser is not
+		if fw, err := frameworks.ParseFramework(strings.ToLower(parts[1])); err == nil && fw.IsSpecificFramework() {
+			return fw
+		}
+	}
+
+	anyFramework := frameworks.AnyFramework
+	return &anyFramework
+}
+
+// compareFrameworks orders Any first, then by identifier, version, platform and profile.
+func compareFrameworks(a, b *frameworks.NuGetFramework) int {
+	if a.IsAny() != b.IsAny() {
+		if a.IsAny() {
+			return -1
+		}
+		return 1
+	}
+	if c := strings.Compare(strings.ToLower(a.Framework), strings.ToLower(b.Framework)); c != 0 {
+		return c
+	}
+	if c := a.Version.Compare(b.Version); c != 0 {
+		return c
+	}
+	if c := strings.Compare(strings.ToLower(a.Platform), strings.ToLower(b.Platform)); c != 0 {
+		return c
+	}
+	return strings.Compare(strings.ToLower(a.Profile), strings.ToLower(b.Profile))
+}
+
+// normalizeZipPath converts backslashes written by some packers to forward slashes.
+func normalizeZipPath(name string) string {
+	return strings.ReplaceAll(name, "\\", "/")
+}
+
+// packagePathPattern converts a wildcard pattern into an anchored, case-insensitive regexp.
+func packagePathPattern(pattern string) *regexp.Regexp {
+	pattern = strings.TrimPrefix(normalizeZipPath(pattern), "/")
+
+	var sb strings.Builder
+	sb.WriteString("(?i)^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			// Recursive wildcard with a slash includes the current folder
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			sb.WriteString("[^/]*")
+		case pattern[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+
+	return regexp.MustCompile(sb.String())
+}
@@ -0,0 +1,262 @@
+package packaging
+
+import (
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/willibrandon/gonuget/frameworks"
+)
+
+// openMultiTargetPackage builds a package with lib/ref/tools/content files for several frameworks.
+func openMultiTargetPackage(t *testing.T) *PackageReader {
+	t.Helper()
+
+	files := map[string]string{
+		"Test.nuspec":         "<package/>",
+		"_rels/.rels":         "rels",
+		"[Content_Types].xml": "types",
+		"package/services/metadata/core-properties/abc.psmdcp": "props",
+		"lib/net45/Test.dll":                       "dll",
+		"lib/net45/Test.xml":                       "xml",
+		"lib/NETSTANDARD2.0/Test.dll":              "dll",
+		"lib/netstandard2.0/de/Test.resources.dll": "dll",
+		"lib/any/Any.dll":                          "dll",
+		"lib/dotnet/Platform.dll":                  "dll",
+		"lib/Root.dll":                             "dll",
+		"ref/netstandard2.0/Test.dll":              "dll",
+		"tools/net8.0/any/DotnetToolSettings.xml":  "settings",
+		"tools/init.ps1":                           "ps1",
+		"content/net45/App.config.transform":       "transform",
+		"content/scripts/app.js":                   "js",
+		"lib\\net48\\Backslash.dll":                "dll",
+	}
+
+	reader := createTestPackage(t, files, true)
+	pkg, err := OpenPackageFromReaderAt(reader, int64(reader.Len()))
+	if err != nil {
+		t.Fatalf("OpenPackageFromReaderAt failed: %v", err)
+	}
+	t.Cleanup(func() { _ = pkg.Close() })
+	return pkg
+}
+
+func TestPackageReader_GetFiles(t *testing.T) {
+	pkg := openMultiTargetPackage(t)
+
+	files := pkg.GetFiles()
+	for _, name := range files {
+		if IsOPCFile(name) {
+			t.Errorf("GetFiles() should exclude OPC metadata, got %s", name)
+		}
+	}
+	if !slices.Contains(files, "Test.nuspec") || !slices.Contains(files, SignaturePath) {
+		t.Errorf("GetFiles() should include the nuspec and signature, got %v", files)
+	}
+	if !slices.Contains(files, "lib/net48/Backslash.dll") {
+		t.Errorf("GetFiles() should normalize backslashes, got %v", files)
+	}
+	if len(files) != 15 {
+		t.Errorf("GetFiles() returned %d files, want 15", len(files))
+	}
+}
+
+func TestPackageReader_GetFilesUnder_FolderBoundary(t *testing.T) {
+	pkg := openMultiTargetPackage(t)
+
+	got := pkg.GetFilesUnder("LIB/NET45")
+	sort.Strings(got)
+	want := []string{"lib/net45/Test.dll", "lib/net45/Test.xml"}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetFilesUnder(LIB/NET45) = %v, want %v", got, want)
+	}
+
+	// net45 must not match net48 or a file named lib/net45x
+	if got := pkg.GetFilesUnder("lib/net4"); len(got) != 0 {
+		t.Errorf("GetFilesUnder(lib/net4) = %v, want none", got)
+	}
+}
+
+func TestPackageReader_GetFilesMatching(t *testing.T) {
+	pkg := openMultiTargetPackage(t)
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{
+			pattern: "lib/*/*.dll",
+			want: []string{
+				"lib/NETSTANDARD2.0/Test.dll",
+				"lib/any/Any.dll",
+				"lib/dotnet/Platform.dll",
+				"lib/net45/Test.dll",
+				"lib/net48/Backslash.dll",
+			},
+		},
+		{
+			pattern: "lib/**/*.resources.dll",
+			want:    []string{"lib/netstandard2.0/de/Test.resources.dll"},
+		},
+		{
+			pattern: "**/test.???",
+			want: []string{
+				"lib/NETSTANDARD2.0/Test.dll",
+				"lib/net45/Test.dll",
+				"lib/net45/Test.xml",
+				"ref/netstandard2.0/Test.dll",
+			},
+		},
+		{
+			pattern: "lib/**/*.dll",
+			want: []string{
+				"lib/NETSTANDARD2.0/Test.dll",
+				"lib/Root.dll",
+				"lib/any/Any.dll",
+				"lib/dotnet/Platform.dll",
+				"lib/net45/Test.dll",
+				"lib/net48/Backslash.dll",
+				"lib/netstandard2.0/de/Test.resources.dll",
+			},
+		},
+		{
+			pattern: "tools\\*.ps1",
+			want:    []string{"tools/init.ps1"},
+		},
+		{
+			pattern: "_rels/*",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			got := pkg.GetFilesMatching(tt.pattern)
+			sort.Strings(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GetFilesMatching(%q) = %v, want %v", tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageReader_GetLibItems(t *testing.T) {
+	pkg := openMultiTargetPackage(t)
+
+	groups := pkg.GetLibItems()
+
+	type group struct {
+		framework string
+		items     []string
+	}
+	var got []group
+	for _, g := range groups {
+		got = append(got, group{framework: g.TargetFramework.GetShortFolderName(frameworks.DefaultFrameworkNameProvider()), items: g.Items})
+	}
+
+	want := []group{
+		// any/ and files directly in lib/ both land in the Any group
+		{framework: "any", items: []string{"lib/any/Any.dll", "lib/Root.dll"}},
+		{framework: "net45", items: []string{"lib/net45/Test.dll", "lib/net45/Test.xml"}},
+		{framework: "net48", items: []string{"lib/net48/Backslash.dll"}},
+		{framework: "dotnet5.0", items: []string{"lib/dotnet/Platform.dll"}},
+		// Folder casing differs but the framework is the same
+		{framework: "netstandard2.0", items: []string{"lib/netstandard2.0/de/Test.resources.dll", "lib/NETSTANDARD2.0/Test.dll"}},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("GetLibItems() returned %d groups, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i].framework != want[i].framework {
+			t.Errorf("group[%d] framework = %s, want %s", i, got[i].framework, want[i].framework)
+		}
+		if !slices.Equal(got[i].items, want[i].items) {
+			t.Errorf("group[%d] items = %v, want %v", i, got[i].items, want[i].items)
+		}
+	}
+}
+
+func TestPackageReader_GetFrameworkGroups_OtherFolders(t *testing.T) {
+	pkg := openMultiTargetPackage(t)
+
+	ref := pkg.GetRefItems()
+	if len(ref) != 1 || ref[0].TargetFramework.Framework != ".NETStandard" {
+		t.Errorf("GetRefItems() = %+v, want a single netstandard2.0 group", ref)
+	}
+
+	tools := pkg.GetToolItems()
+	if len(tools) != 2 || !tools[0].TargetFramework.IsAny() || !tools[1].TargetFramework.IsNet5Era() {
+		t.Errorf("GetToolItems() = %+v, want any and net8.0 groups", tools)
+	}
+
+	content := pkg.GetContentItems()
+	if len(content) != 2 {
+		t.Fatalf("GetContentItems() returned %d groups, want 2", len(content))
+	}
+	// Non-framework folders such as content/scripts/ belong to Any
+	if !content[0].TargetFramework.IsAny() || !slices.Equal(content[0].Items, []string{"content/scripts/app.js"}) {
+		t.Errorf("content Any group = %+v", content[0])
+	}
+}
+
+func TestPackageReader_GetSupportedFrameworks(t *testing.T) {
+	nuspec := `<?xml version="1.0"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata>
+    <id>Test</id>
+    <version>1.0.0</version>
+    <authors>test</authors>
+    <description>test</description>
+    <dependencies>
+      <group targetFramework=".NETStandard2.0" />
+      <group targetFramework="net8.0">
+        <dependency id="Dep" version="1.0.0" />
+      </group>
+      <group targetFramework="net6.0-windows7.0" />
+    </dependencies>
+  </metadata>
+</package>`
+
+	files := map[string]string{
+		"Test.nuspec":                 nuspec,
+		"lib/net45/Test.dll":          "dll",
+		"lib/NETSTANDARD2.0/Test.dll": "dll",
+		"lib/net8.0/Test.dll":         "dll",
+		"ref/net8.0/Test.dll":         "dll",
+		"ref/netstandard2.1/Test.dll": "dll",
+		"tools/net9.0/Tool.dll":       "dll", // tools/ does not make a framework supported
+	}
+
+	reader := createTestPackage(t, files, false)
+	pkg, err := OpenPackageFromReaderAt(reader, int64(reader.Len()))
+	if err != nil {
+		t.Fatalf("OpenPackageFromReaderAt failed: %v", err)
+	}
+	defer func() { _ = pkg.Close() }()
+
+	var got []string
+	for _, fw := range pkg.GetSupportedFrameworks() {
+		got = append(got, fw.GetShortFolderName(frameworks.DefaultFrameworkNameProvider()))
+	}
+
+	// Ordered by framework identifier: .NETCoreApp, .NETFramework, .NETStandard
+	want := []string{"net6.0-windows7.0", "net8.0", "net45", "netstandard2.0", "netstandard2.1"}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetSupportedFrameworks() = %v, want %v", got, want)
+	}
+}
+
+func TestPackageReader_GetSupportedFrameworks_Any(t *testing.T) {
+	pkg := openMultiTargetPackage(t)
+
+	supported := pkg.GetSupportedFrameworks()
+	if len(supported) == 0 || !supported[0].IsAny() {
+		t.Fatalf("GetSupportedFrameworks() = %v, want Any first for lib/Root.dll", supported)
+	}
+
+	net8 := frameworks.MustParseFramework("net8.0")
+	if slices.ContainsFunc(supported, net8.Equals) {
+		t.Error("GetSupportedFrameworks() should not include net8.0 from tools/")
+	}
+}
@@ -291,6 +291,30 @@ func TestPackageReader_GetNuspecFile(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "name cased differently from id",
+			files: map[string]string{
+				"MYPACKAGE.NuSpec":  "content",
+				"lib/net8.0/my.dll": "content",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "only nested nuspecs",
+			files: map[string]string{
+				"content/test.nuspec": "content",
+				"tools\\test.nuspec":  "content",
+			},
+			wantErr: ErrNuspecNotFound,
+		},
+		{
+			name: "nuspecs differing only in case",
+			files: map[string]string{
+				"test.nuspec": "content",
+				"Test.NUSPEC": "content",
+			},
+			wantErr: ErrMultipleNuspecs,
+		},
 	}
 
 	for _, tt := range tests {
@@ -440,7 +464,7 @@ func TestPackageReader_HasFile(t *testing.T) {
 	}
 }
 
-func TestPackageReader_GetFiles(t *testing.T) {
+func TestPackageReader_GetFilesUnder(t *testing.T) {
 	files := map[string]string{
 		"lib/net6.0/test1.dll": "content",
 		"lib/net6.0/test2.dll": "content",
@@ -455,19 +479,19 @@ func TestPackageReader_GetFiles(t *testing.T) {
 	}
 	defer func() { _ = pkg.Close() }()
 
-	matches := pkg.GetFiles("lib/net6.0/")
+	matches := pkg.GetFilesUnder("lib/net6.0/")
 	if len(matches) != 2 {
-		t.Errorf("GetFiles('lib/net6.0/') returned %d files, want 2", len(matches))
+		t.Errorf("GetFilesUnder('lib/net6.0/') returned %d files, want 2", len(matches))
 	}
 
-	matches = pkg.GetFiles("lib/")
+	matches = pkg.GetFilesUnder("lib/")
 	if len(matches) != 3 {
-		t.Errorf("GetFiles('lib/') returned %d files, want 3", len(matches))
+		t.Errorf("GetFilesUnder('lib/') returned %d files, want 3", len(matches))
 	}
 
-	matches = pkg.GetFiles("missing/")
+	matches = pkg.GetFilesUnder("missing/")
 	if len(matches) != 0 {
-		t.Errorf("GetFiles('missing/') returned %d files, want 0", len(matches))
+		t.Errorf("GetFilesUnder('missing/') returned %d files, want 0", len(matches))
 	}
 }
 
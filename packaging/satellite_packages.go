@@ -235,8 +235,8 @@ func isPackageFile(fileName string, saveMode PackageSaveMode) bool {
 		return false
 	}
 
-	// Check if file is .nuspec
-	if strings.HasSuffix(strings.ToLower(fileName), ".nuspec") {
+	// Check if file is the manifest; a .nuspec in a subfolder is a regular file
+	if IsManifestFile(fileName) {
 		return (saveMode & PackageSaveModeNuspec) == PackageSaveModeNuspec
 	}
 
@@ -0,0 +1,57 @@
+package packaging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/willibrandon/gonuget/packaging/signatures"
+)
+
+// SignPackageFile signs the .nupkg file at path and writes the signed package to
+// outputPath, which may be path itself. A package that is already signed is refused with
+// signatures.ErrPackageAlreadySigned, unless overwrite is set, in which case its signature
+// is replaced. The output is written to a temporary file first, so a failure leaves
+// outputPath as it was.
+//
+// Reference: NuGet.Client SigningUtility.SignAsync
+func SignPackageFile(path, outputPath string, opts signatures.SigningOptions, overwrite bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	var unsigned bytes.Buffer
+	signed, err := signatures.RemovePackageSignature(bytes.NewReader(data), &unsigned)
+	if err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+	if signed {
+		if !overwrite {
+			return signatures.ErrPackageAlreadySigned
+		}
+		data = unsigned.Bytes()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(outputPath), ".sign-*")
+	if err != nil {
+		return fmt.Errorf("create signed package: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := signatures.SignPackage(bytes.NewReader(data), tmp, opts); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write signed package: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("write signed package: %w", err)
+	}
+	return os.Rename(tmp.Name(), outputPath)
+}
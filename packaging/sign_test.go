@@ -0,0 +1,53 @@
+package packaging
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/willibrandon/gonuget/packaging/signatures"
+)
+
+func TestSignPackageFile(t *testing.T) {
+	cert, key, chain, err := signatures.LoadSigningCertificateFromPFX("signatures/testdata/signing-chain.pfx", "gonuget")
+	if err != nil {
+		t.Fatalf("LoadSigningCertificateFromPFX() error = %v", err)
+	}
+	opts := signatures.DefaultSigningOptions(cert, key)
+	opts.CertificateChain = chain
+
+	data, err := os.ReadFile("testdata/TestUpdatePackage.1.0.1.nupkg")
+	if err != nil {
+		t.Skipf("Test package not found: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "TestUpdatePackage.1.0.1.nupkg")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SignPackageFile(path, path, opts, false); err != nil {
+		t.Fatalf("SignPackageFile() error = %v", err)
+	}
+
+	verifyOpts := signatures.DefaultVerificationOptions()
+	verifyOpts.AllowUntrustedRoot = true
+	if r := VerifyPackageFile(path, verifyOpts); r.Status != PackageVerificationValid {
+		t.Errorf("signed package: Status = %s, errors = %v", r.Status, r.Errors)
+	}
+
+	if err := SignPackageFile(path, path, opts, false); !errors.Is(err, signatures.ErrPackageAlreadySigned) {
+		t.Errorf("SignPackageFile() of a signed package error = %v, want ErrPackageAlreadySigned", err)
+	}
+
+	// Overwriting replaces the signature, and writes to another directory
+	output := filepath.Join(t.TempDir(), "signed", "TestUpdatePackage.1.0.1.nupkg")
+	opts.HashAlgorithm = signatures.HashAlgorithmSHA512
+	if err := SignPackageFile(path, output, opts, true); err != nil {
+		t.Fatalf("SignPackageFile(overwrite) error = %v", err)
+	}
+	r := VerifyPackageFile(output, verifyOpts)
+	if r.Status != PackageVerificationValid {
+		t.Errorf("re-signed package: Status = %s, errors = %v", r.Status, r.Errors)
+	}
+}
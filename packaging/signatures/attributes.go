@@ -76,7 +76,7 @@ func createContentTypeAttribute() (Attribute, error) {
 		return Attribute{}, err
 	}
 
-	values, err := asn1.Marshal([]asn1.RawValue{{FullBytes: value}})
+	values, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: value}}, "set")
 	if err != nil {
 		return Attribute{}, err
 	}
@@ -98,7 +98,7 @@ func createSigningTimeAttribute(t time.Time) (Attribute, error) {
 		return Attribute{}, err
 	}
 
-	values, err := asn1.Marshal([]asn1.RawValue{{FullBytes: value}})
+	values, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: value}}, "set")
 	if err != nil {
 		return Attribute{}, err
 	}
@@ -120,7 +120,7 @@ func createMessageDigestAttribute(digest []byte) (Attribute, error) {
 		return Attribute{}, err
 	}
 
-	values, err := asn1.Marshal([]asn1.RawValue{{FullBytes: value}})
+	values, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: value}}, "set")
 	if err != nil {
 		return Attribute{}, err
 	}
@@ -134,7 +134,7 @@ func createMessageDigestAttribute(digest []byte) (Attribute, error) {
 // createCommitmentTypeIndicationAttribute creates the commitment-type-indication attribute (RFC 5126 Section 5.11.1).
 // This attribute indicates the commitment type of the signer. NuGet uses this to distinguish
 // between Author signatures (created by package authors) and Repository signatures (created by repositories).
-// The attribute value is the commitment type OID without a SEQUENCE wrapper, matching NuGet.Client behavior.
+// The attribute value is a CommitmentTypeIndication holding the signature type OID, as NuGet.Client reads it.
 // Returns an Attribute with type oidCommitmentTypeIndication containing the signature type OID.
 func createCommitmentTypeIndicationAttribute(sigType SignatureType) (Attribute, error) {
 	var commitmentOID asn1.ObjectIdentifier
@@ -147,9 +147,9 @@ func createCommitmentTypeIndicationAttribute(sigType SignatureType) (Attribute,
 		return Attribute{}, fmt.Errorf("unknown signature type: %s", sigType)
 	}
 
-	// Per NuGet.Client: CommitmentTypeIndication is just an OID (not SEQUENCE wrapper)
-	// Reference: CertificateUtility.cs GetCommitmentTypeIndication
-	value, err := asn1.Marshal(commitmentOID)
+	// CommitmentTypeIndication ::= SEQUENCE { commitmentTypeId OID, ... }
+	// Reference: NuGet.Client CommitmentTypeIndication.cs Read
+	value, err := asn1.Marshal(CommitmentTypeIndication{CommitmentTypeID: commitmentOID})
 	if err != nil {
 		return Attribute{}, err
 	}
@@ -185,9 +185,9 @@ func createSigningCertificateV2Attribute(cert *x509.Certificate, hashAlg HashAlg
 	hasher.Write(cert.Raw)
 	certHash := hasher.Sum(nil)
 
-	// Build IssuerSerial
+	// Build IssuerSerial; the issuer is a GeneralName directoryName ([4] EXPLICIT Name)
 	issuerSerial := IssuerSerial{
-		Issuer:       []asn1.RawValue{{FullBytes: cert.RawIssuer}},
+		Issuer:       []asn1.RawValue{{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: cert.RawIssuer}},
 		SerialNumber: cert.SerialNumber,
 	}
 
@@ -210,7 +210,7 @@ func createSigningCertificateV2Attribute(cert *x509.Certificate, hashAlg HashAlg
 		return Attribute{}, err
 	}
 
-	values, err := asn1.Marshal([]asn1.RawValue{{FullBytes: value}})
+	values, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: value}}, "set")
 	if err != nil {
 		return Attribute{}, err
 	}
@@ -221,6 +221,55 @@ func createSigningCertificateV2Attribute(cert *x509.Certificate, hashAlg HashAlg
 	}, nil
 }
 
+// createNuGetV3ServiceIndexURLAttribute creates the nuget-v3-service-index-url attribute.
+// Repository signatures use it to record the service index URL of the repository that
+// signed the package, so clients can detect packages signed for a different repository.
+// Returns an Attribute with type oidNuGetV3ServiceIndexURL containing the URL as an IA5String.
+func createNuGetV3ServiceIndexURLAttribute(serviceIndexURL string) (Attribute, error) {
+	// NuGetV3ServiceIndexUrl ::= IA5String
+	value, err := asn1.MarshalWithParams(serviceIndexURL, "ia5")
+	if err != nil {
+		return Attribute{}, err
+	}
+
+	values, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: value}}, "set")
+	if err != nil {
+		return Attribute{}, err
+	}
+
+	return Attribute{
+		Type:   oidNuGetV3ServiceIndexURL,
+		Values: asn1.RawValue{FullBytes: values},
+	}, nil
+}
+
+// createNuGetPackageOwnersAttribute creates the nuget-package-owners attribute.
+// Repository signatures use it to record the owners of the package on the repository,
+// so clients can trust a repository's packages from some owners only.
+// Returns an Attribute with type oidNuGetPackageOwners containing a SEQUENCE OF UTF8String.
+func createNuGetPackageOwnersAttribute(owners []string) (Attribute, error) {
+	// NuGetPackageOwners ::= SEQUENCE SIZE (1..MAX) OF NuGetPackageOwner
+	// NuGetPackageOwner ::= UTF8String (SIZE (1..MAX))
+	ownerValues := make([]asn1.RawValue, len(owners))
+	for i, owner := range owners {
+		ownerValues[i] = asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagUTF8String, Bytes: []byte(owner)}
+	}
+	value, err := asn1.Marshal(ownerValues)
+	if err != nil {
+		return Attribute{}, err
+	}
+
+	values, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: value}}, "set")
+	if err != nil {
+		return Attribute{}, err
+	}
+
+	return Attribute{
+		Type:   oidNuGetPackageOwners,
+		Values: asn1.RawValue{FullBytes: values},
+	}, nil
+}
+
 // EncodeAttributesForSigning encodes authenticated attributes for signing using DER with SET tag.
 // Per RFC 5652 Section 5.3, the signature is computed over the DER encoding of the signedAttrs
 // field with the SET OF tag. This function marshals the attributes as a SET (tag 17, constructed)
@@ -2,7 +2,9 @@ package signatures
 
 import (
 	"archive/zip"
+	"bytes"
 	"crypto/sha512"
+	"encoding/asn1"
 	"encoding/base64"
 	"encoding/binary"
 	"fmt"
@@ -15,24 +17,161 @@ import (
 // This matches NuGet.Client's SignedPackageArchiveUtility.GetPackageContentHash behavior.
 // Reference: NuGet.Client SignedPackageArchiveUtility.cs GetPackageContentHash
 func GetPackageContentHash(r io.ReadSeeker) (string, error) {
+	hash := sha512.New()
+	signed, err := hashPackageContent(r, hash)
+	if err != nil || !signed {
+		// Not a signed package, return empty string to indicate unsigned
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
+// VerifyPackageContentHash checks that the content of a signed package is the content
+// its signature was made for, so a package modified after signing is detected.
+//
+// NuGet signatures carry the package hash in their signed content
+// ("Version:1\n\n<hash algorithm OID>-Hash:<base64 hash>"); detached signatures, as
+// created by SignPackageData, carry it in the message-digest attribute.
+// Reference: NuGet.Client SignedPackageArchive.cs ValidateIntegrityAsync
+func VerifyPackageContentHash(sig *PrimarySignature, pkg io.ReadSeeker) error {
+	hashAlg, expected, err := signedContentHash(sig)
+	if err != nil {
+		return err
+	}
+
+	hash := getCryptoHash(hashAlg).New()
+	signed, err := hashPackageContent(pkg, hash)
+	if err != nil {
+		return fmt.Errorf("hash package content: %w", err)
+	}
+	if !signed {
+		return fmt.Errorf("package does not contain a signature file")
+	}
+
+	if !bytes.Equal(hash.Sum(nil), expected) {
+		return fmt.Errorf("package content hash does not match the signature (the package was modified after it was signed)")
+	}
+	return nil
+}
+
+// signedContentHash returns the hash algorithm and the package content hash a signature was made for.
+func signedContentHash(sig *PrimarySignature) (HashAlgorithmName, []byte, error) {
+	if sig.SignedData == nil || len(sig.SignedData.SignerInfos) == 0 {
+		return "", nil, fmt.Errorf("signature has no signer info")
+	}
+
+	if content := sig.SignedData.ContentInfo.Content.Bytes; len(content) > 0 {
+		var data []byte
+		if _, err := asn1.Unmarshal(content, &data); err != nil {
+			return "", nil, fmt.Errorf("unmarshal signature content: %w", err)
+		}
+		return parseSignatureContent(data)
+	}
+
+	digest := extractMessageDigest(sig.SignedData.SignerInfos[0])
+	if digest == nil {
+		return "", nil, fmt.Errorf("signature has no message digest")
+	}
+	if sig.HashAlgorithm == "" {
+		return "", nil, fmt.Errorf("signature has an unsupported digest algorithm")
+	}
+	return sig.HashAlgorithm, digest, nil
+}
+
+// parseSignatureContent reads the package hash from the content of a NuGet signature:
+//
+//	Version:1
+//
+//	2.16.840.1.101.3.4.2.1-Hash:<base64 hash>
+//
+// Reference: NuGet.Client SignatureContent.cs
+func parseSignatureContent(data []byte) (HashAlgorithmName, []byte, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if strings.TrimSpace(lines[0]) != "Version:1" {
+		return "", nil, fmt.Errorf("unsupported signature content version %q", lines[0])
+	}
+
+	for _, line := range lines[1:] {
+		name, value, ok := strings.Cut(line, ":")
+		oid, isHash := strings.CutSuffix(name, "-Hash")
+		if !ok || !isHash {
+			continue
+		}
+
+		hashAlg := hashAlgorithmFromOIDString(oid)
+		if hashAlg == "" {
+			return "", nil, fmt.Errorf("unsupported package hash algorithm %s", oid)
+		}
+		hash, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return "", nil, fmt.Errorf("decode package hash: %w", err)
+		}
+		return hashAlg, hash, nil
+	}
+
+	return "", nil, fmt.Errorf("signature content has no package hash")
+}
+
+// hashAlgorithmFromOIDString converts a dotted OID string to a hash algorithm name
+func hashAlgorithmFromOIDString(oid string) HashAlgorithmName {
+	for _, known := range []asn1.ObjectIdentifier{oidSHA256, oidSHA384, oidSHA512} {
+		if known.String() == oid {
+			return oidToHashAlgorithm(known)
+		}
+	}
+	return ""
+}
+
+// extractMessageDigest extracts the message-digest signed attribute.
+// Returns nil when the attribute is absent.
+func extractMessageDigest(signerInfo SignerInfo) []byte {
+	data := signerInfo.SignedAttrs.Bytes
+
+	for len(data) > 0 {
+		var attr Attribute
+		rest, err := asn1.Unmarshal(data, &attr)
+		if err != nil {
+			break
+		}
+		data = rest
+
+		if !attr.Type.Equal(oidMessageDigest) {
+			continue
+		}
+
+		var digest []byte
+		if _, err := asn1.Unmarshal(attr.Values.Bytes, &digest); err != nil {
+			return nil
+		}
+		return digest
+	}
+
+	return nil
+}
+
+// hashPackageContent writes the content of a signed package, as if its signature file
+// were not there, to hash. It reports false without writing anything when the package
+// is not signed.
+func hashPackageContent(r io.ReadSeeker, hash io.Writer) (bool, error) {
 	// Seek to start
 	if _, err := r.Seek(0, io.SeekStart); err != nil {
-		return "", fmt.Errorf("seek to start: %w", err)
+		return false, fmt.Errorf("seek to start: %w", err)
 	}
 
 	// Read as ZIP archive to find signature file
 	size, err := r.Seek(0, io.SeekEnd)
 	if err != nil {
-		return "", fmt.Errorf("get size: %w", err)
+		return false, fmt.Errorf("get size: %w", err)
 	}
 	if _, err := r.Seek(0, io.SeekStart); err != nil {
-		return "", fmt.Errorf("seek to start: %w", err)
+		return false, fmt.Errorf("seek to start: %w", err)
 	}
 
 	// Open ZIP to check for signature
 	zipReader, err := zip.NewReader(r.(io.ReaderAt), size)
 	if err != nil {
-		return "", fmt.Errorf("open zip: %w", err)
+		return false, fmt.Errorf("open zip: %w", err)
 	}
 
 	// Find signature file
@@ -46,34 +185,32 @@ func GetPackageContentHash(r io.ReadSeeker) (string, error) {
 
 	if signatureFile == nil {
 		// Not a signed package, return empty string to indicate unsigned
-		return "", nil
+		return false, nil
 	}
 
 	// Read ZIP metadata for signed package
 	metadata, err := readSignedArchiveMetadata(r)
 	if err != nil {
-		return "", fmt.Errorf("read archive metadata: %w", err)
+		return false, fmt.Errorf("read archive metadata: %w", err)
 	}
 
 	// Calculate hash excluding signature
-	hash := sha512.New()
-
 	// Hash from start to beginning of file headers
 	if _, err := r.Seek(0, io.SeekStart); err != nil {
-		return "", err
+		return false, err
 	}
 	if err := hashUntilPosition(r, hash, metadata.StartOfLocalFileHeaders); err != nil {
-		return "", err
+		return false, err
 	}
 
 	// Hash all file entries except signature
 	entriesWithoutSig := removeSignatureAndSortByOffset(metadata)
 	for _, entry := range entriesWithoutSig {
 		if _, err := r.Seek(entry.OffsetToLocalFileHeader, io.SeekStart); err != nil {
-			return "", err
+			return false, err
 		}
 		if err := hashUntilPosition(r, hash, entry.OffsetToLocalFileHeader+entry.FileEntryTotalSize); err != nil {
-			return "", err
+			return false, err
 		}
 	}
 
@@ -85,22 +222,22 @@ func GetPackageContentHash(r io.ReadSeeker) (string, error) {
 	// Hash central directory records with adjusted offsets
 	for _, entry := range entriesWithoutSig {
 		if _, err := r.Seek(entry.Position, io.SeekStart); err != nil {
-			return "", err
+			return false, err
 		}
 
 		// Hash up to relative offset field (42 bytes from start of central directory header)
 		if err := hashUntilPosition(r, hash, entry.Position+42); err != nil {
-			return "", err
+			return false, err
 		}
 
 		// Read and adjust relative offset
 		var relativeOffset uint32
 		if err := binary.Read(r, binary.LittleEndian, &relativeOffset); err != nil {
-			return "", err
+			return false, err
 		}
 		adjustedOffset := uint32(int64(relativeOffset) + entry.ChangeInOffset)
 		if err := binary.Write(hash, binary.LittleEndian, adjustedOffset); err != nil {
-			return "", err
+			return false, err
 		}
 
 		// Hash remaining header fields (filename, extra field, comment)
@@ -108,64 +245,73 @@ func GetPackageContentHash(r io.ReadSeeker) (string, error) {
 		currentPos, _ := r.Seek(0, io.SeekCurrent)
 		remainingSize := entry.HeaderSize - 46 // 46 = fixed fields size
 		if err := hashUntilPosition(r, hash, currentPos+remainingSize); err != nil {
-			return "", err
+			return false, err
 		}
 	}
 
 	// Hash End of Central Directory Record with adjustments
 	if _, err := r.Seek(metadata.EndOfCentralDirectory, io.SeekStart); err != nil {
-		return "", err
+		return false, err
 	}
 
 	// Hash first 8 bytes of EOCDR (signature + disk numbers)
 	if err := hashUntilPosition(r, hash, metadata.EndOfCentralDirectory+8); err != nil {
-		return "", err
+		return false, err
 	}
 
 	// Read and adjust entry counts (subtract 1 for signature file)
 	var totalEntries, totalEntriesOnDisk uint16
 	if err := binary.Read(r, binary.LittleEndian, &totalEntries); err != nil {
-		return "", err
+		return false, err
 	}
 	if err := binary.Read(r, binary.LittleEndian, &totalEntriesOnDisk); err != nil {
-		return "", err
+		return false, err
 	}
 	if err := binary.Write(hash, binary.LittleEndian, totalEntries-1); err != nil {
-		return "", err
+		return false, err
 	}
 	if err := binary.Write(hash, binary.LittleEndian, totalEntriesOnDisk-1); err != nil {
-		return "", err
+		return false, err
 	}
 
 	// Read and adjust central directory size (subtract signature header size)
 	var cdSize uint32
 	if err := binary.Read(r, binary.LittleEndian, &cdSize); err != nil {
-		return "", err
+		return false, err
 	}
 	sigHeader := metadata.CentralDirectoryHeaders[metadata.SignatureCentralDirectoryHeaderIndex]
 	adjustedCDSize := uint32(int64(cdSize) - sigHeader.HeaderSize)
 	if err := binary.Write(hash, binary.LittleEndian, adjustedCDSize); err != nil {
-		return "", err
+		return false, err
 	}
 
 	// Read and adjust central directory offset (subtract signature file entry size)
 	var cdOffset uint32
 	if err := binary.Read(r, binary.LittleEndian, &cdOffset); err != nil {
-		return "", err
+		return false, err
 	}
 	adjustedCDOffset := uint32(int64(cdOffset) - sigHeader.FileEntryTotalSize)
 	if err := binary.Write(hash, binary.LittleEndian, adjustedCDOffset); err != nil {
-		return "", err
+		return false, err
 	}
 
-	// Hash remaining EOCDR fields
-	endSize, _ := r.Seek(0, io.SeekEnd)
-	currentPos, _ := r.Seek(0, io.SeekCurrent)
-	if err := hashUntilPosition(r, hash, endSize); err != nil && currentPos < endSize {
-		return "", err
+	// Hash remaining EOCDR fields (comment length and comment)
+	currentPos, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return false, err
+	}
+	endSize, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return false, err
+	}
+	if _, err := r.Seek(currentPos, io.SeekStart); err != nil {
+		return false, err
+	}
+	if err := hashUntilPosition(r, hash, endSize); err != nil {
+		return false, err
 	}
 
-	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+	return true, nil
 }
 
 // SignedPackageArchiveMetadata holds metadata about a signed package archive
@@ -238,7 +384,16 @@ func readSignedArchiveMetadata(r io.ReadSeeker) (*SignedPackageArchiveMetadata,
 		if err != nil {
 			return nil, err
 		}
-		cdMetadata.FileEntryTotalSize = int64(30 + uint32(localHeader.FileNameLength) + uint32(localHeader.ExtraFieldLength) + localHeader.CompressedSize)
+		// The local header has no sizes when they follow the data in a data descriptor;
+		// the central directory header always has them
+		cdMetadata.FileEntryTotalSize = 30 + int64(localHeader.FileNameLength) + int64(localHeader.ExtraFieldLength) + int64(header.CompressedSize)
+		if localHeader.GeneralPurposeBitFlag&0x8 != 0 {
+			descriptorSize, err := readDataDescriptorSize(r, cdMetadata.OffsetToLocalFileHeader+cdMetadata.FileEntryTotalSize)
+			if err != nil {
+				return nil, err
+			}
+			cdMetadata.FileEntryTotalSize += descriptorSize
+		}
 
 		metadata.StartOfLocalFileHeaders = min(metadata.StartOfLocalFileHeaders, cdMetadata.OffsetToLocalFileHeader)
 
@@ -285,6 +440,22 @@ func removeSignatureAndSortByOffset(metadata *SignedPackageArchiveMetadata) []Ce
 	return result
 }
 
+// readDataDescriptorSize returns the size of the data descriptor at offset, whose
+// signature is optional.
+func readDataDescriptorSize(r io.ReadSeeker, offset int64) (int64, error) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+	var signature uint32
+	if err := binary.Read(r, binary.LittleEndian, &signature); err != nil {
+		return 0, fmt.Errorf("read data descriptor: %w", err)
+	}
+	if signature == 0x08074b50 {
+		return 16, nil
+	}
+	return 12, nil
+}
+
 func hashUntilPosition(r io.Reader, h io.Writer, endPos int64) error {
 	if seeker, ok := r.(io.Seeker); ok {
 		currentPos, _ := seeker.Seek(0, io.SeekCurrent)
@@ -3,6 +3,9 @@ package signatures
 import (
 	"archive/zip"
 	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
 	"encoding/base64"
 	"io"
 	"os"
@@ -552,3 +555,160 @@ func createTestPackageReader(tb testing.TB, files map[string]string) *bytes.Read
 
 	return bytes.NewReader(buf.Bytes())
 }
+
+func TestVerifyPackageContentHash_SignatureContent(t *testing.T) {
+	data, err := os.ReadFile("../testdata/TestPackage.AuthorSigned.1.0.0.nupkg")
+	if err != nil {
+		t.Skipf("Test package not found: %v", err)
+	}
+
+	sig := readTestPackageSignature(t, data)
+	if err := VerifyPackageContentHash(sig, bytes.NewReader(data)); err != nil {
+		t.Fatalf("VerifyPackageContentHash() error = %v", err)
+	}
+
+	// Change one byte of the nuspec after signing
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var offset int64 = -1
+	for _, f := range zipReader.File {
+		if strings.HasSuffix(f.Name, ".nuspec") {
+			if offset, err = f.DataOffset(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if offset < 0 {
+		t.Fatal("nuspec not found")
+	}
+	tampered := bytes.Clone(data)
+	tampered[offset] ^= 0xFF
+
+	err = VerifyPackageContentHash(sig, bytes.NewReader(tampered))
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("VerifyPackageContentHash() error = %v, want a content hash mismatch", err)
+	}
+}
+
+func TestVerifyPackageContentHash_MessageDigest(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	signerCert, signerKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+
+	// The content hash excludes the signature file, so it can be computed with a placeholder
+	unsigned := writeSignedTestPackage(t, "binary content", []byte("placeholder"))
+	hash := sha256.New()
+	if _, err := hashPackageContent(bytes.NewReader(unsigned), hash); err != nil {
+		t.Fatalf("hashPackageContent() error = %v", err)
+	}
+
+	sigData, err := SignPackageData(hash.Sum(nil), SigningOptions{
+		Certificate:      signerCert,
+		PrivateKey:       signerKey,
+		CertificateChain: []*x509.Certificate{rootCert},
+		SignatureType:    SignatureTypeAuthor,
+		HashAlgorithm:    HashAlgorithmSHA256,
+	})
+	if err != nil {
+		t.Fatalf("SignPackageData() error = %v", err)
+	}
+	sig, err := ReadSignature(sigData)
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v", err)
+	}
+
+	signed := writeSignedTestPackage(t, "binary content", sigData)
+	if err := VerifyPackageContentHash(sig, bytes.NewReader(signed)); err != nil {
+		t.Errorf("VerifyPackageContentHash() error = %v", err)
+	}
+
+	modified := writeSignedTestPackage(t, "modified content", sigData)
+	if err := VerifyPackageContentHash(sig, bytes.NewReader(modified)); err == nil {
+		t.Error("VerifyPackageContentHash() succeeded for a package modified after signing")
+	}
+}
+
+func TestParseSignatureContent(t *testing.T) {
+	hash := sha512.Sum512([]byte("package"))
+	content := "Version:1\n\n2.16.840.1.101.3.4.2.3-Hash:" + base64.StdEncoding.EncodeToString(hash[:]) + "\n\n"
+
+	hashAlg, got, err := parseSignatureContent([]byte(content))
+	if err != nil {
+		t.Fatalf("parseSignatureContent() error = %v", err)
+	}
+	if hashAlg != HashAlgorithmSHA512 || !bytes.Equal(got, hash[:]) {
+		t.Errorf("parseSignatureContent() = %s, %x, want SHA512, %x", hashAlg, got, hash)
+	}
+
+	for _, invalid := range []string{
+		"Version:2\n\n2.16.840.1.101.3.4.2.1-Hash:AAAA\n\n",
+		"Version:1\n\n1.2.3-Hash:AAAA\n\n",
+		"Version:1\n\n",
+	} {
+		if _, _, err := parseSignatureContent([]byte(invalid)); err == nil {
+			t.Errorf("parseSignatureContent(%q) succeeded, want error", invalid)
+		}
+	}
+}
+
+// readTestPackageSignature reads the primary signature of a package
+func readTestPackageSignature(t *testing.T, data []byte) *PrimarySignature {
+	t.Helper()
+
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zipReader.File {
+		if f.Name != ".signature.p7s" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigData, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := ReadSignature(sigData)
+		if err != nil {
+			t.Fatalf("ReadSignature() error = %v", err)
+		}
+		return sig
+	}
+
+	t.Fatal("package is not signed")
+	return nil
+}
+
+// writeSignedTestPackage creates a package with the given library content whose
+// signature file, stored last like NuGet does, holds sigData
+func writeSignedTestPackage(t *testing.T, content string, sigData []byte) []byte {
+	t.Helper()
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for _, entry := range []struct {
+		name string
+		data []byte
+	}{
+		{"test.nuspec", []byte("<?xml version='1.0'?><package></package>")},
+		{"lib/test.dll", []byte(content)},
+		{".signature.p7s", sigData},
+	} {
+		f, err := w.Create(entry.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write(entry.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,195 @@
+package signatures
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// crlTimeout is the timeout of CRL downloads
+	crlTimeout = 30 * time.Second
+
+	// maxCRLSize bounds the size of the CRLs downloaded
+	maxCRLSize = 32 << 20
+)
+
+var crlHTTPClient = &http.Client{Timeout: crlTimeout}
+
+// CRLCache holds certificate revocation lists. CRLs can be pre-seeded with AddCRL or
+// AddCRLFile, so that offline verification can check revocation, and online
+// verification adds the CRLs it downloads. It is safe for concurrent use.
+type CRLCache struct {
+	mu   sync.Mutex
+	crls []*x509.RevocationList
+}
+
+// NewCRLCache creates an empty CRL cache.
+func NewCRLCache() *CRLCache {
+	return &CRLCache{}
+}
+
+// AddCRL adds a DER or PEM encoded CRL. Its signature is checked when it is used,
+// against the issuer of the certificate being checked.
+func (c *CRLCache) AddCRL(data []byte) error {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "X509 CRL" {
+			return fmt.Errorf("unexpected PEM block %q, want X509 CRL", block.Type)
+		}
+		data = block.Bytes
+	}
+
+	crl, err := x509.ParseRevocationList(data)
+	if err != nil {
+		return fmt.Errorf("parse CRL: %w", err)
+	}
+	c.add(crl)
+	return nil
+}
+
+// AddCRLFile adds the DER or PEM encoded CRL read from path.
+func (c *CRLCache) AddCRLFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := c.AddCRL(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func (c *CRLCache) add(crl *x509.RevocationList) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.crls = append(c.crls, crl)
+}
+
+// find returns the most recent CRL signed by issuer, or nil when there is none
+func (c *CRLCache) find(issuer *x509.Certificate) *x509.RevocationList {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var latest *x509.RevocationList
+	for _, crl := range c.crls {
+		if !bytes.Equal(crl.RawIssuer, issuer.RawSubject) || crl.CheckSignatureFrom(issuer) != nil {
+			continue
+		}
+		if latest == nil || crl.ThisUpdate.After(latest.ThisUpdate) {
+			latest = crl
+		}
+	}
+	return latest
+}
+
+// checkCRL checks with the CRL of issuer that cert has not been revoked. The cached CRL
+// is used while it is current; otherwise, when download is set, the CRL is downloaded
+// from the certificate's CRL distribution points and cached. It returns a
+// *RevocationUnavailableError when no current CRL is available.
+// Reference: RFC 5280 section 6.3
+func (c *revocationChecker) checkCRL(cert, issuer *x509.Certificate, download bool) error {
+	now := c.now
+	var crl *x509.RevocationList
+	if c.crlCache != nil {
+		crl = c.crlCache.find(issuer)
+	}
+
+	if download && (crl == nil || crlExpired(crl, now)) {
+		fresh, err := downloadCRL(c.httpClient(crlHTTPClient), cert, issuer)
+		switch {
+		case err == nil:
+			crl = fresh
+			if c.crlCache != nil {
+				c.crlCache.add(fresh)
+			}
+		case crl == nil:
+			return err
+		}
+	}
+
+	if crl == nil {
+		return &RevocationUnavailableError{Certificate: cert, Err: errors.New("no CRL of the issuer is available")}
+	}
+	if crl.ThisUpdate.After(now.Add(ocspClockSkew)) {
+		return fmt.Errorf("invalid CRL: it is not valid before %s", crl.ThisUpdate.UTC().Format(time.RFC3339))
+	}
+	if crlExpired(crl, now) {
+		return &RevocationUnavailableError{Certificate: cert, Err: fmt.Errorf("the CRL expired at %s", crl.NextUpdate.UTC().Format(time.RFC3339))}
+	}
+
+	for _, entry := range crl.RevokedCertificateEntries {
+		if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+			return &RevokedCertificateError{Certificate: cert, RevokedAt: entry.RevocationTime}
+		}
+	}
+	return nil
+}
+
+// crlExpired reports whether crl is past its nextUpdate time. A CRL without one never
+// expires.
+func crlExpired(crl *x509.RevocationList, now time.Time) bool {
+	return !crl.NextUpdate.IsZero() && crl.NextUpdate.Before(now.Add(-ocspClockSkew))
+}
+
+// downloadCRL downloads the CRL of issuer from the first HTTP CRL distribution point of
+// cert that serves one, and checks it was signed by issuer.
+func downloadCRL(client *http.Client, cert, issuer *x509.Certificate) (*x509.RevocationList, error) {
+	var errs []error
+	for _, url := range cert.CRLDistributionPoints {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			continue
+		}
+
+		data, err := fetchCRL(client, url)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		crl, err := x509.ParseRevocationList(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CRL from %s: %w", url, err)
+		}
+		if !bytes.Equal(crl.RawIssuer, issuer.RawSubject) {
+			return nil, fmt.Errorf("invalid CRL from %s: it was not issued by %s", url, issuer.Subject)
+		}
+		if err := crl.CheckSignatureFrom(issuer); err != nil {
+			return nil, fmt.Errorf("invalid CRL from %s: %w", url, err)
+		}
+		return crl, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, &RevocationUnavailableError{Certificate: cert, Err: errors.New("the certificate names no HTTP CRL distribution point")}
+	}
+	return nil, &RevocationUnavailableError{Certificate: cert, Err: errors.Join(errs...)}
+}
+
+// fetchCRL downloads the CRL at url
+func fetchCRL(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CRL distribution point %s returned HTTP %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCRLSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read CRL from %s: %w", url, err)
+	}
+	if len(data) > maxCRLSize {
+		return nil, fmt.Errorf("CRL from %s is larger than %d bytes", url, maxCRLSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,331 @@
+package signatures
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestCRL returns a DER encoded CRL of issuer revoking the serial numbers revoked
+func newTestCRL(t *testing.T, issuer *x509.Certificate, issuerKey *rsa.PrivateKey, thisUpdate, nextUpdate time.Time, revoked ...int64) []byte {
+	t.Helper()
+
+	template := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: thisUpdate,
+		NextUpdate: nextUpdate,
+	}
+	for _, serial := range revoked {
+		template.RevokedCertificateEntries = append(template.RevokedCertificateEntries, x509.RevocationListEntry{
+			SerialNumber:   big.NewInt(serial),
+			RevocationTime: thisUpdate.Add(-time.Hour),
+		})
+	}
+
+	crl, err := x509.CreateRevocationList(rand.Reader, template, issuer, issuerKey)
+	if err != nil {
+		t.Fatalf("failed to create CRL: %v", err)
+	}
+	return crl
+}
+
+// startTestCRLServer serves the CRL returned by crl and counts the requests
+func startTestCRLServer(t *testing.T, crl func() []byte) (string, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/pkix-crl")
+		_, _ = w.Write(crl())
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/root.crl", &requests
+}
+
+// newCRLTestSignature returns a signature whose signer certificate (serial number 42)
+// names only a CRL distribution point, serving the CRL built by configure
+func newCRLTestSignature(t *testing.T, configure func(root *x509.Certificate, rootKey *rsa.PrivateKey) []byte) (*PrimarySignature, VerificationOptions, *atomic.Int32) {
+	t.Helper()
+
+	var crl []byte
+	crlURL, requests := startTestCRLServer(t, func() []byte { return crl })
+	sig, opts, rootKey := newRevocationTestSignature(t, "", crlURL)
+	crl = configure(sig.Certificates[1], rootKey)
+	return sig, opts, requests
+}
+
+func TestVerifySignature_CRLRevocation(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		revoked    []int64
+		nextUpdate time.Time
+		wantValid  bool
+		wantErr    string
+	}{
+		{"good", []int64{7}, now.Add(time.Hour), true, ""},
+		{"revoked serial", []int64{7, 42}, now.Add(time.Hour), false, "was revoked"},
+		{"expired CRL", nil, now.Add(-time.Hour), false, "expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, opts, _ := newCRLTestSignature(t, func(root *x509.Certificate, rootKey *rsa.PrivateKey) []byte {
+				return newTestCRL(t, root, rootKey, now.Add(-2*time.Hour), tt.nextUpdate, tt.revoked...)
+			})
+			opts.AllowUnknownRevocation = false
+
+			result := VerifySignature(sig, opts)
+			if result.IsValid != tt.wantValid {
+				t.Fatalf("IsValid = %v, want %v (errors: %v)", result.IsValid, tt.wantValid, result.Errors)
+			}
+			if tt.wantErr != "" && (len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), tt.wantErr)) {
+				t.Errorf("errors = %v, want %q", result.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifySignature_CRLRevocation_ExpiredAllowed(t *testing.T) {
+	// An expired CRL leaves the revocation status unknown
+	sig, opts, _ := newCRLTestSignature(t, func(root *x509.Certificate, rootKey *rsa.PrivateKey) []byte {
+		return newTestCRL(t, root, rootKey, time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour))
+	})
+	opts.AllowUnknownRevocation = true
+
+	result := VerifySignature(sig, opts)
+	if !result.IsValid {
+		t.Fatalf("expected valid signature with AllowUnknownRevocation, got errors: %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "expired") {
+		t.Errorf("warnings = %v, want an expired CRL warning", result.Warnings)
+	}
+}
+
+func TestVerifySignature_CRLRevocation_OCSPFallback(t *testing.T) {
+	// The CRL is used when the OCSP responder doesn't know the certificate
+	responder := &testOCSPResponder{status: "unknown", nextUpdate: time.Now().Add(time.Hour)}
+	server := httptest.NewServer(responder)
+	t.Cleanup(server.Close)
+
+	var crl []byte
+	crlURL, requests := startTestCRLServer(t, func() []byte { return crl })
+	sig, opts, rootKey := newRevocationTestSignature(t, server.URL, crlURL)
+	responder.issuerKey = rootKey
+	crl = newTestCRL(t, sig.Certificates[1], rootKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), 42)
+	opts.AllowUnknownRevocation = true
+
+	result := VerifySignature(sig, opts)
+	if result.IsValid {
+		t.Fatal("expected the CRL to reveal the revocation")
+	}
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), "was revoked") {
+		t.Errorf("errors = %v, want a revocation error", result.Errors)
+	}
+	if responder.requests.Load() != 1 || requests.Load() != 1 {
+		t.Errorf("OCSP responder got %d requests and CRL distribution point %d, want 1 each", responder.requests.Load(), requests.Load())
+	}
+}
+
+func TestVerifySignature_CRLRevocation_Cache(t *testing.T) {
+	sig, opts, requests := newCRLTestSignature(t, func(root *x509.Certificate, rootKey *rsa.PrivateKey) []byte {
+		return newTestCRL(t, root, rootKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	})
+	opts.CRLCache = NewCRLCache()
+
+	for range 2 {
+		if result := VerifySignature(sig, opts); !result.IsValid {
+			t.Fatalf("expected valid signature, got errors: %v", result.Errors)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("CRL distribution point got %d requests, want 1", n)
+	}
+}
+
+func TestVerifySignature_CRLRevocation_InvalidSignature(t *testing.T) {
+	otherRoot, otherKey := generateTestRootCA(t)
+	sig, opts, _ := newCRLTestSignature(t, func(*x509.Certificate, *rsa.PrivateKey) []byte {
+		return newTestCRL(t, otherRoot, otherKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	})
+	opts.AllowUnknownRevocation = true
+
+	result := VerifySignature(sig, opts)
+	if result.IsValid {
+		t.Fatal("expected a CRL signed by another issuer to fail verification")
+	}
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), "invalid CRL") {
+		t.Errorf("errors = %v, want an invalid CRL error", result.Errors)
+	}
+}
+
+func TestVerifySignature_OfflineRevocation(t *testing.T) {
+	var crl []byte
+	sig, opts, requests := newCRLTestSignature(t, func(root *x509.Certificate, rootKey *rsa.PrivateKey) []byte {
+		crl = newTestCRL(t, root, rootKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), 42)
+		return crl
+	})
+	opts.RevocationMode = RevocationModeOffline
+	opts.AllowUnknownRevocation = false
+
+	// Without a pre-seeded CRL the status is unknown
+	result := VerifySignature(sig, opts)
+	if result.IsValid || len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), "no CRL") {
+		t.Fatalf("IsValid = %v, errors = %v, want a missing CRL error", result.IsValid, result.Errors)
+	}
+
+	// A CRL pre-seeded from disk reveals the revocation
+	path := filepath.Join(t.TempDir(), "root.crl")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), 0o600); err != nil {
+		t.Fatalf("failed to write CRL: %v", err)
+	}
+	opts.CRLCache = NewCRLCache()
+	if err := opts.CRLCache.AddCRLFile(path); err != nil {
+		t.Fatalf("AddCRLFile() error = %v", err)
+	}
+
+	result = VerifySignature(sig, opts)
+	if result.IsValid || len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), "was revoked") {
+		t.Errorf("IsValid = %v, errors = %v, want a revocation error", result.IsValid, result.Errors)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("CRL distribution point got %d requests offline, want 0", n)
+	}
+}
+
+func TestVerifySignature_RevocationDisabled(t *testing.T) {
+	sig, opts, requests := newCRLTestSignature(t, func(root *x509.Certificate, rootKey *rsa.PrivateKey) []byte {
+		return newTestCRL(t, root, rootKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), 42)
+	})
+
+	for _, mode := range []RevocationMode{RevocationModeDisabled, ""} {
+		opts.RevocationMode = mode
+		if result := VerifySignature(sig, opts); !result.IsValid {
+			t.Errorf("mode %q: expected revocation not to be checked, got errors: %v", mode, result.Errors)
+		}
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("CRL distribution point got %d requests, want 0", n)
+	}
+}
+
+func TestVerifySignature_IntermediateRevocation(t *testing.T) {
+	var rootCRL, intermediateCRL []byte
+	rootCRLURL, _ := startTestCRLServer(t, func() []byte { return rootCRL })
+	intermediateCRLURL, _ := startTestCRLServer(t, func() []byte { return intermediateCRL })
+
+	rootCert, rootKey := generateTestRootCA(t)
+	issue := func(template, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		template.NotBefore = time.Now().Add(-24 * time.Hour)
+		template.NotAfter = time.Now().Add(365 * 24 * time.Hour)
+		der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+		if err != nil {
+			t.Fatalf("failed to create certificate: %v", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			t.Fatalf("failed to parse certificate: %v", err)
+		}
+		return cert, key
+	}
+	intermediateCert, intermediateKey := issue(&x509.Certificate{
+		SerialNumber:          big.NewInt(7),
+		Subject:               pkix.Name{CommonName: "Test Intermediate CA"},
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		CRLDistributionPoints: []string{rootCRLURL},
+	}, rootCert, rootKey)
+	signerCert, _ := issue(&x509.Certificate{
+		SerialNumber:          big.NewInt(42),
+		Subject:               pkix.Name{CommonName: "Test Revocation Signer"},
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		CRLDistributionPoints: []string{intermediateCRLURL},
+	}, intermediateCert, intermediateKey)
+
+	// The root revoked the intermediate certificate; the signer certificate is good
+	rootCRL = newTestCRL(t, rootCert, rootKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour), 7)
+	intermediateCRL = newTestCRL(t, intermediateCert, intermediateKey, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+
+	sig := &PrimarySignature{
+		Type:              SignatureTypeAuthor,
+		SignerCertificate: signerCert,
+		Certificates:      []*x509.Certificate{signerCert, intermediateCert, rootCert},
+		HashAlgorithm:     HashAlgorithmSHA256,
+	}
+	opts := DefaultVerificationOptions()
+	opts.TrustStore.AddCertificate(rootCert)
+	opts.RevocationMode = RevocationModeOnline
+
+	for _, untrusted := range []bool{false, true} {
+		if untrusted {
+			// The chain is built from the signature's certificates
+			opts.TrustStore = NewTrustStore()
+			opts.AllowUntrustedRoot = true
+		}
+
+		result := VerifySignature(sig, opts)
+		if result.IsValid {
+			t.Fatalf("untrusted root %v: expected a revoked intermediate certificate to fail verification", untrusted)
+		}
+		var revoked []VerificationIssue
+		for _, issue := range result.Issues {
+			if issue.Code == CodeCertificateRevoked {
+				revoked = append(revoked, issue)
+			}
+		}
+		if len(revoked) != 1 || !strings.Contains(revoked[0].Message, "Test Intermediate CA") {
+			t.Errorf("untrusted root %v: Issues = %v, want the intermediate certificate revoked", untrusted, result.Issues)
+		}
+	}
+}
+
+func TestVerifySignature_RevocationTimeout(t *testing.T) {
+	// The CRL distribution point doesn't answer in time
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	sig, opts, _ := newRevocationTestSignature(t, "", server.URL+"/root.crl")
+	opts.RevocationTimeout = 200 * time.Millisecond
+
+	start := time.Now()
+	result := VerifySignature(sig, opts)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("verification took %s, want it bounded by RevocationTimeout", elapsed)
+	}
+	if !result.IsValid || len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "revocation status") {
+		t.Errorf("IsValid = %v, warnings = %v, want an unknown revocation warning", result.IsValid, result.Warnings)
+	}
+}
+
+func TestCRLCache_AddCRL_Invalid(t *testing.T) {
+	cache := NewCRLCache()
+	if err := cache.AddCRL([]byte("not a CRL")); err == nil {
+		t.Error("AddCRL() expected an error for invalid data")
+	}
+	if err := cache.AddCRL(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}})); err == nil {
+		t.Error("AddCRL() expected an error for a certificate")
+	}
+}
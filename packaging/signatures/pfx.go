@@ -0,0 +1,133 @@
+package signatures
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/pkcs12" //nolint:staticcheck // the only PKCS#12 decoder available to the module; it reads PFX files with intermediates via ToPEM
+)
+
+// ErrIncorrectPFXPassword is returned when a PFX file cannot be decrypted with the given password.
+var ErrIncorrectPFXPassword = errors.New("the PFX password is incorrect")
+
+// LoadSigningCertificateFromPFX loads a signing certificate, its private key and the rest
+// of its chain from a PFX (PKCS#12) file, as exported by a certificate authority.
+// The chain holds the issuers of the certificate in leaf-to-root order, ready for
+// SigningOptions.CertificateChain; certificates outside that chain are ignored.
+//
+// PFX files protected with 3DES or RC2 (e.g. openssl pkcs12 -export -legacy, or a
+// Windows certificate export) are supported; AES-protected files are not.
+func LoadSigningCertificateFromPFX(path, password string) (*x509.Certificate, crypto.PrivateKey, []*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("read PFX file: %w", err)
+	}
+
+	cert, key, chain, err := ParseSigningCertificatePFX(data, password)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cert, key, chain, nil
+}
+
+// ParseSigningCertificatePFX is LoadSigningCertificateFromPFX for PFX data in memory.
+func ParseSigningCertificatePFX(data []byte, password string) (*x509.Certificate, crypto.PrivateKey, []*x509.Certificate, error) {
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		var notImplemented pkcs12.NotImplementedError
+		switch {
+		case errors.Is(err, pkcs12.ErrIncorrectPassword):
+			return nil, nil, nil, ErrIncorrectPFXPassword
+		case errors.As(err, &notImplemented):
+			return nil, nil, nil, fmt.Errorf("unsupported PFX file (re-export it with 3DES encryption, e.g. openssl pkcs12 -export -legacy): %w", err)
+		}
+		return nil, nil, nil, fmt.Errorf("decode PFX: %w", err)
+	}
+
+	var key crypto.Signer
+	var certs []*x509.Certificate
+	for _, block := range blocks {
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("parse PFX certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		case "PRIVATE KEY":
+			if key != nil {
+				return nil, nil, nil, fmt.Errorf("PFX file contains more than one private key")
+			}
+			if key, err = parsePFXPrivateKey(block.Bytes); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	if key == nil {
+		return nil, nil, nil, fmt.Errorf("PFX file contains no private key")
+	}
+
+	// The signing certificate is the one the private key belongs to
+	var leaf *x509.Certificate
+	for _, cert := range certs {
+		if pub, ok := cert.PublicKey.(interface{ Equal(crypto.PublicKey) bool }); ok && pub.Equal(key.Public()) {
+			leaf = cert
+			break
+		}
+	}
+	if leaf == nil {
+		return nil, nil, nil, fmt.Errorf("PFX file contains no certificate for its private key")
+	}
+
+	return leaf, key, issuerChain(leaf, certs), nil
+}
+
+// parsePFXPrivateKey parses a private key as pkcs12.ToPEM encodes it: PKCS#1 for RSA
+// keys and SEC 1 for ECDSA keys.
+func parsePFXPrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("parse PFX private key: unsupported key type")
+}
+
+// issuerChain returns the issuers of leaf found in certs, from its issuer up to the root.
+func issuerChain(leaf *x509.Certificate, certs []*x509.Certificate) []*x509.Certificate {
+	var chain []*x509.Certificate
+	for current := leaf; !isSelfIssued(current); {
+		var issuer *x509.Certificate
+		for _, cert := range certs {
+			if cert != current && bytes.Equal(cert.RawSubject, current.RawIssuer) && current.CheckSignatureFrom(cert) == nil {
+				issuer = cert
+				break
+			}
+		}
+		// Stop at a missing issuer, and at a loop in a malformed file
+		if issuer == nil || issuer == leaf || containsCertificate(chain, issuer) {
+			break
+		}
+		chain = append(chain, issuer)
+		current = issuer
+	}
+	return chain
+}
+
+func isSelfIssued(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer)
+}
+
+func containsCertificate(certs []*x509.Certificate, cert *x509.Certificate) bool {
+	for _, c := range certs {
+		if c.Equal(cert) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package signatures
+
+import (
+	"crypto/sha256"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testdata/signing-chain.pfx holds a code signing certificate issued by an intermediate CA,
+// the intermediate and the root, stored root before intermediate, with password "gonuget".
+// It was exported with openssl pkcs12 -export -legacy; signing-chain-aes.pfx holds the
+// same certificates exported with OpenSSL 3's default AES encryption.
+
+func TestLoadSigningCertificateFromPFX(t *testing.T) {
+	cert, key, chain, err := LoadSigningCertificateFromPFX(filepath.Join("testdata", "signing-chain.pfx"), "gonuget")
+	if err != nil {
+		t.Fatalf("LoadSigningCertificateFromPFX() error = %v", err)
+	}
+
+	if cert.Subject.CommonName != "gonuget Test Signer" {
+		t.Errorf("certificate = %s, want the signer", cert.Subject.CommonName)
+	}
+	var names []string
+	for _, c := range chain {
+		names = append(names, c.Subject.CommonName)
+	}
+	if got, want := strings.Join(names, ", "), "gonuget Test Intermediate CA, gonuget Test Root CA"; got != want {
+		t.Errorf("chain = %s, want %s", got, want)
+	}
+
+	// The loaded certificate signs packages as is
+	opts := DefaultSigningOptions(cert, key)
+	opts.CertificateChain = chain
+	contentHash := sha256.Sum256([]byte("test content"))
+	signature, err := SignPackageData(contentHash[:], opts)
+	if err != nil {
+		t.Fatalf("SignPackageData() error = %v", err)
+	}
+	sig, err := ReadSignature(signature)
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v", err)
+	}
+	if len(sig.Certificates) != 3 {
+		t.Errorf("signature has %d certificates, want the signer and its chain", len(sig.Certificates))
+	}
+}
+
+func TestLoadSigningCertificateFromPFX_Errors(t *testing.T) {
+	_, _, _, err := LoadSigningCertificateFromPFX(filepath.Join("testdata", "signing-chain.pfx"), "wrong")
+	if !errors.Is(err, ErrIncorrectPFXPassword) {
+		t.Errorf("wrong password error = %v, want ErrIncorrectPFXPassword", err)
+	}
+
+	_, _, _, err = LoadSigningCertificateFromPFX(filepath.Join("testdata", "signing-chain-aes.pfx"), "gonuget")
+	if err == nil || !strings.Contains(err.Error(), "-legacy") {
+		t.Errorf("AES PFX error = %v, want advice to re-export it", err)
+	}
+
+	if _, _, _, err = LoadSigningCertificateFromPFX(filepath.Join("testdata", "missing.pfx"), "gonuget"); err == nil {
+		t.Error("LoadSigningCertificateFromPFX() expected error for a missing file")
+	}
+}
@@ -18,9 +18,21 @@ var (
 	oidAuthorSignature          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 6, 1} // ProofOfOrigin
 	oidRepositorySignature      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 6, 2} // ProofOfReceipt
 
+	// NuGet repository signature attribute (nuget-v3-service-index-url)
+	oidNuGetV3ServiceIndexURL = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 84, 2, 1, 1, 1}
+
+	// NuGet repository signature attribute (nuget-package-owners)
+	oidNuGetPackageOwners = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 84, 2, 1, 1, 2}
+
+	// RFC 5652 - Countersignature unsigned attribute
+	oidCounterSignature = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 6}
+
 	// RFC 3161 - Timestamp token OID
 	oidTimestampToken = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
 
+	// RFC 3161 - TSTInfo content type (id-ct-TSTInfo)
+	oidTSTInfo = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+
 	// Hash algorithm OIDs
 	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
 	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
@@ -86,6 +98,10 @@ func ReadSignature(signatureData []byte) (*PrimarySignature, error) {
 	// Determine signature type from signed attributes
 	sig.Type = determineSignatureType(signerInfo)
 
+	// Extract the service index URL a repository signature was issued for
+	sig.V3ServiceIndexURL = extractV3ServiceIndexURL(signerInfo)
+	sig.PackageOwners = extractPackageOwners(signerInfo)
+
 	// Extract hash algorithm
 	sig.HashAlgorithm = oidToHashAlgorithm(signerInfo.DigestAlgorithm.Algorithm)
 
@@ -93,9 +109,74 @@ func ReadSignature(signatureData []byte) (*PrimarySignature, error) {
 	timestamps, _ := extractTimestamps(signerInfo)
 	sig.Timestamps = timestamps
 
+	// Extract the repository countersignature of an author signature
+	if sig.Type == SignatureTypeAuthor {
+		countersignature, err := extractRepositoryCountersignature(signerInfo, certs)
+		if err != nil {
+			return nil, fmt.Errorf("read repository countersignature: %w", err)
+		}
+		if countersignature != nil {
+			sig.Countersignatures = []RepositoryCountersignature{*countersignature}
+			sig.RepositoryCountersignature = &sig.Countersignatures[0]
+		}
+	}
+
 	return sig, nil
 }
 
+// extractRepositoryCountersignature extracts the repository countersignature from
+// the unsigned attributes. Countersignatures of other types are ignored, as NuGet does.
+// Returns nil when there is no repository countersignature.
+// Reference: NuGet.Client RepositoryCountersignature.GetRepositoryCountersignature
+func extractRepositoryCountersignature(signerInfo SignerInfo, certs []*x509.Certificate) (*RepositoryCountersignature, error) {
+	data := signerInfo.UnsignedAttrs.Bytes
+
+	for len(data) > 0 {
+		var attr Attribute
+		rest, err := asn1.Unmarshal(data, &attr)
+		if err != nil {
+			break
+		}
+		data = rest
+
+		if !attr.Type.Equal(oidCounterSignature) {
+			continue
+		}
+
+		// Each value of the SET is a countersignature
+		values := attr.Values.Bytes
+		for len(values) > 0 {
+			var counterSignerInfo SignerInfo
+			rest, err := asn1.Unmarshal(values, &counterSignerInfo)
+			if err != nil {
+				return nil, fmt.Errorf("unmarshal countersignature: %w", err)
+			}
+			values = rest
+
+			if determineSignatureType(counterSignerInfo) != SignatureTypeRepository {
+				continue
+			}
+
+			signerCert, err := findSignerCertificate(counterSignerInfo, certs)
+			if err != nil {
+				return nil, fmt.Errorf("find countersigner certificate: %w", err)
+			}
+			timestamps, _ := extractTimestamps(counterSignerInfo)
+
+			return &RepositoryCountersignature{
+				SignerInfo:        counterSignerInfo,
+				SignerCertificate: signerCert,
+				Timestamps:        timestamps,
+				HashAlgorithm:     oidToHashAlgorithm(counterSignerInfo.DigestAlgorithm.Algorithm),
+				V3ServiceIndexURL: extractV3ServiceIndexURL(counterSignerInfo),
+				PackageOwners:     extractPackageOwners(counterSignerInfo),
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
 // parseCertificates extracts X.509 certificates from the raw value
 func parseCertificates(certData asn1.RawValue) ([]*x509.Certificate, error) {
 	if len(certData.Bytes) == 0 {
@@ -223,6 +304,63 @@ func determineSignatureType(signerInfo SignerInfo) SignatureType {
 	return SignatureTypeUnknown
 }
 
+// extractV3ServiceIndexURL extracts the nuget-v3-service-index-url signed attribute.
+// Returns an empty string when the attribute is absent (author signatures).
+func extractV3ServiceIndexURL(signerInfo SignerInfo) string {
+	data := signerInfo.SignedAttrs.Bytes
+
+	for len(data) > 0 {
+		var attr Attribute
+		rest, err := asn1.Unmarshal(data, &attr)
+		if err != nil {
+			break
+		}
+		data = rest
+
+		if !attr.Type.Equal(oidNuGetV3ServiceIndexURL) {
+			continue
+		}
+
+		// Values holds a single IA5String; read it from the SET (or SEQUENCE for
+		// signatures created by this package) contents
+		var url string
+		if _, err := asn1.UnmarshalWithParams(attr.Values.Bytes, &url, "ia5"); err != nil {
+			return ""
+		}
+		return url
+	}
+
+	return ""
+}
+
+// extractPackageOwners extracts the nuget-package-owners signed attribute.
+// Returns nil when the attribute is absent.
+func extractPackageOwners(signerInfo SignerInfo) []string {
+	data := signerInfo.SignedAttrs.Bytes
+
+	for len(data) > 0 {
+		var attr Attribute
+		rest, err := asn1.Unmarshal(data, &attr)
+		if err != nil {
+			break
+		}
+		data = rest
+
+		if !attr.Type.Equal(oidNuGetPackageOwners) {
+			continue
+		}
+
+		// Values holds a single SEQUENCE OF UTF8String
+		var owners []string
+		if _, err := asn1.Unmarshal(attr.Values.Bytes, &owners); err != nil {
+			return nil
+		}
+		return owners
+	}
+
+	return nil
+}
+
 // oidToHashAlgorithm converts an OID to a hash algorithm name
 func oidToHashAlgorithm(oid asn1.ObjectIdentifier) HashAlgorithmName {
 	switch {
@@ -291,7 +429,7 @@ func extractTimestamps(signerInfo SignerInfo) ([]Timestamp, error) {
 
 // parseTimestampToken parses an RFC 3161 timestamp token
 func parseTimestampToken(data []byte) (Timestamp, error) {
-	var ts Timestamp
+	ts := Timestamp{RawData: data}
 
 	// Timestamp is a SignedData structure
 	sig, err := ReadSignature(data)
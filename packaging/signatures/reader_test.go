@@ -254,6 +254,11 @@ func TestReadSignature_RepositorySigned(t *testing.T) {
 		t.Errorf("Signature type = %v, want %v", sig.Type, SignatureTypeRepository)
 	}
 
+	// Should expose the repository the signature was issued for
+	if sig.V3ServiceIndexURL != "https://v3ServiceIndex.test/api/index" {
+		t.Errorf("V3ServiceIndexURL = %q, want %q", sig.V3ServiceIndexURL, "https://v3ServiceIndex.test/api/index")
+	}
+
 	// Should have basic signature data
 	if len(sig.RawData) == 0 {
 		t.Error("ReadSignature() RawData is empty")
@@ -684,3 +689,56 @@ func TestReadSignature_UnknownHashAlgorithm(t *testing.T) {
 		t.Error("HashAlgorithm should not be empty")
 	}
 }
+
+func TestReadSignature_RepositoryCountersignature(t *testing.T) {
+	tests := []struct {
+		file       string
+		serviceURL string
+	}{
+		{"testdata/test.signature.p7s", "https://api.nuget.test/v3/index.json"},
+		{"testdata/newtonsoft.signature.p7s", "https://api.nuget.org/v3/index.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			sigData, err := os.ReadFile(tt.file)
+			if err != nil {
+				t.Fatalf("Failed to read signature file: %v", err)
+			}
+			sig, err := ReadSignature(sigData)
+			if err != nil {
+				t.Fatalf("ReadSignature() error = %v, want nil", err)
+			}
+
+			if len(sig.Countersignatures) != 1 {
+				t.Fatalf("len(Countersignatures) = %d, want 1", len(sig.Countersignatures))
+			}
+			cs := sig.Countersignatures[0]
+			if cs.SignerCertificate == nil || cs.SignerCertificate == sig.SignerCertificate {
+				t.Error("countersigner certificate not found")
+			}
+			if cs.V3ServiceIndexURL != tt.serviceURL {
+				t.Errorf("V3ServiceIndexURL = %q, want %q", cs.V3ServiceIndexURL, tt.serviceURL)
+			}
+			if cs.HashAlgorithm != HashAlgorithmSHA256 {
+				t.Errorf("HashAlgorithm = %s, want SHA256", cs.HashAlgorithm)
+			}
+			if len(cs.Timestamps) != 1 {
+				t.Errorf("countersignature has %d timestamps, want 1", len(cs.Timestamps))
+			}
+		})
+	}
+
+	// Repository primary signatures have no countersignature
+	sigData, err := os.ReadFile("testdata/repository.signature.p7s")
+	if err != nil {
+		t.Fatalf("Failed to read signature file: %v", err)
+	}
+	sig, err := ReadSignature(sigData)
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v, want nil", err)
+	}
+	if len(sig.Countersignatures) != 0 {
+		t.Error("repository signature has a repository countersignature")
+	}
+}
@@ -0,0 +1,385 @@
+package signatures
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec // OCSP CertIDs use SHA-1, the hash all responders support (RFC 5019)
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// RevocationMode controls how VerifySignature checks that certificates have not been
+// revoked.
+type RevocationMode string
+
+const (
+	// RevocationModeOnline checks the revocation status with the OCSP responder named in
+	// the certificate, falling back to the CRLs of its distribution points when the
+	// responder is unavailable or doesn't know the certificate.
+	RevocationModeOnline RevocationMode = "Online"
+
+	// RevocationModeOffline only checks the revocation status against the CRLs already in
+	// the CRL cache, for example CRLs pre-seeded from disk, without network access.
+	RevocationModeOffline RevocationMode = "Offline"
+
+	// RevocationModeDisabled doesn't check the revocation status. It is the default, and
+	// the zero value is treated the same way.
+	RevocationModeDisabled RevocationMode = "Disabled"
+)
+
+var (
+	// OCSP nonce extension (RFC 8954)
+	oidOCSPNonce = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 2}
+
+	// SHA-1, the hash of OCSP CertIDs
+	oidSHA1 = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+)
+
+const (
+	// ocspTimeout is the timeout of requests to OCSP responders
+	ocspTimeout = 10 * time.Second
+
+	// ocspClockSkew is the clock difference tolerated with OCSP responders
+	ocspClockSkew = 5 * time.Minute
+
+	// maxOCSPResponseSize bounds the size of the OCSP responses read
+	maxOCSPResponseSize = 1 << 20
+)
+
+var ocspHTTPClient = &http.Client{Timeout: ocspTimeout}
+
+// RevocationUnavailableError is returned when the revocation status of a certificate
+// can't be determined: the certificate names no OCSP responder, the responder can't be
+// reached or refuses the request, or it doesn't know the certificate, and no current
+// CRL is available either.
+type RevocationUnavailableError struct {
+	Certificate *x509.Certificate
+	Err         error
+}
+
+func (e *RevocationUnavailableError) Error() string {
+	return fmt.Sprintf("revocation status of certificate %s is unknown: %v", e.Certificate.Subject, e.Err)
+}
+
+func (e *RevocationUnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// OCSPCache caches OCSP responses until their nextUpdate time, so that one verification
+// run asks a responder about each certificate only once. It is safe for concurrent use.
+type OCSPCache struct {
+	mu        sync.Mutex
+	responses map[string]*ocsp.Response
+}
+
+// NewOCSPCache creates an empty OCSP response cache.
+func NewOCSPCache() *OCSPCache {
+	return &OCSPCache{responses: make(map[string]*ocsp.Response)}
+}
+
+// get returns the cached response for key, if it is still current at now
+func (c *OCSPCache) get(key string, now time.Time) *ocsp.Response {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	resp, ok := c.responses[key]
+	if !ok {
+		return nil
+	}
+	if !now.Before(resp.NextUpdate) {
+		delete(c.responses, key)
+		return nil
+	}
+	return resp
+}
+
+// put caches resp. Responses without a nextUpdate time are not cached, as newer
+// information is always available for them (RFC 6960 section 4.2.2.1).
+func (c *OCSPCache) put(key string, resp *ocsp.Response) {
+	if resp.NextUpdate.IsZero() {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[key] = resp
+}
+
+// ocspRequest is an OCSPRequest for a single certificate (RFC 6960 section 4.1.1)
+type ocspRequest struct {
+	TBSRequest ocspTBSRequest
+}
+
+type ocspTBSRequest struct {
+	RequestList       []ocspSingleRequest
+	RequestExtensions []pkix.Extension `asn1:"optional,explicit,tag:2"`
+}
+
+type ocspSingleRequest struct {
+	Cert ocspCertID
+}
+
+type ocspCertID struct {
+	HashAlgorithm  pkix.AlgorithmIdentifier
+	IssuerNameHash []byte
+	IssuerKeyHash  []byte
+	SerialNumber   *big.Int
+}
+
+// ocspResponseData is the ResponseData of a basic OCSP response, parsed for its
+// response extensions, which hold the nonce and are not exposed by the ocsp package.
+type ocspResponseData struct {
+	Version            int `asn1:"optional,explicit,tag:0,default:0"`
+	RawResponderID     asn1.RawValue
+	ProducedAt         time.Time `asn1:"generalized"`
+	Responses          asn1.RawValue
+	ResponseExtensions []pkix.Extension `asn1:"optional,explicit,tag:1"`
+}
+
+// revocationChecker checks the revocation status of certificates with the revocation
+// settings of a verification.
+type revocationChecker struct {
+	mode      RevocationMode
+	ocspCache *OCSPCache
+	crlCache  *CRLCache
+	timeout   time.Duration // bounds each request; zero keeps the default timeouts
+	now       time.Time
+}
+
+func newRevocationChecker(opts VerificationOptions) *revocationChecker {
+	return &revocationChecker{
+		mode:      opts.RevocationMode,
+		ocspCache: opts.OCSPCache,
+		crlCache:  opts.CRLCache,
+		timeout:   opts.RevocationTimeout,
+		now:       time.Now(),
+	}
+}
+
+// httpClient returns the client to send revocation requests with, def unless a timeout
+// is set
+func (c *revocationChecker) httpClient(def *http.Client) *http.Client {
+	if c.timeout <= 0 {
+		return def
+	}
+	return &http.Client{Timeout: c.timeout}
+}
+
+// RevokedCertificateError is returned when a certificate of the signer's chain has been
+// revoked.
+type RevokedCertificateError struct {
+	Certificate *x509.Certificate
+	RevokedAt   time.Time
+}
+
+func (e *RevokedCertificateError) Error() string {
+	return fmt.Sprintf("certificate %s was revoked at %s", e.Certificate.Subject, e.RevokedAt.UTC().Format(time.RFC3339))
+}
+
+// check checks that cert, issued by issuer, has not been revoked. Online, it asks the
+// OCSP responder, falling back to CRLs when the responder can't tell; offline, it only
+// checks the cached CRLs. It returns a *RevokedCertificateError when the certificate is
+// revoked, a *RevocationUnavailableError when the status can't be determined, and
+// another error when a response or CRL is not valid.
+func (c *revocationChecker) check(cert, issuer *x509.Certificate) error {
+	if c.mode == RevocationModeOffline {
+		return c.checkCRL(cert, issuer, false)
+	}
+
+	err := c.checkOCSP(cert, issuer)
+	var ocspUnavailable *RevocationUnavailableError
+	if !errors.As(err, &ocspUnavailable) {
+		return err
+	}
+
+	err = c.checkCRL(cert, issuer, true)
+	var crlUnavailable *RevocationUnavailableError
+	if errors.As(err, &crlUnavailable) {
+		return &RevocationUnavailableError{
+			Certificate: cert,
+			Err:         fmt.Errorf("OCSP: %w; CRL: %w", ocspUnavailable.Err, crlUnavailable.Err),
+		}
+	}
+	return err
+}
+
+// checkOCSP checks with OCSP that cert, issued by issuer, has not been revoked.
+// Reference: RFC 6960, RFC 8954
+func (c *revocationChecker) checkOCSP(cert, issuer *x509.Certificate) error {
+	if len(cert.OCSPServer) == 0 {
+		return &RevocationUnavailableError{Certificate: cert, Err: errors.New("the certificate names no OCSP responder")}
+	}
+	responderURL := cert.OCSPServer[0]
+
+	certID, err := newOCSPCertID(cert, issuer)
+	if err != nil {
+		return fmt.Errorf("create OCSP request: %w", err)
+	}
+	cacheKey := responderURL + "|" + hex.EncodeToString(certID.IssuerNameHash) + "|" +
+		hex.EncodeToString(certID.IssuerKeyHash) + "|" + certID.SerialNumber.String()
+
+	var resp *ocsp.Response
+	if c.ocspCache != nil {
+		resp = c.ocspCache.get(cacheKey, c.now)
+	}
+	if resp == nil {
+		resp, err = requestOCSPResponse(c.httpClient(ocspHTTPClient), responderURL, cert, issuer, certID, c.now)
+		if err != nil {
+			return err
+		}
+		if c.ocspCache != nil {
+			c.ocspCache.put(cacheKey, resp)
+		}
+	}
+
+	switch resp.Status {
+	case ocsp.Good:
+		return nil
+	case ocsp.Revoked:
+		return &RevokedCertificateError{Certificate: cert, RevokedAt: resp.RevokedAt}
+	default:
+		return &RevocationUnavailableError{Certificate: cert, Err: errors.New("the OCSP responder does not know the certificate")}
+	}
+}
+
+// requestOCSPResponse sends an OCSP request with a nonce to responderURL and validates
+// the response: its signature, its nonce and the times it was produced and is valid for.
+func requestOCSPResponse(client *http.Client, responderURL string, cert, issuer *x509.Certificate, certID ocspCertID, now time.Time) (*ocsp.Response, error) {
+	nonce, err := generateNonce()
+	if err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	nonceValue, err := asn1.Marshal(nonce)
+	if err != nil {
+		return nil, fmt.Errorf("marshal nonce: %w", err)
+	}
+
+	reqBytes, err := asn1.Marshal(ocspRequest{TBSRequest: ocspTBSRequest{
+		RequestList:       []ocspSingleRequest{{Cert: certID}},
+		RequestExtensions: []pkix.Extension{{Id: oidOCSPNonce, Value: nonceValue}},
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("marshal OCSP request: %w", err)
+	}
+
+	httpResp, err := client.Post(responderURL, "application/ocsp-request", bytes.NewReader(reqBytes))
+	if err != nil {
+		return nil, &RevocationUnavailableError{Certificate: cert, Err: err}
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, &RevocationUnavailableError{Certificate: cert, Err: fmt.Errorf("OCSP responder %s returned HTTP %d", responderURL, httpResp.StatusCode)}
+	}
+	respBytes, err := io.ReadAll(io.LimitReader(httpResp.Body, maxOCSPResponseSize))
+	if err != nil {
+		return nil, &RevocationUnavailableError{Certificate: cert, Err: fmt.Errorf("read OCSP response: %w", err)}
+	}
+
+	resp, err := ocsp.ParseResponseForCert(respBytes, cert, issuer)
+	if err != nil {
+		var respErr ocsp.ResponseError
+		if errors.As(err, &respErr) {
+			// tryLater, internalError and the like
+			return nil, &RevocationUnavailableError{Certificate: cert, Err: fmt.Errorf("OCSP responder %s: %w", responderURL, err)}
+		}
+		return nil, fmt.Errorf("invalid OCSP response from %s: %w", responderURL, err)
+	}
+
+	if err := validateOCSPResponse(resp, issuer, nonce, now); err != nil {
+		return nil, fmt.Errorf("invalid OCSP response from %s: %w", responderURL, err)
+	}
+	return resp, nil
+}
+
+// validateOCSPResponse checks what ocsp.ParseResponseForCert leaves to the caller.
+// A response that carries a nonce must carry the request's; responders that serve
+// pre-produced responses don't echo nonces (RFC 8954 section 2.1), so those are
+// accepted when they are current.
+func validateOCSPResponse(resp *ocsp.Response, issuer *x509.Certificate, nonce []byte, now time.Time) error {
+	// A delegated responder must be authorized to sign OCSP responses
+	if resp.Certificate != nil && !resp.Certificate.Equal(issuer) {
+		authorized := false
+		for _, usage := range resp.Certificate.ExtKeyUsage {
+			if usage == x509.ExtKeyUsageOCSPSigning {
+				authorized = true
+				break
+			}
+		}
+		if !authorized {
+			return errors.New("responder certificate is not authorized to sign OCSP responses")
+		}
+	}
+
+	var data ocspResponseData
+	if _, err := asn1.Unmarshal(resp.TBSResponseData, &data); err != nil {
+		return fmt.Errorf("parse response data: %w", err)
+	}
+	for _, ext := range data.ResponseExtensions {
+		if !ext.Id.Equal(oidOCSPNonce) {
+			continue
+		}
+		var responseNonce []byte
+		if _, err := asn1.Unmarshal(ext.Value, &responseNonce); err != nil {
+			// Some responders put the nonce in the extension unwrapped
+			responseNonce = ext.Value
+		}
+		if !bytes.Equal(responseNonce, nonce) {
+			return errors.New("nonce mismatch")
+		}
+	}
+
+	if resp.ProducedAt.After(now.Add(ocspClockSkew)) {
+		return fmt.Errorf("response was produced in the future (%s)", resp.ProducedAt.UTC().Format(time.RFC3339))
+	}
+	if resp.ThisUpdate.After(now.Add(ocspClockSkew)) {
+		return fmt.Errorf("response is not valid before %s", resp.ThisUpdate.UTC().Format(time.RFC3339))
+	}
+	if !resp.NextUpdate.IsZero() && resp.NextUpdate.Before(now.Add(-ocspClockSkew)) {
+		return fmt.Errorf("response expired at %s", resp.NextUpdate.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// newOCSPCertID identifies cert to its OCSP responder by the hashes of its issuer's
+// name and public key, and its serial number.
+func newOCSPCertID(cert, issuer *x509.Certificate) (ocspCertID, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return ocspCertID{}, fmt.Errorf("parse issuer public key: %w", err)
+	}
+
+	nameHash := sha1.Sum(issuer.RawSubject)          //nolint:gosec // see import
+	keyHash := sha1.Sum(spki.PublicKey.RightAlign()) //nolint:gosec // see import
+	return ocspCertID{
+		HashAlgorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidSHA1,
+			Parameters: asn1.RawValue{Tag: asn1.TagNull},
+		},
+		IssuerNameHash: nameHash[:],
+		IssuerKeyHash:  keyHash[:],
+		SerialNumber:   cert.SerialNumber,
+	}, nil
+}
+
+// findIssuer returns the certificate among certs that issued cert
+func findIssuer(cert *x509.Certificate, certs []*x509.Certificate) *x509.Certificate {
+	for _, c := range certs {
+		if c != cert && bytes.Equal(c.RawSubject, cert.RawIssuer) && cert.CheckSignatureFrom(c) == nil {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package signatures
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testOCSPResponder answers OCSP requests, signing its responses with issuerKey
+type testOCSPResponder struct {
+	issuerKey *rsa.PrivateKey
+
+	status     string // "good", "revoked" or "unknown"
+	producedAt time.Time
+	nextUpdate time.Time
+	noNonce    bool   // don't echo the request nonce
+	nonce      []byte // nonce to answer with instead of the request's
+
+	requests atomic.Int32
+}
+
+// ASN.1 structures of OCSP responses (RFC 6960 section 4.2.1)
+type testOCSPResponse struct {
+	Status   asn1.Enumerated
+	Response testOCSPResponseBytes `asn1:"explicit,tag:0"`
+}
+
+type testOCSPResponseBytes struct {
+	ResponseType asn1.ObjectIdentifier
+	Response     []byte
+}
+
+type testBasicOCSPResponse struct {
+	TBSResponseData    asn1.RawValue
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Signature          asn1.BitString
+}
+
+type testOCSPResponseData struct {
+	RawResponderID     asn1.RawValue
+	ProducedAt         time.Time `asn1:"generalized"`
+	Responses          []testOCSPSingleResponse
+	ResponseExtensions []pkix.Extension `asn1:"optional,explicit,tag:1"`
+}
+
+type testOCSPSingleResponse struct {
+	CertID     ocspCertID
+	Good       asn1.Flag           `asn1:"tag:0,optional"`
+	Revoked    testOCSPRevokedInfo `asn1:"tag:1,optional"`
+	Unknown    asn1.Flag           `asn1:"tag:2,optional"`
+	ThisUpdate time.Time           `asn1:"generalized"`
+	NextUpdate time.Time           `asn1:"generalized,explicit,tag:0,optional"`
+}
+
+type testOCSPRevokedInfo struct {
+	RevocationTime time.Time `asn1:"generalized"`
+}
+
+func (r *testOCSPResponder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.requests.Add(1)
+
+	body, _ := io.ReadAll(req.Body)
+	var ocspReq ocspRequest
+	if _, err := asn1.Unmarshal(body, &ocspReq); err != nil || len(ocspReq.TBSRequest.RequestList) != 1 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	single := testOCSPSingleResponse{
+		CertID:     ocspReq.TBSRequest.RequestList[0].Cert,
+		ThisUpdate: now.Add(-time.Hour),
+		NextUpdate: r.nextUpdate,
+	}
+	switch r.status {
+	case "revoked":
+		single.Revoked = testOCSPRevokedInfo{RevocationTime: now.Add(-30 * time.Minute)}
+	case "unknown":
+		single.Unknown = true
+	default:
+		single.Good = true
+	}
+
+	data := testOCSPResponseData{
+		RawResponderID: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 2, IsCompound: true,
+			Bytes: mustMarshal(single.CertID.IssuerKeyHash)},
+		ProducedAt: r.producedAt,
+		Responses:  []testOCSPSingleResponse{single},
+	}
+	if data.ProducedAt.IsZero() {
+		data.ProducedAt = now
+	}
+	if !r.noNonce {
+		for _, ext := range ocspReq.TBSRequest.RequestExtensions {
+			if ext.Id.Equal(oidOCSPNonce) {
+				if r.nonce != nil {
+					ext.Value = mustMarshal(r.nonce)
+				}
+				data.ResponseExtensions = append(data.ResponseExtensions, ext)
+			}
+		}
+	}
+
+	tbs := mustMarshal(data)
+	digest := sha256.Sum256(tbs)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, r.issuerKey, crypto.SHA256, digest[:])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	basic := mustMarshal(testBasicOCSPResponse{
+		TBSResponseData:    asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256WithRSA, Parameters: asn1.NullRawValue},
+		Signature:          asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+	w.Header().Set("Content-Type", "application/ocsp-response")
+	_, _ = w.Write(mustMarshal(testOCSPResponse{
+		Response: testOCSPResponseBytes{
+			ResponseType: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 1},
+			Response:     basic,
+		},
+	}))
+}
+
+func mustMarshal(v any) []byte {
+	data, err := asn1.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// newRevocationTestSignature returns a signature whose signer certificate names
+// ocspURL as its OCSP responder and crlURL as its CRL distribution point (when not
+// empty), options trusting its root, and the root's key
+func newRevocationTestSignature(t *testing.T, ocspURL, crlURL string) (*PrimarySignature, VerificationOptions, *rsa.PrivateKey) {
+	t.Helper()
+
+	rootCert, rootKey := generateTestRootCA(t)
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "Test Revocation Signer"},
+		NotBefore:    time.Now().Add(-24 * time.Hour),
+		NotAfter:     time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	if ocspURL != "" {
+		template.OCSPServer = []string{ocspURL}
+	}
+	if crlURL != "" {
+		template.CRLDistributionPoints = []string{crlURL}
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, rootCert, &priv.PublicKey, rootKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	signerCert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+
+	sig := &PrimarySignature{
+		Type:              SignatureTypeAuthor,
+		SignerCertificate: signerCert,
+		Certificates:      []*x509.Certificate{signerCert, rootCert},
+		HashAlgorithm:     HashAlgorithmSHA256,
+	}
+	opts := DefaultVerificationOptions()
+	opts.TrustStore.AddCertificate(rootCert)
+	opts.RevocationMode = RevocationModeOnline
+	return sig, opts, rootKey
+}
+
+// startTestOCSPResponder starts a responder and returns a signature checked against it
+func startTestOCSPResponder(t *testing.T, configure func(*testOCSPResponder)) (*PrimarySignature, VerificationOptions, *testOCSPResponder) {
+	t.Helper()
+
+	responder := &testOCSPResponder{nextUpdate: time.Now().Add(time.Hour)}
+	server := httptest.NewServer(responder)
+	t.Cleanup(server.Close)
+
+	sig, opts, rootKey := newRevocationTestSignature(t, server.URL, "")
+	responder.issuerKey = rootKey
+	if configure != nil {
+		configure(responder)
+	}
+	return sig, opts, responder
+}
+
+func TestVerifySignature_OnlineRevocation_Good(t *testing.T) {
+	sig, opts, responder := startTestOCSPResponder(t, nil)
+	opts.OCSPCache = NewOCSPCache()
+
+	result := VerifySignature(sig, opts)
+	if !result.IsValid {
+		t.Fatalf("expected valid signature, got errors: %v", result.Errors)
+	}
+
+	// The cached response is used until its nextUpdate
+	result = VerifySignature(sig, opts)
+	if !result.IsValid {
+		t.Fatalf("expected valid signature, got errors: %v", result.Errors)
+	}
+	if n := responder.requests.Load(); n != 1 {
+		t.Errorf("responder got %d requests, want 1", n)
+	}
+}
+
+func TestVerifySignature_OnlineRevocation_Revoked(t *testing.T) {
+	sig, opts, _ := startTestOCSPResponder(t, func(r *testOCSPResponder) { r.status = "revoked" })
+
+	// Revocation fails verification whatever the revocation mode
+	opts.AllowUnknownRevocation = true
+	result := VerifySignature(sig, opts)
+	if result.IsValid {
+		t.Fatal("expected a revoked certificate to fail verification")
+	}
+	if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), "was revoked") {
+		t.Errorf("errors = %v, want a revocation error", result.Errors)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Code != CodeCertificateRevoked {
+		t.Errorf("Issues = %v, want an NU3012 error", result.Issues)
+	}
+}
+
+func TestVerifySignature_OnlineRevocation_InvalidResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*testOCSPResponder)
+		wantErr   string
+	}{
+		{"nonce mismatch", func(r *testOCSPResponder) { r.nonce = []byte("another nonce") }, "nonce mismatch"},
+		{"produced in the future", func(r *testOCSPResponder) { r.producedAt = time.Now().Add(time.Hour) }, "produced in the future"},
+		{"expired", func(r *testOCSPResponder) { r.nextUpdate = time.Now().Add(-time.Hour) }, "expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, opts, _ := startTestOCSPResponder(t, tt.configure)
+			opts.AllowUnknownRevocation = true
+
+			result := VerifySignature(sig, opts)
+			if result.IsValid {
+				t.Fatal("expected an invalid OCSP response to fail verification")
+			}
+			if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), tt.wantErr) {
+				t.Errorf("errors = %v, want %q", result.Errors, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifySignature_OnlineRevocation_NoNonce(t *testing.T) {
+	// Pre-produced responses don't echo the nonce
+	sig, opts, _ := startTestOCSPResponder(t, func(r *testOCSPResponder) { r.noNonce = true })
+
+	if result := VerifySignature(sig, opts); !result.IsValid {
+		t.Errorf("expected valid signature, got errors: %v", result.Errors)
+	}
+}
+
+func TestVerifySignature_OnlineRevocation_Unavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+	sig, opts, _ := newRevocationTestSignature(t, url, "")
+
+	// An unreachable responder is a warning by default, as in NuGet.Client
+	result := VerifySignature(sig, opts)
+	if !result.IsValid {
+		t.Fatalf("expected valid signature by default, got errors: %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "revocation status") {
+		t.Errorf("warnings = %v, want a revocation status warning", result.Warnings)
+	}
+	want := []VerificationIssue{{Code: CodeChainBuildingIssue, Message: result.Warnings[0]}}
+	if !slices.Equal(result.Issues, want) {
+		t.Errorf("Issues = %v, want %v", result.Issues, want)
+	}
+
+	opts.AllowUnknownRevocation = false
+	result = VerifySignature(sig, opts)
+	if result.IsValid {
+		t.Fatal("expected an unreachable responder to fail verification without AllowUnknownRevocation")
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Code != CodeChainBuildingIssue || !result.Issues[0].IsError {
+		t.Errorf("Issues = %v, want an NU3018 error", result.Issues)
+	}
+
+	// A responder that doesn't know the certificate is treated the same way
+	sig, opts, _ = startTestOCSPResponder(t, func(r *testOCSPResponder) { r.status = "unknown" })
+	opts.AllowUnknownRevocation = true
+	if result := VerifySignature(sig, opts); !result.IsValid || len(result.Warnings) != 1 {
+		t.Errorf("unknown status: IsValid = %v, warnings = %v, want a warning only", result.IsValid, result.Warnings)
+	}
+}
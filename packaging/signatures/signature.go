@@ -3,6 +3,11 @@
 // This package implements RFC 5652 (Cryptographic Message Syntax) and RFC 3161 (Time-Stamp Protocol)
 // to read and verify package signatures from signed .nupkg files. It supports both Author and
 // Repository signatures, certificate chain extraction, and RFC 3161 timestamp validation.
+//
+// Author signatures can carry a repository countersignature, which a repository such as
+// nuget.org adds with CountersignPackageData. ReadSignature exposes it in
+// PrimarySignature.Countersignatures, and VerifySignature verifies it along with the
+// author signature when VerifyRepositoryCountersignature is set.
 package signatures
 
 import (
@@ -47,6 +52,56 @@ type PrimarySignature struct {
 
 	// Content hash algorithm
 	HashAlgorithm HashAlgorithmName
+
+	// V3ServiceIndexURL is the service index URL of the repository a repository
+	// signature was issued for (nuget-v3-service-index-url). Empty for author signatures.
+	V3ServiceIndexURL string
+
+	// PackageOwners are the package owners a repository signature lists
+	// (nuget-package-owners). Empty when the attribute is absent.
+	PackageOwners []string
+
+	// Countersignatures are the repository countersignatures of an author signature.
+	// NuGet reads at most one and ignores countersignatures of other types, so it holds
+	// one element or none.
+	Countersignatures []RepositoryCountersignature
+
+	// RepositoryCountersignature is the repository signature countersigning an
+	// author signature (nil when there is none)
+	//
+	// Deprecated: Use Countersignatures, whose first element this points to.
+	RepositoryCountersignature *RepositoryCountersignature
+}
+
+// repositoryCountersignature returns the repository countersignature of the signature,
+// or nil when there is none.
+func (s *PrimarySignature) repositoryCountersignature() *RepositoryCountersignature {
+	if len(s.Countersignatures) == 0 {
+		return nil
+	}
+	return &s.Countersignatures[0]
+}
+
+// RepositoryCountersignature represents a repository countersignature, which a
+// repository adds to an author signed package in place of a primary signature
+type RepositoryCountersignature struct {
+	// SignerInfo is the countersignature, a signer info over the author signature value
+	SignerInfo SignerInfo
+
+	// Signer certificate
+	SignerCertificate *x509.Certificate
+
+	// Timestamp information (RFC 3161)
+	Timestamps []Timestamp
+
+	// Hash algorithm of the countersignature
+	HashAlgorithm HashAlgorithmName
+
+	// V3ServiceIndexURL is the service index URL of the repository that countersigned
+	V3ServiceIndexURL string
+
+	// PackageOwners are the package owners the countersignature lists
+	PackageOwners []string
 }
 
 // Timestamp represents an RFC 3161 timestamp
@@ -62,6 +117,10 @@ type Timestamp struct {
 
 	// Accuracy (optional)
 	Accuracy time.Duration
+
+	// RawData is the DER encoded timestamp token, whose TSA signature is verified
+	// with the timestamp (nil for timestamps not read from a signature)
+	RawData []byte
 }
 
 // HashAlgorithmName represents cryptographic hash algorithms
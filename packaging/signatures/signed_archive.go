@@ -0,0 +1,176 @@
+package signatures
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"time"
+)
+
+// signatureFileName is the name of the package signature entry
+const signatureFileName = ".signature.p7s"
+
+// ErrPackageAlreadySigned is returned when signing a package that already has a signature.
+var ErrPackageAlreadySigned = errors.New("package is already signed")
+
+// SignPackage signs an unsigned package and writes the signed package to w.
+// The hash of the unsigned package is signed as SignPackageHash does, and the signature
+// is added as a stored .signature.p7s entry after the other entries, leaving their bytes
+// unchanged, so the content hash of the signed package is the hash that was signed.
+// Reference: NuGet.Client SignedPackageArchiveUtility.SignZip
+func SignPackage(pkg io.ReadSeeker, w io.Writer, opts SigningOptions) error {
+	if err := opts.Validate(); err != nil {
+		return fmt.Errorf("invalid signing options: %w", err)
+	}
+
+	eocdr, eocdrOffset, err := findEndOfCentralDirectory(pkg)
+	if err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+	if eocdr.NumEntries == math.MaxUint16 || eocdr.CentralDirectoryOffset == math.MaxUint32 {
+		return fmt.Errorf("zip64 packages cannot be signed")
+	}
+
+	metadata, err := readSignedArchiveMetadata(pkg)
+	if err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+	if metadata.SignatureCentralDirectoryHeaderIndex >= 0 {
+		return ErrPackageAlreadySigned
+	}
+
+	// The signature covers the unsigned package as is
+	if _, err := pkg.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	hasher := getCryptoHash(opts.HashAlgorithm).New()
+	if _, err := io.Copy(hasher, pkg); err != nil {
+		return fmt.Errorf("hash package: %w", err)
+	}
+
+	signature, err := SignPackageHash(hasher.Sum(nil), opts)
+	if err != nil {
+		return err
+	}
+
+	return writeSignedArchive(pkg, w, eocdr, eocdrOffset, signature, time.Now())
+}
+
+// RemovePackageSignature writes a signed package without its signature to w, giving
+// back the package as it was before it was signed. It reports false, writing nothing,
+// when the package is not signed. Like GetPackageContentHash, it needs pkg to be an
+// io.ReaderAt too, as *os.File and *bytes.Reader are.
+// Reference: NuGet.Client SignedPackageArchiveUtility.UnsignZip
+func RemovePackageSignature(pkg io.ReadSeeker, w io.Writer) (bool, error) {
+	return hashPackageContent(pkg, w)
+}
+
+// writeSignedArchive writes the package with the signature entry inserted after the last
+// file entry and appended to the central directory.
+func writeSignedArchive(pkg io.ReadSeeker, w io.Writer, eocdr *endOfCentralDirectory, eocdrOffset int64, signature []byte, modified time.Time) error {
+	centralDirectoryOffset := int64(eocdr.CentralDirectoryOffset)
+	entry, header := signatureFileEntry(signature, uint32(centralDirectoryOffset), modified)
+	if centralDirectoryOffset+int64(len(entry)) > math.MaxUint32 {
+		return fmt.Errorf("zip64 packages cannot be signed")
+	}
+
+	// File entries, then the signature entry
+	if err := copyPackageRange(pkg, w, 0, centralDirectoryOffset); err != nil {
+		return err
+	}
+	if _, err := w.Write(entry); err != nil {
+		return err
+	}
+
+	// Central directory, then the signature header
+	if err := copyPackageRange(pkg, w, centralDirectoryOffset, eocdrOffset); err != nil {
+		return err
+	}
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	signed := *eocdr
+	signed.NumEntries++
+	signed.NumEntriesOnDisk++
+	signed.CentralDirectorySize += uint32(len(header))
+	signed.CentralDirectoryOffset += uint32(len(entry))
+	if err := binary.Write(w, binary.LittleEndian, &signed); err != nil {
+		return err
+	}
+
+	// Archive comment
+	end, err := pkg.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	return copyPackageRange(pkg, w, eocdrOffset+22, end)
+}
+
+// signatureFileEntry returns the local file header and data, and the central directory
+// header, of a stored .signature.p7s entry whose local header is at offset.
+func signatureFileEntry(signature []byte, offset uint32, modified time.Time) (entry, header []byte) {
+	modTime, modDate := dosDateTime(modified)
+	crc := crc32.ChecksumIEEE(signature)
+	size := uint32(len(signature))
+	le := binary.LittleEndian
+
+	entry = le.AppendUint32(entry, 0x04034b50)
+	entry = le.AppendUint16(entry, 20) // version needed to extract
+	entry = le.AppendUint16(entry, 0)  // flags
+	entry = le.AppendUint16(entry, 0)  // stored
+	entry = le.AppendUint16(entry, modTime)
+	entry = le.AppendUint16(entry, modDate)
+	entry = le.AppendUint32(entry, crc)
+	entry = le.AppendUint32(entry, size)
+	entry = le.AppendUint32(entry, size)
+	entry = le.AppendUint16(entry, uint16(len(signatureFileName)))
+	entry = le.AppendUint16(entry, 0) // extra field length
+	entry = append(entry, signatureFileName...)
+	entry = append(entry, signature...)
+
+	header = le.AppendUint32(header, 0x02014b50)
+	header = le.AppendUint16(header, 20) // version made by
+	header = le.AppendUint16(header, 20) // version needed to extract
+	header = le.AppendUint16(header, 0)  // flags
+	header = le.AppendUint16(header, 0)  // stored
+	header = le.AppendUint16(header, modTime)
+	header = le.AppendUint16(header, modDate)
+	header = le.AppendUint32(header, crc)
+	header = le.AppendUint32(header, size)
+	header = le.AppendUint32(header, size)
+	header = le.AppendUint16(header, uint16(len(signatureFileName)))
+	header = le.AppendUint16(header, 0) // extra field length
+	header = le.AppendUint16(header, 0) // comment length
+	header = le.AppendUint16(header, 0) // disk number start
+	header = le.AppendUint16(header, 0) // internal attributes
+	header = le.AppendUint32(header, 0) // external attributes
+	header = le.AppendUint32(header, offset)
+	header = append(header, signatureFileName...)
+
+	return entry, header
+}
+
+// dosDateTime converts t to the MS-DOS time and date of ZIP headers.
+func dosDateTime(t time.Time) (uint16, uint16) {
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, t.Location())
+	}
+	dosTime := uint16(t.Hour()<<11 | t.Minute()<<5 | t.Second()/2)
+	dosDate := uint16((t.Year()-1980)<<9 | int(t.Month())<<5 | t.Day())
+	return dosTime, dosDate
+}
+
+// copyPackageRange copies the bytes of pkg from start to end to w.
+func copyPackageRange(pkg io.ReadSeeker, w io.Writer, start, end int64) error {
+	if _, err := pkg.Seek(start, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.CopyN(w, pkg, end-start); err != nil {
+		return fmt.Errorf("copy package: %w", err)
+	}
+	return nil
+}
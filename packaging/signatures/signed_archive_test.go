@@ -0,0 +1,70 @@
+package signatures
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignPackage(t *testing.T) {
+	cert, key, chain, err := LoadSigningCertificateFromPFX(filepath.Join("testdata", "signing-chain.pfx"), "gonuget")
+	if err != nil {
+		t.Fatalf("LoadSigningCertificateFromPFX() error = %v", err)
+	}
+	opts := DefaultSigningOptions(cert, key)
+	opts.CertificateChain = chain
+	opts.HashAlgorithm = HashAlgorithmSHA384
+
+	unsigned := createTestPackageReader(t, map[string]string{
+		"test.nuspec":      "<package />",
+		"lib/net8.0/a.dll": "binary content",
+	})
+	original := make([]byte, unsigned.Len())
+	if _, err := unsigned.ReadAt(original, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	var signed bytes.Buffer
+	if err := SignPackage(bytes.NewReader(original), &signed, opts); err != nil {
+		t.Fatalf("SignPackage() error = %v", err)
+	}
+
+	// The signature is the last, stored entry of a valid archive
+	zipReader, err := zip.NewReader(bytes.NewReader(signed.Bytes()), int64(signed.Len()))
+	if err != nil {
+		t.Fatalf("signed package is not a valid archive: %v", err)
+	}
+	last := zipReader.File[len(zipReader.File)-1]
+	if len(zipReader.File) != 3 || last.Name != ".signature.p7s" || last.Method != zip.Store {
+		t.Fatalf("last entry = %s (method %d) of %d, want a stored .signature.p7s", last.Name, last.Method, len(zipReader.File))
+	}
+
+	// The signature embeds the hash of the unsigned package, as NuGet signatures do
+	sig := readTestPackageSignature(t, signed.Bytes())
+	if sig.Type != SignatureTypeAuthor || sig.HashAlgorithm != HashAlgorithmSHA384 {
+		t.Errorf("signature = %s %s, want an SHA384 author signature", sig.Type, sig.HashAlgorithm)
+	}
+	if len(sig.SignedData.ContentInfo.Content.Bytes) == 0 {
+		t.Error("signature has no signature content")
+	}
+	if err := VerifyPackageContentHash(sig, bytes.NewReader(signed.Bytes())); err != nil {
+		t.Errorf("VerifyPackageContentHash() error = %v", err)
+	}
+
+	// Removing the signature gives back the unsigned package
+	var removed bytes.Buffer
+	wasSigned, err := RemovePackageSignature(bytes.NewReader(signed.Bytes()), &removed)
+	if err != nil || !wasSigned {
+		t.Fatalf("RemovePackageSignature() = %v, %v", wasSigned, err)
+	}
+	if !bytes.Equal(removed.Bytes(), original) {
+		t.Error("RemovePackageSignature() did not give back the unsigned package")
+	}
+
+	err = SignPackage(bytes.NewReader(signed.Bytes()), &bytes.Buffer{}, opts)
+	if !errors.Is(err, ErrPackageAlreadySigned) {
+		t.Errorf("SignPackage() of a signed package error = %v, want ErrPackageAlreadySigned", err)
+	}
+}
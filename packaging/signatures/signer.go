@@ -6,6 +6,8 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/asn1"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"slices"
 	"time"
@@ -13,15 +15,23 @@ import (
 
 // SigningOptions configures NuGet package signature creation.
 // It specifies the signing certificate, private key, certificate chain, signature type,
-// hash algorithm, and optional timestamp authority settings.
+// hash algorithm, and optional timestamp authority settings. Repository signatures can
+// embed the service index URL of the signing repository via V3ServiceIndexURL, and the
+// owners of the package on the repository via PackageOwners.
+//
+// Timestamp servers are tried in order, TimestampURL first and then TimestampURLs,
+// until one returns a valid RFC 3161 token. TimestampTimeout applies to each attempt.
 type SigningOptions struct {
-	Certificate      *x509.Certificate
-	PrivateKey       crypto.PrivateKey
-	CertificateChain []*x509.Certificate
-	SignatureType    SignatureType
-	HashAlgorithm    HashAlgorithmName
-	TimestampURL     string
-	TimestampTimeout time.Duration
+	Certificate       *x509.Certificate
+	PrivateKey        crypto.PrivateKey
+	CertificateChain  []*x509.Certificate
+	SignatureType     SignatureType
+	HashAlgorithm     HashAlgorithmName
+	TimestampURL      string
+	TimestampURLs     []string
+	TimestampTimeout  time.Duration
+	V3ServiceIndexURL string
+	PackageOwners     []string
 }
 
 // DefaultSigningOptions returns signing options with sensible defaults.
@@ -96,6 +106,7 @@ func verifyKeyMatchesCertificate(cert *x509.Certificate, key crypto.PrivateKey)
 // and optionally requests an RFC 3161 timestamp if TimestampURL is configured.
 // The contentHash should be the SHA256/384/512 hash of the package ZIP archive.
 // Returns the DER-encoded PKCS#7 signature bytes ready to be stored in the package.
+// A repository countersignature is added to the result with CountersignPackageData.
 func SignPackageData(contentHash []byte, opts SigningOptions) ([]byte, error) {
 	if err := opts.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid signing options: %w", err)
@@ -107,6 +118,139 @@ func SignPackageData(contentHash []byte, opts SigningOptions) ([]byte, error) {
 		return nil, fmt.Errorf("create signed data: %w", err)
 	}
 
+	return marshalSignedData(signedData)
+}
+
+// SignPackageHash creates a NuGet package signature for the hash of an unsigned package,
+// computed with opts.HashAlgorithm. Unlike SignPackageData, the signature embeds the hash
+// in its content ("Version:1", then "<hash algorithm OID>-Hash:<base64 hash>") and signs
+// the digest of that content, as NuGet.Client does, so NuGet clients can verify it.
+// Reference: NuGet.Client SignatureContent.cs
+func SignPackageHash(packageHash []byte, opts SigningOptions) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid signing options: %w", err)
+	}
+
+	content := createSignatureContent(opts.HashAlgorithm, packageHash)
+	hasher := getCryptoHash(opts.HashAlgorithm).New()
+	hasher.Write(content)
+
+	signedData, err := createSignedData(hasher.Sum(nil), opts)
+	if err != nil {
+		return nil, fmt.Errorf("create signed data: %w", err)
+	}
+
+	contentBytes, err := asn1.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("marshal signature content: %w", err)
+	}
+	signedData.ContentInfo.Content = asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        0,
+		IsCompound: true,
+		Bytes:      contentBytes,
+	}
+
+	return marshalSignedData(signedData)
+}
+
+// CountersignPackageData adds a repository countersignature to an author signature
+// and returns the countersigned signature. opts must describe a repository signature.
+// The countersignature is a signer info over the author signature value, added to the
+// unsigned attributes of the author signer info as a countersignature attribute
+// (RFC 5652 section 11.4), and the countersigner certificates are added to the
+// signature certificates. The author signer info is otherwise left unchanged, so the
+// author signature stays valid.
+// Reference: NuGet.Client SignedPackageArchiveUtility and X509SignatureProvider.CreateRepositoryCountersignatureAsync
+func CountersignPackageData(existingSignature []byte, opts SigningOptions) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid signing options: %w", err)
+	}
+	if opts.SignatureType != SignatureTypeRepository {
+		return nil, fmt.Errorf("a countersignature must be a repository signature, not %s", opts.SignatureType)
+	}
+
+	sig, err := ReadSignature(existingSignature)
+	if err != nil {
+		return nil, fmt.Errorf("read primary signature: %w", err)
+	}
+	if sig.Type != SignatureTypeAuthor {
+		return nil, fmt.Errorf("only author signatures can be countersigned, not %s signatures", sig.Type)
+	}
+	if len(sig.Countersignatures) > 0 {
+		return nil, fmt.Errorf("signature already has a repository countersignature")
+	}
+
+	// The countersignature signs the hash of the author signature value
+	signedData := *sig.SignedData
+	signerInfo := signedData.SignerInfos[0]
+	hasher := getCryptoHash(opts.HashAlgorithm).New()
+	hasher.Write(signerInfo.Signature)
+
+	counterSignerInfo, err := createCounterSignerInfo(hasher.Sum(nil), opts)
+	if err != nil {
+		return nil, fmt.Errorf("create countersigner info: %w", err)
+	}
+	counterSignerInfoBytes, err := asn1.Marshal(*counterSignerInfo)
+	if err != nil {
+		return nil, fmt.Errorf("marshal countersigner info: %w", err)
+	}
+	values, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: counterSignerInfoBytes}}, "set")
+	if err != nil {
+		return nil, fmt.Errorf("marshal countersignature: %w", err)
+	}
+	attrBytes, err := asn1.Marshal(Attribute{
+		Type:   oidCounterSignature,
+		Values: asn1.RawValue{FullBytes: values},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal countersignature attribute: %w", err)
+	}
+
+	// Append the countersignature to the unsigned attributes, keeping the timestamp
+	signerInfo.UnsignedAttrs = asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        1,
+		IsCompound: true,
+		Bytes:      append(slices.Clip(signerInfo.UnsignedAttrs.Bytes), attrBytes...),
+	}
+	signedData.SignerInfos = append([]SignerInfo{signerInfo}, signedData.SignerInfos[1:]...)
+
+	// Add the countersigner certificates the signature doesn't have yet
+	certBytes := slices.Clip(signedData.Certificates.Bytes)
+	for _, cert := range append([]*x509.Certificate{opts.Certificate}, opts.CertificateChain...) {
+		if !slices.ContainsFunc(sig.Certificates, cert.Equal) {
+			certBytes = append(certBytes, cert.Raw...)
+			sig.Certificates = append(sig.Certificates, cert)
+		}
+	}
+	signedData.Certificates = asn1.RawValue{
+		Class:      asn1.ClassContextSpecific,
+		Tag:        0,
+		IsCompound: true,
+		Bytes:      certBytes,
+	}
+
+	return marshalSignedData(&signedData)
+}
+
+// CreateRepositoryCountersignature adds a repository countersignature to an author
+// signature and returns the countersigned signature.
+//
+// Deprecated: Use CountersignPackageData.
+func CreateRepositoryCountersignature(primarySignature []byte, opts SigningOptions) ([]byte, error) {
+	return CountersignPackageData(primarySignature, opts)
+}
+
+// createSignatureContent encodes the content of a NuGet signature, read back by
+// parseSignatureContent.
+func createSignatureContent(hashAlg HashAlgorithmName, packageHash []byte) []byte {
+	return []byte("Version:1\r\n\r\n" +
+		getDigestAlgorithmOID(hashAlg).String() + "-Hash:" + base64.StdEncoding.EncodeToString(packageHash) + "\r\n\r\n")
+}
+
+// marshalSignedData wraps SignedData in a ContentInfo and DER-encodes it.
+func marshalSignedData(signedData *SignedData) ([]byte, error) {
 	// Encode SignedData
 	signedDataBytes, err := asn1.Marshal(*signedData)
 	if err != nil {
@@ -189,6 +333,44 @@ func createSignedData(contentHash []byte, opts SigningOptions) (*SignedData, err
 // builds authenticated attributes, signs them with the private key using RSA-PKCS#1 v1.5,
 // and optionally adds timestamp unsigned attributes if a timestamp URL is configured.
 func createSignerInfo(contentHash []byte, opts SigningOptions) (*SignerInfo, error) {
+	signedAttrs, err := BuildSignedAttributes(
+		contentHash,
+		opts.SignatureType,
+		opts.Certificate,
+		opts.HashAlgorithm,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("build signed attributes: %w", err)
+	}
+
+	return signSignerInfo(signedAttrs, opts)
+}
+
+// createCounterSignerInfo builds the SignerInfo of a repository countersignature over
+// the signature value hashed to signatureHash. Its authenticated attributes are those of
+// a primary signature without content-type, which countersignatures must not have
+// (RFC 5652 Section 11.1).
+func createCounterSignerInfo(signatureHash []byte, opts SigningOptions) (*SignerInfo, error) {
+	signedAttrs, err := BuildSignedAttributes(
+		signatureHash,
+		opts.SignatureType,
+		opts.Certificate,
+		opts.HashAlgorithm,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("build signed attributes: %w", err)
+	}
+	signedAttrs = slices.DeleteFunc(signedAttrs, func(attr Attribute) bool {
+		return attr.Type.Equal(oidContentType)
+	})
+
+	return signSignerInfo(signedAttrs, opts)
+}
+
+// signSignerInfo signs the authenticated attributes of a signer, adding the
+// nuget-v3-service-index-url and nuget-package-owners attributes of repository
+// signatures, and builds its SignerInfo.
+func signSignerInfo(signedAttrs []Attribute, opts SigningOptions) (*SignerInfo, error) {
 	// 1. Build SignerIdentifier (use IssuerAndSerialNumber or SubjectKeyIdentifier)
 	var sid asn1.RawValue
 
@@ -219,15 +401,20 @@ func createSignerInfo(contentHash []byte, opts SigningOptions) (*SignerInfo, err
 		sid = asn1.RawValue{FullBytes: sidBytes}
 	}
 
-	// 2. Build signed attributes
-	signedAttrs, err := BuildSignedAttributes(
-		contentHash,
-		opts.SignatureType,
-		opts.Certificate,
-		opts.HashAlgorithm,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("build signed attributes: %w", err)
+	// 2. Repository signatures name the repository they were issued for
+	if opts.SignatureType == SignatureTypeRepository && opts.V3ServiceIndexURL != "" {
+		serviceIndexAttr, err := createNuGetV3ServiceIndexURLAttribute(opts.V3ServiceIndexURL)
+		if err != nil {
+			return nil, fmt.Errorf("create nuget-v3-service-index-url: %w", err)
+		}
+		signedAttrs = append(signedAttrs, serviceIndexAttr)
+	}
+	if opts.SignatureType == SignatureTypeRepository && len(opts.PackageOwners) > 0 {
+		ownersAttr, err := createNuGetPackageOwnersAttribute(opts.PackageOwners)
+		if err != nil {
+			return nil, fmt.Errorf("create nuget-package-owners: %w", err)
+		}
+		signedAttrs = append(signedAttrs, ownersAttr)
 	}
 
 	// 3. Encode signed attributes for signing
@@ -259,10 +446,10 @@ func createSignerInfo(contentHash []byte, opts SigningOptions) (*SignerInfo, err
 		Signature: signature,
 	}
 
-	// 6. Add timestamp to unsigned attributes (if TimestampURL provided)
+	// 6. Add timestamp to unsigned attributes (if a timestamp server is configured)
 	// Matches NuGet.Client behavior: X509SignatureProvider.cs:51-58
-	// Timestamps are optional - only added when TimestampURL is configured
-	if opts.TimestampURL != "" {
+	// Timestamps are optional - only added when a timestamp server is configured
+	if len(opts.timestampURLs()) > 0 {
 		timestampAttr, err := createTimestampAttribute(signature, opts)
 		if err != nil {
 			return nil, fmt.Errorf("create timestamp: %w", err)
@@ -317,30 +504,52 @@ func signAttributes(attributesBytes []byte, opts SigningOptions) ([]byte, error)
 	return signature, nil
 }
 
+// timestampURLs returns the timestamp servers to try in order: TimestampURL followed by
+// TimestampURLs, without empty or repeated entries.
+func (opts SigningOptions) timestampURLs() []string {
+	var urls []string
+	for _, url := range append([]string{opts.TimestampURL}, opts.TimestampURLs...) {
+		if url != "" && !slices.Contains(urls, url) {
+			urls = append(urls, url)
+		}
+	}
+	return urls
+}
+
 // createTimestampAttribute requests an RFC 3161 timestamp token from a timestamp authority
 // and creates the unsigned timestamp attribute to be added to SignerInfo.
-// It hashes the signature bytes and sends a timestamp request to the configured TimestampURL.
-// This function is only called when opts.TimestampURL is non-empty.
-// Returns an Attribute with type oidTimestampToken containing the timestamp response.
+// It hashes the signature bytes and sends a timestamp request to each configured server
+// in turn until one returns a valid token. This function is only called when at least
+// one timestamp server is configured.
+// Returns an Attribute with type oidTimestampToken containing the timestamp response,
+// or an error listing why each server failed.
 // Matches NuGet.Client behavior: X509SignatureProvider.TimestampPrimarySignatureAsync
 func createTimestampAttribute(signature []byte, opts SigningOptions) (Attribute, error) {
-	// Request RFC 3161 timestamp token from TSA
-	client := NewTimestampClient(opts.TimestampURL, opts.TimestampTimeout)
-
 	// Hash the signature
 	h := getCryptoHash(opts.HashAlgorithm)
 	hasher := h.New()
 	hasher.Write(signature)
 	signatureHash := hasher.Sum(nil)
 
-	// Request timestamp token
-	timestampToken, err := client.RequestTimestamp(signatureHash, opts.HashAlgorithm)
-	if err != nil {
-		return Attribute{}, fmt.Errorf("request timestamp: %w", err)
+	// Request RFC 3161 timestamp token from each TSA until one succeeds
+	var timestampToken []byte
+	var errs []error
+	for _, url := range opts.timestampURLs() {
+		client := NewTimestampClient(url, opts.TimestampTimeout)
+		token, err := client.RequestTimestamp(signatureHash, opts.HashAlgorithm)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+			continue
+		}
+		timestampToken = token
+		break
+	}
+	if timestampToken == nil {
+		return Attribute{}, fmt.Errorf("request timestamp: no timestamp server returned a valid token: %w", errors.Join(errs...))
 	}
 
 	// Timestamp token is already a ContentInfo, just wrap it in a SET
-	values, err := asn1.Marshal([]asn1.RawValue{{FullBytes: timestampToken}})
+	values, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: timestampToken}}, "set")
 	if err != nil {
 		return Attribute{}, err
 	}
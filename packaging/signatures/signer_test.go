@@ -9,7 +9,12 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"io"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -86,6 +91,64 @@ func TestSignPackageData_RepositorySignature(t *testing.T) {
 	}
 }
 
+func TestSignPackageData_RepositorySignatureServiceIndexURL(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	signerCert, signerKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+
+	contentHash := sha256.Sum256([]byte("test package content"))
+
+	opts := SigningOptions{
+		Certificate:       signerCert,
+		PrivateKey:        signerKey,
+		SignatureType:     SignatureTypeRepository,
+		HashAlgorithm:     HashAlgorithmSHA256,
+		V3ServiceIndexURL: "https://feed.test/v3/index.json",
+	}
+
+	signature, err := SignPackageData(contentHash[:], opts)
+	if err != nil {
+		t.Fatalf("SignPackageData failed: %v", err)
+	}
+
+	sig, err := ReadSignature(signature)
+	if err != nil {
+		t.Fatalf("ReadSignature failed: %v", err)
+	}
+
+	if sig.V3ServiceIndexURL != opts.V3ServiceIndexURL {
+		t.Errorf("V3ServiceIndexURL = %q, want %q", sig.V3ServiceIndexURL, opts.V3ServiceIndexURL)
+	}
+}
+
+func TestSignPackageData_RepositorySignaturePackageOwners(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	signerCert, signerKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+
+	contentHash := sha256.Sum256([]byte("test package content"))
+
+	opts := SigningOptions{
+		Certificate:   signerCert,
+		PrivateKey:    signerKey,
+		SignatureType: SignatureTypeRepository,
+		HashAlgorithm: HashAlgorithmSHA256,
+		PackageOwners: []string{"contoso", "Fabrikam Ltd.", "nörthwind"},
+	}
+
+	signature, err := SignPackageData(contentHash[:], opts)
+	if err != nil {
+		t.Fatalf("SignPackageData failed: %v", err)
+	}
+
+	sig, err := ReadSignature(signature)
+	if err != nil {
+		t.Fatalf("ReadSignature failed: %v", err)
+	}
+
+	if !slices.Equal(sig.PackageOwners, opts.PackageOwners) {
+		t.Errorf("PackageOwners = %q, want %q", sig.PackageOwners, opts.PackageOwners)
+	}
+}
+
 // TestSignPackageData_AllHashAlgorithms tests all supported hash algorithms
 func TestSignPackageData_AllHashAlgorithms(t *testing.T) {
 	rootCert, rootKey := generateTestRootCA(t)
@@ -361,14 +424,14 @@ func TestCreateCommitmentTypeIndicationAttribute(t *testing.T) {
 				t.Fatalf("expected 1 value, got %d", len(values))
 			}
 
-			// Per NuGet.Client, commitment is just an OID (not SEQUENCE wrapper)
-			var commitmentOID asn1.ObjectIdentifier
-			_, err = asn1.Unmarshal(values[0].FullBytes, &commitmentOID)
+			// NuGet.Client reads a CommitmentTypeIndication SEQUENCE holding the OID
+			var commitment CommitmentTypeIndication
+			_, err = asn1.Unmarshal(values[0].FullBytes, &commitment)
 			if err != nil {
-				t.Fatalf("failed to unmarshal commitment OID: %v", err)
+				t.Fatalf("failed to unmarshal commitment type indication: %v", err)
 			}
 
-			if !commitmentOID.Equal(tc.expectedOID) {
+			if commitmentOID := commitment.CommitmentTypeID; !commitmentOID.Equal(tc.expectedOID) {
 				t.Errorf("expected commitment OID %v, got %v", tc.expectedOID, commitmentOID)
 			}
 		})
@@ -625,6 +688,160 @@ func TestSignPackageData_WithTimestamp(t *testing.T) {
 	}
 }
 
+// newTestTSA starts an RFC 3161 timestamp authority that answers every request with a
+// token signed by a test TSA certificate
+func newTestTSA(t *testing.T) *httptest.Server {
+	t.Helper()
+	rootCert, rootKey := generateTestRootCA(t)
+	tsaCert, tsaKey := generateTestTimestampCert(t, rootCert, rootKey)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var req timestampRequest
+		if _, err := asn1.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		token := createTestTimestampToken(t, newTestTSTInfo(req.MessageImprint.HashedMessage, req.Nonce.Bytes()), tsaCert, tsaKey)
+		resp, err := asn1.Marshal(timestampResponse{TimeStampToken: asn1.RawValue{FullBytes: token}})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// hasTimestampAttribute reports whether the single signer of signature has a timestamp
+func hasTimestampAttribute(t *testing.T, signature []byte) bool {
+	t.Helper()
+	var contentInfo ContentInfo
+	if _, err := asn1.Unmarshal(signature, &contentInfo); err != nil {
+		t.Fatalf("failed to parse ContentInfo: %v", err)
+	}
+	var signedData SignedData
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		t.Fatalf("failed to parse SignedData: %v", err)
+	}
+
+	data := signedData.SignerInfos[0].UnsignedAttrs.Bytes
+	for len(data) > 0 {
+		var attr Attribute
+		rest, err := asn1.Unmarshal(data, &attr)
+		if err != nil {
+			t.Fatalf("failed to parse attribute: %v", err)
+		}
+		data = rest
+		if attr.Type.Equal(oidTimestampToken) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSignPackageData_TimestampFallback(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	signerCert, signerKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+	hash := sha256.Sum256([]byte("test package content"))
+
+	tsa := newTestTSA(t)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	// A server that never answers is abandoned once the timeout of its attempt expires
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer hanging.Close()
+
+	opts := SigningOptions{
+		Certificate:      signerCert,
+		PrivateKey:       signerKey,
+		SignatureType:    SignatureTypeAuthor,
+		HashAlgorithm:    HashAlgorithmSHA256,
+		TimestampURL:     failing.URL,
+		TimestampURLs:    []string{hanging.URL, tsa.URL},
+		TimestampTimeout: 500 * time.Millisecond,
+	}
+
+	start := time.Now()
+	signature, err := SignPackageData(hash[:], opts)
+	if err != nil {
+		t.Fatalf("SignPackageData() error = %v, want the last server's timestamp", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("SignPackageData() took %v, want the hanging server abandoned after its timeout", elapsed)
+	}
+	if !hasTimestampAttribute(t, signature) {
+		t.Error("timestamp attribute not found in unsigned attributes")
+	}
+}
+
+func TestSignPackageData_TimestampAllServersFail(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	signerCert, signerKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+	hash := sha256.Sum256([]byte("test package content"))
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+	garbage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not a timestamp response"))
+	}))
+	defer garbage.Close()
+
+	opts := SigningOptions{
+		Certificate:      signerCert,
+		PrivateKey:       signerKey,
+		SignatureType:    SignatureTypeAuthor,
+		HashAlgorithm:    HashAlgorithmSHA256,
+		TimestampURLs:    []string{unavailable.URL, garbage.URL},
+		TimestampTimeout: 5 * time.Second,
+	}
+
+	_, err := SignPackageData(hash[:], opts)
+	if err == nil {
+		t.Fatal("SignPackageData() succeeded, want an error when no server returns a token")
+	}
+	for _, want := range []string{
+		unavailable.URL + ": timestamp server error: HTTP 503",
+		garbage.URL + ": unmarshal timestamp response",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to contain %q", err, want)
+		}
+	}
+}
+
+func TestSigningOptions_TimestampURLs(t *testing.T) {
+	opts := SigningOptions{
+		TimestampURL:  "http://a.example",
+		TimestampURLs: []string{"", "http://b.example", "http://a.example", "http://c.example"},
+	}
+	want := []string{"http://a.example", "http://b.example", "http://c.example"}
+	if got := opts.timestampURLs(); !slices.Equal(got, want) {
+		t.Errorf("timestampURLs() = %q, want %q", got, want)
+	}
+
+	if got := (SigningOptions{}).timestampURLs(); len(got) != 0 {
+		t.Errorf("timestampURLs() = %q, want none", got)
+	}
+}
+
 // TestSignPackageData_WithCertificateChain tests signing with certificate chain
 func TestSignPackageData_WithCertificateChain(t *testing.T) {
 	// Create root → intermediate → signer chain
@@ -773,57 +990,48 @@ func TestBuildTimestampRequest(t *testing.T) {
 	}
 }
 
-func TestVerifyTimestampResponse_ValidToken(t *testing.T) {
-	// Create test certificates
-	rootCert, rootKey := generateTestRootCA(t)
-	tsaCert, tsaKey := generateTestTimestampCert(t, rootCert, rootKey)
-
-	// Create test data
-	h := sha256.New()
-	h.Write([]byte("test message"))
-	messageHash := h.Sum(nil)
+// createTestTimestampToken creates an RFC 3161 timestamp token over info, signed by
+// tsaCert with tsaKey as a TSA signs it: the signed attributes hold the TSTInfo content
+// type and the digest of the TSTInfo.
+func createTestTimestampToken(t *testing.T, info tstInfo, tsaCert *x509.Certificate, tsaKey *rsa.PrivateKey) []byte {
+	t.Helper()
 
-	nonce, err := generateNonce()
+	tstInfoBytes, err := asn1.Marshal(info)
 	if err != nil {
-		t.Fatalf("Failed to generate nonce: %v", err)
+		t.Fatalf("Failed to marshal TSTInfo: %v", err)
 	}
-
-	// Build TSTInfo
-	nonceInt := new(big.Int).SetBytes(nonce)
-	tstInfo := tstInfo{
-		Version: 1,
-		Policy:  asn1.ObjectIdentifier{1, 2, 3, 4, 5}, // Example policy OID
-		MessageImprint: messageImprint{
-			HashAlgorithm: AlgorithmIdentifier{
-				Algorithm: oidSHA256,
-			},
-			HashedMessage: messageHash,
-		},
-		SerialNumber: big.NewInt(1),
-		GenTime:      time.Now(),
-		Nonce:        nonceInt,
+	eContent, err := asn1.Marshal(tstInfoBytes)
+	if err != nil {
+		t.Fatalf("Failed to marshal eContent: %v", err)
 	}
 
-	// Marshal TSTInfo
-	tstInfoBytes, err := asn1.Marshal(tstInfo)
+	contentType, err := asn1.Marshal(oidTSTInfo)
 	if err != nil {
-		t.Fatalf("Failed to marshal TSTInfo: %v", err)
+		t.Fatal(err)
+	}
+	contentTypeValues, err := asn1.MarshalWithParams([]asn1.RawValue{{FullBytes: contentType}}, "set")
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(tstInfoBytes)
+	messageDigestAttr, err := createMessageDigestAttribute(digest[:])
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	// Wrap in OCTET STRING for eContent
-	eContent, err := asn1.Marshal(tstInfoBytes)
+	signerInfo, err := signSignerInfo([]Attribute{
+		{Type: oidContentType, Values: asn1.RawValue{FullBytes: contentTypeValues}},
+		messageDigestAttr,
+	}, SigningOptions{Certificate: tsaCert, PrivateKey: tsaKey, HashAlgorithm: HashAlgorithmSHA256})
 	if err != nil {
-		t.Fatalf("Failed to marshal eContent: %v", err)
+		t.Fatalf("Failed to sign TSTInfo: %v", err)
 	}
 
-	// Create SignedData with TSTInfo as content
-	signedData := SignedData{
-		Version: 1,
-		DigestAlgorithms: []AlgorithmIdentifier{
-			{Algorithm: oidSHA256},
-		},
+	token, err := marshalSignedData(&SignedData{
+		Version:          3,
+		DigestAlgorithms: []AlgorithmIdentifier{{Algorithm: oidSHA256}},
 		ContentInfo: EncapsulatedContentInfo{
-			ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}, // id-ct-TSTInfo
+			ContentType: oidTSTInfo,
 			Content: asn1.RawValue{
 				Class:      asn1.ClassContextSpecific,
 				Tag:        0,
@@ -837,202 +1045,140 @@ func TestVerifyTimestampResponse_ValidToken(t *testing.T) {
 			IsCompound: true,
 			Bytes:      tsaCert.Raw,
 		},
-		SignerInfos: []SignerInfo{
-			{
-				Version: 1,
-				SID: asn1.RawValue{
-					Class: asn1.ClassContextSpecific,
-					Tag:   0,
-					Bytes: tsaCert.SubjectKeyId,
-				},
-				DigestAlgorithm: AlgorithmIdentifier{
-					Algorithm: oidSHA256,
-				},
-				SignedAttrs: asn1.RawValue{
-					Class:      asn1.ClassContextSpecific,
-					Tag:        0,
-					IsCompound: true,
-					Bytes:      []byte{}, // Empty for this test
-				},
-				SignatureAlgorithm: AlgorithmIdentifier{
-					Algorithm: oidSHA256WithRSA,
-				},
-				Signature: make([]byte, 256), // Dummy signature for this test
-			},
-		},
-	}
-
-	// Marshal SignedData
-	signedDataBytes, err := asn1.Marshal(signedData)
+		SignerInfos: []SignerInfo{*signerInfo},
+	})
 	if err != nil {
-		t.Fatalf("Failed to marshal SignedData: %v", err)
+		t.Fatalf("Failed to marshal timestamp token: %v", err)
 	}
+	return token
+}
 
-	// Wrap in ContentInfo
-	contentInfo := ContentInfo{
-		ContentType: oidSignedData,
-		Content: asn1.RawValue{
-			Class:      asn1.ClassContextSpecific,
-			Tag:        0,
-			IsCompound: true,
-			Bytes:      signedDataBytes,
+// newTestTSTInfo returns a TSTInfo over messageHash with the given nonce
+func newTestTSTInfo(messageHash, nonce []byte) tstInfo {
+	return tstInfo{
+		Version: 1,
+		Policy:  asn1.ObjectIdentifier{1, 2, 3, 4, 5}, // Example policy OID
+		MessageImprint: messageImprint{
+			HashAlgorithm: AlgorithmIdentifier{
+				Algorithm: oidSHA256,
+			},
+			HashedMessage: messageHash,
 		},
+		SerialNumber: big.NewInt(1),
+		GenTime:      time.Now().UTC().Truncate(time.Second),
+		Nonce:        new(big.Int).SetBytes(nonce),
 	}
+}
+
+func TestVerifyTimestampResponse_ValidToken(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	tsaCert, tsaKey := generateTestTimestampCert(t, rootCert, rootKey)
 
-	// Marshal ContentInfo (this is the timestamp token)
-	tokenBytes, err := asn1.Marshal(contentInfo)
+	messageHash := sha256.Sum256([]byte("test message"))
+	nonce, err := generateNonce()
 	if err != nil {
-		t.Fatalf("Failed to marshal ContentInfo: %v", err)
+		t.Fatalf("Failed to generate nonce: %v", err)
 	}
 
-	// Test verifyTimestampResponse
-	err = verifyTimestampResponse(tokenBytes, messageHash, nonce)
-	if err != nil {
+	tokenBytes := createTestTimestampToken(t, newTestTSTInfo(messageHash[:], nonce), tsaCert, tsaKey)
+
+	if err := verifyTimestampResponse(tokenBytes, messageHash[:], nonce); err != nil {
 		t.Errorf("verifyTimestampResponse failed: %v", err)
 	}
-
-	// Suppress unused variable warnings
-	_ = rootKey
-	_ = tsaKey
 }
 
 func TestVerifyTimestampResponse_NonceMismatch(t *testing.T) {
-	// Create test certificates
 	rootCert, rootKey := generateTestRootCA(t)
 	tsaCert, tsaKey := generateTestTimestampCert(t, rootCert, rootKey)
 
-	// Create test data
-	h := sha256.New()
-	h.Write([]byte("test message"))
-	messageHash := h.Sum(nil)
+	messageHash := sha256.Sum256([]byte("test message"))
 
 	// Generate two different nonces
 	requestNonce, err := generateNonce()
 	if err != nil {
 		t.Fatalf("Failed to generate request nonce: %v", err)
 	}
+	responseNonce := bytes.Clone(requestNonce)
+	responseNonce[len(responseNonce)-1] ^= 0x01
 
-	responseNonce, err := generateNonce()
-	if err != nil {
-		t.Fatalf("Failed to generate response nonce: %v", err)
-	}
+	tokenBytes := createTestTimestampToken(t, newTestTSTInfo(messageHash[:], responseNonce), tsaCert, tsaKey)
 
-	// Ensure they're different
-	if bytes.Equal(requestNonce, responseNonce) {
-		// Modify one byte to ensure difference
-		responseNonce[0] ^= 0x01
+	err = verifyTimestampResponse(tokenBytes, messageHash[:], requestNonce)
+	if err == nil || err.Error() != "timestamp nonce mismatch" {
+		t.Errorf("Expected 'timestamp nonce mismatch' error, got: %v", err)
 	}
+}
 
-	// Build TSTInfo with wrong nonce
-	responseNonceInt := new(big.Int).SetBytes(responseNonce)
-	tstInfo := tstInfo{
-		Version: 1,
-		Policy:  asn1.ObjectIdentifier{1, 2, 3, 4, 5}, // Example policy OID
-		MessageImprint: messageImprint{
-			HashAlgorithm: AlgorithmIdentifier{
-				Algorithm: oidSHA256,
-			},
-			HashedMessage: messageHash,
-		},
-		SerialNumber: big.NewInt(1),
-		GenTime:      time.Now(),
-		Nonce:        responseNonceInt, // Wrong nonce
-	}
+func TestVerifyTimestampResponse_InvalidTokenSignature(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	tsaCert, tsaKey := generateTestTimestampCert(t, rootCert, rootKey)
+	otherTSACert, _ := generateTestTimestampCert(t, rootCert, rootKey)
+	codeSigningCert, codeSigningKey := generateTestCodeSigningCert(t, rootCert, rootKey)
 
-	// Marshal TSTInfo
-	tstInfoBytes, err := asn1.Marshal(tstInfo)
+	messageHash := sha256.Sum256([]byte("test message"))
+	nonce, err := generateNonce()
 	if err != nil {
-		t.Fatalf("Failed to marshal TSTInfo: %v", err)
+		t.Fatalf("Failed to generate nonce: %v", err)
 	}
+	info := newTestTSTInfo(messageHash[:], nonce)
+	token := createTestTimestampToken(t, info, tsaCert, tsaKey)
 
-	// Wrap in OCTET STRING for eContent
-	eContent, err := asn1.Marshal(tstInfoBytes)
+	// The generation time of the TSTInfo, rewritten without re-signing
+	genTime, err := asn1.Marshal(info.GenTime)
 	if err != nil {
-		t.Fatalf("Failed to marshal eContent: %v", err)
+		t.Fatal(err)
+	}
+	laterGenTime, err := asn1.Marshal(info.GenTime.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(token, genTime) {
+		t.Fatal("generation time not found in the token")
 	}
 
-	// Create SignedData with TSTInfo as content
-	signedData := SignedData{
-		Version: 1,
-		DigestAlgorithms: []AlgorithmIdentifier{
-			{Algorithm: oidSHA256},
+	expiredInfo := info
+	expiredInfo.GenTime = tsaCert.NotAfter.Add(time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name    string
+		token   []byte
+		wantErr string
+	}{
+		{
+			"tampered TSTInfo",
+			bytes.Replace(token, genTime, laterGenTime, 1),
+			"message digest does not match the signed content",
 		},
-		ContentInfo: EncapsulatedContentInfo{
-			ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}, // id-ct-TSTInfo
-			Content: asn1.RawValue{
-				Class:      asn1.ClassContextSpecific,
-				Tag:        0,
-				IsCompound: true,
-				Bytes:      eContent,
-			},
+		{
+			"tampered signature",
+			append(bytes.Clone(token[:len(token)-1]), token[len(token)-1]^0xFF),
+			"invalid RSA signature",
 		},
-		Certificates: asn1.RawValue{
-			Class:      asn1.ClassContextSpecific,
-			Tag:        0,
-			IsCompound: true,
-			Bytes:      tsaCert.Raw,
+		{
+			"signed with another key",
+			createTestTimestampToken(t, info, otherTSACert, tsaKey),
+			"invalid RSA signature",
 		},
-		SignerInfos: []SignerInfo{
-			{
-				Version: 1,
-				SID: asn1.RawValue{
-					Class: asn1.ClassContextSpecific,
-					Tag:   0,
-					Bytes: tsaCert.SubjectKeyId,
-				},
-				DigestAlgorithm: AlgorithmIdentifier{
-					Algorithm: oidSHA256,
-				},
-				SignedAttrs: asn1.RawValue{
-					Class:      asn1.ClassContextSpecific,
-					Tag:        0,
-					IsCompound: true,
-					Bytes:      []byte{}, // Empty for this test
-				},
-				SignatureAlgorithm: AlgorithmIdentifier{
-					Algorithm: oidSHA256WithRSA,
-				},
-				Signature: make([]byte, 256), // Dummy signature for this test
-			},
+		{
+			"no time stamping EKU",
+			createTestTimestampToken(t, info, codeSigningCert, codeSigningKey),
+			"does not have the id-kp-timeStamping extended key usage",
 		},
-	}
-
-	// Marshal SignedData
-	signedDataBytes, err := asn1.Marshal(signedData)
-	if err != nil {
-		t.Fatalf("Failed to marshal SignedData: %v", err)
-	}
-
-	// Wrap in ContentInfo
-	contentInfo := ContentInfo{
-		ContentType: oidSignedData,
-		Content: asn1.RawValue{
-			Class:      asn1.ClassContextSpecific,
-			Tag:        0,
-			IsCompound: true,
-			Bytes:      signedDataBytes,
+		{
+			"outside TSA certificate validity",
+			createTestTimestampToken(t, expiredInfo, tsaCert, tsaKey),
+			"is outside the validity period of TSA certificate",
 		},
 	}
 
-	// Marshal ContentInfo (this is the timestamp token)
-	tokenBytes, err := asn1.Marshal(contentInfo)
-	if err != nil {
-		t.Fatalf("Failed to marshal ContentInfo: %v", err)
-	}
-
-	// Test verifyTimestampResponse - should fail due to nonce mismatch
-	err = verifyTimestampResponse(tokenBytes, messageHash, requestNonce)
-	if err == nil {
-		t.Error("Expected verifyTimestampResponse to fail with nonce mismatch, but it succeeded")
-	}
-
-	if err != nil && err.Error() != "timestamp nonce mismatch" {
-		t.Errorf("Expected 'timestamp nonce mismatch' error, got: %v", err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyTimestampResponse(tt.token, messageHash[:], nonce)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("verifyTimestampResponse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
 	}
-
-	// Suppress unused variable warnings
-	_ = rootKey
-	_ = tsaKey
 }
 
 // Test error paths for coverage
@@ -1804,3 +1950,134 @@ func TestSignAllHashAlgorithmsAndVerify(t *testing.T) {
 		})
 	}
 }
+
+func TestCountersignPackageData(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	authorCert, authorKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+	repoCert, repoKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+
+	packageHash := sha256.Sum256([]byte("test package content for countersigning"))
+	authorOpts := DefaultSigningOptions(authorCert, authorKey)
+	authorOpts.CertificateChain = []*x509.Certificate{rootCert}
+	authorSignature, err := SignPackageHash(packageHash[:], authorOpts)
+	if err != nil {
+		t.Fatalf("SignPackageHash() error = %v", err)
+	}
+
+	repoOpts := DefaultSigningOptions(repoCert, repoKey)
+	repoOpts.CertificateChain = []*x509.Certificate{rootCert}
+	repoOpts.SignatureType = SignatureTypeRepository
+	repoOpts.HashAlgorithm = HashAlgorithmSHA384
+	repoOpts.V3ServiceIndexURL = "https://api.example.org/v3/index.json"
+	countersigned, err := CountersignPackageData(authorSignature, repoOpts)
+	if err != nil {
+		t.Fatalf("CountersignPackageData() error = %v", err)
+	}
+
+	author, err := ReadSignature(authorSignature)
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v", err)
+	}
+	sig, err := ReadSignature(countersigned)
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v", err)
+	}
+
+	// The author signature is left as it was
+	if sig.Type != SignatureTypeAuthor || !sig.SignerCertificate.Equal(authorCert) {
+		t.Errorf("primary signature = %s signed by %s, want the author signature", sig.Type, sig.SignerCertificate.Subject)
+	}
+	if !bytes.Equal(sig.SignedData.SignerInfos[0].Signature, author.SignedData.SignerInfos[0].Signature) ||
+		!bytes.Equal(sig.SignedData.SignerInfos[0].SignedAttrs.FullBytes, author.SignedData.SignerInfos[0].SignedAttrs.FullBytes) {
+		t.Error("countersigning changed the author signer info")
+	}
+	if _, hash, err := signedContentHash(sig); err != nil || !bytes.Equal(hash, packageHash[:]) {
+		t.Errorf("signed content hash = %x, %v, want %x", hash, err, packageHash)
+	}
+	if len(sig.Certificates) != 3 {
+		t.Errorf("len(Certificates) = %d, want the author, repository and root certificates", len(sig.Certificates))
+	}
+
+	if len(sig.Countersignatures) != 1 {
+		t.Fatalf("len(Countersignatures) = %d, want 1", len(sig.Countersignatures))
+	}
+	countersignature := sig.Countersignatures[0]
+	if !countersignature.SignerCertificate.Equal(repoCert) {
+		t.Errorf("countersigner = %s, want the repository certificate", countersignature.SignerCertificate.Subject)
+	}
+	if countersignature.HashAlgorithm != HashAlgorithmSHA384 {
+		t.Errorf("countersignature HashAlgorithm = %s, want SHA384", countersignature.HashAlgorithm)
+	}
+	if countersignature.V3ServiceIndexURL != repoOpts.V3ServiceIndexURL {
+		t.Errorf("countersignature V3ServiceIndexURL = %q, want %q", countersignature.V3ServiceIndexURL, repoOpts.V3ServiceIndexURL)
+	}
+	for data := countersignature.SignerInfo.SignedAttrs.Bytes; len(data) > 0; {
+		var attr Attribute
+		if data, err = asn1.Unmarshal(data, &attr); err != nil {
+			t.Fatalf("unmarshal countersignature attribute: %v", err)
+		}
+		if attr.Type.Equal(oidContentType) {
+			t.Error("countersignature has a content-type attribute")
+		}
+	}
+
+	// Both the author signature and the countersignature verify
+	trustStore := NewTrustStore()
+	trustStore.AddCertificate(rootCert)
+	verifyOpts := DefaultVerificationOptions()
+	verifyOpts.TrustStore = trustStore
+	verifyOpts.AllowedHashAlgorithms = []HashAlgorithmName{HashAlgorithmSHA256, HashAlgorithmSHA384}
+	verifyOpts.VerifyRepositoryCountersignature = true
+	result := VerifySignature(sig, verifyOpts)
+	if !result.IsValid {
+		t.Fatalf("VerifySignature() errors = %v", result.Errors)
+	}
+	if result.RepositoryCountersignature == nil || !result.RepositoryCountersignature.IsValid {
+		t.Errorf("RepositoryCountersignature = %+v, want a valid countersignature", result.RepositoryCountersignature)
+	}
+
+	// A signature is countersigned only once, and only author signatures are
+	if _, err := CountersignPackageData(countersigned, repoOpts); err == nil {
+		t.Error("expected countersigning a countersigned signature to fail")
+	}
+	repoSignature, err := SignPackageHash(packageHash[:], repoOpts)
+	if err != nil {
+		t.Fatalf("SignPackageHash() error = %v", err)
+	}
+	if _, err := CountersignPackageData(repoSignature, repoOpts); err == nil {
+		t.Error("expected countersigning a repository signature to fail")
+	}
+	if _, err := CountersignPackageData(authorSignature, authorOpts); err == nil {
+		t.Error("expected an author countersignature to fail")
+	}
+}
+
+func TestCreateRepositoryCountersignature(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	authorCert, authorKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+	repoCert, repoKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+
+	packageHash := sha256.Sum256([]byte("test package content for countersigning"))
+	authorSignature, err := SignPackageHash(packageHash[:], DefaultSigningOptions(authorCert, authorKey))
+	if err != nil {
+		t.Fatalf("SignPackageHash() error = %v", err)
+	}
+
+	repoOpts := DefaultSigningOptions(repoCert, repoKey)
+	repoOpts.SignatureType = SignatureTypeRepository
+	countersigned, err := CreateRepositoryCountersignature(authorSignature, repoOpts) //nolint:staticcheck // the deprecated name keeps working
+	if err != nil {
+		t.Fatalf("CreateRepositoryCountersignature() error = %v", err)
+	}
+
+	sig, err := ReadSignature(countersigned)
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v", err)
+	}
+	if len(sig.Countersignatures) != 1 || !sig.Countersignatures[0].SignerCertificate.Equal(repoCert) {
+		t.Fatalf("Countersignatures = %+v, want the repository countersignature", sig.Countersignatures)
+	}
+	if sig.RepositoryCountersignature != &sig.Countersignatures[0] { //nolint:staticcheck // the deprecated field is still set
+		t.Error("RepositoryCountersignature does not point to Countersignatures[0]")
+	}
+}
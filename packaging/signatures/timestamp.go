@@ -2,12 +2,16 @@ package signatures
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/asn1"
 	"fmt"
 	"io"
 	"math/big"
 	"net/http"
+	"slices"
 	"time"
 )
 
@@ -182,52 +186,149 @@ func generateNonce() ([]byte, error) {
 }
 
 // verifyTimestampResponse validates that a timestamp token matches the request.
-// It parses the timestamp token (ContentInfo/SignedData structure), extracts the TSTInfo,
-// and verifies that the message imprint hash and nonce match the expected values.
-// This prevents replay attacks and ensures the timestamp applies to the correct data.
+// It verifies the token with verifyTimestampToken, then checks that the message imprint
+// hash and nonce match the expected values. This prevents replay attacks and ensures
+// the timestamp applies to the correct data.
 // Returns an error if verification fails.
 func verifyTimestampResponse(tokenBytes, expectedHash, expectedNonce []byte) error {
+	tstInfo, _, err := verifyTimestampToken(tokenBytes)
+	if err != nil {
+		return err
+	}
+
+	// Verify message imprint hash matches
+	if !bytes.Equal(tstInfo.MessageImprint.HashedMessage, expectedHash) {
+		return fmt.Errorf("timestamp message imprint mismatch")
+	}
+
+	// Verify nonce matches (if present)
+	if tstInfo.Nonce != nil {
+		expectedNonceInt := new(big.Int).SetBytes(expectedNonce)
+		if tstInfo.Nonce.Cmp(expectedNonceInt) != 0 {
+			return fmt.Errorf("timestamp nonce mismatch")
+		}
+	}
+
+	return nil
+}
+
+// verifyTimestampToken parses an RFC 3161 timestamp token (a ContentInfo holding
+// SignedData) and verifies the TSA's signature over its TSTInfo. The token must have a
+// single signer whose certificate is in the token, has the id-kp-timeStamping extended
+// key usage, and is valid at the token's generation time. Returns the TSTInfo and the
+// TSA certificate.
+// Reference: RFC 3161 section 2.3 and 2.4.2, RFC 5652 section 5.4
+func verifyTimestampToken(tokenBytes []byte) (*tstInfo, *x509.Certificate, error) {
 	// Parse the timestamp token (it's a ContentInfo containing SignedData)
 	var contentInfo ContentInfo
 	if _, err := asn1.Unmarshal(tokenBytes, &contentInfo); err != nil {
-		return fmt.Errorf("unmarshal content info: %w", err)
+		return nil, nil, fmt.Errorf("unmarshal content info: %w", err)
 	}
 
 	// Verify contentType is SignedData
 	if !contentInfo.ContentType.Equal(oidSignedData) {
-		return fmt.Errorf("invalid content type: expected SignedData")
+		return nil, nil, fmt.Errorf("invalid content type: expected SignedData")
 	}
 
 	// Parse SignedData
 	var signedData SignedData
 	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
-		return fmt.Errorf("unmarshal signed data: %w", err)
+		return nil, nil, fmt.Errorf("unmarshal signed data: %w", err)
+	}
+	if !signedData.ContentInfo.ContentType.Equal(oidTSTInfo) {
+		return nil, nil, fmt.Errorf("invalid encapsulated content type: expected TSTInfo")
 	}
 
 	// Parse TSTInfo from eContent (eContent is OCTET STRING containing DER-encoded TSTInfo)
 	var eContent []byte
 	if _, err := asn1.Unmarshal(signedData.ContentInfo.Content.Bytes, &eContent); err != nil {
-		return fmt.Errorf("unmarshal eContent: %w", err)
+		return nil, nil, fmt.Errorf("unmarshal eContent: %w", err)
 	}
 
-	var tstInfo tstInfo
-	if _, err := asn1.Unmarshal(eContent, &tstInfo); err != nil {
-		return fmt.Errorf("unmarshal TSTInfo: %w", err)
+	var info tstInfo
+	if _, err := asn1.Unmarshal(eContent, &info); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal TSTInfo: %w", err)
 	}
 
-	// Verify message imprint hash matches
-	if !bytes.Equal(tstInfo.MessageImprint.HashedMessage, expectedHash) {
-		return fmt.Errorf("timestamp message imprint mismatch")
+	// Find the TSA certificate and check its signature over the TSTInfo
+	if len(signedData.SignerInfos) != 1 {
+		return nil, nil, fmt.Errorf("timestamp token has %d signers, expected 1", len(signedData.SignerInfos))
 	}
+	signerInfo := signedData.SignerInfos[0]
 
-	// Verify nonce matches (if present)
-	if tstInfo.Nonce != nil {
-		expectedNonceInt := new(big.Int).SetBytes(expectedNonce)
-		if tstInfo.Nonce.Cmp(expectedNonceInt) != 0 {
-			return fmt.Errorf("timestamp nonce mismatch")
+	certs, err := parseCertificates(signedData.Certificates)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse timestamp certificates: %w", err)
+	}
+	tsaCert, err := findSignerCertificate(signerInfo, certs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("find TSA certificate: %w", err)
+	}
+
+	if err := verifySignerInfoSignature(signerInfo, eContent, tsaCert); err != nil {
+		return nil, nil, fmt.Errorf("timestamp signature verification failed: %w", err)
+	}
+
+	// RFC 3161 section 2.3: the TSA certificate must be for time stamping
+	if !slices.Contains(tsaCert.ExtKeyUsage, x509.ExtKeyUsageTimeStamping) {
+		return nil, nil, fmt.Errorf("TSA certificate %s does not have the id-kp-timeStamping extended key usage", tsaCert.Subject)
+	}
+
+	if info.GenTime.Before(tsaCert.NotBefore) || info.GenTime.After(tsaCert.NotAfter) {
+		return nil, nil, fmt.Errorf("timestamp generation time %s is outside the validity period of TSA certificate %s",
+			info.GenTime.UTC().Format(time.RFC3339), tsaCert.Subject)
+	}
+
+	return &info, tsaCert, nil
+}
+
+// verifySignerInfoSignature verifies the signature of signerInfo over content with the
+// public key of cert. With signed attributes, the message-digest attribute must be the
+// digest of content and the signature is over the DER encoding of the attributes;
+// without, the signature is over content itself.
+// Reference: RFC 5652 section 5.4
+func verifySignerInfoSignature(signerInfo SignerInfo, content []byte, cert *x509.Certificate) error {
+	hashAlg := oidToHashAlgorithm(signerInfo.DigestAlgorithm.Algorithm)
+	if hashAlg == "" {
+		return fmt.Errorf("unsupported digest algorithm %s", signerInfo.DigestAlgorithm.Algorithm)
+	}
+	hash := getCryptoHash(hashAlg)
+
+	h := hash.New()
+	h.Write(content)
+	contentDigest := h.Sum(nil)
+
+	digest := contentDigest
+	if len(signerInfo.SignedAttrs.FullBytes) > 0 {
+		messageDigest := extractMessageDigest(signerInfo)
+		if messageDigest == nil {
+			return fmt.Errorf("signed attributes have no message digest")
+		}
+		if !bytes.Equal(messageDigest, contentDigest) {
+			return fmt.Errorf("message digest does not match the signed content")
 		}
+
+		// The signature is over the attributes encoded as a SET OF, not with the
+		// [0] IMPLICIT tag they are stored with
+		signedAttrs := slices.Clone(signerInfo.SignedAttrs.FullBytes)
+		signedAttrs[0] = 0x31
+		attrsHash := hash.New()
+		attrsHash.Write(signedAttrs)
+		digest = attrsHash.Sum(nil)
 	}
 
+	switch pub := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if err := rsa.VerifyPKCS1v15(pub, hash, digest, signerInfo.Signature); err != nil {
+			return fmt.Errorf("invalid RSA signature: %w", err)
+		}
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(pub, digest, signerInfo.Signature) {
+			return fmt.Errorf("invalid ECDSA signature")
+		}
+	default:
+		return fmt.Errorf("unsupported public key type %T", cert.PublicKey)
+	}
 	return nil
 }
 
@@ -1,8 +1,13 @@
 package signatures
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"fmt"
+	"slices"
+	"strings"
 )
 
 // TrustStore manages trusted root certificates
@@ -46,3 +51,170 @@ func (ts *TrustStore) AddCertificatePEM(pemData []byte) error {
 func (ts *TrustStore) GetRootPool() *x509.CertPool {
 	return ts.roots
 }
+
+// TrustPolicy is an allowlist of trusted signers, as configured by the trustedSigners
+// section of NuGet.config. A signature is trusted by the policy when its signer
+// certificate is one of a trusted author's certificates, or when the signer of its
+// repository signature or countersignature is one of a trusted repository's
+// certificates. An empty policy trusts every signer.
+// Reference: NuGet.Client AllowListVerificationProvider
+type TrustPolicy struct {
+	Authors      []TrustedAuthor
+	Repositories []TrustedRepository
+}
+
+// TrustedAuthor is an author whose signatures are trusted
+type TrustedAuthor struct {
+	Name         string
+	Certificates []TrustedCertificate
+}
+
+// TrustedRepository is a repository whose signatures and countersignatures are
+// trusted. When Owners is set, the repository signature must also list one of them
+// in its nuget-package-owners attribute.
+type TrustedRepository struct {
+	Name         string
+	ServiceIndex string
+	Certificates []TrustedCertificate
+	Owners       []string
+}
+
+// TrustedCertificate is a trusted signer certificate, identified by its fingerprint
+type TrustedCertificate struct {
+	// Fingerprint is the hex fingerprint of the certificate computed with HashAlgorithm
+	Fingerprint string
+
+	// HashAlgorithm is the fingerprint algorithm: SHA256 (the default when empty),
+	// SHA384 or SHA512
+	HashAlgorithm HashAlgorithmName
+
+	// AllowUntrustedRoot accepts the certificate when its chain ends in an untrusted root
+	AllowUntrustedRoot bool
+}
+
+// UntrustedSignerError is returned when a signature has a valid certificate chain but
+// neither its signer nor its repository countersigner is trusted by the TrustPolicy.
+type UntrustedSignerError struct {
+	// Certificate is the signer certificate of the primary signature
+	Certificate *x509.Certificate
+
+	// Reason explains why no trusted signer matched
+	Reason string
+}
+
+func (e *UntrustedSignerError) Error() string {
+	fingerprint := sha256.Sum256(e.Certificate.Raw)
+	return fmt.Sprintf("signer %s (SHA-256 fingerprint %X) is not a trusted signer: %s", e.Certificate.Subject, fingerprint, e.Reason)
+}
+
+// isEmpty reports whether the policy trusts every signer
+func (p *TrustPolicy) isEmpty() bool {
+	return p == nil || (len(p.Authors) == 0 && len(p.Repositories) == 0)
+}
+
+// verify returns an *UntrustedSignerError when no signer of sig is trusted by the
+// policy. The author signer of an author signature, or the repository signer of a
+// repository signature or countersignature, must match a trusted certificate.
+func (p *TrustPolicy) verify(sig *PrimarySignature) error {
+	if p.isEmpty() || sig.SignerCertificate == nil {
+		return nil
+	}
+
+	var ownersMismatch bool
+	switch sig.Type {
+	case SignatureTypeAuthor:
+		if p.trustedAuthor(sig.SignerCertificate) != nil {
+			return nil
+		}
+		if cs := sig.repositoryCountersignature(); cs != nil {
+			repository, owners := p.trustedRepository(cs.SignerCertificate, cs.PackageOwners)
+			if repository != nil && owners {
+				return nil
+			}
+			ownersMismatch = repository != nil
+		}
+	case SignatureTypeRepository:
+		repository, owners := p.trustedRepository(sig.SignerCertificate, sig.PackageOwners)
+		if repository != nil && owners {
+			return nil
+		}
+		ownersMismatch = repository != nil
+	}
+
+	reason := "its certificate does not match a trusted author or repository certificate"
+	if ownersMismatch {
+		reason = "the repository signature does not list one of the trusted package owners"
+	}
+	return &UntrustedSignerError{Certificate: sig.SignerCertificate, Reason: reason}
+}
+
+// allowsUntrustedRoot reports whether the signer of sig matches a trusted certificate
+// that accepts an untrusted root
+func (p *TrustPolicy) allowsUntrustedRoot(sig *PrimarySignature) bool {
+	if p.isEmpty() || sig.SignerCertificate == nil {
+		return false
+	}
+
+	var certificates []TrustedCertificate
+	switch sig.Type {
+	case SignatureTypeAuthor:
+		for _, author := range p.Authors {
+			certificates = append(certificates, author.Certificates...)
+		}
+	case SignatureTypeRepository:
+		for _, repository := range p.Repositories {
+			certificates = append(certificates, repository.Certificates...)
+		}
+	}
+	for _, certificate := range certificates {
+		if certificate.AllowUntrustedRoot && certificate.matches(sig.SignerCertificate) {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedAuthor returns the trusted author with the certificate cert
+func (p *TrustPolicy) trustedAuthor(cert *x509.Certificate) *TrustedAuthor {
+	for i, author := range p.Authors {
+		for _, certificate := range author.Certificates {
+			if certificate.matches(cert) {
+				return &p.Authors[i]
+			}
+		}
+	}
+	return nil
+}
+
+// trustedRepository returns the trusted repository with the certificate cert, and
+// whether owners, the package owners of the repository signature, satisfy its owners
+// constraint. A repository whose owners match is preferred.
+func (p *TrustPolicy) trustedRepository(cert *x509.Certificate, owners []string) (*TrustedRepository, bool) {
+	var found *TrustedRepository
+	for i, repository := range p.Repositories {
+		if !slices.ContainsFunc(repository.Certificates, func(c TrustedCertificate) bool { return c.matches(cert) }) {
+			continue
+		}
+		if len(repository.Owners) == 0 || slices.ContainsFunc(repository.Owners, func(owner string) bool { return slices.Contains(owners, owner) }) {
+			return &p.Repositories[i], true
+		}
+		if found == nil {
+			found = &p.Repositories[i]
+		}
+	}
+	return found, false
+}
+
+// matches reports whether cert has the fingerprint of the trusted certificate
+func (c TrustedCertificate) matches(cert *x509.Certificate) bool {
+	if cert == nil {
+		return false
+	}
+	want, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(c.Fingerprint), ":", ""))
+	if err != nil {
+		return false
+	}
+	hash := getCryptoHash(c.HashAlgorithm).New()
+	hash.Write(cert.Raw)
+	return bytes.Equal(hash.Sum(nil), want)
+}
@@ -1,11 +1,19 @@
 package signatures
 
 import (
+	"bytes"
 	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
+
+	nugethttp "github.com/willibrandon/gonuget/http"
 )
 
 // VerificationOptions configures signature verification
@@ -30,6 +38,52 @@ type VerificationOptions struct {
 
 	// VerificationTime is the time at which to verify (defaults to Now)
 	VerificationTime *time.Time
+
+	// SourceServiceIndexURL is the service index URL the package was obtained from.
+	// When set, a repository signature must have been issued for this URL
+	// or one of AllowedServiceIndexURLs.
+	SourceServiceIndexURL string
+
+	// AllowedServiceIndexURLs lists additional service index URLs accepted
+	// for repository signatures (e.g. mirrors of the source)
+	AllowedServiceIndexURLs []string
+
+	// RevocationMode controls whether and how the signer certificate and the
+	// intermediate certificates of its chain are checked for revocation: online with
+	// OCSP and CRLs, offline with the CRLs of CRLCache, or not at all
+	// (RevocationModeDisabled, the default)
+	RevocationMode RevocationMode
+
+	// AllowUnknownRevocation only adds a warning, instead of failing verification, when
+	// the revocation status can't be determined, for example because the OCSP responder
+	// and CRL distribution points are unreachable. DefaultVerificationOptions sets it,
+	// as NuGet's default verification policies do.
+	AllowUnknownRevocation bool
+
+	// RevocationTimeout bounds each OCSP request and CRL download. Zero uses 10 seconds
+	// for OCSP requests and 30 seconds for CRL downloads.
+	RevocationTimeout time.Duration
+
+	// OCSPCache shares OCSP responses between verifications (nil disables caching)
+	OCSPCache *OCSPCache
+
+	// CRLCache holds the CRLs used to check revocation: pre-seeded ones for offline
+	// checks, and those downloaded by online checks (nil disables caching)
+	CRLCache *CRLCache
+
+	// AllowedSignerFingerprints, when set, lists the SHA-256, SHA-384 or SHA-512
+	// fingerprints (hex) of the certificates allowed to sign the primary signature
+	AllowedSignerFingerprints []string
+
+	// TrustPolicy, when set, restricts the trusted signers to the authors and
+	// repositories it lists, as the trustedSigners section of NuGet.config does. A
+	// signature with a valid chain whose signers the policy doesn't trust fails with an
+	// *UntrustedSignerError.
+	TrustPolicy *TrustPolicy
+
+	// VerifyRepositoryCountersignature also verifies the repository countersignature
+	// of an author signature, when there is one
+	VerifyRepositoryCountersignature bool
 }
 
 // DefaultVerificationOptions returns secure default options
@@ -45,9 +99,33 @@ func DefaultVerificationOptions() VerificationOptions {
 			HashAlgorithmSHA384,
 			HashAlgorithmSHA512,
 		},
+		AllowUnknownRevocation: true,
 	}
 }
 
+// NuGet log codes of the verification issues, so that results can be compared with
+// NuGet.Client's
+const (
+	// CodeCertificateRevoked (NU3012) reports a revoked certificate in the signer's chain
+	CodeCertificateRevoked = "NU3012"
+
+	// CodeChainBuildingIssue (NU3018) reports a certificate chain that could not be
+	// verified, an untrusted root, or a revocation status that could not be checked
+	CodeChainBuildingIssue = "NU3018"
+
+	// CodeUntrustedSigner (NU3034) reports a signature whose signers are not trusted
+	// by the trust policy
+	CodeUntrustedSigner = "NU3034"
+)
+
+// VerificationIssue is an error or warning of a verification, with the NuGet log code
+// NuGet.Client reports it with.
+type VerificationIssue struct {
+	Code    string
+	Message string
+	IsError bool
+}
+
 // VerificationResult contains verification results
 type VerificationResult struct {
 	// IsValid indicates if signature is valid
@@ -73,13 +151,35 @@ type VerificationResult struct {
 
 	// SigningTime is the verified signing time (from timestamp)
 	SigningTime *time.Time
+
+	// RepositoryCountersignature is the result of verifying the repository
+	// countersignature (nil when it was not verified)
+	RepositoryCountersignature *VerificationResult
+
+	// Issues holds the errors and warnings that have a NuGet log code, in the order
+	// they were found. They also appear in Errors and Warnings.
+	Issues []VerificationIssue
+}
+
+// addError fails the result with err, reported with the NuGet log code
+func (r *VerificationResult) addError(code string, err error) {
+	r.IsValid = false
+	r.Errors = append(r.Errors, err)
+	r.Issues = append(r.Issues, VerificationIssue{Code: code, Message: err.Error(), IsError: true})
+}
+
+// addWarning adds a warning reported with the NuGet log code
+func (r *VerificationResult) addWarning(code, warning string) {
+	r.Warnings = append(r.Warnings, warning)
+	r.Issues = append(r.Issues, VerificationIssue{Code: code, Message: warning})
 }
 
 // VerifySignature verifies a package signature
 func VerifySignature(sig *PrimarySignature, opts VerificationOptions) VerificationResult {
 	result := VerificationResult{
-		IsValid:       true,
-		SignatureType: sig.Type,
+		IsValid:           true,
+		SignatureType:     sig.Type,
+		SignerCertificate: sig.SignerCertificate,
 	}
 
 	// Verify signature type is allowed
@@ -97,22 +197,61 @@ func VerifySignature(sig *PrimarySignature, opts VerificationOptions) Verificati
 		return result
 	}
 
+	// Verify the repository signature was issued for the source
+	if err := verifyServiceIndexURL(sig, opts); err != nil {
+		result.IsValid = false
+		result.Errors = append(result.Errors, err)
+		return result
+	}
+
+	// Verify the signer is one of the allowed certificates
+	if len(opts.AllowedSignerFingerprints) > 0 && !signerFingerprintAllowed(sig.SignerCertificate, opts.AllowedSignerFingerprints) {
+		result.IsValid = false
+		result.Errors = append(result.Errors, fmt.Errorf("signer certificate does not match any allowed certificate fingerprint"))
+		return result
+	}
+
 	// Verify certificate chain
 	chainResult := verifyCertificateChain(sig, opts)
 	result.SignerCertificate = chainResult.SignerCertificate
 	result.TrustedRoot = chainResult.TrustedRoot
 
 	if !chainResult.IsValid {
-		if !opts.AllowUntrustedRoot {
+		// A trusted signer certificate may accept an untrusted root
+		if !opts.AllowUntrustedRoot && !opts.TrustPolicy.allowsUntrustedRoot(sig) {
 			// Only fail if untrusted roots are not allowed
-			result.IsValid = false
-			result.Errors = append(result.Errors, chainResult.Errors...)
+			for _, err := range chainResult.Errors {
+				result.addError(CodeChainBuildingIssue, err)
+			}
 			return result
 		}
 
 		// Continue with untrusted root if allowed
 		// Don't set IsValid=false, just add a warning
-		result.Warnings = append(result.Warnings, "Signature has untrusted root certificate")
+		result.addWarning(CodeChainBuildingIssue, "Signature has untrusted root certificate")
+	}
+
+	// Check the certificates of the signer's chain have not been revoked. As in
+	// NuGet.Client, an unknown revocation status, such as when the revocation
+	// endpoints are unreachable, is a warning when AllowUnknownRevocation is set.
+	if (opts.RevocationMode == RevocationModeOnline || opts.RevocationMode == RevocationModeOffline) && sig.SignerCertificate != nil {
+		for _, err := range verifyChainRevocation(sig, chainResult.Chain, opts) {
+			var revoked *RevokedCertificateError
+			var unavailable *RevocationUnavailableError
+			switch {
+			case errors.As(err, &revoked):
+				result.addError(CodeCertificateRevoked, err)
+			case errors.As(err, &unavailable) && opts.AllowUnknownRevocation:
+				result.addWarning(CodeChainBuildingIssue, err.Error())
+			default:
+				result.addError(CodeChainBuildingIssue, err)
+			}
+		}
+	}
+
+	// Check the signer, or the repository countersigner, is trusted by the policy
+	if err := opts.TrustPolicy.verify(sig); err != nil {
+		result.addError(CodeUntrustedSigner, err)
 	}
 
 	// Verify timestamp if present
@@ -141,9 +280,105 @@ func VerifySignature(sig *PrimarySignature, opts VerificationOptions) Verificati
 		result.Errors = append(result.Errors, err)
 	}
 
+	// Verify the repository countersignature
+	if opts.VerifyRepositoryCountersignature && sig.RepositoryCountersignature != nil {
+		csResult := verifyRepositoryCountersignature(sig, opts)
+		result.RepositoryCountersignature = &csResult
+		if !csResult.IsValid {
+			result.IsValid = false
+		}
+		for _, err := range csResult.Errors {
+			result.Errors = append(result.Errors, fmt.Errorf("repository countersignature: %w", err))
+		}
+		for _, warning := range csResult.Warnings {
+			result.Warnings = append(result.Warnings, "Repository countersignature: "+warning)
+		}
+		for _, issue := range csResult.Issues {
+			if issue.IsError {
+				issue.Message = "repository countersignature: " + issue.Message
+			} else {
+				issue.Message = "Repository countersignature: " + issue.Message
+			}
+			result.Issues = append(result.Issues, issue)
+		}
+	}
+
 	return result
 }
 
+// verifyRepositoryCountersignature verifies a repository countersignature as a
+// repository primary signature is verified, and checks that it countersigns the
+// author signature: its message digest must be the hash of the author signature value.
+// Reference: NuGet.Client RepositoryCountersignature.Verify
+func verifyRepositoryCountersignature(sig *PrimarySignature, opts VerificationOptions) VerificationResult {
+	countersignature := sig.repositoryCountersignature()
+
+	// The allowed fingerprints are those of the primary signer, and the trust policy
+	// applies to the signature as a whole; only its untrusted root exemptions apply
+	opts.AllowedSignerFingerprints = nil
+	opts.AllowUntrustedRoot = opts.AllowUntrustedRoot || opts.TrustPolicy.allowsUntrustedRoot(&PrimarySignature{
+		Type:              SignatureTypeRepository,
+		SignerCertificate: countersignature.SignerCertificate,
+	})
+	opts.TrustPolicy = nil
+	opts.VerifyRepositoryCountersignature = false
+	result := VerifySignature(&PrimarySignature{
+		Type:              SignatureTypeRepository,
+		SignerCertificate: countersignature.SignerCertificate,
+		Certificates:      sig.Certificates,
+		Timestamps:        countersignature.Timestamps,
+		HashAlgorithm:     countersignature.HashAlgorithm,
+		V3ServiceIndexURL: countersignature.V3ServiceIndexURL,
+	}, opts)
+
+	digest := extractMessageDigest(countersignature.SignerInfo)
+	if countersignature.HashAlgorithm == "" || digest == nil || sig.SignedData == nil || len(sig.SignedData.SignerInfos) == 0 {
+		result.IsValid = false
+		result.Errors = append(result.Errors, fmt.Errorf("countersignature has no message digest"))
+		return result
+	}
+	hash := getCryptoHash(countersignature.HashAlgorithm).New()
+	hash.Write(sig.SignedData.SignerInfos[0].Signature)
+	if !bytes.Equal(hash.Sum(nil), digest) {
+		result.IsValid = false
+		result.Errors = append(result.Errors, fmt.Errorf("countersignature does not countersign the author signature"))
+	}
+
+	return result
+}
+
+// signerFingerprintAllowed reports whether the SHA-256, SHA-384 or SHA-512 fingerprint
+// of cert, chosen by the length of each fingerprint, is one of fingerprints.
+func signerFingerprintAllowed(cert *x509.Certificate, fingerprints []string) bool {
+	if cert == nil {
+		return false
+	}
+	for _, fingerprint := range fingerprints {
+		want, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+		if err != nil {
+			continue
+		}
+		var got []byte
+		switch len(want) {
+		case sha256.Size:
+			sum := sha256.Sum256(cert.Raw)
+			got = sum[:]
+		case sha512.Size384:
+			sum := sha512.Sum384(cert.Raw)
+			got = sum[:]
+		case sha512.Size:
+			sum := sha512.Sum512(cert.Raw)
+			got = sum[:]
+		default:
+			continue
+		}
+		if bytes.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
 func isSignatureTypeAllowed(sigType SignatureType, allowed []SignatureType) bool {
 	return slices.Contains(allowed, sigType)
 }
@@ -152,6 +387,70 @@ func isHashAlgorithmAllowed(hashAlg HashAlgorithmName, allowed []HashAlgorithmNa
 	return slices.Contains(allowed, hashAlg)
 }
 
+// verifyServiceIndexURL checks that a repository signature's nuget-v3-service-index-url
+// matches the source or an allowed URL. URLs are compared case-insensitively, ignoring
+// a trailing slash. No check is made when neither option is set.
+func verifyServiceIndexURL(sig *PrimarySignature, opts VerificationOptions) error {
+	if sig.Type != SignatureTypeRepository {
+		return nil
+	}
+	if opts.SourceServiceIndexURL == "" && len(opts.AllowedServiceIndexURLs) == 0 {
+		return nil
+	}
+
+	if sig.V3ServiceIndexURL == "" {
+		return fmt.Errorf("repository signature does not specify a service index URL")
+	}
+
+	allowed := opts.AllowedServiceIndexURLs
+	if opts.SourceServiceIndexURL != "" {
+		allowed = append([]string{opts.SourceServiceIndexURL}, allowed...)
+	}
+	for _, url := range allowed {
+		if nugethttp.SameSourceURL(sig.V3ServiceIndexURL, url) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("repository signature was issued for %s, not for the package source", sig.V3ServiceIndexURL)
+}
+
+// verifyChainRevocation checks the revocation status of the signer certificate and of
+// the intermediate certificates of its chain, returning an error for each certificate
+// that fails. The chain is the verified one, or is built from the signature's
+// certificates when it could not be verified; it then ends with the last certificate
+// whose issuer is known.
+func verifyChainRevocation(sig *PrimarySignature, chain []*x509.Certificate, opts VerificationOptions) []error {
+	if len(chain) == 0 {
+		chain = buildChain(sig.SignerCertificate, sig.Certificates)
+	}
+	if len(chain) < 2 {
+		return []error{&RevocationUnavailableError{Certificate: sig.SignerCertificate, Err: errors.New("the issuer certificate was not found")}}
+	}
+
+	checker := newRevocationChecker(opts)
+	var errs []error
+	for i := range len(chain) - 1 {
+		if err := checker.check(chain[i], chain[i+1]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// buildChain follows the issuers of cert among certs, until a self-signed certificate or
+// one whose issuer isn't among them
+func buildChain(cert *x509.Certificate, certs []*x509.Certificate) []*x509.Certificate {
+	chain := []*x509.Certificate{cert}
+	for {
+		issuer := findIssuer(chain[len(chain)-1], certs)
+		if issuer == nil || slices.Contains(chain, issuer) {
+			return chain
+		}
+		chain = append(chain, issuer)
+	}
+}
+
 func verifySignerKeyLength(cert *x509.Certificate) error {
 	// RSA minimum 2048 bits
 	// Reference: SigningSpecificationsV1.cs
@@ -174,6 +473,7 @@ type CertificateChainResult struct {
 	IsValid           bool
 	SignerCertificate *x509.Certificate
 	TrustedRoot       *x509.Certificate
+	Chain             []*x509.Certificate
 	Errors            []error
 }
 
@@ -224,6 +524,7 @@ func verifyCertificateChain(sig *PrimarySignature, opts VerificationOptions) Cer
 
 	// Get trusted root from first valid chain
 	if len(chains) > 0 && len(chains[0]) > 0 {
+		result.Chain = chains[0]
 		result.TrustedRoot = chains[0][len(chains[0])-1]
 	}
 
@@ -250,6 +551,15 @@ func verifyTimestamp(ts Timestamp, opts VerificationOptions) TimestampResult {
 		return result
 	}
 
+	// Verify the TSA's signature over the token, so that a tampered token is rejected
+	if len(ts.RawData) > 0 {
+		if _, _, err := verifyTimestampToken(ts.RawData); err != nil {
+			result.IsValid = false
+			result.Errors = append(result.Errors, err)
+			return result
+		}
+	}
+
 	// Verify TSA certificate chain
 	tsaOpts := x509.VerifyOptions{
 		CurrentTime: ts.Time,
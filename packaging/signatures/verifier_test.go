@@ -1,13 +1,21 @@
 package signatures
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"math/big"
+	"os"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -333,6 +341,58 @@ func TestVerifySignature_ValidRepositorySignature(t *testing.T) {
 	}
 }
 
+func TestVerifySignature_ServiceIndexURL(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	signerCert, _ := generateTestCodeSigningCert(t, rootCert, rootKey)
+
+	trustStore := NewTrustStore()
+	trustStore.AddCertificate(rootCert)
+
+	tests := []struct {
+		name      string
+		sigType   SignatureType
+		sigURL    string
+		sourceURL string
+		allowed   []string
+		wantValid bool
+	}{
+		{name: "no check configured", sigType: SignatureTypeRepository, sigURL: "https://other.test/v3/index.json", wantValid: true},
+		{name: "matches source", sigType: SignatureTypeRepository, sigURL: "https://feed.test/v3/index.json", sourceURL: "https://feed.test/v3/index.json", wantValid: true},
+		{name: "case and trailing slash ignored", sigType: SignatureTypeRepository, sigURL: "https://FEED.test/v3/index.json", sourceURL: "https://feed.test/v3/index.json/", wantValid: true},
+		{name: "signed for another repository", sigType: SignatureTypeRepository, sigURL: "https://other.test/v3/index.json", sourceURL: "https://feed.test/v3/index.json", wantValid: false},
+		{name: "allow-list match", sigType: SignatureTypeRepository, sigURL: "https://mirror.test/v3/index.json", sourceURL: "https://feed.test/v3/index.json", allowed: []string{"https://mirror.test/v3/index.json"}, wantValid: true},
+		{name: "allow-list only", sigType: SignatureTypeRepository, sigURL: "https://mirror.test/v3/index.json", allowed: []string{"https://mirror.test/v3/index.json"}, wantValid: true},
+		{name: "missing URL", sigType: SignatureTypeRepository, sourceURL: "https://feed.test/v3/index.json", wantValid: false},
+		{name: "author signature not checked", sigType: SignatureTypeAuthor, sourceURL: "https://feed.test/v3/index.json", wantValid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig := &PrimarySignature{
+				Type:              tt.sigType,
+				SignerCertificate: signerCert,
+				Certificates:      []*x509.Certificate{signerCert, rootCert},
+				HashAlgorithm:     HashAlgorithmSHA256,
+				V3ServiceIndexURL: tt.sigURL,
+			}
+
+			opts := DefaultVerificationOptions()
+			opts.TrustStore = trustStore
+			opts.SourceServiceIndexURL = tt.sourceURL
+			opts.AllowedServiceIndexURLs = tt.allowed
+
+			result := VerifySignature(sig, opts)
+
+			if result.IsValid != tt.wantValid {
+				t.Errorf("IsValid = %v, want %v (errors: %v)", result.IsValid, tt.wantValid, result.Errors)
+			}
+			if !tt.wantValid && len(result.Errors) == 0 {
+				t.Error("expected error about the service index URL")
+			}
+		})
+	}
+}
+
 func TestVerifySignature_DisallowedSignatureType(t *testing.T) {
 	rootCert, rootKey := generateTestRootCA(t)
 	signerCert, _ := generateTestCodeSigningCert(t, rootCert, rootKey)
@@ -851,3 +911,300 @@ func TestVerifySignerKeyLength_Weak1024Bit(t *testing.T) {
 		t.Errorf("unexpected error message: %v", err)
 	}
 }
+
+func TestVerifySignature_RepositoryCountersignature(t *testing.T) {
+	sigData, err := os.ReadFile("testdata/newtonsoft.signature.p7s")
+	if err != nil {
+		t.Fatalf("Failed to read signature file: %v", err)
+	}
+	sig, err := ReadSignature(sigData)
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v", err)
+	}
+
+	opts := DefaultVerificationOptions()
+	opts.AllowUntrustedRoot = true
+	opts.VerifyTimestamp = false
+	opts.VerifyRepositoryCountersignature = true
+	signingTime := sig.Timestamps[0].Time
+	opts.VerificationTime = &signingTime
+
+	result := VerifySignature(sig, opts)
+	if result.RepositoryCountersignature == nil {
+		t.Fatal("repository countersignature was not verified")
+	}
+	if got := result.RepositoryCountersignature.SignerCertificate; got != sig.Countersignatures[0].SignerCertificate {
+		t.Errorf("countersignature SignerCertificate = %v, want the countersigner", got)
+	}
+	for _, err := range result.Errors {
+		if strings.Contains(err.Error(), "countersign") {
+			t.Errorf("unexpected countersignature error: %v", err)
+		}
+	}
+
+	// A countersignature moved to another author signature doesn't countersign it
+	tampered := *sig
+	tampered.SignedData = &SignedData{SignerInfos: []SignerInfo{sig.SignedData.SignerInfos[0]}}
+	tampered.SignedData.SignerInfos[0].Signature = append([]byte{0}, sig.SignedData.SignerInfos[0].Signature[1:]...)
+	result = VerifySignature(&tampered, opts)
+	if result.IsValid {
+		t.Error("expected a countersignature of another signature to fail verification")
+	}
+	found := false
+	for _, err := range result.Errors {
+		if strings.Contains(err.Error(), "does not countersign the author signature") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("errors = %v, want a countersignature mismatch", result.Errors)
+	}
+}
+
+func TestVerifySignature_AllowedSignerFingerprints(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	signerCert, _ := generateTestCodeSigningCert(t, rootCert, rootKey)
+	trustStore := NewTrustStore()
+	trustStore.AddCertificate(rootCert)
+	sig := &PrimarySignature{
+		Type:              SignatureTypeAuthor,
+		SignerCertificate: signerCert,
+		Certificates:      []*x509.Certificate{signerCert, rootCert},
+		HashAlgorithm:     HashAlgorithmSHA256,
+	}
+
+	sha256Sum := sha256.Sum256(signerCert.Raw)
+	sha384Sum := sha512.Sum384(signerCert.Raw)
+	sha512Sum := sha512.Sum512(signerCert.Raw)
+	otherSum := sha256.Sum256(rootCert.Raw)
+
+	tests := []struct {
+		name         string
+		fingerprints []string
+		wantValid    bool
+	}{
+		{"SHA-256", []string{hex.EncodeToString(otherSum[:]), hex.EncodeToString(sha256Sum[:])}, true},
+		{"SHA-384 upper case", []string{strings.ToUpper(hex.EncodeToString(sha384Sum[:]))}, true},
+		{"SHA-512", []string{hex.EncodeToString(sha512Sum[:])}, true},
+		{"other certificate", []string{hex.EncodeToString(otherSum[:])}, false},
+		{"SHA-1 length", []string{hex.EncodeToString(sha256Sum[:20])}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultVerificationOptions()
+			opts.TrustStore = trustStore
+			opts.AllowedSignerFingerprints = tt.fingerprints
+
+			result := VerifySignature(sig, opts)
+			if result.IsValid != tt.wantValid {
+				t.Errorf("IsValid = %v, want %v (errors: %v)", result.IsValid, tt.wantValid, result.Errors)
+			}
+		})
+	}
+}
+
+func TestVerifySignature_TrustPolicy(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	signerCert, signerKey := generateTestCodeSigningCert(t, rootCert, rootKey)
+	otherCert, _ := generateTestCodeSigningCert(t, rootCert, rootKey)
+	trustStore := NewTrustStore()
+	trustStore.AddCertificate(rootCert)
+
+	contentHash := sha256.Sum256([]byte("test package content"))
+	signed, err := SignPackageData(contentHash[:], SigningOptions{
+		Certificate:       signerCert,
+		PrivateKey:        signerKey,
+		SignatureType:     SignatureTypeRepository,
+		HashAlgorithm:     HashAlgorithmSHA256,
+		V3ServiceIndexURL: "https://feed.test/v3/index.json",
+		PackageOwners:     []string{"contoso", "fabrikam"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repositorySig, err := ReadSignature(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorSig := &PrimarySignature{
+		Type:              SignatureTypeAuthor,
+		SignerCertificate: signerCert,
+		Certificates:      []*x509.Certificate{signerCert, rootCert},
+		HashAlgorithm:     HashAlgorithmSHA256,
+	}
+
+	signerSum := sha256.Sum256(signerCert.Raw)
+	signerSHA512 := sha512.Sum512(signerCert.Raw)
+	otherSum := sha256.Sum256(otherCert.Raw)
+	signer := TrustedCertificate{Fingerprint: hex.EncodeToString(signerSum[:]), HashAlgorithm: HashAlgorithmSHA256}
+	other := TrustedCertificate{Fingerprint: hex.EncodeToString(otherSum[:]), HashAlgorithm: HashAlgorithmSHA256}
+
+	tests := []struct {
+		name        string
+		sig         *PrimarySignature
+		policy      *TrustPolicy
+		wantTrusted bool
+	}{
+		{"empty policy", authorSig, &TrustPolicy{}, true},
+		{"trusted author", authorSig, &TrustPolicy{Authors: []TrustedAuthor{{Name: "other", Certificates: []TrustedCertificate{other}}, {Name: "signer", Certificates: []TrustedCertificate{signer}}}}, true},
+		{"trusted author SHA-512", authorSig, &TrustPolicy{Authors: []TrustedAuthor{{Name: "signer", Certificates: []TrustedCertificate{{Fingerprint: strings.ToUpper(hex.EncodeToString(signerSHA512[:])), HashAlgorithm: HashAlgorithmSHA512}}}}}, true},
+		{"untrusted author", authorSig, &TrustPolicy{Authors: []TrustedAuthor{{Name: "other", Certificates: []TrustedCertificate{other}}}}, false},
+		{"author signer trusted as repository", authorSig, &TrustPolicy{Repositories: []TrustedRepository{{Name: "signer", Certificates: []TrustedCertificate{signer}}}}, false},
+		{"trusted repository", repositorySig, &TrustPolicy{Repositories: []TrustedRepository{{Name: "feed", Certificates: []TrustedCertificate{signer}}}}, true},
+		{"trusted repository owner", repositorySig, &TrustPolicy{Repositories: []TrustedRepository{{Name: "feed", Certificates: []TrustedCertificate{signer}, Owners: []string{"northwind", "fabrikam"}}}}, true},
+		{"untrusted repository owners", repositorySig, &TrustPolicy{Repositories: []TrustedRepository{{Name: "feed", Certificates: []TrustedCertificate{signer}, Owners: []string{"northwind"}}}}, false},
+		{"repository signer trusted as author", repositorySig, &TrustPolicy{Authors: []TrustedAuthor{{Name: "signer", Certificates: []TrustedCertificate{signer}}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultVerificationOptions()
+			opts.TrustStore = trustStore
+			opts.TrustPolicy = tt.policy
+
+			result := VerifySignature(tt.sig, opts)
+			if result.IsValid != tt.wantTrusted {
+				t.Fatalf("IsValid = %v, want %v (errors: %v)", result.IsValid, tt.wantTrusted, result.Errors)
+			}
+			if tt.wantTrusted {
+				return
+			}
+
+			// The chain is valid: the only error is the untrusted signer
+			if len(result.Errors) != 1 {
+				t.Fatalf("errors = %v, want only the untrusted signer", result.Errors)
+			}
+			var untrusted *UntrustedSignerError
+			if !errors.As(result.Errors[0], &untrusted) || !untrusted.Certificate.Equal(signerCert) {
+				t.Errorf("error = %v, want an *UntrustedSignerError for the signer", result.Errors[0])
+			}
+			if len(result.Issues) != 1 || result.Issues[0].Code != CodeUntrustedSigner {
+				t.Errorf("Issues = %+v, want a single %s", result.Issues, CodeUntrustedSigner)
+			}
+		})
+	}
+}
+
+func TestVerifySignature_TrustPolicyAllowUntrustedRoot(t *testing.T) {
+	rootCert, rootKey := generateTestRootCA(t)
+	signerCert, _ := generateTestCodeSigningCert(t, rootCert, rootKey)
+	sig := &PrimarySignature{
+		Type:              SignatureTypeAuthor,
+		SignerCertificate: signerCert,
+		Certificates:      []*x509.Certificate{signerCert, rootCert},
+		HashAlgorithm:     HashAlgorithmSHA256,
+	}
+	signerSum := sha256.Sum256(signerCert.Raw)
+
+	for _, allowUntrustedRoot := range []bool{false, true} {
+		opts := DefaultVerificationOptions()
+		opts.TrustPolicy = &TrustPolicy{Authors: []TrustedAuthor{{
+			Name:         "signer",
+			Certificates: []TrustedCertificate{{Fingerprint: hex.EncodeToString(signerSum[:]), AllowUntrustedRoot: allowUntrustedRoot}},
+		}}}
+
+		result := VerifySignature(sig, opts)
+		if result.IsValid != allowUntrustedRoot {
+			t.Errorf("allowUntrustedRoot=%v: IsValid = %v (errors: %v)", allowUntrustedRoot, result.IsValid, result.Errors)
+		}
+	}
+}
+
+func TestVerifySignature_TrustPolicyRepositoryCountersignature(t *testing.T) {
+	sigData, err := os.ReadFile("testdata/newtonsoft.signature.p7s")
+	if err != nil {
+		t.Fatalf("Failed to read signature file: %v", err)
+	}
+	sig, err := ReadSignature(sigData)
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v", err)
+	}
+	if got := sig.Countersignatures[0].PackageOwners; !slices.Equal(got, []string{"dotnetfoundation", "jamesnk", "newtonsoft"}) {
+		t.Errorf("countersignature PackageOwners = %q", got)
+	}
+
+	countersignerSum := sha256.Sum256(sig.Countersignatures[0].SignerCertificate.Raw)
+	nugetOrg := TrustedRepository{
+		Name:         "nuget.org",
+		ServiceIndex: "https://api.nuget.org/v3/index.json",
+		Certificates: []TrustedCertificate{{Fingerprint: hex.EncodeToString(countersignerSum[:])}},
+	}
+
+	tests := []struct {
+		name        string
+		owners      []string
+		wantTrusted bool
+	}{
+		{"any owner", nil, true},
+		{"trusted owner", []string{"microsoft", "jamesnk"}, true},
+		{"other owners", []string{"microsoft"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := nugetOrg
+			repository.Owners = tt.owners
+
+			opts := DefaultVerificationOptions()
+			opts.AllowUntrustedRoot = true
+			opts.VerifyTimestamp = false
+			signingTime := sig.Timestamps[0].Time
+			opts.VerificationTime = &signingTime
+			opts.TrustPolicy = &TrustPolicy{Repositories: []TrustedRepository{repository}}
+
+			result := VerifySignature(sig, opts)
+			var untrusted *UntrustedSignerError
+			trusted := !slices.ContainsFunc(result.Errors, func(err error) bool { return errors.As(err, &untrusted) })
+			if trusted != tt.wantTrusted {
+				t.Errorf("trusted = %v, want %v (errors: %v)", trusted, tt.wantTrusted, result.Errors)
+			}
+			if !tt.wantTrusted && !strings.Contains(untrusted.Error(), "trusted package owners") {
+				t.Errorf("error = %v, want an owners mismatch", untrusted)
+			}
+		})
+	}
+}
+
+func TestVerifyTimestamp_TokenSignature(t *testing.T) {
+	// The timestamp of a nuget.org package verifies
+	sigData, err := os.ReadFile("testdata/newtonsoft.signature.p7s")
+	if err != nil {
+		t.Fatalf("Failed to read signature file: %v", err)
+	}
+	sig, err := ReadSignature(sigData)
+	if err != nil {
+		t.Fatalf("ReadSignature() error = %v", err)
+	}
+	if _, tsaCert, err := verifyTimestampToken(sig.Timestamps[0].RawData); err != nil || !tsaCert.Equal(sig.Timestamps[0].SignerCertificate) {
+		t.Errorf("verifyTimestampToken() = %v, %v, want the TSA certificate", tsaCert, err)
+	}
+
+	// A token whose TSTInfo was altered after signing fails
+	rootCert, rootKey := generateTestRootCA(t)
+	tsaCert, tsaKey := generateTestTimestampCert(t, rootCert, rootKey)
+	messageHash := sha256.Sum256([]byte("signature value"))
+	info := newTestTSTInfo(messageHash[:], nil)
+	info.Nonce = nil
+	token := createTestTimestampToken(t, info, tsaCert, tsaKey)
+
+	opts := VerificationOptions{TrustStore: NewTrustStore()}
+	opts.TrustStore.AddCertificate(rootCert)
+
+	ts, err := parseTimestampToken(token)
+	if err != nil {
+		t.Fatalf("parseTimestampToken() error = %v", err)
+	}
+	if result := verifyTimestamp(ts, opts); !result.IsValid {
+		t.Fatalf("verifyTimestamp() errors = %v, want a valid timestamp", result.Errors)
+	}
+
+	tampered, err := parseTimestampToken(bytes.Replace(token, messageHash[:], make([]byte, len(messageHash)), 1))
+	if err != nil {
+		t.Fatalf("parseTimestampToken() error = %v", err)
+	}
+	result := verifyTimestamp(tampered, opts)
+	if result.IsValid || len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Error(), "timestamp signature verification failed") {
+		t.Errorf("verifyTimestamp() = %+v, want a signature verification failure", result)
+	}
+}
@@ -0,0 +1,102 @@
+package packaging
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// DotnetToolSettingsFileName is the name of the settings file shipped in tool packages.
+const DotnetToolSettingsFileName = "DotnetToolSettings.xml"
+
+// PackageTypeDotnetTool is the package type name declared by .NET tool packages.
+const PackageTypeDotnetTool = "DotnetTool"
+
+// DotnetToolSettings represents a parsed DotnetToolSettings.xml file.
+// Reference: NuGet/sdk ToolConfigurationDeserializer
+type DotnetToolSettings struct {
+	XMLName  xml.Name            `xml:"DotNetCliTool"`
+	Version  string              `xml:"Version,attr"`
+	Commands []DotnetToolCommand `xml:"Commands>Command"`
+}
+
+// DotnetToolCommand represents a single command exposed by a tool package.
+type DotnetToolCommand struct {
+	Name       string `xml:"Name,attr"`
+	EntryPoint string `xml:"EntryPoint,attr"`
+	Runner     string `xml:"Runner,attr"`
+}
+
+// DotnetToolSettingsFile describes where a settings file was found in a package.
+type DotnetToolSettingsFile struct {
+	Path              string // ZIP path, e.g. tools/net8.0/any/DotnetToolSettings.xml
+	TargetFramework   string // Short folder name, e.g. net8.0
+	RuntimeIdentifier string // RID folder name, e.g. any
+}
+
+// ParseDotnetToolSettings parses a DotnetToolSettings.xml document.
+func ParseDotnetToolSettings(r io.Reader) (*DotnetToolSettings, error) {
+	var settings DotnetToolSettings
+	if err := xml.NewDecoder(r).Decode(&settings); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", DotnetToolSettingsFileName, err)
+	}
+
+	if len(settings.Commands) == 0 {
+		return nil, fmt.Errorf("%s does not declare any commands", DotnetToolSettingsFileName)
+	}
+
+	for _, cmd := range settings.Commands {
+		if cmd.Name == "" {
+			return nil, fmt.Errorf("%s contains a command without a name", DotnetToolSettingsFileName)
+		}
+		if cmd.EntryPoint == "" {
+			return nil, fmt.Errorf("%s command %q has no entry point", DotnetToolSettingsFileName, cmd.Name)
+		}
+	}
+
+	return &settings, nil
+}
+
+// IsDotnetTool reports whether the nuspec declares the DotnetTool package type.
+func (n *Nuspec) IsDotnetTool() bool {
+	for _, pt := range n.Metadata.PackageTypes {
+		if strings.EqualFold(pt.Name, PackageTypeDotnetTool) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDotnetToolSettingsFiles returns all tools/<tfm>/<rid>/DotnetToolSettings.xml entries in the package.
+func (r *PackageReader) GetDotnetToolSettingsFiles() []DotnetToolSettingsFile {
+	var result []DotnetToolSettingsFile
+
+	for _, name := range r.GetFilesMatching(ToolsFolder + "*/*/" + DotnetToolSettingsFileName) {
+		parts := strings.Split(name, "/")
+		result = append(result, DotnetToolSettingsFile{
+			Path:              name,
+			TargetFramework:   parts[1],
+			RuntimeIdentifier: parts[2],
+		})
+	}
+
+	return result
+}
+
+// ReadDotnetToolSettings opens and parses the settings file at the given ZIP path.
+func (r *PackageReader) ReadDotnetToolSettings(zipPath string) (*DotnetToolSettings, error) {
+	file, err := r.GetFile(zipPath)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path.Base(file.Name), err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	return ParseDotnetToolSettings(rc)
+}
@@ -0,0 +1,120 @@
+package packaging
+
+import (
+	"strings"
+	"testing"
+)
+
+const testToolSettings = `<?xml version="1.0" encoding="utf-8"?>
+<DotNetCliTool Version="1">
+  <Commands>
+    <Command Name="dotnetsay" EntryPoint="dotnetsay.dll" Runner="dotnet" />
+  </Commands>
+</DotNetCliTool>`
+
+const testToolNuspec = `<?xml version="1.0"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata>
+    <id>dotnetsay</id>
+    <version>2.1.4</version>
+    <description>A tool</description>
+    <authors>Test</authors>
+    <packageTypes>
+      <packageType name="DotnetTool" />
+    </packageTypes>
+  </metadata>
+</package>`
+
+func TestParseDotnetToolSettings(t *testing.T) {
+	settings, err := ParseDotnetToolSettings(strings.NewReader(testToolSettings))
+	if err != nil {
+		t.Fatalf("ParseDotnetToolSettings() error = %v", err)
+	}
+
+	if settings.Version != "1" {
+		t.Errorf("Version = %q, want %q", settings.Version, "1")
+	}
+	if len(settings.Commands) != 1 {
+		t.Fatalf("len(Commands) = %d, want 1", len(settings.Commands))
+	}
+
+	cmd := settings.Commands[0]
+	if cmd.Name != "dotnetsay" || cmd.EntryPoint != "dotnetsay.dll" || cmd.Runner != "dotnet" {
+		t.Errorf("Command = %+v, want dotnetsay/dotnetsay.dll/dotnet", cmd)
+	}
+}
+
+func TestParseDotnetToolSettings_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		xml  string
+	}{
+		{"malformed", `<DotNetCliTool><Commands>`},
+		{"no commands", `<DotNetCliTool Version="1"><Commands /></DotNetCliTool>`},
+		{"missing name", `<DotNetCliTool Version="1"><Commands><Command EntryPoint="a.dll" /></Commands></DotNetCliTool>`},
+		{"missing entry point", `<DotNetCliTool Version="1"><Commands><Command Name="a" /></Commands></DotNetCliTool>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseDotnetToolSettings(strings.NewReader(tt.xml)); err == nil {
+				t.Error("ParseDotnetToolSettings() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestNuspec_IsDotnetTool(t *testing.T) {
+	nuspec, err := ParseNuspec(strings.NewReader(testToolNuspec))
+	if err != nil {
+		t.Fatalf("ParseNuspec() error = %v", err)
+	}
+	if !nuspec.IsDotnetTool() {
+		t.Error("IsDotnetTool() = false, want true")
+	}
+
+	nuspec.Metadata.PackageTypes = []PackageType{{Name: "Dependency"}}
+	if nuspec.IsDotnetTool() {
+		t.Error("IsDotnetTool() = true, want false for Dependency package type")
+	}
+}
+
+func TestPackageReader_GetDotnetToolSettingsFiles(t *testing.T) {
+	packageData := createTestPackage(t, map[string]string{
+		"dotnetsay.nuspec":                            testToolNuspec,
+		"tools/net8.0/any/DotnetToolSettings.xml":     testToolSettings,
+		"tools/net8.0/any/dotnetsay.dll":              "binary",
+		"tools/net6.0/win-x64/DotnetToolSettings.xml": testToolSettings,
+		"tools/DotnetToolSettings.xml":                testToolSettings,
+	}, false)
+
+	reader, err := OpenPackageFromReaderAt(packageData, int64(packageData.Len()))
+	if err != nil {
+		t.Fatalf("OpenPackageFromReaderAt() error = %v", err)
+	}
+
+	files := reader.GetDotnetToolSettingsFiles()
+	if len(files) != 2 {
+		t.Fatalf("len(GetDotnetToolSettingsFiles()) = %d, want 2", len(files))
+	}
+
+	found := make(map[string]DotnetToolSettingsFile)
+	for _, f := range files {
+		found[f.TargetFramework] = f
+	}
+
+	if f, ok := found["net8.0"]; !ok || f.RuntimeIdentifier != "any" {
+		t.Errorf("net8.0 settings = %+v, want RID any", f)
+	}
+	if f, ok := found["net6.0"]; !ok || f.RuntimeIdentifier != "win-x64" {
+		t.Errorf("net6.0 settings = %+v, want RID win-x64", f)
+	}
+
+	settings, err := reader.ReadDotnetToolSettings("tools/net8.0/any/DotnetToolSettings.xml")
+	if err != nil {
+		t.Fatalf("ReadDotnetToolSettings() error = %v", err)
+	}
+	if settings.Commands[0].Name != "dotnetsay" {
+		t.Errorf("Commands[0].Name = %q, want %q", settings.Commands[0].Name, "dotnetsay")
+	}
+}
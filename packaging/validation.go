@@ -138,14 +138,160 @@ func ValidateLicense(metadata PackageMetadata, files []PackageFile) error {
 		return fmt.Errorf("cannot specify both licenseUrl and license metadata")
 	}
 
-	// If license is a file, verify it exists
-	if metadata.LicenseMetadata != nil && metadata.LicenseMetadata.Type == "file" {
-		licenseFile := metadata.LicenseMetadata.Text
-		if !fileExists(files, licenseFile) {
-			return fmt.Errorf("license file %q specified but not found in package", licenseFile)
+	license := metadata.LicenseMetadata
+	if license == nil {
+		return nil
+	}
+
+	switch license.Type {
+	case "file":
+		// The license file must be part of the package (NU5030)
+		if !fileExists(files, license.Text) {
+			return fmt.Errorf("NU5030: The license file '%s' does not exist in the package", license.Text)
+		}
+	case "expression":
+		// Expressions of a newer license version than the toolset knows are not checked
+		if license.Version != "" {
+			licenseVersion, err := version.Parse(license.Version)
+			if err != nil {
+				return fmt.Errorf("invalid license version '%s'", license.Version)
+			}
+			if licenseVersion.Compare(supportedLicenseVersion) > 0 {
+				return nil
+			}
+		}
+		return ValidateLicenseExpression(license.Text)
+	}
+
+	return nil
+}
+
+// supportedLicenseVersion is the newest <license version> whose expressions are validated
+var supportedLicenseVersion = version.MustParse("1.0.0")
+
+// ValidateLicenseExpression validates an SPDX license expression such as
+// "MIT OR Apache-2.0" or "GPL-2.0-or-later WITH Classpath-exception-2.0". License and
+// exception identifiers must be on the SPDX list and not deprecated, a license may end
+// with "+", and the AND, OR and WITH operators are case-sensitive. UNLICENSED is only
+// valid on its own.
+// Reference: NuGetLicenseExpression.Parse in NuGet.Packaging
+func ValidateLicenseExpression(expression string) error {
+	tokens := licenseExpressionTokens(expression)
+	if len(tokens) == 0 {
+		return fmt.Errorf("license expression is empty")
+	}
+	if len(tokens) == 1 && tokens[0] == "UNLICENSED" {
+		return nil
+	}
+
+	p := &licenseExpressionParser{tokens: tokens}
+	err := p.parseExpression()
+	if err == nil && p.pos < len(tokens) {
+		err = fmt.Errorf("unexpected '%s'", tokens[p.pos])
+	}
+	if err != nil {
+		return fmt.Errorf("invalid license expression '%s': %w", expression, err)
+	}
+	return nil
+}
+
+// licenseExpressionTokens splits a license expression into identifiers, operators and
+// parentheses
+func licenseExpressionTokens(expression string) []string {
+	var tokens []string
+	start := -1
+	for i, r := range expression {
+		switch {
+		case unicode.IsSpace(r) || r == '(' || r == ')':
+			if start >= 0 {
+				tokens = append(tokens, expression[start:i])
+				start = -1
+			}
+			if r == '(' || r == ')' {
+				tokens = append(tokens, string(r))
+			}
+		case start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, expression[start:])
+	}
+	return tokens
+}
+
+// licenseExpressionParser checks the grammar of a tokenized license expression:
+//
+//	expression = term { ("AND" | "OR") term }
+//	term       = "(" expression ")" | license [ "WITH" exception ]
+type licenseExpressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseExpressionParser) parseExpression() error {
+	if err := p.parseTerm(); err != nil {
+		return err
+	}
+	for p.pos < len(p.tokens) && (p.tokens[p.pos] == "AND" || p.tokens[p.pos] == "OR") {
+		p.pos++
+		if err := p.parseTerm(); err != nil {
+			return err
 		}
 	}
+	return nil
+}
+
+func (p *licenseExpressionParser) parseTerm() error {
+	if p.pos >= len(p.tokens) {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
 
+	switch token {
+	case "(":
+		if err := p.parseExpression(); err != nil {
+			return err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return nil
+	case ")", "AND", "OR", "WITH":
+		return fmt.Errorf("unexpected '%s'", token)
+	case "UNLICENSED":
+		return fmt.Errorf("UNLICENSED cannot be combined with other licenses")
+	}
+
+	if err := checkLicenseIdentifier(strings.TrimSuffix(token, "+"), spdxLicenses, "license"); err != nil {
+		return err
+	}
+
+	if p.pos < len(p.tokens) && p.tokens[p.pos] == "WITH" {
+		p.pos++
+		if p.pos >= len(p.tokens) {
+			return fmt.Errorf("missing license exception after WITH")
+		}
+		exception := p.tokens[p.pos]
+		p.pos++
+		if err := checkLicenseIdentifier(exception, spdxExceptions, "license exception"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkLicenseIdentifier checks that id is a known, non-deprecated SPDX identifier
+func checkLicenseIdentifier(id string, known map[string]bool, kind string) error {
+	deprecated, ok := known[id]
+	if !ok {
+		return fmt.Errorf("the %s identifier '%s' is not recognized", kind, id)
+	}
+	if deprecated {
+		return fmt.Errorf("the %s identifier '%s' is deprecated", kind, id)
+	}
 	return nil
 }
 
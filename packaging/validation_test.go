@@ -402,6 +402,37 @@ func TestValidateLicense(t *testing.T) {
 			files:   []PackageFile{},
 			wantErr: true,
 		},
+		{
+			name: "valid - license expression",
+			metadata: PackageMetadata{
+				LicenseMetadata: &LicenseMetadata{
+					Type: "expression",
+					Text: "MIT OR Apache-2.0",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid - unknown license in expression",
+			metadata: PackageMetadata{
+				LicenseMetadata: &LicenseMetadata{
+					Type: "expression",
+					Text: "MIT OR Bogus",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid - expression of a newer license version",
+			metadata: PackageMetadata{
+				LicenseMetadata: &LicenseMetadata{
+					Type:    "expression",
+					Version: "2.0.0",
+					Text:    "MIT OR Bogus",
+				},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -414,6 +445,47 @@ func TestValidateLicense(t *testing.T) {
 	}
 }
 
+func TestValidateLicenseExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    string
+	}{
+		{"MIT", ""},
+		{"UNLICENSED", ""},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0", ""},
+		{"(MIT OR Apache-2.0) AND BSD-3-Clause", ""},
+		{"((MIT))", ""},
+		{"LGPL-2.1-only+", ""},
+		{"MIT OR Bogus", "the license identifier 'Bogus' is not recognized"},
+		{"mit", "the license identifier 'mit' is not recognized"},
+		{"MIT or Apache-2.0", "unexpected 'or'"},
+		{"GPL-2.0", "the license identifier 'GPL-2.0' is deprecated"},
+		{"GPL-2.0-only WITH Bogus-exception", "the license exception identifier 'Bogus-exception' is not recognized"},
+		{"MIT WITH", "missing license exception after WITH"},
+		{"MIT AND", "unexpected end of expression"},
+		{"(MIT OR Apache-2.0", "missing ')'"},
+		{"MIT)", "unexpected ')'"},
+		{"MIT Apache-2.0", "unexpected 'Apache-2.0'"},
+		{"MIT OR UNLICENSED", "UNLICENSED cannot be combined"},
+		{"  ", "license expression is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			err := ValidateLicenseExpression(tt.expression)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateLicenseExpression() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateLicenseExpression() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateIcon(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -643,9 +715,9 @@ func TestBuilderValidate(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			b := tt.setup()
-			err := b.Validate()
+			err := b.validate()
 			if (err != nil) != tt.wantErr {
-				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
@@ -0,0 +1,97 @@
+package packaging
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/packaging/signatures"
+)
+
+// writeTamperedPackage copies a signed package and changes one byte of its nuspec
+func writeTamperedPackage(t *testing.T, src, dst string) {
+	t.Helper()
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Skipf("Test package not found: %v", err)
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zipReader.File {
+		if strings.HasSuffix(f.Name, ".nuspec") {
+			offset, err := f.DataOffset()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data[offset] ^= 0xFF
+		}
+	}
+	if err := os.WriteFile(dst, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyPackageFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	signed := "testdata/TestPackage.AuthorSigned.1.0.0.nupkg"
+	unsigned := "testdata/TestUpdatePackage.1.0.1.nupkg"
+	tampered := filepath.Join(dir, "Tampered.1.0.0.nupkg")
+	writeTamperedPackage(t, signed, tampered)
+	corrupt := filepath.Join(dir, "Corrupt.1.0.0.nupkg")
+	if err := os.WriteFile(corrupt, []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The test certificates are not trusted on every machine
+	opts := signatures.DefaultVerificationOptions()
+	opts.AllowUntrustedRoot = true
+
+	paths := []string{signed, unsigned, tampered, corrupt}
+	results := VerifyPackageFiles(paths, opts, 2)
+	if len(results) != len(paths) {
+		t.Fatalf("got %d results, want %d", len(results), len(paths))
+	}
+	for i, result := range results {
+		if result.Path != paths[i] {
+			t.Errorf("results[%d].Path = %s, want %s", i, result.Path, paths[i])
+		}
+	}
+
+	if r := results[0]; r.Status != PackageVerificationValid || r.SignatureType != signatures.SignatureTypeAuthor {
+		t.Errorf("signed package: Status = %s, SignatureType = %s, errors = %v", r.Status, r.SignatureType, r.Errors)
+	}
+	if r := results[0]; r.Identity == nil || r.Identity.ID != "TestPackage.AuthorSigned" {
+		t.Errorf("signed package: Identity = %v", r.Identity)
+	}
+
+	if r := results[1]; r.Status != PackageVerificationUnsigned || len(r.Errors) != 0 {
+		t.Errorf("unsigned package: Status = %s, errors = %v", r.Status, r.Errors)
+	}
+
+	r := results[2]
+	if r.Status != PackageVerificationInvalid || len(r.Errors) == 0 || !strings.Contains(r.Errors[0].Error(), "content hash") {
+		t.Errorf("tampered package: Status = %s, errors = %v, want a content hash error", r.Status, r.Errors)
+	}
+
+	if r := results[3]; r.Status != PackageVerificationInvalid || len(r.Errors) == 0 {
+		t.Errorf("corrupt package: Status = %s, errors = %v", r.Status, r.Errors)
+	}
+}
+
+func TestVerifyPackageFile_UntrustedRoot(t *testing.T) {
+	// Nothing is trusted by an empty trust store
+	result := VerifyPackageFile("testdata/TestPackage.AuthorSigned.1.0.0.nupkg", signatures.DefaultVerificationOptions())
+	if result.Status != PackageVerificationInvalid {
+		t.Errorf("Status = %s, want %s", result.Status, PackageVerificationInvalid)
+	}
+	if len(result.Errors) == 0 {
+		t.Error("no errors for an untrusted signature")
+	}
+}
@@ -44,6 +44,8 @@ func (c *DownloadClient) DownloadPackage(ctx context.Context, feedURL, packageID
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
+	nugethttp.RequestIdentityEncoding(req)
+
 	resp, err := c.httpClient.DoWithRetry(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("download request: %w", err)
@@ -65,6 +67,16 @@ func (c *DownloadClient) DownloadPackage(ctx context.Context, feedURL, packageID
 		return nil, fmt.Errorf("download returned %d: %s", resp.StatusCode, body)
 	}
 
+	if err := nugethttp.CheckPackageContentLength(resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	if err := nugethttp.DecodeContentEncoding(resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("download %s %s: %w", packageID, version, err)
+	}
+
 	return resp.Body, nil
 }
 
@@ -84,6 +96,8 @@ func (c *DownloadClient) DownloadLatestPackage(ctx context.Context, feedURL, pac
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
+	nugethttp.RequestIdentityEncoding(req)
+
 	resp, err := c.httpClient.DoWithRetry(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("download request: %w", err)
@@ -100,9 +114,24 @@ func (c *DownloadClient) DownloadLatestPackage(ctx context.Context, feedURL, pac
 		return nil, fmt.Errorf("download returned %d: %s", resp.StatusCode, body)
 	}
 
+	if err := nugethttp.CheckPackageContentLength(resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	if err := nugethttp.DecodeContentEncoding(resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("download %s: %w", packageID, err)
+	}
+
 	return resp.Body, nil
 }
 
+// PackageDownloadURL returns the URL the .nupkg of a package version is downloaded from.
+func (c *DownloadClient) PackageDownloadURL(feedURL, packageID, version string) (string, error) {
+	return c.buildDownloadURL(feedURL, packageID, version)
+}
+
 func (c *DownloadClient) buildDownloadURL(feedURL, packageID, version string) (string, error) {
 	baseURL := feedURL
 	if !strings.HasSuffix(baseURL, "/") {
@@ -99,8 +99,12 @@ func (c *FeedClient) GetServiceDocument(ctx context.Context, feedURL string) (*S
 		return nil, fmt.Errorf("service returned %d: %s", resp.StatusCode, body)
 	}
 
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseServiceIndex, nugethttp.ContentXML)
+	if err != nil {
+		return nil, err
+	}
 	var service Service
-	if err := xml.NewDecoder(resp.Body).Decode(&service); err != nil {
+	if err := xml.Unmarshal(data, &service); err != nil {
 		return nil, fmt.Errorf("decode service: %w", err)
 	}
 
@@ -75,8 +75,12 @@ func (c *MetadataClient) GetPackageMetadata(ctx context.Context, feedURL, packag
 	}
 
 	// Parse Atom entry response
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseRegistration, nugethttp.ContentXML)
+	if err != nil {
+		return nil, err
+	}
 	var entry Entry
-	if err := xml.NewDecoder(resp.Body).Decode(&entry); err != nil {
+	if err := xml.Unmarshal(data, &entry); err != nil {
 		return nil, fmt.Errorf("decode entry: %w", err)
 	}
 
@@ -139,8 +143,12 @@ func (c *MetadataClient) FindPackagesByID(ctx context.Context, feedURL, packageI
 	}
 
 	// Parse Atom feed response
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseRegistration, nugethttp.ContentXML)
+	if err != nil {
+		return nil, err
+	}
 	var feed Feed
-	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+	if err := xml.Unmarshal(data, &feed); err != nil {
 		return nil, fmt.Errorf("decode feed: %w", err)
 	}
 
@@ -79,8 +79,12 @@ func (c *SearchClient) Search(ctx context.Context, feedURL string, opts SearchOp
 	}
 
 	// Parse Atom feed response
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseSearch, nugethttp.ContentXML)
+	if err != nil {
+		return nil, err
+	}
 	var feed Feed
-	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+	if err := xml.Unmarshal(data, &feed); err != nil {
 		return nil, fmt.Errorf("decode feed: %w", err)
 	}
 
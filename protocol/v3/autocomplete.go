@@ -74,8 +74,12 @@ func (c *AutocompleteClient) AutocompletePackageIDs(ctx context.Context, sourceU
 	}
 
 	// Parse response
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseSearch, nugethttp.ContentJSON)
+	if err != nil {
+		return nil, err
+	}
 	var result AutocompleteResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(data, &result); err != nil {
 		return nil, fmt.Errorf("decode autocomplete response: %w", err)
 	}
 
@@ -97,7 +101,11 @@ func (c *AutocompleteClient) AutocompletePackageVersions(ctx context.Context, so
 
 	// Build full URL
 	packageIDLower := strings.ToLower(packageID)
-	fullURL := strings.TrimSuffix(baseURL, "/") + "?id=" + url.QueryEscape(packageIDLower)
+	separator := "?"
+	if strings.Contains(baseURL, "?") {
+		separator = "&"
+	}
+	fullURL := strings.TrimSuffix(baseURL, "/") + separator + "id=" + url.QueryEscape(packageIDLower)
 	if prerelease {
 		fullURL += "&prerelease=true"
 	} else {
@@ -126,8 +134,12 @@ func (c *AutocompleteClient) AutocompletePackageVersions(ctx context.Context, so
 	}
 
 	// Parse response
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseSearch, nugethttp.ContentJSON)
+	if err != nil {
+		return nil, err
+	}
 	var result AutocompleteResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(data, &result); err != nil {
 		return nil, fmt.Errorf("decode version autocomplete response: %w", err)
 	}
 
@@ -6,15 +6,18 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 
 	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/version"
 )
 
 // DownloadClient provides package download functionality.
 type DownloadClient struct {
 	httpClient         *nugethttp.Client
 	serviceIndexClient *ServiceIndexClient
+	metadataClient     *MetadataClient // Registration lookups for sources without PackageBaseAddress
 }
 
 // NewDownloadClient creates a new download client.
@@ -22,36 +25,100 @@ func NewDownloadClient(httpClient *nugethttp.Client, serviceIndexClient *Service
 	return &DownloadClient{
 		httpClient:         httpClient,
 		serviceIndexClient: serviceIndexClient,
+		metadataClient:     NewMetadataClient(httpClient, serviceIndexClient),
 	}
 }
 
+// SetMetadataClient sets the registration client used when a source has no PackageBaseAddress,
+// so registration lookups can share its HTTP cache.
+func (c *DownloadClient) SetMetadataClient(metadataClient *MetadataClient) {
+	c.metadataClient = metadataClient
+}
+
+// FlatContainerPackageURL builds the PackageBaseAddress download URL of a .nupkg.
+// Format: {baseURL}/{id}/{version}/{id}.{version}.nupkg with the lowercase ID and the
+// lowercase normalized version without build metadata, each escaped as a path segment.
+func FlatContainerPackageURL(baseURL, packageID, version string) string {
+	id := url.PathEscape(strings.ToLower(packageID))
+	ver := url.PathEscape(flatContainerVersion(version))
+	return nugethttp.JoinURLPath(baseURL, id, ver, id+"."+ver+".nupkg")
+}
+
+// flatContainerVersion returns the version as it appears in flat container paths:
+// normalized, lowercase and without build metadata.
+func flatContainerVersion(ver string) string {
+	if parsed, err := version.Parse(ver); err == nil {
+		ver = parsed.ToNormalizedString()
+	}
+	ver, _, _ = strings.Cut(ver, "+")
+	return strings.ToLower(ver)
+}
+
+// PackageDownloadURL returns the URL the .nupkg of a package version is downloaded from.
+// The PackageBaseAddress flat container is preferred; sources without one fall back to the
+// packageContent URL of the package's registration leaf.
+func (c *DownloadClient) PackageDownloadURL(ctx context.Context, sourceURL, packageID, version string) (string, error) {
+	index, err := c.serviceIndexClient.GetServiceIndex(ctx, sourceURL)
+	if err != nil {
+		return "", fmt.Errorf("get service index: %w", err)
+	}
+
+	for _, resource := range index.Resources {
+		if resource.HasType(ResourceTypePackageBaseAddress) {
+			return FlatContainerPackageURL(resource.ID, packageID, version), nil
+		}
+	}
+
+	contentURL, err := c.registrationPackageContentURL(ctx, sourceURL, packageID, version)
+	if err != nil {
+		return "", fmt.Errorf("get package content URL: %w", err)
+	}
+	return contentURL, nil
+}
+
+// registrationPackageContentURL finds the packageContent URL of a version in the registration index.
+func (c *DownloadClient) registrationPackageContentURL(ctx context.Context, sourceURL, packageID, ver string) (string, error) {
+	want, err := version.Parse(ver)
+	if err != nil {
+		return "", fmt.Errorf("parse version %q: %w", ver, err)
+	}
+
+	index, err := c.metadataClient.GetPackageMetadata(ctx, sourceURL, packageID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, page := range index.Items {
+		for _, leaf := range page.Items {
+			if leaf.CatalogEntry == nil || leaf.PackageContent == "" {
+				continue
+			}
+			leafVersion, err := version.Parse(leaf.CatalogEntry.Version)
+			if err == nil && leafVersion.Equals(want) {
+				return leaf.PackageContent, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("package %s %s not found", packageID, ver)
+}
+
 // DownloadPackage downloads a .nupkg file and returns the response body.
 // Caller is responsible for closing the response body.
 func (c *DownloadClient) DownloadPackage(ctx context.Context, sourceURL, packageID, version string) (io.ReadCloser, error) {
-	// Get package base address from service index
-	baseURL, err := c.serviceIndexClient.GetResourceURL(ctx, sourceURL, ResourceTypePackageBaseAddress)
+	downloadURL, err := c.PackageDownloadURL(ctx, sourceURL, packageID, version)
 	if err != nil {
-		return nil, fmt.Errorf("get package base URL: %w", err)
+		return nil, err
 	}
 
-	// Build download URL
-	// Format: {baseURL}/{packageID}/{version}/{packageID}.{version}.nupkg
-	packageIDLower := strings.ToLower(packageID)
-	versionLower := strings.ToLower(version)
-	downloadURL := fmt.Sprintf("%s/%s/%s/%s.%s.nupkg",
-		strings.TrimSuffix(baseURL, "/"),
-		packageIDLower,
-		versionLower,
-		packageIDLower,
-		versionLower,
-	)
-
 	// Execute download request
 	req, err := http.NewRequest("GET", downloadURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 
+	nugethttp.RequestIdentityEncoding(req)
+
 	resp, err := c.httpClient.DoWithRetry(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("download request: %w", err)
@@ -68,6 +135,16 @@ func (c *DownloadClient) DownloadPackage(ctx context.Context, sourceURL, package
 		return nil, fmt.Errorf("download returned %d: %s", resp.StatusCode, body)
 	}
 
+	if err := nugethttp.CheckPackageContentLength(resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	if err := nugethttp.DecodeContentEncoding(resp); err != nil {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("download %s %s: %w", packageID, version, err)
+	}
+
 	return resp.Body, nil
 }
 
@@ -82,14 +159,8 @@ func (c *DownloadClient) DownloadNuspec(ctx context.Context, sourceURL, packageI
 
 	// Build nuspec URL
 	// Format: {baseURL}/{packageID}/{version}/{packageID}.nuspec
-	packageIDLower := strings.ToLower(packageID)
-	versionLower := strings.ToLower(version)
-	nuspecURL := fmt.Sprintf("%s/%s/%s/%s.nuspec",
-		strings.TrimSuffix(baseURL, "/"),
-		packageIDLower,
-		versionLower,
-		packageIDLower,
-	)
+	packageIDLower := url.PathEscape(strings.ToLower(packageID))
+	nuspecURL := nugethttp.JoinURLPath(baseURL, packageIDLower, url.PathEscape(flatContainerVersion(version)), packageIDLower+".nuspec")
 
 	// Execute request
 	req, err := http.NewRequest("GET", nuspecURL, nil)
@@ -116,6 +187,34 @@ func (c *DownloadClient) DownloadNuspec(ctx context.Context, sourceURL, packageI
 	return resp.Body, nil
 }
 
+// PackageReadmeURL returns the URL the readme of a package version is served from without
+// the package: the ReadmeUriTemplate resource, otherwise the readme endpoint of the
+// PackageBaseAddress flat container ({baseURL}/{id}/{version}/readme).
+func (c *DownloadClient) PackageReadmeURL(ctx context.Context, sourceURL, packageID, version string) (string, error) {
+	if template, err := c.serviceIndexClient.GetResourceURL(ctx, sourceURL, ResourceTypeReadmeURITemplate); err == nil {
+		return strings.NewReplacer(
+			"{lower_id}", url.PathEscape(strings.ToLower(packageID)),
+			"{lower_version}", url.PathEscape(flatContainerVersion(version)),
+		).Replace(template), nil
+	}
+	return c.flatContainerFileURL(ctx, sourceURL, packageID, version, "readme")
+}
+
+// PackageIconURL returns the URL the embedded icon of a package version is served from
+// without the package: {baseURL}/{id}/{version}/icon in the PackageBaseAddress flat container.
+func (c *DownloadClient) PackageIconURL(ctx context.Context, sourceURL, packageID, version string) (string, error) {
+	return c.flatContainerFileURL(ctx, sourceURL, packageID, version, "icon")
+}
+
+// flatContainerFileURL builds the URL of a file the flat container serves for a package version.
+func (c *DownloadClient) flatContainerFileURL(ctx context.Context, sourceURL, packageID, version, file string) (string, error) {
+	baseURL, err := c.serviceIndexClient.GetResourceURL(ctx, sourceURL, ResourceTypePackageBaseAddress)
+	if err != nil {
+		return "", fmt.Errorf("get package base URL: %w", err)
+	}
+	return nugethttp.JoinURLPath(baseURL, url.PathEscape(strings.ToLower(packageID)), url.PathEscape(flatContainerVersion(version)), file), nil
+}
+
 // GetPackageVersions lists all available versions for a package.
 // Uses the package base address versions endpoint.
 func (c *DownloadClient) GetPackageVersions(ctx context.Context, sourceURL, packageID string) ([]string, error) {
@@ -127,11 +226,8 @@ func (c *DownloadClient) GetPackageVersions(ctx context.Context, sourceURL, pack
 
 	// Build versions URL
 	// Format: {baseURL}/{packageID}/index.json
-	packageIDLower := strings.ToLower(packageID)
-	versionsURL := fmt.Sprintf("%s/%s/index.json",
-		strings.TrimSuffix(baseURL, "/"),
-		packageIDLower,
-	)
+	packageIDLower := url.PathEscape(strings.ToLower(packageID))
+	versionsURL := nugethttp.JoinURLPath(baseURL, packageIDLower, "index.json")
 
 	// Execute request
 	req, err := http.NewRequest("GET", versionsURL, nil)
@@ -155,11 +251,15 @@ func (c *DownloadClient) GetPackageVersions(ctx context.Context, sourceURL, pack
 	}
 
 	// Parse versions response
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseVersionList, nugethttp.ContentJSON)
+	if err != nil {
+		return nil, err
+	}
 	var versionsResp struct {
 		Versions []string `json:"versions"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&versionsResp); err != nil {
+	if err := json.Unmarshal(data, &versionsResp); err != nil {
 		return nil, fmt.Errorf("decode versions: %w", err)
 	}
 
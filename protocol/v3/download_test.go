@@ -1,6 +1,9 @@
 package v3
 
 import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"io"
@@ -132,6 +135,82 @@ func TestDownloadClient_DownloadPackage_NotFound(t *testing.T) {
 	}
 }
 
+func TestDownloadClient_DownloadPackage_GzipContentEncoding(t *testing.T) {
+	var nupkg bytes.Buffer
+	zw := zip.NewWriter(&nupkg)
+	fw, err := zw.Create("lib/net8.0/My.Package.dll")
+	if err != nil {
+		t.Fatalf("zip Create() error = %v", err)
+	}
+	_, _ = fw.Write([]byte("dll"))
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip Close() error = %v", err)
+	}
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	_, _ = gw.Write(nupkg.Bytes())
+	_ = gw.Close()
+
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{name: "gzip encoded zip", body: gzipped.Bytes()},
+		{name: "gzip header on plain zip", body: nupkg.Bytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acceptEncoding string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) {
+				_ = json.NewEncoder(w).Encode(&ServiceIndex{
+					Version:   "3.0.0",
+					Resources: []Resource{{ID: "http://" + r.Host + "/flat/", Type: ResourceTypePackageBaseAddress}},
+				})
+			})
+			mux.HandleFunc("/flat/my.package/1.0.0/my.package.1.0.0.nupkg", func(w http.ResponseWriter, r *http.Request) {
+				// Encoded regardless of what the client accepts
+				acceptEncoding = r.Header.Get("Accept-Encoding")
+				w.Header().Set("Content-Type", "application/octet-stream")
+				w.Header().Set("Content-Encoding", "gzip")
+				_, _ = w.Write(tt.body)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			httpClient := nugethttp.NewClient(nil)
+			client := NewDownloadClient(httpClient, NewServiceIndexClient(httpClient))
+
+			body, err := client.DownloadPackage(context.Background(), server.URL+"/index.json", "My.Package", "1.0.0")
+			if err != nil {
+				t.Fatalf("DownloadPackage() error = %v", err)
+			}
+			defer func() { _ = body.Close() }()
+
+			content, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if acceptEncoding != "identity" {
+				t.Errorf("Accept-Encoding = %q, want identity", acceptEncoding)
+			}
+			if !bytes.Equal(content, nupkg.Bytes()) {
+				t.Fatalf("downloaded %d bytes, want the %d byte nupkg", len(content), nupkg.Len())
+			}
+
+			zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+			if err != nil {
+				t.Fatalf("downloaded package is not a zip: %v", err)
+			}
+			if len(zr.File) != 1 || zr.File[0].Name != "lib/net8.0/My.Package.dll" {
+				t.Errorf("package entries = %v", zr.File)
+			}
+		})
+	}
+}
+
 func TestDownloadClient_DownloadNuspec(t *testing.T) {
 	server, client := setupDownloadServer()
 	defer server.Close()
@@ -218,3 +297,182 @@ func TestDownloadClient_GetPackageVersions_NotFound(t *testing.T) {
 		t.Errorf("error = %q, want to contain 'not found'", err.Error())
 	}
 }
+
+func TestFlatContainerPackageURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		packageID string
+		version   string
+		want      string
+	}{
+		{
+			name:      "mixed case ID",
+			packageID: "Newtonsoft.Json",
+			version:   "13.0.3",
+			want:      "https://feed.test/flat/newtonsoft.json/13.0.3/newtonsoft.json.13.0.3.nupkg",
+		},
+		{
+			name:      "build metadata stripped",
+			packageID: "My.Package",
+			version:   "1.0.0-Beta.1+Build.42",
+			want:      "https://feed.test/flat/my.package/1.0.0-beta.1/my.package.1.0.0-beta.1.nupkg",
+		},
+		{
+			name:      "version normalized",
+			packageID: "My.Package",
+			version:   "1.01.0",
+			want:      "https://feed.test/flat/my.package/1.1.0/my.package.1.1.0.nupkg",
+		},
+		{
+			name:      "unparseable version still lowercased without metadata",
+			packageID: "My.Package",
+			version:   "NotAVersion+meta",
+			want:      "https://feed.test/flat/my.package/notaversion/my.package.notaversion.nupkg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FlatContainerPackageURL("https://feed.test/flat/", tt.packageID, tt.version); got != tt.want {
+				t.Errorf("FlatContainerPackageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadClient_DownloadPackage_BuildMetadata(t *testing.T) {
+	var requested []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&ServiceIndex{
+			Version:   "3.0.0",
+			Resources: []Resource{{ID: "http://" + r.Host + "/flat/", Type: ResourceTypePackageBaseAddress + "/3.0.0"}},
+		})
+	})
+	mux.HandleFunc("/flat/", func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if r.URL.Path != "/flat/my.package/2.0.0-rc.1/my.package.2.0.0-rc.1.nupkg" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("PK\x03\x04"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	httpClient := nugethttp.NewClient(nil)
+	client := NewDownloadClient(httpClient, NewServiceIndexClient(httpClient))
+
+	body, err := client.DownloadPackage(context.Background(), server.URL+"/index.json", "My.Package", "2.0.0-RC.1+sha.abc")
+	if err != nil {
+		t.Fatalf("DownloadPackage() error = %v (requested %v)", err, requested)
+	}
+	_ = body.Close()
+}
+
+func TestDownloadClient_PackageDownloadURL_RegistrationFallback(t *testing.T) {
+	var downloaded bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) {
+		// No PackageBaseAddress: download URLs come from the registration
+		_ = json.NewEncoder(w).Encode(&ServiceIndex{
+			Version:   "3.0.0",
+			Resources: []Resource{{ID: "http://" + r.Host + "/registration/", Type: ResourceTypeRegistrationsBaseURL + "/3.6.0"}},
+		})
+	})
+	mux.HandleFunc("/registration/my.package/index.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&RegistrationIndex{
+			Count: 1,
+			Items: []RegistrationPage{{
+				Count: 2,
+				Lower: "1.0.0",
+				Upper: "2.0.0",
+				Items: []RegistrationLeaf{
+					{
+						CatalogEntry:   &RegistrationCatalog{PackageID: "My.Package", Version: "1.0.0"},
+						PackageContent: "http://" + r.Host + "/content/My.Package-1.0.0.nupkg",
+					},
+					{
+						CatalogEntry:   &RegistrationCatalog{PackageID: "My.Package", Version: "2.0.0+build.7"},
+						PackageContent: "http://" + r.Host + "/content/My.Package-2.0.0.nupkg",
+					},
+				},
+			}},
+		})
+	})
+	mux.HandleFunc("/content/My.Package-2.0.0.nupkg", func(w http.ResponseWriter, r *http.Request) {
+		downloaded = true
+		_, _ = w.Write([]byte("PK\x03\x04"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	httpClient := nugethttp.NewClient(nil)
+	client := NewDownloadClient(httpClient, NewServiceIndexClient(httpClient))
+	ctx := context.Background()
+
+	got, err := client.PackageDownloadURL(ctx, server.URL+"/index.json", "my.package", "2.0.0")
+	if err != nil {
+		t.Fatalf("PackageDownloadURL() error = %v", err)
+	}
+	if want := server.URL + "/content/My.Package-2.0.0.nupkg"; got != want {
+		t.Errorf("PackageDownloadURL() = %q, want %q", got, want)
+	}
+
+	body, err := client.DownloadPackage(ctx, server.URL+"/index.json", "My.Package", "2.0.0")
+	if err != nil {
+		t.Fatalf("DownloadPackage() error = %v", err)
+	}
+	_ = body.Close()
+	if !downloaded {
+		t.Error("package was not downloaded from the registration packageContent URL")
+	}
+
+	if _, err := client.PackageDownloadURL(ctx, server.URL+"/index.json", "My.Package", "3.0.0"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("PackageDownloadURL() error = %v, want not found", err)
+	}
+}
+
+func TestDownloadClient_PackageReadmeURL(t *testing.T) {
+	var readmeTemplate bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) {
+		resources := []Resource{{ID: "http://" + r.Host + "/flat/", Type: ResourceTypePackageBaseAddress + "/3.0.0"}}
+		if readmeTemplate {
+			resources = append(resources, Resource{ID: "http://" + r.Host + "/readme/{lower_id}/{lower_version}/readme.md", Type: ResourceTypeReadmeURITemplate + "/6.13.0"})
+		}
+		_ = json.NewEncoder(w).Encode(&ServiceIndex{Version: "3.0.0", Resources: resources})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	httpClient := nugethttp.NewClient(nil)
+	client := NewDownloadClient(httpClient, NewServiceIndexClient(httpClient))
+	ctx := context.Background()
+
+	got, err := client.PackageReadmeURL(ctx, server.URL+"/index.json", "My.Package", "1.0.0-Beta+build")
+	if err != nil {
+		t.Fatalf("PackageReadmeURL() error = %v", err)
+	}
+	if want := server.URL + "/flat/my.package/1.0.0-beta/readme"; got != want {
+		t.Errorf("PackageReadmeURL() = %q, want %q", got, want)
+	}
+	got, err = client.PackageIconURL(ctx, server.URL+"/index.json", "My.Package", "1.0")
+	if err != nil {
+		t.Fatalf("PackageIconURL() error = %v", err)
+	}
+	if want := server.URL + "/flat/my.package/1.0.0/icon"; got != want {
+		t.Errorf("PackageIconURL() = %q, want %q", got, want)
+	}
+
+	// ReadmeUriTemplate wins over the flat container
+	readmeTemplate = true
+	client = NewDownloadClient(httpClient, NewServiceIndexClient(httpClient))
+	got, err = client.PackageReadmeURL(ctx, server.URL+"/index.json", "My.Package", "1.0.0")
+	if err != nil {
+		t.Fatalf("PackageReadmeURL() error = %v", err)
+	}
+	if want := server.URL + "/readme/my.package/1.0.0/readme.md"; got != want {
+		t.Errorf("PackageReadmeURL() = %q, want %q", got, want)
+	}
+}
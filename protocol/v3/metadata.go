@@ -48,7 +48,7 @@ func (c *MetadataClient) GetPackageMetadata(ctx context.Context, sourceURL, pack
 	// Build registration index URL
 	// Format: {baseURL}/{packageID}/index.json
 	packageIDLower := strings.ToLower(packageID)
-	registrationURL := strings.TrimSuffix(baseURL, "/") + "/" + packageIDLower + "/index.json"
+	registrationURL := nugethttp.JoinURLPath(baseURL, packageIDLower, "index.json")
 
 	// Cache key matches NuGet.Client: list_{packageid}
 	cacheKey := fmt.Sprintf("list_%s", packageIDLower)
@@ -66,10 +66,14 @@ func (c *MetadataClient) GetPackageMetadata(ctx context.Context, sourceURL, pack
 		cachedReader, hit, err := c.httpCache.Get(registrationURL, cacheKey, httpCacheTTL)
 		if err == nil && hit && cachedReader != nil {
 			// Cache hit - decode from cache
-			defer func() { _ = cachedReader.Close() }()
 			var cachedIndex RegistrationIndex
-			if err := json.NewDecoder(cachedReader).Decode(&cachedIndex); err == nil {
+			decodeErr := json.NewDecoder(cachedReader).Decode(&cachedIndex)
+			_ = cachedReader.Close()
+			if decodeErr == nil {
 				index = &cachedIndex
+			} else {
+				// Corrupt cache entry - discard it and refetch from the network
+				c.httpCache.Invalidate(registrationURL, cacheKey, decodeErr)
 			}
 		}
 	}
@@ -97,9 +101,9 @@ func (c *MetadataClient) GetPackageMetadata(ctx context.Context, sourceURL, pack
 		}
 
 		// Read response body into buffer for caching
-		bodyBytes, err := io.ReadAll(resp.Body)
+		bodyBytes, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseRegistration, nugethttp.ContentJSON)
 		if err != nil {
-			return nil, fmt.Errorf("read response: %w", err)
+			return nil, err
 		}
 
 		// Decode index
@@ -225,11 +229,14 @@ func (c *MetadataClient) fetchRegistrationPage(ctx context.Context, pageURL stri
 		cachedReader, hit, err := c.httpCache.Get(pageURL, cacheKey, httpCacheTTL)
 		if err == nil && hit && cachedReader != nil {
 			// Cache hit - decode from cache
-			defer func() { _ = cachedReader.Close() }()
 			var cachedPage RegistrationPage
-			if err := json.NewDecoder(cachedReader).Decode(&cachedPage); err == nil {
+			decodeErr := json.NewDecoder(cachedReader).Decode(&cachedPage)
+			_ = cachedReader.Close()
+			if decodeErr == nil {
 				return &cachedPage, nil
 			}
+			// Corrupt cache entry - discard it and refetch from the network
+			c.httpCache.Invalidate(pageURL, cacheKey, decodeErr)
 		}
 	}
 
@@ -251,9 +258,9 @@ func (c *MetadataClient) fetchRegistrationPage(ctx context.Context, pageURL stri
 	}
 
 	// Read response body into buffer for caching
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseRegistration, nugethttp.ContentJSON)
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return nil, err
 	}
 
 	// Decode page
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"sync/atomic"
 	"testing"
 
+	"github.com/willibrandon/gonuget/cache"
 	nugethttp "github.com/willibrandon/gonuget/http"
 )
 
@@ -561,3 +564,121 @@ func TestMetadataClient_fetchRegistrationPage_InvalidJSON(t *testing.T) {
 		t.Error("GetPackageMetadata() expected error when page returns invalid JSON")
 	}
 }
+
+func TestMetadataClient_GetPackageMetadata_RecoversFromCorruptCache(t *testing.T) {
+	validIndex := RegistrationIndex{
+		Count: 1,
+		Items: []RegistrationPage{
+			{
+				Lower: "1.0.0",
+				Upper: "1.0.0",
+				Count: 1,
+				Items: []RegistrationLeaf{
+					{CatalogEntry: &RegistrationCatalog{PackageID: "TestPkg", Version: "1.0.0"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func(cacheFile string) error
+	}{
+		{
+			name: "truncated dotnet entry without checksum",
+			corrupt: func(cacheFile string) error {
+				return os.WriteFile(cacheFile, []byte(`{"count":1,"items":[{"lower":`), 0644)
+			},
+		},
+		{
+			name: "gonuget entry truncated",
+			corrupt: func(cacheFile string) error {
+				raw, err := os.ReadFile(cacheFile)
+				if err != nil {
+					return err
+				}
+				return os.WriteFile(cacheFile, raw[:len(raw)/2], 0644)
+			},
+		},
+		{
+			name: "gonuget entry with flipped bit",
+			corrupt: func(cacheFile string) error {
+				raw, err := os.ReadFile(cacheFile)
+				if err != nil {
+					return err
+				}
+				raw[0] ^= 0xFF
+				return os.WriteFile(cacheFile, raw, 0644)
+			},
+		},
+		{
+			name: "binary garbage",
+			corrupt: func(cacheFile string) error {
+				return os.WriteFile(cacheFile, []byte{0x00, 0x01, 0x02, 0xFF}, 0644)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var registrationRequests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/index.json":
+					index := ServiceIndex{
+						Version: "3.0.0",
+						Resources: []Resource{
+							{ID: "http://" + r.Host + "/registration/", Type: ResourceTypeRegistrationsBaseURL},
+						},
+					}
+					_ = json.NewEncoder(w).Encode(index)
+				case "/registration/testpkg/index.json":
+					registrationRequests.Add(1)
+					_ = json.NewEncoder(w).Encode(validIndex)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			httpCache, err := cache.NewDiskCache(t.TempDir(), 1024*1024)
+			if err != nil {
+				t.Fatalf("NewDiskCache() error = %v", err)
+			}
+
+			httpClient := nugethttp.NewClient(nil)
+			client := NewMetadataClient(httpClient, NewServiceIndexClient(httpClient))
+			client.SetHTTPCache(httpCache)
+
+			ctx := context.Background()
+			if _, err := client.GetPackageMetadata(ctx, server.URL+"/index.json", "TestPkg"); err != nil {
+				t.Fatalf("initial GetPackageMetadata() error = %v", err)
+			}
+
+			cacheFile, _ := httpCache.GetCachePath(server.URL+"/registration/testpkg/index.json", "list_testpkg")
+			if err := tt.corrupt(cacheFile); err != nil {
+				t.Fatalf("corrupt cache entry: %v", err)
+			}
+			registrationRequests.Store(0)
+
+			metadata, err := client.GetPackageMetadata(ctx, server.URL+"/index.json", "TestPkg")
+			if err != nil {
+				t.Fatalf("GetPackageMetadata() with corrupt cache error = %v", err)
+			}
+			if metadata.Count != 1 {
+				t.Errorf("Count = %d, want 1", metadata.Count)
+			}
+			if got := registrationRequests.Load(); got != 1 {
+				t.Errorf("registration refetches = %d, want 1", got)
+			}
+
+			// The repaired entry is served from cache without another request
+			if _, err := client.GetPackageMetadata(ctx, server.URL+"/index.json", "TestPkg"); err != nil {
+				t.Fatalf("GetPackageMetadata() after recovery error = %v", err)
+			}
+			if got := registrationRequests.Load(); got != 1 {
+				t.Errorf("registration requests after recovery = %d, want 1", got)
+			}
+		})
+	}
+}
@@ -84,8 +84,12 @@ func (c *SearchClient) Search(ctx context.Context, sourceURL string, opts Search
 		return nil, fmt.Errorf("search returned %d: %s", resp.StatusCode, body)
 	}
 
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseSearch, nugethttp.ContentJSON)
+	if err != nil {
+		return nil, err
+	}
 	var searchResp SearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
+	if err := json.Unmarshal(data, &searchResp); err != nil {
 		return nil, fmt.Errorf("decode search response: %w", err)
 	}
 
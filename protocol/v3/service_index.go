@@ -63,13 +63,15 @@ func (c *ServiceIndexClient) GetServiceIndex(ctx context.Context, sourceURL stri
 		return cached.index, nil
 	}
 
-	// Check disk cache (L2) if available
-	if c.diskCache != nil {
+	// Check disk cache (L2) if available, unless the caller asked to bypass it
+	cacheCtx := cache.FromContext(ctx)
+	if c.diskCache != nil && (cacheCtx == nil || !cacheCtx.NoCache) {
 		data, ok, err := c.diskCache.Get(ctx, sourceURL, "service_index", ServiceIndexCacheTTL)
 		if err == nil && ok {
 			// Deserialize from disk cache
 			var index ServiceIndex
-			if err := json.Unmarshal(data, &index); err == nil {
+			decodeErr := json.Unmarshal(data, &index)
+			if decodeErr == nil {
 				span.SetAttributes(
 					attribute.Bool("cache.hit", true),
 					attribute.String("cache.tier", "disk"))
@@ -84,6 +86,9 @@ func (c *ServiceIndexClient) GetServiceIndex(ctx context.Context, sourceURL stri
 
 				return &index, nil
 			}
+
+			// Corrupt cache entry - discard it and refetch from the server
+			c.diskCache.Invalidate(ctx, sourceURL, "service_index", decodeErr)
 		}
 	}
 
@@ -139,8 +144,12 @@ func (c *ServiceIndexClient) fetchServiceIndex(ctx context.Context, sourceURL st
 		return nil, fmt.Errorf("service index returned %d: %s", resp.StatusCode, body)
 	}
 
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseServiceIndex, nugethttp.ContentJSON)
+	if err != nil {
+		return nil, err
+	}
 	var index ServiceIndex
-	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+	if err := json.Unmarshal(data, &index); err != nil {
 		return nil, fmt.Errorf("decode service index: %w", err)
 	}
 
@@ -159,7 +168,7 @@ func (c *ServiceIndexClient) GetResourceURL(ctx context.Context, sourceURL, reso
 	}
 
 	for _, resource := range index.Resources {
-		if matchesResourceType(resource.Type, resourceType) {
+		if resource.HasType(resourceType) {
 			return resource.ID, nil
 		}
 	}
@@ -190,7 +199,7 @@ func (c *ServiceIndexClient) GetAllResourceURLs(ctx context.Context, sourceURL,
 
 	var urls []string
 	for _, resource := range index.Resources {
-		if matchesResourceType(resource.Type, resourceType) {
+		if resource.HasType(resourceType) {
 			urls = append(urls, resource.ID)
 		}
 	}
@@ -1,6 +1,7 @@
 package v3
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/willibrandon/gonuget/cache"
 	nugethttp "github.com/willibrandon/gonuget/http"
 )
 
@@ -97,6 +99,40 @@ func TestServiceIndexClient_Cache(t *testing.T) {
 	}
 }
 
+func TestServiceIndexClient_NoCacheSkipsDiskCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(testServiceIndex)
+	}))
+	defer server.Close()
+	sourceURL := server.URL + "/index.json"
+
+	diskCache, err := cache.NewDiskCache(t.TempDir(), 1024*1024)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	mtCache := cache.NewMultiTierCache(cache.NewMemoryCache(100, 1024*1024), diskCache)
+
+	// A stale entry for the same URL, e.g. left by an earlier server on the same port
+	stale, _ := json.Marshal(&ServiceIndex{Version: "3.0.0"})
+	if err := mtCache.Set(context.Background(), sourceURL, "service_index", bytes.NewReader(stale), ServiceIndexCacheTTL, nil); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	cacheCtx := cache.NewSourceCacheContext()
+	cacheCtx.NoCache = true
+	ctx := cache.WithCacheContext(context.Background(), cacheCtx)
+
+	client := NewServiceIndexClientWithCache(nugethttp.NewClient(nil), mtCache)
+	index, err := client.GetServiceIndex(ctx, sourceURL)
+	if err != nil {
+		t.Fatalf("GetServiceIndex() error = %v", err)
+	}
+	if len(index.Resources) != 4 {
+		t.Errorf("Resources count = %d, want 4 from the server", len(index.Resources))
+	}
+}
+
 func TestServiceIndexClient_CacheExpiration(t *testing.T) {
 	callCount := 0
 
@@ -260,3 +296,73 @@ func TestServiceIndexClient_ClearCache(t *testing.T) {
 		t.Errorf("cache size = %d, want 0 after clear", len(client.cache))
 	}
 }
+
+func TestServiceIndexClient_ArrayResourceType(t *testing.T) {
+	// Some feeds (e.g. Artifactory) advertise "@type" as an array of types
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+  "version": "3.0.0",
+  "resources": [
+    {"@id": "https://feed.test/registration/", "@type": ["RegistrationsBaseUrl/3.6.0", "RegistrationsBaseUrl"]},
+    {"@id": "https://feed.test/flat/", "@type": ["Artifactory/Internal", "PackageBaseAddress/3.0.0"]},
+    {"@id": "https://feed.test/query", "@type": "SearchQueryService"}
+  ]
+}`))
+	}))
+	defer server.Close()
+
+	client := NewServiceIndexClient(nugethttp.NewClient(nil))
+	ctx := context.Background()
+
+	tests := []struct {
+		resourceType string
+		want         string
+	}{
+		{ResourceTypeRegistrationsBaseURL, "https://feed.test/registration/"},
+		{ResourceTypePackageBaseAddress, "https://feed.test/flat/"}, // matched by a later element
+		{ResourceTypeSearchQueryService, "https://feed.test/query"},
+	}
+	for _, tt := range tests {
+		got, err := client.GetResourceURL(ctx, server.URL+"/index.json", tt.resourceType)
+		if err != nil {
+			t.Errorf("GetResourceURL(%q) error = %v", tt.resourceType, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("GetResourceURL(%q) = %q, want %q", tt.resourceType, got, tt.want)
+		}
+	}
+}
+
+func TestResource_JSONArrayTypeRoundTrip(t *testing.T) {
+	var resource Resource
+	if err := json.Unmarshal([]byte(`{"@id":"https://feed.test/flat/","@type":["Custom/1.0","PackageBaseAddress/3.0.0"]}`), &resource); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if resource.Type != "Custom/1.0" || len(resource.Types) != 2 {
+		t.Errorf("Type = %q, Types = %v", resource.Type, resource.Types)
+	}
+
+	// A cached service index keeps every type
+	data, err := json.Marshal(resource)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var decoded Resource
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !decoded.HasType(ResourceTypePackageBaseAddress) {
+		t.Errorf("round-tripped resource %s lost its PackageBaseAddress type", data)
+	}
+
+	// A single type is still written as a string
+	data, err = json.Marshal(Resource{ID: "https://feed.test/query", Type: ResourceTypeSearchQueryService})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"@id":"https://feed.test/query","@type":"SearchQueryService"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+}
@@ -5,6 +5,8 @@
 package v3
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -17,10 +19,81 @@ type ServiceIndex struct {
 }
 
 // Resource represents a service resource in the service index.
+//
+// "@type" is usually a single string, but some feeds (e.g. certain Artifactory versions)
+// advertise a JSON array of types. Type holds the first type and Types all of them;
+// use HasType to match a resource against a requested type.
 type Resource struct {
-	ID      string `json:"@id"`
-	Type    string `json:"@type"`
-	Comment string `json:"comment,omitempty"`
+	ID      string   `json:"@id"`
+	Type    string   `json:"@type"`
+	Types   []string `json:"-"` // All types when "@type" is an array
+	Comment string   `json:"comment,omitempty"`
+}
+
+// resourceJSON is the wire form of Resource, with "@type" left undecoded.
+type resourceJSON struct {
+	ID      string          `json:"@id"`
+	Type    json.RawMessage `json:"@type"`
+	Comment string          `json:"comment,omitempty"`
+}
+
+// UnmarshalJSON accepts "@type" as a string or an array of strings.
+func (r *Resource) UnmarshalJSON(data []byte) error {
+	var raw resourceJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*r = Resource{ID: raw.ID, Comment: raw.Comment}
+	if len(raw.Type) == 0 || string(raw.Type) == "null" {
+		return nil
+	}
+
+	if raw.Type[0] == '[' {
+		if err := json.Unmarshal(raw.Type, &r.Types); err != nil {
+			return fmt.Errorf("decode @type of resource %s: %w", raw.ID, err)
+		}
+		if len(r.Types) > 0 {
+			r.Type = r.Types[0]
+		}
+		return nil
+	}
+
+	if err := json.Unmarshal(raw.Type, &r.Type); err != nil {
+		return fmt.Errorf("decode @type of resource %s: %w", raw.ID, err)
+	}
+	return nil
+}
+
+// MarshalJSON writes "@type" as an array when the resource has several types,
+// so a cached service index keeps all of them.
+func (r Resource) MarshalJSON() ([]byte, error) {
+	var typeJSON []byte
+	var err error
+	if len(r.Types) > 1 {
+		typeJSON, err = json.Marshal(r.Types)
+	} else {
+		typeJSON, err = json.Marshal(r.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(resourceJSON{ID: r.ID, Type: typeJSON, Comment: r.Comment})
+}
+
+// HasType reports whether any of the resource's types matches the requested type,
+// ignoring version suffixes (e.g. "PackageBaseAddress/3.0.0" matches "PackageBaseAddress").
+func (r *Resource) HasType(requested string) bool {
+	if len(r.Types) == 0 {
+		return matchesResourceType(r.Type, requested)
+	}
+	for _, t := range r.Types {
+		if matchesResourceType(t, requested) {
+			return true
+		}
+	}
+	return false
 }
 
 // Well-known resource types
@@ -35,11 +108,17 @@ const (
 	// Package download
 	ResourceTypePackageBaseAddress = "PackageBaseAddress"
 
+	// Package readme, a URI template with {lower_id} and {lower_version}
+	ResourceTypeReadmeURITemplate = "ReadmeUriTemplate"
+
 	// Package publish
 	ResourceTypePackagePublish = "PackagePublish"
 
 	// Catalog
 	ResourceTypeCatalog = "Catalog/3.0.0"
+
+	// Known vulnerabilities of packages
+	ResourceTypeVulnerabilityInfo = "VulnerabilityInfo"
 )
 
 // ServiceIndexCacheTTL is the default service index cache TTL (40 minutes as per NuGet spec).
@@ -110,9 +189,11 @@ type RegistrationCatalog struct {
 	Description              string            `json:"description,omitempty"`
 	IconURL                  string            `json:"iconUrl,omitempty"`
 	LicenseURL               string            `json:"licenseUrl,omitempty"`
+	ReadmeURL                string            `json:"readmeUrl,omitempty"`
 	LicenseExpression        string            `json:"licenseExpression,omitempty"`
 	ProjectURL               string            `json:"projectUrl,omitempty"`
 	Published                string            `json:"published,omitempty"`
+	Listed                   *bool             `json:"listed,omitempty"` // nil when the feed doesn't say
 	RequireLicenseAcceptance bool              `json:"requireLicenseAcceptance"`
 	Summary                  string            `json:"summary,omitempty"`
 	Tags                     []string          `json:"tags,omitempty"`
@@ -0,0 +1,110 @@
+package v3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	nugethttp "github.com/willibrandon/gonuget/http"
+)
+
+// VulnerabilityPage is an entry of the VulnerabilityInfo index: a file of known
+// vulnerabilities. nuget.org publishes a "base" page and an "update" page with the
+// changes since the base was written.
+type VulnerabilityPage struct {
+	Name    string `json:"@name"`
+	ID      string `json:"@id"`
+	Updated string `json:"@updated"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// PackageVulnerability is a known vulnerability of the package versions in a range.
+type PackageVulnerability struct {
+	URL      string `json:"url"`      // Advisory URL
+	Severity int    `json:"severity"` // 0 low, 1 moderate, 2 high, 3 critical
+	Versions string `json:"versions"` // Version range of the affected versions
+}
+
+// VulnerabilityClient reads the known vulnerabilities a source publishes.
+type VulnerabilityClient struct {
+	httpClient         *nugethttp.Client
+	serviceIndexClient *ServiceIndexClient
+}
+
+// NewVulnerabilityClient creates a new vulnerability client.
+func NewVulnerabilityClient(httpClient *nugethttp.Client, serviceIndexClient *ServiceIndexClient) *VulnerabilityClient {
+	return &VulnerabilityClient{
+		httpClient:         httpClient,
+		serviceIndexClient: serviceIndexClient,
+	}
+}
+
+// GetVulnerabilities returns the vulnerabilities of every page of the source's
+// VulnerabilityInfo resource, keyed by lowercase package ID. A source without the
+// resource returns nil.
+func (c *VulnerabilityClient) GetVulnerabilities(ctx context.Context, sourceURL string) (map[string][]PackageVulnerability, error) {
+	index, err := c.serviceIndexClient.GetServiceIndex(ctx, sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("get service index: %w", err)
+	}
+	indexURL := ""
+	for _, resource := range index.Resources {
+		if resource.HasType(ResourceTypeVulnerabilityInfo) {
+			indexURL = resource.ID
+			break
+		}
+	}
+	if indexURL == "" {
+		return nil, nil
+	}
+
+	var pages []VulnerabilityPage
+	if err := c.getJSON(ctx, indexURL, &pages); err != nil {
+		return nil, fmt.Errorf("get vulnerability index: %w", err)
+	}
+
+	vulnerabilities := make(map[string][]PackageVulnerability)
+	for _, page := range pages {
+		var entries map[string][]PackageVulnerability
+		if err := c.getJSON(ctx, page.ID, &entries); err != nil {
+			return nil, fmt.Errorf("get vulnerability page %s: %w", page.Name, err)
+		}
+		for id, packageVulnerabilities := range entries {
+			id = strings.ToLower(id)
+			vulnerabilities[id] = append(vulnerabilities[id], packageVulnerabilities...)
+		}
+	}
+	return vulnerabilities, nil
+}
+
+// getJSON fetches a vulnerability file and decodes it into v.
+func (c *VulnerabilityClient) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.httpClient.DoWithRetry(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("returned %d: %s", resp.StatusCode, body)
+	}
+
+	// Pages list every vulnerable package, so they are sized like registration pages
+	data, err := c.httpClient.ReadBody(ctx, resp, nugethttp.ResponseRegistration, nugethttp.ContentJSON)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode %s: %w", url, err)
+	}
+	return nil
+}
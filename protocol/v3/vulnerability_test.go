@@ -0,0 +1,87 @@
+package v3
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	nugethttp "github.com/willibrandon/gonuget/http"
+)
+
+func setupVulnerabilityServer(withResource bool) (*httptest.Server, *VulnerabilityClient) {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/index.json", func(w http.ResponseWriter, r *http.Request) {
+		index := &ServiceIndex{Version: "3.0.0"}
+		if withResource {
+			index.Resources = append(index.Resources, Resource{
+				ID:   "http://" + r.Host + "/vulnerabilities/index.json",
+				Type: ResourceTypeVulnerabilityInfo + "/6.7.0",
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(index)
+	})
+
+	mux.HandleFunc("/vulnerabilities/index.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode([]VulnerabilityPage{
+			{Name: "base", ID: "http://" + r.Host + "/vulnerabilities/base.json", Updated: "2025-01-01T00:00:00Z"},
+			{Name: "update", ID: "http://" + r.Host + "/vulnerabilities/update.json", Updated: "2025-01-02T00:00:00Z"},
+		})
+	})
+
+	mux.HandleFunc("/vulnerabilities/base.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"Newtonsoft.Json": [{"url": "https://github.com/advisories/GHSA-5crp-9r3c-p9vr", "severity": 2, "versions": "(, 13.0.1)"}]}`))
+	})
+
+	mux.HandleFunc("/vulnerabilities/update.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"newtonsoft.json": [{"url": "https://github.com/advisories/GHSA-0000-0000-0000", "severity": 0, "versions": "[13.0.1]"}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+
+	httpClient := nugethttp.NewClient(nil)
+	vulnerabilityClient := NewVulnerabilityClient(httpClient, NewServiceIndexClient(httpClient))
+
+	return server, vulnerabilityClient
+}
+
+func TestVulnerabilityClient_GetVulnerabilities(t *testing.T) {
+	server, client := setupVulnerabilityServer(true)
+	defer server.Close()
+
+	vulnerabilities, err := client.GetVulnerabilities(context.Background(), server.URL+"/index.json")
+	if err != nil {
+		t.Fatalf("GetVulnerabilities() error = %v", err)
+	}
+
+	// Pages are merged under the lowercase package ID
+	got := vulnerabilities["newtonsoft.json"]
+	if len(got) != 2 {
+		t.Fatalf("len(vulnerabilities) = %d, want 2: %v", len(got), vulnerabilities)
+	}
+	if got[0].Severity != 2 || got[0].Versions != "(, 13.0.1)" || got[0].URL != "https://github.com/advisories/GHSA-5crp-9r3c-p9vr" {
+		t.Errorf("base vulnerability = %+v", got[0])
+	}
+	if got[1].Versions != "[13.0.1]" {
+		t.Errorf("update vulnerability = %+v", got[1])
+	}
+}
+
+func TestVulnerabilityClient_GetVulnerabilities_NoResource(t *testing.T) {
+	server, client := setupVulnerabilityServer(false)
+	defer server.Close()
+
+	vulnerabilities, err := client.GetVulnerabilities(context.Background(), server.URL+"/index.json")
+	if err != nil {
+		t.Fatalf("GetVulnerabilities() error = %v", err)
+	}
+	if vulnerabilities != nil {
+		t.Errorf("vulnerabilities = %v, want nil without a VulnerabilityInfo resource", vulnerabilities)
+	}
+}
@@ -0,0 +1,58 @@
+package restore
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/gonuget/core/resolver"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// approximateMatch is a direct reference whose lower bound is on no source, so restore
+// resolves a higher version instead (NU1603).
+type approximateMatch struct {
+	PackageID    string
+	VersionRange string
+	LowerBound   *version.NuGetVersion
+}
+
+// missingLowerBound returns the inclusive lower bound of versionRange when none of the
+// available versions is that version, and nil otherwise.
+func missingLowerBound(versionRange string, available []string) *version.NuGetVersion {
+	vr, err := version.ParseVersionRange(versionRange)
+	if err != nil || vr.MinVersion == nil || !vr.MinInclusive {
+		return nil
+	}
+	for _, v := range available {
+		if parsed, err := version.Parse(v); err == nil && parsed.Equals(vr.MinVersion) {
+			return nil
+		}
+	}
+	return vr.MinVersion
+}
+
+// warnApproximateMatches collects NU1603 for the direct references of a target graph
+// that resolved above their missing lower bound.
+// Matches the approximate match warning of NuGet.Client's RestoreCommand.
+func (r *Restorer) warnApproximateMatches(projectPath, targetGraph string, matches []approximateMatch, resolved map[string]*resolver.PackageDependencyInfo) {
+	projectName := strings.TrimSuffix(filepath.Base(projectPath), filepath.Ext(projectPath))
+
+	for _, match := range matches {
+		for _, pkg := range resolved {
+			if !strings.EqualFold(pkg.ID, match.PackageID) {
+				continue
+			}
+			r.addGraphWarning(LogMessage{
+				Code: ErrorCodeApproximateBestMatch,
+				Message: fmt.Sprintf("%s depends on %s (%s) but %s %s was not found. An approximate best match of %s %s was resolved.",
+					projectName, match.PackageID, formatVersionConstraintForDisplay(match.VersionRange),
+					match.PackageID, match.LowerBound.ToNormalizedString(), pkg.ID, pkg.Version),
+				ProjectPath:  projectPath,
+				FilePath:     projectPath,
+				LibraryID:    match.PackageID,
+				WarningLevel: 1,
+			}, targetGraph)
+		}
+	}
+}
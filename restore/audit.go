@@ -0,0 +1,167 @@
+package restore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/core"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/version"
+)
+
+const (
+	// AuditFileVersion is the format version of the audit file
+	AuditFileVersion = 1
+
+	// auditFileSuffix follows the project file name, like .nuget.dgspec.json
+	auditFileSuffix = ".nuget.audit.json"
+)
+
+// AuditFile records the known vulnerabilities of the packages a restore resolved, so they
+// can be shown later without querying the sources. gonuget-specific: NuGet only logs
+// them as NU1901-NU1904 warnings.
+type AuditFile struct {
+	Version   int              `json:"version"`
+	AuditedAt time.Time        `json:"auditedAt"` // When the vulnerability data was read from the sources
+	Sources   []string         `json:"sources"`   // Sources that publish vulnerability data
+	Packages  []AuditedPackage `json:"packages"`  // Resolved packages with known vulnerabilities
+}
+
+// AuditedPackage is a resolved package version with known vulnerabilities.
+type AuditedPackage struct {
+	ID              string                 `json:"id"`
+	Version         string                 `json:"version"`
+	Vulnerabilities []AuditedVulnerability `json:"vulnerabilities"`
+}
+
+// AuditedVulnerability is a known vulnerability of an audited package.
+type AuditedVulnerability struct {
+	Severity    core.VulnerabilitySeverity `json:"severity"` // 0 low, 1 moderate, 2 high, 3 critical
+	AdvisoryURL string                     `json:"url"`
+}
+
+// GetAuditFilePath returns the path of the audit file of a project:
+// obj/{projectFileName}.nuget.audit.json.
+func GetAuditFilePath(projectPath string) string {
+	return filepath.Join(filepath.Dir(projectPath), "obj", filepath.Base(projectPath)+auditFileSuffix)
+}
+
+// LoadAuditFile reads an audit file. A missing file is returned as an fs.ErrNotExist error.
+func LoadAuditFile(path string) (*AuditFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var audit AuditFile
+	if err := json.Unmarshal(data, &audit); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	if audit.Version != AuditFileVersion {
+		return nil, fmt.Errorf("parse %s: unsupported version %d", filepath.Base(path), audit.Version)
+	}
+	return &audit, nil
+}
+
+// Find returns the recorded vulnerabilities of a package version.
+func (a *AuditFile) Find(packageID, packageVersion string) []core.PackageVulnerability {
+	for _, pkg := range a.Packages {
+		if !strings.EqualFold(pkg.ID, packageID) || pkg.Version != packageVersion {
+			continue
+		}
+		found := make([]core.PackageVulnerability, len(pkg.Vulnerabilities))
+		for i, vulnerability := range pkg.Vulnerabilities {
+			found[i] = core.PackageVulnerability{AdvisoryURL: vulnerability.AdvisoryURL, Severity: vulnerability.Severity}
+		}
+		return found
+	}
+	return nil
+}
+
+// Save writes the audit file, creating its directory if needed.
+func (a *AuditFile) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create audit file dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal audit file: %w", err)
+	}
+
+	// Write atomically so package list never reads half a file
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("write audit file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("rename audit file: %w", err)
+	}
+	return nil
+}
+
+// writeAuditFile checks the resolved packages against the vulnerabilities the sources
+// publish and records the result for package list --vulnerable --offline. Auditing is
+// best-effort: when no source publishes vulnerability data or one can't be read, the
+// previous audit file is kept. Projects that set NuGetAudit to false aren't audited.
+func (r *Restorer) writeAuditFile(ctx context.Context, proj *project.Project, result *Result) {
+	if !proj.IsNuGetAuditEnabled() {
+		return
+	}
+
+	audit := &AuditFile{Version: AuditFileVersion, AuditedAt: time.Now().UTC(), Packages: []AuditedPackage{}}
+	vulnerabilities := core.PackageVulnerabilities{}
+	for _, repo := range r.client.GetRepositoryManager().ListRepositories() {
+		found, ok, err := repo.GetVulnerabilities(ctx)
+		if err != nil {
+			if r.opts.Verbosity >= observability.VerbosityDetailed {
+				r.console.Printf("  Vulnerability data could not be read: %v\n", err)
+			}
+			return
+		}
+		if !ok {
+			continue
+		}
+		audit.Sources = append(audit.Sources, repo.SourceURL())
+		for id, known := range found {
+			vulnerabilities[id] = append(vulnerabilities[id], known...)
+		}
+	}
+	if len(audit.Sources) == 0 {
+		return
+	}
+
+	for _, pkg := range result.AllPackages() {
+		ver, err := version.Parse(pkg.Version)
+		if err != nil {
+			continue
+		}
+		known := vulnerabilities.Find(pkg.ID, ver)
+		if len(known) == 0 {
+			continue
+		}
+		audited := AuditedPackage{ID: pkg.ID, Version: pkg.Version}
+		for _, vulnerability := range known {
+			audited.Vulnerabilities = append(audited.Vulnerabilities, AuditedVulnerability{
+				Severity:    vulnerability.Severity,
+				AdvisoryURL: vulnerability.AdvisoryURL,
+			})
+		}
+		audit.Packages = append(audit.Packages, audited)
+	}
+	sort.Slice(audit.Packages, func(i, j int) bool {
+		return strings.ToLower(audit.Packages[i].ID) < strings.ToLower(audit.Packages[j].ID)
+	})
+
+	if err := audit.Save(GetAuditFilePath(proj.Path)); err != nil {
+		r.console.Warning("Failed to write audit file: %v\n", err)
+	}
+}
@@ -0,0 +1,98 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/willibrandon/gonuget/core"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+)
+
+// newAuditTestFeed serves Contoso.App 1.0.0, which depends on Contoso.Core 2.0.0 and
+// Contoso.Logging 1.0.0, with a critical vulnerability in Contoso.Core before 2.1.0.
+func newAuditTestFeed(t *testing.T) *nugethttptest.FakeV3Server {
+	t.Helper()
+	return nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{
+		Packages: []nugethttptest.Package{
+			{
+				ID:      "Contoso.App",
+				Version: "1.0.0",
+				Dependencies: []nugethttptest.Dependency{
+					{ID: "Contoso.Core", Range: "2.0.0"},
+					{ID: "Contoso.Logging", Range: "1.0.0"},
+				},
+			},
+			{ID: "Contoso.Core", Version: "2.0.0"},
+			{ID: "Contoso.Logging", Version: "1.0.0", Dependencies: []nugethttptest.Dependency{{ID: "Contoso.Core", Range: "2.0.0"}}},
+		},
+		Vulnerabilities: []nugethttptest.Vulnerability{
+			{ID: "Contoso.Core", Versions: "(, 2.1.0)", Severity: 3, AdvisoryURL: "https://example.com/advisories/contoso-core"},
+			{ID: "Contoso.Logging", Versions: "[0.1.0]", Severity: 1, AdvisoryURL: "https://example.com/advisories/contoso-logging"},
+		},
+	})
+}
+
+// restoreAuditTestProject writes a net8.0 project referencing Contoso.App with the extra
+// properties and restores it from feed.
+func restoreAuditTestProject(t *testing.T, feed *nugethttptest.FakeV3Server, properties string) string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>` + properties + `
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Contoso.App" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	opts := &Options{
+		Sources:        []string{feed.SourceURL()},
+		PackagesFolder: filepath.Join(tmpDir, "packages"),
+		NoCache:        true,
+	}
+	console := &mockConsole{}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+	return projPath
+}
+
+func TestRun_WritesAuditFile(t *testing.T) {
+	feed := newAuditTestFeed(t)
+	projPath := restoreAuditTestProject(t, feed, "")
+
+	audit, err := LoadAuditFile(GetAuditFilePath(projPath))
+	if err != nil {
+		t.Fatalf("LoadAuditFile() error = %v", err)
+	}
+	if len(audit.Sources) != 1 || audit.Sources[0] != feed.SourceURL() || audit.AuditedAt.IsZero() {
+		t.Errorf("audit file = %+v, want the feed and the audit time", audit)
+	}
+	if len(audit.Packages) != 1 {
+		t.Fatalf("audited packages = %+v, want only Contoso.Core", audit.Packages)
+	}
+
+	found := audit.Find("contoso.core", "2.0.0")
+	if len(found) != 1 || found[0].Severity != core.VulnerabilitySeverityCritical || found[0].AdvisoryURL != "https://example.com/advisories/contoso-core" {
+		t.Errorf("Find() = %+v, want the critical Contoso.Core advisory", found)
+	}
+	if found := audit.Find("Contoso.Core", "2.1.0"); found != nil {
+		t.Errorf("Find() = %+v for an unaudited version, want nil", found)
+	}
+
+	// NuGetAudit opts out of auditing
+	projPath = restoreAuditTestProject(t, feed, "\n    <NuGetAudit>false</NuGetAudit>")
+	if _, err := LoadAuditFile(GetAuditFilePath(projPath)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadAuditFile() error = %v, want no audit file with NuGetAudit false", err)
+	}
+}
@@ -63,6 +63,18 @@ func TestGetBestMatch(t *testing.T) {
 			available:       []string{"1.0.0", "3.0.0", "4.0.0"},
 			expectedNearest: "3.0.0", // First above upper bound
 		},
+		{
+			name:            "Unsorted versions",
+			versionRange:    "[1.0.0,)",
+			available:       []string{"3.0.0", "1.10.0", "1.2.0", "0.9.0"},
+			expectedNearest: "1.2.0", // Closest above lower bound, whatever the source order
+		},
+		{
+			name:            "Unsorted versions below lower bound",
+			versionRange:    "[2.0.0,)",
+			available:       []string{"1.10.0", "1.9.0", "1.0.0-beta"},
+			expectedNearest: "1.10.0", // Highest, not the last listed
+		},
 		{
 			name:            "Empty available versions",
 			versionRange:    "[1.0.0,)",
@@ -59,6 +59,13 @@ const (
 
 	// CacheFileName matches NoOpRestoreUtilities.NoOpCacheFileName
 	CacheFileName = "project.nuget.cache"
+
+	// AssetsFileName matches LockFileFormat.AssetsFileName
+	AssetsFileName = "project.assets.json"
+
+	// DgSpecSnapshotFileName holds the dgspec JSON whose hash is stored in the cache file.
+	// gonuget-specific: it lets InspectNoOpCache report which restore inputs changed.
+	DgSpecSnapshotFileName = "project.gonuget.dgspec.json"
 )
 
 // NewCacheFile creates a new cache file with the given hash.
@@ -135,6 +142,23 @@ func GetCacheFilePath(projectPath string) string {
 	return filepath.Join(objDir, CacheFileName)
 }
 
+// GetAssetsFilePath returns path to project.assets.json for a project.
+func GetAssetsFilePath(projectPath string) string {
+	return filepath.Join(filepath.Dir(projectPath), "obj", AssetsFileName)
+}
+
+// assetsFileExists reports whether the project's project.assets.json exists.
+// A no-op restore needs it, as in NoOpRestoreUtilities.VerifyAssetsAndMSBuildFilesAndPackagesArePresent.
+func assetsFileExists(projectPath string) bool {
+	_, err := os.Stat(GetAssetsFilePath(projectPath))
+	return err == nil
+}
+
+// GetDgSpecSnapshotPath returns path to the dgspec snapshot written with the cache file.
+func GetDgSpecSnapshotPath(projectPath string) string {
+	return filepath.Join(filepath.Dir(projectPath), "obj", DgSpecSnapshotFileName)
+}
+
 // VerifyPackageFilesExist checks if all expected package files exist on disk.
 // Matches NoOpRestoreUtilities.VerifyRestoreOutput in NuGet.Client.
 func (c *CacheFile) VerifyPackageFilesExist() bool {
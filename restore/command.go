@@ -12,14 +12,16 @@ import (
 	"time"
 
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/solution"
 )
 
 // Run executes the restore operation (entry point called from CLI).
 func Run(ctx context.Context, args []string, opts *Options, console Console) error {
 	start := time.Now()
 	// Show detailed summary messages for both detailed and diagnostic verbosity
-	isDetailed := opts.Verbosity == "detailed" || opts.Verbosity == "diagnostic"
-	isQuiet := opts.Verbosity == "quiet" || opts.Verbosity == "q"
+	isDetailed := opts.Verbosity >= observability.VerbosityDetailed
+	isQuiet := opts.Verbosity == observability.VerbosityQuiet
 	isMinimal := !isQuiet // minimal includes minimal, normal, detailed, diagnostic
 
 	// 1. Find project file
@@ -31,20 +33,42 @@ func Run(ctx context.Context, args []string, opts *Options, console Console) err
 	// Note: indent removed - Terminal Logger doesn't use internal MSBuild message indentation
 	_ = isDetailed // Suppress unused warning
 
+	// Solutions restore each of their projects
+	if solution.IsSolutionFile(projectPath) {
+		return runSolution(ctx, projectPath, opts, console)
+	}
+
 	// 2. Load project
 	proj, err := project.LoadProject(projectPath)
 	if err != nil {
 		return fmt.Errorf("failed to load project: %w", err)
 	}
 
-	// 3. Get package references
-	packageRefs := proj.GetPackageReferences()
+	// Apply RestorePackagesPath, RestoreNoCache and RestoreIgnoreFailedSources from the project
+	opts = opts.withProjectProperties(proj.GetRestoreProperties())
+
+	// Sources and credentials not given come from the project's NuGet.config hierarchy
+	opts, err = opts.withConfiguredSources(filepath.Dir(proj.Path))
+	if err != nil {
+		return err
+	}
+
+	// Legacy projects with packages.config are restored like nuget.exe restore
+	if proj.Style == project.StylePackagesConfig {
+		return restorePackagesConfig(ctx, proj, opts, console)
+	}
+
+	// 3. Get package references, including those of GlobalPackageReference items
+	packageRefs, _, err := packageReferences(proj)
+	if err != nil {
+		return fmt.Errorf("failed to load package references: %w", err)
+	}
 
 	// 4. Create restorer (no messages yet - dotnet prints summary first, then details)
 	restorer := NewRestorer(opts, console)
 
 	// Diagnostic: Show project analysis
-	isDiagnostic := opts.Verbosity == "diagnostic" || opts.Verbosity == "diag"
+	isDiagnostic := opts.Verbosity >= observability.VerbosityDiagnostic
 	if isDiagnostic {
 		// Get target frameworks
 		var targetFrameworks []string
@@ -81,9 +105,8 @@ func Run(ctx context.Context, args []string, opts *Options, console Console) err
 	termStatus := NewTerminalStatus(console.Output(), projectName, nil)
 	defer termStatus.Stop()
 
-	restoreStart := time.Now()
 	result, err := restorer.Restore(ctx, proj, packageRefs)
-	restoreElapsed := time.Since(restoreStart)
+	restoreElapsed := restorer.restoreElapsed()
 
 	// Stop terminal status before printing results
 	termStatus.Stop()
@@ -94,74 +117,32 @@ func Run(ctx context.Context, args []string, opts *Options, console Console) err
 			// Detect TTY for colorization (dotnet doesn't colorize when piped)
 			isTTY := termStatus.IsTTY()
 
-			for _, nugetErr := range result.Errors {
-				// NU1102 and NU1103 require multi-line format with per-source version info
-				if nugetErr.Code == ErrorCodePackageVersionNotFound || nugetErr.Code == ErrorCodePackageDownloadFailed {
-					// Format version-not-found errors (NU1102/NU1103)
-					// Dotnet always uses prefix format (each line with full path)
-					errorMsg := FormatVersionNotFoundError(
-						nugetErr.ProjectPath,
-						nugetErr.PackageID,
-						nugetErr.Constraint,
-						nugetErr.VersionInfos,
-						nugetErr.Code,
-						isTTY, // Colorize only for TTY output
-					)
-					console.Printf("%s\n", errorMsg)
-				} else {
-					// Use single-line format for other errors (NU1101)
-					errorMsg := nugetErr.FormatError(isTTY) // Colorize only for TTY output
-					// In quiet mode, remove indent from error messages (dotnet doesn't indent in quiet mode)
-					if isQuiet {
-						errorMsg = strings.TrimPrefix(errorMsg, "    ")
-					}
-					console.Printf("%s\n", errorMsg)
-				}
-			}
+			// Build the whole report first so the multi-line errors (NU1102/NU1103) and the
+			// summary are written as one block, never split by other output
+			var report strings.Builder
+			report.WriteString(formatRestoreErrors(result.Errors, isTTY, isQuiet))
 
 			// In non-quiet mode, print "Restore failed" summary (dotnet doesn't show this in quiet mode)
 			if !isQuiet {
-				elapsed := time.Since(start)
-				errorCount := len(result.Errors)
-
 				// Add blank line before summary (dotnet has spacing)
-				console.Printf("\n")
-
-				// Format: "Restore failed with N error(s) in X.Xs" with red on "failed with N error(s)"
-				// Colorize only for TTY output (dotnet doesn't colorize when piped)
-				if isTTY {
-					// ANSI color codes (use bright red like error codes)
-					const (
-						red   = "\033[1;31m"
-						reset = "\033[0m"
-					)
-					console.Printf("Restore %sfailed with %d error(s)%s in %.1fs\n",
-						red, errorCount, reset, elapsed.Seconds())
-				} else {
-					// Plain text for piped output
-					console.Printf("Restore failed with %d error(s) in %.1fs\n",
-						errorCount, elapsed.Seconds())
-				}
+				report.WriteString("\n")
+				report.WriteString(restoreFailedSummary(len(result.Errors), time.Since(start), isTTY))
 			}
 
+			console.Printf("%s", report.String())
+
 			// Return a clean error without wrapping (main.go will add "Error: " prefix)
 			return fmt.Errorf("")
 		}
 		return err
 	}
 
-	// 6. Generate lock file (project.assets.json) - only if not cache hit
+	// 6. project.assets.json was written by the restore (not for cache hits)
 	// Note: Terminal Logger hides all MSBuild internal messages (dg file, MSBuild files, assets, cache, etc.)
 	// We match Terminal Logger behavior: clean output, no internal spam
 	var assetsInfo *AssetsInfo
 	if !result.CacheHit {
-		lockFile := NewLockFileBuilder().Build(proj, result)
-		objDir := filepath.Join(filepath.Dir(proj.Path), "obj")
-		assetsPath := filepath.Join(objDir, "project.assets.json")
-
-		if err := lockFile.Save(assetsPath); err != nil {
-			return fmt.Errorf("failed to save project.assets.json: %w", err)
-		}
+		assetsPath := GetAssetsFilePath(proj.Path)
 
 		// Diagnostic: Collect assets information
 		if isDiagnostic {
@@ -190,6 +171,8 @@ func Run(ctx context.Context, args []string, opts *Options, console Console) err
 		}
 	}
 
+	restorer.tracePhase(PhaseRestoreCompleted, proj.Path, "")
+
 	// 7. Report summary (matches MSBuild Terminal Logger format)
 	elapsed := time.Since(start)
 
@@ -252,7 +235,10 @@ func Run(ctx context.Context, args []string, opts *Options, console Console) err
 		// Detailed mode: Show verbose restore details (matches dotnet Console Logger detailed verbosity)
 		if isDetailed && !isDiagnostic {
 			// Show "Committing restore..." at LogVerbose level (detailed only)
-			console.Printf("  Committing restore...\n")
+			// The legacy log format has already printed it for full restores
+			if !opts.LegacyLogFormat || result.CacheHit {
+				console.Printf("  Committing restore...\n")
+			}
 
 			// Show file write operations or cache status
 			objDir := filepath.Join(filepath.Dir(proj.Path), "obj")
@@ -260,9 +246,15 @@ func Run(ctx context.Context, args []string, opts *Options, console Console) err
 			cachePath := GetCacheFilePath(proj.Path)
 
 			if !result.CacheHit {
-				// Files were written - show write messages
+				// The restore ran - show what was written
 				dgSpecPath := filepath.Join(objDir, filepath.Base(proj.Path)+".nuget.dgspec.json")
-				console.Printf("  Writing assets file to disk. Path: %s\n", assetsPath)
+				if !opts.LegacyLogFormat {
+					if result.AssetsUnchanged {
+						console.Printf("  Assets file has not changed. Skipping assets file writing. Path: %s\n", assetsPath)
+					} else {
+						console.Printf("  Writing assets file to disk. Path: %s\n", assetsPath)
+					}
+				}
 				console.Printf("  Writing cache file to disk. Path: %s\n", cachePath)
 				console.Printf("  Persisting dg to %s\n", dgSpecPath)
 			} else {
@@ -333,8 +325,11 @@ func RunWithResult(ctx context.Context, args []string, opts *Options, console Co
 		return nil, fmt.Errorf("failed to load project: %w", err)
 	}
 
-	// 3. Get package references
-	packageRefs := proj.GetPackageReferences()
+	// 3. Get package references, including those of GlobalPackageReference items
+	packageRefs, _, err := packageReferences(proj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package references: %w", err)
+	}
 
 	if len(packageRefs) == 0 {
 		// Return empty result for projects with no packages
@@ -350,18 +345,12 @@ func RunWithResult(ctx context.Context, args []string, opts *Options, console Co
 	restorer := NewRestorer(opts, console)
 	result, err := restorer.Restore(ctx, proj, packageRefs)
 
-	// 5. Generate lock file (project.assets.json) - even on error for partial results
-	if result != nil && !result.CacheHit {
-		lockFile := NewLockFileBuilder().Build(proj, result)
-		objDir := filepath.Join(filepath.Dir(proj.Path), "obj")
-		assetsPath := filepath.Join(objDir, "project.assets.json")
-
-		if saveErr := lockFile.Save(assetsPath); saveErr != nil {
-			// If we already have an error, append the save error to the message
-			if err != nil {
-				return result, fmt.Errorf("%w; additionally failed to save project.assets.json: %v", err, saveErr)
-			}
-			return result, fmt.Errorf("failed to save project.assets.json: %w", saveErr)
+	// 5. A successful restore wrote project.assets.json; write one for failed
+	// restores too, for their partial results
+	if err != nil && result != nil && len(result.Errors) > 0 {
+		lockFile := NewLockFileBuilder().WithPackagesFolder(opts.PackagesFolder).Build(proj, result)
+		if saveErr := lockFile.Save(GetAssetsFilePath(proj.Path)); saveErr != nil {
+			return result, fmt.Errorf("%w; additionally failed to save project.assets.json: %v", err, saveErr)
 		}
 	}
 
@@ -369,18 +358,67 @@ func RunWithResult(ctx context.Context, args []string, opts *Options, console Co
 	return result, err
 }
 
+// formatRestoreErrors formats the errors of a failed restore, one per line, the way dotnet
+// prints them. Colors are used only for a terminal.
+func formatRestoreErrors(errs []*NuGetError, isTTY, isQuiet bool) string {
+	var report strings.Builder
+	for _, nugetErr := range errs {
+		// NU1102 and NU1103 require multi-line format with per-source version info
+		if nugetErr.Code == ErrorCodePackageVersionNotFound || nugetErr.Code == ErrorCodePackageDownloadFailed {
+			// Dotnet always uses prefix format (each line with full path)
+			errorMsg := FormatVersionNotFoundError(
+				nugetErr.ProjectPath,
+				nugetErr.PackageID,
+				nugetErr.Constraint,
+				nugetErr.VersionInfos,
+				nugetErr.Code,
+				isTTY, // Colorize only for TTY output
+			)
+			fmt.Fprintf(&report, "%s\n", errorMsg)
+		} else {
+			// Use single-line format for other errors (NU1101)
+			errorMsg := nugetErr.FormatError(isTTY) // Colorize only for TTY output
+			// In quiet mode, remove indent from error messages (dotnet doesn't indent in quiet mode)
+			if isQuiet {
+				errorMsg = strings.TrimPrefix(errorMsg, "    ")
+			}
+			fmt.Fprintf(&report, "%s\n", errorMsg)
+		}
+	}
+	return report.String()
+}
+
+// restoreFailedSummary returns the "Restore failed with N error(s) in X.Xs" line, with
+// "failed with N error(s)" in red for a terminal (dotnet doesn't colorize when piped).
+func restoreFailedSummary(errorCount int, elapsed time.Duration, isTTY bool) string {
+	if isTTY {
+		// ANSI color codes (use bright red like error codes)
+		const (
+			red   = "\033[1;31m"
+			reset = "\033[0m"
+		)
+		return fmt.Sprintf("Restore %sfailed with %d error(s)%s in %.1fs\n", red, errorCount, reset, elapsed.Seconds())
+	}
+	return fmt.Sprintf("Restore failed with %d error(s) in %.1fs\n", errorCount, elapsed.Seconds())
+}
+
 func findProjectFile(args []string) (string, error) {
 	var projectPath string
 	var err error
 
 	if len(args) > 0 {
 		projectPath = args[0]
+		if info, statErr := os.Stat(projectPath); statErr == nil && info.IsDir() {
+			if projectPath, err = findProjectOrSolution(projectPath); err != nil {
+				return "", err
+			}
+		}
 	} else {
 		cwd, err := os.Getwd()
 		if err != nil {
 			return "", err
 		}
-		projectPath, err = project.FindProjectFile(cwd)
+		projectPath, err = findProjectOrSolution(cwd)
 		if err != nil {
 			return "", err
 		}
@@ -395,3 +433,29 @@ func findProjectFile(args []string) (string, error) {
 
 	return absPath, nil
 }
+
+// findProjectOrSolution returns the project file in dir or, when there is none, its only
+// .sln or .slnx solution file, like dotnet restore does for a directory.
+func findProjectOrSolution(dir string) (string, error) {
+	projectPath, projectErr := project.FindProjectFile(dir)
+	if projectErr == nil {
+		return projectPath, nil
+	}
+
+	var solutions []string
+	for _, pattern := range []string{"*.sln", "*.slnx"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return "", err
+		}
+		solutions = append(solutions, matches...)
+	}
+	switch len(solutions) {
+	case 0:
+		return "", projectErr
+	case 1:
+		return solutions[0], nil
+	default:
+		return "", fmt.Errorf("multiple solution files found in directory: %s. Specify which solution to use", dir)
+	}
+}
@@ -0,0 +1,100 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/gonuget/core/resolver"
+)
+
+// DependencyPaths explains why a project uses a package: for each top-level package of
+// the target framework that brings packageID in, the shortest chain of packages from it
+// to packageID, as "ID/Version" keys of the framework's target. Chains are ordered by
+// top-level package. A top-level package has no chain, and nil is returned for a package
+// the framework doesn't resolve.
+//
+// project.assets.json doesn't record the dependencies of each package, so they are read
+// from the nuspec files of the packages folder the restore installed them to.
+func (lf *LockFile) DependencyPaths(ctx context.Context, framework, packageID string) ([][]string, error) {
+	// Resolved packages of the framework, by lowercase ID
+	resolved := make(map[string]string)
+	for key, lib := range lf.Targets[framework] {
+		if id, _, ok := strings.Cut(key, "/"); ok && lib.Type == "package" {
+			resolved[strings.ToLower(id)] = key
+		}
+	}
+	target, ok := resolved[strings.ToLower(packageID)]
+	if !ok {
+		return nil, nil
+	}
+
+	var roots []string
+	for id, dependency := range lf.Project.Frameworks[framework].Dependencies {
+		if dependency.Target != "" && dependency.Target != "Package" {
+			continue
+		}
+		if key, ok := resolved[strings.ToLower(id)]; ok && key != target {
+			roots = append(roots, key)
+		}
+	}
+	slices.SortFunc(roots, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+
+	provider := NewLocalDependencyProvider(lf.Project.Restore.PackagesPath)
+	selector := resolver.NewFrameworkSelector()
+	children := make(map[string][]string)
+	dependenciesOf := func(key string) ([]string, error) {
+		if deps, ok := children[key]; ok {
+			return deps, nil
+		}
+		id, ver, _ := strings.Cut(key, "/")
+		groups, _, err := provider.GetDependencies(ctx, id, ver)
+		if err != nil {
+			return nil, fmt.Errorf("read dependencies of %s %s: %w", id, ver, err)
+		}
+		var deps []string
+		for _, dep := range selector.SelectDependencies(groups, framework) {
+			if child, ok := resolved[strings.ToLower(dep.ID)]; ok {
+				deps = append(deps, child)
+			}
+		}
+		children[key] = deps
+		return deps, nil
+	}
+
+	var paths [][]string
+	for _, root := range roots {
+		// Breadth-first, so the first time the package is reached is through a shortest chain
+		parent := map[string]string{root: ""}
+		queue := []string{root}
+		for len(queue) > 0 {
+			if _, reached := parent[target]; reached {
+				break
+			}
+			key := queue[0]
+			queue = queue[1:]
+			deps, err := dependenciesOf(key)
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range deps {
+				if _, seen := parent[child]; !seen {
+					parent[child] = key
+					queue = append(queue, child)
+				}
+			}
+		}
+		if _, reached := parent[target]; !reached {
+			continue
+		}
+
+		path := []string{target}
+		for key := parent[target]; key != ""; key = parent[key] {
+			path = append(path, key)
+		}
+		slices.Reverse(path)
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
@@ -0,0 +1,32 @@
+package restore
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestLockFile_DependencyPaths(t *testing.T) {
+	projPath := restoreAuditTestProject(t, newAuditTestFeed(t), "")
+	assets, err := LoadLockFile(GetAssetsFilePath(projPath))
+	if err != nil {
+		t.Fatalf("LoadLockFile() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Contoso.Core is reached through Contoso.Logging too; the shortest chain is kept
+	paths, err := assets.DependencyPaths(ctx, "net8.0", "contoso.core")
+	if err != nil {
+		t.Fatalf("DependencyPaths() error = %v", err)
+	}
+	if want := [][]string{{"Contoso.App/1.0.0", "Contoso.Core/2.0.0"}}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("DependencyPaths() = %v, want %v", paths, want)
+	}
+
+	// A top-level package and a package the framework doesn't use have no chain
+	for _, id := range []string{"Contoso.App", "Contoso.Missing"} {
+		if paths, err := assets.DependencyPaths(ctx, "net8.0", id); err != nil || paths != nil {
+			t.Errorf("DependencyPaths(%s) = %v, %v, want nil", id, paths, err)
+		}
+	}
+}
@@ -31,8 +31,55 @@ func CalculateDgSpecHash(proj *project.Project) (string, error) {
 	return CalculateDgSpecHashWithConfig(proj, cfg)
 }
 
+// calculateDgSpecHash computes the dgspec hash with the restorer's effective settings,
+// so a --packages, --no-cache or --ignore-failed-sources override is part of the hash
+// just as the equivalent MSBuild global property is for dotnet.
+func (r *Restorer) calculateDgSpecHash(proj *project.Project) (string, error) {
+	jsonBytes, err := r.generateDgSpecJSON(proj)
+	if err != nil {
+		return "", err
+	}
+	return hashDgSpecJSON(jsonBytes), nil
+}
+
+// generateDgSpecJSON returns the dgspec JSON hashed by calculateDgSpecHash.
+func (r *Restorer) generateDgSpecJSON(proj *project.Project) ([]byte, error) {
+	cfg, err := DiscoverDgSpecConfig(proj)
+	if err != nil {
+		cfg = DefaultDgSpecConfig()
+	}
+	if r.opts.PackagesFolder != "" {
+		cfg.PackagesPath = r.opts.PackagesFolder
+	}
+	cfg.NoCache = cfg.NoCache || r.opts.NoCache
+	cfg.IgnoreFailedSources = cfg.IgnoreFailedSources || r.opts.IgnoreFailedSources
+	cfg.UseLockFile = cfg.UseLockFile || r.opts.UseLockFile
+	cfg.LockedMode = cfg.LockedMode || r.opts.LockedMode
+	cfg.AllowPrereleaseEverywhere = r.opts.AllowPrereleaseEverywhere
+	if r.opts.LockFilePath != "" {
+		cfg.LockFilePath = r.opts.LockFilePath
+	}
+	return generateDgSpecJSON(proj, cfg)
+}
+
 // CalculateDgSpecHashWithConfig computes hash with custom configuration.
 func CalculateDgSpecHashWithConfig(proj *project.Project, config *DgSpecConfig) (string, error) {
+	jsonBytes, err := generateDgSpecJSON(proj, config)
+	if err != nil {
+		return "", err
+	}
+	return hashDgSpecJSON(jsonBytes), nil
+}
+
+// hashDgSpecJSON computes the FNV-1a hash of a dgspec JSON document.
+func hashDgSpecJSON(jsonBytes []byte) string {
+	fnv := NewFnvHash64()
+	fnv.Update(jsonBytes)
+	return fnv.GetHash()
+}
+
+// generateDgSpecJSON builds the dgspec JSON for a project with the given configuration.
+func generateDgSpecJSON(proj *project.Project, config *DgSpecConfig) ([]byte, error) {
 	// Apply defaults
 	if config == nil {
 		config = DefaultDgSpecConfig()
@@ -55,7 +102,11 @@ func CalculateDgSpecHashWithConfig(proj *project.Project, config *DgSpecConfig)
 		WithSources(config.Sources).
 		WithConfigPaths(config.ConfigPaths).
 		WithRuntimeIDPath(config.RuntimeIDPath).
-		WithSdkAnalysisLevel(config.SdkAnalysisLevel)
+		WithSdkAnalysisLevel(config.SdkAnalysisLevel).
+		WithNoCache(config.NoCache).
+		WithIgnoreFailedSources(config.IgnoreFailedSources).
+		WithLockProperties(config.UseLockFile, config.LockFilePath, config.LockedMode).
+		WithAllowPrereleaseEverywhere(config.AllowPrereleaseEverywhere)
 
 	// Only set downloadDependencies if we found any
 	if len(downloadDepsMap) > 0 {
@@ -64,14 +115,9 @@ func CalculateDgSpecHashWithConfig(proj *project.Project, config *DgSpecConfig)
 
 	jsonBytes, err := hasher.GenerateJSON()
 	if err != nil {
-		return "", fmt.Errorf("generate dgspec JSON: %w", err)
+		return nil, fmt.Errorf("generate dgspec JSON: %w", err)
 	}
-
-	// Compute FNV-1a hash
-	fnv := NewFnvHash64()
-	fnv.Update(jsonBytes)
-
-	return fnv.GetHash(), nil
+	return jsonBytes, nil
 }
 
 // DgSpecConfig holds configuration for dgSpec hash calculation.
@@ -82,6 +128,18 @@ type DgSpecConfig struct {
 	ConfigPaths      []string
 	RuntimeIDPath    string
 	SdkAnalysisLevel string
+
+	// NoCache and IgnoreFailedSources are the RestoreNoCache and RestoreIgnoreFailedSources settings
+	NoCache             bool
+	IgnoreFailedSources bool
+
+	// UseLockFile, LockFilePath and LockedMode are the packages.lock.json settings
+	UseLockFile  bool
+	LockFilePath string
+	LockedMode   bool
+
+	// AllowPrereleaseEverywhere is Options.AllowPrereleaseEverywhere, which has no MSBuild property
+	AllowPrereleaseEverywhere bool
 }
 
 // DefaultDgSpecConfig returns default configuration.
@@ -287,16 +345,25 @@ func DiscoverDgSpecConfig(proj *project.Project) (*DgSpecConfig, error) {
 	// Load and merge all configs to get sources
 	// NuGet processes configs from least specific to most specific,
 	// and <clear /> clears all previously accumulated sources.
-	var allSources []string
-	sourceSet := make(map[string]bool)
-
-	// Process configs in reverse order (least specific first)
-	for i := len(configPaths) - 1; i >= 0; i-- {
-		configPath := configPaths[i]
+	var layers []config.ConfigLayer
+	for _, configPath := range configPaths {
 		cfg, err := config.LoadNuGetConfig(configPath)
 		if err != nil {
 			continue // Skip invalid configs
 		}
+		layers = append(layers, config.ConfigLayer{Path: configPath, Config: cfg})
+	}
+
+	// Disabled sources are merged across the whole hierarchy, so a closer
+	// <clear/> in disabledPackageSources can re-enable a farther entry.
+	disabled := config.MergeDisabledSources(layers)
+
+	var allSources []string
+	sourceSet := make(map[string]bool)
+
+	// Process configs in reverse order (least specific first)
+	for i := len(layers) - 1; i >= 0; i-- {
+		cfg := layers[i].Config
 
 		// Check if this config clears all parent sources
 		// In NuGet, any <clear> element (even <clear>false</clear>) triggers a clear
@@ -306,8 +373,8 @@ func DiscoverDgSpecConfig(proj *project.Project) (*DgSpecConfig, error) {
 			sourceSet = make(map[string]bool)
 		}
 
-		// Get enabled sources from this config
-		for _, src := range cfg.GetEnabledPackageSources() {
+		// Get enabled sources from this config, honoring the merged disabled set
+		for _, src := range cfg.EnabledPackageSources(disabled) {
 			sourceValue := src.Value
 
 			// Normalize local file paths to use native separators
@@ -416,13 +483,24 @@ func DiscoverDgSpecConfig(proj *project.Project) (*DgSpecConfig, error) {
 		}
 	}
 
+	// RestorePackagesPath in the project wins over globalPackagesFolder
+	restoreProps := proj.GetRestoreProperties()
+	if restoreProps.PackagesPath != "" {
+		packagesPath = restoreProps.PackagesPath
+	}
+
 	return &DgSpecConfig{
-		PackagesPath:     packagesPath,
-		FallbackFolders:  fallbackFolders,
-		Sources:          allSources,
-		ConfigPaths:      configPaths,
-		RuntimeIDPath:    runtimeIDPath,
-		SdkAnalysisLevel: sdkAnalysisLevel,
+		PackagesPath:        packagesPath,
+		FallbackFolders:     fallbackFolders,
+		Sources:             allSources,
+		ConfigPaths:         configPaths,
+		RuntimeIDPath:       runtimeIDPath,
+		SdkAnalysisLevel:    sdkAnalysisLevel,
+		NoCache:             restoreProps.NoCache,
+		IgnoreFailedSources: restoreProps.IgnoreFailedSources,
+		UseLockFile:         restoreProps.UseLockFile,
+		LockFilePath:        restoreProps.LockFilePath,
+		LockedMode:          restoreProps.LockedMode,
 	}, nil
 }
 
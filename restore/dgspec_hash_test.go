@@ -178,3 +178,119 @@ func TestDgSpecHasher_WithDownloadDependencies(t *testing.T) {
 	assert.Contains(t, jsonStr, "downloadDependencies")
 	assert.Contains(t, jsonStr, "Test.Package")
 }
+
+func TestCalculateDgSpecHash_RestoreProperties(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectPath := filepath.Join(tmpDir, "test.csproj")
+
+	// Same project path for every variant, so only the restore properties differ
+	hashFor := func(property string) (string, *DgSpecConfig) {
+		content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    ` + property + `
+  </PropertyGroup>
+</Project>`
+		require.NoError(t, os.WriteFile(projectPath, []byte(content), 0644))
+
+		proj, err := project.LoadProject(projectPath)
+		require.NoError(t, err)
+		cfg, err := DiscoverDgSpecConfig(proj)
+		require.NoError(t, err)
+		hash, err := CalculateDgSpecHashWithConfig(proj, cfg)
+		require.NoError(t, err)
+		return hash, cfg
+	}
+
+	base, _ := hashFor("")
+
+	hash, cfg := hashFor(`<RestorePackagesPath>packages</RestorePackagesPath>`)
+	assert.Equal(t, filepath.Join(tmpDir, "packages"), cfg.PackagesPath)
+	assert.NotEqual(t, base, hash)
+
+	hash, cfg = hashFor(`<RestoreNoCache>true</RestoreNoCache>`)
+	assert.True(t, cfg.NoCache)
+	assert.NotEqual(t, base, hash)
+
+	hash, cfg = hashFor(`<RestoreIgnoreFailedSources>true</RestoreIgnoreFailedSources>`)
+	assert.True(t, cfg.IgnoreFailedSources)
+	assert.NotEqual(t, base, hash)
+
+	hash, cfg = hashFor(`<RestorePackagesWithLockFile>true</RestorePackagesWithLockFile>`)
+	assert.True(t, cfg.UseLockFile)
+	assert.NotEqual(t, base, hash)
+
+	lockHash := hash
+	hash, cfg = hashFor(`<RestorePackagesWithLockFile>true</RestorePackagesWithLockFile><RestoreLockedMode>true</RestoreLockedMode>`)
+	assert.True(t, cfg.LockedMode)
+	assert.NotEqual(t, lockHash, hash)
+}
+
+func TestDgSpecHasher_WithLockProperties(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "test.csproj")
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(projectPath, []byte(content), 0644))
+	proj, err := project.LoadProject(projectPath)
+	require.NoError(t, err)
+
+	data, err := NewDgSpecHasher(proj).GenerateJSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "restoreLockProperties")
+
+	data, err = NewDgSpecHasher(proj).WithLockProperties(true, "/test/custom.lock.json", true).GenerateJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data),
+		`"restoreLockProperties":{"restorePackagesWithLockFile":"true","nuGetLockFilePath":"/test/custom.lock.json","restoreLockedMode":true},"restoreAuditProperties"`)
+}
+
+func TestDgSpecHasher_CentralPackageVersions(t *testing.T) {
+	proj := writeCentralPackagesProject(t, `
+    <PackageVersion Include="Serilog" Version="3.1.1" />
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.3" />`, `
+    <PackageReference Include="Newtonsoft.Json" />
+    <PackageReference Include="Serilog" VersionOverride="4.0.0" />`)
+
+	data, err := NewDgSpecHasher(proj).GenerateJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"projectStyle":"PackageReference","centralPackageVersionsManagementEnabled":true,`)
+	assert.Contains(t, string(data),
+		`"dependencies":{"Newtonsoft.Json":{"target":"Package","version":"[13.0.3, )","versionCentrallyManaged":true},`+
+			`"Serilog":{"target":"Package","version":"[4.0.0, )","versionOverride":"[4.0.0, )"}},`+
+			`"centralPackageVersions":{"Newtonsoft.Json":"[13.0.3, )","Serilog":"[3.1.1, )"},`)
+
+	// Disabling overrides changes the hash
+	proj.Root.PropertyGroup[0].CentralPackageVersionOverrideEnabled = "false"
+	disabled, err := NewDgSpecHasher(proj).GenerateJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(disabled), `"centralPackageVersionsManagementEnabled":true,"centralPackageVersionOverrideDisabled":true`)
+}
+
+func TestRestorer_DgSpecHashIncludesCommandLineOverrides(t *testing.T) {
+	projectPath := filepath.Join(t.TempDir(), "test.csproj")
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+</Project>`
+	require.NoError(t, os.WriteFile(projectPath, []byte(content), 0644))
+	proj, err := project.LoadProject(projectPath)
+	require.NoError(t, err)
+
+	hashWith := func(opts *Options) string {
+		hash, err := NewRestorer(opts, &mockConsole{}).calculateDgSpecHash(proj)
+		require.NoError(t, err)
+		return hash
+	}
+
+	base := hashWith(&Options{})
+	assert.NotEqual(t, base, hashWith(&Options{PackagesFolder: filepath.Join(t.TempDir(), "packages")}))
+	assert.NotEqual(t, base, hashWith(&Options{NoCache: true}))
+	assert.NotEqual(t, base, hashWith(&Options{IgnoreFailedSources: true}))
+	assert.NotEqual(t, base, hashWith(&Options{UseLockFile: true}))
+	assert.NotEqual(t, base, hashWith(&Options{LockedMode: true}))
+	assert.NotEqual(t, base, hashWith(&Options{AllowPrereleaseEverywhere: true}))
+}
@@ -14,6 +14,12 @@ type DgSpecHasher struct {
 	configPaths             []string
 	runtimeIDPath           string
 	sdkAnalysisLevel        string
+	noCache                 bool
+	ignoreFailedSources     bool
+	useLockFile             bool
+	lockFilePath            string
+	lockedMode              bool
+	allowPrereleaseAll      bool
 	downloadDependenciesMap map[string]map[string]string // tfm -> (name -> version)
 }
 
@@ -60,6 +66,32 @@ func (h *DgSpecHasher) WithSdkAnalysisLevel(level string) *DgSpecHasher {
 	return h
 }
 
+// WithNoCache records RestoreNoCache.
+func (h *DgSpecHasher) WithNoCache(noCache bool) *DgSpecHasher {
+	h.noCache = noCache
+	return h
+}
+
+// WithIgnoreFailedSources records RestoreIgnoreFailedSources.
+func (h *DgSpecHasher) WithIgnoreFailedSources(ignore bool) *DgSpecHasher {
+	h.ignoreFailedSources = ignore
+	return h
+}
+
+// WithLockProperties records RestorePackagesWithLockFile, NuGetLockFilePath and RestoreLockedMode.
+func (h *DgSpecHasher) WithLockProperties(useLockFile bool, lockFilePath string, lockedMode bool) *DgSpecHasher {
+	h.useLockFile = useLockFile
+	h.lockFilePath = lockFilePath
+	h.lockedMode = lockedMode
+	return h
+}
+
+// WithAllowPrereleaseEverywhere records Options.AllowPrereleaseEverywhere.
+func (h *DgSpecHasher) WithAllowPrereleaseEverywhere(allow bool) *DgSpecHasher {
+	h.allowPrereleaseAll = allow
+	return h
+}
+
 // WithDownloadDependencies sets the download dependencies map.
 func (h *DgSpecHasher) WithDownloadDependencies(deps map[string]map[string]string) *DgSpecHasher {
 	h.downloadDependenciesMap = deps
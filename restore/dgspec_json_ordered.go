@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
@@ -148,7 +149,7 @@ func (w *OrderedJSONWriter) writeRestoreMetadata(hasher *DgSpecHasher) {
 
 	// 2. projectName (line 126)
 	// Use filepath.Base for cross-platform compatibility (Windows uses backslashes)
-	projectName := strings.TrimSuffix(filepath.Base(projectPath), ".csproj")
+	projectName := strings.TrimSuffix(filepath.Base(projectPath), filepath.Ext(projectPath))
 	w.writeString(",")
 	w.writeStringField("projectName", projectName)
 
@@ -180,6 +181,33 @@ func (w *OrderedJSONWriter) writeRestoreMetadata(hasher *DgSpecHasher) {
 	w.writeStringField("projectStyle", "PackageReference")
 
 	// 8. Booleans (line 137) - WriteMetadataBooleans - skip if all false
+	if props, err := proj.CentralPackageVersions(); err == nil && props != nil {
+		w.writeString(",")
+		w.writeBoolField("centralPackageVersionsManagementEnabled", true)
+		if !proj.IsCentralPackageVersionOverrideEnabled(props) {
+			w.writeString(",")
+			w.writeBoolField("centralPackageVersionOverrideDisabled", true)
+		}
+		if proj.IsCentralPackageTransitivePinningEnabled(props) {
+			w.writeString(",")
+			w.writeBoolField("CentralPackageTransitivePinningEnabled", true)
+		}
+	}
+	// NuGet.Client keeps RestoreNoCache and RestoreIgnoreFailedSources in the restore
+	// arguments; gonuget writes them here (only when set) so changing them invalidates no-op.
+	if hasher.noCache {
+		w.writeString(",")
+		w.writeBoolField("restoreNoCache", true)
+	}
+	if hasher.ignoreFailedSources {
+		w.writeString(",")
+		w.writeBoolField("restoreIgnoreFailedSources", true)
+	}
+	// gonuget only: resolving with --prerelease must not be a no-op for a restore without it
+	if hasher.allowPrereleaseAll {
+		w.writeString(",")
+		w.writeBoolField("allowPrereleaseEverywhere", true)
+	}
 
 	// 9. fallbackFolders (lines 146-153)
 	if len(hasher.fallbackFolders) > 0 {
@@ -235,10 +263,14 @@ func (w *OrderedJSONWriter) writeRestoreMetadata(hasher *DgSpecHasher) {
 	w.writeWarningProperties()
 
 	// 16. restoreLockProperties (line 163) - skip if empty
+	if hasher.useLockFile || hasher.lockFilePath != "" || hasher.lockedMode {
+		w.writeString(",")
+		w.writeRestoreLockProperties(hasher)
+	}
 
 	// 17. restoreAuditProperties (line 164)
 	w.writeString(",")
-	w.writeRestoreAuditProperties()
+	w.writeRestoreAuditProperties(hasher)
 
 	// 18. packagesConfigPath (lines 166-169) - skip for PackageReference
 
@@ -293,14 +325,43 @@ func (w *OrderedJSONWriter) writeWarningProperties() {
 	w.writeString("}")
 }
 
+// writeRestoreLockProperties writes restore lock properties.
+// Reference: PackageSpecWriter.cs WriteMetadataRestoreLockProperties()
+func (w *OrderedJSONWriter) writeRestoreLockProperties(hasher *DgSpecHasher) {
+	w.writeEscapedString("restoreLockProperties")
+	w.writeString(":{")
+
+	// restorePackagesWithLockFile is written as a string, restoreLockedMode only when true
+	comma := false
+	if hasher.useLockFile {
+		w.writeStringField("restorePackagesWithLockFile", "true")
+		comma = true
+	}
+	if hasher.lockFilePath != "" {
+		if comma {
+			w.writeString(",")
+		}
+		w.writeStringField("nuGetLockFilePath", hasher.lockFilePath)
+		comma = true
+	}
+	if hasher.lockedMode {
+		if comma {
+			w.writeString(",")
+		}
+		w.writeBoolField("restoreLockedMode", true)
+	}
+
+	w.writeString("}")
+}
+
 // writeRestoreAuditProperties writes restore audit properties.
 // Reference: PackageSpecWriter.cs WriteNuGetAuditProperties() (lines 220-243)
-func (w *OrderedJSONWriter) writeRestoreAuditProperties() {
+func (w *OrderedJSONWriter) writeRestoreAuditProperties(hasher *DgSpecHasher) {
 	w.writeEscapedString("restoreAuditProperties")
 	w.writeString(":{")
 
 	// Order from WriteNuGetAuditProperties: enableAudit, auditLevel, auditMode
-	w.writeStringField("enableAudit", "true")
+	w.writeStringField("enableAudit", strconv.FormatBool(hasher.proj.IsNuGetAuditEnabled()))
 	w.writeString(",")
 	w.writeStringField("auditLevel", "low")
 	w.writeString(",")
@@ -358,7 +419,8 @@ func (w *OrderedJSONWriter) writeFrameworkInfo(hasher *DgSpecHasher, tfm string)
 	w.writeStringField("targetAlias", tfm)
 
 	// dependencies
-	packageRefs := hasher.proj.GetPackageReferences()
+	packageRefs, _, _ := packageReferences(hasher.proj)
+	managed := centrallyManagedPackages(hasher.proj)
 	if len(packageRefs) > 0 {
 		w.writeString(",")
 		w.writeEscapedString("dependencies")
@@ -374,15 +436,27 @@ func (w *OrderedJSONWriter) writeFrameworkInfo(hasher *DgSpecHasher, tfm string)
 		for i, pkg := range sorted {
 			w.writeEscapedString(pkg.Include)
 			w.writeString(":{")
+			info := newDependencyInfo(pkg)
+			if info.Include != "" {
+				w.writeStringField("include", info.Include)
+				w.writeString(",")
+			}
+			if info.SuppressParent != "" {
+				w.writeStringField("suppressParent", info.SuppressParent)
+				w.writeString(",")
+			}
 			w.writeStringField("target", "Package")
 			w.writeString(",")
 
-			// Normalize version to range format
-			version := pkg.Version
-			if !strings.HasPrefix(version, "[") && !strings.HasPrefix(version, "(") {
-				version = "[" + version + ", )"
+			w.writeStringField("version", versionRangeString(pkg.Version))
+			if pkg.VersionOverride != "" {
+				w.writeString(",")
+				w.writeStringField("versionOverride", versionRangeString(pkg.VersionOverride))
+			}
+			if managed[strings.ToLower(pkg.Include)] {
+				w.writeString(",")
+				w.writeBoolField("versionCentrallyManaged", true)
 			}
-			w.writeStringField("version", version)
 			w.writeString("}")
 
 			if i < len(sorted)-1 {
@@ -392,6 +466,25 @@ func (w *OrderedJSONWriter) writeFrameworkInfo(hasher *DgSpecHasher, tfm string)
 		w.writeString("}")
 	}
 
+	// centralPackageVersions
+	if props, err := hasher.proj.CentralPackageVersions(); err == nil && props != nil {
+		if versions := props.GetPackageVersions(); len(versions) > 0 {
+			sort.SliceStable(versions, func(i, j int) bool {
+				return strings.ToLower(versions[i].Include) < strings.ToLower(versions[j].Include)
+			})
+			w.writeString(",")
+			w.writeEscapedString("centralPackageVersions")
+			w.writeString(":{")
+			for i, pv := range versions {
+				if i > 0 {
+					w.writeString(",")
+				}
+				w.writeStringField(pv.Include, versionRangeString(pv.Version))
+			}
+			w.writeString("}")
+		}
+	}
+
 	// For .NET 6+, add imports, assetTargetFallback, warn, etc.
 	if strings.HasPrefix(tfm, "net6") || strings.HasPrefix(tfm, "net7") ||
 		strings.HasPrefix(tfm, "net8") || strings.HasPrefix(tfm, "net9") {
@@ -481,3 +574,11 @@ func (w *OrderedJSONWriter) writeFrameworkReferences() {
 	w.writeStringField("privateAssets", "all")
 	w.writeString("}}")
 }
+
+// versionRangeString writes a plain version as the range it stands for, "[1.0.0, )".
+func versionRangeString(version string) string {
+	if strings.HasPrefix(version, "[") || strings.HasPrefix(version, "(") {
+		return version
+	}
+	return "[" + version + ", )"
+}
@@ -3,6 +3,8 @@ package restore
 import (
 	"fmt"
 	"time"
+
+	"github.com/willibrandon/gonuget/observability"
 )
 
 // DiagnosticTracer captures restore operations for diagnostic output.
@@ -53,18 +55,18 @@ type AssetsInfo struct {
 }
 
 // ResolutionTracer implements DiagnosticTracer for dependency resolution tracing.
-// Only active when verbosity is set to "diagnostic".
+// Only active at diagnostic verbosity.
 type ResolutionTracer struct {
 	console Console
 	enabled bool
 }
 
 // NewResolutionTracer creates a new resolution tracer.
-// Tracing is only enabled when verbosity is "diagnostic" or "diag".
-func NewResolutionTracer(console Console, verbosity string) *ResolutionTracer {
+// Tracing is only enabled at diagnostic verbosity.
+func NewResolutionTracer(console Console, verbosity observability.Verbosity) *ResolutionTracer {
 	return &ResolutionTracer{
 		console: console,
-		enabled: verbosity == "diagnostic" || verbosity == "diag",
+		enabled: verbosity >= observability.VerbosityDiagnostic,
 	}
 }
 
@@ -178,6 +180,9 @@ func (t *ResolutionTracer) TracePerformanceBreakdown(timing *PerformanceTiming)
 	// Package downloads
 	if timing.PackageDownloads > 0 {
 		t.console.Printf("  Package downloads: %s\n", formatDuration(timing.PackageDownloads))
+		if timing.LockWait > 0 {
+			t.console.Printf("    Waiting for package locks: %s\n", formatDuration(timing.LockWait))
+		}
 		if len(timing.DownloadTimings) > 0 {
 			for pkg, dur := range timing.DownloadTimings {
 				cacheStatus := ""
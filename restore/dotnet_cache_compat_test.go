@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/observability"
 )
 
 // TestDotnetCacheCompatibility verifies that gonuget correctly handles cache files
@@ -51,7 +52,7 @@ func TestDotnetCacheCompatibility(t *testing.T) {
 
 	// Step 2: Run gonuget restore (should hit cache)
 	opts := &Options{
-		Verbosity: "normal",
+		Verbosity: observability.VerbosityNormal,
 		Sources:   []string{"https://api.nuget.org/v3/index.json"},
 	}
 
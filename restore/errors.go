@@ -2,11 +2,13 @@ package restore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/willibrandon/gonuget/core"
 	"github.com/willibrandon/gonuget/core/resolver"
+	nugethttp "github.com/willibrandon/gonuget/http"
 	"github.com/willibrandon/gonuget/version"
 )
 
@@ -60,6 +62,24 @@ func (e *NuGetError) FormatError(colorize bool) string {
 
 // Common NuGet error codes (matching NuGet.Client)
 const (
+	// NU1004: packages.lock.json is inconsistent with the project in locked mode
+	ErrorCodeLockFileInconsistent = "NU1004"
+
+	// NU1008: A PackageReference defines its version under Central Package Management
+	ErrorCodeCentralPackageVersionDefined = "NU1008"
+
+	// NU1009: An implicitly defined PackageReference also has a PackageVersion
+	ErrorCodeImplicitCentralPackageVersion = "NU1009"
+
+	// NU1010: A PackageReference has no PackageVersion under Central Package Management
+	ErrorCodeMissingCentralPackageVersion = "NU1010"
+
+	// NU1013: A PackageReference has a VersionOverride while overrides are disabled
+	ErrorCodeVersionOverrideDisabled = "NU1013"
+
+	// NU1100: Package not mapped to any source by packageSourceMapping
+	ErrorCodeUnresolvedDependency = "NU1100"
+
 	// NU1101: Unable to find package
 	ErrorCodePackageNotFound = "NU1101"
 
@@ -69,6 +89,26 @@ const (
 	// NU1103: Unable to download package
 	ErrorCodePackageDownloadFailed = "NU1103"
 
+	// NU1301: Unable to load the service index for a source
+	ErrorCodeSourceUnreachable = "NU1301"
+
+	// NU1403: A downloaded package doesn't match the content hash in packages.lock.json
+	ErrorCodeContentHashValidationFailed = "NU1403"
+
+	// NU1801: Unable to load a source whose failure is ignored (RestoreIgnoreFailedSources)
+	ErrorCodeIgnoredSourceFailure = "NU1801"
+
+	// NU1504: Several PackageReference items for the same package
+	ErrorCodeDuplicatePackageReference = "NU1504"
+
+	// GN1001: Same package id and version has different content on multiple sources.
+	// NuGet has no such diagnostic, so the code uses a gonuget prefix rather than
+	// taking a NU code NuGet may assign to something else.
+	ErrorCodeSourceHashMismatch = "GN1001"
+
+	// NU1603: A direct reference's lower bound was not found; a higher version was resolved
+	ErrorCodeApproximateBestMatch = "NU1603"
+
 	// NU1605: Detected package downgrade
 	ErrorCodePackageDowngrade = "NU1605"
 )
@@ -82,15 +122,12 @@ type VersionInfo struct {
 
 // NewPackageNotFoundError creates a NU1101 error for a package that doesn't exist.
 func NewPackageNotFoundError(projectPath, packageID, version string, sources []string) *NuGetError {
-	// Format sources (convert URLs to friendly names when possible)
+	// Format sources (convert nuget.org URLs to its friendly name)
 	sourceNames := make([]string, len(sources))
 	for i, source := range sources {
-		switch {
-		case strings.Contains(source, "nuget.org"):
+		if nugethttp.IsNuGetOrgURL(source) {
 			sourceNames[i] = "nuget.org"
-		case strings.Contains(source, "/v2") || strings.Contains(source, "/v2/"):
-			sourceNames[i] = "NuGet V2"
-		default:
+		} else {
 			sourceNames[i] = source
 		}
 	}
@@ -143,6 +180,84 @@ func NewPackageDownloadFailedError(projectPath, packageID, versionConstraint str
 	}
 }
 
+// NewSourceHashMismatchError creates a GN1001 diagnostic for a package whose content
+// differs between sources. The package from usedSource is the one that was installed.
+func NewSourceHashMismatchError(projectPath, packageID, packageVersion, usedSource string, hashes []SourceHash) *NuGetError {
+	parts := make([]string, len(hashes))
+	for i, h := range hashes {
+		parts[i] = fmt.Sprintf("%s (%s)", h.Source, h.Hash)
+	}
+
+	message := fmt.Sprintf("Package %s %s has different content on multiple sources: %s. The package from %s was used",
+		packageID, packageVersion, strings.Join(parts, ", "), usedSource)
+
+	return &NuGetError{
+		Code:        ErrorCodeSourceHashMismatch,
+		Message:     message,
+		ProjectPath: projectPath,
+		PackageID:   packageID,
+	}
+}
+
+// NewSourceUnreachableError creates a NU1301 diagnostic for a source whose service index
+// could not be loaded. The code is changed to NU1801 when source failures are ignored.
+func NewSourceUnreachableError(projectPath, source string, cause error) *NuGetError {
+	message := fmt.Sprintf("Unable to load the service index for source %s.", source)
+	if cause != nil {
+		message += "\n      " + cause.Error()
+	}
+
+	return &NuGetError{
+		Code:        ErrorCodeSourceUnreachable,
+		Message:     message,
+		ProjectPath: projectPath,
+	}
+}
+
+// NewContentHashValidationError creates a NU1403 error for a downloaded package whose
+// content doesn't match the hash recorded in packages.lock.json.
+func NewContentHashValidationError(projectPath, packageID, packageVersion string) *NuGetError {
+	message := fmt.Sprintf("Package content hash validation failed for %s.%s. The package is different than the last restore.",
+		packageID, packageVersion)
+
+	return &NuGetError{
+		Code:        ErrorCodeContentHashValidationFailed,
+		Message:     message,
+		ProjectPath: projectPath,
+		PackageID:   packageID,
+	}
+}
+
+// NewUnmappedPackageError creates a NU1100 error for a package that packageSourceMapping
+// maps to none of the sources. sources are the names of the sources that were skipped.
+func NewUnmappedPackageError(projectPath, packageID, versionConstraint, targetFramework string, sources []string) *NuGetError {
+	message := fmt.Sprintf("Unable to resolve '%s (%s)' for '%s'. PackageSourceMapping is enabled, the following source(s) were not considered: %s.",
+		packageID, formatVersionConstraintForDisplay(versionConstraint), targetFramework, strings.Join(sources, ", "))
+
+	return &NuGetError{
+		Code:        ErrorCodeUnresolvedDependency,
+		Message:     message,
+		ProjectPath: projectPath,
+		PackageID:   packageID,
+	}
+}
+
+// NewLockFileInconsistentError creates an NU1004 error for a locked-mode restore that
+// would have to change packages.lock.json. reason explains what changed.
+func NewLockFileInconsistentError(projectPath, reason string) *NuGetError {
+	message := "The packages lock file is inconsistent with the project dependencies so restore can't be run in locked mode. " +
+		"Disable the RestoreLockedMode MSBuild property or pass an explicit `--force-evaluate` option to run restore to update the lock file."
+	if reason != "" {
+		message += " " + reason
+	}
+
+	return &NuGetError{
+		Code:        ErrorCodeLockFileInconsistent,
+		Message:     message,
+		ProjectPath: projectPath,
+	}
+}
+
 // formatVersionConstraintForDisplay formats a version constraint for error message display.
 // Converts NuGet range syntax to dotnet's display format:
 // - [1.0.0,) → >= 1.0.0
@@ -267,6 +382,11 @@ func (r *Restorer) buildUnresolvedError(ctx context.Context, unresolvedPkgs []re
 	errors := make([]*NuGetError, 0, len(unresolvedPkgs))
 	for i := range unresolvedPkgs {
 		pkg := &unresolvedPkgs[i]
+		if nugetErr := r.checkPackageMapped(projectPath, pkg.ID, pkg.VersionRange, pkg.TargetFramework); nugetErr != nil {
+			errors = append(errors, nugetErr)
+			continue
+		}
+
 		// Try to detect if this is NU1101, NU1102, or NU1103
 		queryResult := r.tryGetVersionInfo(ctx, pkg.ID, pkg.VersionRange)
 
@@ -299,7 +419,7 @@ func (r *Restorer) buildUnresolvedError(ctx context.Context, unresolvedPkgs []re
 				projectPath,
 				pkg.ID,
 				pkg.VersionRange,
-				r.opts.Sources,
+				r.packageSourceURLs(pkg.ID),
 			)
 			errors = append(errors, err)
 		}
@@ -355,146 +475,75 @@ func (r *Restorer) checkVersionAvailability(ctx context.Context, packageID, vers
 		return nil, nil, nil, true
 	}
 
-	// Get all repositories from the client
-	repos := r.client.GetRepositoryManager().ListRepositories()
-
-	// Parallel source queries for 2x faster error reporting (critical for NU1101/NU1102/NU1103)
-	type sourceResult struct {
-		index          int
-		sourceName     string
-		versions       []string
-		nearestVersion string
-		canSatisfy     bool
-		hasVersions    bool
-	}
-
-	results := make(chan sourceResult, len(repos))
-
-	// Query all sources in parallel - network I/O is the bottleneck
-	for idx, repo := range repos {
-		go func(idx int, repo *core.SourceRepository) {
-			// Format source name (check V2 first since it also contains "nuget.org")
-			sourceName := repo.SourceURL()
-			if strings.Contains(sourceName, "/api/v2") {
-				sourceName = "NuGet V2"
-			} else if strings.Contains(sourceName, "nuget.org") {
-				sourceName = "nuget.org"
-			}
-
-			// Try to list all versions of this package from this repository
-			versions, err := repo.ListVersions(ctx, nil, packageID)
-
-			if err != nil || len(versions) == 0 {
-				// Package doesn't exist in this source
-				results <- sourceResult{index: idx, sourceName: sourceName, hasVersions: false}
-				return
-			}
-
-			// Package exists! Optimize by checking max version first for early rejection
-			var nearestVersion string
-			var maxVersion *version.NuGetVersion
-
-			// Find max version (versions are typically sorted, so check last first)
-			// For NU1102 error display: use HIGHEST version (nearest to requested version)
-			// For NU1103 error display: use LOWEST prerelease (will be updated later)
-			if len(versions) > 0 {
-				// Try last version first (usually the highest) for optimization
-				if maxV, err := version.Parse(versions[len(versions)-1]); err == nil {
-					maxVersion = maxV
-					nearestVersion = versions[len(versions)-1]
-				}
-
-				// Verify it's actually the max by checking a few more
-				for i := len(versions) - 2; i >= 0 && i >= len(versions)-5; i-- {
-					if v, err := version.Parse(versions[i]); err == nil {
-						if maxVersion == nil || v.Compare(maxVersion) > 0 {
-							maxVersion = v
-							nearestVersion = versions[i]
-						}
-					}
-				}
-			}
-
-			// OPTIMIZATION: If constraint minimum > max version, no version can satisfy
-			// This provides fast rejection for NU1102 cases like "99.99.99" > "13.0.4"
-			canSatisfy := false
-			if maxVersion != nil && versionRange.MinVersion != nil {
-				cmp := versionRange.MinVersion.Compare(maxVersion)
-				switch {
-				case !versionRange.MinInclusive && cmp == 0:
-					// Constraint requires > maxVersion, which is impossible
-					// Don't set canSatisfy, use nearestVersion = maxVersion
-				case cmp > 0:
-					// Constraint requires higher than any available version - fast fail
-					// Don't set canSatisfy, use nearestVersion = maxVersion
-				default:
-					// Constraint might be satisfiable - check if max version satisfies
-					if versionRange.Satisfies(maxVersion) {
-						canSatisfy = true
-					} else {
-						// Max doesn't satisfy, need to check other versions
-						for _, v := range versions {
-							nv, err := version.Parse(v)
-							if err != nil {
-								continue
-							}
-							if versionRange.Satisfies(nv) {
-								canSatisfy = true
-								nearestVersion = v
-								break
-							}
-						}
-					}
-				}
-			}
-
-			results <- sourceResult{
-				index:          idx,
-				sourceName:     sourceName,
-				versions:       versions,
-				nearestVersion: nearestVersion,
-				canSatisfy:     canSatisfy,
-				hasVersions:    true,
-			}
-		}(idx, repo)
-	}
+	sources, canSatisfy := r.queryVersions(ctx, packageID, versionRange)
+	versionInfos, allVersions, allSourceNames := summarizeSourceVersions(sources, versionRange)
+	return versionInfos, allVersions, allSourceNames, canSatisfy
+}
 
-	// Collect results from parallel queries and preserve original order
-	resultsByIndex := make([]sourceResult, len(repos))
-	for range len(repos) {
-		result := <-results
-		resultsByIndex[result.index] = result
+// queryVersions lists the versions of a package on every source, as restore sees them
+// (unlisted versions included), and reports whether one satisfies versionRange.
+func (r *Restorer) queryVersions(ctx context.Context, packageID string, versionRange *version.Range) ([]core.SourceVersions, bool) {
+	resolved, err := core.ResolveVersion(ctx, nil, packageID, versionRange, core.ResolveVersionOptions{
+		Repositories:    r.packageRepositories(packageID),
+		Selection:       core.SelectLowest,
+		IncludeUnlisted: true,
+	})
+	if err != nil {
+		var notFound *core.VersionNotFoundError
+		if errors.As(err, &notFound) {
+			return notFound.Sources, false
+		}
+		return nil, false
 	}
+	return resolved.Sources, true
+}
 
-	// Process results in original source order (critical for source name display order)
-	versionInfos := make([]VersionInfo, 0, len(repos))
+// summarizeSourceVersions returns, in source order, the version information of the sources
+// that have the package, all their versions, and the names of all queried sources.
+// The nearest version of each source is computed like NuGet.Client's GetBestMatch.
+func summarizeSourceVersions(sources []core.SourceVersions, versionRange *version.Range) ([]VersionInfo, []string, []string) {
+	versionInfos := make([]VersionInfo, 0, len(sources))
 	allVersions := make([]string, 0)
-	allSourceNames := make([]string, 0, len(repos))
-	canSatisfy := false
+	allSourceNames := make([]string, 0, len(sources))
 
-	for _, result := range resultsByIndex {
+	for _, source := range sources {
+		sourceName := sourceDisplayName(source.Repository.SourceURL())
 		// Track all sources queried (for NU1101 error reporting)
-		allSourceNames = append(allSourceNames, result.sourceName)
+		allSourceNames = append(allSourceNames, sourceName)
 
-		if !result.hasVersions {
+		if len(source.Versions) == 0 {
+			// Package doesn't exist in this source
 			continue
 		}
 
-		// Collect all versions for NU1103 detection
-		allVersions = append(allVersions, result.versions...)
-
-		if result.canSatisfy {
-			canSatisfy = true
+		versions := make([]string, len(source.Versions))
+		for i, v := range source.Versions {
+			versions[i] = v.String()
 		}
+		// Collect all versions for NU1103 detection
+		allVersions = append(allVersions, versions...)
 
 		versionInfos = append(versionInfos, VersionInfo{
-			Source:         result.sourceName,
-			VersionCount:   len(result.versions),
-			NearestVersion: result.nearestVersion,
+			Source:         sourceName,
+			VersionCount:   len(versions),
+			NearestVersion: getBestMatch(versions, versionRange),
 		})
 	}
 
-	return versionInfos, allVersions, allSourceNames, canSatisfy
+	return versionInfos, allVersions, allSourceNames
+}
+
+// sourceDisplayName returns the name restore messages use for a source URL
+func sourceDisplayName(sourceURL string) string {
+	// Only URLs on a nuget.org host get a friendly name
+	switch {
+	case !nugethttp.IsNuGetOrgURL(sourceURL):
+		return sourceURL
+	case strings.Contains(strings.ToLower(sourceURL), "/api/v2"):
+		return "NuGet V2"
+	default:
+		return "nuget.org"
+	}
 }
 
 // updateNearestVersionForNU1103 updates versionInfos to show the LOWEST prerelease version
@@ -551,16 +600,14 @@ func (r *Restorer) updateNearestVersionForNU1103(versionInfos []VersionInfo, all
 // 3. For ranges with bounds, find first version above pivot that is closest
 // 4. If no match, return highest version
 //
+// The versions may come in any order; they are sorted with version.SortVersions.
+//
 // Examples:
 //   - Range [1.0.0, ), Available [0.7.0, 0.9.0] → 0.7.0 (closest below lower bound)
 //   - Range (0.5.0, 1.0.0), Available [0.1.0, 1.0.0] → 1.0.0 (closest to upper bound)
 //   - Range (, 1.0.0), Available [2.0.0, 3.0.0] → 2.0.0 (closest above upper bound)
 //   - Range [1.*,), Available [0.0.1, 0.9.0] → 0.9.0 (highest below lower bound)
 func getBestMatch(versions []string, vr *version.Range) string {
-	if len(versions) == 0 {
-		return ""
-	}
-
 	// Parse all versions
 	parsedVersions := make([]*version.NuGetVersion, 0, len(versions))
 	for _, v := range versions {
@@ -573,10 +620,12 @@ func getBestMatch(versions []string, vr *version.Range) string {
 	if len(parsedVersions) == 0 {
 		return ""
 	}
+	version.SortVersions(parsedVersions)
+	highest := parsedVersions[len(parsedVersions)-1]
 
 	// If no range provided, return highest version
 	if vr == nil {
-		return parsedVersions[len(parsedVersions)-1].String()
+		return highest.String()
 	}
 
 	// Find pivot point (prefer MinVersion, fallback to MaxVersion)
@@ -588,32 +637,18 @@ func getBestMatch(versions []string, vr *version.Range) string {
 		ideal = vr.MaxVersion
 	default:
 		// No bounds, return highest version
-		return parsedVersions[len(parsedVersions)-1].String()
+		return highest.String()
 	}
 
-	var bestMatch *version.NuGetVersion
-
-	// If range has bounds, find first version above pivot that is closest
-	if vr.MinVersion != nil || vr.MaxVersion != nil {
-		for _, v := range parsedVersions {
-			if v.Compare(ideal) == 0 {
-				return v.String()
-			}
-
-			if v.Compare(ideal) > 0 {
-				if bestMatch == nil || v.Compare(bestMatch) < 0 {
-					bestMatch = v
-				}
-			}
+	// The versions are ascending, so the first one at or above the pivot is the closest
+	for _, v := range parsedVersions {
+		if v.Compare(ideal) >= 0 {
+			return v.String()
 		}
 	}
 
-	if bestMatch == nil {
-		// Take the highest possible version
-		bestMatch = parsedVersions[len(parsedVersions)-1]
-	}
-
-	return bestMatch.String()
+	// Take the highest possible version
+	return highest.String()
 }
 
 // tryGetVersionInfo attempts to query available versions for a package to distinguish NU1101 vs NU1102 vs NU1103.
@@ -626,40 +661,8 @@ func (r *Restorer) tryGetVersionInfo(ctx context.Context, packageID, versionCons
 		vr = nil
 	}
 
-	// Get all repositories from the client
-	repos := r.client.GetRepositoryManager().ListRepositories()
-	versionInfos := make([]VersionInfo, 0, len(repos))
-	allVersions := make([]string, 0)
-
-	for _, repo := range repos {
-		// Try to list all versions of this package from this repository
-		versions, err := repo.ListVersions(ctx, nil, packageID)
-
-		if err != nil || len(versions) == 0 {
-			// Package doesn't exist in this source
-			continue
-		}
-
-		// Package exists! Collect all versions for NU1103 detection
-		allVersions = append(allVersions, versions...)
-
-		// Calculate nearest version based on version range (matches NuGet.Client's GetBestMatch)
-		nearestVersion := getBestMatch(versions, vr)
-
-		// Format source name (check V2 first since it also contains "nuget.org")
-		sourceName := repo.SourceURL()
-		if strings.Contains(sourceName, "/api/v2") {
-			sourceName = "NuGet V2"
-		} else if strings.Contains(sourceName, "nuget.org") {
-			sourceName = "nuget.org"
-		}
-
-		versionInfos = append(versionInfos, VersionInfo{
-			Source:         sourceName,
-			VersionCount:   len(versions),
-			NearestVersion: nearestVersion,
-		})
-	}
+	sources, _ := r.queryVersions(ctx, packageID, vr)
+	versionInfos, allVersions, _ := summarizeSourceVersions(sources, vr)
 
 	return versionQueryResult{
 		versionInfos: versionInfos,
@@ -737,3 +740,46 @@ func isPrereleaseAllowed(versionRangeStr string) bool {
 
 	return false
 }
+
+// NewCentralPackageVersionDefinedError creates an NU1008 error for the packages whose
+// PackageReference items define a version in a project using Central Package Management.
+func NewCentralPackageVersionDefinedError(projectPath string, packageIDs []string) *NuGetError {
+	return &NuGetError{
+		Code: ErrorCodeCentralPackageVersionDefined,
+		Message: "Projects that use central package version management should not define the version on the PackageReference items but on the PackageVersion items: " +
+			strings.Join(packageIDs, ";") + ".",
+		ProjectPath: projectPath,
+	}
+}
+
+// NewImplicitCentralPackageVersionError creates an NU1009 error for implicitly defined
+// packages that also have a PackageVersion item.
+func NewImplicitCentralPackageVersionError(projectPath string, packageIDs []string) *NuGetError {
+	return &NuGetError{
+		Code: ErrorCodeImplicitCentralPackageVersion,
+		Message: fmt.Sprintf("The packages %s are implicitly referenced. You do not typically need to reference them from your project or in your central package versions management file. For more information, see https://aka.ms/sdkimplicitrefs",
+			strings.Join(packageIDs, ";")),
+		ProjectPath: projectPath,
+	}
+}
+
+// NewMissingCentralPackageVersionError creates an NU1010 error for the PackageReference
+// items without a PackageVersion in a project using Central Package Management.
+func NewMissingCentralPackageVersionError(projectPath string, packageIDs []string) *NuGetError {
+	return &NuGetError{
+		Code:        ErrorCodeMissingCentralPackageVersion,
+		Message:     fmt.Sprintf("The PackageReference items %s do not have corresponding PackageVersion.", strings.Join(packageIDs, ";")),
+		ProjectPath: projectPath,
+	}
+}
+
+// NewVersionOverrideDisabledError creates an NU1013 error for a PackageReference with a
+// VersionOverride in a project that sets CentralPackageVersionOverrideEnabled to false.
+func NewVersionOverrideDisabledError(projectPath, packageID string) *NuGetError {
+	return &NuGetError{
+		Code:        ErrorCodeVersionOverrideDisabled,
+		Message:     fmt.Sprintf("The package reference %s specifies a VersionOverride but the ability to override a centrally defined version is currently disabled.", packageID),
+		ProjectPath: projectPath,
+		PackageID:   packageID,
+	}
+}
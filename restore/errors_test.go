@@ -239,3 +239,28 @@ func TestNewPackageNotFoundError(t *testing.T) {
 		})
 	}
 }
+
+func TestSourceDisplayName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://api.nuget.org/v3/index.json", "nuget.org"},
+		{"https://www.nuget.org/api/v2/", "NuGet V2"},
+		{"https://nuget.org.evil.example/v3/index.json", "https://nuget.org.evil.example/v3/index.json"},
+		{"https://feed.example/api/v2", "https://feed.example/api/v2"},
+		{"http://[::1]:8081/repository/nuget/index.json", "http://[::1]:8081/repository/nuget/index.json"},
+	}
+
+	for _, tt := range tests {
+		if got := sourceDisplayName(tt.url); got != tt.want {
+			t.Errorf("sourceDisplayName(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	// A lookalike host isn't reported as nuget.org
+	err := NewPackageNotFoundError("/tmp/test.csproj", "Missing", "1.0.0", []string{"https://nuget.org.evil.example/v3/index.json"})
+	if want := []string{"https://nuget.org.evil.example/v3/index.json"}; !slices.Equal(err.Sources, want) {
+		t.Errorf("Sources = %q, want %q", err.Sources, want)
+	}
+}
@@ -0,0 +1,92 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/willibrandon/gonuget/core"
+	nugethttp "github.com/willibrandon/gonuget/http"
+)
+
+// setTransportWrapper installs wrap with fresh global clients and repositories,
+// so requests made afterwards go through it.
+func setTransportWrapper(t *testing.T, wrap func(http.RoundTripper) http.RoundTripper) {
+	t.Helper()
+
+	nugethttp.SetTransportWrapper(wrap)
+	nugethttp.ResetGlobalClient()
+	core.ResetGlobalRepositoryCache()
+	t.Cleanup(func() {
+		nugethttp.SetTransportWrapper(nil)
+		nugethttp.ResetGlobalClient()
+		core.ResetGlobalRepositoryCache()
+	})
+}
+
+func TestRun_ReplaysCapturedHTTP(t *testing.T) {
+	feed := newHermeticFeed(t)
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Legacy.Log" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+
+	restoreInto := func(packagesFolder string) []byte {
+		t.Helper()
+
+		opts := &Options{
+			Sources:        []string{feed.URL + "/index.json"},
+			PackagesFolder: packagesFolder,
+			Force:          true,
+			NoCache:        true,
+		}
+		console := &mockConsole{}
+		if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+			t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+		}
+
+		nupkg, err := os.ReadFile(filepath.Join(packagesFolder, "legacy.log", "1.0.0", "legacy.log.1.0.0.nupkg"))
+		if err != nil {
+			t.Fatalf("package not installed: %v", err)
+		}
+		return nupkg
+	}
+
+	captureDir := filepath.Join(tmpDir, "capture")
+	capture, err := nugethttp.NewCapture(captureDir)
+	if err != nil {
+		t.Fatalf("NewCapture() error = %v", err)
+	}
+	setTransportWrapper(t, capture.Wrap)
+	live := restoreInto(filepath.Join(tmpDir, "packages-live"))
+
+	// Replay with the feed gone
+	feed.Close()
+	replay, err := nugethttp.NewReplayTransport(captureDir)
+	if err != nil {
+		t.Fatalf("NewReplayTransport() error = %v", err)
+	}
+	setTransportWrapper(t, replay.Wrap)
+	replayed := restoreInto(filepath.Join(tmpDir, "packages-replay"))
+
+	if !bytes.Equal(live, replayed) {
+		t.Error("replayed restore installed a different package than the live restore")
+	}
+}
@@ -5,19 +5,115 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/willibrandon/gonuget/core/resolver"
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/observability"
 	"github.com/willibrandon/gonuget/packaging"
 	"github.com/willibrandon/gonuget/version"
 )
 
+// packageInstallPath returns the global packages folder directory a package is extracted to.
+// It uses the same lowercase {id}/{version} layout as the V3 extractor, so cache checks
+// and reported paths match what is written to disk.
+func packageInstallPath(packagesFolder, packageID, packageVersion string) string {
+	resolver := packaging.NewVersionFolderPathResolver(packagesFolder, true)
+	if ver, err := version.Parse(packageVersion); err == nil {
+		return resolver.GetInstallPath(packageID, ver)
+	}
+	return filepath.Join(resolver.GetVersionListDirectory(packageID), strings.ToLower(packageVersion))
+}
+
+// packageFilePath returns the .nupkg path inside a package's install directory.
+func packageFilePath(packagesFolder, packageID, packageVersion string) string {
+	if ver, err := version.Parse(packageVersion); err == nil {
+		return packaging.NewVersionFolderPathResolver(packagesFolder, true).GetPackageFilePath(packageID, ver)
+	}
+	fileName := strings.ToLower(packageID) + "." + strings.ToLower(packageVersion) + ".nupkg"
+	return filepath.Join(packageInstallPath(packagesFolder, packageID, packageVersion), fileName)
+}
+
+// packageHashPath returns the .nupkg.sha512 marker written when a package is extracted.
+func packageHashPath(packagesFolder, packageID, packageVersion string) string {
+	return packageFilePath(packagesFolder, packageID, packageVersion) + ".sha512"
+}
+
+// packageDownload is the outcome of installing one resolved package.
+type packageDownload struct {
+	pkg      *resolver.PackageDependencyInfo
+	path     string        // Install directory in the global packages folder
+	cacheHit bool          // The package was already installed
+	elapsed  time.Duration // Time spent installing the package
+	err      error         // Download or extraction failure
+	mismatch *NuGetError   // GN1001 when the package differs between sources (opt-in check)
+}
+
+// downloadPackages installs packages, up to Options.MaxConcurrentDownloads at a time.
+// Every package is attempted even when others fail. The outcomes are returned ordered by
+// package ID and version, so they are reported in the same order on every run.
+func (r *Restorer) downloadPackages(ctx context.Context, projectPath, packagesFolder string, packages map[string]*resolver.PackageDependencyInfo) []packageDownload {
+	downloads := make([]packageDownload, 0, len(packages))
+	for _, pkg := range packages {
+		downloads = append(downloads, packageDownload{
+			pkg:  pkg,
+			path: packageInstallPath(packagesFolder, pkg.ID, pkg.Version),
+		})
+	}
+	slices.SortFunc(downloads, func(a, b packageDownload) int {
+		if c := strings.Compare(strings.ToLower(a.pkg.ID), strings.ToLower(b.pkg.ID)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.pkg.Version, b.pkg.Version)
+	})
+
+	sem := make(chan struct{}, r.opts.maxConcurrentDownloads())
+	var wg sync.WaitGroup
+	for i := range downloads {
+		download := &downloads[i]
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			// Check if package already exists in cache (--force re-resolves but doesn't re-download)
+			if _, err := os.Stat(download.path); err == nil {
+				download.cacheHit = true
+			}
+
+			// An installed package must still be the one packages.lock.json recorded
+			// Matches NuGet.Client's RestoreCommand.ValidatePackagesSha512
+			if download.cacheHit && !r.installedHashMatches(packagesFolder, download.pkg.ID, download.pkg.Version) {
+				download.err = fmt.Errorf("%w: %s %s in %s", packaging.ErrPackageHashMismatch, download.pkg.ID, download.pkg.Version, packagesFolder)
+				return
+			}
+
+			start := time.Now()
+			installSource, err := r.downloadPackage(ctx, download.pkg.ID, download.pkg.Version, download.path, download.cacheHit)
+			download.err = err
+
+			// Compare content across sources for freshly downloaded packages (opt-in)
+			if err == nil && installSource != "" && r.opts.sourceHashCheckEnabled() {
+				download.mismatch = r.verifySourceHashes(ctx, projectPath, download.pkg.ID, download.pkg.Version, installSource)
+			}
+			download.elapsed = time.Since(start)
+		})
+	}
+	wg.Wait()
+
+	return downloads
+}
+
 // downloadPackage downloads and installs a package using the appropriate protocol (V2 or V3).
+// Returns the URL of the source the package was downloaded from, or "" if it was already installed.
 // Matches NuGet.Client's RestoreCommand package installation flow.
-func (r *Restorer) downloadPackage(ctx context.Context, packageID, packageVersion, packagePath string, cacheHit bool) error {
-	isDiagnostic := r.opts.Verbosity == "diagnostic"
+func (r *Restorer) downloadPackage(ctx context.Context, packageID, packageVersion, packagePath string, cacheHit bool) (string, error) {
+	isDiagnostic := r.opts.Verbosity >= observability.VerbosityDiagnostic
 
 	// Diagnostic: Show cache hit or lock acquisition
 	if isDiagnostic {
@@ -26,26 +122,27 @@ func (r *Restorer) downloadPackage(ctx context.Context, packageID, packageVersio
 			r.console.Printf("         CACHE %s %s (already in %s)\n", packageID, packageVersion, packagePath)
 		} else {
 			// Package needs to be downloaded - show lock acquisition (use 9 space indent)
-			r.console.Printf("         Acquiring lock for the installation of %s %s\n", packageID, packageVersion)
-			r.console.Printf("         Acquired lock for the installation of %s %s\n", packageID, packageVersion)
+			// Both lines are written at once so concurrent installs can't separate them
+			r.console.Printf("         Acquiring lock for the installation of %s %s\n         Acquired lock for the installation of %s %s\n",
+				packageID, packageVersion, packageID, packageVersion)
 		}
 	}
 	// Parse version
 	pkgVer, err := version.Parse(packageVersion)
 	if err != nil {
-		return fmt.Errorf("invalid version: %w", err)
+		return "", fmt.Errorf("invalid version: %w", err)
 	}
 
 	// Get source repository and detect protocol
-	repos := r.client.GetRepositoryManager().ListRepositories()
+	repos := r.packageRepositories(packageID)
 	if len(repos) == 0 {
-		return fmt.Errorf("no package sources configured")
+		return "", fmt.Errorf("no package sources configured for %s", packageID)
 	}
 	repo := repos[0]
 
 	provider, err := repo.GetProvider(ctx)
 	if err != nil {
-		return fmt.Errorf("get provider: %w", err)
+		return "", fmt.Errorf("get provider: %w", err)
 	}
 
 	protocolVersion := provider.ProtocolVersion()
@@ -61,40 +158,99 @@ func (r *Restorer) downloadPackage(ctx context.Context, packageID, packageVersio
 	extractionContext := &packaging.PackageExtractionContext{
 		PackageSaveMode:    packaging.PackageSaveModeNupkg | packaging.PackageSaveModeNuspec | packaging.PackageSaveModeFiles,
 		XMLDocFileSaveMode: packaging.XMLDocFileSaveModeNone,
+		Logger:             &lockLogger{console: r.console, verbose: logsLockWaits(r.opts.Verbosity)},
+		LockTimeout:        r.opts.LockTimeout,
+		OnLockAcquired: func(waited time.Duration) {
+			r.lockWaitMu.Lock()
+			r.lockWait += waited
+			r.lockWaitMu.Unlock()
+		},
 	}
 
 	// Use V3 or V2 installer based on protocol
 	if protocolVersion == "v3" {
-		return r.installPackageV3(ctx, packageID, packageVersion, packagePath, packageIdentity, sourceURL, extractionContext, cacheHit)
+		// Detailed: log the URL the package is actually downloaded from
+		var downloadURL string
+		if r.logsDownloads() && !cacheHit {
+			if resolver, ok := provider.(packageDownloadURLResolver); ok {
+				downloadURL, _ = resolver.PackageDownloadURL(ctx, packageID, packageVersion)
+			}
+		}
+		return r.installPackageV3(ctx, packageID, packageVersion, packagePath, packageIdentity, sourceURL, downloadURL, extractionContext, cacheHit)
 	}
 	return r.installPackageV2(ctx, packageID, packageVersion, packagePath, packageIdentity, sourceURL, extractionContext, cacheHit)
 }
 
+// packageDownloadURLResolver is implemented by providers that can report the .nupkg download URL.
+type packageDownloadURLResolver interface {
+	PackageDownloadURL(ctx context.Context, packageID, version string) (string, error)
+}
+
+// logsDownloads reports whether HTTP GET/OK lines for package downloads are printed.
+func (r *Restorer) logsDownloads() bool {
+	return r.opts.Verbosity >= observability.VerbosityDetailed
+}
+
+// logRetry prints a source request that is about to be retried, in the words of NuGet's
+// HTTP retry handler.
+func (r *Restorer) logRetry(event nugethttp.RetryEvent) {
+	reason := fmt.Sprintf("Response status code does not indicate success: %d (%s).", event.StatusCode, http.StatusText(event.StatusCode))
+	if event.Err != nil {
+		reason = event.Err.Error()
+	}
+	// One Printf, so concurrent installs can't separate the two lines
+	r.console.Printf("           An error was encountered when fetching '%s %s'. The request will now be retried.\n           %s\n",
+		event.Method, event.URL, reason)
+}
+
+// logsLockWaits reports whether messages about waiting for another process's package lock
+// are printed at verbosity (normal and above).
+func logsLockWaits(verbosity observability.Verbosity) bool {
+	return verbosity >= observability.VerbosityNormal
+}
+
+// lockLogger prints the extractor's lock messages. Info messages (waiting for another
+// process) are only printed when verbose; warnings (a stale lock was broken) always are.
+type lockLogger struct {
+	console Console
+	verbose bool
+}
+
+func (l *lockLogger) Info(format string, args ...any) {
+	if l.verbose {
+		l.console.Printf("  "+format+"\n", args...)
+	}
+}
+
+func (l *lockLogger) Warning(format string, args ...any) {
+	l.console.Warning(format, args...)
+}
+
+func (l *lockLogger) Error(format string, args ...any) {
+	l.console.Error(format, args...)
+}
+
 // installPackageV3 installs a package using V3 protocol and layout.
+// downloadURL is the resolved .nupkg URL for logging (empty when not logged).
+// Returns the URL of the source the package was downloaded from, or "" if it was already installed.
 // Matches NuGet.Client's V3 package installation flow.
-func (r *Restorer) installPackageV3(ctx context.Context, packageID, packageVersion, packagePath string, packageIdentity *packaging.PackageIdentity, sourceURL string, extractionContext *packaging.PackageExtractionContext, cacheHit bool) error {
-	isDiagnostic := r.opts.Verbosity == "diagnostic"
+func (r *Restorer) installPackageV3(ctx context.Context, packageID, packageVersion, packagePath string, packageIdentity *packaging.PackageIdentity, sourceURL, downloadURL string, extractionContext *packaging.PackageExtractionContext, cacheHit bool) (string, error) {
+	isDiagnostic := r.opts.Verbosity >= observability.VerbosityDiagnostic
 
 	// Create path resolver for V3 layout
 	packagesFolder := filepath.Dir(filepath.Dir(packagePath)) // Go up to packages root
 	pathResolver := packaging.NewVersionFolderPathResolver(packagesFolder, true)
 
-	// Create download callback
+	// Create download callback, which records the source that served the package
+	var installSource string
 	copyToAsync := func(targetPath string) error {
-		// Diagnostic: HTTP GET request (if not cached) - use 11 space indent
+		// Detailed: HTTP GET request (if not cached) - use 11 space indent
 		downloadStart := time.Now()
-		if isDiagnostic && !cacheHit {
-			// Build package download URL for logging (use lowercase for URL)
-			downloadURL := fmt.Sprintf("%s/flatcontainer/%s/%s/%s.%s.nupkg",
-				strings.TrimSuffix(sourceURL, "/index.json"),
-				strings.ToLower(packageID),
-				strings.ToLower(packageVersion),
-				strings.ToLower(packageID),
-				strings.ToLower(packageVersion))
+		if downloadURL != "" {
 			r.console.Printf("           GET %s\n", downloadURL)
 		}
 
-		stream, err := r.client.DownloadPackage(ctx, packageID, packageVersion)
+		stream, source, err := r.downloadFromSources(ctx, packageID, packageVersion)
 		if err != nil {
 			return fmt.Errorf("download package: %w", err)
 		}
@@ -104,16 +260,9 @@ func (r *Restorer) installPackageV3(ctx context.Context, packageID, packageVersi
 			}
 		}()
 
-		// Diagnostic: HTTP OK response (if not cached) - use 11 space indent
-		if isDiagnostic && !cacheHit {
-			elapsed := time.Since(downloadStart)
-			downloadURL := fmt.Sprintf("%s/flatcontainer/%s/%s/%s.%s.nupkg",
-				strings.TrimSuffix(sourceURL, "/index.json"),
-				strings.ToLower(packageID),
-				strings.ToLower(packageVersion),
-				strings.ToLower(packageID),
-				strings.ToLower(packageVersion))
-			r.console.Printf("           OK %s %dms\n", downloadURL, elapsed.Milliseconds())
+		// Detailed: HTTP OK response (if not cached) - use 11 space indent
+		if downloadURL != "" {
+			r.console.Printf("           OK %s %dms\n", downloadURL, time.Since(downloadStart).Milliseconds())
 		}
 
 		outFile, err := os.Create(targetPath)
@@ -129,6 +278,17 @@ func (r *Restorer) installPackageV3(ctx context.Context, packageID, packageVersi
 		if _, err := io.Copy(outFile, stream); err != nil {
 			return fmt.Errorf("write package: %w", err)
 		}
+		installSource = source
+
+		// A corrupted or changed download is rejected before it is extracted
+		if expected := r.expectedPackageHash(packageID, packageVersion); expected != "" {
+			if _, err := outFile.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("read package: %w", err)
+			}
+			if err := packaging.VerifyPackageHash(outFile, expected); err != nil {
+				return err
+			}
+		}
 
 		return nil
 	}
@@ -144,7 +304,7 @@ func (r *Restorer) installPackageV3(ctx context.Context, packageID, packageVersi
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to install package: %w", err)
+		return "", fmt.Errorf("failed to install package: %w", err)
 	}
 
 	// Diagnostic: Vulnerability check (always CACHE since we don't implement vulnerability DB yet) - use 11 space indent
@@ -153,16 +313,17 @@ func (r *Restorer) installPackageV3(ctx context.Context, packageID, packageVersi
 		r.console.Printf("           CACHE %s\n", vulnURL)
 	}
 
-	// Note: Terminal Logger hides download/cache messages in detailed mode
-	// We match Terminal Logger behavior: diagnostic mode shows download messages, detailed mode is clean
+	// Note: Terminal Logger hides cache messages in detailed mode; download URLs are shown
+	// so the source a package came from can be traced
 
-	return nil
+	return installSource, nil
 }
 
 // installPackageV2 installs a package using V2 protocol and layout.
+// Returns the URL of the source the package was downloaded from, or "" if it was already installed.
 // Matches NuGet.Client's V2 package installation flow.
-func (r *Restorer) installPackageV2(ctx context.Context, packageID, packageVersion, packagePath string, packageIdentity *packaging.PackageIdentity, sourceURL string, extractionContext *packaging.PackageExtractionContext, cacheHit bool) error {
-	isDiagnostic := r.opts.Verbosity == "diagnostic"
+func (r *Restorer) installPackageV2(ctx context.Context, packageID, packageVersion, packagePath string, packageIdentity *packaging.PackageIdentity, sourceURL string, extractionContext *packaging.PackageExtractionContext, cacheHit bool) (string, error) {
+	isDiagnostic := r.opts.Verbosity >= observability.VerbosityDiagnostic
 
 	// Create path resolver for V2 layout
 	packagesFolder := filepath.Dir(filepath.Dir(packagePath)) // Go up to packages root
@@ -172,7 +333,7 @@ func (r *Restorer) installPackageV2(ctx context.Context, packageID, packageVersi
 	targetPath := pathResolver.GetInstallPath(packageIdentity)
 	if _, err := os.Stat(targetPath); err == nil {
 		// Note: Terminal Logger hides this message completely
-		return nil
+		return "", nil
 	}
 
 	// Diagnostic: HTTP GET request (if not cached) - use 11 space indent
@@ -186,9 +347,9 @@ func (r *Restorer) installPackageV2(ctx context.Context, packageID, packageVersi
 	}
 
 	// Download package to memory
-	stream, err := r.client.DownloadPackage(ctx, packageID, packageVersion)
+	stream, sourceURL, err := r.downloadFromSources(ctx, packageID, packageVersion)
 	if err != nil {
-		return fmt.Errorf("download package: %w", err)
+		return "", fmt.Errorf("download package: %w", err)
 	}
 	defer func() {
 		if cerr := stream.Close(); cerr != nil {
@@ -209,7 +370,14 @@ func (r *Restorer) installPackageV2(ctx context.Context, packageID, packageVersi
 	// Read into memory (V2 extractor needs ReadSeeker)
 	packageData, err := io.ReadAll(stream)
 	if err != nil {
-		return fmt.Errorf("read package: %w", err)
+		return "", fmt.Errorf("read package: %w", err)
+	}
+
+	// A corrupted or changed download is rejected before it is extracted
+	if expected := r.expectedPackageHash(packageID, packageVersion); expected != "" {
+		if err := packaging.VerifyPackageHash(bytes.NewReader(packageData), expected); err != nil {
+			return "", err
+		}
 	}
 
 	packageReader := bytes.NewReader(packageData)
@@ -224,7 +392,7 @@ func (r *Restorer) installPackageV2(ctx context.Context, packageID, packageVersi
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to extract package: %w", err)
+		return "", fmt.Errorf("failed to extract package: %w", err)
 	}
 
 	// Diagnostic: Vulnerability check (always CACHE since we don't implement vulnerability DB yet) - use 11 space indent
@@ -234,5 +402,5 @@ func (r *Restorer) installPackageV2(ctx context.Context, packageID, packageVersi
 	}
 
 	// Note: Terminal Logger hides download messages in detailed mode
-	return nil
+	return sourceURL, nil
 }
@@ -1,12 +1,25 @@
 package restore
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
 	"github.com/willibrandon/gonuget/core"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/version"
 )
 
 func TestRestorer_downloadPackage(t *testing.T) {
@@ -33,7 +46,7 @@ func TestRestorer_downloadPackage(t *testing.T) {
 
 	opts := &Options{
 		PackagesFolder: packagesFolder,
-		Verbosity:      "normal",
+		Verbosity:      observability.VerbosityNormal,
 		Sources:        []string{"https://api.nuget.org/v3/index.json"},
 	}
 
@@ -43,7 +56,7 @@ func TestRestorer_downloadPackage(t *testing.T) {
 		console: console,
 	}
 
-	err := restorer.downloadPackage(context.Background(), "Newtonsoft.Json", "13.0.3", packagePath, false)
+	_, err := restorer.downloadPackage(context.Background(), "Newtonsoft.Json", "13.0.3", packagePath, false)
 	if err != nil {
 		t.Fatalf("downloadPackage failed: %v", err)
 	}
@@ -80,7 +93,7 @@ func TestRestorer_downloadPackage_InvalidVersion(t *testing.T) {
 
 	opts := &Options{
 		PackagesFolder: packagesFolder,
-		Verbosity:      "normal",
+		Verbosity:      observability.VerbosityNormal,
 		Sources:        []string{"https://api.nuget.org/v3/index.json"},
 	}
 
@@ -91,7 +104,7 @@ func TestRestorer_downloadPackage_InvalidVersion(t *testing.T) {
 	}
 
 	// Test with invalid version format
-	err := restorer.downloadPackage(context.Background(), "TestPackage", "not-a-version", packagePath, false)
+	_, err := restorer.downloadPackage(context.Background(), "TestPackage", "not-a-version", packagePath, false)
 	if err == nil {
 		t.Error("Expected error for invalid version, got nil")
 	}
@@ -121,7 +134,7 @@ func TestRestorer_downloadPackage_DiagnosticVerbosity(t *testing.T) {
 
 	opts := &Options{
 		PackagesFolder: packagesFolder,
-		Verbosity:      "diagnostic",
+		Verbosity:      observability.VerbosityDiagnostic,
 		Sources:        []string{"https://api.nuget.org/v3/index.json"},
 	}
 
@@ -132,7 +145,7 @@ func TestRestorer_downloadPackage_DiagnosticVerbosity(t *testing.T) {
 	}
 
 	// Test with cacheHit = false (should show lock messages)
-	err := restorer.downloadPackage(context.Background(), "Newtonsoft.Json", "13.0.3", packagePath, false)
+	_, err := restorer.downloadPackage(context.Background(), "Newtonsoft.Json", "13.0.3", packagePath, false)
 	if err != nil {
 		t.Fatalf("downloadPackage failed: %v", err)
 	}
@@ -170,7 +183,7 @@ func TestRestorer_downloadPackage_CacheHit(t *testing.T) {
 
 	opts := &Options{
 		PackagesFolder: packagesFolder,
-		Verbosity:      "diagnostic",
+		Verbosity:      observability.VerbosityDiagnostic,
 		Sources:        []string{"https://api.nuget.org/v3/index.json"},
 	}
 
@@ -187,7 +200,7 @@ func TestRestorer_downloadPackage_CacheHit(t *testing.T) {
 
 	// For this test, we just verify the function handles the cacheHit parameter
 	// The actual download will likely fail or succeed depending on network/package availability
-	_ = restorer.downloadPackage(context.Background(), "NonExistent.Package.Test", "1.0.0", packagePath, true)
+	_, _ = restorer.downloadPackage(context.Background(), "NonExistent.Package.Test", "1.0.0", packagePath, true)
 
 	// The important thing is the cacheHit branch was exercised
 	// Check if CACHE message would have been shown (if package existed)
@@ -218,7 +231,7 @@ func TestRestorer_installPackageV3(t *testing.T) {
 
 	opts := &Options{
 		PackagesFolder: packagesFolder,
-		Verbosity:      "diagnostic",
+		Verbosity:      observability.VerbosityDiagnostic,
 		Sources:        []string{"https://api.nuget.org/v3/index.json"},
 	}
 
@@ -229,7 +242,7 @@ func TestRestorer_installPackageV3(t *testing.T) {
 	}
 
 	// Use downloadPackage which internally calls installPackageV3 for v3 sources
-	err := restorer.downloadPackage(context.Background(), "Newtonsoft.Json", "13.0.3", packagePath, false)
+	_, err := restorer.downloadPackage(context.Background(), "Newtonsoft.Json", "13.0.3", packagePath, false)
 	if err != nil {
 		t.Fatalf("downloadPackage (which calls installPackageV3) failed: %v", err)
 	}
@@ -269,7 +282,7 @@ func TestRestorer_downloadPackage_NoSources(t *testing.T) {
 
 	opts := &Options{
 		PackagesFolder: packagesFolder,
-		Verbosity:      "normal",
+		Verbosity:      observability.VerbosityNormal,
 		Sources:        []string{},
 	}
 
@@ -279,7 +292,7 @@ func TestRestorer_downloadPackage_NoSources(t *testing.T) {
 		console: console,
 	}
 
-	err := restorer.downloadPackage(context.Background(), "TestPackage", "1.0.0", packagePath, false)
+	_, err := restorer.downloadPackage(context.Background(), "TestPackage", "1.0.0", packagePath, false)
 	if err == nil {
 		t.Error("Expected error when no sources configured")
 	}
@@ -288,3 +301,316 @@ func TestRestorer_downloadPackage_NoSources(t *testing.T) {
 		t.Errorf("Expected 'no package sources' error, got: %v", err)
 	}
 }
+
+func TestPackageInstallPaths_MatchVersionFolderLayout(t *testing.T) {
+	packagesFolder := filepath.Join(t.TempDir(), "packages")
+
+	tests := []struct {
+		id      string
+		version string
+		dir     string
+		nupkg   string
+	}{
+		{id: "Newtonsoft.Json", version: "13.0.3", dir: "newtonsoft.json/13.0.3", nupkg: "newtonsoft.json.13.0.3.nupkg"},
+		// Prerelease labels are lowercased like the extractor writes them
+		{id: "My.Package", version: "1.0.0-Beta.2", dir: "my.package/1.0.0-beta.2", nupkg: "my.package.1.0.0-beta.2.nupkg"},
+		// Versions are normalized before building the folder name
+		{id: "Pkg", version: "1.0", dir: "pkg/1.0.0", nupkg: "pkg.1.0.0.nupkg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id+"/"+tt.version, func(t *testing.T) {
+			wantDir := filepath.Join(packagesFolder, filepath.FromSlash(tt.dir))
+			if got := packageInstallPath(packagesFolder, tt.id, tt.version); got != wantDir {
+				t.Errorf("packageInstallPath() = %q, want %q", got, wantDir)
+			}
+			if got := packageFilePath(packagesFolder, tt.id, tt.version); got != filepath.Join(wantDir, tt.nupkg) {
+				t.Errorf("packageFilePath() = %q, want %q", got, filepath.Join(wantDir, tt.nupkg))
+			}
+			if got := packageHashPath(packagesFolder, tt.id, tt.version); got != filepath.Join(wantDir, tt.nupkg+".sha512") {
+				t.Errorf("packageHashPath() = %q, want %q", got, filepath.Join(wantDir, tt.nupkg+".sha512"))
+			}
+		})
+	}
+}
+
+func TestRun_DetailedLogsPackageDownloadURL(t *testing.T) {
+	feed := newHermeticFeed(t)
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Legacy.Log" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	console := &mockConsole{}
+	opts := &Options{
+		Sources:        []string{feed.URL + "/index.json"},
+		PackagesFolder: filepath.Join(tmpDir, "packages"),
+		Verbosity:      observability.VerbosityDetailed,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	downloadURL := feed.URL + "/flat/legacy.log/1.0.0/legacy.log.1.0.0.nupkg"
+	for _, prefix := range []string{"GET ", "OK "} {
+		if !slices.ContainsFunc(console.messages, func(msg string) bool {
+			return strings.Contains(msg, prefix+downloadURL)
+		}) {
+			t.Errorf("detailed output missing %q line: %v", prefix+downloadURL, console.messages)
+		}
+	}
+}
+
+func TestLockLogger_Verbosity(t *testing.T) {
+	tests := []struct {
+		verbosity observability.Verbosity
+		wantInfo  bool
+	}{
+		{observability.VerbosityQuiet, false},
+		{observability.VerbosityMinimal, false},
+		{observability.VerbosityNormal, true},
+		{observability.VerbosityDetailed, true},
+		{observability.VerbosityDiagnostic, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.verbosity.String(), func(t *testing.T) {
+			console := &mockConsole{}
+			logger := &lockLogger{console: console, verbose: logsLockWaits(tt.verbosity)}
+
+			logger.Info("Waiting for another process to finish installing %s", "Contoso.Lib 1.0.0")
+			logger.Warning("Broke the stale lock on %s", "contoso.lib.1.0.0.nupkg")
+
+			if got := len(console.messages) == 1; got != tt.wantInfo {
+				t.Errorf("waiting message printed = %v, want %v", got, tt.wantInfo)
+			}
+			if len(console.warnings) != 1 {
+				t.Errorf("warnings = %v, want the stale lock warning at every verbosity", console.warnings)
+			}
+		})
+	}
+}
+
+// parallelTestFeed serves a V3 feed of dependency-free packages Par.Pkg1..Par.PkgN 1.0.0.
+// Each nupkg download takes a while, so concurrent downloads overlap, and the highest
+// number of downloads in flight at once is recorded.
+type parallelTestFeed struct {
+	*httptest.Server
+
+	nupkgs map[string][]byte // lowercase ID -> nupkg; packages without one fail to download
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func newParallelTestFeed(t *testing.T, count int, failing ...string) *parallelTestFeed {
+	t.Helper()
+
+	feed := &parallelTestFeed{nupkgs: make(map[string][]byte)}
+	for i := 1; i <= count; i++ {
+		id := fmt.Sprintf("Par.Pkg%d", i)
+		if slices.Contains(failing, id) {
+			continue
+		}
+		builder := packaging.NewPackageBuilder().
+			SetID(id).
+			SetVersion(version.MustParse("1.0.0")).
+			SetDescription("Parallel download test package").
+			SetAuthors("gonuget")
+		if err := builder.AddFileFromBytes("lib/net8.0/"+id+".dll", []byte(id)); err != nil {
+			t.Fatalf("AddFileFromBytes() error = %v", err)
+		}
+		var nupkg bytes.Buffer
+		if err := builder.Save(&nupkg); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		feed.nupkgs[strings.ToLower(id)] = nupkg.Bytes()
+	}
+
+	feed.Server = httptest.NewServer(http.HandlerFunc(feed.serve))
+	t.Cleanup(feed.Close)
+	return feed
+}
+
+func (f *parallelTestFeed) serve(w http.ResponseWriter, r *http.Request) {
+	base := "http://" + r.Host
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/index.json":
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"version": "3.0.0",
+			"resources": []map[string]string{
+				{"@id": base + "/flat/", "@type": "PackageBaseAddress/3.0.0"},
+				{"@id": base + "/registration/", "@type": "RegistrationsBaseUrl/3.6.0"},
+			},
+		})
+	case len(parts) == 3 && parts[0] == "flat" && parts[2] == "index.json":
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"versions": []string{"1.0.0"}})
+	case len(parts) == 3 && parts[0] == "registration" && parts[2] == "index.json":
+		id := parts[1]
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"count": 1,
+			"items": []map[string]any{{
+				"@id":   base + "/registration/" + id + "/index.json#page",
+				"lower": "1.0.0",
+				"upper": "1.0.0",
+				"count": 1,
+				"items": []map[string]any{{
+					"@id": base + "/registration/" + id + "/1.0.0.json",
+					"catalogEntry": map[string]any{
+						"@id":     base + "/catalog/" + id + ".1.0.0.json",
+						"id":      "Par.Pkg" + strings.TrimPrefix(id, "par.pkg"),
+						"version": "1.0.0",
+					},
+					"packageContent": base + "/flat/" + id + "/1.0.0/" + id + ".1.0.0.nupkg",
+				}},
+			}},
+		})
+	case len(parts) == 4 && parts[0] == "flat" && strings.HasSuffix(parts[3], ".nupkg"):
+		f.mu.Lock()
+		f.inFlight++
+		f.maxInFlight = max(f.maxInFlight, f.inFlight)
+		f.mu.Unlock()
+
+		time.Sleep(100 * time.Millisecond)
+
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+
+		nupkg, ok := f.nupkgs[parts[1]]
+		if !ok {
+			http.Error(w, "package is gone", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write(nupkg)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writeParallelTestProject writes a project referencing Par.Pkg1..Par.PkgN.
+func writeParallelTestProject(t *testing.T, dir string, count int) string {
+	t.Helper()
+
+	var refs strings.Builder
+	for i := 1; i <= count; i++ {
+		fmt.Fprintf(&refs, "    <PackageReference Include=\"Par.Pkg%d\" Version=\"1.0.0\" />\n", i)
+	}
+	projPath := filepath.Join(dir, "app.csproj")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+` + refs.String() + `  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return projPath
+}
+
+func TestRestore_ParallelDownloads(t *testing.T) {
+	const count = 8
+	feed := newParallelTestFeed(t, count)
+	tmpDir := t.TempDir()
+	projPath := writeParallelTestProject(t, tmpDir, count)
+	packagesFolder := filepath.Join(tmpDir, "packages")
+
+	opts := &Options{
+		Sources:                []string{feed.URL + "/index.json"},
+		PackagesFolder:         packagesFolder,
+		NoCache:                true,
+		Verbosity:              observability.VerbosityDiagnostic,
+		MaxConcurrentDownloads: 3,
+	}
+	console := &mockConsole{}
+	proj, err := project.LoadProject(projPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, _, err := packageReferences(proj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewRestorer(opts, console).Restore(context.Background(), proj, refs)
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if len(result.DirectPackages) != count {
+		t.Errorf("DirectPackages = %d, want %d", len(result.DirectPackages), count)
+	}
+	for i := 1; i <= count; i++ {
+		if _, err := os.Stat(packageHashPath(packagesFolder, fmt.Sprintf("Par.Pkg%d", i), "1.0.0")); err != nil {
+			t.Errorf("Par.Pkg%d not extracted: %v", i, err)
+		}
+	}
+
+	// Downloads overlap, but never more than MaxConcurrentDownloads at once
+	if feed.maxInFlight < 2 || feed.maxInFlight > 3 {
+		t.Errorf("max concurrent downloads = %d, want 2 or 3", feed.maxInFlight)
+	}
+
+	// The two lock lines of each package stay together
+	for _, msg := range console.messages {
+		if strings.Contains(msg, "Acquiring lock") && !strings.Contains(msg, "Acquired lock") {
+			t.Errorf("lock lines split: %q", msg)
+		}
+	}
+}
+
+func TestRestore_ParallelDownloads_ReportsEveryFailure(t *testing.T) {
+	const count = 5
+	feed := newParallelTestFeed(t, count, "Par.Pkg2", "Par.Pkg4")
+	tmpDir := t.TempDir()
+	projPath := writeParallelTestProject(t, tmpDir, count)
+
+	opts := &Options{
+		Sources:        []string{feed.URL + "/index.json"},
+		PackagesFolder: filepath.Join(tmpDir, "packages"),
+		NoCache:        true,
+		Verbosity:      observability.VerbosityQuiet,
+	}
+	err := Run(context.Background(), []string{projPath}, opts, &mockConsole{})
+	if err == nil {
+		t.Fatal("Run() error = nil, want download failures")
+	}
+	for _, want := range []string{"Par.Pkg2 1.0.0", "Par.Pkg4 1.0.0"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %v, want %s listed", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "Par.Pkg1 ") {
+		t.Errorf("error = %v, lists a package that was downloaded", err)
+	}
+
+	// The packages that could be downloaded were installed
+	if _, err := os.Stat(packageHashPath(opts.PackagesFolder, "Par.Pkg5", "1.0.0")); err != nil {
+		t.Errorf("Par.Pkg5 not extracted: %v", err)
+	}
+}
+
+func TestOptions_MaxConcurrentDownloads(t *testing.T) {
+	if got := (&Options{}).maxConcurrentDownloads(); got != DefaultMaxConcurrentDownloads {
+		t.Errorf("default = %d, want %d", got, DefaultMaxConcurrentDownloads)
+	}
+	if got := (&Options{MaxConcurrentDownloads: 4}).maxConcurrentDownloads(); got != 4 {
+		t.Errorf("maxConcurrentDownloads() = %d, want 4", got)
+	}
+}
@@ -133,6 +133,12 @@ func (p *LocalDependencyProvider) packageExists(packageID string, ver *version.N
 	return false // Not cached
 }
 
+// isInstalled reports whether the given version of a package is fully installed.
+func (p *LocalDependencyProvider) isInstalled(packageID, packageVersion string) bool {
+	ver, err := version.Parse(packageVersion)
+	return err == nil && p.packageExists(packageID, ver)
+}
+
 // extractAllDependencyGroups parses ALL dependency groups from nuspec.
 // Returns all groups without framework filtering (walker does that).
 // Matches GetDependencyInfo logic from FindPackageByIdResource.cs (lines 145-156)
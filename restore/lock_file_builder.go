@@ -2,6 +2,7 @@ package restore
 
 import (
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -14,6 +15,7 @@ import (
 // LockFileBuilder builds project.assets.json from restore results.
 // Ported from NuGet.Commands/RestoreCommand/LockFileBuilder.cs
 type LockFileBuilder struct {
+	packagesFolder string
 }
 
 // NewLockFileBuilder creates a new lock file builder.
@@ -21,11 +23,21 @@ func NewLockFileBuilder() *LockFileBuilder {
 	return &LockFileBuilder{}
 }
 
+// WithPackagesFolder sets the global packages folder the restored packages were
+// extracted to. Without it ~/.nuget/packages is used.
+func (b *LockFileBuilder) WithPackagesFolder(path string) *LockFileBuilder {
+	b.packagesFolder = path
+	return b
+}
+
 // Build creates a LockFile from project and restore results.
 func (b *LockFileBuilder) Build(proj *project.Project, result *Result) *LockFile {
 	// Get packages folder
-	home, _ := os.UserHomeDir()
-	packagesPath := filepath.Join(home, ".nuget", "packages")
+	packagesPath := b.packagesFolder
+	if packagesPath == "" {
+		home, _ := os.UserHomeDir()
+		packagesPath = filepath.Join(home, ".nuget", "packages")
+	}
 
 	// Get all target frameworks
 	targetFrameworks := proj.GetTargetFrameworks()
@@ -66,13 +78,28 @@ func (b *LockFileBuilder) Build(proj *project.Project, result *Result) *LockFile
 		},
 	}
 
-	// Get package references once
-	packageRefs := proj.GetPackageReferences()
+	// Get package references once; the restore already reported any problem loading them
+	packageRefs, _, _ := packageReferences(proj)
+
+	// Central Package Management settings and PackageVersion items
+	managed := centrallyManagedPackages(proj)
+	var centralVersions map[string]string
+	if props, err := proj.CentralPackageVersions(); err == nil && props != nil {
+		lf.Project.Restore.CentralPackageVersionsManagementEnabled = true
+		lf.Project.Restore.CentralPackageVersionOverrideDisabled = !proj.IsCentralPackageVersionOverrideEnabled(props)
+		lf.Project.Restore.CentralPackageTransitivePinningEnabled = proj.IsCentralPackageTransitivePinningEnabled(props)
+		centralVersions = make(map[string]string)
+		for _, pv := range props.GetPackageVersions() {
+			centralVersions[pv.Include] = pv.Version
+		}
+	}
 
 	// Build dependencies list for ProjectFileDependencyGroups
 	dependencies := make([]string, 0, len(packageRefs))
+	includeFlags := make(map[string]uint16, len(packageRefs))
 	for _, pkgRef := range packageRefs {
 		dependencies = append(dependencies, pkgRef.Include+" >= "+pkgRef.Version)
+		includeFlags[strings.ToLower(pkgRef.Include)] = includeAssets(pkgRef)
 	}
 
 	// Get all packages (direct + transitive) - needed for both Libraries and Targets
@@ -90,14 +117,14 @@ func (b *LockFileBuilder) Build(proj *project.Project, result *Result) *LockFile
 		// Add to Project.Frameworks
 		frameworkDeps := make(map[string]DependencyInfo)
 		for _, pkgRef := range packageRefs {
-			frameworkDeps[pkgRef.Include] = DependencyInfo{
-				Target:  "Package",
-				Version: pkgRef.Version,
-			}
+			info := newDependencyInfo(pkgRef)
+			info.VersionCentrallyManaged = managed[strings.ToLower(pkgRef.Include)]
+			frameworkDeps[pkgRef.Include] = info
 		}
 		lf.Project.Frameworks[tfm] = ProjectFrameworkInfo{
-			TargetAlias:  tfm,
-			Dependencies: frameworkDeps,
+			TargetAlias:            tfm,
+			Dependencies:           frameworkDeps,
+			CentralPackageVersions: centralVersions,
 		}
 
 		// Add to ProjectFileDependencyGroups (per-framework)
@@ -119,6 +146,9 @@ func (b *LockFileBuilder) Build(proj *project.Project, result *Result) *LockFile
 		for _, pkg := range allPackages {
 			targetLib := b.createTargetLibrary(pkg, framework, packagesPath)
 			if targetLib != nil {
+				if flags, ok := includeFlags[strings.ToLower(pkg.ID)]; ok {
+					excludeAssets(targetLib, flags)
+				}
 				key := pkg.ID + "/" + pkg.Version
 				target[key] = *targetLib
 			}
@@ -155,8 +185,7 @@ func (b *LockFileBuilder) createTargetLibrary(
 	packagesPath string,
 ) *TargetLibrary {
 	// Build package path
-	pkgPath := filepath.Join(packagesPath, strings.ToLower(pkg.ID), pkg.Version)
-	nupkgPath := filepath.Join(pkgPath, strings.ToLower(pkg.ID)+"."+pkg.Version+".nupkg")
+	nupkgPath := packageFilePath(packagesPath, pkg.ID, pkg.Version)
 
 	// Check if package exists
 	if _, err := os.Stat(nupkgPath); os.IsNotExist(err) {
@@ -180,15 +209,8 @@ func (b *LockFileBuilder) createTargetLibrary(
 	}
 	defer func() { _ = reader.Close() }()
 
-	// Get all files from package
-	files := reader.GetFiles("")
-	paths := make([]string, 0, len(files))
-	for _, file := range files {
-		paths = append(paths, file.Name)
-	}
-
 	// Create content item collection from package files
-	collection := assets.NewContentItemCollection(paths)
+	collection := assets.NewContentItemCollection(reader.GetFiles())
 
 	// Create managed code conventions for asset selection
 	conventions := assets.NewManagedCodeConventions()
@@ -222,3 +244,53 @@ func (b *LockFileBuilder) createTargetLibrary(
 
 	return targetLib
 }
+
+// newDependencyInfo creates the project.frameworks dependency entry for a package reference.
+// Asset metadata is only written when it differs from the defaults, like NuGet's PackageSpecWriter.
+func newDependencyInfo(ref project.PackageReference) DependencyInfo {
+	info := DependencyInfo{
+		Target:          "Package",
+		Version:         ref.Version,
+		VersionOverride: ref.VersionOverride,
+	}
+	if include := includeAssets(ref); include != includeAll {
+		info.Include = formatIncludeFlags(include)
+	}
+	if suppress := suppressParent(ref); suppress != defaultSuppressParent {
+		info.SuppressParent = formatIncludeFlags(suppress)
+	}
+	return info
+}
+
+// excludeAssets clears the asset groups of a target library that the reference doesn't include.
+// A cleared group that had items keeps a "_._" placeholder in its directory, so the package
+// still counts as supporting the framework. Matches LockFileUtils.ExcludeItems.
+func excludeAssets(lib *TargetLibrary, include uint16) {
+	if include&includeCompile == 0 {
+		clearGroup(lib.Compile)
+	}
+	if include&includeRuntime == 0 {
+		clearGroup(lib.Runtime)
+	}
+}
+
+// clearGroup replaces the items of a group that has any real items with a "_._"
+// placeholder in the directory of its shallowest item. Matches LockFileUtils.ClearIfExists.
+func clearGroup(group map[string]map[string]string) {
+	first := ""
+	for item := range group {
+		if strings.HasSuffix(item, "/_._") {
+			continue
+		}
+		depth, firstDepth := strings.LastIndex(item, "/"), strings.LastIndex(first, "/")
+		if first == "" || depth < firstDepth || (depth == firstDepth && strings.ToLower(item) < strings.ToLower(first)) {
+			first = item
+		}
+	}
+	if first == "" {
+		return
+	}
+
+	clear(group)
+	group[path.Dir(first)+"/_._"] = map[string]string{}
+}
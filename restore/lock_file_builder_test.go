@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/version"
 )
 
 func TestLockFileBuilder_Build(t *testing.T) {
@@ -443,6 +445,60 @@ func TestLockFileBuilder_Build_MultiFramework(t *testing.T) {
 	}
 }
 
+func TestLockFileBuilder_Build_PackagesFolder(t *testing.T) {
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "test.csproj")
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Custom.Folder" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	proj, err := project.LoadProject(projPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The package is only in a custom packages folder, as with --packages
+	packagesFolder := filepath.Join(tmpDir, "packages")
+	builder := packaging.NewPackageBuilder().
+		SetID("Custom.Folder").
+		SetVersion(version.MustParse("1.0.0")).
+		SetDescription("Custom folder test package").
+		SetAuthors("gonuget")
+	if err := builder.AddFileFromBytes("lib/netstandard2.0/Custom.Folder.dll", []byte("dll")); err != nil {
+		t.Fatal(err)
+	}
+	nupkgPath := packageFilePath(packagesFolder, "Custom.Folder", "1.0.0")
+	if err := os.MkdirAll(filepath.Dir(nupkgPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.SaveToFile(nupkgPath); err != nil {
+		t.Fatal(err)
+	}
+
+	result := &Result{
+		DirectPackages: []PackageInfo{{ID: "Custom.Folder", Version: "1.0.0", IsDirect: true}},
+	}
+	lockFile := NewLockFileBuilder().WithPackagesFolder(packagesFolder).Build(proj, result)
+
+	lib := lockFile.Targets["net8.0"]["Custom.Folder/1.0.0"]
+	if _, ok := lib.Compile["lib/netstandard2.0/Custom.Folder.dll"]; !ok {
+		t.Errorf("compile = %v, want the assembly from the custom packages folder", lib.Compile)
+	}
+	if _, ok := lockFile.PackageFolders[packagesFolder]; !ok {
+		t.Errorf("packageFolders = %v, want %s", lockFile.PackageFolders, packagesFolder)
+	}
+	if lockFile.Project.Restore.PackagesPath != packagesFolder {
+		t.Errorf("packagesPath = %s, want %s", lockFile.Project.Restore.PackagesPath, packagesFolder)
+	}
+}
+
 // contains checks if string s contains substring substr (helper for tests).
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && containsHelper(s, substr))
@@ -1,9 +1,13 @@
 package restore
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
 // LockFile represents project.assets.json structure.
@@ -15,6 +19,49 @@ type LockFile struct {
 	ProjectFileDependencyGroups map[string][]string      `json:"projectFileDependencyGroups"`
 	PackageFolders              map[string]PackageFolder `json:"packageFolders"`
 	Project                     ProjectInfo              `json:"project"`
+	Logs                        []AssetsLogMessage       `json:"logs,omitempty"`
+}
+
+// AssetsLogMessage is a warning or error in the assets file's logs section.
+// Matches LockFileFormat.WriteLogMessage in NuGet.Client: the project path is implied,
+// and a file path equal to it is left out.
+type AssetsLogMessage struct {
+	Code         string   `json:"code"`
+	Level        string   `json:"level"`
+	WarningLevel int      `json:"warningLevel,omitempty"`
+	FilePath     string   `json:"filePath,omitempty"`
+	Message      string   `json:"message"`
+	LibraryID    string   `json:"libraryId,omitempty"`
+	TargetGraphs []string `json:"targetGraphs,omitempty"`
+}
+
+// newAssetsLogMessages converts the restore logs of a project to its assets file logs.
+func newAssetsLogMessages(projectPath string, logs []LogMessage) []AssetsLogMessage {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	messages := make([]AssetsLogMessage, 0, len(logs))
+	for _, log := range logs {
+		msg := AssetsLogMessage{
+			Code:      log.Code,
+			Level:     log.Level,
+			Message:   log.Message,
+			LibraryID: log.LibraryID,
+		}
+		if log.Level == "Warning" {
+			msg.WarningLevel = log.WarningLevel
+		}
+		if log.FilePath != projectPath {
+			msg.FilePath = log.FilePath
+		}
+		// NuGet.Client writes targetGraphs only when every graph is named
+		if !slices.Contains(log.TargetGraphs, "") {
+			msg.TargetGraphs = log.TargetGraphs
+		}
+		messages = append(messages, msg)
+	}
+	return messages
 }
 
 // Target represents a target framework's dependency graph.
@@ -49,17 +96,21 @@ type ProjectInfo struct {
 
 // Info represents restore metadata.
 type Info struct {
-	ProjectUniqueName        string                   `json:"projectUniqueName"`
-	ProjectName              string                   `json:"projectName"`
-	ProjectPath              string                   `json:"projectPath"`
-	PackagesPath             string                   `json:"packagesPath"`
-	OutputPath               string                   `json:"outputPath"`
-	ProjectStyle             string                   `json:"projectStyle"`
-	Sources                  map[string]SourceInfo    `json:"sources"`
-	FallbackFolders          []string                 `json:"fallbackFolders"`
-	ConfigFilePaths          []string                 `json:"configFilePaths"`
-	OriginalTargetFrameworks []string                 `json:"originalTargetFrameworks"`
-	Frameworks               map[string]FrameworkInfo `json:"frameworks"`
+	ProjectUniqueName string `json:"projectUniqueName"`
+	ProjectName       string `json:"projectName"`
+	ProjectPath       string `json:"projectPath"`
+	PackagesPath      string `json:"packagesPath"`
+	OutputPath        string `json:"outputPath"`
+	ProjectStyle      string `json:"projectStyle"`
+	// Central Package Management settings, written only when set
+	CentralPackageVersionsManagementEnabled bool                     `json:"centralPackageVersionsManagementEnabled,omitempty"`
+	CentralPackageVersionOverrideDisabled   bool                     `json:"centralPackageVersionOverrideDisabled,omitempty"`
+	CentralPackageTransitivePinningEnabled  bool                     `json:"CentralPackageTransitivePinningEnabled,omitempty"`
+	Sources                                 map[string]SourceInfo    `json:"sources"`
+	FallbackFolders                         []string                 `json:"fallbackFolders"`
+	ConfigFilePaths                         []string                 `json:"configFilePaths"`
+	OriginalTargetFrameworks                []string                 `json:"originalTargetFrameworks"`
+	Frameworks                              map[string]FrameworkInfo `json:"frameworks"`
 }
 
 // SourceInfo represents a package source.
@@ -80,28 +131,84 @@ type FrameworkInfo struct {
 type ProjectFrameworkInfo struct {
 	TargetAlias  string                    `json:"targetAlias"`
 	Dependencies map[string]DependencyInfo `json:"dependencies"`
+	// CentralPackageVersions holds the PackageVersion items of a project using Central
+	// Package Management
+	CentralPackageVersions map[string]string `json:"centralPackageVersions,omitempty"`
 }
 
 // DependencyInfo represents a package dependency.
 type DependencyInfo struct {
-	Target  string `json:"target"`
-	Version string `json:"version"`
+	Include        string `json:"include,omitempty"`
+	SuppressParent string `json:"suppressParent,omitempty"`
+	Target         string `json:"target"`
+	Version        string `json:"version"`
+	// VersionOverride is the version the reference overrides its central version with
+	VersionOverride string `json:"versionOverride,omitempty"`
+	// VersionCentrallyManaged is set when the version comes from a PackageVersion item
+	VersionCentrallyManaged bool `json:"versionCentrallyManaged,omitempty"`
 }
 
-// Save writes the lock file to disk.
-func (lf *LockFile) Save(path string) error {
-	// Ensure directory exists
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
+// LoadLockFile reads a project.assets.json file.
+func LoadLockFile(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lf LockFile
+	if err := json.Unmarshal(data, &lf); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
 	}
+	return &lf, nil
+}
 
-	// Marshal to JSON
+// PackageVersion returns the version of a package among the lock file's libraries, or
+// an empty string when the project doesn't use the package.
+func (lf *LockFile) PackageVersion(packageID string) string {
+	for key, lib := range lf.Libraries {
+		id, ver, ok := strings.Cut(key, "/")
+		if ok && lib.Type == "package" && strings.EqualFold(id, packageID) {
+			return ver
+		}
+	}
+	return ""
+}
+
+// Save writes the lock file to disk, leaving an identical file untouched.
+func (lf *LockFile) Save(path string) error {
+	_, err := lf.SaveIfChanged(path)
+	return err
+}
+
+// SaveIfChanged writes the lock file to disk unless the file already holds the same
+// serialized content, and reports whether it was written. Skipping an unchanged file
+// keeps its modification time, so incremental builds watching project.assets.json
+// don't rebuild (matches NuGet.Client's "Assets file has not changed").
+func (lf *LockFile) SaveIfChanged(path string) (bool, error) {
+	// Write paths with dotnet's separators, whatever platform produced them
+	lf.normalizePaths(nativeAssetsPaths)
+
+	// Marshal to JSON; maps are written in key order, so the bytes are deterministic
 	data, err := json.MarshalIndent(lf, "", "  ")
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	// Write to file
-	return os.WriteFile(path, data, 0644)
+	return writeFileIfChanged(path, data)
+}
+
+// writeFileIfChanged writes data to path, creating its directory, unless the file
+// already has exactly that content. Reports whether the file was written.
+func writeFileIfChanged(path string, data []byte) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
 }
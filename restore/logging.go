@@ -1,6 +1,7 @@
 package restore
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/fatih/color"
@@ -8,11 +9,69 @@ import (
 )
 
 // addLog adds a log message to the collector for cache file persistence.
-// Matches MSBuildRestoreUtility.CollectMessage in NuGet.Client.
+// Matches MSBuildRestoreUtility.CollectMessage in NuGet.Client: a message already
+// collected for another target graph gains that graph instead of being repeated.
 func (r *Restorer) addLog(log LogMessage) {
+	if i := slices.IndexFunc(r.logs, func(existing LogMessage) bool {
+		return sameLogMessage(&existing, &log)
+	}); i >= 0 {
+		r.logs[i].TargetGraphs = mergeTargetGraphs(r.logs[i].TargetGraphs, log.TargetGraphs)
+		return
+	}
 	r.logs = append(r.logs, log)
 }
 
+// addGraphWarning collects a warning raised while restoring one target graph.
+// The warning is printed by flushGraphWarnings once every graph is restored, so one
+// raised by several graphs is printed once.
+func (r *Restorer) addGraphWarning(log LogMessage, targetGraph string) {
+	log.Level = "Warning"
+	log.TargetGraphs = []string{targetGraph}
+	if i := slices.IndexFunc(r.graphWarnings, func(existing LogMessage) bool {
+		return sameLogMessage(&existing, &log)
+	}); i >= 0 {
+		r.graphWarnings[i].TargetGraphs = mergeTargetGraphs(r.graphWarnings[i].TargetGraphs, log.TargetGraphs)
+		return
+	}
+	r.graphWarnings = append(r.graphWarnings, log)
+}
+
+// flushGraphWarnings prints the collected target graph warnings ordered by code, then
+// library, and adds them to the logs.
+func (r *Restorer) flushGraphWarnings() {
+	slices.SortStableFunc(r.graphWarnings, func(a, b LogMessage) int {
+		if c := strings.Compare(a.Code, b.Code); c != 0 {
+			return c
+		}
+		return strings.Compare(strings.ToLower(a.LibraryID), strings.ToLower(b.LibraryID))
+	})
+	for i := range r.graphWarnings {
+		r.addLog(r.graphWarnings[i])
+		r.printWarningLog(&r.graphWarnings[i])
+	}
+	r.graphWarnings = nil
+}
+
+// sameLogMessage reports whether two messages are the same diagnostic, possibly for
+// different target graphs.
+func sameLogMessage(a, b *LogMessage) bool {
+	return a.Code == b.Code &&
+		a.Level == b.Level &&
+		a.Message == b.Message &&
+		a.ProjectPath == b.ProjectPath &&
+		strings.EqualFold(a.LibraryID, b.LibraryID)
+}
+
+// mergeTargetGraphs appends the graphs of add missing from graphs.
+func mergeTargetGraphs(graphs, add []string) []string {
+	for _, graph := range add {
+		if !slices.Contains(graphs, graph) {
+			graphs = append(graphs, graph)
+		}
+	}
+	return graphs
+}
+
 // addErrorLog creates and adds an error log from a NuGetError.
 // Matches NuGet.Client's error logging in RestoreCommand.
 func (r *Restorer) addErrorLog(err *NuGetError, targetFramework string) {
@@ -50,22 +109,32 @@ func (r *Restorer) replayLogs(logs []LogMessage) {
 					log.ProjectPath, log.Code, log.Message)
 			}
 		case "warning":
-			// Format warnings similarly (yellow color in TTY mode)
-			if !color.NoColor {
-				const (
-					yellow = "\033[1;33m"
-					reset  = "\033[0m"
-				)
-				r.console.Printf("    %s : %swarning %s%s: %s\n",
-					log.ProjectPath, yellow, log.Code, reset, log.Message)
-			} else {
-				r.console.Printf("    %s : warning %s: %s\n",
-					log.ProjectPath, log.Code, log.Message)
-			}
+			r.printWarningLog(log)
 		}
 	}
 }
 
+// printWarningLog outputs a warning log in dotnet's format (yellow code in TTY mode).
+// A warning raised by several target graphs lists them after the message.
+func (r *Restorer) printWarningLog(log *LogMessage) {
+	message := log.Message
+	if graphs := slices.DeleteFunc(slices.Clone(log.TargetGraphs), func(graph string) bool { return graph == "" }); len(graphs) > 1 {
+		message += " [" + strings.Join(graphs, ", ") + "]"
+	}
+
+	if !color.NoColor {
+		const (
+			yellow = "\033[1;33m"
+			reset  = "\033[0m"
+		)
+		r.console.Printf("    %s : %swarning %s%s: %s\n",
+			log.ProjectPath, yellow, log.Code, reset, message)
+	} else {
+		r.console.Printf("    %s : warning %s: %s\n",
+			log.ProjectPath, log.Code, message)
+	}
+}
+
 // writeCacheFileOnError writes a cache file when restore fails early.
 // Matches NuGet.Client behavior of writing cache even on failure (with success=false).
 func (r *Restorer) writeCacheFileOnError(proj *project.Project, dgSpecHash, cachePath string) {
@@ -0,0 +1,134 @@
+package restore
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+)
+
+func TestAddLog_MergesTargetGraphs(t *testing.T) {
+	r := &Restorer{}
+	log := LogMessage{Code: "NU1101", Level: "Error", Message: "Unable to find package A.", LibraryID: "A"}
+
+	log.TargetGraphs = []string{"net8.0"}
+	r.addLog(log)
+	log.TargetGraphs = []string{"net9.0"}
+	r.addLog(log)
+	r.addLog(log)
+	r.addLog(LogMessage{Code: "NU1101", Level: "Error", Message: "Unable to find package B.", LibraryID: "B", TargetGraphs: []string{"net8.0"}})
+
+	if len(r.logs) != 2 {
+		t.Fatalf("logs = %+v, want 2 messages", r.logs)
+	}
+	if want := []string{"net8.0", "net9.0"}; !reflect.DeepEqual(r.logs[0].TargetGraphs, want) {
+		t.Errorf("TargetGraphs = %v, want %v", r.logs[0].TargetGraphs, want)
+	}
+}
+
+func TestFlushGraphWarnings_Order(t *testing.T) {
+	console := &mockConsole{}
+	r := &Restorer{console: console}
+
+	r.addGraphWarning(LogMessage{Code: "NU1701", Message: "b", LibraryID: "B"}, "net8.0")
+	r.addGraphWarning(LogMessage{Code: "NU1603", Message: "b", LibraryID: "B"}, "net8.0")
+	r.addGraphWarning(LogMessage{Code: "NU1603", Message: "a", LibraryID: "a"}, "net8.0")
+	r.addGraphWarning(LogMessage{Code: "NU1603", Message: "b", LibraryID: "B"}, "net9.0")
+	r.flushGraphWarnings()
+
+	var got []string
+	for _, log := range r.logs {
+		got = append(got, log.Code+" "+log.LibraryID+" "+strings.Join(log.TargetGraphs, ","))
+	}
+	want := []string{"NU1603 a net8.0", "NU1603 B net8.0,net9.0", "NU1701 B net8.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("logs = %v, want %v", got, want)
+	}
+	if len(console.messages) != 3 {
+		t.Errorf("printed %d warnings, want 3: %v", len(console.messages), console.messages)
+	}
+	if len(r.graphWarnings) != 0 {
+		t.Errorf("graphWarnings = %v, want none after flush", r.graphWarnings)
+	}
+}
+
+func TestRun_MultiTargetWarningPrintedOnce(t *testing.T) {
+	// 1.0.0 is not on the feed, so both graphs resolve 1.0.1 (NU1603)
+	server := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{
+		Packages: []nugethttptest.Package{
+			{ID: "Contoso.Core", Version: "1.0.1", Files: map[string][]byte{"lib/net8.0/Contoso.Core.dll": []byte("MZ")}},
+		},
+	})
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFrameworks>net8.0;net9.0</TargetFrameworks>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Contoso.Core" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+	oldNoColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = oldNoColor }()
+
+	console := &mockConsole{}
+	opts := &Options{
+		Sources:        []string{server.SourceURL()},
+		PackagesFolder: filepath.Join(tmpDir, "packages"),
+		NoCache:        true,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	var warnings []string
+	for _, msg := range console.messages {
+		if strings.Contains(msg, "warning NU1603") {
+			warnings = append(warnings, msg)
+		}
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("NU1603 printed %d times, want once: %v", len(warnings), warnings)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(warnings[0]), "was resolved. [net8.0, net9.0]") {
+		t.Errorf("warning = %q, want both target frameworks listed", warnings[0])
+	}
+
+	data, err := os.ReadFile(GetAssetsFilePath(projPath))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var assets struct {
+		Logs []AssetsLogMessage `json:"logs"`
+	}
+	if err := json.Unmarshal(data, &assets); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	fixture, err := os.ReadFile(filepath.Join("testdata", "assets_logs_nu1603.json"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	var want []AssetsLogMessage
+	if err := json.Unmarshal(fixture, &want); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(assets.Logs, want) {
+		t.Errorf("assets logs = %+v\nwant %+v", assets.Logs, want)
+	}
+}
@@ -3,10 +3,12 @@ package restore
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/willibrandon/gonuget/core/resolver"
 	"github.com/willibrandon/gonuget/frameworks"
+	"github.com/willibrandon/gonuget/version"
 )
 
 // createLocalFirstMetadataClient creates a metadata client that checks local cache first before HTTP.
@@ -15,6 +17,7 @@ import (
 func (r *Restorer) createLocalFirstMetadataClient(
 	localProvider *LocalDependencyProvider,
 	targetFramework *frameworks.NuGetFramework,
+	lockedVersions map[string]string,
 ) (resolver.PackageMetadataClient, error) {
 	// Create local-first metadata client
 	// Remote metadata client is created lazily only when needed (when local provider returns nil)
@@ -22,6 +25,7 @@ func (r *Restorer) createLocalFirstMetadataClient(
 		localProvider:   localProvider,
 		restorer:        r,
 		targetFramework: targetFramework,
+		lockedVersions:  lockedVersions,
 	}, nil
 }
 
@@ -41,6 +45,7 @@ type localFirstMetadataClient struct {
 	restorer             *Restorer
 	remoteMetadataClient resolver.PackageMetadataClient // Lazy-initialized only when needed
 	targetFramework      *frameworks.NuGetFramework
+	lockedVersions       map[string]string // Versions pinned by packages.lock.json (lowercase ID -> version)
 }
 
 // GetPackageMetadata implements resolver.PackageMetadataClient.
@@ -51,6 +56,11 @@ func (c *localFirstMetadataClient) GetPackageMetadata(
 	packageID string,
 	versionRange string,
 ) ([]*resolver.PackageDependencyInfo, error) {
+	// A package pinned by packages.lock.json resolves to its locked version, as long as the
+	// range still allows it, instead of to the best of the versions the sources have
+	// Matches NuGet.Client's RemoteWalkContext.LockFileLibraries
+	versionRange, locked := lockedRange(c.lockedVersions, packageID, versionRange)
+
 	// Try local provider first (NO HTTP!)
 	// LocalDependencyProvider now handles both exact versions and version ranges
 	// Matches NuGet.Client: LocalLibraryProviders are tried before RemoteLibraryProviders
@@ -71,6 +81,11 @@ func (c *localFirstMetadataClient) GetPackageMetadata(
 		return []*resolver.PackageDependencyInfo{info}, nil
 	}
 
+	// packageSourceMapping: the walker skips sources the package isn't mapped to
+	if !c.restorer.sourceAllowed(packageID, source) {
+		return nil, fmt.Errorf("package %s is not mapped to source %s", packageID, source)
+	}
+
 	// Not in local cache - lazy-initialize remote metadata client (only when needed)
 	// This avoids creating HTTP clients and fetching service index until we actually need it
 	if c.remoteMetadataClient == nil {
@@ -84,7 +99,33 @@ func (c *localFirstMetadataClient) GetPackageMetadata(
 	// Fall back to remote metadata client (HTTP)
 	// This will fetch from nuget.org using V3 registration API
 	// Matches NuGet.Client: RemoteLibraryProviders fallback
-	return c.remoteMetadataClient.GetPackageMetadata(ctx, source, packageID, versionRange)
+	packages, err := c.remoteMetadataClient.GetPackageMetadata(ctx, source, packageID, versionRange)
+	if err != nil || locked == nil {
+		return packages, err
+	}
+	// The walker picks among the versions returned; only the locked one may be picked
+	return slices.DeleteFunc(packages, func(pkg *resolver.PackageDependencyInfo) bool {
+		ver, err := version.Parse(pkg.Version)
+		return err != nil || !ver.Equals(locked)
+	}), nil
+}
+
+// lockedRange returns the range a package is resolved with: the exact version locked in
+// packages.lock.json when versionRange allows it, otherwise versionRange itself. locked is
+// the locked version, or nil when the package isn't pinned.
+func lockedRange(lockedVersions map[string]string, packageID, versionRange string) (string, *version.NuGetVersion) {
+	lockedVersion, ok := lockedVersions[strings.ToLower(packageID)]
+	if !ok {
+		return versionRange, nil
+	}
+	locked, err := version.Parse(lockedVersion)
+	if err != nil {
+		return versionRange, nil
+	}
+	if parsed, err := version.ParseVersionRange(versionRange); err != nil || !parsed.Satisfies(locked) {
+		return versionRange, nil
+	}
+	return "[" + locked.String() + "]", locked
 }
 
 // isFrameworkReferencePack checks if a package ID is a framework reference pack.
@@ -0,0 +1,268 @@
+package restore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+)
+
+// NoOpCacheInfo describes a project's no-op restore cache (obj/project.nuget.cache)
+// and whether the next restore of the project will be skipped.
+type NoOpCacheInfo struct {
+	ProjectPath    string
+	CachePath      string
+	AssetsFilePath string
+
+	// Exists reports whether the cache file exists; the fields below it are read from the file
+	Exists        bool
+	Version       int
+	StoredHash    string // dgspec hash of the cached restore
+	Success       bool   // Whether the cached restore succeeded
+	CachedProject string // Project path recorded in the cache file
+	PackageFiles  int    // Number of package files the cached restore installed
+
+	// CurrentHash is the dgspec hash of the project's current restore inputs
+	CurrentHash string
+
+	// MissingPackageFiles lists expected .nupkg.sha512 files that no longer exist
+	MissingPackageFiles []string
+
+	// InputChanges lists the restore inputs that changed since the cached restore.
+	// It is only known when the cached restore was made by gonuget (which keeps a
+	// snapshot of its inputs); InputChangesKnown reports whether it is.
+	InputChanges      []DgSpecChange
+	InputChangesKnown bool
+
+	// Reasons explains why the next restore is a full restore; it is empty when the
+	// next restore is a no-op.
+	Reasons []string
+}
+
+// UpToDate reports whether the next restore will be a no-op.
+func (i *NoOpCacheInfo) UpToDate() bool {
+	return len(i.Reasons) == 0
+}
+
+// DgSpecChange is a restore input that differs between the cached restore and the project.
+type DgSpecChange struct {
+	Path    string // Path of the value in the project's dgspec, e.g. "restore.sources.https://api.nuget.org/v3/index.json"
+	Stored  string // Value of the cached restore (empty if the value was added)
+	Current string // Current value (empty if the value was removed)
+}
+
+// InspectNoOpCache reports the state of a project's no-op restore cache, computed the
+// way a restore with default options evaluates it.
+func InspectNoOpCache(projectPath string) (*NoOpCacheInfo, error) {
+	return InspectNoOpCacheWithOptions(projectPath, nil)
+}
+
+// InspectNoOpCacheWithOptions is InspectNoOpCache for a restore run with opts, whose
+// packages folder and lock file settings are part of the restore inputs.
+func InspectNoOpCacheWithOptions(projectPath string, opts *Options) (*NoOpCacheInfo, error) {
+	proj, err := project.LoadProject(projectPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project: %w", err)
+	}
+
+	if opts == nil {
+		opts = &Options{}
+	}
+	opts = opts.withProjectProperties(proj.GetRestoreProperties())
+	r := &Restorer{opts: opts}
+
+	currentJSON, err := r.generateDgSpecJSON(proj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate dgspec hash: %w", err)
+	}
+
+	info := &NoOpCacheInfo{
+		ProjectPath:    proj.Path,
+		CachePath:      GetCacheFilePath(proj.Path),
+		AssetsFilePath: GetAssetsFilePath(proj.Path),
+		CurrentHash:    hashDgSpecJSON(currentJSON),
+	}
+
+	if _, err := os.Stat(info.CachePath); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+		info.Reasons = append(info.Reasons, "there is no cache file; the project has not been restored")
+		return info, nil
+	}
+
+	cache, err := LoadCacheFile(info.CachePath)
+	if err != nil {
+		return nil, err
+	}
+	info.Exists = true
+	info.Version = cache.Version
+	info.StoredHash = cache.DgSpecHash
+	info.Success = cache.Success
+	info.CachedProject = cache.ProjectFilePath
+	info.PackageFiles = len(cache.ExpectedPackageFiles)
+
+	switch {
+	case cache.Version != CacheFileVersion:
+		info.Reasons = append(info.Reasons, fmt.Sprintf("the cache file has version %d, expected %d", cache.Version, CacheFileVersion))
+	case cache.DgSpecHash == "":
+		info.Reasons = append(info.Reasons, "the cache file has no dgspec hash (it is unreadable or incomplete)")
+	}
+
+	if !cache.Success {
+		info.Reasons = append(info.Reasons, "the last restore failed")
+	}
+
+	if cache.DgSpecHash != "" && cache.DgSpecHash != info.CurrentHash {
+		info.Reasons = append(info.Reasons, "the restore inputs changed since the last restore")
+		info.InputChanges, info.InputChangesKnown = loadInputChanges(proj.Path, cache.DgSpecHash, currentJSON)
+	}
+
+	for _, pkgPath := range cache.ExpectedPackageFiles {
+		if _, err := os.Stat(pkgPath); err != nil {
+			info.MissingPackageFiles = append(info.MissingPackageFiles, pkgPath)
+		}
+	}
+	if len(info.MissingPackageFiles) > 0 {
+		info.Reasons = append(info.Reasons, fmt.Sprintf("%d package file(s) are missing from the packages folder", len(info.MissingPackageFiles)))
+	}
+
+	if !assetsFileExists(proj.Path) {
+		info.Reasons = append(info.Reasons, "project.assets.json is missing")
+	}
+
+	lockPath := r.lockFilePath(proj)
+	existingLock, err := LoadPackagesLockFile(lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packages lock file: %w", err)
+	}
+	if !r.noOpAllowed(opts.UseLockFile || existingLock != nil, existingLock) {
+		if opts.Force || opts.ForceEvaluate {
+			info.Reasons = append(info.Reasons, "the restore is forced")
+		} else {
+			info.Reasons = append(info.Reasons, fmt.Sprintf("the lock file is enabled but %s does not exist", lockPath))
+		}
+	}
+
+	return info, nil
+}
+
+// ClearNoOpCache deletes a project's no-op restore cache file, so the next restore is a
+// full restore. Downloaded packages and project.assets.json are kept. It reports
+// whether there was a cache file to delete.
+func ClearNoOpCache(projectPath string) (bool, error) {
+	err := os.Remove(GetCacheFilePath(projectPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("delete cache file: %w", err)
+	}
+	return true, nil
+}
+
+// loadInputChanges diffs the dgspec snapshot of the cached restore against the current
+// dgspec. The snapshot is only used when its hash is the one stored in the cache file.
+func loadInputChanges(projectPath, storedHash string, currentJSON []byte) ([]DgSpecChange, bool) {
+	storedJSON, err := os.ReadFile(GetDgSpecSnapshotPath(projectPath))
+	if err != nil || hashDgSpecJSON(storedJSON) != storedHash {
+		return nil, false
+	}
+
+	changes, err := diffDgSpec(storedJSON, currentJSON)
+	if err != nil {
+		return nil, false
+	}
+	return changes, true
+}
+
+// diffDgSpec lists the values that differ between the package specs of two dgspec documents.
+func diffDgSpec(storedJSON, currentJSON []byte) ([]DgSpecChange, error) {
+	stored, err := flattenDgSpec(storedJSON)
+	if err != nil {
+		return nil, err
+	}
+	current, err := flattenDgSpec(currentJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []DgSpecChange
+	for path, value := range stored {
+		if currentValue, ok := current[path]; !ok || currentValue != value {
+			changes = append(changes, DgSpecChange{Path: path, Stored: value, Current: currentValue})
+		}
+	}
+	for path, value := range current {
+		if _, ok := stored[path]; !ok {
+			changes = append(changes, DgSpecChange{Path: path, Current: value})
+		}
+	}
+
+	slices.SortFunc(changes, func(a, b DgSpecChange) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return changes, nil
+}
+
+// flattenDgSpec maps the value paths of the project's package spec in a dgspec
+// document to their values.
+func flattenDgSpec(data []byte) (map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc struct {
+		Projects map[string]any `json:"projects"`
+	}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse dgspec: %w", err)
+	}
+
+	// A dgspec written for a restore holds exactly one project
+	values := make(map[string]string)
+	for _, spec := range doc.Projects {
+		flattenJSONValue("", spec, values)
+	}
+	return values, nil
+}
+
+// flattenJSONValue adds the leaf values of v to values, keyed by their dotted path.
+// Arrays of plain values are kept as one comma-separated value.
+func flattenJSONValue(path string, v any, values map[string]string) {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			values[path] = "{}"
+		}
+		for key, child := range v {
+			flattenJSONValue(join(key), child, values)
+		}
+	case []any:
+		plain := make([]string, 0, len(v))
+		for i, child := range v {
+			switch child.(type) {
+			case map[string]any, []any:
+				flattenJSONValue(join(fmt.Sprint(i)), child, values)
+			default:
+				plain = append(plain, fmt.Sprint(child))
+			}
+		}
+		if len(plain) > 0 || len(v) == 0 {
+			values[path] = "[" + strings.Join(plain, ", ") + "]"
+		}
+	default:
+		values[path] = fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,207 @@
+package restore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// noOpCacheTest restores a project referencing Lock.Pkg from a test feed.
+type noOpCacheTest struct {
+	projPath string
+	opts     Options
+}
+
+func newNoOpCacheTest(t *testing.T) *noOpCacheTest {
+	t.Helper()
+
+	feed := newLockTestFeed(t)
+	feed.publish(t, "1.0.0")
+	feed.publish(t, "2.0.0")
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	t.Cleanup(func() { DefaultTTYDetector = oldDetector })
+
+	tmpDir := t.TempDir()
+	test := &noOpCacheTest{
+		projPath: filepath.Join(tmpDir, "app.csproj"),
+		opts: Options{
+			Sources:        []string{feed.URL + "/index.json"},
+			PackagesFolder: filepath.Join(tmpDir, "packages"),
+			NoCache:        true,
+		},
+	}
+	writeFloatTestProject(t, test.projPath, "1.0.0", false)
+	return test
+}
+
+func (c *noOpCacheTest) restore(opts Options) error {
+	console := &mockConsole{}
+	return Run(context.Background(), []string{c.projPath}, &opts, console)
+}
+
+func (c *noOpCacheTest) inspect(t *testing.T) *NoOpCacheInfo {
+	t.Helper()
+
+	info, err := InspectNoOpCacheWithOptions(c.projPath, &c.opts)
+	if err != nil {
+		t.Fatalf("InspectNoOpCacheWithOptions() error = %v", err)
+	}
+	return info
+}
+
+func TestInspectNoOpCache_NotRestored(t *testing.T) {
+	test := newNoOpCacheTest(t)
+
+	info := test.inspect(t)
+	if info.Exists || info.UpToDate() {
+		t.Errorf("Exists = %v, UpToDate() = %v, want false, false", info.Exists, info.UpToDate())
+	}
+	if info.CachePath != GetCacheFilePath(test.projPath) {
+		t.Errorf("CachePath = %q, want %q", info.CachePath, GetCacheFilePath(test.projPath))
+	}
+	if info.CurrentHash == "" {
+		t.Error("CurrentHash is empty")
+	}
+	if len(info.Reasons) != 1 || !strings.Contains(info.Reasons[0], "no cache file") {
+		t.Errorf("Reasons = %q, want the missing cache file", info.Reasons)
+	}
+}
+
+func TestInspectNoOpCache_ReportsChangedInputs(t *testing.T) {
+	test := newNoOpCacheTest(t)
+	if err := test.restore(test.opts); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	info := test.inspect(t)
+	if !info.UpToDate() {
+		t.Fatalf("UpToDate() = false after a restore, reasons: %q", info.Reasons)
+	}
+	if !info.Exists || !info.Success || info.StoredHash != info.CurrentHash || info.PackageFiles != 1 {
+		t.Errorf("info = %+v, want a successful cache matching the current hash", info)
+	}
+
+	// Changing the referenced version changes the inputs
+	writeFloatTestProject(t, test.projPath, "2.0.0", false)
+
+	info = test.inspect(t)
+	if info.UpToDate() || info.StoredHash == info.CurrentHash {
+		t.Fatalf("UpToDate() = true after the project changed (hashes %s, %s)", info.StoredHash, info.CurrentHash)
+	}
+	if !info.InputChangesKnown {
+		t.Fatal("InputChangesKnown = false for a restore made by gonuget")
+	}
+	want := DgSpecChange{
+		Path:    "frameworks.net8.0.dependencies.Lock.Pkg.version",
+		Stored:  "[1.0.0, )",
+		Current: "[2.0.0, )",
+	}
+	if len(info.InputChanges) != 1 || info.InputChanges[0] != want {
+		t.Errorf("InputChanges = %+v, want [%+v]", info.InputChanges, want)
+	}
+}
+
+func TestInspectNoOpCache_MissingOutputs(t *testing.T) {
+	test := newNoOpCacheTest(t)
+	if err := test.restore(test.opts); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	hashPath := packageHashPath(test.opts.PackagesFolder, "Lock.Pkg", "1.0.0")
+	if err := os.Remove(hashPath); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := os.Remove(GetAssetsFilePath(test.projPath)); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	info := test.inspect(t)
+	if len(info.MissingPackageFiles) != 1 || info.MissingPackageFiles[0] != hashPath {
+		t.Errorf("MissingPackageFiles = %q, want [%s]", info.MissingPackageFiles, hashPath)
+	}
+	reasons := strings.Join(info.Reasons, "\n")
+	if !strings.Contains(reasons, "1 package file(s) are missing") || !strings.Contains(reasons, "project.assets.json is missing") {
+		t.Errorf("Reasons = %q", info.Reasons)
+	}
+	if info.StoredHash != info.CurrentHash {
+		t.Errorf("StoredHash = %s, want the current hash %s", info.StoredHash, info.CurrentHash)
+	}
+}
+
+func TestClearNoOpCache(t *testing.T) {
+	test := newNoOpCacheTest(t)
+	if err := test.restore(test.opts); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	deleted, err := ClearNoOpCache(test.projPath)
+	if err != nil || !deleted {
+		t.Fatalf("ClearNoOpCache() = %v, %v, want true, nil", deleted, err)
+	}
+	if _, err := os.Stat(GetCacheFilePath(test.projPath)); !os.IsNotExist(err) {
+		t.Errorf("cache file still exists: %v", err)
+	}
+
+	// Only the cache file is deleted
+	if _, err := os.Stat(GetAssetsFilePath(test.projPath)); err != nil {
+		t.Errorf("project.assets.json was deleted: %v", err)
+	}
+	if _, err := os.Stat(packageHashPath(test.opts.PackagesFolder, "Lock.Pkg", "1.0.0")); err != nil {
+		t.Errorf("package was deleted: %v", err)
+	}
+
+	deleted, err = ClearNoOpCache(test.projPath)
+	if err != nil || deleted {
+		t.Errorf("second ClearNoOpCache() = %v, %v, want false, nil", deleted, err)
+	}
+}
+
+func TestRun_FailedAssetsWriteNeverLeavesSuccessfulCache(t *testing.T) {
+	test := newNoOpCacheTest(t)
+	if err := test.restore(test.opts); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// Fail the commit between installing the packages and writing the cache file:
+	// project.assets.json can't be written over a directory
+	assetsPath := GetAssetsFilePath(test.projPath)
+	if err := os.Remove(assetsPath); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := os.Mkdir(assetsPath, 0755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+
+	forced := test.opts
+	forced.Force = true
+	err := test.restore(forced)
+	if err == nil || !strings.Contains(err.Error(), "project.assets.json") {
+		t.Fatalf("Run() error = %v, want an assets file error", err)
+	}
+
+	cache, err := LoadCacheFile(GetCacheFilePath(test.projPath))
+	if err != nil {
+		t.Fatalf("LoadCacheFile() error = %v", err)
+	}
+	if cache.Success {
+		t.Error("cache file claims success after the assets file failed to write")
+	}
+
+	// The next restore is a full restore that succeeds once the assets file can be written
+	if err := os.Remove(assetsPath); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if info := test.inspect(t); info.UpToDate() {
+		t.Error("UpToDate() = true after a failed restore")
+	}
+	if err := test.restore(test.opts); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if info := test.inspect(t); !info.UpToDate() {
+		t.Errorf("UpToDate() = false after a successful restore, reasons: %q", info.Reasons)
+	}
+}
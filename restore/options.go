@@ -1,12 +1,125 @@
 package restore
 
+import (
+	"time"
+
+	"github.com/willibrandon/gonuget/auth"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/observability"
+)
+
+// DefaultMaxConcurrentDownloads is the number of packages installed at once when
+// Options.MaxConcurrentDownloads is not set. Matches the 16 concurrent installs of
+// NuGet.Client's ProjectRestoreCommand.
+const DefaultMaxConcurrentDownloads = 16
+
 // Options holds restore configuration.
 type Options struct {
+	// Sources are the package sources to restore from. When empty, the enabled sources of
+	// ConfigFile or of the NuGet.config hierarchy of the project directory are used.
 	Sources        []string
 	PackagesFolder string
 	ConfigFile     string
-	Force          bool
 	NoCache        bool
 	NoDependencies bool
-	Verbosity      string
+	Verbosity      observability.Verbosity
+
+	// AllowPrereleaseEverywhere lets every dependency range resolve to a prerelease version.
+	// By default, as in NuGet, only a range with a prerelease bound or a dependency of a
+	// package that resolved to a prerelease may. dotnet has no equivalent setting.
+	AllowPrereleaseEverywhere bool
+
+	// SourceProtocolVersions maps a source URL to the protocolVersion configured for it in
+	// NuGet.config ("2" or "3"). Sources without an entry detect their protocol.
+	SourceProtocolVersions map[string]string
+
+	// CredentialProvider supplies credentials when a source answers 401. When nil, the
+	// packageSourceCredentials of the NuGet.config hierarchy are used, then any credential
+	// provider plugins (NUGET_PLUGIN_PATHS or ~/.nuget/plugins).
+	CredentialProvider auth.CredentialProvider
+
+	// PackageSourceMapping restricts the sources each package is restored from. When nil,
+	// the packageSourceMapping of the NuGet.config hierarchy is used.
+	PackageSourceMapping *config.SourceMapping
+	// SourceNames maps a source URL to its configured name, which PackageSourceMapping
+	// refers to. A source without an entry is known by its URL.
+	SourceNames map[string]string
+
+	// Force resolves all dependencies even if the last restore succeeded, bypassing the
+	// no-op cache. Packages missing from the packages folder are downloaded; a
+	// packages.lock.json that matches the project is still honored.
+	Force bool
+	// ForceEvaluate also re-evaluates packages.lock.json: floating versions are re-floated
+	// and the lock file is regenerated even when it matches the project. Implies Force.
+	ForceEvaluate bool
+
+	// UseLockFile writes packages.lock.json and honors it on later restores
+	// (RestorePackagesWithLockFile). A lock file that already exists is always used.
+	UseLockFile bool
+	// LockedMode fails with NU1004 instead of updating packages.lock.json (RestoreLockedMode).
+	LockedMode bool
+	// LockFilePath overrides the lock file location (NuGetLockFilePath).
+	// Default: packages.lock.json in the project directory.
+	LockFilePath string
+
+	// IgnoreFailedSources reports unreachable sources as warnings (NU1801) and
+	// restores from the remaining sources instead of failing with NU1301.
+	// Failed sources are removed from Sources for the rest of the restore.
+	IgnoreFailedSources bool
+
+	// VerifySourceHashes downloads newly installed packages from every configured
+	// source that has them and warns (GN1001) when their content hashes differ.
+	VerifySourceHashes bool
+	// StrictSourceHashes turns a source hash mismatch into a restore error.
+	// Implies VerifySourceHashes.
+	StrictSourceHashes bool
+
+	// LegacyLogFormat also prints the nuget.exe restore milestones ("Restoring packages for X...",
+	// "Restore completed in 1.2 sec for X.") for build wrappers that parse them.
+	LegacyLogFormat bool
+
+	// LockTimeout bounds how long a package install waits for another process installing
+	// the same package. Zero uses packaging.DefaultLockTimeout.
+	LockTimeout time.Duration
+
+	// MaxConcurrentDownloads bounds how many packages are downloaded and extracted at
+	// once. Zero uses DefaultMaxConcurrentDownloads.
+	MaxConcurrentDownloads int
+
+	// FailFast stops a solution restore at the first project that fails. By default the
+	// remaining projects are still restored.
+	FailFast bool
+
+	// resolverCaches shares package metadata lookups between the projects of a solution
+	// restore; nil for a single project.
+	resolverCaches *resolverCaches
+}
+
+// maxConcurrentDownloads returns the download concurrency, applying the default.
+func (o *Options) maxConcurrentDownloads() int {
+	if o.MaxConcurrentDownloads > 0 {
+		return o.MaxConcurrentDownloads
+	}
+	return DefaultMaxConcurrentDownloads
+}
+
+// withProjectProperties returns a copy of the options with the project's restore
+// properties (RestorePackagesPath, RestoreNoCache, RestoreIgnoreFailedSources and the
+// lock file properties) applied.
+// Command-line values win, as MSBuild global properties do; a flag can only turn a
+// boolean property on.
+func (o *Options) withProjectProperties(props project.RestoreProperties) *Options {
+	merged := *o
+	if merged.PackagesFolder == "" {
+		merged.PackagesFolder = props.PackagesPath
+	}
+	merged.NoCache = merged.NoCache || props.NoCache
+	merged.IgnoreFailedSources = merged.IgnoreFailedSources || props.IgnoreFailedSources
+	if merged.LockFilePath == "" {
+		merged.LockFilePath = props.LockFilePath
+	}
+	merged.UseLockFile = merged.UseLockFile || props.UseLockFile
+	merged.LockedMode = merged.LockedMode || props.LockedMode
+	return &merged
 }
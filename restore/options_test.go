@@ -0,0 +1,44 @@
+package restore
+
+import (
+	"testing"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+)
+
+func TestOptions_WithProjectProperties(t *testing.T) {
+	props := project.RestoreProperties{
+		PackagesPath:        "/project/packages",
+		NoCache:             true,
+		IgnoreFailedSources: true,
+		UseLockFile:         true,
+		LockFilePath:        "/project/app.lock.json",
+		LockedMode:          true,
+	}
+
+	// Project properties apply when the command line doesn't set them
+	merged := (&Options{}).withProjectProperties(props)
+	if merged.PackagesFolder != "/project/packages" || !merged.NoCache || !merged.IgnoreFailedSources {
+		t.Errorf("withProjectProperties() = %+v, want the project properties", merged)
+	}
+	if !merged.UseLockFile || !merged.LockedMode || merged.LockFilePath != "/project/app.lock.json" {
+		t.Errorf("withProjectProperties() = %+v, want the lock file properties", merged)
+	}
+
+	// --packages wins over RestorePackagesPath
+	cli := &Options{PackagesFolder: "/cli/packages"}
+	if merged := cli.withProjectProperties(props); merged.PackagesFolder != "/cli/packages" {
+		t.Errorf("PackagesFolder = %q, want the command-line value", merged.PackagesFolder)
+	}
+
+	// Flags turn booleans on even when the project leaves them off
+	cli = &Options{NoCache: true, IgnoreFailedSources: true}
+	if merged := cli.withProjectProperties(project.RestoreProperties{}); !merged.NoCache || !merged.IgnoreFailedSources {
+		t.Errorf("withProjectProperties() = %+v, want command-line booleans kept", merged)
+	}
+
+	// The receiver is not modified
+	if cli.PackagesFolder != "" {
+		t.Errorf("receiver modified: %+v", cli)
+	}
+}
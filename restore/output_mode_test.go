@@ -11,6 +11,8 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/willibrandon/gonuget/observability"
 )
 
 // mockTTYDetector allows simulating TTY or piped mode for tests
@@ -202,7 +204,7 @@ func TestRun_TTYMode_Output(t *testing.T) {
 	ctx := context.Background()
 	opts := &Options{
 		Sources:   []string{"https://api.nuget.org/v3/index.json"},
-		Verbosity: "detailed", // Enable detailed output to see "Determining projects" message
+		Verbosity: observability.VerbosityDetailed, // Enable detailed output to see "Determining projects" message
 	}
 
 	err := Run(ctx, []string{projPath}, opts, console)
@@ -289,7 +291,7 @@ func TestRun_PipedMode_Output(t *testing.T) {
 	ctx := context.Background()
 	opts := &Options{
 		Sources:   []string{"https://api.nuget.org/v3/index.json"},
-		Verbosity: "detailed", // Enable detailed output to see "Committing restore" message
+		Verbosity: observability.VerbosityDetailed, // Enable detailed output to see "Committing restore" message
 	}
 
 	err := Run(ctx, []string{projPath}, opts, console)
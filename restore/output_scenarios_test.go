@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/willibrandon/gonuget/observability"
 )
 
 // TestOutputScenarios provides comprehensive coverage of all scenarios from RESTORE-OUTPUT-TESTING.md
@@ -72,13 +74,13 @@ func TestOutputScenarios(t *testing.T) {
 
 	verbosityLevels := []struct {
 		name      string
-		verbosity string
+		verbosity observability.Verbosity
 	}{
-		{"Quiet", "quiet"},
-		{"Minimal", "minimal"},
-		{"Normal", "normal"},
-		{"Detailed", "detailed"},
-		{"Diagnostic", "diagnostic"},
+		{"Quiet", observability.VerbosityQuiet},
+		{"Minimal", observability.VerbosityMinimal},
+		{"Normal", observability.VerbosityNormal},
+		{"Detailed", observability.VerbosityDetailed},
+		{"Diagnostic", observability.VerbosityDiagnostic},
 	}
 
 	outputModes := []struct {
@@ -157,7 +159,7 @@ func TestOutputScenarios(t *testing.T) {
 						}
 
 						// In non-quiet mode, check for "failed" message
-						if verbosity.verbosity != "quiet" {
+						if verbosity.verbosity != observability.VerbosityQuiet {
 							foundFailed := false
 							for _, msg := range console.messages {
 								if strings.Contains(msg, "failed") {
@@ -193,7 +195,7 @@ func TestOutputScenarios(t *testing.T) {
 						}
 
 						// In non-quiet mode, check for success indicators
-						if verbosity.verbosity != "quiet" {
+						if verbosity.verbosity != observability.VerbosityQuiet {
 							if mode.isTTY {
 								// TTY mode always shows "succeeded"
 								foundSuccess := false
@@ -222,7 +224,7 @@ func TestOutputScenarios(t *testing.T) {
 						}
 
 						// Detailed mode specific checks
-						if verbosity.verbosity == "detailed" {
+						if verbosity.verbosity == observability.VerbosityDetailed {
 							if mode.isTTY {
 								// TTY detailed mode should show "Determining projects"
 								foundDetermining := false
@@ -251,7 +253,7 @@ func TestOutputScenarios(t *testing.T) {
 						}
 
 						// Diagnostic mode specific checks
-						if verbosity.verbosity == "diagnostic" {
+						if verbosity.verbosity == observability.VerbosityDiagnostic {
 							// Diagnostic mode should show download-related messages
 							// (Acquiring, GET, OK, CACHE) - but only if packages were actually downloaded
 							// For cached packages, we won't see these, so this is optional
@@ -299,7 +301,7 @@ func TestVerbosityMinimal(t *testing.T) {
 	ctx := context.Background()
 	opts := &Options{
 		Sources:   []string{"https://api.nuget.org/v3/index.json"},
-		Verbosity: "minimal",
+		Verbosity: observability.VerbosityMinimal,
 	}
 
 	err = Run(ctx, []string{projectPath}, opts, console)
@@ -369,7 +371,7 @@ func TestVerbosityQuiet(t *testing.T) {
 	ctx := context.Background()
 	opts := &Options{
 		Sources:   []string{"https://api.nuget.org/v3/index.json"},
-		Verbosity: "quiet",
+		Verbosity: observability.VerbosityQuiet,
 	}
 
 	err = Run(ctx, []string{projectPath}, opts, console)
@@ -434,7 +436,7 @@ func TestErrorScenarios(t *testing.T) {
 					ctx := context.Background()
 					opts := &Options{
 						Sources:   []string{"https://api.nuget.org/v3/index.json"},
-						Verbosity: "normal",
+						Verbosity: observability.VerbosityNormal,
 					}
 
 					err := Run(ctx, []string{projectPath}, opts, console)
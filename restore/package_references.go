@@ -0,0 +1,281 @@
+package restore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+)
+
+// Asset flags of IncludeAssets, ExcludeAssets and PrivateAssets metadata.
+// Matches NuGet.LibraryModel.LibraryIncludeFlags.
+const (
+	includeRuntime uint16 = 1 << iota
+	includeCompile
+	includeBuild
+	includeNative
+	includeContentFiles
+	includeAnalyzers
+	includeBuildTransitive
+
+	includeNone uint16 = 0
+	includeAll         = includeRuntime | includeCompile | includeBuild | includeNative |
+		includeContentFiles | includeAnalyzers | includeBuildTransitive
+
+	// defaultSuppressParent is what flows no further than the referencing project
+	// when PrivateAssets is not set. Matches LibraryIncludeFlagUtils.DefaultSuppressParent.
+	defaultSuppressParent = includeContentFiles | includeAnalyzers | includeBuild
+)
+
+// includeFlagNames are the flag names in the order NuGet writes them.
+var includeFlagNames = []struct {
+	name string
+	flag uint16
+}{
+	{"Runtime", includeRuntime},
+	{"Compile", includeCompile},
+	{"Build", includeBuild},
+	{"Native", includeNative},
+	{"ContentFiles", includeContentFiles},
+	{"Analyzers", includeAnalyzers},
+	{"BuildTransitive", includeBuildTransitive},
+}
+
+// parseIncludeFlags parses asset metadata such as "runtime; build; analyzers".
+// Empty metadata yields def; unknown names are ignored like NuGet does.
+func parseIncludeFlags(metadata string, def uint16) uint16 {
+	if strings.TrimSpace(metadata) == "" {
+		return def
+	}
+
+	var flags uint16
+	for name := range strings.SplitSeq(metadata, ";") {
+		name = strings.TrimSpace(name)
+		switch {
+		case strings.EqualFold(name, "all"):
+			flags |= includeAll
+		case strings.EqualFold(name, "none"):
+		default:
+			for _, f := range includeFlagNames {
+				if strings.EqualFold(name, f.name) {
+					flags |= f.flag
+				}
+			}
+		}
+	}
+	return flags
+}
+
+// formatIncludeFlags writes flags the way dgspec and project.assets.json do,
+// e.g. "Runtime, Build, Native, ContentFiles, Analyzers".
+func formatIncludeFlags(flags uint16) string {
+	switch flags {
+	case includeNone:
+		return "None"
+	case includeAll:
+		return "All"
+	}
+
+	var names []string
+	for _, f := range includeFlagNames {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// includeAssets returns the assets a package reference consumes: IncludeAssets without ExcludeAssets.
+func includeAssets(ref project.PackageReference) uint16 {
+	return parseIncludeFlags(ref.IncludeAssets, includeAll) &^ parseIncludeFlags(ref.ExcludeAssets, includeNone)
+}
+
+// suppressParent returns the assets of a package reference that don't flow to referencing projects.
+func suppressParent(ref project.PackageReference) uint16 {
+	return parseIncludeFlags(ref.PrivateAssets, defaultSuppressParent)
+}
+
+// packageReferences returns the PackageReference items restore uses for the project: one
+// for each GlobalPackageReference of its Directory.Packages.props, followed by its own.
+// Under Central Package Management the project's items get their versions from the
+// PackageVersion items of Directory.Packages.props, or from their VersionOverride.
+// When several items reference the same package, the first one is used and every item
+// for that package is returned in duplicates.
+func packageReferences(proj *project.Project) (refs, duplicates []project.PackageReference, err error) {
+	props, err := proj.CentralPackageVersions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var all []project.PackageReference
+	if props != nil {
+		for _, globalRef := range props.GetGlobalPackageReferences() {
+			all = append(all, globalRef.PackageReference())
+		}
+	}
+	for _, ref := range proj.GetPackageReferences() {
+		all = append(all, centralPackageReference(ref, props))
+	}
+
+	count := make(map[string]int, len(all))
+	for _, ref := range all {
+		count[strings.ToLower(ref.Include)]++
+	}
+
+	seen := make(map[string]bool, len(all))
+	for _, ref := range all {
+		id := strings.ToLower(ref.Include)
+		if count[id] > 1 {
+			duplicates = append(duplicates, ref)
+		}
+		if !seen[id] {
+			seen[id] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs, duplicates, nil
+}
+
+// warnDuplicatePackageReferences logs NU1504 for a project with several PackageReference
+// items for the same package, such as a package it references both directly and
+// through a GlobalPackageReference.
+func (r *Restorer) warnDuplicatePackageReferences(proj *project.Project) {
+	_, duplicates, err := packageReferences(proj)
+	if err != nil || len(duplicates) == 0 {
+		return
+	}
+
+	items := make([]string, len(duplicates))
+	for i, ref := range duplicates {
+		items[i] = strings.TrimSpace(ref.Include + " " + ref.Version)
+	}
+
+	log := LogMessage{
+		Code:  ErrorCodeDuplicatePackageReference,
+		Level: "Warning",
+		Message: fmt.Sprintf("Duplicate 'PackageReference' items found. Remove the duplicate items or use the Update functionality to ensure a consistent restore behavior. The duplicate 'PackageReference' items are: %s.",
+			strings.Join(items, ", ")),
+		ProjectPath: proj.Path,
+		FilePath:    proj.Path,
+	}
+	r.addLog(log)
+	r.printWarningLog(&log)
+}
+
+// PackageReferences returns the PackageReference items restore uses for the project,
+// with GlobalPackageReference items and the versions of Central Package Management applied.
+func PackageReferences(proj *project.Project) ([]project.PackageReference, error) {
+	refs, _, err := packageReferences(proj)
+	return refs, err
+}
+
+// centralPackageReference returns ref with the version Central Package Management gives
+// it: its VersionOverride, or else the version of the package's PackageVersion item.
+// Items that define their own version (which is NU1008 unless the SDK defined them
+// implicitly) keep it. Without central versions VersionOverride has no meaning and is
+// dropped.
+func centralPackageReference(ref project.PackageReference, props *project.DirectoryPackagesProps) project.PackageReference {
+	if props == nil {
+		ref.VersionOverride = ""
+		return ref
+	}
+	if ref.Version != "" {
+		return ref
+	}
+	if ref.VersionOverride != "" {
+		ref.Version = ref.VersionOverride
+	} else {
+		ref.Version = props.GetPackageVersion(ref.Include)
+	}
+	return ref
+}
+
+// centrallyManagedPackages returns the lowercased IDs of the project's packages whose
+// version comes from a PackageVersion item, rather than from the PackageReference itself
+// or its VersionOverride.
+func centrallyManagedPackages(proj *project.Project) map[string]bool {
+	props, err := proj.CentralPackageVersions()
+	if err != nil || props == nil {
+		return nil
+	}
+
+	managed := make(map[string]bool)
+	for _, ref := range proj.GetPackageReferences() {
+		if ref.Version == "" && ref.VersionOverride == "" && props.GetPackageVersion(ref.Include) != "" {
+			managed[strings.ToLower(ref.Include)] = true
+		}
+	}
+	return managed
+}
+
+// centralTransitivePins returns the versions Central Package Management pins transitive
+// packages to, keyed by lowercased ID: with CentralPackageTransitivePinningEnabled, the
+// version range of every PackageVersion item for a package the project doesn't reference
+// directly. Returns nil when pinning is not enabled.
+func centralTransitivePins(proj *project.Project, packageRefs []project.PackageReference) map[string]string {
+	props, err := proj.CentralPackageVersions()
+	if err != nil || props == nil || !proj.IsCentralPackageTransitivePinningEnabled(props) {
+		return nil
+	}
+
+	direct := make(map[string]bool, len(packageRefs))
+	for _, ref := range packageRefs {
+		direct[strings.ToLower(ref.Include)] = true
+	}
+
+	pins := make(map[string]string)
+	for _, pv := range props.GetPackageVersions() {
+		id := strings.ToLower(pv.Include)
+		if !direct[id] && pv.Version != "" {
+			pins[id] = normalizeRequestedRange(pv.Version)
+		}
+	}
+	return pins
+}
+
+// centralPackageErrors returns the errors of a project whose PackageReference items don't
+// follow Central Package Management: items that define their own version (NU1008),
+// implicitly defined items that also have a PackageVersion (NU1009), items without any
+// version (NU1010) and items with a VersionOverride while overrides are disabled
+// (NU1013). Matches the checks of NuGet's RestoreCommand.
+func centralPackageErrors(proj *project.Project) ([]*NuGetError, error) {
+	props, err := proj.CentralPackageVersions()
+	if err != nil || props == nil {
+		return nil, err
+	}
+	overrideEnabled := proj.IsCentralPackageVersionOverrideEnabled(props)
+
+	var inlineVersions, implicitVersions, missingVersions, overrides []string
+	for _, ref := range proj.GetPackageReferences() {
+		if ref.VersionOverride != "" && !overrideEnabled {
+			overrides = append(overrides, ref.Include)
+		}
+
+		central := props.GetPackageVersion(ref.Include)
+		switch {
+		case strings.EqualFold(ref.IsImplicitlyDefined, "true"):
+			if central != "" {
+				implicitVersions = append(implicitVersions, ref.Include)
+			}
+		case ref.Version != "":
+			inlineVersions = append(inlineVersions, ref.Include)
+		case ref.VersionOverride == "" && central == "":
+			missingVersions = append(missingVersions, ref.Include)
+		}
+	}
+
+	var errs []*NuGetError
+	if len(inlineVersions) > 0 {
+		errs = append(errs, NewCentralPackageVersionDefinedError(proj.Path, inlineVersions))
+	}
+	if len(implicitVersions) > 0 {
+		errs = append(errs, NewImplicitCentralPackageVersionError(proj.Path, implicitVersions))
+	}
+	if len(missingVersions) > 0 {
+		errs = append(errs, NewMissingCentralPackageVersionError(proj.Path, missingVersions))
+	}
+	for _, id := range overrides {
+		errs = append(errs, NewVersionOverrideDisabledError(proj.Path, id))
+	}
+	return errs, nil
+}
@@ -0,0 +1,485 @@
+package restore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+)
+
+func TestParseIncludeFlags(t *testing.T) {
+	tests := []struct {
+		metadata string
+		def      uint16
+		want     string
+	}{
+		{metadata: "", def: includeAll, want: "All"},
+		{metadata: "", def: defaultSuppressParent, want: "Build, ContentFiles, Analyzers"},
+		{metadata: "all", def: includeNone, want: "All"},
+		{metadata: "none", def: includeAll, want: "None"},
+		{metadata: "Runtime;Build;Native;ContentFiles;Analyzers", def: includeAll, want: "Runtime, Build, Native, ContentFiles, Analyzers"},
+		{metadata: " analyzers ; compile; unknown", def: includeAll, want: "Compile, Analyzers"},
+	}
+
+	for _, tt := range tests {
+		if got := formatIncludeFlags(parseIncludeFlags(tt.metadata, tt.def)); got != tt.want {
+			t.Errorf("parseIncludeFlags(%q) = %q, want %q", tt.metadata, got, tt.want)
+		}
+	}
+}
+
+func TestNewDependencyInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  project.PackageReference
+		want DependencyInfo
+	}{
+		{
+			name: "defaults",
+			ref:  project.PackageReference{Include: "Newtonsoft.Json", Version: "13.0.3"},
+			want: DependencyInfo{Target: "Package", Version: "13.0.3"},
+		},
+		{
+			name: "global package reference",
+			ref:  project.GlobalPackageReference{Include: "StyleCop.Analyzers", Version: "1.1.118"}.PackageReference(),
+			want: DependencyInfo{
+				Include:        "Runtime, Build, Native, ContentFiles, Analyzers",
+				SuppressParent: "All",
+				Target:         "Package",
+				Version:        "1.1.118",
+			},
+		},
+		{
+			name: "exclude assets",
+			ref:  project.PackageReference{Include: "A", Version: "1.0.0", ExcludeAssets: "compile;runtime"},
+			want: DependencyInfo{
+				Include: "Build, Native, ContentFiles, Analyzers, BuildTransitive",
+				Target:  "Package",
+				Version: "1.0.0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newDependencyInfo(tt.ref); got != tt.want {
+				t.Errorf("newDependencyInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeGlobalPackageReferenceProject writes a project below a Directory.Packages.props that
+// manages versions centrally and references StyleCop.Analyzers from every project.
+func writeGlobalPackageReferenceProject(t *testing.T, packageReferences string) *project.Project {
+	t.Helper()
+
+	root := t.TempDir()
+	props := `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <GlobalPackageReference Include="StyleCop.Analyzers" Version="1.1.118" IncludeAssets="compile" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(filepath.Join(root, "Directory.Packages.props"), []byte(props), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	projPath := filepath.Join(root, "App.csproj")
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>` + packageReferences + `
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	proj, err := project.LoadProject(projPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proj
+}
+
+func TestPackageReferences_GlobalPackageReference(t *testing.T) {
+	proj := writeGlobalPackageReferenceProject(t, `
+    <PackageReference Include="Newtonsoft.Json" Version="13.0.3" />`)
+
+	refs, duplicates, err := packageReferences(proj)
+	if err != nil {
+		t.Fatalf("packageReferences() error = %v", err)
+	}
+	if len(duplicates) != 0 {
+		t.Errorf("duplicates = %v, want none", duplicates)
+	}
+
+	want := []project.PackageReference{
+		{Include: "StyleCop.Analyzers", Version: "1.1.118", PrivateAssets: "All", IncludeAssets: "Runtime;Build;Native;ContentFiles;Analyzers"},
+		{Include: "Newtonsoft.Json", Version: "13.0.3"},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("packageReferences() = %+v, want %+v", refs, want)
+	}
+}
+
+func TestPackageReferences_Duplicates(t *testing.T) {
+	proj := writeGlobalPackageReferenceProject(t, `
+    <PackageReference Include="stylecop.analyzers" Version="1.2.0-beta.556" />
+    <PackageReference Include="Newtonsoft.Json" Version="13.0.3" />`)
+
+	refs, duplicates, err := packageReferences(proj)
+	if err != nil {
+		t.Fatalf("packageReferences() error = %v", err)
+	}
+
+	// The GlobalPackageReference comes first and wins
+	if len(refs) != 2 || refs[0].Version != "1.1.118" {
+		t.Errorf("packageReferences() = %+v, want the GlobalPackageReference and Newtonsoft.Json", refs)
+	}
+	if len(duplicates) != 2 || duplicates[0].Include != "StyleCop.Analyzers" || duplicates[1].Include != "stylecop.analyzers" {
+		t.Errorf("duplicates = %+v, want both StyleCop.Analyzers items", duplicates)
+	}
+
+	console := &mockConsole{}
+	r := NewRestorer(&Options{}, console)
+	r.warnDuplicatePackageReferences(proj)
+	if len(r.logs) != 1 || r.logs[0].Code != ErrorCodeDuplicatePackageReference || r.logs[0].Level != "Warning" {
+		t.Fatalf("logs = %+v, want one NU1504 warning", r.logs)
+	}
+	wantMessage := "Duplicate 'PackageReference' items found. Remove the duplicate items or use the Update functionality to ensure a consistent restore behavior. The duplicate 'PackageReference' items are: StyleCop.Analyzers 1.1.118, stylecop.analyzers 1.2.0-beta.556."
+	if r.logs[0].Message != wantMessage {
+		t.Errorf("message = %q, want %q", r.logs[0].Message, wantMessage)
+	}
+	if len(console.messages) != 1 || !strings.Contains(console.messages[0], "warning NU1504") {
+		t.Errorf("console = %v, want the NU1504 warning", console.messages)
+	}
+}
+
+// writeCentralPackagesProject writes a project using Central Package Management with
+// packageVersions in its Directory.Packages.props and loads it.
+func writeCentralPackagesProject(t *testing.T, packageVersions, packageReferences string) *project.Project {
+	t.Helper()
+
+	root := t.TempDir()
+	props := `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>` + packageVersions + `
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(filepath.Join(root, "Directory.Packages.props"), []byte(props), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The project lives below the props file, which is found by walking up
+	projPath := filepath.Join(root, "src", "App", "App.csproj")
+	if err := os.MkdirAll(filepath.Dir(projPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>` + packageReferences + `
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	proj, err := project.LoadProject(projPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proj
+}
+
+func TestPackageReferences_CentralVersions(t *testing.T) {
+	proj := writeCentralPackagesProject(t, `
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.3" />
+    <PackageVersion Include="Serilog" Version="3.1.1" />
+    <GlobalPackageReference Include="StyleCop.Analyzers" Version="1.1.118" />`, `
+    <PackageReference Include="newtonsoft.json" />
+    <PackageReference Include="Serilog" VersionOverride="4.0.0" />
+    <PackageReference Include="Microsoft.NETCore.App.Ref" Version="8.0.0" IsImplicitlyDefined="true" />`)
+
+	refs, _, err := packageReferences(proj)
+	if err != nil {
+		t.Fatalf("packageReferences() error = %v", err)
+	}
+
+	var got []string
+	for _, ref := range refs {
+		got = append(got, ref.Include+" "+ref.Version)
+	}
+	want := []string{
+		"StyleCop.Analyzers 1.1.118",
+		"newtonsoft.json 13.0.3",
+		"Serilog 4.0.0",
+		"Microsoft.NETCore.App.Ref 8.0.0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("packageReferences() = %q, want %q", got, want)
+	}
+
+	errs, err := centralPackageErrors(proj)
+	if err != nil || len(errs) != 0 {
+		t.Errorf("centralPackageErrors() = %v, %v, want none", errs, err)
+	}
+}
+
+func TestPackageReferences_CentralVersionsSharedByProjects(t *testing.T) {
+	app := writeCentralPackagesProject(t, `
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.3" />
+    <PackageVersion Include="Serilog" Version="3.1.1" />`, `
+    <PackageReference Include="Newtonsoft.Json" />
+    <PackageReference Include="Serilog" />`)
+
+	// A second project of the same repository gets its versions from the same props file
+	libPath := filepath.Join(filepath.Dir(filepath.Dir(app.Path)), "Lib", "Lib.csproj")
+	if err := os.MkdirAll(filepath.Dir(libPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Serilog" />
+    <PackageReference Include="Newtonsoft.Json" Version="12.0.1" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(libPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lib, err := project.LoadProject(libPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		proj *project.Project
+		want []project.PackageReference
+	}{
+		{app, []project.PackageReference{{Include: "Newtonsoft.Json", Version: "13.0.3"}, {Include: "Serilog", Version: "3.1.1"}}},
+		{lib, []project.PackageReference{{Include: "Serilog", Version: "3.1.1"}, {Include: "Newtonsoft.Json", Version: "12.0.1"}}},
+	} {
+		refs, err := PackageReferences(tt.proj)
+		if err != nil {
+			t.Fatalf("PackageReferences(%s) error = %v", filepath.Base(tt.proj.Path), err)
+		}
+		if !reflect.DeepEqual(refs, tt.want) {
+			t.Errorf("PackageReferences(%s) = %+v, want %+v", filepath.Base(tt.proj.Path), refs, tt.want)
+		}
+	}
+
+	// Only the project that versions its own reference fails, with NU1008
+	if errs, err := centralPackageErrors(app); err != nil || len(errs) != 0 {
+		t.Errorf("centralPackageErrors(App) = %v, %v, want none", errs, err)
+	}
+	errs, err := centralPackageErrors(lib)
+	if err != nil {
+		t.Fatalf("centralPackageErrors(Lib) error = %v", err)
+	}
+	if len(errs) != 1 || errs[0].Code != ErrorCodeCentralPackageVersionDefined || errs[0].ProjectPath != lib.Path ||
+		!strings.HasSuffix(errs[0].Message, ": Newtonsoft.Json.") {
+		t.Errorf("centralPackageErrors(Lib) = %v, want NU1008 for Newtonsoft.Json", errs)
+	}
+}
+
+func TestCentralPackageErrors(t *testing.T) {
+	proj := writeCentralPackagesProject(t, `
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.3" />
+    <PackageVersion Include="Microsoft.NETCore.App.Ref" Version="8.0.0" />`, `
+    <PackageReference Include="Newtonsoft.Json" Version="12.0.1" />
+    <PackageReference Include="Serilog" Version="3.1.1" />
+    <PackageReference Include="Microsoft.NETCore.App.Ref" Version="8.0.0" IsImplicitlyDefined="true" />
+    <PackageReference Include="Polly" />
+    <PackageReference Include="Dapper" />`)
+
+	errs, err := centralPackageErrors(proj)
+	if err != nil {
+		t.Fatalf("centralPackageErrors() error = %v", err)
+	}
+
+	want := []struct{ code, message string }{
+		{ErrorCodeCentralPackageVersionDefined, "Projects that use central package version management should not define the version on the PackageReference items but on the PackageVersion items: Newtonsoft.Json;Serilog."},
+		{ErrorCodeImplicitCentralPackageVersion, "The packages Microsoft.NETCore.App.Ref are implicitly referenced. You do not typically need to reference them from your project or in your central package versions management file. For more information, see https://aka.ms/sdkimplicitrefs"},
+		{ErrorCodeMissingCentralPackageVersion, "The PackageReference items Polly;Dapper do not have corresponding PackageVersion."},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("centralPackageErrors() = %v, want %d errors", errs, len(want))
+	}
+	for i, w := range want {
+		if errs[i].Code != w.code || errs[i].Message != w.message || errs[i].ProjectPath != proj.Path {
+			t.Errorf("error %d = %s: %s, want %s: %s", i, errs[i].Code, errs[i].Message, w.code, w.message)
+		}
+	}
+}
+
+func TestCentralPackageErrors_VersionOverrideDisabled(t *testing.T) {
+	proj := writeCentralPackagesProject(t, `
+    <PackageVersion Include="Serilog" Version="3.1.1" />`, `
+    <PackageReference Include="Serilog" VersionOverride="4.0.0" />`)
+
+	errs, err := centralPackageErrors(proj)
+	if err != nil || len(errs) != 0 {
+		t.Fatalf("centralPackageErrors() = %v, %v, want none while overrides are enabled", errs, err)
+	}
+
+	proj.Root.PropertyGroup[0].CentralPackageVersionOverrideEnabled = "false"
+	errs, err = centralPackageErrors(proj)
+	if err != nil {
+		t.Fatalf("centralPackageErrors() error = %v", err)
+	}
+	wantMessage := "The package reference Serilog specifies a VersionOverride but the ability to override a centrally defined version is currently disabled."
+	if len(errs) != 1 || errs[0].Code != ErrorCodeVersionOverrideDisabled || errs[0].Message != wantMessage {
+		t.Errorf("centralPackageErrors() = %v, want one NU1013 error", errs)
+	}
+}
+
+func TestPackageReferences_CentralPackageManagementOptOut(t *testing.T) {
+	proj := writeCentralPackagesProject(t, `
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.3" />
+    <GlobalPackageReference Include="StyleCop.Analyzers" Version="1.1.118" />`, `
+    <PackageReference Include="Newtonsoft.Json" />`)
+
+	// A sibling project of the same repository opts out and versions its own references
+	siblingPath := filepath.Join(filepath.Dir(filepath.Dir(proj.Path)), "Legacy", "Legacy.csproj")
+	if err := os.MkdirAll(filepath.Dir(siblingPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <ManagePackageVersionsCentrally>false</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="12.0.1" VersionOverride="11.0.2" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(siblingPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sibling, err := project.LoadProject(siblingPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		proj *project.Project
+		want []project.PackageReference
+	}{
+		{proj, []project.PackageReference{
+			{Include: "StyleCop.Analyzers", Version: "1.1.118", PrivateAssets: "All", IncludeAssets: "Runtime;Build;Native;ContentFiles;Analyzers"},
+			{Include: "Newtonsoft.Json", Version: "13.0.3"},
+		}},
+		{sibling, []project.PackageReference{{Include: "Newtonsoft.Json", Version: "12.0.1"}}},
+	} {
+		refs, err := PackageReferences(tt.proj)
+		if err != nil {
+			t.Fatalf("PackageReferences(%s) error = %v", filepath.Base(tt.proj.Path), err)
+		}
+		if !reflect.DeepEqual(refs, tt.want) {
+			t.Errorf("PackageReferences(%s) = %+v, want %+v", filepath.Base(tt.proj.Path), refs, tt.want)
+		}
+		if errs, err := centralPackageErrors(tt.proj); err != nil || len(errs) != 0 {
+			t.Errorf("centralPackageErrors(%s) = %v, %v, want none", filepath.Base(tt.proj.Path), errs, err)
+		}
+	}
+	if got := packagesLockFileVersion(sibling); got != PackagesLockFileVersion {
+		t.Errorf("packagesLockFileVersion() = %d, want %d for the opted out project", got, PackagesLockFileVersion)
+	}
+}
+
+func TestLockFileBuilder_CentralPackageVersions(t *testing.T) {
+	proj := writeCentralPackagesProject(t, `
+    <PackageVersion Include="Newtonsoft.Json" Version="13.0.3" />
+    <PackageVersion Include="Serilog" Version="3.1.1" />`, `
+    <PackageReference Include="Newtonsoft.Json" />
+    <PackageReference Include="Serilog" VersionOverride="4.0.0" />`)
+
+	lf := NewLockFileBuilder().Build(proj, &Result{})
+
+	if !lf.Project.Restore.CentralPackageVersionsManagementEnabled || lf.Project.Restore.CentralPackageVersionOverrideDisabled {
+		t.Errorf("restore = %+v, want central package management with overrides", lf.Project.Restore)
+	}
+	framework := lf.Project.Frameworks["net8.0"]
+	want := map[string]DependencyInfo{
+		"Newtonsoft.Json": {Target: "Package", Version: "13.0.3", VersionCentrallyManaged: true},
+		"Serilog":         {Target: "Package", Version: "4.0.0", VersionOverride: "4.0.0"},
+	}
+	if !reflect.DeepEqual(framework.Dependencies, want) {
+		t.Errorf("dependencies = %+v, want %+v", framework.Dependencies, want)
+	}
+	if want := map[string]string{"Newtonsoft.Json": "13.0.3", "Serilog": "3.1.1"}; !reflect.DeepEqual(framework.CentralPackageVersions, want) {
+		t.Errorf("centralPackageVersions = %v, want %v", framework.CentralPackageVersions, want)
+	}
+	if got := lf.ProjectFileDependencyGroups["net8.0"]; !reflect.DeepEqual(got, []string{"Newtonsoft.Json >= 13.0.3", "Serilog >= 4.0.0"}) {
+		t.Errorf("projectFileDependencyGroups = %v", got)
+	}
+}
+
+func TestLockFileBuilder_GlobalPackageReference(t *testing.T) {
+	proj := writeGlobalPackageReferenceProject(t, "")
+
+	lf := NewLockFileBuilder().Build(proj, &Result{
+		DirectPackages: []PackageInfo{{ID: "StyleCop.Analyzers", Version: "1.1.118", IsDirect: true}},
+	})
+
+	dep, ok := lf.Project.Frameworks["net8.0"].Dependencies["StyleCop.Analyzers"]
+	if !ok {
+		t.Fatal("GlobalPackageReference missing from project dependencies")
+	}
+	if dep.Include != "Runtime, Build, Native, ContentFiles, Analyzers" || dep.SuppressParent != "All" {
+		t.Errorf("dependency = %+v, want the GlobalPackageReference asset defaults", dep)
+	}
+	if got := lf.ProjectFileDependencyGroups["net8.0"]; !reflect.DeepEqual(got, []string{"StyleCop.Analyzers >= 1.1.118"}) {
+		t.Errorf("projectFileDependencyGroups = %v", got)
+	}
+	if lib := lf.Targets["net8.0"]["StyleCop.Analyzers/1.1.118"]; len(lib.Compile) != 0 {
+		t.Errorf("compile = %v, want none", lib.Compile)
+	}
+}
+
+func TestExcludeAssets(t *testing.T) {
+	lib := &TargetLibrary{
+		Type: "package",
+		Compile: map[string]map[string]string{
+			"lib/netstandard2.0/Analyzers.Helpers.dll":     {"related": ".xml"},
+			"lib/netstandard2.0/sub/Analyzers.Extra.dll":   {"related": ".xml"},
+			"lib/netstandard2.0/Analyzers.Annotations.dll": {"related": ".xml"},
+		},
+		Runtime: map[string]map[string]string{
+			"lib/netstandard2.0/Analyzers.Helpers.dll": {"related": ".xml"},
+		},
+	}
+
+	excludeAssets(lib, parseIncludeFlags(project.GlobalPackageReferenceIncludeAssets, includeAll))
+
+	wantCompile := map[string]map[string]string{"lib/netstandard2.0/_._": {}}
+	if !reflect.DeepEqual(lib.Compile, wantCompile) {
+		t.Errorf("compile = %v, want %v", lib.Compile, wantCompile)
+	}
+	if len(lib.Runtime) != 1 {
+		t.Errorf("runtime = %v, want it kept", lib.Runtime)
+	}
+
+	// Clearing again keeps the placeholder
+	excludeAssets(lib, includeNone)
+	if !reflect.DeepEqual(lib.Compile, wantCompile) {
+		t.Errorf("compile = %v, want %v", lib.Compile, wantCompile)
+	}
+	if _, ok := lib.Runtime["lib/netstandard2.0/_._"]; !ok || len(lib.Runtime) != 1 {
+		t.Errorf("runtime = %v, want the placeholder", lib.Runtime)
+	}
+}
@@ -0,0 +1,139 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// restorePackagesConfig restores the packages listed in the packages.config of a legacy
+// project into the solution's packages folder, in the <id>.<version> layout of
+// nuget.exe restore. Packages already in the folder are left as they are; there is no
+// dependency resolution, since packages.config lists every package.
+// Reference: PackageRestoreManager.RestoreMissingPackagesAsync
+func restorePackagesConfig(ctx context.Context, proj *project.Project, opts *Options, console Console) error {
+	entries, err := project.LoadPackagesConfig(proj.PackagesConfigPath())
+	if err != nil {
+		return err
+	}
+	quiet := opts.Verbosity == observability.VerbosityQuiet
+	if len(entries) == 0 {
+		if !quiet {
+			console.Printf("Nothing to restore\n")
+		}
+		return nil
+	}
+
+	packagesFolder, err := packagesConfigFolder(proj, opts)
+	if err != nil {
+		return err
+	}
+	pathResolver := packaging.NewPackagePathResolver(packagesFolder, true)
+	restorer := NewRestorer(opts, console)
+
+	installed := 0
+	var errs []error
+	for _, entry := range entries {
+		pkgVersion, err := version.Parse(entry.Version)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid version '%s' of package '%s' in packages.config", entry.Version, entry.ID))
+			continue
+		}
+		identity := &packaging.PackageIdentity{ID: entry.ID, Version: pkgVersion}
+		if _, err := os.Stat(pathResolver.GetInstallPath(identity)); err == nil {
+			continue
+		}
+
+		if err := restorer.installPackagesConfigEntry(ctx, identity, pathResolver); err != nil {
+			errs = append(errs, fmt.Errorf("unable to find version '%s' of package '%s': %w", entry.Version, entry.ID, err))
+			continue
+		}
+		installed++
+		if !quiet {
+			console.Printf("Added package '%s.%s' to folder '%s'\n", entry.ID, pkgVersion.String(), packagesFolder)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if installed == 0 && !quiet {
+		console.Printf("All packages listed in packages.config are already installed.\n")
+	}
+	return nil
+}
+
+// installPackagesConfigEntry downloads a package and extracts it in the V2 layout
+func (r *Restorer) installPackagesConfigEntry(ctx context.Context, identity *packaging.PackageIdentity, pathResolver *packaging.PackagePathResolver) error {
+	stream, _, err := r.downloadFromSources(ctx, identity.ID, identity.Version.String())
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stream.Close() }()
+
+	// The V2 extractor needs a ReadSeeker
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return fmt.Errorf("read package: %w", err)
+	}
+
+	extractionContext := &packaging.PackageExtractionContext{
+		PackageSaveMode:    packaging.PackageSaveModeDefaultV2,
+		XMLDocFileSaveMode: packaging.XMLDocFileSaveModeNone,
+		Logger:             &lockLogger{console: r.console, verbose: logsLockWaits(r.opts.Verbosity)},
+		LockTimeout:        r.opts.LockTimeout,
+	}
+	if _, err := packaging.ExtractPackageV2(ctx, "", bytes.NewReader(data), pathResolver, extractionContext); err != nil {
+		return fmt.Errorf("failed to extract package: %w", err)
+	}
+	return nil
+}
+
+// packagesConfigFolder returns the folder packages.config packages are restored to: the
+// repositoryPath of the closest NuGet.config that sets it, relative to that file, or the
+// packages folder next to the closest solution file above the project.
+func packagesConfigFolder(proj *project.Project, opts *Options) (string, error) {
+	projectDir := filepath.Dir(proj.Path)
+
+	var layers []config.ConfigLayer
+	if opts.ConfigFile != "" {
+		if cfg, err := config.LoadNuGetConfig(opts.ConfigFile); err == nil {
+			layers = []config.ConfigLayer{{Path: opts.ConfigFile, Config: cfg}}
+		}
+	} else {
+		layers = config.LoadConfigLayers(projectDir)
+	}
+	for _, layer := range layers {
+		if repositoryPath := layer.Config.GetConfigValue("repositoryPath"); repositoryPath != "" {
+			if !filepath.IsAbs(repositoryPath) {
+				repositoryPath = filepath.Join(filepath.Dir(layer.Path), repositoryPath)
+			}
+			return repositoryPath, nil
+		}
+	}
+
+	for dir := projectDir; ; {
+		for _, pattern := range []string{"*.sln", "*.slnx"} {
+			if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+				return filepath.Join(dir, "packages"), nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return "", fmt.Errorf("cannot determine the packages folder to restore the packages.config of '%s': set repositoryPath in NuGet.config or place the project under a folder with a solution file", proj.Path)
+}
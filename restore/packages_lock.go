@@ -0,0 +1,501 @@
+package restore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/core/resolver"
+)
+
+// PackagesLockFileName is the default lock file name, next to the project file.
+const PackagesLockFileName = "packages.lock.json"
+
+// PackagesLockFileVersion is the packages.lock.json format version written by NuGet.
+const PackagesLockFileVersion = 1
+
+// PackagesLockFileCentralVersion is the format version NuGet writes for projects that use
+// Central Package Management.
+const PackagesLockFileCentralVersion = 2
+
+// Dependency types in packages.lock.json, in the order NuGet writes them.
+const (
+	LockDependencyDirect            = "Direct"
+	LockDependencyTransitive        = "Transitive"
+	LockDependencyProject           = "Project"
+	LockDependencyCentralTransitive = "CentralTransitive"
+)
+
+// lockDependencyTypeOrder ranks dependency types like NuGet's PackageDependencyType enum
+var lockDependencyTypeOrder = []string{LockDependencyDirect, LockDependencyTransitive, LockDependencyProject, LockDependencyCentralTransitive}
+
+// PackagesLockFile is packages.lock.json: the exact package versions a project restored,
+// per target framework, so later restores and other machines get the same graph.
+// Ported from NuGet.ProjectModel/ProjectLockFile/PackagesLockFile.cs
+type PackagesLockFile struct {
+	Version int
+	Targets []*PackagesLockTarget // Ordered by target framework
+}
+
+// PackagesLockTarget holds the locked packages of one target framework.
+type PackagesLockTarget struct {
+	TargetFramework string
+	Dependencies    []*LockedDependency // Direct packages first, then transitive, each by ID
+}
+
+// LockedDependency is one locked package.
+type LockedDependency struct {
+	ID           string
+	Type         string // One of the LockDependency types
+	Requested    string // Requested range (direct and central transitive packages only)
+	Resolved     string // Empty for project references
+	ContentHash  string // Base64 SHA512 of the .nupkg
+	Dependencies []LockedRange
+}
+
+// LockedRange is a dependency of a locked package and the range it requests.
+type LockedRange struct {
+	ID           string
+	VersionRange string
+}
+
+// lockFileJSON is the on-disk shape of packages.lock.json, used for reading.
+type lockFileJSON struct {
+	Version      int                                        `json:"version"`
+	Dependencies map[string]map[string]lockedDependencyJSON `json:"dependencies"`
+}
+
+type lockedDependencyJSON struct {
+	Type         string            `json:"type"`
+	Requested    string            `json:"requested,omitempty"`
+	Resolved     string            `json:"resolved,omitempty"`
+	ContentHash  string            `json:"contentHash,omitempty"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
+}
+
+// LoadPackagesLockFile reads a lock file. It returns nil and no error when the file doesn't exist.
+func LoadPackagesLockFile(path string) (*PackagesLockFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw lockFileJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+
+	lockFile := &PackagesLockFile{Version: raw.Version}
+	for tfm, packages := range raw.Dependencies {
+		target := &PackagesLockTarget{TargetFramework: tfm}
+		for id, pkg := range packages {
+			dep := &LockedDependency{
+				ID:          id,
+				Type:        pkg.Type,
+				Requested:   pkg.Requested,
+				Resolved:    pkg.Resolved,
+				ContentHash: pkg.ContentHash,
+			}
+			for depID, depRange := range pkg.Dependencies {
+				dep.Dependencies = append(dep.Dependencies, LockedRange{ID: depID, VersionRange: depRange})
+			}
+			target.Dependencies = append(target.Dependencies, dep)
+		}
+		lockFile.Targets = append(lockFile.Targets, target)
+	}
+	lockFile.sort()
+
+	return lockFile, nil
+}
+
+// Save writes the lock file, leaving a file with the same content untouched.
+func (lf *PackagesLockFile) Save(path string) error {
+	data, err := lf.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = writeFileIfChanged(path, data)
+	return err
+}
+
+// MarshalJSON writes the lock file in NuGet's layout: targets in order, direct packages
+// before transitive ones, indented with two spaces.
+func (lf *PackagesLockFile) MarshalJSON() ([]byte, error) {
+	var compact bytes.Buffer
+	fmt.Fprintf(&compact, `{"version":%d,"dependencies":{`, lf.Version)
+	for i, target := range lf.Targets {
+		if i > 0 {
+			compact.WriteByte(',')
+		}
+		writeJSONString(&compact, target.TargetFramework)
+		compact.WriteString(":{")
+		for j, dep := range target.Dependencies {
+			if j > 0 {
+				compact.WriteByte(',')
+			}
+			writeJSONString(&compact, dep.ID)
+			compact.WriteString(`:{"type":`)
+			writeJSONString(&compact, dep.Type)
+			if dep.Requested != "" {
+				compact.WriteString(`,"requested":`)
+				writeJSONString(&compact, dep.Requested)
+			}
+			if dep.Resolved != "" {
+				compact.WriteString(`,"resolved":`)
+				writeJSONString(&compact, dep.Resolved)
+			}
+			if dep.ContentHash != "" {
+				compact.WriteString(`,"contentHash":`)
+				writeJSONString(&compact, dep.ContentHash)
+			}
+			if len(dep.Dependencies) > 0 {
+				compact.WriteString(`,"dependencies":{`)
+				for k, child := range dep.Dependencies {
+					if k > 0 {
+						compact.WriteByte(',')
+					}
+					writeJSONString(&compact, child.ID)
+					compact.WriteByte(':')
+					writeJSONString(&compact, child.VersionRange)
+				}
+				compact.WriteByte('}')
+			}
+			compact.WriteByte('}')
+		}
+		compact.WriteByte('}')
+	}
+	compact.WriteString("}}")
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return indented.Bytes(), nil
+}
+
+// writeJSONString writes s as a JSON string without HTML escaping.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(s)
+	buf.Truncate(buf.Len() - 1) // Encode appends a newline
+}
+
+// Equal reports whether two lock files lock the same packages.
+func (lf *PackagesLockFile) Equal(other *PackagesLockFile) bool {
+	if lf == nil || other == nil {
+		return lf == other
+	}
+	a, errA := lf.MarshalJSON()
+	b, errB := other.MarshalJSON()
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
+
+// sort orders targets by framework, packages by dependency type (direct, transitive,
+// project, central transitive) and then by ID, and their dependencies by ID.
+func (lf *PackagesLockFile) sort() {
+	slices.SortFunc(lf.Targets, func(a, b *PackagesLockTarget) int {
+		return strings.Compare(a.TargetFramework, b.TargetFramework)
+	})
+	for _, target := range lf.Targets {
+		slices.SortFunc(target.Dependencies, func(a, b *LockedDependency) int {
+			if a.Type != b.Type {
+				return lockDependencyTypeRank(a.Type) - lockDependencyTypeRank(b.Type)
+			}
+			return strings.Compare(strings.ToLower(a.ID), strings.ToLower(b.ID))
+		})
+		for _, dep := range target.Dependencies {
+			slices.SortFunc(dep.Dependencies, func(a, b LockedRange) int {
+				return strings.Compare(strings.ToLower(a.ID), strings.ToLower(b.ID))
+			})
+		}
+	}
+}
+
+// lockDependencyTypeRank returns the position of a dependency type in NuGet's order;
+// unknown types sort last.
+func lockDependencyTypeRank(dependencyType string) int {
+	if i := slices.Index(lockDependencyTypeOrder, dependencyType); i >= 0 {
+		return i
+	}
+	return len(lockDependencyTypeOrder)
+}
+
+// target returns the locked packages of a target framework, or nil.
+func (lf *PackagesLockFile) target(tfm string) *PackagesLockTarget {
+	for _, target := range lf.Targets {
+		if strings.EqualFold(target.TargetFramework, tfm) {
+			return target
+		}
+	}
+	return nil
+}
+
+// matchesProject reports whether the lock file was produced for the project's current
+// format version, target frameworks, package references and the central versions pinning
+// its transitive packages. Runtime-specific targets (net8.0/win-x64) aren't compared. When
+// it doesn't match, the reason is returned for the NU1004 message.
+// Reference: PackagesLockFileUtilities.IsLockFileStillValid
+func (lf *PackagesLockFile) matchesProject(version int, targetFrameworks []string, packageRefs []project.PackageReference, pinned map[string]string) (bool, string) {
+	if lf.Version != version {
+		return false, fmt.Sprintf("The lock file version %d does not match the version %d expected for the project.", lf.Version, version)
+	}
+
+	frameworkTargets := 0
+	for _, target := range lf.Targets {
+		if !strings.Contains(target.TargetFramework, "/") {
+			frameworkTargets++
+		}
+	}
+	if frameworkTargets != len(targetFrameworks) {
+		return false, "The project target frameworks are different than the lock file's target frameworks."
+	}
+
+	for _, tfm := range targetFrameworks {
+		target := lf.target(tfm)
+		if target == nil {
+			return false, fmt.Sprintf("The project target framework %s was not found in the lock file.", tfm)
+		}
+
+		locked := make(map[string]string)
+		for _, dep := range target.Dependencies {
+			switch dep.Type {
+			case LockDependencyDirect:
+				locked[strings.ToLower(dep.ID)] = dep.Requested
+			case LockDependencyCentralTransitive:
+				if pinned[strings.ToLower(dep.ID)] != dep.Requested {
+					return false, fmt.Sprintf("The central package version of %s has changed for %s.", dep.ID, tfm)
+				}
+			}
+		}
+		if len(locked) != len(packageRefs) {
+			return false, fmt.Sprintf("The package references have changed for %s.", tfm)
+		}
+		for _, ref := range packageRefs {
+			requested, ok := locked[strings.ToLower(ref.Include)]
+			if !ok || requested != normalizeRequestedRange(ref.Version) {
+				return false, fmt.Sprintf("The package reference %s version has changed for %s.", ref.Include, tfm)
+			}
+		}
+	}
+
+	return true, ""
+}
+
+// ResolvedVersion returns the locked version of a package, from the first target
+// framework that has it, or an empty string when the package isn't locked.
+func (lf *PackagesLockFile) ResolvedVersion(packageID string) string {
+	for _, target := range lf.Targets {
+		for _, dep := range target.Dependencies {
+			if strings.EqualFold(dep.ID, packageID) {
+				return dep.Resolved
+			}
+		}
+	}
+	return ""
+}
+
+// resolvedVersions returns the locked version of each package, direct and transitive, per
+// target framework, keyed by lowercase package ID.
+func (lf *PackagesLockFile) resolvedVersions() map[string]map[string]string {
+	versions := make(map[string]map[string]string)
+	for _, target := range lf.Targets {
+		byID := make(map[string]string)
+		for _, dep := range target.Dependencies {
+			if dep.Type != LockDependencyProject && dep.Resolved != "" {
+				byID[strings.ToLower(dep.ID)] = dep.Resolved
+			}
+		}
+		versions[target.TargetFramework] = byID
+	}
+	return versions
+}
+
+// contentHashes returns the content hash of each locked package, keyed by lockedHashKey.
+func (lf *PackagesLockFile) contentHashes() map[string]string {
+	hashes := make(map[string]string)
+	for _, target := range lf.Targets {
+		for _, dep := range target.Dependencies {
+			if dep.ContentHash != "" && dep.Resolved != "" {
+				hashes[lockedHashKey(dep.ID, dep.Resolved)] = dep.ContentHash
+			}
+		}
+	}
+	return hashes
+}
+
+// lockedHashKey identifies a package in Restorer.lockedHashes.
+func lockedHashKey(packageID, packageVersion string) string {
+	return strings.ToLower(packageID) + "/" + strings.ToLower(packageVersion)
+}
+
+// expectedPackageHash returns the hash a downloaded package must have, or "" when
+// the lock file records none.
+func (r *Restorer) expectedPackageHash(packageID, packageVersion string) string {
+	return r.lockedHashes[lockedHashKey(packageID, packageVersion)]
+}
+
+// installedHashMatches reports whether an installed package has the content hash the lock
+// file records for it. A package the lock file has no hash for, or that was installed
+// without one, matches.
+func (r *Restorer) installedHashMatches(packagesFolder, packageID, packageVersion string) bool {
+	expected := r.expectedPackageHash(packageID, packageVersion)
+	if expected == "" {
+		return true
+	}
+	installed := readContentHash(packagesFolder, packageID, packageVersion)
+	return installed == "" || installed == expected
+}
+
+// packagesLockFileVersion returns the lock file format version of a project: 2 when it
+// uses Central Package Management, through the project or its Directory.Packages.props,
+// and doesn't opt out of it.
+func packagesLockFileVersion(proj *project.Project) int {
+	if proj.IsCentralPackageManagementEnabled() {
+		return PackagesLockFileCentralVersion
+	}
+	if props, err := proj.CentralPackageVersions(); err == nil && props != nil {
+		return PackagesLockFileCentralVersion
+	}
+	return PackagesLockFileVersion
+}
+
+// buildPackagesLockFile creates the lock file of format version for a successful restore.
+// Transitive packages pinned by a central version are CentralTransitive.
+func buildPackagesLockFile(version int, packageRefs []project.PackageReference, pinned map[string]string, result *Result, packagesFolder string) *PackagesLockFile {
+	requested := make(map[string]string)
+	for _, ref := range packageRefs {
+		requested[strings.ToLower(ref.Include)] = normalizeRequestedRange(ref.Version)
+	}
+
+	selector := resolver.NewFrameworkSelector()
+	lockFile := &PackagesLockFile{Version: version}
+	for tfm, frameworkResult := range result.FrameworkResults {
+		target := &PackagesLockTarget{TargetFramework: tfm}
+		for _, pkg := range frameworkResult.allResolvedPackages {
+			dep := &LockedDependency{
+				ID:          pkg.ID,
+				Type:        LockDependencyTransitive,
+				Resolved:    pkg.Version,
+				ContentHash: readContentHash(packagesFolder, pkg.ID, pkg.Version),
+			}
+			if requestedRange, ok := requested[strings.ToLower(pkg.ID)]; ok {
+				dep.Type = LockDependencyDirect
+				dep.Requested = requestedRange
+			} else if pinnedRange, ok := pinned[strings.ToLower(pkg.ID)]; ok {
+				dep.Type = LockDependencyCentralTransitive
+				dep.Requested = pinnedRange
+			}
+
+			dependencies := pkg.Dependencies
+			if len(pkg.DependencyGroups) > 0 {
+				dependencies = selector.SelectDependencies(pkg.DependencyGroups, tfm)
+			}
+			for _, child := range dependencies {
+				dep.Dependencies = append(dep.Dependencies, LockedRange{ID: child.ID, VersionRange: normalizeRequestedRange(child.VersionRange)})
+			}
+
+			target.Dependencies = append(target.Dependencies, dep)
+		}
+		lockFile.Targets = append(lockFile.Targets, target)
+	}
+	lockFile.sort()
+
+	return lockFile
+}
+
+// readContentHash reads the hash recorded when a package was extracted.
+func readContentHash(packagesFolder, packageID, packageVersion string) string {
+	data, err := os.ReadFile(packageHashPath(packagesFolder, packageID, packageVersion))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// normalizeRequestedRange writes a version as the range NuGet records for it,
+// e.g. "13.0.1" as "[13.0.1, )" and "1.*" as "[1.*, )".
+func normalizeRequestedRange(versionRange string) string {
+	versionRange = strings.TrimSpace(versionRange)
+	if versionRange == "" {
+		return "[0.0.0, )"
+	}
+	if strings.HasPrefix(versionRange, "[") || strings.HasPrefix(versionRange, "(") {
+		return versionRange
+	}
+	return "[" + versionRange + ", )"
+}
+
+// lockFilePath returns where the project's packages.lock.json lives.
+func (r *Restorer) lockFilePath(proj *project.Project) string {
+	if r.opts.LockFilePath != "" {
+		return r.opts.LockFilePath
+	}
+	return filepath.Join(filepath.Dir(proj.Path), PackagesLockFileName)
+}
+
+// noOpAllowed reports whether a valid no-op cache may skip the restore.
+// --force and --force-evaluate always restore, and so does a project whose lock file
+// is enabled but hasn't been written yet.
+func (r *Restorer) noOpAllowed(useLockFile bool, existingLock *PackagesLockFile) bool {
+	if r.opts.Force || r.opts.ForceEvaluate {
+		return false
+	}
+	return !useLockFile || existingLock != nil
+}
+
+// evaluateLockFile decides how the lock file constrains this restore. A lock file that
+// matches the project pins the versions of all packages it lists, unless --force-evaluate
+// asks for them to be re-resolved. In locked mode a lock file that doesn't match is an
+// NU1004 error.
+// Reference: RestoreCommand.EvaluatePackagesLockFileAsync
+func (r *Restorer) evaluateLockFile(proj *project.Project, packageRefs []project.PackageReference, useLockFile bool, existingLock *PackagesLockFile) *NuGetError {
+	r.lockedVersions = nil
+	r.lockedHashes = nil
+	if !useLockFile || r.opts.ForceEvaluate {
+		return nil
+	}
+
+	if existingLock == nil {
+		if r.opts.LockedMode {
+			return NewLockFileInconsistentError(proj.Path, "The lock file does not exist.")
+		}
+		return nil
+	}
+
+	valid, reason := existingLock.matchesProject(packagesLockFileVersion(proj), proj.GetTargetFrameworks(), packageRefs, r.pinnedVersions)
+	if valid {
+		r.lockedVersions = existingLock.resolvedVersions()
+		r.lockedHashes = existingLock.contentHashes()
+		return nil
+	}
+	if r.opts.LockedMode {
+		return NewLockFileInconsistentError(proj.Path, reason)
+	}
+	return nil
+}
+
+// commitLockFile writes the lock file for the restored graph when it changed.
+// In locked mode, unless --force-evaluate is set, a change is an NU1004 error instead.
+func (r *Restorer) commitLockFile(lockPath string, existingLock, restored *PackagesLockFile, projectPath string) *NuGetError {
+	if restored.Equal(existingLock) {
+		return nil
+	}
+	if r.opts.LockedMode && !r.opts.ForceEvaluate {
+		return NewLockFileInconsistentError(projectPath, "The restored packages differ from the lock file.")
+	}
+
+	if err := restored.Save(lockPath); err != nil {
+		r.console.Warning("Failed to write packages lock file: %v\n", err)
+	}
+	return nil
+}
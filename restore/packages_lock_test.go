@@ -0,0 +1,926 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// lockTestFeed serves a V3 feed for the dependency-free package Lock.Pkg.
+// Versions can be published while the test runs, and nupkg downloads are recorded.
+type lockTestFeed struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	nupkgs       map[string][]byte // version -> nupkg
+	downloads    []string
+	versionLists int // Requests listing the versions of Lock.Pkg
+}
+
+func newLockTestFeed(t *testing.T) *lockTestFeed {
+	t.Helper()
+
+	feed := &lockTestFeed{nupkgs: make(map[string][]byte)}
+	feed.Server = httptest.NewServer(http.HandlerFunc(feed.serve))
+	t.Cleanup(feed.Close)
+	return feed
+}
+
+// publish adds a version of Lock.Pkg to the feed.
+func (f *lockTestFeed) publish(t *testing.T, ver string) {
+	t.Helper()
+
+	builder := packaging.NewPackageBuilder().
+		SetID("Lock.Pkg").
+		SetVersion(version.MustParse(ver)).
+		SetDescription("Lock file test package").
+		SetAuthors("gonuget")
+	if err := builder.AddFileFromBytes("lib/net8.0/Lock.Pkg.dll", []byte(ver)); err != nil {
+		t.Fatalf("AddFileFromBytes() error = %v", err)
+	}
+	var nupkg bytes.Buffer
+	if err := builder.Save(&nupkg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nupkgs[ver] = nupkg.Bytes()
+}
+
+// downloaded returns the versions whose nupkg was requested, and resets the list.
+func (f *lockTestFeed) downloaded() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	downloads := f.downloads
+	f.downloads = nil
+	return downloads
+}
+
+func (f *lockTestFeed) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var versions []string
+	for ver := range f.nupkgs {
+		versions = append(versions, ver)
+	}
+	slices.Sort(versions)
+
+	base := "http://" + r.Host
+	switch {
+	case r.URL.Path == "/index.json":
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"version": "3.0.0",
+			"resources": []map[string]string{
+				{"@id": base + "/flat/", "@type": "PackageBaseAddress/3.0.0"},
+				{"@id": base + "/registration/", "@type": "RegistrationsBaseUrl/3.6.0"},
+			},
+		})
+	case r.URL.Path == "/flat/lock.pkg/index.json":
+		f.versionLists++
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"versions": versions})
+	case r.URL.Path == "/registration/lock.pkg/index.json":
+		f.versionLists++
+		var items []map[string]any
+		for _, ver := range versions {
+			items = append(items, map[string]any{
+				"@id": base + "/registration/lock.pkg/" + ver + ".json",
+				"catalogEntry": map[string]any{
+					"@id":     base + "/catalog/lock.pkg." + ver + ".json",
+					"id":      "Lock.Pkg",
+					"version": ver,
+				},
+				"packageContent": base + "/flat/lock.pkg/" + ver + "/lock.pkg." + ver + ".nupkg",
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"count": 1,
+			"items": []map[string]any{{
+				"@id":   base + "/registration/lock.pkg/index.json#page",
+				"lower": versions[0],
+				"upper": versions[len(versions)-1],
+				"count": len(items),
+				"items": items,
+			}},
+		})
+	case strings.HasPrefix(r.URL.Path, "/flat/lock.pkg/") && strings.HasSuffix(r.URL.Path, ".nupkg"):
+		ver := strings.Split(strings.TrimPrefix(r.URL.Path, "/flat/lock.pkg/"), "/")[0]
+		nupkg, ok := f.nupkgs[ver]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		f.downloads = append(f.downloads, ver)
+		_, _ = w.Write(nupkg)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writeLockTestProject writes a project referencing Lock.Pkg with the given range.
+func writeLockTestProject(t *testing.T, projPath, versionRange string) {
+	t.Helper()
+	writeFloatTestProject(t, projPath, versionRange, true)
+}
+
+// writeFloatTestProject writes a project referencing Lock.Pkg, optionally with a lock file.
+func writeFloatTestProject(t *testing.T, projPath, versionRange string, useLockFile bool) {
+	t.Helper()
+
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    <RestorePackagesWithLockFile>` + strconv.FormatBool(useLockFile) + `</RestorePackagesWithLockFile>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Lock.Pkg" Version="` + versionRange + `" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestRun_ForceHonorsLockFileAndForceEvaluateRewritesIt(t *testing.T) {
+	feed := newLockTestFeed(t)
+	feed.publish(t, "1.0.0")
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	lockPath := filepath.Join(tmpDir, PackagesLockFileName)
+	writeLockTestProject(t, projPath, "1.*")
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+
+	restore := func(opts Options) *mockConsole {
+		t.Helper()
+
+		opts.Sources = []string{feed.URL + "/index.json"}
+		opts.PackagesFolder = filepath.Join(tmpDir, "packages")
+		opts.NoCache = true
+		console := &mockConsole{}
+		if err := Run(context.Background(), []string{projPath}, &opts, console); err != nil {
+			t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+		}
+		return console
+	}
+	lockedVersion := func() string {
+		t.Helper()
+
+		lockFile, err := LoadPackagesLockFile(lockPath)
+		if err != nil || lockFile == nil {
+			t.Fatalf("LoadPackagesLockFile() = %v, %v", lockFile, err)
+		}
+		dep := lockFile.target("net8.0").Dependencies[0]
+		if dep.Type != LockDependencyDirect || dep.Requested != "[1.*, )" || dep.ContentHash == "" {
+			t.Errorf("locked dependency = %+v", dep)
+		}
+		return dep.Resolved
+	}
+
+	// The first restore writes the lock file with the only version available
+	restore(Options{})
+	if got := lockedVersion(); got != "1.0.0" {
+		t.Fatalf("locked version = %s, want 1.0.0", got)
+	}
+	if got := feed.downloaded(); !slices.Equal(got, []string{"1.0.0"}) {
+		t.Fatalf("downloads = %v, want [1.0.0]", got)
+	}
+	lockBefore, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	feed.publish(t, "1.1.0")
+
+	// --force restores again but the lock file still pins 1.0.0
+	restore(Options{Force: true})
+	lockAfter, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !bytes.Equal(lockBefore, lockAfter) {
+		t.Errorf("--force changed the lock file:\n%s", lockAfter)
+	}
+	if got := feed.downloaded(); len(got) != 0 {
+		t.Errorf("--force downloads = %v, want none", got)
+	}
+
+	// --force-evaluate re-resolves the floating version and rewrites the lock file
+	restore(Options{ForceEvaluate: true})
+	if got := lockedVersion(); got != "1.1.0" {
+		t.Errorf("locked version after --force-evaluate = %s, want 1.1.0", got)
+	}
+	if got := feed.downloaded(); !slices.Equal(got, []string{"1.1.0"}) {
+		t.Errorf("--force-evaluate downloads = %v, want [1.1.0]", got)
+	}
+}
+
+func TestRun_ForceEvaluateReResolvesFloatingVersions(t *testing.T) {
+	feed := newLockTestFeed(t)
+	feed.publish(t, "1.0.0")
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	packagesFolder := filepath.Join(tmpDir, "packages")
+	writeFloatTestProject(t, projPath, "1.*", false)
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+
+	restore := func(opts Options) {
+		t.Helper()
+
+		opts.Sources = []string{feed.URL + "/index.json"}
+		opts.PackagesFolder = packagesFolder
+		opts.NoCache = true
+		console := &mockConsole{}
+		if err := Run(context.Background(), []string{projPath}, &opts, console); err != nil {
+			t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+		}
+	}
+
+	restore(Options{})
+	if got := feed.downloaded(); !slices.Equal(got, []string{"1.0.0"}) {
+		t.Fatalf("downloads = %v, want [1.0.0]", got)
+	}
+
+	feed.publish(t, "1.1.0")
+
+	// The dgspec hash is unchanged, so a plain restore is a no-op
+	restore(Options{})
+	if got := feed.downloaded(); len(got) != 0 {
+		t.Errorf("no-op restore downloads = %v, want none", got)
+	}
+
+	// --force-evaluate re-resolves 1.* and downloads only the new version
+	restore(Options{ForceEvaluate: true})
+	if got := feed.downloaded(); !slices.Equal(got, []string{"1.1.0"}) {
+		t.Errorf("--force-evaluate downloads = %v, want [1.1.0]", got)
+	}
+	if _, err := os.Stat(filepath.Join(packagesFolder, "lock.pkg", "1.1.0", "lock.pkg.1.1.0.nupkg")); err != nil {
+		t.Errorf("1.1.0 not installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, PackagesLockFileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file written without RestorePackagesWithLockFile: %v", err)
+	}
+}
+
+func TestRun_FloatingVersions(t *testing.T) {
+	feed := newLockTestFeed(t)
+	for _, ver := range []string{"1.0.0", "1.0.5", "1.1.0-beta.1", "2.0.0-beta.1", "2.0.0-rc.1", "2.0.0-rc.2", "3.0.0"} {
+		feed.publish(t, ver)
+	}
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+
+	tests := []struct {
+		versionRange string
+		useLockFile  bool
+		want         string
+	}{
+		{"*", false, "3.0.0"},
+		{"1.*", false, "1.0.5"},
+		{"1.0.*", false, "1.0.5"},
+		{"2.0.0-*", false, "2.0.0-rc.2"},
+		{"2.0.0-beta*", false, "2.0.0-beta.1"},
+		{"1.1.*-*", false, "1.1.0-beta.1"},
+		{"*-rc.*", false, "3.0.0"},
+		// Nothing matches the float: the nearest version above it, like NuGet
+		{"0.9.*", false, "1.0.0"},
+		{"2.0.0-rc.*", true, "2.0.0-rc.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.versionRange, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			projPath := filepath.Join(tmpDir, "app.csproj")
+			writeFloatTestProject(t, projPath, tt.versionRange, tt.useLockFile)
+
+			console := &mockConsole{}
+			opts := &Options{
+				Sources:        []string{feed.URL + "/index.json"},
+				PackagesFolder: filepath.Join(tmpDir, "packages"),
+				NoCache:        true,
+			}
+			if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+				t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+			}
+
+			// project.assets.json records the concrete version the float resolved to
+			assets, err := LoadLockFile(GetAssetsFilePath(projPath))
+			if err != nil {
+				t.Fatalf("LoadLockFile() error = %v", err)
+			}
+			if got := assets.PackageVersion("Lock.Pkg"); got != tt.want {
+				t.Errorf("resolved version = %s, want %s", got, tt.want)
+			}
+			if got := assets.ProjectFileDependencyGroups["net8.0"]; !slices.Equal(got, []string{"Lock.Pkg >= " + tt.versionRange}) {
+				t.Errorf("projectFileDependencyGroups = %v, want the floating range", got)
+			}
+
+			if tt.useLockFile {
+				lockFile, err := LoadPackagesLockFile(filepath.Join(tmpDir, PackagesLockFileName))
+				if err != nil || lockFile == nil {
+					t.Fatalf("LoadPackagesLockFile() = %v, %v", lockFile, err)
+				}
+				dep := lockFile.target("net8.0").Dependencies[0]
+				if dep.Requested != "["+tt.versionRange+", )" || dep.Resolved != tt.want {
+					t.Errorf("locked dependency = %+v", dep)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_DetailedLogsRetriedRequests(t *testing.T) {
+	feed := newLockTestFeed(t)
+	feed.publish(t, "1.0.0")
+
+	// The first download of the nupkg fails like an overloaded feed
+	var failed atomic.Bool
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".nupkg") && failed.CompareAndSwap(false, true) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		feed.serve(w, r)
+	}))
+	defer flaky.Close()
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	writeFloatTestProject(t, projPath, "1.0.0", false)
+
+	console := &mockConsole{}
+	opts := &Options{
+		Sources:        []string{flaky.URL + "/index.json"},
+		PackagesFolder: filepath.Join(tmpDir, "packages"),
+		NoCache:        true,
+		Verbosity:      observability.VerbosityDetailed,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	// Both lines are written in one call, so concurrent installs can't separate them
+	nupkgURL := flaky.URL + "/flat/lock.pkg/1.0.0/lock.pkg.1.0.0.nupkg"
+	want := "           An error was encountered when fetching 'GET " + nupkgURL + "'. The request will now be retried.\n" +
+		"           Response status code does not indicate success: 503 (Service Unavailable).\n"
+	if !slices.Contains(console.messages, want) {
+		t.Errorf("output does not contain the retry message %q in one write:\n%s", want, strings.Join(console.messages, ""))
+	}
+}
+
+func TestRun_LockedModeFailsWhenLockFileWouldChange(t *testing.T) {
+	feed := newLockTestFeed(t)
+	feed.publish(t, "1.0.0")
+	feed.publish(t, "1.1.0")
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	writeLockTestProject(t, projPath, "1.0.0")
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+
+	restore := func(opts Options) (*mockConsole, error) {
+		opts.Sources = []string{feed.URL + "/index.json"}
+		opts.PackagesFolder = filepath.Join(tmpDir, "packages")
+		opts.NoCache = true
+		console := &mockConsole{}
+		return console, Run(context.Background(), []string{projPath}, &opts, console)
+	}
+
+	// Locked mode without a lock file fails
+	if _, err := restore(Options{LockedMode: true}); err == nil {
+		t.Fatal("Run() in locked mode without a lock file succeeded")
+	}
+
+	if console, err := restore(Options{}); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	// Changing the reference invalidates the lock file
+	writeLockTestProject(t, projPath, "1.1.0")
+	console, err := restore(Options{LockedMode: true})
+	if err == nil {
+		t.Fatal("Run() in locked mode with an outdated lock file succeeded")
+	}
+	if !slices.ContainsFunc(console.messages, func(msg string) bool { return strings.Contains(msg, "NU1004") }) {
+		t.Errorf("output missing NU1004: %v", console.messages)
+	}
+
+	// --force-evaluate updates the lock file even in locked mode
+	if console, err := restore(Options{LockedMode: true, ForceEvaluate: true}); err != nil {
+		t.Fatalf("Run() with --force-evaluate error = %v\noutput: %v", err, console.messages)
+	}
+	if console, err := restore(Options{LockedMode: true}); err != nil {
+		t.Errorf("Run() in locked mode after --force-evaluate error = %v\noutput: %v", err, console.messages)
+	}
+}
+
+func TestRun_ContentHashMismatchFailsBeforeExtraction(t *testing.T) {
+	feed := newLockTestFeed(t)
+	feed.publish(t, "1.0.0")
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	packagesFolder := filepath.Join(tmpDir, "packages")
+	writeLockTestProject(t, projPath, "1.0.0")
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+
+	restore := func() (*mockConsole, error) {
+		opts := Options{
+			Sources:        []string{feed.URL + "/index.json"},
+			PackagesFolder: packagesFolder,
+			NoCache:        true,
+			Force:          true,
+		}
+		console := &mockConsole{}
+		return console, Run(context.Background(), []string{projPath}, &opts, console)
+	}
+
+	// The first restore records the content hash in the lock file
+	if console, err := restore(); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	// The feed now serves different bytes for the same version
+	feed.mu.Lock()
+	feed.nupkgs["1.0.0"] = append(slices.Clone(feed.nupkgs["1.0.0"]), "corrupted"...)
+	feed.mu.Unlock()
+	if err := os.RemoveAll(packagesFolder); err != nil {
+		t.Fatal(err)
+	}
+
+	console, err := restore()
+	if err == nil {
+		t.Fatal("Run() succeeded with a package that doesn't match the lock file")
+	}
+	want := "NU1403: Package content hash validation failed for Lock.Pkg.1.0.0. The package is different than the last restore."
+	if !slices.ContainsFunc(console.messages, func(msg string) bool { return strings.Contains(msg, want) }) {
+		t.Errorf("output missing %q: %v", want, console.messages)
+	}
+	installDir := packageInstallPath(packagesFolder, "Lock.Pkg", "1.0.0")
+	for _, name := range []string{"lock.pkg.1.0.0.nupkg", "lock.pkg.nuspec", ".nupkg.metadata"} {
+		if _, err := os.Stat(filepath.Join(installDir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s written despite the hash mismatch: %v", name, err)
+		}
+	}
+}
+
+func TestRun_LockFileShortCircuitsVersionResolution(t *testing.T) {
+	feed := newLockTestFeed(t)
+	feed.publish(t, "1.0.0")
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	lockPath := filepath.Join(tmpDir, PackagesLockFileName)
+	packagesFolder := filepath.Join(tmpDir, "packages")
+	writeLockTestProject(t, projPath, "[1.0.0, )")
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+
+	restore := func() (*mockConsole, error) {
+		opts := Options{
+			Sources:        []string{feed.URL + "/index.json"},
+			PackagesFolder: packagesFolder,
+			NoCache:        true,
+			Force:          true,
+		}
+		console := &mockConsole{}
+		return console, Run(context.Background(), []string{projPath}, &opts, console)
+	}
+
+	if console, err := restore(); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+	lockBefore, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	// With the locked version installed, the sources aren't asked for versions at all
+	feed.publish(t, "1.1.0")
+	feed.mu.Lock()
+	feed.versionLists = 0
+	feed.mu.Unlock()
+	if console, err := restore(); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+	feed.mu.Lock()
+	versionLists := feed.versionLists
+	feed.mu.Unlock()
+	if versionLists != 0 {
+		t.Errorf("restore with a lock file listed versions %d times, want none", versionLists)
+	}
+	lockAfter, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !bytes.Equal(lockBefore, lockAfter) {
+		t.Errorf("lock file changed:\n%s", lockAfter)
+	}
+
+	// An installed package that no longer matches the lock file fails with NU1403
+	hashPath := packageHashPath(packagesFolder, "Lock.Pkg", "1.0.0")
+	if err := os.WriteFile(hashPath, []byte("dGFtcGVyZWQ="), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	console, err := restore()
+	if err == nil {
+		t.Fatal("Run() succeeded with an installed package that doesn't match the lock file")
+	}
+	want := "NU1403: Package content hash validation failed for Lock.Pkg.1.0.0."
+	if !slices.ContainsFunc(console.messages, func(msg string) bool { return strings.Contains(msg, want) }) {
+		t.Errorf("output missing %q: %v", want, console.messages)
+	}
+}
+
+func TestLockedRange(t *testing.T) {
+	locked := map[string]string{"lock.pkg": "1.1.0"}
+
+	tests := []struct {
+		packageID    string
+		versionRange string
+		want         string
+		wantLocked   bool
+	}{
+		{"Lock.Pkg", "[1.0.0, )", "[1.1.0]", true},
+		{"Lock.Pkg", "[2.0.0, )", "[2.0.0, )", false},
+		{"Other.Pkg", "[1.0.0, )", "[1.0.0, )", false},
+	}
+	for _, tt := range tests {
+		got, gotLocked := lockedRange(locked, tt.packageID, tt.versionRange)
+		if got != tt.want || (gotLocked != nil) != tt.wantLocked {
+			t.Errorf("lockedRange(%s, %s) = %s, %v, want %s, locked %v", tt.packageID, tt.versionRange, got, gotLocked, tt.want, tt.wantLocked)
+		}
+	}
+}
+
+func TestPackagesLockFile_MarshalJSON(t *testing.T) {
+	lockFile := &PackagesLockFile{
+		Version: PackagesLockFileVersion,
+		Targets: []*PackagesLockTarget{{
+			TargetFramework: "net8.0",
+			Dependencies: []*LockedDependency{
+				{
+					ID:       "B.Transitive",
+					Type:     LockDependencyTransitive,
+					Resolved: "2.0.0",
+				},
+				{
+					ID:           "a.Direct",
+					Type:         LockDependencyDirect,
+					Requested:    "[1.0.0, )",
+					Resolved:     "1.0.0",
+					ContentHash:  "abc+/=",
+					Dependencies: []LockedRange{{ID: "B.Transitive", VersionRange: "[2.0.0, )"}},
+				},
+			},
+		}},
+	}
+	lockFile.sort()
+
+	data, err := lockFile.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+
+	want := `{
+  "version": 1,
+  "dependencies": {
+    "net8.0": {
+      "a.Direct": {
+        "type": "Direct",
+        "requested": "[1.0.0, )",
+        "resolved": "1.0.0",
+        "contentHash": "abc+/=",
+        "dependencies": {
+          "B.Transitive": "[2.0.0, )"
+        }
+      },
+      "B.Transitive": {
+        "type": "Transitive",
+        "resolved": "2.0.0"
+      }
+    }
+  }
+}`
+	if string(data) != want {
+		t.Errorf("MarshalJSON() =\n%s\nwant\n%s", data, want)
+	}
+
+	path := filepath.Join(t.TempDir(), PackagesLockFileName)
+	if err := lockFile.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadPackagesLockFile(path)
+	if err != nil {
+		t.Fatalf("LoadPackagesLockFile() error = %v", err)
+	}
+	if !loaded.Equal(lockFile) {
+		t.Errorf("loaded lock file differs from saved one")
+	}
+}
+
+func TestLoadPackagesLockFile_Missing(t *testing.T) {
+	lockFile, err := LoadPackagesLockFile(filepath.Join(t.TempDir(), PackagesLockFileName))
+	if lockFile != nil || err != nil {
+		t.Errorf("LoadPackagesLockFile() = %v, %v, want nil, nil", lockFile, err)
+	}
+
+	path := filepath.Join(t.TempDir(), PackagesLockFileName)
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadPackagesLockFile(path); err == nil || errors.Is(err, os.ErrNotExist) {
+		t.Errorf("LoadPackagesLockFile() error = %v, want parse error", err)
+	}
+}
+
+func TestPackagesLockFile_DotnetRoundTrip(t *testing.T) {
+	// Written by dotnet restore for a Central Package Management project with a project
+	// reference, transitive pinning and a runtime identifier
+	path := filepath.Join("testdata", "packages.lock.dotnet.json")
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lockFile, err := LoadPackagesLockFile(path)
+	if err != nil {
+		t.Fatalf("LoadPackagesLockFile() error = %v", err)
+	}
+	got, err := lockFile.MarshalJSON()
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("MarshalJSON() =\n%s\nwant the dotnet file unchanged\n%s", got, want)
+	}
+
+	refs := []project.PackageReference{
+		{Include: "Newtonsoft.Json", Version: "13.0.3"},
+		{Include: "Serilog.Sinks.Console", Version: "5.0.1"},
+	}
+	pinned := map[string]string{"system.text.json": "[8.0.4, )"}
+	if valid, reason := lockFile.matchesProject(PackagesLockFileCentralVersion, []string{"net8.0"}, refs, pinned); !valid {
+		t.Errorf("matchesProject() = false (%s), want the lock file accepted", reason)
+	}
+	if valid, _ := lockFile.matchesProject(PackagesLockFileCentralVersion, []string{"net8.0"}, refs, nil); valid {
+		t.Error("matchesProject() = true without the pinned System.Text.Json version, want a mismatch")
+	}
+	if valid, _ := lockFile.matchesProject(PackagesLockFileVersion, []string{"net8.0"}, refs, pinned); valid {
+		t.Error("matchesProject() = true for a project without Central Package Management, want a version mismatch")
+	}
+}
+
+func TestPackagesLockFileVersion(t *testing.T) {
+	dir := t.TempDir()
+	projPath := filepath.Join(dir, "app.csproj")
+	if err := os.WriteFile(projPath, []byte(`<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	proj, err := project.LoadProject(projPath)
+	if err != nil {
+		t.Fatalf("LoadProject() error = %v", err)
+	}
+	if got := packagesLockFileVersion(proj); got != PackagesLockFileVersion {
+		t.Errorf("version = %d, want %d", got, PackagesLockFileVersion)
+	}
+
+	// Central Package Management enabled by Directory.Packages.props
+	props := `<Project><PropertyGroup><ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally></PropertyGroup></Project>`
+	if err := os.WriteFile(filepath.Join(dir, "Directory.Packages.props"), []byte(props), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := packagesLockFileVersion(proj); got != PackagesLockFileCentralVersion {
+		t.Errorf("version = %d, want %d", got, PackagesLockFileCentralVersion)
+	}
+}
+
+func TestRun_WritesPackagesLockFile(t *testing.T) {
+	feed := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{
+		Packages: []nugethttptest.Package{
+			{
+				ID:      "Contoso.App",
+				Version: "1.0.0",
+				Dependencies: []nugethttptest.Dependency{
+					{ID: "Contoso.Core", Range: "2.0.0"},
+					{ID: "Contoso.Logging", Range: "[1.0.0, 2.0.0)"},
+				},
+				Files: map[string][]byte{"lib/net8.0/Contoso.App.dll": []byte("MZ")},
+			},
+			{ID: "Contoso.Core", Version: "2.0.0", Files: map[string][]byte{"lib/net8.0/Contoso.Core.dll": []byte("MZ")}},
+			{ID: "Contoso.Core", Version: "2.1.0", Files: map[string][]byte{"lib/net8.0/Contoso.Core.dll": []byte("MZ")}},
+			{ID: "Contoso.Logging", Version: "1.0.0", Files: map[string][]byte{"lib/net8.0/Contoso.Logging.dll": []byte("MZ")}},
+		},
+	})
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	lockPath := filepath.Join(tmpDir, PackagesLockFileName)
+	packagesFolder := filepath.Join(tmpDir, "packages")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Contoso.App" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	restore := func(opts Options) {
+		t.Helper()
+
+		opts.Sources = []string{feed.SourceURL()}
+		opts.PackagesFolder = packagesFolder
+		opts.NoCache = true
+		console := &mockConsole{}
+		if err := Run(context.Background(), []string{projPath}, &opts, console); err != nil {
+			t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+		}
+	}
+
+	// Without UseLockFile no lock file is written
+	restore(Options{})
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Fatalf("lock file written without UseLockFile: %v", err)
+	}
+
+	restore(Options{UseLockFile: true, Force: true})
+	got, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	// Content hashes are the base64 SHA512 of the installed .nupkg files
+	fixture, err := os.ReadFile(filepath.Join("testdata", "packages.lock.generated.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := string(fixture)
+	for _, pkg := range [][2]string{{"Contoso.App", "1.0.0"}, {"Contoso.Core", "2.0.0"}, {"Contoso.Logging", "1.0.0"}} {
+		nupkg, err := os.ReadFile(packageFilePath(packagesFolder, pkg[0], pkg[1]))
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		sum := sha512.Sum512(nupkg)
+		want = strings.ReplaceAll(want, "{{"+pkg[0]+"/"+pkg[1]+"}}", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	if strings.TrimSpace(string(got)) != strings.TrimSpace(want) {
+		t.Errorf("packages.lock.json =\n%s\nwant\n%s", got, want)
+	}
+
+	// A locked restore accepts the lock file it wrote and verifies the hashes
+	if err := os.RemoveAll(packagesFolder); err != nil {
+		t.Fatal(err)
+	}
+	restore(Options{LockedMode: true, Force: true})
+	if after, err := os.ReadFile(lockPath); err != nil || !bytes.Equal(after, got) {
+		t.Errorf("locked restore changed the lock file (%v):\n%s", err, after)
+	}
+}
+
+func TestRun_CentralTransitivePinning(t *testing.T) {
+	feed := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{
+		Packages: []nugethttptest.Package{
+			{
+				ID:           "Contoso.App",
+				Version:      "1.0.0",
+				Dependencies: []nugethttptest.Dependency{{ID: "Contoso.Core", Range: "2.0.0"}},
+				Files:        map[string][]byte{"lib/net8.0/Contoso.App.dll": []byte("MZ")},
+			},
+			{ID: "Contoso.Core", Version: "2.0.0", Files: map[string][]byte{"lib/net8.0/Contoso.Core.dll": []byte("MZ")}},
+			{ID: "Contoso.Core", Version: "2.1.0", Files: map[string][]byte{"lib/net8.0/Contoso.Core.dll": []byte("MZ")}},
+		},
+	})
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	lockPath := filepath.Join(tmpDir, PackagesLockFileName)
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Contoso.App" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// Contoso.Unused is pinned but no package depends on it
+	writeProps := func(pinning, coreVersion string) {
+		t.Helper()
+		props := `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+    <CentralPackageTransitivePinningEnabled>` + pinning + `</CentralPackageTransitivePinningEnabled>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Contoso.App" Version="1.0.0" />
+    <PackageVersion Include="Contoso.Core" Version="` + coreVersion + `" />
+    <PackageVersion Include="Contoso.Unused" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+		if err := os.WriteFile(filepath.Join(tmpDir, "Directory.Packages.props"), []byte(props), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+
+	restoreCore := func() *LockedDependency {
+		t.Helper()
+
+		opts := &Options{
+			Sources:        []string{feed.SourceURL()},
+			PackagesFolder: filepath.Join(tmpDir, "packages"),
+			NoCache:        true,
+			UseLockFile:    true,
+		}
+		console := &mockConsole{}
+		if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+			t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+		}
+
+		lockFile, err := LoadPackagesLockFile(lockPath)
+		if err != nil || lockFile == nil {
+			t.Fatalf("LoadPackagesLockFile() = %v, %v", lockFile, err)
+		}
+		var core *LockedDependency
+		for _, dep := range lockFile.target("net8.0").Dependencies {
+			switch dep.ID {
+			case "Contoso.Core":
+				core = dep
+			case "Contoso.Unused":
+				t.Errorf("Contoso.Unused locked, want only packages of the graph pinned")
+			}
+		}
+		if core == nil {
+			t.Fatal("Contoso.Core not locked")
+		}
+		return core
+	}
+
+	// Without pinning Contoso.App pulls in the Contoso.Core it depends on
+	writeProps("false", "2.1.0")
+	if core := restoreCore(); core.Type != LockDependencyTransitive || core.Resolved != "2.0.0" {
+		t.Errorf("unpinned Contoso.Core = %s %s, want Transitive 2.0.0", core.Type, core.Resolved)
+	}
+
+	// The central version of a transitive package wins with pinning
+	writeProps("true", "2.1.0")
+	if core := restoreCore(); core.Type != LockDependencyCentralTransitive || core.Resolved != "2.1.0" || core.Requested != "[2.1.0, )" {
+		t.Errorf("pinned Contoso.Core = %s %s requested %s, want CentralTransitive 2.1.0 requested [2.1.0, )", core.Type, core.Resolved, core.Requested)
+	}
+
+	// Changing the central version invalidates the lock file
+	writeProps("true", "2.0.0")
+	if core := restoreCore(); core.Resolved != "2.0.0" || core.Requested != "[2.0.0, )" {
+		t.Errorf("re-pinned Contoso.Core = %s requested %s, want 2.0.0 requested [2.0.0, )", core.Resolved, core.Requested)
+	}
+}
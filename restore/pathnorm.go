@@ -0,0 +1,112 @@
+package restore
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/gonuget/internal/pathnorm"
+)
+
+// Paths in project.assets.json follow dotnet's conventions, which differ per field
+// (see testdata/assets_paths_linux.json and assets_paths_windows.json):
+//
+//   - native separators: project.restore projectUniqueName, projectPath,
+//     fallbackFolders and configFilePaths, the packageFolders keys of fallback folders,
+//     and logs filePath
+//   - native separators with a trailing separator: project.restore packagesPath and
+//     outputPath, and the packageFolders key of the global packages folder
+//   - forward slashes: libraries path and files, and the asset paths in targets
+//
+// packages.lock.json holds no file paths.
+
+// assetsPathStyle applies the path conventions of project.assets.json for a platform
+type assetsPathStyle struct {
+	separator byte
+}
+
+// nativeAssetsPaths is the assets path style of the running platform
+var nativeAssetsPaths = assetsPathStyle{separator: filepath.Separator}
+
+// nativePath converts p to the platform's separators
+func (s assetsPathStyle) nativePath(p string) string {
+	if s.separator == '\\' {
+		return strings.ReplaceAll(p, "/", `\`)
+	}
+	return p
+}
+
+// folderPath converts p to the platform's separators, ending with a separator
+func (s assetsPathStyle) folderPath(p string) string {
+	if p == "" {
+		return p
+	}
+	p = s.nativePath(p)
+	if p[len(p)-1] != s.separator {
+		p += string(s.separator)
+	}
+	return p
+}
+
+// packagePath converts a path inside a package to forward slashes
+func packagePath(p string) string {
+	return strings.ReplaceAll(p, `\`, "/")
+}
+
+// normalizePaths rewrites every path of the assets file in style's conventions
+func (lf *LockFile) normalizePaths(style assetsPathStyle) {
+	info := &lf.Project.Restore
+	info.ProjectUniqueName = style.nativePath(info.ProjectUniqueName)
+	info.ProjectPath = style.nativePath(info.ProjectPath)
+	info.PackagesPath = style.folderPath(info.PackagesPath)
+	info.OutputPath = style.folderPath(info.OutputPath)
+	for i, folder := range info.FallbackFolders {
+		info.FallbackFolders[i] = style.nativePath(folder)
+	}
+	for i, configFile := range info.ConfigFilePaths {
+		info.ConfigFilePaths[i] = style.nativePath(configFile)
+	}
+
+	if lf.PackageFolders != nil {
+		folders := make(map[string]PackageFolder, len(lf.PackageFolders))
+		for folder, value := range lf.PackageFolders {
+			if info.PackagesPath != "" && pathnorm.TrimSeparators(folder) == pathnorm.TrimSeparators(info.PackagesPath) {
+				folders[style.folderPath(folder)] = value
+			} else {
+				folders[style.nativePath(folder)] = value
+			}
+		}
+		lf.PackageFolders = folders
+	}
+
+	for key, lib := range lf.Libraries {
+		lib.Path = packagePath(lib.Path)
+		for i, file := range lib.Files {
+			lib.Files[i] = packagePath(file)
+		}
+		lf.Libraries[key] = lib
+	}
+
+	for _, target := range lf.Targets {
+		for key, lib := range target {
+			lib.Compile = packagePathKeys(lib.Compile)
+			lib.Runtime = packagePathKeys(lib.Runtime)
+			target[key] = lib
+		}
+	}
+
+	for i := range lf.Logs {
+		lf.Logs[i].FilePath = style.nativePath(lf.Logs[i].FilePath)
+	}
+}
+
+// packagePathKeys converts the asset paths of a target library to forward slashes
+func packagePathKeys(assets map[string]map[string]string) map[string]map[string]string {
+	if assets == nil {
+		return nil
+	}
+	normalized := make(map[string]map[string]string, len(assets))
+	for assetPath, metadata := range assets {
+		normalized[packagePath(assetPath)] = metadata
+	}
+	return normalized
+}
@@ -0,0 +1,137 @@
+package restore
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLockFile_NormalizePaths_DotnetFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		style   assetsPathStyle
+	}{
+		{"assets_paths_linux.json", assetsPathStyle{separator: '/'}},
+		{"assets_paths_windows.json", assetsPathStyle{separator: '\\'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			path := filepath.Join("testdata", tt.fixture)
+			want, err := LoadLockFile(path)
+			if err != nil {
+				t.Fatalf("LoadLockFile() error = %v", err)
+			}
+
+			// dotnet's own output is left as is
+			lf, _ := LoadLockFile(path)
+			lf.normalizePaths(tt.style)
+			if !reflect.DeepEqual(lf, want) {
+				t.Errorf("normalizePaths() changed dotnet's paths:\n%+v\nwant %+v", lf, want)
+			}
+
+			// Paths written with the other platform's separators are rewritten
+			lf, _ = LoadLockFile(path)
+			mangleAssetsPaths(lf, tt.style)
+			lf.normalizePaths(tt.style)
+			if !reflect.DeepEqual(lf, want) {
+				t.Errorf("normalizePaths() =\n%+v\nwant %+v", lf, want)
+			}
+		})
+	}
+}
+
+// mangleAssetsPaths writes the paths of lf the way another platform would, without
+// trailing separators
+func mangleAssetsPaths(lf *LockFile, style assetsPathStyle) {
+	native := func(p string) string {
+		if style.separator == '\\' {
+			p = strings.ReplaceAll(p, `\`, "/")
+		}
+		return p
+	}
+	folder := func(p string) string {
+		return strings.TrimRight(native(p), `/\`)
+	}
+	pkg := func(p string) string {
+		return strings.ReplaceAll(p, "/", `\`)
+	}
+
+	info := &lf.Project.Restore
+	info.ProjectUniqueName = native(info.ProjectUniqueName)
+	info.ProjectPath = native(info.ProjectPath)
+	info.PackagesPath = folder(info.PackagesPath)
+	info.OutputPath = folder(info.OutputPath)
+	for i := range info.FallbackFolders {
+		info.FallbackFolders[i] = native(info.FallbackFolders[i])
+	}
+	for i := range info.ConfigFilePaths {
+		info.ConfigFilePaths[i] = native(info.ConfigFilePaths[i])
+	}
+	folders := make(map[string]PackageFolder)
+	for key, value := range lf.PackageFolders {
+		folders[folder(key)] = value
+	}
+	lf.PackageFolders = folders
+	for key, lib := range lf.Libraries {
+		lib.Path = pkg(lib.Path)
+		for i := range lib.Files {
+			lib.Files[i] = pkg(lib.Files[i])
+		}
+		lf.Libraries[key] = lib
+	}
+	for _, target := range lf.Targets {
+		for key, lib := range target {
+			compile := make(map[string]map[string]string)
+			for assetPath, metadata := range lib.Compile {
+				compile[pkg(assetPath)] = metadata
+			}
+			lib.Compile = compile
+			target[key] = lib
+		}
+	}
+	for i := range lf.Logs {
+		lf.Logs[i].FilePath = native(lf.Logs[i].FilePath)
+	}
+}
+
+func TestLockFile_Save_NormalizesPaths(t *testing.T) {
+	dir := t.TempDir()
+	lf, err := LoadLockFile(filepath.Join("testdata", "assets_paths_linux.json"))
+	if err != nil {
+		t.Fatalf("LoadLockFile() error = %v", err)
+	}
+	packagesPath := filepath.Join(dir, "packages")
+	lf.Project.Restore.PackagesPath = packagesPath
+	lf.Project.Restore.OutputPath = filepath.Join(dir, "obj")
+	lf.PackageFolders = map[string]PackageFolder{packagesPath: {}}
+	lib := lf.Libraries["Newtonsoft.Json/13.0.3"]
+	lib.Files = append(lib.Files, `lib\netstandard2.0\Newtonsoft.Json.dll`)
+	lf.Libraries["Newtonsoft.Json/13.0.3"] = lib
+
+	path := filepath.Join(dir, "project.assets.json")
+	if err := lf.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	saved, err := LoadLockFile(path)
+	if err != nil {
+		t.Fatalf("LoadLockFile() error = %v", err)
+	}
+
+	wantFolder := packagesPath + string(filepath.Separator)
+	if saved.Project.Restore.PackagesPath != wantFolder {
+		t.Errorf("packagesPath = %q, want %q", saved.Project.Restore.PackagesPath, wantFolder)
+	}
+	if _, ok := saved.PackageFolders[wantFolder]; !ok || len(saved.PackageFolders) != 1 {
+		t.Errorf("packageFolders = %v, want %q", saved.PackageFolders, wantFolder)
+	}
+	if !strings.Contains(string(data), `"lib/netstandard2.0/Newtonsoft.Json.dll"`) {
+		t.Error("library files were not written with forward slashes")
+	}
+}
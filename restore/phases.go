@@ -0,0 +1,116 @@
+package restore
+
+import (
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LegacyLogFormatEnvVar enables the legacy log format when set to a true value.
+// Build wrappers that parse nuget.exe output set it so they don't need to pass a flag.
+const LegacyLogFormatEnvVar = "GONUGET_LEGACY_LOG_FORMAT"
+
+// RestorePhase identifies a milestone of a project restore.
+type RestorePhase int
+
+const (
+	// PhaseRestoreStarted is reported before dependency resolution begins (not for no-op restores).
+	PhaseRestoreStarted RestorePhase = iota
+	// PhaseCommitStarted is reported before the restore outputs are written.
+	PhaseCommitStarted
+	// PhaseAssetsWritten is reported when project.assets.json is written.
+	PhaseAssetsWritten
+	// PhaseAssetsUnchanged is reported instead of PhaseAssetsWritten when project.assets.json
+	// already has the restored content and is left untouched.
+	PhaseAssetsUnchanged
+	// PhaseRestoreCompleted is reported when the project restore finishes, including no-op restores.
+	PhaseRestoreCompleted
+)
+
+// PhaseEvent describes a restore milestone.
+type PhaseEvent struct {
+	Phase       RestorePhase
+	ProjectPath string
+	Path        string        // Assets file of the phase (PhaseAssetsWritten and PhaseAssetsUnchanged only)
+	Elapsed     time.Duration // Time since the project restore started
+}
+
+// PhaseTracer receives restore milestones.
+// All milestone output is driven by these events so every consumer shares the same timings.
+type PhaseTracer interface {
+	TracePhase(event PhaseEvent)
+}
+
+// LegacyLogFormatFromEnv reports whether LegacyLogFormatEnvVar requests the legacy log format.
+func LegacyLogFormatFromEnv() bool {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(os.Getenv(LegacyLogFormatEnvVar)))
+	return err == nil && enabled
+}
+
+// tracePhase reports a milestone to the restorer's phase tracers.
+func (r *Restorer) tracePhase(phase RestorePhase, projectPath, path string) {
+	event := PhaseEvent{
+		Phase:       phase,
+		ProjectPath: projectPath,
+		Path:        path,
+		Elapsed:     r.restoreElapsed(),
+	}
+	for _, tracer := range r.phaseTracers {
+		tracer.TracePhase(event)
+	}
+}
+
+// restoreElapsed returns the time since Restore started for the current project.
+func (r *Restorer) restoreElapsed() time.Duration {
+	if r.restoreStart.IsZero() {
+		return 0
+	}
+	return time.Since(r.restoreStart)
+}
+
+// legacyLogTracer prints the milestone lines of nuget.exe and msbuild /t:Restore
+// for build wrappers that parse them.
+type legacyLogTracer struct {
+	console Console
+}
+
+// TracePhase prints the nuget.exe line for a milestone.
+func (t *legacyLogTracer) TracePhase(event PhaseEvent) {
+	switch event.Phase {
+	case PhaseRestoreStarted:
+		t.console.Printf("  Restoring packages for %s...\n", event.ProjectPath)
+	case PhaseCommitStarted:
+		t.console.Printf("  Committing restore...\n")
+	case PhaseAssetsWritten:
+		t.console.Printf("  Writing assets file to disk. Path: %s\n", event.Path)
+	case PhaseAssetsUnchanged:
+		t.console.Printf("  Assets file has not changed. Skipping assets file writing. Path: %s\n", event.Path)
+	case PhaseRestoreCompleted:
+		t.console.Printf("  Restore completed in %s for %s.\n", FormatReadableDuration(event.Elapsed), event.ProjectPath)
+	}
+}
+
+// FormatReadableDuration formats a duration the way NuGet reports restore times,
+// e.g. "37.34 ms", "1.5 sec" or "2.03 min".
+// Matches DatetimeUtility.ToReadableTimeFormat in NuGet.Client ("{0:0.##}" with the unit).
+func FormatReadableDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return formatDotNetFixed2(float64(d)/float64(time.Millisecond)) + " ms"
+	case d < time.Minute:
+		return formatDotNetFixed2(d.Seconds()) + " sec"
+	case d < time.Hour:
+		return formatDotNetFixed2(d.Minutes()) + " min"
+	default:
+		return formatDotNetFixed2(d.Hours()) + " hr"
+	}
+}
+
+// formatDotNetFixed2 formats v like .NET's "0.##" custom format:
+// at most two decimals, trailing zeros dropped, midpoints rounded away from zero.
+func formatDotNetFixed2(v float64) string {
+	rounded := math.Round(v*100) / 100
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
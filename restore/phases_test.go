@@ -0,0 +1,210 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// newHermeticFeed serves a V3 feed with a single dependency-free package Legacy.Log 1.0.0.
+func newHermeticFeed(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	builder := packaging.NewPackageBuilder().
+		SetID("Legacy.Log").
+		SetVersion(version.MustParse("1.0.0")).
+		SetDescription("Legacy log test package").
+		SetAuthors("gonuget")
+	if err := builder.AddFileFromBytes("lib/net8.0/Legacy.Log.dll", []byte("dll")); err != nil {
+		t.Fatalf("AddFileFromBytes() error = %v", err)
+	}
+	var nupkg bytes.Buffer
+	if err := builder.Save(&nupkg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		base := "http://" + r.Host
+		switch r.URL.Path {
+		case "/index.json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": base + "/flat/", "@type": "PackageBaseAddress/3.0.0"},
+					{"@id": base + "/registration/", "@type": "RegistrationsBaseUrl/3.6.0"},
+				},
+			})
+		case "/flat/legacy.log/index.json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{"versions": []string{"1.0.0"}})
+		case "/registration/legacy.log/index.json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"count": 1,
+				"items": []map[string]any{{
+					"@id":   base + "/registration/legacy.log/index.json#page/1.0.0/1.0.0",
+					"lower": "1.0.0",
+					"upper": "1.0.0",
+					"count": 1,
+					"items": []map[string]any{{
+						"@id": base + "/registration/legacy.log/1.0.0.json",
+						"catalogEntry": map[string]any{
+							"@id":     base + "/catalog/legacy.log.1.0.0.json",
+							"id":      "Legacy.Log",
+							"version": "1.0.0",
+						},
+						"packageContent": base + "/flat/legacy.log/1.0.0/legacy.log.1.0.0.nupkg",
+					}},
+				}},
+			})
+		case "/flat/legacy.log/1.0.0/legacy.log.1.0.0.nupkg":
+			_, _ = w.Write(nupkg.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// readMilestoneFixture reads a transcript fixture, skipping comment lines.
+func readMilestoneFixture(t *testing.T, name string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+
+	var lines []string
+	for line := range strings.SplitSeq(strings.TrimRight(string(data), "\n"), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+var readableTimePattern = regexp.MustCompile(`Restore completed in \d+(\.\d{1,2})? (ms|sec|min|hr) for`)
+
+// legacyMilestones extracts the nuget.exe milestone lines with paths and timings normalized.
+func legacyMilestones(messages []string, projectPath string) []string {
+	objDir := filepath.Join(filepath.Dir(projectPath), "obj")
+
+	var lines []string
+	for _, msg := range messages {
+		line := strings.TrimRight(msg, "\n")
+		if !strings.HasPrefix(line, "  Restoring packages for ") &&
+			line != "  Committing restore..." &&
+			!strings.HasPrefix(line, "  Writing assets file to disk.") &&
+			!strings.HasPrefix(line, "  Restore completed in ") {
+			continue
+		}
+
+		line = readableTimePattern.ReplaceAllString(line, "Restore completed in {TIME} for")
+		line = strings.ReplaceAll(line, objDir+string(filepath.Separator), "{OBJ}/")
+		line = strings.ReplaceAll(line, projectPath, "{PROJECT}")
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRun_LegacyLogFormat_MatchesNuGetTranscript(t *testing.T) {
+	feed := newHermeticFeed(t)
+
+	tmpDir := t.TempDir()
+	projPath := filepath.Join(tmpDir, "app.csproj")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Legacy.Log" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	oldDetector := DefaultTTYDetector
+	DefaultTTYDetector = &mockTTYDetector{isTTY: false}
+	defer func() { DefaultTTYDetector = oldDetector }()
+
+	opts := &Options{
+		Sources:         []string{feed.URL + "/index.json"},
+		PackagesFolder:  filepath.Join(tmpDir, "packages"),
+		Verbosity:       observability.VerbosityMinimal,
+		LegacyLogFormat: true,
+	}
+
+	for _, fixture := range []string{"legacy_restore_log.txt", "legacy_restore_log_noop.txt"} {
+		console := &mockConsole{}
+		if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+			t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+		}
+
+		got := legacyMilestones(console.messages, projPath)
+		want := readMilestoneFixture(t, fixture)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: milestones =\n%s\nwant\n%s", fixture, strings.Join(got, "\n"), strings.Join(want, "\n"))
+		}
+
+		// Regular output is still printed
+		if !slices.ContainsFunc(console.messages, func(msg string) bool { return strings.Contains(msg, "Determining projects to restore") }) {
+			t.Errorf("%s: regular restore output missing: %v", fixture, console.messages)
+		}
+	}
+}
+
+func TestRun_LegacyLogFormat_OffByDefault(t *testing.T) {
+	r := NewRestorer(&Options{}, &mockConsole{})
+	if len(r.phaseTracers) != 0 {
+		t.Errorf("phaseTracers = %d, want none without LegacyLogFormat", len(r.phaseTracers))
+	}
+}
+
+func TestFormatReadableDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 37340 * time.Microsecond, want: "37.34 ms"},
+		{d: 52275 * time.Microsecond, want: "52.28 ms"}, // midpoint rounds away from zero
+		{d: 120 * time.Millisecond, want: "120 ms"},
+		{d: 999994 * time.Microsecond, want: "999.99 ms"},
+		{d: 1500 * time.Millisecond, want: "1.5 sec"},
+		{d: 12345 * time.Millisecond, want: "12.35 sec"},
+		{d: 90 * time.Second, want: "1.5 min"},
+		{d: 2 * time.Hour, want: "2 hr"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatReadableDuration(tt.d); got != tt.want {
+			t.Errorf("FormatReadableDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestLegacyLogFormatFromEnv(t *testing.T) {
+	for value, want := range map[string]bool{"1": true, "true": true, "TRUE": true, "0": false, "": false, "yes": false} {
+		t.Setenv(LegacyLogFormatEnvVar, value)
+		if got := LegacyLogFormatFromEnv(); got != want {
+			t.Errorf("LegacyLogFormatFromEnv() with %q = %v, want %v", value, got, want)
+		}
+	}
+}
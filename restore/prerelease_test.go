@@ -0,0 +1,67 @@
+package restore
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+)
+
+// newPrereleaseOnlyFeed serves a stable package whose only dependency version in range is a prerelease
+func newPrereleaseOnlyFeed(t *testing.T) *nugethttptest.FakeV3Server {
+	t.Helper()
+	return nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{
+		Packages: []nugethttptest.Package{
+			{
+				ID:           "Contoso.App",
+				Version:      "1.0.0",
+				Dependencies: []nugethttptest.Dependency{{ID: "Contoso.Lib", Range: "1.0.0"}},
+				Files:        map[string][]byte{"lib/net8.0/Contoso.App.dll": []byte("MZ")},
+			},
+			{
+				ID:      "Contoso.Lib",
+				Version: "2.0.0-beta",
+				Files:   map[string][]byte{"lib/net8.0/Contoso.Lib.dll": []byte("MZ")},
+			},
+		},
+	})
+}
+
+func TestRun_PrereleaseDependencyOfStablePackage(t *testing.T) {
+	feed := newPrereleaseOnlyFeed(t)
+	tmpDir := t.TempDir()
+	projPath := writeSourcesTestProject(t, tmpDir, "Contoso.App", "")
+
+	opts := &Options{
+		Sources:        []string{feed.SourceURL()},
+		PackagesFolder: filepath.Join(tmpDir, "packages"),
+		NoCache:        true,
+	}
+	console := &mockConsole{}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err == nil {
+		t.Fatal("Run() succeeded, want NU1103")
+	}
+	if !containsMessage(console.messages, "NU1103") {
+		t.Errorf("output = %v, want NU1103", console.messages)
+	}
+
+	// The opt-in admits the prerelease and, being part of the hash, is not a no-op
+	opts.AllowPrereleaseEverywhere = true
+	console = &mockConsole{}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() with AllowPrereleaseEverywhere error = %v\noutput: %v", err, console.messages)
+	}
+	lockFile, err := LoadLockFile(GetAssetsFilePath(projPath))
+	if err != nil {
+		t.Fatalf("load assets file: %v", err)
+	}
+	found := false
+	for key := range lockFile.Libraries {
+		found = found || strings.EqualFold(key, "Contoso.Lib/2.0.0-beta")
+	}
+	if !found {
+		t.Errorf("assets libraries = %v, want Contoso.Lib/2.0.0-beta", lockFile.Libraries)
+	}
+}
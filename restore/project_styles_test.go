@@ -0,0 +1,227 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+)
+
+// newStylesFeed serves a package with assets for .NET Framework and .NET
+func newStylesFeed(t *testing.T) *nugethttptest.FakeV3Server {
+	t.Helper()
+	return nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{
+		Packages: []nugethttptest.Package{{
+			ID:      "Contoso.Core",
+			Version: "1.0.0",
+			Files: map[string][]byte{
+				"lib/net45/Contoso.Core.dll":   []byte("MZ"),
+				"lib/net8.0/Contoso.Core.dll":  []byte("MZ"),
+				"content/readme.contoso.txt":   []byte("readme"),
+				"lib/net45/Contoso.Core.xml":   []byte("<doc />"),
+				"lib/net8.0/Contoso.Core.xml":  []byte("<doc />"),
+				"tools/net45/install.contoso1": []byte("tool"),
+			},
+		}},
+	})
+}
+
+func writeStyleFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_PackagesConfigProject(t *testing.T) {
+	feed := newStylesFeed(t)
+	root := t.TempDir()
+	writeStyleFile(t, filepath.Join(root, "App.sln"), "")
+	projPath := filepath.Join(root, "src", "Legacy", "Legacy.csproj")
+	writeStyleFile(t, projPath, `<?xml version="1.0" encoding="utf-8"?>
+<Project ToolsVersion="4.0" xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <PropertyGroup>
+    <TargetFrameworkVersion>v4.5</TargetFrameworkVersion>
+  </PropertyGroup>
+</Project>`)
+	writeStyleFile(t, filepath.Join(root, "src", "Legacy", "packages.config"), `<?xml version="1.0" encoding="utf-8"?>
+<packages>
+  <package id="Contoso.Core" version="1.0.0" targetFramework="net45" />
+</packages>`)
+
+	opts := &Options{
+		Sources:        []string{feed.SourceURL()},
+		PackagesFolder: filepath.Join(root, "global-packages"),
+		NoCache:        true,
+	}
+	console := &mockConsole{}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	// Packages go next to the solution in the <id>.<version> layout, not to the global folder
+	packagesFolder := filepath.Join(root, "packages")
+	for _, file := range []string{"Contoso.Core.1.0.0.nupkg", filepath.Join("lib", "net45", "Contoso.Core.dll")} {
+		if _, err := os.Stat(filepath.Join(packagesFolder, "Contoso.Core.1.0.0", file)); err != nil {
+			t.Errorf("%s not restored: %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "global-packages")); !os.IsNotExist(err) {
+		t.Error("packages.config restore wrote to the global packages folder")
+	}
+	if _, err := os.Stat(GetAssetsFilePath(projPath)); !os.IsNotExist(err) {
+		t.Error("packages.config restore wrote project.assets.json")
+	}
+	if !containsMessage(console.messages, "Added package 'Contoso.Core.1.0.0' to folder '"+packagesFolder+"'") {
+		t.Errorf("output = %v, want the added package reported", console.messages)
+	}
+
+	console = &mockConsole{}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if !containsMessage(console.messages, "All packages listed in packages.config are already installed.") {
+		t.Errorf("second output = %v, want the packages reported as installed", console.messages)
+	}
+}
+
+func TestRun_PackagesConfigProject_RepositoryPathAndMissingPackage(t *testing.T) {
+	feed := newStylesFeed(t)
+	root := t.TempDir()
+	writeStyleFile(t, filepath.Join(root, "NuGet.Config"), `<configuration>
+  <config>
+    <add key="repositoryPath" value="lib/packages" />
+  </config>
+</configuration>`)
+	projPath := filepath.Join(root, "Legacy.vbproj")
+	writeStyleFile(t, projPath, `<Project ToolsVersion="4.0"><PropertyGroup><TargetFrameworkVersion>v4.5</TargetFrameworkVersion></PropertyGroup></Project>`)
+	writeStyleFile(t, filepath.Join(root, "packages.config"), `<packages>
+  <package id="Contoso.Core" version="1.0.0" />
+  <package id="Missing.Package" version="2.0.0" />
+</packages>`)
+
+	opts := &Options{
+		Sources:        []string{feed.SourceURL()},
+		PackagesFolder: filepath.Join(root, "global-packages"),
+		NoCache:        true,
+	}
+	err := Run(context.Background(), []string{projPath}, opts, &mockConsole{})
+	if err == nil || !strings.Contains(err.Error(), "unable to find version '2.0.0' of package 'Missing.Package'") {
+		t.Errorf("Run() error = %v, want the missing package named", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "lib", "packages", "Contoso.Core.1.0.0", "Contoso.Core.1.0.0.nupkg")); err != nil {
+		t.Errorf("package not restored to repositoryPath: %v", err)
+	}
+}
+
+func TestRun_PackagesConfigProject_NoPackagesFolder(t *testing.T) {
+	root := t.TempDir()
+	projPath := filepath.Join(root, "Legacy.csproj")
+	writeStyleFile(t, projPath, `<Project ToolsVersion="4.0"><PropertyGroup><TargetFrameworkVersion>v4.5</TargetFrameworkVersion></PropertyGroup></Project>`)
+	writeStyleFile(t, filepath.Join(root, "packages.config"), `<packages><package id="Contoso.Core" version="1.0.0" /></packages>`)
+
+	// The config has no repositoryPath and there is no solution file above the project
+	configPath := filepath.Join(root, "NuGet.Config")
+	writeStyleFile(t, configPath, `<configuration />`)
+
+	opts := &Options{Sources: []string{"https://example.invalid/v3/index.json"}, ConfigFile: configPath}
+	err := Run(context.Background(), []string{projPath}, opts, &mockConsole{})
+	if err == nil || !strings.Contains(err.Error(), "cannot determine the packages folder") {
+		t.Errorf("Run() error = %v, want the packages folder reported as unknown", err)
+	}
+}
+
+func TestRun_LegacyPackageReferenceAndFSharpProjects(t *testing.T) {
+	feed := newStylesFeed(t)
+
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		framework string
+	}{
+		{
+			name: "legacy csproj",
+			file: "Legacy.csproj",
+			content: `<?xml version="1.0" encoding="utf-8"?>
+<Project ToolsVersion="15.0" xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <PropertyGroup>
+    <TargetFrameworkVersion>v4.7.2</TargetFrameworkVersion>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Contoso.Core">
+      <Version>1.0.0</Version>
+    </PackageReference>
+  </ItemGroup>
+</Project>`,
+			framework: "net472",
+		},
+		{
+			name: "fsproj",
+			file: "Lib.fsproj",
+			content: `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Contoso.Core" Version="1.0.0" />
+  </ItemGroup>
+</Project>`,
+			framework: "net8.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			projPath := filepath.Join(root, tt.file)
+			writeStyleFile(t, projPath, tt.content)
+
+			opts := &Options{
+				Sources:        []string{feed.SourceURL()},
+				PackagesFolder: filepath.Join(root, "packages"),
+				NoCache:        true,
+			}
+			console := &mockConsole{}
+			if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+				t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+			}
+
+			assets, err := LoadLockFile(GetAssetsFilePath(projPath))
+			if err != nil {
+				t.Fatalf("LoadLockFile() error = %v", err)
+			}
+			if _, ok := assets.Targets[tt.framework]; !ok {
+				t.Errorf("targets = %v, want %s", assets.Targets, tt.framework)
+			}
+		})
+	}
+}
+
+func TestRun_UnsupportedProject(t *testing.T) {
+	root := t.TempDir()
+	projPath := filepath.Join(root, "Portable.csproj")
+	writeStyleFile(t, projPath, `<Project ToolsVersion="14.0" xmlns="http://schemas.microsoft.com/developer/msbuild/2003">
+  <PropertyGroup>
+    <TargetFrameworkIdentifier>.NETPortable</TargetFrameworkIdentifier>
+    <TargetFrameworkVersion>v4.5</TargetFrameworkVersion>
+  </PropertyGroup>
+</Project>`)
+
+	err := Run(context.Background(), []string{projPath}, &Options{Sources: []string{"https://example.invalid/v3/index.json"}}, &mockConsole{})
+	var unsupported *project.UnsupportedProjectError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Run() error = %v, want *project.UnsupportedProjectError", err)
+	}
+	if !strings.Contains(err.Error(), "detected a legacy (non-SDK) project targeting .NETPortable v4.5") {
+		t.Errorf("Run() error = %v", err)
+	}
+}
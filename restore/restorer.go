@@ -5,17 +5,23 @@ package restore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/willibrandon/gonuget/cache"
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
 	"github.com/willibrandon/gonuget/core"
 	"github.com/willibrandon/gonuget/core/resolver"
 	"github.com/willibrandon/gonuget/frameworks"
+	nugethttp "github.com/willibrandon/gonuget/http"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/packaging"
 	"github.com/willibrandon/gonuget/version"
 )
 
@@ -26,6 +32,18 @@ type Restorer struct {
 	client  *core.Client
 	tracer  DiagnosticTracer // Diagnostic output tracer (enabled for diagnostic verbosity only)
 	logs    []LogMessage     // Collected warnings/errors during restore (for cache file)
+
+	graphWarnings []LogMessage // Target graph warnings not yet printed (see flushGraphWarnings)
+
+	phaseTracers []PhaseTracer // Restore milestone consumers (legacy log format)
+	restoreStart time.Time     // Start of the current project restore; the clock for every phase event
+
+	lockedVersions map[string]map[string]string // Package versions from packages.lock.json (TFM -> lowercase ID -> version)
+	lockedHashes   map[string]string            // Content hashes from packages.lock.json (lowercase "id/version" -> hash)
+	pinnedVersions map[string]string            // Central versions of transitive packages with transitive pinning (lowercase ID -> range)
+
+	lockWait   time.Duration // Time spent waiting for other processes' package folder locks in the current project restore
+	lockWaitMu sync.Mutex    // Guards lockWait, added to by concurrent package installs
 }
 
 // NewRestorer creates a new restorer.
@@ -40,6 +58,7 @@ func NewRestorer(opts *Options, console Console) *Restorer {
 		for _, source := range opts.Sources {
 			// Get or create repository from global cache (avoids protocol detection on every restore!)
 			repo := core.GetOrCreateRepository(source)
+			repo.SetProtocolVersion(opts.SourceProtocolVersions[source])
 			if err := repoManager.AddRepository(repo); err != nil {
 				console.Warning(fmt.Sprintf("Failed to add repository %s: %v", source, err))
 			}
@@ -48,16 +67,24 @@ func NewRestorer(opts *Options, console Console) *Restorer {
 
 	// Create client
 	client := core.NewClient(core.ClientConfig{
-		RepositoryManager: repoManager,
+		RepositoryManager:  repoManager,
+		CredentialProvider: opts.CredentialProvider,
 	})
 
-	return &Restorer{
+	restorer := &Restorer{
 		opts:    opts,
 		console: console,
 		client:  client,
 		tracer:  NewResolutionTracer(console, opts.Verbosity),
 		logs:    make([]LogMessage, 0),
 	}
+
+	// nuget.exe milestone lines are printed in addition to the regular output
+	if opts.LegacyLogFormat {
+		restorer.phaseTracers = append(restorer.phaseTracers, &legacyLogTracer{console: console})
+	}
+
+	return restorer
 }
 
 // Restore executes the restore operation with full transitive dependency resolution.
@@ -67,6 +94,20 @@ func (r *Restorer) Restore(
 	proj *project.Project,
 	packageRefs []project.PackageReference,
 ) (*Result, error) {
+	r.restoreStart = time.Now()
+
+	// RestoreNoCache: bypass the HTTP cache for every source request of this restore
+	if r.opts.NoCache {
+		cacheCtx := cache.NewSourceCacheContext()
+		cacheCtx.NoCache = true
+		ctx = cache.WithCacheContext(ctx, cacheCtx)
+	}
+
+	// Detailed: report each source request retried after a transient failure
+	if r.logsDownloads() {
+		ctx = nugethttp.WithRetryObserver(ctx, r.logRetry)
+	}
+
 	result := &Result{
 		DirectPackages:     make([]PackageInfo, 0, len(packageRefs)),
 		TransitivePackages: make([]PackageInfo, 0),
@@ -74,7 +115,7 @@ func (r *Restorer) Restore(
 	}
 
 	// Initialize performance timing in diagnostic mode
-	isDiagnostic := r.opts.Verbosity == "diagnostic" || r.opts.Verbosity == "diag"
+	isDiagnostic := r.opts.Verbosity >= observability.VerbosityDiagnostic
 	if isDiagnostic {
 		result.PerformanceTiming = &PerformanceTiming{
 			ResolutionTimings: make(map[string]time.Duration),
@@ -83,12 +124,20 @@ func (r *Restorer) Restore(
 		}
 	}
 
+	// packages.lock.json is used when enabled or when one already exists
+	lockPath := r.lockFilePath(proj)
+	existingLock, err := LoadPackagesLockFile(lockPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read packages lock file: %w", err)
+	}
+	useLockFile := r.opts.UseLockFile || existingLock != nil
+
 	// Phase 0: No-op optimization (cache check)
 	// Matches RestoreCommand.EvaluateNoOpAsync (line 442-501)
 	cachePath := GetCacheFilePath(proj.Path)
 
 	// Calculate current hash
-	currentHash, err := CalculateDgSpecHash(proj)
+	currentHash, err := r.calculateDgSpecHash(proj)
 	if err != nil {
 		// If we can't calculate hash, just proceed with full restore
 		r.console.Warning("Failed to calculate dgspec hash: %v\n", err)
@@ -97,12 +146,12 @@ func (r *Restorer) Restore(
 		cacheValid, cachedFile, err := IsCacheValid(cachePath, currentHash)
 		if err != nil {
 			r.console.Warning("Failed to validate cache: %v\n", err)
-		} else if cacheValid && !r.opts.Force {
+		} else if cacheValid && r.noOpAllowed(useLockFile, existingLock) && assetsFileExists(proj.Path) {
 			// Cache hit! Return cached result without doing restore
 			// (Message will be printed by Run() function)
 
 			// Diagnostic: Show project-level cache hit
-			isDiagnostic := r.opts.Verbosity == "diagnostic" || r.opts.Verbosity == "diag"
+			isDiagnostic := r.opts.Verbosity >= observability.VerbosityDiagnostic
 			if isDiagnostic {
 				r.console.Printf("Project restore cache hit (dgspec hash: %s)\n", currentHash)
 				r.console.Printf("  Using cached restore result from: %s\n", cachePath)
@@ -154,7 +203,7 @@ func (r *Restorer) Restore(
 				info := PackageInfo{
 					ID:       id,
 					Version:  version,
-					Path:     filepath.Join(packagesFolder, normalizedID, version),
+					Path:     packageInstallPath(packagesFolder, id, version),
 					IsDirect: isDirect,
 				}
 
@@ -197,6 +246,8 @@ func (r *Restorer) Restore(
 
 fullRestore:
 	// Cache miss or invalid - proceed with full restore
+	r.tracePhase(PhaseRestoreStarted, proj.Path, "")
+
 	// Get global packages folder
 	packagesFolder := r.opts.PackagesFolder
 	if packagesFolder == "" {
@@ -218,6 +269,43 @@ fullRestore:
 		return nil, fmt.Errorf("project has no target frameworks")
 	}
 
+	// Several items for the same package restore the first one (NU1504)
+	r.warnDuplicatePackageReferences(proj)
+
+	// Items that don't follow Central Package Management fail the restore (NU1008-NU1010)
+	if cpmErrors, err := centralPackageErrors(proj); err == nil && len(cpmErrors) > 0 {
+		for _, cpmErr := range cpmErrors {
+			result.Errors = append(result.Errors, cpmErr)
+			r.addErrorLog(cpmErr, "")
+		}
+		if currentHash != "" {
+			r.writeCacheFileOnError(proj, currentHash, cachePath)
+		}
+		return result, fmt.Errorf("restore failed with %d error(s)", len(result.Errors))
+	}
+
+	// With transitive pinning, PackageVersion items also constrain transitive packages
+	r.pinnedVersions = centralTransitivePins(proj, packageRefs)
+
+	// Unreachable sources fail the restore (NU1301) unless failures are ignored (NU1801)
+	if sourceErrors := r.checkSources(ctx, proj.Path); len(sourceErrors) > 0 {
+		result.Errors = append(result.Errors, sourceErrors...)
+		if currentHash != "" {
+			r.writeCacheFileOnError(proj, currentHash, cachePath)
+		}
+		return result, fmt.Errorf("restore failed with %d error(s)", len(result.Errors))
+	}
+
+	// Honor an up-to-date lock file; --force-evaluate re-resolves and regenerates it
+	if lockErr := r.evaluateLockFile(proj, packageRefs, useLockFile, existingLock); lockErr != nil {
+		result.Errors = append(result.Errors, lockErr)
+		r.addErrorLog(lockErr, "")
+		if currentHash != "" {
+			r.writeCacheFileOnError(proj, currentHash, cachePath)
+		}
+		return result, fmt.Errorf("restore failed with %d error(s)", len(result.Errors))
+	}
+
 	// Initialize FrameworkResults for multi-TFM support
 	result.FrameworkResults = make(map[string]*FrameworkResult)
 
@@ -237,7 +325,7 @@ fullRestore:
 
 	// Loop through ALL target frameworks and restore each
 	// Matches NuGet.Client RestoreCommand.GenerateRestoreGraphsAsync (creates one graph per framework)
-	isDiagnostic = r.opts.Verbosity == "diagnostic" || r.opts.Verbosity == "diag"
+	isDiagnostic = r.opts.Verbosity >= observability.VerbosityDiagnostic
 	for _, targetFrameworkStr := range targetFrameworkStrings {
 		// Parse target framework
 		targetFramework, err := frameworks.ParseFramework(targetFrameworkStr)
@@ -278,6 +366,9 @@ fullRestore:
 		}
 	}
 
+	// Warnings raised by several graphs are printed once, listing the graphs
+	r.flushGraphWarnings()
+
 	// Check if any frameworks had errors
 	if len(result.Errors) > 0 {
 		// Write cache file even on error (matches NuGet.Client behavior)
@@ -295,43 +386,52 @@ fullRestore:
 	// This maintains compatibility with existing code that expects flat lists
 	allResolvedPackages := allResolvedPackagesUnion
 
-	// Phase 2: Download all resolved packages (direct + transitive)
+	// Phase 2: Download all resolved packages (direct + transitive), several at a time
 	// Matches ProjectRestoreCommand.InstallPackagesAsync behavior
 	downloadStart := time.Now()
-	for _, pkgInfo := range allResolvedPackages {
-		normalizedID := strings.ToLower(pkgInfo.ID)
-		packagePath := filepath.Join(packagesFolder, normalizedID, pkgInfo.Version)
+	r.lockWait = 0
+	var downloadErrs []error
+	for _, download := range r.downloadPackages(ctx, proj.Path, packagesFolder, allResolvedPackages) {
+		pkgInfo := download.pkg
 
-		// Check if package already exists in cache
-		cacheHit := false
-		if !r.opts.Force {
-			if _, err := os.Stat(packagePath); err == nil {
-				cacheHit = true
-			}
-		}
-
-		// Record cache hit status
+		// Record cache hit status and per-package download timing
 		if isDiagnostic && result.PerformanceTiming != nil {
-			result.PerformanceTiming.CacheHits[pkgInfo.ID] = cacheHit
+			result.PerformanceTiming.CacheHits[pkgInfo.ID] = download.cacheHit
+			result.PerformanceTiming.DownloadTimings[pkgInfo.ID] = download.elapsed
 		}
 
-		// Time individual package download
-		pkgDownloadStart := time.Now()
-
-		// Download package (pass original ID for display, with cache hit flag for logging)
-		if err := r.downloadPackage(ctx, pkgInfo.ID, pkgInfo.Version, packagePath, cacheHit); err != nil {
-			return nil, fmt.Errorf("failed to download package %s %s: %w", pkgInfo.ID, pkgInfo.Version, err)
+		// Every failed package is reported, not only the first
+		if errors.Is(download.err, packaging.ErrPackageHashMismatch) {
+			result.Errors = append(result.Errors, NewContentHashValidationError(proj.Path, pkgInfo.ID, pkgInfo.Version))
+			continue
+		}
+		if download.err != nil {
+			downloadErrs = append(downloadErrs, fmt.Errorf("failed to download package %s %s: %w", pkgInfo.ID, pkgInfo.Version, download.err))
+			continue
 		}
 
-		// Record per-package download timing
-		if isDiagnostic && result.PerformanceTiming != nil {
-			result.PerformanceTiming.DownloadTimings[pkgInfo.ID] = time.Since(pkgDownloadStart)
+		if download.mismatch != nil && r.reportSourceHashMismatch(download.mismatch) {
+			// Don't keep the package: a later restore would treat it as a cache hit and skip the check
+			_ = os.RemoveAll(download.path)
+			result.Errors = append(result.Errors, download.mismatch)
 		}
 	}
+	if len(downloadErrs) > 0 {
+		return nil, errors.Join(downloadErrs...)
+	}
 
 	// Record total download timing
 	if isDiagnostic && result.PerformanceTiming != nil {
 		result.PerformanceTiming.PackageDownloads = time.Since(downloadStart)
+		result.PerformanceTiming.LockWait = r.lockWait
+	}
+
+	// Content hash and strict source hash verification failures fail the restore
+	if len(result.Errors) > 0 {
+		if currentHash != "" {
+			r.writeCacheFileOnError(proj, currentHash, cachePath)
+		}
+		return result, fmt.Errorf("restore failed with %d error(s)", len(result.Errors))
 	}
 
 	// Phase 3: Categorize packages as direct vs transitive
@@ -339,7 +439,7 @@ fullRestore:
 	// This matches NuGet.Client behavior and cache hit path
 	for _, pkgInfo := range allResolvedPackages {
 		normalizedID := strings.ToLower(pkgInfo.ID)
-		packagePath := filepath.Join(packagesFolder, normalizedID, pkgInfo.Version)
+		packagePath := packageInstallPath(packagesFolder, pkgInfo.ID, pkgInfo.Version)
 
 		// Check if this package ID was directly referenced in project file
 		isDirect := directPackageIDs[normalizedID]
@@ -358,41 +458,27 @@ fullRestore:
 		}
 	}
 
-	// Phase 4: Write cache file for no-op optimization
-	// Matches RestoreCommand.CommitCacheFileAsync (RestoreResult.cs line 296)
-	assetsStart := time.Now()
-	cachePath = GetCacheFilePath(proj.Path)
-
-	// Calculate hash
-	dgSpecHash, err := CalculateDgSpecHash(proj)
-	if err != nil {
-		// If we can't calculate hash, just proceed without cache
-		r.console.Warning("Failed to calculate dgspec hash: %v\n", err)
-	} else {
-		// Build expected package file paths (all .nupkg.sha512 files)
-		expectedPackageFiles := make([]string, 0, len(allResolvedPackages))
-		for _, pkgInfo := range allResolvedPackages {
-			normalizedID := strings.ToLower(pkgInfo.ID)
-			sha512Path := filepath.Join(packagesFolder, normalizedID, pkgInfo.Version,
-				fmt.Sprintf("%s.%s.nupkg.sha512", normalizedID, pkgInfo.Version))
-			expectedPackageFiles = append(expectedPackageFiles, sha512Path)
-		}
-
-		// Create cache file
-		cacheFile := &CacheFile{
-			Version:              CacheFileVersion,
-			DgSpecHash:           dgSpecHash,
-			Success:              true,
-			ProjectFilePath:      proj.Path,
-			ExpectedPackageFiles: expectedPackageFiles,
-			Logs:                 r.logs, // Collected warnings/errors during restore
+	// Phase 3b: Write packages.lock.json (locked mode fails instead of changing it)
+	if useLockFile {
+		if lockErr := r.commitLockFile(lockPath, existingLock, buildPackagesLockFile(packagesLockFileVersion(proj), packageRefs, r.pinnedVersions, result, packagesFolder), proj.Path); lockErr != nil {
+			result.Errors = append(result.Errors, lockErr)
+			r.addErrorLog(lockErr, "")
+			if currentHash != "" {
+				r.writeCacheFileOnError(proj, currentHash, cachePath)
+			}
+			return result, fmt.Errorf("restore failed with %d error(s)", len(result.Errors))
 		}
+	}
 
-		// Save cache file
-		if err := cacheFile.Save(cachePath); err != nil {
-			// Don't fail restore if cache write fails
-			r.console.Warning("Failed to write cache file: %v\n", err)
+	// Phase 4: Commit the restore outputs
+	// Matches RestoreResult.CommitAsync in NuGet.Client: the cache file is written last,
+	// so it never claims success for a restore whose assets file isn't in place
+	assetsStart := time.Now()
+	if err := r.commit(proj, result, packagesFolder, allResolvedPackages, cachePath); err != nil {
+		if currentHash != "" {
+			r.writeCacheFileOnError(proj, currentHash, cachePath)
 		}
+		return result, err
 	}
 
 	// Record assets generation timing
@@ -400,9 +486,68 @@ fullRestore:
 		result.PerformanceTiming.AssetsGeneration = time.Since(assetsStart)
 	}
 
+	// Phase 5: Record the known vulnerabilities of the resolved packages
+	r.writeAuditFile(ctx, proj, result)
+
 	return result, nil
 }
 
+// commit writes project.assets.json unless it is unchanged, then the dgspec snapshot and
+// the no-op cache file.
+func (r *Restorer) commit(
+	proj *project.Project,
+	result *Result,
+	packagesFolder string,
+	allResolvedPackages map[string]*resolver.PackageDependencyInfo,
+	cachePath string,
+) error {
+	r.tracePhase(PhaseCommitStarted, proj.Path, "")
+
+	assetsPath := GetAssetsFilePath(proj.Path)
+	lockFile := NewLockFileBuilder().WithPackagesFolder(packagesFolder).Build(proj, result)
+	lockFile.Logs = newAssetsLogMessages(proj.Path, r.logs)
+	written, err := lockFile.SaveIfChanged(assetsPath)
+	if err != nil {
+		return fmt.Errorf("failed to save project.assets.json: %w", err)
+	}
+	result.AssetsUnchanged = !written
+	if written {
+		r.tracePhase(PhaseAssetsWritten, proj.Path, assetsPath)
+	} else {
+		r.tracePhase(PhaseAssetsUnchanged, proj.Path, assetsPath)
+	}
+
+	dgSpecJSON, err := r.generateDgSpecJSON(proj)
+	if err != nil {
+		// If we can't calculate hash, just proceed without cache
+		r.console.Warning("Failed to calculate dgspec hash: %v\n", err)
+		return nil
+	}
+
+	// The snapshot is best-effort: it only feeds InspectNoOpCache
+	_ = os.WriteFile(GetDgSpecSnapshotPath(proj.Path), dgSpecJSON, 0644)
+
+	// Build expected package file paths (all .nupkg.sha512 files)
+	expectedPackageFiles := make([]string, 0, len(allResolvedPackages))
+	for _, pkgInfo := range allResolvedPackages {
+		expectedPackageFiles = append(expectedPackageFiles, packageHashPath(packagesFolder, pkgInfo.ID, pkgInfo.Version))
+	}
+
+	cacheFile := &CacheFile{
+		Version:              CacheFileVersion,
+		DgSpecHash:           hashDgSpecJSON(dgSpecJSON),
+		Success:              true,
+		ProjectFilePath:      proj.Path,
+		ExpectedPackageFiles: expectedPackageFiles,
+		Logs:                 r.logs, // Collected warnings/errors during restore
+	}
+	if err := cacheFile.Save(cachePath); err != nil {
+		// Don't fail restore if cache write fails
+		r.console.Warning("Failed to write cache file: %v\n", err)
+	}
+	return nil
+}
+
 // restoreFramework handles dependency resolution for a single target framework.
 // Matches NuGet.Client's WalkDependenciesAsync pattern.
 // Returns FrameworkResult with resolved packages for this framework.
@@ -440,6 +585,9 @@ func (r *Restorer) restoreFramework(
 	// Build list of package dependencies for multi-root resolution
 	packageDependencies := make([]resolver.PackageDependency, 0, len(packageRefs))
 
+	// Direct references whose lower bound no source has (NU1603 once resolved)
+	var approximateMatches []approximateMatch
+
 	// First pass: Validate all package versions exist (early failure optimization)
 	for _, pkgRef := range packageRefs {
 		versionRange := pkgRef.Version
@@ -447,14 +595,49 @@ func (r *Restorer) restoreFramework(
 			versionRange = "0.0.0" // Empty means any version >= 0.0.0
 		}
 
+		// A version locked in packages.lock.json wins; a floating version floats to the highest match
+		lockedVersion, isLocked := r.lockedVersions[targetFrameworkStr][strings.ToLower(pkgRef.Include)]
+		if isLocked {
+			versionRange = "[" + lockedVersion + "]"
+		}
+		floatRange := parseFloatingRange(versionRange)
+		availabilityRange := versionRange
+		if floatRange != nil {
+			availabilityRange = floatingLowerBound(floatRange)
+		}
+
 		// Diagnostic: Trace package resolution start
 		if isDiagnostic {
 			r.console.Printf("  %s %s (direct reference)\n", pkgRef.Include, versionRange)
 			r.console.Printf("    Constraint: %s\n", versionRange)
 		}
 
-		// OPTIMIZATION: Early version availability check
-		versionInfos, allVersions, allSourceNames, canSatisfy := r.checkVersionAvailability(ctx, pkgRef.Include, versionRange)
+		// A package packageSourceMapping maps to no source can't be restored (NU1100)
+		if nugetErr := r.checkPackageMapped(projectPath, pkgRef.Include, versionRange, targetFrameworkStr); nugetErr != nil {
+			frameworkResult.Errors = append(frameworkResult.Errors, nugetErr)
+			r.addErrorLog(nugetErr, targetFrameworkStr)
+			continue
+		}
+
+		// OPTIMIZATION: Early version availability check. A locked version that is already
+		// installed is resolved without asking the sources for their versions.
+		var versionInfos []VersionInfo
+		var allVersions, allSourceNames []string
+		canSatisfy := true
+		if !isLocked || !localProvider.isInstalled(pkgRef.Include, lockedVersion) {
+			versionInfos, allVersions, allSourceNames, canSatisfy = r.checkVersionAvailability(ctx, pkgRef.Include, availabilityRange)
+		}
+		if floatRange != nil && canSatisfy {
+			if best := bestFloatingVersion(floatRange, allVersions); best != nil {
+				// Pinned like a locked version: range bounds compare prerelease labels loosely
+				versionRange = "[" + best.String() + "]"
+				if isDiagnostic {
+					r.console.Printf("    Floating %s resolved to %s\n", floatRange, best)
+				}
+			} else {
+				canSatisfy = false
+			}
+		}
 
 		// Diagnostic: Show available versions (limit to last 10)
 		if isDiagnostic && len(allVersions) > 0 {
@@ -471,6 +654,8 @@ func (r *Restorer) restoreFramework(
 			switch {
 			case len(versionInfos) == 0:
 				nugetErr = NewPackageNotFoundError(projectPath, pkgRef.Include, versionRange, allSourceNames)
+			case floatRange != nil:
+				nugetErr = NewPackageVersionNotFoundError(projectPath, pkgRef.Include, versionRange, versionInfos)
 			case !isPrereleaseAllowed(versionRange) && hasPrereleaseVersionsOnly(versionRange, allVersions):
 				parsedRange, _ := version.ParseVersionRange(versionRange)
 				versionInfosNU1103 := r.updateNearestVersionForNU1103(versionInfos, allVersions, parsedRange)
@@ -490,6 +675,16 @@ func (r *Restorer) restoreFramework(
 			continue
 		}
 
+		if floatRange == nil && !isLocked {
+			if lower := missingLowerBound(versionRange, allVersions); lower != nil {
+				approximateMatches = append(approximateMatches, approximateMatch{
+					PackageID:    pkgRef.Include,
+					VersionRange: versionRange,
+					LowerBound:   lower,
+				})
+			}
+		}
+
 		// Add to package dependencies list
 		packageDependencies = append(packageDependencies, resolver.PackageDependency{
 			ID:           pkgRef.Include,
@@ -505,13 +700,18 @@ func (r *Restorer) restoreFramework(
 
 	// Phase 2: Resolve all dependencies together using multi-root resolution
 	// Create metadata client for resolver
-	metadataClient, err := r.createLocalFirstMetadataClient(localProvider, targetFramework)
+	metadataClient, err := r.createLocalFirstMetadataClient(localProvider, targetFramework, r.lockedVersions[targetFrameworkStr])
 	if err != nil {
 		return nil, fmt.Errorf("failed to create metadata client for %s: %w", targetFrameworkStr, err)
 	}
 
 	// Create resolver with conflict detection and resolution
 	res := resolver.NewResolver(metadataClient, r.opts.Sources, targetFrameworkStr)
+	res.SetAllowPrereleaseEverywhere(r.opts.AllowPrereleaseEverywhere)
+	res.SetPinnedVersions(r.pinnedVersions)
+	if shared := r.opts.resolverCaches.get(r.opts, len(r.lockedVersions[targetFrameworkStr]) > 0 || len(r.pinnedVersions) > 0); shared != nil {
+		res.SetCache(shared)
+	}
 	transitiveResolver := resolver.NewTransitiveResolver(res)
 
 	// Resolve all dependencies together (creates synthetic project root internally)
@@ -559,10 +759,12 @@ func (r *Restorer) restoreFramework(
 	// Store resolved packages in framework result
 	frameworkResult.allResolvedPackages = allResolvedPackages
 
+	r.warnApproximateMatches(projectPath, targetFrameworkStr, approximateMatches, allResolvedPackages)
+
 	// Categorize packages as direct vs transitive
 	for _, pkgInfo := range allResolvedPackages {
 		normalizedID := strings.ToLower(pkgInfo.ID)
-		packagePath := filepath.Join(packagesFolder, normalizedID, pkgInfo.Version)
+		packagePath := packageInstallPath(packagesFolder, pkgInfo.ID, pkgInfo.Version)
 
 		pkg := PackageInfo{
 			ID:       pkgInfo.ID,
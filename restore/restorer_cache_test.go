@@ -6,10 +6,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/observability"
 )
 
 func TestRestorer_Restore_WritesCacheFile(t *testing.T) {
@@ -244,3 +246,43 @@ func TestRestorer_Restore_ForceFlag_IgnoresCache(t *testing.T) {
 	assert.False(t, result.CacheHit, "restore with Force should not use cache")
 	assert.NotEmpty(t, result.DirectPackages)
 }
+
+func TestRun_UnchangedAssetsFileIsNotRewritten(t *testing.T) {
+	feed := newHermeticFeed(t)
+
+	tmpDir := t.TempDir()
+	projPath := writeSourcesTestProject(t, tmpDir, "Legacy.Log", "")
+	opts := &Options{
+		Sources:        []string{feed.URL + "/index.json"},
+		PackagesFolder: filepath.Join(tmpDir, "packages"),
+		NoCache:        true,
+		Verbosity:      observability.VerbosityMinimal,
+	}
+	require.NoError(t, Run(context.Background(), []string{projPath}, opts, &mockConsole{}))
+
+	// Age the outputs so a rewrite is visible regardless of the file system's timestamp
+	// resolution, then touch the project without changing its content
+	assetsPath := GetAssetsFilePath(projPath)
+	cachePath := GetCacheFilePath(projPath)
+	past := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(assetsPath, past, past))
+	require.NoError(t, os.Chtimes(cachePath, past, past))
+	require.NoError(t, os.Chtimes(projPath, time.Now(), time.Now()))
+
+	// The no-op check compares content, so force the restore to run again
+	opts.Force = true
+	opts.Verbosity = observability.VerbosityDetailed
+	console := &mockConsole{}
+	require.NoError(t, Run(context.Background(), []string{projPath}, opts, console))
+
+	info, err := os.Stat(assetsPath)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(past), "project.assets.json was rewritten: mtime %v, want %v", info.ModTime(), past)
+
+	info, err = os.Stat(cachePath)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().After(past), "the no-op cache file was not updated")
+
+	assert.True(t, containsMessage(console.messages, "Assets file has not changed. Skipping assets file writing. Path: "+assetsPath), "messages: %v", console.messages)
+	assert.False(t, containsMessage(console.messages, "Writing assets file to disk"), "messages: %v", console.messages)
+}
@@ -7,27 +7,37 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/observability"
 )
 
-// mockConsole implements Console interface for testing
+// mockConsole implements Console interface for testing. Like the real console it is
+// safe for concurrent use, as packages are installed in parallel.
 type mockConsole struct {
+	mu       sync.Mutex
 	messages []string
 	errors   []string
 	warnings []string
 }
 
 func (m *mockConsole) Printf(format string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.messages = append(m.messages, fmt.Sprintf(format, args...))
 }
 
 func (m *mockConsole) Error(format string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.errors = append(m.errors, fmt.Sprintf(format, args...))
 }
 
 func (m *mockConsole) Warning(format string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.warnings = append(m.warnings, fmt.Sprintf(format, args...))
 }
 
@@ -452,7 +462,7 @@ func TestRun_WithPackages(t *testing.T) {
 	opts := &Options{
 		PackagesFolder: packagesFolder,
 		Sources:        []string{"https://api.nuget.org/v3/index.json"},
-		Verbosity:      "detailed", // Required for "Determining" and "Restored" messages
+		Verbosity:      observability.VerbosityDetailed, // Required for "Determining" and "Restored" messages
 	}
 
 	err := Run(context.Background(), []string{projPath}, opts, console)
@@ -1121,7 +1131,7 @@ func TestRunWithResult_NoPackages(t *testing.T) {
 
 	console := &mockConsole{}
 	opts := &Options{
-		Verbosity: "normal",
+		Verbosity: observability.VerbosityNormal,
 	}
 
 	result, err := RunWithResult(context.Background(), []string{projPath}, opts, console)
@@ -1170,7 +1180,7 @@ func TestRunWithResult_WithPackage(t *testing.T) {
 
 	console := &mockConsole{}
 	opts := &Options{
-		Verbosity: "normal",
+		Verbosity: observability.VerbosityNormal,
 		Sources:   []string{"https://api.nuget.org/v3/index.json"},
 	}
 
@@ -1198,7 +1208,7 @@ func TestRunWithResult_WithPackage(t *testing.T) {
 func TestRunWithResult_NoProjectFile(t *testing.T) {
 	console := &mockConsole{}
 	opts := &Options{
-		Verbosity: "normal",
+		Verbosity: observability.VerbosityNormal,
 	}
 
 	// Call with empty args and empty directory - should fail to find project
@@ -0,0 +1,266 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/gonuget/cmd/gonuget/project"
+	"github.com/willibrandon/gonuget/core/resolver"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/solution"
+)
+
+// resolverCaches holds the package metadata caches of a solution restore, one for each
+// combination of sources, packages folder, source mapping and prerelease setting, so
+// projects restoring from the same sources with the same settings share their lookups.
+type resolverCaches struct {
+	mu     sync.Mutex
+	caches map[string]*resolver.WalkerCache
+}
+
+// newResolverCaches creates an empty set of caches.
+func newResolverCaches() *resolverCaches {
+	return &resolverCaches{caches: make(map[string]*resolver.WalkerCache)}
+}
+
+// get returns the cache for a restore with opts, or nil when there is none to share: for
+// a single project restore, or when locked is set because packages.lock.json or pinned
+// central versions make the lookups of the project its own.
+func (c *resolverCaches) get(opts *Options, locked bool) *resolver.WalkerCache {
+	if c == nil || locked {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s|%s|%t|%v|%t", strings.Join(opts.Sources, "\n"), opts.PackagesFolder, opts.NoCache,
+		opts.PackageSourceMapping, opts.AllowPrereleaseEverywhere)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cache, ok := c.caches[key]
+	if !ok {
+		cache = resolver.NewWalkerCache()
+		c.caches[key] = cache
+	}
+	return cache
+}
+
+// solutionProject is a project of a solution being restored.
+type solutionProject struct {
+	path string
+	proj *project.Project // nil when the project could not be loaded
+	err  error            // Why the project could not be loaded
+}
+
+// runSolution restores the projects of a .sln, .slnx or .slnf solution, each after the
+// projects it references, sharing package metadata lookups between them. A project that
+// fails doesn't stop the others unless opts.FailFast is set; the errors of all projects
+// are reported together, followed by a summary like dotnet's.
+func runSolution(ctx context.Context, solutionPath string, opts *Options, console Console) error {
+	start := time.Now()
+	isQuiet := opts.Verbosity == observability.VerbosityQuiet
+	isDetailed := opts.Verbosity >= observability.VerbosityDetailed
+	isDiagnostic := opts.Verbosity >= observability.VerbosityDiagnostic
+
+	sol, err := solution.ParseSolution(solutionPath)
+	if err != nil {
+		return fmt.Errorf("failed to parse solution %s: %w", solutionPath, err)
+	}
+	projects, err := orderSolutionProjects(sol.GetProjects())
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		if !isQuiet {
+			console.Printf("Nothing to restore\n")
+		}
+		return nil
+	}
+
+	shared := *opts
+	shared.resolverCaches = newResolverCaches()
+	opts = &shared
+
+	isTTY := (&RealTTYDetector{}).IsTTY(console.Output())
+	var restored []string // "Restored <path> (in N ms)." of each project the restore wrote
+	var report strings.Builder
+	errorCount, skipped := 0, 0
+	for i, p := range projects {
+		result, elapsed, err := restoreSolutionProject(ctx, p, opts, console)
+		switch {
+		case err != nil && result != nil && len(result.Errors) > 0:
+			report.WriteString(formatRestoreErrors(result.Errors, isTTY, isQuiet))
+			errorCount += len(result.Errors)
+		case err != nil:
+			line := fmt.Sprintf("    %s : error : %v", p.path, err)
+			if isQuiet {
+				line = strings.TrimPrefix(line, "    ")
+			}
+			report.WriteString(line + "\n")
+			errorCount++
+		case result != nil && !result.CacheHit:
+			restored = append(restored, fmt.Sprintf("Restored %s (in %d ms).", p.path, elapsed.Milliseconds()))
+		}
+		if err != nil && opts.FailFast {
+			skipped = len(projects) - i - 1
+			break
+		}
+	}
+
+	if errorCount > 0 {
+		if !isQuiet && !isTTY {
+			for _, line := range restored {
+				console.Printf("  %s\n", line)
+			}
+		}
+		console.Printf("%s", report.String())
+		if !isQuiet {
+			if skipped > 0 {
+				console.Printf("\n%d project(s) were not restored because of --fail-fast.\n", skipped)
+			}
+			console.Printf("\n%s", restoreFailedSummary(errorCount, time.Since(start), isTTY))
+		}
+		// The errors are printed; main.go prints nothing for an empty error
+		return fmt.Errorf("")
+	}
+
+	if isQuiet {
+		return nil
+	}
+	if isTTY {
+		console.Printf("Restore complete (%.1fs)\n", time.Since(start).Seconds())
+		if isDetailed && !isDiagnostic {
+			printSolutionSummary(console, "    ", restored)
+		}
+		const (
+			green = "\033[32;1m"
+			reset = "\033[0m"
+		)
+		console.Printf("\nRestore %ssucceeded%s in %.1fs\n", green, reset, time.Since(start).Seconds())
+		return nil
+	}
+	printSolutionSummary(console, "  ", restored)
+	return nil
+}
+
+// printSolutionSummary prints the projects the restore wrote, or that all projects were
+// up to date, the way dotnet's console logger does.
+func printSolutionSummary(console Console, indent string, restored []string) {
+	console.Printf("%sDetermining projects to restore...\n", indent)
+	for _, line := range restored {
+		console.Printf("%s%s\n", indent, line)
+	}
+	if len(restored) == 0 {
+		console.Printf("%sAll projects are up-to-date for restore.\n", indent)
+	}
+}
+
+// restoreSolutionProject restores one project of a solution with its own restore
+// properties and NuGet.config sources. The result is nil for a project with nothing to
+// restore and for a packages.config project.
+func restoreSolutionProject(ctx context.Context, p solutionProject, opts *Options, console Console) (*Result, time.Duration, error) {
+	if p.err != nil {
+		return nil, 0, p.err
+	}
+	proj := p.proj
+
+	opts = opts.withProjectProperties(proj.GetRestoreProperties())
+	opts, err := opts.withConfiguredSources(filepath.Dir(proj.Path))
+	if err != nil {
+		return nil, 0, err
+	}
+	if proj.Style == project.StylePackagesConfig {
+		return nil, 0, restorePackagesConfig(ctx, proj, opts, console)
+	}
+
+	packageRefs, _, err := packageReferences(proj)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load package references: %w", err)
+	}
+	if len(packageRefs) == 0 {
+		return nil, 0, nil
+	}
+
+	restorer := NewRestorer(opts, console)
+	termStatus := NewTerminalStatus(console.Output(), filepath.Base(proj.Path), nil)
+	result, err := restorer.Restore(ctx, proj, packageRefs)
+	termStatus.Stop()
+	return result, restorer.restoreElapsed(), err
+}
+
+// orderSolutionProjects loads the projects of a solution and orders them so that each
+// comes after the projects of the solution it references, keeping the solution order
+// otherwise. A project that can't be loaded is kept, with its error.
+func orderSolutionProjects(paths []string) ([]solutionProject, error) {
+	var projects []solutionProject
+	index := make(map[string]int) // Lowercase path -> position in projects
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			absPath = path
+		}
+		key := strings.ToLower(absPath)
+		if _, seen := index[key]; seen {
+			continue
+		}
+		index[key] = len(projects)
+
+		p := solutionProject{path: absPath}
+		if p.proj, err = project.LoadProject(absPath); err != nil {
+			p.err = fmt.Errorf("failed to load project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+
+	// Depth-first in solution order: a project is added once its references are
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(projects))
+	ordered := make([]solutionProject, 0, len(projects))
+	var chain []int
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := chain[slices.Index(chain, i):]
+			names := make([]string, 0, len(cycle)+1)
+			for _, j := range append(cycle, i) {
+				names = append(names, filepath.Base(projects[j].path))
+			}
+			return fmt.Errorf("circular project references: %s", strings.Join(names, " -> "))
+		}
+
+		state[i] = visiting
+		chain = append(chain, i)
+		if proj := projects[i].proj; proj != nil {
+			for _, ref := range proj.GetProjectReferences() {
+				refPath := filepath.Join(filepath.Dir(projects[i].path), solution.ConvertToSystemPath(ref.Include))
+				// References to projects outside the solution don't order anything
+				if j, ok := index[strings.ToLower(refPath)]; ok {
+					if err := visit(j); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		chain = chain[:len(chain)-1]
+		state[i] = visited
+		ordered = append(ordered, projects[i])
+		return nil
+	}
+
+	for i := range projects {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,208 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeSolutionProject writes an SDK-style project under root/src/<name> referencing
+// packageID 1.0.0 and the given sibling projects.
+func writeSolutionProject(t *testing.T, root, name, packageID string, projectRefs ...string) string {
+	t.Helper()
+	var refs strings.Builder
+	for _, ref := range projectRefs {
+		fmt.Fprintf(&refs, "    <ProjectReference Include=\"..\\%s\\%s.csproj\" />\n", ref, ref)
+	}
+	path := filepath.Join(root, "src", name, name+".csproj")
+	writeStyleFile(t, path, fmt.Sprintf(`<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="%s" Version="1.0.0" />
+%s  </ItemGroup>
+</Project>`, packageID, refs.String()))
+	return path
+}
+
+// writeSolution writes root/App.sln listing the projects under root/src in the given order.
+func writeSolution(t *testing.T, root string, names ...string) string {
+	t.Helper()
+	var sln strings.Builder
+	sln.WriteString("\nMicrosoft Visual Studio Solution File, Format Version 12.00\n# Visual Studio Version 17\n")
+	for i, name := range names {
+		fmt.Fprintf(&sln, "Project(\"{9A19103F-16F7-4668-BE54-9A1E7A4F7556}\") = %q, \"src\\%s\\%s.csproj\", \"{%08d-1111-1111-1111-111111111111}\"\nEndProject\n", name, name, name, i+1)
+	}
+	sln.WriteString("Global\nEndGlobal\n")
+	path := filepath.Join(root, "App.sln")
+	writeStyleFile(t, path, sln.String())
+	return path
+}
+
+// restoredProjects returns the names of the projects in the "Restored" lines of messages.
+func restoredProjects(messages []string) []string {
+	var names []string
+	for _, msg := range messages {
+		if _, rest, ok := strings.Cut(msg, "Restored "); ok {
+			path, _, _ := strings.Cut(rest, " (in ")
+			names = append(names, strings.TrimSuffix(filepath.Base(path), ".csproj"))
+		}
+	}
+	return names
+}
+
+func TestRun_Solution_ReferencedProjectsFirst(t *testing.T) {
+	feed := newStylesFeed(t)
+	root := t.TempDir()
+	writeSolutionProject(t, root, "App", "Contoso.Core", "Lib")
+	writeSolutionProject(t, root, "Lib", "Contoso.Core")
+	slnPath := writeSolution(t, root, "App", "Lib")
+
+	opts := &Options{
+		Sources:        []string{feed.SourceURL()},
+		PackagesFolder: filepath.Join(root, "global-packages"),
+		NoCache:        true,
+	}
+	console := &mockConsole{}
+	if err := Run(context.Background(), []string{slnPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	if got, want := restoredProjects(console.messages), []string{"Lib", "App"}; !slices.Equal(got, want) {
+		t.Errorf("restored %v, want %v\noutput: %v", got, want, console.messages)
+	}
+	for _, name := range []string{"App", "Lib"} {
+		if _, err := os.Stat(GetAssetsFilePath(filepath.Join(root, "src", name, name+".csproj"))); err != nil {
+			t.Errorf("%s not restored: %v", name, err)
+		}
+	}
+
+	// A second restore finds every project up to date
+	console = &mockConsole{}
+	if err := Run(context.Background(), []string{root}, opts, console); err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if !containsMessage(console.messages, "All projects are up-to-date for restore.") {
+		t.Errorf("second output = %v, want all projects up to date", console.messages)
+	}
+}
+
+func TestRun_Solution_FailedProject(t *testing.T) {
+	feed := newStylesFeed(t)
+	root := t.TempDir()
+	writeSolutionProject(t, root, "Broken", "Missing.Package")
+	writeSolutionProject(t, root, "App", "Contoso.Core")
+	slnPath := writeSolution(t, root, "Broken", "Missing", "App")
+
+	tests := []struct {
+		name         string
+		failFast     bool
+		wantRestored []string
+		wantErrors   []string
+	}{
+		{
+			name:         "keeps going",
+			wantRestored: []string{"App"},
+			wantErrors:   []string{"NU1101", "Missing.Package", "failed to load project", "failed with 2 error(s)"},
+		},
+		{
+			name:       "fail fast",
+			failFast:   true,
+			wantErrors: []string{"NU1101", "2 project(s) were not restored because of --fail-fast", "failed with 1 error(s)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &Options{
+				Sources:        []string{feed.SourceURL()},
+				PackagesFolder: filepath.Join(t.TempDir(), "global-packages"),
+				NoCache:        true,
+				Force:          true,
+				FailFast:       tt.failFast,
+			}
+			console := &mockConsole{}
+			if err := Run(context.Background(), []string{slnPath}, opts, console); err == nil {
+				t.Fatalf("Run() succeeded, want an error\noutput: %v", console.messages)
+			}
+
+			if got := restoredProjects(console.messages); !slices.Equal(got, tt.wantRestored) {
+				t.Errorf("restored %v, want %v\noutput: %v", got, tt.wantRestored, console.messages)
+			}
+			output := strings.Join(console.messages, "")
+			for _, want := range tt.wantErrors {
+				if !strings.Contains(output, want) {
+					t.Errorf("output missing %q:\n%s", want, output)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_Slnx(t *testing.T) {
+	feed := newStylesFeed(t)
+	root := t.TempDir()
+	writeSolutionProject(t, root, "Lib", "Contoso.Core")
+	writeStyleFile(t, filepath.Join(root, "App.slnx"), `<Solution>
+  <Folder Name="/src/">
+    <Project Path="src/Lib/Lib.csproj" />
+  </Folder>
+</Solution>`)
+
+	opts := &Options{
+		Sources:        []string{feed.SourceURL()},
+		PackagesFolder: filepath.Join(root, "global-packages"),
+		NoCache:        true,
+	}
+	console := &mockConsole{}
+	if err := Run(context.Background(), []string{filepath.Join(root, "App.slnx")}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+	if got := restoredProjects(console.messages); !slices.Equal(got, []string{"Lib"}) {
+		t.Errorf("restored %v, want [Lib]\noutput: %v", got, console.messages)
+	}
+}
+
+func TestOrderSolutionProjects_Cycle(t *testing.T) {
+	root := t.TempDir()
+	a := writeSolutionProject(t, root, "A", "Contoso.Core", "B")
+	b := writeSolutionProject(t, root, "B", "Contoso.Core", "A")
+
+	_, err := orderSolutionProjects([]string{a, b})
+	if err == nil || !strings.Contains(err.Error(), "circular project references: A.csproj -> B.csproj -> A.csproj") {
+		t.Errorf("orderSolutionProjects() error = %v, want the cycle named", err)
+	}
+}
+
+func TestResolverCaches_Get(t *testing.T) {
+	caches := newResolverCaches()
+	opts := &Options{Sources: []string{"https://api.nuget.org/v3/index.json"}}
+
+	first := caches.get(opts, false)
+	if first == nil {
+		t.Fatal("get() = nil, want a cache")
+	}
+	sameSettings := *opts
+	if got := caches.get(&sameSettings, false); got != first {
+		t.Error("get() returned another cache for a project with the same settings")
+	}
+
+	prerelease := *opts
+	prerelease.AllowPrereleaseEverywhere = true
+	if got := caches.get(&prerelease, false); got == first {
+		t.Error("get() shared the cache with a project that allows prerelease versions everywhere")
+	}
+
+	if got := caches.get(opts, true); got != nil {
+		t.Error("get() returned a cache for a locked project")
+	}
+	var none *resolverCaches
+	if got := none.get(opts, false); got != nil {
+		t.Error("get() returned a cache outside a solution restore")
+	}
+}
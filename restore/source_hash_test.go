@@ -0,0 +1,115 @@
+package restore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFlatContainerFeed serves a minimal V3 feed that returns content for every .nupkg request.
+// A nil content means the feed doesn't have the package.
+func newFlatContainerFeed(t *testing.T, content []byte) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/index.json":
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"version": "3.0.0",
+				"resources": []map[string]string{
+					{"@id": "http://" + r.Host + "/flat/", "@type": "PackageBaseAddress/3.0.0"},
+				},
+			})
+		case strings.HasSuffix(r.URL.Path, ".nupkg") && content != nil:
+			_, _ = w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestVerifySourceHashes(t *testing.T) {
+	tests := []struct {
+		name         string
+		feeds        [][]byte
+		installed    int // Index of the feed the package was installed from
+		wantMismatch bool
+	}{
+		{name: "single source", feeds: [][]byte{[]byte("a")}},
+		{name: "identical content", feeds: [][]byte{[]byte("a"), []byte("a")}},
+		{name: "missing on second source", feeds: [][]byte{[]byte("a"), nil}},
+		{name: "different content", feeds: [][]byte{[]byte("a"), []byte("b")}, wantMismatch: true},
+		{name: "mismatch on third source", feeds: [][]byte{[]byte("a"), []byte("a"), []byte("c")}, wantMismatch: true},
+		{name: "installed from a later source", feeds: [][]byte{[]byte("a"), []byte("b")}, installed: 1, wantMismatch: true},
+		{name: "install source no longer has it", feeds: [][]byte{[]byte("a"), []byte("b"), nil}, installed: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sources []string
+			for _, content := range tt.feeds {
+				sources = append(sources, newFlatContainerFeed(t, content).URL+"/index.json")
+			}
+
+			console := &mockConsole{}
+			r := NewRestorer(&Options{Sources: sources, VerifySourceHashes: true}, console)
+
+			mismatch := r.verifySourceHashes(context.Background(), "/proj/app.csproj", "Test.Package", "1.0.0", sources[tt.installed])
+			if (mismatch != nil) != tt.wantMismatch {
+				t.Fatalf("verifySourceHashes() = %v, want mismatch %v", mismatch, tt.wantMismatch)
+			}
+			if mismatch == nil {
+				return
+			}
+
+			if mismatch.Code != ErrorCodeSourceHashMismatch {
+				t.Errorf("Code = %s, want %s", mismatch.Code, ErrorCodeSourceHashMismatch)
+			}
+			if !strings.Contains(mismatch.Message, "Test.Package 1.0.0") {
+				t.Errorf("Message = %q, want package identity", mismatch.Message)
+			}
+			if !strings.HasSuffix(mismatch.Message, "The package from "+sources[tt.installed]+" was used") {
+				t.Errorf("Message = %q, want install source reported as used", mismatch.Message)
+			}
+		})
+	}
+}
+
+func TestReportSourceHashMismatch(t *testing.T) {
+	mismatch := NewSourceHashMismatchError("/proj/app.csproj", "Test.Package", "1.0.0", "https://a/index.json",
+		[]SourceHash{{Source: "https://a/index.json", Hash: "AAAA"}, {Source: "https://b/index.json", Hash: "BBBB"}})
+
+	t.Run("warning by default", func(t *testing.T) {
+		console := &mockConsole{}
+		r := &Restorer{opts: &Options{VerifySourceHashes: true}, console: console}
+
+		if r.reportSourceHashMismatch(mismatch) {
+			t.Error("reportSourceHashMismatch() = true, want false without strict mode")
+		}
+		if len(r.logs) != 1 || r.logs[0].Level != "Warning" || r.logs[0].Code != "GN1001" {
+			t.Errorf("logs = %+v, want one GN1001 warning", r.logs)
+		}
+		if len(console.messages) != 1 || !strings.Contains(console.messages[0], "warning GN1001") {
+			t.Errorf("console = %v, want printed GN1001 warning", console.messages)
+		}
+	})
+
+	t.Run("error in strict mode", func(t *testing.T) {
+		console := &mockConsole{}
+		r := &Restorer{opts: &Options{StrictSourceHashes: true}, console: console}
+
+		if !r.reportSourceHashMismatch(mismatch) {
+			t.Error("reportSourceHashMismatch() = false, want true in strict mode")
+		}
+		if len(r.logs) != 1 || r.logs[0].Level != "Error" {
+			t.Errorf("logs = %+v, want one error", r.logs)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/willibrandon/gonuget/core"
+	nugethttp "github.com/willibrandon/gonuget/http"
+)
+
+// sourceName returns the configured name of a source URL, or the URL itself
+func (r *Restorer) sourceName(sourceURL string) string {
+	if name, ok := r.opts.SourceNames[sourceURL]; ok {
+		return name
+	}
+	for configured, name := range r.opts.SourceNames {
+		if nugethttp.SameSourceURL(configured, sourceURL) {
+			return name
+		}
+	}
+	return sourceURL
+}
+
+// sourceAllowed reports whether packageSourceMapping lets packageID be restored from
+// sourceURL. Every source is allowed when no mapping is configured.
+func (r *Restorer) sourceAllowed(packageID, sourceURL string) bool {
+	if r.opts.PackageSourceMapping == nil {
+		return true
+	}
+	name := r.sourceName(sourceURL)
+	return slices.ContainsFunc(r.opts.PackageSourceMapping.SourcesFor(packageID), func(source string) bool {
+		return strings.EqualFold(source, name)
+	})
+}
+
+// packageRepositories returns the repositories packageID may be restored from, in
+// source order.
+func (r *Restorer) packageRepositories(packageID string) []*core.SourceRepository {
+	repos := r.client.GetRepositoryManager().ListRepositories()
+	if r.opts.PackageSourceMapping == nil {
+		return repos
+	}
+	return slices.DeleteFunc(slices.Clone(repos), func(repo *core.SourceRepository) bool {
+		return !r.sourceAllowed(packageID, repo.SourceURL())
+	})
+}
+
+// packageSourceURLs returns the URLs of the sources packageID may be restored from
+func (r *Restorer) packageSourceURLs(packageID string) []string {
+	var urls []string
+	for _, repo := range r.packageRepositories(packageID) {
+		urls = append(urls, repo.SourceURL())
+	}
+	return urls
+}
+
+// downloadFromSources downloads a package from the first of its sources that has it, and
+// returns the URL of that source
+func (r *Restorer) downloadFromSources(ctx context.Context, packageID, packageVersion string) (io.ReadCloser, string, error) {
+	repos := r.packageRepositories(packageID)
+	if len(repos) == 0 {
+		return nil, "", fmt.Errorf("no package sources configured for %s", packageID)
+	}
+
+	var lastErr error
+	for _, repo := range repos {
+		body, err := repo.DownloadPackage(ctx, nil, packageID, packageVersion)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return body, repo.SourceURL(), nil
+	}
+	return nil, "", fmt.Errorf("download failed: %w", lastErr)
+}
+
+// checkPackageMapped returns a NU1100 error when packageSourceMapping is enabled and
+// none of its patterns match packageID, or nil. Matches NuGet.Client, which considers
+// no source for such a package and lists every configured source as skipped.
+func (r *Restorer) checkPackageMapped(projectPath, packageID, versionRange, targetFramework string) *NuGetError {
+	mapping := r.opts.PackageSourceMapping
+	if mapping == nil || mapping.SourcesFor(packageID) != nil {
+		return nil
+	}
+
+	var sources []string
+	for _, repo := range r.client.GetRepositoryManager().ListRepositories() {
+		sources = append(sources, r.sourceName(repo.SourceURL()))
+	}
+	return NewUnmappedPackageError(projectPath, packageID, versionRange, targetFramework, sources)
+}
@@ -0,0 +1,141 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/willibrandon/gonuget/auth"
+	"github.com/willibrandon/gonuget/auth/credentialprovider"
+	"github.com/willibrandon/gonuget/cmd/gonuget/config"
+)
+
+// withConfiguredSources returns a copy of the options completed from NuGet.config: the
+// ConfigFile when set, otherwise the config hierarchy of projectDir (its directory and
+// parents, then the user and machine-wide configs), as dotnet restore reads it.
+// Without Sources the enabled packageSources of the configs are used, merged with
+// clear/add semantics; nuget.org is used when no config has packageSources. Without a
+// CredentialProvider the packageSourceCredentials of the configs, the
+// NuGetPackageSourceCredentials_<name> environment variables and, for other sources,
+// credential provider plugins are supplied to sources that answer 401. Without a PackageSourceMapping the packageSourceMapping of
+// the configs is enforced.
+func (o *Options) withConfiguredSources(projectDir string) (*Options, error) {
+	var layers []config.ConfigLayer
+	if o.ConfigFile != "" {
+		cfg, err := config.LoadNuGetConfig(o.ConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config file '%s': %w", o.ConfigFile, err)
+		}
+		layers = []config.ConfigLayer{{Path: o.ConfigFile, Config: cfg}}
+	} else {
+		layers = config.LoadConfigLayers(projectDir)
+	}
+
+	merged := *o
+	if len(merged.Sources) == 0 {
+		sources := config.MergeEnabledPackageSources(layers)
+		if !slices.ContainsFunc(layers, func(layer config.ConfigLayer) bool { return layer.Config.PackageSources != nil }) {
+			sources = config.DefaultPackageSources()
+		}
+
+		merged.SourceProtocolVersions = make(map[string]string)
+		for _, source := range sources {
+			merged.Sources = append(merged.Sources, source.Value)
+			if source.ProtocolVersion != "" {
+				merged.SourceProtocolVersions[source.Value] = source.ProtocolVersion
+			}
+		}
+	}
+
+	if merged.PackageSourceMapping == nil {
+		mapping, err := config.MergeSourceMapping(layers)
+		if err != nil {
+			return nil, err
+		}
+		merged.PackageSourceMapping = mapping
+	}
+	if merged.SourceNames == nil {
+		merged.SourceNames = make(map[string]string)
+		for _, source := range config.MergePackageSources(layers) {
+			merged.SourceNames[source.Value] = source.Key
+		}
+	}
+
+	// A repository given a credential provider drops its detected protocol, so one is
+	// only created when the configs, the environment or a plugin have credentials to offer.
+	// Credential provider plugins are asked only for sources without configured credentials.
+	if merged.CredentialProvider == nil {
+		var providers auth.CredentialProviders
+		if config.HasSourceCredentials(layers) {
+			providers = append(providers, config.NewCredentialProvider(layers))
+		}
+		if plugins := credentialprovider.Discover(); len(plugins) > 0 {
+			providers = append(providers, credentialprovider.NewProvider(plugins))
+		}
+		if len(providers) == 1 {
+			merged.CredentialProvider = providers[0]
+		} else if len(providers) > 1 {
+			merged.CredentialProvider = providers
+		}
+	}
+
+	return &merged, nil
+}
+
+// checkSources loads the service index of every source before dependency resolution.
+// Matches NuGet.Client: an unreachable source fails the restore with NU1301. With
+// IgnoreFailedSources it is reported as warning NU1801 instead and left out of the
+// restore, so packages are resolved from the remaining sources.
+// Returns the NU1301 errors; nil if every source is usable or failures are ignored.
+func (r *Restorer) checkSources(ctx context.Context, projectPath string) []*NuGetError {
+	repoManager := r.client.GetRepositoryManager()
+	repos := repoManager.ListRepositories()
+
+	// Load all service indexes concurrently; results are reported in source order
+	failures := make([]error, len(repos))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Go(func() {
+			_, failures[i] = repo.GetProvider(ctx)
+		})
+	}
+	wg.Wait()
+
+	var errs []*NuGetError
+	for i, repo := range repos {
+		if failures[i] == nil {
+			continue
+		}
+
+		nugetErr := NewSourceUnreachableError(projectPath, repo.SourceURL(), failures[i])
+		if !r.opts.IgnoreFailedSources {
+			r.addLog(LogMessage{
+				Code:        nugetErr.Code,
+				Level:       "Error",
+				Message:     nugetErr.Message,
+				ProjectPath: projectPath,
+				FilePath:    projectPath,
+			})
+			errs = append(errs, nugetErr)
+			continue
+		}
+
+		log := LogMessage{
+			Code:        ErrorCodeIgnoredSourceFailure,
+			Level:       "Warning",
+			Message:     nugetErr.Message,
+			ProjectPath: projectPath,
+			FilePath:    projectPath,
+		}
+		r.addLog(log)
+		r.printWarningLog(&log)
+
+		_ = repoManager.RemoveRepository(repo.Name())
+		r.opts.Sources = slices.DeleteFunc(slices.Clone(r.opts.Sources), func(source string) bool {
+			return source == repo.SourceURL()
+		})
+	}
+
+	return errs
+}
@@ -0,0 +1,464 @@
+package restore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/willibrandon/gonuget/auth"
+	"github.com/willibrandon/gonuget/auth/credentialprovider"
+	"github.com/willibrandon/gonuget/observability"
+)
+
+// newDeadSource returns the service index URL of a server that is no longer listening.
+func newDeadSource(t *testing.T) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	deadURL := server.URL + "/index.json"
+	server.Close()
+	return deadURL
+}
+
+// writeSourcesTestProject writes a net8.0 project referencing packageID 1.0.0 with extra properties.
+func writeSourcesTestProject(t *testing.T, dir, packageID, properties string) string {
+	t.Helper()
+
+	projPath := filepath.Join(dir, "app.csproj")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+    ` + properties + `
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="` + packageID + `" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return projPath
+}
+
+func containsMessage(messages []string, substr string) bool {
+	return slices.ContainsFunc(messages, func(msg string) bool { return strings.Contains(msg, substr) })
+}
+
+func TestRun_FailedSource_NU1301(t *testing.T) {
+	feed := newHermeticFeed(t)
+	deadSource := newDeadSource(t)
+
+	tmpDir := t.TempDir()
+	projPath := writeSourcesTestProject(t, tmpDir, "Legacy.Log", "")
+
+	console := &mockConsole{}
+	opts := &Options{
+		Sources:        []string{deadSource, feed.URL + "/index.json"},
+		PackagesFolder: filepath.Join(tmpDir, "packages"),
+		Verbosity:      observability.VerbosityMinimal,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err == nil {
+		t.Fatal("Run() expected error for an unreachable source")
+	}
+
+	if !containsMessage(console.messages, "error NU1301: Unable to load the service index for source "+deadSource) {
+		t.Errorf("NU1301 not reported: %v", console.messages)
+	}
+}
+
+func TestRun_IgnoreFailedSources_WarnsAndRestores(t *testing.T) {
+	feed := newHermeticFeed(t)
+	deadSource := newDeadSource(t)
+
+	tmpDir := t.TempDir()
+	projPath := writeSourcesTestProject(t, tmpDir, "Legacy.Log", `<RestoreIgnoreFailedSources>true</RestoreIgnoreFailedSources>`)
+
+	console := &mockConsole{}
+	opts := &Options{
+		Sources:        []string{deadSource, feed.URL + "/index.json"},
+		PackagesFolder: filepath.Join(tmpDir, "packages"),
+		Verbosity:      observability.VerbosityMinimal,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	if !containsMessage(console.messages, "warning NU1801: Unable to load the service index for source "+deadSource) {
+		t.Errorf("NU1801 not reported: %v", console.messages)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "packages", "legacy.log", "1.0.0", "legacy.log.1.0.0.nupkg")); err != nil {
+		t.Errorf("package not installed from the remaining source: %v", err)
+	}
+
+	// The caller's source list is left alone
+	if len(opts.Sources) != 2 {
+		t.Errorf("opts.Sources = %v, want both sources", opts.Sources)
+	}
+}
+
+func TestRun_IgnoreFailedSources_MissingPackageStillFails(t *testing.T) {
+	feed := newHermeticFeed(t)
+	deadSource := newDeadSource(t)
+
+	tmpDir := t.TempDir()
+	projPath := writeSourcesTestProject(t, tmpDir, "Missing.Package", "")
+
+	console := &mockConsole{}
+	opts := &Options{
+		Sources:             []string{deadSource, feed.URL + "/index.json"},
+		PackagesFolder:      filepath.Join(tmpDir, "packages"),
+		Verbosity:           observability.VerbosityMinimal,
+		IgnoreFailedSources: true,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err == nil {
+		t.Fatal("Run() expected error for a package that no source has")
+	}
+
+	if !containsMessage(console.messages, "warning NU1801") {
+		t.Errorf("NU1801 not reported: %v", console.messages)
+	}
+	if !containsMessage(console.messages, "error NU1101: Unable to find package Missing.Package") {
+		t.Errorf("NU1101 not reported: %v", console.messages)
+	}
+}
+
+func TestRun_RestorePackagesPath(t *testing.T) {
+	feed := newHermeticFeed(t)
+
+	tmpDir := t.TempDir()
+	projPath := writeSourcesTestProject(t, tmpDir, "Legacy.Log", `<RestorePackagesPath>project-packages</RestorePackagesPath>`)
+
+	console := &mockConsole{}
+	opts := &Options{
+		Sources:   []string{feed.URL + "/index.json"},
+		Verbosity: observability.VerbosityMinimal,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "project-packages", "legacy.log", "1.0.0", "legacy.log.1.0.0.nupkg")); err != nil {
+		t.Errorf("package not installed into RestorePackagesPath: %v", err)
+	}
+}
+
+func TestRun_SourcesFromNuGetConfig(t *testing.T) {
+	feed := newHermeticFeed(t)
+	feedURL, err := url.Parse(feed.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The private feed asks for the credentials of the config
+	var authorized atomic.Bool
+	proxy := httputil.NewSingleHostReverseProxy(feedURL)
+	private := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "ci" || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="feed"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		authorized.Store(true)
+		proxy.ServeHTTP(w, r)
+	}))
+	defer private.Close()
+
+	root := t.TempDir()
+	projDir := filepath.Join(root, "src", "app")
+	if err := os.MkdirAll(projDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	projPath := writeSourcesTestProject(t, projDir, "Legacy.Log", "")
+
+	// The solution config clears the user sources and adds the private feed; a disabled
+	// source from the project config is skipped
+	solutionConfig := `<configuration>
+  <packageSources>
+    <clear />
+    <add key="private" value="` + private.URL + `/index.json" />
+  </packageSources>
+  <packageSourceCredentials>
+    <private>
+      <add key="Username" value="ci" />
+      <add key="ClearTextPassword" value="secret" />
+    </private>
+  </packageSourceCredentials>
+</configuration>`
+	projectConfig := `<configuration>
+  <packageSources>
+    <add key="dead" value="` + newDeadSource(t) + `" />
+  </packageSources>
+  <disabledPackageSources>
+    <add key="dead" value="true" />
+  </disabledPackageSources>
+</configuration>`
+	if err := os.WriteFile(filepath.Join(root, "NuGet.Config"), []byte(solutionConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projDir, "NuGet.Config"), []byte(projectConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	console := &mockConsole{}
+	opts := &Options{
+		PackagesFolder: filepath.Join(root, "packages"),
+		NoCache:        true,
+		Verbosity:      observability.VerbosityMinimal,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "packages", "legacy.log", "1.0.0", "legacy.log.1.0.0.nupkg")); err != nil {
+		t.Errorf("package not installed from the configured feed: %v", err)
+	}
+	if !authorized.Load() {
+		t.Error("the configured credentials were not sent to the private feed")
+	}
+}
+
+func TestRun_SourceCredentialsFromEnvironment(t *testing.T) {
+	feed := newHermeticFeed(t)
+	feedURL, err := url.Parse(feed.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Like Azure Artifacts, any username is accepted with the personal access token,
+	// and the service index itself needs it
+	proxy := httputil.NewSingleHostReverseProxy(feedURL)
+	private := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, ok := r.BasicAuth(); !ok || password != "pat-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	defer private.Close()
+
+	root := t.TempDir()
+	projPath := writeSourcesTestProject(t, root, "Legacy.Log", "")
+	config := `<configuration>
+  <packageSources>
+    <clear />
+    <add key="private feed" value="` + private.URL + `/index.json" />
+  </packageSources>
+</configuration>`
+	if err := os.WriteFile(filepath.Join(root, "NuGet.Config"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NuGetPackageSourceCredentials_private feed", "Username=az;Password=pat-token")
+
+	console := &mockConsole{}
+	opts := &Options{
+		PackagesFolder: filepath.Join(root, "packages"),
+		NoCache:        true,
+		Verbosity:      observability.VerbosityMinimal,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+	if _, err := os.Stat(filepath.Join(root, "packages", "legacy.log", "1.0.0", "legacy.log.1.0.0.nupkg")); err != nil {
+		t.Errorf("package not installed from the authenticated feed: %v", err)
+	}
+}
+
+// newCountingSource returns the service index URL of a proxy to feed that counts the
+// requests for package content and metadata, leaving out the service index.
+func newCountingSource(t *testing.T, feed *httptest.Server, requests *atomic.Int32) string {
+	t.Helper()
+
+	feedURL, err := url.Parse(feed.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(feedURL)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.json" {
+			requests.Add(1)
+		}
+		proxy.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/index.json"
+}
+
+// writePackageSourceMappingConfig writes a NuGet.Config with the public and private
+// sources and a packageSourceMapping section.
+func writePackageSourceMappingConfig(t *testing.T, dir, publicURL, privateURL, mapping string) {
+	t.Helper()
+
+	content := `<configuration>
+  <packageSources>
+    <clear />
+    <add key="public" value="` + publicURL + `" />
+    <add key="private" value="` + privateURL + `" />
+  </packageSources>
+  <packageSourceMapping>` + mapping + `</packageSourceMapping>
+</configuration>`
+	if err := os.WriteFile(filepath.Join(dir, "NuGet.Config"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_PackageSourceMapping(t *testing.T) {
+	feed := newHermeticFeed(t)
+	var publicRequests, privateRequests atomic.Int32
+	publicURL := newCountingSource(t, feed, &publicRequests)
+	privateURL := newCountingSource(t, feed, &privateRequests)
+
+	// Both sources have the package, but it is mapped to the second one only
+	dir := t.TempDir()
+	projPath := writeSourcesTestProject(t, dir, "Legacy.Log", "")
+	writePackageSourceMappingConfig(t, dir, publicURL, privateURL, `
+    <packageSource key="public"><package pattern="*" /></packageSource>
+    <packageSource key="private"><package pattern="Legacy.*" /></packageSource>`)
+
+	console := &mockConsole{}
+	opts := &Options{
+		PackagesFolder: filepath.Join(dir, "packages"),
+		NoCache:        true,
+		Verbosity:      observability.VerbosityMinimal,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %v", err, console.messages)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "packages", "legacy.log", "1.0.0", "legacy.log.1.0.0.nupkg")); err != nil {
+		t.Errorf("package not installed: %v", err)
+	}
+	if privateRequests.Load() == 0 {
+		t.Error("the mapped source was not queried")
+	}
+	if n := publicRequests.Load(); n != 0 {
+		t.Errorf("the unmapped source got %d package requests, want none", n)
+	}
+}
+
+func TestRun_PackageSourceMapping_Unmapped(t *testing.T) {
+	feed := newHermeticFeed(t)
+	var publicRequests, privateRequests atomic.Int32
+	publicURL := newCountingSource(t, feed, &publicRequests)
+	privateURL := newCountingSource(t, feed, &privateRequests)
+
+	dir := t.TempDir()
+	projPath := writeSourcesTestProject(t, dir, "Legacy.Log", "")
+	writePackageSourceMappingConfig(t, dir, publicURL, privateURL, `
+    <packageSource key="private"><package pattern="Contoso.*" /></packageSource>`)
+
+	console := &mockConsole{}
+	opts := &Options{
+		PackagesFolder: filepath.Join(dir, "packages"),
+		NoCache:        true,
+		Verbosity:      observability.VerbosityMinimal,
+	}
+	if err := Run(context.Background(), []string{projPath}, opts, console); err == nil {
+		t.Fatal("Run() expected error for a package mapped to no source")
+	}
+
+	want := "error NU1100: Unable to resolve 'Legacy.Log (>= 1.0.0)' for 'net8.0'. PackageSourceMapping is enabled, the following source(s) were not considered: public, private."
+	if !containsMessage(console.messages, want) {
+		t.Errorf("NU1100 not reported: %v", console.messages)
+	}
+	if n := publicRequests.Load() + privateRequests.Load(); n != 0 {
+		t.Errorf("sources got %d package requests, want none", n)
+	}
+}
+
+func TestOptions_WithConfiguredSources(t *testing.T) {
+	dir := t.TempDir()
+	// No credential provider plugins
+	t.Setenv(credentialprovider.PluginPathsEnv, filepath.Join(dir, "no-plugins"))
+	configFile := filepath.Join(dir, "NuGet.Config")
+	content := `<configuration><packageSources>
+  <add key="a" value="https://a.example/v3/index.json" protocolVersion="3" />
+  <add key="b" value="https://b.example/api/v2" protocolVersion="2" />
+</packageSources></configuration>`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := (&Options{ConfigFile: configFile}).withConfiguredSources(dir)
+	if err != nil {
+		t.Fatalf("withConfiguredSources() error = %v", err)
+	}
+	if want := []string{"https://a.example/v3/index.json", "https://b.example/api/v2"}; !slices.Equal(opts.Sources, want) {
+		t.Errorf("Sources = %v, want %v", opts.Sources, want)
+	}
+	if got := opts.SourceProtocolVersions["https://b.example/api/v2"]; got != "2" {
+		t.Errorf("protocol version of b = %q, want 2", got)
+	}
+	if opts.CredentialProvider != nil {
+		t.Error("CredentialProvider set without configured credentials")
+	}
+	if got := opts.SourceNames["https://b.example/api/v2"]; got != "b" {
+		t.Errorf("name of b = %q, want b", got)
+	}
+	if opts.PackageSourceMapping != nil {
+		t.Error("PackageSourceMapping set without a packageSourceMapping section")
+	}
+
+	// Sources given by the caller are kept
+	opts, err = (&Options{ConfigFile: configFile, Sources: []string{"https://c.example/v3/index.json"}}).withConfiguredSources(dir)
+	if err != nil {
+		t.Fatalf("withConfiguredSources() error = %v", err)
+	}
+	if want := []string{"https://c.example/v3/index.json"}; !slices.Equal(opts.Sources, want) {
+		t.Errorf("Sources = %v, want %v", opts.Sources, want)
+	}
+
+	if _, err := (&Options{ConfigFile: filepath.Join(dir, "missing.config")}).withConfiguredSources(dir); err == nil {
+		t.Error("withConfiguredSources() expected error for a missing config file")
+	}
+}
+
+func TestOptions_WithConfiguredSources_CredentialPlugins(t *testing.T) {
+	dir := t.TempDir()
+	plugin := filepath.Join(dir, "plugins", "CredentialProvider.Microsoft.dll")
+	if err := os.MkdirAll(filepath.Dir(plugin), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(plugin, []byte("plugin"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(credentialprovider.PluginPathsEnv, plugin)
+
+	configFile := filepath.Join(dir, "NuGet.Config")
+	content := `<configuration><packageSources>
+  <add key="feed" value="https://pkgs.example/v3/index.json" />
+</packageSources></configuration>`
+	if err := os.WriteFile(configFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without configured credentials the plugins supply them
+	opts, err := (&Options{ConfigFile: configFile}).withConfiguredSources(dir)
+	if err != nil {
+		t.Fatalf("withConfiguredSources() error = %v", err)
+	}
+	if _, ok := opts.CredentialProvider.(*credentialprovider.Provider); !ok {
+		t.Errorf("CredentialProvider = %T, want the plugin provider", opts.CredentialProvider)
+	}
+
+	// Configured credentials are asked first
+	t.Setenv("NuGetPackageSourceCredentials_feed", "Username=user;Password=token")
+	opts, err = (&Options{ConfigFile: configFile}).withConfiguredSources(dir)
+	if err != nil {
+		t.Fatalf("withConfiguredSources() error = %v", err)
+	}
+	providers, ok := opts.CredentialProvider.(auth.CredentialProviders)
+	if !ok || len(providers) != 2 {
+		t.Fatalf("CredentialProvider = %#v, want the config and plugin providers", opts.CredentialProvider)
+	}
+	if _, ok := providers[1].(*credentialprovider.Provider); !ok {
+		t.Errorf("last provider = %T, want the plugin provider", providers[1])
+	}
+}
@@ -19,11 +19,20 @@ type TerminalStatus struct {
 	ticker      *time.Ticker
 	start       time.Time
 	done        chan struct{}
+	exited      chan struct{}
+	status      statusWriter // draws the status through the console when output supports it
 	projectName string
 	stopped     bool
 	mu          sync.Mutex // Protects concurrent writes to output
 }
 
+// statusWriter is implemented by console outputs that draw the status line themselves,
+// clearing and redrawing it around lines written by other goroutines.
+type statusWriter interface {
+	SetStatus(line string)
+	ClearStatus()
+}
+
 // NewTerminalStatus creates a new terminal status updater
 // If detector is nil, DefaultTTYDetector is used
 func NewTerminalStatus(output io.Writer, projectName string, detector TTYDetector) *TerminalStatus {
@@ -47,8 +56,10 @@ func NewTerminalStatus(output io.Writer, projectName string, detector TTYDetecto
 		width:       width,
 		start:       time.Now(),
 		done:        make(chan struct{}),
+		exited:      make(chan struct{}),
 		projectName: projectName,
 	}
+	t.status, _ = output.(statusWriter)
 
 	if isTTY {
 		t.ticker = time.NewTicker(33 * time.Millisecond) // 30Hz
@@ -60,6 +71,7 @@ func NewTerminalStatus(output io.Writer, projectName string, detector TTYDetecto
 
 // updateLoop runs in background, updating status at 30Hz
 func (t *TerminalStatus) updateLoop() {
+	defer close(t.exited)
 	for {
 		select {
 		case <-t.ticker.C:
@@ -88,9 +100,13 @@ func (t *TerminalStatus) updateStatus() {
 	backwardCount := len(status)
 
 	// Hide cursor, position, write, show cursor
+	line := fmt.Sprintf("\x1B[?25l\x1B[%dG\x1B[%dD%s\r\x1B[?25h", column, backwardCount, status)
+	if t.status != nil {
+		t.status.SetStatus(line)
+		return
+	}
 	t.mu.Lock()
-	_, _ = fmt.Fprintf(t.output, "\x1B[?25l\x1B[%dG\x1B[%dD%s\r\x1B[?25h",
-		column, backwardCount, status)
+	_, _ = fmt.Fprint(t.output, line)
 	t.mu.Unlock()
 }
 
@@ -105,8 +121,14 @@ func (t *TerminalStatus) Stop() {
 	if t.ticker != nil {
 		t.ticker.Stop()
 		close(t.done)
+		// Wait for an in-flight update so it can't redraw the status after it is cleared
+		<-t.exited
 	}
 
+	if t.status != nil {
+		t.status.ClearStatus()
+		return
+	}
 	if t.isTTY {
 		// Clear to end of line
 		t.mu.Lock()
@@ -1,6 +1,8 @@
 package restore
 
 import (
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -53,3 +55,54 @@ func TestTerminalStatus_IsTTY(t *testing.T) {
 		})
 	}
 }
+
+// recordingStatusWriter records status calls the way a console output would receive them.
+type recordingStatusWriter struct {
+	mu    sync.Mutex
+	calls []string
+	raw   strings.Builder
+}
+
+func (w *recordingStatusWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.raw.Write(p)
+}
+
+func (w *recordingStatusWriter) SetStatus(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.calls = append(w.calls, "set")
+}
+
+func (w *recordingStatusWriter) ClearStatus() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.calls = append(w.calls, "clear")
+}
+
+func TestTerminalStatus_DrawsThroughStatusWriter(t *testing.T) {
+	out := &recordingStatusWriter{}
+	status := NewTerminalStatus(out, "test.csproj", &mockTTYDetector{isTTY: true, width: 120})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		out.mu.Lock()
+		n := len(out.calls)
+		out.mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	status.Stop()
+
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	if len(out.calls) < 2 || out.calls[0] != "set" || out.calls[len(out.calls)-1] != "clear" {
+		t.Errorf("status calls = %v, want set ... clear", out.calls)
+	}
+	if out.raw.Len() != 0 {
+		t.Errorf("status was written directly to the output: %q", out.raw.String())
+	}
+}
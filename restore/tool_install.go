@@ -0,0 +1,277 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/willibrandon/gonuget/core"
+	"github.com/willibrandon/gonuget/frameworks"
+	"github.com/willibrandon/gonuget/observability"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// toolStoreFolder is the folder under the tool path that holds extracted tool packages.
+// Matches the dotnet CLI tool store layout: <tool-path>/.store/<id>/<version>/<id>/<version>/
+const toolStoreFolder = ".store"
+
+// ToolInstallOptions holds configuration for installing a .NET tool package.
+type ToolInstallOptions struct {
+	PackageID  string
+	Version    string // Exact version or version range; empty selects the latest version
+	ToolPath   string // Custom tool location; mutually exclusive with Global
+	Global     bool   // Install into the user-wide tool location (~/.dotnet/tools)
+	Sources    []string
+	Prerelease bool
+	Verbosity  observability.Verbosity
+}
+
+// ToolInstallResult describes an installed tool package.
+type ToolInstallResult struct {
+	PackageID         string
+	Version           string
+	ToolPath          string // Root tool location (contains .store)
+	InstallPath       string // Extracted package directory
+	SettingsPath      string // Absolute path of the DotnetToolSettings.xml used
+	TargetFramework   string
+	RuntimeIdentifier string
+	Commands          []packaging.DotnetToolCommand
+
+	// AlreadyInstalled indicates the tool was present in the store and not re-extracted
+	AlreadyInstalled bool
+}
+
+// GetGlobalToolPath returns the user-wide .NET tools directory.
+func GetGlobalToolPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".dotnet", "tools"), nil
+}
+
+// GetToolStoreRoot returns the per-version store root for a tool package.
+// The package itself is laid out beneath it using the version folder layout.
+func GetToolStoreRoot(toolPath, packageID string, ver *version.NuGetVersion) string {
+	return filepath.Join(toolPath, toolStoreFolder, strings.ToLower(packageID), strings.ToLower(ver.ToNormalizedString()))
+}
+
+// InstallTool resolves a DotnetTool package, extracts it into the tool store layout
+// and reads the commands declared by its DotnetToolSettings.xml.
+// Shims are not created; callers report the commands to the user.
+func InstallTool(ctx context.Context, opts *ToolInstallOptions, console Console) (*ToolInstallResult, error) {
+	if opts.PackageID == "" {
+		return nil, fmt.Errorf("package ID is required")
+	}
+	if opts.Global && opts.ToolPath != "" {
+		return nil, fmt.Errorf("--global and --tool-path cannot be used together")
+	}
+	if !opts.Global && opts.ToolPath == "" {
+		return nil, fmt.Errorf("either --global or --tool-path must be specified")
+	}
+	if len(opts.Sources) == 0 {
+		return nil, fmt.Errorf("no package sources configured")
+	}
+
+	toolPath := opts.ToolPath
+	if opts.Global {
+		globalPath, err := GetGlobalToolPath()
+		if err != nil {
+			return nil, err
+		}
+		toolPath = globalPath
+	}
+
+	absToolPath, err := filepath.Abs(toolPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve tool path: %w", err)
+	}
+
+	repoManager := core.NewRepositoryManager()
+	for _, source := range opts.Sources {
+		if err := repoManager.AddRepository(core.GetOrCreateRepository(source)); err != nil {
+			console.Warning("Failed to add repository %s: %v\n", source, err)
+		}
+	}
+	client := core.NewClient(core.ClientConfig{RepositoryManager: repoManager})
+
+	resolved, err := resolveToolVersion(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+	ver, source := resolved.Version, resolved.Repository
+
+	storeRoot := GetToolStoreRoot(absToolPath, opts.PackageID, ver)
+	pathResolver := packaging.NewVersionFolderPathResolver(storeRoot, true)
+	identity := &packaging.PackageIdentity{ID: opts.PackageID, Version: ver}
+
+	copyToAsync := func(targetPath string) error {
+		stream, err := source.DownloadPackage(ctx, nil, opts.PackageID, ver.ToNormalizedString())
+		if err != nil {
+			return fmt.Errorf("download package: %w", err)
+		}
+		defer func() { _ = stream.Close() }()
+
+		outFile, err := os.Create(targetPath)
+		if err != nil {
+			return fmt.Errorf("create temp file: %w", err)
+		}
+		defer func() { _ = outFile.Close() }()
+
+		if _, err := io.Copy(outFile, stream); err != nil {
+			return fmt.Errorf("write package: %w", err)
+		}
+		return nil
+	}
+
+	extractionContext := &packaging.PackageExtractionContext{
+		PackageSaveMode:    packaging.PackageSaveModeNupkg | packaging.PackageSaveModeNuspec | packaging.PackageSaveModeFiles,
+		XMLDocFileSaveMode: packaging.XMLDocFileSaveModeNone,
+		Logger:             &lockLogger{console: console, verbose: logsLockWaits(opts.Verbosity)},
+	}
+
+	// .nupkg.metadata records the source the package is downloaded from
+	installed, err := packaging.InstallFromSourceV3(ctx, source.SourceURL(), identity, copyToAsync, pathResolver, extractionContext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to install tool package %s %s: %w", opts.PackageID, ver.ToNormalizedString(), err)
+	}
+
+	installPath := pathResolver.GetInstallPath(opts.PackageID, ver)
+	result, err := readInstalledTool(pathResolver.GetPackageFilePath(opts.PackageID, ver), installPath)
+	if err != nil {
+		// Don't leave a non-tool package behind in the store
+		if installed {
+			_ = os.RemoveAll(storeRoot)
+		}
+		return nil, err
+	}
+
+	result.PackageID = opts.PackageID
+	result.Version = ver.ToNormalizedString()
+	result.ToolPath = absToolPath
+	result.InstallPath = installPath
+	result.AlreadyInstalled = !installed
+
+	return result, nil
+}
+
+// resolveToolVersion picks the version to install from the configured sources, and the
+// first source that has it.
+func resolveToolVersion(ctx context.Context, client *core.Client, opts *ToolInstallOptions) (core.ResolvedVersion, error) {
+	repositories := client.GetRepositoryManager().ListRepositories()
+
+	if opts.Version != "" {
+		versionRange, err := version.ParseVersionRange(opts.Version)
+		if err != nil {
+			return core.ResolvedVersion{}, fmt.Errorf("invalid version or range: %s", opts.Version)
+		}
+		// A plain version asks for that exact version, as in ResolvePackageVersion
+		if exactVer, err := version.Parse(opts.Version); err == nil {
+			versionRange = &version.Range{MinVersion: exactVer, MaxVersion: exactVer, MinInclusive: true, MaxInclusive: true}
+		}
+
+		resolved, err := core.ResolveVersion(ctx, nil, opts.PackageID, versionRange, core.ResolveVersionOptions{
+			Repositories:      repositories,
+			Selection:         core.SelectLowest,
+			IncludePrerelease: opts.Prerelease,
+			IncludeUnlisted:   true,
+		})
+		if err != nil {
+			return core.ResolvedVersion{}, fmt.Errorf("failed to resolve version %s for %s: %w", opts.Version, opts.PackageID, err)
+		}
+		return resolved, nil
+	}
+
+	// Without a version, the latest listed version is installed
+	resolved, err := core.ResolveVersion(ctx, nil, opts.PackageID, nil, core.ResolveVersionOptions{
+		Repositories:      repositories,
+		Selection:         core.SelectHighest,
+		IncludePrerelease: opts.Prerelease,
+	})
+	if err != nil {
+		var notFound *core.VersionNotFoundError
+		if errors.As(err, &notFound) && notFound.PackageFound() {
+			if !opts.Prerelease {
+				return core.ResolvedVersion{}, fmt.Errorf("no stable version found for tool '%s'. Use --prerelease to include prerelease versions", opts.PackageID)
+			}
+			return core.ResolvedVersion{}, fmt.Errorf("no versions found for tool '%s'", opts.PackageID)
+		}
+		return core.ResolvedVersion{}, fmt.Errorf("failed to list versions for %s: %w", opts.PackageID, err)
+	}
+
+	return resolved, nil
+}
+
+// readInstalledTool validates the package type and parses its tool settings.
+func readInstalledTool(nupkgPath, installPath string) (*ToolInstallResult, error) {
+	reader, err := packaging.OpenPackage(nupkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("open tool package: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	nuspec, err := reader.GetNuspec()
+	if err != nil {
+		return nil, fmt.Errorf("read nuspec: %w", err)
+	}
+
+	if !nuspec.IsDotnetTool() {
+		return nil, fmt.Errorf("package %s is not a .NET tool (missing %s package type)", nuspec.Metadata.ID, packaging.PackageTypeDotnetTool)
+	}
+
+	settingsFile := selectToolSettingsFile(reader.GetDotnetToolSettingsFiles())
+	if settingsFile == nil {
+		return nil, fmt.Errorf("package %s does not contain tools/<tfm>/<rid>/%s", nuspec.Metadata.ID, packaging.DotnetToolSettingsFileName)
+	}
+
+	settings, err := reader.ReadDotnetToolSettings(settingsFile.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ToolInstallResult{
+		SettingsPath:      filepath.Join(installPath, filepath.FromSlash(settingsFile.Path)),
+		TargetFramework:   settingsFile.TargetFramework,
+		RuntimeIdentifier: settingsFile.RuntimeIdentifier,
+		Commands:          settings.Commands,
+	}, nil
+}
+
+// selectToolSettingsFile prefers the portable ("any") RID and the highest target framework.
+func selectToolSettingsFile(files []packaging.DotnetToolSettingsFile) *packaging.DotnetToolSettingsFile {
+	var best *packaging.DotnetToolSettingsFile
+	var bestFramework *frameworks.NuGetFramework
+
+	for i := range files {
+		candidate := &files[i]
+		fw, err := frameworks.ParseFramework(candidate.TargetFramework)
+		if err != nil {
+			continue
+		}
+
+		if best == nil {
+			best, bestFramework = candidate, fw
+			continue
+		}
+
+		candidateAny := strings.EqualFold(candidate.RuntimeIdentifier, "any")
+		bestAny := strings.EqualFold(best.RuntimeIdentifier, "any")
+		if candidateAny != bestAny {
+			if candidateAny {
+				best, bestFramework = candidate, fw
+			}
+			continue
+		}
+
+		if fw.Version.Compare(bestFramework.Version) > 0 {
+			best, bestFramework = candidate, fw
+		}
+	}
+
+	return best
+}
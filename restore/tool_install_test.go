@@ -0,0 +1,195 @@
+package restore
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/http/nugethttptest"
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// writeTestNupkg writes a .nupkg with the given entries to path.
+func writeTestNupkg(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create nupkg: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		entry, err := w.Create(name)
+		if err != nil {
+			t.Fatalf("create entry %s: %v", name, err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatalf("write entry %s: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+}
+
+func toolNuspec(packageType string) string {
+	return `<?xml version="1.0"?>
+<package xmlns="http://schemas.microsoft.com/packaging/2013/05/nuspec.xsd">
+  <metadata>
+    <id>dotnetsay</id>
+    <version>2.1.4</version>
+    <description>A tool</description>
+    <authors>Test</authors>
+    <packageTypes><packageType name="` + packageType + `" /></packageTypes>
+  </metadata>
+</package>`
+}
+
+const toolSettingsXML = `<DotNetCliTool Version="1"><Commands><Command Name="dotnetsay" EntryPoint="dotnetsay.dll" Runner="dotnet" /></Commands></DotNetCliTool>`
+
+func TestGetToolStoreRoot(t *testing.T) {
+	root := GetToolStoreRoot("/tools", "DotnetSay", version.MustParse("2.1.4"))
+	want := filepath.Join("/tools", ".store", "dotnetsay", "2.1.4")
+	if root != want {
+		t.Errorf("GetToolStoreRoot() = %q, want %q", root, want)
+	}
+}
+
+func TestSelectToolSettingsFile(t *testing.T) {
+	files := []packaging.DotnetToolSettingsFile{
+		{Path: "tools/net8.0/win-x64/DotnetToolSettings.xml", TargetFramework: "net8.0", RuntimeIdentifier: "win-x64"},
+		{Path: "tools/net6.0/any/DotnetToolSettings.xml", TargetFramework: "net6.0", RuntimeIdentifier: "any"},
+		{Path: "tools/net7.0/any/DotnetToolSettings.xml", TargetFramework: "net7.0", RuntimeIdentifier: "any"},
+	}
+
+	selected := selectToolSettingsFile(files)
+	if selected == nil {
+		t.Fatal("selectToolSettingsFile() = nil")
+	}
+	if selected.TargetFramework != "net7.0" || selected.RuntimeIdentifier != "any" {
+		t.Errorf("selected %s/%s, want net7.0/any", selected.TargetFramework, selected.RuntimeIdentifier)
+	}
+
+	if selectToolSettingsFile(nil) != nil {
+		t.Error("selectToolSettingsFile(nil) should return nil")
+	}
+}
+
+func TestReadInstalledTool(t *testing.T) {
+	dir := t.TempDir()
+	nupkgPath := filepath.Join(dir, "dotnetsay.2.1.4.nupkg")
+	writeTestNupkg(t, nupkgPath, map[string]string{
+		"dotnetsay.nuspec":                        toolNuspec("DotnetTool"),
+		"tools/net8.0/any/DotnetToolSettings.xml": toolSettingsXML,
+		"tools/net8.0/any/dotnetsay.dll":          "binary",
+	})
+
+	result, err := readInstalledTool(nupkgPath, dir)
+	if err != nil {
+		t.Fatalf("readInstalledTool() error = %v", err)
+	}
+
+	if result.TargetFramework != "net8.0" || result.RuntimeIdentifier != "any" {
+		t.Errorf("settings location = %s/%s, want net8.0/any", result.TargetFramework, result.RuntimeIdentifier)
+	}
+	wantSettings := filepath.Join(dir, "tools", "net8.0", "any", "DotnetToolSettings.xml")
+	if result.SettingsPath != wantSettings {
+		t.Errorf("SettingsPath = %q, want %q", result.SettingsPath, wantSettings)
+	}
+	if len(result.Commands) != 1 || result.Commands[0].Name != "dotnetsay" {
+		t.Errorf("Commands = %+v, want [dotnetsay]", result.Commands)
+	}
+}
+
+func TestReadInstalledTool_NotATool(t *testing.T) {
+	dir := t.TempDir()
+	nupkgPath := filepath.Join(dir, "lib.1.0.0.nupkg")
+	writeTestNupkg(t, nupkgPath, map[string]string{
+		"dotnetsay.nuspec": toolNuspec("Dependency"),
+		"lib/net8.0/a.dll": "binary",
+	})
+
+	_, err := readInstalledTool(nupkgPath, dir)
+	if err == nil || !strings.Contains(err.Error(), "is not a .NET tool") {
+		t.Errorf("readInstalledTool() error = %v, want not a .NET tool", err)
+	}
+}
+
+func TestReadInstalledTool_MissingSettings(t *testing.T) {
+	dir := t.TempDir()
+	nupkgPath := filepath.Join(dir, "dotnetsay.2.1.4.nupkg")
+	writeTestNupkg(t, nupkgPath, map[string]string{
+		"dotnetsay.nuspec":               toolNuspec("DotnetTool"),
+		"tools/net8.0/any/dotnetsay.dll": "binary",
+	})
+
+	_, err := readInstalledTool(nupkgPath, dir)
+	if err == nil || !strings.Contains(err.Error(), packaging.DotnetToolSettingsFileName) {
+		t.Errorf("readInstalledTool() error = %v, want missing settings error", err)
+	}
+}
+
+func TestInstallTool_OptionValidation(t *testing.T) {
+	console := &mockConsole{}
+	tests := []struct {
+		name string
+		opts *ToolInstallOptions
+	}{
+		{"missing package ID", &ToolInstallOptions{ToolPath: "tools", Sources: []string{"https://example.com/index.json"}}},
+		{"global and tool path", &ToolInstallOptions{PackageID: "a", ToolPath: "tools", Global: true, Sources: []string{"https://example.com/index.json"}}},
+		{"no location", &ToolInstallOptions{PackageID: "a", Sources: []string{"https://example.com/index.json"}}},
+		{"no sources", &ToolInstallOptions{PackageID: "a", ToolPath: "tools"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := InstallTool(context.Background(), tt.opts, console); err == nil {
+				t.Error("InstallTool() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestInstallTool_RecordsServingSource(t *testing.T) {
+	nupkgPath := filepath.Join(t.TempDir(), "dotnetsay.2.1.4.nupkg")
+	writeTestNupkg(t, nupkgPath, map[string]string{
+		"dotnetsay.nuspec":                        toolNuspec("DotnetTool"),
+		"tools/net8.0/any/DotnetToolSettings.xml": toolSettingsXML,
+		"tools/net8.0/any/dotnetsay.dll":          "binary",
+	})
+	nupkg, err := os.ReadFile(nupkgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first source doesn't have the tool, so it comes from the second
+	empty := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{})
+	feed := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: []nugethttptest.Package{
+		{ID: "dotnetsay", Version: "2.1.4", Nupkg: nupkg},
+	}})
+
+	opts := &ToolInstallOptions{
+		PackageID: "dotnetsay",
+		Version:   "2.1.4",
+		ToolPath:  t.TempDir(),
+		Sources:   []string{empty.SourceURL(), feed.SourceURL()},
+	}
+	result, err := InstallTool(context.Background(), opts, &mockConsole{})
+	if err != nil {
+		t.Fatalf("InstallTool() error = %v", err)
+	}
+
+	metadata, err := packaging.ReadNupkgMetadataFile(filepath.Join(result.InstallPath, ".nupkg.metadata"))
+	if err != nil {
+		t.Fatalf("read .nupkg.metadata: %v", err)
+	}
+	if metadata.Source != feed.SourceURL() {
+		t.Errorf(".nupkg.metadata source = %q, want the serving source %q", metadata.Source, feed.SourceURL())
+	}
+}
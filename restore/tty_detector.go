@@ -21,9 +21,14 @@ type TTYDetector interface {
 // RealTTYDetector uses golang.org/x/term to detect real terminals
 type RealTTYDetector struct{}
 
+// fdWriter is a writer backed by a file descriptor, such as *os.File or a console output
+type fdWriter interface {
+	Fd() uintptr
+}
+
 // IsTTY returns true if w is a terminal
 func (d *RealTTYDetector) IsTTY(w io.Writer) bool {
-	if f, ok := w.(*os.File); ok {
+	if f, ok := w.(fdWriter); ok {
 		return term.IsTerminal(int(f.Fd()))
 	}
 	return false
@@ -31,7 +36,7 @@ func (d *RealTTYDetector) IsTTY(w io.Writer) bool {
 
 // GetSize returns the terminal dimensions
 func (d *RealTTYDetector) GetSize(w io.Writer) (width, height int, err error) {
-	if f, ok := w.(*os.File); ok {
+	if f, ok := w.(fdWriter); ok {
 		return term.GetSize(int(f.Fd()))
 	}
 	return 0, 0, os.ErrInvalid
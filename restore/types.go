@@ -24,6 +24,10 @@ type Result struct {
 	// CacheHit indicates restore was skipped (cache valid)
 	CacheHit bool
 
+	// AssetsUnchanged indicates the restore produced the project.assets.json already on
+	// disk, so the file was not rewritten
+	AssetsUnchanged bool
+
 	// Errors contains NuGet errors encountered during restore
 	Errors []*NuGetError
 
@@ -47,6 +51,10 @@ type PerformanceTiming struct {
 	PackageDownloads     time.Duration
 	AssetsGeneration     time.Duration
 
+	// LockWait is the part of PackageDownloads spent waiting for other processes
+	// installing the same packages
+	LockWait time.Duration
+
 	// Per-package resolution timing
 	ResolutionTimings map[string]time.Duration // packageID -> duration
 
@@ -2,7 +2,9 @@ package restore
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/willibrandon/gonuget/core"
 	"github.com/willibrandon/gonuget/version"
@@ -11,71 +13,93 @@ import (
 // ResolveLatestVersionOptions holds options for version resolution.
 type ResolveLatestVersionOptions struct {
 	Source     string
+	Sources    []string // Searched together for the highest version; overrides Source
 	Prerelease bool
 }
 
-// ResolveLatestVersion finds the latest version of a package.
+// ResolveLatestVersion finds the latest listed version of a package across the sources.
 // Returns the latest stable version by default, or latest prerelease if Prerelease is true.
 // Ported from NuGet.Protocol version resolution logic.
 func ResolveLatestVersion(ctx context.Context, packageID string, opts *ResolveLatestVersionOptions) (string, error) {
 	// Use default source if not specified
-	source := opts.Source
-	if source == "" {
-		source = "https://api.nuget.org/v3/index.json"
+	sources := opts.Sources
+	if len(sources) == 0 && opts.Source != "" {
+		sources = []string{opts.Source}
+	}
+	if len(sources) == 0 {
+		sources = []string{"https://api.nuget.org/v3/index.json"}
 	}
 
-	// Create repository manager and add the source
+	// Create repository manager and add the sources
 	repoManager := core.NewRepositoryManager()
-	repo := core.NewSourceRepository(core.RepositoryConfig{
-		Name:      "default",
-		SourceURL: source,
-	})
-
-	if err := repoManager.AddRepository(repo); err != nil {
-		return "", fmt.Errorf("failed to add repository %s: %w", source, err)
+	repos := make([]*core.SourceRepository, 0, len(sources))
+	for _, source := range sources {
+		repo := core.NewSourceRepository(core.RepositoryConfig{
+			Name:      source,
+			SourceURL: source,
+		})
+		if err := repoManager.AddRepository(repo); err != nil {
+			return "", fmt.Errorf("failed to add repository %s: %w", source, err)
+		}
+		repos = append(repos, repo)
 	}
 
-	// List all versions
-	versions, err := repo.ListVersions(ctx, nil, packageID)
-	if err != nil {
-		return "", fmt.Errorf("failed to list versions: %w", err)
+	resolved, err := core.ResolveVersion(ctx, nil, packageID, nil, core.ResolveVersionOptions{
+		Repositories:      repos,
+		Selection:         core.SelectHighest,
+		IncludePrerelease: opts.Prerelease,
+	})
+	if err == nil {
+		return resolved.Version.String(), nil
 	}
 
-	if len(versions) == 0 {
-		return "", fmt.Errorf("package '%s' not found in source %s", packageID, source)
+	var notFound *core.VersionNotFoundError
+	switch {
+	case !errors.As(err, &notFound):
+		return "", err
+	case !notFound.PackageFound() && len(notFound.Unwrap()) > 0:
+		return "", fmt.Errorf("failed to list versions: %w", notFound.Unwrap()[0])
+	case !notFound.PackageFound() && len(sources) == 1:
+		return "", fmt.Errorf("package '%s' not found in source %s", packageID, sources[0])
+	case !notFound.PackageFound():
+		return "", fmt.Errorf("package '%s' not found in sources %s", packageID, strings.Join(sources, ", "))
+	case !opts.Prerelease:
+		return "", fmt.Errorf("no stable version found for package '%s'. Use --prerelease to include prerelease versions", packageID)
+	default:
+		return "", fmt.Errorf("no versions found for package '%s'", packageID)
 	}
+}
 
-	// Filter and find latest version
-	// NuGet.Client behavior:
-	// - When includePrerelease=false: Only consider stable versions, return max
-	// - When includePrerelease=true: Consider ALL versions (stable + prerelease), return max
-	var latest *version.NuGetVersion
-
-	for _, v := range versions {
-		parsed, err := version.Parse(v)
-		if err != nil {
-			// Skip invalid versions
-			continue
-		}
-
-		// Skip prerelease versions if not requested
-		if !opts.Prerelease && parsed.IsPrerelease() {
-			continue
-		}
-
-		// Find the maximum version
-		if latest == nil || parsed.Compare(latest) > 0 {
-			latest = parsed
-		}
+// parseFloatingRange returns the floating range of a PackageReference version such as
+// "1.*" or "2.0.0-*", or nil when the version doesn't float.
+func parseFloatingRange(versionRange string) *version.FloatRange {
+	if !strings.Contains(versionRange, "*") {
+		return nil
 	}
-
-	if latest != nil {
-		return latest.String(), nil
+	floatRange, err := version.ParseFloatRange(versionRange)
+	if err != nil {
+		return nil
 	}
+	return floatRange
+}
 
-	if !opts.Prerelease {
-		return "", fmt.Errorf("no stable version found for package '%s'. Use --prerelease to include prerelease versions", packageID)
+// floatingLowerBound returns the range used to list candidates for a floating version.
+func floatingLowerBound(floatRange *version.FloatRange) string {
+	if floatRange.MinVersion == nil {
+		return "0.0.0"
 	}
+	return floatRange.MinVersion.String()
+}
 
-	return "", fmt.Errorf("no versions found for package '%s'", packageID)
+// bestFloatingVersion picks the available version a floating range resolves to: the
+// highest version it matches, or else the lowest version above its minimum. Prerelease
+// versions are only considered for prerelease floats (1.0.0-*, 8.*-preview*).
+func bestFloatingVersion(floatRange *version.FloatRange, available []string) *version.NuGetVersion {
+	candidates := make([]*version.NuGetVersion, 0, len(available))
+	for _, s := range available {
+		if v, err := version.Parse(s); err == nil {
+			candidates = append(candidates, v)
+		}
+	}
+	return floatRange.FindBestMatch(candidates)
 }
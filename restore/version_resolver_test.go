@@ -4,6 +4,8 @@ import (
 	"context"
 	"strings"
 	"testing"
+
+	"github.com/willibrandon/gonuget/http/nugethttptest"
 )
 
 func TestResolveLatestVersion(t *testing.T) {
@@ -154,3 +156,27 @@ func TestResolveLatestVersion_StableWhenBothExist(t *testing.T) {
 		t.Errorf("expected stable version but got prerelease: %s", version)
 	}
 }
+
+func TestResolveLatestVersion_Sources(t *testing.T) {
+	first := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: []nugethttptest.Package{
+		{ID: "Contoso.Core", Version: "1.0.0"},
+		{ID: "Contoso.Core", Version: "3.0.0-beta"},
+	}})
+	second := nugethttptest.NewFakeV3Server(t, nugethttptest.Feed{Packages: []nugethttptest.Package{
+		{ID: "Contoso.Core", Version: "2.0.0"},
+	}})
+	sources := []string{first.SourceURL(), second.SourceURL()}
+
+	// The highest version of all the sources wins
+	for prerelease, want := range map[bool]string{false: "2.0.0", true: "3.0.0-beta"} {
+		got, err := ResolveLatestVersion(context.Background(), "contoso.core", &ResolveLatestVersionOptions{Sources: sources, Prerelease: prerelease})
+		if err != nil || got != want {
+			t.Errorf("ResolveLatestVersion(prerelease %v) = %q, %v, want %q", prerelease, got, err, want)
+		}
+	}
+
+	_, err := ResolveLatestVersion(context.Background(), "Contoso.Missing", &ResolveLatestVersionOptions{Sources: sources})
+	if err == nil || !strings.Contains(err.Error(), `"Contoso.Missing" not found`) {
+		t.Errorf("ResolveLatestVersion() error = %v, want Contoso.Missing not found", err)
+	}
+}
@@ -0,0 +1,258 @@
+package serve
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/willibrandon/gonuget/packaging"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// ErrPackageExists is returned when a pushed package version is already in the folder.
+var ErrPackageExists = errors.New("package version already exists")
+
+// folderPackage is one .nupkg found in the feed folder.
+type folderPackage struct {
+	Path    string
+	ID      string
+	Version *version.NuGetVersion
+	Nuspec  *packaging.Nuspec
+
+	modTime time.Time
+	size    int64
+}
+
+// lowerVersion is the normalized, lowercase version used in flat container URLs.
+func (p *folderPackage) lowerVersion() string {
+	return strings.ToLower(p.Version.ToNormalizedString())
+}
+
+// folderFeed indexes the packages of a local folder feed. Both the flat layout
+// (*.nupkg in the folder) and the hierarchical layout ({id}/{version}/{id}.{version}.nupkg)
+// are read. The folder is rescanned on every lookup so packages copied into it
+// are served right away; nuspecs are only read again when a file changes.
+type folderFeed struct {
+	root string
+
+	mu    sync.Mutex
+	files map[string]*folderPackage // path -> package, reused while the file is unchanged
+
+	pushMu sync.Mutex // serializes pushes so a version can't be written twice
+}
+
+func newFolderFeed(root string) *folderFeed {
+	return &folderFeed{root: root, files: make(map[string]*folderPackage)}
+}
+
+// packages returns every package in the folder, grouped by lowercase ID with versions ascending.
+// When the same version is in the folder twice, the first file found wins.
+func (f *folderFeed) packages() (map[string][]*folderPackage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	seen := make(map[string]bool)
+	err := filepath.WalkDir(f.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isPackageFile(d.Name()) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		seen[path] = true
+		if cached := f.files[path]; cached != nil && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+			return nil
+		}
+
+		pkg, err := readFolderPackage(path)
+		if err != nil {
+			// Not a valid package; leave it out of the feed
+			delete(f.files, path)
+			return nil
+		}
+		pkg.modTime = info.ModTime()
+		pkg.size = info.Size()
+		f.files[path] = pkg
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", f.root, err)
+	}
+
+	byID := make(map[string][]*folderPackage)
+	paths := make([]string, 0, len(f.files))
+	for path := range f.files {
+		if !seen[path] {
+			delete(f.files, path)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		pkg := f.files[path]
+		id := strings.ToLower(pkg.ID)
+		if slices.ContainsFunc(byID[id], func(other *folderPackage) bool { return other.Version.Equals(pkg.Version) }) {
+			continue
+		}
+		byID[id] = append(byID[id], pkg)
+	}
+	for _, versions := range byID {
+		slices.SortFunc(versions, func(a, b *folderPackage) int { return a.Version.Compare(b.Version) })
+	}
+
+	return byID, nil
+}
+
+// versions returns the versions of a package, ascending.
+func (f *folderFeed) versions(id string) ([]*folderPackage, error) {
+	all, err := f.packages()
+	if err != nil {
+		return nil, err
+	}
+	return all[strings.ToLower(id)], nil
+}
+
+// find returns one version of a package, or nil.
+func (f *folderFeed) find(id, ver string) (*folderPackage, error) {
+	parsed, err := version.Parse(ver)
+	if err != nil {
+		return nil, nil
+	}
+	versions, err := f.versions(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range versions {
+		if pkg.Version.Equals(parsed) {
+			return pkg, nil
+		}
+	}
+	return nil, nil
+}
+
+// add writes a pushed package into the folder in the hierarchical layout, with the
+// nuspec and SHA512 files next to it as "nuget add" does.
+func (f *folderFeed) add(data []byte) (*folderPackage, error) {
+	f.pushMu.Lock()
+	defer f.pushMu.Unlock()
+
+	reader, err := packaging.OpenPackageFromReaderAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("open package: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	identity, err := reader.GetIdentity()
+	if err != nil {
+		return nil, fmt.Errorf("read package identity: %w", err)
+	}
+	if err := packaging.ValidatePackageID(identity.ID); err != nil {
+		return nil, err
+	}
+
+	existing, err := f.find(identity.ID, identity.Version.String())
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%s %s: %w", identity.ID, identity.Version.ToNormalizedString(), ErrPackageExists)
+	}
+
+	nuspec, err := reader.OpenNuspec()
+	if err != nil {
+		return nil, fmt.Errorf("read nuspec: %w", err)
+	}
+	var nuspecData bytes.Buffer
+	_, err = nuspecData.ReadFrom(nuspec)
+	_ = nuspec.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read nuspec: %w", err)
+	}
+
+	resolver := packaging.NewVersionFolderPathResolver(f.root, true)
+	if err := os.MkdirAll(resolver.GetInstallPath(identity.ID, identity.Version), 0755); err != nil {
+		return nil, err
+	}
+
+	hash := sha512.Sum512(data)
+	files := []struct {
+		path string
+		data []byte
+	}{
+		{resolver.GetManifestFilePath(identity.ID, identity.Version), nuspecData.Bytes()},
+		{resolver.GetHashPath(identity.ID, identity.Version), []byte(base64.StdEncoding.EncodeToString(hash[:]))},
+		// The package goes last, so a scan never sees it without its nuspec
+		{resolver.GetPackageFilePath(identity.ID, identity.Version), data},
+	}
+	for _, file := range files {
+		if err := writeFileAtomic(file.path, file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	return &folderPackage{
+		Path:    resolver.GetPackageFilePath(identity.ID, identity.Version),
+		ID:      identity.ID,
+		Version: identity.Version,
+	}, nil
+}
+
+// readFolderPackage reads the identity and nuspec of a .nupkg.
+func readFolderPackage(path string) (*folderPackage, error) {
+	reader, err := packaging.OpenPackage(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+
+	nuspec, err := reader.GetNuspec()
+	if err != nil {
+		return nil, err
+	}
+	identity, err := nuspec.GetParsedIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	return &folderPackage{Path: path, ID: identity.ID, Version: identity.Version, Nuspec: nuspec}, nil
+}
+
+// isPackageFile reports whether a file name is a servable package; symbol packages are skipped.
+func isPackageFile(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".nupkg") && !strings.HasSuffix(lower, ".symbols.nupkg")
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".push-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,390 @@
+// Package serve exposes a local folder feed over HTTP as a minimal NuGet V3 feed.
+//
+// The served surface is what restore and package management need: the service index,
+// the flat container (version lists, .nupkg and .nuspec downloads), registration
+// indexes with inlined leaves generated from the nuspecs, autocomplete and, unless
+// the feed is read-only, package push.
+package serve
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/willibrandon/gonuget/packaging"
+	v3 "github.com/willibrandon/gonuget/protocol/v3"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// MaxPushSize is the largest package accepted by push (250 MB, as on nuget.org).
+const MaxPushSize = 250 << 20
+
+// Route prefixes of the served resources, relative to the server root.
+const (
+	ServiceIndexPath  = "/v3/index.json"
+	flatContainerPath = "/v3/flatcontainer/"
+	registrationPath  = "/v3/registration/"
+	autocompletePath  = "/v3/autocomplete"
+	publishPath       = "/api/v2/package"
+)
+
+// Options configures a Server.
+type Options struct {
+	// Folder is the local folder feed to serve.
+	Folder string
+
+	// ReadOnly disables push; the service index then has no PackagePublish resource.
+	ReadOnly bool
+
+	// APIKey is required in the X-NuGet-ApiKey header of push requests when set.
+	// Without it anyone who can reach the server can push.
+	APIKey string
+}
+
+// Server serves a folder feed. It implements http.Handler.
+type Server struct {
+	opts Options
+	feed *folderFeed
+	mux  *http.ServeMux
+}
+
+// NewServer creates a server for a folder feed. The folder must exist.
+func NewServer(opts Options) (*Server, error) {
+	info, err := os.Stat(opts.Folder)
+	if err != nil {
+		return nil, fmt.Errorf("feed folder: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("feed folder %s is not a directory", opts.Folder)
+	}
+
+	s := &Server{
+		opts: opts,
+		feed: newFolderFeed(opts.Folder),
+		mux:  http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET "+ServiceIndexPath, s.handleServiceIndex)
+	s.mux.HandleFunc("GET "+flatContainerPath+"{id}/index.json", s.handleVersions)
+	s.mux.HandleFunc("GET "+flatContainerPath+"{id}/{version}/{file}", s.handlePackageFile)
+	s.mux.HandleFunc("GET "+registrationPath+"{id}/index.json", s.handleRegistration)
+	s.mux.HandleFunc("GET "+autocompletePath, s.handleAutocomplete)
+	if !opts.ReadOnly {
+		s.mux.HandleFunc("PUT "+publishPath, s.handlePush)
+		s.mux.HandleFunc("PUT "+publishPath+"/", s.handlePush)
+	}
+
+	return s, nil
+}
+
+// ServeHTTP dispatches a feed request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// baseURL returns the URL clients used to reach the server, so resource URLs in
+// responses work behind port forwarding and from other machines.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func (s *Server) handleServiceIndex(w http.ResponseWriter, r *http.Request) {
+	base := baseURL(r)
+	resources := []v3.Resource{
+		{ID: base + flatContainerPath, Type: "PackageBaseAddress/3.0.0"},
+		{ID: base + registrationPath, Type: "RegistrationsBaseUrl"},
+		{ID: base + registrationPath, Type: "RegistrationsBaseUrl/3.0.0-beta"},
+		{ID: base + registrationPath, Type: "RegistrationsBaseUrl/3.0.0-rc"},
+		{ID: base + registrationPath, Type: "RegistrationsBaseUrl/3.6.0"},
+		{ID: base + autocompletePath, Type: "SearchAutocompleteService"},
+		{ID: base + autocompletePath, Type: "SearchAutocompleteService/3.0.0-beta"},
+		{ID: base + autocompletePath, Type: "SearchAutocompleteService/3.0.0-rc"},
+	}
+	if !s.opts.ReadOnly {
+		resources = append(resources, v3.Resource{ID: base + publishPath, Type: "PackagePublish/2.0.0"})
+	}
+
+	writeJSON(w, v3.ServiceIndex{Version: "3.0.0", Resources: resources})
+}
+
+// handleVersions serves {id}/index.json: the lowercase normalized versions of a package.
+func (s *Server) handleVersions(w http.ResponseWriter, r *http.Request) {
+	packages, err := s.feed.versions(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(packages) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	versions := make([]string, len(packages))
+	for i, pkg := range packages {
+		versions[i] = pkg.lowerVersion()
+	}
+	writeJSON(w, map[string][]string{"versions": versions})
+}
+
+// handlePackageFile serves {id}/{version}/{id}.{version}.nupkg and {id}/{version}/{id}.nuspec.
+func (s *Server) handlePackageFile(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(r.PathValue("id"))
+	ver := strings.ToLower(r.PathValue("version"))
+	file := strings.ToLower(r.PathValue("file"))
+
+	pkg, err := s.feed.find(id, ver)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if pkg == nil || ver != pkg.lowerVersion() {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch file {
+	case id + "." + ver + ".nupkg":
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.ServeFile(w, r, pkg.Path)
+	case id + ".nuspec":
+		reader, err := packaging.OpenPackage(pkg.Path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = reader.Close() }()
+
+		nuspec, err := reader.OpenNuspec()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = nuspec.Close() }()
+
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = io.Copy(w, nuspec)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleRegistration serves a registration index with a single page of inlined leaves.
+func (s *Server) handleRegistration(w http.ResponseWriter, r *http.Request) {
+	id := strings.ToLower(r.PathValue("id"))
+	packages, err := s.feed.versions(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(packages) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	base := baseURL(r)
+	indexURL := base + registrationPath + id + "/index.json"
+	leaves := make([]v3.RegistrationLeaf, len(packages))
+	for i, pkg := range packages {
+		ver := pkg.lowerVersion()
+		leaves[i] = v3.RegistrationLeaf{
+			ID:             base + registrationPath + id + "/" + ver + ".json",
+			CatalogEntry:   catalogEntry(pkg, base+registrationPath+id+"/"+ver+".json#catalogEntry"),
+			PackageContent: base + flatContainerPath + id + "/" + ver + "/" + id + "." + ver + ".nupkg",
+		}
+	}
+
+	writeJSON(w, v3.RegistrationIndex{
+		Count: 1,
+		Items: []v3.RegistrationPage{{
+			ID:    indexURL + "#page/" + packages[0].lowerVersion() + "/" + packages[len(packages)-1].lowerVersion(),
+			Count: len(leaves),
+			Items: leaves,
+			Lower: packages[0].Version.ToNormalizedString(),
+			Upper: packages[len(packages)-1].Version.ToNormalizedString(),
+		}},
+	})
+}
+
+// catalogEntry builds the registration catalog entry of a package from its nuspec.
+func catalogEntry(pkg *folderPackage, entryURL string) *v3.RegistrationCatalog {
+	metadata := pkg.Nuspec.Metadata
+	entry := &v3.RegistrationCatalog{
+		ID:                       entryURL,
+		PackageID:                pkg.ID,
+		Version:                  pkg.Version.ToNormalizedString(),
+		Authors:                  metadata.Authors,
+		Description:              metadata.Description,
+		IconURL:                  metadata.IconURL,
+		LicenseURL:               metadata.LicenseURL,
+		ProjectURL:               metadata.ProjectURL,
+		RequireLicenseAcceptance: metadata.RequireLicenseAcceptance,
+		Summary:                  metadata.Summary,
+		Tags:                     pkg.Nuspec.GetTags(),
+		Title:                    metadata.Title,
+	}
+	if metadata.License != nil && metadata.License.Type == "expression" {
+		entry.LicenseExpression = metadata.License.Text
+	}
+
+	if deps := metadata.Dependencies; deps != nil {
+		if len(deps.Dependencies) > 0 {
+			entry.DependencyGroups = append(entry.DependencyGroups, dependencyGroup("", deps.Dependencies))
+		}
+		for _, group := range deps.Groups {
+			entry.DependencyGroups = append(entry.DependencyGroups, dependencyGroup(group.TargetFramework, group.Dependencies))
+		}
+	}
+	for _, packageType := range metadata.PackageTypes {
+		entry.PackageTypes = append(entry.PackageTypes, v3.PackageType{Name: packageType.Name, Version: packageType.Version})
+	}
+
+	return entry
+}
+
+// dependencyGroup converts a nuspec dependency group, normalizing ranges as nuget.org does.
+func dependencyGroup(targetFramework string, deps []packaging.Dependency) v3.DependencyGroup {
+	group := v3.DependencyGroup{TargetFramework: targetFramework}
+	for _, dep := range deps {
+		depRange := dep.Version
+		if parsed, err := version.ParseVersionRange(dep.Version); err == nil {
+			depRange = parsed.String()
+		}
+		group.Dependencies = append(group.Dependencies, v3.Dependency{ID: dep.ID, Range: depRange})
+	}
+	return group
+}
+
+// handleAutocomplete serves package ID completion (q) and version listing (id).
+func (s *Server) handleAutocomplete(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	prerelease, _ := strconv.ParseBool(query.Get("prerelease"))
+
+	all, err := s.feed.packages()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if id := query.Get("id"); id != "" {
+		var versions []string
+		for _, pkg := range all[strings.ToLower(id)] {
+			if prerelease || !pkg.Version.IsPrerelease() {
+				versions = append(versions, pkg.Version.ToNormalizedString())
+			}
+		}
+		writeJSON(w, v3.AutocompleteResponse{TotalHits: len(versions), Data: nonNil(versions)})
+		return
+	}
+
+	term := strings.ToLower(query.Get("q"))
+	var ids []string
+	for lowerID, packages := range all {
+		if !strings.Contains(lowerID, term) {
+			continue
+		}
+		// The latest version's casing is the one shown
+		for _, pkg := range slices.Backward(packages) {
+			if prerelease || !pkg.Version.IsPrerelease() {
+				ids = append(ids, pkg.ID)
+				break
+			}
+		}
+	}
+	slices.SortFunc(ids, func(a, b string) int { return strings.Compare(strings.ToLower(a), strings.ToLower(b)) })
+
+	total := len(ids)
+	skip, _ := strconv.Atoi(query.Get("skip"))
+	take, err := strconv.Atoi(query.Get("take"))
+	if err != nil || take <= 0 {
+		take = 20
+	}
+	ids = ids[min(max(skip, 0), len(ids)):]
+	ids = ids[:min(take, len(ids))]
+
+	writeJSON(w, v3.AutocompleteResponse{TotalHits: total, Data: nonNil(ids)})
+}
+
+// handlePush accepts a package pushed as multipart/form-data, as "dotnet nuget push" sends it,
+// or as the raw request body.
+func (s *Server) handlePush(w http.ResponseWriter, r *http.Request) {
+	if s.opts.APIKey != "" {
+		key := r.Header.Get("X-NuGet-ApiKey")
+		if subtle.ConstantTimeCompare([]byte(key), []byte(s.opts.APIKey)) != 1 {
+			http.Error(w, "The specified API key is invalid.", http.StatusForbidden)
+			return
+		}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxPushSize)
+	data, err := readPushedPackage(r)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "The package is too large.", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.feed.add(data); err != nil {
+		if errors.Is(err, ErrPackageExists) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// readPushedPackage reads the package from the first file of a multipart body, or the whole body.
+func readPushedPackage(r *http.Request) ([]byte, error) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return io.ReadAll(r.Body)
+	}
+
+	reader := multipart.NewReader(r.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("no package in request")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FileName() == "" && part.FormName() != "package" {
+			continue
+		}
+		return io.ReadAll(part)
+	}
+}
+
+// writeJSON writes a JSON response.
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// nonNil returns an empty slice for nil so "data" encodes as [].
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
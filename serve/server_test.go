@@ -0,0 +1,371 @@
+package serve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/willibrandon/gonuget/frameworks"
+	"github.com/willibrandon/gonuget/packaging"
+	v3 "github.com/willibrandon/gonuget/protocol/v3"
+	"github.com/willibrandon/gonuget/restore"
+	"github.com/willibrandon/gonuget/version"
+)
+
+// buildPackage returns a .nupkg for id and version, optionally depending on dependencyID.
+func buildPackage(t *testing.T, id, ver, dependencyID string) []byte {
+	t.Helper()
+
+	builder := packaging.NewPackageBuilder().
+		SetID(id).
+		SetVersion(version.MustParse(ver)).
+		SetDescription(id + " test package").
+		SetAuthors("gonuget")
+	if dependencyID != "" {
+		fw, err := frameworks.ParseFramework("net8.0")
+		if err != nil {
+			t.Fatalf("ParseFramework() error = %v", err)
+		}
+		depRange, err := version.ParseVersionRange("1.0.0")
+		if err != nil {
+			t.Fatalf("ParseVersionRange() error = %v", err)
+		}
+		builder.AddDependency(fw, dependencyID, depRange)
+	}
+	if err := builder.AddFileFromBytes("lib/net8.0/"+id+".dll", []byte(id)); err != nil {
+		t.Fatalf("AddFileFromBytes() error = %v", err)
+	}
+
+	var nupkg bytes.Buffer
+	if err := builder.Save(&nupkg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	return nupkg.Bytes()
+}
+
+// newTestFeed serves a folder holding Served.App 1.0.0 (depending on Served.Dep) in the flat
+// layout and Served.Dep 1.0.0 and 2.0.0-beta in the hierarchical layout.
+func newTestFeed(t *testing.T, opts Options) (*httptest.Server, string) {
+	t.Helper()
+
+	folder := t.TempDir()
+	write := func(path string, data []byte) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	write(filepath.Join(folder, "Served.App.1.0.0.nupkg"), buildPackage(t, "Served.App", "1.0.0", "Served.Dep"))
+	write(filepath.Join(folder, "served.dep", "1.0.0", "served.dep.1.0.0.nupkg"), buildPackage(t, "Served.Dep", "1.0.0", ""))
+	write(filepath.Join(folder, "served.dep", "2.0.0-beta", "served.dep.2.0.0-beta.nupkg"), buildPackage(t, "Served.Dep", "2.0.0-beta", ""))
+
+	opts.Folder = folder
+	server, err := NewServer(opts)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return httpServer, folder
+}
+
+func getJSON(t *testing.T, url string, value any) int {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s error = %v", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode == http.StatusOK && value != nil {
+		if err := json.NewDecoder(resp.Body).Decode(value); err != nil {
+			t.Fatalf("decode %s: %v", url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// writeTestProject writes a net8.0 project referencing Served.App 1.0.0.
+func writeTestProject(t *testing.T, dir string) string {
+	t.Helper()
+
+	projPath := filepath.Join(dir, "app.csproj")
+	csproj := `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Served.App" Version="1.0.0" />
+  </ItemGroup>
+</Project>`
+	if err := os.WriteFile(projPath, []byte(csproj), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return projPath
+}
+
+// testConsole collects restore output.
+type testConsole struct {
+	out bytes.Buffer
+}
+
+func (c *testConsole) Printf(format string, args ...any) { _, _ = fmt.Fprintf(&c.out, format, args...) }
+func (c *testConsole) Error(format string, args ...any)  { _, _ = fmt.Fprintf(&c.out, format, args...) }
+func (c *testConsole) Warning(format string, args ...any) {
+	_, _ = fmt.Fprintf(&c.out, format, args...)
+}
+func (c *testConsole) Output() io.Writer { return &c.out }
+
+func TestServer_GonugetRestore(t *testing.T) {
+	feed, _ := newTestFeed(t, Options{ReadOnly: true})
+
+	tmpDir := t.TempDir()
+	projPath := writeTestProject(t, tmpDir)
+	packagesFolder := filepath.Join(tmpDir, "packages")
+
+	console := &testConsole{}
+	opts := &restore.Options{
+		Sources:        []string{feed.URL + ServiceIndexPath},
+		PackagesFolder: packagesFolder,
+		NoCache:        true,
+	}
+	if err := restore.Run(context.Background(), []string{projPath}, opts, console); err != nil {
+		t.Fatalf("Run() error = %v\noutput: %s", err, console.out.String())
+	}
+
+	for _, path := range []string{
+		filepath.Join(packagesFolder, "served.app", "1.0.0", "served.app.1.0.0.nupkg"),
+		filepath.Join(packagesFolder, "served.dep", "1.0.0", "served.dep.1.0.0.nupkg"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("package not installed: %v", err)
+		}
+	}
+}
+
+func TestServer_DotnetRestore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping dotnet restore in short mode")
+	}
+	if _, err := exec.LookPath("dotnet"); err != nil {
+		t.Skip("dotnet not available")
+	}
+
+	feed, _ := newTestFeed(t, Options{ReadOnly: true})
+
+	tmpDir := t.TempDir()
+	projPath := writeTestProject(t, tmpDir)
+	config := `<?xml version="1.0" encoding="utf-8"?>
+<configuration>
+  <packageSources>
+    <clear />
+    <add key="served" value="` + feed.URL + ServiceIndexPath + `" allowInsecureConnections="true" />
+  </packageSources>
+</configuration>`
+	if err := os.WriteFile(filepath.Join(tmpDir, "NuGet.config"), []byte(config), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	packagesFolder := filepath.Join(tmpDir, "packages")
+
+	cmd := exec.Command("dotnet", "restore", projPath, "--packages", packagesFolder)
+	cmd.Dir = tmpDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("dotnet restore error = %v\n%s", err, out)
+	}
+	if _, err := os.Stat(filepath.Join(packagesFolder, "served.dep", "1.0.0", "served.dep.1.0.0.nupkg")); err != nil {
+		t.Errorf("package not installed: %v", err)
+	}
+}
+
+func TestServer_FlatContainer(t *testing.T) {
+	feed, _ := newTestFeed(t, Options{ReadOnly: true})
+
+	var versions struct {
+		Versions []string `json:"versions"`
+	}
+	if status := getJSON(t, feed.URL+"/v3/flatcontainer/SERVED.DEP/index.json", &versions); status != http.StatusOK {
+		t.Fatalf("versions status = %d", status)
+	}
+	if want := []string{"1.0.0", "2.0.0-beta"}; !slices.Equal(versions.Versions, want) {
+		t.Errorf("versions = %v, want %v", versions.Versions, want)
+	}
+
+	resp, err := http.Get(feed.URL + "/v3/flatcontainer/served.app/1.0.0/served.app.nuspec")
+	if err != nil {
+		t.Fatalf("GET nuspec error = %v", err)
+	}
+	nuspec, err := packaging.ParseNuspec(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatalf("ParseNuspec() error = %v", err)
+	}
+	if nuspec.Metadata.ID != "Served.App" {
+		t.Errorf("nuspec id = %q, want Served.App", nuspec.Metadata.ID)
+	}
+
+	for _, path := range []string{
+		"/v3/flatcontainer/missing/index.json",
+		"/v3/flatcontainer/served.app/9.9.9/served.app.9.9.9.nupkg",
+		"/v3/flatcontainer/served.app/1.0.0/other.1.0.0.nupkg",
+	} {
+		if status := getJSON(t, feed.URL+path, nil); status != http.StatusNotFound {
+			t.Errorf("GET %s status = %d, want 404", path, status)
+		}
+	}
+}
+
+func TestServer_Registration(t *testing.T) {
+	feed, _ := newTestFeed(t, Options{ReadOnly: true})
+
+	var index v3.RegistrationIndex
+	if status := getJSON(t, feed.URL+"/v3/registration/served.app/index.json", &index); status != http.StatusOK {
+		t.Fatalf("registration status = %d", status)
+	}
+	if len(index.Items) != 1 || len(index.Items[0].Items) != 1 {
+		t.Fatalf("registration = %+v, want one inlined leaf", index)
+	}
+
+	leaf := index.Items[0].Items[0]
+	if leaf.CatalogEntry.PackageID != "Served.App" || leaf.CatalogEntry.Version != "1.0.0" {
+		t.Errorf("catalogEntry = %+v", leaf.CatalogEntry)
+	}
+	if got := leaf.CatalogEntry.DependencyGroups; len(got) != 1 || len(got[0].Dependencies) != 1 || got[0].Dependencies[0].Range != "[1.0.0, )" {
+		t.Errorf("dependencyGroups = %+v", got)
+	}
+	if want := feed.URL + "/v3/flatcontainer/served.app/1.0.0/served.app.1.0.0.nupkg"; leaf.PackageContent != want {
+		t.Errorf("packageContent = %q, want %q", leaf.PackageContent, want)
+	}
+}
+
+func TestServer_Autocomplete(t *testing.T) {
+	feed, _ := newTestFeed(t, Options{ReadOnly: true})
+
+	var ids v3.AutocompleteResponse
+	getJSON(t, feed.URL+"/v3/autocomplete?q=served", &ids)
+	if want := []string{"Served.App", "Served.Dep"}; !slices.Equal(ids.Data, want) {
+		t.Errorf("autocomplete = %v, want %v", ids.Data, want)
+	}
+
+	var versions v3.AutocompleteResponse
+	getJSON(t, feed.URL+"/v3/autocomplete?id=served.dep&prerelease=false", &versions)
+	if want := []string{"1.0.0"}; !slices.Equal(versions.Data, want) {
+		t.Errorf("versions = %v, want %v", versions.Data, want)
+	}
+	getJSON(t, feed.URL+"/v3/autocomplete?id=served.dep&prerelease=true", &versions)
+	if want := []string{"1.0.0", "2.0.0-beta"}; !slices.Equal(versions.Data, want) {
+		t.Errorf("versions = %v, want %v", versions.Data, want)
+	}
+}
+
+// push sends a package the way "dotnet nuget push" does.
+func push(t *testing.T, url, apiKey string, nupkg []byte) int {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("package", "package.nupkg")
+	if err != nil {
+		t.Fatalf("CreateFormFile() error = %v", err)
+	}
+	_, _ = part.Write(nupkg)
+	_ = form.Close()
+
+	req, err := http.NewRequest(http.MethodPut, url, &body)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if apiKey != "" {
+		req.Header.Set("X-NuGet-ApiKey", apiKey)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT error = %v", err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServer_Push(t *testing.T) {
+	feed, folder := newTestFeed(t, Options{APIKey: "secret"})
+
+	var index v3.ServiceIndex
+	getJSON(t, feed.URL+ServiceIndexPath, &index)
+	var publishURL string
+	for _, resource := range index.Resources {
+		if resource.HasType(v3.ResourceTypePackagePublish) {
+			publishURL = resource.ID
+		}
+	}
+	if publishURL == "" {
+		t.Fatal("service index has no PackagePublish resource")
+	}
+
+	nupkg := buildPackage(t, "Pushed.Pkg", "1.2.0", "")
+	if status := push(t, publishURL, "wrong", nupkg); status != http.StatusForbidden {
+		t.Errorf("push with wrong key status = %d, want 403", status)
+	}
+	if status := push(t, publishURL, "secret", nupkg); status != http.StatusCreated {
+		t.Fatalf("push status = %d, want 201", status)
+	}
+	if status := push(t, publishURL, "secret", nupkg); status != http.StatusConflict {
+		t.Errorf("second push status = %d, want 409", status)
+	}
+
+	for _, name := range []string{"pushed.pkg.1.2.0.nupkg", "pushed.pkg.1.2.0.nupkg.sha512", "pushed.pkg.nuspec"} {
+		if _, err := os.Stat(filepath.Join(folder, "pushed.pkg", "1.2.0", name)); err != nil {
+			t.Errorf("pushed file missing: %v", err)
+		}
+	}
+
+	var versions struct {
+		Versions []string `json:"versions"`
+	}
+	getJSON(t, feed.URL+"/v3/flatcontainer/pushed.pkg/index.json", &versions)
+	if !slices.Equal(versions.Versions, []string{"1.2.0"}) {
+		t.Errorf("versions after push = %v", versions.Versions)
+	}
+}
+
+func TestServer_ReadOnly(t *testing.T) {
+	feed, _ := newTestFeed(t, Options{ReadOnly: true})
+
+	var index v3.ServiceIndex
+	getJSON(t, feed.URL+ServiceIndexPath, &index)
+	for _, resource := range index.Resources {
+		if resource.HasType(v3.ResourceTypePackagePublish) {
+			t.Errorf("read-only service index has %s", resource.Type)
+		}
+	}
+
+	status := push(t, feed.URL+publishPath, "", buildPackage(t, "Pushed.Pkg", "1.0.0", ""))
+	if status == http.StatusCreated {
+		t.Error("push to a read-only feed succeeded")
+	}
+}
+
+func TestNewServer_MissingFolder(t *testing.T) {
+	if _, err := NewServer(Options{Folder: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("NewServer() with a missing folder succeeded")
+	}
+	file := filepath.Join(t.TempDir(), "file")
+	_ = os.WriteFile(file, nil, 0644)
+	if _, err := NewServer(Options{Folder: file}); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("NewServer() with a file error = %v", err)
+	}
+}
@@ -36,6 +36,8 @@ func TestHelpOutput(t *testing.T) {
 		{"source enable help", []string{"source", "enable", "--help"}, "help_source_enable.golden"},
 		{"source disable help", []string{"source", "disable", "--help"}, "help_source_disable.golden"},
 		{"source update help", []string{"source", "update", "--help"}, "help_source_update.golden"},
+		{"tool help", []string{"tool", "--help"}, "help_tool.golden"},
+		{"tool install help", []string{"tool", "install", "--help"}, "help_tool_install.golden"},
 	}
 
 	for _, tt := range tests {
@@ -105,6 +107,8 @@ func init() {
 	cli.AddCommand(commands.GetPackageCommand())
 	cli.AddCommand(commands.GetSourceCommand())
 	commands.RegisterSourceSubcommands(cli.Console)
+	cli.AddCommand(commands.GetToolCommand())
+	commands.RegisterToolSubcommands(cli.Console)
 
 	// Setup custom error handler
 	commands.SetupCustomErrorHandler(cli.GetRootCommand())
@@ -145,6 +145,47 @@ func TestPackageSearchJSONSchema(t *testing.T) {
 
 	validateJSON(t, schema, validJSONWithResults)
 
+	// Test results per source with a failed source
+	validJSONWithProblems := `{
+		"schemaVersion": "1.0.0",
+		"searchTerm": "Serilog",
+		"sources": ["https://api.nuget.org/v3/index.json", "https://pkgs.example.com/v3/index.json"],
+		"items": [
+			{
+				"id": "Serilog",
+				"version": "3.1.1",
+				"description": "Simple .NET logging",
+				"authors": "Serilog Contributors"
+			}
+		],
+		"total": 1,
+		"searchResults": [
+			{
+				"source": "nuget.org",
+				"sourceUrl": "https://api.nuget.org/v3/index.json",
+				"items": [
+					{
+						"id": "Serilog",
+						"version": "3.1.1",
+						"description": "Simple .NET logging",
+						"authors": "Serilog Contributors"
+					}
+				],
+				"total": 1
+			}
+		],
+		"problems": [
+			{
+				"source": "internal",
+				"sourceUrl": "https://pkgs.example.com/v3/index.json",
+				"message": "the source did not answer within 30s"
+			}
+		],
+		"elapsedMs": 156
+	}`
+
+	validateJSON(t, schema, validJSONWithProblems)
+
 	// Test that searchTerm is required
 	invalidJSON := `{
 		"schemaVersion": "1.0.0",
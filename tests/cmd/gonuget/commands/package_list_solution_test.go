@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -105,15 +106,15 @@ EndGlobal
 		}
 	}
 
-	// Verify output contains all packages
+	// Verify output contains all packages, in table rows with their requested versions
 	expectedPackages := []string{
-		"Newtonsoft.Json 13.0.1",
-		"Microsoft.AspNetCore.OpenApi 8.0.0",
-		"Microsoft.EntityFrameworkCore 8.0.0",
-		"Dapper 2.1.0",
+		`> Newtonsoft\.Json +13\.0\.1`,
+		`> Microsoft\.AspNetCore\.OpenApi +8\.0\.0`,
+		`> Microsoft\.EntityFrameworkCore +8\.0\.0`,
+		`> Dapper +2\.1\.0`,
 	}
 	for _, pkg := range expectedPackages {
-		if !strings.Contains(output, pkg) {
+		if !regexp.MustCompile(pkg).MatchString(output) {
 			t.Errorf("Output missing package %s\nActual output:\n%s", pkg, output)
 		}
 	}
@@ -37,7 +37,7 @@ func TestPackageSearchFlags(t *testing.T) {
 	cmd := commands.NewPackageSearchCommand()
 
 	// Check required flags exist
-	flags := []string{"source", "format", "take", "skip", "prerelease"}
+	flags := []string{"source", "configfile", "format", "take", "skip", "prerelease", "strict"}
 	for _, flagName := range flags {
 		flag := cmd.Flags().Lookup(flagName)
 		if flag == nil {
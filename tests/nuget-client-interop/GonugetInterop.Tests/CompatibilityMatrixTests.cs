@@ -0,0 +1,215 @@
+using System;
+using System.Collections.Generic;
+using System.Linq;
+using System.Text;
+using GonugetInterop.Tests.TestHelpers;
+using NuGet.Client;
+using NuGet.ContentModel;
+using NuGet.Frameworks;
+using NuGet.RuntimeModel;
+using Xunit;
+
+namespace GonugetInterop.Tests;
+
+/// <summary>
+/// Generative asset selection tests. Each package layout is built and restored by gonuget
+/// for a matrix of project frameworks, and the compile and runtime groups written to
+/// project.assets.json are compared against NuGet.Client's selection for the same files.
+/// A reduced matrix runs by default; set GONUGET_FULL_COMPAT_MATRIX=1 for the full one.
+/// </summary>
+public class CompatibilityMatrixTests
+{
+    private const string FullMatrixVariable = "GONUGET_FULL_COMPAT_MATRIX";
+
+    /// <summary>
+    /// Package layouts by name. Every layout is restored as its own package.
+    /// </summary>
+    private static readonly Dictionary<string, string[]> Layouts = new()
+    {
+        ["lib-only"] = new[]
+        {
+            "lib/net462/Matrix.dll",
+            "lib/netstandard2.0/Matrix.dll",
+            "lib/net6.0/Matrix.dll",
+        },
+        ["ref-and-lib"] = new[]
+        {
+            "ref/netstandard2.0/Matrix.dll",
+            "lib/net462/Matrix.dll",
+            "lib/netstandard2.0/Matrix.dll",
+        },
+        ["runtimes-with-rids"] = new[]
+        {
+            "lib/netstandard2.0/Matrix.dll",
+            "runtimes/win/lib/net6.0/Matrix.dll",
+            "runtimes/linux-x64/native/libmatrix.so",
+        },
+        ["content-files"] = new[]
+        {
+            "contentFiles/cs/net6.0/Sample.cs",
+            "contentFiles/any/any/readme.txt",
+            "lib/net6.0/Matrix.dll",
+            "lib/net6.0/Matrix.xml",
+            "lib/net6.0/Matrix.pdb",
+        },
+        ["placeholders"] = new[]
+        {
+            "lib/net462/_._",
+            "ref/net462/_._",
+            "lib/netstandard2.0/Matrix.dll",
+            "ref/netstandard2.0/Matrix.dll",
+        },
+        ["portable-profiles"] = new[]
+        {
+            "lib/portable-net45+win8+wpa81/Matrix.dll",
+            "lib/netstandard1.0/Matrix.dll",
+        },
+        ["platform-tfms"] = new[]
+        {
+            "lib/net8.0/Matrix.dll",
+            "lib/net8.0-windows7.0/Matrix.dll",
+            "lib/net6.0-android31.0/Matrix.dll",
+        },
+        ["lib-root"] = new[]
+        {
+            "lib/Matrix.dll",
+            "lib/net40/Matrix.dll",
+        },
+        ["multiple-assemblies"] = new[]
+        {
+            "lib/netstandard2.0/Matrix.dll",
+            "lib/netstandard2.0/Matrix.Core.dll",
+            "lib/net6.0/Matrix.dll",
+            "lib/net6.0/Matrix.Core.winmd",
+            "lib/net6.0/Matrix.Tool.exe",
+        },
+    };
+
+    /// <summary>
+    /// Project frameworks of the reduced matrix, run in normal CI.
+    /// </summary>
+    private static readonly string[] ReducedFrameworks =
+    {
+        "net472",
+        "netcoreapp3.1",
+        "net6.0",
+        "net8.0",
+        "net8.0-windows",
+    };
+
+    /// <summary>
+    /// Additional project frameworks of the full matrix.
+    /// </summary>
+    private static readonly string[] FullFrameworks =
+    {
+        "net40",
+        "net462",
+        "net48",
+        "netstandard1.3",
+        "netstandard2.0",
+        "netstandard2.1",
+        "net5.0",
+        "net7.0",
+        "net9.0",
+        "net6.0-android31.0",
+        "net8.0-android34.0",
+        "net8.0-ios17.0",
+        "net8.0-windows10.0.19041.0",
+    };
+
+    public static IEnumerable<object[]> LayoutNames() => Layouts.Keys.Select(name => new object[] { name });
+
+    [Theory]
+    [MemberData(nameof(LayoutNames))]
+    public void SelectedAssets_MatchNuGetClient(string layout)
+    {
+        var files = Layouts[layout];
+        var targetFrameworks = ProjectFrameworks();
+
+        var response = GonugetBridge.SelectAssetsForPackage(PackageId(layout), files, targetFrameworks);
+
+        var divergences = new StringBuilder();
+        foreach (var target in response.Targets)
+        {
+            var (compile, runtime) = SelectWithNuGetClient(files, target.TargetFramework);
+            AppendDivergence(divergences, layout, target.TargetFramework, "compile", target.Compile, compile);
+            AppendDivergence(divergences, layout, target.TargetFramework, "runtime", target.Runtime, runtime);
+        }
+
+        Assert.Equal(targetFrameworks.Length, response.Targets.Length);
+        Assert.True(divergences.Length == 0, "Asset selection differs from NuGet.Client:" + Environment.NewLine + divergences);
+    }
+
+    /// <summary>
+    /// Returns the project frameworks of the matrix selected by the environment.
+    /// </summary>
+    private static string[] ProjectFrameworks()
+    {
+        if (Environment.GetEnvironmentVariable(FullMatrixVariable) == "1")
+        {
+            return ReducedFrameworks.Concat(FullFrameworks).ToArray();
+        }
+        return ReducedFrameworks;
+    }
+
+    /// <summary>
+    /// Returns a package ID for a layout name, e.g. "Matrix.LibOnly" for "lib-only".
+    /// </summary>
+    private static string PackageId(string layout)
+    {
+        var parts = layout.Split('-').Select(part => char.ToUpperInvariant(part[0]) + part[1..]);
+        return "Matrix." + string.Concat(parts);
+    }
+
+    /// <summary>
+    /// Selects the compile and runtime groups with NuGet.Client's content model, as
+    /// LockFileUtils does for a target without a runtime identifier.
+    /// </summary>
+    private static (string[] Compile, string[] Runtime) SelectWithNuGetClient(string[] files, string targetFramework)
+    {
+        var conventions = new ManagedCodeConventions(new RuntimeGraph());
+        var collection = new ContentItemCollection();
+        collection.Load(files);
+        var criteria = conventions.Criteria.ForFramework(NuGetFramework.Parse(targetFramework));
+
+        var compile = collection.FindBestItemGroup(criteria,
+            conventions.Patterns.CompileRefAssemblies,
+            conventions.Patterns.CompileLibAssemblies);
+        var runtime = collection.FindBestItemGroup(criteria, conventions.Patterns.RuntimeAssemblies);
+
+        return (Paths(compile), Paths(runtime));
+    }
+
+    private static string[] Paths(ContentItemGroup? group)
+    {
+        return group?.Items.Select(item => item.Path).OrderBy(path => path, StringComparer.Ordinal).ToArray()
+            ?? Array.Empty<string>();
+    }
+
+    /// <summary>
+    /// Appends a readable description of a differing group, naming the layout and framework.
+    /// </summary>
+    private static void AppendDivergence(
+        StringBuilder divergences,
+        string layout,
+        string targetFramework,
+        string group,
+        string[] gonuget,
+        string[] nuget)
+    {
+        if (gonuget.SequenceEqual(nuget, StringComparer.Ordinal))
+        {
+            return;
+        }
+
+        divergences.AppendLine($"  layout '{layout}', project {targetFramework}, {group}:");
+        foreach (var path in nuget.Except(gonuget, StringComparer.Ordinal))
+        {
+            divergences.AppendLine($"    - {path} (NuGet.Client only)");
+        }
+        foreach (var path in gonuget.Except(nuget, StringComparer.Ordinal))
+        {
+            divergences.AppendLine($"    + {path} (gonuget only)");
+        }
+    }
+}
@@ -464,6 +464,47 @@ public class PackageReaderTests
         Assert.False(isSigned);
     }
 
+    [Theory]
+    [InlineData("1.0.0", "lib/net45/test.dll")]
+    [InlineData("1.0.0-beta", "lib/net45/test.dll")]
+    [InlineData("1.0.0", "contentFiles/any/any/readme.txt")]
+    [InlineData("1.0.0", "content/web.config.install.xdt")]
+    public void RoundTrip_NuspecNamespace_MatchesNuGetClient(string version, string file)
+    {
+        var files = new Dictionary<string, byte[]>
+        {
+            [file] = "content"u8.ToArray()
+        };
+
+        var buildResult = GonugetBridge.BuildPackage(
+            id: "Test.Package",
+            version: version,
+            description: "Test description",
+            authors: ["TestAuthor"],
+            files: files);
+
+        // Build the same package with NuGet.Client, which picks the minimal schema
+        var builder = new PackageBuilder
+        {
+            Id = "Test.Package",
+            Version = NuGet.Versioning.NuGetVersion.Parse(version),
+            Description = "Test description"
+        };
+        builder.Authors.Add("TestAuthor");
+        builder.Files.Add(new PhysicalPackageFile(new MemoryStream("content"u8.ToArray())) { TargetPath = file });
+        using var expected = new MemoryStream();
+        builder.Save(expected);
+        expected.Position = 0;
+
+        using var stream = new MemoryStream(buildResult.PackageBytes);
+        using var gonugetReader = new PackageArchiveReader(stream);
+        using var csharpReader = new PackageArchiveReader(expected);
+
+        Assert.Equal(
+            csharpReader.NuspecReader.Xml.Root!.Name.NamespaceName,
+            gonugetReader.NuspecReader.Xml.Root!.Name.NamespaceName);
+    }
+
     #endregion
 
     #region OPC Compliance
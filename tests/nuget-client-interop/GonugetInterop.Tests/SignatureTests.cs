@@ -435,10 +435,12 @@ public sealed class SignatureTests : IDisposable
             allowUntrustedRoot: false,
             requireTimestamp: false);
 
-        // Assert
+        // Assert - NuGet.Client reports an untrusted chain as NU3018
         Assert.False(result.Valid);
         Assert.NotNull(result.Errors);
         Assert.NotEmpty(result.Errors);
+        Assert.NotNull(result.Issues);
+        Assert.Contains(result.Issues, issue => issue.Code == "NU3018" && issue.IsError);
     }
 
     [Fact]
@@ -32,8 +32,18 @@ public class CompareProjectAssetsResponse
     /// </summary>
     public bool PathsMatch { get; set; }
 
+    /// <summary>
+    /// No path differs from NuGet.Client in its separators alone.
+    /// </summary>
+    public bool FormattingMatch { get; set; }
+
     /// <summary>
     /// Human-readable list of differences found during comparison.
     /// </summary>
     public List<string> Differences { get; set; } = new();
+
+    /// <summary>
+    /// Paths that differ from NuGet.Client only in their separators or a trailing separator.
+    /// </summary>
+    public List<string> FormattingErrors { get; set; } = new();
 }
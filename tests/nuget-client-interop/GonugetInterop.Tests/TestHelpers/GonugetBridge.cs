@@ -68,7 +68,9 @@ public static class GonugetBridge
         byte[] signature,
         byte[][]? trustedRoots = null,
         bool allowUntrustedRoot = false,
-        bool requireTimestamp = false)
+        bool requireTimestamp = false,
+        string? revocationMode = null,
+        int revocationTimeoutMs = 0)
     {
         var request = new
         {
@@ -78,7 +80,9 @@ public static class GonugetBridge
                 signature,
                 trustedRoots = trustedRoots ?? Array.Empty<byte[]>(),
                 allowUntrustedRoot,
-                requireTimestamp
+                requireTimestamp,
+                revocationMode,
+                revocationTimeoutMs
             }
         };
 
@@ -118,6 +122,29 @@ public static class GonugetBridge
         return Execute<ParseVersionResponse>(request);
     }
 
+    /// <summary>
+    /// Picks the version of a list that satisfies a range, as core.ResolveVersion does.
+    /// </summary>
+    /// <param name="versions">The available versions.</param>
+    /// <param name="range">The version range, or an empty string for any version.</param>
+    /// <param name="selection">"lowest" (restore) or "highest" (add and update).</param>
+    /// <param name="includePrerelease">Whether prerelease versions are allowed in any range.</param>
+    /// <returns>The selected version, empty when no version satisfies the range.</returns>
+    public static SelectVersionResponse SelectVersion(
+        string[] versions,
+        string range,
+        string selection,
+        bool includePrerelease = false)
+    {
+        var request = new
+        {
+            action = "select_version",
+            data = new { versions, range, selection, includePrerelease }
+        };
+
+        return Execute<SelectVersionResponse>(request);
+    }
+
     /// <summary>
     /// Checks if a package framework is compatible with a project framework.
     /// </summary>
@@ -256,6 +283,28 @@ public static class GonugetBridge
         return Execute<FindAssembliesResponse>(request);
     }
 
+    /// <summary>
+    /// Builds a package from a file list, restores it into a project targeting each framework
+    /// and returns the compile and runtime groups gonuget wrote to project.assets.json.
+    /// </summary>
+    /// <param name="packageId">The ID of the generated package.</param>
+    /// <param name="files">Package file paths (e.g., "lib/net6.0/MyLib.dll").</param>
+    /// <param name="targetFrameworks">Project target frameworks (e.g., "net8.0").</param>
+    /// <returns>The selected assets per target framework, in request order.</returns>
+    public static SelectAssetsForPackageResponse SelectAssetsForPackage(
+        string packageId,
+        string[] files,
+        string[] targetFrameworks)
+    {
+        var request = new
+        {
+            action = "select_assets_for_package",
+            data = new { packageId, files, targetFrameworks }
+        };
+
+        return Execute<SelectAssetsForPackageResponse>(request);
+    }
+
     /// <summary>
     /// Parses a single asset path and extracts its properties (tfm, assembly, rid, etc.).
     /// </summary>
@@ -751,7 +800,8 @@ public static class GonugetBridge
     public static ResolveTransitiveResponse ResolveTransitive(
         PackageSpec[] rootPackages,
         string targetFramework,
-        string[] sources)
+        string[] sources,
+        bool allowPrereleaseEverywhere = false)
     {
         var request = new
         {
@@ -760,7 +810,8 @@ public static class GonugetBridge
             {
                 rootPackages,
                 targetFramework,
-                sources
+                sources,
+                allowPrereleaseEverywhere
             }
         };
 
@@ -0,0 +1,36 @@
+using System;
+
+namespace GonugetInterop.Tests.TestHelpers;
+
+/// <summary>
+/// Response from select_assets_for_package: the assets a gonuget restore selected
+/// from a generated package, per project target framework.
+/// </summary>
+public class SelectAssetsForPackageResponse
+{
+    /// <summary>
+    /// One entry per requested target framework, in request order.
+    /// </summary>
+    public SelectedAssets[] Targets { get; set; } = Array.Empty<SelectedAssets>();
+}
+
+/// <summary>
+/// The compile and runtime groups of the package in one project.assets.json target.
+/// </summary>
+public class SelectedAssets
+{
+    /// <summary>
+    /// The project target framework (e.g., "net8.0").
+    /// </summary>
+    public string TargetFramework { get; set; } = string.Empty;
+
+    /// <summary>
+    /// Compile items, sorted by path.
+    /// </summary>
+    public string[] Compile { get; set; } = Array.Empty<string>();
+
+    /// <summary>
+    /// Runtime items, sorted by path.
+    /// </summary>
+    public string[] Runtime { get; set; } = Array.Empty<string>();
+}
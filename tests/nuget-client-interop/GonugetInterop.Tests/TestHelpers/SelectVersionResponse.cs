@@ -0,0 +1,12 @@
+namespace GonugetInterop.Tests.TestHelpers;
+
+/// <summary>
+/// Response from the select_version operation.
+/// </summary>
+public class SelectVersionResponse
+{
+    /// <summary>
+    /// The selected version, empty when no version satisfies the range.
+    /// </summary>
+    public string Version { get; set; } = "";
+}
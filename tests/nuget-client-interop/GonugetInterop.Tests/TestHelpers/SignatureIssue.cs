@@ -0,0 +1,22 @@
+namespace GonugetInterop.Tests.TestHelpers;
+
+/// <summary>
+/// A signature verification error or warning with its NuGet log code.
+/// </summary>
+public class SignatureIssue
+{
+    /// <summary>
+    /// NuGet log code (e.g. NU3012 for a revoked certificate, NU3018 for a chain building issue).
+    /// </summary>
+    public required string Code { get; set; }
+
+    /// <summary>
+    /// Issue message.
+    /// </summary>
+    public required string Message { get; set; }
+
+    /// <summary>
+    /// Whether the issue fails verification (otherwise it is a warning).
+    /// </summary>
+    public bool IsError { get; set; }
+}
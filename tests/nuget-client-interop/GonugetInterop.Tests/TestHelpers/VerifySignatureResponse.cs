@@ -24,4 +24,9 @@ public class VerifySignatureResponse
     /// The subject (Distinguished Name) of the signer certificate.
     /// </summary>
     public string? SignerSubject { get; set; }
+
+    /// <summary>
+    /// Errors and warnings with their NuGet log codes (e.g. NU3018), for comparison with NuGet.Client.
+    /// </summary>
+    public SignatureIssue[]? Issues { get; set; }
 }
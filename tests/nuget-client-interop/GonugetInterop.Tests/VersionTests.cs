@@ -1,4 +1,6 @@
+using System.Linq;
 using GonugetInterop.Tests.TestHelpers;
+using NuGet.Versioning;
 using Xunit;
 
 namespace GonugetInterop.Tests;
@@ -387,4 +389,58 @@ public sealed class VersionTests
     }
 
     #endregion
+
+    #region Version Selection Tests
+
+    private static readonly string[] SelectionVersions =
+        ["0.9.0", "1.0.0-beta", "1.0.0", "1.5.0", "2.0.0-rc.1", "2.0.0", "3.0.0-alpha"];
+
+    [Theory]
+    [InlineData("1.0.0", "lowest", false)]
+    [InlineData("(1.0.0, )", "lowest", false)]
+    [InlineData("[1.1.0, 2.0.0]", "lowest", false)]
+    [InlineData("(, 2.0.0]", "lowest", false)]
+    [InlineData("[1.5.0]", "lowest", false)]
+    [InlineData("[1.2.0]", "lowest", false)]
+    [InlineData("4.0.0", "lowest", false)]
+    [InlineData("1.0.0-alpha", "lowest", false)]
+    [InlineData("[1.0.0-rc, 1.1.0)", "lowest", false)]
+    [InlineData("[2.0.0-rc.1]", "lowest", false)]
+    [InlineData("(1.5.0, )", "lowest", false)]
+    [InlineData("(1.5.0, )", "lowest", true)]
+    [InlineData("", "highest", false)]
+    [InlineData("", "highest", true)]
+    [InlineData("[1.0.0, 2.0.0)", "highest", false)]
+    [InlineData("[1.0.0, 2.0.0)", "highest", true)]
+    [InlineData("[1.0.0, 2.0.0]", "highest", false)]
+    [InlineData("[1.0.0, 2.0.0-rc.2)", "highest", false)]
+    [InlineData("(2.0.0, 3.0.0]", "highest", false)]
+    [InlineData("(2.0.0, 3.0.0]", "highest", true)]
+    public void SelectVersion_MatchesNuGetClient(string range, string selection, bool includePrerelease)
+    {
+        var versions = SelectionVersions.Select(NuGetVersion.Parse).ToList();
+        var versionRange = range == "" ? VersionRange.All : VersionRange.Parse(range);
+
+        // NuGet.Client: restore takes FindBestMatch (lowest, prerelease only for prerelease
+        // bounds); add and update take the highest version the range satisfies
+        NuGetVersion? expected;
+        if (selection == "lowest" && !includePrerelease)
+        {
+            expected = versionRange.FindBestMatch(versions);
+        }
+        else
+        {
+            var prereleaseBound = (versionRange.MinVersion?.IsPrerelease ?? false) ||
+                (versionRange.MaxVersion?.IsPrerelease ?? false);
+            var candidates = versions.Where(v =>
+                versionRange.Satisfies(v) && (includePrerelease || prereleaseBound || !v.IsPrerelease));
+            expected = selection == "lowest" ? candidates.Min() : candidates.Max();
+        }
+
+        var result = GonugetBridge.SelectVersion(SelectionVersions, range, selection, includePrerelease);
+
+        Assert.Equal(expected?.ToString() ?? "", result.Version);
+    }
+
+    #endregion
 }
@@ -0,0 +1,90 @@
+package version
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SortVersions sorts versions in NuGet order, lowest first. Versions that differ only in
+// metadata are equal and keep their order, as with NuGet's VersionComparer.Default.
+func SortVersions(versions []*NuGetVersion) {
+	slices.SortStableFunc(versions, compareSortOrder)
+}
+
+// compareSortOrder compares versions like NuGet's VersionComparer.Default. Unlike Compare,
+// the revision always counts, so 1.0.0.1 sorts after 1.0.0 and the order is total.
+func compareSortOrder(a, b *NuGetVersion) int {
+	if c := cmp.Compare(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.Revision, b.Revision); c != 0 {
+		return c
+	}
+	return compareReleaseLabels(a.ReleaseLabels, b.ReleaseLabels)
+}
+
+// VersionBucket holds the highest version of a group of versions, with and without
+// prerelease versions.
+type VersionBucket struct {
+	// Release is the highest version that isn't a prerelease, nil when there is none
+	Release *NuGetVersion
+
+	// Prerelease is the highest version, prerelease or not
+	Prerelease *NuGetVersion
+}
+
+// Version returns the highest version of the bucket, considering prerelease versions
+// when includePrerelease is set. Returns nil when the bucket has no such version.
+func (b VersionBucket) Version(includePrerelease bool) *NuGetVersion {
+	if includePrerelease {
+		return b.Prerelease
+	}
+	return b.Release
+}
+
+// add makes v the highest version of the bucket; versions are added in ascending order.
+func (b *VersionBucket) add(v *NuGetVersion) {
+	b.Prerelease = v
+	if !v.IsPrerelease() {
+		b.Release = v
+	}
+}
+
+// VersionBuckets holds the highest versions an update from a current version can pick,
+// as used by package list --outdated with --highest-patch and --highest-minor. A bucket
+// version may be lower than the current version when no higher version exists.
+type VersionBuckets struct {
+	// HighestPatch is taken from the versions with the major and minor version of the
+	// current version
+	HighestPatch VersionBucket
+
+	// HighestMinor is taken from the versions with the major version of the current version
+	HighestMinor VersionBucket
+
+	// Highest is taken from all versions
+	Highest VersionBucket
+}
+
+// BucketVersions returns the buckets of sorted, a list in SortVersions order, for an update
+// from current, in a single pass. Versions that compare equal resolve to the last of them.
+func BucketVersions(sorted []*NuGetVersion, current *NuGetVersion) VersionBuckets {
+	var buckets VersionBuckets
+	// The list is ascending, so the last version of a bucket is its highest
+	for _, v := range sorted {
+		buckets.Highest.add(v)
+		if v.Major != current.Major {
+			continue
+		}
+		buckets.HighestMinor.add(v)
+		if v.Minor == current.Minor {
+			buckets.HighestPatch.add(v)
+		}
+	}
+	return buckets
+}
@@ -0,0 +1,164 @@
+package version
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSortVersions(t *testing.T) {
+	want := []string{
+		"0.9.0",
+		"1.0.0-2",
+		"1.0.0-10",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-Beta",
+		"1.0.0-rc.1+build.2", // Metadata doesn't order, so the input order is kept
+		"1.0.0-rc.1+build.1",
+		"1.0.0",
+		"1.0.0.1", // The revision counts, unlike with Compare
+		"1.0.1",
+		"1.2.0.5",
+		"1.10.0",
+		"10.0.0",
+	}
+
+	// Shuffle the input, keeping the metadata-only pair in its order
+	input := slices.Clone(want)
+	rng := rand.New(rand.NewPCG(1, 2))
+	rng.Shuffle(len(input), func(i, j int) { input[i], input[j] = input[j], input[i] })
+	i := slices.Index(input, "1.0.0-rc.1+build.2")
+	j := slices.Index(input, "1.0.0-rc.1+build.1")
+	if i > j {
+		input[i], input[j] = input[j], input[i]
+	}
+
+	versions := make([]*NuGetVersion, len(input))
+	for i, s := range input {
+		versions[i] = MustParse(s)
+	}
+	SortVersions(versions)
+
+	got := make([]string, len(versions))
+	for i, v := range versions {
+		got[i] = v.String()
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("SortVersions() =\n  %v\nwant\n  %v", got, want)
+	}
+}
+
+func TestBucketVersions(t *testing.T) {
+	versions := make([]*NuGetVersion, 0)
+	for _, s := range []string{"1.0.0", "1.0.1", "1.0.2-beta", "1.1.0", "1.2.0-rc.1", "2.0.0", "3.0.0-preview.1"} {
+		versions = append(versions, MustParse(s))
+	}
+	buckets := BucketVersions(versions, MustParse("1.0.1"))
+
+	tests := []struct {
+		name              string
+		bucket            VersionBucket
+		includePrerelease bool
+		want              string
+	}{
+		{"highest patch", buckets.HighestPatch, false, "1.0.1"},
+		{"highest patch with prerelease", buckets.HighestPatch, true, "1.0.2-beta"},
+		{"highest minor", buckets.HighestMinor, false, "1.1.0"},
+		{"highest minor with prerelease", buckets.HighestMinor, true, "1.2.0-rc.1"},
+		{"highest", buckets.Highest, false, "2.0.0"},
+		{"highest with prerelease", buckets.Highest, true, "3.0.0-preview.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.bucket.Version(tt.includePrerelease); got.String() != tt.want {
+				t.Errorf("Version(%v) = %v, want %s", tt.includePrerelease, got, tt.want)
+			}
+		})
+	}
+
+	empty := BucketVersions(versions, MustParse("4.0.0"))
+	if empty.HighestPatch.Version(true) != nil || empty.HighestMinor.Version(true) != nil {
+		t.Errorf("buckets of another major version = %+v, want them empty", empty)
+	}
+}
+
+// TestBucketVersions_Random checks the buckets of random version sets against picking each
+// highest version by brute force.
+func TestBucketVersions_Random(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 11))
+
+	for iteration := range 500 {
+		versions := randomVersions(rng, rng.IntN(40))
+		current := randomVersions(rng, 1)[0]
+
+		sorted := slices.Clone(versions)
+		SortVersions(sorted)
+		for i := 1; i < len(sorted); i++ {
+			if compareSortOrder(sorted[i-1], sorted[i]) > 0 {
+				t.Fatalf("iteration %d: SortVersions() put %s before %s", iteration, sorted[i-1], sorted[i])
+			}
+		}
+
+		buckets := BucketVersions(sorted, current)
+		checks := []struct {
+			name   string
+			bucket VersionBucket
+			in     func(v *NuGetVersion) bool
+		}{
+			{"HighestPatch", buckets.HighestPatch, func(v *NuGetVersion) bool {
+				return v.Major == current.Major && v.Minor == current.Minor
+			}},
+			{"HighestMinor", buckets.HighestMinor, func(v *NuGetVersion) bool { return v.Major == current.Major }},
+			{"Highest", buckets.Highest, func(v *NuGetVersion) bool { return true }},
+		}
+		for _, check := range checks {
+			for _, includePrerelease := range []bool{false, true} {
+				want := highestVersion(versions, func(v *NuGetVersion) bool {
+					return check.in(v) && (includePrerelease || !v.IsPrerelease())
+				})
+				got := check.bucket.Version(includePrerelease)
+				if (got == nil) != (want == nil) || (got != nil && compareSortOrder(got, want) != 0) {
+					t.Fatalf("iteration %d: %s.Version(%v) = %v, want %v\ncurrent: %s\nversions: %v",
+						iteration, check.name, includePrerelease, got, want, current, versions)
+				}
+			}
+		}
+	}
+}
+
+// highestVersion returns the highest of versions that keep accepts, or nil.
+func highestVersion(versions []*NuGetVersion, keep func(v *NuGetVersion) bool) *NuGetVersion {
+	var highest *NuGetVersion
+	for _, v := range versions {
+		if keep(v) && (highest == nil || compareSortOrder(v, highest) > 0) {
+			highest = v
+		}
+	}
+	return highest
+}
+
+// randomVersions returns n versions from a small range, so that buckets overlap and
+// versions repeat, with prerelease labels, legacy revisions and metadata mixed in.
+func randomVersions(rng *rand.Rand, n int) []*NuGetVersion {
+	labels := []string{"alpha", "alpha.1", "beta", "beta.2", "rc.1", "rc.10", "1", "preview"}
+	versions := make([]*NuGetVersion, n)
+	for i := range versions {
+		var s strings.Builder
+		fmt.Fprintf(&s, "%d.%d.%d", rng.IntN(3), rng.IntN(3), rng.IntN(3))
+		if rng.IntN(6) == 0 {
+			fmt.Fprintf(&s, ".%d", 1+rng.IntN(2))
+		}
+		if rng.IntN(3) == 0 {
+			s.WriteString("-" + labels[rng.IntN(len(labels))])
+		}
+		if rng.IntN(5) == 0 {
+			fmt.Fprintf(&s, "+build.%d", rng.IntN(3))
+		}
+		versions[i] = MustParse(s.String())
+	}
+	return versions
+}
@@ -0,0 +1,147 @@
+package version
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// errZeroVersion is returned when marshaling a NuGetVersion that was never set, which
+// would otherwise silently become "0.0.0"
+var errZeroVersion = errors.New("cannot marshal the zero NuGetVersion; use a nil *NuGetVersion for no version")
+
+// isZero reports whether v is the zero value rather than a parsed or constructed version
+func (v *NuGetVersion) isZero() bool {
+	return v.originalString == "" && v.Major == 0 && v.Minor == 0 && v.Patch == 0 && v.Revision == 0 &&
+		len(v.ReleaseLabels) == 0 && v.Metadata == "" && !v.IsLegacyVersion
+}
+
+// MarshalText implements encoding.TextMarshaler, writing the normalized version string.
+// The zero value is an error; a nil *NuGetVersion marshals as JSON null.
+func (v NuGetVersion) MarshalText() ([]byte, error) {
+	if v.isZero() {
+		return nil, errZeroVersion
+	}
+	return []byte(v.ToNormalizedString()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing a version string.
+func (v *NuGetVersion) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*v = *parsed
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, writing the normalized version as a JSON string.
+func (v NuGetVersion) MarshalJSON() ([]byte, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, parsing a JSON string. JSON null leaves v
+// unchanged, as for other types.
+func (v *NuGetVersion) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("version must be a JSON string: %w", err)
+	}
+	return v.UnmarshalText([]byte(s))
+}
+
+// Value implements driver.Valuer, storing the normalized version string. A nil
+// *NuGetVersion is stored as NULL.
+func (v NuGetVersion) Value() (driver.Value, error) {
+	text, err := v.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return string(text), nil
+}
+
+// Scan implements sql.Scanner, parsing a version stored as text. Scan into a
+// **NuGetVersion or sql.Null[NuGetVersion] for nullable columns.
+func (v *NuGetVersion) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return v.UnmarshalText([]byte(src))
+	case []byte:
+		return v.UnmarshalText(src)
+	case nil:
+		return errors.New("cannot scan NULL into a NuGetVersion")
+	default:
+		return fmt.Errorf("cannot scan %T into a NuGetVersion", src)
+	}
+}
+
+// normalizedString writes the range with normalized versions, like String
+func (r Range) normalizedString() string {
+	minBracket, maxBracket := "(", ")"
+	if r.MinInclusive {
+		minBracket = "["
+	}
+	if r.MaxInclusive {
+		maxBracket = "]"
+	}
+
+	minStr, maxStr := "", ""
+	if r.MinVersion != nil {
+		minStr = r.MinVersion.ToNormalizedString()
+	}
+	if r.MaxVersion != nil {
+		maxStr = r.MaxVersion.ToNormalizedString()
+	}
+	return fmt.Sprintf("%s%s, %s%s", minBracket, minStr, maxStr, maxBracket)
+}
+
+// MarshalText implements encoding.TextMarshaler, writing the range in interval notation
+// with normalized versions, such as "[1.0.0, 2.0.0)".
+func (r Range) MarshalText() ([]byte, error) {
+	for _, bound := range []*NuGetVersion{r.MinVersion, r.MaxVersion} {
+		if bound != nil && bound.isZero() {
+			return nil, errZeroVersion
+		}
+	}
+	return []byte(r.normalizedString()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing a version range string.
+func (r *Range) UnmarshalText(text []byte) error {
+	parsed, err := ParseVersionRange(string(text))
+	if err != nil {
+		return err
+	}
+	*r = *parsed
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, writing the range as a JSON string.
+func (r Range) MarshalJSON() ([]byte, error) {
+	text, err := r.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, parsing a JSON string. JSON null leaves r
+// unchanged, as for other types.
+func (r *Range) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("version range must be a JSON string: %w", err)
+	}
+	return r.UnmarshalText([]byte(s))
+}
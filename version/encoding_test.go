@@ -0,0 +1,171 @@
+package version
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNuGetVersion_JSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1.0.0", `"1.0.0"`},
+		{"1.0", `"1.0.0"`},
+		{"01.02.03", `"1.2.3"`},
+		{"1.2.3.4", `"1.2.3.4"`},
+		{"1.0.0-Beta.1+build.5", `"1.0.0-Beta.1+build.5"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			data, err := json.Marshal(MustParse(tt.input))
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", data, tt.want)
+			}
+
+			var v NuGetVersion
+			if err := json.Unmarshal(data, &v); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !v.Equals(MustParse(tt.input)) || v.Metadata != MustParse(tt.input).Metadata {
+				t.Errorf("Unmarshal() = %v, want %s", &v, tt.input)
+			}
+		})
+	}
+}
+
+// TestVersionTypes_JSONSchema pins the JSON of structs holding versions, which external
+// consumers store
+func TestVersionTypes_JSONSchema(t *testing.T) {
+	type document struct {
+		Version   *NuGetVersion            `json:"version"`
+		Missing   *NuGetVersion            `json:"missing"`
+		Range     *Range                   `json:"range"`
+		ByVersion map[*NuGetVersion]string `json:"byVersion"`
+	}
+	doc := document{
+		Version:   MustParse("2.0.0-rc.1"),
+		Range:     MustParseRange("[1.0, 2.0)"),
+		ByVersion: map[*NuGetVersion]string{MustParse("1.0"): "one"},
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":"2.0.0-rc.1","missing":null,"range":"[1.0.0, 2.0.0)","byVersion":{"1.0.0":"one"}}`
+	if string(data) != want {
+		t.Errorf("Marshal() =\n  %s\nwant\n  %s", data, want)
+	}
+
+	var decoded document
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Version.String() != "2.0.0-rc.1" || decoded.Missing != nil || decoded.Range.String() != "[1.0.0, 2.0.0)" {
+		t.Errorf("Unmarshal() = %+v", decoded)
+	}
+}
+
+func TestNuGetVersion_MarshalZeroValue(t *testing.T) {
+	for _, v := range []any{&NuGetVersion{}, NuGetVersion{}, struct{ V NuGetVersion }{}, &Range{MinVersion: &NuGetVersion{}}} {
+		if data, err := json.Marshal(v); err == nil || !strings.Contains(err.Error(), "zero NuGetVersion") {
+			t.Errorf("Marshal(%#v) = %s, %v, want the zero value rejected", v, data, err)
+		}
+	}
+
+	// A parsed or constructed 0.0.0 is a version
+	for _, v := range []*NuGetVersion{MustParse("0.0.0"), {Revision: 0, IsLegacyVersion: true}} {
+		if _, err := json.Marshal(v); err != nil {
+			t.Errorf("Marshal(%#v) error = %v", v, err)
+		}
+	}
+}
+
+func TestNuGetVersion_UnmarshalErrors(t *testing.T) {
+	for _, input := range []string{`""`, `"not-a-version"`, `1`, `{"Major":1}`} {
+		var v NuGetVersion
+		if err := json.Unmarshal([]byte(input), &v); err == nil {
+			t.Errorf("Unmarshal(%s) = %v, want an error", input, &v)
+		}
+	}
+
+	var r Range
+	if err := json.Unmarshal([]byte(`"[1.0, 2.0"`), &r); err == nil {
+		t.Errorf("Unmarshal() of a malformed range = %v, want an error", &r)
+	}
+}
+
+func TestRange_JSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1.0", `"[1.0.0, )"`},
+		{"[1.0, 2.0]", `"[1.0.0, 2.0.0]"`},
+		{"(, 2.0)", `"(, 2.0.0)"`},
+		{"[1.2.3]", `"[1.2.3, 1.2.3]"`},
+		{"(, )", `"(, )"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			data, err := json.Marshal(MustParseRange(tt.input))
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", data, tt.want)
+			}
+
+			var r Range
+			if err := json.Unmarshal(data, &r); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if again, _ := json.Marshal(&r); string(again) != tt.want {
+				t.Errorf("round trip = %s, want %s", again, tt.want)
+			}
+		})
+	}
+}
+
+func TestNuGetVersion_SQL(t *testing.T) {
+	value, err := MustParse("1.0-beta").Value()
+	if err != nil || value != "1.0.0-beta" {
+		t.Errorf("Value() = %v, %v, want 1.0.0-beta", value, err)
+	}
+	if value, err := driver.DefaultParameterConverter.ConvertValue((*NuGetVersion)(nil)); value != nil || err != nil {
+		t.Errorf("Value() of nil = %v, %v, want NULL", value, err)
+	}
+	var _ driver.Valuer = (*NuGetVersion)(nil)
+	var _ sql.Scanner = (*NuGetVersion)(nil)
+
+	for _, src := range []any{"1.0.0-beta", []byte("1.0.0-beta")} {
+		var v NuGetVersion
+		if err := v.Scan(src); err != nil || v.String() != "1.0.0-beta" {
+			t.Errorf("Scan(%#v) = %v, %v", src, &v, err)
+		}
+	}
+	for _, src := range []any{nil, int64(1), "bad version"} {
+		var v NuGetVersion
+		if err := v.Scan(src); err == nil {
+			t.Errorf("Scan(%#v) = %v, want an error", src, &v)
+		}
+	}
+
+	// Nullable columns scan through sql.Null
+	var nullable sql.Null[NuGetVersion]
+	if err := nullable.Scan(nil); err != nil || nullable.Valid {
+		t.Errorf("sql.Null Scan(nil) = %+v, %v", nullable, err)
+	}
+	if err := nullable.Scan("2.0.0"); err != nil || !nullable.Valid || nullable.V.String() != "2.0.0" {
+		t.Errorf("sql.Null Scan() = %+v, %v", nullable, err)
+	}
+}
@@ -26,6 +26,21 @@ const (
 
 	// FloatMajor floats to latest major: *
 	FloatMajor
+
+	// FloatPrereleaseRevision floats to latest revision and prerelease: 1.0.0.*-*
+	FloatPrereleaseRevision
+
+	// FloatPrereleasePatch floats to latest patch and prerelease: 1.0.*-*
+	FloatPrereleasePatch
+
+	// FloatPrereleaseMinor floats to latest minor and prerelease: 1.*-*
+	FloatPrereleaseMinor
+
+	// FloatPrereleaseMajor floats to latest major and prerelease: *-rc.*
+	FloatPrereleaseMajor
+
+	// FloatAbsoluteLatest floats to the latest version, prerelease or not: *-*
+	FloatAbsoluteLatest
 )
 
 // String returns the string representation of FloatBehavior.
@@ -43,24 +58,50 @@ func (f FloatBehavior) String() string {
 		return "minor"
 	case FloatMajor:
 		return "major"
+	case FloatPrereleaseRevision:
+		return "prerelease-revision"
+	case FloatPrereleasePatch:
+		return "prerelease-patch"
+	case FloatPrereleaseMinor:
+		return "prerelease-minor"
+	case FloatPrereleaseMajor:
+		return "prerelease-major"
+	case FloatAbsoluteLatest:
+		return "absolute-latest"
 	default:
 		return "unknown"
 	}
 }
 
 // FloatRange represents a floating version range.
+// Matches NuGet.Versioning.FloatRange.
 type FloatRange struct {
 	MinVersion    *NuGetVersion
 	FloatBehavior FloatBehavior
+	// ReleasePrefix is the prerelease label a prerelease float requires, e.g. "rc." for
+	// 1.0.0-rc.*; empty for 1.0.0-* and for floats without a prerelease part
+	ReleasePrefix string
 }
 
-// ParseFloatRange parses floating version ranges like 1.0.*, 1.0.0-*, or *.
+// ParseFloatRange parses floating version ranges like 1.0.*, 1.0.0-*, 1.0.0-rc.*,
+// 1.*-*, *-* or *. The minimum version fills the floating parts with their lowest value.
 func ParseFloatRange(s string) (*FloatRange, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return nil, fmt.Errorf("float range cannot be empty")
 	}
 
+	firstStar := strings.Index(s, "*")
+	lastStar := strings.LastIndex(s, "*")
+	switch {
+	case firstStar == -1:
+		return nil, fmt.Errorf("float range must contain wildcard: %s", s)
+	case strings.Contains(s, "+"):
+		return nil, fmt.Errorf("float range can't float build metadata: %s", s)
+	case lastStar != len(s)-1:
+		return nil, fmt.Errorf("invalid wildcard position in: %s", s)
+	}
+
 	// Wildcard only: *
 	if s == "*" {
 		return &FloatRange{
@@ -69,42 +110,68 @@ func ParseFloatRange(s string) (*FloatRange, error) {
 		}, nil
 	}
 
-	// Prerelease float: 1.0.0-*
-	if strings.HasSuffix(s, "-*") {
-		versionPart := s[:len(s)-2]
-		v, err := Parse(versionPart)
+	if s == "*-*" {
+		return &FloatRange{
+			MinVersion:    MustParse("0.0.0-0"),
+			FloatBehavior: FloatAbsoluteLatest,
+		}, nil
+	}
+
+	// Two wildcards float both the version and its prerelease label: 1.*-rc.*
+	if firstStar != lastStar {
+		dash := strings.Index(s, "-")
+		if dash == -1 || firstStar != dash-1 {
+			return nil, fmt.Errorf("invalid float range: %s", s)
+		}
+
+		stable := s[:firstStar] + "0"
+		var behavior FloatBehavior
+		switch strings.Count(stable, ".") {
+		case 0:
+			behavior = FloatPrereleaseMajor
+		case 1:
+			behavior = FloatPrereleaseMinor
+		case 2:
+			behavior = FloatPrereleasePatch
+		case 3:
+			behavior = FloatPrereleaseRevision
+		default:
+			return nil, fmt.Errorf("invalid float range: %s", s)
+		}
+
+		prefix := s[dash+1 : lastStar]
+		v, err := Parse(stable + "-" + lowestRelease(prefix))
 		if err != nil {
 			return nil, fmt.Errorf("invalid float range: %w", err)
 		}
 		return &FloatRange{
 			MinVersion:    v,
-			FloatBehavior: FloatPrerelease,
+			FloatBehavior: behavior,
+			ReleasePrefix: prefix,
 		}, nil
 	}
 
-	// Patch/Minor/Major float: 1.0.*, 1.*, or *.*.*
-	if !strings.Contains(s, "*") {
-		return nil, fmt.Errorf("float range must contain wildcard: %s", s)
-	}
-
-	parts := strings.Split(s, ".")
-	floatIndex := -1
-	for i, part := range parts {
-		if part == "*" {
-			floatIndex = i
-			break
+	// Prerelease float: 1.0.0-*, 1.0.0-rc.* or 1.0.0-beta*
+	if dash := strings.Index(s, "-"); dash != -1 {
+		if dash != strings.LastIndex(s, "-") {
+			return nil, fmt.Errorf("invalid float range: %s", s)
 		}
+		prefix := s[dash+1 : lastStar]
+		v, err := Parse(s[:dash] + "-" + lowestRelease(prefix))
+		if err != nil {
+			return nil, fmt.Errorf("invalid float range: %w", err)
+		}
+		return &FloatRange{
+			MinVersion:    v,
+			FloatBehavior: FloatPrerelease,
+			ReleasePrefix: prefix,
+		}, nil
 	}
 
-	if floatIndex == -1 {
-		return nil, fmt.Errorf("invalid float range: %s", s)
-	}
-
-	// Determine float behavior based on position
+	// Patch/Minor/Revision float: 1.0.*, 1.* or 1.0.0.*
+	stable := s[:lastStar] + "0"
 	var behavior FloatBehavior
-	switch floatIndex {
-	case 0:
-		behavior = FloatMajor
+	switch strings.Count(stable, ".") {
 	case 1:
 		behavior = FloatMinor
 	case 2:
@@ -115,102 +182,161 @@ func ParseFloatRange(s string) (*FloatRange, error) {
 		return nil, fmt.Errorf("invalid wildcard position in: %s", s)
 	}
 
-	// Build minimum version from non-wildcard parts
-	var minVersion *NuGetVersion
-	if floatIndex > 0 {
-		versionParts := parts[:floatIndex]
-		// Pad with zeros to make a valid version
-		for len(versionParts) < 2 {
-			versionParts = append(versionParts, "0")
-		}
-		versionStr := strings.Join(versionParts, ".")
-		v, err := Parse(versionStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid float range: %w", err)
-		}
-		minVersion = v
+	v, err := Parse(stable)
+	if err != nil {
+		return nil, fmt.Errorf("invalid float range: %w", err)
 	}
-
 	return &FloatRange{
-		MinVersion:    minVersion,
+		MinVersion:    v,
 		FloatBehavior: behavior,
 	}, nil
 }
 
-// Satisfies returns true if the version satisfies this floating range.
+// lowestRelease returns the lowest prerelease label starting with prefix: a numeric 0
+// when the prefix is empty or ends a label ("rc." becomes "rc.0"), the prefix itself
+// otherwise ("beta").
+func lowestRelease(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, ".") {
+		return prefix + "0"
+	}
+	if strings.HasSuffix(prefix, "-") {
+		return prefix + "-"
+	}
+	return prefix
+}
+
+// Satisfies returns true if the version satisfies this floating range. Stable floats
+// only match stable versions; prerelease floats match the stable version too.
 func (f *FloatRange) Satisfies(version *NuGetVersion) bool {
 	if version == nil {
 		return false
 	}
 
-	// No minimum version means any version satisfies (for * wildcard)
-	if f.MinVersion == nil {
+	switch f.FloatBehavior {
+	case FloatAbsoluteLatest:
 		return true
+	case FloatMajor:
+		return !version.IsPrerelease()
 	}
 
+	if f.MinVersion == nil {
+		return false
+	}
+
+	sameMajor := version.Major == f.MinVersion.Major
+	sameMinor := sameMajor && version.Minor == f.MinVersion.Minor
+	samePatch := sameMinor && version.Patch == f.MinVersion.Patch
+
 	switch f.FloatBehavior {
 	case FloatPrerelease:
-		// Must match major.minor.patch exactly, can have any prerelease
-		return version.Major == f.MinVersion.Major &&
-			version.Minor == f.MinVersion.Minor &&
-			version.Patch == f.MinVersion.Patch
-
+		return version.CompareNumericOnly(f.MinVersion) == 0 && f.matchesRelease(version)
+	case FloatPrereleaseRevision:
+		return samePatch && f.matchesRelease(version)
+	case FloatPrereleasePatch:
+		return sameMinor && f.matchesRelease(version)
+	case FloatPrereleaseMinor:
+		return sameMajor && f.matchesRelease(version)
+	case FloatPrereleaseMajor:
+		return f.matchesRelease(version)
 	case FloatRevision:
-		// Must match major.minor.patch, can have any revision
-		return version.Major == f.MinVersion.Major &&
-			version.Minor == f.MinVersion.Minor &&
-			version.Patch == f.MinVersion.Patch
-
+		return samePatch && !version.IsPrerelease()
 	case FloatPatch:
-		// Must match major.minor, can have any patch
-		return version.Major == f.MinVersion.Major &&
-			version.Minor == f.MinVersion.Minor
-
+		return sameMinor && !version.IsPrerelease()
 	case FloatMinor:
-		// Must match major, can have any minor
-		return version.Major == f.MinVersion.Major
-
-	case FloatMajor:
-		// Any version satisfies
-		return true
-
+		return sameMajor && !version.IsPrerelease()
 	default:
 		return false
 	}
 }
 
-// FindBestMatch finds the highest version that satisfies this floating range.
+// matchesRelease reports whether version is stable or has a prerelease label starting
+// with the release prefix
+func (f *FloatRange) matchesRelease(version *NuGetVersion) bool {
+	if !version.IsPrerelease() {
+		return true
+	}
+	release := strings.Join(version.ReleaseLabels, ".")
+	return len(release) >= len(f.ReleasePrefix) && strings.EqualFold(release[:len(f.ReleasePrefix)], f.ReleasePrefix)
+}
+
+// FindBestMatch finds the version a floating range resolves to among versions, like
+// NuGet's VersionRange.FindBestMatch for the range [MinVersion, ): the highest version
+// the float matches, or else the lowest version above the minimum. Prerelease versions
+// are only considered when the minimum version is a prerelease. Returns nil when no
+// version is at or above the minimum.
 func (f *FloatRange) FindBestMatch(versions []*NuGetVersion) *NuGetVersion {
 	var best *NuGetVersion
-
 	for _, v := range versions {
-		if f.Satisfies(v) {
-			if best == nil || v.GreaterThan(best) {
-				best = v
-			}
+		if f.isBetter(best, v) {
+			best = v
 		}
 	}
-
 	return best
 }
 
+// isBetter reports whether considering is a better match than current.
+// Matches VersionRange.IsBetter for a floating range.
+func (f *FloatRange) isBetter(current, considering *NuGetVersion) bool {
+	if considering == nil {
+		return false
+	}
+	prereleaseAllowed := f.MinVersion != nil && f.MinVersion.IsPrerelease()
+	if considering.IsPrerelease() && !prereleaseAllowed {
+		return false
+	}
+	if f.MinVersion != nil && considering.LessThan(f.MinVersion) {
+		return false
+	}
+	if current == nil {
+		return true
+	}
+
+	currentFloats := f.Satisfies(current)
+	consideringFloats := f.Satisfies(considering)
+	switch {
+	case currentFloats && consideringFloats:
+		// Prefer the highest version the float matches
+		return considering.GreaterThan(current)
+	case currentFloats != consideringFloats:
+		return consideringFloats
+	default:
+		// Neither floats: prefer the lowest version above the minimum
+		return considering.LessThan(current)
+	}
+}
+
 // String returns the string representation of the floating range.
 func (f *FloatRange) String() string {
 	if f.MinVersion == nil {
 		return "*"
 	}
 
+	v := f.MinVersion
 	switch f.FloatBehavior {
 	case FloatPrerelease:
-		return f.MinVersion.String() + "-*"
+		stable := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+		if v.IsLegacyVersion {
+			stable += fmt.Sprintf(".%d", v.Revision)
+		}
+		return stable + "-" + f.ReleasePrefix + "*"
 	case FloatRevision:
-		return fmt.Sprintf("%d.%d.%d.*", f.MinVersion.Major, f.MinVersion.Minor, f.MinVersion.Patch)
+		return fmt.Sprintf("%d.%d.%d.*", v.Major, v.Minor, v.Patch)
 	case FloatPatch:
-		return fmt.Sprintf("%d.%d.*", f.MinVersion.Major, f.MinVersion.Minor)
+		return fmt.Sprintf("%d.%d.*", v.Major, v.Minor)
 	case FloatMinor:
-		return fmt.Sprintf("%d.*", f.MinVersion.Major)
+		return fmt.Sprintf("%d.*", v.Major)
 	case FloatMajor:
 		return "*"
+	case FloatPrereleaseRevision:
+		return fmt.Sprintf("%d.%d.%d.*-%s*", v.Major, v.Minor, v.Patch, f.ReleasePrefix)
+	case FloatPrereleasePatch:
+		return fmt.Sprintf("%d.%d.*-%s*", v.Major, v.Minor, f.ReleasePrefix)
+	case FloatPrereleaseMinor:
+		return fmt.Sprintf("%d.*-%s*", v.Major, f.ReleasePrefix)
+	case FloatPrereleaseMajor:
+		return "*-" + f.ReleasePrefix + "*"
+	case FloatAbsoluteLatest:
+		return "*-*"
 	default:
 		return ""
 	}
@@ -14,7 +14,16 @@ func TestParseFloatRange(t *testing.T) {
 		{"minor float", "1.0.*", FloatPatch, false},
 		{"patch float", "1.0.0.*", FloatRevision, false},
 		{"prerelease float", "1.0.0-*", FloatPrerelease, false},
+		{"prerelease prefix float", "1.0.0-rc.*", FloatPrerelease, false},
+		{"prerelease revision float", "1.0.0.*-*", FloatPrereleaseRevision, false},
+		{"prerelease patch float", "1.0.*-beta*", FloatPrereleasePatch, false},
+		{"prerelease minor float", "8.*-preview*", FloatPrereleaseMinor, false},
+		{"prerelease major float", "*-rc.*", FloatPrereleaseMajor, false},
+		{"absolute latest", "*-*", FloatAbsoluteLatest, false},
 		{"no wildcard", "1.0.0", FloatNone, true},
+		{"wildcard not last", "1.*.0", FloatNone, true},
+		{"wildcard in metadata", "1.0.0+*", FloatNone, true},
+		{"two wildcards without prerelease", "1.*.*", FloatNone, true},
 		{"empty", "", FloatNone, true},
 	}
 
@@ -41,7 +50,7 @@ func TestFloatRange_Satisfies(t *testing.T) {
 	}{
 		// Major float
 		{"major float any", "*", "5.0.0", true},
-		{"major float prerelease", "*", "1.0.0-beta", true},
+		{"major float prerelease", "*", "1.0.0-beta", false},
 
 		// Minor float
 		{"minor float match", "1.*", "1.5.0", true},
@@ -60,6 +69,21 @@ func TestFloatRange_Satisfies(t *testing.T) {
 		{"prerelease float match", "1.0.0-*", "1.0.0-beta", true},
 		{"prerelease float match stable", "1.0.0-*", "1.0.0", true},
 		{"prerelease float no match", "1.0.0-*", "1.0.1", false},
+
+		// Prerelease prefix
+		{"prefix match", "1.0.0-rc.*", "1.0.0-rc.2", true},
+		{"prefix case insensitive", "1.0.0-rc.*", "1.0.0-RC.2", true},
+		{"prefix no match", "1.0.0-rc.*", "1.0.0-beta.1", false},
+		{"prefix stable", "1.0.0-rc.*", "1.0.0", true},
+
+		// Version and prerelease floats
+		{"prerelease minor match", "8.*-preview*", "8.2.0-preview.3", true},
+		{"prerelease minor other major", "8.*-preview*", "9.0.0-preview.1", false},
+		{"prerelease minor other label", "8.*-preview*", "8.0.0-rc.1", false},
+		{"prerelease patch match", "1.0.*-*", "1.0.3-alpha", true},
+		{"prerelease patch other minor", "1.0.*-*", "1.1.0-alpha", false},
+		{"prerelease major match", "*-rc.*", "5.0.0-rc.1", true},
+		{"absolute latest prerelease", "*-*", "5.0.0-alpha", true},
 	}
 
 	for _, tt := range tests {
@@ -98,6 +122,8 @@ func TestFloatRange_FindBestMatch(t *testing.T) {
 		{"major 2", "2.*", "2.5.0"},
 		{"minor 1.0", "1.0.*", "1.0.5"},
 		{"no match", "3.*", ""},
+		{"nearest above the float", "0.9.*", "1.0.0"},
+		{"nearest above within major", "1.2.*", "1.5.0"},
 	}
 
 	for _, tt := range tests {
@@ -135,6 +161,8 @@ func TestFloatBehavior_String(t *testing.T) {
 		{FloatPatch, "patch"},
 		{FloatMinor, "minor"},
 		{FloatMajor, "major"},
+		{FloatPrereleaseMinor, "prerelease-minor"},
+		{FloatAbsoluteLatest, "absolute-latest"},
 	}
 
 	for _, tt := range tests {
@@ -146,3 +174,56 @@ func TestFloatBehavior_String(t *testing.T) {
 		})
 	}
 }
+
+func TestFloatRange_FindBestMatch_Prerelease(t *testing.T) {
+	versions := []*NuGetVersion{
+		MustParse("1.0.0"),
+		MustParse("1.1.0-beta.1"),
+		MustParse("2.0.0-beta.1"),
+		MustParse("2.0.0-rc.1"),
+		MustParse("2.0.0-rc.2"),
+		MustParse("3.0.0"),
+		MustParse("3.1.0-preview.1"),
+	}
+
+	tests := []struct {
+		floatStr string
+		expected string
+	}{
+		{"*", "3.0.0"},
+		{"2.0.0-*", "2.0.0-rc.2"},
+		{"2.0.0-rc.*", "2.0.0-rc.2"},
+		{"2.0.0-beta*", "2.0.0-beta.1"},
+		{"1.1.*-*", "1.1.0-beta.1"},
+		{"3.*-preview*", "3.1.0-preview.1"},
+		{"*-*", "3.1.0-preview.1"},
+		// Stable floats skip prerelease versions even above the minimum
+		{"1.1.*", "3.0.0"},
+		// Nothing matches the prefix: the lowest version above the minimum
+		{"2.0.0-zeta*", "3.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.floatStr, func(t *testing.T) {
+			fr, err := ParseFloatRange(tt.floatStr)
+			if err != nil {
+				t.Fatalf("ParseFloatRange() error = %v", err)
+			}
+			if got := fr.FindBestMatch(versions); got == nil || got.String() != tt.expected {
+				t.Errorf("FindBestMatch() = %v, want %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFloatRange_String(t *testing.T) {
+	for _, s := range []string{"*", "1.*", "1.0.*", "1.0.0.*", "1.0.0-*", "1.0.0-rc.*", "1.*-*", "1.0.*-beta*", "*-rc.*", "*-*"} {
+		fr, err := ParseFloatRange(s)
+		if err != nil {
+			t.Fatalf("ParseFloatRange(%q) error = %v", s, err)
+		}
+		if got := fr.String(); got != s {
+			t.Errorf("ParseFloatRange(%q).String() = %q", s, got)
+		}
+	}
+}